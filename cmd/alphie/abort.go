@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var (
+	abortForce     bool
+	abortDryRun    bool
+	abortResetBase bool
+	abortWait      time.Duration
+)
+
+var abortCmd = &cobra.Command{
+	Use:   "abort <session-or-epic-id>",
+	Short: "Stop a session and roll back what it did",
+	Long: `Stop a session and return the repository to where it started.
+
+Given a session ID, abort stops the session if it is still running, then:
+  - removes its agent worktrees and deletes their branches
+  - deletes the session branch
+  - checks out the branch and commit the session started from
+  - cancels its unfinished prog tasks
+  - marks the session canceled
+
+Given an epic ID, it does the same for every session that executed the
+epic, returns the repository to where the first of them started, and
+cancels the epic itself.
+
+Work already merged into the starting branch is kept unless --reset-base
+is given, which resets the branch to the commit the session started from
+and drops every commit made on it since, including your own.
+
+Examples:
+  alphie abort 7f3a2b1c                  # Preview, confirm, roll back
+  alphie abort 7f3a2b1c --dry-run        # Only show what would be undone
+  alphie abort ts-a1b2c3 --reset-base    # Roll back a whole epic and its merges`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAbort,
+}
+
+func init() {
+	abortCmd.Flags().BoolVarP(&abortForce, "force", "f", false, "Skip confirmation prompt")
+	abortCmd.Flags().BoolVar(&abortDryRun, "dry-run", false, "Show what would be undone without changing anything")
+	abortCmd.Flags().BoolVar(&abortResetBase, "reset-base", false, "Reset the starting branch to the commit the session started from")
+	abortCmd.Flags().DurationVar(&abortWait, "wait", 2*time.Minute, "How long to wait for a running session to stop")
+}
+
+func runAbort(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	db := openOwnershipDB(repoPath)
+	if db == nil {
+		return fmt.Errorf("no sessions recorded in %s", state.ProjectDBPath(repoPath))
+	}
+	defer db.Close()
+
+	// prog is optional: without it, prog tasks are left alone
+	var tracker prog.ProgTracker
+	if client, err := prog.NewClientDefault(filepath.Base(repoPath)); err == nil {
+		defer client.Close()
+		tracker = client
+	} else {
+		fmt.Printf("Warning: prog tasks will not be canceled: %v\n", err)
+	}
+
+	wtManager, err := agent.NewWorktreeManager("", repoPath, agent.WithOwnershipStore(db))
+	if err != nil {
+		return fmt.Errorf("create worktree manager: %w", err)
+	}
+	rollback := orchestrator.NewRollback(db, git.NewRunner(repoPath), wtManager, tracker)
+
+	plan, err := rollback.Plan(id)
+	if err != nil {
+		return err
+	}
+
+	// A running session must stop before its worktrees and branches go
+	control := orchestrator.NewControlClient(orchestrator.ControlSocketPath(repoPath))
+	ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+	status, err := control.Status(ctx)
+	cancel()
+	running := ""
+	switch {
+	case errors.Is(err, orchestrator.ErrNoRunningSession):
+	case err != nil:
+		return fmt.Errorf("check for a running session: %w", err)
+	case !slices.Contains(plan.SessionIDs, status.SessionID):
+		return fmt.Errorf("session %s is running in this repository and is not part of %s; stop it first", status.SessionID, id)
+	default:
+		running = status.SessionID
+	}
+
+	printRollbackPlan(plan, running)
+	if abortDryRun {
+		fmt.Println("Dry run mode - nothing was changed.")
+		return nil
+	}
+
+	if !abortForce {
+		fmt.Print("Roll back? [y/N] ")
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("read confirmation: %w", err)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Abort cancelled.")
+			return nil
+		}
+	}
+
+	if running != "" {
+		fmt.Printf("Stopping session %s...\n", running)
+		if err := stopSession(cmd.Context(), control, abortWait); err != nil {
+			return err
+		}
+		// The session may have created worktrees and branches since
+		if plan, err = rollback.Plan(id); err != nil {
+			return err
+		}
+	}
+
+	if err := rollback.Apply(plan, abortResetBase); err != nil {
+		return fmt.Errorf("roll back: %w", err)
+	}
+
+	fmt.Printf("Rolled back %s; %s is checked out at %s.\n", id, orDetached(plan.BaseBranch), shortHash(plan.BaseCommit))
+	if len(plan.DiscardedCommits) > 0 && !abortResetBase {
+		fmt.Printf("%s still has %d commit(s) made since the session started; rerun with --reset-base to drop them.\n",
+			plan.BaseBranch, len(plan.DiscardedCommits))
+	}
+	return nil
+}
+
+// stopSession asks the running session to abort and waits until it has
+// released its control socket.
+func stopSession(ctx context.Context, control *orchestrator.ControlClient, wait time.Duration) error {
+	abortCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	_, err := control.Abort(abortCtx)
+	cancel()
+	if err != nil && !errors.Is(err, orchestrator.ErrNoRunningSession) {
+		return fmt.Errorf("abort running session: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		statusCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := control.Status(statusCtx)
+		cancel()
+		if errors.Is(err, orchestrator.ErrNoRunningSession) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return fmt.Errorf("session still running after %s; rerun abort once it has stopped", wait)
+}
+
+// printRollbackPlan prints what a rollback undoes.
+func printRollbackPlan(plan *orchestrator.RollbackPlan, running string) {
+	if plan.WholeEpic {
+		fmt.Printf("Epic %s (%d session(s))\n", plan.EpicID, len(plan.SessionIDs))
+	} else {
+		fmt.Printf("Session %s\n", plan.SessionIDs[0])
+	}
+	if running != "" {
+		fmt.Printf("  Stop running session %s\n", running)
+	}
+	fmt.Printf("  Check out %s at %s\n", orDetached(plan.BaseBranch), shortHash(plan.BaseCommit))
+	if n := len(plan.DiscardedCommits); n > 0 {
+		if abortResetBase {
+			fmt.Printf("  Drop %d commit(s) made on %s since then\n", n, plan.BaseBranch)
+		} else {
+			fmt.Printf("  Keep %d commit(s) made on %s since then (use --reset-base to drop them)\n", n, plan.BaseBranch)
+		}
+	}
+	for _, path := range plan.Worktrees {
+		fmt.Printf("  Remove worktree %s\n", path)
+	}
+	for _, branch := range append(slices.Clone(plan.AgentBranches), plan.SessionBranches...) {
+		fmt.Printf("  Delete branch %s\n", branch)
+	}
+	if len(plan.ProgTasks) > 0 {
+		fmt.Printf("  Cancel prog tasks %s\n", strings.Join(plan.ProgTasks, ", "))
+	}
+	if plan.WholeEpic {
+		fmt.Printf("  Cancel epic %s\n", plan.EpicID)
+	}
+}
+
+// orDetached names the branch a session started on, or a detached HEAD.
+func orDetached(branch string) string {
+	if branch == "" || branch == "HEAD" {
+		return "detached HEAD"
+	}
+	return branch
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var (
+	annotateBase    string
+	annotateSession string
+	annotateFormat  string
+	annotateOutput  string
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate [head]",
+	Short: "Explain what a session changed and why",
+	Long: `Produce an annotated diff of a session where every hunk is attributed
+to the task (and, for architect runs, the spec feature) that produced it.
+
+Agent work lands on the session branch as "Merge branch 'agent-<task>'"
+commits. annotate walks those merges between base and head, diffs each
+one, and looks the task up in the state and prog databases.
+
+Head defaults to HEAD (or session-<id> with --session). Base defaults to
+the merge base of head with the default branch.
+
+Formats:
+  markdown (default)  Readable report grouped by task
+  review              GitHub pull request review JSON with one inline
+                      comment per hunk
+
+Examples:
+  alphie annotate --session abc123 -o changes.md
+  alphie annotate --base main --format review -o review.json
+  gh api repos/{owner}/{repo}/pulls/42/reviews --input review.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAnnotate,
+}
+
+func init() {
+	annotateCmd.Flags().StringVar(&annotateBase, "base", "", "Base ref (default: merge base with the default branch)")
+	annotateCmd.Flags().StringVar(&annotateSession, "session", "", "Annotate the session-<id> branch")
+	annotateCmd.Flags().StringVar(&annotateFormat, "format", "markdown", "Output format: markdown or review")
+	annotateCmd.Flags().StringVarP(&annotateOutput, "output", "o", "", "Write to file instead of stdout")
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) error {
+	if annotateFormat != "markdown" && annotateFormat != "review" {
+		return fmt.Errorf("unknown format %q (use markdown or review)", annotateFormat)
+	}
+
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	g := git.NewRunner(repoPath)
+
+	head := "HEAD"
+	switch {
+	case len(args) == 1 && annotateSession != "":
+		return fmt.Errorf("pass either a head ref or --session, not both")
+	case len(args) == 1:
+		head = args[0]
+	case annotateSession != "":
+		head = "session-" + annotateSession
+	}
+
+	base := annotateBase
+	if base == "" {
+		base, err = defaultAnnotateBase(g, head)
+		if err != nil {
+			return err
+		}
+	}
+
+	lookup, closeLookup := newTaskLookup(repoPath)
+	defer closeLookup()
+
+	report, err := annotate.Build(g, base, head, lookup)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if annotateOutput != "" {
+		f, err := os.Create(annotateOutput)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", annotateOutput, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if annotateFormat == "review" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report.Review())
+	}
+	return report.Markdown(w)
+}
+
+// defaultAnnotateBase returns the merge base of head with the repository's
+// default branch.
+func defaultAnnotateBase(g git.Runner, head string) (string, error) {
+	branch, err := git.DetectDefaultBranch(g)
+	if err != nil {
+		return "", fmt.Errorf("%w; pass --base explicitly", err)
+	}
+	base, err := g.MergeBase(branch, head)
+	if err != nil {
+		return "", fmt.Errorf("find merge base of %s and %s: %w", branch, head, err)
+	}
+	headCommit, err := g.Run("rev-parse", head)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", head, err)
+	}
+	if base == headCommit {
+		return "", fmt.Errorf("%s is already contained in %s; pass --base explicitly", head, branch)
+	}
+	return base, nil
+}
+
+// newTaskLookup resolves task IDs from the project state database, falling
+// back to prog for tasks created by architect runs. Both sources are
+// optional; unknown tasks are reported by ID only. The returned func closes
+// the databases.
+func newTaskLookup(repoPath string) (annotate.TaskLookup, func()) {
+	var closers []func()
+
+	// Only read an existing state database; opening creates one otherwise.
+	var db *state.DB
+	if state.Exists(state.ProjectDBPath(repoPath)) {
+		if opened, err := state.OpenProject(repoPath); err == nil {
+			if err := opened.Migrate(); err != nil {
+				opened.Close()
+			} else {
+				db = opened
+				closers = append(closers, func() { db.Close() })
+			}
+		}
+	}
+
+	progClient, err := prog.NewClientDefault(filepath.Base(repoPath))
+	if err == nil {
+		closers = append(closers, func() { progClient.Close() })
+	} else {
+		progClient = nil
+	}
+
+	lookup := func(taskID string) *annotate.Task {
+		if db != nil {
+			if t, err := db.GetTask(taskID); err == nil && t != nil {
+				task := &annotate.Task{ID: t.ID, Title: t.Title, Description: t.Description}
+				if t.ParentID != "" {
+					if p, err := db.GetTask(t.ParentID); err == nil && p != nil {
+						task.Parent = p.Title
+					}
+				}
+				return task
+			}
+		}
+		if progClient != nil {
+			if item, err := progClient.GetItem(taskID); err == nil && item != nil {
+				task := &annotate.Task{ID: item.ID, Title: item.Title, Description: strings.TrimSpace(item.Description)}
+				if item.ParentID != nil {
+					if p, err := progClient.GetItem(*item.ParentID); err == nil && p != nil {
+						task.Parent = p.Title
+					}
+				}
+				return task
+			}
+		}
+		return nil
+	}
+
+	return lookup, func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+}
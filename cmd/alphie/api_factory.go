@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/anthropics/anthropic-sdk-go"
 
@@ -23,11 +24,18 @@ func (f *ProcessRunnerFactory) NewRunner() agent.ClaudeRunner {
 
 // createRunnerFactory creates a ClaudeRunnerFactory for Claude execution.
 // If useCLI is true, uses subprocess (claude CLI). Otherwise uses API.
+// The usage of every runner it creates is recorded in its own UsageMeter,
+// see agent.UsageMeterOf.
 func createRunnerFactory(useCLI bool) (agent.ClaudeRunnerFactory, error) {
-	if useCLI {
-		return &ProcessRunnerFactory{}, nil
+	var factory agent.ClaudeRunnerFactory = &ProcessRunnerFactory{}
+	if !useCLI {
+		var err error
+		factory, err = createRunnerFactoryWithModel(anthropic.ModelClaudeSonnet4_20250514)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return createRunnerFactoryWithModel(anthropic.ModelClaudeSonnet4_20250514)
+	return &agent.MeteredRunnerFactory{Factory: factory, Meter: agent.NewUsageMeter()}, nil
 }
 
 // createRunnerFactoryWithModel creates an API factory with a specific model.
@@ -57,6 +65,9 @@ func createRunnerFactoryWithModel(model anthropic.Model) (agent.ClaudeRunnerFact
 		return nil, fmt.Errorf("create API client: %w", err)
 	}
 
+	limiter := apiRateLimiter(cfg)
+	apiClient.SetLimiter(limiter)
+
 	cwd, _ := os.Getwd()
 	notifs, err := api.NewNotificationManager(cwd)
 	if err != nil {
@@ -66,5 +77,30 @@ func createRunnerFactoryWithModel(model anthropic.Model) (agent.ClaudeRunnerFact
 	return &agent.APIRunnerFactory{
 		Client:        apiClient,
 		Notifications: notifs,
+		RateLimiter:   limiter,
 	}, nil
 }
+
+var (
+	rateLimiterOnce sync.Once
+	rateLimiter     *agent.RateLimiter
+)
+
+// apiRateLimiter returns the rate limiter shared by every API runner of
+// the process, so all agents queue for the same limits however many runner
+// factories a command creates. It is configured from cfg on first use.
+func apiRateLimiter(cfg *config.Config) *agent.RateLimiter {
+	rateLimiterOnce.Do(func() {
+		limits := cfg.RateLimits
+		models := make(map[string]agent.RateLimit, len(limits.Models))
+		for model, m := range limits.Models {
+			models[model] = agent.RateLimit{RequestsPerMinute: m.RequestsPerMinute, TokensPerMinute: m.TokensPerMinute}
+		}
+		rateLimiter = agent.NewRateLimiter(agent.RateLimiterConfig{
+			Default:    agent.RateLimit{RequestsPerMinute: limits.RequestsPerMinute, TokensPerMinute: limits.TokensPerMinute},
+			Models:     models,
+			MaxRetries: limits.MaxRetries,
+		})
+	})
+	return rateLimiter
+}
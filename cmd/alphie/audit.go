@@ -11,15 +11,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var auditJSON bool
+var (
+	auditJSON         bool
+	auditNoCache      bool
+	auditFeatureTests string
+	auditSequential   bool
+)
 
 var auditCmd = &cobra.Command{
-	Use:   "audit <arch.md>",
+	Use:   "audit <arch.md|spec-dir>",
 	Short: "Audit codebase against architecture specification",
 	Long: `Audit the current codebase against an architecture document.
 
-This command parses an architecture specification (markdown file) and
-compares it against the actual codebase to identify implementation gaps.
+This command parses an architecture specification (a markdown file, or a
+directory of markdown files composed into one spec) and compares it against
+the actual codebase to identify implementation gaps.
 
 The audit process:
   1. Parses the architecture document to extract features/requirements
@@ -33,6 +39,7 @@ Output formats:
 Examples:
   alphie audit docs/architecture.md           # Human-readable report
   alphie audit docs/architecture.md --json    # JSON output
+  alphie audit spec.md --feature-tests tests.yaml  # Also run each feature's acceptance tests
   alphie audit spec.md | jq '.gaps'           # Filter JSON for gaps only`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAudit,
@@ -40,6 +47,9 @@ Examples:
 
 func init() {
 	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output in JSON format")
+	auditCmd.Flags().StringVar(&auditFeatureTests, "feature-tests", "", "YAML file mapping feature IDs to the acceptance tests proving them")
+	auditCmd.Flags().BoolVar(&auditSequential, "sequential", false, "Run the acceptance tests after the semantic review instead of alongside the code layers")
+	auditCmd.Flags().BoolVar(&auditNoCache, "no-cache", false, "Call Claude even when the spec and codebase are unchanged since a cached parse or audit")
 }
 
 func runAudit(cmd *cobra.Command, args []string) error {
@@ -56,9 +66,20 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("get working directory: %w", err)
 	}
 
+	var featureTests architect.FeatureTestMap
+	if auditFeatureTests != "" {
+		if featureTests, err = architect.LoadFeatureTestMap(auditFeatureTests); err != nil {
+			return err
+		}
+	}
+
 	// Create a context for the operation
 	ctx := context.Background()
 
+	// Answer an unchanged spec and codebase from earlier responses
+	promptCache, closeCache := openPromptCache(repoPath, auditNoCache)
+	defer closeCache()
+
 	// Create runner factory for API calls
 	runnerFactory, err := createRunnerFactory(false) // audit always uses API
 	if err != nil {
@@ -74,6 +95,7 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	}
 
 	parser := architect.NewParser()
+	parser.SetPromptCache(promptCache)
 	spec, err := parser.Parse(ctx, docPath, parserClaude)
 	if err != nil {
 		return fmt.Errorf("parse architecture document: %w", err)
@@ -92,10 +114,23 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	}
 
 	auditor := architect.NewAuditor()
+	auditor.SetPromptCache(promptCache)
+	auditor.SetSequential(auditSequential)
+	auditor.SetRubric(reviewRubric())
+	if featureTests != nil {
+		auditor.SetFeatureTests(featureTests)
+	}
 	report, err := auditor.Audit(ctx, spec, repoPath, auditorClaude)
 	if err != nil {
 		return fmt.Errorf("audit codebase: %w", err)
 	}
+	if !auditJSON {
+		for _, s := range promptCache.Report() {
+			if s.Hits > 0 {
+				fmt.Printf("Reused the cached %s (alphie cache clear to discard)\n", s.Kind)
+			}
+		}
+	}
 
 	// Output the report
 	if auditJSON {
@@ -128,8 +163,13 @@ func outputAuditHumanReadable(report *architect.GapReport) error {
 	completeCount := 0
 	partialCount := 0
 	missingCount := 0
+	deferredCount := 0
 
 	for _, fs := range report.Features {
+		if fs.Feature.IsDeferred() {
+			deferredCount++
+			continue
+		}
 		switch fs.Status {
 		case architect.AuditStatusComplete:
 			completeCount++
@@ -140,31 +180,90 @@ func outputAuditHumanReadable(report *architect.GapReport) error {
 		}
 	}
 
-	fmt.Printf("Feature Status: %d complete, %d partial, %d missing\n",
+	fmt.Printf("Feature Status: %d complete, %d partial, %d missing",
 		completeCount, partialCount, missingCount)
+	if deferredCount > 0 {
+		fmt.Printf(" (%d deferred)", deferredCount)
+	}
+	fmt.Println()
+	if report.Score != nil && report.Score.Total > 0 {
+		fmt.Printf("Weighted Completion: %.0f%%", report.Score.WeightedCompletion)
+		if report.Score.CriticalTotal > 0 {
+			fmt.Printf(" (%d/%d critical features complete)", report.Score.CriticalComplete, report.Score.CriticalTotal)
+		}
+		fmt.Println()
+	}
 	fmt.Println()
 
 	// Detailed feature status
 	fmt.Println("--- Feature Details ---")
 	for _, fs := range report.Features {
 		statusIcon := auditStatusIcon(fs.Status)
-		fmt.Printf("\n%s [%s] %s\n", statusIcon, fs.Feature.ID, fs.Feature.Name)
+		fmt.Printf("\n%s [%s] %s", statusIcon, fs.Feature.ID, fs.Feature.Name)
+		if fs.Feature.IsDeferred() {
+			fmt.Printf(" (deferred: %s)", fs.Feature.Deferred)
+		}
+		fmt.Println()
 
 		if fs.Evidence != "" {
 			fmt.Printf("   Evidence: %s\n", truncateAuditStr(fs.Evidence, 100))
 		}
+		for _, a := range fs.Anchors {
+			if a.Problem != "" {
+				fmt.Printf("   Bad anchor: %s (%s)\n", a, a.Problem)
+			}
+		}
 		if fs.Reasoning != "" {
 			fmt.Printf("   Reasoning: %s\n", truncateAuditStr(fs.Reasoning, 150))
 		}
 	}
 
+	// Acceptance tests section
+	if len(report.AcceptanceTests) > 0 {
+		fmt.Println()
+		fmt.Println("--- Acceptance Tests ---")
+		for _, r := range report.AcceptanceTests {
+			result := "PASS"
+			if !r.Passed {
+				result = "FAIL"
+			}
+			fmt.Printf("\n[%s] %s\n", r.FeatureID, result)
+			for _, sr := range r.Results {
+				if sr.Passed {
+					fmt.Printf("   ok    %s\n", sr.Command)
+				} else {
+					fmt.Printf("   FAIL  %s: %s\n", sr.Command, truncateAuditStr(sr.Reason, 100))
+				}
+			}
+		}
+	}
+
+	// Static analysis section
+	if len(report.StaticChecks) > 0 {
+		fmt.Println()
+		fmt.Println("--- Static Analysis ---")
+		for _, c := range report.StaticChecks {
+			fmt.Printf("\n[%s] %d/%d required names declared\n", c.FeatureID, len(c.Found), len(c.Found)+len(c.Missing))
+			for _, ref := range c.Found {
+				fmt.Printf("   found    %s (%s:%d)\n", ref.Name, ref.File, ref.Line)
+			}
+			for _, name := range c.Missing {
+				fmt.Printf("   missing  %s\n", name)
+			}
+		}
+	}
+
 	// Gaps section
 	if len(report.Gaps) > 0 {
 		fmt.Println()
 		fmt.Println("--- Gaps Requiring Action ---")
 		for _, gap := range report.Gaps {
 			statusIcon := auditStatusIcon(gap.Status)
-			fmt.Printf("\n%s [%s] %s\n", statusIcon, gap.FeatureID, gap.Status)
+			fmt.Printf("\n%s [%s] %s", statusIcon, gap.FeatureID, gap.Status)
+			if gap.Severity != "" {
+				fmt.Printf(" (%s)", gap.Severity)
+			}
+			fmt.Println()
 			fmt.Printf("   Issue: %s\n", gap.Description)
 			if gap.SuggestedAction != "" {
 				fmt.Printf("   Suggested: %s\n", gap.SuggestedAction)
@@ -175,6 +274,15 @@ func outputAuditHumanReadable(report *architect.GapReport) error {
 		fmt.Println("No gaps found - all features appear to be implemented!")
 	}
 
+	if len(report.Deferred) > 0 {
+		fmt.Println()
+		fmt.Println("--- Deferred Gaps (not required) ---")
+		for _, gap := range report.Deferred {
+			fmt.Printf("\n%s [%s] %s\n", auditStatusIcon(gap.Status), gap.FeatureID, gap.Status)
+			fmt.Printf("   Issue: %s\n", gap.Description)
+		}
+	}
+
 	fmt.Println()
 	return nil
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+var auditTrailOutput string
+
+var auditTrailCmd = &cobra.Command{
+	Use:   "audit-trail [session-id]",
+	Short: "Export a session's tamper-evident audit trail as JSON",
+	Long: `Export the audit trail of a session: its complete event log, every
+approval, rejection, override and waiver with who made it, and the merges
+that brought the work onto the main branch.
+
+Event log records are hash-chained, so the export reports whether the log
+was modified after it was written. Keep chain.head_hash somewhere outside
+the repository to also detect records removed from the end of the log.
+
+The command fails if the hash chain does not verify; the trail is still
+written so the damage can be inspected.
+
+Without a session ID the most recent session is used.
+
+Examples:
+  alphie audit-trail                          # Latest session to stdout
+  alphie audit-trail abc123 -o audit.json     # Session abc123 to a file`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAuditTrail,
+}
+
+func init() {
+	auditTrailCmd.Flags().StringVarP(&auditTrailOutput, "output", "o", "", "Write the audit trail to a file instead of stdout")
+}
+
+func runAuditTrail(cmd *cobra.Command, args []string) error {
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	var path string
+	if len(args) == 1 {
+		path = orchestrator.EventLogPath(repoPath, args[0])
+	} else {
+		path, err = latestEventLog(repoPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	records, err := orchestrator.ReadEventLog(path)
+	if err != nil {
+		return err
+	}
+	trail := orchestrator.NewAuditTrail(strings.TrimSuffix(filepath.Base(path), ".jsonl"), records)
+
+	var w io.Writer = os.Stdout
+	if auditTrailOutput != "" {
+		f, err := os.Create(auditTrailOutput)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", auditTrailOutput, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeAuditTrail(w, trail); err != nil {
+		return err
+	}
+	if auditTrailOutput != "" {
+		fmt.Fprintf(os.Stderr, "Audit trail of session %s written to %s (%d events, %d decisions)\n",
+			trail.SessionID, auditTrailOutput, len(trail.Events), len(trail.Decisions))
+	}
+
+	if !trail.Chain.Verified {
+		return fmt.Errorf("audit trail does not verify: %s", trail.Chain.Problem)
+	}
+	return nil
+}
+
+// writeAuditTrail writes the trail as indented JSON.
+func writeAuditTrail(w io.Writer, trail *orchestrator.AuditTrail) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(trail); err != nil {
+		return fmt.Errorf("write audit trail: %w", err)
+	}
+	return nil
+}
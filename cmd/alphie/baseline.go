@@ -64,6 +64,14 @@ func showBaseline() {
 	}
 
 	fmt.Printf("Baseline captured at: %s\n", baseline.CapturedAt.Format("2006-01-02 15:04:05"))
+	if baseline.Commit != "" {
+		fmt.Printf("Commit: %s\n", baseline.Commit)
+	}
+	if baseline.BuildPassed {
+		fmt.Println("Build: passing")
+	} else {
+		fmt.Println("Build: failing")
+	}
 	fmt.Printf("Failing tests: %d\n", len(baseline.FailingTests))
 	for _, t := range baseline.FailingTests {
 		fmt.Printf("  - %s\n", t)
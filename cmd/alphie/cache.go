@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var cacheKind string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache [stats | clear]",
+	Short: "Show or clear the prompt cache",
+	Long: `Show or clear the prompt cache of alphie audit and alphie implement.
+
+Parsing a spec and auditing a codebase are deterministic calls. Their
+responses are cached in .alphie/state.db, addressed by the prompt and, for
+audits, the repository's commit and uncommitted changes. Re-running them on
+an unchanged spec or codebase is answered from the cache instead of paying
+for the call again; a changed spec or repository misses the cache and
+replaces the stale entry. Pass --no-cache to audit or implement to bypass it.
+
+Commands:
+  alphie cache                      # Hit rate and entries per kind of call
+  alphie cache clear                # Delete every cached response
+  alphie cache clear --kind audit   # Delete the cached audits only`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCache,
+}
+
+func init() {
+	cacheCmd.Flags().StringVar(&cacheKind, "kind", "", "Kind of cached call to clear (parse or audit; default all)")
+}
+
+func runCache(cmd *cobra.Command, args []string) error {
+	subcommand := "stats"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	switch cacheKind {
+	case "", architect.PromptKindParse, architect.PromptKindAudit:
+	default:
+		return fmt.Errorf("unknown kind %q (use parse or audit)", cacheKind)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		fmt.Println("The prompt cache is empty.")
+		return nil
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate state database: %w", err)
+	}
+
+	switch subcommand {
+	case "stats":
+		return printPromptCacheStats(db)
+	case "clear":
+		n, err := db.InvalidatePrompts(cacheKind, "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %d cached response(s)\n", n)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q (use stats or clear)", subcommand)
+	}
+}
+
+// printPromptCacheStats prints the hit rate and entries of each kind of
+// cached call.
+func printPromptCacheStats(db *state.DB) error {
+	stats, err := db.PromptCacheStats()
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Println("The prompt cache is empty.")
+		return nil
+	}
+	fmt.Printf("%-8s  %8s  %8s  %8s  %8s\n", "KIND", "HITS", "MISSES", "HIT RATE", "ENTRIES")
+	for _, s := range stats {
+		fmt.Printf("%-8s  %8d  %8d  %7.0f%%  %8d\n", s.Kind, s.Hits, s.Misses, s.HitRate()*100, s.Entries)
+	}
+	return nil
+}
+
+// openPromptCache opens the prompt cache of the repository at repoPath. It
+// returns a nil cache, which caches nothing, when disabled or when the
+// state database cannot be opened: the cache only saves cost.
+func openPromptCache(repoPath string, disabled bool) (*architect.PromptCache, func()) {
+	if disabled {
+		return nil, func() {}
+	}
+	db, err := state.OpenProject(repoPath)
+	if err != nil {
+		return nil, func() {}
+	}
+	if err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, func() {}
+	}
+	return architect.NewPromptCache(db), func() { db.Close() }
+}
@@ -14,10 +14,12 @@ import (
 )
 
 var (
-	cleanupForce    bool
-	cleanupVerbose  bool
-	cleanupDryRun   bool
-	cleanupSessions bool
+	cleanupForce            bool
+	cleanupVerbose          bool
+	cleanupDryRun           bool
+	cleanupSessions         bool
+	cleanupDrainPool        bool
+	cleanupUnmergedBranches bool
 )
 
 var cleanupCmd = &cobra.Command{
@@ -29,11 +31,19 @@ This command:
   - Lists all Alphie-related worktrees
   - Identifies orphaned worktrees (no active session)
   - Removes orphaned worktrees and their branches
+  - Removes worktrees whose owning alphie process has exited
+  - Deletes leftover agent branches already merged into HEAD
   - Runs git worktree prune
 
 With --sessions flag:
   - Deletes sessions older than 30 days from the database
 
+With --drain-pool flag:
+  - Also removes idle pooled worktrees kept for reuse
+
+With --unmerged-branches flag:
+  - Also deletes agent branches that were never merged (their work is lost)
+
 Use this after a crash or interrupted session to clean up.
 
 Examples:
@@ -41,7 +51,8 @@ Examples:
   alphie cleanup --force      # Skip confirmation prompt
   alphie cleanup --dry-run    # Show what would be removed
   alphie cleanup -v           # Verbose output showing each removal
-  alphie cleanup --sessions   # Also purge sessions older than 30 days`,
+  alphie cleanup --sessions   # Also purge sessions older than 30 days
+  alphie cleanup --drain-pool # Also remove pooled worktrees`,
 	RunE: runCleanup,
 }
 
@@ -50,6 +61,8 @@ func init() {
 	cleanupCmd.Flags().BoolVarP(&cleanupVerbose, "verbose", "v", false, "Show each worktree as it's removed")
 	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "Show what would be removed without removing")
 	cleanupCmd.Flags().BoolVar(&cleanupSessions, "sessions", false, "Purge sessions older than 30 days")
+	cleanupCmd.Flags().BoolVar(&cleanupDrainPool, "drain-pool", false, "Also remove idle pooled worktrees")
+	cleanupCmd.Flags().BoolVar(&cleanupUnmergedBranches, "unmerged-branches", false, "Also delete agent branches that were never merged")
 }
 
 func runCleanup(cmd *cobra.Command, args []string) error {
@@ -64,8 +77,15 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("find git repository: %w", err)
 	}
 
+	// Worktree ownership is recorded in the project database by runs
+	var wtOpts []agent.WorktreeManagerOption
+	if db := openOwnershipDB(repoPath); db != nil {
+		defer db.Close()
+		wtOpts = append(wtOpts, agent.WithOwnershipStore(db))
+	}
+
 	// Create worktree manager
-	wtManager, err := agent.NewWorktreeManager("", repoPath)
+	wtManager, err := agent.NewWorktreeManager("", repoPath, wtOpts...)
 	if err != nil {
 		return fmt.Errorf("create worktree manager: %w", err)
 	}
@@ -88,11 +108,6 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("list orphaned worktrees: %w", err)
 	}
 
-	if len(orphans) == 0 && !cleanupSessions {
-		fmt.Println("No orphaned worktrees found.")
-		return nil
-	}
-
 	if len(orphans) > 0 {
 		// Display orphan count and list
 		fmt.Printf("Found %d orphaned worktree(s):\n", len(orphans))
@@ -154,6 +169,10 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		fmt.Println("No orphaned worktrees found.")
 	}
 
+	if err := pruneStaleWorktrees(wtManager); err != nil {
+		return err
+	}
+
 	// Handle session cleanup if --sessions flag is set
 	if cleanupSessions {
 		if err := cleanupOldSessions(cwd); err != nil {
@@ -164,17 +183,68 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pruneStaleWorktrees removes worktrees left behind by exited processes and
+// leftover agent branches, honoring --dry-run.
+func pruneStaleWorktrees(wtManager *agent.WorktreeManager) error {
+	report, err := wtManager.PruneStale(agent.StaleCleanupOptions{
+		DryRun:           cleanupDryRun,
+		DrainPool:        cleanupDrainPool,
+		UnmergedBranches: cleanupUnmergedBranches,
+	})
+	if err != nil {
+		return fmt.Errorf("prune stale worktrees: %w", err)
+	}
+
+	verb := "Removed"
+	if cleanupDryRun {
+		verb = "Would remove"
+	}
+	if cleanupVerbose {
+		for _, path := range report.Worktrees {
+			fmt.Printf("%s stale worktree: %s\n", verb, path)
+		}
+		for _, branch := range report.Branches {
+			fmt.Printf("%s agent branch: %s\n", verb, branch)
+		}
+	}
+	if len(report.Worktrees) > 0 || len(report.Branches) > 0 {
+		fmt.Printf("%s %d stale worktree(s) and %d agent branch(es).\n", verb, len(report.Worktrees), len(report.Branches))
+	}
+	if len(report.Forgotten) > 0 && cleanupVerbose {
+		fmt.Printf("Dropped %d ownership record(s) for missing worktrees.\n", len(report.Forgotten))
+	}
+	return nil
+}
+
+// openOwnershipDB opens the project database that records worktree
+// ownership. Returns nil if the project has no database.
+func openOwnershipDB(repoPath string) *state.DB {
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		return nil
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return nil
+	}
+	if err := db.Migrate(); err != nil {
+		db.Close()
+		return nil
+	}
+	return db
+}
+
 // cleanupOldSessions purges sessions older than 30 days.
 func cleanupOldSessions(cwd string) error {
 	const sessionMaxAge = 30 * 24 * time.Hour // 30 days
 
 	dbPath := state.ProjectDBPath(cwd)
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		// Fall back to global database
 		dbPath = state.GlobalDBPath()
 	}
 
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		fmt.Println("No database found - no sessions to purge.")
 		return nil
 	}
@@ -226,12 +296,12 @@ func getActiveSessions() ([]string, error) {
 	}
 
 	dbPath := state.ProjectDBPath(cwd)
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		// Fall back to global database
 		dbPath = state.GlobalDBPath()
 	}
 
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		// No database exists, return empty list
 		return []string{}, nil
 	}
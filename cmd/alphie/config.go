@@ -55,6 +55,7 @@ func displayAllConfig(cfg *config.Config) {
 	fmt.Printf("timeouts.scout: %s\n", cfg.Timeouts.Scout)
 	fmt.Printf("timeouts.builder: %s\n", cfg.Timeouts.Builder)
 	fmt.Printf("timeouts.architect: %s\n", cfg.Timeouts.Architect)
+	fmt.Printf("timeouts.stall: %s\n", cfg.Timeouts.Stall)
 	fmt.Printf("quality_gates.test: %t\n", cfg.QualityGates.Test)
 	fmt.Printf("quality_gates.build: %t\n", cfg.QualityGates.Build)
 	fmt.Printf("quality_gates.lint: %t\n", cfg.QualityGates.Lint)
@@ -106,6 +107,8 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 		return cfg.Timeouts.Builder.String(), nil
 	case "timeouts.architect":
 		return cfg.Timeouts.Architect.String(), nil
+	case "timeouts.stall":
+		return cfg.Timeouts.Stall.String(), nil
 	case "quality_gates.test":
 		return strconv.FormatBool(cfg.QualityGates.Test), nil
 	case "quality_gates.build":
@@ -156,6 +159,12 @@ func setConfigValue(cfg *config.Config, key, value string) error {
 			return fmt.Errorf("invalid duration for timeouts.architect: %w", err)
 		}
 		cfg.Timeouts.Architect = d
+	case "timeouts.stall":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration for timeouts.stall: %w", err)
+		}
+		cfg.Timeouts.Stall = d
 	case "quality_gates.test":
 		b, err := strconv.ParseBool(value)
 		if err != nil {
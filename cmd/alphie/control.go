@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+var controlCmd = &cobra.Command{
+	Use:   "control [status | pause | resume | max-agents <n> | cancel <task-id> | approve [note] | reject [note]]",
+	Short: "Pause, resume or throttle a running session, or answer its approval gates",
+	Long: `Control a session running in this repository without stopping it.
+
+While agents are executing, a session listens on a control socket
+(.alphie/control.sock) that only the user running it can reach.
+
+Commands:
+  alphie control                   # Show the running session's status
+  alphie control pause             # Stop spawning agents; running ones finish
+  alphie control resume            # Spawn agents again
+  alphie control max-agents 1      # Change how many agents run at once
+  alphie control cancel <task-id>  # Stop a task's agent and fail the task
+  alphie control approve [note]    # Let the run past its approval gate
+  alphie control reject [note]     # Stop the run at its approval gate
+
+Lowering max-agents does not stop running agents; no new ones start until
+fewer than the new limit are running. A cancelled task is not retried and
+its dependents stay blocked.
+
+A run held at an approval gate (approval.gates in the config) serves the
+control socket until the gate is answered, even between the sessions of
+alphie implement. The note is recorded with the decision in the audit
+trail.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runControl,
+}
+
+func runControl(cmd *cobra.Command, args []string) error {
+	subcommand := "status"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	client := orchestrator.NewControlClient(orchestrator.ControlSocketPath(repoPath))
+	ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+	defer cancel()
+
+	var status *orchestrator.ControlStatus
+	switch subcommand {
+	case "status":
+		status, err = client.Status(ctx)
+	case "pause":
+		status, err = client.Pause(ctx)
+	case "resume":
+		status, err = client.Resume(ctx)
+	case "max-agents":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: alphie control max-agents <n>")
+		}
+		n, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("invalid agent count %q", args[1])
+		}
+		status, err = client.SetMaxAgents(ctx, n)
+	case "cancel":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: alphie control cancel <task-id>")
+		}
+		status, err = client.CancelTask(ctx, args[1])
+	case "approve":
+		status, err = client.Approve(ctx, strings.Join(args[1:], " "))
+	case "reject":
+		status, err = client.Reject(ctx, strings.Join(args[1:], " "))
+	default:
+		return fmt.Errorf("unknown subcommand %q (use status, pause, resume, max-agents, cancel, approve or reject)", subcommand)
+	}
+	if err != nil {
+		return err
+	}
+
+	printControlStatus(status)
+	return nil
+}
+
+// printControlStatus prints a running session's status.
+func printControlStatus(status *orchestrator.ControlStatus) {
+	state := "running"
+	if status.Paused {
+		state = "paused"
+	}
+	fmt.Printf("Session %s (%s)\n", status.SessionID, state)
+	fmt.Printf("  Max agents: %d\n", status.MaxAgents)
+	fmt.Printf("  Tasks:      %d/%d complete\n", status.TasksCompleted, status.TasksTotal)
+	if a := status.Approval; a != nil {
+		fmt.Printf("  Waiting for approval at the %s gate (since %s):\n", a.Gate, a.RequestedAt.Format("15:04:05"))
+		for _, line := range strings.Split(a.Summary, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	if len(status.Running) == 0 {
+		fmt.Println("  No agents running")
+		return
+	}
+	fmt.Println("  Running:")
+	for _, r := range status.Running {
+		fmt.Printf("    %-10s  %-40s  %s\n", r.TaskID, truncate(r.Title, 40), time.Since(r.StartedAt).Round(time.Second))
+	}
+}
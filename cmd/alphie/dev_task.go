@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+var (
+	devTaskTier   string
+	devTaskMerge  bool
+	devTaskUseCLI bool
+)
+
+var devTaskCmd = &cobra.Command{
+	Use:   "dev-task <task.yaml | prog-task-id>",
+	Short: "Run one task through the full agent pipeline for debugging",
+	Long: `Run exactly one task through the executor, validation layers and merge
+queue without decomposing a request or running a whole session.
+
+Use it to debug or tune prompts, validation and merge behavior on a task
+you can run again and again.
+
+The task is read from a YAML file:
+
+  title: Add a --json flag to the status command
+  description: |
+    Print the status as JSON when --json is given.
+  acceptance_criteria: alphie status --json prints valid JSON
+  verification_intent: status output parses as JSON
+  task_type: FEATURE
+  tier: builder
+  file_boundaries: [cmd/alphie/status.go]
+
+or taken from a prog task by ID (its status in prog is not changed).
+
+The agent's work is merged into a session branch, which is kept for
+inspection instead of being merged into the main branch. Use --merge to
+merge it as a normal run would. The session's event log is written to
+.alphie/events/ as usual; 'alphie inspect' and 'alphie audit-trail' work
+on it.
+
+Examples:
+  alphie dev-task task.yaml
+  alphie dev-task ts-a1b2c3 --tier scout
+  alphie dev-task task.yaml --merge`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDevTask,
+}
+
+func init() {
+	devTaskCmd.Flags().StringVar(&devTaskTier, "tier", "builder", "Tier for tasks that do not set one: scout, builder, or architect")
+	devTaskCmd.Flags().BoolVar(&devTaskMerge, "merge", false, "Merge the session branch into the main branch when the task succeeds")
+	devTaskCmd.Flags().BoolVar(&devTaskUseCLI, "cli", false, "Use Claude CLI subprocess instead of API")
+}
+
+func runDevTask(cmd *cobra.Command, args []string) error {
+	tier := models.Tier(devTaskTier)
+	if !tier.Valid() || tier == models.TierQuick {
+		return fmt.Errorf("invalid tier %q: must be scout, builder, or architect", devTaskTier)
+	}
+
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	task, err := loadDevTask(args[0], repoPath, tier)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckClaudeCLI(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Println("\nReceived interrupt, shutting down...")
+		cancel()
+	}()
+
+	db, err := state.OpenProject(repoPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+
+	runnerFactory, err := createRunnerFactory(devTaskUseCLI)
+	if err != nil {
+		return fmt.Errorf("create runner factory: %w", err)
+	}
+
+	appConfig, err := config.Load()
+	if err != nil {
+		appConfig = config.Default()
+	}
+	registerValidationLayers(appConfig)
+
+	executor, err := agent.NewExecutor(agent.ExecutorConfig{
+		RepoPath:         repoPath,
+		Model:            modelForTier(task.Tier),
+		RunnerFactory:    runnerFactory,
+		WarmUps:          warmUpsFromConfig(appConfig),
+		WorktreePoolSize: appConfig.Scheduling.WorktreePoolSize,
+		WorktreeStore:    db,
+		CommitIdentity:   commitIdentity(appConfig),
+	})
+	if err != nil {
+		return fmt.Errorf("create executor: %w", err)
+	}
+
+	tierConfigs, err := config.LoadTierConfigs(filepath.Join(repoPath, "configs"))
+	if err != nil {
+		tierConfigs = config.DefaultTierConfigs()
+	}
+
+	// An invalid protected-area policy aborts the run rather than going unenforced
+	protectedPolicy, err := protect.LoadPolicy(repoPath)
+	if err != nil {
+		return fmt.Errorf("load protected-area policy: %w", err)
+	}
+
+	orch := orchestrator.New(
+		orchestrator.RequiredConfig{
+			RepoPath: repoPath,
+			Tier:     task.Tier,
+			Executor: executor,
+		},
+		orchestrator.WithTasks(task),
+		orchestrator.WithKeepSessionBranch(!devTaskMerge),
+		orchestrator.WithMaxAgents(1),
+		orchestrator.WithTierConfigs(tierConfigs),
+		orchestrator.WithPolicy(policyFromConfig(appConfig)),
+		orchestrator.WithProtectedAreaChecker(protectedAreasFromConfig(appConfig)),
+		orchestrator.WithSandboxAllowlist(appConfig.Sandbox.Allowlist),
+		orchestrator.WithProtectedPolicy(protectedPolicy),
+		orchestrator.WithMainBranch(appConfig.Merge.DefaultBranch),
+		orchestrator.WithCommitIdentity(commitIdentity(appConfig)),
+		orchestrator.WithMergerClaude(runnerFactory.NewRunner()),
+		orchestrator.WithSecondReviewerClaude(runnerFactory.NewRunner()),
+		orchestrator.WithRunnerFactory(runnerFactory),
+		orchestrator.WithRateLimiter(agent.RateLimiterOf(runnerFactory)),
+		orchestrator.WithUsageMeter(agent.UsageMeterOf(runnerFactory)),
+		orchestrator.WithStateDB(db),
+	)
+	defer orch.Stop()
+
+	go consumeEventsHeadless(orch.Events())
+
+	fmt.Printf("Dev task %s: %s\n", task.ID, task.Title)
+	fmt.Printf("  Tier: %s\n", task.Tier)
+	fmt.Printf("  Session: %s\n\n", orch.SessionID())
+
+	runErr := orch.Run(ctx, task.Title)
+
+	fmt.Println()
+	if branch := orch.GetSessionBranch(); branch != "" && !devTaskMerge {
+		fmt.Printf("Session branch: %s (kept for inspection)\n", branch)
+	}
+	fmt.Printf("Event log: %s\n", orchestrator.EventLogPath(repoPath, orch.SessionID()))
+	if runErr != nil {
+		return fmt.Errorf("dev task failed: %w", runErr)
+	}
+	fmt.Println("Dev task completed successfully")
+	return nil
+}
+
+// loadDevTask reads a task from a YAML file, or from prog if arg is not a file.
+func loadDevTask(arg, repoPath string, tier models.Tier) (*models.Task, error) {
+	if _, err := os.Stat(arg); err == nil {
+		def, err := orchestrator.LoadTaskDefinition(arg)
+		if err != nil {
+			return nil, err
+		}
+		return def.Task(tier), nil
+	}
+
+	client, err := prog.NewClientDefault(filepath.Base(repoPath))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a file and prog is unavailable: %w", arg, err)
+	}
+	defer client.Close()
+	item, err := client.GetItem(arg)
+	if err != nil {
+		return nil, fmt.Errorf("get prog task %s: %w", arg, err)
+	}
+	if item.Type != prog.ItemTypeTask {
+		return nil, fmt.Errorf("prog item %s is not a task", arg)
+	}
+	return orchestrator.TaskFromProgItem(item, tier), nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/prog"
+)
+
+var (
+	epicAll         bool
+	epicDescription string
+	epicPriority    int
+	epicDependsOn   []string
+)
+
+var epicCmd = &cobra.Command{
+	Use:   "epic [list | show <id> | add <epic-id> <title> | cancel <task-id> [reason] | note <id> <text>]",
+	Short: "Co-manage the epics that sessions execute",
+	Long: `Inspect and edit the prog epics that alphie sessions plan and execute.
+
+Every run records its plan as an epic with one task per unit of work.
+These commands work on the same epics, so you can follow progress, add
+work the planner missed, cancel tasks you no longer want, and leave notes
+for the agents. Changes take effect when the epic is resumed: new tasks
+are executed, canceled tasks are skipped, and notes are part of the task
+description the agent sees.
+
+Commands:
+  alphie epic                                 # List open epics
+  alphie epic list --all                      # Include done and canceled epics
+  alphie epic show <id>                       # Show progress, tasks and notes
+  alphie epic add <epic-id> Add rate limiting --priority 1 --depends-on ts-a1b2c3
+  alphie epic cancel <task-id> done by hand   # Cancel a task
+  alphie epic note <id> use the v2 client     # Attach a note to a task or epic`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runEpic,
+}
+
+func init() {
+	epicCmd.Flags().BoolVar(&epicAll, "all", false, "List done and canceled epics too")
+	epicCmd.Flags().StringVar(&epicDescription, "description", "", "Description of the added task")
+	epicCmd.Flags().IntVar(&epicPriority, "priority", 2, "Priority of the added task (1=high, 2=medium, 3=low)")
+	epicCmd.Flags().StringSliceVar(&epicDependsOn, "depends-on", nil, "IDs of tasks the added task depends on")
+}
+
+func runEpic(cmd *cobra.Command, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	switch {
+	case subcommand == "show" && len(args) != 2:
+		return fmt.Errorf("usage: alphie epic show <id>")
+	case subcommand == "add" && len(args) < 3:
+		return fmt.Errorf("usage: alphie epic add <epic-id> <title>")
+	case subcommand == "cancel" && len(args) < 2:
+		return fmt.Errorf("usage: alphie epic cancel <task-id> [reason]")
+	case subcommand == "note" && len(args) < 3:
+		return fmt.Errorf("usage: alphie epic note <id> <text>")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	client, err := prog.NewClientDefault(filepath.Base(repoPath))
+	if err != nil {
+		return fmt.Errorf("open prog database: %w", err)
+	}
+	defer client.Close()
+
+	switch subcommand {
+	case "list":
+		return listEpics(client)
+	case "show":
+		return showEpic(client, args[1])
+	case "add":
+		id, err := client.AddTaskToEpic(args[1], strings.Join(args[2:], " "), &prog.TaskOptions{
+			Description: epicDescription,
+			Priority:    epicPriority,
+			DependsOn:   epicDependsOn,
+		})
+		if err != nil {
+			return err
+		}
+		_ = client.AddLog(id, "Added by hand")
+		fmt.Printf("Added task %s to epic %s\n", id, args[1])
+		return nil
+	case "cancel":
+		if err := client.CancelTask(args[1], strings.Join(args[2:], " ")); err != nil {
+			return err
+		}
+		fmt.Printf("Canceled task %s; it is skipped when its epic is resumed\n", args[1])
+		return nil
+	case "note":
+		if err := client.AddNote(args[1], strings.Join(args[2:], " ")); err != nil {
+			return err
+		}
+		fmt.Printf("Added note to %s\n", args[1])
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q (use list, show, add, cancel or note)", subcommand)
+	}
+}
+
+// listEpics prints the project's epics with their progress.
+func listEpics(client *prog.Client) error {
+	epics, err := client.ListEpics(epicAll)
+	if err != nil {
+		return err
+	}
+	if len(epics) == 0 {
+		fmt.Println("No open epics.")
+		return nil
+	}
+	for _, epic := range epics {
+		completed, total, err := client.ComputeEpicProgress(epic.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-10s  %-11s  %3d/%-3d  %s\n", epic.ID, epic.Status, completed, total, truncate(epic.Title, 60))
+	}
+	return nil
+}
+
+// showEpic prints an epic's progress, its tasks with their dependencies,
+// and the epic's log.
+func showEpic(client *prog.Client, id string) error {
+	epic, err := client.GetEpic(id)
+	if err != nil {
+		return err
+	}
+	tasks, err := client.GetChildTasks(id)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[prog.Status]int)
+	for _, t := range tasks {
+		counts[t.Status]++
+	}
+	fmt.Printf("Epic %s (%s)\n", epic.ID, epic.Status)
+	fmt.Printf("  Title:    %s\n", epic.Title)
+	fmt.Printf("  Progress: %d/%d done", counts[prog.StatusDone], len(tasks)-counts[prog.StatusCanceled])
+	for _, status := range []prog.Status{prog.StatusInProgress, prog.StatusBlocked, prog.StatusOpen, prog.StatusCanceled} {
+		if counts[status] > 0 {
+			fmt.Printf(", %d %s", counts[status], status)
+		}
+	}
+	fmt.Println()
+
+	if len(tasks) > 0 {
+		fmt.Println()
+		fmt.Println("Tasks:")
+		for _, t := range tasks {
+			line := fmt.Sprintf("  %-10s  %-11s  P%d  %s", t.ID, t.Status, t.Priority, truncate(t.Title, 50))
+			if deps, err := client.GetDependencies(t.ID); err == nil && len(deps) > 0 {
+				line += fmt.Sprintf("  (after %s)", strings.Join(deps, ", "))
+			}
+			fmt.Println(line)
+		}
+	}
+
+	logs, err := client.GetLogs(id)
+	if err != nil {
+		return err
+	}
+	if len(logs) > 0 {
+		fmt.Println()
+		fmt.Println("Log:")
+		for _, l := range logs {
+			fmt.Printf("  %s  %s\n", l.CreatedAt.Format("2006-01-02 15:04"), l.Message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var (
+	escalationsAll      bool
+	escalationsOperator string
+)
+
+var escalationsCmd = &cobra.Command{
+	Use:   "escalations [list | show <id> | resolve <id> <resolution>]",
+	Short: "Resolve tasks parked for human intervention",
+	Long: `Resolve tasks that need a human before they can go on.
+
+When a task runs out of attempts, or keeps failing verification, it is
+parked instead of failed and an escalation is recorded here with the
+reason, what went wrong and the suggested resolutions. Escalations persist
+across sessions: a running session resumes the task as soon as it is
+resolved, otherwise the next session to resume the task does.
+
+Resolutions:
+  retry               # Re-run the task with a fresh set of attempts
+  retry: <guidance>   # Re-run it, telling the agent what to do differently
+  skip                # Treat the task as done (e.g. you fixed it by hand)
+  abandon             # Fail the task; its dependents stay blocked
+Any other text is taken as guidance for a retry.
+
+Commands:
+  alphie escalations                         # List open escalations
+  alphie escalations list --all              # Include resolved escalations
+  alphie escalations show <id>               # Show the reason and context
+  alphie escalations resolve <id> retry: use the staging API key`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runEscalations,
+}
+
+func init() {
+	escalationsCmd.Flags().BoolVar(&escalationsAll, "all", false, "List resolved escalations too")
+	escalationsCmd.Flags().StringVar(&escalationsOperator, "operator", "", "Identity recorded for the resolution (default: git user.email)")
+}
+
+func runEscalations(cmd *cobra.Command, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	switch {
+	case subcommand == "show" && len(args) != 2:
+		return fmt.Errorf("usage: alphie escalations show <id>")
+	case subcommand == "resolve" && len(args) < 3:
+		return fmt.Errorf("usage: alphie escalations resolve <id> <resolution>")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		fmt.Println("No open escalations.")
+		return nil
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate state database: %w", err)
+	}
+
+	inbox := orchestrator.NewEscalationInbox(db, repoPath)
+	switch subcommand {
+	case "list":
+		return listEscalations(inbox)
+	case "show":
+		return showEscalation(inbox, args[1])
+	case "resolve":
+		e, err := inbox.Resolve(args[1], strings.Join(args[2:], " "), escalationsOperator)
+		if err != nil {
+			return err
+		}
+		action, _ := orchestrator.ParseResolution(e.Resolution)
+		fmt.Printf("Resolved %s (%s); the running session, or the next to resume task %s, applies it\n", e.ID, action, e.TaskID)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q (use list, show or resolve)", subcommand)
+	}
+}
+
+// listEscalations prints the escalation inbox.
+func listEscalations(inbox *orchestrator.EscalationInbox) error {
+	escalations, err := inbox.List(escalationsAll)
+	if err != nil {
+		return err
+	}
+	if len(escalations) == 0 {
+		fmt.Println("No open escalations.")
+		return nil
+	}
+	for _, e := range escalations {
+		line := fmt.Sprintf("%-8s  %-8s  %-30s  %s", e.ID, e.Status, truncate(e.TaskTitle, 30), e.Reason)
+		if e.ResolvedBy != "" {
+			line += fmt.Sprintf("  (%s by %s)", e.Resolution, e.ResolvedBy)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// showEscalation prints an escalation and its suggested resolutions.
+func showEscalation(inbox *orchestrator.EscalationInbox, id string) error {
+	e, err := inbox.Get(id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Escalation %s (%s)\n", e.ID, e.Status)
+	fmt.Printf("  Task:    %s %s\n", e.TaskID, e.TaskTitle)
+	fmt.Printf("  Raised:  %s\n", e.CreatedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("  Reason:  %s\n", e.Reason)
+	if e.Resolution != "" {
+		fmt.Printf("  Resolution: %s (by %s)\n", e.Resolution, e.ResolvedBy)
+	}
+	if e.Context != "" {
+		fmt.Println()
+		fmt.Println(e.Context)
+	}
+	if e.Status == state.EscalationOpen && len(e.Options) > 0 {
+		fmt.Println()
+		fmt.Println("Options:")
+		for _, option := range e.Options {
+			fmt.Printf("  %s\n", option)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// Process exit codes. Wrappers and CI pipelines can branch on these
+// instead of parsing human-oriented output.
+const (
+	exitSuccess            = 0
+	exitError              = 1
+	exitVerificationFailed = 2
+	exitBudgetExceeded     = 3
+	exitEscalated          = 4
+	exitRejected           = 5
+	exitMaxIterations      = 6
+	exitNoProgress         = 7
+	exitDeadline           = 8
+	exitIterationTimeout   = 9
+	// exitCanceled follows the shell convention for SIGINT (128+2).
+	exitCanceled = 130
+)
+
+// Final run statuses recorded in .alphie/last-run.json.
+const (
+	runStatusSuccess            = "success"
+	runStatusVerificationFailed = "verification-failed"
+	runStatusBudgetExceeded     = "budget-exceeded"
+	runStatusEscalated          = "escalated"
+	runStatusRejected           = "rejected"
+	runStatusMaxIterations      = "max-iterations"
+	runStatusNoProgress         = "no-progress"
+	runStatusDeadline           = "deadline"
+	runStatusIterationTimeout   = "iteration-timeout"
+	runStatusCanceled           = "canceled"
+	runStatusInterrupted        = "interrupted"
+	runStatusError              = "error"
+)
+
+// lastRunFile is where the final status of run and implement is written,
+// relative to the working directory.
+const lastRunFile = ".alphie/last-run.json"
+
+// lastRunAnnotation marks commands whose outcome is recorded in lastRunFile.
+const lastRunAnnotation = "alphie.last-run"
+
+// lastRun is the machine-readable final status of a command.
+type lastRun struct {
+	Status          string    `json:"status"`
+	ExitCode        int       `json:"exit_code"`
+	Command         string    `json:"command"`
+	Args            []string  `json:"args,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Version         string    `json:"version"`
+}
+
+// exitStatus maps a command error to its final status and exit code.
+// Escalation is checked before verification because a merge handed to a
+// human may also wrap the verification failure that caused it.
+func exitStatus(err error) (string, int) {
+	switch {
+	case err == nil:
+		return runStatusSuccess, exitSuccess
+	case errors.Is(err, orchestrator.ErrSessionInterrupted):
+		return runStatusInterrupted, exitCanceled
+	case errors.Is(err, orchestrator.ErrSessionAborted), errors.Is(err, context.Canceled):
+		return runStatusCanceled, exitCanceled
+	case errors.Is(err, orchestrator.ErrBudgetExceeded):
+		return runStatusBudgetExceeded, exitBudgetExceeded
+	case errors.Is(err, orchestrator.ErrMergeNeedsHuman), errors.Is(err, orchestrator.ErrSessionMergeRejected):
+		return runStatusEscalated, exitEscalated
+	case errors.Is(err, orchestrator.ErrVerificationFailed):
+		return runStatusVerificationFailed, exitVerificationFailed
+	case errors.Is(err, orchestrator.ErrApprovalRejected):
+		return runStatusRejected, exitRejected
+	case errors.Is(err, architect.ErrMaxIterations):
+		return runStatusMaxIterations, exitMaxIterations
+	case errors.Is(err, architect.ErrNoProgress):
+		return runStatusNoProgress, exitNoProgress
+	case errors.Is(err, architect.ErrDeadline):
+		return runStatusDeadline, exitDeadline
+	case errors.Is(err, architect.ErrIterationTimeout):
+		return runStatusIterationTimeout, exitIterationTimeout
+	default:
+		return runStatusError, exitError
+	}
+}
+
+// recordsLastRun reports whether cmd writes lastRunFile.
+func recordsLastRun(cmd *cobra.Command) bool {
+	return cmd != nil && cmd.Annotations[lastRunAnnotation] == "true"
+}
+
+// writeLastRun writes the final status of cmd to lastRunFile under dir.
+func writeLastRun(dir string, cmd *cobra.Command, args []string, started time.Time, runErr error) error {
+	status, code := exitStatus(runErr)
+	finished := time.Now()
+	rec := lastRun{
+		Status:          status,
+		ExitCode:        code,
+		Command:         cmd.Name(),
+		Args:            args,
+		StartedAt:       started,
+		FinishedAt:      finished,
+		DurationSeconds: finished.Sub(started).Seconds(),
+		Version:         Version(),
+	}
+	if runErr != nil {
+		rec.Error = runErr.Error()
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode last run: %w", err)
+	}
+	path := filepath.Join(dir, lastRunFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	// Write to a temp file and rename so readers never see a partial file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
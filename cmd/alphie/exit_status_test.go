@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantCode   int
+	}{
+		{"success", nil, runStatusSuccess, exitSuccess},
+		{"generic error", errors.New("boom"), runStatusError, exitError},
+		{"canceled", fmt.Errorf("orchestration failed: %w", context.Canceled), runStatusCanceled, exitCanceled},
+		{"aborted", fmt.Errorf("execution loop: %w", orchestrator.ErrSessionAborted), runStatusCanceled, exitCanceled},
+		{"interrupted", fmt.Errorf("orchestration failed: %w", &orchestrator.SessionInterruptedError{Checkpoint: &orchestrator.Checkpoint{}}), runStatusInterrupted, exitCanceled},
+		{"budget", &orchestrator.BudgetExceededError{Scope: "session", Spent: 6, Limit: 5}, runStatusBudgetExceeded, exitBudgetExceeded},
+		{"verification", fmt.Errorf("task t1: %w", orchestrator.ErrVerificationFailed), runStatusVerificationFailed, exitVerificationFailed},
+		{"session merge held", fmt.Errorf("%w: declined (branch session-1 kept)", orchestrator.ErrSessionMergeRejected), runStatusEscalated, exitEscalated},
+		{"escalated wins over verification", &orchestrator.MergeConflictError{TaskID: "t1", Err: orchestrator.ErrVerificationFailed}, runStatusEscalated, exitEscalated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := exitStatus(tt.err)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("exitStatus() = %s/%d, want %s/%d", status, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestExitStatus_ImplementStops checks every reason implement stops for
+// short of a complete spec ends with its own status and a non-zero code.
+func TestExitStatus_ImplementStops(t *testing.T) {
+	tests := []struct {
+		reason     architect.StopReason
+		wantStatus string
+		wantCode   int
+	}{
+		{architect.StopReasonMaxVerificationRounds, runStatusVerificationFailed, exitVerificationFailed},
+		{architect.StopReasonBudgetExceeded, runStatusBudgetExceeded, exitBudgetExceeded},
+		{architect.StopReasonRejected, runStatusRejected, exitRejected},
+		{architect.StopReasonMaxIterations, runStatusMaxIterations, exitMaxIterations},
+		{architect.StopReasonConverged, runStatusNoProgress, exitNoProgress},
+		{architect.StopReasonDeadline, runStatusDeadline, exitDeadline},
+		{architect.StopReasonIterationTimeout, runStatusIterationTimeout, exitIterationTimeout},
+	}
+	codes := map[int]architect.StopReason{}
+	for _, tt := range tests {
+		t.Run(string(tt.reason), func(t *testing.T) {
+			err := &architect.StopError{Reason: tt.reason, Iteration: 3, CompletionPct: 60}
+			status, code := exitStatus(err)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("exitStatus() = %s/%d, want %s/%d", status, code, tt.wantStatus, tt.wantCode)
+			}
+			if other, ok := codes[code]; ok {
+				t.Errorf("exit code %d is shared by %s and %s", code, other, tt.reason)
+			}
+			codes[code] = tt.reason
+		})
+	}
+}
+
+func TestWriteLastRun(t *testing.T) {
+	dir := t.TempDir()
+	cmd := &cobra.Command{Use: "run", Annotations: map[string]string{lastRunAnnotation: "true"}}
+	if !recordsLastRun(cmd) || recordsLastRun(&cobra.Command{Use: "status"}) {
+		t.Fatal("recordsLastRun should follow the annotation")
+	}
+
+	started := time.Now().Add(-2 * time.Second)
+	runErr := &orchestrator.BudgetExceededError{Scope: "session", Spent: 6, Limit: 5}
+	if err := writeLastRun(dir, cmd, []string{"add login"}, started, runErr); err != nil {
+		t.Fatalf("writeLastRun: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, lastRunFile))
+	if err != nil {
+		t.Fatalf("read last run: %v", err)
+	}
+	var rec lastRun
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("decode last run: %v", err)
+	}
+	if rec.Status != runStatusBudgetExceeded || rec.ExitCode != exitBudgetExceeded {
+		t.Errorf("status = %s/%d", rec.Status, rec.ExitCode)
+	}
+	if rec.Command != "run" || len(rec.Args) != 1 || rec.Error == "" || rec.DurationSeconds < 2 {
+		t.Errorf("record = %+v", rec)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lastRunFile+".tmp")); !os.IsNotExist(err) {
+		t.Error("temp file left behind")
+	}
+}
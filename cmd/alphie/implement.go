@@ -2,28 +2,54 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/remote"
 	"github.com/ShayCichocki/alphie/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	implementAgents          int
-	implementMaxIterations   int
-	implementBudget          float64
-	implementNoConvergeAfter int
-	implementDryRun          bool
-	implementResume          bool
-	implementProject         string
-	implementUseCLI          bool
+	implementAgents               int
+	implementMaxIterations        int
+	implementBudget               float64
+	implementNoConvergeAfter      int
+	implementDryRun               bool
+	implementResume               bool
+	implementProject              string
+	implementUseCLI               bool
+	implementJSON                 bool
+	implementReportDir            string
+	implementPlanOnly             bool
+	implementIncludeDeferred      bool
+	implementFeatureTests         string
+	implementSequentialVerify     bool
+	implementVerificationRounds   int
+	implementVerificationMode     string
+	implementScoring              string
+	implementVerify               string
+	implementGreenfield           bool
+	implementBaseBranch           string
+	implementPR                   bool
+	implementNoCache              bool
+	implementDeadline             time.Duration
+	implementMaxIterationDuration time.Duration
+	implementShutdownGrace        time.Duration
 )
 
 var implementCmd = &cobra.Command{
-	Use:   "implement <spec.md|spec.xml>",
+	Use:   "implement <spec.md|spec.xml|spec-dir>",
 	Short: "Implement architecture specification iteratively",
 	Long: `Implement an architecture specification by iterating through audit-plan-execute cycles.
 
@@ -37,23 +63,139 @@ This command orchestrates the full architecture implementation loop:
 Supported formats:
   - Markdown (.md) - Standard markdown with sections and headers
   - XML (.xml) - Custom XML schemas with features/requirements
+  - Directory - Every Markdown file in it, composed into one spec
+
+Modular specs:
+  A spec directory is composed index.md (or README.md) first, then the other
+  Markdown files in path order. A line <!-- include: path.md --> inlines
+  another file in place; included files are not composed again. Links to
+  other spec files are kept as cross-file references. Feature IDs written in
+  the spec are kept; features without one get a stable ID derived from their
+  file and name (e.g. auth/user-login), so adding files does not renumber them.
+
+Scoring and verification (--scoring, --verify):
+  Every gap is rated low, medium, high or critical. --scoring weighted
+  gives PARTIAL features partial credit by their most severe gap and counts
+  critical features double; the stop condition and progress use that
+  percentage instead of the share of complete features. --verify critical
+  completes the run once every critical feature is complete and passes its
+  acceptance tests; gaps in other features are reported but do not fail the
+  final verification. Specs without critical features require them all.
+
+Deferred features:
+  Features the spec marks as optional, deferred or for a later phase (e.g.
+  "Phase 2", "Nice to have") are audited and listed in a separate section of
+  the reports, but are not planned and do not count toward completion or
+  final verification. Pass --include-deferred to implement them too.
 
 Stop conditions:
   - All features implemented (100% completion)
   - Maximum iterations reached (--max-iterations)
+  - Verification failed too often (--max-verification-rounds)
   - Budget exceeded (--budget)
   - No progress for N iterations (--no-converge-after)
+  - Wall-clock deadline passed (--deadline)
+  - An iteration ran too long (--max-iteration-duration)
+
+  A no-progress stop writes convergence.md to --report-dir: the gaps that
+  persisted, the tasks that failed repeatedly, their suspected causes and
+  the recommended human interventions.
+
+  When a time limit passes, running agents are stopped and the loop ends
+  without another audit.
+
+Graceful shutdown:
+  The first SIGINT or SIGTERM starts no further iteration and gives the
+  running agents --shutdown-grace to finish and merge. Agents still running
+  after that are stopped. The merged work is kept and the command to
+  continue is printed: running implement again audits that work and plans
+  only the gaps left. A second signal stops immediately.
 
 Examples:
   alphie implement docs/architecture.md                    # Markdown spec
   alphie implement spec.xml                                # XML spec
+  alphie implement docs/spec/                              # Spec split across files
   alphie implement spec.md --agents 5                      # Use 5 concurrent agents
   alphie implement spec.md --max-iterations 20             # Allow more iterations
   alphie implement spec.md --budget 10.00                  # Cap cost at $10
+  alphie implement spec.md --deadline 4h                   # Stop after 4 hours (CI jobs)
   alphie implement spec.md --dry-run                       # Show plan without executing
-  alphie implement spec.md --project myproject             # Use specific prog project`,
-	Args: cobra.ExactArgs(1),
-	RunE: runImplement,
+  alphie implement spec.md --plan-only                     # Audit and print task plan with cost estimates
+  alphie implement spec.md --include-deferred              # Also implement phase-2/optional features
+  alphie implement spec.md --feature-tests tests.yaml      # Verify features with their acceptance tests
+  alphie implement spec.md --verify critical               # Done once all critical features are complete
+  alphie implement spec.md --project myproject             # Use specific prog project
+  alphie implement spec.md --json                          # Stream NDJSON progress (no TUI)
+  alphie implement spec.md --report-dir docs/status        # Write audit reports to docs/status
+  alphie implement spec.md --pr                            # Open a pull/merge request when done
+
+Plan-only mode (--plan-only):
+  Parses the spec, audits the codebase and decomposes the gaps into tasks,
+  then prints every task with its dependencies and estimated tokens and cost,
+  plus the total estimate. No agents run and nothing is written to prog;
+  only the parse, audit and planning calls are billed.
+
+Acceptance tests (--feature-tests):
+  A YAML file maps feature IDs to the tests proving them. Every audit,
+  including the final verification, runs them and adds each feature's
+  pass/fail as a validation layer next to the semantic review; the final
+  verification check fails while an in-scope feature's tests fail. The
+  tests run concurrently with the code context and static cross-check, and
+  the semantic review is given their results. --sequential-verification
+  runs them after the review instead, which does not see them.
+
+    auth/login:
+      - package: ./internal/auth
+        run: TestLogin
+    F2:
+      - package: ./...
+        tags: [integration]
+        run: TestRefund
+    F3:
+      - pytest -m checkout      # any shell command; passes if it exits 0
+
+Audit reports:
+  After every audit, including the final one, a Markdown and an HTML report
+  (audit-report.md, audit-report.html) with per-feature status, evidence
+  files, gaps, suggested fixes and the agent cost per feature are written to
+  --report-dir. Once final verification passes, a traceability matrix
+  (traceability.json, traceability.md) mapping each feature to the tasks
+  that implemented it, the files they changed, the tests covering it and the
+  audit's evidence is written there too. Pass an empty --report-dir to
+  disable them.
+
+Verification artifacts:
+  Every audit also writes its raw artifacts to .alphie/verify/<run-id>/:
+  the gap report (audit.json), each feature's verdict from every validation
+  layer (layers.json), the gap list (gaps.json) and the transcript of the
+  semantic review: the prompt, Claude's raw response and any error
+  (transcript.md). A failed audit's error names its transcript.
+
+Pull requests (--pr):
+  Epics merge into a new alphie/implement-<timestamp> branch instead of the
+  current branch. When the loop stops, the branch is pushed and a pull
+  request against the current branch is opened with the spec summary and
+  the final audit report. The validation-layer and final-verification
+  results are reported as checks or commit statuses, and second-reviewer
+  concerns are posted as review comments. Ignored with --greenfield.
+
+  The host is set by remote.provider in the config ("auto" detects it from
+  the remote URL, falling back to GitHub) and the remote by remote.remote:
+    github     GitHub pull request; requires an authenticated gh CLI
+    gitlab     GitLab merge request; requires an authenticated glab CLI
+    bitbucket  Bitbucket Cloud pull request; requires BITBUCKET_USERNAME and
+               BITBUCKET_APP_PASSWORD, or BITBUCKET_TOKEN
+
+JSON output (--json):
+  Disables the TUI and writes one JSON object per line to stdout. Each record
+  has a "type" of "progress" (phase updates), "task" (task started, completed,
+  failed, budget events), "estimate" (per-task cost estimates with low/high
+  bands before an iteration's tasks run) or "result" (final record with
+  "status" of "success" or "failed"), plus phase, iteration, feature counts
+  and cost.`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        runImplement,
+	Annotations: map[string]string{lastRunAnnotation: "true"},
 }
 
 func init() {
@@ -61,10 +203,27 @@ func init() {
 	implementCmd.Flags().IntVar(&implementMaxIterations, "max-iterations", 10, "Hard cap on iterations")
 	implementCmd.Flags().Float64Var(&implementBudget, "budget", 0, "Cost limit in dollars (0 = unlimited)")
 	implementCmd.Flags().IntVar(&implementNoConvergeAfter, "no-converge-after", 3, "Stop if no progress for N iterations")
+	implementCmd.Flags().DurationVar(&implementDeadline, "deadline", 0, "Stop after this much wall-clock time, e.g. 4h (0 = unlimited)")
+	implementCmd.Flags().DurationVar(&implementMaxIterationDuration, "max-iteration-duration", 0, "Stop when an iteration runs longer than this, e.g. 45m (0 = unlimited)")
+	implementCmd.Flags().DurationVar(&implementShutdownGrace, "shutdown-grace", 2*time.Minute, "How long running agents may finish after an interrupt before they are stopped")
 	implementCmd.Flags().BoolVar(&implementDryRun, "dry-run", false, "Show plan without executing")
 	implementCmd.Flags().BoolVar(&implementResume, "resume", false, "Resume from checkpoint")
 	implementCmd.Flags().StringVar(&implementProject, "project", "", "Prog project name (defaults to directory name)")
 	implementCmd.Flags().BoolVar(&implementUseCLI, "cli", false, "Use Claude CLI subprocess instead of API")
+	implementCmd.Flags().BoolVar(&implementJSON, "json", false, "Disable the TUI and stream NDJSON progress records to stdout")
+	implementCmd.Flags().BoolVar(&implementPlanOnly, "plan-only", false, "Audit and print the task plan with cost estimates without running agents")
+	implementCmd.Flags().BoolVar(&implementIncludeDeferred, "include-deferred", false, "Also implement features the spec marks as optional, deferred or for a later phase")
+	implementCmd.Flags().StringVar(&implementScoring, "scoring", "strict", "Completion scoring: strict (share of complete features) or weighted (partial credit by gap severity)")
+	implementCmd.Flags().IntVar(&implementVerificationRounds, "max-verification-rounds", 3, "Stop after this many failed verification rounds (0 = unlimited)")
+	implementCmd.Flags().StringVar(&implementVerificationMode, "verification-mode", "full", "What a verification round re-audits: every feature (full) or those that had gaps (incremental)")
+	implementCmd.Flags().StringVar(&implementVerify, "verify", "all", "Final verification requires all features complete, or only the critical ones (all|critical)")
+	implementCmd.Flags().StringVar(&implementFeatureTests, "feature-tests", "", "YAML file mapping feature IDs to the acceptance tests proving them")
+	implementCmd.Flags().BoolVar(&implementSequentialVerify, "sequential-verification", false, "Run the acceptance tests after the semantic review instead of alongside the code layers")
+	implementCmd.Flags().BoolVar(&implementGreenfield, "greenfield", false, "Direct merge to main (skip session branches)")
+	implementCmd.Flags().StringVar(&implementBaseBranch, "base-branch", "", "Branch to start from and merge into (default: merge.default_branch, else detected from origin/HEAD)")
+	implementCmd.Flags().BoolVar(&implementPR, "pr", false, "Push the work and open a pull request on the configured remote when done")
+	implementCmd.Flags().BoolVar(&implementNoCache, "no-cache", false, "Call Claude even when the spec and codebase are unchanged since a cached parse or audit")
+	implementCmd.Flags().StringVar(&implementReportDir, "report-dir", ".alphie/reports", "Directory for Markdown/HTML audit reports (empty disables)")
 }
 
 func runImplement(cmd *cobra.Command, args []string) error {
@@ -75,6 +234,16 @@ func runImplement(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("architecture document not found: %s", archDoc)
 	}
 
+	if _, err := architect.ParseScoringMode(implementScoring); err != nil {
+		return err
+	}
+	if _, err := architect.ParseStrictness(implementVerify); err != nil {
+		return err
+	}
+	if _, err := architect.ParseVerificationMode(implementVerificationMode); err != nil {
+		return err
+	}
+
 	// Get current working directory as repo path
 	repoPath, err := os.Getwd()
 	if err != nil {
@@ -93,6 +262,17 @@ func runImplement(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Load user/project config once for every mode (fallback to defaults)
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+
+	// JSON mode keeps stdout machine-readable: no banner, no TUI
+	if implementJSON {
+		return runImplementJSON(cfg, archDoc, repoPath, projectName)
+	}
+
 	// Display configuration
 	fmt.Println("=== Alphie Implement ===")
 	fmt.Println()
@@ -110,7 +290,16 @@ func runImplement(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  No-converge:      %d iterations\n", implementNoConvergeAfter)
 	fmt.Printf("  Dry-run:          %v\n", implementDryRun)
+	fmt.Printf("  Plan-only:        %v\n", implementPlanOnly)
+	fmt.Printf("  Include deferred: %v\n", implementIncludeDeferred)
+	fmt.Printf("  Scoring:          %s\n", implementScoring)
+	fmt.Printf("  Verify:           %s\n", implementVerify)
 	fmt.Printf("  Resume:           %v\n", implementResume)
+	fmt.Printf("  Greenfield:       %v\n", implementGreenfield)
+	fmt.Printf("  Pull request:     %v\n", implementPR && !implementGreenfield)
+	if implementReportDir != "" {
+		fmt.Printf("  Reports:          %s\n", implementReportDir)
+	}
 	fmt.Println()
 
 	// Handle dry-run mode
@@ -118,29 +307,59 @@ func runImplement(cmd *cobra.Command, args []string) error {
 		return runImplementDryRun(archDoc, repoPath)
 	}
 
+	if implementPlanOnly {
+		return runImplementPlanOnly(cfg, archDoc, repoPath, projectName)
+	}
+
 	// Handle resume mode (placeholder for future implementation)
 	if implementResume {
 		fmt.Println("Note: Resume mode not yet fully implemented, starting fresh")
 	}
 
 	// Create TUI program
-	program, _ := tui.NewImplementProgram()
+	program, app := tui.NewImplementProgram()
+
+	// Approval gates are answered with y or n in the TUI, or alphie control
+	approvals := implementApprovalGates(cfg)
+	if approvals != nil {
+		app.SetApprovalHandler(func(approved bool) error {
+			if approved {
+				return approvals.Approve("")
+			}
+			return approvals.Reject("")
+		})
+	}
 
 	// Create context that can be cancelled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	progressCallback := func(event architect.ProgressEvent) {
+		// Cost ticks only move the displayed cost
+		if event.EventType == string(orchestrator.EventCostTick) {
+			program.Send(tui.ImplementCostMsg{Cost: event.Cost})
+			return
+		}
+		switch event.EventType {
+		case string(orchestrator.EventApprovalRequired):
+			program.Send(tui.ImplementApprovalMsg{Pending: true, Summary: event.Message})
+		case string(orchestrator.EventApprovalResolved):
+			program.Send(tui.ImplementApprovalMsg{Pending: false})
+		}
+
 		phaseStr := string(event.Phase)
 
 		// Convert architect.WorkerInfo to tui.WorkerInfo
 		activeWorkers := make(map[string]tui.WorkerInfo)
 		for k, v := range event.ActiveWorkers {
 			activeWorkers[k] = tui.WorkerInfo{
-				AgentID:   v.AgentID,
-				TaskID:    v.TaskID,
-				TaskTitle: v.TaskTitle,
-				Status:    v.Status,
+				AgentID:          v.AgentID,
+				TaskID:           v.TaskID,
+				TaskTitle:        v.TaskTitle,
+				Status:           v.Status,
+				LogFile:          v.LogFile,
+				QuestionsAsked:   v.QuestionsAsked,
+				QuestionsAllowed: v.QuestionsAllowed,
 			}
 		}
 
@@ -167,35 +386,260 @@ func runImplement(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	// Create and configure the controller
+	controller, closeController, err := newImplementController(cfg, repoPath, projectName, approvals,
+		architect.WithProgressCallback(progressCallback),
+	)
+	if err != nil {
+		return err
+	}
+	defer closeController()
+
+	// A signal stops the TUI along with the run: no one may be at the
+	// keyboard to quit it, e.g. under SIGTERM
+	stopSignals := handleImplementSignals(controller, func() {
+		cancel()
+		program.Quit()
+	}, func(msg string) {
+		program.Send(tui.ImplementLogMsg{Timestamp: time.Now(), Message: msg})
+	})
+	defer stopSignals()
+
+	// Run controller in background goroutine; its error is the command's,
+	// so a run that stops short of complete exits non-zero
+	runErr := make(chan error, 1)
+	go func() {
+		err := controller.Run(ctx, archDoc, implementAgents)
+		runErr <- err
+		program.Send(tui.ImplementDoneMsg{Err: err})
+		if errors.Is(err, orchestrator.ErrSessionInterrupted) {
+			program.Quit()
+		}
+	}()
+
+	// Run TUI (blocks until quit)
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+
+	select {
+	case err := <-runErr:
+		printImplementCheckpoint(os.Stdout, err, archDoc)
+		return err
+	default:
+		// The TUI was quit while the controller was still running
+		return context.Canceled
+	}
+}
+
+// newImplementController builds the controller of every implement mode
+// from the flags and cfg, which is loaded once per run. opts add what the
+// mode needs on top, such as its progress callback. The returned func
+// releases the prompt cache once the controller is done.
+func newImplementController(cfg *config.Config, repoPath, projectName string, approvals *orchestrator.ApprovalGates, opts ...architect.ControllerOption) (*architect.Controller, func(), error) {
 	// Create runner factory (CLI subprocess or API)
 	runnerFactory, err := createRunnerFactory(implementUseCLI)
 	if err != nil {
-		return fmt.Errorf("create runner factory: %w", err)
+		return nil, nil, fmt.Errorf("create runner factory: %w", err)
+	}
+
+	provider, err := implementRemoteProvider(repoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create remote provider: %w", err)
+	}
+
+	featureTests, err := implementFeatureTestMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	promptCache, closeCache := openPromptCache(repoPath, implementNoCache)
+
+	// The sessions run under the same policy and sandbox as alphie run's
+	sessionPolicy := policyFromConfig(cfg)
+	tierConfigs, err := config.LoadTierConfigs(filepath.Join(repoPath, "configs"))
+	if err != nil {
+		tierConfigs = config.DefaultTierConfigs()
 	}
 
-	// Create and configure the controller
 	controller := architect.NewController(
 		implementMaxIterations,
 		implementBudget,
 		implementNoConvergeAfter,
-		architect.WithRepoPath(repoPath),
-		architect.WithProjectName(projectName),
-		architect.WithProgressCallback(progressCallback),
-		architect.WithRunnerFactory(runnerFactory),
+		append([]architect.ControllerOption{
+			architect.WithRepoPath(repoPath),
+			architect.WithProjectName(projectName),
+			architect.WithRunnerFactory(runnerFactory),
+			architect.WithReportDir(implementReportDir),
+			architect.WithPromptCache(promptCache),
+			architect.WithPlanOnly(implementPlanOnly),
+			architect.WithGreenfield(implementGreenfield),
+			architect.WithIncludeDeferred(implementIncludeDeferred),
+			architect.WithFeatureTests(featureTests),
+			architect.WithSequentialVerification(implementSequentialVerify),
+			architect.WithScoring(architect.ScoringMode(implementScoring)),
+			architect.WithStrictness(architect.Strictness(implementVerify)),
+			architect.WithMaxVerificationRounds(implementVerificationRounds),
+			architect.WithVerificationMode(architect.VerificationMode(implementVerificationMode)),
+			architect.WithDeadline(implementDeadline),
+			architect.WithMaxIterationDuration(implementMaxIterationDuration),
+			architect.WithBaseBranch(baseBranch(implementBaseBranch, cfg)),
+			architect.WithCommitIdentity(commitIdentity(cfg)),
+			architect.WithRemoteProvider(provider),
+			architect.WithApprovalGates(approvals),
+			architect.WithReviewRubric(sessionPolicy.Review.Rubric),
+			architect.WithPolicy(sessionPolicy),
+			architect.WithProtectedAreaChecker(protectedAreasFromConfig(cfg)),
+			architect.WithTierConfigs(tierConfigs),
+			architect.WithSandboxAllowlist(cfg.Sandbox.Allowlist),
+		}, opts...)...,
+	)
+	return controller, closeCache, nil
+}
+
+// implementRemoteProvider returns the provider pull requests are opened
+// with, or nil unless --pr is set. Plan-only runs open no pull request.
+func implementRemoteProvider(repoPath string) (remote.Provider, error) {
+	if !implementPR || implementPlanOnly {
+		return nil, nil
+	}
+	return createRemoteProvider(repoPath)
+}
+
+// implementApprovalGates returns the approval gates configured in
+// approval.gates, or nil if none are.
+func implementApprovalGates(cfg *config.Config) *orchestrator.ApprovalGates {
+	p := policyFromConfig(cfg)
+	if len(p.Approval.Gates) == 0 {
+		return nil
+	}
+	return orchestrator.NewApprovalGates(p.Approval)
+}
+
+// implementFeatureTestMap loads the --feature-tests map, or returns nil
+// if it is not set.
+func implementFeatureTestMap() (architect.FeatureTestMap, error) {
+	if implementFeatureTests == "" {
+		return nil, nil
+	}
+	return architect.LoadFeatureTestMap(implementFeatureTests)
+}
+
+// runImplementJSON runs the implement loop without the TUI, streaming
+// NDJSON progress records to stdout and finishing with a result record.
+func runImplementJSON(cfg *config.Config, archDoc, repoPath, projectName string) error {
+	out := newJSONProgressWriter(os.Stdout)
+
+	if implementDryRun {
+		out.Status(jsonStatusDryRun, fmt.Sprintf("Would implement %s in %s with %d agents", archDoc, repoPath, implementAgents))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	controller, closeController, err := newImplementController(cfg, repoPath, projectName, implementApprovalGates(cfg),
+		architect.WithProgressCallback(out.Progress),
+	)
+	if err != nil {
+		out.Result(err)
+		return err
+	}
+	defer closeController()
+
+	// Interrupt notices go to stderr to keep stdout NDJSON
+	stopSignals := handleImplementSignals(controller, cancel, func(msg string) {
+		fmt.Fprintln(os.Stderr, msg)
+	})
+	defer stopSignals()
+
+	err = controller.Run(ctx, archDoc, implementAgents)
+	if err == nil && controller.ExecutionPlan() != nil {
+		out.Plan(controller.ExecutionPlan())
+	}
+	out.Result(err)
+	printImplementCheckpoint(os.Stderr, err, archDoc)
+	return err
+}
+
+// runImplementPlanOnly audits and plans without running agents, then
+// prints the execution plan with its cost estimate.
+func runImplementPlanOnly(cfg *config.Config, archDoc, repoPath, projectName string) error {
+	controller, closeController, err := newImplementController(cfg, repoPath, projectName, implementApprovalGates(cfg),
+		architect.WithProgressCallback(func(event architect.ProgressEvent) {
+			if event.EventType == "" && event.Message != "" {
+				fmt.Println(event.Message)
+			}
+		}),
 	)
+	if err != nil {
+		return err
+	}
+	defer closeController()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopSignals := handleImplementSignals(controller, cancel, func(msg string) {
+		fmt.Println("\n" + msg)
+	})
+	defer stopSignals()
 
-	// Run controller in background goroutine
+	if err := controller.Run(ctx, archDoc, implementAgents); err != nil {
+		return err
+	}
+	plan := controller.ExecutionPlan()
+	if plan == nil {
+		return fmt.Errorf("no execution plan was produced")
+	}
+	fmt.Println()
+	return plan.Format(os.Stdout)
+}
+
+// handleImplementSignals handles SIGINT and SIGTERM for an implement run
+// the way alphie run does: the first signal drains controller, the second
+// calls cancel to stop at once. say reports each signal in the mode's own
+// output. The returned func stops handling them.
+func handleImplementSignals(controller *architect.Controller, cancel context.CancelFunc, say func(string)) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	done := make(chan struct{})
 	go func() {
-		err := controller.Run(ctx, archDoc, implementAgents)
-		program.Send(tui.ImplementDoneMsg{Err: err})
+		select {
+		case <-sigCh:
+		case <-done:
+			return
+		}
+		say(fmt.Sprintf("Received interrupt, letting running agents finish (up to %v); interrupt again to stop now...", implementShutdownGrace))
+		controller.Drain(implementShutdownGrace)
+		select {
+		case <-sigCh:
+		case <-done:
+			return
+		}
+		say("Received interrupt, shutting down...")
+		cancel()
 	}()
-
-	// Run TUI (blocks until quit)
-	if _, err := program.Run(); err != nil {
-		return fmt.Errorf("TUI error: %w", err)
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
 	}
+}
 
-	return nil
+// printImplementCheckpoint prints how to continue an interrupted implement
+// run to w. The merged work stays and the next run's audit plans only the
+// gaps left, so it continues by running implement again rather than with
+// alphie run --epic. Other errors are ignored.
+func printImplementCheckpoint(w io.Writer, err error, archDoc string) {
+	if !errors.Is(err, orchestrator.ErrSessionInterrupted) {
+		return
+	}
+	var interrupted *orchestrator.SessionInterruptedError
+	if errors.As(err, &interrupted) {
+		printSessionProgress(w, interrupted.Checkpoint)
+	} else {
+		fmt.Fprintln(w, "\nImplementation stopped between iterations; no agents were running.")
+	}
+	fmt.Fprintf(w, "Continue with:\n  alphie implement %q\n", archDoc)
 }
 
 // runImplementDryRun shows what would be done without executing.
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestNewImplementController_SharesConfigAcrossModes(t *testing.T) {
+	savedCLI, savedCache, savedPlanOnly := implementUseCLI, implementNoCache, implementPlanOnly
+	defer func() { implementUseCLI, implementNoCache, implementPlanOnly = savedCLI, savedCache, savedPlanOnly }()
+	implementUseCLI, implementNoCache = true, true
+
+	cfg := config.Default()
+	cfg.Merge.DefaultBranch = "develop"
+	cfg.Commit.Name = "Alphie Bot"
+
+	// Plan-only runs used to miss the options the other modes set
+	for _, planOnly := range []bool{false, true} {
+		implementPlanOnly = planOnly
+		controller, closeController, err := newImplementController(cfg, t.TempDir(), "project", nil)
+		if err != nil {
+			t.Fatalf("newImplementController(plan-only=%v) error = %v", planOnly, err)
+		}
+		closeController()
+
+		if controller.PlanOnly != planOnly {
+			t.Errorf("PlanOnly = %v, want %v", controller.PlanOnly, planOnly)
+		}
+		if controller.BaseBranch != "develop" {
+			t.Errorf("plan-only=%v: BaseBranch = %q, want develop", planOnly, controller.BaseBranch)
+		}
+		if controller.CommitIdentity == nil || controller.CommitIdentity.Name != "Alphie Bot" {
+			t.Errorf("plan-only=%v: CommitIdentity = %+v", planOnly, controller.CommitIdentity)
+		}
+	}
+}
+
+func TestPrintImplementCheckpoint(t *testing.T) {
+	session := fmt.Errorf("execute epic (iteration 2): %w", &orchestrator.SessionInterruptedError{
+		Checkpoint: &orchestrator.Checkpoint{SessionID: "s1", EpicID: "ep-1", CompletedTasks: 2, TotalTasks: 3, InterruptedTasks: []string{"Add login"}},
+	})
+	between := &architect.StopError{Reason: architect.StopReasonInterrupted, Iteration: 1}
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"drained session", session, []string{"2/3 tasks complete", "Add login", `alphie implement "spec.md"`}},
+		{"between iterations", between, []string{"no agents were running", `alphie implement "spec.md"`}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		printImplementCheckpoint(&out, tt.err, "spec.md")
+		for _, want := range tt.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s: output %q does not contain %q", tt.name, out.String(), want)
+			}
+		}
+		// The session's epic is not how an implement run continues
+		if strings.Contains(out.String(), "--epic") {
+			t.Errorf("%s: output suggests alphie run --epic: %q", tt.name, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	printImplementCheckpoint(&out, errors.New("audit failed"), "spec.md")
+	if out.Len() != 0 {
+		t.Errorf("expected no output for other errors, got %q", out.String())
+	}
+}
@@ -22,6 +22,8 @@ var (
 	initProjectName    string
 	initWithConfigs    bool
 	initSkipClaudeCheck bool
+	initWizard          bool
+	initYes             bool
 )
 
 var initCmd = &cobra.Command{
@@ -35,6 +37,7 @@ This command sets up everything needed to run Alphie:
   - Creates .alphie directory structure
   - Initializes databases (state.db, learnings.db)
   - Optionally creates example configuration files
+  - Optionally runs a setup wizard that writes .alphie/config.yaml
 
 The directory argument is optional and defaults to the current directory.
 
@@ -43,7 +46,9 @@ Examples:
   alphie init ./myproject  # Initialize specific directory
   alphie init --force      # Reinitialize even if already set up
   alphie init --no-git     # Skip git initialization
-  alphie init --with-configs  # Create example tier config files`,
+  alphie init --with-configs  # Create example tier config files
+  alphie init --wizard     # Profile the repo and write .alphie/config.yaml
+  alphie init --wizard --yes  # Accept every suggested setting`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
@@ -54,6 +59,8 @@ func init() {
 	initCmd.Flags().StringVar(&initProjectName, "project-name", "", "Override auto-detected project name")
 	initCmd.Flags().BoolVar(&initWithConfigs, "with-configs", false, "Create example tier configuration files")
 	initCmd.Flags().BoolVar(&initSkipClaudeCheck, "skip-claude-check", false, "Skip Claude CLI availability check")
+	initCmd.Flags().BoolVar(&initWizard, "wizard", false, "Run the setup wizard to write .alphie/config.yaml")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Accept the wizard's suggested settings without prompting")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -83,6 +90,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Step 2: Check if already initialized
 	stateDBPath := filepath.Join(absPath, ".alphie", "state.db")
 	if _, err := os.Stat(stateDBPath); err == nil && !initForce {
+		if initWizard {
+			return runInitWizard(newWizardPrompter(os.Stdin, os.Stdout, initYes), absPath, false)
+		}
 		fmt.Printf("Directory already initialized. Use --force to reinitialize.\n")
 		return nil
 	}
@@ -173,7 +183,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 		printStatus("✓", "Created .alphie.yaml template", color.FgGreen)
 	}
 
-	// Step 10: Success message
+	// Step 10: Setup wizard (if --wizard)
+	if initWizard {
+		prompter := newWizardPrompter(os.Stdin, os.Stdout, initYes)
+		if err := runInitWizard(prompter, absPath, initForce); err != nil {
+			return err
+		}
+	}
+
+	// Step 11: Success message
 	projectName := initProjectName
 	if projectName == "" {
 		projectName = detectProjectName(absPath)
@@ -287,7 +305,7 @@ func ensureInitialCommit(repoPath string) error {
 	gitignorePath := filepath.Join(repoPath, ".gitignore")
 	if _, err := os.Stat(gitignorePath); os.IsNotExist(err) {
 		// Create minimal .gitignore
-		content := "# Alphie\n.alphie/state.db*\n.alphie/learnings.db*\n.alphie/logs/\nalphie\n"
+		content := "# Alphie\n.alphie/state.db*\n.alphie/learnings.db*\n.alphie/logs/\n.alphie/transcripts/\nalphie\n"
 		if err := os.WriteFile(gitignorePath, []byte(content), 0644); err != nil {
 			return fmt.Errorf("creating .gitignore: %w", err)
 		}
@@ -369,6 +387,7 @@ func updateGitignore(repoPath string) error {
 		".alphie/state.db*",
 		".alphie/learnings.db*",
 		".alphie/logs/",
+		".alphie/transcripts/",
 		"alphie",
 	}
 
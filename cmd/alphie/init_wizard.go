@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/verification"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// repoProfile summarizes what the init wizard learned about a repository.
+type repoProfile struct {
+	// Type is the detected project type ("go", "node", "rust", "python", "unknown").
+	Type string
+	// SourceFiles is the number of tracked-looking source files.
+	SourceFiles int
+	// Commands are the detected build, test and lint commands.
+	Commands config.CommandsConfig
+	// ProtectedAreas are sensitive paths present in the repo that the
+	// built-in protected area defaults do not cover.
+	ProtectedAreas []string
+}
+
+// wizardSkipDirs are directories not counted when profiling a repo.
+var wizardSkipDirs = map[string]bool{
+	".git":         true,
+	".alphie":      true,
+	".worktrees":   true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+}
+
+// wizardProtectedCandidates are paths worth protecting when present.
+// The built-in defaults already cover auth, secrets, migrations and infra.
+var wizardProtectedCandidates = []string{
+	".github/workflows",
+	"deploy",
+	"billing",
+	"payments",
+	"db/schema",
+	"release",
+}
+
+// profileRepo inspects the repository at repoPath.
+func profileRepo(repoPath string) *repoProfile {
+	ctx := verification.DetectProjectContext(repoPath)
+	profile := &repoProfile{
+		Type: ctx.Type,
+		Commands: config.CommandsConfig{
+			Build: strings.Join(ctx.BuildCommand, " "),
+			Test:  strings.Join(ctx.TestCommand, " "),
+			Lint:  strings.Join(ctx.LintCommand, " "),
+		},
+	}
+
+	_ = filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != repoPath && (wizardSkipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") && d.Name() != ".github") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isSourceFile(d.Name()) {
+			profile.SourceFiles++
+		}
+		return nil
+	})
+
+	for _, candidate := range wizardProtectedCandidates {
+		if dirExists(filepath.Join(repoPath, candidate)) {
+			profile.ProtectedAreas = append(profile.ProtectedAreas, candidate+"/**")
+		}
+	}
+
+	return profile
+}
+
+// isSourceFile reports whether name looks like a source file.
+func isSourceFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".go", ".ts", ".tsx", ".js", ".jsx", ".py", ".rs", ".java", ".kt", ".rb", ".c", ".cc", ".cpp", ".h", ".cs", ".swift":
+		return true
+	}
+	return false
+}
+
+// dirExists reports whether path is an existing directory.
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// proposeConfig builds the wizard's suggested configuration for a profile.
+// Larger codebases get a higher tier and larger budgets.
+func proposeConfig(profile *repoProfile) *config.Config {
+	cfg := config.Default()
+
+	switch {
+	case profile.SourceFiles < 50:
+		cfg.Defaults.Tier = string(models.TierScout)
+		cfg.Budget.SessionLimit = 5
+	case profile.SourceFiles < 500:
+		cfg.Defaults.Tier = string(models.TierBuilder)
+		cfg.Budget.SessionLimit = 20
+	default:
+		cfg.Defaults.Tier = string(models.TierArchitect)
+		cfg.Budget.SessionLimit = 50
+	}
+	cfg.Budget.TaskLimit = cfg.Budget.SessionLimit / 4
+
+	cfg.ProtectedAreas.Patterns = append([]string(nil), profile.ProtectedAreas...)
+	cfg.Commands = profile.Commands
+
+	return cfg
+}
+
+// wizardPrompter asks questions on in/out. With assumeYes set it accepts
+// every default without reading input.
+type wizardPrompter struct {
+	in        *bufio.Reader
+	out       io.Writer
+	assumeYes bool
+}
+
+// newWizardPrompter creates a prompter reading answers from in.
+func newWizardPrompter(in io.Reader, out io.Writer, assumeYes bool) *wizardPrompter {
+	return &wizardPrompter{in: bufio.NewReader(in), out: out, assumeYes: assumeYes}
+}
+
+// ask prompts for a string. An empty answer (or EOF) keeps def.
+func (p *wizardPrompter) ask(label, def string) string {
+	if p.assumeYes {
+		fmt.Fprintf(p.out, "  %s: %s\n", label, def)
+		return def
+	}
+	fmt.Fprintf(p.out, "  %s [%s]: ", label, def)
+	line, err := p.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		if err != nil {
+			fmt.Fprintln(p.out)
+		}
+		return def
+	}
+	return line
+}
+
+// askChoice prompts until the answer is one of choices.
+func (p *wizardPrompter) askChoice(label, def string, choices []string) string {
+	for {
+		answer := p.ask(fmt.Sprintf("%s (%s)", label, strings.Join(choices, "/")), def)
+		for _, c := range choices {
+			if answer == c {
+				return answer
+			}
+		}
+		fmt.Fprintf(p.out, "  %q is not one of %s\n", answer, strings.Join(choices, ", "))
+	}
+}
+
+// askFloat prompts until the answer is a non-negative number.
+func (p *wizardPrompter) askFloat(label string, def float64) float64 {
+	for {
+		answer := p.ask(label, strconv.FormatFloat(def, 'f', -1, 64))
+		v, err := strconv.ParseFloat(strings.TrimPrefix(answer, "$"), 64)
+		if err == nil && v >= 0 {
+			return v
+		}
+		fmt.Fprintf(p.out, "  %q is not a non-negative number\n", answer)
+	}
+}
+
+// askList prompts for a comma-separated list. "none" clears it.
+func (p *wizardPrompter) askList(label string, def []string) []string {
+	defStr := strings.Join(def, ", ")
+	if defStr == "" {
+		defStr = "none"
+	}
+	answer := p.ask(label, defStr)
+	if answer == "none" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(answer, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// askCommand prompts for a shell command. "none" clears it.
+func (p *wizardPrompter) askCommand(label, def string) string {
+	if def == "" {
+		def = "none"
+	}
+	answer := p.ask(label, def)
+	if answer == "none" {
+		return ""
+	}
+	return answer
+}
+
+// runInitWizard profiles the repo, walks the user through the proposed
+// settings, writes them to .alphie/config.yaml, and validates the written
+// file with the preflight checker.
+func runInitWizard(p *wizardPrompter, repoPath string, force bool) error {
+	configPath := filepath.Join(repoPath, config.ProjectConfigFile)
+	if _, err := os.Stat(configPath); err == nil && !force {
+		printStatus("✓", fmt.Sprintf("Project config exists (%s), skipping wizard. Use --force to rerun it.", config.ProjectConfigFile), color.FgGreen)
+		return nil
+	}
+
+	profile := profileRepo(repoPath)
+	fmt.Fprintf(p.out, "\nProject profile: %s, %d source files\n", profile.Type, profile.SourceFiles)
+	fmt.Fprintln(p.out, "Press Enter to accept a suggestion, or type a new value.")
+
+	cfg := proposeConfig(profile)
+
+	fmt.Fprintln(p.out, "\nTier")
+	cfg.Defaults.Tier = p.askChoice("Default tier", cfg.Defaults.Tier,
+		[]string{string(models.TierQuick), string(models.TierScout), string(models.TierBuilder), string(models.TierArchitect)})
+
+	fmt.Fprintln(p.out, "\nBudgets (dollars, 0 = unlimited)")
+	cfg.Budget.SessionLimit = p.askFloat("Session limit", cfg.Budget.SessionLimit)
+	cfg.Budget.TaskLimit = p.askFloat("Per-task limit", cfg.Budget.TaskLimit)
+
+	fmt.Fprintln(p.out, "\nProtected areas (in addition to auth, secrets, migrations, infra, ...)")
+	cfg.ProtectedAreas.Patterns = p.askList("Extra patterns", cfg.ProtectedAreas.Patterns)
+
+	fmt.Fprintln(p.out, "\nMerge policy")
+	cfg.Merge.OversizeConflictAction = p.askChoice("Large conflicts go to", cfg.Merge.OversizeConflictAction,
+		[]string{"human", "reexecute"})
+
+	fmt.Fprintln(p.out, "\nCommands")
+	cfg.Commands.Build = p.askCommand("Build", cfg.Commands.Build)
+	cfg.Commands.Test = p.askCommand("Test", cfg.Commands.Test)
+	cfg.Commands.Lint = p.askCommand("Lint", cfg.Commands.Lint)
+	cfg.QualityGates.Build = cfg.Commands.Build != ""
+	cfg.QualityGates.Test = cfg.Commands.Test != ""
+	cfg.QualityGates.Lint = cfg.Commands.Lint != ""
+
+	if err := config.SaveProject(cfg, configPath); err != nil {
+		return fmt.Errorf("writing %s: %w", config.ProjectConfigFile, err)
+	}
+	fmt.Fprintln(p.out)
+	printStatus("✓", fmt.Sprintf("Wrote %s", config.ProjectConfigFile), color.FgGreen)
+
+	// Validate what was actually written, not the in-memory copy.
+	written, err := config.LoadFromPath(configPath)
+	if err != nil {
+		return fmt.Errorf("reloading %s: %w", config.ProjectConfigFile, err)
+	}
+	report := config.Preflight(written)
+	for _, check := range report.Checks {
+		switch check.Status {
+		case config.PreflightOK:
+			printStatus("✓", fmt.Sprintf("%s: %s", check.Name, check.Message), color.FgGreen)
+		case config.PreflightWarn:
+			printStatus("⚠", fmt.Sprintf("%s: %s", check.Name, check.Message), color.FgYellow)
+		default:
+			printStatus("✗", fmt.Sprintf("%s: %s", check.Name, check.Message), color.FgRed)
+		}
+	}
+	if !report.OK() {
+		return fmt.Errorf("%s failed preflight checks; edit it and rerun 'alphie init --wizard --force'", config.ProjectConfigFile)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+)
+
+func writeWizardRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                    "module example.com/demo\n\ngo 1.24\n",
+		"main.go":                   "package main\n\nfunc main() {}\n",
+		"deploy/app.yaml":           "replicas: 1\n",
+		"node_modules/x/index.js":   "ignored\n",
+		".github/workflows/ci.yaml": "on: push\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestProfileRepo(t *testing.T) {
+	profile := profileRepo(writeWizardRepo(t))
+
+	if profile.Type != "go" {
+		t.Errorf("Type = %q, want go", profile.Type)
+	}
+	if profile.SourceFiles != 1 {
+		t.Errorf("SourceFiles = %d, want 1 (node_modules skipped)", profile.SourceFiles)
+	}
+	if profile.Commands.Test != "go test ./..." {
+		t.Errorf("Commands.Test = %q", profile.Commands.Test)
+	}
+	want := map[string]bool{".github/workflows/**": true, "deploy/**": true}
+	if len(profile.ProtectedAreas) != len(want) {
+		t.Fatalf("ProtectedAreas = %v", profile.ProtectedAreas)
+	}
+	for _, p := range profile.ProtectedAreas {
+		if !want[p] {
+			t.Errorf("unexpected protected area %q", p)
+		}
+	}
+
+	cfg := proposeConfig(profile)
+	if cfg.Defaults.Tier != "scout" {
+		t.Errorf("proposed tier = %q, want scout for a tiny repo", cfg.Defaults.Tier)
+	}
+	if cfg.Budget.TaskLimit <= 0 || cfg.Budget.TaskLimit > cfg.Budget.SessionLimit {
+		t.Errorf("proposed budget = %+v", cfg.Budget)
+	}
+}
+
+func TestRunInitWizard_Answers(t *testing.T) {
+	dir := writeWizardRepo(t)
+
+	// Tier (invalid, then valid), session, task, protected areas, merge action, build, test, lint.
+	answers := strings.Join([]string{
+		"huge",
+		"builder",
+		"12",
+		"3",
+		"deploy/**, billing/**",
+		"reexecute",
+		"",
+		"",
+		"none",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	prompter := newWizardPrompter(strings.NewReader(answers), &out, false)
+
+	if err := runInitWizard(prompter, dir, false); err != nil {
+		t.Fatalf("runInitWizard: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), `"huge" is not one of`) {
+		t.Errorf("expected invalid tier to be rejected, output:\n%s", out.String())
+	}
+
+	cfg, err := config.LoadFromPath(filepath.Join(dir, config.ProjectConfigFile))
+	if err != nil {
+		t.Fatalf("LoadFromPath: %v", err)
+	}
+	if cfg.Defaults.Tier != "builder" {
+		t.Errorf("tier = %q, want builder", cfg.Defaults.Tier)
+	}
+	if cfg.Budget.SessionLimit != 12 || cfg.Budget.TaskLimit != 3 {
+		t.Errorf("budget = %+v", cfg.Budget)
+	}
+	if len(cfg.ProtectedAreas.Patterns) != 2 || cfg.ProtectedAreas.Patterns[1] != "billing/**" {
+		t.Errorf("protected patterns = %v", cfg.ProtectedAreas.Patterns)
+	}
+	if cfg.Merge.OversizeConflictAction != "reexecute" {
+		t.Errorf("oversize action = %q", cfg.Merge.OversizeConflictAction)
+	}
+	if cfg.Commands.Build != "go build ./..." || cfg.Commands.Lint != "" || cfg.QualityGates.Lint {
+		t.Errorf("commands = %+v, lint gate = %v", cfg.Commands, cfg.QualityGates.Lint)
+	}
+}
+
+func TestRunInitWizard_KeepsExistingConfig(t *testing.T) {
+	dir := writeWizardRepo(t)
+	path := filepath.Join(dir, config.ProjectConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("defaults:\n  tier: architect\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runInitWizard(newWizardPrompter(strings.NewReader(""), &out, true), dir, false); err != nil {
+		t.Fatalf("runInitWizard: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "architect") {
+		t.Errorf("existing config was overwritten:\n%s", data)
+	}
+}
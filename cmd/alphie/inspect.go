@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+var (
+	inspectAt       string
+	inspectTimeline bool
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [session-id]",
+	Short: "Show the dependency graph of a past session at any point in time",
+	Long: `Reconstruct a session's dependency graph from its event log
+(.alphie/events/<session-id>.jsonl) as it stood at a given moment:
+which tasks were running, ready, waiting on dependencies, or blocked.
+
+Use it for post-mortems: why tasks ran in the order they did, why a task
+sat ready without being scheduled, or where a session deadlocked.
+
+--at accepts an RFC3339 timestamp or an offset from the start of the
+session such as 90s or 5m. Without --at the end of the log is shown.
+Without a session ID the most recent session is used.
+
+Examples:
+  alphie inspect                       # Final state of the latest session
+  alphie inspect abc123 --at 5m        # Five minutes into session abc123
+  alphie inspect abc123 --timeline     # List events with their offsets`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().StringVar(&inspectAt, "at", "", "Point in time: RFC3339 timestamp or offset from session start (e.g. 5m)")
+	inspectCmd.Flags().BoolVar(&inspectTimeline, "timeline", false, "List the session's events instead of a graph snapshot")
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	var path string
+	if len(args) == 1 {
+		path = orchestrator.EventLogPath(repoPath, args[0])
+	} else {
+		path, err = latestEventLog(repoPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	records, err := orchestrator.ReadEventLog(path)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("event log %s is empty", path)
+	}
+	start := records[0].Timestamp
+
+	if inspectTimeline {
+		for _, rec := range records {
+			line := fmt.Sprintf("+%-8s %-24s %s", rec.Timestamp.Sub(start).Truncate(time.Second), rec.Type, rec.TaskID)
+			if rec.Message != "" {
+				line += "  " + rec.Message
+			}
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+		return nil
+	}
+
+	at := records[len(records)-1].Timestamp
+	if inspectAt != "" {
+		at, err = parseInspectTime(inspectAt, start)
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshot, err := orchestrator.GraphAt(records, at)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Session %s\n", strings.TrimSuffix(filepath.Base(path), ".jsonl"))
+	return snapshot.Render(os.Stdout)
+}
+
+// parseInspectTime parses an RFC3339 timestamp or an offset from start.
+func parseInspectTime(value string, start time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	offset, err := time.ParseDuration(strings.TrimPrefix(value, "+"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --at %q: use an RFC3339 timestamp or an offset like 5m", value)
+	}
+	return start.Add(offset), nil
+}
+
+// latestEventLog returns the most recently written event log in the repo.
+func latestEventLog(repoPath string) (string, error) {
+	dir := orchestrator.EventLogDir(repoPath)
+	matches, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil || len(matches) == 0 {
+		return "", fmt.Errorf("no session event logs in %s", dir)
+	}
+	modTime := func(p string) time.Time {
+		info, err := os.Stat(p)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+	sort.Slice(matches, func(i, j int) bool { return modTime(matches[i]).After(modTime(matches[j])) })
+	return matches[0], nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/config"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/internal/prog"
 	"github.com/ShayCichocki/alphie/internal/state"
@@ -91,11 +92,14 @@ func runInteractive() error {
 		return fmt.Errorf("create runner factory: %w", err)
 	}
 
+	identity := commitIdentity(nil)
+
 	// Create executor (use sonnet as default model)
 	executor, err := agent.NewExecutor(agent.ExecutorConfig{
-		RepoPath:      repoPath,
-		Model:         "sonnet",
-		RunnerFactory: runnerFactory,
+		RepoPath:       repoPath,
+		Model:          "sonnet",
+		RunnerFactory:  runnerFactory,
+		CommitIdentity: identity,
 	})
 	if err != nil {
 		return fmt.Errorf("create executor: %w", err)
@@ -106,6 +110,8 @@ func runInteractive() error {
 		RepoPath:       repoPath,
 		TierConfigs:    tierConfigs,
 		Greenfield:     interactiveGreenfield,
+		MainBranch:     baseBranch("", nil),
+		CommitIdentity: identity,
 		Executor:       executor,
 		StateDB:        stateDB,
 		LearningSystem: learningSys,
@@ -133,12 +139,14 @@ func runInteractive() error {
 	originalOutput := log.Writer()
 	log.SetOutput(io.Discard)
 	defer log.SetOutput(originalOutput)
+	defer logging.SetOutput(io.Discard)()
 
 	// Create TUI program
 	program, app := tui.NewInteractiveProgram()
 
 	// Create quick executor for !quick tasks
 	quickExec := orchestrator.NewQuickExecutor(repoPath, runnerFactory)
+	quickExec.SetCommitIdentity(identity)
 
 	// Track active tasks to know when ALL are done
 	var activeTaskCount int32
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// JSON record types emitted in --json mode.
+const (
+	// jsonRecordProgress is a phase or status update.
+	jsonRecordProgress = "progress"
+	// jsonRecordTask is a task lifecycle event (started, completed, failed, budget).
+	jsonRecordTask = "task"
+	// jsonRecordResult is the final record of a run.
+	jsonRecordResult = "result"
+	// jsonRecordPlan is the execution plan of a --plan-only run.
+	jsonRecordPlan = "plan"
+	// jsonRecordEstimate is the cost estimate of an iteration's tasks before they run.
+	jsonRecordEstimate = "estimate"
+	// jsonRecordCost is the running cost and token usage of every runner,
+	// written about once a second while it grows.
+	jsonRecordCost = "cost"
+)
+
+// Final result statuses.
+const (
+	jsonStatusSuccess = "success"
+	jsonStatusFailed  = "failed"
+	jsonStatusDryRun  = "dry_run"
+)
+
+// jsonPlanTask is one task of a "plan" or "estimate" record.
+type jsonPlanTask struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Status        string   `json:"status,omitempty"`
+	DependsOn     []string `json:"depends_on,omitempty"`
+	InputTokens   int64    `json:"input_tokens"`
+	OutputTokens  int64    `json:"output_tokens"`
+	EstimatedCost float64  `json:"estimated_cost"`
+	// Estimate fields, set on "estimate" records only.
+	Tier     string  `json:"tier,omitempty"`
+	Model    string  `json:"model,omitempty"`
+	CostLow  float64 `json:"cost_low,omitempty"`
+	CostHigh float64 `json:"cost_high,omitempty"`
+	Samples  int     `json:"samples,omitempty"`
+}
+
+// jsonRecord is a single NDJSON line written in --json mode.
+// Every record carries its type and timestamp; other fields are omitted when empty.
+type jsonRecord struct {
+	Type             string    `json:"type"`
+	Timestamp        time.Time `json:"timestamp"`
+	Phase            string    `json:"phase,omitempty"`
+	Iteration        int       `json:"iteration,omitempty"`
+	MaxIterations    int       `json:"max_iterations,omitempty"`
+	FeaturesComplete int       `json:"features_complete"`
+	FeaturesTotal    int       `json:"features_total"`
+	Cost             float64   `json:"cost"`
+	CostBudget       float64   `json:"cost_budget,omitempty"`
+	TokensUsed       int64     `json:"tokens_used,omitempty"`
+	WorkersRunning   int       `json:"workers_running,omitempty"`
+	WorkersBlocked   int       `json:"workers_blocked,omitempty"`
+	Event            string    `json:"event,omitempty"`
+	TaskID           string    `json:"task_id,omitempty"`
+	TaskTitle        string    `json:"task_title,omitempty"`
+	Message          string    `json:"message,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	// Plan fields, set on "plan" and "estimate" records only.
+	Tasks         []jsonPlanTask `json:"tasks,omitempty"`
+	EstimatedCost float64        `json:"estimated_cost,omitempty"`
+	// Confidence band of the estimated cost, set on "estimate" records only.
+	EstimatedCostLow  float64 `json:"estimated_cost_low,omitempty"`
+	EstimatedCostHigh float64 `json:"estimated_cost_high,omitempty"`
+}
+
+// jsonProgressWriter streams architect progress as NDJSON records.
+// It is safe for concurrent use and remembers the latest progress so the
+// final result record can report totals.
+type jsonProgressWriter struct {
+	enc  *json.Encoder
+	last architect.ProgressEvent
+	mu   sync.Mutex
+}
+
+// newJSONProgressWriter creates a writer that emits records to w.
+func newJSONProgressWriter(w io.Writer) *jsonProgressWriter {
+	return &jsonProgressWriter{enc: json.NewEncoder(w)}
+}
+
+// Progress writes a record for a controller progress event.
+// Cost estimates are written as "estimate" records, cost ticks as "cost"
+// records, other task-level events as "task" records and everything else
+// as "progress".
+func (j *jsonProgressWriter) Progress(event architect.ProgressEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Cost ticks carry no progress, so the totals keep the last update's
+	if event.EventType == string(orchestrator.EventCostTick) {
+		j.last.Cost = event.Cost
+		_ = j.enc.Encode(jsonRecord{
+			Type:       jsonRecordCost,
+			Timestamp:  event.Timestamp,
+			Cost:       event.Cost,
+			CostBudget: event.CostBudget,
+			TokensUsed: event.TokensUsed,
+		})
+		return
+	}
+	j.last = event
+
+	rec := recordFromProgress(event)
+	rec.Type = jsonRecordProgress
+	switch {
+	case event.Estimate != nil:
+		rec.Type = jsonRecordEstimate
+		rec.EstimatedCost = event.Estimate.Cost
+		rec.EstimatedCostLow = event.Estimate.CostLow
+		rec.EstimatedCostHigh = event.Estimate.CostHigh
+		rec.Tasks = make([]jsonPlanTask, 0, len(event.Estimate.Tasks))
+		for _, t := range event.Estimate.Tasks {
+			rec.Tasks = append(rec.Tasks, jsonPlanTask{
+				ID:            t.TaskID,
+				Title:         t.TaskTitle,
+				InputTokens:   t.InputTokens,
+				OutputTokens:  t.OutputTokens,
+				EstimatedCost: t.Cost,
+				Tier:          string(t.Tier),
+				Model:         t.Model,
+				CostLow:       t.CostLow,
+				CostHigh:      t.CostHigh,
+				Samples:       t.Samples,
+			})
+		}
+	case event.EventType != "":
+		rec.Type = jsonRecordTask
+	}
+	_ = j.enc.Encode(rec)
+}
+
+// Result writes the final record of the run. A nil err reports success.
+func (j *jsonProgressWriter) Result(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	rec := recordFromProgress(j.last)
+	rec.Type = jsonRecordResult
+	rec.Timestamp = time.Now()
+	rec.Event = ""
+	rec.TaskID = ""
+	rec.TaskTitle = ""
+	rec.Message = ""
+	rec.Status = jsonStatusSuccess
+	if err != nil {
+		rec.Status = jsonStatusFailed
+		rec.Error = err.Error()
+	}
+	_ = j.enc.Encode(rec)
+}
+
+// Status writes a final record with an explicit status and message.
+func (j *jsonProgressWriter) Status(status, message string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.enc.Encode(jsonRecord{
+		Type:      jsonRecordResult,
+		Timestamp: time.Now(),
+		Status:    status,
+		Message:   message,
+	})
+}
+
+// Plan writes the execution plan of a plan-only run.
+func (j *jsonProgressWriter) Plan(plan *architect.ExecutionPlan) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	rec := jsonRecord{
+		Type:             jsonRecordPlan,
+		Timestamp:        time.Now(),
+		FeaturesComplete: plan.FeaturesComplete,
+		FeaturesTotal:    plan.FeaturesTotal,
+		Cost:             plan.PlanningCost,
+		Message:          plan.EpicTitle,
+		EstimatedCost:    plan.TotalCost(),
+		Tasks:            make([]jsonPlanTask, 0, len(plan.Tasks)),
+	}
+	for _, t := range plan.Tasks {
+		rec.Tasks = append(rec.Tasks, jsonPlanTask{
+			ID:            t.Task.Key,
+			Title:         t.Task.Title,
+			Status:        string(t.Gap.Status),
+			DependsOn:     t.Task.DependsOn,
+			InputTokens:   t.InputTokens,
+			OutputTokens:  t.OutputTokens,
+			EstimatedCost: t.Cost,
+		})
+	}
+	_ = j.enc.Encode(rec)
+}
+
+// recordFromProgress copies the shared fields of a progress event into a record.
+func recordFromProgress(event architect.ProgressEvent) jsonRecord {
+	return jsonRecord{
+		Timestamp:        event.Timestamp,
+		Phase:            string(event.Phase),
+		Iteration:        event.Iteration,
+		MaxIterations:    event.MaxIterations,
+		FeaturesComplete: event.FeaturesComplete,
+		FeaturesTotal:    event.FeaturesTotal,
+		Cost:             event.Cost,
+		CostBudget:       event.CostBudget,
+		WorkersRunning:   event.WorkersRunning,
+		WorkersBlocked:   event.WorkersBlocked,
+		Event:            event.EventType,
+		TaskID:           event.TaskID,
+		TaskTitle:        event.TaskTitle,
+		Message:          event.Message,
+	}
+}
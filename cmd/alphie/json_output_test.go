@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []jsonRecord {
+	t.Helper()
+	var records []jsonRecord
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var rec jsonRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestJSONProgressWriter_RecordTypes(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJSONProgressWriter(&buf)
+
+	out.Progress(architect.ProgressEvent{
+		Phase:         architect.PhaseAuditing,
+		Iteration:     1,
+		MaxIterations: 5,
+		Message:       "Auditing codebase",
+		Timestamp:     time.Now(),
+	})
+	out.Progress(architect.ProgressEvent{
+		Phase:            architect.PhaseExecuting,
+		Iteration:        1,
+		MaxIterations:    5,
+		FeaturesComplete: 2,
+		FeaturesTotal:    4,
+		Cost:             0.42,
+		EventType:        "task_completed",
+		TaskID:           "task-1",
+		TaskTitle:        "Add login",
+		Message:          "Completed: Add login",
+		Timestamp:        time.Now(),
+	})
+	out.Result(nil)
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	if records[0].Type != jsonRecordProgress || records[0].Phase != "auditing" {
+		t.Errorf("expected progress record for auditing, got %+v", records[0])
+	}
+	if records[1].Type != jsonRecordTask || records[1].TaskID != "task-1" || records[1].Event != "task_completed" {
+		t.Errorf("expected task record for task-1, got %+v", records[1])
+	}
+
+	result := records[2]
+	if result.Type != jsonRecordResult || result.Status != jsonStatusSuccess {
+		t.Errorf("expected success result, got %+v", result)
+	}
+	if result.Cost != 0.42 || result.FeaturesComplete != 2 || result.FeaturesTotal != 4 {
+		t.Errorf("expected result to carry final totals, got %+v", result)
+	}
+	if result.TaskID != "" || result.Message != "" {
+		t.Errorf("expected result not to carry task fields, got %+v", result)
+	}
+}
+
+func TestJSONProgressWriter_CostTick(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJSONProgressWriter(&buf)
+
+	out.Progress(architect.ProgressEvent{
+		Phase:            architect.PhaseExecuting,
+		FeaturesComplete: 1,
+		FeaturesTotal:    3,
+		Cost:             0.10,
+		Timestamp:        time.Now(),
+	})
+	out.Progress(architect.ProgressEvent{
+		EventType:  string(orchestrator.EventCostTick),
+		Cost:       0.25,
+		TokensUsed: 12000,
+		Timestamp:  time.Now(),
+	})
+	out.Result(nil)
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if tick := records[1]; tick.Type != jsonRecordCost || tick.Cost != 0.25 || tick.TokensUsed != 12000 {
+		t.Errorf("expected cost record, got %+v", tick)
+	}
+	// The result keeps the last progress and the latest cost
+	if result := records[2]; result.Cost != 0.25 || result.FeaturesComplete != 1 || result.FeaturesTotal != 3 {
+		t.Errorf("expected result to carry the ticked cost and last totals, got %+v", result)
+	}
+}
+
+func TestJSONProgressWriter_FailedResult(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJSONProgressWriter(&buf)
+
+	out.Result(errors.New("budget exceeded"))
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Status != jsonStatusFailed || records[0].Error != "budget exceeded" {
+		t.Errorf("expected failed result with error, got %+v", records[0])
+	}
+}
+
+func TestJSONProgressWriter_Plan(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJSONProgressWriter(&buf)
+
+	plan := architect.NewExecutionPlan(
+		&architect.ArchSpec{Features: []architect.Feature{{ID: "db"}}},
+		&architect.GapReport{},
+		&architect.TaskPlan{
+			EpicTitle: "Implement architecture",
+			Tasks:     []orchestrator.PlannedTask{{Key: "db", Title: "Implement db", DependsOn: []string{"base"}}},
+			Gaps:      []architect.Gap{{FeatureID: "db", Status: architect.AuditStatusMissing}},
+		},
+	)
+	out.Plan(plan)
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 || records[0].Type != jsonRecordPlan {
+		t.Fatalf("records = %+v", records)
+	}
+	rec := records[0]
+	if len(rec.Tasks) != 1 || rec.Tasks[0].ID != "db" || rec.Tasks[0].Status != "MISSING" || rec.Tasks[0].DependsOn[0] != "base" {
+		t.Errorf("tasks = %+v", rec.Tasks)
+	}
+	if rec.EstimatedCost <= 0 || rec.EstimatedCost != rec.Tasks[0].EstimatedCost {
+		t.Errorf("estimated cost = %f", rec.EstimatedCost)
+	}
+}
+
+func TestJSONProgressWriter_Estimate(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJSONProgressWriter(&buf)
+
+	estimate := orchestrator.NewTaskEstimator(nil).EstimateAll([]*models.Task{
+		{ID: "task-1", Title: "Add login", Tier: models.TierBuilder},
+	})
+	out.Progress(architect.ProgressEvent{
+		Phase:     architect.PhaseExecuting,
+		EventType: string(orchestrator.EventCostEstimate),
+		Message:   estimate.Summary(),
+		Estimate:  estimate,
+		Timestamp: time.Now(),
+	})
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 || records[0].Type != jsonRecordEstimate {
+		t.Fatalf("records = %+v", records)
+	}
+	rec := records[0]
+	if rec.EstimatedCost != estimate.Cost || rec.EstimatedCostLow >= rec.EstimatedCost || rec.EstimatedCostHigh <= rec.EstimatedCost {
+		t.Errorf("estimate = %f (%f-%f)", rec.EstimatedCost, rec.EstimatedCostLow, rec.EstimatedCostHigh)
+	}
+	if len(rec.Tasks) != 1 || rec.Tasks[0].ID != "task-1" || rec.Tasks[0].Tier != "builder" || rec.Tasks[0].CostHigh <= rec.Tasks[0].CostLow {
+		t.Errorf("tasks = %+v", rec.Tasks)
+	}
+}
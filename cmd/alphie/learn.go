@@ -18,7 +18,7 @@ var (
 )
 
 var learnCmd = &cobra.Command{
-	Use:   "learn [CAO triple | show <id>]",
+	Use:   "learn [CAO triple | show <id> | export [file] | import <file>]",
 	Short: "Manage learnings in the CAO format",
 	Long: `Manage learnings stored as CAO (Condition-Action-Outcome) triples.
 
@@ -29,14 +29,23 @@ Usage:
   alphie learn                           # List recent learnings
   alphie learn "WHEN X DO Y RESULT Z"    # Add a new learning
   alphie learn --search "query"          # Search learnings
-  alphie learn --concept build           # List by concept
+  alphie learn --concept build           # List by concept (with its aliases and sub-concepts)
+  alphie learn concepts                  # Manage the concept taxonomy
   alphie learn show <id>                 # Show learning details
   alphie learn --delete <id>             # Delete a learning
+  alphie learn export [file]             # Export all learnings as JSONL (stdout if no file)
+  alphie learn import <file>             # Import JSONL, merging duplicates
+
+Export/import lets a team share learnings across machines and CI runners.
+Imported learnings with the same condition and action as an existing one
+are merged into it rather than duplicated, so re-importing is safe.
 
 Examples:
   alphie learn "WHEN tests fail with timeout DO increase test timeout RESULT tests pass"
   alphie learn --search "timeout"
-  alphie learn show lr-abc123`,
+  alphie learn show lr-abc123
+  alphie learn export team-learnings.jsonl
+  alphie learn import team-learnings.jsonl`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: runLearn,
 }
@@ -83,6 +92,21 @@ func runLearn(cmd *cobra.Command, args []string) error {
 		return showLearning(store, args[1])
 	}
 
+	// Handle subcommands: export [file], import <file>
+	if len(args) >= 1 && args[0] == "export" {
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		return exportLearnings(store, path)
+	}
+	if len(args) >= 1 && args[0] == "import" {
+		if len(args) < 2 {
+			return fmt.Errorf("usage: alphie learn import <file>")
+		}
+		return importLearnings(store, args[1])
+	}
+
 	// Handle positional arg: add a new learning
 	if len(args) == 1 {
 		return addLearning(store, args[0])
@@ -138,11 +162,14 @@ func searchLearnings(store *learning.LearningStore, query string) error {
 	return nil
 }
 
-// listByConcept lists learnings tagged with a specific concept
+// listByConcept lists learnings about a concept, its aliases or any of its
+// sub-concepts
 func listByConcept(store *learning.LearningStore, concept string) error {
-	// For now, search by concept name as a keyword
-	// Full concept support would require querying the learning_concepts table
-	results, err := store.Search(concept)
+	terms, err := learning.NewConceptRegistry(store).Expand([]string{concept})
+	if err != nil {
+		return fmt.Errorf("expand concept: %w", err)
+	}
+	results, err := store.Search(learning.FTSQuery(terms))
 	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
 	}
@@ -214,6 +241,50 @@ func deleteLearning(store *learning.LearningStore, id string) error {
 	return nil
 }
 
+// exportLearnings writes all learnings as JSONL to path, or stdout if path is empty or "-"
+func exportLearnings(store *learning.LearningStore, path string) error {
+	if path == "" || path == "-" {
+		_, err := store.Export(os.Stdout)
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	n, err := store.Export(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	fmt.Printf("Exported %d learning(s) to %s\n", n, path)
+	return nil
+}
+
+// importLearnings merges learnings from a JSONL file, or stdin if path is "-"
+func importLearnings(store *learning.LearningStore, path string) error {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	res, err := store.Import(in)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	fmt.Printf("Imported learnings: %d added, %d merged, %d skipped\n", res.Added, res.Merged, res.Skipped)
+	return nil
+}
+
 // printLearning prints a learning in the standard format
 func printLearning(lr *learning.Learning) {
 	fmt.Printf("[%s] WHEN: %s\n", lr.ID, lr.Condition)
@@ -256,6 +327,3 @@ func truncate(s string, max int) string {
 	}
 	return s[:max-3] + "..."
 }
-
-// Ensure we have os imported for potential future use
-var _ = os.Exit
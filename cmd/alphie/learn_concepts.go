@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/learning"
+)
+
+var learnConceptsSummary string
+
+var learnConceptsCmd = &cobra.Command{
+	Use:   "concepts [list | add <name> | alias <name> <alias> | unalias <alias> | parent <name> [parent] | remove <name>]",
+	Short: "Manage the concept taxonomy learnings are tagged with",
+	Long: `Manage the concepts learnings are tagged with and retrieved by.
+
+Learnings are tagged with the concepts their task mentions, by name or
+alias. Concepts form a hierarchy: retrieving learnings for a concept also
+retrieves those of its sub-concepts, so a task about security finds
+learnings about auth once auth is a sub-concept of security.
+
+Alphie starts with built-in concepts (testing, bug-fix, security, ...).
+They can be given aliases and parents but not removed.
+
+Commands:
+  alphie learn concepts                         # Show the taxonomy
+  alphie learn concepts add auth --summary "Authentication and sessions"
+  alphie learn concepts alias auth login        # "login" now means auth
+  alphie learn concepts unalias login
+  alphie learn concepts parent auth security    # auth is a kind of security
+  alphie learn concepts parent auth             # Make auth a root concept
+  alphie learn concepts remove auth             # Sub-concepts move up a level`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runLearnConcepts,
+}
+
+func init() {
+	learnConceptsCmd.Flags().StringVar(&learnConceptsSummary, "summary", "", "Description of the concept (add)")
+	learnCmd.AddCommand(learnConceptsCmd)
+}
+
+func runLearnConcepts(cmd *cobra.Command, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	switch {
+	case subcommand == "add" && len(args) != 2:
+		return fmt.Errorf("usage: alphie learn concepts add <name> [--summary text]")
+	case subcommand == "alias" && len(args) != 3:
+		return fmt.Errorf("usage: alphie learn concepts alias <name> <alias>")
+	case subcommand == "unalias" && len(args) != 2:
+		return fmt.Errorf("usage: alphie learn concepts unalias <alias>")
+	case subcommand == "parent" && len(args) != 2 && len(args) != 3:
+		return fmt.Errorf("usage: alphie learn concepts parent <name> [parent]")
+	case subcommand == "remove" && len(args) != 2:
+		return fmt.Errorf("usage: alphie learn concepts remove <name>")
+	}
+
+	store, err := learning.NewLearningStore(learning.GlobalDBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open learning store: %w", err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate learning store: %w", err)
+	}
+	registry := learning.NewConceptRegistry(store)
+
+	switch subcommand {
+	case "list":
+		return printTaxonomy(registry)
+	case "add":
+		if err := registry.Define(args[1], learnConceptsSummary); err != nil {
+			return err
+		}
+		fmt.Printf("Concept defined: %s\n", args[1])
+	case "alias":
+		if err := registry.AddAlias(args[1], args[2]); err != nil {
+			return err
+		}
+		fmt.Printf("%q now means %s\n", args[2], args[1])
+	case "unalias":
+		if err := registry.RemoveAlias(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Alias removed: %s\n", args[1])
+	case "parent":
+		parent := ""
+		if len(args) == 3 {
+			parent = args[2]
+		}
+		if err := registry.SetParent(args[1], parent); err != nil {
+			return err
+		}
+		if parent == "" {
+			fmt.Printf("%s is now a root concept\n", args[1])
+		} else {
+			fmt.Printf("%s is now a sub-concept of %s\n", args[1], parent)
+		}
+	case "remove":
+		if err := registry.Remove(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Concept removed: %s\n", args[1])
+	default:
+		return fmt.Errorf("unknown command %q (want list, add, alias, unalias, parent or remove)", subcommand)
+	}
+	return nil
+}
+
+// printTaxonomy prints the concepts as a tree, sub-concepts indented under
+// their parent.
+func printTaxonomy(registry *learning.ConceptRegistry) error {
+	nodes, err := registry.Taxonomy()
+	if err != nil {
+		return fmt.Errorf("failed to load concepts: %w", err)
+	}
+
+	children := make(map[string][]learning.ConceptNode)
+	for _, n := range nodes {
+		children[n.Parent] = append(children[n.Parent], n)
+	}
+	var printLevel func(parent string, depth int)
+	printLevel = func(parent string, depth int) {
+		for _, n := range children[parent] {
+			line := strings.Repeat("  ", depth) + n.Name
+			if len(n.Aliases) > 0 {
+				line += " (" + strings.Join(n.Aliases, ", ") + ")"
+			}
+			if n.Builtin {
+				line += " [built-in]"
+			}
+			if n.Summary != "" {
+				line += " - " + n.Summary
+			}
+			fmt.Println(line)
+			printLevel(n.Name, depth+1)
+		}
+	}
+	printLevel("", 0)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/redact"
+)
+
+var (
+	logLevel   string
+	logFormat  string
+	logModules string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Minimum log level: debug, info, warn or error (default from logging.level)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: text or json (default from logging.format)")
+	rootCmd.PersistentFlags().StringVar(&logModules, "log-modules", "", "Per-module log levels, e.g. merge_queue=debug,orchestrator=warn")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = config.Default()
+		}
+		logCfg, err := loggingConfig(cfg.Logging, logLevel, logFormat, logModules)
+		if err != nil {
+			return err
+		}
+		logging.Configure(logCfg)
+
+		r, err := redactor(cfg.Redaction)
+		if err != nil {
+			return err
+		}
+		redact.Configure(r)
+		return nil
+	}
+}
+
+// loggingConfig builds the structured logger configuration from the logging
+// section of the config, overridden by the --log-* flags that are set.
+// Module levels from the flag are merged over the configured ones.
+func loggingConfig(cfg config.LoggingConfig, level, format, modules string) (logging.Config, error) {
+	if level == "" {
+		level = cfg.Level
+	}
+	if format == "" {
+		format = cfg.Format
+	}
+
+	var out logging.Config
+	var err error
+	if out.Level, err = logging.ParseLevel(level); err != nil {
+		return logging.Config{}, err
+	}
+	if out.Format, err = logging.ParseFormat(format); err != nil {
+		return logging.Config{}, err
+	}
+
+	levels := make(map[string]string, len(cfg.Modules))
+	for module, l := range cfg.Modules {
+		levels[module] = l
+	}
+	flagLevels, err := logging.SplitModuleLevels(modules)
+	if err != nil {
+		return logging.Config{}, fmt.Errorf("--log-modules: %w", err)
+	}
+	for module, l := range flagLevels {
+		levels[module] = l
+	}
+	if out.Modules, err = logging.ParseModuleLevels(levels); err != nil {
+		return logging.Config{}, err
+	}
+	return out, nil
+}
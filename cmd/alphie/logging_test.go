@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/logging"
+)
+
+func TestLoggingConfig(t *testing.T) {
+	cfg := config.LoggingConfig{
+		Level:   "warn",
+		Format:  "text",
+		Modules: map[string]string{"merge_queue": "debug", "planner": "error"},
+	}
+
+	got, err := loggingConfig(cfg, "", "", "")
+	if err != nil {
+		t.Fatalf("loggingConfig: %v", err)
+	}
+	if got.Level != slog.LevelWarn || got.Format != logging.FormatText || got.Modules["merge_queue"] != slog.LevelDebug {
+		t.Errorf("config = %+v", got)
+	}
+
+	// Flags override the config; flag module levels merge over configured ones
+	got, err = loggingConfig(cfg, "info", "json", "planner=info,agent_spawner=debug")
+	if err != nil {
+		t.Fatalf("loggingConfig with flags: %v", err)
+	}
+	want := map[string]slog.Level{"merge_queue": slog.LevelDebug, "planner": slog.LevelInfo, "agent_spawner": slog.LevelDebug}
+	if got.Level != slog.LevelInfo || got.Format != logging.FormatJSON || len(got.Modules) != len(want) {
+		t.Fatalf("config with flags = %+v", got)
+	}
+	for module, level := range want {
+		if got.Modules[module] != level {
+			t.Errorf("module %s level = %v, want %v", module, got.Modules[module], level)
+		}
+	}
+
+	for _, bad := range [][3]string{{"loud", "", ""}, {"", "xml", ""}, {"", "", "planner"}} {
+		if _, err := loggingConfig(cfg, bad[0], bad[1], bad[2]); err == nil {
+			t.Errorf("loggingConfig(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var (
+	mergesAll      bool
+	mergesOperator string
+)
+
+var mergesCmd = &cobra.Command{
+	Use:   "merges [list | show <id> | edit <id> | approve <id> | reject <id>]",
+	Short: "Review low-confidence merges held in quarantine",
+	Long: `Review semantic merges that were not confident enough to land on their
+target branch on their own.
+
+When the semantic merger resolves a conflict with a confidence below
+merge.review_confidence_threshold, the merge commit is moved onto a
+quarantine branch (alphie/quarantine/<task-id>) and queued here instead.
+Nothing reaches the session branch until a human approves it.
+
+Commands:
+  alphie merges               # List merges awaiting review
+  alphie merges list --all    # Include approved and rejected merges
+  alphie merges show <id>     # Show what the merge would change
+  alphie merges edit <id>     # Check the merge out in a worktree to amend it
+  alphie merges approve <id>  # Merge it into its target branch
+  alphie merges reject <id>   # Discard it (the agent branch is kept)
+
+Edits made with 'edit' must be committed in the worktree before approving.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runMerges,
+}
+
+func init() {
+	mergesCmd.Flags().BoolVar(&mergesAll, "all", false, "List decided merges too")
+	mergesCmd.Flags().StringVar(&mergesOperator, "operator", "", "Identity recorded for the decision (default: git user.email)")
+}
+
+func runMerges(cmd *cobra.Command, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	if subcommand != "list" && len(args) != 2 {
+		return fmt.Errorf("usage: alphie merges %s <id>", subcommand)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		fmt.Println("No merges awaiting review.")
+		return nil
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate state database: %w", err)
+	}
+
+	queue := orchestrator.NewMergeReviewQueue(db, repoPath)
+	switch subcommand {
+	case "list":
+		return listMergeReviews(queue)
+	case "show":
+		return showMergeReview(queue, args[1])
+	case "edit":
+		path, err := queue.Edit(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Merge %s checked out in %s\n", args[1], path)
+		fmt.Printf("Commit your changes there, then run 'alphie merges approve %s'.\n", args[1])
+		return nil
+	case "approve":
+		review, err := queue.Approve(args[1], mergesOperator)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Approved: %s merged into %s\n", review.QuarantineBranch, review.TargetBranch)
+		return nil
+	case "reject":
+		review, err := queue.Reject(args[1], mergesOperator)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rejected: %s discarded; agent branch %s kept\n", review.QuarantineBranch, review.AgentBranch)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q (use list, show, edit, approve or reject)", subcommand)
+	}
+}
+
+// listMergeReviews prints the review queue.
+func listMergeReviews(queue *orchestrator.MergeReviewQueue) error {
+	reviews, err := queue.List(mergesAll)
+	if err != nil {
+		return err
+	}
+	if len(reviews) == 0 {
+		fmt.Println("No merges awaiting review.")
+		return nil
+	}
+	for _, r := range reviews {
+		line := fmt.Sprintf("%-8s  %-8s  %3.0f%%  %-20s -> %s", r.ID, r.Status, r.Confidence*100, r.TaskID, r.TargetBranch)
+		if r.DecidedBy != "" {
+			line += fmt.Sprintf("  (by %s)", r.DecidedBy)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// showMergeReview prints a review and the diff it would merge.
+func showMergeReview(queue *orchestrator.MergeReviewQueue, id string) error {
+	review, err := queue.Get(id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merge review %s (%s)\n", review.ID, review.Status)
+	fmt.Printf("  Task:       %s\n", review.TaskID)
+	fmt.Printf("  Branch:     %s -> %s\n", review.QuarantineBranch, review.TargetBranch)
+	fmt.Printf("  Confidence: %.0f%%\n", review.Confidence*100)
+	fmt.Printf("  Files:      %s\n", strings.Join(review.Files, ", "))
+	if review.Reason != "" {
+		fmt.Printf("  Reasoning:  %s\n", review.Reason)
+	}
+	if review.Status != state.MergeReviewPending {
+		return nil
+	}
+
+	diff, err := queue.Diff(id)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Print(diff)
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/redact"
+)
+
+// redactor builds the secret redactor from the redaction section of the
+// config. It returns nil, turning redaction off, if the section disables it.
+func redactor(cfg config.RedactionConfig) (*redact.Redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	rules := make([]redact.Rule, 0, len(cfg.Patterns))
+	for _, p := range cfg.Patterns {
+		rules = append(rules, redact.Rule{Name: p.Name, Pattern: p.Pattern})
+	}
+	return redact.New(redact.Config{
+		Rules:            rules,
+		EntropyMinLength: cfg.Entropy.MinLength,
+		EntropyThreshold: cfg.Entropy.Threshold,
+	})
+}
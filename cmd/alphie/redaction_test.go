@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+)
+
+func TestRedactor(t *testing.T) {
+	r, err := redactor(config.RedactionConfig{})
+	if err != nil || r != nil {
+		t.Fatalf("redactor(disabled) = %v, %v, want nil, nil", r, err)
+	}
+
+	r, err = redactor(config.RedactionConfig{
+		Enabled:  true,
+		Patterns: []config.RedactionPattern{{Name: "internal-token", Pattern: `itk_[0-9a-f]{8}`}},
+	})
+	if err != nil {
+		t.Fatalf("redactor() error = %v", err)
+	}
+	if got, want := r.Redact("itk_deadbeef"), "[REDACTED:internal-token]"; got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+
+	if _, err := redactor(config.RedactionConfig{
+		Enabled:  true,
+		Patterns: []config.RedactionPattern{{Name: "bad", Pattern: "("}},
+	}); err == nil {
+		t.Error("redactor() with an invalid pattern succeeded")
+	}
+}
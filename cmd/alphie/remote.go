@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShayCichocki/alphie/internal/bitbucket"
+	"github.com/ShayCichocki/alphie/internal/config"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/github"
+	"github.com/ShayCichocki/alphie/internal/gitlab"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// createRemoteProvider returns the provider sessions in repoPath are
+// published to, as configured by the remote section of the config. With
+// provider "auto" the kind is detected from the remote's URL; hosts that
+// name no known provider (e.g. GitHub Enterprise) are treated as GitHub.
+func createRemoteProvider(repoPath string) (remote.Provider, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+	remoteName := cfg.Remote.Remote
+	if remoteName == "" {
+		remoteName = remote.DefaultRemote
+	}
+
+	kind, err := remote.ParseKind(cfg.Remote.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "" {
+		git := remote.NewGit(repoPath, iexec.NewRunner())
+		git.Remote = remoteName
+		url, err := git.URL(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("detect remote provider: %w", err)
+		}
+		if kind = remote.DetectKind(url); kind == "" {
+			kind = remote.KindGitHub
+		}
+	}
+
+	switch kind {
+	case remote.KindGitLab:
+		client := gitlab.NewClient(repoPath)
+		client.SetRemote(remoteName)
+		return client, nil
+	case remote.KindBitbucket:
+		client := bitbucket.NewClient(repoPath)
+		client.SetRemote(remoteName)
+		return client, nil
+	default:
+		client := github.NewClient(repoPath)
+		client.SetRemote(remoteName)
+		return client, nil
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/internal/tui"
+)
+
+var (
+	replayAt    string
+	replayTUI   bool
+	replaySpeed float64
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <session-id>",
+	Short: "Reconstruct the state of a past session from its recorded events",
+	Long: `Replay the events a session recorded in the state database to
+reconstruct what it looked like at any point: the state, agent and usage of
+every task, the session's outcome, and its latest events.
+
+Every event is recorded, including agent progress and cost ticks, so the
+replay shows what the TUI showed. Use it for post-mortems.
+
+--at accepts an RFC3339 timestamp or an offset from the start of the
+session such as 90s or 5m. Without --at the end of the session is shown.
+--tui plays the events back through the dashboard instead, --speed times
+faster than they happened.
+
+Examples:
+  alphie replay abc123                 # Final state of session abc123
+  alphie replay abc123 --at 5m         # Five minutes into the session
+  alphie replay abc123 --tui --speed 10`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayAt, "at", "", "Point in time: RFC3339 timestamp or offset from session start (e.g. 5m)")
+	replayCmd.Flags().BoolVar(&replayTUI, "tui", false, "Play the session back through the TUI dashboard")
+	replayCmd.Flags().Float64Var(&replaySpeed, "speed", 1, "Playback speed for --tui, as a multiple of real time")
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	if replaySpeed <= 0 {
+		return fmt.Errorf("invalid --speed %v: must be positive", replaySpeed)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		return fmt.Errorf("no sessions recorded yet")
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate state database: %w", err)
+	}
+
+	events, err := orchestrator.LoadSessionEvents(db, args[0])
+	if err != nil {
+		return fmt.Errorf("load session events: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no events recorded for session %s", args[0])
+	}
+
+	var at time.Time
+	if replayAt != "" {
+		at, err = parseInspectTime(replayAt, events[0].Timestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if replayTUI {
+		return replayWithTUI(events, at)
+	}
+	return orchestrator.ReplaySession(events, at).Render(os.Stdout)
+}
+
+// replayWithTUI plays events up to at back through the TUI dashboard,
+// keeping their spacing scaled by --speed. The dashboard stays open until
+// the user quits.
+func replayWithTUI(events []orchestrator.OrchestratorEvent, at time.Time) error {
+	program, _ := tui.NewPanelProgram()
+	if program == nil {
+		return fmt.Errorf("failed to create TUI program (nil)")
+	}
+
+	ch := make(chan orchestrator.OrchestratorEvent)
+	go forwardEventsToTUI(program, ch)
+	go func() {
+		defer close(ch)
+		prev := events[0].Timestamp
+		for _, e := range events {
+			if !at.IsZero() && e.Timestamp.After(at) {
+				return
+			}
+			if gap := e.Timestamp.Sub(prev); gap > 0 {
+				time.Sleep(time.Duration(float64(gap) / replaySpeed))
+			}
+			prev = e.Timestamp
+			ch <- e
+		}
+	}()
+
+	_, err := program.Run()
+	return err
+}
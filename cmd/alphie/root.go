@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/spf13/cobra"
 )
 
@@ -41,17 +44,63 @@ Core capabilities:
 - Spawns isolated agents in git worktrees
 - Self-improves code via Ralph-loop (critique, improve, repeat)
 - Learns from failures and successes
-- Merges safely via session branches`,
+- Merges safely via session branches
+
+Exit codes:
+  0    success
+  1    error
+  2    verification failed
+  3    budget exceeded
+  4    escalated to a human (e.g. unresolvable merge conflict)
+  5    rejected at an approval gate
+  6    implement: iteration limit reached before the spec was complete
+  7    implement: stopped making progress
+  8    implement: deadline passed
+  9    implement: an iteration ran past its maximum duration
+  130  canceled
+
+run and implement also write their final status to .alphie/last-run.json.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInteractive()
 	},
 }
 
-// Execute runs the root command
+// errorHint returns a suggestion for well-known error kinds, or "".
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, orchestrator.ErrSessionLocked):
+		return "Hint: another alphie run is active in this repository. Wait for it to finish, or remove .alphie/session.lock if that process is gone."
+	case errors.Is(err, orchestrator.ErrBudgetExceeded):
+		return "Hint: the cost budget was exhausted. Raise it with --budget or the budget policy to continue."
+	case errors.Is(err, orchestrator.ErrMergeNeedsHuman):
+		return "Hint: a merge conflict needs manual resolution. Resolve it on the session branch and re-run."
+	case errors.Is(err, orchestrator.ErrVerificationFailed):
+		return "Hint: verification failed. See the task logs in .alphie/logs for details."
+	}
+	return ""
+}
+
+// Execute runs the root command and exits with a code describing the outcome.
+// Commands marked with lastRunAnnotation also record it in .alphie/last-run.json.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	started := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+
+	if recordsLastRun(cmd) {
+		if wd, wdErr := os.Getwd(); wdErr == nil {
+			if writeErr := writeLastRun(wd, cmd, cmd.Flags().Args(), started, err); writeErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", writeErr)
+			}
+		}
+	}
+
+	if err != nil {
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
 	}
+	_, code := exitStatus(err)
+	os.Exit(code)
 }
 
 func init() {
@@ -72,6 +121,20 @@ func init() {
 	rootCmd.AddCommand(cleanupCmd)
 	rootCmd.AddCommand(baselineCmd)
 	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(annotateCmd)
+	rootCmd.AddCommand(inspectCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(auditTrailCmd)
+	rootCmd.AddCommand(mergesCmd)
+	rootCmd.AddCommand(escalationsCmd)
+	rootCmd.AddCommand(epicCmd)
+	rootCmd.AddCommand(abortCmd)
+	rootCmd.AddCommand(controlCmd)
+	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(implementCmd)
+	rootCmd.AddCommand(specCmd)
+	rootCmd.AddCommand(devTaskCmd)
+	rootCmd.AddCommand(selftestCmd)
 	rootCmd.AddCommand(versionCmd)
 }
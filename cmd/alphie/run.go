@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,20 +19,23 @@ import (
 	"github.com/ShayCichocki/alphie/internal/learning"
 	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/protect"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
 var (
-	runTier        string
-	runGreenfield  bool
-	runHeadless    bool
-	runEpicID      string
-	runQuick       bool
-	runParallel    bool
-	runSingle      bool
-	runPassthrough bool
-	runUseCLI      bool
+	runTier          string
+	runGreenfield    bool
+	runBaseBranch    string
+	runHeadless      bool
+	runEpicID        string
+	runQuick         bool
+	runParallel      bool
+	runSingle        bool
+	runPassthrough   bool
+	runUseCLI        bool
+	runShutdownGrace time.Duration
 )
 
 var runCmd = &cobra.Command{
@@ -62,14 +69,23 @@ Use --greenfield for new projects to merge directly to main.
 Cross-session continuity:
   Use --epic <id> to resume an incomplete epic from a previous session.
   Completed tasks will be skipped, and remaining tasks will be executed.
-  Run 'prog list -p <project> --type epic' to see available epics.`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: runTask,
+  Run 'prog list -p <project> --type epic' to see available epics.
+
+Graceful shutdown:
+  The first SIGINT or SIGTERM stops scheduling new tasks and gives running
+  agents --shutdown-grace to finish and merge. Agents still running after
+  that are stopped and their tasks re-run on resume. The completed work is
+  kept, the session is checkpointed in the state database and the command
+  to resume it is printed. A second signal stops immediately.`,
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        runTask,
+	Annotations: map[string]string{lastRunAnnotation: "true"},
 }
 
 func init() {
 	runCmd.Flags().StringVar(&runTier, "tier", "builder", "Agent tier: quick, scout, builder, or architect")
 	runCmd.Flags().BoolVar(&runGreenfield, "greenfield", false, "Direct merge to main (skip session branch)")
+	runCmd.Flags().StringVar(&runBaseBranch, "base-branch", "", "Branch to start from and merge into (default: merge.default_branch, else detected from origin/HEAD)")
 	runCmd.Flags().BoolVar(&runHeadless, "headless", false, "Run without TUI (headless mode)")
 	runCmd.Flags().StringVar(&runEpicID, "epic", "", "Resume an existing prog epic by ID (cross-session continuity)")
 	runCmd.Flags().BoolVar(&runQuick, "quick", false, "Force quick mode: single agent, no decomposition")
@@ -77,6 +93,7 @@ func init() {
 	runCmd.Flags().BoolVar(&runSingle, "single", false, "Force single mode: decompose but run one agent at a time")
 	runCmd.Flags().BoolVar(&runPassthrough, "passthrough", false, "Bypass orchestration, run Claude directly (debugging/cost control)")
 	runCmd.Flags().BoolVar(&runUseCLI, "cli", false, "Use Claude CLI subprocess instead of API")
+	runCmd.Flags().DurationVar(&runShutdownGrace, "shutdown-grace", 2*time.Minute, "How long running agents may finish after an interrupt before they are stopped")
 }
 
 func runTask(cmd *cobra.Command, args []string) (retErr error) {
@@ -137,11 +154,18 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle signals for graceful shutdown
+	// Handle signals for graceful shutdown: once the orchestrator runs, the
+	// first signal drains it and a second one stops it immediately
+	var draining atomic.Pointer[orchestrator.Orchestrator]
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
+		if orch := draining.Load(); orch != nil {
+			fmt.Printf("\nReceived interrupt, letting running agents finish (up to %v); interrupt again to stop now...\n", runShutdownGrace)
+			orch.Drain(runShutdownGrace)
+			<-sigCh
+		}
 		fmt.Println("\nReceived interrupt, shutting down...")
 		cancel()
 	}()
@@ -283,14 +307,30 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 		return fmt.Errorf("create runner factory: %w", err)
 	}
 
+	// Load user/project config for policy overrides (fallback to defaults)
+	appConfig, err := config.Load()
+	if err != nil {
+		appConfig = config.Default()
+	}
+	registerValidationLayers(appConfig)
+	workspaces, err := workspaceVCS(appConfig, repoPath)
+	if err != nil {
+		return fmt.Errorf("vcs config: %w", err)
+	}
+
 	// Create executor
 	if verbose {
 		fmt.Println("[DEBUG] Creating executor...")
 	}
 	executor, err := agent.NewExecutor(agent.ExecutorConfig{
-		RepoPath:      repoPath,
-		Model:         model,
-		RunnerFactory: runnerFactory,
+		RepoPath:         repoPath,
+		Model:            model,
+		RunnerFactory:    runnerFactory,
+		WarmUps:          warmUpsFromConfig(appConfig),
+		WorktreePoolSize: appConfig.Scheduling.WorktreePoolSize,
+		WorktreeStore:    db,
+		CommitIdentity:   commitIdentity(appConfig),
+		VCS:              workspaces,
 	})
 	if err != nil {
 		return fmt.Errorf("create executor: %w", err)
@@ -314,6 +354,12 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 		tierConfigs = config.DefaultTierConfigs()
 	}
 
+	// An invalid protected-area policy aborts the run rather than going unenforced
+	protectedPolicy, err := protect.LoadPolicy(repoPath)
+	if err != nil {
+		return fmt.Errorf("load protected-area policy: %w", err)
+	}
+
 	// Initialize learning system for auto-learning and retrieval
 	learningsDBPath := filepath.Join(repoPath, ".alphie", "learnings.db")
 	learningSystem, err := learning.NewLearningSystem(learningsDBPath)
@@ -347,17 +393,28 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 		},
 		orchestrator.WithMaxAgents(maxAgents),
 		orchestrator.WithTierConfigs(tierConfigs),
+		orchestrator.WithPolicy(policyFromConfig(appConfig)),
+		orchestrator.WithProtectedAreaChecker(protectedAreasFromConfig(appConfig)),
+		orchestrator.WithSandboxAllowlist(appConfig.Sandbox.Allowlist),
+		orchestrator.WithProtectedPolicy(protectedPolicy),
 		orchestrator.WithGreenfield(runGreenfield),
+		orchestrator.WithMainBranch(baseBranch(runBaseBranch, appConfig)),
+		orchestrator.WithVCS(workspaces),
+		orchestrator.WithCommitIdentity(commitIdentity(appConfig)),
 		orchestrator.WithDecomposerClaude(decomposerClaude),
 		orchestrator.WithMergerClaude(mergerClaude),
 		orchestrator.WithSecondReviewerClaude(secondReviewerClaude),
 		orchestrator.WithRunnerFactory(runnerFactory),
+		orchestrator.WithRateLimiter(agent.RateLimiterOf(runnerFactory)),
+		orchestrator.WithUsageMeter(agent.UsageMeterOf(runnerFactory)),
 		orchestrator.WithStateDB(db),
 		orchestrator.WithLearningSystem(learningSystem),
 		orchestrator.WithProgClient(progClient),
 		orchestrator.WithResumeEpicID(runEpicID),
+		orchestrator.WithSessionMergeGate(sessionMergeGateFromConfig(appConfig, runHeadless)),
 	)
 	defer orch.Stop()
+	draining.Store(orch)
 	if verbose {
 		fmt.Println("[DEBUG] Orchestrator created")
 	}
@@ -377,6 +434,7 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 		fmt.Println()
 
 		if err := orch.Run(ctx, taskDescription); err != nil {
+			printCheckpoint(err, taskDescription)
 			return fmt.Errorf("orchestration failed: %w", err)
 		}
 
@@ -388,5 +446,34 @@ func runTask(cmd *cobra.Command, args []string) (retErr error) {
 	if verbose {
 		fmt.Println("[DEBUG] Starting TUI mode...")
 	}
-	return runWithTUI(ctx, orch, taskDescription)
+	err = runWithTUI(ctx, orch, taskDescription)
+	printCheckpoint(err, taskDescription)
+	return err
+}
+
+// printCheckpoint prints how to resume a session that was shut down
+// gracefully. Other errors are ignored.
+func printCheckpoint(err error, taskDescription string) {
+	var interrupted *orchestrator.SessionInterruptedError
+	if !errors.As(err, &interrupted) {
+		return
+	}
+	c := interrupted.Checkpoint
+	printSessionProgress(os.Stdout, c)
+	if resume := c.ResumeCommand(taskDescription); resume != "" {
+		fmt.Printf("Resume with:\n  %s\n", resume)
+	} else {
+		fmt.Println("The remaining tasks are not tracked in prog; run the task again to continue.")
+	}
+}
+
+// printSessionProgress writes how far an interrupted session got to w.
+func printSessionProgress(w io.Writer, c *orchestrator.Checkpoint) {
+	fmt.Fprintf(w, "\nSession %s stopped: %d/%d tasks complete and merged.\n", c.SessionID, c.CompletedTasks, c.TotalTasks)
+	if len(c.InterruptedTasks) > 0 {
+		fmt.Fprintln(w, "Interrupted before finishing (will re-run):")
+		for _, title := range c.InterruptedTasks {
+			fmt.Fprintf(w, "  - %s\n", title)
+		}
+	}
 }
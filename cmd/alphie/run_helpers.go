@@ -1,12 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/git"
 	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -104,3 +113,218 @@ func checkAndReportResumableSessions(progClient *prog.Client, repoPath string) e
 
 	return nil
 }
+
+// policyFromConfig builds the orchestrator policy, applying user and project
+// configuration on top of the defaults.
+func policyFromConfig(cfg *config.Config) *policy.Config {
+	p := policy.Default()
+	if cfg == nil {
+		return p
+	}
+	for _, rl := range cfg.Scheduling.ResourceLocks {
+		if rl.Resource == "" || len(rl.Patterns) == 0 {
+			continue
+		}
+		p.Scheduling.ResourceRules = append(p.Scheduling.ResourceRules, policy.ResourceRule{
+			Resource: rl.Resource,
+			Patterns: rl.Patterns,
+		})
+	}
+	p.Scheduling.Preemption = cfg.Scheduling.Preemption
+	p.Heartbeat.StallTimeout = cfg.Timeouts.Stall
+	p.Resources.MinFreeDiskMB = cfg.Resources.MinFreeDiskMB
+	p.Resources.MinFreeMemoryMB = cfg.Resources.MinFreeMemoryMB
+	if cfg.Resources.CheckInterval > 0 {
+		p.Resources.CheckInterval = cfg.Resources.CheckInterval
+	}
+	p.Resources.CleanupCommands = cfg.Resources.CleanupCommands
+	p.Approval.Gates = append([]string(nil), cfg.Approval.Gates...)
+	p.Scheduling.ConflictThreshold = cfg.Scheduling.ConflictThreshold
+	p.Merge.SemanticMaxConflictFiles = cfg.Merge.SemanticMaxConflictFiles
+	p.Merge.SemanticMaxConflictLines = cfg.Merge.SemanticMaxConflictLines
+	p.Merge.OptimizeOrder = cfg.Merge.OptimizeOrder
+	p.Merge.PartialMerge = cfg.Merge.PartialMerge
+	p.Merge.ReviewConfidenceThreshold = cfg.Merge.ReviewConfidenceThreshold
+	p.Merge.SessionReview = cfg.Merge.SessionReview
+	if cfg.Merge.OversizeConflictAction != "" {
+		p.Merge.OversizeConflictAction = cfg.Merge.OversizeConflictAction
+	}
+	p.Review.Quorum = cfg.SecondReview.Quorum
+	for _, r := range cfg.SecondReview.Reviewers {
+		p.Review.Reviewers = append(p.Review.Reviewers, policy.ReviewerSpec{
+			Name:     r.Name,
+			Focus:    r.Focus,
+			Triggers: r.Triggers,
+		})
+	}
+	for _, c := range cfg.ReviewRubric {
+		p.Review.Rubric = append(p.Review.Rubric, policy.RubricCriterion{
+			Name:        c.Name,
+			Description: c.Description,
+		})
+	}
+	p.Budget.TaskLimit = cfg.Budget.TaskLimit
+	p.Budget.SessionLimit = cfg.Budget.SessionLimit
+	if cfg.Events.CoalesceWindow > 0 {
+		p.Events.CoalesceWindow = cfg.Events.CoalesceWindow
+	}
+	for eventType, level := range cfg.Events.Verbosity {
+		p.Events.Verbosity[eventType] = level
+	}
+	_ = p.Validate()
+	return p
+}
+
+// reviewRubric returns the review rubric configured in review_rubric, or
+// nil if none is.
+func reviewRubric() []policy.RubricCriterion {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	return policyFromConfig(cfg).Review.Rubric
+}
+
+// warmUpsFromConfig converts the configured warm-up commands into executor
+// hooks, dropping entries without a command.
+func warmUpsFromConfig(cfg *config.Config) []agent.WarmUpHook {
+	if cfg == nil {
+		return nil
+	}
+	var hooks []agent.WarmUpHook
+	for _, w := range cfg.WarmUp {
+		if strings.TrimSpace(w.Command) == "" {
+			continue
+		}
+		hooks = append(hooks, agent.WarmUpHook{
+			Name:    w.Name,
+			Paths:   w.Paths,
+			Command: w.Command,
+			Timeout: w.Timeout,
+		})
+	}
+	return hooks
+}
+
+// registerValidationLayers registers the configured command validation
+// layers so tier pipelines can list them, skipping entries without a name
+// or command and layers already registered.
+func registerValidationLayers(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	for _, l := range cfg.ValidationLayers {
+		if strings.TrimSpace(l.Name) == "" || strings.TrimSpace(l.Command) == "" {
+			continue
+		}
+		err := agent.RegisterValidationLayer(&agent.CommandLayer{
+			LayerName: l.Name,
+			Command:   l.Command,
+			Paths:     l.Paths,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring validation layer: %v\n", err)
+		}
+	}
+}
+
+// protectedAreasFromConfig builds a protected area detector with the
+// configured project areas added to the built-in defaults.
+func protectedAreasFromConfig(cfg *config.Config) *protect.Detector {
+	d := protect.New()
+	if cfg == nil {
+		return d
+	}
+	for _, p := range cfg.ProtectedAreas.Patterns {
+		d.AddPattern(p)
+	}
+	for _, k := range cfg.ProtectedAreas.Keywords {
+		d.AddKeyword(k)
+	}
+	for _, ft := range cfg.ProtectedAreas.FileTypes {
+		d.AddFileType(ft)
+	}
+	return d
+}
+
+// sessionMergeGateFromConfig returns the gate that asks on the terminal
+// before a finished session merges, when the config wants confirmation and
+// there is a terminal to ask on. It returns nil otherwise, leaving the gate
+// to the policy: without a prompt, a confirm gate holds the merge.
+func sessionMergeGateFromConfig(cfg *config.Config, headless bool) orchestrator.SessionMergeGate {
+	if cfg == nil || cfg.Merge.SessionReview != policy.SessionReviewConfirm || !headless {
+		return nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return confirmSessionMerge(os.Stdin, os.Stdout)
+}
+
+// confirmSessionMerge returns a gate that prints the session summary to out
+// and approves the merge if the answer read from in is yes.
+func confirmSessionMerge(in io.Reader, out io.Writer) orchestrator.SessionMergeGate {
+	reader := bufio.NewReader(in)
+	return orchestrator.SessionMergeGateFunc(func(_ context.Context, summary *orchestrator.SessionDiffSummary, _ string) (*orchestrator.SessionMergeVerdict, error) {
+		fmt.Fprintf(out, "\n%s\n\nMerge %s into %s? [y/N] ", summary, summary.Branch, summary.MainBranch)
+		response, err := reader.ReadString('\n')
+		if err != nil && response == "" {
+			return nil, fmt.Errorf("read confirmation: %w", err)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response == "y" || response == "yes" {
+			return &orchestrator.SessionMergeVerdict{Approved: true, Reason: "Session merge confirmed at the prompt"}, nil
+		}
+		return &orchestrator.SessionMergeVerdict{Reason: "Session merge declined at the prompt"}, nil
+	})
+}
+
+// baseBranch returns the branch sessions start from and merge into: flag
+// if set, else merge.default_branch from cfg, loading the config if cfg is
+// nil. Empty leaves the orchestrator to detect the default branch.
+func baseBranch(flag string, cfg *config.Config) string {
+	if flag != "" {
+		return flag
+	}
+	if cfg == nil {
+		loaded, err := config.Load()
+		if err != nil {
+			return ""
+		}
+		cfg = loaded
+	}
+	return cfg.Merge.DefaultBranch
+}
+
+// workspaceVCS returns the workspace backend the vcs config selects for
+// the project at repoPath.
+func workspaceVCS(cfg *config.Config, repoPath string) (vcs.Kind, error) {
+	kind, err := vcs.ParseKind(cfg.VCS)
+	if err != nil {
+		return "", err
+	}
+	return vcs.Resolve(kind, repoPath)
+}
+
+// commitIdentity returns the commit identity the commit config sets, or
+// nil if it sets none. A nil cfg loads the configuration.
+func commitIdentity(cfg *config.Config) *git.CommitIdentity {
+	if cfg == nil {
+		loaded, err := config.Load()
+		if err != nil {
+			return nil
+		}
+		cfg = loaded
+	}
+	c := cfg.Commit
+	if c == (config.CommitConfig{}) {
+		return nil
+	}
+	return &git.CommitIdentity{
+		Name:            c.Name,
+		Email:           c.Email,
+		Sign:            c.Sign,
+		SigningKey:      c.SigningKey,
+		MessageTemplate: c.MessageTemplate,
+	}
+}
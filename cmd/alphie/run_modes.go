@@ -28,6 +28,7 @@ func runQuickMode(ctx context.Context, repoPath, task string, verbose bool) erro
 
 	// Create and run quick executor
 	executor := orchestrator.NewQuickExecutor(repoPath, runnerFactory)
+	executor.SetCommitIdentity(commitIdentity(nil))
 	result, err := executor.Execute(ctx, task)
 	if err != nil {
 		return fmt.Errorf("quick execution failed: %w", err)
@@ -165,14 +166,52 @@ func consumeEventsHeadless(events <-chan orchestrator.OrchestratorEvent) {
 			fmt.Printf("[DONE] %s\n", event.Message)
 		case orchestrator.EventTaskFailed:
 			fmt.Printf("[FAILED] %s: %v\n", event.Message, event.Error)
+		case orchestrator.EventMergeQueued:
+			fmt.Printf("[MERGE QUEUE] %s: %s\n", event.TaskID, event.Message)
 		case orchestrator.EventMergeStarted:
 			fmt.Printf("[MERGE] %s\n", event.Message)
+		case orchestrator.EventMergePartial:
+			fmt.Printf("[PARTIAL MERGE] %s: %s\n", event.TaskID, event.Message)
 		case orchestrator.EventMergeCompleted:
 			fmt.Printf("[MERGED] %s\n", event.Message)
+		case orchestrator.EventMergeOrderChosen:
+			fmt.Printf("[MERGE ORDER] %s\n", event.Message)
 		case orchestrator.EventSessionDone:
 			fmt.Printf("[SESSION] %s\n", event.Message)
 		case orchestrator.EventTaskBlocked:
 			fmt.Printf("[BLOCKED] %s: %v\n", event.Message, event.Error)
+		case orchestrator.EventTaskPreempted:
+			fmt.Printf("[PREEMPTED] %s\n", event.Message)
+		case orchestrator.EventAgentStalled:
+			fmt.Printf("[STALLED] %s: %s\n", event.TaskTitle, event.Message)
+		case orchestrator.EventResourceLow:
+			fmt.Printf("[RESOURCES LOW] %s\n", event.Message)
+		case orchestrator.EventResourceRecovered:
+			fmt.Printf("[RESOURCES] %s\n", event.Message)
+		case orchestrator.EventApprovalRequired:
+			fmt.Printf("[APPROVAL] %s\n", event.Message)
+			fmt.Println("  Answer with: alphie control approve [note] | alphie control reject [note]")
+		case orchestrator.EventApprovalResolved:
+			fmt.Printf("[APPROVAL] %s\n", event.Message)
+		case orchestrator.EventTaskEscalated:
+			fmt.Printf("[ESCALATED] %s\n", event.Message)
+		case orchestrator.EventBudgetWarning:
+			fmt.Printf("[BUDGET] %s\n", event.Message)
+		case orchestrator.EventBudgetExceeded:
+			fmt.Printf("[BUDGET EXCEEDED] %s\n", event.Message)
+		case orchestrator.EventRateLimited:
+			fmt.Printf("[RATE LIMIT] %s\n", event.Message)
+		case orchestrator.EventQuestionAsked:
+			fmt.Printf("[QUESTION] %s (%s): %s\n", event.TaskTitle, orchestrator.FormatQuestionUsage(event.QuestionsAsked, event.QuestionsAllowed), event.Message)
+		case orchestrator.EventQuestionsExhausted:
+			fmt.Printf("[QUESTIONS] %s\n", event.Message)
+		case orchestrator.EventCostEstimate:
+			fmt.Printf("[ESTIMATE] %s\n", event.Message)
+			if event.Estimate != nil {
+				for _, t := range event.Estimate.Tasks {
+					fmt.Printf("  %s: $%.2f ($%.2f-$%.2f, %s)\n", t.TaskTitle, t.Cost, t.CostLow, t.CostHigh, t.Model)
+				}
+			}
 		}
 	}
 }
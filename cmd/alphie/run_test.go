@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -193,3 +197,25 @@ func TestMaxAgentsFromTierConfigs_Scaling(t *testing.T) {
 		t.Errorf("Builder (%d) should have fewer agents than Architect (%d)", builderAgents, architectAgents)
 	}
 }
+
+func TestConfirmSessionMerge(t *testing.T) {
+	summary := &orchestrator.SessionDiffSummary{SessionID: "s1", Branch: "session-s1", MainBranch: "main"}
+	for answer, want := range map[string]bool{"y\n": true, "Yes\n": true, "\n": false, "no\n": false} {
+		var out bytes.Buffer
+		gate := confirmSessionMerge(strings.NewReader(answer), &out)
+		verdict, err := gate.ReviewSessionMerge(context.Background(), summary, "")
+		if err != nil {
+			t.Fatalf("answer %q: error = %v", answer, err)
+		}
+		if verdict.Approved != want {
+			t.Errorf("answer %q: approved = %v, want %v", answer, verdict.Approved, want)
+		}
+		if !strings.Contains(out.String(), "Merge session-s1 into main?") {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+
+	if gate := sessionMergeGateFromConfig(config.Default(), true); gate != nil {
+		t.Error("expected no prompt unless merge.session_review is confirm")
+	}
+}
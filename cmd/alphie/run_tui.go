@@ -9,6 +9,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/internal/tui"
 )
@@ -21,6 +22,7 @@ func runWithTUI(ctx context.Context, orch *orchestrator.Orchestrator, task strin
 	originalOutput := log.Writer()
 	log.SetOutput(io.Discard)
 	defer log.SetOutput(originalOutput)
+	defer logging.SetOutput(io.Discard)()
 
 	// Recover from panics
 	defer func() {
@@ -40,6 +42,14 @@ func runWithTUI(ctx context.Context, orch *orchestrator.Orchestrator, task strin
 	if app == nil && verbose {
 		fmt.Println("[DEBUG] Warning: TUI app is nil")
 	}
+	if app != nil {
+		app.SetApprovalHandler(func(approved bool) error {
+			if approved {
+				return orch.Approve("")
+			}
+			return orch.Reject("")
+		})
+	}
 
 	if verbose {
 		fmt.Println("[DEBUG] runWithTUI: TUI program created")
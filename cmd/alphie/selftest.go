@@ -0,0 +1,576 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/architect"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selftestLive    bool
+	selftestUseCLI  bool
+	selftestKeep    bool
+	selftestTimeout time.Duration
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "self-test",
+	Short: "Verify Alphie works end-to-end on a sample project",
+	Long: `Scaffold a tiny sample repository and spec, run a miniature implement
+loop against it, and verify the whole pipeline works in this environment:
+parse, audit, plan, execute in worktrees, merge, acceptance tests and
+final verification.
+
+By default model calls are scripted, so the self-test is free and exercises
+everything except the model itself. Use --live to make real (tiny) model calls.
+
+Examples:
+  alphie self-test              # Scripted model calls, no cost
+  alphie self-test --live       # Real model calls via the API
+  alphie self-test --live --cli # Real model calls via the Claude CLI
+  alphie self-test --keep       # Keep the sample repo for inspection`,
+	Args: cobra.NoArgs,
+	RunE: runSelfTest,
+}
+
+func init() {
+	selftestCmd.Flags().BoolVar(&selftestLive, "live", false, "Make real model calls instead of scripted ones")
+	selftestCmd.Flags().BoolVar(&selftestUseCLI, "cli", false, "Use Claude CLI subprocess instead of API (with --live)")
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "Keep the sample repository after the run")
+	selftestCmd.Flags().DurationVar(&selftestTimeout, "timeout", 5*time.Minute, "Maximum duration of the self-test session")
+}
+
+// selftestFile is a file the sample spec asks Alphie to create.
+type selftestFile struct {
+	Path    string
+	Content string
+}
+
+// selftestFiles are the independent files the sample session must produce.
+// Two files exercise parallel scheduling and serialized merging.
+var selftestFiles = []selftestFile{
+	{Path: "hello.txt", Content: "hello from alphie"},
+	{Path: "goodbye.txt", Content: "goodbye from alphie"},
+}
+
+// selftestSpecFile is the sample spec's path in the sample repo.
+const selftestSpecFile = "SPEC.md"
+
+// selftestExpectedDir holds, in the sample repo, the expected content of
+// every sample file for the acceptance tests to compare with. It is
+// ignored by git, so agents never see it.
+const selftestExpectedDir = ".alphie/selftest"
+
+// selftestSpec is the sample spec implemented by the self-test: one
+// feature per file, identified by the file's path. It keeps the layout of
+// architect.ScaffoldSpec, so it is parsed without a model call.
+func selftestSpec() string {
+	var sb strings.Builder
+	sb.WriteString("<!-- alphie-spec: scaffold v1 -->\n")
+	sb.WriteString("# Alphie self-test\n\n")
+	sb.WriteString("Create the following plain text files in the repository root. ")
+	sb.WriteString("Each file must contain exactly the given text with no trailing newline.\n\n")
+	sb.WriteString("## Features\n")
+	for _, f := range selftestFiles {
+		sb.WriteString(fmt.Sprintf("\n### %s: Create %s\n\n", f.Path, f.Path))
+		sb.WriteString("- Priority: critical\n")
+		sb.WriteString("- Scope: in scope\n\n")
+		sb.WriteString(fmt.Sprintf("Create the file %s containing exactly: %s\n\n", f.Path, f.Content))
+		sb.WriteString("Acceptance criteria:\n")
+		sb.WriteString(fmt.Sprintf("- %s exists with the expected content\n", f.Path))
+	}
+	return sb.String()
+}
+
+// selftestStep records the outcome of one self-test check.
+type selftestStep struct {
+	Name   string
+	Err    error
+	Detail string
+}
+
+// selftestReport accumulates step results and prints them as they happen.
+type selftestReport struct {
+	steps []selftestStep
+}
+
+// record prints and stores a step result. Returns err for convenient chaining.
+func (r *selftestReport) record(name, detail string, err error) error {
+	r.steps = append(r.steps, selftestStep{Name: name, Err: err, Detail: detail})
+	switch {
+	case err != nil:
+		fmt.Printf("  [FAIL] %s: %v\n", name, err)
+	case detail != "":
+		fmt.Printf("  [PASS] %s (%s)\n", name, detail)
+	default:
+		fmt.Printf("  [PASS] %s\n", name)
+	}
+	return err
+}
+
+// failed returns the number of failed steps.
+func (r *selftestReport) failed() int {
+	n := 0
+	for _, s := range r.steps {
+		if s.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func runSelfTest(cmd *cobra.Command, args []string) error {
+	mode := "scripted"
+	if selftestLive {
+		mode = "live"
+	}
+	fmt.Println("=== Alphie Self-Test ===")
+	fmt.Println()
+	fmt.Printf("Version: %s\n", Version())
+	fmt.Printf("Mode:    %s\n", mode)
+	fmt.Println()
+
+	report := &selftestReport{}
+
+	// 1. Environment checks
+	fmt.Println("Environment:")
+	if err := report.record("git available", "", checkGitAvailable()); err != nil {
+		return fmt.Errorf("self-test failed: git is required")
+	}
+	var runnerFactory agent.ClaudeRunnerFactory
+	if selftestLive {
+		if selftestUseCLI {
+			if err := report.record("claude CLI available", "", CheckClaudeCLI()); err != nil {
+				return fmt.Errorf("self-test failed: claude CLI is required with --cli")
+			}
+		}
+		factory, err := createRunnerFactory(selftestUseCLI)
+		if err := report.record("model backend configured", "", err); err != nil {
+			return fmt.Errorf("self-test failed: %w", err)
+		}
+		runnerFactory = factory
+	} else {
+		runnerFactory = &selftestRunnerFactory{}
+	}
+	fmt.Println()
+
+	// 2. Scaffold the sample project
+	fmt.Println("Sample project:")
+	baseDir, err := os.MkdirTemp("", "alphie-selftest-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	if selftestKeep {
+		defer fmt.Printf("\nSample project kept at: %s\n", baseDir)
+	} else {
+		defer os.RemoveAll(baseDir)
+	}
+
+	repoPath := filepath.Join(baseDir, "repo")
+	if err := report.record("scaffold sample repo", repoPath, scaffoldSelftestRepo(repoPath)); err != nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+	fmt.Println()
+
+	// 3. Run a miniature implement loop
+	fmt.Println("Pipeline:")
+	ctx, cancel := context.WithTimeout(context.Background(), selftestTimeout)
+	defer cancel()
+
+	stats, audit, runErr := runSelftestSession(ctx, baseDir, repoPath, runnerFactory)
+	report.record("plan", fmt.Sprintf("%d tasks", stats.tasksPlanned), firstErr(errIf(stats.tasksPlanned == 0 && runErr != nil, "%v", runErr), errIf(stats.tasksPlanned == 0, "no tasks were planned")))
+	report.record("execute", fmt.Sprintf("%d/%d tasks completed", stats.tasksCompleted, stats.tasksStarted), errIf(stats.tasksFailed > 0 || stats.tasksStarted == 0, "%d of %d tasks failed", stats.tasksFailed, stats.tasksStarted))
+	merged, mergeErr := selftestMerged(repoPath)
+	report.record("merge", fmt.Sprintf("%d files on main", merged), firstErr(mergeErr, errIf(merged < len(selftestFiles), "%d of %d files were merged", merged, len(selftestFiles))))
+
+	// 4. The loop's last audit is the final verification; its acceptance
+	// tests are the validation
+	report.record("validation", fmt.Sprintf("%d acceptance tests", len(selftestFiles)), selftestValidation(audit))
+	report.record("final verification", "", firstErr(runErr, selftestVerified(audit)))
+
+	fmt.Println()
+	if n := report.failed(); n > 0 {
+		return fmt.Errorf("self-test failed: %d of %d checks failed", n, len(report.steps))
+	}
+	fmt.Println("Self-test passed: Alphie is working in this environment.")
+	return nil
+}
+
+// checkGitAvailable verifies that git is installed and runnable.
+func checkGitAvailable() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found in PATH")
+	}
+	return nil
+}
+
+// scaffoldSelftestRepo creates a git repository with a README, spec, and
+// an initial commit on main, plus the ignored expected contents the
+// acceptance tests compare with.
+func scaffoldSelftestRepo(repoPath string) error {
+	if err := os.MkdirAll(filepath.Join(repoPath, selftestExpectedDir), 0755); err != nil {
+		return fmt.Errorf("create repo dir: %w", err)
+	}
+
+	files := map[string]string{
+		"README.md":      "# Alphie self-test\n\nSample project generated by `alphie self-test`.\n",
+		selftestSpecFile: selftestSpec(),
+		".gitignore":     ".alphie/\n",
+	}
+	for _, f := range selftestFiles {
+		files[path.Join(selftestExpectedDir, f.Path)] = f.Content
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repoPath, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	gitCmds := [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "selftest@alphie.local"},
+		{"config", "user.name", "Alphie Self-Test"},
+		{"add", "."},
+		{"commit", "-m", "Initial commit"},
+	}
+	for _, args := range gitCmds {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// selftestStats summarizes the implement loop's plan and task events.
+type selftestStats struct {
+	tasksPlanned   int
+	tasksStarted   int
+	tasksCompleted int
+	tasksFailed    int
+}
+
+// runSelftestSession runs the implement loop on the sample repo through
+// the architect controller, as alphie implement does, with its own prog
+// database and worktrees under baseDir. It returns the task counts and the
+// loop's last audit, which is nil if no audit finished.
+func runSelftestSession(ctx context.Context, baseDir, repoPath string, runnerFactory agent.ClaudeRunnerFactory) (selftestStats, *architect.GapReport, error) {
+	var stats selftestStats
+
+	progDB, err := prog.Open(filepath.Join(baseDir, "prog.db"))
+	if err != nil {
+		return stats, nil, fmt.Errorf("open prog database: %w", err)
+	}
+	defer progDB.Close()
+	if err := progDB.Init(); err != nil {
+		return stats, nil, fmt.Errorf("initialize prog database: %w", err)
+	}
+
+	var mu sync.Mutex
+	controller := architect.NewController(3, 0, 2,
+		architect.WithRepoPath(repoPath),
+		architect.WithProjectName("alphie-selftest"),
+		architect.WithProgClient(prog.NewClient(progDB, "alphie-selftest")),
+		architect.WithRunnerFactory(runnerFactory),
+		architect.WithWorktreeDir(filepath.Join(baseDir, "worktrees")),
+		architect.WithFeatureTests(selftestFeatureTests()),
+		architect.WithBaseBranch("main"),
+		architect.WithProgressCallback(func(event architect.ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch orchestrator.EventType(event.EventType) {
+			case orchestrator.EventTaskStarted:
+				stats.tasksStarted++
+			case orchestrator.EventTaskCompleted:
+				stats.tasksCompleted++
+			case orchestrator.EventTaskFailed:
+				stats.tasksFailed++
+			}
+		}),
+	)
+
+	runErr := controller.Run(ctx, filepath.Join(repoPath, selftestSpecFile), len(selftestFiles))
+
+	mu.Lock()
+	defer mu.Unlock()
+	result := controller.Result()
+	if result == nil {
+		return stats, nil, runErr
+	}
+	for _, iteration := range result.Iterations {
+		stats.tasksPlanned += iteration.TasksCreated
+	}
+	audit, err := selftestLastAudit(result)
+	return stats, audit, firstErr(runErr, err)
+}
+
+// selftestLastAudit loads the gap report of the run's last audit from its
+// verification artifacts.
+func selftestLastAudit(result *architect.RunResult) (*architect.GapReport, error) {
+	if len(result.Iterations) == 0 {
+		return nil, fmt.Errorf("no audit finished")
+	}
+	artifacts := result.Iterations[len(result.Iterations)-1].Artifacts
+	if artifacts == nil || artifacts.Audit == "" {
+		return nil, fmt.Errorf("the last audit wrote no report")
+	}
+	data, err := os.ReadFile(artifacts.Audit)
+	if err != nil {
+		return nil, fmt.Errorf("read last audit: %w", err)
+	}
+	var report architect.GapReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse last audit: %w", err)
+	}
+	return &report, nil
+}
+
+// selftestMerged counts the sample files committed on the sample repo's
+// main branch.
+func selftestMerged(repoPath string) (int, error) {
+	merged := 0
+	for _, f := range selftestFiles {
+		cmd := exec.Command("git", "cat-file", "-e", "main:"+f.Path)
+		cmd.Dir = repoPath
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				continue
+			}
+			return merged, fmt.Errorf("git cat-file: %w", err)
+		}
+		merged++
+	}
+	return merged, nil
+}
+
+// selftestFeatureTests maps each sample feature to an acceptance test
+// comparing its file with the expected content. git is the one tool the
+// self-test requires, so the comparison runs in any shell.
+func selftestFeatureTests() architect.FeatureTestMap {
+	tests := make(architect.FeatureTestMap, len(selftestFiles))
+	for _, f := range selftestFiles {
+		tests[f.Path] = []architect.TestSelector{{
+			Command: fmt.Sprintf("git diff --no-index --quiet -- %s %s", path.Join(selftestExpectedDir, f.Path), f.Path),
+		}}
+	}
+	return tests
+}
+
+// selftestValidation returns an error naming the features whose acceptance
+// tests did not run or failed.
+func selftestValidation(report *architect.GapReport) error {
+	if report == nil {
+		return nil
+	}
+	passed := make(map[string]bool)
+	for _, result := range report.AcceptanceTests {
+		passed[result.FeatureID] = result.Passed
+	}
+	var problems []string
+	for _, f := range selftestFiles {
+		if !passed[f.Path] {
+			problems = append(problems, fmt.Sprintf("%s failed its acceptance test", f.Path))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// selftestVerified returns an error listing the gaps when the audit does
+// not pass final verification.
+func selftestVerified(report *architect.GapReport) error {
+	if report == nil {
+		return nil
+	}
+	score := architect.ScoreReport(report)
+	if score.Total == len(selftestFiles) && score.Verified(architect.StrictnessAll) {
+		return nil
+	}
+	problems := []string{fmt.Sprintf("%d of %d features complete", score.Complete, len(selftestFiles))}
+	for _, gap := range report.Gaps {
+		problems = append(problems, fmt.Sprintf("%s: %s", gap.FeatureID, gap.Description))
+	}
+	return fmt.Errorf("%s", strings.Join(problems, "; "))
+}
+
+// errIf returns a formatted error when cond is true.
+func errIf(cond bool, format string, args ...interface{}) error {
+	if !cond {
+		return nil
+	}
+	return fmt.Errorf(format, args...)
+}
+
+// firstErr returns the first non-nil error.
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selftestFilePattern extracts file instructions from the sample spec.
+var selftestFilePattern = regexp.MustCompile(`Create the file (\S+) containing exactly: ([^\n]+)`)
+
+// selftestAuditPattern marks the architect's audit prompts, which list the
+// features to audit by ID.
+var selftestAuditPattern = regexp.MustCompile(`\(ID: [^)]+\)`)
+
+// selftestRunnerFactory creates scripted runners that stand in for the model.
+type selftestRunnerFactory struct{}
+
+// NewRunner creates a new scripted runner.
+func (f *selftestRunnerFactory) NewRunner() agent.ClaudeRunner {
+	return &selftestRunner{}
+}
+
+// selftestRunner is a scripted agent.ClaudeRunner. It audits the files for
+// audit prompts, orders the gaps as independent for planning prompts,
+// performs the file write for task prompts, and acknowledges everything
+// else.
+type selftestRunner struct {
+	outputCh chan agent.StreamEvent
+}
+
+// Start runs the scripted response for prompt in workDir.
+func (r *selftestRunner) Start(prompt, workDir string) error {
+	return r.StartWithOptions(prompt, workDir, nil)
+}
+
+// StartWithOptions runs the scripted response for prompt in workDir.
+func (r *selftestRunner) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	r.outputCh = make(chan agent.StreamEvent, 1)
+	response, err := selftestRespond(prompt, workDir)
+	if err != nil {
+		r.outputCh <- agent.StreamEvent{Type: agent.StreamEventError, Error: err.Error()}
+	} else {
+		r.outputCh <- agent.StreamEvent{Type: agent.StreamEventResult, Message: response}
+	}
+	close(r.outputCh)
+	return nil
+}
+
+func (r *selftestRunner) Output() <-chan agent.StreamEvent { return r.outputCh }
+func (r *selftestRunner) Wait() error                      { return nil }
+func (r *selftestRunner) Kill() error                      { return nil }
+func (r *selftestRunner) Stderr() string                   { return "" }
+func (r *selftestRunner) PID() int                         { return 0 }
+
+// selftestRespond produces the scripted reply for a prompt.
+func selftestRespond(prompt, workDir string) (string, error) {
+	matches := selftestFilePattern.FindAllStringSubmatch(prompt, -1)
+
+	// Audit: the prompt lists the features by ID, check each file
+	if selftestAuditPattern.MatchString(prompt) {
+		return selftestAudit(matches, workDir)
+	}
+
+	// Planning: the gaps' suggested actions name every file, none of which
+	// depends on another
+	if strings.Contains(prompt, "ordered_gaps") {
+		var order architect.DependencyOrderResponse
+		for i, m := range matches {
+			order.OrderedGaps = append(order.OrderedGaps, architect.DependencyOrderItem{
+				FeatureID: m[1],
+				Priority:  i,
+				Reason:    "independent file",
+				Files:     []string{m[1]},
+			})
+		}
+		order.Rationale = "The files do not depend on each other."
+		data, err := json.Marshal(order)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+
+	// Task execution: write the requested file into the worktree
+	if len(matches) == 1 && workDir != "" {
+		path, content := matches[0][1], strings.TrimSpace(matches[0][2])
+		if err := os.WriteFile(filepath.Join(workDir, path), []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("write %s: %w", path, err)
+		}
+		return fmt.Sprintf("Created %s.", path), nil
+	}
+
+	return "OK", nil
+}
+
+// selftestAudit answers an audit prompt: a feature is COMPLETE if its file
+// in the repo holds the expected content and MISSING otherwise.
+func selftestAudit(matches [][]string, repoPath string) (string, error) {
+	type feature struct {
+		FeatureID  string                     `json:"feature_id"`
+		Status     string                     `json:"status"`
+		Evidence   string                     `json:"evidence"`
+		Anchors    []architect.EvidenceAnchor `json:"anchors,omitempty"`
+		Confidence float64                    `json:"confidence"`
+	}
+	type gap struct {
+		FeatureID       string `json:"feature_id"`
+		Status          string `json:"status"`
+		Description     string `json:"description"`
+		SuggestedAction string `json:"suggested_action"`
+		Severity        string `json:"severity"`
+	}
+	var audit struct {
+		Features []feature `json:"features"`
+		Gaps     []gap     `json:"gaps"`
+		Summary  string    `json:"summary"`
+	}
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		path, content := m[1], strings.TrimSpace(m[2])
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		data, err := os.ReadFile(filepath.Join(repoPath, path))
+		if err == nil && strings.TrimSpace(string(data)) == content {
+			audit.Features = append(audit.Features, feature{
+				FeatureID:  path,
+				Status:     "COMPLETE",
+				Evidence:   fmt.Sprintf("%s contains the expected text", path),
+				Anchors:    []architect.EvidenceAnchor{{File: path}},
+				Confidence: 1,
+			})
+			continue
+		}
+		audit.Features = append(audit.Features, feature{FeatureID: path, Status: "MISSING", Confidence: 1})
+		audit.Gaps = append(audit.Gaps, gap{
+			FeatureID:       path,
+			Status:          "MISSING",
+			Description:     fmt.Sprintf("%s is missing or has the wrong content", path),
+			SuggestedAction: m[0],
+			Severity:        "critical",
+		})
+	}
+	audit.Summary = fmt.Sprintf("%d features audited", len(audit.Features))
+	data, err := json.Marshal(audit)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
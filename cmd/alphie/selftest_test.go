@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+func TestSelftestRespond_DependencyOrder(t *testing.T) {
+	var prompt strings.Builder
+	prompt.WriteString("Analyze these features and determine the optimal implementation order:\n\n")
+	for _, f := range selftestFiles {
+		prompt.WriteString(fmt.Sprintf("   Suggested Action: Create the file %s containing exactly: %s\n", f.Path, f.Content))
+	}
+	prompt.WriteString(`Respond with ONLY a JSON object containing: {"ordered_gaps": []}`)
+
+	response, err := selftestRespond(prompt.String(), "")
+	if err != nil {
+		t.Fatalf("selftestRespond() error = %v", err)
+	}
+
+	var order architect.DependencyOrderResponse
+	if err := json.Unmarshal([]byte(response), &order); err != nil {
+		t.Fatalf("response is not a dependency order: %v", err)
+	}
+	if len(order.OrderedGaps) != len(selftestFiles) {
+		t.Fatalf("expected %d ordered gaps, got %d", len(selftestFiles), len(order.OrderedGaps))
+	}
+	for i, item := range order.OrderedGaps {
+		if item.FeatureID != selftestFiles[i].Path || len(item.DependsOn) != 0 {
+			t.Errorf("gap %d = %+v, want independent %s", i, item, selftestFiles[i].Path)
+		}
+	}
+}
+
+func TestSelftestSpec_ParsedWithoutModel(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), selftestSpecFile)
+	if err := os.WriteFile(specPath, []byte(selftestSpec()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A nil runner fails the test if the parser calls the model
+	spec, err := architect.NewParser().Parse(context.Background(), specPath, nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(spec.Features) != len(selftestFiles) {
+		t.Fatalf("expected %d features, got %d", len(selftestFiles), len(spec.Features))
+	}
+	for i, feature := range spec.Features {
+		if feature.ID != selftestFiles[i].Path {
+			t.Errorf("feature %d ID = %q, want %q", i, feature.ID, selftestFiles[i].Path)
+		}
+	}
+}
+
+func TestSelftestRespond_TaskExecution(t *testing.T) {
+	workDir := t.TempDir()
+	prompt := "Title: Create hello.txt\n\nDescription:\n- Create the file hello.txt containing exactly: hello from alphie\n"
+
+	if _, err := selftestRespond(prompt, workDir); err != nil {
+		t.Fatalf("selftestRespond() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "hello.txt"))
+	if err != nil {
+		t.Fatalf("expected hello.txt to be written: %v", err)
+	}
+	if string(data) != "hello from alphie" {
+		t.Errorf("hello.txt = %q, want %q", string(data), "hello from alphie")
+	}
+}
+
+func TestSelftestRespond_OtherPrompts(t *testing.T) {
+	response, err := selftestRespond("Review this diff for problems.", t.TempDir())
+	if err != nil {
+		t.Fatalf("selftestRespond() error = %v", err)
+	}
+	if response != "OK" {
+		t.Errorf("expected acknowledgement, got %q", response)
+	}
+}
+
+func TestSelftestSession_Scripted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	baseDir := t.TempDir()
+	repoPath := filepath.Join(baseDir, "repo")
+	if err := scaffoldSelftestRepo(repoPath); err != nil {
+		t.Fatalf("scaffoldSelftestRepo() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	stats, audit, err := runSelftestSession(ctx, baseDir, repoPath, &selftestRunnerFactory{})
+	if err != nil {
+		t.Fatalf("runSelftestSession() error = %v", err)
+	}
+	if stats.tasksPlanned != len(selftestFiles) || stats.tasksCompleted != len(selftestFiles) {
+		t.Errorf("expected %d planned and completed tasks, got %+v", len(selftestFiles), stats)
+	}
+	if merged, err := selftestMerged(repoPath); err != nil || merged != len(selftestFiles) {
+		t.Errorf("selftestMerged() = %d, %v, want %d", merged, err, len(selftestFiles))
+	}
+	if err := selftestValidation(audit); err != nil {
+		t.Errorf("selftestValidation() error = %v", err)
+	}
+	if err := selftestVerified(audit); err != nil {
+		t.Errorf("selftestVerified() error = %v", err)
+	}
+}
+
+func TestSelftestFeatureTests_DetectWrongContent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	if err := scaffoldSelftestRepo(repoPath); err != nil {
+		t.Fatalf("scaffoldSelftestRepo() error = %v", err)
+	}
+	// Only the first file is right
+	for i, f := range selftestFiles {
+		content := f.Content
+		if i > 0 {
+			content = "wrong"
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, f.Path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := &architect.ArchSpec{}
+	for _, f := range selftestFiles {
+		spec.Features = append(spec.Features, architect.Feature{ID: f.Path})
+	}
+	results := architect.RunFeatureTests(context.Background(), iexec.NewRunner(), repoPath, selftestFeatureTests(), spec.Features)
+	if len(results) != len(selftestFiles) {
+		t.Fatalf("expected %d results, got %d", len(selftestFiles), len(results))
+	}
+	for _, result := range results {
+		want := result.FeatureID == selftestFiles[0].Path
+		if result.Passed != want {
+			t.Errorf("%s passed = %v, want %v", result.FeatureID, result.Passed, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/architect"
+)
+
+var (
+	specOutput    string
+	specName      string
+	specFeatures  []string
+	specProtected []string
+	specForce     bool
+)
+
+var specCmd = &cobra.Command{
+	Use:   "spec <description>",
+	Short: "Scaffold an architecture spec for alphie implement",
+	Long: `Generate a Markdown spec template from a short project description: an
+overview, a section per feature with its priority, scope and acceptance
+criteria placeholders, and the protected areas.
+
+Fill in the TODOs and pass the file to alphie implement. As long as the
+features keep the template's "### ID: Name" layout, the spec is parsed
+without a call to Claude.
+
+Examples:
+  alphie spec "A URL shortener with click analytics"
+  alphie spec "Team wiki" --feature Pages --feature Search --protected "migrations/**"
+  alphie spec "CLI for backups" -o docs/spec.md`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSpec,
+}
+
+func init() {
+	specCmd.Flags().StringVarP(&specOutput, "output", "o", "SPEC.md", "File to write the spec to")
+	specCmd.Flags().StringVar(&specName, "name", "", "Title of the spec (default: the description's first sentence)")
+	specCmd.Flags().StringArrayVar(&specFeatures, "feature", nil, "Name of a feature to scaffold (repeatable; default: placeholders)")
+	specCmd.Flags().StringArrayVar(&specProtected, "protected", nil, "Path or glob agents must not change without review (repeatable)")
+	specCmd.Flags().BoolVar(&specForce, "force", false, "Overwrite an existing file")
+}
+
+func runSpec(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(specOutput); err == nil && !specForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", specOutput)
+	}
+
+	content := architect.ScaffoldSpec(architect.ScaffoldOptions{
+		Name:           specName,
+		Description:    strings.Join(args, " "),
+		Features:       specFeatures,
+		ProtectedAreas: specProtected,
+	})
+	if dir := filepath.Dir(specOutput); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(specOutput, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write spec: %w", err)
+	}
+
+	fmt.Printf("Wrote %s\n", specOutput)
+	fmt.Println("Fill in the TODOs, then run:")
+	fmt.Printf("  alphie implement %s\n", specOutput)
+	return nil
+}
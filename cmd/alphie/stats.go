@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+var (
+	statsSince string
+	statsJSON  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show historical metrics of past sessions",
+	Long: `Show metrics aggregated over the sessions recorded in this repository's
+state database:
+
+  Sessions   success rate of finished sessions
+  Tasks      attempts, success rate and average cost per delivered feature
+             (the cost of failed attempts counts toward the features)
+  Failures   the most common reasons task attempts failed
+  Merges     how often merging task work hit conflicts, and how many
+             merges were quarantined for human review
+  Tiers      attempts and cost per feature by tier
+
+Examples:
+  alphie stats                # All history
+  alphie stats --since 30d    # Sessions from the last 30 days
+  alphie stats --json         # Machine-readable report`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "", "Only include sessions newer than this (e.g. 30d, 12h)")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output in JSON format")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	since, err := parseStatsSince(statsSince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	repoPath, err := findGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("find git repository: %w", err)
+	}
+
+	dbPath := state.ProjectDBPath(repoPath)
+	if !state.Exists(dbPath) {
+		fmt.Println("No sessions recorded yet.")
+		return nil
+	}
+	db, err := state.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open state database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("migrate state database: %w", err)
+	}
+
+	report, err := db.Report(since)
+	if err != nil {
+		return err
+	}
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	writeStatsReport(os.Stdout, report)
+	return nil
+}
+
+// parseStatsSince converts a --since value to the start of the reporting
+// window. It accepts Go durations plus a "d" suffix for days; an empty
+// value selects all history.
+func parseStatsSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q: expected e.g. 30d or 12h", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q: expected e.g. 30d or 12h", value)
+	}
+	return now.Add(-d), nil
+}
+
+// writeStatsReport prints the report as tables.
+func writeStatsReport(w io.Writer, r *state.Report) {
+	if r.Since.IsZero() {
+		fmt.Fprintln(w, "Alphie stats (all history)")
+	} else {
+		fmt.Fprintf(w, "Alphie stats (since %s)\n", r.Since.Local().Format("2006-01-02 15:04"))
+	}
+
+	s := r.Sessions
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Sessions")
+	fmt.Fprintf(w, "  Total:         %d (%d completed, %d failed, %d unfinished)\n", s.Total, s.Completed, s.Failed, s.Active)
+	fmt.Fprintf(w, "  Success rate:  %s\n", percent(s.SuccessRate, s.Completed+s.Failed))
+
+	t := r.Tasks
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Tasks")
+	fmt.Fprintf(w, "  Attempts:      %d (%d succeeded, %d failed)\n", t.Attempts, t.Succeeded, t.Failed)
+	fmt.Fprintf(w, "  Success rate:  %s\n", percent(t.SuccessRate, t.Attempts))
+	fmt.Fprintf(w, "  Total cost:    $%.2f\n", t.TotalCost)
+	if t.Succeeded > 0 {
+		fmt.Fprintf(w, "  Cost/feature:  $%.2f\n", t.AvgCostPerFeature)
+	} else {
+		fmt.Fprintln(w, "  Cost/feature:  -")
+	}
+	if t.Attempts > 0 {
+		fmt.Fprintf(w, "  Avg duration:  %s\n", t.AvgDuration.Round(time.Second))
+	}
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Failures")
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "  %-14s %4d  %5.1f%%\n", f.Category, f.Count, float64(f.Count)/float64(t.Failed)*100)
+		}
+	}
+
+	m := r.Merges
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Merges")
+	fmt.Fprintf(w, "  Attempted:     %d\n", m.Attempted)
+	fmt.Fprintf(w, "  Conflict rate: %s (%d conflicted)\n", percent(m.ConflictRate, m.Attempted), m.Conflicts)
+	fmt.Fprintf(w, "  Quarantined:   %d\n", m.Quarantined)
+
+	if len(r.Tiers) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Tiers")
+		fmt.Fprintf(w, "  %-10s %8s %9s %12s\n", "TIER", "ATTEMPTS", "SUCCEEDED", "COST/FEATURE")
+		for _, tier := range r.Tiers {
+			cost := "-"
+			if tier.Succeeded > 0 {
+				cost = fmt.Sprintf("$%.2f", tier.AvgCostPerFeature)
+			}
+			fmt.Fprintf(w, "  %-10s %8d %9d %12s\n", tier.Tier, tier.Attempts, tier.Succeeded, cost)
+		}
+	}
+}
+
+// percent formats a rate, or "-" if it was computed over nothing.
+func percent(rate float64, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
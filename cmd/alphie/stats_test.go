@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+func TestParseStatsSince(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"30d", now.AddDate(0, 0, -30), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"1h30m", now.Add(-90 * time.Minute), false},
+		{"xd", time.Time{}, true},
+		{"-2d", time.Time{}, true},
+		{"soon", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseStatsSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatsSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseStatsSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteStatsReport(t *testing.T) {
+	report := &state.Report{
+		Sessions: state.SessionMetrics{Total: 4, Completed: 3, Failed: 1, SuccessRate: 0.75},
+		Tasks: state.TaskMetrics{
+			Attempts: 5, Succeeded: 4, Failed: 1, TotalCost: 2, SuccessRate: 0.8,
+			AvgCostPerFeature: 0.5, AvgDuration: 90 * time.Second,
+		},
+		Failures: []state.FailureCount{{Category: "verification", Count: 1}},
+		Merges:   state.MergeMetrics{Attempted: 4, Conflicts: 1, ConflictRate: 0.25, Quarantined: 1},
+		Tiers:    []state.TierMetrics{{Tier: "builder", Attempts: 5, Succeeded: 4, AvgCostPerFeature: 0.5}},
+	}
+
+	var buf bytes.Buffer
+	writeStatsReport(&buf, report)
+	out := buf.String()
+
+	for _, want := range []string{
+		"all history",
+		"Success rate:  75.0%",
+		"Cost/feature:  $0.50",
+		"Avg duration:  1m30s",
+		"verification      1  100.0%",
+		"Conflict rate: 25.0% (1 conflicted)",
+		"builder",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteStatsReport_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	writeStatsReport(&buf, &state.Report{Since: time.Now()})
+	out := buf.String()
+
+	if !strings.Contains(out, "since ") {
+		t.Errorf("expected the window to be shown, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Success rate:  -") || !strings.Contains(out, "Cost/feature:  -") {
+		t.Errorf("expected rates over nothing to show as '-', got:\n%s", out)
+	}
+	if strings.Contains(out, "Failures") || strings.Contains(out, "Tiers") {
+		t.Errorf("expected empty breakdowns to be omitted, got:\n%s", out)
+	}
+}
@@ -32,12 +32,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Try project database first, then global
 	dbPath := state.ProjectDBPath(cwd)
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		dbPath = state.GlobalDBPath()
 	}
 
 	// Check if any database exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if !state.Exists(dbPath) {
 		fmt.Println("No active session. Run 'alphie run <task>' to start.")
 		return nil
 	}
@@ -18,6 +18,10 @@ var (
 	ErrInvalidTransition = errors.New("invalid state transition")
 	// ErrAgentAlreadyExists indicates an agent with that ID already exists.
 	ErrAgentAlreadyExists = errors.New("agent already exists")
+	// ErrSuspended is the cancellation cause of an agent stopped so its task
+	// can run again later. The executor commits the agent's work to the task
+	// branch before releasing the worktree, so the next attempt resumes it.
+	ErrSuspended = errors.New("agent suspended")
 )
 
 // LifecycleEventType represents the type of agent lifecycle event.
@@ -15,12 +15,19 @@ import (
 // This allows enforcing "no regressions" during the session - pre-existing failures
 // are allowed, but new or worse failures are blocked.
 type Baseline struct {
+	// Commit is the commit the baseline was captured on, if known.
+	Commit string `json:"commit,omitempty"`
+	// BuildPassed is true if the project built at capture time.
+	BuildPassed bool `json:"build_passed"`
 	// FailingTests is the list of test identifiers that were failing at capture time.
 	FailingTests []string `json:"failing_tests"`
 	// LintErrors is the list of lint error messages at capture time.
 	LintErrors []string `json:"lint_errors"`
 	// TypeErrors is the list of type/compilation error messages at capture time.
 	TypeErrors []string `json:"type_errors"`
+	// Coverage is the test coverage percentage of each package at capture
+	// time, by Go import path ("." for a Node project as a whole).
+	Coverage map[string]float64 `json:"coverage,omitempty"`
 	// CapturedAt is when this baseline was captured.
 	CapturedAt time.Time `json:"captured_at"`
 }
@@ -53,17 +60,22 @@ type Comparison struct {
 // This should be called at session start to establish the baseline.
 func CaptureBaseline(repoPath string) (*Baseline, error) {
 	baseline := &Baseline{
+		Commit:     headCommit(repoPath),
 		CapturedAt: time.Now(),
 	}
 
-	// Run tests and capture failures
-	failingTests, err := runTests(repoPath)
+	// Run tests and capture failures and coverage
+	failingTests, coverage, err := runTests(repoPath)
 	if err != nil {
 		// Error running tests is not fatal - we capture whatever we can
 		baseline.FailingTests = failingTests
 	} else {
 		baseline.FailingTests = failingTests
 	}
+	baseline.Coverage = coverage
+	if len(baseline.Coverage) == 0 {
+		baseline.Coverage = runNodeCoverage(repoPath)
+	}
 
 	// Run lint and capture errors
 	lintErrors, err := runLint(repoPath)
@@ -80,6 +92,7 @@ func CaptureBaseline(repoPath string) (*Baseline, error) {
 	} else {
 		baseline.TypeErrors = typeErrors
 	}
+	baseline.BuildPassed = len(baseline.TypeErrors) == 0
 
 	return baseline, nil
 }
@@ -173,29 +186,48 @@ func LoadBaseline(path string) (*Baseline, error) {
 	return &baseline, nil
 }
 
-// runTests executes tests and returns a list of failing test identifiers.
-func runTests(repoPath string) ([]string, error) {
+// runTests executes tests and returns a list of failing test identifiers
+// and the coverage of each package.
+func runTests(repoPath string) ([]string, map[string]float64, error) {
 	var failures []string
 
 	// Try go test first
-	cmd := exec.Command("go", "test", "./...", "-json")
+	cmd := exec.Command("go", "test", "-cover", "./...", "-json")
 	cmd.Dir = repoPath
 
 	output, err := cmd.Output()
+	coverage := parseGoTestJSONCoverage(output)
 	if err != nil {
 		// Parse the output even on error - tests may have run but some failed
 		failures = parseGoTestJSON(output)
 		if len(failures) > 0 {
-			return failures, nil
+			return failures, coverage, nil
 		}
 		// If no JSON output, try parsing plain output
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			failures = parseGoTestPlain(exitErr.Stderr)
 		}
-		return failures, err
+		return failures, coverage, err
 	}
 
-	return parseGoTestJSON(output), nil
+	return parseGoTestJSON(output), coverage, nil
+}
+
+// runNodeCoverage runs a Node project's tests with jest's --coverage and
+// returns the coverage of the project, or nil if it has no test script or
+// reports no coverage.
+func runNodeCoverage(repoPath string) map[string]float64 {
+	data, err := os.ReadFile(filepath.Join(repoPath, "package.json"))
+	if err != nil || !bytes.Contains(data, []byte(`"test"`)) {
+		return nil
+	}
+	cmd := exec.Command("npm", "test", "--", "--coverage")
+	cmd.Dir = repoPath
+	output, _ := cmd.CombinedOutput()
+	if coverage := parseCoverage(string(output)); len(coverage) > 0 {
+		return coverage
+	}
+	return nil
 }
 
 // runLint executes the linter and returns a list of lint errors.
@@ -0,0 +1,115 @@
+// Package agent provides the AI agent implementation for Alphie.
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuildConfigFile is the project-level file that overrides the build, test,
+// lint and typecheck commands QualityGates would otherwise detect.
+//
+// Example:
+//
+//	timeout: 10m
+//	build: make build
+//	test:
+//	  command: make test-unit
+//	  timeout: 20m
+//	lint:
+//	  skip: true
+const BuildConfigFile = ".alphie/build.yml"
+
+// BuildCommand overrides the command run by one quality gate.
+type BuildCommand struct {
+	// Command is run with sh -c in the work directory.
+	Command string `yaml:"command"`
+	// Timeout bounds the command. Zero uses the config or gate timeout.
+	Timeout time.Duration `yaml:"timeout"`
+	// Skip disables the gate for the project.
+	Skip bool `yaml:"skip"`
+}
+
+// UnmarshalYAML accepts either a mapping or a bare command string.
+func (c *BuildCommand) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Command = node.Value
+		return nil
+	}
+	type plain BuildCommand
+	return node.Decode((*plain)(c))
+}
+
+// BuildConfig is the content of BuildConfigFile. Gates without an entry
+// keep their auto-detected command.
+type BuildConfig struct {
+	// Timeout is the default timeout for the configured commands.
+	Timeout time.Duration `yaml:"timeout"`
+	// Build overrides the build gate.
+	Build *BuildCommand `yaml:"build"`
+	// Test overrides the test gate.
+	Test *BuildCommand `yaml:"test"`
+	// Lint overrides the lint gate.
+	Lint *BuildCommand `yaml:"lint"`
+	// Typecheck overrides the typecheck gate.
+	Typecheck *BuildCommand `yaml:"typecheck"`
+}
+
+// LoadBuildConfig reads BuildConfigFile from workDir. It returns nil without
+// an error if the project has no such file.
+func LoadBuildConfig(workDir string) (*BuildConfig, error) {
+	path := filepath.Join(workDir, BuildConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", BuildConfigFile, err)
+	}
+
+	var cfg BuildConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", BuildConfigFile, err)
+	}
+	for gate, cmd := range cfg.commands() {
+		if cmd != nil && !cmd.Skip && strings.TrimSpace(cmd.Command) == "" {
+			return nil, fmt.Errorf("%s: %s has no command", BuildConfigFile, gate)
+		}
+	}
+	return &cfg, nil
+}
+
+// Command returns the override for gate, or nil if the gate is auto-detected.
+func (c *BuildConfig) Command(gate string) *BuildCommand {
+	if c == nil {
+		return nil
+	}
+	return c.commands()[gate]
+}
+
+// TimeoutFor returns the timeout for cmd, falling back to the config
+// timeout and then to fallback.
+func (c *BuildConfig) TimeoutFor(cmd *BuildCommand, fallback time.Duration) time.Duration {
+	if cmd != nil && cmd.Timeout > 0 {
+		return cmd.Timeout
+	}
+	if c != nil && c.Timeout > 0 {
+		return c.Timeout
+	}
+	return fallback
+}
+
+// commands maps gate names to their overrides.
+func (c *BuildConfig) commands() map[string]*BuildCommand {
+	return map[string]*BuildCommand{
+		"build":     c.Build,
+		"test":      c.Test,
+		"lint":      c.Lint,
+		"typecheck": c.Typecheck,
+	}
+}
@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeBuildConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, BuildConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadBuildConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeBuildConfig(t, dir, `
+timeout: 10m
+build: make build
+test:
+  command: make test-unit
+  timeout: 20m
+lint:
+  skip: true
+`)
+
+	cfg, err := LoadBuildConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadBuildConfig() error = %v", err)
+	}
+	if cfg.Command("build").Command != "make build" {
+		t.Errorf("build command = %q, want make build", cfg.Command("build").Command)
+	}
+	if got := cfg.TimeoutFor(cfg.Command("build"), time.Minute); got != 10*time.Minute {
+		t.Errorf("build timeout = %v, want the config timeout", got)
+	}
+	if got := cfg.TimeoutFor(cfg.Command("test"), time.Minute); got != 20*time.Minute {
+		t.Errorf("test timeout = %v, want 20m", got)
+	}
+	if !cfg.Command("lint").Skip {
+		t.Error("expected lint to be skipped")
+	}
+	if cfg.Command("typecheck") != nil {
+		t.Error("expected typecheck to be auto-detected")
+	}
+}
+
+func TestLoadBuildConfig_Missing(t *testing.T) {
+	cfg, err := LoadBuildConfig(t.TempDir())
+	if cfg != nil || err != nil {
+		t.Errorf("LoadBuildConfig() = %v, %v; want nil, nil", cfg, err)
+	}
+	if got := cfg.TimeoutFor(cfg.Command("test"), time.Minute); got != time.Minute {
+		t.Errorf("nil config timeout = %v, want the fallback", got)
+	}
+}
+
+func TestLoadBuildConfig_EmptyCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeBuildConfig(t, dir, "test:\n  timeout: 5m\n")
+
+	if _, err := LoadBuildConfig(dir); err == nil || !strings.Contains(err.Error(), "test has no command") {
+		t.Errorf("expected a missing command error, got %v", err)
+	}
+}
+
+func TestQualityGates_BuildConfigOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeBuildConfig(t, dir, `
+test: echo custom tests
+build: exit 3
+lint:
+  command: exec sleep 5
+  timeout: 50ms
+typecheck:
+  skip: true
+`)
+
+	qg := NewQualityGates(dir)
+	qg.EnableTest(true)
+	qg.EnableBuild(true)
+	qg.EnableLint(true)
+	qg.EnableTypecheck(true)
+
+	results, err := qg.RunGates()
+	if err != nil {
+		t.Fatalf("RunGates() error = %v", err)
+	}
+	got := make(map[string]*GateOutput)
+	for _, r := range results {
+		got[r.Gate] = r
+	}
+
+	if got["test"].Result != GatePass || !strings.Contains(got["test"].Output, "custom tests") {
+		t.Errorf("test gate = %v %q, want the configured command to pass", got["test"].Result, got["test"].Output)
+	}
+	if got["build"].Result != GateFail {
+		t.Errorf("build gate = %v, want fail", got["build"].Result)
+	}
+	if got["lint"].Result != GateError || !strings.Contains(got["lint"].Output, "timed out") {
+		t.Errorf("lint gate = %v %q, want a timeout", got["lint"].Result, got["lint"].Output)
+	}
+	if got["typecheck"].Result != GateSkip {
+		t.Errorf("typecheck gate = %v, want skip", got["typecheck"].Result)
+	}
+}
+
+func TestQualityGates_InvalidBuildConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeBuildConfig(t, dir, "test: [unterminated\n")
+
+	qg := NewQualityGates(dir)
+	qg.EnableTest(true)
+	results, _ := qg.RunGates()
+	if results[0].Result != GateError || !strings.Contains(results[0].Output, "Invalid build config") {
+		t.Errorf("test gate = %v %q, want an invalid config error", results[0].Result, results[0].Output)
+	}
+}
+
+func TestQualityGates_MakeTargets(t *testing.T) {
+	dir := t.TempDir()
+	makefile := "GO := go\n\nbuild:\n\t@echo building\n\nlint: build\n\t@echo linting\n"
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte(makefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	qg := NewQualityGates(dir)
+	for target, want := range map[string]bool{"build": true, "lint": true, "test": false, "GO": false} {
+		if got := qg.hasMakeTarget(target); got != want {
+			t.Errorf("hasMakeTarget(%q) = %v, want %v", target, got, want)
+		}
+	}
+
+	output := qg.runMakeTarget(&GateOutput{Gate: "test"}, "test")
+	if output.Result != GateSkip {
+		t.Errorf("missing make target should skip, got %v", output.Result)
+	}
+}
+
+func TestQualityGates_PythonRunner(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"plain", map[string]string{"requirements.txt": ""}, nil},
+		{"uv", map[string]string{"pyproject.toml": "", "uv.lock": ""}, []string{"uv", "run"}},
+		{"poetry lock", map[string]string{"pyproject.toml": "", "poetry.lock": ""}, []string{"poetry", "run"}},
+		{"poetry section", map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"x\"\n"}, []string{"poetry", "run"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := NewQualityGates(dir).pythonRunner(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pythonRunner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualityGates_PythonToolUsesRunner(t *testing.T) {
+	dir := t.TempDir()
+	pyproject := "[tool.poetry]\nname = \"x\"\n\n[tool.poetry.group.dev.dependencies]\nruff = \"^0.4\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte(pyproject), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := NewQualityGates(dir).pythonTool("ruff")
+	if want := []string{"poetry", "run", "ruff"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pythonTool(ruff) = %v, want %v", got, want)
+	}
+}
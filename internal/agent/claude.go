@@ -9,6 +9,8 @@ import (
 	"io"
 	"os/exec"
 	"sync"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
 )
 
 // StreamEventType represents the type of stream event from Claude Code.
@@ -114,8 +116,8 @@ func (p *ClaudeProcess) StartWithOptions(prompt, worktreePath string, opts *Star
 		args = append(args, "--model", opts.Model)
 	}
 
-	// Add prompt last
-	args = append(args, "-p", prompt)
+	// Add prompt last, with secrets from learnings or task context redacted
+	args = append(args, "-p", redact.String(prompt))
 
 	p.cmd = exec.CommandContext(p.ctx, "claude", args...)
 
@@ -117,6 +117,9 @@ var _ ClaudeRunner = (*ClaudeAPIAdapter)(nil)
 type APIRunnerFactory struct {
 	Client        *api.Client
 	Notifications *api.NotificationManager
+	// RateLimiter is the limiter set on Client, kept so its events can be
+	// reported. Nil if Client is not rate limited.
+	RateLimiter *RateLimiter
 }
 
 // NewRunner creates a new API-based ClaudeRunner.
@@ -128,3 +131,12 @@ func (f *APIRunnerFactory) NewRunner() ClaudeRunner {
 	return NewClaudeAPIAdapter(claudeAPI)
 }
 
+
+// RateLimiterOf returns the rate limiter pacing the runners factory
+// creates, or nil if they are not rate limited.
+func RateLimiterOf(factory ClaudeRunnerFactory) *RateLimiter {
+	if f, ok := unwrapFactory(factory).(*APIRunnerFactory); ok {
+		return f.RateLimiter
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -18,6 +19,16 @@ type TaskExecutor interface {
 // Compile-time verification that Executor implements TaskExecutor.
 var _ TaskExecutor = (*Executor)(nil)
 
+// PatchApplier applies the changes of tasks run in plain workspaces, which
+// are returned as patches instead of branches.
+type PatchApplier interface {
+	// ApplyPatch applies a task's patch to the project.
+	ApplyPatch(p *vcs.Patch) error
+}
+
+// Compile-time verification that Executor implements PatchApplier.
+var _ PatchApplier = (*Executor)(nil)
+
 // ClaudeRunner defines the interface for Claude execution backends.
 // This interface is implemented by both:
 // - ClaudeProcess (subprocess-based, uses claude CLI)
@@ -0,0 +1,38 @@
+package agent
+
+import "time"
+
+// commitCheckInterval is how often the worktree is checked for the agent's
+// first commit while it runs.
+const commitCheckInterval = 2 * time.Second
+
+// commitWatcher notices the first commit an agent makes in its worktree, so
+// that the files it actually touches are known before it finishes.
+type commitWatcher struct {
+	workDir   string
+	base      string
+	lastCheck time.Time
+	seen      bool
+}
+
+// newCommitWatcher watches workDir for commits on top of base.
+func newCommitWatcher(workDir, base string) *commitWatcher {
+	return &commitWatcher{workDir: workDir, base: base, lastCheck: time.Now()}
+}
+
+// check returns the files changed since base once HEAD has moved past it.
+// It reports true only for the first commit seen, and checks git at most
+// every commitCheckInterval.
+func (w *commitWatcher) check() ([]string, bool) {
+	if w.seen || w.base == "" || time.Since(w.lastCheck) < commitCheckInterval {
+		return nil, false
+	}
+	w.lastCheck = time.Now()
+
+	head := headCommit(w.workDir)
+	if head == "" || head == w.base {
+		return nil, false
+	}
+	w.seen = true
+	return changedFilesSince(w.workDir, w.base), true
+}
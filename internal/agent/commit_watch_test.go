@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommitWatcher_ReportsFirstCommitOnce(t *testing.T) {
+	dir := t.TempDir()
+	if err := initTestGitRepo(dir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	w := newCommitWatcher(dir, headCommit(dir))
+	w.lastCheck = time.Time{}
+	if _, ok := w.check(); ok {
+		t.Fatal("check() reported a commit before one was made")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "internal", "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "internal", "auth", "login.go"), []byte("package auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "Add login"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+
+	// Checks are throttled
+	if _, ok := w.check(); ok {
+		t.Fatal("check() did not wait for the check interval")
+	}
+
+	w.lastCheck = time.Time{}
+	files, ok := w.check()
+	if !ok || !reflect.DeepEqual(files, []string{"internal/auth/login.go"}) {
+		t.Errorf("check() = %v, %v, want the committed file", files, ok)
+	}
+
+	w.lastCheck = time.Time{}
+	if _, ok := w.check(); ok {
+		t.Error("check() reported the first commit twice")
+	}
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/protect"
+)
+
+// Weights of the confidence signals. They sum to 1.
+const (
+	confidenceContractWeight  = 0.4
+	confidenceDiffWeight      = 0.2
+	confidenceProtectedWeight = 0.2
+	confidenceHistoryWeight   = 0.2
+)
+
+const (
+	// confidenceSmallDiff is the diff size, in changed lines, at or below
+	// which a diff counts as fully low-risk.
+	confidenceSmallDiff = 50
+	// confidenceLargeDiff is the diff size at or above which a diff earns
+	// no confidence.
+	confidenceLargeDiff = 500
+	// confidenceMinHistory is how many past attempts of a task type are
+	// needed before their success rate counts.
+	confidenceMinHistory = 3
+	// confidenceUnknown scores a signal that could not be measured.
+	confidenceUnknown = 0.5
+)
+
+// TaskHistory is the track record of past attempts at tasks of the same type.
+type TaskHistory struct {
+	// Succeeded is how many of the attempts succeeded.
+	Succeeded int
+	// Attempts is how many attempts were recorded.
+	Attempts int
+}
+
+// ConfidenceSignals are the measurements a task's confidence score is
+// computed from.
+type ConfidenceSignals struct {
+	// ContractChecks is how many verification contract checks ran. Zero
+	// means the task has no contract.
+	ContractChecks int
+	// ContractPassed is how many of the checks passed.
+	ContractPassed int
+	// LinesChanged is the number of lines added and removed.
+	LinesChanged int
+	// ProtectedFiles lists changed files in protected areas.
+	ProtectedFiles []string
+	// History is the success record of the task's type.
+	History TaskHistory
+}
+
+// Score combines the signals into a confidence between 0 and 1 that the
+// work is correct without a semantic review. A passing contract weighs the
+// most; a small diff, no protected files and a task type that usually
+// succeeds make up the rest. Signals that could not be measured count as
+// neutral.
+func (s ConfidenceSignals) Score() float64 {
+	contract := confidenceUnknown
+	if s.ContractChecks > 0 {
+		contract = float64(s.ContractPassed) / float64(s.ContractChecks)
+	}
+
+	var diff float64
+	switch {
+	case s.LinesChanged <= confidenceSmallDiff:
+		diff = 1
+	case s.LinesChanged < confidenceLargeDiff:
+		diff = float64(confidenceLargeDiff-s.LinesChanged) / float64(confidenceLargeDiff-confidenceSmallDiff)
+	}
+
+	protected := 1.0
+	if len(s.ProtectedFiles) > 0 {
+		protected = 0
+	}
+
+	history := confidenceUnknown
+	if s.History.Attempts >= confidenceMinHistory {
+		history = float64(s.History.Succeeded) / float64(s.History.Attempts)
+	}
+
+	return contract*confidenceContractWeight +
+		diff*confidenceDiffWeight +
+		protected*confidenceProtectedWeight +
+		history*confidenceHistoryWeight
+}
+
+// measureConfidence gathers the confidence signals for a task's worktree.
+// It runs the verification contract as it stands, without refining it,
+// so that no model calls are made.
+func (e *Executor) measureConfidence(ctx context.Context, run *validationRun) ConfidenceSignals {
+	var s ConfidenceSignals
+	if run.opts != nil {
+		s.History = run.opts.TaskHistory
+	}
+
+	if contract := run.verifyCtx.finalContract; contract != nil || run.verifyCtx.draftContract != nil {
+		if contract == nil {
+			contract = run.verifyCtx.draftContract
+		}
+		if vr, err := newContractRunner(run.worktreePath, run.commands()).Run(ctx, contract); err == nil {
+			for _, c := range vr.CommandResults {
+				s.ContractChecks++
+				if c.Passed {
+					s.ContractPassed++
+				}
+			}
+			for _, f := range vr.FileResults {
+				s.ContractChecks++
+				if f.Passed {
+					s.ContractPassed++
+				}
+			}
+		}
+	}
+
+	s.LinesChanged = worktreeDiffLines(run.worktreePath)
+
+	detector := protect.New()
+	if run.opts != nil && run.opts.ProtectedAreas != nil {
+		detector = run.opts.ProtectedAreas
+	}
+	for _, f := range worktreeChangedFiles(run.worktreePath) {
+		if detector.IsProtected(f) {
+			s.ProtectedFiles = append(s.ProtectedFiles, f)
+		}
+	}
+	return s
+}
+
+// worktreeDiffLines counts the lines added and removed in the worktree
+// since its last commit. Untracked files count in full.
+func worktreeDiffLines(dir string) int {
+	total := 0
+	cmd := exec.Command("git", "diff", "--numstat", "HEAD")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		total += sumNumstat(string(out))
+	}
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard", "-z")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return total
+	}
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f == "" {
+			continue
+		}
+		cmd = exec.Command("git", "diff", "--numstat", "--no-index", "/dev/null", f)
+		cmd.Dir = dir
+		// diff --no-index exits 1 when the files differ
+		numstat, _ := cmd.Output()
+		total += sumNumstat(string(numstat))
+	}
+	return total
+}
+
+// sumNumstat adds up the added and removed counts of git --numstat output.
+// Binary files, reported as "-", count as nothing.
+func sumNumstat(out string) int {
+	total := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		for _, f := range fields[:2] {
+			if n, err := strconv.Atoi(f); err == nil {
+				total += n
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/verification"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestConfidenceSignalsScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals ConfidenceSignals
+		want    float64
+	}{
+		{
+			name:    "all good",
+			signals: ConfidenceSignals{ContractChecks: 4, ContractPassed: 4, LinesChanged: 20, History: TaskHistory{Succeeded: 9, Attempts: 10}},
+			want:    0.4 + 0.2 + 0.2 + 0.18,
+		},
+		{
+			name:    "unknown contract and thin history are neutral",
+			signals: ConfidenceSignals{LinesChanged: 10, History: TaskHistory{Succeeded: 2, Attempts: 2}},
+			want:    0.2 + 0.2 + 0.2 + 0.1,
+		},
+		{
+			name:    "large diff in a protected area",
+			signals: ConfidenceSignals{ContractChecks: 2, ContractPassed: 1, LinesChanged: 275, ProtectedFiles: []string{"auth/login.go"}},
+			want:    0.2 + 0.1 + 0 + 0.1,
+		},
+		{
+			name:    "huge diff earns nothing",
+			signals: ConfidenceSignals{ContractChecks: 1, ContractPassed: 1, LinesChanged: 5000, History: TaskHistory{Succeeded: 3, Attempts: 3}},
+			want:    0.4 + 0 + 0.2 + 0.2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.signals.Score(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumNumstat(t *testing.T) {
+	out := "10\t2\tmain.go\n-\t-\tlogo.png\n3\t0\tREADME.md\n"
+	if got := sumNumstat(out); got != 15 {
+		t.Errorf("sumNumstat = %d, want 15", got)
+	}
+}
+
+func TestMeasureConfidence(t *testing.T) {
+	dir := t.TempDir()
+	if err := initTestGitRepo(dir); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nmore\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auth", "login.go"), []byte("package auth\n\nfunc Login() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Executor{}
+	s := e.measureConfidence(context.Background(), &validationRun{
+		result:       &ExecutionResult{},
+		task:         &models.Task{ID: "task-1"},
+		opts:         &ExecuteOptions{TaskHistory: TaskHistory{Succeeded: 4, Attempts: 5}},
+		worktreePath: dir,
+		verifyCtx: &verificationContext{draftContract: &verification.VerificationContract{
+			Commands: []verification.VerificationCommand{
+				{Command: "true", Expect: "exit 0", Required: true},
+				{Command: "false", Expect: "exit 0", Required: true},
+			},
+		}},
+	})
+
+	if s.ContractChecks != 2 || s.ContractPassed != 1 {
+		t.Errorf("contract = %d/%d, want 1/2", s.ContractPassed, s.ContractChecks)
+	}
+	// README: 1 removed + 2 added; auth/login.go: 3 added
+	if s.LinesChanged != 6 {
+		t.Errorf("lines changed = %d, want 6", s.LinesChanged)
+	}
+	if len(s.ProtectedFiles) != 1 || s.ProtectedFiles[0] != "auth/login.go" {
+		t.Errorf("protected files = %v", s.ProtectedFiles)
+	}
+	if s.History.Attempts != 5 {
+		t.Errorf("history = %+v", s.History)
+	}
+}
+
+func TestRunValidationPipeline_SkipsConfidentReview(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		wantSkip  bool
+	}{
+		{"confident", 0.7, true},
+		{"not confident enough", 0.95, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := initTestGitRepo(dir); err != nil {
+				t.Skipf("git not available: %v", err)
+			}
+			result := &ExecutionResult{}
+			// The self-critique loop is disabled so that a review that is not
+			// skipped for confidence reports "self-critique disabled"
+			opts := &ExecuteOptions{TaskHistory: TaskHistory{Succeeded: 10, Attempts: 10}}
+			pipeline := &ValidationPipeline{
+				Layers:               []PipelineLayer{{Name: ValidationReview}},
+				SkipReviewConfidence: tt.threshold,
+			}
+
+			e := &Executor{}
+			e.runValidationPipeline(context.Background(), pipeline, &validationRun{
+				result:       result,
+				task:         &models.Task{ID: "task-1"},
+				tier:         models.TierBuilder,
+				opts:         opts,
+				worktreePath: dir,
+				verifyCtx:    &verificationContext{},
+			})
+
+			// No contract (0.2), empty diff (0.2), nothing protected (0.2), perfect history (0.2)
+			if result.Confidence == nil || math.Abs(*result.Confidence-0.8) > 1e-9 {
+				t.Fatalf("confidence = %v, want 0.8", result.Confidence)
+			}
+			review := result.Validation[0]
+			if !review.Skipped {
+				t.Fatalf("review outcome = %+v", review)
+			}
+			if gotConfidenceSkip := strings.HasPrefix(review.Detail, "confidence"); gotConfidenceSkip != tt.wantSkip {
+				t.Errorf("review detail = %q, want confidence skip %v", review.Detail, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestRunValidationPipeline_NoThresholdNoConfidence(t *testing.T) {
+	result := &ExecutionResult{}
+	e := &Executor{}
+	e.runValidationPipeline(context.Background(), &ValidationPipeline{}, &validationRun{
+		result:       result,
+		task:         &models.Task{ID: "task-1"},
+		opts:         &ExecuteOptions{},
+		worktreePath: t.TempDir(),
+		verifyCtx:    &verificationContext{},
+	})
+	if result.Confidence != nil {
+		t.Errorf("expected no confidence without a threshold, got %v", *result.Confidence)
+	}
+}
@@ -0,0 +1,216 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// ContextKind is a kind of context injected into an agent's prompt.
+type ContextKind string
+
+const (
+	// ContextFailure is what went wrong in the task's previous attempt.
+	ContextFailure ContextKind = "failure"
+	// ContextProtected notes the task's file boundaries that are protected
+	// areas.
+	ContextProtected ContextKind = "protected_area"
+	// ContextSpec is the part of the spec the task implements, its
+	// acceptance criteria.
+	ContextSpec ContextKind = "spec"
+	// ContextAvoid is a learning about an approach that failed before.
+	ContextAvoid ContextKind = "avoid"
+	// ContextLearning is a learning about an approach that worked before.
+	ContextLearning ContextKind = "learning"
+	// ContextStructure is a directory convention of the repository.
+	ContextStructure ContextKind = "structure"
+)
+
+// contextPriority orders the kinds of context from the most to the least
+// important. When the budget runs out, later kinds are dropped first.
+var contextPriority = map[ContextKind]int{
+	ContextFailure:   0,
+	ContextProtected: 1,
+	ContextSpec:      2,
+	ContextAvoid:     3,
+	ContextLearning:  4,
+	ContextStructure: 5,
+}
+
+// DefaultContextBudgets is the context budget of each tier, in estimated
+// tokens, when the tier config sets none.
+var DefaultContextBudgets = map[models.Tier]int{
+	models.TierQuick:     1000,
+	models.TierScout:     2000,
+	models.TierBuilder:   4000,
+	models.TierArchitect: 8000,
+}
+
+// minTruncatedTokens is the smallest part of a truncatable item worth
+// keeping; below it the item is dropped instead.
+const minTruncatedTokens = 50
+
+// truncatedMarker ends the content of a truncated item.
+const truncatedMarker = "\n[... truncated to fit the context budget]"
+
+// ContextItem is one piece of context for a prompt.
+type ContextItem struct {
+	// Kind decides the item's priority and the section it is rendered in.
+	Kind ContextKind
+	// Key identifies the item in the log of dropped context, e.g. a
+	// learning ID.
+	Key string
+	// Content is the rendered text of the item.
+	Content string
+	// Truncatable items that do not fit are cut to the remaining budget
+	// instead of dropped.
+	Truncatable bool
+}
+
+// DroppedContext records an item that did not fit the budget.
+type DroppedContext struct {
+	Kind ContextKind
+	Key  string
+	// Tokens is the estimated size of the item before packing.
+	Tokens int
+	// Truncated is set if the item was cut rather than dropped.
+	Truncated bool
+}
+
+// String describes the dropped item for logs.
+func (d DroppedContext) String() string {
+	action := "dropped"
+	if d.Truncated {
+		action = "truncated"
+	}
+	return fmt.Sprintf("%s %s %s (~%d tokens)", action, d.Kind, d.Key, d.Tokens)
+}
+
+// ContextPack is the context chosen for a prompt.
+type ContextPack struct {
+	// Items are the kept items, in priority order.
+	Items []ContextItem
+	// Dropped lists the items that were dropped or truncated, in priority
+	// order.
+	Dropped []DroppedContext
+	// Tokens is the estimated size of the kept items.
+	Tokens int
+	// Budget is the budget the items were packed into.
+	Budget int
+}
+
+// Of returns the kept items of a kind, in their original order.
+func (p *ContextPack) Of(kind ContextKind) []ContextItem {
+	var items []ContextItem
+	for _, item := range p.Items {
+		if item.Kind == kind {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ContextBudgeter fits the context injected into a prompt to a token
+// budget. Items are ranked by kind, then by their order within the kind, so
+// the same items always pack the same way.
+type ContextBudgeter struct {
+	budget int
+}
+
+// NewContextBudgeter creates a budgeter for a budget in estimated tokens.
+// A budget of 0 or less keeps every item.
+func NewContextBudgeter(budget int) *ContextBudgeter {
+	return &ContextBudgeter{budget: budget}
+}
+
+// ContextBudgetFor returns the context budget of a tier: override if it is
+// positive, otherwise the tier's default.
+func ContextBudgetFor(tier models.Tier, override int) int {
+	if override > 0 {
+		return override
+	}
+	if budget, ok := DefaultContextBudgets[tier]; ok {
+		return budget
+	}
+	return DefaultContextBudgets[models.TierBuilder]
+}
+
+// EstimateTokens estimates the tokens of s at four characters per token.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Pack chooses the items that fit the budget. Items are taken in priority
+// order; one that does not fit is truncated if it is truncatable and enough
+// budget remains, otherwise dropped, and packing continues with the next.
+func (b *ContextBudgeter) Pack(items []ContextItem) *ContextPack {
+	ranked := make([]ContextItem, len(items))
+	copy(ranked, items)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return contextPriority[ranked[i].Kind] < contextPriority[ranked[j].Kind]
+	})
+
+	pack := &ContextPack{Budget: b.budget}
+	for _, item := range ranked {
+		tokens := EstimateTokens(item.Content)
+		if b.budget <= 0 || pack.Tokens+tokens <= b.budget {
+			pack.Items = append(pack.Items, item)
+			pack.Tokens += tokens
+			continue
+		}
+
+		remaining := b.budget - pack.Tokens
+		if item.Truncatable && remaining >= minTruncatedTokens {
+			item.Content = truncateToTokens(item.Content, remaining)
+			pack.Items = append(pack.Items, item)
+			pack.Tokens += EstimateTokens(item.Content)
+			pack.Dropped = append(pack.Dropped, DroppedContext{Kind: item.Kind, Key: item.Key, Tokens: tokens, Truncated: true})
+			continue
+		}
+		pack.Dropped = append(pack.Dropped, DroppedContext{Kind: item.Kind, Key: item.Key, Tokens: tokens})
+	}
+	return pack
+}
+
+// truncateToTokens cuts s, ending it with truncatedMarker, so that it
+// estimates to at most tokens. It cuts at the last line break when one is
+// near the end, and never inside a UTF-8 sequence.
+func truncateToTokens(s string, tokens int) string {
+	limit := tokens*4 - len(truncatedMarker)
+	if limit <= 0 {
+		return ""
+	}
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	cut := s[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > limit*3/4 {
+		cut = cut[:i]
+	}
+	return cut + truncatedMarker
+}
+
+// usedLearnings returns the IDs of the learnings kept in pack, in the order
+// they were retrieved.
+func usedLearnings(learnings []*learning.Learning, pack *ContextPack) []string {
+	kept := make(map[string]bool)
+	for _, item := range pack.Items {
+		if item.Kind == ContextLearning || item.Kind == ContextAvoid {
+			kept[item.Key] = true
+		}
+	}
+	var ids []string
+	for _, l := range learnings {
+		if kept[l.ID] {
+			ids = append(ids, l.ID)
+		}
+	}
+	return ids
+}
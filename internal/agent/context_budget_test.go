@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// tokensOf returns content estimating to n tokens.
+func tokensOf(n int) string {
+	return strings.Repeat("abcd", n)
+}
+
+func TestContextBudgeter_Pack_DropsLowestPriorityFirst(t *testing.T) {
+	items := []ContextItem{
+		{Kind: ContextStructure, Key: "cmd/*", Content: tokensOf(30)},
+		{Kind: ContextLearning, Key: "l-1", Content: tokensOf(30)},
+		{Kind: ContextLearning, Key: "l-2", Content: tokensOf(30)},
+		{Kind: ContextFailure, Key: "attempt-1", Content: tokensOf(30)},
+	}
+
+	pack := NewContextBudgeter(90).Pack(items)
+
+	var kept []string
+	for _, item := range pack.Items {
+		kept = append(kept, item.Key)
+	}
+	if want := []string{"attempt-1", "l-1", "l-2"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %v, want %v", kept, want)
+	}
+	if want := []DroppedContext{{Kind: ContextStructure, Key: "cmd/*", Tokens: 30}}; !reflect.DeepEqual(pack.Dropped, want) {
+		t.Errorf("Dropped = %v, want %v", pack.Dropped, want)
+	}
+	if pack.Tokens != 90 || pack.Budget != 90 {
+		t.Errorf("Tokens = %d, Budget = %d, want 90, 90", pack.Tokens, pack.Budget)
+	}
+}
+
+func TestContextBudgeter_Pack_Deterministic(t *testing.T) {
+	a := ContextItem{Kind: ContextAvoid, Key: "a", Content: tokensOf(40)}
+	b := ContextItem{Kind: ContextLearning, Key: "b", Content: tokensOf(40)}
+	c := ContextItem{Kind: ContextLearning, Key: "c", Content: tokensOf(40)}
+
+	first := NewContextBudgeter(80).Pack([]ContextItem{c, b, a})
+	second := NewContextBudgeter(80).Pack([]ContextItem{b, a, c})
+
+	// Kinds rank first; within a kind the given order decides
+	if first.Items[0].Key != "a" || second.Items[0].Key != "a" {
+		t.Errorf("avoid learning not ranked first: %v, %v", first.Items, second.Items)
+	}
+	if first.Dropped[0].Key != "b" || second.Dropped[0].Key != "c" {
+		t.Errorf("Dropped = %v, %v, want the later learning of each", first.Dropped, second.Dropped)
+	}
+}
+
+func TestContextBudgeter_Pack_TruncatesTruncatable(t *testing.T) {
+	failure := strings.Repeat("error line\n", 200)
+	items := []ContextItem{
+		{Kind: ContextFailure, Key: "attempt-2", Content: failure, Truncatable: true},
+		{Kind: ContextLearning, Key: "l-1", Content: tokensOf(10)},
+	}
+
+	pack := NewContextBudgeter(100).Pack(items)
+
+	if len(pack.Items) != 1 || pack.Items[0].Key != "attempt-2" {
+		t.Fatalf("Items = %v, want only the truncated failure", pack.Items)
+	}
+	got := pack.Items[0].Content
+	if !strings.HasSuffix(got, truncatedMarker) || !strings.HasPrefix(got, "error line\n") {
+		t.Errorf("truncated content = %q", got)
+	}
+	if EstimateTokens(got) > 100 || pack.Tokens > 100 {
+		t.Errorf("truncated to %d tokens, over the budget of 100", EstimateTokens(got))
+	}
+	if len(pack.Dropped) != 2 || !pack.Dropped[0].Truncated || pack.Dropped[1].Truncated {
+		t.Errorf("Dropped = %v, want the failure truncated and the learning dropped", pack.Dropped)
+	}
+}
+
+func TestContextBudgeter_Pack_Unlimited(t *testing.T) {
+	items := []ContextItem{{Kind: ContextLearning, Content: tokensOf(10000)}}
+	if pack := NewContextBudgeter(0).Pack(items); len(pack.Items) != 1 || len(pack.Dropped) != 0 {
+		t.Errorf("budget 0 should keep everything, got %+v", pack)
+	}
+}
+
+func TestContextBudgetFor(t *testing.T) {
+	if got := ContextBudgetFor(models.TierScout, 0); got != DefaultContextBudgets[models.TierScout] {
+		t.Errorf("ContextBudgetFor(scout, 0) = %d", got)
+	}
+	if got := ContextBudgetFor(models.TierScout, 123); got != 123 {
+		t.Errorf("ContextBudgetFor(scout, 123) = %d, want 123", got)
+	}
+}
+
+func TestExecutor_BuildPrompt_ContextBudget(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "executor-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := initTestGitRepo(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	executor, err := NewExecutor(ExecutorConfig{RepoPath: tmpDir, RunnerFactory: testRunnerFactory()})
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+
+	task := &models.Task{
+		ID:             "task-1",
+		Title:          "Task",
+		ExecutionCount: 1,
+		LastFailure:    "go test ./... exited 1",
+	}
+	var learnings []*learning.Learning
+	for i := 0; i < 20; i++ {
+		learnings = append(learnings, &learning.Learning{
+			ID:        fmt.Sprintf("l-%02d", i),
+			Condition: fmt.Sprintf("condition %02d %s", i, tokensOf(10)),
+			Action:    "action",
+			Outcome:   "outcome",
+		})
+	}
+
+	prompt, pack := executor.buildPromptWithContext(task, models.TierBuilder, &ExecuteOptions{
+		Learnings:     learnings,
+		ContextBudget: 200,
+	})
+
+	if !strings.Contains(prompt, "go test ./... exited 1") {
+		t.Error("failure context should outrank learnings")
+	}
+	if !strings.Contains(prompt, "condition 00") {
+		t.Error("first learning should fit the budget")
+	}
+	if strings.Contains(prompt, "condition 19") {
+		t.Error("last learning should be dropped")
+	}
+	if len(pack.Dropped) == 0 || pack.Dropped[len(pack.Dropped)-1].Key != "l-19" {
+		t.Errorf("Dropped = %v, want it to end with l-19", pack.Dropped)
+	}
+
+	used := usedLearnings(learnings, pack)
+	if len(used) == 0 || len(used)+len(pack.Dropped) != len(learnings) || used[0] != "l-00" {
+		t.Errorf("usedLearnings = %v, want the learnings kept in the prompt", used)
+	}
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoverageGate fails the quality gates when the test coverage of a package
+// the agent changed drops below the session baseline.
+type CoverageGate struct {
+	// MaxDrop is how many percentage points a changed package's coverage
+	// may fall below its baseline coverage.
+	MaxDrop float64
+}
+
+// coverageCheck is a CoverageGate applied to one task's changes.
+type coverageCheck struct {
+	baseline *Baseline
+	maxDrop  float64
+	changed  []string
+}
+
+// nodeCoverageKey is the coverage key of a Node project, which reports
+// coverage of the project as a whole.
+const nodeCoverageKey = "."
+
+var (
+	// goCoverageLine matches a package's line in go test -cover output,
+	// e.g. "ok  example.com/app/api 0.01s coverage: 75.0% of statements".
+	goCoverageLine = regexp.MustCompile(`^(?:ok\s+)?(\S+)\s+.*coverage: ([\d.]+)% of statements`)
+	// goCoverage matches the coverage in a go test -json output event.
+	goCoverage = regexp.MustCompile(`coverage: ([\d.]+)% of statements`)
+	// jestCoverageLine matches the totals row of jest's coverage table.
+	jestCoverageLine = regexp.MustCompile(`^All files\s*\|\s*([\d.]+)`)
+)
+
+// parseCoverage extracts coverage percentages from go test -cover or jest
+// --coverage output, keyed by Go import path or nodeCoverageKey.
+func parseCoverage(output string) map[string]float64 {
+	coverage := make(map[string]float64)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := goCoverageLine.FindStringSubmatch(line); m != nil {
+			if pct, err := strconv.ParseFloat(m[2], 64); err == nil {
+				coverage[m[1]] = pct
+			}
+		} else if m := jestCoverageLine.FindStringSubmatch(line); m != nil {
+			if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+				coverage[nodeCoverageKey] = pct
+			}
+		}
+	}
+	return coverage
+}
+
+// parseGoTestJSONCoverage extracts per-package coverage from go test -cover
+// -json output.
+func parseGoTestJSONCoverage(output []byte) map[string]float64 {
+	coverage := make(map[string]float64)
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		var event struct {
+			Action  string `json:"Action"`
+			Package string `json:"Package"`
+			Output  string `json:"Output"`
+		}
+		if json.Unmarshal(line, &event) != nil || event.Action != "output" || event.Package == "" {
+			continue
+		}
+		if m := goCoverage.FindStringSubmatch(event.Output); m != nil {
+			if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+				coverage[event.Package] = pct
+			}
+		}
+	}
+	return coverage
+}
+
+// changedCoverageKeys returns the coverage keys of the packages holding the
+// changed files: Go import paths in a Go module, nodeCoverageKey for a
+// Node project with changed JavaScript or TypeScript files.
+func changedCoverageKeys(workDir string, changed []string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	module := goModulePath(workDir)
+	for _, f := range changed {
+		f = filepath.ToSlash(f)
+		switch path.Ext(f) {
+		case ".go":
+			if module == "" {
+				continue
+			}
+			if dir := path.Dir(f); dir == "." {
+				add(module)
+			} else {
+				add(module + "/" + dir)
+			}
+		case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+			add(nodeCoverageKey)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// goModulePath returns the module path declared in workDir's go.mod.
+func goModulePath(workDir string) string {
+	data, err := os.ReadFile(filepath.Join(workDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// runCoverage compares the coverage the test gate reported with the
+// baseline for each changed package. Packages the baseline or the test run
+// have no coverage for are not compared.
+func (q *QualityGates) runCoverage(tests *GateOutput) *GateOutput {
+	output := &GateOutput{Gate: "coverage"}
+	if q.coverage.baseline == nil || len(q.coverage.baseline.Coverage) == 0 {
+		output.Result = GateSkip
+		output.Output = "No baseline coverage"
+		return output
+	}
+	if tests == nil || tests.Result == GateSkip {
+		output.Result = GateSkip
+		output.Output = "Tests did not run"
+		return output
+	}
+
+	current := parseCoverage(tests.Output)
+	var drops, compared []string
+	for _, key := range changedCoverageKeys(q.workDir, q.coverage.changed) {
+		before, ok := q.coverage.baseline.Coverage[key]
+		after, ok2 := current[key]
+		if !ok || !ok2 {
+			continue
+		}
+		line := fmt.Sprintf("%s: %.1f%% -> %.1f%%", key, before, after)
+		compared = append(compared, line)
+		if before-after > q.coverage.maxDrop {
+			drops = append(drops, fmt.Sprintf("%s (-%.1f, max drop %.1f)", line, before-after, q.coverage.maxDrop))
+		}
+	}
+
+	switch {
+	case len(drops) > 0:
+		output.Result = GateFail
+		output.Output = "Coverage dropped:\n" + strings.Join(drops, "\n")
+	case len(compared) == 0:
+		output.Result = GateSkip
+		output.Output = "No changed package has baseline coverage"
+	default:
+		output.Result = GatePass
+		output.Output = strings.Join(compared, "\n")
+	}
+	return output
+}
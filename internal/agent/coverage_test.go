@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCoverage(t *testing.T) {
+	output := "ok  \texample.com/app/api\t0.012s\tcoverage: 75.0% of statements\n" +
+		"ok  \texample.com/app/store\t(cached)\tcoverage: 62.5% of statements\n" +
+		"\texample.com/app/cmd\t\tcoverage: 0.0% of statements\n" +
+		"FAIL\texample.com/app/web [build failed]\n" +
+		"All files |   81.25 |    70 |   90 |   81.25 |\n"
+
+	got := parseCoverage(output)
+	want := map[string]float64{
+		"example.com/app/api":   75,
+		"example.com/app/store": 62.5,
+		"example.com/app/cmd":   0,
+		nodeCoverageKey:         81.25,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCoverage() = %v, want %v", got, want)
+	}
+}
+
+func TestParseGoTestJSONCoverage(t *testing.T) {
+	output := []byte(`{"Action":"output","Package":"example.com/app/api","Output":"coverage: 75.0% of statements\n"}
+{"Action":"output","Package":"example.com/app/api","Output":"ok  \texample.com/app/api\t0.01s\tcoverage: 75.0% of statements\n"}
+{"Action":"pass","Package":"example.com/app/api"}
+{"Action":"output","Package":"example.com/app/cmd","Output":"\texample.com/app/cmd\t\tcoverage: 0.0% of statements\n"}`)
+
+	got := parseGoTestJSONCoverage(output)
+	want := map[string]float64{"example.com/app/api": 75, "example.com/app/cmd": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoTestJSONCoverage() = %v, want %v", got, want)
+	}
+}
+
+func TestChangedCoverageKeys(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := changedCoverageKeys(dir, []string{"main.go", "api/handler.go", "api/handler_test.go", "web/app.ts", "README.md"})
+	want := []string{nodeCoverageKey, "example.com/app", "example.com/app/api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedCoverageKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestRunCoverage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseline := &Baseline{Coverage: map[string]float64{
+		"example.com/app/api":   80,
+		"example.com/app/store": 60,
+	}}
+	tests := &GateOutput{Gate: "test", Result: GatePass, Output: "ok  \texample.com/app/api\t0.01s\tcoverage: 71.0% of statements\n" +
+		"ok  \texample.com/app/store\t0.01s\tcoverage: 58.0% of statements\n"}
+
+	q := NewQualityGates(dir)
+	q.SetCoverage(baseline, 5, []string{"api/handler.go", "store/db.go"})
+	got := q.runCoverage(tests)
+	if got.Result != GateFail || !strings.Contains(got.Output, "example.com/app/api: 80.0% -> 71.0%") || strings.Contains(got.Output, "store") {
+		t.Errorf("expected only the api drop past 5 points to fail, got %v:\n%s", got.Result, got.Output)
+	}
+
+	q.SetCoverage(baseline, 10, []string{"api/handler.go"})
+	if got := q.runCoverage(tests); got.Result != GatePass {
+		t.Errorf("expected a drop within max_drop to pass, got %v:\n%s", got.Result, got.Output)
+	}
+
+	q.SetCoverage(baseline, 0, []string{"cmd/main.go"})
+	if got := q.runCoverage(tests); got.Result != GateSkip {
+		t.Errorf("expected packages without baseline coverage to be skipped, got %v", got.Result)
+	}
+
+	q.SetCoverage(nil, 0, []string{"api/handler.go"})
+	if got := q.runCoverage(tests); got.Result != GateSkip {
+		t.Errorf("expected no baseline to skip, got %v", got.Result)
+	}
+}
+
+func TestEvaluateGatesWithBaseline_CoverageDrop(t *testing.T) {
+	e := &Executor{}
+	results := []*GateOutput{
+		{Gate: "test", Result: GatePass},
+		{Gate: "coverage", Result: GateFail, Output: "Coverage dropped"},
+	}
+	if e.evaluateGatesWithBaseline(results, &Baseline{}) {
+		t.Error("expected a coverage drop to fail the gates")
+	}
+}
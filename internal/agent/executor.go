@@ -3,13 +3,20 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/git"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/redact"
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -42,6 +49,9 @@ type ExecutionResult struct {
 	Model string
 	// LogFile is the path to the detailed execution log.
 	LogFile string
+	// ChangedFiles lists the files the agent's commits changed, relative to
+	// the repository root. Empty if the agent did not finish.
+	ChangedFiles []string
 
 	// Learning (always populated, may be empty)
 	// SuggestedLearnings contains potential learnings extracted from failures.
@@ -63,6 +73,44 @@ type ExecutionResult struct {
 	VerifyPassed *bool
 	// VerifySummary is a human-readable summary of verification results.
 	VerifySummary string
+	// WarmUps records the warm-up hooks run in the worktree, in order.
+	WarmUps []WarmUpResult
+	// Validation records the validation layers in the order they ran.
+	Validation []ValidationOutcome
+	// LintViolations lists the violations the lint layer found that the
+	// session baseline does not have, as "file:rule: message".
+	LintViolations []string
+	// SecurityFindings lists what the security layer found, blocking or not.
+	SecurityFindings []SecurityFinding
+	// Patch holds the agent's changes when it ran in a plain workspace,
+	// which has no branch to merge. Nil for git worktrees.
+	Patch *vcs.Patch
+	// Confidence is the score that decides whether semantic review is
+	// skipped. Nil means it was not computed.
+	Confidence *float64
+	// ContextDropped lists the prompt context that was dropped or truncated
+	// to fit the tier's context budget.
+	ContextDropped []DroppedContext
+	// Questions lists the questions the agent asked.
+	Questions []string
+	// Stalled is set if the agent was stopped for showing no progress for
+	// its stall timeout.
+	Stalled bool
+	// Transcript records the prompt, steps and diff of the run for
+	// rendering as Markdown. Nil if the agent did not start.
+	Transcript *Transcript
+}
+
+// BlockingSecurityFindings returns the security findings that failed the
+// task.
+func (r *ExecutionResult) BlockingSecurityFindings() []SecurityFinding {
+	var blocking []SecurityFinding
+	for _, f := range r.SecurityFindings {
+		if f.Blocking {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
 }
 
 // AreGatesPassed returns whether quality gates passed, or true if not run.
@@ -84,6 +132,11 @@ type Executor struct {
 	model           string
 	failureAnalyzer learning.FailureAnalyzerProvider
 	taskTimeout     time.Duration
+	warmUps         []WarmUpHook
+	// flakes quarantines flaky tests across every task in the session.
+	flakes *FlakeDetector
+	// commitIdentity is who agent commits are made as; nil uses the git config.
+	commitIdentity *git.CommitIdentity
 
 	// Runner factory for creating ClaudeRunner instances (API-based)
 	runnerFactory ClaudeRunnerFactory
@@ -112,11 +165,48 @@ type ExecutorConfig struct {
 	AgentManager AgentLifecycle
 	// FailureAnalyzer is the failure analyzer. If nil, learning.NewFailureAnalyzer() is used.
 	FailureAnalyzer learning.FailureAnalyzerProvider
+
+	// WarmUps are setup commands run in each task's worktree before the
+	// agent starts and again before validation if their inputs changed.
+	WarmUps []WarmUpHook
+
+	// WorktreePoolSize is how many idle worktrees are kept for reuse by
+	// later tasks. 0 removes each worktree when its task finishes.
+	WorktreePoolSize int
+	// WorktreeStore records worktree ownership so cleanup can find the
+	// worktrees of crashed runs. Nil disables tracking.
+	WorktreeStore WorktreeOwnershipStore
+
+	// CommitIdentity sets the author, signing and message template of
+	// agent commits. Nil uses the repository's git config and the default
+	// message template.
+	CommitIdentity *git.CommitIdentity
+
+	// VCS is the workspace backend: git worktrees (the default) or plain
+	// copies of a directory that is not a git repository. vcs.KindAuto
+	// detects it from RepoPath.
+	VCS vcs.Kind
 }
 
 // NewExecutor creates a new Executor with the given configuration.
 func NewExecutor(cfg ExecutorConfig) (*Executor, error) {
-	worktreeMgr, err := NewWorktreeManager(cfg.WorktreeBaseDir, cfg.RepoPath)
+	var wtOpts []WorktreeManagerOption
+	if cfg.WorktreePoolSize > 0 {
+		wtOpts = append(wtOpts, WithPoolSize(cfg.WorktreePoolSize))
+	}
+	if cfg.WorktreeStore != nil {
+		wtOpts = append(wtOpts, WithOwnershipStore(cfg.WorktreeStore))
+	}
+	kind, err := vcs.Resolve(cfg.VCS, cfg.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	var worktreeMgr WorktreeProvider
+	if kind == vcs.KindPlain {
+		worktreeMgr, err = NewPlainWorkspaceManager(cfg.WorktreeBaseDir, cfg.RepoPath)
+	} else {
+		worktreeMgr, err = NewWorktreeManager(cfg.WorktreeBaseDir, cfg.RepoPath, wtOpts...)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("create worktree manager: %w", err)
 	}
@@ -159,10 +249,39 @@ func NewExecutor(cfg ExecutorConfig) (*Executor, error) {
 		model:           model,
 		failureAnalyzer: failureAnalyzer,
 		taskTimeout:     taskTimeout,
+		warmUps:         cfg.WarmUps,
+		flakes:          NewFlakeDetector(),
+		commitIdentity:  cfg.CommitIdentity,
 		runnerFactory:   cfg.RunnerFactory,
 	}, nil
 }
 
+// ApplyPatch applies the patch of a task run in a plain workspace to the
+// project. It fails for git worktrees, whose work is merged as a branch.
+func (e *Executor) ApplyPatch(p *vcs.Patch) error {
+	plain, ok := e.worktreeMgr.(*PlainWorkspaceManager)
+	if !ok {
+		return fmt.Errorf("apply patch: executor does not use plain workspaces")
+	}
+	return plain.ApplyPatch(p)
+}
+
+// WorktreeBaseDir returns the directory task worktrees are created in.
+func (e *Executor) WorktreeBaseDir() string {
+	return e.worktreeMgr.BaseDir()
+}
+
+// PruneWorktrees frees disk by removing idle pooled worktrees, the
+// worktrees of exited processes and merged agent branches. Worktrees of
+// running tasks are kept.
+func (e *Executor) PruneWorktrees() (*StaleCleanupReport, error) {
+	m, ok := e.worktreeMgr.(*WorktreeManager)
+	if !ok {
+		return &StaleCleanupReport{}, e.worktreeMgr.Prune()
+	}
+	return m.PruneStale(StaleCleanupOptions{DrainPool: true})
+}
+
 // ProgressUpdate contains current execution progress information.
 type ProgressUpdate struct {
 	// AgentID is the ID of the agent executing the task.
@@ -175,6 +294,8 @@ type ProgressUpdate struct {
 	Duration time.Duration
 	// CurrentAction describes what the agent is doing right now (e.g., "Reading auth.go").
 	CurrentAction string
+	// LogFile is the execution log, which receives the transcript as it streams.
+	LogFile string
 }
 
 // ProgressCallback is called periodically during task execution with progress updates.
@@ -205,6 +326,41 @@ type ExecuteOptions struct {
 	// StructureRules provides directory structure guidance to the agent.
 	// When set, the agent receives information about common directory patterns.
 	StructureRules interface{} // Uses interface{} to avoid circular dependency
+	// Validation lists the validation layers run after the agent finishes.
+	// Nil uses DefaultValidationPipeline for the tier.
+	Validation *ValidationPipeline
+	// TaskHistory is the success record of past tasks of this task's type,
+	// a signal of the confidence score.
+	TaskHistory TaskHistory
+	// ProtectedAreas detects changed files in protected areas, a signal of
+	// the confidence score. Nil uses protect.New().
+	ProtectedAreas *protect.Detector
+	// Sandbox runs quality gate and verification commands, e.g. in a
+	// container. Nil runs them on the host.
+	Sandbox iexec.CommandRunner
+	// Model overrides the model SelectModel would choose, e.g. for a task
+	// escalated to a higher tier. Empty selects the model as usual.
+	Model string
+	// SessionID is the session the task runs in, recorded in the agent's
+	// commit message.
+	SessionID string
+	// ContextBudget is the budget, in estimated tokens, of the context
+	// injected into the prompt. 0 uses DefaultContextBudgets for the tier.
+	ContextBudget int
+	// OnQuestion is called with each question the agent asks. When set,
+	// the prompt tells the agent how to ask and that it may ask
+	// QuestionsAllowed more questions (any number if negative).
+	OnQuestion       func(question string)
+	QuestionsAllowed int
+	// OnFirstCommit is called once, while the agent runs, when it makes its
+	// first commit, with the files changed since the worktree was created.
+	OnFirstCommit func(files []string)
+	// StallTimeout stops the agent when it produces no stream events for
+	// this long; 0 disables the watchdog. OnStall is called once per
+	// silence, after half the timeout, with how long the agent has been
+	// silent.
+	StallTimeout time.Duration
+	OnStall      func(idle time.Duration)
 }
 
 // Execute runs a single task with a single agent.
@@ -230,14 +386,6 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 	startTime := time.Now()
 	result := &ExecutionResult{}
 
-	// Track which learnings were used for this task (for effectiveness tracking)
-	if opts != nil && len(opts.Learnings) > 0 {
-		result.LearningsUsed = make([]string, len(opts.Learnings))
-		for i, l := range opts.Learnings {
-			result.LearningsUsed[i] = l.ID
-		}
-	}
-
 	// Apply task timeout
 	ctx, cancel := context.WithTimeout(ctx, e.taskTimeout)
 	defer cancel()
@@ -255,11 +403,26 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 		return nil, fmt.Errorf("create worktree: %w", err)
 	}
 	result.WorktreePath = worktree.Path
+	baseCommit := headCommit(worktree.Path)
 
 	// Ensure cleanup happens regardless of outcome
 	defer func() {
-		// Force remove the worktree on cleanup
-		_ = e.worktreeMgr.Remove(worktree.Path, true)
+		// Remove the task's container before its worktree
+		if opts != nil {
+			if releaser, ok := opts.Sandbox.(iexec.WorkDirReleaser); ok {
+				_ = releaser.ReleaseWorkDir(worktree.Path)
+			}
+		}
+		// Keep a suspended agent's work on its branch for the next attempt
+		if errors.Is(context.Cause(ctx), ErrSuspended) {
+			var sessionID string
+			if opts != nil {
+				sessionID = opts.SessionID
+			}
+			e.checkpointWorktree(worktree, task, sessionID)
+		}
+		// Return the worktree to the pool, or force remove it
+		_ = e.worktreeMgr.Release(worktree)
 	}()
 
 	// 2. Create agent and token tracker
@@ -276,13 +439,48 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 
 	// Select model dynamically based on task keywords and tier
 	selectedModel := SelectModel(task, tier)
+	if opts != nil && opts.Model != "" {
+		selectedModel = opts.Model
+	}
 	result.Model = selectedModel
 	tracker := NewTokenTracker(selectedModel)
 	e.tokenTracker.Add(agent.ID, tracker)
 	defer e.tokenTracker.Remove(agent.ID)
 
-	// 3. Build the prompt from task
-	prompt := e.buildPrompt(task, tier, opts)
+	// Stream the transcript to the log file so it can be followed live
+	liveLog := startLogFile(logFile, task, tier, selectedModel, startTime)
+	defer func() {
+		if liveLog != nil {
+			_ = liveLog.Close()
+		}
+	}()
+	logged := 0
+
+	// 2b. Warm up the fresh worktree so builds work before the agent starts
+	warmUp := newWorktreeWarmUp(e.warmUps, worktree.Path)
+	if len(e.warmUps) > 0 {
+		warmUpResults, err := warmUp.Run(ctx, WarmUpBeforeAgent, task.FileBoundaries)
+		result.WarmUps = append(result.WarmUps, warmUpResults...)
+		if err != nil {
+			_ = e.agentMgr.Fail(agent.ID, err.Error())
+			return nil, fmt.Errorf("warm up worktree: %w", err)
+		}
+	}
+
+	// 3. Build the prompt from task, fitting its context to the tier's budget
+	prompt, pack := e.buildPromptWithContext(task, tier, opts)
+	result.ContextDropped = pack.Dropped
+	for _, d := range pack.Dropped {
+		log.Printf("[agent] task %s: %s to fit the context budget of %d tokens", task.ID, d, pack.Budget)
+	}
+
+	// Track which learnings made it into the prompt (for effectiveness tracking)
+	if opts != nil {
+		result.LearningsUsed = usedLearnings(opts.Learnings, pack)
+	}
+
+	transcript := &Transcript{Prompt: prompt}
+	result.Transcript = transcript
 
 	// Declare variables used across both pre-impl contract and execution
 	var proc ClaudeRunner
@@ -292,7 +490,13 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 
 	// 3b. Generate draft verification contract BEFORE implementation
 	// This establishes minimum verification requirements that cannot be weakened
-	verifyCtx := e.generateDraftContract(ctx, task.ID, task.VerificationIntent, task.FileBoundaries, worktree.Path)
+	verifyCtx := e.generateDraftContract(ctx, task.ID, task.VerificationIntent, task.AcceptanceCriteria, task.FileBoundaries, worktree.Path)
+
+	// Watch for the agent's first commit to report the files it touches
+	var commits *commitWatcher
+	if opts != nil && opts.OnFirstCommit != nil {
+		commits = newCommitWatcher(worktree.Path, baseCommit)
+	}
 
 	// 4. Start Claude Code process with retry logic for startup hangs
 
@@ -300,6 +504,7 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 		if attempt > 0 {
 			// Log retry attempt
 			outputBuilder.WriteString(fmt.Sprintf("\n[Retry attempt %d: previous startup timed out after %v]\n", attempt, startupTimeout))
+			transcript.note("Restarted (attempt %d): no output within %v", attempt, startupTimeout)
 			// Brief delay before retry to avoid hammering
 			select {
 			case <-ctx.Done():
@@ -331,6 +536,14 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 		lastProgressUpdate := time.Now()
 		progressInterval := 2 * time.Second
 
+		// Watch for an agent that goes silent after starting
+		var stallTimeout time.Duration
+		if opts != nil {
+			stallTimeout = opts.StallTimeout
+		}
+		beats := newHeartbeat(stallTimeout, time.Now())
+		var stalledFor time.Duration
+
 	streamLoop:
 		for {
 			select {
@@ -341,7 +554,25 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 				}
 
 				gotFirstOutput = true
+				beats.beat(time.Now())
 				e.processStreamEvent(event, tracker, &outputBuilder)
+				transcript.record(event)
+				if event.Type == StreamEventAssistant {
+					for _, q := range ExtractQuestions(event.Message) {
+						result.Questions = append(result.Questions, q)
+						if opts != nil && opts.OnQuestion != nil {
+							opts.OnQuestion(q)
+						}
+					}
+				}
+
+				if liveLog != nil {
+					_, _ = liveLog.WriteString(redact.String(outputBuilder.String()[logged:]))
+					logged = outputBuilder.Len()
+					if event.ToolAction != "" && event.ToolAction != currentAction {
+						_, _ = fmt.Fprintf(liveLog, "> %s\n", redact.String(event.ToolAction))
+					}
+				}
 
 				// Track current tool action
 				if event.ToolAction != "" {
@@ -357,10 +588,24 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 						Cost:          tracker.GetCost(),
 						Duration:      time.Since(startTime),
 						CurrentAction: currentAction,
+						LogFile:       logFile,
 					})
 					lastProgressUpdate = time.Now()
 				}
 
+				if commits != nil {
+					if files, ok := commits.check(); ok {
+						opts.OnFirstCommit(files)
+					}
+				}
+
+			case <-ctx.Done():
+				// Stopped by the orchestrator or the task timeout
+				outputBuilder.WriteString(fmt.Sprintf("\n[Stopped: %v]\n", context.Cause(ctx)))
+				transcript.note("Stopped: %v", context.Cause(ctx))
+				_ = proc.Kill()
+				break streamLoop
+
 			case <-time.After(100 * time.Millisecond):
 				// Check startup timeout only if we haven't received any output yet
 				if !gotFirstOutput && time.Now().After(startupDeadline) {
@@ -369,6 +614,21 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 					_ = proc.Kill()
 					break streamLoop
 				}
+				if !gotFirstOutput {
+					continue
+				}
+				switch idle, stall := beats.check(time.Now()); stall {
+				case StallWarn:
+					if opts.OnStall != nil {
+						opts.OnStall(idle)
+					}
+				case StallKill:
+					stalledFor = idle
+					outputBuilder.WriteString(fmt.Sprintf("\n[Stalled: no progress for %v]\n", idle.Round(time.Second)))
+					transcript.note("Stopped: no progress for %v", idle.Round(time.Second))
+					_ = proc.Kill()
+					break streamLoop
+				}
 			}
 		}
 
@@ -379,9 +639,19 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 
 		// Otherwise, we're done with retries (either success or final failure)
 		procErr = proc.Wait()
+		if stalledFor > 0 {
+			procErr = stallError(stalledFor)
+			result.Stalled = true
+		}
 		break
 	}
 
+	// The complete log is written when execution finishes
+	if liveLog != nil {
+		_ = liveLog.Close()
+		liveLog = nil
+	}
+
 	// Capture final results
 	result.Output = outputBuilder.String()
 	result.Duration = time.Since(startTime)
@@ -393,17 +663,59 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 	// Update agent with usage
 	_ = e.agentMgr.UpdateUsage(agent.ID, usage.TotalTokens, result.Cost)
 
-	// 6b. Run Ralph-loop if enabled and appropriate for tier
-	if procErr == nil {
-		e.runRalphLoopIfEnabled(ctx, result, task, tier, opts, worktree.Path, verifyCtx)
+	// 6a. Re-run warm-ups whose inputs the agent changed before validating
+	var warmUpErr error
+	if procErr == nil && len(e.warmUps) > 0 {
+		files := append(worktreeChangedFiles(worktree.Path), task.FileBoundaries...)
+		var warmUpResults []WarmUpResult
+		warmUpResults, warmUpErr = warmUp.Run(ctx, WarmUpBeforeValidation, files)
+		result.WarmUps = append(result.WarmUps, warmUpResults...)
+	}
+	if len(result.WarmUps) > 0 {
+		result.Output += formatWarmUps(result.WarmUps)
+	}
+
+	// 6b. Run the validation layers (self-critique, gates, verification) in
+	// the order configured for the tier
+	var validationErr string
+	if procErr == nil && warmUpErr == nil && ctx.Err() == nil {
+		pipeline := DefaultValidationPipeline(tier)
+		if opts != nil && opts.Validation != nil {
+			pipeline = opts.Validation
+		}
+		validationErr = e.runValidationPipeline(ctx, pipeline, &validationRun{
+			result:       result,
+			task:         task,
+			tier:         tier,
+			opts:         opts,
+			worktreePath: worktree.Path,
+			verifyCtx:    verifyCtx,
+		})
+		result.Output += formatValidation(result.Validation)
 	}
 
 	// 7. Auto-commit any changes made by the agent
 	// This ensures changes are preserved when the worktree is removed
 	if procErr == nil {
-		if err := e.autoCommitChanges(worktree.Path, task.Title); err != nil {
-			// Log but don't fail - agent might have made no changes
-			result.Output += fmt.Sprintf("\n[Auto-commit: %v]", err)
+		var sessionID string
+		if opts != nil {
+			sessionID = opts.SessionID
+		}
+		if plain, ok := e.worktreeMgr.(*PlainWorkspaceManager); ok {
+			// Plain workspaces have no branch; the changes travel as a patch
+			patch, err := plain.Patch(worktree)
+			if err != nil {
+				result.Output += fmt.Sprintf("\n[Patch: %v]", err)
+			}
+			result.Patch = patch
+			result.ChangedFiles = patch.Files()
+		} else {
+			if err := e.autoCommitChanges(worktree.Path, task.Title, task.ID, sessionID); err != nil {
+				// Log but don't fail - agent might have made no changes
+				result.Output += fmt.Sprintf("\n[Auto-commit: %v]", err)
+			}
+			result.ChangedFiles = changedFilesSince(worktree.Path, baseCommit)
+			transcript.captureDiff(worktree.Path, baseCommit)
 		}
 	}
 
@@ -415,13 +727,20 @@ func (e *Executor) ExecuteWithOptions(ctx context.Context, task *models.Task, ti
 		_ = e.agentMgr.Complete(agent.ID)
 	}
 
-	// Run quality gates if enabled and execution succeeded
-	if result.Success {
-		e.runQualityGatesIfEnabled(result, opts, worktree.Path, tier, agent.ID)
+	// A failed warm-up leaves the worktree unable to build, so validation
+	// results would be meaningless
+	if result.Success && warmUpErr != nil {
+		result.Success = false
+		result.Error = fmt.Sprintf("warm-up before validation failed: %v", warmUpErr)
+		_ = e.agentMgr.Fail(agent.ID, result.Error)
 	}
 
-	// Unified pass/fail: both verification and gates must pass
-	e.checkVerificationPassed(result, agent.ID)
+	// Unified pass/fail: every validation layer must pass
+	if result.Success && validationErr != "" {
+		result.Success = false
+		result.Error = validationErr
+		_ = e.agentMgr.Fail(agent.ID, result.Error)
+	}
 
 	// Write detailed log file
 	e.writeLogFile(logFile, task, tier, result, startTime)
@@ -1,12 +1,25 @@
 package agent
 
 import (
+	"context"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
-// runQualityGates runs tier-specific quality gates in the given work directory.
-func (e *Executor) runQualityGates(workDir string, tier models.Tier) []*GateOutput {
+// runQualityGates runs tier-specific quality gates in the given work directory,
+// through commands if set. Gate commands are stopped when ctx is done. Test
+// failures are classified against baseline so flaky and pre-existing ones
+// do not fail the test gate. With coverage, the coverage of changed
+// packages is also compared against baseline.
+func (e *Executor) runQualityGates(ctx context.Context, workDir string, tier models.Tier, commands iexec.CommandRunner, baseline *Baseline, coverage *CoverageGate) []*GateOutput {
 	gates := NewQualityGates(workDir)
+	if commands != nil {
+		gates.SetRunner(commands)
+	}
+	if e.flakes != nil {
+		gates.SetFlakeDetector(e.flakes, baseline)
+	}
 
 	// Configure gates based on tier
 	gateConfig := GateConfigForTier(tier)
@@ -14,9 +27,12 @@ func (e *Executor) runQualityGates(workDir string, tier models.Tier) []*GateOutp
 	gates.EnableBuild(gateConfig.Build)
 	gates.EnableTest(gateConfig.Test)
 	gates.EnableTypecheck(gateConfig.TypeCheck)
+	if coverage != nil {
+		gates.SetCoverage(baseline, coverage.MaxDrop, worktreeChangedFiles(workDir))
+	}
 
 	// Run the enabled gates
-	results, err := gates.RunGates()
+	results, err := gates.RunGatesContext(ctx)
 	if err != nil {
 		// Return a single error result if gate execution itself failed
 		return []*GateOutput{{
@@ -53,6 +69,10 @@ func (e *Executor) evaluateGatesWithBaseline(gateResults []*GateOutput, baseline
 		if gate.Result != GateFail && gate.Result != GateError {
 			continue
 		}
+		if gate.Gate == "coverage" {
+			// The coverage gate already compares against the baseline
+			return false
+		}
 
 		failures := parseGateOutputForFailures(gate.Gate, gate.Output)
 		switch gate.Gate {
@@ -69,10 +89,3 @@ func (e *Executor) evaluateGatesWithBaseline(gateResults []*GateOutput, baseline
 	comparison := CompareToBaseline(current, baseline)
 	return !comparison.IsRegression
 }
-
-// shouldRunRalphLoop determines if the Ralph self-critique loop should run for the given tier.
-// Scout and Quick tiers skip the loop (simple tasks don't need self-critique).
-// Builder and Architect tiers run the loop for quality improvement.
-func (e *Executor) shouldRunRalphLoop(tier models.Tier) bool {
-	return tier == models.TierBuilder || tier == models.TierArchitect
-}
@@ -7,12 +7,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ShayCichocki/alphie/internal/redact"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
 // autoCommitChanges commits any uncommitted changes in the worktree.
 // This ensures agent changes are preserved when the worktree is removed.
-func (e *Executor) autoCommitChanges(worktreePath, taskTitle string) error {
+// taskID and sessionID are recorded in the commit message if not empty.
+func (e *Executor) autoCommitChanges(worktreePath, taskTitle, taskID, sessionID string) error {
 	// Check if there are any changes to commit
 	statusCmd := exec.Command("git", "status", "--porcelain")
 	statusCmd.Dir = worktreePath
@@ -33,10 +35,8 @@ func (e *Executor) autoCommitChanges(worktreePath, taskTitle string) error {
 		return fmt.Errorf("git add: %s: %w", string(output), err)
 	}
 
-	// Commit with task title as message
-	commitMsg := fmt.Sprintf("Agent: %s", taskTitle)
-	commitCmd := exec.Command("git", "commit", "-m", commitMsg)
-	commitCmd.Dir = worktreePath
+	commitMsg := e.commitIdentity.Message("Agent: "+taskTitle, taskID, sessionID)
+	commitCmd := e.commitIdentity.Command(worktreePath, "commit", "-m", commitMsg)
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git commit: %s: %w", string(output), err)
 	}
@@ -44,6 +44,17 @@ func (e *Executor) autoCommitChanges(worktreePath, taskTitle string) error {
 	return nil
 }
 
+// checkpointWorktree commits the uncommitted work of a suspended agent to
+// its task branch, so the task's next attempt resumes from it instead of
+// starting over. Plain workspaces have no branch to keep and are skipped.
+func (e *Executor) checkpointWorktree(worktree *Worktree, task *models.Task, sessionID string) {
+	if _, ok := e.worktreeMgr.(*PlainWorkspaceManager); ok {
+		return
+	}
+	// Fails harmlessly if the agent left nothing uncommitted
+	_ = e.autoCommitChanges(worktree.Path, task.Title+" (checkpoint)", task.ID, sessionID)
+}
+
 // getModifiedFiles returns a list of files modified in the worktree since the last commit.
 // This is used to determine what files were created/modified for verification generation.
 func (e *Executor) getModifiedFiles(workDir string) []string {
@@ -71,7 +82,56 @@ func (e *Executor) getModifiedFiles(workDir string) []string {
 	return result
 }
 
-// writeLogFile writes the execution log to the specified file.
+// headCommit returns the commit checked out in the worktree, or "" if it
+// cannot be resolved.
+func headCommit(workDir string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// changedFilesSince returns the files changed by the commits made in the
+// worktree since base. Returns nil if base is empty or git fails.
+func changedFilesSince(workDir, base string) []string {
+	if base == "" {
+		return nil
+	}
+	cmd := exec.Command("git", "diff", "--name-only", base, "HEAD")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// startLogFile creates the execution log and writes its header, so the
+// transcript can be appended and followed while the agent runs. writeLogFile
+// replaces it with the complete log when execution finishes. Returns nil if
+// the file cannot be created.
+func startLogFile(logFile string, task *models.Task, tier models.Tier, model string, startTime time.Time) *os.File {
+	f, err := os.Create(logFile)
+	if err != nil {
+		return nil
+	}
+	_, _ = fmt.Fprintf(f, "Task: %s\nTask ID: %s\nTier: %s\nModel: %s\nStarted: %s\n\n--- Output ---\n",
+		redact.String(task.Title), task.ID, tier, model, startTime.Format(time.RFC3339))
+	return f
+}
+
+// writeLogFile writes the execution log to the specified file, with secrets
+// redacted.
 func (e *Executor) writeLogFile(logFile string, task *models.Task, tier models.Tier, result *ExecutionResult, startTime time.Time) {
 	var logContent strings.Builder
 	logContent.WriteString(fmt.Sprintf("Task: %s\n", task.Title))
@@ -86,8 +146,11 @@ func (e *Executor) writeLogFile(logFile string, task *models.Task, tier models.T
 	if result.Error != "" {
 		logContent.WriteString(fmt.Sprintf("Error: %s\n", result.Error))
 	}
+	for _, d := range result.ContextDropped {
+		logContent.WriteString(fmt.Sprintf("Context: %s\n", d))
+	}
 	logContent.WriteString("\n--- Output ---\n")
 	logContent.WriteString(result.Output)
 	logContent.WriteString("\n")
-	_ = os.WriteFile(logFile, []byte(logContent.String()), 0644)
+	_ = os.WriteFile(logFile, []byte(redact.String(logContent.String())), 0644)
 }
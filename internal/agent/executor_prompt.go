@@ -4,11 +4,27 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ShayCichocki/alphie/internal/learning"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
 // buildPrompt constructs the prompt for the Claude Code agent.
 func (e *Executor) buildPrompt(task *models.Task, tier models.Tier, opts *ExecuteOptions) string {
+	prompt, _ := e.buildPromptWithContext(task, tier, opts)
+	return prompt
+}
+
+// buildPromptWithContext constructs the prompt for the Claude Code agent
+// and returns the context packed into it. Learnings, failure context,
+// acceptance criteria, protected-area notes and structure conventions are
+// fitted to the tier's context budget; the task itself always goes in.
+func (e *Executor) buildPromptWithContext(task *models.Task, tier models.Tier, opts *ExecuteOptions) (string, *ContextPack) {
+	var budget int
+	if opts != nil {
+		budget = opts.ContextBudget
+	}
+	pack := NewContextBudgeter(ContextBudgetFor(tier, budget)).Pack(promptContext(task, opts))
+
 	var sb strings.Builder
 
 	// Inject scope guidance at task start to prevent scope creep
@@ -33,6 +49,14 @@ func (e *Executor) buildPrompt(task *models.Task, tier models.Tier, opts *Execut
 		sb.WriteString("\n")
 	}
 
+	if spec := pack.Of(ContextSpec); len(spec) > 0 {
+		sb.WriteString("\n## Acceptance Criteria\n\n")
+		for _, item := range spec {
+			sb.WriteString(item.Content)
+			sb.WriteString("\n")
+		}
+	}
+
 	// Add file boundary constraints if specified
 	if len(task.FileBoundaries) > 0 {
 		sb.WriteString("\n## CRITICAL: File Boundary Constraints\n\n")
@@ -47,39 +71,29 @@ func (e *Executor) buildPrompt(task *models.Task, tier models.Tier, opts *Execut
 		sb.WriteString("Violating these constraints will cause verification to fail.\n")
 	}
 
-	// Add directory structure guidance if available
-	if opts != nil && opts.StructureRules != nil {
-		// Type assert to get rules with a method-based interface to avoid import cycles
-		type ruleGetter interface {
-			GetPattern() string
-			GetDescription() string
-			GetExamples() []string
-		}
-		type ruleProvider interface {
-			GetRulesForPath([]string) []ruleGetter
+	if protected := pack.Of(ContextProtected); len(protected) > 0 {
+		sb.WriteString("\n## Protected Areas\n\n")
+		sb.WriteString("These boundaries are protected areas. Changes to them get extra review; keep them minimal and deliberate:\n\n")
+		for _, item := range protected {
+			sb.WriteString(item.Content)
 		}
+	}
 
-		if provider, ok := opts.StructureRules.(ruleProvider); ok {
-			relevantRules := provider.GetRulesForPath(task.FileBoundaries)
-			if len(relevantRules) > 0 {
-				sb.WriteString("\n## Project Structure Conventions\n\n")
-				sb.WriteString("This repository follows these directory patterns:\n\n")
-
-				for _, rule := range relevantRules {
-					sb.WriteString(fmt.Sprintf("- **%s**: `%s`\n", rule.GetDescription(), rule.GetPattern()))
-					examples := rule.GetExamples()
-					if len(examples) > 0 {
-						// Show up to 3 examples
-						displayExamples := examples
-						if len(displayExamples) > 3 {
-							displayExamples = displayExamples[:3]
-						}
-						sb.WriteString(fmt.Sprintf("  Examples: %s\n", strings.Join(displayExamples, ", ")))
-					}
-				}
-				sb.WriteString("\n**IMPORTANT**: Follow these patterns when creating new files.\n")
-			}
+	// Add directory structure guidance if available
+	if rules := pack.Of(ContextStructure); len(rules) > 0 {
+		sb.WriteString("\n## Project Structure Conventions\n\n")
+		sb.WriteString("This repository follows these directory patterns:\n\n")
+		for _, item := range rules {
+			sb.WriteString(item.Content)
 		}
+		sb.WriteString("\n**IMPORTANT**: Follow these patterns when creating new files.\n")
+	}
+
+	// Tell retried tasks what went wrong last time
+	if failure := pack.Of(ContextFailure); len(failure) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## Previous Attempt Failed (attempt %d)\n\n", task.ExecutionCount))
+		sb.WriteString(failure[0].Content)
+		sb.WriteString("\n\nAddress these problems in this attempt. Do not repeat the same approach if it caused the failure.\n")
 	}
 
 	sb.WriteString("\nTier: ")
@@ -96,20 +110,126 @@ func (e *Executor) buildPrompt(task *models.Task, tier models.Tier, opts *Execut
 		sb.WriteString("\nYou are operating as an Architect agent. Focus on complex design, architecture, and system-level decisions.\n")
 	}
 
-	// Inject relevant learnings if available
-	if opts != nil && len(opts.Learnings) > 0 {
+	// Inject relevant learnings if available, keeping known-bad approaches
+	// in their own section so they read as warnings rather than advice
+	if follow := pack.Of(ContextLearning); len(follow) > 0 {
 		sb.WriteString("\n## Relevant Learnings\n")
 		sb.WriteString("The following learnings from previous experiences may be helpful:\n\n")
-		for i, l := range opts.Learnings {
+		for i, item := range follow {
 			sb.WriteString(fmt.Sprintf("### Learning %d\n", i+1))
-			sb.WriteString(fmt.Sprintf("- **When**: %s\n", l.Condition))
-			sb.WriteString(fmt.Sprintf("- **Do**: %s\n", l.Action))
-			sb.WriteString(fmt.Sprintf("- **Result**: %s\n", l.Outcome))
+			sb.WriteString(item.Content)
 			sb.WriteString("\n")
 		}
 	}
+	if avoid := pack.Of(ContextAvoid); len(avoid) > 0 {
+		sb.WriteString("\n## Approaches to Avoid\n")
+		sb.WriteString("These approaches were tried before in similar situations and failed. Do not repeat them; choose a different approach:\n\n")
+		for _, item := range avoid {
+			sb.WriteString(item.Content)
+		}
+	}
+
+	if opts != nil && opts.OnQuestion != nil {
+		sb.WriteString(questionGuidance(opts.QuestionsAllowed))
+	}
 
 	sb.WriteString("\nPlease complete this task. When finished, provide a summary of what was done.\n")
 
-	return sb.String()
+	return sb.String(), pack
+}
+
+// promptContext collects the context a prompt may include, for the
+// ContextBudgeter to rank and fit.
+func promptContext(task *models.Task, opts *ExecuteOptions) []ContextItem {
+	var items []ContextItem
+
+	// Retried tasks carry what went wrong last time
+	if task.ExecutionCount > 0 && task.LastFailure != "" {
+		items = append(items, ContextItem{
+			Kind:        ContextFailure,
+			Key:         fmt.Sprintf("attempt-%d", task.ExecutionCount),
+			Content:     task.LastFailure,
+			Truncatable: true,
+		})
+	}
+
+	if task.AcceptanceCriteria != "" {
+		items = append(items, ContextItem{
+			Kind:        ContextSpec,
+			Key:         "acceptance-criteria",
+			Content:     task.AcceptanceCriteria,
+			Truncatable: true,
+		})
+	}
+
+	if opts == nil {
+		return items
+	}
+
+	if opts.ProtectedAreas != nil {
+		for _, boundary := range task.FileBoundaries {
+			if ok, reason := opts.ProtectedAreas.IsProtectedWithReason(boundary); ok {
+				items = append(items, ContextItem{
+					Kind:    ContextProtected,
+					Key:     boundary,
+					Content: fmt.Sprintf("- `%s`: %s\n", boundary, reason),
+				})
+			}
+		}
+	}
+
+	follow, avoid := learning.SplitByOutcome(opts.Learnings)
+	for _, l := range avoid {
+		items = append(items, ContextItem{
+			Kind:    ContextAvoid,
+			Key:     l.ID,
+			Content: fmt.Sprintf("- **When**: %s\n  **Avoid**: %s\n  **Because**: %s\n", l.Condition, l.Action, l.Outcome),
+		})
+	}
+	for _, l := range follow {
+		items = append(items, ContextItem{
+			Kind:    ContextLearning,
+			Key:     l.ID,
+			Content: fmt.Sprintf("- **When**: %s\n- **Do**: %s\n- **Result**: %s\n", l.Condition, l.Action, l.Outcome),
+		})
+	}
+
+	return append(items, structureContext(task, opts.StructureRules)...)
+}
+
+// structureContext returns the directory conventions that apply to the
+// task's file boundaries.
+func structureContext(task *models.Task, structureRules interface{}) []ContextItem {
+	if structureRules == nil {
+		return nil
+	}
+
+	// Type assert to get rules with a method-based interface to avoid import cycles
+	type ruleGetter interface {
+		GetPattern() string
+		GetDescription() string
+		GetExamples() []string
+	}
+	type ruleProvider interface {
+		GetRulesForPath([]string) []ruleGetter
+	}
+
+	provider, ok := structureRules.(ruleProvider)
+	if !ok {
+		return nil
+	}
+
+	var items []ContextItem
+	for _, rule := range provider.GetRulesForPath(task.FileBoundaries) {
+		content := fmt.Sprintf("- **%s**: `%s`\n", rule.GetDescription(), rule.GetPattern())
+		if examples := rule.GetExamples(); len(examples) > 0 {
+			// Show up to 3 examples
+			if len(examples) > 3 {
+				examples = examples[:3]
+			}
+			content += fmt.Sprintf("  Examples: %s\n", strings.Join(examples, ", "))
+		}
+		items = append(items, ContextItem{Kind: ContextStructure, Key: rule.GetPattern(), Content: content})
+	}
+	return items
 }
@@ -8,9 +8,9 @@ import (
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
-// runRalphLoopIfEnabled runs the Ralph self-critique loop if conditions are met.
-// It returns the modified output and updates the result with loop outcomes.
-func (e *Executor) runRalphLoopIfEnabled(
+// runRalphLoop runs the Ralph self-critique loop (the review validation layer).
+// It updates the result with the modified output and loop outcomes.
+func (e *Executor) runRalphLoop(
 	ctx context.Context,
 	result *ExecutionResult,
 	task *models.Task,
@@ -19,12 +19,11 @@ func (e *Executor) runRalphLoopIfEnabled(
 	worktreePath string,
 	verifyCtx *verificationContext,
 ) {
-	if opts == nil || !opts.EnableRalphLoop || !e.shouldRunRalphLoop(tier) {
-		return
-	}
-
 	ralphLoop := NewRalphLoop(tier, worktreePath)
 	ralphLoop.SetRunnerFactory(e.runnerFactory)
+	if opts.Sandbox != nil {
+		ralphLoop.SetCommandRunner(opts.Sandbox)
+	}
 
 	// Enable gates based on tier for the ralph loop's internal gate checks
 	gateConfig := GateConfigForTier(tier)
@@ -45,10 +44,13 @@ func (e *Executor) runRalphLoopIfEnabled(
 	if opts.Baseline != nil {
 		ralphLoop.SetBaseline(opts.Baseline)
 	}
+	if e.flakes != nil {
+		ralphLoop.SetFlakeDetector(e.flakes)
+	}
 
 	// Generate verification contract using draft→refine flow
 	// Draft was generated pre-implementation; now refine post-implementation
-	if task.VerificationIntent != "" {
+	if task.VerificationIntent != "" || verifyCtx.draftContract != nil {
 		modifiedFiles := e.getModifiedFiles(worktreePath)
 		finalContract := e.refineVerificationContract(ctx, verifyCtx, task.ID, task.VerificationIntent, modifiedFiles, worktreePath)
 		result.Output += verifyCtx.output.String()
@@ -112,35 +114,22 @@ func (e *Executor) handleExecutionFailure(
 	}
 }
 
-// runQualityGatesIfEnabled runs quality gates and updates result accordingly.
+// runQualityGatesIfEnabled runs quality gates (the gates validation layer),
+// records whether they passed and returns it.
 func (e *Executor) runQualityGatesIfEnabled(
+	ctx context.Context,
 	result *ExecutionResult,
 	opts *ExecuteOptions,
 	worktreePath string,
 	tier models.Tier,
-	agentID string,
-) {
+	coverage *CoverageGate,
+) bool {
 	if opts == nil || !opts.EnableQualityGates {
-		return
+		return true
 	}
 
-	gateResults := e.runQualityGates(worktreePath, tier)
+	gateResults := e.runQualityGates(ctx, worktreePath, tier, opts.Sandbox, opts.Baseline, coverage)
 	passed := e.evaluateGatesWithBaseline(gateResults, opts.Baseline)
 	result.GatesPassed = &passed
-
-	// Gates failing now blocks task completion
-	if !passed {
-		result.Success = false
-		result.Error = "quality gates failed (regression detected or new failures)"
-		_ = e.agentMgr.Fail(agentID, result.Error)
-	}
-}
-
-// checkVerificationPassed checks if verification passed and updates result if failed.
-func (e *Executor) checkVerificationPassed(result *ExecutionResult, agentID string) {
-	if result.Success && !result.IsVerified() {
-		result.Success = false
-		result.Error = "verification contract failed"
-		_ = e.agentMgr.Fail(agentID, result.Error)
-	}
+	return passed
 }
@@ -1,11 +1,13 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -247,6 +249,40 @@ func TestExecutor_BuildPrompt_WithDescription(t *testing.T) {
 	}
 }
 
+func TestExecutor_BuildPrompt_WithLastFailure(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "executor-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := initTestGitRepo(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	executor, err := NewExecutor(ExecutorConfig{RepoPath: tmpDir, RunnerFactory: testRunnerFactory()})
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+
+	task := &models.Task{
+		ID:          "task-123",
+		Title:       "Fix the bug",
+		LastFailure: "verification failed: go test ./... exited 1",
+	}
+
+	// First attempt: no failure context even if the field is set
+	if prompt := executor.buildPrompt(task, models.TierBuilder, nil); strings.Contains(prompt, "Previous Attempt Failed") {
+		t.Error("First attempt should not include failure context")
+	}
+
+	task.ExecutionCount = 1
+	prompt := executor.buildPrompt(task, models.TierBuilder, nil)
+	if !strings.Contains(prompt, "Previous Attempt Failed") || !strings.Contains(prompt, "go test ./... exited 1") {
+		t.Error("Retried task prompt should include the previous failure")
+	}
+}
+
 func TestExecutor_BuildPrompt_TierGuidance(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "executor-test-*")
 	if err != nil {
@@ -325,6 +361,48 @@ func TestExecutor_BuildPrompt_WithLearnings(t *testing.T) {
 	}
 }
 
+func TestExecutor_BuildPrompt_WithAntiPatterns(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "executor-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := initTestGitRepo(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	executor, err := NewExecutor(ExecutorConfig{RepoPath: tmpDir, RunnerFactory: testRunnerFactory()})
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+
+	task := &models.Task{ID: "task-1", Title: "Task"}
+
+	opts := &ExecuteOptions{
+		Learnings: []*learning.Learning{
+			{
+				Condition:   "working on auth middleware",
+				Action:      "mock the token store globally",
+				Outcome:     "failure: race detected",
+				OutcomeType: learning.OutcomeFailure,
+			},
+		},
+	}
+
+	prompt := executor.buildPrompt(task, models.TierBuilder, opts)
+
+	if strings.Contains(prompt, "Relevant Learnings") {
+		t.Error("Anti-patterns should not be listed as learnings to follow")
+	}
+	if !strings.Contains(prompt, "Approaches to Avoid") {
+		t.Error("Prompt should contain the approaches to avoid section")
+	}
+	if !strings.Contains(prompt, "**Avoid**: mock the token store globally") {
+		t.Error("Prompt should contain the failed approach")
+	}
+}
+
 func TestExecutor_ProcessStreamEvent_Assistant(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "executor-test-*")
 	if err != nil {
@@ -525,7 +603,7 @@ func TestExecutor_AutoCommitChanges_NoChanges(t *testing.T) {
 		t.Fatalf("NewExecutor failed: %v", err)
 	}
 
-	err = executor.autoCommitChanges(tmpDir, "test task")
+	err = executor.autoCommitChanges(tmpDir, "test task", "", "")
 
 	if err == nil {
 		t.Error("Expected error for no changes")
@@ -557,7 +635,7 @@ func TestExecutor_AutoCommitChanges_WithChanges(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	err = executor.autoCommitChanges(tmpDir, "add new file")
+	err = executor.autoCommitChanges(tmpDir, "add new file", "", "")
 
 	if err != nil {
 		t.Fatalf("autoCommitChanges failed: %v", err)
@@ -576,6 +654,45 @@ func TestExecutor_AutoCommitChanges_WithChanges(t *testing.T) {
 	}
 }
 
+func TestChangedFilesSince(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := initTestGitRepo(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	executor, err := NewExecutor(ExecutorConfig{RepoPath: tmpDir, RunnerFactory: testRunnerFactory()})
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+
+	base := headCommit(tmpDir)
+	if base == "" {
+		t.Fatal("headCommit returned no commit")
+	}
+	if files := changedFilesSince(tmpDir, base); len(files) != 0 {
+		t.Errorf("changedFilesSince() = %v before any commit, want none", files)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"auth/login.go", "auth/login_test.go"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("package auth\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := executor.autoCommitChanges(tmpDir, "add login", "", ""); err != nil {
+		t.Fatalf("autoCommitChanges failed: %v", err)
+	}
+
+	files := changedFilesSince(tmpDir, base)
+	if len(files) != 2 || files[0] != "auth/login.go" || files[1] != "auth/login_test.go" {
+		t.Errorf("changedFilesSince() = %v, want the committed files", files)
+	}
+	if files := changedFilesSince(tmpDir, ""); files != nil {
+		t.Errorf("changedFilesSince() without a base = %v, want nil", files)
+	}
+}
+
 func TestExecutorConfig_Fields(t *testing.T) {
 	cfg := ExecutorConfig{
 		WorktreeBaseDir: "/tmp/worktrees",
@@ -655,3 +772,71 @@ func initTestGitRepo(dir string) error {
 	commitCmd.Dir = dir
 	return commitCmd.Run()
 }
+
+// suspendRunner writes a file into the worktree and then runs until killed,
+// like an agent stopped partway through its task.
+type suspendRunner struct {
+	outputCh chan StreamEvent
+	started  chan struct{}
+	killOnce sync.Once
+}
+
+func (r *suspendRunner) Start(prompt, workDir string) error {
+	return r.StartWithOptions(prompt, workDir, nil)
+}
+func (r *suspendRunner) StartWithOptions(prompt, workDir string, opts *StartOptions) error {
+	if err := os.WriteFile(filepath.Join(workDir, "progress.txt"), []byte("half done"), 0644); err != nil {
+		return err
+	}
+	close(r.started)
+	return nil
+}
+func (r *suspendRunner) Output() <-chan StreamEvent { return r.outputCh }
+func (r *suspendRunner) Wait() error                { return nil }
+func (r *suspendRunner) Kill() error {
+	r.killOnce.Do(func() { close(r.outputCh) })
+	return nil
+}
+func (r *suspendRunner) Stderr() string { return "" }
+func (r *suspendRunner) PID() int       { return 0 }
+
+type suspendRunnerFactory struct{ runner *suspendRunner }
+
+func (f *suspendRunnerFactory) NewRunner() ClaudeRunner { return f.runner }
+
+func TestExecutor_SuspendedAgentCheckpointsBranch(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := initTestGitRepo(repoDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	runner := &suspendRunner{outputCh: make(chan StreamEvent), started: make(chan struct{})}
+	executor, err := NewExecutor(ExecutorConfig{
+		RepoPath:        repoDir,
+		WorktreeBaseDir: t.TempDir(),
+		RunnerFactory:   &suspendRunnerFactory{runner: runner},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		<-runner.started
+		cancel(ErrSuspended)
+	}()
+
+	task := &models.Task{ID: "task-suspend-1", Title: "Add progress"}
+	_, _ = executor.ExecuteWithOptions(ctx, task, models.TierBuilder, nil)
+
+	// The next attempt checks out this branch and resumes from the file
+	show := exec.Command("git", "show", "agent-"+task.ID+":progress.txt")
+	show.Dir = repoDir
+	output, err := show.Output()
+	if err != nil {
+		t.Fatalf("progress.txt not committed to the task branch: %v", err)
+	}
+	if string(output) != "half done" {
+		t.Errorf("progress.txt = %q, want %q", string(output), "half done")
+	}
+}
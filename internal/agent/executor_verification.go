@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/internal/verification"
+	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
 // verificationContext holds verification state during task execution.
@@ -18,35 +20,57 @@ type verificationContext struct {
 
 // generateDraftContract creates a verification contract before implementation.
 // This establishes minimum verification requirements that cannot be weakened.
+// Checks generated from the task's acceptance criteria are added to the draft,
+// so a task with acceptance criteria but no verification intent still gets one.
 func (e *Executor) generateDraftContract(
 	ctx context.Context,
 	taskID string,
 	verificationIntent string,
+	acceptanceCriteria string,
 	fileBoundaries []string,
 	workDir string,
 ) *verificationContext {
 	vc := &verificationContext{}
 
-	if verificationIntent == "" {
+	if verificationIntent == "" && strings.TrimSpace(acceptanceCriteria) == "" {
 		return vc
 	}
 
 	vc.contractStorage = verification.NewContractStorage(e.worktreeMgr.RepoPath())
 	promptRunner := NewClaudePromptRunnerWithFactory(e.runnerFactory)
-	verifyGen := verification.NewGenerator(workDir, promptRunner)
 	projectCtx := verification.GetProjectContext(workDir)
 
-	var draftErr error
-	// expectedFiles is empty initially - we don't know what will be created yet
-	vc.draftContract, draftErr = verifyGen.DraftContract(ctx, verificationIntent, nil, fileBoundaries, projectCtx)
-	if draftErr == nil && vc.draftContract != nil {
+	if verificationIntent != "" {
+		verifyGen := verification.NewGenerator(workDir, promptRunner)
+		// expectedFiles is empty initially - we don't know what will be created yet
+		draft, draftErr := verifyGen.DraftContract(ctx, verificationIntent, nil, fileBoundaries, projectCtx)
+		if draftErr == nil {
+			vc.draftContract = draft
+		}
+		// If draft generation fails, continue without it - we'll fallback to post-impl only
+	}
+
+	// Turn acceptance criteria into executable checks
+	criteriaGen := verification.NewContractGenerator(vc.contractStorage, promptRunner)
+	acceptance, acceptErr := criteriaGen.Generate(ctx, taskID, acceptanceCriteria, fileBoundaries, projectCtx, workDir)
+	if acceptErr != nil {
+		vc.output.WriteString(fmt.Sprintf("[Acceptance check generation warning: %v]\n", acceptErr))
+	}
+	if acceptance != nil {
+		if vc.draftContract == nil {
+			vc.draftContract = &verification.VerificationContract{Intent: acceptance.Criteria}
+		}
+		vc.draftContract.Commands = append(vc.draftContract.Commands,
+			acceptance.Commands(vc.contractStorage.AcceptanceDir(taskID))...)
+	}
+
+	if vc.draftContract != nil {
 		// Store draft contract before implementation
 		if saveErr := vc.contractStorage.SaveDraft(taskID, vc.draftContract); saveErr != nil {
 			// Log but continue - verification can still work in-memory
 			vc.output.WriteString(fmt.Sprintf("[Contract storage warning: %v]\n", saveErr))
 		}
 	}
-	// If draft generation fails, continue without it - we'll fallback to post-impl only
 
 	return vc
 }
@@ -61,7 +85,7 @@ func (e *Executor) refineVerificationContract(
 	modifiedFiles []string,
 	workDir string,
 ) *verification.VerificationContract {
-	if verificationIntent == "" {
+	if verificationIntent == "" && vc.draftContract == nil {
 		return nil
 	}
 
@@ -103,3 +127,46 @@ func (e *Executor) refineVerificationContract(
 	vc.finalContract = finalContract
 	return finalContract
 }
+
+// runVerificationContract refines and runs the task's verification contract
+// when the review layer did not run it, through commands if set. The outcome is recorded on the
+// result; it stays unset if the task has no contract.
+func (e *Executor) runVerificationContract(
+	ctx context.Context,
+	result *ExecutionResult,
+	task *models.Task,
+	worktreePath string,
+	vc *verificationContext,
+	commands iexec.CommandRunner,
+) {
+	contract := vc.finalContract
+	if contract == nil {
+		if task.VerificationIntent == "" && vc.draftContract == nil {
+			return
+		}
+		modifiedFiles := e.getModifiedFiles(worktreePath)
+		contract = e.refineVerificationContract(ctx, vc, task.ID, task.VerificationIntent, modifiedFiles, worktreePath)
+		result.Output += vc.output.String()
+		if contract == nil {
+			return
+		}
+	}
+
+	vr, err := newContractRunner(worktreePath, commands).Run(ctx, contract)
+	passed := err == nil && vr.AllPassed
+	result.VerifyPassed = &passed
+	if err != nil {
+		result.VerifySummary = fmt.Sprintf("verification could not run: %v", err)
+	} else {
+		result.VerifySummary = vr.Summary
+	}
+}
+
+// newContractRunner creates a contract runner that runs its commands
+// through commands, such as a sandbox, or on the host if commands is nil.
+func newContractRunner(workDir string, commands iexec.CommandRunner) *verification.ContractRunner {
+	if commands == nil {
+		return verification.NewContractRunner(workDir)
+	}
+	return verification.NewContractRunnerWithExec(workDir, commands)
+}
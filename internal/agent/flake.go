@@ -0,0 +1,231 @@
+// Package agent provides the AI agent implementation for Alphie.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// Classifications of a failing test.
+const (
+	// TestFailurePreExisting failed in the session baseline too, before the
+	// agent changed anything.
+	TestFailurePreExisting = "pre_existing"
+	// TestFailureFlaky passed when rerun, or was found flaky earlier in the
+	// session.
+	TestFailureFlaky = "flaky"
+	// TestFailureIntroduced fails consistently and did not fail in the
+	// baseline: the agent's change broke it.
+	TestFailureIntroduced = "introduced"
+)
+
+// Flake detection defaults.
+const (
+	// defaultFlakeReruns is how many times a failing test is rerun.
+	defaultFlakeReruns = 2
+	// defaultMaxFlakeTests caps the tests rerun per gate run. More failures
+	// than this are a real regression, not flakiness, and are not rerun.
+	defaultMaxFlakeTests = 20
+)
+
+// TestFailure is a failing test and why it failed.
+type TestFailure struct {
+	// Test identifies the test as "<package>/<TestName>", the format the
+	// session baseline uses.
+	Test string
+	// Class is TestFailurePreExisting, TestFailureFlaky or TestFailureIntroduced.
+	Class string
+}
+
+// FlakeReport classifies the failures of a test gate run.
+type FlakeReport struct {
+	// Failures are the failing tests, in the order they were reported.
+	Failures []TestFailure
+	// Unparsed is true if some failures could not be attributed to a test,
+	// such as a package that failed to build. Those always fail the gate.
+	Unparsed bool
+}
+
+// Introduced returns the failures the agent's change caused.
+func (r *FlakeReport) Introduced() []TestFailure {
+	var introduced []TestFailure
+	for _, f := range r.Failures {
+		if f.Class == TestFailureIntroduced {
+			introduced = append(introduced, f)
+		}
+	}
+	return introduced
+}
+
+// Passes reports whether the gate should pass: every failure is attributed
+// to a test and none was introduced.
+func (r *FlakeReport) Passes() bool {
+	return !r.Unparsed && len(r.Failures) > 0 && len(r.Introduced()) == 0
+}
+
+// Summary lists the failures by classification.
+func (r *FlakeReport) Summary() string {
+	byClass := make(map[string][]string)
+	for _, f := range r.Failures {
+		byClass[f.Class] = append(byClass[f.Class], f.Test)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Test failures: %d introduced, %d flaky, %d pre-existing",
+		len(byClass[TestFailureIntroduced]), len(byClass[TestFailureFlaky]), len(byClass[TestFailurePreExisting]))
+	for _, class := range []string{TestFailureIntroduced, TestFailureFlaky, TestFailurePreExisting} {
+		for _, test := range byClass[class] {
+			fmt.Fprintf(&sb, "\n  %s: %s", class, test)
+		}
+	}
+	if r.Unparsed {
+		sb.WriteString("\n  some failures could not be attributed to a test")
+	}
+	return sb.String()
+}
+
+// FlakeDetector tells apart test failures an agent introduced from flaky
+// and pre-existing ones. Failing tests are compared against the session
+// baseline and rerun; a test that passes on a rerun is flaky and stays
+// quarantined for the rest of the session. Only Go tests are classified;
+// other test failures always fail the gate.
+type FlakeDetector struct {
+	reruns   int
+	maxTests int
+
+	mu sync.Mutex
+	// quarantined are the tests found flaky so far.
+	quarantined map[string]bool
+}
+
+// NewFlakeDetector creates a FlakeDetector with an empty quarantine.
+func NewFlakeDetector() *FlakeDetector {
+	return &FlakeDetector{
+		reruns:      defaultFlakeReruns,
+		maxTests:    defaultMaxFlakeTests,
+		quarantined: make(map[string]bool),
+	}
+}
+
+// Quarantined returns the tests found flaky so far.
+func (d *FlakeDetector) Quarantined() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	tests := make([]string, 0, len(d.quarantined))
+	for t := range d.quarantined {
+		tests = append(tests, t)
+	}
+	return tests
+}
+
+// Classify classifies the failures in a failed Go test gate's output,
+// rerunning tests through runner in workDir. A nil baseline treats no
+// failure as pre-existing.
+func (d *FlakeDetector) Classify(ctx context.Context, runner iexec.CommandRunner, workDir, output string, baseline *Baseline) *FlakeReport {
+	tests, complete := parseGoTestFailures(output)
+	report := &FlakeReport{Unparsed: !complete}
+
+	preExisting := make(map[string]bool)
+	if baseline != nil {
+		for _, t := range baseline.FailingTests {
+			preExisting[t] = true
+		}
+	}
+
+	var suspects []string
+	for _, t := range tests {
+		switch {
+		case preExisting[t]:
+			report.Failures = append(report.Failures, TestFailure{Test: t, Class: TestFailurePreExisting})
+		case d.isQuarantined(t):
+			report.Failures = append(report.Failures, TestFailure{Test: t, Class: TestFailureFlaky})
+		default:
+			suspects = append(suspects, t)
+		}
+	}
+
+	rerun := len(suspects) <= d.maxTests
+	for _, t := range suspects {
+		class := TestFailureIntroduced
+		if rerun && d.passesOnRerun(ctx, runner, workDir, t) {
+			class = TestFailureFlaky
+			d.quarantine(t)
+		}
+		report.Failures = append(report.Failures, TestFailure{Test: t, Class: class})
+	}
+	return report
+}
+
+// passesOnRerun reruns a test up to d.reruns times and reports whether any
+// run passed.
+func (d *FlakeDetector) passesOnRerun(ctx context.Context, runner iexec.CommandRunner, workDir, test string) bool {
+	i := strings.LastIndex(test, "/")
+	if i < 0 {
+		return false
+	}
+	pkg, name := test[:i], test[i+1:]
+	for n := 0; n < d.reruns; n++ {
+		if ctx.Err() != nil {
+			return false
+		}
+		if _, err := runner.Run(ctx, workDir, "go", "test", "-count=1", "-run", "^"+name+"$", pkg); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *FlakeDetector) isQuarantined(test string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.quarantined[test]
+}
+
+func (d *FlakeDetector) quarantine(test string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quarantined[test] = true
+}
+
+// parseGoTestFailures returns the top-level tests that failed in plain
+// `go test` output as "<package>/<TestName>". complete is false if a
+// package failed without a failing test to show for it (e.g. a build
+// failure), or no failing test was found.
+func parseGoTestFailures(output string) (tests []string, complete bool) {
+	complete = true
+	var pending []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Subtests are indented; only top-level tests can be rerun by name
+		if strings.HasPrefix(line, "--- FAIL: ") {
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				pending = append(pending, fields[2])
+			}
+			continue
+		}
+		// "FAIL\t<package>\t0.01s" or "FAIL\t<package> [build failed]"
+		if !strings.HasPrefix(line, "FAIL\t") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "FAIL\t"))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(pending) == 0 {
+			complete = false
+			continue
+		}
+		for _, name := range pending {
+			tests = append(tests, fields[0]+"/"+name)
+		}
+		pending = nil
+	}
+	if len(pending) > 0 || len(tests) == 0 {
+		complete = false
+	}
+	return tests, complete
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rerunRunner passes reruns of the tests in pass and fails every other
+// command, recording the tests it was asked to run.
+type rerunRunner struct {
+	pass map[string]bool
+	runs []string
+}
+
+func (r *rerunRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	// go test -count=1 -run ^Name$ pkg
+	test := args[len(args)-1] + "/" + strings.Trim(args[len(args)-2], "^$")
+	r.runs = append(r.runs, test)
+	if r.pass[test] {
+		return []byte("ok"), nil
+	}
+	return []byte("FAIL"), errors.New("exit status 1")
+}
+
+func (r *rerunRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return nil, errors.New("unexpected shell command")
+}
+
+func (r *rerunRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+const flakyGoTestOutput = `--- FAIL: TestParse (0.00s)
+    parse_test.go:12: unexpected token
+--- FAIL: TestRetry (0.10s)
+    --- FAIL: TestRetry/backoff (0.10s)
+FAIL
+FAIL	example.com/app/parser	0.112s
+--- FAIL: TestLegacy (0.00s)
+FAIL
+FAIL	example.com/app/legacy	0.010s
+ok  	example.com/app/cmd	0.020s
+FAIL
+`
+
+func TestParseGoTestFailures(t *testing.T) {
+	tests, complete := parseGoTestFailures(flakyGoTestOutput)
+	want := []string{
+		"example.com/app/parser/TestParse",
+		"example.com/app/parser/TestRetry",
+		"example.com/app/legacy/TestLegacy",
+	}
+	if !reflect.DeepEqual(tests, want) || !complete {
+		t.Errorf("parseGoTestFailures() = %v, %v, want %v, true", tests, complete, want)
+	}
+
+	buildFailure := "# example.com/app/parser\nparser.go:3:1: syntax error\nFAIL\texample.com/app/parser [build failed]\nFAIL\n"
+	if _, complete := parseGoTestFailures(buildFailure); complete {
+		t.Error("a build failure should not be attributed to a test")
+	}
+}
+
+func TestFlakeDetector_Classify(t *testing.T) {
+	runner := &rerunRunner{pass: map[string]bool{"example.com/app/parser/TestRetry": true}}
+	baseline := &Baseline{FailingTests: []string{"example.com/app/legacy/TestLegacy"}}
+	d := NewFlakeDetector()
+
+	report := d.Classify(context.Background(), runner, "", flakyGoTestOutput, baseline)
+	want := []TestFailure{
+		{Test: "example.com/app/legacy/TestLegacy", Class: TestFailurePreExisting},
+		{Test: "example.com/app/parser/TestParse", Class: TestFailureIntroduced},
+		{Test: "example.com/app/parser/TestRetry", Class: TestFailureFlaky},
+	}
+	if !reflect.DeepEqual(report.Failures, want) {
+		t.Errorf("Failures = %+v, want %+v", report.Failures, want)
+	}
+	if report.Passes() {
+		t.Error("a report with an introduced failure should not pass")
+	}
+	// TestParse is rerun every time it fails; TestRetry passes on its first rerun
+	if len(runner.runs) != defaultFlakeReruns+1 {
+		t.Errorf("reruns = %v, want %d", runner.runs, defaultFlakeReruns+1)
+	}
+	if q := d.Quarantined(); !reflect.DeepEqual(q, []string{"example.com/app/parser/TestRetry"}) {
+		t.Errorf("Quarantined() = %v", q)
+	}
+
+	// A quarantined test is not rerun again
+	runner.runs = nil
+	output := "--- FAIL: TestRetry (0.10s)\nFAIL\nFAIL\texample.com/app/parser\t0.1s\n"
+	report = d.Classify(context.Background(), runner, "", output, baseline)
+	if !report.Passes() || len(runner.runs) != 0 {
+		t.Errorf("quarantined failure: Passes() = %v, reruns = %v", report.Passes(), runner.runs)
+	}
+	if !strings.Contains(report.Summary(), "flaky: example.com/app/parser/TestRetry") {
+		t.Errorf("Summary() = %q", report.Summary())
+	}
+}
+
+func TestFlakeDetector_UnparsedFailuresFail(t *testing.T) {
+	output := "--- FAIL: TestLegacy (0.00s)\nFAIL\nFAIL\texample.com/app/legacy\t0.01s\nFAIL\texample.com/app/parser [build failed]\n"
+	baseline := &Baseline{FailingTests: []string{"example.com/app/legacy/TestLegacy"}}
+
+	report := NewFlakeDetector().Classify(context.Background(), &rerunRunner{}, "", output, baseline)
+	if report.Passes() {
+		t.Error("a build failure should fail the gate even if every test failure is pre-existing")
+	}
+}
+
+func TestQualityGates_ClassifyTestFailures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewQualityGates(dir)
+	q.SetFlakeDetector(NewFlakeDetector(), &Baseline{FailingTests: []string{"example.com/app/legacy/TestLegacy"}})
+	q.SetRunner(&rerunRunner{})
+
+	output := "--- FAIL: TestLegacy (0.00s)\nFAIL\nFAIL\texample.com/app/legacy\t0.01s\n"
+	got := q.classifyTestFailures(&GateOutput{Gate: "test", Result: GateFail, Output: output})
+	if got.Result != GatePass {
+		t.Errorf("Result = %s, want pass for a pre-existing failure", got.Result)
+	}
+	if !strings.HasPrefix(got.Output, "Test failures: 0 introduced, 0 flaky, 1 pre-existing") {
+		t.Errorf("Output should lead with the classification, got:\n%s", got.Output)
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 // GateResult represents the outcome of a quality gate check.
@@ -54,6 +58,8 @@ type GateOutput struct {
 }
 
 // QualityGates runs quality checks (tests, build, lint, typecheck) on a codebase.
+// Commands are detected from the project type unless BuildConfigFile
+// overrides them.
 type QualityGates struct {
 	testEnabled      bool
 	buildEnabled     bool
@@ -61,11 +67,24 @@ type QualityGates struct {
 	typecheckEnabled bool
 	workDir          string
 	timeout          time.Duration
+	buildConfig      *BuildConfig
+	buildConfigErr   error
+	// ctx, if set, stops gate commands when done.
+	ctx context.Context
+	// runner, if set, runs gate commands instead of the host (e.g. a sandbox).
+	runner iexec.CommandRunner
+	// flakes, if set, classifies test failures against baseline so only
+	// failures the agent introduced fail the test gate.
+	flakes   *FlakeDetector
+	baseline *Baseline
+	// coverage, if set, collects test coverage and adds the coverage gate.
+	coverage *coverageCheck
 }
 
 // NewQualityGates creates a new QualityGates runner for the given work directory.
 // All gates are disabled by default; use the Enable* methods to enable them.
 func NewQualityGates(workDir string) *QualityGates {
+	buildConfig, buildConfigErr := LoadBuildConfig(workDir)
 	return &QualityGates{
 		testEnabled:      false,
 		buildEnabled:     false,
@@ -73,6 +92,8 @@ func NewQualityGates(workDir string) *QualityGates {
 		typecheckEnabled: false,
 		workDir:          workDir,
 		timeout:          5 * time.Minute,
+		buildConfig:      buildConfig,
+		buildConfigErr:   buildConfigErr,
 	}
 }
 
@@ -96,6 +117,28 @@ func (q *QualityGates) EnableTypecheck(enabled bool) {
 	q.typecheckEnabled = enabled
 }
 
+// SetRunner runs gate commands through runner, such as a sandbox, instead
+// of directly on the host.
+func (q *QualityGates) SetRunner(runner iexec.CommandRunner) {
+	q.runner = runner
+}
+
+// SetFlakeDetector reruns failing tests with flakes and compares them
+// against baseline, so the test gate passes when every failure is flaky or
+// pre-existing.
+func (q *QualityGates) SetFlakeDetector(flakes *FlakeDetector, baseline *Baseline) {
+	q.flakes = flakes
+	q.baseline = baseline
+}
+
+// SetCoverage collects coverage when running tests and adds a coverage gate
+// that fails when a package with changed files loses more than maxDrop
+// percentage points of coverage against baseline. It enables the test gate.
+func (q *QualityGates) SetCoverage(baseline *Baseline, maxDrop float64, changed []string) {
+	q.coverage = &coverageCheck{baseline: baseline, maxDrop: maxDrop, changed: changed}
+	q.testEnabled = true
+}
+
 // SetTimeout sets the timeout for each individual gate.
 func (q *QualityGates) SetTimeout(d time.Duration) {
 	q.timeout = d
@@ -107,7 +150,11 @@ func (q *QualityGates) RunGates() ([]*GateOutput, error) {
 	var results []*GateOutput
 
 	if q.testEnabled {
-		results = append(results, q.runTests())
+		tests := q.classifyTestFailures(q.runTests())
+		results = append(results, tests)
+		if q.coverage != nil {
+			results = append(results, q.runCoverage(tests))
+		}
 	}
 
 	if q.buildEnabled {
@@ -125,6 +172,14 @@ func (q *QualityGates) RunGates() ([]*GateOutput, error) {
 	return results, nil
 }
 
+// RunGatesContext runs all enabled quality gates like RunGates, stopping
+// gate commands when ctx is done.
+func (q *QualityGates) RunGatesContext(ctx context.Context) ([]*GateOutput, error) {
+	q.ctx = ctx
+	defer func() { q.ctx = nil }()
+	return q.RunGates()
+}
+
 // runTests runs the test suite for the project.
 func (q *QualityGates) runTests() *GateOutput {
 	output := &GateOutput{
@@ -139,6 +194,10 @@ func (q *QualityGates) runTests() *GateOutput {
 		output.Duration = time.Since(start)
 	}()
 
+	if result, ok := q.runOverride(output); ok {
+		return result
+	}
+
 	switch projectType {
 	case "go":
 		// Check for Go test files
@@ -147,6 +206,9 @@ func (q *QualityGates) runTests() *GateOutput {
 			output.Output = "No Go test files found"
 			return output
 		}
+		if q.coverage != nil {
+			return q.runCommand(output, "go", "test", "-cover", "./...")
+		}
 		return q.runCommand(output, "go", "test", "./...")
 
 	case "node":
@@ -156,6 +218,9 @@ func (q *QualityGates) runTests() *GateOutput {
 			output.Output = "No test script in package.json"
 			return output
 		}
+		if q.coverage != nil {
+			return q.runCommand(output, "npm", "test", "--", "--coverage")
+		}
 		return q.runCommand(output, "npm", "test")
 
 	case "python":
@@ -165,8 +230,26 @@ func (q *QualityGates) runTests() *GateOutput {
 			output.Output = "No Python test files found"
 			return output
 		}
+		if runner := q.pythonRunner(); runner != nil {
+			return q.runArgv(output, append(runner, "pytest"))
+		}
 		return q.runCommand(output, "python", "-m", "pytest")
 
+	case "rust":
+		return q.runCommand(output, "cargo", "test")
+
+	case "maven":
+		return q.runCommand(output, q.wrapperOr("mvnw", "mvn"), "-B", "-q", "test")
+
+	case "gradle":
+		return q.runCommand(output, q.wrapperOr("gradlew", "gradle"), "test")
+
+	case "dotnet":
+		return q.runCommand(output, "dotnet", "test")
+
+	case "make":
+		return q.runMakeTarget(output, "test")
+
 	default:
 		output.Result = GateSkip
 		output.Output = "Unknown project type, cannot run tests"
@@ -174,6 +257,33 @@ func (q *QualityGates) runTests() *GateOutput {
 	}
 }
 
+// classifyTestFailures lets a failed Go test gate pass when the flake
+// detector finds none of its failures were introduced by the agent. The
+// classification is prepended to the gate output either way.
+func (q *QualityGates) classifyTestFailures(output *GateOutput) *GateOutput {
+	if q.flakes == nil || output.Result != GateFail || q.detectProjectType() != "go" {
+		return output
+	}
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	runner := q.runner
+	if runner == nil {
+		runner = iexec.NewRunner()
+	}
+
+	report := q.flakes.Classify(ctx, runner, q.workDir, output.Output, q.baseline)
+	if len(report.Failures) == 0 {
+		return output
+	}
+	if report.Passes() {
+		output.Result = GatePass
+	}
+	output.Output = report.Summary() + "\n\n" + output.Output
+	return output
+}
+
 // runBuild runs the build command for the project.
 func (q *QualityGates) runBuild() *GateOutput {
 	output := &GateOutput{
@@ -187,6 +297,10 @@ func (q *QualityGates) runBuild() *GateOutput {
 		output.Duration = time.Since(start)
 	}()
 
+	if result, ok := q.runOverride(output); ok {
+		return result
+	}
+
 	switch projectType {
 	case "go":
 		return q.runCommand(output, "go", "build", "./...")
@@ -205,6 +319,21 @@ func (q *QualityGates) runBuild() *GateOutput {
 		output.Output = "Python projects typically don't require building"
 		return output
 
+	case "rust":
+		return q.runCommand(output, "cargo", "build")
+
+	case "maven":
+		return q.runCommand(output, q.wrapperOr("mvnw", "mvn"), "-B", "-q", "compile")
+
+	case "gradle":
+		return q.runCommand(output, q.wrapperOr("gradlew", "gradle"), "assemble")
+
+	case "dotnet":
+		return q.runCommand(output, "dotnet", "build")
+
+	case "make":
+		return q.runMakeTarget(output, "build")
+
 	default:
 		output.Result = GateSkip
 		output.Output = "Unknown project type, cannot run build"
@@ -225,6 +354,10 @@ func (q *QualityGates) runLint() *GateOutput {
 		output.Duration = time.Since(start)
 	}()
 
+	if result, ok := q.runOverride(output); ok {
+		return result
+	}
+
 	switch projectType {
 	case "go":
 		// Use go vet as a basic linter, or golangci-lint if available
@@ -242,15 +375,29 @@ func (q *QualityGates) runLint() *GateOutput {
 		return q.runCommand(output, "npm", "run", "lint")
 
 	case "python":
-		if q.commandExists("ruff") {
-			return q.runCommand(output, "ruff", "check", ".")
-		} else if q.commandExists("flake8") {
-			return q.runCommand(output, "flake8", ".")
+		if ruff := q.pythonTool("ruff"); ruff != nil {
+			return q.runArgv(output, append(ruff, "check", "."))
+		} else if flake8 := q.pythonTool("flake8"); flake8 != nil {
+			return q.runArgv(output, append(flake8, "."))
 		}
 		output.Result = GateSkip
 		output.Output = "No Python linter (ruff, flake8) found"
 		return output
 
+	case "rust":
+		if !q.commandExists("cargo-clippy") {
+			output.Result = GateSkip
+			output.Output = "cargo clippy not found"
+			return output
+		}
+		return q.runCommand(output, "cargo", "clippy", "--", "-D", "warnings")
+
+	case "dotnet":
+		return q.runCommand(output, "dotnet", "format", "--verify-no-changes")
+
+	case "make":
+		return q.runMakeTarget(output, "lint")
+
 	default:
 		output.Result = GateSkip
 		output.Output = "Unknown project type, cannot run lint"
@@ -271,6 +418,10 @@ func (q *QualityGates) runTypecheck() *GateOutput {
 		output.Duration = time.Since(start)
 	}()
 
+	if result, ok := q.runOverride(output); ok {
+		return result
+	}
+
 	switch projectType {
 	case "go":
 		// Go doesn't have a separate typecheck; it's part of build/vet
@@ -288,13 +439,22 @@ func (q *QualityGates) runTypecheck() *GateOutput {
 		return q.runCommand(output, "npx", "tsc", "--noEmit")
 
 	case "python":
-		if q.commandExists("mypy") {
-			return q.runCommand(output, "mypy", ".")
+		if mypy := q.pythonTool("mypy"); mypy != nil {
+			return q.runArgv(output, append(mypy, "."))
 		}
 		output.Result = GateSkip
 		output.Output = "mypy not found"
 		return output
 
+	case "rust", "maven", "gradle", "dotnet":
+		// Compiled languages check types as part of the build gate
+		output.Result = GateSkip
+		output.Output = "Type checking is handled by build gate"
+		return output
+
+	case "make":
+		return q.runMakeTarget(output, "typecheck")
+
 	default:
 		output.Result = GateSkip
 		output.Output = "Unknown project type, cannot run typecheck"
@@ -302,32 +462,75 @@ func (q *QualityGates) runTypecheck() *GateOutput {
 	}
 }
 
+// runOverride runs the BuildConfigFile command for output's gate. It
+// reports false if the gate has no override and should be auto-detected.
+func (q *QualityGates) runOverride(output *GateOutput) (*GateOutput, bool) {
+	if q.buildConfigErr != nil {
+		output.Result = GateError
+		output.Output = "Invalid build config: " + q.buildConfigErr.Error()
+		return output, true
+	}
+	cmd := q.buildConfig.Command(output.Gate)
+	if cmd == nil {
+		return nil, false
+	}
+	if cmd.Skip {
+		output.Result = GateSkip
+		output.Output = "Disabled in " + BuildConfigFile
+		return output, true
+	}
+	timeout := q.buildConfig.TimeoutFor(cmd, q.timeout)
+	argv := iexec.ShellCommand(cmd.Command)
+	return q.runCommandWithTimeout(output, timeout, argv[0], argv[1:]...), true
+}
+
+// runArgv executes argv[0] with the remaining arguments.
+func (q *QualityGates) runArgv(output *GateOutput, argv []string) *GateOutput {
+	return q.runCommand(output, argv[0], argv[1:]...)
+}
+
 // runCommand executes a command and populates the GateOutput.
 func (q *QualityGates) runCommand(output *GateOutput, name string, args ...string) *GateOutput {
-	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	return q.runCommandWithTimeout(output, q.timeout, name, args...)
+}
+
+// runCommandWithTimeout executes a command bounded by timeout and populates the GateOutput.
+func (q *QualityGates) runCommandWithTimeout(output *GateOutput, timeout time.Duration, name string, args ...string) *GateOutput {
+	parent := q.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Dir = q.workDir
+	var err error
+	if q.runner != nil {
+		var out []byte
+		out, err = q.runner.Run(ctx, q.workDir, name, args...)
+		output.Output = string(out)
+	} else {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = q.workDir
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
 
-	err := cmd.Run()
+		err = cmd.Run()
 
-	// Combine stdout and stderr
-	var combined strings.Builder
-	if stdout.Len() > 0 {
-		combined.WriteString(stdout.String())
-	}
-	if stderr.Len() > 0 {
-		if combined.Len() > 0 {
-			combined.WriteString("\n")
+		// Combine stdout and stderr
+		var combined strings.Builder
+		if stdout.Len() > 0 {
+			combined.WriteString(stdout.String())
+		}
+		if stderr.Len() > 0 {
+			if combined.Len() > 0 {
+				combined.WriteString("\n")
+			}
+			combined.WriteString(stderr.String())
 		}
-		combined.WriteString(stderr.String())
+		output.Output = combined.String()
 	}
-	output.Output = combined.String()
 
 	if ctx.Err() == context.DeadlineExceeded {
 		output.Result = GateError
@@ -352,12 +555,36 @@ func (q *QualityGates) runCommand(output *GateOutput, name string, args ...strin
 }
 
 // detectProjectType determines the type of project in the work directory.
+// Compiled ecosystems are checked before Node.js because their projects
+// often carry a package.json for frontend tooling.
 func (q *QualityGates) detectProjectType() string {
 	// Check for Go project
 	if _, err := os.Stat(filepath.Join(q.workDir, "go.mod")); err == nil {
 		return "go"
 	}
 
+	// Check for Rust project
+	if _, err := os.Stat(filepath.Join(q.workDir, "Cargo.toml")); err == nil {
+		return "rust"
+	}
+
+	// Check for Maven and Gradle projects
+	if _, err := os.Stat(filepath.Join(q.workDir, "pom.xml")); err == nil {
+		return "maven"
+	}
+	for _, name := range []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(q.workDir, name)); err == nil {
+			return "gradle"
+		}
+	}
+
+	// Check for .NET project
+	for _, pattern := range []string{"*.sln", "*.csproj", "*.fsproj"} {
+		if matches, _ := filepath.Glob(filepath.Join(q.workDir, pattern)); len(matches) > 0 {
+			return "dotnet"
+		}
+	}
+
 	// Check for Node.js project
 	if _, err := os.Stat(filepath.Join(q.workDir, "package.json")); err == nil {
 		return "node"
@@ -374,9 +601,97 @@ func (q *QualityGates) detectProjectType() string {
 		return "python"
 	}
 
+	// Fall back to Makefile targets
+	if q.makefile() != "" {
+		return "make"
+	}
+
 	return "unknown"
 }
 
+// wrapperOr returns the project's build tool wrapper script (such as
+// ./gradlew) if it has one, otherwise the globally installed tool.
+func (q *QualityGates) wrapperOr(wrapper, tool string) string {
+	for _, name := range wrapperNames(runtime.GOOS, wrapper) {
+		if _, err := os.Stat(filepath.Join(q.workDir, name)); err == nil {
+			return "." + string(filepath.Separator) + name
+		}
+	}
+	return tool
+}
+
+// wrapperNames returns the file names of a build tool wrapper script on
+// goos: the shell script on Unix, gradlew.bat or mvnw.cmd on Windows.
+func wrapperNames(goos, wrapper string) []string {
+	if goos == "windows" {
+		return []string{wrapper + ".bat", wrapper + ".cmd"}
+	}
+	return []string{wrapper}
+}
+
+// pythonRunner returns the command prefix that runs tools in the project's
+// environment: "uv run" for uv projects, "poetry run" for Poetry projects,
+// or nil to run tools directly.
+func (q *QualityGates) pythonRunner() []string {
+	if _, err := os.Stat(filepath.Join(q.workDir, "uv.lock")); err == nil {
+		return []string{"uv", "run"}
+	}
+	if _, err := os.Stat(filepath.Join(q.workDir, "poetry.lock")); err == nil {
+		return []string{"poetry", "run"}
+	}
+	if content, err := os.ReadFile(filepath.Join(q.workDir, "pyproject.toml")); err == nil &&
+		strings.Contains(string(content), "[tool.poetry]") {
+		return []string{"poetry", "run"}
+	}
+	return nil
+}
+
+// pythonTool returns the command that runs a Python tool: through the
+// project's runner if pyproject.toml mentions the tool, otherwise from PATH.
+// It returns nil if the tool is unavailable.
+func (q *QualityGates) pythonTool(name string) []string {
+	if runner := q.pythonRunner(); runner != nil {
+		content, err := os.ReadFile(filepath.Join(q.workDir, "pyproject.toml"))
+		if err == nil && strings.Contains(string(content), name) {
+			return append(runner, name)
+		}
+	}
+	if q.commandExists(name) {
+		return []string{name}
+	}
+	return nil
+}
+
+// makefile returns the name of the project's Makefile, or "" if it has none.
+func (q *QualityGates) makefile() string {
+	for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		if _, err := os.Stat(filepath.Join(q.workDir, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// hasMakeTarget checks if the project's Makefile defines target.
+func (q *QualityGates) hasMakeTarget(target string) bool {
+	content, err := os.ReadFile(filepath.Join(q.workDir, q.makefile()))
+	if err != nil {
+		return false
+	}
+	pattern := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(target) + `\s*:([^=]|$)`)
+	return pattern.Match(content)
+}
+
+// runMakeTarget runs a Makefile target, skipping the gate if it is not defined.
+func (q *QualityGates) runMakeTarget(output *GateOutput, target string) *GateOutput {
+	if !q.hasMakeTarget(target) {
+		output.Result = GateSkip
+		output.Output = "No " + target + " target in Makefile"
+		return output
+	}
+	return q.runCommand(output, "make", target)
+}
+
 // hasGoTestFiles checks if the project has any Go test files.
 func (q *QualityGates) hasGoTestFiles() bool {
 	found := false
@@ -1,10 +1,15 @@
 package agent
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 func TestGateResult_String(t *testing.T) {
@@ -104,6 +109,41 @@ func TestQualityGates_DetectProjectType(t *testing.T) {
 			files:    []string{"go.mod", "package.json"},
 			wantType: "go",
 		},
+		{
+			name:     "rust project",
+			files:    []string{"Cargo.toml"},
+			wantType: "rust",
+		},
+		{
+			name:     "maven project",
+			files:    []string{"pom.xml"},
+			wantType: "maven",
+		},
+		{
+			name:     "gradle kotlin project",
+			files:    []string{"build.gradle.kts"},
+			wantType: "gradle",
+		},
+		{
+			name:     "dotnet project",
+			files:    []string{"App.csproj"},
+			wantType: "dotnet",
+		},
+		{
+			name:     "gradle over node",
+			files:    []string{"build.gradle", "package.json"},
+			wantType: "gradle",
+		},
+		{
+			name:     "makefile project",
+			files:    []string{"Makefile"},
+			wantType: "make",
+		},
+		{
+			name:     "python over makefile",
+			files:    []string{"pyproject.toml", "Makefile"},
+			wantType: "python",
+		},
 	}
 
 	for _, tt := range tests {
@@ -340,3 +380,31 @@ func TestQualityGates_RunGates_GoProject_NoTests(t *testing.T) {
 		t.Errorf("Test gate should skip with no test files, got %v", results[0].Result)
 	}
 }
+
+func TestQualityGates_SetRunner(t *testing.T) {
+	q := NewQualityGates(t.TempDir())
+
+	q.SetRunner(iexec.NewFailingRunner(errors.New("sandbox unavailable")))
+	out := q.runCommand(&GateOutput{Gate: "build"}, "go", "build", "./...")
+	if out.Result != GateError || !strings.Contains(out.Output, "sandbox unavailable") {
+		t.Errorf("expected a runner error to be a gate error, got %v: %q", out.Result, out.Output)
+	}
+
+	// Exit codes from the runner still fail the gate
+	q.SetRunner(iexec.NewRunner())
+	q.ctx = context.Background()
+	out = q.runCommand(&GateOutput{Gate: "test"}, "sh", "-c", "echo broken; exit 3")
+	if out.Result != GateFail || !strings.Contains(out.Output, "broken") {
+		t.Errorf("expected a failing command to fail the gate, got %v: %q", out.Result, out.Output)
+	}
+}
+
+func TestWrapperNames(t *testing.T) {
+	if got := wrapperNames("linux", "gradlew"); len(got) != 1 || got[0] != "gradlew" {
+		t.Errorf("wrapperNames(linux) = %v", got)
+	}
+	got := wrapperNames("windows", "gradlew")
+	if len(got) != 2 || got[0] != "gradlew.bat" || got[1] != "gradlew.cmd" {
+		t.Errorf("wrapperNames(windows) = %v", got)
+	}
+}
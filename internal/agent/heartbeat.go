@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+)
+
+// StallState is what a heartbeat asks of a silent agent.
+type StallState int
+
+const (
+	// StallNone means the agent showed progress recently.
+	StallNone StallState = iota
+	// StallWarn means the agent has been silent for half its stall timeout.
+	StallWarn
+	// StallKill means the agent has been silent for its whole stall timeout
+	// and must be stopped.
+	StallKill
+)
+
+// heartbeat tracks when an agent last showed progress, a stream event or
+// tokens, and decides when a silent agent has stalled. It warns once per
+// silence, after half the timeout, and asks for the agent to be stopped
+// after the whole timeout. A timeout of 0 or less never stalls.
+type heartbeat struct {
+	timeout time.Duration
+	last    time.Time
+	warned  bool
+}
+
+// newHeartbeat creates a heartbeat whose last beat is now.
+func newHeartbeat(timeout time.Duration, now time.Time) *heartbeat {
+	return &heartbeat{timeout: timeout, last: now}
+}
+
+// beat records progress at now.
+func (h *heartbeat) beat(now time.Time) {
+	h.last = now
+	h.warned = false
+}
+
+// check returns how long the agent has been silent at now and what to do
+// about it. StallWarn is returned once per silence.
+func (h *heartbeat) check(now time.Time) (time.Duration, StallState) {
+	idle := now.Sub(h.last)
+	switch {
+	case h.timeout <= 0:
+		return idle, StallNone
+	case idle >= h.timeout:
+		return idle, StallKill
+	case idle >= h.timeout/2 && !h.warned:
+		h.warned = true
+		return idle, StallWarn
+	}
+	return idle, StallNone
+}
+
+// stallError is the error of an agent stopped for showing no progress.
+func stallError(idle time.Duration) error {
+	return fmt.Errorf("agent stalled: no progress for %v, stopped by the watchdog", idle.Round(time.Second))
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	start := time.Now()
+	h := newHeartbeat(10*time.Minute, start)
+
+	if _, state := h.check(start.Add(4 * time.Minute)); state != StallNone {
+		t.Errorf("check at 4m = %v, want StallNone", state)
+	}
+	if idle, state := h.check(start.Add(5 * time.Minute)); state != StallWarn || idle != 5*time.Minute {
+		t.Errorf("check at 5m = %v, %v, want 5m, StallWarn", idle, state)
+	}
+	if _, state := h.check(start.Add(6 * time.Minute)); state != StallNone {
+		t.Errorf("check at 6m = %v, want the warning only once", state)
+	}
+	if _, state := h.check(start.Add(10 * time.Minute)); state != StallKill {
+		t.Errorf("check at 10m = %v, want StallKill", state)
+	}
+
+	// Progress restarts the silence, and a new silence warns again
+	h.beat(start.Add(10 * time.Minute))
+	if _, state := h.check(start.Add(14 * time.Minute)); state != StallNone {
+		t.Errorf("check 4m after a beat = %v, want StallNone", state)
+	}
+	if _, state := h.check(start.Add(15 * time.Minute)); state != StallWarn {
+		t.Errorf("check 5m after a beat = %v, want StallWarn", state)
+	}
+}
+
+func TestHeartbeat_Disabled(t *testing.T) {
+	start := time.Now()
+	h := newHeartbeat(0, start)
+	if _, state := h.check(start.Add(24 * time.Hour)); state != StallNone {
+		t.Errorf("disabled heartbeat check = %v, want StallNone", state)
+	}
+}
+
+func TestStallError(t *testing.T) {
+	err := stallError(10*time.Minute + 300*time.Millisecond)
+	if !strings.Contains(err.Error(), "no progress for 10m0s") {
+		t.Errorf("stallError() = %q", err)
+	}
+}
@@ -0,0 +1,376 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// maxLintViolations caps the violations reported in a lint layer's detail.
+const maxLintViolations = 20
+
+// Linter is a static-analysis tool the lint layer runs on changed files.
+type Linter struct {
+	// Name labels the linter's violations.
+	Name string
+	// Extensions selects the changed files the linter checks, e.g. ".go".
+	// Empty checks every changed file.
+	Extensions []string
+	// Command is the linter's argv. The changed files it checks are
+	// appended to it (their directories for golangci-lint).
+	Command []string
+	// parse turns the linter's output into violations keyed
+	// "file:rule: message", without line numbers so a violation is
+	// recognized after the code around it moves. Paths are relative to
+	// workDir. Nil parses "file:line[:col]: message" lines.
+	parse func(output []byte, workDir string) ([]string, error)
+	// packages passes the linter the directories of the files instead of
+	// the files.
+	packages bool
+}
+
+// DefaultLinters returns the built-in linters: golangci-lint for Go, eslint
+// for JavaScript and TypeScript, and ruff for Python.
+func DefaultLinters() []Linter {
+	return []Linter{
+		{
+			Name:       "golangci-lint",
+			Extensions: []string{".go"},
+			Command:    []string{"golangci-lint", "run", "--out-format=json"},
+			parse:      parseGolangciLintViolations,
+			packages:   true,
+		},
+		{
+			Name:       "eslint",
+			Extensions: []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"},
+			Command:    []string{"npx", "--no-install", "eslint", "--format", "json"},
+			parse:      parseESLintViolations,
+		},
+		{
+			Name:       "ruff",
+			Extensions: []string{".py"},
+			Command:    []string{"ruff", "check", "--output-format", "json"},
+			parse:      parseRuffViolations,
+		},
+	}
+}
+
+// LintersFromConfig builds the lint layer's linters. A linter named after a
+// built-in one without a command is the built-in linter; others run their
+// command and report "file:line: message" lines. Empty returns nil, which
+// runs the built-in linters.
+func LintersFromConfig(cfgs []config.LinterConfig) ([]Linter, error) {
+	builtins := make(map[string]Linter)
+	for _, l := range DefaultLinters() {
+		builtins[l.Name] = l
+	}
+
+	var linters []Linter
+	for _, c := range cfgs {
+		name := strings.TrimSpace(c.Name)
+		command := strings.Fields(c.Command)
+		if len(command) == 0 {
+			l, ok := builtins[name]
+			if !ok {
+				return nil, fmt.Errorf("linter %q is not built in and has no command", name)
+			}
+			if len(c.Extensions) > 0 {
+				l.Extensions = c.Extensions
+			}
+			linters = append(linters, l)
+			continue
+		}
+		if name == "" {
+			name = command[0]
+		}
+		linters = append(linters, Linter{Name: name, Extensions: c.Extensions, Command: command})
+	}
+	return linters, nil
+}
+
+// lints reports whether the linter checks file.
+func (l Linter) lints(file string) bool {
+	if len(l.Extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(file)
+	for _, e := range l.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintResult is the outcome of the lint layer.
+type lintResult struct {
+	// Violations are the violations in the changed files not in the baseline.
+	Violations []string
+	// Ran names the linters that ran.
+	Ran []string
+	// Errors describe linters that could not run or whose output could not
+	// be parsed.
+	Errors []string
+}
+
+// runLinters runs each linter on the changed files it checks and returns
+// the violations in those files that the baseline does not already have.
+// Linters that are not installed are skipped.
+func runLinters(ctx context.Context, runner iexec.CommandRunner, workDir string, linters []Linter, changed []string, baseline *Baseline) lintResult {
+	var files []string
+	for _, f := range changed {
+		if _, err := os.Stat(filepath.Join(workDir, f)); err == nil {
+			files = append(files, filepath.ToSlash(f))
+		}
+	}
+
+	var res lintResult
+	var violations []string
+	for _, l := range linters {
+		var targets []string
+		checked := make(map[string]bool)
+		for _, f := range files {
+			if l.lints(f) {
+				targets = append(targets, f)
+				checked[f] = true
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		if l.packages {
+			targets = packageDirs(targets)
+		}
+
+		out, err := runner.Run(ctx, workDir, l.Command[0], append(append([]string(nil), l.Command[1:]...), targets...)...)
+		if errors.Is(err, exec.ErrNotFound) {
+			continue
+		}
+		if ctx.Err() != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", l.Name, ctx.Err()))
+			continue
+		}
+		parse := l.parse
+		if parse == nil {
+			parse = parseLineViolations
+		}
+		found, parseErr := parse(out, workDir)
+		if parseErr != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", l.Name, parseErr))
+			continue
+		}
+		if err != nil && len(found) == 0 && l.parse == nil {
+			// A linter that failed without reporting a violation did not run
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v\n%s", l.Name, err, outputTail(string(out), 5)))
+			continue
+		}
+		res.Ran = append(res.Ran, l.Name)
+		for _, v := range found {
+			if checked[violationFile(v)] {
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	var known []string
+	if baseline != nil {
+		known = baseline.LintErrors
+	}
+	res.Violations = newViolations(violations, known)
+	return res
+}
+
+// runLintLayer runs the lint layer, recording new violations on the result
+// for the retry's failure context.
+func (e *Executor) runLintLayer(ctx context.Context, run *validationRun) (ValidationOutcome, string) {
+	changed := worktreeChangedFiles(run.worktreePath)
+	if len(changed) == 0 {
+		return ValidationOutcome{Skipped: true, Detail: "no changed files"}, ""
+	}
+	linters := run.linters
+	if len(linters) == 0 {
+		linters = DefaultLinters()
+	}
+	runner := run.commands()
+	if runner == nil {
+		runner = iexec.NewRunner()
+	}
+	var baseline *Baseline
+	if run.opts != nil {
+		baseline = run.opts.Baseline
+	}
+
+	res := runLinters(ctx, runner, run.worktreePath, linters, changed, baseline)
+	run.result.LintViolations = res.Violations
+	notes := ""
+	if len(res.Errors) > 0 {
+		notes = "\nlinters that did not run:\n- " + strings.Join(res.Errors, "\n- ")
+	}
+	if len(res.Violations) > 0 {
+		return ValidationOutcome{Detail: formatLintViolations(res.Violations) + notes},
+			fmt.Sprintf("lint validation failed: %d new violation(s)", len(res.Violations))
+	}
+	if len(res.Ran) == 0 {
+		return ValidationOutcome{Skipped: true, Detail: "no linter ran on the changed files" + notes}, ""
+	}
+	return ValidationOutcome{Passed: true, Detail: "ran " + strings.Join(res.Ran, ", ") + notes}, ""
+}
+
+// packageDirs returns the distinct directories of files as ./dir paths.
+func packageDirs(files []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, f := range files {
+		dir := "./" + filepath.ToSlash(filepath.Dir(f))
+		if dir == "./." {
+			dir = "."
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// newViolations returns the violations that known does not account for.
+// A violation known once accounts for one occurrence, so a second copy of
+// an existing violation is new.
+func newViolations(violations, known []string) []string {
+	counts := make(map[string]int, len(known))
+	for _, k := range known {
+		counts[k]++
+	}
+	var fresh []string
+	for _, v := range violations {
+		if counts[v] > 0 {
+			counts[v]--
+			continue
+		}
+		fresh = append(fresh, v)
+	}
+	return fresh
+}
+
+// violationFile returns the file a violation key is in.
+func violationFile(v string) string {
+	if i := strings.Index(v, ":"); i >= 0 {
+		return filepath.ToSlash(strings.TrimPrefix(v[:i], "./"))
+	}
+	return v
+}
+
+// relativeTo returns path relative to dir, with forward slashes, if it is
+// an absolute path inside dir.
+func relativeTo(dir, path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// parseGolangciLintViolations parses golangci-lint's JSON report in the
+// form the session baseline records.
+func parseGolangciLintViolations(output []byte, _ string) ([]string, error) {
+	start := strings.Index(string(output), "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON report in output: %s", outputTail(string(output), 5))
+	}
+	var report struct {
+		Issues []json.RawMessage `json:"Issues"`
+	}
+	if err := json.Unmarshal(output[start:], &report); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	return parseGolangciLintJSON(output[start:]), nil
+}
+
+// parseESLintViolations parses eslint's JSON formatter output.
+func parseESLintViolations(output []byte, workDir string) ([]string, error) {
+	start := strings.Index(string(output), "[")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON report in output: %s", outputTail(string(output), 5))
+	}
+	var files []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID  string `json:"ruleId"`
+			Message string `json:"message"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(output[start:], &files); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	var violations []string
+	for _, f := range files {
+		path := relativeTo(workDir, f.FilePath)
+		for _, m := range f.Messages {
+			violations = append(violations, fmt.Sprintf("%s:%s: %s", path, m.RuleID, strings.TrimSpace(m.Message)))
+		}
+	}
+	return violations, nil
+}
+
+// parseRuffViolations parses ruff's JSON output.
+func parseRuffViolations(output []byte, workDir string) ([]string, error) {
+	start := strings.Index(string(output), "[")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON report in output: %s", outputTail(string(output), 5))
+	}
+	var issues []struct {
+		Filename string `json:"filename"`
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+	}
+	if err := json.Unmarshal(output[start:], &issues); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	var violations []string
+	for _, i := range issues {
+		path := relativeTo(workDir, i.Filename)
+		violations = append(violations, fmt.Sprintf("%s:%s: %s", path, i.Code, strings.TrimSpace(i.Message)))
+	}
+	return violations, nil
+}
+
+// lineViolation matches a "file:line[:col]: message" linter line.
+var lineViolation = regexp.MustCompile(`^([^\s:]+):\d+(?::\d+)?:?\s*(.+)$`)
+
+// parseLineViolations parses "file:line[:col]: message" lines, ignoring
+// other output.
+func parseLineViolations(output []byte, _ string) ([]string, error) {
+	var violations []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if m := lineViolation.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			violations = append(violations, m[1]+": "+strings.TrimSpace(m[2]))
+		}
+	}
+	return violations, nil
+}
+
+// formatLintViolations describes the new violations for the layer's detail.
+func formatLintViolations(violations []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d new lint violation(s)", len(violations))
+	for i, v := range violations {
+		if i == maxLintViolations {
+			fmt.Fprintf(&b, "\n- ... and %d more", len(violations)-maxLintViolations)
+			break
+		}
+		b.WriteString("\n- " + v)
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+)
+
+// lintRunner answers linter invocations with canned output by command name.
+type lintRunner struct {
+	outputs map[string]string
+	calls   [][]string
+}
+
+func (r *lintRunner) Run(_ context.Context, _ string, name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	out, ok := r.outputs[name]
+	if !ok {
+		return nil, &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	if out == "" || out == "[]" {
+		return []byte(out), nil
+	}
+	return []byte(out), fmt.Errorf("exit status 1")
+}
+
+func (r *lintRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return r.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (r *lintRunner) Exists(context.Context, string, string) bool { return true }
+
+func writeLintFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunLinters_NewViolationsOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeLintFiles(t, dir, "api/handler.go", "web/app.ts", "README.md")
+
+	runner := &lintRunner{outputs: map[string]string{
+		"golangci-lint": `{"Issues":[
+			{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"api/handler.go","Line":12}},
+			{"FromLinter":"unused","Text":"func old is unused","Pos":{"Filename":"api/handler.go","Line":40}},
+			{"FromLinter":"unused","Text":"func other is unused","Pos":{"Filename":"api/other.go","Line":3}}
+		]}`,
+		"npx": fmt.Sprintf(`[{"filePath":%q,"messages":[{"ruleId":"no-unused-vars","message":"'x' is unused"}]}]`,
+			filepath.Join(dir, "web/app.ts")),
+	}}
+	baseline := &Baseline{LintErrors: []string{"api/handler.go:unused: func old is unused"}}
+
+	res := runLinters(context.Background(), runner, dir, DefaultLinters(),
+		[]string{"api/handler.go", "web/app.ts", "README.md", "deleted.py"}, baseline)
+
+	want := []string{
+		"api/handler.go:errcheck: Error return value is not checked",
+		"web/app.ts:no-unused-vars: 'x' is unused",
+	}
+	if !reflect.DeepEqual(res.Violations, want) {
+		t.Errorf("Violations = %v, want %v", res.Violations, want)
+	}
+	if !reflect.DeepEqual(res.Ran, []string{"golangci-lint", "eslint"}) {
+		t.Errorf("Ran = %v", res.Ran)
+	}
+	if len(runner.calls) != 2 || runner.calls[0][len(runner.calls[0])-1] != "./api" {
+		t.Errorf("calls = %v, want golangci-lint on ./api and eslint only", runner.calls)
+	}
+}
+
+func TestRunLinters_MissingLinterSkipped(t *testing.T) {
+	dir := t.TempDir()
+	writeLintFiles(t, dir, "main.py")
+
+	res := runLinters(context.Background(), &lintRunner{}, dir, DefaultLinters(), []string{"main.py"}, nil)
+	if len(res.Ran) != 0 || len(res.Violations) != 0 || len(res.Errors) != 0 {
+		t.Errorf("expected a missing ruff to be skipped, got %+v", res)
+	}
+}
+
+func TestNewViolations(t *testing.T) {
+	got := newViolations([]string{"a", "a", "b"}, []string{"a", "c"})
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("newViolations() = %v, want a second copy of a and b", got)
+	}
+}
+
+func TestParseLineViolations(t *testing.T) {
+	got, _ := parseLineViolations([]byte("src/app.rb:12:3: Style/StringLiterals: prefer single quotes\n2 offenses\n"), "")
+	want := []string{"src/app.rb: Style/StringLiterals: prefer single quotes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLineViolations() = %v, want %v", got, want)
+	}
+}
+
+func TestLintersFromConfig(t *testing.T) {
+	linters, err := LintersFromConfig(nil)
+	if err != nil || linters != nil {
+		t.Errorf("LintersFromConfig(nil) = %v, %v", linters, err)
+	}
+
+	pipeline, err := ValidationPipelineFromConfig(&config.ValidationConfig{
+		Layers:  []config.ValidationLayerConfig{{Name: "gates"}, {Name: "lint"}},
+		Linters: []config.LinterConfig{{Name: "ruff"}, {Name: "rubocop", Command: "rubocop --format emacs", Extensions: []string{".rb"}}},
+	})
+	if err != nil {
+		t.Fatalf("ValidationPipelineFromConfig() error = %v", err)
+	}
+	if len(pipeline.Linters) != 2 || pipeline.Linters[0].parse == nil || pipeline.Linters[1].Command[0] != "rubocop" {
+		t.Errorf("Linters = %+v", pipeline.Linters)
+	}
+
+	if _, err := LintersFromConfig([]config.LinterConfig{{Name: "pylint"}}); err == nil || !strings.Contains(err.Error(), "no command") {
+		t.Errorf("expected an unknown linter without a command to be rejected, got %v", err)
+	}
+}
@@ -74,6 +74,22 @@ func SelectModel(task *models.Task, tier models.Tier) string {
 	return getTierDefault(tier)
 }
 
+// ResolveModel returns the model ID for a model name from a tier config:
+// "haiku", "sonnet" and "opus" map to the current model of that family and
+// anything else is returned unchanged, as a full model ID.
+func ResolveModel(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "haiku":
+		return ModelHaiku
+	case "sonnet":
+		return ModelSonnet
+	case "opus":
+		return ModelOpus
+	default:
+		return name
+	}
+}
+
 // getTierDefault returns the default model for a tier.
 func getTierDefault(tier models.Tier) string {
 	if model, ok := TierDefaultModels[tier]; ok {
@@ -288,3 +288,17 @@ func TestHaikuKeywordsTakePrecedence(t *testing.T) {
 		t.Errorf("Expected haiku to take precedence when both keyword types present, got %v", result)
 	}
 }
+
+func TestResolveModel(t *testing.T) {
+	tests := map[string]string{
+		"haiku":              ModelHaiku,
+		"Sonnet":             ModelSonnet,
+		"opus":               ModelOpus,
+		"claude-custom-1234": "claude-custom-1234",
+	}
+	for name, want := range tests {
+		if got := ResolveModel(name); got != want {
+			t.Errorf("ResolveModel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
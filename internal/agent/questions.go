@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuestionPrefix starts a line in which an agent asks a question only a
+// person can answer.
+const QuestionPrefix = "QUESTION:"
+
+// ExtractQuestions returns the questions asked in an agent message: the
+// text of each line starting with QuestionPrefix.
+func ExtractQuestions(message string) []string {
+	var questions []string
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, QuestionPrefix); ok {
+			if q := strings.TrimSpace(rest); q != "" {
+				questions = append(questions, q)
+			}
+		}
+	}
+	return questions
+}
+
+// questionGuidance tells the agent how many questions it may ask: allowed
+// more, none if 0, or any number if negative.
+func questionGuidance(allowed int) string {
+	var sb strings.Builder
+	sb.WriteString("\n## Questions\n\n")
+	switch {
+	case allowed == 0:
+		sb.WriteString("Do not ask questions. Make reasonable assumptions and state them in your summary.\n")
+	case allowed < 0:
+		sb.WriteString(fmt.Sprintf("If something only a person can decide blocks you, write the question on its own line starting with `%s`. Otherwise make reasonable assumptions and state them in your summary.\n", QuestionPrefix))
+	default:
+		sb.WriteString(fmt.Sprintf("If something only a person can decide blocks you, write the question on its own line starting with `%s`. You may ask at most %d; otherwise make reasonable assumptions and state them in your summary.\n", QuestionPrefix, allowed))
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractQuestions(t *testing.T) {
+	message := "Looking at the schema.\nQUESTION: Should IDs be UUIDs?\n  QUESTION:   Keep the v1 endpoint?  \nQUESTION:\nNo question here: QUESTION: inline"
+
+	got := ExtractQuestions(message)
+	want := []string{"Should IDs be UUIDs?", "Keep the v1 endpoint?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractQuestions() = %q, want %q", got, want)
+	}
+	if got := ExtractQuestions("All done."); got != nil {
+		t.Errorf("ExtractQuestions() = %q, want none", got)
+	}
+}
+
+func TestQuestionGuidance(t *testing.T) {
+	if got := questionGuidance(0); !strings.Contains(got, "Do not ask questions") {
+		t.Errorf("questionGuidance(0) = %q", got)
+	}
+	if got := questionGuidance(2); !strings.Contains(got, "at most 2") || !strings.Contains(got, QuestionPrefix) {
+		t.Errorf("questionGuidance(2) = %q", got)
+	}
+	if got := questionGuidance(-1); strings.Contains(got, "at most") || !strings.Contains(got, QuestionPrefix) {
+		t.Errorf("questionGuidance(-1) = %q", got)
+	}
+}
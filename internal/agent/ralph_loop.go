@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/internal/verification"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
@@ -18,11 +19,13 @@ type RalphLoop struct {
 	controller           *IterationController
 	gates                *QualityGates
 	baseline             *Baseline
+	flakes               *FlakeDetector
 	testSelector         *FocusedTestSelector
 	tier                 models.Tier
 	workDir              string
 	verificationContract *verification.VerificationContract
 	contractRunner       *verification.ContractRunner
+	commandRunner        iexec.CommandRunner
 	// runnerFactory creates ClaudeRunner instances for critique iterations.
 	// If nil, falls back to creating ClaudeProcess (legacy).
 	runnerFactory ClaudeRunnerFactory
@@ -66,6 +69,16 @@ func NewRalphLoop(tier models.Tier, workDir string) *RalphLoop {
 // When set, quality gates will compare current results against this baseline.
 func (r *RalphLoop) SetBaseline(baseline *Baseline) {
 	r.baseline = baseline
+	if r.flakes != nil {
+		r.gates.SetFlakeDetector(r.flakes, baseline)
+	}
+}
+
+// SetFlakeDetector reruns failing tests so the test gate only fails on
+// failures the agent introduced. Failures in the baseline are pre-existing.
+func (r *RalphLoop) SetFlakeDetector(flakes *FlakeDetector) {
+	r.flakes = flakes
+	r.gates.SetFlakeDetector(flakes, r.baseline)
 }
 
 // EnableGate enables a specific quality gate.
@@ -94,7 +107,17 @@ func (r *RalphLoop) EnableAllGates() {
 // When set, the loop will run verification commands and use results in the decision matrix.
 func (r *RalphLoop) SetVerificationContract(contract *verification.VerificationContract) {
 	r.verificationContract = contract
-	r.contractRunner = verification.NewContractRunner(r.workDir)
+	r.contractRunner = newContractRunner(r.workDir, r.commandRunner)
+}
+
+// SetCommandRunner runs the loop's gate and verification commands through
+// runner, such as a sandbox, instead of directly on the host.
+func (r *RalphLoop) SetCommandRunner(runner iexec.CommandRunner) {
+	r.commandRunner = runner
+	r.gates.SetRunner(runner)
+	if r.contractRunner != nil {
+		r.contractRunner = newContractRunner(r.workDir, runner)
+	}
 }
 
 // SetRunnerFactory sets the factory for creating ClaudeRunner instances.
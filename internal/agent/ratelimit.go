@@ -0,0 +1,338 @@
+package agent
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/api"
+)
+
+// RateLimit is how fast requests to a model may be sent. Zero fields are
+// unlimited.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// RateLimiterConfig configures a RateLimiter.
+type RateLimiterConfig struct {
+	// Default applies to models without an entry in Models.
+	Default RateLimit
+	// Models maps model names to their own limits.
+	Models map[string]RateLimit
+	// MaxRetries is how often a rate limited request is retried before its
+	// error is returned. Default 5.
+	MaxRetries int
+	// BaseBackoff is the wait before the first retry when the API does not
+	// say how long to wait. It doubles with each retry. Default 2s.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between retries. Default 1m.
+	MaxBackoff time.Duration
+}
+
+// RateLimitEventType identifies what a RateLimitEvent reports.
+type RateLimitEventType string
+
+const (
+	// RateLimitQueued reports a request waiting for the model's limits.
+	RateLimitQueued RateLimitEventType = "queued"
+	// RateLimitThrottled reports a request the API rejected as rate
+	// limited, which is retried after a backoff.
+	RateLimitThrottled RateLimitEventType = "throttled"
+	// RateLimitExhausted reports a rate limited request that ran out of
+	// retries.
+	RateLimitExhausted RateLimitEventType = "exhausted"
+)
+
+// RateLimitStats counts the requests sent to one model.
+type RateLimitStats struct {
+	// Requests and Tokens are what was sent successfully.
+	Requests int64
+	Tokens   int64
+	// Queued counts requests that had to wait for the limits, and
+	// QueueTime is how long they waited in total.
+	Queued    int64
+	QueueTime time.Duration
+	// Throttled counts requests the API rejected as rate limited.
+	Throttled int64
+	// Waiting is how many requests are waiting right now.
+	Waiting int
+}
+
+// RateLimitEvent reports a request that was delayed or rejected by rate
+// limits.
+type RateLimitEvent struct {
+	Type  RateLimitEventType
+	Model string
+	// Wait is how long the request waits before it is sent or retried.
+	Wait time.Duration
+	// Attempt is the retry the event is about, 0 for the first try.
+	Attempt int
+	// Err is the API error (throttled and exhausted events only).
+	Err error
+	// Stats are the model's counts after the event.
+	Stats RateLimitStats
+}
+
+// RateLimiter paces the API requests of every runner that shares it, so
+// concurrent agents and validators queue for a model's request and token
+// budgets instead of failing on rate limit errors. It keeps a token bucket
+// per model for requests and tokens per minute: requests are admitted in
+// arrival order, and a request's tokens are charged when its response
+// arrives, delaying later requests once the budget runs out. A request the
+// API still rejects as rate limited pauses the model for every runner and is
+// retried after a jittered backoff.
+type RateLimiter struct {
+	cfg RateLimiterConfig
+
+	mu          sync.Mutex
+	buckets     map[string]*rateBucket
+	subscribers map[int]func(RateLimitEvent)
+	nextSubID   int
+}
+
+// rateBucket is the budget of one model.
+type rateBucket struct {
+	limit RateLimit
+	// requests and tokens are what is left of the budget. requests goes
+	// negative while requests are queued; tokens goes negative when
+	// responses used more than was left.
+	requests float64
+	tokens   float64
+	updated  time.Time
+	// pausedUntil holds back every request after the API rate limited one.
+	pausedUntil time.Time
+	stats       RateLimitStats
+}
+
+// Verify RateLimiter implements api.Limiter at compile time.
+var _ api.Limiter = (*RateLimiter)(nil)
+
+// NewRateLimiter creates a RateLimiter.
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 2 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	return &RateLimiter{
+		cfg:         cfg,
+		buckets:     make(map[string]*rateBucket),
+		subscribers: make(map[int]func(RateLimitEvent)),
+	}
+}
+
+// Subscribe calls fn with every rate limit event until the returned
+// function is called. fn must not block.
+func (l *RateLimiter) Subscribe(fn func(RateLimitEvent)) (unsubscribe func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextSubID
+	l.nextSubID++
+	l.subscribers[id] = fn
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, id)
+	}
+}
+
+// Stats returns the counts of requests sent to model.
+func (l *RateLimiter) Stats(model string) RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[model]; ok {
+		return b.stats
+	}
+	return RateLimitStats{}
+}
+
+// Do calls call once model's limits allow it and retries it while the API
+// rejects it as rate limited, up to MaxRetries times.
+func (l *RateLimiter) Do(ctx context.Context, model string, call func() (int64, error)) error {
+	for attempt := 0; ; attempt++ {
+		if err := l.acquire(ctx, model, attempt); err != nil {
+			return err
+		}
+		tokens, err := call()
+		if err == nil {
+			l.record(model, tokens)
+			return nil
+		}
+		retryAfter, limited := api.RetryAfter(err)
+		if !limited {
+			return err
+		}
+		if attempt >= l.cfg.MaxRetries {
+			l.emit(l.event(RateLimitEvent{Type: RateLimitExhausted, Model: model, Attempt: attempt, Err: err}, nil))
+			return err
+		}
+		l.throttle(model, l.backoff(retryAfter, attempt), attempt+1, err)
+	}
+}
+
+// acquire waits until a request to model may be sent.
+func (l *RateLimiter) acquire(ctx context.Context, model string, attempt int) error {
+	l.mu.Lock()
+	wait := l.reserve(model, time.Now())
+	if wait <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	b := l.buckets[model]
+	b.stats.Queued++
+	b.stats.Waiting++
+	event := l.event(RateLimitEvent{Type: RateLimitQueued, Model: model, Wait: wait, Attempt: attempt}, b)
+	l.mu.Unlock()
+	l.emit(event)
+
+	start := time.Now()
+	defer func() {
+		l.mu.Lock()
+		b.stats.Waiting--
+		b.stats.QueueTime += time.Since(start)
+		l.mu.Unlock()
+	}()
+	for wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.mu.Lock()
+			b.requests++ // Give back the reservation
+			l.mu.Unlock()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		// A rate limited response may have paused the model meanwhile
+		l.mu.Lock()
+		wait = time.Until(b.pausedUntil)
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// reserve takes a request from model's budget and returns how long the
+// request must wait for it. Callers hold l.mu.
+func (l *RateLimiter) reserve(model string, now time.Time) time.Duration {
+	b := l.bucket(model, now)
+	b.refill(now)
+
+	var wait time.Duration
+	if b.limit.RequestsPerMinute > 0 {
+		b.requests--
+		if b.requests < 0 {
+			wait = max(wait, perMinute(-b.requests, b.limit.RequestsPerMinute))
+		}
+	}
+	if b.limit.TokensPerMinute > 0 && b.tokens < 0 {
+		wait = max(wait, perMinute(-b.tokens, b.limit.TokensPerMinute))
+	}
+	return max(wait, b.pausedUntil.Sub(now))
+}
+
+// record charges a sent request's tokens to model's budget.
+func (l *RateLimiter) record(model string, tokens int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(model, time.Now())
+	b.refill(time.Now())
+	if b.limit.TokensPerMinute > 0 {
+		b.tokens -= float64(tokens)
+	}
+	b.stats.Requests++
+	b.stats.Tokens += tokens
+}
+
+// throttle pauses model for wait after the API rate limited a request.
+func (l *RateLimiter) throttle(model string, wait time.Duration, attempt int, err error) {
+	l.mu.Lock()
+	b := l.bucket(model, time.Now())
+	if until := time.Now().Add(wait); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.stats.Throttled++
+	event := l.event(RateLimitEvent{Type: RateLimitThrottled, Model: model, Wait: wait, Attempt: attempt, Err: err}, b)
+	l.mu.Unlock()
+	l.emit(event)
+}
+
+// backoff returns how long to wait before retry attempt+1: what the API
+// asked for, or an exponential backoff, with jitter so queued runners do
+// not retry in lockstep.
+func (l *RateLimiter) backoff(retryAfter time.Duration, attempt int) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter + time.Duration(rand.Float64()*float64(retryAfter)/4)
+	}
+	wait := l.cfg.BaseBackoff << attempt
+	if wait <= 0 || wait > l.cfg.MaxBackoff {
+		wait = l.cfg.MaxBackoff
+	}
+	return time.Duration((0.5 + rand.Float64()) * float64(wait))
+}
+
+// bucket returns model's bucket, creating it with a full budget. Callers
+// hold l.mu.
+func (l *RateLimiter) bucket(model string, now time.Time) *rateBucket {
+	b, ok := l.buckets[model]
+	if !ok {
+		limit, ok := l.cfg.Models[model]
+		if !ok {
+			limit = l.cfg.Default
+		}
+		b = &rateBucket{
+			limit:    limit,
+			requests: float64(limit.RequestsPerMinute),
+			tokens:   float64(limit.TokensPerMinute),
+			updated:  now,
+		}
+		l.buckets[model] = b
+	}
+	return b
+}
+
+// event completes e with the stats of b, if any. Callers hold l.mu.
+func (l *RateLimiter) event(e RateLimitEvent, b *rateBucket) RateLimitEvent {
+	if b == nil {
+		b = l.buckets[e.Model]
+	}
+	if b != nil {
+		e.Stats = b.stats
+	}
+	return e
+}
+
+// emit calls the subscribers with e.
+func (l *RateLimiter) emit(e RateLimitEvent) {
+	l.mu.Lock()
+	subscribers := make([]func(RateLimitEvent), 0, len(l.subscribers))
+	for _, fn := range l.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	l.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(e)
+	}
+}
+
+// refill adds the budget earned since the last update, up to a minute's.
+func (b *rateBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Minutes()
+	if elapsed <= 0 {
+		return
+	}
+	b.updated = now
+	b.requests = min(b.requests+elapsed*float64(b.limit.RequestsPerMinute), float64(b.limit.RequestsPerMinute))
+	b.tokens = min(b.tokens+elapsed*float64(b.limit.TokensPerMinute), float64(b.limit.TokensPerMinute))
+}
+
+// perMinute returns how long a budget of rate a minute takes to earn amount.
+func perMinute(amount float64, rate int) time.Duration {
+	return time.Duration(amount / float64(rate) * float64(time.Minute))
+}
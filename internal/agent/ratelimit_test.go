@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{
+		Default: RateLimit{RequestsPerMinute: 60},
+		Models:  map[string]RateLimit{"opus": {TokensPerMinute: 600}},
+	})
+	now := time.Now()
+
+	// A full minute's budget is available at once, then requests queue
+	for i := 0; i < 60; i++ {
+		if wait := l.reserve("sonnet", now); wait != 0 {
+			t.Fatalf("request %d waits %s, want none", i+1, wait)
+		}
+	}
+	if wait := l.reserve("sonnet", now); wait != time.Second {
+		t.Errorf("61st request waits %s, want 1s", wait)
+	}
+	if wait := l.reserve("sonnet", now); wait != 2*time.Second {
+		t.Errorf("62nd request waits %s, want 2s", wait)
+	}
+
+	// Tokens are charged when the response arrives and hold back later requests
+	if wait := l.reserve("opus", now); wait != 0 {
+		t.Errorf("first opus request waits %s, want none", wait)
+	}
+	l.record("opus", 660)
+	if wait := l.reserve("opus", time.Now()); wait <= 5*time.Second || wait > 6*time.Second {
+		t.Errorf("opus request after overspending waits %s, want about 6s", wait)
+	}
+	if stats := l.Stats("opus"); stats.Requests != 1 || stats.Tokens != 660 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestRateLimiter_Do(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+	var events []RateLimitEvent
+	unsubscribe := l.Subscribe(func(e RateLimitEvent) { events = append(events, e) })
+	defer unsubscribe()
+
+	rateLimited := &anthropic.Error{StatusCode: 429}
+
+	calls := 0
+	err := l.Do(context.Background(), "sonnet", func() (int64, error) {
+		calls++
+		if calls < 3 {
+			return 0, rateLimited
+		}
+		return 100, nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Do() = %v after %d calls, want success after 3", err, calls)
+	}
+	throttled := 0
+	for _, e := range events {
+		if e.Type == RateLimitThrottled {
+			throttled++
+		}
+	}
+	if throttled != 2 {
+		t.Errorf("got %d throttled events, want 2: %+v", throttled, events)
+	}
+	if stats := l.Stats("sonnet"); stats.Throttled != 2 || stats.Requests != 1 || stats.Tokens != 100 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	// Out of retries
+	calls = 0
+	err = l.Do(context.Background(), "sonnet", func() (int64, error) {
+		calls++
+		return 0, rateLimited
+	})
+	if !errors.Is(err, rateLimited) || calls != 3 {
+		t.Errorf("Do() = %v after %d calls, want the rate limit error after 3", err, calls)
+	}
+	if last := events[len(events)-1]; last.Type != RateLimitExhausted {
+		t.Errorf("last event = %s, want exhausted", last.Type)
+	}
+
+	// Other errors are not retried
+	calls = 0
+	failure := errors.New("bad request")
+	if err := l.Do(context.Background(), "sonnet", func() (int64, error) { calls++; return 0, failure }); err != failure || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want the error after 1", err, calls)
+	}
+}
+
+func TestRateLimiter_AcquireCanceled(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{Default: RateLimit{RequestsPerMinute: 1}})
+	if err := l.acquire(context.Background(), "sonnet", 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx, "sonnet", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() = %v, want the context's error", err)
+	}
+	if stats := l.Stats("sonnet"); stats.Queued != 1 || stats.Waiting != 0 {
+		t.Errorf("Stats() = %+v, want one request queued and none waiting", stats)
+	}
+}
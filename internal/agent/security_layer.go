@@ -0,0 +1,404 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/redact"
+)
+
+// Kinds of security findings.
+const (
+	// SecurityFindingSecret is a secret added in the diff.
+	SecurityFindingSecret = "secret"
+	// SecurityFindingVulnerability is a known vulnerability in a dependency.
+	SecurityFindingVulnerability = "vulnerability"
+)
+
+// SecurityFinding is an issue the security layer found in a task's changes.
+type SecurityFinding struct {
+	// Kind is SecurityFindingSecret or SecurityFindingVulnerability.
+	Kind string
+	// Rule is the redaction rule that found a secret, or the advisory ID
+	// of a vulnerability.
+	Rule string
+	// Severity is critical for secrets and the advisory's severity for
+	// vulnerabilities.
+	Severity string
+	// File is the file the secret was added to, or the manifest whose
+	// dependencies were audited.
+	File string
+	// Line is the line the secret was added on. Zero for vulnerabilities.
+	Line int
+	// Message describes the finding. It never contains the secret.
+	Message string
+	// Blocking is true if the finding fails the layer: every secret, and
+	// critical vulnerabilities in dependencies the task added or changed.
+	Blocking bool
+}
+
+// String describes the finding for the layer's detail and failure context.
+func (f SecurityFinding) String() string {
+	loc := f.File
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Severity, loc, f.Message)
+}
+
+// addedLine is a line the agent added to a file.
+type addedLine struct {
+	File string
+	Line int
+	Text string
+}
+
+// hunkHeader matches a unified diff hunk header, capturing the new start line.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// worktreeAddedLines returns the lines added in the worktree since its last
+// commit. Untracked files are added in full.
+func worktreeAddedLines(dir string) []addedLine {
+	var lines []addedLine
+	cmd := exec.Command("git", "diff", "HEAD", "-U0", "--no-color", "--no-ext-diff")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		lines = append(lines, parseAddedLines(string(out))...)
+	}
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard", "-z")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return lines
+	}
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil || strings.IndexByte(string(data), 0) >= 0 {
+			continue // Unreadable or binary
+		}
+		for i, text := range strings.Split(string(data), "\n") {
+			lines = append(lines, addedLine{File: filepath.ToSlash(f), Line: i + 1, Text: text})
+		}
+	}
+	return lines
+}
+
+// parseAddedLines extracts the added lines from a unified diff.
+func parseAddedLines(diff string) []addedLine {
+	var lines []addedLine
+	file, next := "", 0
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(line, "@@"):
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				next, _ = strconv.Atoi(m[1])
+			}
+		case strings.HasPrefix(line, "+") && file != "":
+			lines = append(lines, addedLine{File: file, Line: next, Text: line[1:]})
+			next++
+		case strings.HasPrefix(line, " "):
+			next++
+		}
+	}
+	return lines
+}
+
+// scanSecrets returns a finding for each added line containing a secret.
+func scanSecrets(lines []addedLine, r *redact.Redactor) []SecurityFinding {
+	var findings []SecurityFinding
+	for _, l := range lines {
+		for _, rule := range r.Find(l.Text) {
+			findings = append(findings, SecurityFinding{
+				Kind:     SecurityFindingSecret,
+				Rule:     rule,
+				Severity: "critical",
+				File:     l.File,
+				Line:     l.Line,
+				Message:  fmt.Sprintf("possible secret added (%s)", rule),
+				Blocking: true,
+			})
+		}
+	}
+	return findings
+}
+
+// Dependency manifests whose changes trigger an audit.
+var (
+	goManifests   = map[string]bool{"go.mod": true, "go.sum": true}
+	nodeManifests = map[string]bool{"package.json": true, "package-lock.json": true}
+)
+
+// changedManifestDirs returns the directories of the changed Go and Node
+// manifests.
+func changedManifestDirs(changed []string) (goDirs, nodeDirs []string) {
+	seenGo, seenNode := make(map[string]bool), make(map[string]bool)
+	for _, f := range changed {
+		f = filepath.ToSlash(f)
+		dir, base := path.Dir(f), path.Base(f)
+		switch {
+		case goManifests[base] && !seenGo[dir]:
+			seenGo[dir] = true
+			goDirs = append(goDirs, dir)
+		case nodeManifests[base] && !seenNode[dir]:
+			seenNode[dir] = true
+			nodeDirs = append(nodeDirs, dir)
+		}
+	}
+	sort.Strings(goDirs)
+	sort.Strings(nodeDirs)
+	return goDirs, nodeDirs
+}
+
+// manifestAdditions joins the lines added to the manifests in dir, so an
+// audit can tell dependencies the task touched from ones it inherited.
+func manifestAdditions(lines []addedLine, dir string, manifests map[string]bool) string {
+	var b strings.Builder
+	for _, l := range lines {
+		if path.Dir(l.File) == dir && manifests[path.Base(l.File)] {
+			b.WriteString(l.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// auditGo runs govulncheck in a Go module. Vulnerabilities the module's
+// code calls are critical, and block if their module was added or changed.
+func auditGo(ctx context.Context, runner iexec.CommandRunner, workDir, dir, additions string) ([]SecurityFinding, error) {
+	out, err := runner.Run(ctx, filepath.Join(workDir, dir), "govulncheck", "-format", "json", "./...")
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, err
+	}
+	findings, parseErr := parseGovulncheck(out, path.Join(dir, "go.mod"), additions)
+	if parseErr != nil {
+		return nil, fmt.Errorf("govulncheck: %w", parseErr)
+	}
+	return findings, nil
+}
+
+// parseGovulncheck parses govulncheck's JSON stream. Only vulnerabilities
+// with a call trace into the vulnerable symbol are reported.
+func parseGovulncheck(output []byte, manifest, additions string) ([]SecurityFinding, error) {
+	type frame struct {
+		Module   string `json:"module"`
+		Version  string `json:"version"`
+		Function string `json:"function"`
+	}
+	var message struct {
+		OSV *struct {
+			ID      string   `json:"id"`
+			Aliases []string `json:"aliases"`
+			Summary string   `json:"summary"`
+		} `json:"osv"`
+		Finding *struct {
+			OSV          string  `json:"osv"`
+			FixedVersion string  `json:"fixed_version"`
+			Trace        []frame `json:"trace"`
+		} `json:"finding"`
+	}
+
+	summaries := make(map[string]string)
+	seen := make(map[string]bool)
+	var findings []SecurityFinding
+	dec := json.NewDecoder(strings.NewReader(string(output)))
+	for {
+		message.OSV, message.Finding = nil, nil
+		if err := dec.Decode(&message); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if o := message.OSV; o != nil {
+			summaries[o.ID] = strings.TrimSpace(strings.Join(append([]string{o.Summary}, o.Aliases...), " "))
+			continue
+		}
+		f := message.Finding
+		if f == nil || len(f.Trace) == 0 || f.Trace[0].Function == "" || seen[f.OSV] {
+			continue
+		}
+		seen[f.OSV] = true
+		module := f.Trace[0].Module
+		msg := fmt.Sprintf("%s %s is affected by %s", module, f.Trace[0].Version, f.OSV)
+		if f.FixedVersion != "" {
+			msg += ", fixed in " + f.FixedVersion
+		}
+		findings = append(findings, SecurityFinding{
+			Kind:     SecurityFindingVulnerability,
+			Rule:     f.OSV,
+			Severity: "critical",
+			File:     manifest,
+			Message:  msg,
+			Blocking: strings.Contains(additions, module+" "),
+		})
+	}
+	for i := range findings {
+		if s := summaries[findings[i].Rule]; s != "" {
+			findings[i].Message += " (" + s + ")"
+		}
+	}
+	return findings, nil
+}
+
+// auditNode runs npm audit in a Node project. Critical vulnerabilities
+// block if their package was added or changed.
+func auditNode(ctx context.Context, runner iexec.CommandRunner, workDir, dir, additions string) ([]SecurityFinding, error) {
+	out, err := runner.Run(ctx, filepath.Join(workDir, dir), "npm", "audit", "--json")
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, err
+	}
+	findings, parseErr := parseNpmAudit(out, path.Join(dir, "package.json"), additions)
+	if parseErr != nil {
+		return nil, fmt.Errorf("npm audit: %w", parseErr)
+	}
+	return findings, nil
+}
+
+// parseNpmAudit parses npm audit's JSON report.
+func parseNpmAudit(output []byte, manifest, additions string) ([]SecurityFinding, error) {
+	start := strings.Index(string(output), "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no JSON report in output: %s", outputTail(string(output), 5))
+	}
+	var report struct {
+		Vulnerabilities map[string]struct {
+			Severity string            `json:"severity"`
+			Via      []json.RawMessage `json:"via"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(output[start:], &report); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(report.Vulnerabilities))
+	for name := range report.Vulnerabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []SecurityFinding
+	for _, name := range names {
+		v := report.Vulnerabilities[name]
+		rule, title := name, ""
+		for _, via := range v.Via {
+			var advisory struct {
+				Title string `json:"title"`
+				URL   string `json:"url"`
+			}
+			// Entries that are package names point at another vulnerability
+			if json.Unmarshal(via, &advisory) == nil && advisory.Title != "" {
+				rule, title = advisory.URL, advisory.Title
+				break
+			}
+		}
+		msg := fmt.Sprintf("%s has a %s vulnerability", name, v.Severity)
+		if title != "" {
+			msg += ": " + title
+		}
+		touched := strings.Contains(additions, `"`+name+`"`) || strings.Contains(additions, `node_modules/`+name+`"`)
+		findings = append(findings, SecurityFinding{
+			Kind:     SecurityFindingVulnerability,
+			Rule:     rule,
+			Severity: v.Severity,
+			File:     manifest,
+			Message:  msg,
+			Blocking: v.Severity == "critical" && touched,
+		})
+	}
+	return findings, nil
+}
+
+// runSecurityLayer scans the task's added lines for secrets and, if it
+// changed dependency manifests, audits the dependencies. Findings are
+// recorded on the result; blocking ones fail the layer.
+func (e *Executor) runSecurityLayer(ctx context.Context, run *validationRun) (ValidationOutcome, string) {
+	changed := worktreeChangedFiles(run.worktreePath)
+	if len(changed) == 0 {
+		return ValidationOutcome{Skipped: true, Detail: "no changed files"}, ""
+	}
+	lines := worktreeAddedLines(run.worktreePath)
+
+	scanner := redact.Default()
+	if scanner == nil {
+		// Redaction being off does not turn off secret scanning
+		scanner, _ = redact.New(redact.Config{})
+	}
+	findings := scanSecrets(lines, scanner)
+
+	runner := run.commands()
+	if runner == nil {
+		runner = iexec.NewRunner()
+	}
+	var notes []string
+	goDirs, nodeDirs := changedManifestDirs(changed)
+	for _, dir := range goDirs {
+		found, err := auditGo(ctx, runner, run.worktreePath, dir, manifestAdditions(lines, dir, goManifests))
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: dependency audit did not run: %v", path.Join(dir, "go.mod"), err))
+		}
+		findings = append(findings, found...)
+	}
+	for _, dir := range nodeDirs {
+		found, err := auditNode(ctx, runner, run.worktreePath, dir, manifestAdditions(lines, dir, nodeManifests))
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: dependency audit did not run: %v", path.Join(dir, "package.json"), err))
+		}
+		findings = append(findings, found...)
+	}
+	run.result.SecurityFindings = findings
+
+	var blocking, other []string
+	for _, f := range findings {
+		if f.Blocking {
+			blocking = append(blocking, "- "+f.String())
+		} else {
+			other = append(other, "- "+f.String())
+		}
+	}
+	var detail strings.Builder
+	if len(blocking) > 0 {
+		fmt.Fprintf(&detail, "%d blocking security finding(s):\n%s", len(blocking), strings.Join(blocking, "\n"))
+	}
+	if len(other) > 0 {
+		if detail.Len() > 0 {
+			detail.WriteString("\n")
+		}
+		fmt.Fprintf(&detail, "%d non-blocking finding(s):\n%s", len(other), strings.Join(other, "\n"))
+	}
+	for _, n := range notes {
+		if detail.Len() > 0 {
+			detail.WriteString("\n")
+		}
+		detail.WriteString(n)
+	}
+
+	if len(blocking) > 0 {
+		return ValidationOutcome{Detail: detail.String()},
+			fmt.Sprintf("security validation failed: %d blocking finding(s)", len(blocking))
+	}
+	return ValidationOutcome{Passed: true, Detail: detail.String()}, ""
+}
@@ -2,22 +2,25 @@
 package agent
 
 import (
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
 // FocusedTestSelector selects tests relevant to changed files.
-// It uses co-located test patterns (file.go -> file_test.go),
+// It uses co-located test patterns (file.go -> file_test.go, with
+// JavaScript/TypeScript and Python strategies in testselect_lang.go),
 // package scope expansion when insufficient tests are found,
-// and tag-based test selection for path prefix to test tag mapping.
+// tag-based test selection for path prefix to test tag mapping,
+// and the Go import graph for tests of packages importing changed code.
 type FocusedTestSelector struct {
 	repoPath   string
 	minTests   int
 	tagMapping map[string][]string // pathPrefix → test tags
+
+	// graph is the module's import graph, loaded on first use.
+	graph       *ImportGraph
+	graphLoaded bool
 }
 
 // DefaultTagMapping returns the default path prefix to test tag mappings.
@@ -90,21 +93,26 @@ func (f *FocusedTestSelector) SelectTests(changedFiles []string) ([]string, erro
 
 // SelectTestsWithTags returns test selection results including both test files
 // and test tags relevant to the given changed files.
-// It follows a 3-level selection strategy:
-//  1. Co-located tests: file.go -> file_test.go
-//  2. Package scope: expand to all tests in the package if < minTests found
+// It follows a 4-level selection strategy:
+//  1. Co-located tests: file.go -> file_test.go, file.ts -> file.test.ts or a
+//     mirror under the jest/vitest roots, file.py -> test_file.py or a mirror under tests/
+//  2. Package scope: expand to all tests in the package (or, for JS/TS and
+//     Python, the file's directory or mirrored test directory) if < minTests found
 //  3. Tag-based: map path prefixes to test tags (e.g., src/auth/* -> @auth)
+//  4. Import graph: tests of every package that transitively imports a changed Go package
 func (f *FocusedTestSelector) SelectTestsWithTags(changedFiles []string) (*SelectTestResult, error) {
 	testFiles := make(map[string]struct{})
 	testTags := make(map[string]struct{})
 
-	// Step 1: Find co-located tests for each changed file
+	// Work with slash-separated paths, as git reports them, on every platform
+	changedFiles = toSlashPaths(changedFiles)
+
+	// Step 1: Find co-located (or, for JS/TS and Python, mirrored) tests for each changed file
 	for _, file := range changedFiles {
-		colocated := f.GetColocated(file)
-		if colocated != "" {
-			fullPath := filepath.Join(f.repoPath, colocated)
+		for _, candidate := range f.testCandidates(file) {
+			fullPath := filepath.Join(f.repoPath, candidate)
 			if _, err := os.Stat(fullPath); err == nil {
-				testFiles[colocated] = struct{}{}
+				testFiles[candidate] = struct{}{}
 			}
 		}
 	}
@@ -124,6 +132,9 @@ func (f *FocusedTestSelector) SelectTestsWithTags(changedFiles []string) (*Selec
 					testFiles[t] = struct{}{}
 				}
 			}
+			for _, t := range f.scopeTests(file) {
+				testFiles[t] = struct{}{}
+			}
 		}
 	}
 
@@ -135,9 +146,25 @@ func (f *FocusedTestSelector) SelectTestsWithTags(changedFiles []string) (*Selec
 		}
 	}
 
-	// Convert maps to slices
+	// Step 4: Add tests of packages that import the changed Go packages
+	importerTests, err := f.GetImporterTests(changedFiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range importerTests {
+		testFiles[t] = struct{}{}
+	}
+
+	// Convert maps to slices; the same test may have been found with
+	// either separator on Windows
 	fileResult := make([]string, 0, len(testFiles))
+	seenFiles := make(map[string]struct{}, len(testFiles))
 	for t := range testFiles {
+		t = filepath.ToSlash(t)
+		if _, seen := seenFiles[t]; seen {
+			continue
+		}
+		seenFiles[t] = struct{}{}
 		fileResult = append(fileResult, t)
 	}
 
@@ -152,6 +179,15 @@ func (f *FocusedTestSelector) SelectTestsWithTags(changedFiles []string) (*Selec
 	}, nil
 }
 
+// toSlashPaths returns paths with slash separators.
+func toSlashPaths(paths []string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = filepath.ToSlash(p)
+	}
+	return out
+}
+
 // GetColocated returns the co-located test file for a given source file.
 // For example, handler.go -> handler_test.go
 // Returns empty string if the file is already a test file or not a Go file.
@@ -279,148 +315,9 @@ func BuildTestRunPattern(tags []string) string {
 	return "Test.*(" + strings.Join(tags, "|") + ")"
 }
 
-// GetCallerTests finds tests for functions that call exported functions in the changed file.
-// It parses Go files in the repo, finds call sites of exported functions from the changed file,
-// and returns the test files for those calling packages.
+// GetCallerTests returns the tests of packages that depend on the changed
+// file's package, as determined by the Go import graph (see GetImporterTests).
+// Returns nil for test files, non-Go files and repositories that are not Go modules.
 func (f *FocusedTestSelector) GetCallerTests(changedFile string) ([]string, error) {
-	// Skip non-Go files and test files
-	if !strings.HasSuffix(changedFile, ".go") || strings.HasSuffix(changedFile, "_test.go") {
-		return nil, nil
-	}
-
-	// Extract exported function names from the changed file
-	exportedFuncs, err := f.getExportedFunctions(changedFile)
-	if err != nil || len(exportedFuncs) == 0 {
-		return nil, err
-	}
-
-	// Find files that call these exported functions
-	callerFiles, err := f.findCallers(exportedFuncs)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get test files for the caller files
-	testFiles := make(map[string]struct{})
-	for _, caller := range callerFiles {
-		colocated := f.GetColocated(caller)
-		if colocated != "" {
-			fullPath := filepath.Join(f.repoPath, colocated)
-			if _, err := os.Stat(fullPath); err == nil {
-				testFiles[colocated] = struct{}{}
-			}
-		}
-	}
-
-	result := make([]string, 0, len(testFiles))
-	for t := range testFiles {
-		result = append(result, t)
-	}
-
-	return result, nil
-}
-
-// getExportedFunctions parses a Go file and returns the names of exported functions.
-func (f *FocusedTestSelector) getExportedFunctions(file string) ([]string, error) {
-	fullPath := filepath.Join(f.repoPath, file)
-
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, fullPath, nil, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var exported []string
-	for _, decl := range node.Decls {
-		if fn, ok := decl.(*ast.FuncDecl); ok {
-			// Skip methods (have a receiver)
-			if fn.Recv != nil {
-				continue
-			}
-			name := fn.Name.Name
-			// Check if exported (starts with uppercase)
-			if len(name) > 0 && name[0] >= 'A' && name[0] <= 'Z' {
-				exported = append(exported, name)
-			}
-		}
-	}
-
-	return exported, nil
-}
-
-// findCallers searches all Go files in the repo for calls to the given function names.
-func (f *FocusedTestSelector) findCallers(funcNames []string) ([]string, error) {
-	funcSet := make(map[string]struct{}, len(funcNames))
-	for _, name := range funcNames {
-		funcSet[name] = struct{}{}
-	}
-
-	var callers []string
-
-	err := filepath.Walk(f.repoPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-
-		// Skip directories and non-Go files
-		if info.IsDir() {
-			// Skip common non-source directories
-			name := info.Name()
-			if name == ".git" || name == "vendor" || name == "node_modules" || name == ".worktrees" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		// Parse the file and look for calls
-		fset := token.NewFileSet()
-		node, err := parser.ParseFile(fset, path, nil, 0)
-		if err != nil {
-			return nil // Skip files that don't parse
-		}
-
-		found := false
-		ast.Inspect(node, func(n ast.Node) bool {
-			if found {
-				return false
-			}
-			call, ok := n.(*ast.CallExpr)
-			if !ok {
-				return true
-			}
-
-			// Check for direct function call: FuncName()
-			if ident, ok := call.Fun.(*ast.Ident); ok {
-				if _, exists := funcSet[ident.Name]; exists {
-					found = true
-					return false
-				}
-			}
-
-			// Check for package-qualified call: pkg.FuncName()
-			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
-				if _, exists := funcSet[sel.Sel.Name]; exists {
-					found = true
-					return false
-				}
-			}
-
-			return true
-		})
-
-		if found {
-			relPath, err := filepath.Rel(f.repoPath, path)
-			if err == nil {
-				callers = append(callers, relPath)
-			}
-		}
-
-		return nil
-	})
-
-	return callers, err
+	return f.GetImporterTests([]string{changedFile})
 }
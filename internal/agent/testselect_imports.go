@@ -0,0 +1,243 @@
+// Package agent provides the AI agent implementation for Alphie.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// importGraphTimeout bounds the go list call that builds the import graph.
+const importGraphTimeout = 2 * time.Minute
+
+// goListPackage is the subset of `go list -json` output used by ImportGraph.
+type goListPackage struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// ImportGraph is the reverse import graph of a Go module's packages. It
+// answers which packages are affected when other packages change.
+type ImportGraph struct {
+	// packages maps import paths to the module's packages.
+	packages map[string]*goListPackage
+	// dirs maps import paths to repository-relative directories.
+	dirs map[string]string
+	// byDir maps repository-relative directories to import paths.
+	byDir map[string]string
+	// importers maps an import path to the packages importing it.
+	importers map[string][]string
+	// testImporters maps an import path to the packages whose tests import it.
+	testImporters map[string][]string
+}
+
+// LoadImportGraph builds the import graph of the Go module at repoPath by
+// running go list over every package in it.
+func LoadImportGraph(repoPath string) (*ImportGraph, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), importGraphTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-e",
+		"-json=ImportPath,Dir,Imports,TestImports,XTestImports,TestGoFiles,XTestGoFiles", "./...")
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseImportGraph(repoPath, out)
+}
+
+// parseImportGraph builds an ImportGraph from a stream of `go list -json` objects.
+func parseImportGraph(repoPath string, data []byte) (*ImportGraph, error) {
+	// go list reports absolute directories with symlinks resolved
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	g := &ImportGraph{
+		packages:      make(map[string]*goListPackage),
+		dirs:          make(map[string]string),
+		byDir:         make(map[string]string),
+		importers:     make(map[string][]string),
+		testImporters: make(map[string][]string),
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var pkg goListPackage
+		err := dec.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse go list output: %w", err)
+		}
+		if pkg.ImportPath == "" || pkg.Dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, pkg.Dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		g.packages[pkg.ImportPath] = &pkg
+		g.dirs[pkg.ImportPath] = rel
+		g.byDir[filepath.ToSlash(rel)] = pkg.ImportPath
+	}
+
+	for path, pkg := range g.packages {
+		for _, imp := range pkg.Imports {
+			g.importers[imp] = append(g.importers[imp], path)
+		}
+		for _, imp := range pkg.TestImports {
+			g.testImporters[imp] = append(g.testImporters[imp], path)
+		}
+		for _, imp := range pkg.XTestImports {
+			g.testImporters[imp] = append(g.testImporters[imp], path)
+		}
+	}
+	return g, nil
+}
+
+// PackageForDir returns the import path of the package in a repository-relative
+// directory, or "" if the directory holds no package of the module.
+func (g *ImportGraph) PackageForDir(dir string) string {
+	return g.byDir[filepath.ToSlash(filepath.Clean(dir))]
+}
+
+// Importers returns the packages whose tests may break when the changed
+// packages change: every package that imports one of them directly or
+// transitively, and every package whose tests import one of those. Test-only
+// imports do not propagate further, since test code is not importable. The
+// changed packages themselves are not included.
+func (g *ImportGraph) Importers(changed []string) []string {
+	isChanged := make(map[string]bool, len(changed))
+	affected := make(map[string]bool)
+	var queue []string
+	for _, path := range changed {
+		if _, ok := g.packages[path]; ok && !affected[path] {
+			isChanged[path] = true
+			affected[path] = true
+			queue = append(queue, path)
+		}
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, importer := range g.importers[path] {
+			if !affected[importer] {
+				affected[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	result := make(map[string]bool, len(affected))
+	for path := range affected {
+		result[path] = true
+		for _, importer := range g.testImporters[path] {
+			result[importer] = true
+		}
+	}
+
+	paths := make([]string, 0, len(result))
+	for path := range result {
+		if !isChanged[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TestFiles returns the test files of the given packages, relative to the
+// repository root.
+func (g *ImportGraph) TestFiles(packages []string) []string {
+	var files []string
+	for _, path := range packages {
+		pkg, ok := g.packages[path]
+		if !ok {
+			continue
+		}
+		for _, name := range pkg.TestGoFiles {
+			files = append(files, filepath.Join(g.dirs[path], name))
+		}
+		for _, name := range pkg.XTestGoFiles {
+			files = append(files, filepath.Join(g.dirs[path], name))
+		}
+	}
+	return files
+}
+
+// importGraph returns the repository's import graph, loading it on first
+// use. It returns nil if the repository is not a Go module or go list fails;
+// the failure is remembered so the graph is not rebuilt on every call.
+func (f *FocusedTestSelector) importGraph() *ImportGraph {
+	if f.graphLoaded {
+		return f.graph
+	}
+	f.graphLoaded = true
+	if _, err := os.Stat(filepath.Join(f.repoPath, "go.mod")); err != nil {
+		return nil
+	}
+	graph, err := LoadImportGraph(f.repoPath)
+	if err != nil {
+		log.Printf("[testselect] import graph unavailable: %v", err)
+		return nil
+	}
+	f.graph = graph
+	return graph
+}
+
+// GetImporterTests returns the test files of every package that imports a
+// package containing one of the changed Go files, directly or transitively
+// (see ImportGraph.Importers),
+// using the module's import graph. Changed test files are ignored because
+// test code cannot be imported. Returns nil if the import graph cannot be built.
+func (f *FocusedTestSelector) GetImporterTests(changedFiles []string) ([]string, error) {
+	var dirs []string
+	for _, file := range changedFiles {
+		if strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go") {
+			dirs = append(dirs, filepath.Dir(file))
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	graph := f.importGraph()
+	if graph == nil {
+		return nil, nil
+	}
+
+	var changed []string
+	for _, dir := range dirs {
+		if path := graph.PackageForDir(dir); path != "" {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return graph.TestFiles(graph.Importers(changed)), nil
+}
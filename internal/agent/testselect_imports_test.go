@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeGoMod makes dir the root of the example.com/m module.
+func writeGoMod(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+}
+
+func TestImportGraph_Importers(t *testing.T) {
+	graph, err := parseImportGraph("/repo", []byte(`
+{"ImportPath": "m/core", "Dir": "/repo/core", "TestGoFiles": ["core_test.go"]}
+{"ImportPath": "m/store", "Dir": "/repo/store", "Imports": ["m/core"], "TestGoFiles": ["store_test.go"]}
+{"ImportPath": "m/api", "Dir": "/repo/api", "Imports": ["m/store", "fmt"], "XTestGoFiles": ["api_ext_test.go"]}
+{"ImportPath": "m/fixtures", "Dir": "/repo/fixtures", "Imports": ["m/core"]}
+{"ImportPath": "m/cli", "Dir": "/repo/cli", "TestImports": ["m/api"], "TestGoFiles": ["cli_test.go"]}
+{"ImportPath": "m/tools", "Dir": "/repo/tools", "Imports": ["m/cli"], "TestGoFiles": ["tools_test.go"]}
+{"ImportPath": "m/other", "Dir": "/repo/other", "TestGoFiles": ["other_test.go"]}
+`))
+	if err != nil {
+		t.Fatalf("parseImportGraph() error = %v", err)
+	}
+
+	if got := graph.PackageForDir("store/"); got != "m/store" {
+		t.Errorf("PackageForDir(store/) = %q, want m/store", got)
+	}
+
+	// cli's tests import api, but tools imports cli's non-test code only
+	importers := graph.Importers([]string{"m/core"})
+	want := []string{"m/api", "m/cli", "m/fixtures", "m/store"}
+	if !reflect.DeepEqual(importers, want) {
+		t.Errorf("Importers(m/core) = %v, want %v", importers, want)
+	}
+
+	files := graph.TestFiles(importers)
+	sort.Strings(files)
+	wantFiles := []string{"api/api_ext_test.go", "cli/cli_test.go", "store/store_test.go"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("TestFiles() = %v, want %v", files, wantFiles)
+	}
+}
+
+func TestFocusedTestSelector_ImportGraphTransitive(t *testing.T) {
+	dir := t.TempDir()
+	writeGoMod(t, dir)
+	writeRepoFiles(t, dir, map[string]string{
+		"core/core.go":        "package core\n\nfunc Value() int { return 1 }\n",
+		"core/core_test.go":   "package core\n",
+		"store/store.go":      "package store\n\nimport \"example.com/m/core\"\n\nvar V = core.Value()\n",
+		"store/store_test.go": "package store\n",
+		"api/api.go":          "package api\n\nimport _ \"example.com/m/store\"\n",
+		"api/api_test.go":     "package api\n",
+		"other/other.go":      "package other\n",
+		"other/other_test.go": "package other\n",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	selector.SetMinTests(1)
+
+	got := selectSorted(t, selector, "core/core.go")
+	want := []string{"api/api_test.go", "core/core_test.go", "store/store_test.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+}
+
+func TestFocusedTestSelector_ImportGraphWithoutModule(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{"pkg/a.go": "package pkg\n"})
+
+	selector := NewFocusedTestSelector(dir)
+	tests, err := selector.GetImporterTests([]string{"pkg/a.go"})
+	if err != nil || tests != nil {
+		t.Errorf("GetImporterTests() = %v, %v; want nil without a go.mod", tests, err)
+	}
+}
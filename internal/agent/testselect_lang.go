@@ -0,0 +1,269 @@
+// Package agent provides the AI agent implementation for Alphie.
+package agent
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// jsExtensions are the source extensions handled by the JavaScript/TypeScript strategy.
+var jsExtensions = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// jsTestSuffixes mark JavaScript/TypeScript test files (handler.test.ts, handler.spec.js).
+var jsTestSuffixes = []string{".test", ".spec"}
+
+// jsTestConfigFiles are the jest and vitest config files read for test roots.
+var jsTestConfigFiles = []string{
+	"jest.config.js", "jest.config.ts", "jest.config.mjs", "jest.config.cjs", "jest.config.json",
+	"vitest.config.ts", "vitest.config.js", "vitest.config.mts", "vitest.config.mjs",
+	"vite.config.ts", "vite.config.js", "vite.config.mts", "vite.config.mjs",
+}
+
+// defaultJSTestRoots are conventional test directories next to package.json.
+var defaultJSTestRoots = []string{"test", "tests", "__tests__"}
+
+// defaultPythonTestRoots are conventional test directories mirroring the source tree.
+var defaultPythonTestRoots = []string{"tests", "test"}
+
+var (
+	// jsRootsPattern matches jest "roots", "testMatch" and vitest "include" arrays.
+	jsRootsPattern = regexp.MustCompile(`["']?(?:roots|testMatch|include)["']?\s*:\s*\[([^\]]*)\]`)
+	// quotedPattern matches a quoted string literal.
+	quotedPattern = regexp.MustCompile(`["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`)
+)
+
+// testCandidates returns the test files that may cover file, relative to
+// the repository root, using the strategy for its language. Candidates are
+// not checked for existence. A test file is its own candidate.
+func (f *FocusedTestSelector) testCandidates(file string) []string {
+	if colocated := f.GetColocated(file); colocated != "" {
+		return []string{colocated}
+	}
+	switch {
+	case isJSFile(file):
+		return f.jsTestCandidates(filepath.ToSlash(file))
+	case strings.HasSuffix(file, ".py"):
+		return f.pythonTestCandidates(filepath.ToSlash(file))
+	}
+	return nil
+}
+
+// scopeTests returns the wider set of tests for a non-Go file, used when
+// too few candidates exist: every test next to the file for
+// JavaScript/TypeScript, and the mirrored test directory for Python.
+func (f *FocusedTestSelector) scopeTests(file string) []string {
+	file = filepath.ToSlash(file)
+	switch {
+	case isJSFile(file):
+		dir := path.Dir(file)
+		tests := f.listTests(dir, isJSTestFile)
+		return append(tests, f.listTests(path.Join(dir, "__tests__"), isJSFile)...)
+	case strings.HasSuffix(file, ".py"):
+		dir := path.Dir(file)
+		if isPythonTestFile(file) {
+			return f.listTests(dir, isPythonTestFile)
+		}
+		for _, root := range defaultPythonTestRoots {
+			for _, suffix := range pathSuffixes(dir) {
+				if tests := f.listTests(path.Join(root, suffix), isPythonTestFile); len(tests) > 0 {
+					return tests
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsTestCandidates maps a JavaScript/TypeScript file to co-located tests
+// (handler.test.ts, handler.spec.ts, __tests__/handler.ts) and to mirrored
+// tests under the jest/vitest roots of its project.
+func (f *FocusedTestSelector) jsTestCandidates(file string) []string {
+	if isJSTestFile(file) {
+		return []string{file}
+	}
+	dir, base := path.Split(file)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+
+	var candidates []string
+	for _, name := range jsTestNames(stem) {
+		candidates = append(candidates, path.Join(dir, name), path.Join(dir, "__tests__", name))
+	}
+	for _, ext := range jsExtensions {
+		candidates = append(candidates, path.Join(dir, "__tests__", stem+ext))
+	}
+
+	projectDir := f.jsProjectDir(dir)
+	rel := path.Dir(file)
+	if projectDir != "." {
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, projectDir), "/")
+	}
+	for _, root := range f.jsTestRoots(projectDir) {
+		for _, suffix := range pathSuffixes(rel) {
+			for _, name := range jsTestNames(stem) {
+				candidates = append(candidates, path.Join(root, suffix, name))
+			}
+		}
+	}
+	return candidates
+}
+
+// jsProjectDir returns the nearest directory at or above dir that holds a
+// package.json, so each package of a monorepo uses its own test config.
+func (f *FocusedTestSelector) jsProjectDir(dir string) string {
+	dir = path.Clean(dir)
+	for {
+		if _, err := os.Stat(filepath.Join(f.repoPath, dir, "package.json")); err == nil {
+			return dir
+		}
+		if dir == "." || dir == "/" {
+			return "."
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// jsTestRoots returns the test directories of a JavaScript/TypeScript
+// project: the roots, testMatch and include entries of its jest or vitest
+// config (including the "jest" key of package.json), or conventional test
+// directories if none are configured. Roots covering the whole project are
+// left to co-location.
+func (f *FocusedTestSelector) jsTestRoots(projectDir string) []string {
+	var entries []string
+	for _, name := range append(jsTestConfigFiles, "package.json") {
+		content, err := os.ReadFile(filepath.Join(f.repoPath, projectDir, name))
+		if err != nil {
+			continue
+		}
+		for _, match := range jsRootsPattern.FindAllStringSubmatch(string(content), -1) {
+			for _, quoted := range quotedPattern.FindAllStringSubmatch(match[1], -1) {
+				entries = append(entries, quoted[1])
+			}
+		}
+	}
+	if len(entries) == 0 {
+		entries = defaultJSTestRoots
+	}
+
+	seen := make(map[string]bool)
+	var roots []string
+	for _, entry := range entries {
+		root := strings.TrimPrefix(entry, "<rootDir>")
+		root = strings.TrimPrefix(strings.TrimPrefix(root, "/"), "./")
+		if i := strings.IndexAny(root, "*?{["); i >= 0 {
+			root = root[:strings.LastIndex(root[:i], "/")+1]
+		}
+		root = path.Clean(path.Join(projectDir, root))
+		if root == path.Clean(projectDir) || seen[root] {
+			continue
+		}
+		seen[root] = true
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// pythonTestCandidates maps a Python file to co-located tests (test_x.py,
+// x_test.py, tests/test_x.py) and to tests mirroring its path under a
+// top-level tests/ directory.
+func (f *FocusedTestSelector) pythonTestCandidates(file string) []string {
+	if isPythonTestFile(file) {
+		return []string{file}
+	}
+	dir, base := path.Split(file)
+	stem := strings.TrimSuffix(base, ".py")
+	names := []string{"test_" + stem + ".py", stem + "_test.py"}
+
+	var candidates []string
+	for _, name := range names {
+		candidates = append(candidates, path.Join(dir, name), path.Join(dir, "tests", name))
+	}
+	for _, root := range defaultPythonTestRoots {
+		for _, suffix := range pathSuffixes(path.Dir(file)) {
+			for _, name := range names {
+				candidates = append(candidates, path.Join(root, suffix, name))
+			}
+		}
+	}
+	return candidates
+}
+
+// listTests returns the files in dir matching isTest, relative to the repository root.
+func (f *FocusedTestSelector) listTests(dir string, isTest func(string) bool) []string {
+	entries, err := os.ReadDir(filepath.Join(f.repoPath, dir))
+	if err != nil {
+		return nil
+	}
+	var tests []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isTest(entry.Name()) {
+			tests = append(tests, path.Join(dir, entry.Name()))
+		}
+	}
+	return tests
+}
+
+// jsTestNames returns the test file names that may test a source file stem.
+func jsTestNames(stem string) []string {
+	var names []string
+	for _, suffix := range jsTestSuffixes {
+		for _, ext := range jsExtensions {
+			names = append(names, stem+suffix+ext)
+		}
+	}
+	return names
+}
+
+// pathSuffixes returns dir followed by the paths left after dropping its
+// leading components, ending with "." (src/auth -> src/auth, auth, .).
+// Mirrored test trees may or may not repeat the source root.
+func pathSuffixes(dir string) []string {
+	dir = path.Clean(dir)
+	var suffixes []string
+	for dir != "." && dir != "/" && dir != "" {
+		suffixes = append(suffixes, dir)
+		i := strings.Index(dir, "/")
+		if i < 0 {
+			break
+		}
+		dir = dir[i+1:]
+	}
+	return append(suffixes, ".")
+}
+
+// isJSFile reports whether file is a JavaScript or TypeScript source file.
+func isJSFile(file string) bool {
+	if strings.HasSuffix(file, ".d.ts") {
+		return false
+	}
+	ext := path.Ext(file)
+	for _, e := range jsExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSTestFile reports whether file is a JavaScript or TypeScript test file.
+func isJSTestFile(file string) bool {
+	if !isJSFile(file) {
+		return false
+	}
+	stem := strings.TrimSuffix(file, path.Ext(file))
+	for _, suffix := range jsTestSuffixes {
+		if strings.HasSuffix(stem, suffix) {
+			return true
+		}
+	}
+	return strings.Contains(filepath.ToSlash(file), "/__tests__/")
+}
+
+// isPythonTestFile reports whether file is a pytest test file.
+func isPythonTestFile(file string) bool {
+	base := path.Base(filepath.ToSlash(file))
+	return strings.HasSuffix(base, ".py") &&
+		(strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py"))
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeRepoFiles creates files (with empty content unless given) under dir.
+func writeRepoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func selectSorted(t *testing.T, selector *FocusedTestSelector, changed ...string) []string {
+	t.Helper()
+	tests, err := selector.SelectTests(changed)
+	if err != nil {
+		t.Fatalf("SelectTests() error = %v", err)
+	}
+	sort.Strings(tests)
+	return tests
+}
+
+func TestFocusedTestSelector_JSColocatedTests(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"package.json":                        "{}",
+		"src/auth/login.ts":                   "",
+		"src/auth/login.test.ts":              "",
+		"src/auth/session.tsx":                "",
+		"src/auth/__tests__/session.spec.tsx": "",
+		"src/auth/other.test.ts":              "",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	selector.SetMinTests(1)
+
+	got := selectSorted(t, selector, "src/auth/login.ts", "src/auth/session.tsx")
+	want := []string{"src/auth/__tests__/session.spec.tsx", "src/auth/login.test.ts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+}
+
+func TestFocusedTestSelector_JSConfigRoots(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"packages/api/package.json": "{}",
+		"packages/api/jest.config.js": `module.exports = {
+  roots: ['<rootDir>/src', '<rootDir>/spec'],
+  testMatch: ['**/?(*.)+(spec|test).[jt]s?(x)'],
+};`,
+		"packages/api/src/routes/users.ts":       "",
+		"packages/api/spec/routes/users.spec.ts": "",
+		"packages/api/spec/routes/posts.spec.ts": "",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	selector.SetMinTests(1)
+
+	if roots := selector.jsTestRoots("packages/api"); !reflect.DeepEqual(roots, []string{"packages/api/src", "packages/api/spec"}) {
+		t.Errorf("jsTestRoots() = %v", roots)
+	}
+	got := selectSorted(t, selector, "packages/api/src/routes/users.ts")
+	want := []string{"packages/api/spec/routes/users.spec.ts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+}
+
+func TestFocusedTestSelector_JSVitestInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"package.json": "{}",
+		"vitest.config.ts": `export default defineConfig({
+  test: { include: ["tests/unit/**/*.test.ts"] },
+})`,
+		"src/math.ts":             "",
+		"tests/unit/math.test.ts": "",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	selector.SetMinTests(1)
+
+	got := selectSorted(t, selector, "src/math.ts")
+	if want := []string{"tests/unit/math.test.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+}
+
+func TestFocusedTestSelector_JSDirectoryExpansion(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"package.json":          "{}",
+		"src/util.ts":           "",
+		"src/format.test.ts":    "",
+		"src/__tests__/a.ts":    "",
+		"src/other.ts":          "",
+		"lib/elsewhere.test.ts": "",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	got := selectSorted(t, selector, "src/util.ts")
+	want := []string{"src/__tests__/a.ts", "src/format.test.ts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+}
+
+func TestFocusedTestSelector_PythonMirroredTests(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"src/shop/cart.py":              "",
+		"src/shop/pricing.py":           "",
+		"src/shop/test_pricing.py":      "",
+		"tests/shop/test_cart.py":       "",
+		"tests/shop/test_checkout.py":   "",
+		"tests/other/test_unrelated.py": "",
+	})
+
+	selector := NewFocusedTestSelector(dir)
+	selector.SetMinTests(1)
+
+	got := selectSorted(t, selector, "src/shop/cart.py", "src/shop/pricing.py")
+	want := []string{"src/shop/test_pricing.py", "tests/shop/test_cart.py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() = %v, want %v", got, want)
+	}
+
+	// Too few tests expands to the mirrored test directory
+	selector.SetMinTests(5)
+	got = selectSorted(t, selector, "src/shop/cart.py")
+	want = []string{"tests/shop/test_cart.py", "tests/shop/test_checkout.py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectTests() with expansion = %v, want %v", got, want)
+	}
+}
+
+func TestIsJSTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"src/a.test.ts":         true,
+		"src/a.spec.jsx":        true,
+		"src/__tests__/a.ts":    true,
+		"src/a.ts":              false,
+		"src/types.d.ts":        false,
+		"src/a.test.go":         false,
+		"src/testing/helper.ts": false,
+	}
+	for file, want := range tests {
+		if got := isJSTestFile(file); got != want {
+			t.Errorf("isJSTestFile(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestPathSuffixes(t *testing.T) {
+	got := pathSuffixes("src/shop/cart")
+	want := []string{"src/shop/cart", "shop/cart", "cart", "."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pathSuffixes() = %v, want %v", got, want)
+	}
+	if got := pathSuffixes("."); !reflect.DeepEqual(got, []string{"."}) {
+		t.Errorf("pathSuffixes(.) = %v", got)
+	}
+}
@@ -1016,6 +1016,8 @@ func TestFocusedTestSelector_GetCallerTests(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	writeGoMod(t, tmpDir)
+
 	// Create source package with exported function
 	srcDir := filepath.Join(tmpDir, "pkg", "utils")
 	if err := os.MkdirAll(srcDir, 0755); err != nil {
@@ -1044,7 +1046,7 @@ func helperFunc() {}
 	// Create file that calls the exported function
 	handlerContent := `package handler
 
-import "pkg/utils"
+import "example.com/m/pkg/utils"
 
 func Handle(input string) string {
 	return utils.ProcessData(input)
@@ -1071,7 +1073,7 @@ func TestHandle(t *testing.T) {}
 		t.Fatalf("GetCallerTests() error = %v", err)
 	}
 
-	// Should find handler_test.go because handler.go calls ProcessData
+	// Should find handler_test.go because handler.go imports utils
 	if len(tests) != 1 {
 		t.Errorf("Expected 1 test file, got %d: %v", len(tests), tests)
 	}
@@ -1121,14 +1123,16 @@ func TestFocusedTestSelector_GetCallerTests_NonGoFile(t *testing.T) {
 	}
 }
 
-func TestFocusedTestSelector_GetCallerTests_NoExportedFunctions(t *testing.T) {
+func TestFocusedTestSelector_GetCallerTests_NoImporters(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "testselect-caller-*")
 	if err != nil {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	// Create file with only unexported functions
+	writeGoMod(t, tmpDir)
+
+	// Create a package nothing imports
 	pkgDir := filepath.Join(tmpDir, "pkg")
 	if err := os.MkdirAll(pkgDir, 0755); err != nil {
 		t.Fatalf("Failed to create pkg dir: %v", err)
@@ -1150,7 +1154,7 @@ func anotherPrivate() int { return 0 }
 	}
 
 	if tests != nil {
-		t.Errorf("Expected nil for file with no exported functions, got %v", tests)
+		t.Errorf("Expected nil for package with no importers, got %v", tests)
 	}
 }
 
@@ -1161,6 +1165,8 @@ func TestFocusedTestSelector_GetCallerTests_MultipleCallers(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	writeGoMod(t, tmpDir)
+
 	// Create shared library
 	libDir := filepath.Join(tmpDir, "lib")
 	if err := os.MkdirAll(libDir, 0755); err != nil {
@@ -1185,7 +1191,7 @@ func SharedHelper(s string) string {
 
 	caller1Content := `package caller1
 
-import "lib"
+import "example.com/m/lib"
 
 func Use1() string {
 	return lib.SharedHelper("1")
@@ -1206,7 +1212,7 @@ func Use1() string {
 
 	caller2Content := `package caller2
 
-import "lib"
+import "example.com/m/lib"
 
 func Use2() string {
 	return lib.SharedHelper("2")
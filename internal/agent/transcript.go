@@ -0,0 +1,259 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// maxTranscriptDiff caps the diff kept in a transcript, in bytes.
+const maxTranscriptDiff = 100 * 1024
+
+// TranscriptStepKind is the kind of a transcript step.
+type TranscriptStepKind string
+
+const (
+	// TranscriptAssistant is a message the agent wrote.
+	TranscriptAssistant TranscriptStepKind = "assistant"
+	// TranscriptTool is a tool call the agent made.
+	TranscriptTool TranscriptStepKind = "tool"
+	// TranscriptResult is the agent's final result.
+	TranscriptResult TranscriptStepKind = "result"
+	// TranscriptError is an error the agent's run reported.
+	TranscriptError TranscriptStepKind = "error"
+	// TranscriptNote is something the executor did to the run, such as
+	// restarting it after a startup timeout.
+	TranscriptNote TranscriptStepKind = "note"
+)
+
+// TranscriptStep is one step of an agent's run.
+type TranscriptStep struct {
+	Kind TranscriptStepKind
+	Text string
+	At   time.Time
+}
+
+// Transcript records an agent's run, the prompt it was given, what it did
+// and what it changed, for rendering as Markdown. Unlike the execution log
+// it keeps tool calls as separate steps.
+type Transcript struct {
+	// Prompt is the prompt the agent was started with.
+	Prompt string
+	// Steps are the agent's messages, tool calls and results in order.
+	Steps []TranscriptStep
+	// Diff is the diff of the agent's commits, cut at maxTranscriptDiff.
+	Diff string
+	// DiffTruncated is set if Diff was cut.
+	DiffTruncated bool
+
+	// action is the last tool action recorded, so repeats are skipped.
+	action string
+}
+
+// record adds the steps of a stream event.
+func (t *Transcript) record(event StreamEvent) {
+	now := time.Now()
+	if event.ToolAction != "" && event.ToolAction != t.action {
+		t.action = event.ToolAction
+		t.Steps = append(t.Steps, TranscriptStep{Kind: TranscriptTool, Text: event.ToolAction, At: now})
+	}
+	switch {
+	case event.Type == StreamEventAssistant && event.Message != "":
+		t.Steps = append(t.Steps, TranscriptStep{Kind: TranscriptAssistant, Text: event.Message, At: now})
+	case event.Type == StreamEventResult && event.Message != "":
+		t.Steps = append(t.Steps, TranscriptStep{Kind: TranscriptResult, Text: event.Message, At: now})
+	case event.Type == StreamEventError && event.Error != "":
+		t.Steps = append(t.Steps, TranscriptStep{Kind: TranscriptError, Text: event.Error, At: now})
+	}
+}
+
+// note adds a step describing something the executor did to the run.
+func (t *Transcript) note(format string, args ...interface{}) {
+	t.Steps = append(t.Steps, TranscriptStep{Kind: TranscriptNote, Text: fmt.Sprintf(format, args...), At: time.Now()})
+}
+
+// captureDiff records the diff of the commits made in workDir since base.
+func (t *Transcript) captureDiff(workDir, base string) {
+	if base == "" {
+		return
+	}
+	cmd := exec.Command("git", "diff", base, "HEAD")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return
+	}
+	if len(output) > maxTranscriptDiff {
+		output = output[:maxTranscriptDiff]
+		t.DiffTruncated = true
+	}
+	t.Diff = string(output)
+}
+
+// RenderTranscriptHeader renders the Markdown heading of a task's
+// transcript, which the sections of its attempts follow.
+func RenderTranscriptHeader(task *models.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", task.Title)
+	fmt.Fprintf(&b, "- Task ID: `%s`\n", task.ID)
+	if task.ParentID != "" {
+		fmt.Fprintf(&b, "- Parent: `%s`\n", task.ParentID)
+	}
+	if len(task.FileBoundaries) > 0 {
+		fmt.Fprintf(&b, "- File boundaries: %s\n", codeList(task.FileBoundaries))
+	}
+	if task.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", task.Description)
+	}
+	if task.AcceptanceCriteria != "" {
+		fmt.Fprintf(&b, "\n**Acceptance criteria:**\n\n%s\n", task.AcceptanceCriteria)
+	}
+	return redact.String(b.String())
+}
+
+// RenderTranscriptAttempt renders one attempt at a task as a Markdown
+// section: the feedback it retried with, the prompt, the agent's steps, the
+// diff of its work, the validation feedback and the outcome.
+func RenderTranscriptAttempt(attempt int, result *ExecutionResult, retryFeedback string) string {
+	var b strings.Builder
+	outcome := "succeeded"
+	if !result.Success {
+		outcome = "failed"
+	}
+	fmt.Fprintf(&b, "\n## Attempt %d (%s)\n\n", attempt, outcome)
+	fmt.Fprintf(&b, "- Agent: `%s`\n", result.AgentID)
+	fmt.Fprintf(&b, "- Model: `%s`\n", result.Model)
+	fmt.Fprintf(&b, "- Duration: %s\n", result.Duration.Round(time.Second))
+	fmt.Fprintf(&b, "- Tokens: %d ($%.4f)\n", result.TokensUsed, result.Cost)
+	if result.LogFile != "" {
+		fmt.Fprintf(&b, "- Raw log: `%s`\n", result.LogFile)
+	}
+
+	if retryFeedback != "" {
+		b.WriteString("\n### Retry feedback\n\n")
+		b.WriteString(quote(retryFeedback))
+	}
+
+	t := result.Transcript
+	if t != nil && t.Prompt != "" {
+		b.WriteString("\n### Prompt\n\n<details>\n<summary>Prompt given to the agent</summary>\n\n")
+		b.WriteString(fence(t.Prompt, ""))
+		b.WriteString("\n</details>\n")
+	}
+
+	if t != nil && len(t.Steps) > 0 {
+		b.WriteString("\n### Run\n\n")
+		start := t.Steps[0].At
+		for _, step := range t.Steps {
+			offset := step.At.Sub(start).Round(time.Second)
+			switch step.Kind {
+			case TranscriptTool:
+				fmt.Fprintf(&b, "- `+%s` **Tool:** %s\n", offset, oneLine(step.Text))
+			case TranscriptNote:
+				fmt.Fprintf(&b, "- `+%s` _%s_\n", offset, oneLine(step.Text))
+			case TranscriptError:
+				fmt.Fprintf(&b, "- `+%s` **Error:**\n\n%s\n", offset, indent(fence(step.Text, "")))
+			case TranscriptResult:
+				fmt.Fprintf(&b, "- `+%s` **Result:**\n\n%s\n", offset, indent(quote(step.Text)))
+			default:
+				fmt.Fprintf(&b, "- `+%s`\n\n%s\n", offset, indent(quote(step.Text)))
+			}
+		}
+	}
+
+	if len(result.ChangedFiles) > 0 || (t != nil && t.Diff != "") {
+		b.WriteString("\n### Changes\n\n")
+		if len(result.ChangedFiles) > 0 {
+			fmt.Fprintf(&b, "Files: %s\n\n", codeList(result.ChangedFiles))
+		}
+		if t != nil && t.Diff != "" {
+			b.WriteString(fence(t.Diff, "diff"))
+			if t.DiffTruncated {
+				fmt.Fprintf(&b, "\n_Diff cut at %d KB._\n", maxTranscriptDiff/1024)
+			}
+		}
+	}
+
+	if len(result.Validation) > 0 || result.LoopIterations > 0 || result.VerifySummary != "" {
+		b.WriteString("\n### Validation\n\n")
+		for _, v := range result.Validation {
+			switch {
+			case v.Passed:
+				fmt.Fprintf(&b, "- %s: passed (%s)\n", v.Layer, v.Duration.Round(time.Millisecond))
+			case v.Skipped:
+				fmt.Fprintf(&b, "- %s: skipped, %s\n", v.Layer, oneLine(v.Detail))
+			default:
+				fmt.Fprintf(&b, "- %s: **failed** (%s)\n\n%s\n", v.Layer, v.Duration.Round(time.Millisecond), indent(fence(v.Detail, "")))
+			}
+		}
+		if result.LoopIterations > 0 {
+			fmt.Fprintf(&b, "- Self-critique: %d iteration(s), %s\n", result.LoopIterations, result.LoopExitReason)
+		}
+		if result.VerifySummary != "" {
+			fmt.Fprintf(&b, "- Verification: %s\n", oneLine(result.VerifySummary))
+		}
+	}
+
+	b.WriteString("\n### Outcome\n\n")
+	if result.Success {
+		b.WriteString("Succeeded.\n")
+	} else {
+		fmt.Fprintf(&b, "Failed: %s\n", oneLine(result.Error))
+	}
+	return redact.String(b.String())
+}
+
+// fence wraps content in a code fence longer than any backtick run in it.
+func fence(content, lang string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	marker := strings.Repeat("`", max(3, longest+1))
+	return marker + lang + "\n" + strings.TrimRight(content, "\n") + "\n" + marker + "\n"
+}
+
+// quote renders text as a Markdown block quote.
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// indent indents a Markdown block so it nests under a list item.
+func indent(block string) string {
+	lines := strings.Split(strings.TrimRight(block, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// oneLine collapses text to a single line.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// codeList renders items as a comma-separated list of code spans.
+func codeList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
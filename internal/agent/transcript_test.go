@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscript_Record(t *testing.T) {
+	tr := &Transcript{}
+	tr.record(StreamEvent{Type: StreamEventAssistant, Message: "Looking at the handler", ToolAction: "Reading auth.go"})
+	tr.record(StreamEvent{Type: StreamEventSystem, ToolAction: "Reading auth.go"})
+	tr.record(StreamEvent{Type: StreamEventSystem, ToolAction: "Editing auth.go"})
+	tr.record(StreamEvent{Type: StreamEventResult, Message: "Done"})
+	tr.note("Stopped: no progress for %v", time.Minute)
+
+	var kinds []TranscriptStepKind
+	for _, s := range tr.Steps {
+		kinds = append(kinds, s.Kind)
+	}
+	want := []TranscriptStepKind{TranscriptTool, TranscriptAssistant, TranscriptTool, TranscriptResult, TranscriptNote}
+	if len(kinds) != len(want) {
+		t.Fatalf("steps = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("steps = %v, want %v", kinds, want)
+		}
+	}
+}
+
+func TestRenderTranscriptAttempt(t *testing.T) {
+	start := time.Now()
+	result := &ExecutionResult{
+		Success:      false,
+		Error:        "validation layer test failed",
+		AgentID:      "agent-1",
+		Model:        "claude-sonnet",
+		ChangedFiles: []string{"auth.go"},
+		Validation: []ValidationOutcome{
+			{Layer: "build", Passed: true},
+			{Layer: "test", Detail: "--- FAIL: TestLogin"},
+		},
+		Transcript: &Transcript{
+			Prompt: "Fix the login handler",
+			Steps: []TranscriptStep{
+				{Kind: TranscriptTool, Text: "Editing auth.go", At: start},
+				{Kind: TranscriptAssistant, Text: "Use ```go fences```", At: start.Add(3 * time.Second)},
+			},
+			Diff: "--- a/auth.go\n+++ b/auth.go\n",
+		},
+	}
+
+	md := RenderTranscriptAttempt(2, result, "Tests failed: TestLogin")
+	for _, want := range []string{
+		"## Attempt 2 (failed)",
+		"### Retry feedback\n\n> Tests failed: TestLogin",
+		"Fix the login handler",
+		"- `+0s` **Tool:** Editing auth.go",
+		"- `+3s`\n\n  > Use ```go fences```",
+		"Files: `auth.go`",
+		"```diff\n--- a/auth.go",
+		"- build: passed",
+		"- test: **failed**",
+		"Failed: validation layer test failed",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("transcript missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestFence(t *testing.T) {
+	if got := fence("a ``` b", ""); !strings.HasPrefix(got, "````\n") || !strings.HasSuffix(got, "\n````\n") {
+		t.Errorf("fence() = %q, want a fence longer than the content's backticks", got)
+	}
+	if got := fence("plain", "diff"); got != "```diff\nplain\n```\n" {
+		t.Errorf("fence() = %q", got)
+	}
+}
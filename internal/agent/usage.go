@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageSnapshot is the usage a UsageMeter recorded so far.
+type UsageSnapshot struct {
+	Usage TokenUsage
+	Cost  float64
+}
+
+// UsageMeter adds up the tokens and cost of every runner created by a
+// MeteredRunnerFactory as their responses stream in: executors as well as
+// decomposers, auditors, validators, reviewers and mergers. It keeps a
+// TokenTracker per model for pricing.
+type UsageMeter struct {
+	mu       sync.Mutex
+	trackers map[string]*TokenTracker
+}
+
+// NewUsageMeter creates an empty UsageMeter.
+func NewUsageMeter() *UsageMeter {
+	return &UsageMeter{trackers: make(map[string]*TokenTracker)}
+}
+
+// Record adds usage of a response from model. An empty or unknown model is
+// priced by its family (opus, haiku, otherwise sonnet).
+func (m *UsageMeter) Record(model string, usage MessageDeltaUsage) {
+	if m == nil || (usage.InputTokens <= 0 && usage.OutputTokens <= 0) {
+		return
+	}
+	model = pricingModel(model)
+	m.mu.Lock()
+	tracker, ok := m.trackers[model]
+	if !ok {
+		tracker = NewTokenTracker(model)
+		m.trackers[model] = tracker
+	}
+	m.mu.Unlock()
+	tracker.Update(usage)
+}
+
+// Snapshot returns the usage and cost recorded so far.
+func (m *UsageMeter) Snapshot() UsageSnapshot {
+	var s UsageSnapshot
+	if m == nil {
+		return s
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.trackers {
+		usage := t.GetUsage()
+		s.Usage.InputTokens += usage.InputTokens
+		s.Usage.OutputTokens += usage.OutputTokens
+		s.Usage.TotalTokens += usage.TotalTokens
+		s.Cost += t.GetCost()
+	}
+	return s
+}
+
+// Watch calls fn with the snapshot every interval while usage keeps
+// changing, until the returned function is called.
+func (m *UsageMeter) Watch(interval time.Duration, fn func(UsageSnapshot)) (stop func()) {
+	if m == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	last := m.Snapshot()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s := m.Snapshot(); s != last {
+					last = s
+					fn(s)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// pricingModel returns the DefaultModelPricing key model is priced by.
+func pricingModel(model string) string {
+	if _, ok := DefaultModelPricing[model]; ok {
+		return model
+	}
+	for _, family := range []string{"opus", "haiku"} {
+		if strings.Contains(model, family) {
+			return family
+		}
+	}
+	return "sonnet"
+}
+
+// MeteredRunnerFactory wraps a ClaudeRunnerFactory so the usage of every
+// runner it creates is recorded in Meter.
+type MeteredRunnerFactory struct {
+	Factory ClaudeRunnerFactory
+	Meter   *UsageMeter
+}
+
+// NewRunner creates a runner of the wrapped factory that records its usage.
+func (f *MeteredRunnerFactory) NewRunner() ClaudeRunner {
+	return &meteredRunner{
+		ClaudeRunner: f.Factory.NewRunner(),
+		meter:        f.Meter,
+		streamed:     make(map[string]MessageDeltaUsage),
+	}
+}
+
+// UsageMeterOf returns the meter recording the usage of the runners factory
+// creates, or nil if they are not metered.
+func UsageMeterOf(factory ClaudeRunnerFactory) *UsageMeter {
+	if f, ok := factory.(*MeteredRunnerFactory); ok {
+		return f.Meter
+	}
+	return nil
+}
+
+// unwrapFactory returns the factory a MeteredRunnerFactory wraps, or
+// factory itself.
+func unwrapFactory(factory ClaudeRunnerFactory) ClaudeRunnerFactory {
+	if f, ok := factory.(*MeteredRunnerFactory); ok {
+		return f.Factory
+	}
+	return factory
+}
+
+// meteredRunner passes a runner's events through, recording the usage they
+// report.
+type meteredRunner struct {
+	ClaudeRunner
+	meter *UsageMeter
+	model string
+
+	once sync.Once
+	out  chan StreamEvent
+	// streamed is the usage recorded per assistant message ID, and total
+	// all usage recorded, so repeated and cumulative reports of the same
+	// tokens are only counted once.
+	streamed map[string]MessageDeltaUsage
+	total    MessageDeltaUsage
+}
+
+// Start launches the runner.
+func (r *meteredRunner) Start(prompt, workDir string) error {
+	return r.StartWithOptions(prompt, workDir, nil)
+}
+
+// StartWithOptions launches the runner, noting the model it is priced by.
+func (r *meteredRunner) StartWithOptions(prompt, workDir string, opts *StartOptions) error {
+	if opts != nil {
+		r.model = opts.Model
+	}
+	return r.ClaudeRunner.StartWithOptions(prompt, workDir, opts)
+}
+
+// Output returns the runner's events, recording their usage as they pass.
+func (r *meteredRunner) Output() <-chan StreamEvent {
+	r.once.Do(func() {
+		in := r.ClaudeRunner.Output()
+		r.out = make(chan StreamEvent, cap(in))
+		go func() {
+			defer close(r.out)
+			for event := range in {
+				if event.Raw != nil {
+					r.observe(event.Raw)
+				}
+				r.out <- event
+			}
+		}()
+	})
+	return r.out
+}
+
+// observe records the usage reported by an event's raw JSON: the usage of
+// an assistant message as it grows, a response's usage (API runners), or
+// the run's cumulative usage in the final result (CLI runners).
+func (r *meteredRunner) observe(raw json.RawMessage) {
+	var data struct {
+		Type    string             `json:"type"`
+		Usage   *MessageDeltaUsage `json:"usage"`
+		Message *struct {
+			ID    string             `json:"id"`
+			Model string             `json:"model"`
+			Usage *MessageDeltaUsage `json:"usage"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return
+	}
+
+	// The model a message names prices the rest of the run's usage too
+	if data.Message != nil && data.Message.Model != "" {
+		r.model = data.Message.Model
+	}
+	var delta MessageDeltaUsage
+	switch {
+	case data.Message != nil && data.Message.Usage != nil && data.Message.ID != "":
+		delta = usageSince(*data.Message.Usage, r.streamed[data.Message.ID])
+		r.streamed[data.Message.ID] = *data.Message.Usage
+	case data.Usage != nil && data.Type == string(StreamEventResult):
+		delta = usageSince(*data.Usage, r.total)
+	case data.Usage != nil:
+		delta = *data.Usage
+	default:
+		return
+	}
+	r.total.InputTokens += delta.InputTokens
+	r.total.OutputTokens += delta.OutputTokens
+	r.meter.Record(r.model, delta)
+}
+
+// usageSince returns the tokens in usage beyond seen.
+func usageSince(usage, seen MessageDeltaUsage) MessageDeltaUsage {
+	return MessageDeltaUsage{
+		InputTokens:  max(usage.InputTokens-seen.InputTokens, 0),
+		OutputTokens: max(usage.OutputTokens-seen.OutputTokens, 0),
+	}
+}
+
+// Verify the wrappers implement the runner interfaces at compile time.
+var (
+	_ ClaudeRunnerFactory = (*MeteredRunnerFactory)(nil)
+	_ ClaudeRunner        = (*meteredRunner)(nil)
+)
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// meteredEvents runs events through a metered runner and drains its output.
+func meteredEvents(t *testing.T, meter *UsageMeter, model string, raws ...string) {
+	t.Helper()
+	ch := make(chan StreamEvent, len(raws))
+	for _, raw := range raws {
+		ch <- StreamEvent{Type: StreamEventAssistant, Raw: json.RawMessage(raw)}
+	}
+	close(ch)
+
+	factory := &MeteredRunnerFactory{
+		Factory: &stubRunnerFactory{runner: &mockRunner{outputCh: ch}},
+		Meter:   meter,
+	}
+	runner := factory.NewRunner()
+	if err := runner.StartWithOptions("prompt", t.TempDir(), &StartOptions{Model: model}); err != nil {
+		t.Fatalf("StartWithOptions() error = %v", err)
+	}
+	n := 0
+	for range runner.Output() {
+		n++
+	}
+	if n != len(raws) {
+		t.Errorf("metered runner passed %d events, want %d", n, len(raws))
+	}
+}
+
+// stubRunnerFactory returns the same runner every time.
+type stubRunnerFactory struct {
+	runner ClaudeRunner
+}
+
+func (f *stubRunnerFactory) NewRunner() ClaudeRunner { return f.runner }
+
+func TestMeteredRunner_APIResponses(t *testing.T) {
+	meter := NewUsageMeter()
+	meteredEvents(t, meter, "claude-sonnet-4-20250514",
+		`{"usage": {"input_tokens": 1000, "output_tokens": 200}}`,
+		`{"usage": {"input_tokens": 1500, "output_tokens": 300}}`,
+	)
+
+	s := meter.Snapshot()
+	if s.Usage.InputTokens != 2500 || s.Usage.OutputTokens != 500 || s.Usage.TotalTokens != 3000 {
+		t.Errorf("Snapshot().Usage = %+v, want both responses added up", s.Usage)
+	}
+	// Priced as sonnet: $3 in and $15 out per million tokens
+	if want := 0.0075 + 0.0075; math.Abs(s.Cost-want) > 1e-9 {
+		t.Errorf("Snapshot().Cost = %f, want %f", s.Cost, want)
+	}
+}
+
+func TestMeteredRunner_CLIStream(t *testing.T) {
+	meter := NewUsageMeter()
+	meteredEvents(t, meter, "",
+		// The usage of a message is repeated by each of its content blocks
+		`{"type": "assistant", "message": {"id": "msg_1", "model": "claude-opus-4-5-20251101", "usage": {"input_tokens": 100, "output_tokens": 10}}}`,
+		`{"type": "assistant", "message": {"id": "msg_1", "model": "claude-opus-4-5-20251101", "usage": {"input_tokens": 100, "output_tokens": 10}}}`,
+		`{"type": "assistant", "message": {"id": "msg_2", "model": "claude-opus-4-5-20251101", "usage": {"input_tokens": 200, "output_tokens": 20}}}`,
+		// The result reports the run's total, adding only what was not streamed
+		`{"type": "result", "usage": {"input_tokens": 350, "output_tokens": 30}}`,
+	)
+
+	s := meter.Snapshot()
+	if s.Usage.InputTokens != 350 || s.Usage.OutputTokens != 30 {
+		t.Errorf("Snapshot().Usage = %+v, want 350 in and 30 out", s.Usage)
+	}
+	// Priced as opus: $15 in and $75 out per million tokens
+	if want := 350*15.0/1e6 + 30*75.0/1e6; math.Abs(s.Cost-want) > 1e-9 {
+		t.Errorf("Snapshot().Cost = %f, want %f", s.Cost, want)
+	}
+}
+
+func TestUsageMeter_Watch(t *testing.T) {
+	meter := NewUsageMeter()
+	ticks := make(chan UsageSnapshot, 10)
+	stop := meter.Watch(10*time.Millisecond, func(s UsageSnapshot) { ticks <- s })
+	defer stop()
+
+	meter.Record("haiku", MessageDeltaUsage{InputTokens: 1000})
+	select {
+	case s := <-ticks:
+		if s.Usage.InputTokens != 1000 {
+			t.Errorf("tick usage = %+v, want 1000 input tokens", s.Usage)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no tick after usage changed")
+	}
+
+	// Unchanged usage does not tick
+	select {
+	case s := <-ticks:
+		t.Errorf("unexpected tick without new usage: %+v", s)
+	case <-time.After(50 * time.Millisecond):
+	}
+	stop()
+}
+
+func TestPricingModel(t *testing.T) {
+	tests := map[string]string{
+		"sonnet":                    "sonnet",
+		"claude-3-5-haiku-20241022": "claude-3-5-haiku-20241022",
+		"claude-opus-4-6":           "opus",
+		"claude-haiku-4-5":          "haiku",
+		"claude-sonnet-4-20250514":  "sonnet",
+		"":                          "sonnet",
+	}
+	for model, want := range tests {
+		if got := pricingModel(model); got != want {
+			t.Errorf("pricingModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// Validation layers run in a task's worktree after the agent finishes.
+const (
+	// ValidationReview is the self-critique loop: the agent scores its work
+	// against the rubric and iterates, running the verification contract
+	// along the way. It is the most expensive layer.
+	ValidationReview = "review"
+	// ValidationGates runs the tier's quality gates (lint, build, test, typecheck).
+	ValidationGates = "gates"
+	// ValidationVerification checks the task's verification contract,
+	// running it if the review layer did not.
+	ValidationVerification = "verification"
+	// ValidationLint runs the pipeline's linters on the changed files and
+	// fails on violations the session baseline does not have. It belongs
+	// between the gates and the review.
+	ValidationLint = "lint"
+	// ValidationSecurity scans the added lines for secrets and audits the
+	// dependencies when a manifest changed, failing on secrets and on
+	// critical vulnerabilities the task introduced.
+	ValidationSecurity = "security"
+)
+
+// PipelineLayer configures one layer of a ValidationPipeline.
+type PipelineLayer struct {
+	// Name is the layer: review, gates, verification, lint, security or
+	// the name of a registered ValidationLayer.
+	Name string
+	// Timeout bounds the layer. Zero leaves it bounded by the task timeout only.
+	Timeout time.Duration
+}
+
+// ValidationPipeline lists the validation layers a task runs, in order.
+type ValidationPipeline struct {
+	// Layers run in order. Layers not listed are skipped.
+	Layers []PipelineLayer
+	// ContinueOnFailure runs the remaining layers after one fails, so the
+	// agent's work is reported on in full. By default the pipeline stops at
+	// the first failure. The task fails either way.
+	ContinueOnFailure bool
+	// SkipReviewConfidence skips the review layer when the task's confidence
+	// score (see ConfidenceSignals.Score) is at least this high. Zero always
+	// reviews.
+	SkipReviewConfidence float64
+	// Linters are the linters the lint layer runs. Empty runs the
+	// built-in linters (see DefaultLinters).
+	Linters []Linter
+	// Coverage adds a coverage gate to the gates layer. Nil collects no
+	// coverage.
+	Coverage *CoverageGate
+}
+
+// DefaultValidationPipeline returns the pipeline used when a tier configures
+// none: Builder and Architect review their work, then run the quality gates
+// and check the verification contract; other tiers only run the gates.
+func DefaultValidationPipeline(tier models.Tier) *ValidationPipeline {
+	if tier == models.TierBuilder || tier == models.TierArchitect {
+		return &ValidationPipeline{Layers: []PipelineLayer{
+			{Name: ValidationReview},
+			{Name: ValidationGates},
+			{Name: ValidationVerification},
+		}}
+	}
+	return &ValidationPipeline{Layers: []PipelineLayer{{Name: ValidationGates}}}
+}
+
+// Validate checks that every layer is built in or registered and listed
+// once, and that the confidence threshold is between 0 and 1.
+func (p *ValidationPipeline) Validate() error {
+	if p.SkipReviewConfidence < 0 || p.SkipReviewConfidence > 1 {
+		return fmt.Errorf("skip_review_confidence %v: must be between 0 and 1", p.SkipReviewConfidence)
+	}
+	if p.Coverage != nil && p.Coverage.MaxDrop < 0 {
+		return fmt.Errorf("coverage max_drop %v: must not be negative", p.Coverage.MaxDrop)
+	}
+	seen := make(map[string]bool)
+	for _, l := range p.Layers {
+		switch l.Name {
+		case ValidationReview, ValidationGates, ValidationVerification, ValidationLint, ValidationSecurity:
+		default:
+			if _, ok := registeredValidationLayer(l.Name); !ok {
+				return fmt.Errorf("unknown validation layer %q (use %s, %s, %s, %s, %s or a registered layer)",
+					l.Name, ValidationReview, ValidationGates, ValidationVerification, ValidationLint, ValidationSecurity)
+			}
+		}
+		if seen[l.Name] {
+			return fmt.Errorf("validation layer %q listed twice", l.Name)
+		}
+		if l.Timeout < 0 {
+			return fmt.Errorf("validation layer %q: negative timeout", l.Name)
+		}
+		seen[l.Name] = true
+	}
+	return nil
+}
+
+// ValidationOutcome records how one validation layer went.
+type ValidationOutcome struct {
+	// Layer is the layer's name.
+	Layer string
+	// Passed is true if the layer ran and passed.
+	Passed bool
+	// Skipped is true if the layer did not run: it was disabled, had nothing
+	// to check, or an earlier layer failed.
+	Skipped bool
+	// TimedOut is true if the layer exceeded its timeout.
+	TimedOut bool
+	// Duration is how long the layer ran.
+	Duration time.Duration
+	// Detail explains a failure or skip.
+	Detail string
+}
+
+// validationRun carries the state the layers of one task share.
+type validationRun struct {
+	result       *ExecutionResult
+	task         *models.Task
+	tier         models.Tier
+	opts         *ExecuteOptions
+	worktreePath string
+	verifyCtx    *verificationContext
+	// skipReviewAt is the confidence at which the review is skipped; zero
+	// never skips.
+	skipReviewAt float64
+	// linters are the lint layer's linters.
+	linters []Linter
+	// coverage is the gates layer's coverage gate, if any.
+	coverage *CoverageGate
+}
+
+// commands returns the runner for the task's build and test commands, or
+// nil to run them on the host.
+func (run *validationRun) commands() iexec.CommandRunner {
+	if run.opts == nil {
+		return nil
+	}
+	return run.opts.Sandbox
+}
+
+// runValidationPipeline runs the pipeline's layers in order and records
+// their outcomes on the result. It returns the first failed layer's error,
+// or "" if every layer passed.
+func (e *Executor) runValidationPipeline(ctx context.Context, pipeline *ValidationPipeline, run *validationRun) string {
+	if pipeline.SkipReviewConfidence > 0 {
+		score := e.measureConfidence(ctx, run).Score()
+		run.result.Confidence = &score
+		run.skipReviewAt = pipeline.SkipReviewConfidence
+	}
+	run.linters = pipeline.Linters
+	run.coverage = pipeline.Coverage
+
+	var failure string
+	for _, layer := range pipeline.Layers {
+		if failure != "" && !pipeline.ContinueOnFailure {
+			run.result.Validation = append(run.result.Validation, ValidationOutcome{
+				Layer:   layer.Name,
+				Skipped: true,
+				Detail:  "skipped after an earlier layer failed",
+			})
+			continue
+		}
+
+		layerCtx, cancel := ctx, context.CancelFunc(func() {})
+		if layer.Timeout > 0 {
+			layerCtx, cancel = context.WithTimeout(ctx, layer.Timeout)
+		}
+		start := time.Now()
+		outcome, errMsg := e.runValidationLayer(layerCtx, layer.Name, run)
+		outcome.Layer = layer.Name
+		outcome.Duration = time.Since(start)
+		if layerCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil && !outcome.Skipped {
+			outcome.Passed = false
+			outcome.TimedOut = true
+			outcome.Detail = fmt.Sprintf("timed out after %v", layer.Timeout)
+			// A review that runs out of time is noted but, being advisory, fails nothing
+			if layer.Name != ValidationReview {
+				errMsg = fmt.Sprintf("%s validation timed out after %v", layer.Name, layer.Timeout)
+			}
+		}
+		cancel()
+
+		run.result.Validation = append(run.result.Validation, outcome)
+		if errMsg != "" && failure == "" {
+			failure = errMsg
+		}
+	}
+	return failure
+}
+
+// runValidationLayer runs a single layer. It returns the layer's outcome
+// and, if the layer failed, the task error it causes.
+func (e *Executor) runValidationLayer(ctx context.Context, name string, run *validationRun) (ValidationOutcome, string) {
+	switch name {
+	case ValidationReview:
+		if c := run.result.Confidence; c != nil {
+			if *c >= run.skipReviewAt {
+				log.Printf("[agent] skipping review of task %s: confidence %.2f >= %.2f", run.task.ID, *c, run.skipReviewAt)
+				return ValidationOutcome{Skipped: true, Detail: fmt.Sprintf("confidence %.2f >= %.2f", *c, run.skipReviewAt)}, ""
+			}
+			log.Printf("[agent] reviewing task %s: confidence %.2f < %.2f", run.task.ID, *c, run.skipReviewAt)
+		}
+		if run.opts == nil || !run.opts.EnableRalphLoop {
+			return ValidationOutcome{Skipped: true, Detail: "self-critique disabled"}, ""
+		}
+		e.runRalphLoop(ctx, run.result, run.task, run.tier, run.opts, run.worktreePath, run.verifyCtx)
+		// The review is advisory: the gates and verification layers decide
+		return ValidationOutcome{Passed: true, Detail: run.result.LoopExitReason}, ""
+
+	case ValidationGates:
+		if run.opts == nil || !run.opts.EnableQualityGates {
+			return ValidationOutcome{Skipped: true, Detail: "quality gates disabled"}, ""
+		}
+		if e.runQualityGatesIfEnabled(ctx, run.result, run.opts, run.worktreePath, run.tier, run.coverage) {
+			return ValidationOutcome{Passed: true}, ""
+		}
+		return ValidationOutcome{Detail: "quality gates failed"}, "quality gates failed (regression detected or new failures)"
+
+	case ValidationVerification:
+		if run.result.VerifyPassed == nil {
+			e.runVerificationContract(ctx, run.result, run.task, run.worktreePath, run.verifyCtx, run.commands())
+		}
+		if run.result.VerifyPassed == nil {
+			return ValidationOutcome{Skipped: true, Detail: "no verification contract"}, ""
+		}
+		if *run.result.VerifyPassed {
+			return ValidationOutcome{Passed: true, Detail: run.result.VerifySummary}, ""
+		}
+		return ValidationOutcome{Detail: run.result.VerifySummary}, "verification contract failed"
+
+	case ValidationLint:
+		return e.runLintLayer(ctx, run)
+
+	case ValidationSecurity:
+		return e.runSecurityLayer(ctx, run)
+
+	default:
+		layer, ok := registeredValidationLayer(name)
+		if !ok {
+			return ValidationOutcome{Skipped: true, Detail: "unknown layer"}, ""
+		}
+		res := layer.Run(ctx, ValidationInput{
+			Task:         run.task,
+			Tier:         run.tier,
+			WorktreePath: run.worktreePath,
+			ChangedFiles: worktreeChangedFiles(run.worktreePath),
+			Commands:     run.commands(),
+		})
+		outcome := ValidationOutcome{Passed: res.Passed && !res.Skipped, Skipped: res.Skipped, Detail: res.Detail}
+		if outcome.Passed || outcome.Skipped {
+			return outcome, ""
+		}
+		return outcome, fmt.Sprintf("%s validation failed", name)
+	}
+}
+
+// formatValidation describes the layers that failed and the layers skipped
+// because of them, for the task output.
+func formatValidation(outcomes []ValidationOutcome) string {
+	var b strings.Builder
+	failed := false
+	for _, o := range outcomes {
+		switch {
+		case o.Passed:
+			continue
+		case o.Skipped:
+			if failed {
+				fmt.Fprintf(&b, "\n[Validation %s: %s]", o.Layer, o.Detail)
+			}
+		default:
+			failed = true
+			fmt.Fprintf(&b, "\n[Validation %s: failed after %v: %s]", o.Layer, o.Duration.Round(time.Millisecond), o.Detail)
+		}
+	}
+	return b.String()
+}
+
+// ValidationPipelineFromConfig builds a tier's pipeline from its config.
+// It returns nil, selecting the default pipeline, if cfg is nil.
+func ValidationPipelineFromConfig(cfg *config.ValidationConfig) (*ValidationPipeline, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	pipeline := &ValidationPipeline{
+		ContinueOnFailure:    cfg.ContinueOnFailure,
+		SkipReviewConfidence: cfg.SkipReviewConfidence,
+	}
+	linters, err := LintersFromConfig(cfg.Linters)
+	if err != nil {
+		return nil, err
+	}
+	pipeline.Linters = linters
+	if cfg.Coverage != nil {
+		pipeline.Coverage = &CoverageGate{MaxDrop: cfg.Coverage.MaxDrop}
+	}
+	for _, l := range cfg.Layers {
+		pipeline.Layers = append(pipeline.Layers, PipelineLayer{
+			Name:    strings.ToLower(strings.TrimSpace(l.Name)),
+			Timeout: l.Timeout,
+		})
+	}
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
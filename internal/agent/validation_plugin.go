@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// ValidationLayer is a project-specific validation layer, such as a license
+// header check, OpenAPI schema validation or a migration safety check.
+// Registered layers can be listed in a tier's pipeline by name next to the
+// built-in review, gates and verification layers.
+type ValidationLayer interface {
+	// Name is the layer's name in pipelines and outcomes. It must not be
+	// the name of a built-in layer.
+	Name() string
+	// Run checks the agent's work. It is bounded by the layer's timeout
+	// through ctx.
+	Run(ctx context.Context, in ValidationInput) LayerResult
+}
+
+// ValidationInput is the agent's work a validation layer checks.
+type ValidationInput struct {
+	// Task is the task the agent worked on.
+	Task *models.Task
+	// Tier is the tier the task ran at.
+	Tier models.Tier
+	// WorktreePath is the worktree holding the agent's uncommitted changes.
+	WorktreePath string
+	// ChangedFiles lists the files changed or added in the worktree,
+	// relative to it.
+	ChangedFiles []string
+	// Commands runs commands for the layer: in the tier's sandbox if it
+	// has one, on the host otherwise.
+	Commands iexec.CommandRunner
+}
+
+// LayerResult is how a validation layer's run went.
+type LayerResult struct {
+	// Passed is true if the work passed the layer.
+	Passed bool
+	// Skipped is true if the layer had nothing to check. A skipped layer
+	// does not fail the task.
+	Skipped bool
+	// Detail explains a failure or skip.
+	Detail string
+}
+
+var (
+	// validationLayers holds the registered layers by name.
+	validationLayers = make(map[string]ValidationLayer)
+	// validationLayersMu protects validationLayers.
+	validationLayersMu sync.RWMutex
+)
+
+// RegisterValidationLayer makes layer available to validation pipelines
+// under its name. Registering a built-in layer's name, or a name twice, is
+// an error.
+func RegisterValidationLayer(layer ValidationLayer) error {
+	name := strings.ToLower(strings.TrimSpace(layer.Name()))
+	switch name {
+	case "":
+		return fmt.Errorf("validation layer needs a name")
+	case ValidationReview, ValidationGates, ValidationVerification, ValidationLint, ValidationSecurity:
+		return fmt.Errorf("validation layer %q is built in", name)
+	}
+
+	validationLayersMu.Lock()
+	defer validationLayersMu.Unlock()
+	if _, ok := validationLayers[name]; ok {
+		return fmt.Errorf("validation layer %q already registered", name)
+	}
+	validationLayers[name] = layer
+	return nil
+}
+
+// UnregisterValidationLayer removes a registered layer.
+func UnregisterValidationLayer(name string) {
+	validationLayersMu.Lock()
+	defer validationLayersMu.Unlock()
+	delete(validationLayers, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// RegisteredValidationLayers returns the names of the registered layers,
+// sorted.
+func RegisteredValidationLayers() []string {
+	validationLayersMu.RLock()
+	defer validationLayersMu.RUnlock()
+	names := make([]string, 0, len(validationLayers))
+	for name := range validationLayers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredValidationLayer returns the layer registered under name.
+func registeredValidationLayer(name string) (ValidationLayer, bool) {
+	validationLayersMu.RLock()
+	defer validationLayersMu.RUnlock()
+	layer, ok := validationLayers[name]
+	return layer, ok
+}
+
+// CommandLayer is a validation layer that runs a shell command in the
+// worktree and passes if it exits zero, so projects can add layers from
+// config without writing Go.
+type CommandLayer struct {
+	// LayerName is the layer's name.
+	LayerName string
+	// Command is run with sh -c in the worktree root.
+	Command string
+	// Paths are glob patterns the layer applies to, as for warm-up hooks.
+	// The layer is skipped for tasks touching no matching file. Empty
+	// runs it for every task.
+	Paths []string
+}
+
+// Name returns the layer's name.
+func (l *CommandLayer) Name() string {
+	return l.LayerName
+}
+
+// Run runs the command, reporting its output's tail on failure.
+func (l *CommandLayer) Run(ctx context.Context, in ValidationInput) LayerResult {
+	hook := WarmUpHook{Paths: l.Paths}
+	if len(l.Paths) > 0 && (len(in.ChangedFiles) == 0 || !hook.applies(in.ChangedFiles)) {
+		return LayerResult{Skipped: true, Detail: "no matching files changed"}
+	}
+
+	runner := in.Commands
+	if runner == nil {
+		runner = iexec.NewRunner()
+	}
+	out, err := runner.RunShell(ctx, in.WorktreePath, l.Command)
+	if err != nil {
+		detail := fmt.Sprintf("%s: %v", l.Command, err)
+		if tail := outputTail(string(out), 20); tail != "" {
+			detail += "\n" + tail
+		}
+		return LayerResult{Detail: detail}
+	}
+	return LayerResult{Passed: true}
+}
+
+// outputTail returns the last n lines of output.
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// stubLayer is a ValidationLayer returning a fixed result.
+type stubLayer struct {
+	name   string
+	result LayerResult
+	input  ValidationInput
+}
+
+func (l *stubLayer) Name() string { return l.name }
+
+func (l *stubLayer) Run(_ context.Context, in ValidationInput) LayerResult {
+	l.input = in
+	return l.result
+}
+
+func TestRegisterValidationLayer(t *testing.T) {
+	layer := &stubLayer{name: "License-Headers"}
+	if err := RegisterValidationLayer(layer); err != nil {
+		t.Fatalf("RegisterValidationLayer() error = %v", err)
+	}
+	defer UnregisterValidationLayer("license-headers")
+
+	if err := RegisterValidationLayer(&stubLayer{name: "license-headers"}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("expected a duplicate to be rejected, got %v", err)
+	}
+	if err := RegisterValidationLayer(&stubLayer{name: ValidationGates}); err == nil || !strings.Contains(err.Error(), "built in") {
+		t.Errorf("expected a built-in name to be rejected, got %v", err)
+	}
+	if got := RegisteredValidationLayers(); len(got) != 1 || got[0] != "license-headers" {
+		t.Errorf("RegisteredValidationLayers() = %v", got)
+	}
+
+	pipeline := &ValidationPipeline{Layers: []PipelineLayer{{Name: ValidationGates}, {Name: "license-headers"}}}
+	if err := pipeline.Validate(); err != nil {
+		t.Errorf("expected a registered layer to be valid, got %v", err)
+	}
+}
+
+func TestRunValidationPipeline_RegisteredLayer(t *testing.T) {
+	layer := &stubLayer{name: "openapi", result: LayerResult{Detail: "schema mismatch in /users"}}
+	if err := RegisterValidationLayer(layer); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterValidationLayer("openapi")
+
+	dir := t.TempDir()
+	e := &Executor{}
+	result := &ExecutionResult{}
+	task := &models.Task{ID: "task-1"}
+	errMsg := e.runValidationPipeline(context.Background(), &ValidationPipeline{
+		Layers: []PipelineLayer{{Name: "openapi"}},
+	}, &validationRun{
+		result:       result,
+		task:         task,
+		tier:         models.TierBuilder,
+		worktreePath: dir,
+		verifyCtx:    &verificationContext{},
+	})
+
+	if errMsg != "openapi validation failed" {
+		t.Errorf("error = %q", errMsg)
+	}
+	if v := result.Validation[0]; v.Layer != "openapi" || v.Passed || v.Skipped || v.Detail != "schema mismatch in /users" {
+		t.Errorf("outcome = %+v", v)
+	}
+	if layer.input.Task != task || layer.input.WorktreePath != dir || layer.input.Tier != models.TierBuilder {
+		t.Errorf("layer input = %+v", layer.input)
+	}
+}
+
+func TestCommandLayer(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check := &CommandLayer{LayerName: "license", Command: "grep -q Copyright main.go || { echo missing header; exit 1; }"}
+
+	res := check.Run(context.Background(), ValidationInput{WorktreePath: dir})
+	if res.Passed || !strings.Contains(res.Detail, "missing header") {
+		t.Errorf("expected the check to fail with its output, got %+v", res)
+	}
+
+	scoped := &CommandLayer{LayerName: "migrations", Command: "false", Paths: []string{"db/migrations/**"}}
+	res = scoped.Run(context.Background(), ValidationInput{WorktreePath: dir, ChangedFiles: []string{"main.go"}})
+	if !res.Skipped {
+		t.Errorf("expected a layer for other paths to be skipped, got %+v", res)
+	}
+
+	pass := &CommandLayer{LayerName: "ok", Command: "true"}
+	if res := pass.Run(context.Background(), ValidationInput{WorktreePath: dir}); !res.Passed {
+		t.Errorf("expected the command to pass, got %+v", res)
+	}
+}
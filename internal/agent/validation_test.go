@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/verification"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestDefaultValidationPipeline(t *testing.T) {
+	tests := []struct {
+		tier models.Tier
+		want []string
+	}{
+		{models.TierQuick, []string{ValidationGates}},
+		{models.TierScout, []string{ValidationGates}},
+		{models.TierBuilder, []string{ValidationReview, ValidationGates, ValidationVerification}},
+		{models.TierArchitect, []string{ValidationReview, ValidationGates, ValidationVerification}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tier), func(t *testing.T) {
+			p := DefaultValidationPipeline(tt.tier)
+			if got := layerNames(p.Layers); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("layers = %v, want %v", got, tt.want)
+			}
+			if p.ContinueOnFailure {
+				t.Error("expected the default pipeline to fail fast")
+			}
+		})
+	}
+}
+
+func TestValidationPipelineFromConfig(t *testing.T) {
+	p, err := ValidationPipelineFromConfig(nil)
+	if err != nil || p != nil {
+		t.Fatalf("expected nil config to select the default, got %+v, %v", p, err)
+	}
+
+	p, err = ValidationPipelineFromConfig(&config.ValidationConfig{
+		Layers: []config.ValidationLayerConfig{
+			{Name: " Gates ", Timeout: time.Minute},
+			{Name: "verification"},
+		},
+		ContinueOnFailure: true,
+	})
+	if err != nil {
+		t.Fatalf("ValidationPipelineFromConfig failed: %v", err)
+	}
+	if got := layerNames(p.Layers); strings.Join(got, ",") != "gates,verification" {
+		t.Errorf("layers = %v", got)
+	}
+	if p.Layers[0].Timeout != time.Minute || !p.ContinueOnFailure {
+		t.Errorf("pipeline = %+v", p)
+	}
+
+	// An empty layer list is valid: nothing is validated
+	if p, err := ValidationPipelineFromConfig(&config.ValidationConfig{}); err != nil || len(p.Layers) != 0 {
+		t.Errorf("expected an empty pipeline, got %+v, %v", p, err)
+	}
+}
+
+func TestValidationPipelineValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layers  []PipelineLayer
+		wantErr string
+	}{
+		{"unknown", []PipelineLayer{{Name: "fuzz"}}, "unknown validation layer"},
+		{"duplicate", []PipelineLayer{{Name: ValidationGates}, {Name: ValidationGates}}, "listed twice"},
+		{"negative timeout", []PipelineLayer{{Name: ValidationReview, Timeout: -time.Second}}, "negative timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ValidationPipeline{Layers: tt.layers}).Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if err := (&ValidationPipeline{SkipReviewConfidence: 1.5}).Validate(); err == nil || !strings.Contains(err.Error(), "between 0 and 1") {
+		t.Errorf("expected an out-of-range confidence threshold to be rejected, got %v", err)
+	}
+	if err := (&ValidationPipeline{Coverage: &CoverageGate{MaxDrop: -1}}).Validate(); err == nil || !strings.Contains(err.Error(), "max_drop") {
+		t.Errorf("expected a negative coverage drop to be rejected, got %v", err)
+	}
+}
+
+func TestRunValidationPipeline_FailFast(t *testing.T) {
+	e := &Executor{}
+	failed := false
+
+	tests := []struct {
+		name              string
+		continueOnFailure bool
+		wantGates         ValidationOutcome
+	}{
+		{"fail fast", false, ValidationOutcome{Layer: ValidationGates, Skipped: true}},
+		{"continue", true, ValidationOutcome{Layer: ValidationGates, Passed: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ExecutionResult{VerifyPassed: &failed, VerifySummary: "1 check failed"}
+			pipeline := &ValidationPipeline{
+				Layers:            []PipelineLayer{{Name: ValidationVerification}, {Name: ValidationGates}},
+				ContinueOnFailure: tt.continueOnFailure,
+			}
+			errMsg := e.runValidationPipeline(context.Background(), pipeline, &validationRun{
+				result:       result,
+				task:         &models.Task{ID: "task-1"},
+				tier:         models.TierScout,
+				opts:         &ExecuteOptions{EnableQualityGates: true},
+				worktreePath: t.TempDir(),
+				verifyCtx:    &verificationContext{},
+			})
+
+			if errMsg != "verification contract failed" {
+				t.Errorf("error = %q, want the first failure", errMsg)
+			}
+			if len(result.Validation) != 2 {
+				t.Fatalf("outcomes = %+v", result.Validation)
+			}
+			if v := result.Validation[0]; v.Layer != ValidationVerification || v.Passed || v.Skipped {
+				t.Errorf("verification outcome = %+v", v)
+			}
+			g := result.Validation[1]
+			if g.Layer != tt.wantGates.Layer || g.Passed != tt.wantGates.Passed || g.Skipped != tt.wantGates.Skipped {
+				t.Errorf("gates outcome = %+v, want %+v", g, tt.wantGates)
+			}
+			if ran := result.GatesPassed != nil; ran != tt.continueOnFailure {
+				t.Errorf("gates ran = %v, want %v", ran, tt.continueOnFailure)
+			}
+		})
+	}
+}
+
+func TestRunValidationPipeline_SkipsDisabledLayers(t *testing.T) {
+	e := &Executor{}
+	result := &ExecutionResult{}
+	pipeline := DefaultValidationPipeline(models.TierBuilder)
+
+	errMsg := e.runValidationPipeline(context.Background(), pipeline, &validationRun{
+		result:       result,
+		task:         &models.Task{ID: "task-1"},
+		tier:         models.TierBuilder,
+		opts:         &ExecuteOptions{},
+		worktreePath: t.TempDir(),
+		verifyCtx:    &verificationContext{},
+	})
+
+	if errMsg != "" {
+		t.Errorf("expected no failure, got %q", errMsg)
+	}
+	for _, o := range result.Validation {
+		if !o.Skipped {
+			t.Errorf("expected %s to be skipped, got %+v", o.Layer, o)
+		}
+	}
+	if out := formatValidation(result.Validation); out != "" {
+		t.Errorf("expected skips without a failure to stay out of the output, got %q", out)
+	}
+}
+
+func TestRunValidationPipeline_LayerTimeout(t *testing.T) {
+	dir := t.TempDir()
+	e := &Executor{}
+	result := &ExecutionResult{}
+	contract := &verification.VerificationContract{
+		Commands: []verification.VerificationCommand{{Command: "exec sleep 5", Expect: "exit 0", Required: true}},
+	}
+	pipeline := &ValidationPipeline{Layers: []PipelineLayer{
+		{Name: ValidationVerification, Timeout: 100 * time.Millisecond},
+		{Name: ValidationGates},
+	}}
+
+	start := time.Now()
+	errMsg := e.runValidationPipeline(context.Background(), pipeline, &validationRun{
+		result:       result,
+		task:         &models.Task{ID: "task-1"},
+		tier:         models.TierScout,
+		opts:         &ExecuteOptions{EnableQualityGates: true},
+		worktreePath: dir,
+		verifyCtx:    &verificationContext{finalContract: contract},
+	})
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the layer to be cut off, took %v", elapsed)
+	}
+	if !strings.Contains(errMsg, "verification validation timed out") {
+		t.Errorf("error = %q", errMsg)
+	}
+	if v := result.Validation[0]; !v.TimedOut || v.Passed {
+		t.Errorf("verification outcome = %+v", v)
+	}
+	if !result.Validation[1].Skipped {
+		t.Errorf("expected gates to be skipped after the timeout, got %+v", result.Validation[1])
+	}
+
+	out := formatValidation(result.Validation)
+	if !strings.Contains(out, "[Validation verification: failed") || !strings.Contains(out, "[Validation gates: skipped after an earlier layer failed]") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func layerNames(layers []PipelineLayer) []string {
+	names := make([]string, len(layers))
+	for i, l := range layers {
+		names[i] = l.Name
+	}
+	return names
+}
@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// defaultWarmUpTimeout bounds a warm-up command without a configured timeout.
+const defaultWarmUpTimeout = 5 * time.Minute
+
+// WarmUpPhase identifies when a warm-up runs.
+type WarmUpPhase string
+
+const (
+	// WarmUpBeforeAgent runs in a fresh worktree before the agent starts.
+	WarmUpBeforeAgent WarmUpPhase = "before_agent"
+	// WarmUpBeforeValidation runs after the agent finishes, before quality
+	// gates and verification.
+	WarmUpBeforeValidation WarmUpPhase = "before_validation"
+)
+
+// WarmUpHook is a setup command (code generation, asset compilation, schema
+// load) run in a task's worktree so builds work there.
+type WarmUpHook struct {
+	// Name labels the hook in logs. Defaults to the command.
+	Name string
+	// Paths are glob patterns ("db/schema/**", "*.proto", "assets/") the
+	// hook applies to. A hook runs for tasks touching a matching path; a hook
+	// without patterns runs for every task.
+	Paths []string
+	// Command is run with sh -c in the worktree root.
+	Command string
+	// Timeout bounds the command. Defaults to 5 minutes.
+	Timeout time.Duration
+}
+
+// label returns the name the hook is reported under.
+func (h WarmUpHook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Command
+}
+
+// applies returns true if the hook should run for a task touching files.
+// Without known files every hook applies, since the task could touch anything.
+func (h WarmUpHook) applies(files []string) bool {
+	if len(h.Paths) == 0 || len(files) == 0 {
+		return true
+	}
+	for _, f := range files {
+		for _, p := range h.Paths {
+			if matchPathPattern(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WarmUpResult records one warm-up of a hook.
+type WarmUpResult struct {
+	// Hook is the hook's label.
+	Hook string
+	// Phase is when the warm-up ran.
+	Phase WarmUpPhase
+	// Skipped is true if the hook's inputs were unchanged since it last ran.
+	Skipped bool
+	// Duration is how long the command ran.
+	Duration time.Duration
+	// Output is the combined command output.
+	Output string
+	// Err is set if the command failed.
+	Err error
+}
+
+// worktreeWarmUp runs the warm-up hooks of one worktree. It remembers the
+// fingerprint of each hook's input files so a hook is not rerun before
+// validation unless the agent changed its inputs.
+type worktreeWarmUp struct {
+	hooks []WarmUpHook
+	dir   string
+	// fingerprints maps hook index to the input fingerprint of its last run.
+	fingerprints map[int]string
+}
+
+// newWorktreeWarmUp prepares the hooks for the worktree at dir.
+func newWorktreeWarmUp(hooks []WarmUpHook, dir string) *worktreeWarmUp {
+	return &worktreeWarmUp{hooks: hooks, dir: dir, fingerprints: make(map[int]string)}
+}
+
+// Run runs every hook that applies to files, skipping hooks whose inputs are
+// unchanged since their last run. It stops at the first failing hook.
+func (w *worktreeWarmUp) Run(ctx context.Context, phase WarmUpPhase, files []string) ([]WarmUpResult, error) {
+	var results []WarmUpResult
+	for i, hook := range w.hooks {
+		if !hook.applies(files) {
+			continue
+		}
+		res := WarmUpResult{Hook: hook.label(), Phase: phase}
+
+		fingerprint, err := w.fingerprint(hook)
+		if err != nil {
+			return results, fmt.Errorf("warm-up %q: fingerprint inputs: %w", hook.label(), err)
+		}
+		if prev, ok := w.fingerprints[i]; ok && prev == fingerprint {
+			res.Skipped = true
+			results = append(results, res)
+			continue
+		}
+
+		start := time.Now()
+		res.Output, res.Err = w.run(ctx, hook)
+		res.Duration = time.Since(start)
+		results = append(results, res)
+		if res.Err != nil {
+			return results, fmt.Errorf("warm-up %q: %w", hook.label(), res.Err)
+		}
+		// Fingerprint after running so files the hook generates count as unchanged
+		if fingerprint, err = w.fingerprint(hook); err == nil {
+			w.fingerprints[i] = fingerprint
+		}
+	}
+	return results, nil
+}
+
+// run executes the hook's command in the worktree.
+func (w *worktreeWarmUp) run(ctx context.Context, hook WarmUpHook) (string, error) {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultWarmUpTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	argv := iexec.ShellCommand(hook.Command)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = w.dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out.String(), fmt.Errorf("timed out after %v", timeout)
+	}
+	return out.String(), err
+}
+
+// fingerprint hashes the hook's command and the contents of the worktree
+// files matching its patterns.
+func (w *worktreeWarmUp) fingerprint(hook WarmUpHook) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, hook.Command)
+	if len(hook.Paths) == 0 {
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(w.dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range hook.Paths {
+			if matchPathPattern(p, rel) {
+				files = append(files, rel)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(w.dir, f))
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(h, "\x00%s\x00%x", f, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// formatWarmUps renders warm-up results for the task log.
+func formatWarmUps(results []WarmUpResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			fmt.Fprintf(&b, "\n[Warm-up %s (%s): skipped, inputs unchanged]", r.Hook, r.Phase)
+		case r.Err != nil:
+			fmt.Fprintf(&b, "\n[Warm-up %s (%s): failed after %v: %v]\n%s", r.Hook, r.Phase, r.Duration.Round(time.Millisecond), r.Err, r.Output)
+		default:
+			fmt.Fprintf(&b, "\n[Warm-up %s (%s): done in %v]", r.Hook, r.Phase, r.Duration.Round(time.Millisecond))
+		}
+	}
+	return b.String()
+}
+
+// matchPathPattern reports whether a slash-separated relative path matches a
+// glob pattern. A pattern ending in "/" matches everything under that
+// directory, "**" matches any number of directories, and a pattern without
+// a slash is matched against the file name.
+func matchPathPattern(pattern, path string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	if pattern == "" {
+		return false
+	}
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern)
+	}
+	if strings.Contains(pattern, "**") {
+		return globRegexp(pattern).MatchString(path)
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := pathpkg.Match(pattern, pathpkg.Base(path))
+		return ok
+	}
+	ok, _ := pathpkg.Match(pattern, path)
+	return ok
+}
+
+// globRegexp converts a glob pattern with "**" into an anchored regexp.
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// worktreeChangedFiles lists files changed or added in the worktree since
+// its last commit.
+func worktreeChangedFiles(dir string) []string {
+	cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=all")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		// Renames are reported as "old -> new"
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+4:]
+		}
+		files = append(files, strings.Trim(path, `"`))
+	}
+	return files
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"db/schema/**", "db/schema/users.sql", true},
+		{"db/schema/**", "db/schema/v2/orders.sql", true},
+		{"db/schema/**", "db/seed.sql", false},
+		{"**/*.proto", "api/v1/user.proto", true},
+		{"**/*.proto", "user.proto", true},
+		{"*.proto", "api/v1/user.proto", true},
+		{"assets/", "assets/css/site.css", true},
+		{"assets/", "src/assets.go", false},
+		{"internal/*.go", "internal/a.go", true},
+		{"internal/*.go", "internal/x/a.go", false},
+		{"", "a.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWorktreeWarmUp(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("schema/users.sql", "create table users;")
+
+	hooks := []WarmUpHook{
+		// Generates a file from the schema and counts its runs
+		{Name: "schema", Paths: []string{"schema/**"}, Command: "echo run >> schema.log && cat schema/*.sql > gen.go"},
+		{Name: "assets", Paths: []string{"assets/"}, Command: "echo run >> assets.log"},
+	}
+	w := newWorktreeWarmUp(hooks, dir)
+	ctx := context.Background()
+	runs := func(log string) int {
+		data, _ := os.ReadFile(filepath.Join(dir, log))
+		return strings.Count(string(data), "run")
+	}
+
+	// Only hooks matching the task's files run before the agent
+	results, err := w.Run(ctx, WarmUpBeforeAgent, []string{"schema/users.sql"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 1 || results[0].Hook != "schema" || results[0].Skipped {
+		t.Fatalf("results = %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gen.go")); err != nil {
+		t.Errorf("warm-up did not run in the worktree: %v", err)
+	}
+
+	// Unchanged inputs skip the rerun before validation
+	results, err = w.Run(ctx, WarmUpBeforeValidation, []string{"schema/users.sql"})
+	if err != nil || len(results) != 1 || !results[0].Skipped || runs("schema.log") != 1 {
+		t.Fatalf("expected a cached skip, got %+v, %v (%d runs)", results, err, runs("schema.log"))
+	}
+
+	// Changed inputs run it again
+	write("schema/users.sql", "create table users (id int);")
+	if _, err := w.Run(ctx, WarmUpBeforeValidation, []string{"schema/users.sql"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := runs("schema.log"); n != 2 {
+		t.Errorf("schema warm-up ran %d times, want 2", n)
+	}
+	if n := runs("assets.log"); n != 0 {
+		t.Errorf("unrelated warm-up ran %d times", n)
+	}
+}
+
+func TestWorktreeWarmUpFailure(t *testing.T) {
+	w := newWorktreeWarmUp([]WarmUpHook{
+		{Command: "echo broken >&2; exit 3"},
+		{Name: "never", Command: "touch never"},
+	}, t.TempDir())
+
+	results, err := w.Run(context.Background(), WarmUpBeforeAgent, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Output, "broken") {
+		t.Errorf("results = %+v", results)
+	}
+	if !strings.Contains(formatWarmUps(results), "failed") {
+		t.Errorf("formatWarmUps() = %q", formatWarmUps(results))
+	}
+}
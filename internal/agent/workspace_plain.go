@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ShayCichocki/alphie/internal/vcs"
+)
+
+// Verify PlainWorkspaceManager implements WorktreeProvider at compile time.
+var _ WorktreeProvider = (*PlainWorkspaceManager)(nil)
+
+// PlainWorkspaceManager gives each agent a copy-on-write copy of a project
+// that is not a git repository. Agents' changes are returned as patches
+// instead of commits on a branch.
+type PlainWorkspaceManager struct {
+	workspaces *vcs.PlainWorkspaces
+}
+
+// NewPlainWorkspaceManager creates a PlainWorkspaceManager for the project
+// at repoPath. baseDir is where workspaces are created (defaults to a
+// per-project directory under ~/.cache/alphie/workspaces).
+func NewPlainWorkspaceManager(baseDir, repoPath string) (*PlainWorkspaceManager, error) {
+	if baseDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("get home directory: %w", err)
+		}
+		baseDir = filepath.Join(home, ".cache", "alphie", "workspaces", projectDirName(repoPath))
+	}
+	workspaces, err := vcs.NewPlainWorkspaces(repoPath, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return &PlainWorkspaceManager{workspaces: workspaces}, nil
+}
+
+// projectDirName names a project's workspace directory after the project,
+// with a hash of its absolute path so same-named projects do not share one.
+func projectDirName(repoPath string) string {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		abs = repoPath
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Base(abs) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Create copies the project into a new workspace for the agent. A
+// workspace left at the same path by a crashed run is replaced.
+func (m *PlainWorkspaceManager) Create(agentID string) (*Worktree, error) {
+	if agentID == "" {
+		agentID = uuid.New().String()
+	}
+	name := fmt.Sprintf("agent-%s", agentID)
+	path := filepath.Join(m.workspaces.BaseDir(), name)
+	if m.workspaces.Get(path) == nil {
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("remove stale workspace: %w", err)
+		}
+	}
+
+	ws, err := m.workspaces.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Worktree{Path: ws.Path, BranchName: name, AgentID: agentID, CreatedAt: time.Now()}, nil
+}
+
+// Patch returns the changes the agent made in its workspace.
+func (m *PlainWorkspaceManager) Patch(wt *Worktree) (*vcs.Patch, error) {
+	return m.workspaces.Diff(wt.Path)
+}
+
+// ApplyPatch applies an agent's patch to the project. A patch that
+// conflicts with one applied since its workspace was created returns a
+// *vcs.ConflictError and changes nothing.
+func (m *PlainWorkspaceManager) ApplyPatch(p *vcs.Patch) error {
+	return m.workspaces.Apply(p)
+}
+
+// Release removes the workspace; plain workspaces are not pooled.
+func (m *PlainWorkspaceManager) Release(wt *Worktree) error {
+	return m.workspaces.Remove(wt.Path)
+}
+
+// Remove removes the workspace at path.
+func (m *PlainWorkspaceManager) Remove(path string, force bool) error {
+	return m.workspaces.Remove(path)
+}
+
+// Unlock is a no-op; plain workspaces are never locked.
+func (m *PlainWorkspaceManager) Unlock(path string) error {
+	return nil
+}
+
+// List returns the workspaces on disk, including those left by earlier
+// runs.
+func (m *PlainWorkspaceManager) List() ([]*Worktree, error) {
+	paths, err := m.workspaces.List()
+	if err != nil {
+		return nil, err
+	}
+	worktrees := make([]*Worktree, 0, len(paths))
+	for _, path := range paths {
+		name := filepath.Base(path)
+		worktrees = append(worktrees, &Worktree{
+			Path:       path,
+			BranchName: name,
+			AgentID:    strings.TrimPrefix(name, "agent-"),
+		})
+	}
+	return worktrees, nil
+}
+
+// Prune is a no-op; plain workspaces have no references to prune.
+func (m *PlainWorkspaceManager) Prune() error {
+	return nil
+}
+
+// RecoverOrphaned removes the workspaces this process did not create.
+func (m *PlainWorkspaceManager) RecoverOrphaned() ([]string, error) {
+	orphans, err := m.ListOrphans(nil)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, wt := range orphans {
+		if err := m.workspaces.Remove(wt.Path); err == nil {
+			removed = append(removed, wt.Path)
+		}
+	}
+	return removed, nil
+}
+
+// ListOrphans returns the workspaces this process did not create. Plain
+// workspaces are not tied to sessions, so activeSessions is not used.
+func (m *PlainWorkspaceManager) ListOrphans(activeSessions []string) ([]*Worktree, error) {
+	worktrees, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	var orphans []*Worktree
+	for _, wt := range worktrees {
+		if m.workspaces.Get(wt.Path) == nil {
+			orphans = append(orphans, wt)
+		}
+	}
+	return orphans, nil
+}
+
+// CleanupOrphans removes orphaned workspaces and returns how many were
+// removed.
+func (m *PlainWorkspaceManager) CleanupOrphans(activeSessions []string, verbose func(path string)) (int, error) {
+	removed, err := m.RecoverOrphaned()
+	if err != nil {
+		return 0, err
+	}
+	if verbose != nil {
+		for _, path := range removed {
+			verbose(path)
+		}
+	}
+	return len(removed), nil
+}
+
+// StartupCleanup removes workspaces left by crashed runs.
+func (m *PlainWorkspaceManager) StartupCleanup(activeSessions []string) (int, error) {
+	return m.CleanupOrphans(activeSessions, nil)
+}
+
+// BaseDir returns the directory workspaces are created in.
+func (m *PlainWorkspaceManager) BaseDir() string {
+	return m.workspaces.BaseDir()
+}
+
+// RepoPath returns the project directory.
+func (m *PlainWorkspaceManager) RepoPath() string {
+	return m.workspaces.Root()
+}
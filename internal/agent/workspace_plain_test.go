@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlainWorkspaceManager(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseDir := t.TempDir()
+	// A workspace left behind by a crashed run
+	stale := filepath.Join(baseDir, "agent-old")
+	if err := os.MkdirAll(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewPlainWorkspaceManager(baseDir, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := m.Create("task-1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if wt.Path != filepath.Join(baseDir, "agent-task-1") {
+		t.Errorf("workspace path = %s", wt.Path)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "util.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := m.ListOrphans(nil)
+	if err != nil || len(orphans) != 1 || orphans[0].Path != stale {
+		t.Fatalf("ListOrphans() = %+v, %v, want only the stale workspace", orphans, err)
+	}
+	if n, err := m.StartupCleanup(nil); err != nil || n != 1 {
+		t.Errorf("StartupCleanup() = %d, %v", n, err)
+	}
+
+	patch, err := m.Patch(wt)
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if !reflect.DeepEqual(patch.Files(), []string{"util.go"}) {
+		t.Errorf("patch files = %v", patch.Files())
+	}
+	if err := m.Release(wt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "util.go")); !os.IsNotExist(err) {
+		t.Error("expected the project to be unchanged until the patch is applied")
+	}
+
+	e := &Executor{worktreeMgr: m}
+	if err := e.ApplyPatch(patch); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "util.go")); err != nil {
+		t.Errorf("expected util.go to be applied: %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/state"
 )
 
 // Worktree represents a git worktree managed by Alphie.
@@ -28,6 +29,9 @@ type Worktree struct {
 type WorktreeProvider interface {
 	// Create creates a new worktree for the given agent.
 	Create(agentID string) (*Worktree, error)
+	// Release returns a worktree whose task has finished, keeping it for
+	// reuse if the pool has room and removing it otherwise.
+	Release(wt *Worktree) error
 	// Remove removes a worktree at the given path.
 	Remove(path string, force bool) error
 	// Unlock unlocks a locked worktree.
@@ -59,12 +63,17 @@ type WorktreeManager struct {
 	repoPath string // Path to the main git repository
 	git      git.Runner
 	mu       sync.Mutex
+
+	// poolSize is how many idle worktrees are kept for reuse (0 disables pooling).
+	poolSize int
+	// store records worktree ownership; nil disables tracking.
+	store WorktreeOwnershipStore
 }
 
 // NewWorktreeManager creates a new WorktreeManager.
 // baseDir is where worktrees will be created (defaults to ~/.cache/alphie/worktrees).
 // repoPath is the path to the main git repository.
-func NewWorktreeManager(baseDir, repoPath string) (*WorktreeManager, error) {
+func NewWorktreeManager(baseDir, repoPath string, opts ...WorktreeManagerOption) (*WorktreeManager, error) {
 	if baseDir == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -78,15 +87,19 @@ func NewWorktreeManager(baseDir, repoPath string) (*WorktreeManager, error) {
 		return nil, fmt.Errorf("create worktree base directory: %w", err)
 	}
 
-	return &WorktreeManager{
+	m := &WorktreeManager{
 		baseDir:  baseDir,
 		repoPath: repoPath,
 		git:      git.NewRunner(repoPath),
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
 }
 
 // NewWorktreeManagerWithRunner creates a new WorktreeManager with a custom git runner (for testing).
-func NewWorktreeManagerWithRunner(baseDir, repoPath string, runner git.Runner) (*WorktreeManager, error) {
+func NewWorktreeManagerWithRunner(baseDir, repoPath string, runner git.Runner, opts ...WorktreeManagerOption) (*WorktreeManager, error) {
 	if baseDir == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -100,16 +113,21 @@ func NewWorktreeManagerWithRunner(baseDir, repoPath string, runner git.Runner) (
 		return nil, fmt.Errorf("create worktree base directory: %w", err)
 	}
 
-	return &WorktreeManager{
+	m := &WorktreeManager{
 		baseDir:  baseDir,
 		repoPath: repoPath,
 		git:      runner,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
 }
 
 // Create creates a new worktree for the given agent.
 // Returns the created Worktree with path and branch information.
-// If a worktree already exists at the path, it will be reused if clean or reset if dirty.
+// With pooling enabled an idle pooled worktree is checked out on the agent's
+// branch instead when one is available.
 func (m *WorktreeManager) Create(agentID string) (*Worktree, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -118,8 +136,31 @@ func (m *WorktreeManager) Create(agentID string) (*Worktree, error) {
 		agentID = uuid.New().String()
 	}
 
+	var wt *Worktree
+	var err error
+	if m.poolSize > 0 {
+		wt, err = m.acquirePooled(agentID)
+	}
+	if wt == nil && err == nil {
+		wt, err = m.create(agentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.recordOwnership(wt, state.WorktreeInUse)
+	return wt, nil
+}
+
+// create adds a new worktree for the agent. Pooled worktrees get a
+// pool-<id> directory since they outlive the agent.
+// If a worktree already exists at the path, it will be reused if clean or reset if dirty.
+func (m *WorktreeManager) create(agentID string) (*Worktree, error) {
 	branchName := fmt.Sprintf("agent-%s", agentID)
 	worktreePath := filepath.Join(m.baseDir, branchName)
+	if m.poolSize > 0 {
+		worktreePath = filepath.Join(m.baseDir, poolDirPrefix+uuid.New().String()[:8])
+	}
 
 	// Check if worktree directory already exists
 	if _, err := os.Stat(worktreePath); err == nil {
@@ -253,6 +294,7 @@ func (m *WorktreeManager) Remove(path string, force bool) error {
 	if err := m.git.WorktreeRemoveOptionalForce(path, force); err != nil {
 		return fmt.Errorf("remove worktree: %w", err)
 	}
+	m.forget(path)
 
 	return nil
 }
@@ -398,6 +440,7 @@ func (m *WorktreeManager) RecoverOrphaned() ([]string, error) {
 				continue // Skip if we can't remove it
 			}
 		}
+		m.forget(path)
 		recovered = append(recovered, path)
 	}
 
@@ -507,6 +550,9 @@ func (m *WorktreeManager) ListOrphans(activeSessions []string) ([]*Worktree, err
 		activeSet[sessionID] = true
 	}
 
+	// Worktrees owned by a running process are never orphans
+	owned := m.liveOwnedPaths()
+
 	// Find orphaned worktrees
 	var orphans []*Worktree
 	for _, wt := range worktrees {
@@ -520,6 +566,10 @@ func (m *WorktreeManager) ListOrphans(activeSessions []string) ([]*Worktree, err
 			continue
 		}
 
+		if owned[wt.Path] {
+			continue
+		}
+
 		// Check if this worktree's session is active
 		sessionID := extractSessionID(wt)
 		if sessionID != "" && activeSet[sessionID] {
@@ -567,6 +617,7 @@ func (m *WorktreeManager) CleanupOrphans(activeSessions []string, verbose func(p
 				continue // Skip if we can't remove it
 			}
 		}
+		m.forget(wt.Path)
 
 		if verbose != nil {
 			verbose(wt.Path)
@@ -0,0 +1,355 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// poolDirPrefix names the directories of pooled worktrees. Pooled worktrees
+// outlive the agents that use them, so they are not named after an agent.
+const poolDirPrefix = "pool-"
+
+// WorktreeOwnershipStore persists which process owns each worktree so the
+// worktrees of crashed processes can be found and pruned. *state.DB
+// implements it.
+type WorktreeOwnershipStore interface {
+	// SaveWorktree creates or replaces the ownership record of a worktree.
+	SaveWorktree(w *state.WorktreeRecord) error
+	// ListWorktrees lists the worktrees of a repository, optionally filtered by status.
+	ListWorktrees(repoPath string, status *state.WorktreeStatus) ([]state.WorktreeRecord, error)
+	// DeleteWorktree deletes the ownership record of the worktree at path.
+	DeleteWorktree(path string) error
+}
+
+// Verify state.DB implements WorktreeOwnershipStore at compile time.
+var _ WorktreeOwnershipStore = (*state.DB)(nil)
+
+// WorktreeManagerOption configures a WorktreeManager.
+type WorktreeManagerOption func(*WorktreeManager)
+
+// WithPoolSize keeps up to n idle worktrees for reuse instead of removing
+// them when their task finishes. 0 disables pooling.
+func WithPoolSize(n int) WorktreeManagerOption {
+	return func(m *WorktreeManager) {
+		if n > 0 {
+			m.poolSize = n
+		}
+	}
+}
+
+// WithOwnershipStore records worktree ownership in store.
+func WithOwnershipStore(store WorktreeOwnershipStore) WorktreeManagerOption {
+	return func(m *WorktreeManager) {
+		m.store = store
+	}
+}
+
+// Release returns a worktree whose task has finished. If pooling is enabled
+// and the pool has room, the worktree is reset, detached from the agent's
+// branch (so the branch can still be merged or deleted) and kept for the
+// next task; ignored files such as dependency and build caches survive.
+// Otherwise the worktree is removed.
+func (m *WorktreeManager) Release(wt *Worktree) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.poolSize > 0 && isPoolWorktreePath(wt.Path, m.baseDir) {
+		idle, err := m.idleWorktrees()
+		if err == nil && len(idle) < m.poolSize {
+			err = m.resetWorktree(wt.Path, "--detach")
+			if err == nil {
+				m.recordOwnership(&Worktree{Path: wt.Path}, state.WorktreePooled)
+				return nil
+			}
+			log.Printf("[worktree] could not return %s to the pool: %v", wt.Path, err)
+		}
+	}
+
+	if err := m.git.WorktreeRemoveOptionalForce(wt.Path, true); err != nil {
+		return fmt.Errorf("remove worktree: %w", err)
+	}
+	m.forget(wt.Path)
+	return nil
+}
+
+// acquirePooled checks out the agent's branch in an idle pooled worktree.
+// Returns nil if no pooled worktree is available.
+func (m *WorktreeManager) acquirePooled(agentID string) (*Worktree, error) {
+	idle, err := m.idleWorktrees()
+	if err != nil || len(idle) == 0 {
+		return nil, nil
+	}
+
+	head, err := m.git.Run("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolve HEAD: %w", err)
+	}
+
+	branchName := fmt.Sprintf("agent-%s", agentID)
+	exists, err := m.git.BranchExists(branchName)
+	if err != nil {
+		return nil, fmt.Errorf("check branch: %w", err)
+	}
+
+	for _, path := range idle {
+		// Keep work already on an existing agent branch (e.g. a retried task)
+		checkout := []string{"-b", branchName, strings.TrimSpace(head)}
+		if exists {
+			checkout = []string{branchName}
+		}
+		if err := m.resetWorktree(path, checkout...); err != nil {
+			// A broken pooled worktree is dropped rather than reused
+			log.Printf("[worktree] discarding pooled worktree %s: %v", path, err)
+			_ = m.git.WorktreeRemoveOptionalForce(path, true)
+			m.forget(path)
+			continue
+		}
+		return &Worktree{
+			Path:       path,
+			BranchName: branchName,
+			AgentID:    agentID,
+			CreatedAt:  time.Now(),
+		}, nil
+	}
+	return nil, nil
+}
+
+// resetWorktree discards changes and untracked files in the worktree at
+// path, then runs git checkout with the given arguments there.
+func (m *WorktreeManager) resetWorktree(path string, checkout ...string) error {
+	steps := [][]string{
+		{"reset", "--hard", "HEAD"},
+		{"clean", "-fd"},
+		append([]string{"checkout"}, checkout...),
+	}
+	for _, args := range steps {
+		if _, err := m.git.Run(append([]string{"-C", path}, args...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idleWorktrees returns the pooled worktrees of this repository that are
+// not checked out on a branch and not claimed by another running process.
+func (m *WorktreeManager) idleWorktrees() ([]string, error) {
+	output, err := m.git.WorktreeListPorcelain()
+	if err != nil {
+		return nil, fmt.Errorf("list worktrees: %w", err)
+	}
+	worktrees, err := m.parseWorktreeListUnlocked(output)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := m.liveOwnedPaths()
+	var idle []string
+	for _, wt := range worktrees {
+		if wt.BranchName != "" || !isPoolWorktreePath(wt.Path, m.baseDir) || owned[wt.Path] {
+			continue
+		}
+		idle = append(idle, wt.Path)
+	}
+	return idle, nil
+}
+
+// isPoolWorktreePath returns true if path is a pooled worktree directory
+// under baseDir.
+func isPoolWorktreePath(path, baseDir string) bool {
+	return filepath.Dir(path) == filepath.Clean(baseDir) && strings.HasPrefix(filepath.Base(path), poolDirPrefix)
+}
+
+// recordOwnership records that this process holds wt with the given status.
+func (m *WorktreeManager) recordOwnership(wt *Worktree, status state.WorktreeStatus) {
+	if m.store == nil {
+		return
+	}
+	rec := &state.WorktreeRecord{
+		Path:       wt.Path,
+		RepoPath:   m.repoPath,
+		BranchName: wt.BranchName,
+		TaskID:     wt.AgentID,
+		Status:     status,
+		PID:        os.Getpid(),
+	}
+	if err := m.store.SaveWorktree(rec); err != nil {
+		log.Printf("[worktree] failed to record ownership of %s: %v", wt.Path, err)
+	}
+}
+
+// forget drops the ownership record of the worktree at path.
+func (m *WorktreeManager) forget(path string) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.DeleteWorktree(path); err != nil {
+		log.Printf("[worktree] failed to drop ownership record of %s: %v", path, err)
+	}
+}
+
+// liveOwnedPaths returns the worktrees marked in use by a running process.
+func (m *WorktreeManager) liveOwnedPaths() map[string]bool {
+	owned := make(map[string]bool)
+	if m.store == nil {
+		return owned
+	}
+	inUse := state.WorktreeInUse
+	records, err := m.store.ListWorktrees(m.repoPath, &inUse)
+	if err != nil {
+		return owned
+	}
+	for _, r := range records {
+		if iexec.ProcessAlive(r.PID) {
+			owned[r.Path] = true
+		}
+	}
+	return owned
+}
+
+// StaleCleanupOptions controls PruneStale.
+type StaleCleanupOptions struct {
+	// DryRun reports what would be removed without removing anything.
+	DryRun bool
+	// DrainPool also removes idle pooled worktrees.
+	DrainPool bool
+	// UnmergedBranches also deletes agent branches that are not merged into
+	// HEAD. Their work is lost, so only use this when no session is running.
+	UnmergedBranches bool
+}
+
+// StaleCleanupReport lists what PruneStale removed (or would remove).
+type StaleCleanupReport struct {
+	// Worktrees are removed worktree paths.
+	Worktrees []string
+	// Branches are deleted agent branches.
+	Branches []string
+	// Forgotten are ownership records dropped because their worktree no
+	// longer exists.
+	Forgotten []string
+}
+
+// PruneStale removes worktrees whose owning process has exited, drops
+// ownership records of worktrees that no longer exist, and deletes agent
+// branches that are no longer checked out anywhere and are merged into HEAD.
+func (m *WorktreeManager) PruneStale(opts StaleCleanupOptions) (*StaleCleanupReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := &StaleCleanupReport{}
+	if !opts.DryRun {
+		if err := m.git.WorktreePruneExpireNow(); err != nil {
+			return nil, fmt.Errorf("prune worktrees: %w", err)
+		}
+	}
+
+	output, err := m.git.WorktreeListPorcelain()
+	if err != nil {
+		return nil, fmt.Errorf("list worktrees: %w", err)
+	}
+	worktrees, err := m.parseWorktreeListUnlocked(output)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]*Worktree, len(worktrees))
+	for _, wt := range worktrees {
+		known[wt.Path] = wt
+	}
+
+	stale := make(map[string]bool)
+	if m.store != nil {
+		records, err := m.store.ListWorktrees(m.repoPath, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list worktree ownership: %w", err)
+		}
+		for _, r := range records {
+			switch {
+			case known[r.Path] == nil:
+				report.Forgotten = append(report.Forgotten, r.Path)
+				if !opts.DryRun {
+					m.forget(r.Path)
+				}
+			case r.Status == state.WorktreeInUse && !iexec.ProcessAlive(r.PID):
+				stale[r.Path] = true
+			}
+		}
+	}
+	if opts.DrainPool {
+		idle, err := m.idleWorktrees()
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range idle {
+			stale[path] = true
+		}
+	}
+
+	for _, wt := range worktrees {
+		if !stale[wt.Path] {
+			continue
+		}
+		report.Worktrees = append(report.Worktrees, wt.Path)
+		if opts.DryRun {
+			delete(known, wt.Path)
+			continue
+		}
+		_ = m.git.WorktreeUnlock(wt.Path) // Ignore errors, it may not be locked
+		if err := m.git.WorktreeRemove(wt.Path); err != nil {
+			log.Printf("[worktree] failed to remove stale worktree %s: %v", wt.Path, err)
+			continue
+		}
+		m.forget(wt.Path)
+		delete(known, wt.Path)
+	}
+
+	branches, err := m.staleAgentBranches(known, opts.UnmergedBranches)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		if !opts.DryRun {
+			if err := m.git.DeleteBranch(b); err != nil {
+				log.Printf("[worktree] failed to delete branch %s: %v", b, err)
+				continue
+			}
+		}
+		report.Branches = append(report.Branches, b)
+	}
+
+	return report, nil
+}
+
+// staleAgentBranches returns the agent branches not checked out in any of
+// the remaining worktrees, limited to branches merged into HEAD unless
+// unmerged is true.
+func (m *WorktreeManager) staleAgentBranches(remaining map[string]*Worktree, unmerged bool) ([]string, error) {
+	checkedOut := make(map[string]bool, len(remaining))
+	for _, wt := range remaining {
+		checkedOut[wt.BranchName] = true
+	}
+
+	args := []string{"branch", "--format=%(refname:short)", "--list", "agent-*"}
+	if !unmerged {
+		args = append(args, "--merged", "HEAD")
+	}
+	output, err := m.git.Run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("list agent branches: %w", err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
+		b := strings.TrimSpace(line)
+		if b == "" || checkedOut[b] {
+			continue
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
@@ -0,0 +1,208 @@
+package agent
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// newPoolTestManager creates a repository, a state database and a
+// WorktreeManager with the given pool size recording ownership in it.
+func newPoolTestManager(t *testing.T, poolSize int) (*WorktreeManager, *state.DB) {
+	t.Helper()
+	repo := t.TempDir()
+	if err := initTestGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	m, err := NewWorktreeManager(t.TempDir(), repo, WithPoolSize(poolSize), WithOwnershipStore(db))
+	if err != nil {
+		t.Fatalf("NewWorktreeManager: %v", err)
+	}
+	return m, db
+}
+
+// gitIn runs git in dir and returns its trimmed output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestWorktreeManager_ReleaseReusesPooledWorktree(t *testing.T) {
+	m, db := newPoolTestManager(t, 1)
+
+	first, err := m.Create("task-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(first.Path), poolDirPrefix) {
+		t.Errorf("pooled worktree path = %s, want %s* directory", first.Path, poolDirPrefix)
+	}
+	rec, _ := db.GetWorktree(first.Path)
+	if rec == nil || rec.Status != state.WorktreeInUse || rec.PID != os.Getpid() || rec.TaskID != "task-1" {
+		t.Fatalf("ownership record = %+v, want in use by this process", rec)
+	}
+
+	// Leave a stray file behind; the next task must not see it
+	if err := os.WriteFile(filepath.Join(first.Path, "scratch.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Release(first); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if rec, _ := db.GetWorktree(first.Path); rec == nil || rec.Status != state.WorktreePooled {
+		t.Fatalf("ownership record after release = %+v, want pooled", rec)
+	}
+
+	second, err := m.Create("task-2")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if second.Path != first.Path {
+		t.Errorf("second worktree = %s, want reused %s", second.Path, first.Path)
+	}
+	if branch := gitIn(t, second.Path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "agent-task-2" {
+		t.Errorf("reused worktree on branch %s, want agent-task-2", branch)
+	}
+	if _, err := os.Stat(filepath.Join(second.Path, "scratch.txt")); !os.IsNotExist(err) {
+		t.Error("stray file from previous task survived reuse")
+	}
+	// The previous task's branch is kept for merging
+	if exists, _ := m.git.BranchExists("agent-task-1"); !exists {
+		t.Error("agent-task-1 branch was deleted on release")
+	}
+}
+
+func TestWorktreeManager_ReleaseRemovesWhenPoolFull(t *testing.T) {
+	m, db := newPoolTestManager(t, 1)
+
+	a, err := m.Create("task-a")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	b, err := m.Create("task-b")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if a.Path == b.Path {
+		t.Fatal("concurrent tasks share a worktree")
+	}
+
+	if err := m.Release(a); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := m.Release(b); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	if _, err := os.Stat(a.Path); err != nil {
+		t.Errorf("first released worktree should be pooled: %v", err)
+	}
+	if _, err := os.Stat(b.Path); !os.IsNotExist(err) {
+		t.Errorf("worktree released into a full pool should be removed")
+	}
+	if rec, _ := db.GetWorktree(b.Path); rec != nil {
+		t.Errorf("removed worktree still tracked: %+v", rec)
+	}
+}
+
+func TestWorktreeManager_PruneStale(t *testing.T) {
+	m, db := newPoolTestManager(t, 2)
+
+	crashed, err := m.Create("crashed")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Simulate a crashed owner
+	rec, _ := db.GetWorktree(crashed.Path)
+	rec.PID = 1 << 30
+	if err := db.SaveWorktree(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	live, err := m.Create("live")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// An unmerged agent branch left by an earlier crash
+	gitIn(t, m.repoPath, "checkout", "-q", "-b", "agent-unmerged")
+	if err := os.WriteFile(filepath.Join(m.repoPath, "work.txt"), []byte("work"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, m.repoPath, "add", ".")
+	gitIn(t, m.repoPath, "commit", "-q", "-m", "work")
+	gitIn(t, m.repoPath, "checkout", "-q", "-")
+
+	dry, err := m.PruneStale(StaleCleanupOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneStale (dry run): %v", err)
+	}
+	if len(dry.Worktrees) != 1 || dry.Worktrees[0] != crashed.Path {
+		t.Errorf("dry run worktrees = %v, want [%s]", dry.Worktrees, crashed.Path)
+	}
+	if _, err := os.Stat(crashed.Path); err != nil {
+		t.Fatalf("dry run removed the worktree: %v", err)
+	}
+
+	report, err := m.PruneStale(StaleCleanupOptions{})
+	if err != nil {
+		t.Fatalf("PruneStale: %v", err)
+	}
+	if len(report.Worktrees) != 1 || report.Worktrees[0] != crashed.Path {
+		t.Errorf("removed worktrees = %v, want [%s]", report.Worktrees, crashed.Path)
+	}
+	if _, err := os.Stat(live.Path); err != nil {
+		t.Errorf("worktree of a running process was removed: %v", err)
+	}
+	if strings.Join(report.Branches, ",") != "agent-crashed" {
+		t.Errorf("deleted branches = %v, want [agent-crashed]", report.Branches)
+	}
+	if exists, _ := m.git.BranchExists("agent-unmerged"); !exists {
+		t.Error("unmerged agent branch deleted without UnmergedBranches")
+	}
+
+	report, err = m.PruneStale(StaleCleanupOptions{UnmergedBranches: true})
+	if err != nil {
+		t.Fatalf("PruneStale: %v", err)
+	}
+	if strings.Join(report.Branches, ",") != "agent-unmerged" {
+		t.Errorf("deleted branches = %v, want [agent-unmerged]", report.Branches)
+	}
+}
+
+func TestWorktreeManager_ListOrphansSkipsLiveOwners(t *testing.T) {
+	m, _ := newPoolTestManager(t, 0)
+
+	wt, err := m.Create("busy")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	orphans, err := m.ListOrphans(nil)
+	if err != nil {
+		t.Fatalf("ListOrphans: %v", err)
+	}
+	for _, o := range orphans {
+		if o.Path == wt.Path {
+			t.Errorf("worktree owned by a running process listed as orphan")
+		}
+	}
+}
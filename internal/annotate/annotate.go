@@ -0,0 +1,149 @@
+// Package annotate attributes the changes of an Alphie session to the tasks
+// and spec features that motivated them.
+//
+// Agent work reaches the session branch as "Merge branch 'agent-<taskID>'"
+// commits, so walking the first-parent history between a base and head
+// recovers which task produced each hunk. The resulting Report renders as
+// Markdown for reading or as a pull request review for posting inline.
+package annotate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+// Task describes the task behind a change.
+type Task struct {
+	// ID is the task ID.
+	ID string
+	// Title is the task title.
+	Title string
+	// Description is the task description.
+	Description string
+	// Parent is the title of the parent epic, if any.
+	Parent string
+}
+
+// TaskLookup resolves a task ID. It returns nil if the task is unknown.
+type TaskLookup func(taskID string) *Task
+
+// Change is one commit on the session branch and the task it is attributed to.
+type Change struct {
+	// Commit is the commit hash.
+	Commit string
+	// Subject is the commit subject line.
+	Subject string
+	// TaskID is the attributed task ID, or empty if unattributed.
+	TaskID string
+	// Task holds task details when the lookup knew the task.
+	Task *Task
+	// Feature is the spec feature ID the task implements, if known.
+	Feature string
+	// Files are the files changed by the commit.
+	Files []FileDiff
+}
+
+// Title returns a human-readable label for the change.
+func (c *Change) Title() string {
+	switch {
+	case c.Task != nil && c.Task.Title != "":
+		return c.Task.Title
+	case c.TaskID != "":
+		return c.TaskID
+	default:
+		return c.Subject
+	}
+}
+
+// Report is an annotated session diff.
+type Report struct {
+	// Base is the ref the diff starts from.
+	Base string
+	// Head is the ref the diff ends at.
+	Head string
+	// Changes are the attributed changes, oldest first.
+	Changes []*Change
+}
+
+var (
+	// agentMergePattern matches merges of agent branches into the session branch.
+	agentMergePattern = regexp.MustCompile(`^Merge branch '?agent-([^'\s]+)'?`)
+	// sessionMergePattern matches the merge of a session branch into main.
+	sessionMergePattern = regexp.MustCompile(`^Merge session \S+`)
+	// agentCommitPattern matches the commits agents make in their worktrees.
+	agentCommitPattern = regexp.MustCompile(`^Agent: (.+)$`)
+	// featurePattern matches the titles the architect planner gives gap tasks.
+	featurePattern = regexp.MustCompile(`^(?:Implement|Complete) (\S+)$`)
+)
+
+// Build walks the first-parent history of head back to base and attributes
+// each commit's diff to a task. Merged sessions are expanded so annotating
+// main after "Merge session" still reaches the individual agent merges.
+// lookup may be nil, in which case tasks are identified by ID only.
+func Build(g git.Runner, base, head string, lookup TaskLookup) (*Report, error) {
+	report := &Report{Base: base, Head: head}
+	if err := collect(g, base, head, lookup, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// collect appends the changes in base..head to report.
+func collect(g git.Runner, base, head string, lookup TaskLookup, report *Report) error {
+	out, err := g.Run("log", "--first-parent", "--reverse", "--format=%H%x1f%P%x1f%s", base+".."+head)
+	if err != nil {
+		return fmt.Errorf("list commits %s..%s: %w", base, head, err)
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		commit, subject := fields[0], fields[2]
+		parents := strings.Fields(fields[1])
+
+		if len(parents) > 1 && sessionMergePattern.MatchString(subject) {
+			if err := collect(g, parents[0], parents[1], lookup, report); err != nil {
+				return err
+			}
+			continue
+		}
+
+		change := &Change{Commit: commit, Subject: subject}
+		if m := agentMergePattern.FindStringSubmatch(subject); m != nil && len(parents) > 1 {
+			change.TaskID = m[1]
+		}
+		if change.TaskID != "" && lookup != nil {
+			change.Task = lookup(change.TaskID)
+		}
+		title := ""
+		if change.Task != nil {
+			title = change.Task.Title
+		} else if m := agentCommitPattern.FindStringSubmatch(subject); m != nil {
+			title = m[1]
+		}
+		if m := featurePattern.FindStringSubmatch(title); m != nil {
+			change.Feature = m[1]
+		}
+
+		var diff string
+		if len(parents) == 0 {
+			diff, err = g.Run("show", "--format=", commit)
+		} else {
+			diff, err = g.DiffBetween(parents[0], commit)
+		}
+		if err != nil {
+			return fmt.Errorf("diff %s: %w", commit, err)
+		}
+		change.Files = ParseDiff(diff)
+		if len(change.Files) == 0 {
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	return nil
+}
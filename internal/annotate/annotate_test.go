@@ -0,0 +1,208 @@
+package annotate
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+// gitCmd runs git in dir and fails the test on error.
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeFile writes content to dir/name.
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupSession builds a repo with a session branch holding two agent merges
+// and one direct commit, mirroring how the orchestrator lays out history.
+func setupSession(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-b", "main")
+	gitCmd(t, dir, "config", "user.name", "Test")
+	gitCmd(t, dir, "config", "user.email", "test@test.com")
+	writeFile(t, dir, "a.go", "package a\n\nfunc A() {}\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-m", "initial")
+
+	gitCmd(t, dir, "checkout", "-b", "session-s1")
+
+	gitCmd(t, dir, "checkout", "-b", "agent-t1", "session-s1")
+	writeFile(t, dir, "a.go", "package a\n\nfunc A() {}\n\nfunc B() {}\n")
+	gitCmd(t, dir, "commit", "-am", "Agent: Implement F1")
+	gitCmd(t, dir, "checkout", "session-s1")
+	gitCmd(t, dir, "merge", "--no-ff", "--no-edit", "agent-t1")
+
+	gitCmd(t, dir, "checkout", "-b", "agent-t2", "session-s1")
+	writeFile(t, dir, "b.go", "package a\n\nfunc C() {}\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-m", "Agent: Add C")
+	gitCmd(t, dir, "checkout", "session-s1")
+	gitCmd(t, dir, "merge", "--no-ff", "--no-edit", "agent-t2")
+
+	writeFile(t, dir, "README", "notes\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-m", "Auto-commit pending changes")
+
+	return dir
+}
+
+func TestBuildAttributesAgentMerges(t *testing.T) {
+	dir := setupSession(t)
+	lookup := func(id string) *Task {
+		if id == "t1" {
+			return &Task{ID: "t1", Title: "Implement F1", Description: "## Gap Details\n\n**Status:** MISSING\n\n**Description:** Add B\n", Parent: "Spec run"}
+		}
+		return nil
+	}
+
+	report, err := Build(git.NewRunner(dir), "main", "session-s1", lookup)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(report.Changes) != 3 {
+		t.Fatalf("got %d changes, want 3", len(report.Changes))
+	}
+
+	first := report.Changes[0]
+	if first.TaskID != "t1" || first.Feature != "F1" || first.Task == nil {
+		t.Errorf("first change = task %q feature %q, want t1/F1 with task details", first.TaskID, first.Feature)
+	}
+	if len(first.Files) != 1 || first.Files[0].Path != "a.go" || len(first.Files[0].Hunks) != 1 {
+		t.Fatalf("first change files = %+v", first.Files)
+	}
+	if h := first.Files[0].Hunks[0]; h.Added() != 2 || h.Removed() != 0 {
+		t.Errorf("hunk +%d -%d, want +2 -0", h.Added(), h.Removed())
+	}
+
+	second := report.Changes[1]
+	if second.TaskID != "t2" || second.Task != nil || second.Feature != "" {
+		t.Errorf("second change = task %q feature %q", second.TaskID, second.Feature)
+	}
+	if len(second.Files) != 1 || second.Files[0].Path != "b.go" {
+		t.Errorf("second change files = %+v", second.Files)
+	}
+
+	if third := report.Changes[2]; third.TaskID != "" {
+		t.Errorf("direct commit attributed to %q, want unattributed", third.TaskID)
+	}
+}
+
+func TestBuildExpandsMergedSession(t *testing.T) {
+	dir := setupSession(t)
+	gitCmd(t, dir, "checkout", "main")
+	gitCmd(t, dir, "merge", "--no-ff", "-m", "Merge session s1", "session-s1")
+
+	report, err := Build(git.NewRunner(dir), "HEAD~1", "main", nil)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(report.Changes) != 3 {
+		t.Fatalf("got %d changes, want 3", len(report.Changes))
+	}
+	if report.Changes[0].TaskID != "t1" || report.Changes[1].TaskID != "t2" {
+		t.Errorf("tasks = %q, %q; want t1, t2", report.Changes[0].TaskID, report.Changes[1].TaskID)
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/x.go b/x.go
+index 1111111..2222222 100644
+--- a/x.go
++++ b/x.go
+@@ -1,3 +1,4 @@ package x
+ package x
++// added
+ func X() {}
+@@ -10 +11,0 @@
+-gone
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+\ No newline at end of file`
+
+	files := ParseDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if files[0].Path != "x.go" || len(files[0].Hunks) != 2 {
+		t.Fatalf("x.go = %+v", files[0])
+	}
+	h := files[0].Hunks[0]
+	if h.OldStart != 1 || h.OldLines != 3 || h.NewStart != 1 || h.NewLines != 4 || h.Added() != 1 {
+		t.Errorf("hunk 0 = %+v", h)
+	}
+	h = files[0].Hunks[1]
+	if h.OldStart != 10 || h.OldLines != 1 || h.NewLines != 0 || h.Removed() != 1 {
+		t.Errorf("hunk 1 = %+v", h)
+	}
+	if files[1].Path != "old.txt" || len(files[1].Hunks) != 1 || len(files[1].Hunks[0].Lines) != 1 {
+		t.Errorf("old.txt = %+v", files[1])
+	}
+}
+
+func TestRenderMarkdownAndReview(t *testing.T) {
+	report := &Report{
+		Base: "main",
+		Head: "session-s1",
+		Changes: []*Change{{
+			Commit:  "0123456789abcdef",
+			TaskID:  "t1",
+			Task:    &Task{ID: "t1", Title: "Implement F1", Description: "**Description:** Add B"},
+			Feature: "F1",
+			Files: []FileDiff{{
+				Path: "a.go",
+				Hunks: []Hunk{
+					{Header: "@@ -3,0 +4,2 @@", OldStart: 3, OldLines: 0, NewStart: 4, NewLines: 2, Lines: []string{"+", "+func B() {}"}},
+					{Header: "@@ -9 +10,0 @@", OldStart: 9, OldLines: 1, NewStart: 10, NewLines: 0, Lines: []string{"-old"}},
+				},
+			}},
+		}},
+	}
+
+	var sb strings.Builder
+	if err := report.Markdown(&sb); err != nil {
+		t.Fatal(err)
+	}
+	md := sb.String()
+	for _, want := range []string{"| Implement F1 (`t1`) | F1 | 1 | +2 -1 |", "Feature: `F1`", "> Add B", "### `a.go`", "+func B() {}"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	review := report.Review()
+	if review.Event != "COMMENT" || len(review.Comments) != 2 {
+		t.Fatalf("review = %+v", review)
+	}
+	if c := review.Comments[0]; c.Path != "a.go" || c.Line != 5 || c.Side != "RIGHT" {
+		t.Errorf("comment 0 = %+v", c)
+	}
+	if c := review.Comments[1]; c.Line != 9 || c.Side != "LEFT" {
+		t.Errorf("comment 1 = %+v", c)
+	}
+	if _, err := json.Marshal(review); err != nil {
+		t.Errorf("marshal review: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package annotate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the diff of a single file.
+type FileDiff struct {
+	// Path is the file path after the change (before it, for deletions).
+	Path string
+	// Hunks are the changed regions of the file.
+	Hunks []Hunk
+}
+
+// Hunk is one changed region of a file.
+type Hunk struct {
+	// Header is the "@@ -a,b +c,d @@" line.
+	Header string
+	// OldStart and OldLines locate the hunk in the old file.
+	OldStart, OldLines int
+	// NewStart and NewLines locate the hunk in the new file.
+	NewStart, NewLines int
+	// Lines are the hunk body lines, including their +, - or space prefix.
+	Lines []string
+}
+
+// Added returns the number of added lines in the hunk.
+func (h *Hunk) Added() int {
+	return h.count('+')
+}
+
+// Removed returns the number of removed lines in the hunk.
+func (h *Hunk) Removed() int {
+	return h.count('-')
+}
+
+// count returns the number of body lines starting with prefix.
+func (h *Hunk) count(prefix byte) int {
+	n := 0
+	for _, l := range h.Lines {
+		if len(l) > 0 && l[0] == prefix {
+			n++
+		}
+	}
+	return n
+}
+
+// hunkHeaderPattern matches unified diff hunk headers.
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses unified diff output from git into per-file hunks.
+// Binary files and pure renames appear with no hunks.
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+
+	flushHunk := func() {
+		if file != nil && hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if file != nil {
+			files = append(files, *file)
+		}
+		file = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			file = &FileDiff{Path: pathFromDiffHeader(line)}
+		case file == nil:
+			continue
+		case hunk == nil && strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				file.Path = strings.TrimPrefix(p, "b/")
+			}
+		case hunk == nil && strings.HasPrefix(line, "--- "):
+			// Path comes from the +++ line unless the file was deleted.
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			hunk = &Hunk{
+				Header:   line,
+				OldStart: atoi(m[1]),
+				OldLines: countOrOne(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: countOrOne(m[4]),
+			}
+		case hunk != nil:
+			if line == "" || strings.HasPrefix(line, `\`) {
+				continue
+			}
+			hunk.Lines = append(hunk.Lines, line)
+		}
+	}
+	flushFile()
+
+	return files
+}
+
+// pathFromDiffHeader extracts the new path from a "diff --git a/x b/y" line.
+func pathFromDiffHeader(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// atoi parses a decimal string, returning 0 on failure.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// countOrOne parses an optional hunk line count, which defaults to 1.
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
@@ -0,0 +1,212 @@
+package annotate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// maxHunkLines caps the body lines rendered per hunk in Markdown.
+	maxHunkLines = 200
+	// maxSummaryLen caps the task description summary length.
+	maxSummaryLen = 240
+)
+
+// Markdown writes the report as a Markdown document: a summary table
+// followed by one section per change with its task context and hunks.
+func (r *Report) Markdown(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Session changes `%s..%s`\n\n", r.Base, r.Head))
+	if len(r.Changes) == 0 {
+		sb.WriteString("No changes.\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	sb.WriteString("| Task | Feature | Files | Lines |\n")
+	sb.WriteString("|------|---------|-------|-------|\n")
+	for _, c := range r.Changes {
+		added, removed := c.lineCounts()
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | +%d -%d |\n",
+			escapeCell(c.label()), escapeCell(orDash(c.Feature)), len(c.Files), added, removed))
+	}
+
+	for _, c := range r.Changes {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", c.label()))
+		sb.WriteString(c.contextLine())
+		sb.WriteString("\n")
+		if summary := c.summary(); summary != "" {
+			sb.WriteString(fmt.Sprintf("\n> %s\n", summary))
+		}
+
+		for _, f := range c.Files {
+			sb.WriteString(fmt.Sprintf("\n### `%s`\n\n", f.Path))
+			if len(f.Hunks) == 0 {
+				sb.WriteString("_No textual changes (binary or rename)._\n")
+				continue
+			}
+			sb.WriteString("```diff\n")
+			for _, h := range f.Hunks {
+				sb.WriteString(h.Header)
+				sb.WriteString("\n")
+				for i, l := range h.Lines {
+					if i == maxHunkLines {
+						sb.WriteString(fmt.Sprintf("... %d more lines\n", len(h.Lines)-maxHunkLines))
+						break
+					}
+					sb.WriteString(l)
+					sb.WriteString("\n")
+				}
+			}
+			sb.WriteString("```\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Review is a pull request review in the shape accepted by the GitHub
+// "create a review" API, so it can be posted with
+// `gh api repos/{owner}/{repo}/pulls/{n}/reviews --input review.json`.
+type Review struct {
+	Body     string          `json:"body"`
+	Event    string          `json:"event"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// ReviewComment is an inline review comment on one hunk.
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// Review converts the report into a pull request review with one inline
+// comment per hunk explaining which task and feature produced it.
+func (r *Report) Review() *Review {
+	review := &Review{Event: "COMMENT", Comments: []ReviewComment{}}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Alphie attributed %d changes in `%s..%s` to their tasks.\n\n", len(r.Changes), r.Base, r.Head))
+	for _, c := range r.Changes {
+		added, removed := c.lineCounts()
+		line := fmt.Sprintf("- %s (+%d -%d)", c.label(), added, removed)
+		if c.Feature != "" {
+			line += fmt.Sprintf(" — feature `%s`", c.Feature)
+		}
+		body.WriteString(line + "\n")
+	}
+	review.Body = body.String()
+
+	for _, c := range r.Changes {
+		comment := fmt.Sprintf("**%s**\n\n%s", c.label(), c.contextLine())
+		if summary := c.summary(); summary != "" {
+			comment += "\n\n> " + summary
+		}
+		for _, f := range c.Files {
+			for _, h := range f.Hunks {
+				rc := ReviewComment{Path: f.Path, Side: "RIGHT", Body: comment}
+				if h.NewLines > 0 {
+					rc.Line = h.NewStart + h.NewLines - 1
+				} else {
+					// Pure deletion: anchor on the removed lines.
+					rc.Side = "LEFT"
+					rc.Line = h.OldStart + h.OldLines - 1
+				}
+				if rc.Line < 1 {
+					rc.Line = 1
+				}
+				review.Comments = append(review.Comments, rc)
+			}
+		}
+	}
+
+	return review
+}
+
+// label returns the change title with its task ID, if any.
+func (c *Change) label() string {
+	title := c.Title()
+	if c.TaskID != "" && title != c.TaskID {
+		return fmt.Sprintf("%s (`%s`)", title, c.TaskID)
+	}
+	if c.TaskID != "" {
+		return fmt.Sprintf("`%s`", c.TaskID)
+	}
+	return title
+}
+
+// contextLine returns the task, feature, epic and commit of the change.
+func (c *Change) contextLine() string {
+	var parts []string
+	if c.TaskID == "" {
+		parts = append(parts, "Unattributed commit")
+	}
+	if c.Feature != "" {
+		parts = append(parts, fmt.Sprintf("Feature: `%s`", c.Feature))
+	}
+	if c.Task != nil && c.Task.Parent != "" {
+		parts = append(parts, fmt.Sprintf("Epic: %s", c.Task.Parent))
+	}
+	commit := c.Commit
+	if len(commit) > 10 {
+		commit = commit[:10]
+	}
+	parts = append(parts, fmt.Sprintf("Commit: `%s`", commit))
+	return strings.Join(parts, " · ")
+}
+
+// summary returns a one-line summary of the task description.
+// Gap tasks created by the architect planner carry a "**Description:**"
+// line, which is preferred; otherwise the first non-heading line is used.
+func (c *Change) summary() string {
+	if c.Task == nil {
+		return ""
+	}
+	var first string
+	for _, line := range strings.Split(c.Task.Description, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "**Description:**"); ok {
+			first = strings.TrimSpace(rest)
+			break
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	if len(first) > maxSummaryLen {
+		first = first[:maxSummaryLen] + "..."
+	}
+	return first
+}
+
+// lineCounts returns the total added and removed lines of the change.
+func (c *Change) lineCounts() (added, removed int) {
+	for _, f := range c.Files {
+		for i := range f.Hunks {
+			added += f.Hunks[i].Added()
+			removed += f.Hunks[i].Removed()
+		}
+	}
+	return added, removed
+}
+
+// escapeCell escapes pipes so text can sit in a Markdown table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
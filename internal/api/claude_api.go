@@ -181,7 +181,7 @@ func (c *ClaudeAPI) runLoop(prompt string, model anthropic.Model) {
 			params.Temperature = anthropic.Float(*c.temperature)
 		}
 
-		resp, err := c.client.sdk().Messages.New(c.ctx, params)
+		resp, err := c.client.newMessage(c.ctx, params)
 		if err != nil {
 			// Debug: Write full error to file
 			if debugFile, ferr := os.OpenFile("/tmp/alphie-api-error.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); ferr == nil {
@@ -199,7 +199,8 @@ func (c *ClaudeAPI) runLoop(prompt string, model anthropic.Model) {
 		// Track tokens
 		c.client.Tracker().Add(resp.Usage.InputTokens, resp.Usage.OutputTokens)
 
-		// Emit usage as raw JSON for compatibility with token extraction
+		// Emit usage as raw JSON for compatibility with token extraction.
+		// Only the response's first event carries it, so it is counted once.
 		usageJSON, _ := json.Marshal(map[string]interface{}{
 			"usage": map[string]interface{}{
 				"input_tokens":  resp.Usage.InputTokens,
@@ -219,6 +220,7 @@ func (c *ClaudeAPI) runLoop(prompt string, model anthropic.Model) {
 					Message: variant.Text,
 					Raw:     usageJSON,
 				})
+				usageJSON = nil
 				assistantBlocks = append(assistantBlocks, anthropic.NewTextBlock(variant.Text))
 
 			case anthropic.ToolUseBlock:
@@ -229,6 +231,7 @@ func (c *ClaudeAPI) runLoop(prompt string, model anthropic.Model) {
 					ToolAction: toolAction,
 					Raw:        usageJSON,
 				})
+				usageJSON = nil
 
 				// Execute tool
 				toolResult := c.executor.Execute(c.ctx, variant.Name, variant.Input)
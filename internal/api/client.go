@@ -19,6 +19,8 @@ type Client struct {
 	inner   anthropic.Client
 	model   anthropic.Model
 	tracker *TokenTracker
+	// limiter paces requests; nil sends them unpaced.
+	limiter Limiter
 }
 
 // ClientConfig contains configuration for creating a new Client.
@@ -105,12 +107,6 @@ func translateModelForBedrock(model anthropic.Model) anthropic.Model {
 	return model
 }
 
-// sdk returns the underlying Anthropic client for internal API access.
-// This is package-private to prevent implementation leakage.
-func (c *Client) sdk() *anthropic.Client {
-	return &c.inner
-}
-
 // Model returns the configured model name.
 func (c *Client) Model() anthropic.Model {
 	return c.model
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 // ToolExecutor executes tool calls from the Claude API.
@@ -183,7 +185,8 @@ func (e *ToolExecutor) execBash(ctx context.Context, input json.RawMessage) Tool
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", params.Command)
+	argv := iexec.ShellCommand(params.Command)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
 	cmd.Dir = e.workDir
 
 	output, err := cmd.CombinedOutput()
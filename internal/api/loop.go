@@ -104,7 +104,7 @@ func (l *AgentLoop) Run(ctx context.Context, systemPrompt, userPrompt string) (*
 		}
 
 		// Make API call
-		resp, err := l.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+		resp, err := l.client.newMessage(ctx, anthropic.MessageNewParams{
 			Model:     l.client.Model(),
 			MaxTokens: 8192,
 			System: []anthropic.TextBlockParam{
@@ -200,7 +200,7 @@ func (l *AgentLoop) RunWithTools(ctx context.Context, systemPrompt, userPrompt s
 			return result, fmt.Errorf("stop signal received")
 		}
 
-		resp, err := l.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+		resp, err := l.client.newMessage(ctx, anthropic.MessageNewParams{
 			Model:     l.client.Model(),
 			MaxTokens: 8192,
 			System: []anthropic.TextBlockParam{
@@ -260,7 +260,7 @@ func (l *AgentLoop) RunWithTools(ctx context.Context, systemPrompt, userPrompt s
 
 // SimpleCall makes a single API call without tool execution (for simple prompts).
 func (l *AgentLoop) SimpleCall(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	resp, err := l.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := l.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     l.client.Model(),
 		MaxTokens: 4096,
 		System: []anthropic.TextBlockParam{
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// statusOverloaded is the status the API returns when it is overloaded.
+const statusOverloaded = 529
+
+// Limiter paces the requests of every runner sharing a Client.
+// agent.RateLimiter implements it.
+type Limiter interface {
+	// Do calls call once model's rate limits allow it, queueing behind
+	// earlier requests, and retries it while RetryAfter reports it rate
+	// limited. call returns the tokens the request used.
+	Do(ctx context.Context, model string, call func() (tokens int64, err error)) error
+}
+
+// SetLimiter paces the client's requests with limiter. Nil sends them
+// unpaced.
+func (c *Client) SetLimiter(limiter Limiter) {
+	c.limiter = limiter
+}
+
+// newMessage sends a Messages request through the client's limiter, with
+// secrets redacted from its text.
+func (c *Client) newMessage(ctx context.Context, params anthropic.MessageNewParams) (*anthropic.Message, error) {
+	params = redactParams(params)
+	if c.limiter == nil {
+		return c.inner.Messages.New(ctx, params)
+	}
+	var resp *anthropic.Message
+	err := c.limiter.Do(ctx, string(params.Model), func() (int64, error) {
+		r, err := c.inner.Messages.New(ctx, params)
+		if err != nil {
+			return 0, err
+		}
+		resp = r
+		return r.Usage.InputTokens + r.Usage.OutputTokens, nil
+	})
+	return resp, err
+}
+
+// RetryAfter reports whether err is the API rejecting a request because of
+// rate limits or overload, and how long the API asked to wait before
+// retrying (0 if it did not say).
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests && apiErr.StatusCode != statusOverloaded {
+		return 0, false
+	}
+	if apiErr.Response == nil {
+		return 0, true
+	}
+	if ms, err := strconv.ParseFloat(apiErr.Response.Header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if s, err := strconv.ParseFloat(apiErr.Response.Header.Get("retry-after"), 64); err == nil && s > 0 {
+		return time.Duration(s * float64(time.Second)), true
+	}
+	return 0, true
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestRetryAfter(t *testing.T) {
+	withHeader := func(key, value string) *http.Response {
+		return &http.Response{Header: http.Header{http.CanonicalHeaderKey(key): []string{value}}}
+	}
+	tests := []struct {
+		name    string
+		err     error
+		wait    time.Duration
+		limited bool
+	}{
+		{"other error", errors.New("boom"), 0, false},
+		{"bad request", &anthropic.Error{StatusCode: 400}, 0, false},
+		{"rate limited", &anthropic.Error{StatusCode: 429}, 0, true},
+		{"overloaded", &anthropic.Error{StatusCode: 529}, 0, true},
+		{"retry-after", &anthropic.Error{StatusCode: 429, Response: withHeader("retry-after", "3")}, 3 * time.Second, true},
+		{"retry-after-ms", &anthropic.Error{StatusCode: 429, Response: withHeader("retry-after-ms", "250")}, 250 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, limited := RetryAfter(tt.err)
+			if wait != tt.wait || limited != tt.limited {
+				t.Errorf("RetryAfter() = %s, %v; want %s, %v", wait, limited, tt.wait, tt.limited)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
+)
+
+// redactParams returns params with secrets redacted from the system prompt
+// and from the text and tool results of its messages. Tool results carry
+// what the agent read from disk or a command printed, such as a .env file.
+// params is not modified: the conversation history it shares with the
+// runner keeps the original text.
+func redactParams(params anthropic.MessageNewParams) anthropic.MessageNewParams {
+	if redact.Default() == nil {
+		return params
+	}
+
+	if len(params.System) > 0 {
+		system := make([]anthropic.TextBlockParam, len(params.System))
+		for i, block := range params.System {
+			block.Text = redact.String(block.Text)
+			system[i] = block
+		}
+		params.System = system
+	}
+
+	messages := make([]anthropic.MessageParam, len(params.Messages))
+	for i, msg := range params.Messages {
+		content := make([]anthropic.ContentBlockParamUnion, len(msg.Content))
+		for j, block := range msg.Content {
+			content[j] = redactBlock(block)
+		}
+		msg.Content = content
+		messages[i] = msg
+	}
+	params.Messages = messages
+	return params
+}
+
+// redactBlock returns a copy of a text or tool result block with secrets
+// redacted. Other blocks are returned as is.
+func redactBlock(block anthropic.ContentBlockParamUnion) anthropic.ContentBlockParamUnion {
+	switch {
+	case block.OfText != nil:
+		text := *block.OfText
+		text.Text = redact.String(text.Text)
+		block.OfText = &text
+	case block.OfToolResult != nil:
+		result := *block.OfToolResult
+		content := make([]anthropic.ToolResultBlockParamContentUnion, len(result.Content))
+		for i, c := range result.Content {
+			if c.OfText != nil {
+				text := *c.OfText
+				text.Text = redact.String(text.Text)
+				c.OfText = &text
+			}
+			content[i] = c
+		}
+		result.Content = content
+		block.OfToolResult = &result
+	}
+	return block
+}
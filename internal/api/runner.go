@@ -23,7 +23,7 @@ func NewRunner(client *Client) *Runner {
 // Run executes a prompt and returns the text response.
 // No tools are provided - this is for simple text completion tasks.
 func (r *Runner) Run(ctx context.Context, prompt string) (string, error) {
-	resp, err := r.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := r.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     r.client.Model(),
 		MaxTokens: 8192,
 		Messages: []anthropic.MessageParam{
@@ -48,7 +48,7 @@ func (r *Runner) Run(ctx context.Context, prompt string) (string, error) {
 
 // RunWithSystem executes a prompt with a system message.
 func (r *Runner) RunWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	resp, err := r.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := r.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     r.client.Model(),
 		MaxTokens: 8192,
 		System: []anthropic.TextBlockParam{
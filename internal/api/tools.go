@@ -80,12 +80,12 @@ func ToolDefinitions() []anthropic.ToolUnionParam {
 		{
 			OfTool: &anthropic.ToolParam{
 				Name:        "Bash",
-				Description: anthropic.String("Execute a bash command and return the output."),
+				Description: anthropic.String("Execute a shell command and return the output. Commands run in sh, or in cmd or PowerShell on Windows."),
 				InputSchema: anthropic.ToolInputSchemaParam{
 					Properties: map[string]interface{}{
 						"command": map[string]interface{}{
 							"type":        "string",
-							"description": "The bash command to execute",
+							"description": "The shell command to execute",
 						},
 						"timeout": map[string]interface{}{
 							"type":        "integer",
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 // Verifier implements 3-tier verification for agent output.
@@ -66,7 +68,8 @@ func (v *Verifier) verifyBuild(ctx context.Context) *VerificationResult {
 
 	for _, c := range commands {
 		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-		cmd := exec.CommandContext(ctx, "bash", "-c", c.cmd)
+		argv := iexec.ShellCommand(c.cmd)
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
 		cmd.Dir = v.workDir
 		output, err := cmd.CombinedOutput()
 		cancel()
@@ -115,7 +118,7 @@ Respond with EXACTLY one of:
 
 Be strict but fair. Only flag actual violations, not style preferences.`, archDocs, string(diff))
 
-	resp, err := v.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := v.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.ModelClaudeSonnet4_20250514,
 		MaxTokens: 1024,
 		Messages: []anthropic.MessageParam{
@@ -186,7 +189,7 @@ If you find ANY issue that could cause bugs, security problems, or significant m
 
 %s`, diffStr)
 
-	resp, err := v.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := v.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.ModelClaudeSonnet4_20250514,
 		MaxTokens: 2048,
 		Messages: []anthropic.MessageParam{
@@ -278,7 +281,7 @@ Status: [NEEDS_WORK | LGTM]
 
 If LGTM, the score should be 90+. Be honest but fair.`, taskDescription, previousOutput, diffStr)
 
-	resp, err := v.client.sdk().Messages.New(ctx, anthropic.MessageNewParams{
+	resp, err := v.client.newMessage(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.ModelClaudeSonnet4_20250514,
 		MaxTokens: 1024,
 		Messages: []anthropic.MessageParam{
@@ -0,0 +1,230 @@
+package architect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// acceptanceConfidence is the weight of the acceptance test layer: tests
+// passing or failing is stronger evidence than any review of the code.
+const acceptanceConfidence = 0.9
+
+// maxAcceptanceOutput bounds the test output kept per selector; the end of
+// the output is kept, where test runners report failures.
+const maxAcceptanceOutput = 4000
+
+// safeShellWord matches words the shell passes through unchanged.
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_./,=:-]+$`)
+
+// TestSelector selects the tests proving a feature. Go tests are selected by
+// package, name pattern and build tags; other projects give the command
+// running their tests.
+type TestSelector struct {
+	// Package is the Go package pattern to test. Empty tests ./...
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+	// Run is the test name pattern (go test -run).
+	Run string `yaml:"run,omitempty" json:"run,omitempty"`
+	// Tags are the build tags the tests need (go test -tags).
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Command is a shell command run from the repository root instead of
+	// go test, e.g. "pytest -m checkout". It passes if it exits 0.
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+}
+
+// FeatureTestMap maps feature IDs to the tests proving them.
+type FeatureTestMap map[string][]TestSelector
+
+// LoadFeatureTestMap reads a feature test map from a YAML (or JSON) file.
+// Each feature ID lists its selectors; a selector may also be written as
+// a shell command string.
+func LoadFeatureTestMap(path string) (FeatureTestMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read feature test map: %w", err)
+	}
+	var raw map[string][]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse feature test map %s: %w", path, err)
+	}
+
+	tests := make(FeatureTestMap, len(raw))
+	for id, nodes := range raw {
+		for _, node := range nodes {
+			var sel TestSelector
+			if node.Kind == yaml.ScalarNode {
+				sel.Command = node.Value
+			} else if err := node.Decode(&sel); err != nil {
+				return nil, fmt.Errorf("parse feature test map %s: feature %s: %w", path, id, err)
+			}
+			if sel.Command != "" && (sel.Package != "" || sel.Run != "" || len(sel.Tags) > 0) {
+				return nil, fmt.Errorf("parse feature test map %s: feature %s: a selector has either a command or go test fields", path, id)
+			}
+			tests[id] = append(tests[id], sel)
+		}
+	}
+	return tests, nil
+}
+
+// String returns the shell command running the selected tests.
+func (s TestSelector) String() string {
+	if s.Command != "" {
+		return s.Command
+	}
+	args := []string{"go", "test", "-count=1"}
+	if len(s.Tags) > 0 {
+		args = append(args, "-tags", shellWord(strings.Join(s.Tags, ",")))
+	}
+	if s.Run != "" {
+		args = append(args, "-run", shellWord(s.Run))
+	}
+	pkg := s.Package
+	if pkg == "" {
+		pkg = "./..."
+	}
+	return strings.Join(append(args, shellWord(pkg)), " ")
+}
+
+// SelectorResult is the outcome of one selector's tests.
+type SelectorResult struct {
+	// Command is the command that ran the tests.
+	Command string `json:"command"`
+	// Passed is true if the tests ran and passed.
+	Passed bool `json:"passed"`
+	// Reason explains a failure.
+	Reason string `json:"reason,omitempty"`
+	// Output is the end of the tests' output.
+	Output string `json:"output,omitempty"`
+}
+
+// FeatureTestResult is a feature's pass/fail from its acceptance tests.
+type FeatureTestResult struct {
+	FeatureID string `json:"feature_id"`
+	// Passed is true if every selector's tests passed.
+	Passed  bool             `json:"passed"`
+	Results []SelectorResult `json:"results"`
+}
+
+// RunFeatureTests runs the acceptance tests of every feature in features
+// that the map covers, in feature order. Selectors for features not in the
+// spec are ignored.
+func RunFeatureTests(ctx context.Context, runner exec.CommandRunner, repoPath string, tests FeatureTestMap, features []Feature) []FeatureTestResult {
+	var results []FeatureTestResult
+	for _, f := range features {
+		selectors := tests[f.ID]
+		if len(selectors) == 0 {
+			continue
+		}
+		result := FeatureTestResult{FeatureID: f.ID, Passed: true}
+		for _, sel := range selectors {
+			sr := runSelector(ctx, runner, repoPath, sel)
+			result.Passed = result.Passed && sr.Passed
+			result.Results = append(result.Results, sr)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runSelector runs one selector's tests. Go tests that match nothing fail:
+// a feature is not proven by tests that do not exist.
+func runSelector(ctx context.Context, runner exec.CommandRunner, repoPath string, sel TestSelector) SelectorResult {
+	sr := SelectorResult{Command: sel.String()}
+	output, err := runner.RunShell(ctx, repoPath, sr.Command)
+	out := string(output)
+	switch {
+	case err != nil:
+		sr.Reason = err.Error()
+	case sel.Command == "" && !ranGoTests(out):
+		sr.Reason = "no tests matched"
+	default:
+		sr.Passed = true
+	}
+
+	if len(out) > maxAcceptanceOutput {
+		out = "..." + out[len(out)-maxAcceptanceOutput:]
+	}
+	sr.Output = strings.TrimSpace(out)
+	return sr
+}
+
+// ranGoTests reports whether go test output shows a package whose tests
+// ran, rather than packages without tests or without tests matching -run.
+func ranGoTests(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "ok ") && !strings.Contains(line, "[no tests to run]") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptanceVerdict is the acceptance test layer's verdict on a feature:
+// COMPLETE if its tests pass, PARTIAL if any fail.
+func acceptanceVerdict(result FeatureTestResult) LayerVerdict {
+	v := LayerVerdict{Layer: LayerAcceptance, Status: AuditStatusComplete, Confidence: acceptanceConfidence}
+	var failed []string
+	for _, sr := range result.Results {
+		if !sr.Passed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", sr.Command, sr.Reason))
+		}
+	}
+	if len(failed) == 0 {
+		v.Reason = fmt.Sprintf("%d acceptance test selector(s) passed", len(result.Results))
+		return v
+	}
+	v.Status = AuditStatusPartial
+	v.Reason = "acceptance tests failed: " + strings.Join(failed, "; ")
+	return v
+}
+
+// shellWord quotes s for use as a single POSIX shell word, unless it is
+// safe as is.
+func shellWord(s string) string {
+	if s != "" && safeShellWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// maxPromptTestOutput bounds the test output of a failing selector in the
+// audit prompt.
+const maxPromptTestOutput = 1000
+
+// writeAcceptanceSection gives the semantic review the acceptance test
+// results of the features, with the end of each failing selector's output.
+func writeAcceptanceSection(sb *strings.Builder, results []FeatureTestResult) {
+	sb.WriteString("## Acceptance Tests\n\n")
+	sb.WriteString("These tests were run against the codebase for this audit. A feature whose tests fail is not COMPLETE; tests that pass support a COMPLETE status but do not prove criteria they do not cover.\n\n")
+	for _, result := range results {
+		for _, sr := range result.Results {
+			if sr.Passed {
+				sb.WriteString(fmt.Sprintf("- %s: passed (`%s`)\n", result.FeatureID, sr.Command))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("- %s: FAILED (`%s`): %s\n", result.FeatureID, sr.Command, sr.Reason))
+			if out := sr.Output; out != "" {
+				if len(out) > maxPromptTestOutput {
+					out = "..." + out[len(out)-maxPromptTestOutput:]
+				}
+				sb.WriteString("```\n" + out + "\n```\n")
+			}
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// featureTestResults indexes acceptance test results by feature ID.
+func featureTestResults(results []FeatureTestResult) map[string]FeatureTestResult {
+	byFeature := make(map[string]FeatureTestResult, len(results))
+	for _, r := range results {
+		byFeature[r.FeatureID] = r
+	}
+	return byFeature
+}
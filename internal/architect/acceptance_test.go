@@ -0,0 +1,229 @@
+package architect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+)
+
+// fakeTestRunner answers shell commands from canned outputs.
+type fakeTestRunner struct {
+	outputs map[string]string
+	fail    map[string]bool
+	ran     []string
+}
+
+func (f *fakeTestRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	return nil, errors.New("unexpected command")
+}
+
+func (f *fakeTestRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	f.ran = append(f.ran, command)
+	if f.fail[command] {
+		return []byte(f.outputs[command]), errors.New("exit status 1")
+	}
+	return []byte(f.outputs[command]), nil
+}
+
+func (f *fakeTestRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+func TestLoadFeatureTestMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tests.yaml")
+	data := `
+auth/login:
+  - package: ./internal/auth
+    run: TestLogin
+F2:
+  - tags: [integration, db]
+    run: "TestRefund|TestVoid"
+  - pytest -m refunds
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests, err := LoadFeatureTestMap(path)
+	if err != nil {
+		t.Fatalf("LoadFeatureTestMap() error = %v", err)
+	}
+
+	var got []string
+	for _, id := range []string{"auth/login", "F2"} {
+		for _, sel := range tests[id] {
+			got = append(got, sel.String())
+		}
+	}
+	want := []string{
+		"go test -count=1 -run TestLogin ./internal/auth",
+		"go test -count=1 -tags integration,db -run 'TestRefund|TestVoid' ./...",
+		"pytest -m refunds",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("selector commands = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("F1:\n  - command: make test\n    run: TestX\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFeatureTestMap(path); err == nil {
+		t.Error("LoadFeatureTestMap() accepted a selector with both a command and go test fields")
+	}
+}
+
+func TestRunFeatureTests(t *testing.T) {
+	tests := FeatureTestMap{
+		"F1":      {{Package: "./auth", Run: "TestLogin"}},
+		"F2":      {{Package: "./billing", Run: "TestRefund"}, {Command: "make e2e"}},
+		"F3":      {{Package: "./export", Run: "TestNothing"}},
+		"unknown": {{Command: "make unknown"}},
+	}
+	runner := &fakeTestRunner{
+		outputs: map[string]string{
+			"go test -count=1 -run TestLogin ./auth":     "ok  \texample.com/auth\t0.2s\n",
+			"go test -count=1 -run TestRefund ./billing": "ok  \texample.com/billing\t0.1s\n",
+			"make e2e": "--- FAIL: refund e2e\n",
+			"go test -count=1 -run TestNothing ./export": "ok  \texample.com/export\t0.1s [no tests to run]\n",
+		},
+		fail: map[string]bool{"make e2e": true},
+	}
+	features := []Feature{{ID: "F1"}, {ID: "F2"}, {ID: "F3"}, {ID: "F4"}}
+
+	results := RunFeatureTests(context.Background(), runner, "/repo", tests, features)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.FeatureID+":"+acceptanceResult(r.Passed))
+	}
+	// Features without tests, and tests of unknown features, are skipped
+	if want := []string{"F1:PASS", "F2:FAIL", "F3:FAIL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if len(runner.ran) != 4 {
+		t.Errorf("ran %q, want the 4 selectors of known features", runner.ran)
+	}
+	if r := results[1].Results[1]; r.Passed || r.Reason != "exit status 1" || r.Output != "--- FAIL: refund e2e" {
+		t.Errorf("failing selector result = %+v", r)
+	}
+	if r := results[2].Results[0]; r.Reason != "no tests matched" {
+		t.Errorf("selector matching no tests = %+v, want it to fail", r)
+	}
+}
+
+func TestAssessFeatures_AcceptanceTests(t *testing.T) {
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1"}, Status: AuditStatusComplete, Confidence: 0.8},
+			{Feature: Feature{ID: "F2"}, Status: AuditStatusMissing, Confidence: 0.6},
+		},
+		Gaps: []Gap{{FeatureID: "F2", Status: AuditStatusMissing}},
+		AcceptanceTests: []FeatureTestResult{
+			{FeatureID: "F1", Passed: false, Results: []SelectorResult{{Command: "go test ./auth", Reason: "exit status 1"}}},
+			{FeatureID: "F2", Passed: true, Results: []SelectorResult{{Command: "go test ./billing", Passed: true}}},
+		},
+	}
+	assessFeatures(report, "")
+
+	// Failing tests outweigh the audit's COMPLETE, and the feature gets a gap
+	if report.Features[0].Status != AuditStatusPartial {
+		t.Errorf("F1 status = %s, want PARTIAL from its failing tests", report.Features[0].Status)
+	}
+	var gap *Gap
+	for i := range report.Gaps {
+		if report.Gaps[i].FeatureID == "F1" {
+			gap = &report.Gaps[i]
+		}
+	}
+	if gap == nil || !strings.Contains(gap.Description, "acceptance tests failed: go test ./auth (exit status 1)") {
+		t.Errorf("F1 gap = %+v, want the failing tests named", gap)
+	}
+
+	// Passing tests alone do not outweigh the audit and gap list
+	if report.Features[1].Status != AuditStatusMissing {
+		t.Errorf("F2 status = %s, want MISSING", report.Features[1].Status)
+	}
+	if len(report.Disagreements) != 2 {
+		t.Errorf("disagreements = %+v, want both features contested", report.Disagreements)
+	}
+}
+
+func TestWriteMarkdownReport_AcceptanceTests(t *testing.T) {
+	report := sampleGapReport()
+	report.AcceptanceTests = []FeatureTestResult{
+		{FeatureID: "F1", Passed: true, Results: []SelectorResult{{Command: "go test ./auth", Passed: true}}},
+		{FeatureID: "F2", Passed: false, Results: []SelectorResult{{Command: "make audit-test", Reason: "exit status 2"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	for _, want := range []string{
+		"## Acceptance Tests",
+		"| Login (F1) | PASS | `go test ./auth`: PASS |",
+		"| Audit <log> (F2) | FAIL | `make audit-test`: FAIL (exit status 2) |",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown report missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := WriteHTMLReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<code>make audit-test</code>: FAIL (exit status 2)") {
+		t.Errorf("html report missing the failing acceptance test:\n%s", buf.String())
+	}
+}
+
+// promptRecorder is a scriptedRunner that keeps the prompt it was given.
+type promptRecorder struct {
+	scriptedRunner
+	prompt string
+}
+
+func (r *promptRecorder) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	r.prompt = prompt
+	return r.scriptedRunner.StartWithOptions(prompt, workDir, opts)
+}
+
+func TestAudit_AcceptanceTestsInReview(t *testing.T) {
+	spec := &ArchSpec{Name: "spec", Features: []Feature{{ID: "F1", Name: "Login"}, {ID: "F2", Name: "Refunds"}}}
+	reply := `{"features": [{"feature_id": "F1", "status": "COMPLETE"}, {"feature_id": "F2", "status": "COMPLETE"}], "gaps": [], "summary": "done"}`
+	tests := FeatureTestMap{"F1": {{Command: "make login"}}, "F2": {{Command: "make refunds"}}}
+
+	for _, sequential := range []bool{false, true} {
+		runner := &fakeTestRunner{
+			outputs: map[string]string{"make refunds": "refund total mismatch"},
+			fail:    map[string]bool{"make refunds": true},
+		}
+		auditor := NewAuditor()
+		auditor.SetFeatureTests(tests)
+		auditor.testRunner = runner
+		auditor.SetSequential(sequential)
+		claude := &promptRecorder{scriptedRunner: scriptedRunner{reply: reply}}
+
+		report, err := auditor.Audit(context.Background(), spec, t.TempDir(), claude)
+		if err != nil {
+			t.Fatalf("Audit(sequential=%v) error = %v", sequential, err)
+		}
+		if len(report.AcceptanceTests) != 2 || report.AcceptanceTests[1].Passed {
+			t.Errorf("sequential=%v: acceptance tests = %+v, want F2 failing", sequential, report.AcceptanceTests)
+		}
+
+		// The review sees the test results unless the layers run in sequence
+		inPrompt := strings.Contains(claude.prompt, "- F2: FAILED (`make refunds`): exit status 1") &&
+			strings.Contains(claude.prompt, "refund total mismatch") &&
+			strings.Contains(claude.prompt, "- F1: passed (`make login`)")
+		if inPrompt == sequential {
+			t.Errorf("sequential=%v: acceptance results in the review prompt = %v", sequential, inPrompt)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package architect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Validation layers that give a verdict on a feature. Each layer looks at
+// the feature independently; their verdicts are combined by
+// AggregateFeatureStatus.
+const (
+	// LayerAudit is the auditor's status for the feature.
+	LayerAudit = "audit"
+	// LayerGapList is the auditor listing the feature among the gaps.
+	LayerGapList = "gap_list"
+	// LayerEvidence checks that the files cited as evidence exist.
+	LayerEvidence = "evidence"
+	// LayerAnchors checks that the evidence anchors of a claim exist.
+	LayerAnchors = "anchors"
+	// LayerStatic checks that the names a feature's spec requires are
+	// declared in the code (see SymbolIndex).
+	LayerStatic = "static"
+	// LayerAcceptance runs the feature's acceptance tests (see FeatureTestMap).
+	LayerAcceptance = "acceptance"
+)
+
+// Default layer confidences, used when a layer does not report its own.
+const (
+	defaultAuditConfidence    = 0.7
+	gapListConfidence         = 0.6
+	missingEvidenceConfidence = 0.5
+)
+
+// LayerVerdict is one validation layer's verdict on a feature.
+type LayerVerdict struct {
+	// Layer names the validation layer.
+	Layer string `json:"layer"`
+	// Status is the layer's verdict.
+	Status AuditStatus `json:"status"`
+	// Confidence is how sure the layer is, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+	// Reason explains the verdict.
+	Reason string `json:"reason,omitempty"`
+}
+
+// FeatureAssessment is a feature's status aggregated across layers.
+type FeatureAssessment struct {
+	// FeatureID is the assessed feature.
+	FeatureID string `json:"feature_id"`
+	// Status is the confidence-weighted status.
+	Status AuditStatus `json:"status"`
+	// Confidence is the share of the layers' total confidence behind Status.
+	Confidence float64 `json:"confidence"`
+	// Verdicts are the layer verdicts the status was aggregated from.
+	Verdicts []LayerVerdict `json:"verdicts"`
+	// Contested is true if the layers disagree on the status.
+	Contested bool `json:"contested"`
+}
+
+// statusSeverity orders statuses from best to worst, to break ties
+// conservatively.
+func statusSeverity(s AuditStatus) int {
+	switch s {
+	case AuditStatusComplete:
+		return 0
+	case AuditStatusPartial:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// AggregateFeatureStatus combines the layers' verdicts on a feature. Each
+// verdict votes for its status with its confidence; the status with the most
+// weight wins, and ties go to the worse status. A feature without verdicts
+// is MISSING.
+func AggregateFeatureStatus(featureID string, verdicts []LayerVerdict) FeatureAssessment {
+	fa := FeatureAssessment{FeatureID: featureID, Status: AuditStatusMissing, Verdicts: verdicts}
+
+	weights := make(map[AuditStatus]float64)
+	var total float64
+	for _, v := range verdicts {
+		if v.Confidence <= 0 {
+			continue
+		}
+		weights[v.Status] += v.Confidence
+		total += v.Confidence
+	}
+	if total == 0 {
+		return fa
+	}
+
+	best := -1.0
+	for status, w := range weights {
+		if w > best || (w == best && statusSeverity(status) > statusSeverity(fa.Status)) {
+			best = w
+			fa.Status = status
+		}
+	}
+	fa.Confidence = best / total
+	fa.Contested = len(weights) > 1
+	return fa
+}
+
+// assessFeatures aggregates the layer verdicts for every audited feature.
+// Features whose aggregated status differs from the audit are updated, and
+// get a gap if the layers find them incomplete. The report's disagreement
+// list and gap order put the most contested features first, so gap
+// analysis targets them before uncontested ones.
+func assessFeatures(report *GapReport, repoPath string) {
+	gapsByFeature := make(map[string][]Gap)
+	for _, gap := range report.Gaps {
+		gapsByFeature[gap.FeatureID] = append(gapsByFeature[gap.FeatureID], gap)
+	}
+
+	tests := featureTestResults(report.AcceptanceTests)
+	static := staticChecks(report.StaticChecks)
+
+	report.Assessments = nil
+	report.Disagreements = nil
+	for i := range report.Features {
+		fs := &report.Features[i]
+		id := fs.Feature.ID
+		if repoPath != "" {
+			validateAnchors(fs.Anchors, repoPath)
+		}
+		verdicts := featureVerdicts(*fs, gapsByFeature[id], repoPath)
+		if check, ok := static[id]; ok {
+			if v, ok := staticVerdict(*fs, check, auditConfidence(*fs)); ok {
+				verdicts = append(verdicts, v)
+			}
+		}
+		if result, ok := tests[id]; ok {
+			verdicts = append(verdicts, acceptanceVerdict(result))
+		}
+		fa := AggregateFeatureStatus(id, verdicts)
+		report.Assessments = append(report.Assessments, fa)
+
+		if fa.Status != fs.Status {
+			fs.Status = fa.Status
+			if fa.Status != AuditStatusComplete && len(gapsByFeature[id]) == 0 {
+				gap := Gap{
+					FeatureID:       id,
+					Status:          fa.Status,
+					Description:     dissentReason(fa),
+					SuggestedAction: fmt.Sprintf("Re-check %s against its criteria and finish what is incomplete", featureLabel(fs.Feature)),
+					Critical:        fs.Feature.Critical,
+					Severity:        gapSeverity("", fa.Status),
+				}
+				report.Gaps = append(report.Gaps, gap)
+				gapsByFeature[id] = []Gap{gap}
+			}
+		}
+		if fa.Contested {
+			report.Disagreements = append(report.Disagreements, fa)
+		}
+	}
+
+	sort.SliceStable(report.Disagreements, func(i, j int) bool {
+		return report.Disagreements[i].Confidence < report.Disagreements[j].Confidence
+	})
+
+	// Contested gaps first, most contested first
+	rank := make(map[string]int, len(report.Disagreements))
+	for i, d := range report.Disagreements {
+		rank[d.FeatureID] = i
+	}
+	sort.SliceStable(report.Gaps, func(i, j int) bool {
+		ri, oki := rank[report.Gaps[i].FeatureID]
+		rj, okj := rank[report.Gaps[j].FeatureID]
+		if oki != okj {
+			return oki
+		}
+		return oki && ri < rj
+	})
+}
+
+// featureVerdicts collects each layer's verdict on an audited feature.
+// Claims with evidence anchors are checked by their anchors, and COMPLETE
+// claims without them by the files their evidence text cites.
+func featureVerdicts(fs FeatureStatus, gaps []Gap, repoPath string) []LayerVerdict {
+	confidence := auditConfidence(fs)
+	verdicts := []LayerVerdict{{
+		Layer:      LayerAudit,
+		Status:     fs.Status,
+		Confidence: confidence,
+		Reason:     fs.Reasoning,
+	}}
+
+	for _, gap := range gaps {
+		verdicts = append(verdicts, LayerVerdict{
+			Layer:      LayerGapList,
+			Status:     gap.Status,
+			Confidence: gapListConfidence,
+			Reason:     gap.Description,
+		})
+	}
+
+	if len(fs.Anchors) > 0 && repoPath != "" && fs.Status != AuditStatusMissing {
+		if v, ok := anchorVerdict(fs, confidence); ok {
+			verdicts = append(verdicts, v)
+		}
+	} else if fs.Status == AuditStatusComplete {
+		if missing, cited := missingEvidenceFiles(fs.Evidence, repoPath); cited > 0 && len(missing) == cited {
+			verdicts = append(verdicts, LayerVerdict{
+				Layer:      LayerEvidence,
+				Status:     AuditStatusPartial,
+				Confidence: missingEvidenceConfidence,
+				Reason:     "none of the cited files exist: " + strings.Join(missing, ", "),
+			})
+		}
+	}
+	return verdicts
+}
+
+// auditConfidence returns the auditor's confidence in a feature's status,
+// or the default if it did not give a valid one.
+func auditConfidence(fs FeatureStatus) float64 {
+	if fs.Confidence <= 0 || fs.Confidence > 1 {
+		return defaultAuditConfidence
+	}
+	return fs.Confidence
+}
+
+// missingEvidenceFiles returns the files cited in evidence that do not
+// exist in the repository, and how many files were cited.
+func missingEvidenceFiles(evidence, repoPath string) ([]string, int) {
+	if repoPath == "" {
+		return nil, 0
+	}
+	files := evidenceFiles(evidence)
+	var missing []string
+	for _, f := range files {
+		path := f
+		if i := strings.Index(path, ":"); i >= 0 {
+			path = path[:i]
+		}
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(path))); err != nil {
+			missing = append(missing, f)
+		}
+	}
+	return missing, len(files)
+}
+
+// dissentReason summarizes the layers that disagree with the audit.
+func dissentReason(fa FeatureAssessment) string {
+	var reasons []string
+	for _, v := range fa.Verdicts {
+		if v.Layer != LayerAudit && v.Status == fa.Status && v.Reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", v.Layer, v.Reason))
+		}
+	}
+	if len(reasons) == 0 {
+		return fmt.Sprintf("Validation layers rate this feature %s", fa.Status)
+	}
+	return strings.Join(reasons, "; ")
+}
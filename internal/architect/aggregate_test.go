@@ -0,0 +1,149 @@
+package architect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAggregateFeatureStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		verdicts      []LayerVerdict
+		wantStatus    AuditStatus
+		wantContested bool
+	}{
+		{
+			name:       "no verdicts is missing",
+			wantStatus: AuditStatusMissing,
+		},
+		{
+			name: "agreement",
+			verdicts: []LayerVerdict{
+				{Layer: LayerAudit, Status: AuditStatusComplete, Confidence: 0.9},
+			},
+			wantStatus: AuditStatusComplete,
+		},
+		{
+			name: "confident audit outweighs one dissent",
+			verdicts: []LayerVerdict{
+				{Layer: LayerAudit, Status: AuditStatusComplete, Confidence: 0.9},
+				{Layer: LayerGapList, Status: AuditStatusPartial, Confidence: 0.6},
+			},
+			wantStatus:    AuditStatusComplete,
+			wantContested: true,
+		},
+		{
+			name: "dissenting layers outweigh the audit",
+			verdicts: []LayerVerdict{
+				{Layer: LayerAudit, Status: AuditStatusComplete, Confidence: 0.7},
+				{Layer: LayerGapList, Status: AuditStatusPartial, Confidence: 0.6},
+				{Layer: LayerEvidence, Status: AuditStatusPartial, Confidence: 0.5},
+			},
+			wantStatus:    AuditStatusPartial,
+			wantContested: true,
+		},
+		{
+			name: "tie goes to the worse status",
+			verdicts: []LayerVerdict{
+				{Layer: LayerAudit, Status: AuditStatusComplete, Confidence: 0.6},
+				{Layer: LayerGapList, Status: AuditStatusMissing, Confidence: 0.6},
+			},
+			wantStatus:    AuditStatusMissing,
+			wantContested: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := AggregateFeatureStatus("F1", tt.verdicts)
+			if fa.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", fa.Status, tt.wantStatus)
+			}
+			if fa.Contested != tt.wantContested {
+				t.Errorf("Contested = %v, want %v", fa.Contested, tt.wantContested)
+			}
+		})
+	}
+}
+
+func TestAssessFeatures_DowngradesAndOrdersContestedFirst(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "internal", "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "internal", "auth", "login.go"), []byte("package auth"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1", Name: "Search"}, Status: AuditStatusMissing},
+			// Claimed complete, but the evidence cites a file that does not exist
+			// and the auditor also lists it as a gap.
+			{Feature: Feature{ID: "F2", Name: "Export"}, Status: AuditStatusComplete, Evidence: "See internal/export/csv.go:12"},
+			// Claimed complete with real evidence, contested by the gap list only.
+			{Feature: Feature{ID: "F3", Name: "Login"}, Status: AuditStatusComplete, Confidence: 0.95, Evidence: "internal/auth/login.go"},
+		},
+		Gaps: []Gap{
+			{FeatureID: "F1", Status: AuditStatusMissing, Description: "No search"},
+			{FeatureID: "F3", Status: AuditStatusPartial, Description: "No lockout"},
+			{FeatureID: "F2", Status: AuditStatusPartial, Description: "Only CSV"},
+		},
+	}
+
+	assessFeatures(report, repo)
+
+	if report.Features[1].Status != AuditStatusPartial {
+		t.Errorf("F2 status = %s, want PARTIAL after dissenting layers", report.Features[1].Status)
+	}
+	if report.Features[2].Status != AuditStatusComplete {
+		t.Errorf("F3 status = %s, want COMPLETE", report.Features[2].Status)
+	}
+	if len(report.Assessments) != 3 {
+		t.Errorf("got %d assessments, want 3", len(report.Assessments))
+	}
+
+	var contested []string
+	for _, d := range report.Disagreements {
+		contested = append(contested, d.FeatureID)
+	}
+	// F2 (2 of 3 layers against the audit) is less settled than F3
+	if strings.Join(contested, ",") != "F3,F2" && strings.Join(contested, ",") != "F2,F3" {
+		t.Fatalf("disagreements = %v, want F2 and F3", contested)
+	}
+	for i := 1; i < len(report.Disagreements); i++ {
+		if report.Disagreements[i-1].Confidence > report.Disagreements[i].Confidence {
+			t.Errorf("disagreements not ordered most contested first: %+v", report.Disagreements)
+		}
+	}
+
+	var order []string
+	for _, g := range report.Gaps {
+		order = append(order, g.FeatureID)
+	}
+	if order[len(order)-1] != "F1" {
+		t.Errorf("gap order = %v, want uncontested F1 last", order)
+	}
+}
+
+func TestAssessFeatures_AddsGapForDowngradedFeature(t *testing.T) {
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1", Name: "Export"}, Status: AuditStatusComplete, Confidence: 0.4, Evidence: "cmd/export/main.go"},
+		},
+	}
+
+	assessFeatures(report, t.TempDir())
+
+	if report.Features[0].Status != AuditStatusPartial {
+		t.Fatalf("status = %s, want PARTIAL", report.Features[0].Status)
+	}
+	if len(report.Gaps) != 1 || report.Gaps[0].FeatureID != "F1" {
+		t.Fatalf("gaps = %+v, want one gap for F1", report.Gaps)
+	}
+	if !strings.Contains(report.Gaps[0].Description, "cmd/export/main.go") {
+		t.Errorf("gap description = %q, want the dissenting reason", report.Gaps[0].Description)
+	}
+}
@@ -0,0 +1,157 @@
+package architect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// anchorLineSlack is how many lines outside an anchor's range its symbol
+// may be found at; reported line numbers often drift by a few lines.
+const anchorLineSlack = 10
+
+// EvidenceAnchor points at the code supporting an audit claim.
+type EvidenceAnchor struct {
+	// File is the path relative to the repository root.
+	File string `json:"file"`
+	// Symbol is the function, type or method implementing the claim.
+	Symbol string `json:"symbol,omitempty"`
+	// StartLine and EndLine are the 1-based line range. 0 means the whole file.
+	StartLine int `json:"start_line,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+	// Problem explains why the anchor does not check out. Empty if it does
+	// or was not checked.
+	Problem string `json:"problem,omitempty"`
+}
+
+// Location returns the anchor as file:start-end.
+func (a EvidenceAnchor) Location() string {
+	switch {
+	case a.StartLine <= 0:
+		return a.File
+	case a.EndLine <= a.StartLine:
+		return fmt.Sprintf("%s:%d", a.File, a.StartLine)
+	default:
+		return fmt.Sprintf("%s:%d-%d", a.File, a.StartLine, a.EndLine)
+	}
+}
+
+// String returns the anchor's location and symbol.
+func (a EvidenceAnchor) String() string {
+	if a.Symbol == "" {
+		return a.Location()
+	}
+	return fmt.Sprintf("%s (%s)", a.Location(), a.Symbol)
+}
+
+// validateAnchors checks every anchor against the repository, recording
+// what is wrong with it in its Problem.
+func validateAnchors(anchors []EvidenceAnchor, repoPath string) {
+	for i := range anchors {
+		anchors[i].Problem = anchorProblem(anchors[i], repoPath)
+	}
+}
+
+// anchorProblem explains why an anchor does not exist in the repository:
+// its file is missing, its lines are past the end of the file, or its
+// symbol is not near them. It returns "" for a valid anchor.
+func anchorProblem(a EvidenceAnchor, repoPath string) string {
+	file := filepath.Clean(filepath.FromSlash(strings.TrimSpace(a.File)))
+	if a.File == "" {
+		return "no file given"
+	}
+	if filepath.IsAbs(file) || file == ".." || strings.HasPrefix(file, ".."+string(filepath.Separator)) {
+		return "file is outside the repository"
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoPath, file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "file not found"
+		}
+		return fmt.Sprintf("file cannot be read: %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	start, end := a.StartLine, a.EndLine
+	if start > 0 {
+		if end <= 0 {
+			end = start
+		}
+		if end < start {
+			return fmt.Sprintf("invalid line range %d-%d", start, end)
+		}
+		if end > len(lines) {
+			return fmt.Sprintf("lines %d-%d are past the end of the file (%d lines)", start, end, len(lines))
+		}
+	}
+
+	if a.Symbol == "" {
+		return ""
+	}
+	symbol := symbolPattern(a.Symbol)
+	if symbol == nil {
+		return ""
+	}
+	if start <= 0 {
+		if !symbol.Match(data) {
+			return fmt.Sprintf("symbol %s not found in the file", a.Symbol)
+		}
+		return ""
+	}
+	from, to := max(start-anchorLineSlack, 1), min(end+anchorLineSlack, len(lines))
+	if !symbol.MatchString(strings.Join(lines[from-1:to], "\n")) {
+		return fmt.Sprintf("symbol %s not found at lines %d-%d", a.Symbol, start, end)
+	}
+	return ""
+}
+
+// symbolPattern matches the last identifier of a symbol as a whole word,
+// so "Auditor.Audit" and "(*Auditor).Audit" both look for Audit. It returns
+// nil if the symbol has no identifier.
+func symbolPattern(symbol string) *regexp.Regexp {
+	fields := strings.FieldsFunc(symbol, func(r rune) bool {
+		return !(r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(^|[^\w$])` + regexp.QuoteMeta(fields[len(fields)-1]) + `($|[^\w$])`)
+}
+
+// downgradeStatus returns the next worse status: COMPLETE becomes PARTIAL
+// and anything else MISSING.
+func downgradeStatus(s AuditStatus) AuditStatus {
+	if s == AuditStatusComplete {
+		return AuditStatusPartial
+	}
+	return AuditStatusMissing
+}
+
+// anchorVerdict is the anchor layer's verdict on a COMPLETE or PARTIAL
+// claim whose anchors were validated. A claim none of whose anchors check
+// out is downgraded with the audit's own confidence, so it only stands if
+// another layer supports it; a claim with some bad anchors is contested.
+// It returns false if every anchor checks out.
+func anchorVerdict(fs FeatureStatus, auditConfidence float64) (LayerVerdict, bool) {
+	var bad []string
+	for _, a := range fs.Anchors {
+		if a.Problem != "" {
+			bad = append(bad, fmt.Sprintf("%s: %s", a, a.Problem))
+		}
+	}
+	if len(bad) == 0 {
+		return LayerVerdict{}, false
+	}
+
+	v := LayerVerdict{Layer: LayerAnchors, Status: downgradeStatus(fs.Status), Confidence: missingEvidenceConfidence}
+	if len(bad) == len(fs.Anchors) {
+		v.Confidence = auditConfidence
+		v.Reason = "no evidence anchor checks out: " + strings.Join(bad, "; ")
+	} else {
+		v.Reason = fmt.Sprintf("%d of %d evidence anchors do not check out: %s", len(bad), len(fs.Anchors), strings.Join(bad, "; "))
+	}
+	return v, true
+}
@@ -0,0 +1,143 @@
+package architect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// anchorRepo creates a repository with internal/auth/login.go.
+func anchorRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "internal", "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package auth\n\n// Login checks a password.\nfunc (s *Service) Login(user, password string) error {\n\treturn nil\n}\n"
+	if err := os.WriteFile(filepath.Join(repo, "internal", "auth", "login.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestAnchorProblem(t *testing.T) {
+	repo := anchorRepo(t)
+	tests := []struct {
+		name   string
+		anchor EvidenceAnchor
+		want   string
+	}{
+		{"whole file", EvidenceAnchor{File: "internal/auth/login.go"}, ""},
+		{"symbol in range", EvidenceAnchor{File: "internal/auth/login.go", Symbol: "Service.Login", StartLine: 4, EndLine: 6}, ""},
+		{"symbol near range", EvidenceAnchor{File: "internal/auth/login.go", Symbol: "(*Service).Login", StartLine: 1}, ""},
+		{"symbol in file", EvidenceAnchor{File: "internal/auth/login.go", Symbol: "Login"}, ""},
+		{"missing file", EvidenceAnchor{File: "internal/auth/logout.go"}, "file not found"},
+		{"outside repo", EvidenceAnchor{File: "../secrets.go"}, "file is outside the repository"},
+		{"past end", EvidenceAnchor{File: "internal/auth/login.go", StartLine: 40, EndLine: 60}, "lines 40-60 are past the end of the file (7 lines)"},
+		{"reversed range", EvidenceAnchor{File: "internal/auth/login.go", StartLine: 5, EndLine: 2}, "invalid line range 5-2"},
+		{"unknown symbol", EvidenceAnchor{File: "internal/auth/login.go", Symbol: "Logout"}, "symbol Logout not found in the file"},
+		{"partial word", EvidenceAnchor{File: "internal/auth/login.go", Symbol: "Log", StartLine: 4}, "symbol Log not found at lines 4-4"},
+	}
+	for _, tt := range tests {
+		if got := anchorProblem(tt.anchor, repo); got != tt.want {
+			t.Errorf("%s: anchorProblem() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseAuditResponse_Anchors(t *testing.T) {
+	response := `{
+		"features": [{
+			"feature_id": "F1",
+			"status": "COMPLETE",
+			"anchors": [
+				{"file": "internal/auth/login.go", "symbol": "Login", "start_line": 4, "end_line": 6, "problem": ""},
+				{}
+			]
+		}]
+	}`
+	report, err := NewAuditor().parseAuditResponse(response, []Feature{{ID: "F1", Name: "Login"}})
+	if err != nil {
+		t.Fatalf("parseAuditResponse() error = %v", err)
+	}
+	anchors := report.Features[0].Anchors
+	if len(anchors) != 1 || anchors[0].String() != "internal/auth/login.go:4-6 (Login)" {
+		t.Errorf("anchors = %+v, want the one non-empty anchor", anchors)
+	}
+}
+
+func TestAssessFeatures_EvidenceAnchors(t *testing.T) {
+	repo := anchorRepo(t)
+	report := &GapReport{
+		Features: []FeatureStatus{
+			// Every anchor is made up: downgraded, with a gap naming them
+			{Feature: Feature{ID: "F1", Name: "Export"}, Status: AuditStatusComplete, Confidence: 0.9, Anchors: []EvidenceAnchor{
+				{File: "internal/export/csv.go", Symbol: "WriteCSV"},
+				{File: "internal/auth/login.go", Symbol: "Export"},
+			}},
+			// One good anchor: contested but kept
+			{Feature: Feature{ID: "F2", Name: "Login"}, Status: AuditStatusComplete, Confidence: 0.9, Anchors: []EvidenceAnchor{
+				{File: "internal/auth/login.go", Symbol: "Login", StartLine: 4, EndLine: 6},
+				{File: "internal/auth/lockout.go"},
+			}},
+			// A PARTIAL claim without real code is MISSING
+			{Feature: Feature{ID: "F3", Name: "Search"}, Status: AuditStatusPartial, Anchors: []EvidenceAnchor{{File: "search.go"}}},
+			// Valid anchors agree with the audit
+			{Feature: Feature{ID: "F4", Name: "Auth"}, Status: AuditStatusComplete, Anchors: []EvidenceAnchor{{File: "internal/auth/login.go"}}},
+		},
+	}
+	assessFeatures(report, repo)
+
+	want := []AuditStatus{AuditStatusPartial, AuditStatusComplete, AuditStatusMissing, AuditStatusComplete}
+	for i, fs := range report.Features {
+		if fs.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", fs.Feature.ID, fs.Status, want[i])
+		}
+	}
+	if p := report.Features[0].Anchors[0].Problem; p != "file not found" {
+		t.Errorf("F1 anchor problem = %q, want it recorded on the anchor", p)
+	}
+	if len(report.Gaps) != 2 || report.Gaps[0].FeatureID != "F1" && report.Gaps[1].FeatureID != "F1" {
+		t.Fatalf("gaps = %+v, want gaps for F1 and F3", report.Gaps)
+	}
+	for _, gap := range report.Gaps {
+		if gap.FeatureID == "F1" && !strings.Contains(gap.Description, "internal/export/csv.go (WriteCSV): file not found") {
+			t.Errorf("F1 gap = %q, want the bad anchors named", gap.Description)
+		}
+	}
+	if len(report.Disagreements) != 3 {
+		t.Errorf("disagreements = %+v, want F1, F2 and F3 contested", report.Disagreements)
+	}
+}
+
+func TestWriteMarkdownReport_Anchors(t *testing.T) {
+	report := sampleGapReport()
+	report.Features[0].Anchors = []EvidenceAnchor{
+		{File: "internal/auth/login.go", Symbol: "Login", StartLine: 4, EndLine: 6},
+		{File: "internal/auth/lockout.go", Problem: "file not found"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	for _, want := range []string{
+		"**Anchors:**",
+		"- `internal/auth/login.go:4-6` `Login`\n",
+		"- `internal/auth/lockout.go` — does not check out: file not found",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown report missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := WriteHTMLReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<code>internal/auth/lockout.go</code> — does not check out: file not found") {
+		t.Errorf("html report missing the bad anchor:\n%s", buf.String())
+	}
+}
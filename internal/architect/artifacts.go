@@ -0,0 +1,114 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// verifyArtifactsDir is where each audit writes its raw artifacts, in a
+// directory per run, relative to the repository.
+const verifyArtifactsDir = ".alphie/verify"
+
+// Artifact file names written into an audit's run directory.
+const (
+	auditArtifactFile      = "audit.json"
+	layersArtifactFile     = "layers.json"
+	transcriptArtifactFile = "transcript.md"
+	gapsArtifactFile       = "gaps.json"
+)
+
+// VerificationArtifacts locates the raw artifacts an audit wrote, so a
+// verification can be debugged after the process exits. Paths are empty
+// for artifacts that were not written.
+type VerificationArtifacts struct {
+	// Dir is the audit's run directory, .alphie/verify/<run-id>.
+	Dir string `json:"dir"`
+	// Audit is the gap report as JSON.
+	Audit string `json:"audit,omitempty"`
+	// Layers holds each feature's verdict from every validation layer.
+	Layers string `json:"layers,omitempty"`
+	// Transcript is the semantic review: the prompt sent to Claude, its raw
+	// response and the error that ended the audit, if any.
+	Transcript string `json:"transcript,omitempty"`
+	// Gaps is the gap list as JSON.
+	Gaps string `json:"gaps,omitempty"`
+}
+
+// newVerificationArtifacts creates a timestamped run directory under the
+// repository's .alphie/verify. Returns nil if it cannot be created; the
+// audit runs without artifacts rather than failing.
+func newVerificationArtifacts(repoPath string, now time.Time) *VerificationArtifacts {
+	runID := fmt.Sprintf("%s-%s", now.Format("20060102-150405"), uuid.New().String()[:6])
+	dir := filepath.Join(repoPath, verifyArtifactsDir, runID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil
+	}
+	return &VerificationArtifacts{Dir: dir}
+}
+
+// writeTranscript writes the audit prompt, Claude's raw response and the
+// error that ended the audit, if any.
+func (va *VerificationArtifacts) writeTranscript(prompt, response string, auditErr error) {
+	if va == nil {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("# Audit transcript\n\n## Prompt\n\n")
+	sb.WriteString(prompt)
+	sb.WriteString("\n\n## Response\n\n")
+	sb.WriteString(response)
+	sb.WriteString("\n")
+	if auditErr != nil {
+		fmt.Fprintf(&sb, "\n## Error\n\n%v\n", auditErr)
+	}
+	va.Transcript = va.write(transcriptArtifactFile, []byte(sb.String()))
+}
+
+// writeReport writes the gap report, its per-layer verdicts and gap list,
+// and records the artifact paths on the report.
+func (va *VerificationArtifacts) writeReport(report *GapReport) {
+	if va == nil {
+		return
+	}
+	va.Layers = va.writeJSON(layersArtifactFile, report.Assessments)
+	va.Gaps = va.writeJSON(gapsArtifactFile, report.Gaps)
+	// The audit file is written last so it references the others
+	va.Audit = filepath.Join(va.Dir, auditArtifactFile)
+	report.Artifacts = va
+	if va.writeJSON(auditArtifactFile, report) == "" {
+		va.Audit = ""
+	}
+}
+
+// wrapError points an audit error at the run's artifacts.
+func (va *VerificationArtifacts) wrapError(err error) error {
+	if va == nil || va.Transcript == "" {
+		return err
+	}
+	return fmt.Errorf("%w (transcript: %s)", err, va.Transcript)
+}
+
+// writeJSON writes v as indented JSON, returning its path or "" on failure.
+func (va *VerificationArtifacts) writeJSON(name string, v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return va.write(name, append(data, '\n'))
+}
+
+// write writes an artifact file, returning its path or "" on failure.
+func (va *VerificationArtifacts) write(name string, data []byte) string {
+	path := filepath.Join(va.Dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return ""
+	}
+	return path
+}
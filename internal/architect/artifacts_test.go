@@ -0,0 +1,83 @@
+package architect
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditWritesVerificationArtifacts(t *testing.T) {
+	repo := t.TempDir()
+	spec := &ArchSpec{Name: "spec", Features: []Feature{
+		{ID: "f1", Name: "Login"},
+		{ID: "f2", Name: "Logout"},
+	}}
+	runner := &scriptedRunner{reply: "```json\n" + `{
+  "features": [
+    {"feature_id": "f1", "status": "COMPLETE", "evidence": "Found in main.go"},
+    {"feature_id": "f2", "status": "MISSING", "evidence": ""}
+  ],
+  "gaps": [{"feature_id": "f2", "status": "MISSING", "description": "No logout", "suggested_action": "Add it"}],
+  "summary": "One of two"
+}` + "\n```"}
+
+	report, err := NewAuditor().Audit(context.Background(), spec, repo, runner)
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+	a := report.Artifacts
+	if a == nil {
+		t.Fatal("expected the report to reference its artifacts")
+	}
+	if rel, _ := filepath.Rel(repo, a.Dir); filepath.Dir(rel) != filepath.FromSlash(verifyArtifactsDir) {
+		t.Errorf("artifacts dir = %s, want a run directory under %s", a.Dir, verifyArtifactsDir)
+	}
+
+	var saved GapReport
+	data, err := os.ReadFile(a.Audit)
+	if err != nil {
+		t.Fatalf("read audit artifact: %v", err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("parse audit artifact: %v", err)
+	}
+	if len(saved.Gaps) != 1 || saved.Artifacts == nil || saved.Artifacts.Gaps != a.Gaps {
+		t.Errorf("unexpected audit artifact: %s", data)
+	}
+
+	var layers []FeatureAssessment
+	data, _ = os.ReadFile(a.Layers)
+	if err := json.Unmarshal(data, &layers); err != nil || len(layers) != 2 || len(layers[0].Verdicts) == 0 {
+		t.Errorf("layers artifact should hold every feature's layer verdicts, got %s (%v)", data, err)
+	}
+
+	transcript, _ := os.ReadFile(a.Transcript)
+	if !strings.Contains(string(transcript), "## Prompt") || !strings.Contains(string(transcript), "No logout") {
+		t.Errorf("transcript should hold the prompt and raw response, got:\n%s", transcript)
+	}
+}
+
+func TestAuditFailureKeepsTranscript(t *testing.T) {
+	repo := t.TempDir()
+	spec := &ArchSpec{Features: []Feature{{ID: "f1", Name: "Login"}}}
+	runner := &scriptedRunner{reply: "I could not decide."}
+
+	_, err := NewAuditor().Audit(context.Background(), spec, repo, runner)
+	if err == nil {
+		t.Fatal("expected an unparseable audit to fail")
+	}
+	matches, _ := filepath.Glob(filepath.Join(repo, verifyArtifactsDir, "*", transcriptArtifactFile))
+	if len(matches) != 1 {
+		t.Fatalf("expected one transcript, found %v", matches)
+	}
+	if !strings.Contains(err.Error(), matches[0]) {
+		t.Errorf("error should name the transcript %s, got %v", matches[0], err)
+	}
+	transcript, _ := os.ReadFile(matches[0])
+	if !strings.Contains(string(transcript), "I could not decide.") || !strings.Contains(string(transcript), "## Error") {
+		t.Errorf("transcript should hold the response and error, got:\n%s", transcript)
+	}
+}
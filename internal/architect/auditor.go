@@ -8,9 +8,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 )
 
 // AuditStatus represents the implementation status of a feature.
@@ -35,6 +39,20 @@ type Feature struct {
 	Description string `json:"description"`
 	// Criteria defines what constitutes full implementation.
 	Criteria string `json:"criteria,omitempty"`
+	// Source is the spec file defining the feature (multi-file specs only).
+	Source string `json:"source,omitempty"`
+	// Critical is true if the spec marks the feature as critical or must-have.
+	// Gaps in critical features are fixed first.
+	Critical bool `json:"critical,omitempty"`
+	// Deferred is the spec's marker for a feature out of the current scope,
+	// such as "optional", "deferred" or "phase-2". Deferred features are
+	// audited and reported, but not planned or required for completion.
+	Deferred string `json:"deferred,omitempty"`
+}
+
+// IsDeferred reports whether the spec defers the feature.
+func (f Feature) IsDeferred() bool {
+	return f.Deferred != ""
 }
 
 // FeatureStatus represents the status of a single feature after audit.
@@ -45,8 +63,13 @@ type FeatureStatus struct {
 	Status AuditStatus `json:"status"`
 	// Evidence contains file references and code snippets supporting the assessment.
 	Evidence string `json:"evidence"`
+	// Anchors point at the code supporting a COMPLETE or PARTIAL status.
+	Anchors []EvidenceAnchor `json:"anchors,omitempty"`
 	// Reasoning explains the rationale for the status determination.
 	Reasoning string `json:"reasoning"`
+	// Confidence is how sure the auditor is of Status, from 0 to 1.
+	// 0 means the auditor did not say.
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
 // Gap represents a feature that needs work.
@@ -59,6 +82,10 @@ type Gap struct {
 	Description string `json:"description"`
 	// SuggestedAction provides guidance on how to address the gap.
 	SuggestedAction string `json:"suggested_action"`
+	// Critical is true if the gap is in a critical feature.
+	Critical bool `json:"critical,omitempty"`
+	// Severity is how much of the feature the gap leaves unfinished.
+	Severity GapSeverity `json:"severity,omitempty"`
 }
 
 // GapReport contains the full audit results.
@@ -67,8 +94,30 @@ type GapReport struct {
 	Features []FeatureStatus `json:"features"`
 	// Gaps lists features that need work.
 	Gaps []Gap `json:"gaps"`
+	// Deferred lists the gaps in features the spec defers. They are
+	// reported but not planned, and do not count against completion.
+	Deferred []Gap `json:"deferred,omitempty"`
 	// Summary provides an overall assessment.
 	Summary string `json:"summary"`
+	// Assessments holds each feature's status aggregated across validation layers.
+	Assessments []FeatureAssessment `json:"assessments,omitempty"`
+	// Disagreements lists the features the layers disagree on, most
+	// contested first.
+	Disagreements []FeatureAssessment `json:"disagreements,omitempty"`
+	// Score is the completion of the in-scope features, strict and
+	// weighted by gap severity.
+	Score *AuditScore `json:"score,omitempty"`
+	// AcceptanceTests are the results of the features' acceptance tests,
+	// if the auditor was given a FeatureTestMap.
+	AcceptanceTests []FeatureTestResult `json:"acceptance_tests,omitempty"`
+	// StaticChecks cross-check the names each feature's spec requires
+	// against a symbol index of the code.
+	StaticChecks []StaticCheck `json:"static_checks,omitempty"`
+	// Rubric holds the auditor's verdict on each review rubric criterion,
+	// in rubric order, if the auditor was given a rubric.
+	Rubric []orchestrator.CriterionVerdict `json:"rubric,omitempty"`
+	// Artifacts locates the raw artifacts the audit wrote, if any.
+	Artifacts *VerificationArtifacts `json:"artifacts,omitempty"`
 }
 
 // ArchSpec represents an architecture specification.
@@ -79,10 +128,41 @@ type ArchSpec struct {
 	Features []Feature `json:"features"`
 }
 
+// InScope returns the features the spec does not defer.
+func (s *ArchSpec) InScope() []Feature {
+	features := make([]Feature, 0, len(s.Features))
+	for _, f := range s.Features {
+		if !f.IsDeferred() {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// IncludeDeferred clears the deferral markers so every feature is in scope.
+func (s *ArchSpec) IncludeDeferred() {
+	for i := range s.Features {
+		s.Features[i].Deferred = ""
+	}
+}
+
 // Auditor compares architecture specifications against actual code.
 type Auditor struct {
 	// maxFilesToScan limits the number of files sent to Claude for context.
 	maxFilesToScan int
+	// baseline lists what already failed before implementation started.
+	baseline *agent.Baseline
+	// promptCache keeps Claude's responses across runs.
+	promptCache *PromptCache
+	// featureTests selects the acceptance tests of features, and
+	// testRunner runs them.
+	featureTests FeatureTestMap
+	testRunner   exec.CommandRunner
+	// sequential runs the acceptance tests after the semantic review
+	// instead of next to the code layers and before it.
+	sequential bool
+	// rubric lists the criteria the review gives a verdict on.
+	rubric []policy.RubricCriterion
 }
 
 // NewAuditor creates a new Auditor instance.
@@ -92,6 +172,43 @@ func NewAuditor() *Auditor {
 	}
 }
 
+// SetBaseline tells the audit what already failed before implementation
+// started, so pre-existing failures are not reported as gaps.
+func (a *Auditor) SetBaseline(baseline *agent.Baseline) {
+	a.baseline = baseline
+}
+
+// SetFeatureTests runs each feature's acceptance tests in every audit, so
+// features get a pass/fail from concrete tests next to the semantic review.
+// Nil disables them.
+func (a *Auditor) SetFeatureTests(tests FeatureTestMap) {
+	a.featureTests = tests
+	if a.testRunner == nil {
+		a.testRunner = exec.NewRunner()
+	}
+}
+
+// SetSequential runs the validation layers one after another: the code
+// context and static cross-check, the semantic review, then the acceptance
+// tests, whose results the review does not see. By default the acceptance
+// tests run concurrently with the code layers and the review is given both.
+func (a *Auditor) SetSequential(sequential bool) {
+	a.sequential = sequential
+}
+
+// SetRubric has the semantic review give a structured verdict on each
+// rubric criterion for the implemented code, next to the feature statuses.
+// Empty disables it.
+func (a *Auditor) SetRubric(rubric []policy.RubricCriterion) {
+	a.rubric = rubric
+}
+
+// SetPromptCache answers audits of an unchanged spec and codebase from
+// cache's earlier responses instead of calling Claude. Nil disables it.
+func (a *Auditor) SetPromptCache(cache *PromptCache) {
+	a.promptCache = cache
+}
+
 // Audit compares parsed features against the codebase and returns a gap report.
 // It uses Claude to analyze each feature's implementation status.
 func (a *Auditor) Audit(ctx context.Context, spec *ArchSpec, repoPath string, claude agent.ClaudeRunner) (*GapReport, error) {
@@ -103,22 +220,106 @@ func (a *Auditor) Audit(ctx context.Context, spec *ArchSpec, repoPath string, cl
 		}, nil
 	}
 
-	// Gather context from the codebase
-	codeContext, err := a.gatherCodeContext(repoPath)
+	// The acceptance tests do not depend on the code layers, so they run
+	// alongside them and the semantic review weighs both
+	var tests []FeatureTestResult
+	runTests := func() {
+		if len(a.featureTests) > 0 {
+			tests = RunFeatureTests(ctx, a.testRunner, repoPath, a.featureTests, spec.Features)
+		}
+	}
+	var wg sync.WaitGroup
+	if !a.sequential {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runTests()
+		}()
+	}
+	codeContext, checks, err := a.codeLayers(spec, repoPath)
+	wg.Wait()
 	if err != nil {
-		return nil, fmt.Errorf("gather code context: %w", err)
+		return nil, err
 	}
 
 	// Build the audit prompt
-	prompt := a.buildAuditPrompt(spec, codeContext)
+	prompt := a.buildAuditPrompt(spec, codeContext, tests)
+	artifacts := newVerificationArtifacts(repoPath, time.Now())
+
+	// Claude reads the repository, so a cached response is only valid for
+	// the exact state it was audited in
+	var key, response string
+	cached := false
+	fingerprint, cacheable := repoFingerprint(repoPath)
+	if cacheable {
+		key, response, cached = a.promptCache.lookup(PromptKindAudit, prompt, fingerprint)
+	}
+	if !cached {
+		response, err = a.runAudit(prompt, repoPath, claude)
+	}
+	var report *GapReport
+	if err == nil {
+		// Parse the response
+		if report, err = a.parseAuditResponse(response, spec.Features); err != nil {
+			err = fmt.Errorf("parse audit response: %w", err)
+		} else if !cached && cacheable {
+			a.promptCache.save(PromptKindAudit, promptCacheScope(repoPath), key, response)
+		}
+	}
+	artifacts.writeTranscript(prompt, response, err)
+	if err != nil {
+		return nil, artifacts.wrapError(err)
+	}
 
+	// Weigh the audit against the other validation layers
+	if a.sequential {
+		runTests()
+	}
+	report.AcceptanceTests = tests
+	report.StaticChecks = checks
+	assessFeatures(report, repoPath)
+	deferGaps(report, spec.Features)
+	score := ScoreReport(report)
+	report.Score = &score
+	artifacts.writeReport(report)
+
+	// Debug logging removed - interferes with TUI
+	// Audit results are sent to TUI via progress callbacks
+
+	return report, nil
+}
+
+// codeLayers gathers the code context of the semantic review and
+// cross-checks the names the spec requires, so the auditor is told what the
+// code declares.
+func (a *Auditor) codeLayers(spec *ArchSpec, repoPath string) (string, []StaticCheck, error) {
+	codeContext, err := a.gatherCodeContext(repoPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("gather code context: %w", err)
+	}
+
+	var checks []StaticCheck
+	if idx, err := BuildSymbolIndex(repoPath); err == nil && idx.Files > 0 {
+		checks = idx.CrossCheck(spec.Features)
+		var sb strings.Builder
+		sb.WriteString(codeContext)
+		sb.WriteString("\n")
+		writeStaticSection(&sb, idx, checks)
+		codeContext = sb.String()
+	}
+	return codeContext, checks, nil
+}
+
+// runAudit sends the audit prompt to Claude and returns its raw response.
+// On error the response received so far is returned with it.
+func (a *Auditor) runAudit(prompt, repoPath string, claude agent.ClaudeRunner) (string, error) {
 	// Start Claude process with temperature=0 for deterministic auditing
 	temp := 0.0
 	opts := &agent.StartOptions{
 		Temperature: &temp,
 	}
 	if err := claude.StartWithOptions(prompt, repoPath, opts); err != nil {
-		return nil, fmt.Errorf("start claude process: %w", err)
+		return "", fmt.Errorf("start claude process: %w", err)
 	}
 
 	// Collect output
@@ -131,34 +332,16 @@ func (a *Auditor) Audit(ctx context.Context, spec *ArchSpec, repoPath string, cl
 			}
 		case agent.StreamEventError:
 			if event.Error != "" {
-				// Debug: Write error to file
-				if debugFile, ferr := os.OpenFile("/tmp/alphie-audit-error.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); ferr == nil {
-					fmt.Fprintf(debugFile, "[%s] Audit Error:\n", time.Now().Format(time.RFC3339))
-					fmt.Fprintf(debugFile, "  Error: %s\n", event.Error)
-					fmt.Fprintf(debugFile, "  ToolAction: %s\n", event.ToolAction)
-					fmt.Fprintf(debugFile, "\n")
-					debugFile.Close()
-				}
-				return nil, fmt.Errorf("claude error: %s", event.Error)
+				return outputBuilder.String(), fmt.Errorf("claude error: %s", event.Error)
 			}
 		}
 	}
 
 	// Wait for process completion
 	if err := claude.Wait(); err != nil {
-		return nil, fmt.Errorf("claude process failed: %w", err)
+		return outputBuilder.String(), fmt.Errorf("claude process failed: %w", err)
 	}
-
-	// Parse the response
-	report, err := a.parseAuditResponse(outputBuilder.String(), spec.Features)
-	if err != nil {
-		return nil, fmt.Errorf("parse audit response: %w", err)
-	}
-
-	// Debug logging removed - interferes with TUI
-	// Audit results are sent to TUI via progress callbacks
-
-	return report, nil
+	return outputBuilder.String(), nil
 }
 
 // gatherCodeContext scans the repository and gathers relevant file information.
@@ -234,7 +417,7 @@ func isCodeFile(ext string) bool {
 }
 
 // buildAuditPrompt constructs the prompt for Claude to audit features.
-func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string) string {
+func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string, tests []FeatureTestResult) string {
 	var sb strings.Builder
 
 	sb.WriteString("You are auditing a codebase against an architecture specification.\n\n")
@@ -251,20 +434,38 @@ func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string) string {
 		if f.Criteria != "" {
 			sb.WriteString(fmt.Sprintf("Criteria: %s\n", f.Criteria))
 		}
+		if f.IsDeferred() {
+			sb.WriteString(fmt.Sprintf("Scope: deferred (%s) - audit it like any other feature; it will not be worked on yet\n", f.Deferred))
+		}
 		sb.WriteString("\n")
 	}
 
 	sb.WriteString(codeContext)
 	sb.WriteString("\n")
 
+	if a.baseline != nil {
+		writeBaselineSection(&sb, a.baseline)
+	}
+	if len(tests) > 0 {
+		writeAcceptanceSection(&sb, tests)
+	}
+
 	sb.WriteString("## Instructions\n\n")
 	sb.WriteString("For each feature, examine the codebase and determine:\n")
 	sb.WriteString("- Status: COMPLETE (core functionality implemented and working), PARTIAL (some implementation exists but incomplete), or MISSING (not implemented)\n")
 	sb.WriteString("- Evidence: File references and code snippets supporting your assessment\n")
-	sb.WriteString("- Reasoning: Why you reached this conclusion\n\n")
+	sb.WriteString("- Anchors: For COMPLETE and PARTIAL, the code implementing the feature: file path relative to the repository root, symbol (function, type or method) and line range. Anchors are checked against the repository and claims whose anchors do not exist are downgraded\n")
+	sb.WriteString("- Reasoning: Why you reached this conclusion\n")
+	sb.WriteString("- Confidence: How sure you are of the status, from 0.0 to 1.0\n")
+	sb.WriteString("For each gap, rate its severity: low (cosmetic or edge cases), medium (secondary behavior), high (main behavior) or critical (the feature is unusable)\n\n")
 	sb.WriteString("IMPORTANT: Mark a feature as COMPLETE if its core functionality is implemented, even if minor details or edge cases remain. ")
 	sb.WriteString("Only mark as PARTIAL if significant portions are missing or broken.\n\n")
 
+	if len(a.rubric) > 0 {
+		orchestrator.WriteRubricSection(&sb, a.rubric)
+		sb.WriteString("Judge the criteria across the code implementing the features and return one verdict per criterion in \"rubric\".\n\n")
+	}
+
 	sb.WriteString("Respond with valid JSON in this exact format:\n")
 	sb.WriteString("```json\n")
 	sb.WriteString(`{
@@ -273,7 +474,11 @@ func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string) string {
       "feature_id": "string",
       "status": "COMPLETE|PARTIAL|MISSING",
       "evidence": "string",
-      "reasoning": "string"
+      "anchors": [
+        {"file": "path/to/file.go", "symbol": "string", "start_line": 0, "end_line": 0}
+      ],
+      "reasoning": "string",
+      "confidence": 0.0
     }
   ],
   "gaps": [
@@ -281,9 +486,17 @@ func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string) string {
       "feature_id": "string",
       "status": "PARTIAL|MISSING",
       "description": "string",
-      "suggested_action": "string"
+      "suggested_action": "string",
+      "severity": "low|medium|high|critical"
     }
-  ],
+  ],`)
+	if len(a.rubric) > 0 {
+		sb.WriteString(`
+  "rubric": [
+    {"criterion": "string", "verdict": "pass|fail|n/a", "reasoning": "string", "concerns": ["string"]}
+  ],`)
+	}
+	sb.WriteString(`
   "summary": "string"
 }
 `)
@@ -292,7 +505,47 @@ func (a *Auditor) buildAuditPrompt(spec *ArchSpec, codeContext string) string {
 	return sb.String()
 }
 
+// maxBaselineItems caps each list of pre-existing failures in the audit prompt.
+const maxBaselineItems = 20
+
+// writeBaselineSection describes the failures that predate implementation.
+func writeBaselineSection(sb *strings.Builder, baseline *agent.Baseline) {
+	sb.WriteString("## Baseline\n\n")
+	sb.WriteString("Before implementation started")
+	if baseline.Commit != "" {
+		sb.WriteString(fmt.Sprintf(" (commit %s)", baseline.Commit))
+	}
+	if baseline.BuildPassed {
+		sb.WriteString(" the project built.")
+	} else {
+		sb.WriteString(" the project did not build.")
+	}
+	sb.WriteString(" These failures already existed then. Do not report them as gaps or count them against a feature unless its criteria require fixing them.\n")
+	for _, list := range []struct {
+		name  string
+		items []string
+	}{
+		{"Failing tests", baseline.FailingTests},
+		{"Type errors", baseline.TypeErrors},
+		{"Lint errors", baseline.LintErrors},
+	} {
+		if len(list.items) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", list.name, len(list.items)))
+		for i, item := range list.items {
+			if i == maxBaselineItems {
+				sb.WriteString(fmt.Sprintf("- ... and %d more\n", len(list.items)-maxBaselineItems))
+				break
+			}
+			sb.WriteString("- " + item + "\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
 // parseAuditResponse parses Claude's JSON response into a GapReport.
+// With a rubric, the auditor's verdicts are matched to its criteria.
 func (a *Auditor) parseAuditResponse(response string, features []Feature) (*GapReport, error) {
 	// Extract JSON from response (it may be wrapped in markdown code blocks)
 	jsonStr := extractJSON(response)
@@ -302,18 +555,22 @@ func (a *Auditor) parseAuditResponse(response string, features []Feature) (*GapR
 
 	var rawReport struct {
 		Features []struct {
-			FeatureID string `json:"feature_id"`
-			Status    string `json:"status"`
-			Evidence  string `json:"evidence"`
-			Reasoning string `json:"reasoning"`
+			FeatureID  string           `json:"feature_id"`
+			Status     string           `json:"status"`
+			Evidence   string           `json:"evidence"`
+			Anchors    []EvidenceAnchor `json:"anchors"`
+			Reasoning  string           `json:"reasoning"`
+			Confidence float64          `json:"confidence"`
 		} `json:"features"`
 		Gaps []struct {
 			FeatureID       string `json:"feature_id"`
 			Status          string `json:"status"`
 			Description     string `json:"description"`
 			SuggestedAction string `json:"suggested_action"`
+			Severity        string `json:"severity"`
 		} `json:"gaps"`
-		Summary string `json:"summary"`
+		Rubric  []orchestrator.CriterionVerdict `json:"rubric"`
+		Summary string                          `json:"summary"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &rawReport); err != nil {
@@ -332,6 +589,9 @@ func (a *Auditor) parseAuditResponse(response string, features []Feature) (*GapR
 		Gaps:     make([]Gap, 0, len(rawReport.Gaps)),
 		Summary:  rawReport.Summary,
 	}
+	if len(a.rubric) > 0 {
+		report.Rubric = orchestrator.NormalizeVerdicts(rawReport.Rubric, a.rubric)
+	}
 
 	for _, rf := range rawReport.Features {
 		feature, ok := featureMap[rf.FeatureID]
@@ -340,11 +600,21 @@ func (a *Auditor) parseAuditResponse(response string, features []Feature) (*GapR
 		}
 
 		status := parseAuditStatus(rf.Status)
+		var anchors []EvidenceAnchor
+		for _, anchor := range rf.Anchors {
+			if anchor.File == "" && anchor.Symbol == "" {
+				continue
+			}
+			anchor.Problem = ""
+			anchors = append(anchors, anchor)
+		}
 		report.Features = append(report.Features, FeatureStatus{
-			Feature:   feature,
-			Status:    status,
-			Evidence:  rf.Evidence,
-			Reasoning: rf.Reasoning,
+			Feature:    feature,
+			Status:     status,
+			Evidence:   rf.Evidence,
+			Anchors:    anchors,
+			Reasoning:  rf.Reasoning,
+			Confidence: rf.Confidence,
 		})
 	}
 
@@ -355,12 +625,38 @@ func (a *Auditor) parseAuditResponse(response string, features []Feature) (*GapR
 			Status:          status,
 			Description:     rg.Description,
 			SuggestedAction: rg.SuggestedAction,
+			Critical:        featureMap[rg.FeatureID].Critical,
+			Severity:        gapSeverity(rg.Severity, status),
 		})
 	}
 
 	return report, nil
 }
 
+// deferGaps moves the gaps in deferred features from the report's gaps to
+// its deferred gaps, so they are reported without being planned.
+func deferGaps(report *GapReport, features []Feature) {
+	deferred := make(map[string]bool)
+	for _, f := range features {
+		if f.IsDeferred() {
+			deferred[f.ID] = true
+		}
+	}
+	if len(deferred) == 0 {
+		return
+	}
+
+	gaps := report.Gaps[:0]
+	for _, gap := range report.Gaps {
+		if deferred[gap.FeatureID] {
+			report.Deferred = append(report.Deferred, gap)
+		} else {
+			gaps = append(gaps, gap)
+		}
+	}
+	report.Gaps = gaps
+}
+
 // extractJSON extracts JSON content from a response that may include markdown.
 func extractJSON(response string) string {
 	// Try to find JSON in code blocks first
@@ -1,7 +1,12 @@
 package architect
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 )
 
 func TestAuditStatusConstants(t *testing.T) {
@@ -146,7 +151,7 @@ func TestBuildAuditPrompt(t *testing.T) {
 
 	codeContext := "## Repository Structure\n\n- main.go\n- pkg/util.go\n"
 
-	prompt := auditor.buildAuditPrompt(spec, codeContext)
+	prompt := auditor.buildAuditPrompt(spec, codeContext, nil)
 
 	// Check that prompt contains essential elements
 	checks := []string{
@@ -171,6 +176,39 @@ func TestBuildAuditPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildAuditPromptWithBaseline(t *testing.T) {
+	auditor := NewAuditor()
+	spec := &ArchSpec{Name: "Test Spec", Features: []Feature{{ID: "f1", Name: "Feature One"}}}
+
+	if prompt := auditor.buildAuditPrompt(spec, "", nil); contains(prompt, "## Baseline") {
+		t.Error("prompt should not have a baseline section without a baseline")
+	}
+
+	failing := make([]string, maxBaselineItems+3)
+	for i := range failing {
+		failing[i] = fmt.Sprintf("example.com/app/TestLegacy%d", i)
+	}
+	auditor.SetBaseline(&agent.Baseline{Commit: "abc123", FailingTests: failing, LintErrors: []string{"main.go: unused variable"}})
+	prompt := auditor.buildAuditPrompt(spec, "", nil)
+
+	for _, check := range []string{
+		"## Baseline",
+		"commit abc123",
+		"did not build",
+		"Failing tests (23)",
+		"example.com/app/TestLegacy0",
+		"... and 3 more",
+		"main.go: unused variable",
+	} {
+		if !contains(prompt, check) {
+			t.Errorf("prompt should contain %q", check)
+		}
+	}
+	if contains(prompt, "Type errors") {
+		t.Error("prompt should omit empty baseline lists")
+	}
+}
+
 func TestParseAuditResponse(t *testing.T) {
 	auditor := NewAuditor()
 	features := []Feature{
@@ -361,3 +399,65 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDeferGaps(t *testing.T) {
+	features := []Feature{{ID: "F1", Name: "Login"}, {ID: "F2", Name: "SSO", Deferred: "phase-2"}}
+	report := &GapReport{
+		Gaps: []Gap{
+			{FeatureID: "F1", Status: AuditStatusPartial},
+			{FeatureID: "F2", Status: AuditStatusMissing},
+		},
+	}
+
+	deferGaps(report, features)
+	if len(report.Gaps) != 1 || report.Gaps[0].FeatureID != "F1" {
+		t.Errorf("Gaps = %+v, want F1's gap only", report.Gaps)
+	}
+	if len(report.Deferred) != 1 || report.Deferred[0].FeatureID != "F2" {
+		t.Errorf("Deferred = %+v, want F2's gap", report.Deferred)
+	}
+}
+
+func TestBuildAuditPromptDeferred(t *testing.T) {
+	spec := &ArchSpec{Features: []Feature{{ID: "F1", Name: "SSO", Deferred: "phase-2"}}}
+	prompt := NewAuditor().buildAuditPrompt(spec, "", nil)
+	if !strings.Contains(prompt, "Scope: deferred (phase-2)") {
+		t.Errorf("prompt does not mark the deferred feature:\n%s", prompt)
+	}
+}
+
+func TestParseAuditResponse_Rubric(t *testing.T) {
+	auditor := NewAuditor()
+	auditor.SetRubric([]policy.RubricCriterion{
+		{Name: "security", Description: "No injection"},
+		{Name: "i18n", Description: "Text is translatable"},
+	})
+
+	response := `{
+  "features": [{"feature_id": "f1", "status": "COMPLETE"}],
+  "gaps": [],
+  "rubric": [
+    {"criterion": "security", "verdict": "fail", "reasoning": "SQL built by concatenation", "concerns": ["[critical] db/query.go:10 unescaped input"]}
+  ],
+  "summary": "done"
+}`
+
+	report, err := auditor.parseAuditResponse(response, []Feature{{ID: "f1", Name: "Query"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Rubric) != 2 {
+		t.Fatalf("rubric = %+v, want a verdict per criterion", report.Rubric)
+	}
+	if !report.Rubric[0].Failed() || len(report.Rubric[0].Concerns) != 1 {
+		t.Errorf("security verdict = %+v", report.Rubric[0])
+	}
+	if report.Rubric[1].Criterion != "i18n" || !report.Rubric[1].Failed() {
+		t.Errorf("missing i18n verdict = %+v, want failed", report.Rubric[1])
+	}
+
+	prompt := auditor.buildAuditPrompt(&ArchSpec{Features: []Feature{{ID: "f1"}}}, "", nil)
+	if !strings.Contains(prompt, "- i18n: Text is translatable") || !strings.Contains(prompt, `"rubric"`) {
+		t.Error("audit prompt should enumerate the rubric and ask for verdicts")
+	}
+}
@@ -0,0 +1,280 @@
+// Package architect provides architecture document parsing and analysis.
+package architect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// includeDirective matches an include line such as
+// <!-- include: auth/login.md -->.
+var includeDirective = regexp.MustCompile(`(?m)^[ \t]*<!--\s*include:\s*(\S+)\s*-->[ \t]*$`)
+
+// specLink matches a Markdown link to another spec file, optionally with an
+// anchor: [Login](auth.md#login).
+var specLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s#]+\.(?:md|markdown))(?:#([^)\s]+))?\)`)
+
+// specFileMarker introduces each file of a composed spec.
+const specFileMarker = "<!-- spec-file: %s -->"
+
+// SpecDocument is an architecture specification read from a single file or
+// composed from a directory of Markdown files.
+type SpecDocument struct {
+	// Path is the file or directory the spec was loaded from.
+	Path string
+	// Files lists the files the spec was composed from, relative to the spec
+	// directory, in composition order. Included files are listed too.
+	Files []string
+	// Content is the composed document text.
+	Content string
+	// XML is true for single-file XML specs.
+	XML bool
+}
+
+// MultiFile returns true if the document was composed from several files.
+func (d *SpecDocument) MultiFile() bool {
+	return len(d.Files) > 1
+}
+
+// LoadSpec reads the spec at path. A file is read as is, after expanding
+// include directives. A directory is composed from every Markdown file in it:
+// index.md (or README.md) first, then the rest in path order, skipping files
+// already pulled in by an include directive. Each file is introduced by a
+// <!-- spec-file: path --> marker, and links to other spec files are
+// rewritten into plain references the parser can follow.
+func LoadSpec(path string) (*SpecDocument, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read document: %w", err)
+	}
+
+	if !info.IsDir() {
+		doc := &SpecDocument{Path: path, XML: strings.EqualFold(filepath.Ext(path), ".xml")}
+		c := &specComposer{root: filepath.Dir(path), included: make(map[string]bool)}
+		content, err := c.expand(filepath.Base(path), nil)
+		if err != nil {
+			return nil, err
+		}
+		doc.Files = c.files
+		doc.Content = content
+		if doc.MultiFile() {
+			doc.Content = rewriteSpecLinks(fmt.Sprintf(specFileMarker+"\n\n%s", doc.Files[0], content))
+		}
+		return doc, nil
+	}
+
+	files, err := specFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Markdown files in spec directory %s", path)
+	}
+
+	// Files pulled in by an include are not composed again on their own
+	c := &specComposer{root: path, included: make(map[string]bool)}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(path, f))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f, err)
+		}
+		for _, m := range includeDirective.FindAllStringSubmatch(string(data), -1) {
+			c.included[c.resolve(f, m[1])] = true
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range files {
+		if c.included[f] {
+			continue
+		}
+		content, err := c.expand(f, nil)
+		if err != nil {
+			return nil, err
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, specFileMarker+"\n\n", f)
+		b.WriteString(strings.TrimSpace(content))
+	}
+	return &SpecDocument{Path: path, Files: c.files, Content: rewriteSpecLinks(b.String())}, nil
+}
+
+// specFiles lists the Markdown files under dir relative to it, index first.
+func specFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read spec directory: %w", err)
+	}
+
+	rank := func(f string) int {
+		switch strings.ToLower(f) {
+		case "index.md", "index.markdown":
+			return 0
+		case "readme.md", "readme.markdown":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if ri, rj := rank(files[i]), rank(files[j]); ri != rj {
+			return ri < rj
+		}
+		return files[i] < files[j]
+	})
+	return files, nil
+}
+
+// specComposer expands include directives relative to a spec root.
+type specComposer struct {
+	root string
+	// included holds files pulled in by an include directive.
+	included map[string]bool
+	// files records every file read, in order.
+	files []string
+}
+
+// resolve returns the path of an include target relative to the root.
+func (c *specComposer) resolve(from, target string) string {
+	return filepath.ToSlash(filepath.Clean(filepath.Join(filepath.Dir(from), target)))
+}
+
+// expand reads file and replaces its include directives with the included
+// files' content. stack holds the files being expanded, to detect cycles.
+func (c *specComposer) expand(file string, stack []string) (string, error) {
+	for _, f := range stack {
+		if f == file {
+			return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), file)
+		}
+	}
+	if strings.HasPrefix(file, "../") {
+		return "", fmt.Errorf("include %s is outside the spec directory", file)
+	}
+	data, err := os.ReadFile(filepath.Join(c.root, file))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	c.files = append(c.files, file)
+	stack = append(stack, file)
+
+	var expandErr error
+	content := includeDirective.ReplaceAllStringFunc(string(data), func(line string) string {
+		if expandErr != nil {
+			return line
+		}
+		target := c.resolve(file, includeDirective.FindStringSubmatch(line)[1])
+		included, err := c.expand(target, stack)
+		if err != nil {
+			expandErr = err
+			return line
+		}
+		return fmt.Sprintf(specFileMarker+"\n\n%s", target, strings.TrimSpace(included))
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return content, nil
+}
+
+// rewriteSpecLinks turns links to other spec files into references that
+// name the file and section, since the files are no longer separate.
+func rewriteSpecLinks(content string) string {
+	return specLink.ReplaceAllStringFunc(content, func(link string) string {
+		m := specLink.FindStringSubmatch(link)
+		ref := filepath.ToSlash(filepath.Clean(m[2]))
+		if m[3] != "" {
+			ref += ", section " + strings.ReplaceAll(m[3], "-", " ")
+		}
+		return fmt.Sprintf("%s (see %s)", m[1], ref)
+	})
+}
+
+// stabilizeFeatureIDs makes the feature IDs of a multi-file spec stable and
+// unique. IDs written in the spec are kept; IDs the parser made up are
+// replaced by one derived from the feature's file and name, so adding or
+// reordering files does not renumber features. An explicit ID defined in
+// two files is an error.
+func stabilizeFeatureIDs(spec *ArchSpec, doc *SpecDocument) error {
+	seen := make(map[string]string, len(spec.Features))
+	for i := range spec.Features {
+		f := &spec.Features[i]
+		if !containsWord(doc.Content, f.ID) {
+			f.ID = stableFeatureID(f.Source, f.Name)
+		}
+		if prev, ok := seen[f.ID]; ok {
+			if containsWord(doc.Content, f.ID) && prev != f.Source {
+				return fmt.Errorf("feature ID %q is defined in both %s and %s", f.ID, prev, f.Source)
+			}
+			base := f.ID
+			for n := 2; ; n++ {
+				f.ID = fmt.Sprintf("%s-%d", base, n)
+				if _, ok := seen[f.ID]; !ok {
+					break
+				}
+			}
+		}
+		seen[f.ID] = f.Source
+	}
+	return nil
+}
+
+// stableFeatureID derives a feature ID from its source file and name,
+// e.g. "auth/user-login" for the "User Login" feature of auth.md.
+func stableFeatureID(source, name string) string {
+	id := slugify(name)
+	if source == "" {
+		return id
+	}
+	stem := strings.TrimSuffix(source, filepath.Ext(source))
+	return strings.ToLower(stem) + "/" + id
+}
+
+// slugify lowercases s and joins its words with hyphens.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// containsWord returns true if word appears in text on word boundaries.
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	return regexp.MustCompile(`(^|[^\w-])` + regexp.QuoteMeta(word) + `($|[^\w-])`).MatchString(text)
+}
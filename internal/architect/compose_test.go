@@ -0,0 +1,145 @@
+package architect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeSpecFiles writes files (path -> content) under a temp dir.
+func writeSpecFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadSpecDirectory(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"index.md":           "# Shop\n\n<!-- include: shared/glossary.md -->\n",
+		"auth.md":            "## User Login\nUsers log in. Orders need [login](auth.md#user-login).\n",
+		"orders.md":          "## Checkout\nRequires [User Login](./auth.md#user-login).\n",
+		"shared/glossary.md": "Glossary: SKU.\n",
+		".drafts/wip.md":     "## Unfinished\n",
+		"notes.txt":          "not a spec",
+	})
+
+	doc, err := LoadSpec(dir)
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	if want := []string{"index.md", "shared/glossary.md", "auth.md", "orders.md"}; !reflect.DeepEqual(doc.Files, want) {
+		t.Errorf("Files = %v, want %v", doc.Files, want)
+	}
+	if !doc.MultiFile() {
+		t.Error("directory spec should be multi-file")
+	}
+	for _, want := range []string{
+		"<!-- spec-file: index.md -->",
+		"<!-- spec-file: shared/glossary.md -->\n\nGlossary: SKU.",
+		"<!-- spec-file: orders.md -->",
+		"User Login (see auth.md, section user login)",
+	} {
+		if !strings.Contains(doc.Content, want) {
+			t.Errorf("composed spec missing %q:\n%s", want, doc.Content)
+		}
+	}
+	// The included glossary appears once, inside index.md
+	if n := strings.Count(doc.Content, "Glossary: SKU."); n != 1 {
+		t.Errorf("glossary composed %d times", n)
+	}
+	if strings.Contains(doc.Content, "Unfinished") {
+		t.Error("hidden directories should be skipped")
+	}
+}
+
+func TestLoadSpecSingleFile(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{"spec.md": "# Spec\n[link](other.md)\n"})
+	doc, err := LoadSpec(filepath.Join(dir, "spec.md"))
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	// A plain file is read unchanged
+	if doc.MultiFile() || doc.Content != "# Spec\n[link](other.md)\n" {
+		t.Errorf("doc = %+v", doc)
+	}
+}
+
+func TestLoadSpecIncludeErrors(t *testing.T) {
+	cycle := writeSpecFiles(t, map[string]string{
+		"a.md": "<!-- include: b.md -->\n",
+		"b.md": "<!-- include: a.md -->\n",
+	})
+	if _, err := LoadSpec(filepath.Join(cycle, "a.md")); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+
+	outside := writeSpecFiles(t, map[string]string{"spec/a.md": "<!-- include: ../secret.md -->\n", "secret.md": "x"})
+	if _, err := LoadSpec(filepath.Join(outside, "spec")); err == nil {
+		t.Error("expected an error for an include outside the spec directory")
+	}
+
+	if _, err := LoadSpec(writeSpecFiles(t, map[string]string{"a.txt": "x"})); err == nil {
+		t.Error("expected an error for a directory without Markdown files")
+	}
+}
+
+func TestStabilizeFeatureIDs(t *testing.T) {
+	doc := &SpecDocument{
+		Files:   []string{"auth.md", "orders.md"},
+		Content: "<!-- spec-file: auth.md -->\n## AUTH-1 User Login\n<!-- spec-file: orders.md -->\n## Checkout\n",
+	}
+	spec := &ArchSpec{Features: []Feature{
+		{ID: "AUTH-1", Name: "User Login", Source: "auth.md"},
+		{ID: "F002", Name: "Checkout", Source: "orders.md"},
+		{ID: "F003", Name: "Checkout", Source: "orders.md"},
+	}}
+	if err := stabilizeFeatureIDs(spec, doc); err != nil {
+		t.Fatalf("stabilizeFeatureIDs: %v", err)
+	}
+	var ids []string
+	for _, f := range spec.Features {
+		ids = append(ids, f.ID)
+	}
+	if want := []string{"AUTH-1", "orders/checkout", "orders/checkout-2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs = %v, want %v", ids, want)
+	}
+
+	dup := &ArchSpec{Features: []Feature{
+		{ID: "AUTH-1", Name: "Login", Source: "auth.md"},
+		{ID: "AUTH-1", Name: "Checkout", Source: "orders.md"},
+	}}
+	if err := stabilizeFeatureIDs(dup, doc); err == nil {
+		t.Error("expected an error for an explicit ID defined in two files")
+	}
+}
+
+func TestParseSpecDirectory(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"auth.md":   "## User Login\n",
+		"orders.md": "## ORD-1 Checkout\nNeeds [login](auth.md#user-login).\n",
+	})
+	runner := &scriptedRunner{reply: `{"name": "Shop", "features": [
+		{"id": "F001", "name": "User Login", "description": "Log in", "source": "auth.md"},
+		{"id": "ORD-1", "name": "Checkout", "description": "Pay", "source": "orders.md"}
+	]}`}
+
+	spec, err := NewParser().Parse(context.Background(), dir, runner)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if spec.Features[0].ID != "auth/user-login" || spec.Features[1].ID != "ORD-1" {
+		t.Errorf("feature IDs = %s, %s", spec.Features[0].ID, spec.Features[1].ID)
+	}
+}
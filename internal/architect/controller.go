@@ -3,21 +3,35 @@ package architect
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/remote"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
+// archLog is the logger of the implementation loop's records.
+var archLog = logging.Logger("architect")
+
 // ProgressPhase represents the current phase of the implementation loop.
 type ProgressPhase string
 
 const (
+	// PhaseBaseline indicates the build and test baseline is being captured.
+	PhaseBaseline ProgressPhase = "baseline"
 	// PhaseParsing indicates the architecture document is being parsed.
 	PhaseParsing ProgressPhase = "parsing"
 	// PhaseAuditing indicates the codebase is being audited.
@@ -62,6 +76,18 @@ type ProgressEvent struct {
 	ActiveWorkers map[string]WorkerInfo
 	// Message is an optional status message.
 	Message string
+	// EventType is the orchestrator event type for task-level events (empty for phase updates).
+	EventType string
+	// TaskID is the task the event refers to, if any.
+	TaskID string
+	// TaskTitle is the title of the task the event refers to, if any.
+	TaskTitle string
+	// TokensUsed is the cumulative token usage of every runner so far
+	// (cost_tick events only).
+	TokensUsed int64
+	// Estimate is the pre-run cost estimate of an iteration's tasks
+	// (cost_estimate events only).
+	Estimate *orchestrator.RunEstimate
 	// Timestamp is when the event occurred.
 	Timestamp time.Time
 }
@@ -72,6 +98,10 @@ type WorkerInfo struct {
 	TaskID    string
 	TaskTitle string
 	Status    string
+	LogFile   string // Execution log, known once the agent reports progress
+	// Questions the task's agents asked and may ask, -1 for unlimited
+	QuestionsAsked   int
+	QuestionsAllowed int
 }
 
 // ProgressCallback is called when progress events occur.
@@ -81,20 +111,65 @@ type ProgressCallback func(event ProgressEvent)
 // It parses the architecture document, audits the codebase for gaps,
 // plans epics from gaps, executes them, and repeats until done or stopped.
 type Controller struct {
-	// MaxIterations is the maximum number of iterations before stopping.
-	// A value of 0 means no limit.
+	// MaxIterations is the maximum number of implementation iterations
+	// before stopping. Verification rounds do not count against it. A value
+	// of 0 means no limit.
 	MaxIterations int
+	// MaxVerificationRounds is the maximum number of verification rounds
+	// that may fail before stopping. A verification round is an iteration
+	// whose audit follows an iteration that completed every task it
+	// planned: the audit verifies the work instead of finding the next
+	// gaps. A value of 0 means no limit.
+	MaxVerificationRounds int
+	// VerificationMode selects whether a verification round re-audits every
+	// feature (full, the default) or only those that had gaps (incremental).
+	VerificationMode VerificationMode
 	// Budget is the maximum cost allowed (in dollars).
 	// A value of 0 means no limit.
 	Budget float64
 	// NoConvergeAfter is the number of consecutive iterations without progress
 	// before considering the loop converged. A value of 0 means no convergence check.
 	NoConvergeAfter int
+	// Deadline is the wall-clock time Run may take. Running agents are
+	// stopped when it passes. A value of 0 means no limit.
+	Deadline time.Duration
+	// MaxIterationDuration is the wall-clock time a single iteration may
+	// take. Running agents are stopped when it passes. A value of 0 means
+	// no limit.
+	MaxIterationDuration time.Duration
 
 	// RepoPath is the path to the repository being audited.
 	RepoPath string
 	// ProjectName is the prog project name for task management.
 	ProjectName string
+	// ReportDir is where Markdown and HTML audit reports are written after
+	// each audit. Empty disables report output.
+	ReportDir string
+	// PlanOnly stops after the first audit is planned: the execution plan
+	// with per-task estimates is built but no agents run and nothing is
+	// written to prog. Retrieve it with ExecutionPlan.
+	PlanOnly bool
+	// IncludeDeferred brings the features the spec marks as optional,
+	// deferred or for a later phase into scope, so they are planned and
+	// required for completion like the others.
+	IncludeDeferred bool
+	// Scoring selects how completion is computed: the share of complete
+	// features (strict, the default) or with partial credit by gap severity
+	// (weighted).
+	Scoring ScoringMode
+	// Strictness selects what final verification requires: every feature
+	// complete (all, the default) or every critical feature (critical).
+	Strictness Strictness
+	// Greenfield merges agent work directly into the current branch instead
+	// of through session branches. Greenfield runs never open a pull request.
+	Greenfield bool
+	// BaseBranch is the branch epics start from and merge into, and the
+	// branch a pull request targets. Empty uses the repository's default
+	// branch, or the current branch when opening a pull request.
+	BaseBranch string
+	// CommitIdentity sets the author, signing and message template of the
+	// commits agents and sessions make. Nil uses the git config.
+	CommitIdentity *git.CommitIdentity
 
 	// parser parses architecture documents into feature specs.
 	parser *Parser
@@ -111,21 +186,86 @@ type Controller struct {
 	// runnerFactory creates ClaudeRunner instances.
 	// If nil, falls back to creating ClaudeProcess (legacy).
 	runnerFactory agent.ClaudeRunnerFactory
+	// worktreeDir is where the sessions create task worktrees; empty uses
+	// the executor's default.
+	worktreeDir string
 	// tokenTracker tracks cumulative token usage and cost.
 	tokenTracker *agent.TokenTracker
+	// usageMeter records the usage of every runner runnerFactory creates,
+	// if it is metered. It replaces tokenTracker as the source of cost.
+	usageMeter *agent.UsageMeter
+	// baseline is the build, test and lint state before the first
+	// iteration. Every iteration's session and audit compare against it.
+	baseline *agent.Baseline
+	// promptCache answers parses and audits of unchanged inputs, if set.
+	promptCache *PromptCache
+	// approvals hold the loop, and the sessions it runs, for an operator's
+	// approval, if set.
+	approvals *orchestrator.ApprovalGates
+	// reviewRubric is the rubric the audits and the sessions' second
+	// reviewers give a verdict on, if set.
+	reviewRubric []policy.RubricCriterion
+	// sessionPolicy is the policy the sessions run under, if set; the
+	// defaults otherwise.
+	sessionPolicy *policy.Config
+	// protectedAreas flags the sessions' tasks that touch sensitive areas,
+	// if set.
+	protectedAreas *protect.Detector
+	// tierConfigs are the tier settings the sessions run with, sandbox
+	// included, if set.
+	tierConfigs *config.TierConfigs
+	// sandboxAllowlist is the repository's sandbox allowlist, added to the
+	// tier sandbox's own.
+	sandboxAllowlist []string
+
+	// drainMu guards the graceful shutdown state below.
+	drainMu sync.Mutex
+	// draining is set once Drain is called; no further iteration starts.
+	draining bool
+	// drainGrace is how long the running agents may finish after Drain.
+	drainGrace time.Duration
+	// session is the orchestrator of the epic being executed, if any.
+	session *orchestrator.Orchestrator
 
 	// Current state tracking (for progress events during execution)
 	currentIteration        int
 	currentFeaturesTotal    int
+	currentCriticalTotal    int
 	currentFeaturesComplete int
 
 	// Feature completion tracking (for real-time updates during execution)
-	featureToTasks map[string][]string // maps feature ID to task IDs
-	completedTasks map[string]bool     // tracks which tasks are done
-	featureGaps    map[string]Gap      // maps feature ID to gap details
+	featureToTasks map[string][]string  // maps feature ID to task IDs
+	completedTasks map[string]bool      // tracks which tasks are done
+	featureGaps    map[string]Gap       // maps feature ID to gap details
+	taskFeatures   map[string]string    // maps task ID to feature ID
+	taskCosts      map[string]*TaskCost // spend of the iteration's tasks by task ID
 
 	// Active worker tracking (for UI display)
 	activeWorkers map[string]WorkerInfo // maps agent ID to worker info
+
+	// executionPlan is the plan built in PlanOnly mode.
+	executionPlan *ExecutionPlan
+	// result is the outcome of the current or last run.
+	result *RunResult
+	// traceTasks are the tasks completed across the run, for the
+	// traceability matrix.
+	traceTasks []TraceTask
+	// gapHistory holds the gaps each iteration's audit reported, and
+	// taskFailures the tasks that failed across the run, for the
+	// convergence report.
+	gapHistory   [][]Gap
+	taskFailures []TaskFailure
+
+	// remoteProvider publishes the run as a pull request when set.
+	remoteProvider remote.Provider
+	// prBase is the branch the pull request targets.
+	prBase string
+	// prBranch is the branch the run's epics merge into and the pull request head.
+	prBranch string
+	// reviewConcerns collects second-reviewer concerns for the pull request review.
+	reviewConcerns []remote.Concern
+	// pullRequest is the pull request opened at the end of the run.
+	pullRequest *remote.PullRequest
 }
 
 // ControllerOption is a functional option for configuring a Controller.
@@ -145,6 +285,186 @@ func WithProjectName(name string) ControllerOption {
 	}
 }
 
+// WithReportDir sets the directory audit reports are written to.
+func WithReportDir(dir string) ControllerOption {
+	return func(c *Controller) {
+		c.ReportDir = dir
+	}
+}
+
+// WithPlanOnly enables plan-only mode (see Controller.PlanOnly).
+func WithPlanOnly(planOnly bool) ControllerOption {
+	return func(c *Controller) {
+		c.PlanOnly = planOnly
+	}
+}
+
+// WithIncludeDeferred brings deferred features into scope (see
+// Controller.IncludeDeferred).
+func WithIncludeDeferred(include bool) ControllerOption {
+	return func(c *Controller) {
+		c.IncludeDeferred = include
+	}
+}
+
+// WithScoring sets how completion is computed (see Controller.Scoring).
+func WithScoring(mode ScoringMode) ControllerOption {
+	return func(c *Controller) {
+		c.Scoring = mode
+	}
+}
+
+// WithStrictness sets what final verification requires (see
+// Controller.Strictness).
+func WithStrictness(strictness Strictness) ControllerOption {
+	return func(c *Controller) {
+		c.Strictness = strictness
+	}
+}
+
+// WithMaxVerificationRounds limits the verification rounds that may fail
+// (see Controller.MaxVerificationRounds).
+func WithMaxVerificationRounds(n int) ControllerOption {
+	return func(c *Controller) {
+		c.MaxVerificationRounds = n
+	}
+}
+
+// WithVerificationMode sets what a verification round audits (see
+// Controller.VerificationMode).
+func WithVerificationMode(mode VerificationMode) ControllerOption {
+	return func(c *Controller) {
+		c.VerificationMode = mode
+	}
+}
+
+// WithGreenfield enables greenfield mode (see Controller.Greenfield).
+func WithGreenfield(greenfield bool) ControllerOption {
+	return func(c *Controller) {
+		c.Greenfield = greenfield
+	}
+}
+
+// WithBaseBranch sets the branch epics merge into (see Controller.BaseBranch).
+func WithBaseBranch(branch string) ControllerOption {
+	return func(c *Controller) {
+		c.BaseBranch = branch
+	}
+}
+
+// WithDeadline limits the wall-clock time of the run (see
+// Controller.Deadline).
+func WithDeadline(d time.Duration) ControllerOption {
+	return func(c *Controller) {
+		c.Deadline = d
+	}
+}
+
+// WithMaxIterationDuration limits the wall-clock time of each iteration
+// (see Controller.MaxIterationDuration).
+func WithMaxIterationDuration(d time.Duration) ControllerOption {
+	return func(c *Controller) {
+		c.MaxIterationDuration = d
+	}
+}
+
+// WithCommitIdentity sets who agent and session commits are made as (see
+// Controller.CommitIdentity).
+func WithCommitIdentity(identity *git.CommitIdentity) ControllerOption {
+	return func(c *Controller) {
+		c.CommitIdentity = identity
+	}
+}
+
+// WithPromptCache answers the parser's and auditor's calls from cache when
+// the spec and repository are unchanged (see PromptCache).
+func WithPromptCache(cache *PromptCache) ControllerOption {
+	return func(c *Controller) {
+		c.promptCache = cache
+		c.parser.SetPromptCache(cache)
+		c.auditor.SetPromptCache(cache)
+	}
+}
+
+// WithFeatureTests runs each feature's acceptance tests in every audit,
+// including the final verification (see FeatureTestMap).
+func WithFeatureTests(tests FeatureTestMap) ControllerOption {
+	return func(c *Controller) {
+		c.auditor.SetFeatureTests(tests)
+	}
+}
+
+// WithSequentialVerification runs the validation layers of every audit one
+// after another instead of running the acceptance tests concurrently with
+// the code layers (see Auditor.SetSequential).
+func WithSequentialVerification(sequential bool) ControllerOption {
+	return func(c *Controller) {
+		c.auditor.SetSequential(sequential)
+	}
+}
+
+// WithReviewRubric has every audit's semantic review and the sessions'
+// second reviewers give a structured verdict on each rubric criterion
+// (see policy.ReviewPolicy.Rubric).
+func WithReviewRubric(rubric []policy.RubricCriterion) ControllerOption {
+	return func(c *Controller) {
+		c.reviewRubric = rubric
+		c.auditor.SetRubric(rubric)
+	}
+}
+
+// WithPolicy sets the policy the loop's sessions run under: scheduling,
+// budgets, merging, review and the rest. Each session gets a copy carrying
+// the review rubric and what is left of the implement budget.
+func WithPolicy(p *policy.Config) ControllerOption {
+	return func(c *Controller) {
+		c.sessionPolicy = p
+	}
+}
+
+// WithProtectedAreaChecker sets the detector the sessions use to flag
+// tasks touching protected areas.
+func WithProtectedAreaChecker(d *protect.Detector) ControllerOption {
+	return func(c *Controller) {
+		c.protectedAreas = d
+	}
+}
+
+// WithTierConfigs sets the tier configurations the sessions run with,
+// including the sandbox their gate, verification and post-merge build
+// commands run in.
+func WithTierConfigs(tc *config.TierConfigs) ControllerOption {
+	return func(c *Controller) {
+		c.tierConfigs = tc
+	}
+}
+
+// WithSandboxAllowlist adds the repository's allowlisted programs to the
+// sessions' tier sandbox.
+func WithSandboxAllowlist(programs []string) ControllerOption {
+	return func(c *Controller) {
+		c.sandboxAllowlist = programs
+	}
+}
+
+// WithRemoteProvider publishes the run as a pull request through provider:
+// epics merge into a fresh branch that is pushed and opened as a pull
+// request once the loop stops. Ignored in greenfield and plan-only runs.
+func WithRemoteProvider(provider remote.Provider) ControllerOption {
+	return func(c *Controller) {
+		c.remoteProvider = provider
+	}
+}
+
+// WithApprovalGates holds the loop at g's gates for an operator's approval:
+// after each iteration's tasks are planned, before each iteration's
+// session merges and before each iteration after the first.
+func WithApprovalGates(g *orchestrator.ApprovalGates) ControllerOption {
+	return func(c *Controller) {
+		c.approvals = g
+	}
+}
+
 // WithProgClient sets a custom prog client.
 func WithProgClient(client *prog.Client) ControllerOption {
 	return func(c *Controller) {
@@ -166,6 +486,14 @@ func WithRunnerFactory(factory agent.ClaudeRunnerFactory) ControllerOption {
 	}
 }
 
+// WithWorktreeDir sets the directory the sessions create task worktrees
+// in, instead of the executor's default under the user cache.
+func WithWorktreeDir(dir string) ControllerOption {
+	return func(c *Controller) {
+		c.worktreeDir = dir
+	}
+}
+
 // createRunner creates a new ClaudeRunner using the factory.
 // The factory must be set via WithRunnerFactory option.
 func (c *Controller) createRunner(ctx context.Context) agent.ClaudeRunner {
@@ -181,10 +509,36 @@ func (c *Controller) emitProgress(event ProgressEvent) {
 		event.Timestamp = time.Now()
 		event.MaxIterations = c.MaxIterations
 		event.CostBudget = c.Budget
+		if c.usageMeter != nil {
+			event.Cost = c.cost()
+		}
 		c.onProgress(event)
 	}
 }
 
+// cost returns the cost of the run so far: the usage meter's, which
+// includes every runner as its responses stream in, or else the cost of
+// the parses, audits and plans tracked after they finished.
+func (c *Controller) cost() float64 {
+	if c.usageMeter != nil {
+		return c.usageMeter.Snapshot().Cost
+	}
+	return c.tokenTracker.GetCost()
+}
+
+// reportUsage emits a cost_tick progress event with the run's usage and
+// cost whenever it changed in the last second, until the returned function
+// is called. It does nothing without a usage meter.
+func (c *Controller) reportUsage() (stop func()) {
+	return c.usageMeter.Watch(time.Second, func(s agent.UsageSnapshot) {
+		c.emitProgress(ProgressEvent{
+			EventType:  string(orchestrator.EventCostTick),
+			Cost:       s.Cost,
+			TokensUsed: s.Usage.TotalTokens,
+		})
+	})
+}
+
 // NewController creates a new Controller with the given configuration.
 func NewController(maxIterations int, budget float64, noConvergeAfter int, opts ...ControllerOption) *Controller {
 	c := &Controller{
@@ -193,18 +547,22 @@ func NewController(maxIterations int, budget float64, noConvergeAfter int, opts
 		NoConvergeAfter: noConvergeAfter,
 		parser:          NewParser(),
 		auditor:         NewAuditor(),
-		stopper: NewStopChecker(StopConfig{
-			MaxIterations:   maxIterations,
-			BudgetLimit:     budget,
-			NoProgressLimit: noConvergeAfter,
-		}),
-		tokenTracker:  agent.NewTokenTracker("sonnet"),
-		activeWorkers: make(map[string]WorkerInfo),
+		tokenTracker:    agent.NewTokenTracker("sonnet"),
+		activeWorkers:   make(map[string]WorkerInfo),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.stopper = NewStopChecker(StopConfig{
+		MaxIterations:         maxIterations,
+		MaxVerificationRounds: c.MaxVerificationRounds,
+		BudgetLimit:           budget,
+		NoProgressLimit:       noConvergeAfter,
+		Deadline:              c.Deadline,
+		MaxIterationDuration:  c.MaxIterationDuration,
+	})
+	c.usageMeter = agent.UsageMeterOf(c.runnerFactory)
 
 	return c
 }
@@ -213,6 +571,9 @@ func NewController(maxIterations int, budget float64, noConvergeAfter int, opts
 type IterationResult struct {
 	// Iteration is the iteration number (1-based).
 	Iteration int
+	// VerificationRound is the number of the verification round the
+	// iteration's audit was (1-based), or 0 for an implementation iteration.
+	VerificationRound int
 	// GapsFound is the number of gaps identified in this iteration.
 	GapsFound int
 	// GapsRemaining is the number of gaps still remaining after execution.
@@ -227,6 +588,13 @@ type IterationResult struct {
 	ProgressMade bool
 	// Cost is the estimated cost incurred in this iteration.
 	Cost float64
+	// TaskCosts is the agent spend of each task executed in this iteration,
+	// most expensive first.
+	TaskCosts []TaskCost
+	// FeatureCosts rolls TaskCosts up per spec feature, most expensive first.
+	FeatureCosts []FeatureCost
+	// Artifacts locates the raw artifacts of the iteration's audit.
+	Artifacts *VerificationArtifacts
 }
 
 // RunResult captures the final result of the controller run.
@@ -239,6 +607,15 @@ type RunResult struct {
 	TotalCost float64
 	// FinalCompletionPct is the final completion percentage.
 	FinalCompletionPct float64
+	// FeatureCosts is the agent spend per spec feature across all
+	// iterations, most expensive first.
+	FeatureCosts []FeatureCost
+	// PromptCache counts the parses and audits answered from the prompt
+	// cache, if one is set.
+	PromptCache []state.PromptCacheStats
+	// Convergence explains why the loop stopped making progress (converged
+	// stops only).
+	Convergence *ConvergenceReport
 }
 
 // Run executes the architecture iteration loop.
@@ -246,8 +623,10 @@ type RunResult struct {
 // executes them via the /alphie skill pattern, and repeats until
 // a stop condition is met.
 func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error {
-	// Initialize prog client if not provided
-	if c.progClient == nil && c.ProjectName != "" {
+	c.stopper.Start()
+
+	// Initialize prog client if not provided (plan-only runs never write to prog)
+	if c.progClient == nil && c.ProjectName != "" && !c.PlanOnly {
 		client, err := prog.NewClientDefault(c.ProjectName)
 		if err != nil {
 			return fmt.Errorf("create prog client: %w", err)
@@ -261,10 +640,35 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 		c.planner = NewPlanner(c.progClient)
 	}
 
-	var result RunResult
+	// Collect the run's work on its own branch for the pull request
+	if c.publishesPullRequest() {
+		if err := c.startPullRequestBranch(); err != nil {
+			return fmt.Errorf("prepare pull request branch: %w", err)
+		}
+	}
+
+	// Record what already fails before any agent changes the repository
+	if !c.PlanOnly {
+		c.captureBaseline()
+	}
+
+	defer c.reportUsage()()
+
+	c.result = &RunResult{}
+	result := c.result
+	c.traceTasks = nil
+	c.gapHistory = nil
+	c.taskFailures = nil
 	var totalCost float64
 	var lastGapCount int = -1
 	var lastIterationCost float64
+	// verifying is set when the last iteration completed every task it
+	// planned, so the next audit is a verification round; lastReport is
+	// the audit an incremental round builds on
+	var implIterations, verificationRounds int
+	verifying := false
+	var lastReport *GapReport
+	var lastCompletionPct float64
 
 	for iteration := 1; ; iteration++ {
 		select {
@@ -272,6 +676,17 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 			return ctx.Err()
 		default:
 		}
+		if c.drainRequested() {
+			return c.stopInterrupted(iteration-1, lastCompletionPct, nil)
+		}
+		c.stopper.StartIteration()
+		round := 0
+		if verifying {
+			verificationRounds++
+			round = verificationRounds
+		} else {
+			implIterations++
+		}
 
 		// Step 1: Parse architecture document
 		c.emitProgress(ProgressEvent{
@@ -286,6 +701,10 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 		if err != nil {
 			return fmt.Errorf("parse architecture doc (iteration %d): %w", iteration, err)
 		}
+		if c.IncludeDeferred {
+			spec.IncludeDeferred()
+		}
+		inScope := len(spec.InScope())
 
 		// Track tokens from parsing
 		if apiRunner, ok := claude.(*agent.ClaudeAPIAdapter); ok {
@@ -299,20 +718,47 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 			}
 		}
 
-		// Step 2: Audit codebase for gaps
+		// Step 2: Audit codebase for gaps, or verify the last iteration's
+		// work; an incremental verification round only re-audits the
+		// features that had gaps
+		auditSpec, incremental := spec, false
+		if round > 0 && c.VerificationMode == VerificationIncremental && lastReport != nil {
+			if partial, ok := recheckSpec(spec, lastReport); ok {
+				auditSpec, incremental = partial, true
+			}
+		}
+		message := auditingMessage(iteration, c.MaxIterations, inScope, len(spec.Features)-inScope)
+		if round > 0 {
+			message = verificationMessage(round, c.MaxVerificationRounds, len(auditSpec.Features), incremental)
+		}
 		c.emitProgress(ProgressEvent{
 			Phase:         PhaseAuditing,
 			Iteration:     iteration,
-			FeaturesTotal: len(spec.Features),
+			FeaturesTotal: inScope,
 			Cost:          totalCost,
-			Message:       fmt.Sprintf("Iteration %d/%d: Auditing codebase against %d features...", iteration, c.MaxIterations, len(spec.Features)),
+			Message:       message,
 		})
 
 		auditClaude := c.createRunner(ctx)
-		gapReport, err := c.auditor.Audit(ctx, spec, c.RepoPath, auditClaude)
+		gapReport, err := c.auditor.Audit(ctx, auditSpec, c.RepoPath, auditClaude)
 		if err != nil {
 			return fmt.Errorf("audit codebase (iteration %d): %w", iteration, err)
 		}
+		if incremental {
+			gapReport = mergeIncrementalAudit(spec, lastReport, gapReport)
+		}
+		lastReport = gapReport
+		c.writeReports(spec, gapReport, iteration)
+		c.gapHistory = append(c.gapHistory, gapReport.Gaps)
+		if c.promptCache != nil {
+			result.PromptCache = c.promptCache.Report()
+			c.emitProgress(ProgressEvent{
+				Phase:     PhaseAuditing,
+				Iteration: iteration,
+				Cost:      totalCost,
+				Message:   promptCacheMessage(result.PromptCache),
+			})
+		}
 
 		// Track tokens from auditing
 		if apiRunner, ok := auditClaude.(*agent.ClaudeAPIAdapter); ok {
@@ -328,48 +774,74 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 
 		// Calculate metrics
 		gapsFound := len(gapReport.Gaps)
-		completedFeatures := 0
-		for _, fs := range gapReport.Features {
-			if fs.Status == AuditStatusComplete {
-				completedFeatures++
-			}
-		}
-		totalFeatures := len(spec.Features)
+		score := ScoreReport(gapReport)
+		completedFeatures := score.Complete
+		totalFeatures := inScope
 		completionPct := 0.0
 		if totalFeatures > 0 {
 			completionPct = float64(completedFeatures) / float64(totalFeatures) * 100.0
 		}
+		if c.Scoring == ScoringWeighted {
+			completionPct = score.WeightedCompletion
+		}
+		lastCompletionPct = completionPct
 
 		// Update controller state for progress events
 		c.currentIteration = iteration
 		c.currentFeaturesTotal = totalFeatures
 		c.currentFeaturesComplete = completedFeatures
+		c.currentCriticalTotal = score.CriticalTotal
 
 		// Determine if progress was made
 		progressMade := lastGapCount < 0 || gapsFound < lastGapCount
 		lastGapCount = gapsFound
 
-		// Get real cost from token tracker
-		totalCost = c.tokenTracker.GetCost()
+		// Get real cost from the usage meter or token tracker
+		totalCost = c.cost()
 		iterationCost := totalCost - lastIterationCost // Delta for this iteration
 		lastIterationCost = totalCost
 
+		// Plan-only mode stops here, before any agent runs
+		if c.PlanOnly {
+			return c.planOnly(ctx, spec, gapReport, iteration)
+		}
+
 		iterResult := IterationResult{
-			Iteration:     iteration,
-			GapsFound:     gapsFound,
-			GapsRemaining: gapsFound,
-			ProgressMade:  progressMade,
-			Cost:          iterationCost,
+			Iteration:         iteration,
+			VerificationRound: round,
+			GapsFound:         gapsFound,
+			GapsRemaining:     gapsFound,
+			ProgressMade:      progressMade,
+			Cost:              iterationCost,
+			Artifacts:         gapReport.Artifacts,
 		}
 
 		// Step 3: Check stop conditions
-		stopReason, shouldStop := c.stopper.Check(iteration, totalCost, completionPct, progressMade)
+		// Verification of the critical features alone completes the run
+		stopPct := completionPct
+		if c.Strictness == StrictnessCritical && score.CriticalTotal > 0 && score.Verified(c.Strictness) {
+			stopPct = 100.0
+		}
+		// Implementation iterations and failed verification rounds have
+		// budgets of their own
+		stopReason, shouldStop := c.stopper.Check(implIterations, totalCost, stopPct, progressMade)
+		if !shouldStop && round > 0 {
+			stopReason, shouldStop = c.stopper.CheckVerification(round)
+		}
 		if shouldStop {
 			result.Iterations = append(result.Iterations, iterResult)
 			result.StopReason = stopReason
 			result.TotalCost = totalCost
 			result.FinalCompletionPct = completionPct
-			return nil
+			c.emitStop(iteration, stopReason, totalCost)
+			if stopReason == StopReasonConverged {
+				result.Convergence = c.reportConvergence(spec, iteration)
+			}
+			if stopReason == StopReasonComplete {
+				c.writeTraceability(spec, gapReport, iteration)
+			}
+			c.publishPullRequest(ctx, spec, gapReport, iteration, stopReason)
+			return c.stopError(stopReason, iteration, completionPct, totalCost)
 		}
 
 		if gapsFound > 0 && c.planner != nil {
@@ -392,10 +864,23 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 			iterResult.EpicID = planResult.EpicID
 			iterResult.TasksCreated = len(planResult.TaskIDs)
 
+			// Hold the planned tasks for approval before any agent runs
+			summary := fmt.Sprintf("Iteration %d: %d task(s) planned in epic %s for %d gap(s)", iteration, len(planResult.TaskIDs), planResult.EpicID, gapsFound)
+			approved, err := c.awaitApproval(ctx, policy.ApprovalDecomposition, summary)
+			if err != nil {
+				return fmt.Errorf("approve plan (iteration %d): %w", iteration, err)
+			}
+			if !approved {
+				result.Iterations = append(result.Iterations, iterResult)
+				return c.stopRejected(iteration, completionPct)
+			}
+
 			// Initialize feature tracking for this iteration
 			c.featureToTasks = make(map[string][]string)
 			c.completedTasks = make(map[string]bool)
 			c.featureGaps = make(map[string]Gap)
+			c.taskFeatures = make(map[string]string)
+			c.taskCosts = make(map[string]*TaskCost)
 
 			// Build feature→task mapping
 			// TaskIDs correspond 1-to-1 with gaps in the order they appear
@@ -406,13 +891,14 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 
 					// Track which tasks belong to this feature
 					c.featureToTasks[featureID] = append(c.featureToTasks[featureID], taskID)
+					c.taskFeatures[taskID] = featureID
 					// Store gap details for later
 					c.featureGaps[featureID] = gap
 				}
 			} else {
 				// Mismatch between tasks and gaps - try to map what we can
-				log.Printf("[architect] WARNING: Task/Gap count mismatch (tasks=%d, gaps=%d), progress tracking may be inaccurate",
-					len(planResult.TaskIDs), len(gapReport.Gaps))
+				archLog.Warn("task and gap counts differ, progress tracking may be inaccurate",
+					"tasks", len(planResult.TaskIDs), "gaps", len(gapReport.Gaps))
 
 				// Build best-effort mapping using min of both lengths
 				minLen := len(planResult.TaskIDs)
@@ -426,10 +912,17 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 					featureID := gap.FeatureID
 
 					c.featureToTasks[featureID] = append(c.featureToTasks[featureID], taskID)
+					c.taskFeatures[taskID] = featureID
 					c.featureGaps[featureID] = gap
 				}
 			}
 
+			// An interrupt during planning stops before any agent runs
+			if c.drainRequested() {
+				result.Iterations = append(result.Iterations, iterResult)
+				return c.stopInterrupted(iteration, completionPct, nil)
+			}
+
 			// Step 5: Execute epics via /alphie skill pattern
 			if planResult.EpicID != "" {
 				c.emitProgress(ProgressEvent{
@@ -444,8 +937,31 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 					Message:          fmt.Sprintf("Iteration %d/%d: Executing epic %s with %d tasks...", iteration, c.MaxIterations, planResult.EpicID, len(planResult.TaskIDs)),
 				})
 
-				completed, err := c.executeEpic(ctx, planResult.EpicID, agents)
-				if err != nil {
+				// Agents still running when a wall-clock limit passes are stopped
+				execCtx, cancelExec := ctx, context.CancelFunc(func() {})
+				if deadline, ok := c.stopper.Deadline(); ok {
+					execCtx, cancelExec = context.WithDeadline(ctx, deadline)
+				}
+				completed, err := c.executeEpic(execCtx, planResult.EpicID, agents)
+				cancelExec()
+				if errors.Is(err, orchestrator.ErrBudgetExceeded) || errors.Is(err, orchestrator.ErrSessionLocked) {
+					return fmt.Errorf("execute epic (iteration %d): %w", iteration, err)
+				}
+				// A drained session has merged what finished; the loop stops with it
+				if c.drainRequested() {
+					iterResult.TasksCompleted = completed
+					result.Iterations = append(result.Iterations, iterResult)
+					return c.stopInterrupted(iteration, completionPct, err)
+				}
+				if err != nil && ctx.Err() == nil && errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+					c.emitProgress(ProgressEvent{
+						Phase:     PhaseExecuting,
+						Iteration: iteration,
+						EpicID:    planResult.EpicID,
+						Cost:      totalCost,
+						Message:   "Time limit reached: stopped the running agents",
+					})
+				} else if err != nil {
 					// Log error but continue to next iteration
 					// Epic execution failures are not fatal to the loop
 					c.emitProgress(ProgressEvent{
@@ -457,10 +973,17 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 					})
 				}
 				iterResult.TasksCompleted = completed
+
+				// Attribute the epic's agent spend to the features it implemented
+				iterResult.TaskCosts = c.iterationTaskCosts()
+				iterResult.FeatureCosts = rollUpFeatureCosts(nil, iterResult.TaskCosts)
+				result.FeatureCosts = rollUpFeatureCosts(result.FeatureCosts, iterResult.TaskCosts)
+				c.taskCosts = nil
 			}
 		}
 
 		result.Iterations = append(result.Iterations, iterResult)
+		verifying = iterResult.TasksCreated > 0 && iterResult.TasksCompleted >= iterResult.TasksCreated
 
 		// Emit iteration complete event
 		c.emitProgress(ProgressEvent{
@@ -489,11 +1012,273 @@ func (c *Controller) Run(ctx context.Context, archDoc string, agents int) error
 				Cost:             totalCost,
 				Message:          "All features implemented!",
 			})
+			c.writeTraceability(spec, gapReport, iteration)
+			c.publishPullRequest(ctx, spec, gapReport, iteration, StopReasonComplete)
 			return nil
 		}
+
+		// A passed wall-clock limit stops the loop without another audit
+		if stopReason, shouldStop := c.stopper.CheckTime(); shouldStop {
+			totalCost = c.cost()
+			result.StopReason = stopReason
+			result.TotalCost = totalCost
+			result.FinalCompletionPct = completionPct
+			c.emitStop(iteration, stopReason, totalCost)
+			c.publishPullRequest(ctx, spec, gapReport, iteration, stopReason)
+			return c.stopError(stopReason, iteration, completionPct, totalCost)
+		}
+
+		// Hold the next iteration for approval
+		summary := fmt.Sprintf("Iteration %d complete: %d/%d features, %d gaps remaining, $%.2f spent; approve to start iteration %d",
+			iteration, completedFeatures, totalFeatures, gapsFound, c.cost(), iteration+1)
+		approved, err := c.awaitApproval(ctx, policy.ApprovalIteration, summary)
+		if err != nil {
+			return fmt.Errorf("approve iteration %d: %w", iteration+1, err)
+		}
+		if !approved {
+			c.publishPullRequest(ctx, spec, gapReport, iteration, StopReasonRejected)
+			return c.stopRejected(iteration, completionPct)
+		}
+	}
+}
+
+// awaitApproval holds the loop at gate until an operator answers it from
+// the TUI or alphie control, whose socket is served while no session is
+// running, and reports whether it was approved. Gates that are not enabled
+// pass. An abort request through the socket returns ErrSessionAborted.
+func (c *Controller) awaitApproval(ctx context.Context, gate, summary string) (bool, error) {
+	if !c.approvals.Enabled(gate) {
+		return true, nil
+	}
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var aborted atomic.Bool
+	server, err := orchestrator.ServeApprovals(c.RepoPath, c.approvals, func() {
+		aborted.Store(true)
+		cancel()
+	})
+	if err != nil {
+		archLog.Warn("control socket unavailable", logging.Err(err))
+	} else {
+		defer server.Close()
+	}
+
+	var req orchestrator.ApprovalRequest
+	verdict, err := c.approvals.Wait(waitCtx, gate, summary, func(r orchestrator.ApprovalRequest) {
+		req = r
+		c.handleOrchestratorEvent(orchestrator.ApprovalEvent(r, nil))
+	})
+	if err != nil {
+		if aborted.Load() && ctx.Err() == nil {
+			return false, orchestrator.ErrSessionAborted
+		}
+		return false, err
+	}
+	c.handleOrchestratorEvent(orchestrator.ApprovalEvent(req, &verdict))
+	archLog.Info("approval answered", "gate", gate, "approved", verdict.Approved, "note", verdict.Note)
+	return verdict.Approved, nil
+}
+
+// stopRejected ends the run after an operator rejected it at a gate in
+// iteration.
+func (c *Controller) stopRejected(iteration int, completionPct float64) error {
+	totalCost := c.cost()
+	c.result.StopReason = StopReasonRejected
+	c.result.TotalCost = totalCost
+	c.result.FinalCompletionPct = completionPct
+	c.emitStop(iteration, StopReasonRejected, totalCost)
+	return c.stopError(StopReasonRejected, iteration, completionPct, totalCost)
+}
+
+// stopError is the error Run returns when the loop stops for reason after
+// iteration: nil once the spec is complete, a BudgetExceededError for the
+// budget, and a *StopError otherwise, so callers can tell the reasons apart.
+func (c *Controller) stopError(reason StopReason, iteration int, completionPct, totalCost float64) error {
+	switch reason {
+	case StopReasonComplete:
+		return nil
+	case StopReasonBudgetExceeded:
+		return &orchestrator.BudgetExceededError{Scope: "session", Spent: totalCost, Limit: c.Budget}
+	default:
+		return &StopError{Reason: reason, Iteration: iteration, CompletionPct: completionPct, Detail: c.describeStop(reason)}
+	}
+}
+
+// emitStop reports why the loop stopped after iteration.
+func (c *Controller) emitStop(iteration int, reason StopReason, cost float64) {
+	c.emitProgress(ProgressEvent{
+		Phase:            PhaseComplete,
+		Iteration:        iteration,
+		FeaturesComplete: c.currentFeaturesComplete,
+		FeaturesTotal:    c.currentFeaturesTotal,
+		Cost:             cost,
+		Message:          fmt.Sprintf("Stopped after iteration %d: %s", iteration, c.describeStop(reason)),
+	})
+}
+
+// describeStop explains a stop reason, naming the critical features when
+// only they had to be complete.
+func (c *Controller) describeStop(reason StopReason) string {
+	if reason == StopReasonComplete && c.Strictness == StrictnessCritical && c.currentCriticalTotal > 0 {
+		return "all critical features complete"
+	}
+	return c.stopper.Describe(reason)
+}
+
+// auditingMessage announces an audit of the in-scope features, noting the
+// deferred ones audited alongside them.
+func auditingMessage(iteration, maxIterations, inScope, deferred int) string {
+	msg := fmt.Sprintf("Iteration %d/%d: Auditing codebase against %d features", iteration, maxIterations, inScope)
+	if deferred > 0 {
+		msg += fmt.Sprintf(" (%d deferred)", deferred)
+	}
+	return msg + "..."
+}
+
+// verificationMessage is the progress message of a verification round's
+// audit.
+func verificationMessage(round, maxRounds, features int, incremental bool) string {
+	limit := "unlimited"
+	if maxRounds > 0 {
+		limit = fmt.Sprintf("%d", maxRounds)
+	}
+	msg := fmt.Sprintf("Verification round %d/%s: Re-auditing %d features", round, limit, features)
+	if incremental {
+		msg += " that had gaps"
+	}
+	return msg + "..."
+}
+
+// planOnly builds the execution plan for the audit without writing it to
+// prog or executing it.
+func (c *Controller) planOnly(ctx context.Context, spec *ArchSpec, gapReport *GapReport, iteration int) error {
+	planner := c.planner
+	if planner == nil {
+		planner = NewPlanner(nil)
+	}
+
+	c.emitProgress(ProgressEvent{
+		Phase:     PhasePlanning,
+		Iteration: iteration,
+		GapsFound: len(gapReport.Gaps),
+		Cost:      c.cost(),
+		Message:   fmt.Sprintf("Planning tasks for %d gaps (plan only)...", len(gapReport.Gaps)),
+	})
+
+	planClaude := c.createRunner(ctx)
+	plan := planner.BuildPlan(ctx, gapReport, planClaude)
+
+	// Track tokens from planning
+	if apiRunner, ok := planClaude.(*agent.ClaudeAPIAdapter); ok {
+		apiClient := apiRunner.Client()
+		if apiClient != nil {
+			input, output := apiClient.Tracker().Total()
+			c.tokenTracker.Update(agent.MessageDeltaUsage{
+				InputTokens:  input,
+				OutputTokens: output,
+			})
+		}
+	}
+
+	c.executionPlan = NewExecutionPlan(spec, gapReport, plan)
+	c.executionPlan.PlanningCost = c.cost()
+
+	c.emitProgress(ProgressEvent{
+		Phase:            PhaseComplete,
+		Iteration:        iteration,
+		FeaturesComplete: c.executionPlan.FeaturesComplete,
+		FeaturesTotal:    c.executionPlan.FeaturesTotal,
+		GapsFound:        len(gapReport.Gaps),
+		TasksCreated:     len(c.executionPlan.Tasks),
+		Cost:             c.executionPlan.PlanningCost,
+		Message:          fmt.Sprintf("Plan ready: %d tasks, estimated $%.2f", len(c.executionPlan.Tasks), c.executionPlan.TotalCost()),
+	})
+	return nil
+}
+
+// Result returns the outcome of the current or last Run, or nil if Run
+// has not been called.
+func (c *Controller) Result() *RunResult {
+	return c.result
+}
+
+// ExecutionPlan returns the plan built by a PlanOnly run, or nil.
+func (c *Controller) ExecutionPlan() *ExecutionPlan {
+	return c.executionPlan
+}
+
+// writeReports writes the audit reports for stakeholders who don't follow
+// the TUI. Failures are logged rather than stopping the loop.
+func (c *Controller) writeReports(spec *ArchSpec, report *GapReport, iteration int) {
+	if c.ReportDir == "" {
+		return
+	}
+	meta := ReportMeta{
+		SpecName:    spec.Name,
+		Iteration:   iteration,
+		GeneratedAt: time.Now(),
+	}
+	if c.result != nil {
+		meta.FeatureCosts = c.result.FeatureCosts
+	}
+	if err := WriteReports(c.ReportDir, report, meta); err != nil {
+		archLog.Warn("failed to write audit report", logging.Err(err))
+	}
+}
+
+// Drain starts a graceful shutdown: no further iteration, audit or epic
+// starts, and the agents of the epic being executed get up to grace to
+// finish and merge their work, as with Orchestrator.Drain. Run then
+// returns an error matching orchestrator.ErrSessionInterrupted, wrapping
+// the session's *orchestrator.SessionInterruptedError if an epic was
+// running. Drain returns immediately; calling it again has no effect.
+func (c *Controller) Drain(grace time.Duration) {
+	c.drainMu.Lock()
+	defer c.drainMu.Unlock()
+	if c.draining {
+		return
+	}
+	c.draining = true
+	c.drainGrace = grace
+	if c.session != nil {
+		c.session.Drain(grace)
+	}
+}
+
+// drainRequested reports whether Drain was called.
+func (c *Controller) drainRequested() bool {
+	c.drainMu.Lock()
+	defer c.drainMu.Unlock()
+	return c.draining
+}
+
+// setSession records orch as the session being executed, draining it at
+// once if Drain was called while it was being created.
+func (c *Controller) setSession(orch *orchestrator.Orchestrator) {
+	c.drainMu.Lock()
+	defer c.drainMu.Unlock()
+	c.session = orch
+	if orch != nil && c.draining {
+		orch.Drain(c.drainGrace)
 	}
 }
 
+// stopInterrupted ends the run after Drain, in iteration. sessionErr is
+// what the iteration's epic returned; a drained session's checkpoint is
+// passed on rather than a bare StopError.
+func (c *Controller) stopInterrupted(iteration int, completionPct float64, sessionErr error) error {
+	totalCost := c.cost()
+	c.result.StopReason = StopReasonInterrupted
+	c.result.TotalCost = totalCost
+	c.result.FinalCompletionPct = completionPct
+	c.emitStop(iteration, StopReasonInterrupted, totalCost)
+	var interrupted *orchestrator.SessionInterruptedError
+	if errors.As(sessionErr, &interrupted) {
+		return fmt.Errorf("execute epic (iteration %d): %w", iteration, sessionErr)
+	}
+	return c.stopError(StopReasonInterrupted, iteration, completionPct, totalCost)
+}
+
 // executeEpic runs the orchestrator directly to execute an epic's tasks.
 // It streams progress events to the TUI and tracks worker state in real-time.
 // Returns the number of tasks completed and any error.
@@ -504,6 +1289,8 @@ func (c *Controller) executeEpic(ctx context.Context, epicID string, agents int)
 		return 0, fmt.Errorf("create orchestrator: %w", err)
 	}
 	defer orch.Stop()
+	c.setSession(orch)
+	defer c.setSession(nil)
 
 	// Subscribe to events for progress updates
 	eventsCh := orch.Events()
@@ -520,7 +1307,8 @@ func (c *Controller) executeEpic(ctx context.Context, epicID string, agents int)
 	// Run orchestrator (empty request since we're resuming an epic)
 	err = orch.Run(ctx, "")
 
-	// Wait for event processing to complete
+	// Stop closes the events channel; wait for event processing to complete
+	_ = orch.Stop()
 	<-eventsDone
 
 	if err != nil {
@@ -543,6 +1331,47 @@ func (c *Controller) executeEpic(ctx context.Context, epicID string, agents int)
 	return 0, nil
 }
 
+// captureBaseline captures the build, test and lint state of the starting
+// commit. Without one, each iteration's session captures its own from a
+// repository earlier iterations already changed.
+func (c *Controller) captureBaseline() {
+	c.emitProgress(ProgressEvent{
+		Phase:   PhaseBaseline,
+		Message: "Capturing build and test baseline...",
+	})
+	baseline, err := agent.CaptureBaseline(c.RepoPath)
+	if err != nil {
+		return
+	}
+	c.baseline = baseline
+	c.auditor.SetBaseline(baseline)
+}
+
+// sessionPolicyConfig returns the policy of the next session: the
+// configured policy carrying the review rubric and the remaining implement
+// budget, so the session can stop mid-epic instead of overshooting until
+// the next stop check.
+func (c *Controller) sessionPolicyConfig() *policy.Config {
+	policyConfig := policy.Default()
+	if c.sessionPolicy != nil {
+		p := *c.sessionPolicy
+		policyConfig = &p
+	}
+	if len(c.reviewRubric) > 0 {
+		policyConfig.Review.Rubric = c.reviewRubric
+	}
+	if c.Budget > 0 {
+		remaining := c.Budget - c.cost()
+		if remaining <= 0 {
+			remaining = 0.01
+		}
+		if limit := policyConfig.Budget.SessionLimit; limit <= 0 || remaining < limit {
+			policyConfig.Budget.SessionLimit = remaining
+		}
+	}
+	return policyConfig
+}
+
 // createOrchestrator creates a new orchestrator instance for epic execution.
 func (c *Controller) createOrchestrator(epicID string, agents int) (*orchestrator.Orchestrator, error) {
 	// Open state database
@@ -559,9 +1388,11 @@ func (c *Controller) createOrchestrator(epicID string, agents int) (*orchestrato
 
 	// Create executor
 	executor, err := agent.NewExecutor(agent.ExecutorConfig{
-		RepoPath:      c.RepoPath,
-		Model:         "sonnet",
-		RunnerFactory: c.runnerFactory,
+		WorktreeBaseDir: c.worktreeDir,
+		RepoPath:        c.RepoPath,
+		Model:           "sonnet",
+		RunnerFactory:   c.runnerFactory,
+		CommitIdentity:  c.CommitIdentity,
 	})
 	if err != nil {
 		db.Close()
@@ -574,20 +1405,46 @@ func (c *Controller) createOrchestrator(epicID string, agents int) (*orchestrato
 	secondReviewerClaude := c.runnerFactory.NewRunner()
 
 	// Create orchestrator with all required dependencies
-	orch := orchestrator.New(
-		orchestrator.RequiredConfig{
-			RepoPath: c.RepoPath,
-			Tier:     models.TierBuilder,
-			Executor: executor,
-		},
+	opts := []orchestrator.Option{
 		orchestrator.WithMaxAgents(agents),
 		orchestrator.WithDecomposerClaude(decomposerClaude),
 		orchestrator.WithMergerClaude(mergerClaude),
 		orchestrator.WithSecondReviewerClaude(secondReviewerClaude),
 		orchestrator.WithRunnerFactory(c.runnerFactory),
+		orchestrator.WithRateLimiter(agent.RateLimiterOf(c.runnerFactory)),
 		orchestrator.WithStateDB(db),
 		orchestrator.WithProgClient(c.progClient),
 		orchestrator.WithResumeEpicID(epicID),
+		orchestrator.WithPolicy(c.sessionPolicyConfig()),
+		orchestrator.WithGreenfield(c.Greenfield),
+		orchestrator.WithBaseline(c.baseline),
+		orchestrator.WithCommitIdentity(c.CommitIdentity),
+	}
+	if c.approvals != nil {
+		opts = append(opts, orchestrator.WithApprovalGates(c.approvals))
+	}
+	if c.protectedAreas != nil {
+		opts = append(opts, orchestrator.WithProtectedAreaChecker(c.protectedAreas))
+	}
+	if c.tierConfigs != nil {
+		opts = append(opts, orchestrator.WithTierConfigs(c.tierConfigs))
+	}
+	if len(c.sandboxAllowlist) > 0 {
+		opts = append(opts, orchestrator.WithSandboxAllowlist(c.sandboxAllowlist))
+	}
+	// Epics merge into the pull request branch rather than the base branch
+	if c.prBranch != "" {
+		opts = append(opts, orchestrator.WithMainBranch(c.prBranch))
+	} else if c.BaseBranch != "" {
+		opts = append(opts, orchestrator.WithMainBranch(c.BaseBranch))
+	}
+	orch := orchestrator.New(
+		orchestrator.RequiredConfig{
+			RepoPath: c.RepoPath,
+			Tier:     models.TierBuilder,
+			Executor: executor,
+		},
+		opts...,
 	)
 
 	return orch, nil
@@ -599,10 +1456,12 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 	case orchestrator.EventTaskStarted:
 		// Track active worker
 		c.activeWorkers[event.AgentID] = WorkerInfo{
-			AgentID:   event.AgentID,
-			TaskID:    event.TaskID,
-			TaskTitle: event.TaskTitle,
-			Status:    "running",
+			AgentID:          event.AgentID,
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
+			Status:           "running",
+			QuestionsAsked:   event.QuestionsAsked,
+			QuestionsAllowed: event.QuestionsAllowed,
 		}
 
 		c.emitProgress(ProgressEvent{
@@ -612,6 +1471,9 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 			FeaturesComplete: c.currentFeaturesComplete,
 			FeaturesTotal:    c.currentFeaturesTotal,
 			Message:          fmt.Sprintf("Started: %s", event.TaskTitle),
+			EventType:        string(event.Type),
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
 			Cost:             event.Cost,
 			WorkersRunning:   event.WorkersRunning,
 			WorkersBlocked:   event.WorkersBlocked,
@@ -623,6 +1485,7 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 
 		// Update feature completion tracking
 		c.updateFeatureCompletion(event.TaskID)
+		c.recordTraceTask(event)
 
 		c.emitProgress(ProgressEvent{
 			Phase:            PhaseExecuting,
@@ -631,6 +1494,9 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 			FeaturesComplete: c.currentFeaturesComplete,
 			FeaturesTotal:    c.currentFeaturesTotal,
 			Message:          fmt.Sprintf("Completed: %s", event.TaskTitle),
+			EventType:        string(event.Type),
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
 			Cost:             event.Cost,
 			WorkersRunning:   event.WorkersRunning,
 			WorkersBlocked:   event.WorkersBlocked,
@@ -639,6 +1505,7 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 	case orchestrator.EventTaskFailed:
 		// Remove from active workers
 		delete(c.activeWorkers, event.AgentID)
+		c.recordTaskFailure(event)
 
 		c.emitProgress(ProgressEvent{
 			Phase:            PhaseExecuting,
@@ -647,12 +1514,93 @@ func (c *Controller) handleOrchestratorEvent(event orchestrator.OrchestratorEven
 			FeaturesComplete: c.currentFeaturesComplete,
 			FeaturesTotal:    c.currentFeaturesTotal,
 			Message:          fmt.Sprintf("Failed: %s", event.TaskTitle),
+			EventType:        string(event.Type),
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
 			Cost:             event.Cost,
 			WorkersRunning:   event.WorkersRunning,
 			WorkersBlocked:   event.WorkersBlocked,
 			ActiveWorkers:    c.cloneActiveWorkers(),
 		})
+	case orchestrator.EventSecondReviewCompleted:
+		c.recordReviewConcerns(event)
+	case orchestrator.EventTaskUsage:
+		c.recordTaskUsage(event)
+	case orchestrator.EventCostEstimate:
+		c.emitProgress(ProgressEvent{
+			Phase:            PhaseExecuting,
+			Iteration:        c.currentIteration,
+			MaxIterations:    c.MaxIterations,
+			FeaturesComplete: c.currentFeaturesComplete,
+			FeaturesTotal:    c.currentFeaturesTotal,
+			Message:          event.Message,
+			EventType:        string(event.Type),
+			Estimate:         event.Estimate,
+			ActiveWorkers:    c.cloneActiveWorkers(),
+		})
+	case orchestrator.EventBudgetWarning, orchestrator.EventBudgetExceeded, orchestrator.EventResourceLow, orchestrator.EventResourceRecovered,
+		orchestrator.EventApprovalRequired, orchestrator.EventApprovalResolved:
+		c.emitProgress(ProgressEvent{
+			Phase:            PhaseExecuting,
+			Iteration:        c.currentIteration,
+			MaxIterations:    c.MaxIterations,
+			FeaturesComplete: c.currentFeaturesComplete,
+			FeaturesTotal:    c.currentFeaturesTotal,
+			Message:          event.Message,
+			EventType:        string(event.Type),
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
+			Cost:             event.Cost,
+			ActiveWorkers:    c.cloneActiveWorkers(),
+		})
+	case orchestrator.EventMergeQueued, orchestrator.EventMergeStarted:
+		status := "merging"
+		if event.Type == orchestrator.EventMergeQueued {
+			status = strings.ToLower(orchestrator.MergeWaitMessage(event.MergeQueueAhead))
+		}
+		if worker, ok := c.activeWorkers[event.AgentID]; ok && worker.Status != status {
+			worker.Status = status
+			c.activeWorkers[event.AgentID] = worker
+			c.emitProgress(ProgressEvent{
+				Phase:            PhaseExecuting,
+				Iteration:        c.currentIteration,
+				MaxIterations:    c.MaxIterations,
+				FeaturesComplete: c.currentFeaturesComplete,
+				FeaturesTotal:    c.currentFeaturesTotal,
+				Message:          fmt.Sprintf("%s: %s", worker.TaskTitle, event.Message),
+				EventType:        string(event.Type),
+				TaskID:           event.TaskID,
+				TaskTitle:        worker.TaskTitle,
+				ActiveWorkers:    c.cloneActiveWorkers(),
+			})
+		}
+	case orchestrator.EventQuestionAsked, orchestrator.EventQuestionsExhausted:
+		if worker, ok := c.activeWorkers[event.AgentID]; ok {
+			worker.QuestionsAsked = event.QuestionsAsked
+			worker.QuestionsAllowed = event.QuestionsAllowed
+			c.activeWorkers[event.AgentID] = worker
+		}
+		message := event.Message
+		if event.Type == orchestrator.EventQuestionAsked {
+			message = fmt.Sprintf("Question from %s: %s", event.TaskTitle, event.Message)
+		}
+		c.emitProgress(ProgressEvent{
+			Phase:            PhaseExecuting,
+			Iteration:        c.currentIteration,
+			MaxIterations:    c.MaxIterations,
+			FeaturesComplete: c.currentFeaturesComplete,
+			FeaturesTotal:    c.currentFeaturesTotal,
+			Message:          message,
+			EventType:        string(event.Type),
+			TaskID:           event.TaskID,
+			TaskTitle:        event.TaskTitle,
+			ActiveWorkers:    c.cloneActiveWorkers(),
+		})
 	case orchestrator.EventAgentProgress:
+		if worker, ok := c.activeWorkers[event.AgentID]; ok && event.LogFile != "" && worker.LogFile != event.LogFile {
+			worker.LogFile = event.LogFile
+			c.activeWorkers[event.AgentID] = worker
+		}
 		if event.CurrentAction != "" {
 			c.emitProgress(ProgressEvent{
 				Phase:            PhaseExecuting,
@@ -719,8 +1667,8 @@ func (c *Controller) updateFeatureCompletion(taskID string) {
 			// Remove the feature from tracking so we don't count it again
 			delete(c.featureToTasks, featureID)
 			c.currentFeaturesComplete++
-			log.Printf("[architect] Feature %s completed, total features complete: %d/%d",
-				featureID, c.currentFeaturesComplete, c.currentFeaturesTotal)
+			archLog.Info("feature completed",
+				"feature", featureID, "complete", c.currentFeaturesComplete, "total", c.currentFeaturesTotal)
 
 			// Emit progress event to update TUI
 			c.emitProgress(ProgressEvent{
@@ -730,7 +1678,7 @@ func (c *Controller) updateFeatureCompletion(taskID string) {
 				FeaturesComplete: c.currentFeaturesComplete,
 				FeaturesTotal:    c.currentFeaturesTotal,
 				Message:          fmt.Sprintf("Feature completed: %s (%d/%d)", featureID, c.currentFeaturesComplete, c.currentFeaturesTotal),
-				Cost:             c.tokenTracker.GetCost(),
+				Cost:             c.cost(),
 			})
 		}
 	}
@@ -2,7 +2,12 @@ package architect
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 )
 
 func TestNewController(t *testing.T) {
@@ -42,6 +47,31 @@ func TestNewController_WithOptions(t *testing.T) {
 	}
 }
 
+func TestController_SessionPolicyConfig(t *testing.T) {
+	p := policy.Default()
+	p.Scheduling.Preemption = true
+	p.Budget.TaskLimit = 0.5
+	p.Budget.SessionLimit = 5.0
+
+	c := NewController(5, 2.0, 2, WithPolicy(p))
+	got := c.sessionPolicyConfig()
+	if !got.Scheduling.Preemption || got.Budget.TaskLimit != 0.5 {
+		t.Errorf("expected the configured policy, got %+v", got.Scheduling)
+	}
+	// The implement budget is tighter than the configured session limit
+	if got.Budget.SessionLimit != 2.0 {
+		t.Errorf("SessionLimit = %v, want the remaining implement budget 2.0", got.Budget.SessionLimit)
+	}
+	if p.Budget.SessionLimit != 5.0 {
+		t.Error("expected the configured policy to be left unchanged")
+	}
+
+	// Without an implement budget the configured limit stands
+	if got := NewController(5, 0, 2, WithPolicy(p)).sessionPolicyConfig(); got.Budget.SessionLimit != 5.0 {
+		t.Errorf("SessionLimit = %v, want 5.0", got.Budget.SessionLimit)
+	}
+}
+
 func TestController_RunContextCanceled(t *testing.T) {
 	c := NewController(10, 5.0, 3,
 		WithRepoPath("/nonexistent"),
@@ -57,6 +87,20 @@ func TestController_RunContextCanceled(t *testing.T) {
 	}
 }
 
+func TestController_RunDrained(t *testing.T) {
+	c := NewController(10, 5.0, 3, WithRepoPath(t.TempDir()))
+
+	// A drain before the first iteration stops without parsing the spec
+	c.Drain(time.Minute)
+	err := c.Run(context.Background(), "nonexistent.md", 1)
+	if !errors.Is(err, orchestrator.ErrSessionInterrupted) {
+		t.Fatalf("expected ErrSessionInterrupted, got %v", err)
+	}
+	if c.Result().StopReason != StopReasonInterrupted {
+		t.Errorf("StopReason = %q, want %q", c.Result().StopReason, StopReasonInterrupted)
+	}
+}
+
 func TestIterationResult_Fields(t *testing.T) {
 	result := IterationResult{
 		Iteration:      1,
@@ -159,3 +203,38 @@ func TestController_StopperConfig(t *testing.T) {
 		t.Errorf("expected stop after 2 no-progress iterations, got stop=%v, reason=%s", stop, reason)
 	}
 }
+
+func TestController_StopError(t *testing.T) {
+	c := NewController(5, 10.0, 3)
+
+	if err := c.stopError(StopReasonComplete, 2, 100, 1); err != nil {
+		t.Errorf("expected a complete run to return nil, got %v", err)
+	}
+	if err := c.stopError(StopReasonBudgetExceeded, 2, 40, 12); !errors.Is(err, orchestrator.ErrBudgetExceeded) {
+		t.Errorf("expected a budget stop to match ErrBudgetExceeded, got %v", err)
+	}
+
+	tests := []struct {
+		reason StopReason
+		want   error
+	}{
+		{StopReasonMaxIterations, ErrMaxIterations},
+		{StopReasonMaxVerificationRounds, orchestrator.ErrVerificationFailed},
+		{StopReasonConverged, ErrNoProgress},
+		{StopReasonDeadline, ErrDeadline},
+		{StopReasonIterationTimeout, ErrIterationTimeout},
+		{StopReasonRejected, orchestrator.ErrApprovalRejected},
+		{StopReasonInterrupted, orchestrator.ErrSessionInterrupted},
+	}
+	for _, tt := range tests {
+		err := c.stopError(tt.reason, 5, 60, 1)
+		var stopErr *StopError
+		if !errors.As(err, &stopErr) || stopErr.Reason != tt.reason {
+			t.Errorf("stopError(%s) = %v, want a StopError", tt.reason, err)
+			continue
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("stopError(%s) does not match %v", tt.reason, tt.want)
+		}
+	}
+}
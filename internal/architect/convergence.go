@@ -0,0 +1,408 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// Convergence report file names written by WriteConvergence.
+const (
+	convergenceJSONFile     = "convergence.json"
+	convergenceMarkdownFile = "convergence.md"
+)
+
+// maxFailureMessages caps the failure messages kept per repeated failure.
+const maxFailureMessages = 3
+
+// Suspected root causes of a stalled loop.
+const (
+	CauseVerification   = "verification"
+	CauseMergeConflict  = "merge_conflict"
+	CausePostMergeBuild = "post_merge_build"
+	CauseProtectedArea  = "protected_area"
+	CauseEscalated      = "escalated"
+	CauseTimeout        = "timeout"
+	CauseExecution      = "execution"
+	// CauseUnresolvedGap is a gap whose tasks completed, yet the audit
+	// still reports it.
+	CauseUnresolvedGap = "unresolved_gap"
+)
+
+// causeInterventions is the human intervention recommended for each cause.
+var causeInterventions = map[string]string{
+	CauseVerification:   "Run the failing checks locally and fix or clarify them; if the spec asks for behavior the tests contradict, amend the spec or the tests.",
+	CauseMergeConflict:  "Resolve the conflicting changes by hand, or split the feature so parallel tasks stop touching the same files (run with fewer --agents).",
+	CausePostMergeBuild: "Fix the build on the session branch: tasks pass on their own but break once merged together.",
+	CauseProtectedArea:  "Make the protected-area change yourself, or relax the protected_areas policy for this spec.",
+	CauseEscalated:      "Answer the open escalations (alphie escalations) so the parked tasks can resume.",
+	CauseTimeout:        "Split the long-running tasks into smaller ones, or raise the task timeout.",
+	CauseExecution:      "Inspect the agent logs of the failed tasks for crashes or tool errors.",
+	CauseUnresolvedGap:  "Tasks finish but the audit still reports the gap: make the acceptance criteria concrete and checkable, or implement the remaining part by hand.",
+}
+
+// TaskFailure is a failed task of the loop.
+type TaskFailure struct {
+	TaskID    string `json:"task_id"`
+	Title     string `json:"title"`
+	FeatureID string `json:"feature_id,omitempty"`
+	Iteration int    `json:"iteration"`
+	// Cause is the suspected root cause, one of the Cause constants.
+	Cause string `json:"cause"`
+	// Message is the failure's summary, including validation results.
+	Message string `json:"message,omitempty"`
+}
+
+// PersistentGap is a gap the audits kept reporting across iterations.
+type PersistentGap struct {
+	Gap
+	// Iterations lists the consecutive iterations, ending with the last
+	// one, whose audits reported the gap.
+	Iterations []int `json:"iterations"`
+}
+
+// RepeatedFailure groups the failures of a feature's tasks, or of a task
+// that could not be attributed to a feature, that failed more than once.
+type RepeatedFailure struct {
+	FeatureID string   `json:"feature_id,omitempty"`
+	Titles    []string `json:"titles"`
+	Failures  int      `json:"failures"`
+	// Causes counts the failures per suspected root cause.
+	Causes map[string]int `json:"causes"`
+	// Messages are the most recent distinct failure messages.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// SuspectedCause is a root cause behind the stalled loop with the
+// features it affects and the intervention recommended for it.
+type SuspectedCause struct {
+	Cause        string   `json:"cause"`
+	Occurrences  int      `json:"occurrences"`
+	Features     []string `json:"features,omitempty"`
+	Intervention string   `json:"intervention"`
+}
+
+// ConvergenceReport explains why the loop stopped making progress.
+type ConvergenceReport struct {
+	SpecName    string    `json:"spec_name,omitempty"`
+	Iterations  int       `json:"iterations"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// NoProgressIterations is how many iterations in a row closed no gap.
+	NoProgressIterations int               `json:"no_progress_iterations"`
+	PersistentGaps       []PersistentGap   `json:"persistent_gaps,omitempty"`
+	RepeatedFailures     []RepeatedFailure `json:"repeated_failures,omitempty"`
+	// SuspectedCauses are ordered by occurrences, most frequent first.
+	SuspectedCauses []SuspectedCause `json:"suspected_causes,omitempty"`
+}
+
+// Summary describes the report in one line.
+func (r *ConvergenceReport) Summary() string {
+	s := fmt.Sprintf("No progress for %d iterations: %d gap(s) persisted, %d task group(s) failed repeatedly",
+		r.NoProgressIterations, len(r.PersistentGaps), len(r.RepeatedFailures))
+	if len(r.SuspectedCauses) > 0 {
+		s += fmt.Sprintf("; most likely cause: %s", r.SuspectedCauses[0].Cause)
+	}
+	return s
+}
+
+// BuildConvergenceReport analyzes a stalled loop from the gaps each
+// iteration's audit reported (audits[i] is iteration i+1) and the tasks
+// that failed along the way.
+func BuildConvergenceReport(audits [][]Gap, failures []TaskFailure, noProgress int, meta ReportMeta) *ConvergenceReport {
+	report := &ConvergenceReport{
+		SpecName:             meta.SpecName,
+		Iterations:           meta.Iteration,
+		GeneratedAt:          meta.GeneratedAt,
+		NoProgressIterations: noProgress,
+	}
+	report.PersistentGaps = persistentGaps(audits)
+	report.RepeatedFailures = repeatedFailures(failures)
+
+	// Causes of the failures, and of gaps that persist without any failure
+	causes := make(map[string]*SuspectedCause)
+	addCause := func(cause, featureID string) {
+		sc, ok := causes[cause]
+		if !ok {
+			sc = &SuspectedCause{Cause: cause, Intervention: causeInterventions[cause]}
+			causes[cause] = sc
+		}
+		sc.Occurrences++
+		if featureID != "" && !slices.Contains(sc.Features, featureID) {
+			sc.Features = append(sc.Features, featureID)
+		}
+	}
+	failed := make(map[string]bool)
+	for _, f := range failures {
+		addCause(f.Cause, f.FeatureID)
+		failed[f.FeatureID] = true
+	}
+	for _, g := range report.PersistentGaps {
+		if !failed[g.FeatureID] {
+			addCause(CauseUnresolvedGap, g.FeatureID)
+		}
+	}
+	for _, sc := range causes {
+		sort.Strings(sc.Features)
+		report.SuspectedCauses = append(report.SuspectedCauses, *sc)
+	}
+	sort.Slice(report.SuspectedCauses, func(i, j int) bool {
+		a, b := report.SuspectedCauses[i], report.SuspectedCauses[j]
+		if a.Occurrences != b.Occurrences {
+			return a.Occurrences > b.Occurrences
+		}
+		return a.Cause < b.Cause
+	})
+	return report
+}
+
+// persistentGaps returns the gaps of the last audit that earlier audits
+// reported too, longest-standing first.
+func persistentGaps(audits [][]Gap) []PersistentGap {
+	if len(audits) < 2 {
+		return nil
+	}
+	last := len(audits) - 1
+	var gaps []PersistentGap
+	for _, gap := range audits[last] {
+		pg := PersistentGap{Gap: gap, Iterations: []int{last + 1}}
+		for i := last - 1; i >= 0 && hasGapFor(audits[i], gap.FeatureID); i-- {
+			pg.Iterations = append([]int{i + 1}, pg.Iterations...)
+		}
+		if len(pg.Iterations) > 1 {
+			gaps = append(gaps, pg)
+		}
+	}
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if len(gaps[i].Iterations) != len(gaps[j].Iterations) {
+			return len(gaps[i].Iterations) > len(gaps[j].Iterations)
+		}
+		return gaps[i].Critical && !gaps[j].Critical
+	})
+	return gaps
+}
+
+// hasGapFor reports whether gaps include one for featureID.
+func hasGapFor(gaps []Gap, featureID string) bool {
+	for _, g := range gaps {
+		if g.FeatureID == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatedFailures groups failures by feature, or by title for tasks not
+// attributed to one, keeping the groups that failed more than once, most
+// failures first.
+func repeatedFailures(failures []TaskFailure) []RepeatedFailure {
+	groups := make(map[string]*RepeatedFailure)
+	var order []string
+	for _, f := range failures {
+		key := "feature:" + f.FeatureID
+		if f.FeatureID == "" {
+			key = "task:" + strings.ToLower(strings.TrimSpace(f.Title))
+		}
+		rf, ok := groups[key]
+		if !ok {
+			rf = &RepeatedFailure{FeatureID: f.FeatureID, Causes: make(map[string]int)}
+			groups[key] = rf
+			order = append(order, key)
+		}
+		rf.Failures++
+		rf.Causes[f.Cause]++
+		if f.Title != "" && !slices.Contains(rf.Titles, f.Title) {
+			rf.Titles = append(rf.Titles, f.Title)
+		}
+		if f.Message != "" && !slices.Contains(rf.Messages, f.Message) {
+			rf.Messages = append(rf.Messages, f.Message)
+			if len(rf.Messages) > maxFailureMessages {
+				rf.Messages = rf.Messages[1:]
+			}
+		}
+	}
+
+	var repeated []RepeatedFailure
+	for _, key := range order {
+		if rf := groups[key]; rf.Failures > 1 {
+			repeated = append(repeated, *rf)
+		}
+	}
+	sort.SliceStable(repeated, func(i, j int) bool { return repeated[i].Failures > repeated[j].Failures })
+	return repeated
+}
+
+// failureCause classifies a task_failed event's suspected root cause.
+func failureCause(event orchestrator.OrchestratorEvent) string {
+	err := event.Error
+	switch {
+	case errors.Is(err, orchestrator.ErrTaskEscalated):
+		return CauseEscalated
+	case errors.Is(err, orchestrator.ErrProtectedAreaBlocked):
+		return CauseProtectedArea
+	case errors.Is(err, orchestrator.ErrMergeNeedsHuman), errors.Is(err, orchestrator.ErrMergeQuarantined):
+		return CauseMergeConflict
+	}
+
+	text := strings.ToLower(event.Message)
+	if err != nil {
+		text += " " + strings.ToLower(err.Error())
+	}
+	switch {
+	case strings.Contains(text, "post-merge"):
+		return CausePostMergeBuild
+	case errors.Is(err, orchestrator.ErrVerificationFailed):
+		return CauseVerification
+	case strings.Contains(text, "merge"), strings.Contains(text, "conflict"):
+		return CauseMergeConflict
+	case strings.Contains(text, "verification"):
+		return CauseVerification
+	case strings.Contains(text, "timeout"), strings.Contains(text, "timed out"), strings.Contains(text, "deadline exceeded"):
+		return CauseTimeout
+	default:
+		return CauseExecution
+	}
+}
+
+// WriteConvergenceMarkdown renders the convergence report as Markdown.
+func WriteConvergenceMarkdown(w io.Writer, report *ConvergenceReport) error {
+	var b strings.Builder
+
+	title := "Convergence Report"
+	if report.SpecName != "" {
+		title += ": " + report.SpecName
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "%s.\n\n", report.Summary())
+	if report.Iterations > 0 {
+		fmt.Fprintf(&b, "- **Iterations:** %d\n", report.Iterations)
+	}
+	if !report.GeneratedAt.IsZero() {
+		fmt.Fprintf(&b, "- **Generated:** %s\n", report.GeneratedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+
+	if len(report.SuspectedCauses) > 0 {
+		b.WriteString("## Recommended Interventions\n\n")
+		for i, sc := range report.SuspectedCauses {
+			fmt.Fprintf(&b, "%d. **%s** (%d)", i+1, sc.Cause, sc.Occurrences)
+			if len(sc.Features) > 0 {
+				fmt.Fprintf(&b, " — %s", strings.Join(sc.Features, ", "))
+			}
+			fmt.Fprintf(&b, "\n   %s\n", sc.Intervention)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.PersistentGaps) > 0 {
+		b.WriteString("## Persistent Gaps\n\n")
+		b.WriteString("| Feature | Status | Iterations | Gap | Suggested Action |\n|---|---|---|---|---|\n")
+		for _, g := range report.PersistentGaps {
+			iterations := make([]string, len(g.Iterations))
+			for i, it := range g.Iterations {
+				iterations[i] = fmt.Sprint(it)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", markdownCell(g.FeatureID), g.Status,
+				strings.Join(iterations, ", "), markdownCell(g.Description), markdownCell(g.SuggestedAction))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.RepeatedFailures) > 0 {
+		b.WriteString("## Repeatedly Failing Tasks\n\n")
+		for _, rf := range report.RepeatedFailures {
+			label := rf.FeatureID
+			if label == "" {
+				label = "Unattributed"
+			}
+			causes := make([]string, 0, len(rf.Causes))
+			for cause, n := range rf.Causes {
+				causes = append(causes, fmt.Sprintf("%s ×%d", cause, n))
+			}
+			sort.Strings(causes)
+			fmt.Fprintf(&b, "### %s — %d failures (%s)\n\n", label, rf.Failures, strings.Join(causes, ", "))
+			for _, title := range rf.Titles {
+				fmt.Fprintf(&b, "- %s\n", title)
+			}
+			b.WriteString("\n")
+			for _, msg := range rf.Messages {
+				fmt.Fprintf(&b, "> %s\n\n", strings.Join(strings.Fields(msg), " "))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteConvergence writes the JSON and Markdown renderings of the
+// convergence report into dir.
+func WriteConvergence(dir string, report *ConvergenceReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create report directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("render %s: %w", convergenceJSONFile, err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, convergenceJSONFile), append(data, '\n')); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteConvergenceMarkdown(&buf, report); err != nil {
+		return fmt.Errorf("render %s: %w", convergenceMarkdownFile, err)
+	}
+	return writeFileAtomic(filepath.Join(dir, convergenceMarkdownFile), buf.Bytes())
+}
+
+// recordTaskFailure records a failed task for the convergence report.
+func (c *Controller) recordTaskFailure(event orchestrator.OrchestratorEvent) {
+	c.taskFailures = append(c.taskFailures, TaskFailure{
+		TaskID:    event.TaskID,
+		Title:     event.TaskTitle,
+		FeatureID: c.taskFeatures[event.TaskID],
+		Iteration: c.currentIteration,
+		Cause:     failureCause(event),
+		Message:   event.Message,
+	})
+}
+
+// reportConvergence explains a no-progress stop: it builds the convergence
+// report, writes it to the report directory and emits its summary.
+// Failures to write are logged rather than failing the run.
+func (c *Controller) reportConvergence(spec *ArchSpec, iteration int) *ConvergenceReport {
+	report := BuildConvergenceReport(c.gapHistory, c.taskFailures, c.stopper.NoProgressCount(), ReportMeta{
+		SpecName:    spec.Name,
+		Iteration:   iteration,
+		GeneratedAt: time.Now(),
+	})
+	message := report.Summary()
+	if c.ReportDir != "" {
+		if err := WriteConvergence(c.ReportDir, report); err != nil {
+			archLog.Warn("failed to write convergence report", logging.Err(err))
+		} else {
+			message += " (see " + filepath.Join(c.ReportDir, convergenceMarkdownFile) + ")"
+		}
+	}
+	c.emitProgress(ProgressEvent{
+		Phase:            PhaseComplete,
+		Iteration:        iteration,
+		FeaturesComplete: c.currentFeaturesComplete,
+		FeaturesTotal:    c.currentFeaturesTotal,
+		Message:          message,
+	})
+	return report
+}
@@ -0,0 +1,131 @@
+package architect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestBuildConvergenceReport(t *testing.T) {
+	c := NewController(10, 5.0, 3)
+	c.taskFeatures = map[string]string{"ts-1": "F1", "ts-2": "F1", "ts-3": "F3"}
+	for i, e := range []orchestrator.OrchestratorEvent{
+		{TaskID: "ts-1", TaskTitle: "Add login", Message: "Task aborted: max iterations reached (3) without passing verification. 2/5 tests failing",
+			Error: fmt.Errorf("%w after 3 iterations", orchestrator.ErrVerificationFailed)},
+		{TaskID: "ts-2", TaskTitle: "Add login form", Message: "Task aborted: max iterations reached (3) without passing verification. 1/5 tests failing",
+			Error: fmt.Errorf("%w after 3 iterations", orchestrator.ErrVerificationFailed)},
+		{TaskID: "ts-3", TaskTitle: "Rotate logs", Message: "Merge failed for task: Rotate logs", Error: errors.New("conflict in audit.go")},
+	} {
+		c.currentIteration = i + 1
+		e.Type = orchestrator.EventTaskFailed
+		c.handleOrchestratorEvent(e)
+	}
+
+	audits := [][]Gap{
+		{{FeatureID: "F1", Status: AuditStatusMissing}, {FeatureID: "F2", Status: AuditStatusPartial}},
+		{{FeatureID: "F1", Status: AuditStatusPartial}, {FeatureID: "F2", Status: AuditStatusPartial}, {FeatureID: "F3", Status: AuditStatusMissing}},
+		{{FeatureID: "F1", Status: AuditStatusPartial, Description: "No logout"}, {FeatureID: "F2", Status: AuditStatusPartial}, {FeatureID: "F3", Status: AuditStatusMissing}},
+	}
+	report := BuildConvergenceReport(audits, c.taskFailures, 2, ReportMeta{SpecName: "Auth", Iteration: 3})
+
+	// Longest-standing gaps first, with the final audit's details
+	var gaps []string
+	for _, g := range report.PersistentGaps {
+		gaps = append(gaps, fmt.Sprintf("%s%v", g.FeatureID, g.Iterations))
+	}
+	if want := []string{"F1[1 2 3]", "F2[1 2 3]", "F3[2 3]"}; !reflect.DeepEqual(gaps, want) {
+		t.Errorf("persistent gaps = %v, want %v", gaps, want)
+	}
+	if report.PersistentGaps[0].Description != "No logout" {
+		t.Errorf("persistent gap = %+v, want the last audit's description", report.PersistentGaps[0])
+	}
+
+	// Only F1's tasks failed more than once
+	if len(report.RepeatedFailures) != 1 {
+		t.Fatalf("repeated failures = %+v, want F1 only", report.RepeatedFailures)
+	}
+	rf := report.RepeatedFailures[0]
+	if rf.FeatureID != "F1" || rf.Failures != 2 || rf.Causes[CauseVerification] != 2 || len(rf.Titles) != 2 || len(rf.Messages) != 2 {
+		t.Errorf("repeated failure = %+v", rf)
+	}
+
+	var causes []string
+	for _, sc := range report.SuspectedCauses {
+		causes = append(causes, fmt.Sprintf("%s:%d:%s", sc.Cause, sc.Occurrences, strings.Join(sc.Features, ",")))
+		if sc.Intervention == "" {
+			t.Errorf("cause %s has no intervention", sc.Cause)
+		}
+	}
+	// F2 persisted without a failing task
+	if want := []string{"verification:2:F1", "merge_conflict:1:F3", "unresolved_gap:1:F2"}; !reflect.DeepEqual(causes, want) {
+		t.Errorf("suspected causes = %v, want %v", causes, want)
+	}
+	if got := report.Summary(); got != "No progress for 2 iterations: 3 gap(s) persisted, 1 task group(s) failed repeatedly; most likely cause: verification" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestFailureCause(t *testing.T) {
+	tests := []struct {
+		event orchestrator.OrchestratorEvent
+		want  string
+	}{
+		{orchestrator.OrchestratorEvent{Message: "Task abandoned: x", Error: fmt.Errorf("%w: gave up", orchestrator.ErrTaskEscalated)}, CauseEscalated},
+		{orchestrator.OrchestratorEvent{Message: "Task blocked: x", Error: fmt.Errorf("%w: secrets/", orchestrator.ErrProtectedAreaBlocked)}, CauseProtectedArea},
+		{orchestrator.OrchestratorEvent{Message: "Post-merge verification failed: undefined: Foo",
+			Error: fmt.Errorf("build %w: %w", orchestrator.ErrVerificationFailed, errors.New("exit 1"))}, CausePostMergeBuild},
+		{orchestrator.OrchestratorEvent{Message: "Merge failed for task: x", Error: errors.New("rebase failed")}, CauseMergeConflict},
+		{orchestrator.OrchestratorEvent{Message: "Task aborted", Error: fmt.Errorf("%w after 3 iterations", orchestrator.ErrVerificationFailed)}, CauseVerification},
+		{orchestrator.OrchestratorEvent{Message: "Task failed: x", Error: errors.New("context deadline exceeded")}, CauseTimeout},
+		{orchestrator.OrchestratorEvent{Message: "Task failed: x", Error: errors.New("claude exited with status 1")}, CauseExecution},
+	}
+	for _, tt := range tests {
+		if got := failureCause(tt.event); got != tt.want {
+			t.Errorf("failureCause(%q) = %s, want %s", tt.event.Message, got, tt.want)
+		}
+	}
+}
+
+func TestWriteConvergence(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	report := BuildConvergenceReport(
+		[][]Gap{{{FeatureID: "F1", Status: AuditStatusMissing}}, {{FeatureID: "F1", Status: AuditStatusMissing, SuggestedAction: "Add a handler"}}},
+		[]TaskFailure{
+			{Title: "Fix build", Iteration: 1, Cause: CauseExecution, Message: "crashed"},
+			{Title: "Fix build", Iteration: 2, Cause: CauseExecution, Message: "crashed"},
+		},
+		1, ReportMeta{SpecName: "Auth", Iteration: 2})
+	if err := WriteConvergence(dir, report); err != nil {
+		t.Fatalf("WriteConvergence() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, convergenceJSONFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ConvergenceReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("convergence.json is not valid JSON: %v", err)
+	}
+	if len(decoded.PersistentGaps) != 1 || len(decoded.RepeatedFailures) != 1 || decoded.RepeatedFailures[0].Failures != 2 {
+		t.Errorf("decoded report = %+v", decoded)
+	}
+
+	md, err := os.ReadFile(filepath.Join(dir, convergenceMarkdownFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Convergence Report: Auth", "## Recommended Interventions", "**unresolved_gap** (1) — F1", "Add a handler", "### Unattributed — 2 failures (execution ×2)", "> crashed"} {
+		if !bytes.Contains(md, []byte(want)) {
+			t.Errorf("convergence.md missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"sort"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// TaskCost is the agent spend attributed to one task, summed over its attempts.
+type TaskCost struct {
+	// TaskID is the prog task ID, or the orchestrator's task ID for tasks
+	// not tracked in prog.
+	TaskID string
+	// Title is the task title.
+	Title string
+	// FeatureID is the spec feature the task implements, or empty if the
+	// task could not be attributed to one.
+	FeatureID string
+	// Attempts is the number of attempts the task took.
+	Attempts int
+	// TokensUsed is the tokens used across all attempts.
+	TokensUsed int64
+	// Cost is the cost of all attempts, in dollars.
+	Cost float64
+}
+
+// FeatureCost is the agent spend attributed to one spec feature.
+type FeatureCost struct {
+	// FeatureID is the spec feature, or empty for spend on tasks that could
+	// not be attributed to a feature.
+	FeatureID string
+	// Tasks is the number of tasks worked on for the feature.
+	Tasks int
+	// Attempts is the number of attempts across those tasks.
+	Attempts int
+	// TokensUsed is the tokens used across those attempts.
+	TokensUsed int64
+	// Cost is the cost of those attempts, in dollars.
+	Cost float64
+}
+
+// recordTaskUsage attributes a finished attempt's spend to its task and,
+// through the task, to the feature it implements.
+func (c *Controller) recordTaskUsage(event orchestrator.OrchestratorEvent) {
+	if c.taskCosts == nil {
+		c.taskCosts = make(map[string]*TaskCost)
+	}
+	taskID := event.ProgTaskID
+	if taskID == "" {
+		taskID = event.TaskID
+	}
+
+	tc, ok := c.taskCosts[taskID]
+	if !ok {
+		tc = &TaskCost{TaskID: taskID, Title: event.TaskTitle, FeatureID: c.taskFeatures[taskID]}
+		c.taskCosts[taskID] = tc
+	}
+	tc.Attempts++
+	tc.TokensUsed += event.TokensUsed
+	tc.Cost += event.Cost
+}
+
+// iterationTaskCosts returns the spend of the iteration's tasks, most
+// expensive first.
+func (c *Controller) iterationTaskCosts() []TaskCost {
+	costs := make([]TaskCost, 0, len(c.taskCosts))
+	for _, tc := range c.taskCosts {
+		costs = append(costs, *tc)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Cost != costs[j].Cost {
+			return costs[i].Cost > costs[j].Cost
+		}
+		return costs[i].TaskID < costs[j].TaskID
+	})
+	return costs
+}
+
+// rollUpFeatureCosts adds task spend to the per-feature totals in costs and
+// returns them, most expensive feature first.
+func rollUpFeatureCosts(costs []FeatureCost, tasks []TaskCost) []FeatureCost {
+	index := make(map[string]int, len(costs))
+	for i, fc := range costs {
+		index[fc.FeatureID] = i
+	}
+	for _, tc := range tasks {
+		i, ok := index[tc.FeatureID]
+		if !ok {
+			i = len(costs)
+			index[tc.FeatureID] = i
+			costs = append(costs, FeatureCost{FeatureID: tc.FeatureID})
+		}
+		costs[i].Tasks++
+		costs[i].Attempts += tc.Attempts
+		costs[i].TokensUsed += tc.TokensUsed
+		costs[i].Cost += tc.Cost
+	}
+	sort.SliceStable(costs, func(i, j int) bool {
+		if costs[i].Cost != costs[j].Cost {
+			return costs[i].Cost > costs[j].Cost
+		}
+		return costs[i].FeatureID < costs[j].FeatureID
+	})
+	return costs
+}
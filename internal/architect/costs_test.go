@@ -0,0 +1,48 @@
+package architect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestController_FeatureCostAttribution(t *testing.T) {
+	c := NewController(10, 5.0, 3)
+	c.taskFeatures = map[string]string{"ts-1": "F1", "ts-2": "F1", "ts-3": "F2"}
+
+	for _, e := range []orchestrator.OrchestratorEvent{
+		{TaskID: "a", ProgTaskID: "ts-1", TaskTitle: "Add login form", TokensUsed: 1000, Cost: 0.50},
+		{TaskID: "a", ProgTaskID: "ts-1", TaskTitle: "Add login form", TokensUsed: 500, Cost: 0.25},
+		{TaskID: "b", ProgTaskID: "ts-2", TaskTitle: "Add session store", TokensUsed: 200, Cost: 0.10},
+		{TaskID: "c", ProgTaskID: "ts-3", TaskTitle: "Rotate logs", TokensUsed: 3000, Cost: 1.00},
+		{TaskID: "d", TaskTitle: "Fix build", TokensUsed: 100, Cost: 0.05},
+	} {
+		e.Type = orchestrator.EventTaskUsage
+		c.handleOrchestratorEvent(e)
+	}
+
+	tasks := c.iterationTaskCosts()
+	if len(tasks) != 4 || tasks[0].TaskID != "ts-3" || tasks[3].TaskID != "d" {
+		t.Fatalf("task costs = %+v, want 4 tasks, most expensive first", tasks)
+	}
+	if tasks[1] != (TaskCost{TaskID: "ts-1", Title: "Add login form", FeatureID: "F1", Attempts: 2, TokensUsed: 1500, Cost: 0.75}) {
+		t.Errorf("ts-1 cost = %+v, want both attempts summed", tasks[1])
+	}
+
+	got := rollUpFeatureCosts(nil, tasks)
+	want := []FeatureCost{
+		{FeatureID: "F2", Tasks: 1, Attempts: 1, TokensUsed: 3000, Cost: 1.00},
+		{FeatureID: "F1", Tasks: 2, Attempts: 3, TokensUsed: 1700, Cost: 0.85},
+		{FeatureID: "", Tasks: 1, Attempts: 1, TokensUsed: 100, Cost: 0.05},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("feature costs = %+v, want %+v", got, want)
+	}
+
+	// A later iteration adds to the run's totals
+	got = rollUpFeatureCosts(got, []TaskCost{{TaskID: "ts-9", FeatureID: "F1", Attempts: 1, TokensUsed: 4000, Cost: 0.40}})
+	if got[0].FeatureID != "F1" || got[0].Tasks != 3 || got[0].TokensUsed != 5700 {
+		t.Errorf("run feature costs = %+v, want F1 first with three tasks", got)
+	}
+}
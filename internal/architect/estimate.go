@@ -0,0 +1,187 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// estimateModel is the model implement tasks run on, used to price estimates.
+const estimateModel = "sonnet"
+
+// Baseline token usage of a task, from typical agent sessions. Implementing
+// a missing feature reads and writes more code than completing a partial one.
+const (
+	missingTaskInputTokens  = 60_000
+	missingTaskOutputTokens = 8_000
+	partialTaskInputTokens  = 35_000
+	partialTaskOutputTokens = 5_000
+	// perFileInputTokens is added for each file the task is expected to touch.
+	perFileInputTokens = 5_000
+)
+
+// TaskEstimate is the expected cost of one planned task.
+type TaskEstimate struct {
+	// Task is the planned task.
+	Task orchestrator.PlannedTask
+	// Gap is the gap the task addresses.
+	Gap Gap
+	// InputTokens is the estimated number of input tokens.
+	InputTokens int64
+	// OutputTokens is the estimated number of output tokens.
+	OutputTokens int64
+	// Cost is the estimated cost in dollars.
+	Cost float64
+}
+
+// ExecutionPlan is a plan-only run's output: the tasks that would be
+// executed and what they are expected to cost.
+type ExecutionPlan struct {
+	// SpecName is the name of the audited specification.
+	SpecName string
+	// FeaturesTotal is the number of features in the specification.
+	FeaturesTotal int
+	// FeaturesComplete is the number of features already implemented.
+	FeaturesComplete int
+	// EpicTitle is the title of the epic that would be created.
+	EpicTitle string
+	// Phases groups the gaps in execution order.
+	Phases []Phase
+	// Tasks holds the estimate of each task in dependency order.
+	Tasks []TaskEstimate
+	// PlanningCost is the actual cost of parsing, auditing and planning.
+	PlanningCost float64
+}
+
+// EstimateTask estimates the tokens and cost of executing a planned task.
+// Estimates are heuristics based on the gap status, the task description
+// length and the number of files the task is expected to touch.
+func EstimateTask(task orchestrator.PlannedTask, gap Gap) TaskEstimate {
+	input, output := int64(partialTaskInputTokens), int64(partialTaskOutputTokens)
+	if gap.Status == AuditStatusMissing {
+		input, output = missingTaskInputTokens, missingTaskOutputTokens
+	}
+	// The description is sent with every turn (rough: ~4 chars per token)
+	input += int64(len(task.Description) / 4)
+	input += int64(len(orchestrator.ParseFileBoundaries(task.Description))) * perFileInputTokens
+
+	est := TaskEstimate{Task: task, Gap: gap, InputTokens: input, OutputTokens: output}
+	if pricing, ok := agent.DefaultModelPricing[estimateModel]; ok {
+		est.Cost = float64(input)/1_000_000*pricing.InputPerMillion +
+			float64(output)/1_000_000*pricing.OutputPerMillion
+	}
+	return est
+}
+
+// NewExecutionPlan estimates every task of the plan.
+func NewExecutionPlan(spec *ArchSpec, report *GapReport, plan *TaskPlan) *ExecutionPlan {
+	ep := &ExecutionPlan{}
+	if spec != nil {
+		ep.SpecName = spec.Name
+		ep.FeaturesTotal = len(spec.InScope())
+	}
+	if report != nil {
+		for _, fs := range report.Features {
+			if fs.Status == AuditStatusComplete && !fs.Feature.IsDeferred() {
+				ep.FeaturesComplete++
+			}
+		}
+	}
+	if plan != nil {
+		ep.EpicTitle = plan.EpicTitle
+		ep.Phases = plan.Phases
+		for i, task := range plan.Tasks {
+			ep.Tasks = append(ep.Tasks, EstimateTask(task, plan.Gaps[i]))
+		}
+	}
+	return ep
+}
+
+// TotalTokens returns the estimated input and output tokens of all tasks.
+func (p *ExecutionPlan) TotalTokens() (input, output int64) {
+	for _, t := range p.Tasks {
+		input += t.InputTokens
+		output += t.OutputTokens
+	}
+	return input, output
+}
+
+// TotalCost returns the estimated cost of executing all tasks.
+func (p *ExecutionPlan) TotalCost() float64 {
+	var total float64
+	for _, t := range p.Tasks {
+		total += t.Cost
+	}
+	return total
+}
+
+// Format writes the plan as human-readable text.
+func (p *ExecutionPlan) Format(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "=== Execution Plan ===\n\n")
+	if p.SpecName != "" {
+		fmt.Fprintf(&b, "Specification: %s\n", p.SpecName)
+	}
+	fmt.Fprintf(&b, "Features:      %d/%d complete\n", p.FeaturesComplete, p.FeaturesTotal)
+	if len(p.Tasks) == 0 {
+		fmt.Fprintf(&b, "\nNo gaps found - nothing to execute.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	fmt.Fprintf(&b, "Epic:          %s\n", p.EpicTitle)
+
+	byKey := make(map[string]TaskEstimate, len(p.Tasks))
+	for _, t := range p.Tasks {
+		byKey[t.Task.Key] = t
+	}
+	n := 0
+	for _, phase := range p.Phases {
+		fmt.Fprintf(&b, "\n%s (%d tasks)\n", phase.Name, len(phase.Gaps))
+		for _, gap := range phase.Gaps {
+			t, ok := byKey[gap.FeatureID]
+			if !ok {
+				continue
+			}
+			n++
+			fmt.Fprintf(&b, "  %2d. %s [%s] ~%s tokens, ~$%.2f\n",
+				n, t.Task.Title, gap.Status, formatTokens(t.InputTokens+t.OutputTokens), t.Cost)
+			if gap.Description != "" {
+				fmt.Fprintf(&b, "      %s\n", gap.Description)
+			}
+			if len(t.Task.DependsOn) > 0 {
+				fmt.Fprintf(&b, "      depends on: %s\n", strings.Join(t.Task.DependsOn, ", "))
+			}
+			if files := orchestrator.ParseFileBoundaries(t.Task.Description); len(files) > 0 {
+				fmt.Fprintf(&b, "      files: %s\n", strings.Join(files, ", "))
+			}
+		}
+	}
+
+	input, output := p.TotalTokens()
+	fmt.Fprintf(&b, "\nTotal: %d tasks, ~%s input + ~%s output tokens, ~$%.2f (%s pricing)\n",
+		len(p.Tasks), formatTokens(input), formatTokens(output), p.TotalCost(), estimateModel)
+	if p.PlanningCost > 0 {
+		fmt.Fprintf(&b, "Planning so far: $%.2f\n", p.PlanningCost)
+	}
+	fmt.Fprintf(&b, "Estimates are heuristic; retries, merges and verification add to the actual cost.\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatTokens abbreviates a token count (e.g. 65000 -> "65k").
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%dk", n/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
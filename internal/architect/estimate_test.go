@@ -0,0 +1,99 @@
+package architect
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestEstimateTask(t *testing.T) {
+	missing := EstimateTask(orchestrator.PlannedTask{Key: "a"}, Gap{Status: AuditStatusMissing})
+	partial := EstimateTask(orchestrator.PlannedTask{Key: "b"}, Gap{Status: AuditStatusPartial})
+	if missing.InputTokens <= partial.InputTokens || missing.Cost <= partial.Cost {
+		t.Errorf("missing gap should cost more than partial: %+v vs %+v", missing, partial)
+	}
+
+	withFiles := EstimateTask(orchestrator.PlannedTask{
+		Key:         "c",
+		Description: orchestrator.FormatFileBoundaries([]string{"a.go", "b.go"}),
+	}, Gap{Status: AuditStatusPartial})
+	if withFiles.InputTokens < partial.InputTokens+2*perFileInputTokens {
+		t.Errorf("file hints not counted: %d", withFiles.InputTokens)
+	}
+
+	// sonnet: $3/M input, $15/M output
+	want := float64(partialTaskInputTokens)*3/1e6 + float64(partialTaskOutputTokens)*15/1e6
+	if math.Abs(partial.Cost-want) > 1e-9 {
+		t.Errorf("partial cost = %f, want %f", partial.Cost, want)
+	}
+}
+
+func TestBuildPlanAndFormat(t *testing.T) {
+	spec := &ArchSpec{Name: "Shop", Features: []Feature{{ID: "db"}, {ID: "auth"}, {ID: "docs"}}}
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "db"}, Status: AuditStatusMissing},
+			{Feature: Feature{ID: "auth"}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "docs"}, Status: AuditStatusComplete},
+		},
+		Gaps: []Gap{
+			{FeatureID: "auth", Status: AuditStatusPartial, Description: "No logout"},
+			{FeatureID: "db", Status: AuditStatusMissing, Description: "No schema"},
+		},
+	}
+	runner := &scriptedRunner{reply: `{"ordered_gaps": [
+		{"feature_id": "db", "priority": 0, "files": ["migrations/"]},
+		{"feature_id": "auth", "priority": 1, "depends_on": ["db"]}
+	]}`}
+
+	// Building a plan needs no prog client
+	plan := NewPlanner(nil).BuildPlan(context.Background(), report, runner)
+	if plan == nil || len(plan.Tasks) != 2 || len(plan.Gaps) != 2 {
+		t.Fatalf("BuildPlan() = %+v", plan)
+	}
+
+	ep := NewExecutionPlan(spec, report, plan)
+	if ep.FeaturesComplete != 1 || ep.FeaturesTotal != 3 || len(ep.Tasks) != 2 {
+		t.Fatalf("execution plan = %+v", ep)
+	}
+	if ep.Tasks[0].Task.Key != "db" || ep.Tasks[1].Task.Key != "auth" {
+		t.Errorf("tasks not in dependency order: %s, %s", ep.Tasks[0].Task.Key, ep.Tasks[1].Task.Key)
+	}
+	if got := ep.Tasks[0].Cost + ep.Tasks[1].Cost; math.Abs(ep.TotalCost()-got) > 1e-9 {
+		t.Errorf("TotalCost() = %f, want %f", ep.TotalCost(), got)
+	}
+
+	var buf bytes.Buffer
+	if err := ep.Format(&buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Specification: Shop",
+		"Features:      1/3 complete",
+		"Implement db [MISSING]",
+		"Complete auth [PARTIAL]",
+		"depends on: db",
+		"files: migrations/",
+		"Total: 2 tasks",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExecutionPlanFormatNoGaps(t *testing.T) {
+	ep := NewExecutionPlan(&ArchSpec{Features: []Feature{{ID: "a"}}}, &GapReport{}, nil)
+	var buf bytes.Buffer
+	if err := ep.Format(&buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if !strings.Contains(buf.String(), "nothing to execute") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
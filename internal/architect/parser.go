@@ -49,6 +49,8 @@ type Parser struct {
 	cache *ParserCache
 	// enableCache controls whether caching is enabled
 	enableCache bool
+	// promptCache keeps Claude's responses across runs
+	promptCache *PromptCache
 }
 
 // NewParser creates a new Parser with default settings.
@@ -62,6 +64,12 @@ func NewParser() *Parser {
 	}
 }
 
+// SetPromptCache answers parses of an unchanged spec from cache's earlier
+// responses instead of calling Claude. Nil disables it.
+func (p *Parser) SetPromptCache(cache *PromptCache) {
+	p.promptCache = cache
+}
+
 // defaultExtractionPrompt is the prompt used to extract features from markdown documents.
 const defaultExtractionPrompt = `You are an architecture document parser. Analyze the following markdown document and extract all features, requirements, and specifications.
 
@@ -70,6 +78,8 @@ For each feature/requirement you identify, extract:
 2. Name: A short descriptive name for the feature
 3. Description: The full description of the feature
 4. Criteria: What constitutes full implementation (optional)
+5. Critical: true only if the document marks the feature as critical, must-have or P0
+6. Deferred: the document's marker if it defers the feature out of the current scope ("optional", "deferred", "phase-2", "future"), otherwise ""
 
 Respond with a JSON object in this exact format:
 {
@@ -79,7 +89,9 @@ Respond with a JSON object in this exact format:
       "id": "F001",
       "name": "Feature Name",
       "description": "Full description",
-      "criteria": "What defines complete implementation"
+      "criteria": "What defines complete implementation",
+      "critical": false,
+      "deferred": ""
     }
   ]
 }
@@ -103,6 +115,8 @@ For each feature/requirement you identify, extract:
 2. Name: A short descriptive name for the feature
 3. Description: The full description of the feature
 4. Criteria: What constitutes full implementation (optional)
+5. Critical: true only if the document marks the feature as critical, must-have or P0
+6. Deferred: the document's marker if it defers the feature out of the current scope ("optional", "deferred", "phase-2", "future"), otherwise ""
 
 Parse XML elements, attributes, and nested structures. Common patterns:
 - <feature id="F001" name="...">description</feature>
@@ -118,7 +132,9 @@ Respond with a JSON object in this exact format:
       "id": "F001",
       "name": "Feature Name",
       "description": "Full description",
-      "criteria": "What defines complete implementation"
+      "criteria": "What defines complete implementation",
+      "critical": false,
+      "deferred": ""
     }
   ]
 }
@@ -135,20 +151,45 @@ IMPORTANT:
 XML document to parse:
 `
 
+// multiFilePromptSuffix is added to the extraction prompt for specs composed
+// from several files.
+const multiFilePromptSuffix = `
+This specification is composed of several files. Each file starts with a
+<!-- spec-file: path --> marker. For each feature also extract:
+7. Source: the path from the marker of the file that defines the feature
+
+Add it to each feature object as "source". Features may reference features
+defined in other files; extract each feature once, from the file defining it.
+`
+
 // Parse extracts features from an architecture document (markdown or XML).
-// It reads the file at docPath and uses Claude to extract structured features.
+// docPath may be a single file or a directory of Markdown files composed
+// with LoadSpec. It uses Claude to extract structured features, except from
+// specs generated by ScaffoldSpec, which are parsed directly.
 func (p *Parser) Parse(ctx context.Context, docPath string, claude agent.ClaudeRunner) (*ArchSpec, error) {
-	// Read the document
-	content, err := os.ReadFile(docPath)
+	// Read (and compose) the document
+	doc, err := LoadSpec(docPath)
 	if err != nil {
-		return nil, fmt.Errorf("read document: %w", err)
+		return nil, err
 	}
+	content := []byte(doc.Content)
 
 	// Validate content is not empty
 	if len(strings.TrimSpace(string(content))) == 0 {
 		return nil, fmt.Errorf("document is empty")
 	}
 
+	// Scaffolded specs are parsed without Claude
+	if !doc.XML && !doc.MultiFile() {
+		spec, err := parseScaffold(doc.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parse scaffolded spec: %w", err)
+		}
+		if spec != nil {
+			return spec, nil
+		}
+	}
+
 	// Compute content hash for caching
 	hash := computeSHA256(content)
 
@@ -162,20 +203,63 @@ func (p *Parser) Parse(ctx context.Context, docPath string, claude agent.ClaudeR
 	// Cache miss - proceed with parsing
 	// Select prompt based on file extension
 	promptTemplate := p.extractionPrompt
-	if strings.HasSuffix(strings.ToLower(docPath), ".xml") {
+	if doc.XML {
 		promptTemplate = xmlExtractionPrompt
 	}
+	if doc.MultiFile() {
+		// Insert the instructions before the closing "... to parse:" line
+		if i := strings.LastIndex(promptTemplate, "\n\n"); i >= 0 {
+			promptTemplate = promptTemplate[:i] + "\n" + multiFilePromptSuffix + promptTemplate[i:]
+		}
+	}
 
 	// Build the prompt
 	prompt := promptTemplate + string(content)
 
+	// Reuse the response to the same prompt from an earlier run
+	key, response, cached := p.promptCache.lookup(PromptKindParse, prompt)
+	if !cached {
+		if response, err = p.runParse(prompt, claude); err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse the JSON response
+	spec, err := parseResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if doc.MultiFile() {
+		if err := stabilizeFeatureIDs(spec, doc); err != nil {
+			return nil, fmt.Errorf("compose spec: %w", err)
+		}
+	}
+
+	// Store in cache after successful parse
+	if !cached {
+		p.promptCache.save(PromptKindParse, promptCacheScope(docPath), key, response)
+	}
+	if p.enableCache && p.cache != nil {
+		p.cache.Set(hash, &CachedSpec{
+			Spec:     spec,
+			FilePath: docPath,
+			FileHash: hash,
+			ParsedAt: time.Now(),
+		})
+	}
+
+	return spec, nil
+}
+
+// runParse sends the extraction prompt to Claude and returns its raw response.
+func (p *Parser) runParse(prompt string, claude agent.ClaudeRunner) (string, error) {
 	// Start Claude process with temperature=0 for deterministic parsing
 	temp := 0.0
 	opts := &agent.StartOptions{
 		Temperature: &temp,
 	}
 	if err := claude.StartWithOptions(prompt, "", opts); err != nil {
-		return nil, fmt.Errorf("start claude: %w", err)
+		return "", fmt.Errorf("start claude: %w", err)
 	}
 
 	// Collect the response
@@ -187,33 +271,15 @@ func (p *Parser) Parse(ctx context.Context, docPath string, claude agent.ClaudeR
 		case agent.StreamEventAssistant:
 			responseBuilder.WriteString(event.Message)
 		case agent.StreamEventError:
-			return nil, fmt.Errorf("claude error: %s", event.Error)
+			return "", fmt.Errorf("claude error: %s", event.Error)
 		}
 	}
 
 	// Wait for process to complete
 	if err := claude.Wait(); err != nil {
-		return nil, fmt.Errorf("claude process failed: %w", err)
+		return "", fmt.Errorf("claude process failed: %w", err)
 	}
-
-	// Parse the JSON response
-	response := responseBuilder.String()
-	spec, err := parseResponse(response)
-	if err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
-	}
-
-	// Store in cache after successful parse
-	if p.enableCache && p.cache != nil {
-		p.cache.Set(hash, &CachedSpec{
-			Spec:     spec,
-			FilePath: docPath,
-			FileHash: hash,
-			ParsedAt: time.Now(),
-		})
-	}
-
-	return spec, nil
+	return responseBuilder.String(), nil
 }
 
 // parseResponse extracts the ArchSpec from Claude's response.
@@ -283,6 +349,10 @@ func parseResponse(response string) (*ArchSpec, error) {
 		return nil, fmt.Errorf("unmarshal JSON: %w", err)
 	}
 
+	for i := range spec.Features {
+		spec.Features[i].Deferred = normalizeDeferred(spec.Features[i].Deferred)
+	}
+
 	// Validate the parsed spec
 	if err := validateSpec(&spec); err != nil {
 		return nil, fmt.Errorf("validate spec: %w", err)
@@ -291,6 +361,18 @@ func parseResponse(response string) (*ArchSpec, error) {
 	return &spec, nil
 }
 
+// normalizeDeferred turns a deferral marker such as "Phase 2" into its
+// canonical form ("phase-2"). Markers meaning the feature is in scope
+// ("no", "false", "none") become empty.
+func normalizeDeferred(marker string) string {
+	marker = strings.Join(strings.Fields(strings.ToLower(marker)), "-")
+	switch marker {
+	case "no", "false", "none", "n/a":
+		return ""
+	}
+	return marker
+}
+
 // validateSpec performs basic validation on the parsed ArchSpec.
 func validateSpec(spec *ArchSpec) error {
 	if spec == nil {
@@ -261,3 +261,32 @@ func TestParseResponse_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestParseResponse_Deferred(t *testing.T) {
+	spec, err := parseResponse(`{
+		"name": "Test",
+		"features": [
+			{"id": "F1", "name": "Login", "deferred": ""},
+			{"id": "F2", "name": "SSO", "deferred": "Phase 2"},
+			{"id": "F3", "name": "Themes", "deferred": " Optional "},
+			{"id": "F4", "name": "Export", "deferred": "no"}
+		]
+	}`)
+	if err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	want := []string{"", "phase-2", "optional", ""}
+	for i, f := range spec.Features {
+		if f.Deferred != want[i] {
+			t.Errorf("feature %s Deferred = %q, want %q", f.ID, f.Deferred, want[i])
+		}
+	}
+	if got := len(spec.InScope()); got != 2 {
+		t.Errorf("InScope() = %d features, want 2", got)
+	}
+
+	spec.IncludeDeferred()
+	if got := len(spec.InScope()); got != 4 {
+		t.Errorf("InScope() after IncludeDeferred() = %d features, want 4", got)
+	}
+}
@@ -11,9 +11,14 @@ import (
 	"strings"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/internal/prog"
 )
 
+// plannerLog is the logger of the planner's records.
+var plannerLog = logging.Logger("planner")
+
 // PlanResult contains the IDs of created prog items.
 type PlanResult struct {
 	// EpicID is the ID of the created epic that groups all tasks.
@@ -44,76 +49,199 @@ type Phase struct {
 	DependsOnPhases []int
 }
 
-func (p *Planner) Plan(ctx context.Context, gaps *GapReport, projectName string, claude agent.ClaudeRunner) (*PlanResult, error) {
+// milestoneRegex extracts the milestone number from feature IDs like "M2-auth".
+var milestoneRegex = regexp.MustCompile(`^M(\d+)`)
+
+// TaskPlan is the work planned for a gap report, before anything is
+// written to prog.
+type TaskPlan struct {
+	// EpicTitle is the title of the epic grouping the tasks.
+	EpicTitle string
+	// EpicDescription describes the epic's phases.
+	EpicDescription string
+	// Phases groups the gaps in execution order.
+	Phases []Phase
+	// Tasks holds one task per gap in dependency order, keyed by feature ID.
+	Tasks []orchestrator.PlannedTask
+	// Gaps holds the gap behind each task, in the same order as Tasks.
+	Gaps []Gap
+}
+
+// BuildPlan decomposes the gap report into one task per gap without writing
+// anything to prog. Each task depends on the gap tasks it needs (from the
+// AI's dependency hints, or on the previous milestone's tasks for
+// milestone-numbered features) and records the files it is expected to
+// touch. Returns nil if there are no gaps.
+func (p *Planner) BuildPlan(ctx context.Context, gaps *GapReport, claude agent.ClaudeRunner) *TaskPlan {
 	if gaps == nil || len(gaps.Gaps) == 0 {
-		return &PlanResult{}, nil
+		return nil
 	}
 
-	phases := p.groupGapsIntoPhases(ctx, gaps.Gaps, claude)
-
-	// Create epic for the entire implementation
-	epicTitle := p.generateEpicTitle(gaps)
-	epicDesc := p.generateEpicDescription(gaps, phases)
-
-	epicID, err := p.client.CreateEpic(epicTitle, &prog.EpicOptions{
-		Project:     projectName,
-		Description: epicDesc,
-		Priority:    2,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create epic: %w", err)
+	var hints map[string]DependencyOrderItem
+	if claude != nil {
+		var err error
+		hints, err = p.inferDependencyOrder(ctx, gaps.Gaps, claude)
+		if err != nil {
+			plannerLog.Warn("dependency ordering failed, falling back to heuristic sorting", logging.Err(err))
+		}
 	}
 
-	// Create tasks for each phase
-	result := &PlanResult{
-		EpicID:  epicID,
-		TaskIDs: make([]string, 0, len(gaps.Gaps)),
+	phases := p.groupGapsIntoPhases(gaps.Gaps, hints)
+	plan := &TaskPlan{
+		EpicTitle:       p.generateEpicTitle(gaps),
+		EpicDescription: p.generateEpicDescription(gaps, phases),
+		Phases:          phases,
 	}
 
-	// Track task IDs by phase for dependency management
-	phaseTaskIDs := make([][]string, len(phases))
-
 	for i, phase := range phases {
-		phaseTaskIDs[i] = make([]string, 0, len(phase.Gaps))
-
 		for gapIdx, gap := range phase.Gaps {
-			var dependsOn []string
+			dependsOn := p.gapDependencies(gap, plan.Gaps, hints)
 
-			if gapIdx == 0 {
+			// Without gap-level dependencies, order the phase after the phases it depends on
+			if len(dependsOn) == 0 && gapIdx == 0 {
 				for _, depPhaseIdx := range phase.DependsOnPhases {
-					if depPhaseIdx < i && len(phaseTaskIDs[depPhaseIdx]) > 0 {
-						dependsOn = append(dependsOn, phaseTaskIDs[depPhaseIdx][len(phaseTaskIDs[depPhaseIdx])-1])
+					if depPhaseIdx < i && len(phases[depPhaseIdx].Gaps) > 0 {
+						depGaps := phases[depPhaseIdx].Gaps
+						dependsOn = append(dependsOn, depGaps[len(depGaps)-1].FeatureID)
 					}
 				}
 			}
 
-			taskTitle := p.generateTaskTitle(gap)
 			taskDesc := p.generateTaskDescription(gap)
+			// Record collision hints so the scheduler keeps overlapping fixes apart
+			if files := hints[gap.FeatureID].Files; len(files) > 0 {
+				taskDesc += "\n" + orchestrator.FormatFileBoundaries(files)
+			}
 
-			taskID, err := p.client.CreateTask(taskTitle, &prog.TaskOptions{
-				Project:     projectName,
+			plan.Tasks = append(plan.Tasks, orchestrator.PlannedTask{
+				Key:         gap.FeatureID,
+				Title:       p.generateTaskTitle(gap),
 				Description: taskDesc,
 				Priority:    p.gapPriority(gap),
-				ParentID:    epicID,
 				DependsOn:   dependsOn,
 			})
-			if err != nil {
-				return result, fmt.Errorf("create task for gap %s: %w", gap.FeatureID, err)
-			}
+			plan.Gaps = append(plan.Gaps, gap)
+		}
+	}
+	return plan
+}
+
+// Plan creates an epic with the tasks of BuildPlan, so the orchestrator
+// runs independent gap fixes in parallel and serializes the rest.
+func (p *Planner) Plan(ctx context.Context, gaps *GapReport, projectName string, claude agent.ClaudeRunner) (*PlanResult, error) {
+	// Build the full plan before writing anything, so an interrupted write
+	// can be completed on the next run.
+	plan := p.BuildPlan(ctx, gaps, claude)
+	if plan == nil {
+		return &PlanResult{}, nil
+	}
+	tasks := plan.Tasks
+
+	// Create epic for the entire implementation, or finish one whose plan
+	// was interrupted instead of creating a duplicate
+	unfinished, err := orchestrator.FindUnfinishedEpic(p.client, plan.EpicTitle)
+	if err != nil {
+		plannerLog.Warn("failed to check for unfinished epics", logging.Err(err))
+	}
+	var epicID string
+	if unfinished != nil {
+		epicID = unfinished.ID
+		plannerLog.Info("resuming partially written epic", "epic", epicID)
+	} else {
+		epicID, err = orchestrator.StartEpicPlan(p.client, plan.EpicTitle, &prog.EpicOptions{
+			Project:     projectName,
+			Description: plan.EpicDescription,
+			Priority:    2,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			phaseTaskIDs[i] = append(phaseTaskIDs[i], taskID)
-			result.TaskIDs = append(result.TaskIDs, taskID)
+	result := &PlanResult{
+		EpicID:  epicID,
+		TaskIDs: make([]string, 0, len(tasks)),
+	}
+	taskIDs, err := orchestrator.WriteEpicPlan(p.client, epicID, projectName, tasks)
+	for _, task := range tasks {
+		if id, ok := taskIDs[task.Key]; ok {
+			result.TaskIDs = append(result.TaskIDs, id)
 		}
 	}
+	if err != nil {
+		return result, fmt.Errorf("write tasks of epic %s: %w", epicID, err)
+	}
 
 	return result, nil
 }
 
-// DependencyOrderItem represents a gap with its inferred priority order.
+// gapDependencies returns the feature IDs, among the already planned gaps,
+// that gap must wait for. AI hints take precedence; otherwise a
+// milestone-numbered feature depends on the planned features of the nearest
+// earlier milestone. Only planned gaps are eligible, which keeps the
+// resulting task graph acyclic.
+func (p *Planner) gapDependencies(gap Gap, planned []Gap, hints map[string]DependencyOrderItem) []string {
+	if hint, ok := hints[gap.FeatureID]; ok && len(hint.DependsOn) > 0 {
+		var deps []string
+		for _, featureID := range hint.DependsOn {
+			if containsGap(planned, featureID) {
+				deps = append(deps, featureID)
+			} else if featureID != gap.FeatureID {
+				plannerLog.Info("ignoring dependency on a feature that is not an earlier gap", "feature", gap.FeatureID, "dependency", featureID)
+			}
+		}
+		return deps
+	}
+
+	milestone, ok := gapMilestone(gap)
+	if !ok {
+		return nil
+	}
+	nearest := -1
+	for _, other := range planned {
+		if m, ok := gapMilestone(other); ok && m < milestone && m > nearest {
+			nearest = m
+		}
+	}
+	var deps []string
+	for _, other := range planned {
+		if m, ok := gapMilestone(other); ok && m == nearest {
+			deps = append(deps, other.FeatureID)
+		}
+	}
+	return deps
+}
+
+// gapMilestone returns the milestone number of a feature ID like "M2-auth".
+func gapMilestone(gap Gap) (int, bool) {
+	match := milestoneRegex.FindStringSubmatch(gap.FeatureID)
+	if len(match) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(match[1])
+	return n, err == nil
+}
+
+// containsGap reports whether gaps includes the feature.
+func containsGap(gaps []Gap, featureID string) bool {
+	for _, gap := range gaps {
+		if gap.FeatureID == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyOrderItem represents a gap with its inferred priority order,
+// dependencies and collision hints.
 type DependencyOrderItem struct {
 	FeatureID string `json:"feature_id"`
 	Priority  int    `json:"priority"`
 	Reason    string `json:"reason"`
+	// DependsOn lists feature IDs that must be implemented first.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Files lists files or directories the fix is expected to modify.
+	Files []string `json:"files,omitempty"`
 }
 
 // DependencyOrderResponse is the structured response from Claude about gap ordering.
@@ -122,9 +250,10 @@ type DependencyOrderResponse struct {
 	Rationale   string                `json:"rationale"`
 }
 
-// inferDependencyOrder uses AI to analyze gaps and determine optimal implementation order.
-// Returns a map of featureID -> priority (lower number = higher priority).
-func (p *Planner) inferDependencyOrder(ctx context.Context, gaps []Gap, claude agent.ClaudeRunner) (map[string]int, error) {
+// inferDependencyOrder uses AI to analyze gaps and determine optimal implementation
+// order, dependencies between gaps, and the files each fix will touch.
+// Returns a map of featureID -> hint (lower priority number = do earlier).
+func (p *Planner) inferDependencyOrder(ctx context.Context, gaps []Gap, claude agent.ClaudeRunner) (map[string]DependencyOrderItem, error) {
 	if claude == nil || len(gaps) == 0 {
 		return nil, nil
 	}
@@ -148,11 +277,15 @@ Consider:
 - Dependencies between features (e.g., authentication before protected endpoints)
 - Setup and infrastructure before application features
 
+Features are implemented in parallel where possible, so also state for each feature:
+- depends_on: the feature IDs that must be finished before it can start (only real dependencies; leave empty if it can start right away)
+- files: the files or directories (relative to the repository root, directories ending in /) the implementation will most likely modify
+
 Respond with ONLY a JSON object (no markdown, no explanation), containing:
 {
   "ordered_gaps": [
-    {"feature_id": "...", "priority": 0, "reason": "why this comes first"},
-    {"feature_id": "...", "priority": 1, "reason": "why this comes second"},
+    {"feature_id": "...", "priority": 0, "reason": "why this comes first", "depends_on": [], "files": ["..."]},
+    {"feature_id": "...", "priority": 1, "reason": "why this comes second", "depends_on": ["..."], "files": ["..."]},
     ...
   ],
   "rationale": "Overall explanation of the ordering strategy"
@@ -206,25 +339,27 @@ Priority 0 = highest priority (do first), increasing numbers = lower priority (d
 	}
 
 	// Convert to map
-	orderMap := make(map[string]int)
+	hints := make(map[string]DependencyOrderItem)
 	for _, item := range response.OrderedGaps {
-		orderMap[item.FeatureID] = item.Priority
+		hints[item.FeatureID] = item
 	}
 
-	return orderMap, nil
+	return hints, nil
 }
 
-func (p *Planner) groupGapsIntoPhases(ctx context.Context, gaps []Gap, claude agent.ClaudeRunner) []Phase {
+// groupGapsIntoPhases splits gaps into a Foundation phase (missing features)
+// and a Refinement phase (partial features), each sorted by milestone and
+// then by the AI priority in hints.
+func (p *Planner) groupGapsIntoPhases(gaps []Gap, hints map[string]DependencyOrderItem) []Phase {
 	if len(gaps) == 0 {
 		return nil
 	}
 
 	var aiOrder map[string]int
-	if claude != nil {
-		var err error
-		aiOrder, err = p.inferDependencyOrder(ctx, gaps, claude)
-		if err != nil {
-			fmt.Printf("[planner] AI ordering warning: %v, falling back to heuristic sorting\n", err)
+	if hints != nil {
+		aiOrder = make(map[string]int, len(hints))
+		for featureID, hint := range hints {
+			aiOrder[featureID] = hint.Priority
 		}
 	}
 
@@ -263,8 +398,6 @@ func (p *Planner) groupGapsIntoPhases(ctx context.Context, gaps []Gap, claude ag
 }
 
 func (p *Planner) sortGapsByMilestone(gaps []Gap, aiOrder map[string]int) {
-	milestoneRegex := regexp.MustCompile(`^M(\d+)`)
-
 	sort.SliceStable(gaps, func(i, j int) bool {
 		iMatch := milestoneRegex.FindStringSubmatch(gaps[i].FeatureID)
 		jMatch := milestoneRegex.FindStringSubmatch(gaps[j].FeatureID)
@@ -368,9 +501,10 @@ func (p *Planner) generateTaskDescription(gap Gap) string {
 }
 
 // gapPriority determines the priority for a gap task.
-// MISSING gaps get higher priority than PARTIAL gaps.
+// MISSING gaps, high or critical severity gaps and gaps in critical
+// features get higher priority than other PARTIAL gaps.
 func (p *Planner) gapPriority(gap Gap) int {
-	if gap.Status == AuditStatusMissing {
+	if gap.Status == AuditStatusMissing || gap.Critical || gap.Severity.Weight() >= SeverityHigh.Weight() {
 		return 1 // High priority
 	}
 	return 2 // Medium priority
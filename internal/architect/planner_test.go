@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	"github.com/ShayCichocki/alphie/internal/prog"
 )
 
@@ -254,7 +256,7 @@ func TestGroupGapsIntoPhases(t *testing.T) {
 		{FeatureID: "f1", Status: AuditStatusMissing},
 		{FeatureID: "f2", Status: AuditStatusMissing},
 	}
-	phases := planner.groupGapsIntoPhases(missingGaps)
+	phases := planner.groupGapsIntoPhases(missingGaps, nil)
 	if len(phases) != 1 {
 		t.Errorf("expected 1 phase for missing-only gaps, got %d", len(phases))
 	}
@@ -269,7 +271,7 @@ func TestGroupGapsIntoPhases(t *testing.T) {
 	partialGaps := []Gap{
 		{FeatureID: "f1", Status: AuditStatusPartial},
 	}
-	phases = planner.groupGapsIntoPhases(partialGaps)
+	phases = planner.groupGapsIntoPhases(partialGaps, nil)
 	if len(phases) != 1 {
 		t.Errorf("expected 1 phase for partial-only gaps, got %d", len(phases))
 	}
@@ -282,7 +284,7 @@ func TestGroupGapsIntoPhases(t *testing.T) {
 		{FeatureID: "f1", Status: AuditStatusMissing},
 		{FeatureID: "f2", Status: AuditStatusPartial},
 	}
-	phases = planner.groupGapsIntoPhases(mixedGaps)
+	phases = planner.groupGapsIntoPhases(mixedGaps, nil)
 	if len(phases) != 2 {
 		t.Errorf("expected 2 phases for mixed gaps, got %d", len(phases))
 	}
@@ -297,7 +299,7 @@ func TestGroupGapsIntoPhases(t *testing.T) {
 	}
 
 	// Test with empty gaps
-	phases = planner.groupGapsIntoPhases([]Gap{})
+	phases = planner.groupGapsIntoPhases([]Gap{}, nil)
 	if phases != nil {
 		t.Errorf("expected nil phases for empty gaps, got %v", phases)
 	}
@@ -413,6 +415,11 @@ func TestGapPriority(t *testing.T) {
 	if planner.gapPriority(partialGap) != 2 {
 		t.Error("partial gap should have priority 2")
 	}
+
+	criticalGap := Gap{Status: AuditStatusPartial, Critical: true}
+	if planner.gapPriority(criticalGap) != 1 {
+		t.Error("critical partial gap should have priority 1")
+	}
 }
 
 func TestPlanResultStruct(t *testing.T) {
@@ -458,3 +465,128 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+// scriptedRunner is an agent.ClaudeRunner that replies with a fixed message.
+type scriptedRunner struct {
+	reply    string
+	outputCh chan agent.StreamEvent
+}
+
+func (r *scriptedRunner) Start(prompt, workDir string) error {
+	return r.StartWithOptions(prompt, workDir, nil)
+}
+func (r *scriptedRunner) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	r.outputCh = make(chan agent.StreamEvent, 1)
+	r.outputCh <- agent.StreamEvent{Type: agent.StreamEventResult, Message: r.reply}
+	close(r.outputCh)
+	return nil
+}
+func (r *scriptedRunner) Output() <-chan agent.StreamEvent { return r.outputCh }
+func (r *scriptedRunner) Wait() error                      { return nil }
+func (r *scriptedRunner) Kill() error                      { return nil }
+func (r *scriptedRunner) Stderr() string                   { return "" }
+func (r *scriptedRunner) PID() int                         { return 0 }
+
+func TestPlanWithDependencyHints(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	gaps := &GapReport{Gaps: []Gap{
+		{FeatureID: "auth", Status: AuditStatusMissing, Description: "No auth"},
+		{FeatureID: "db", Status: AuditStatusMissing, Description: "No schema"},
+		{FeatureID: "docs", Status: AuditStatusMissing, Description: "No docs"},
+	}}
+	runner := &scriptedRunner{reply: "```json\n" + `{"ordered_gaps": [
+		{"feature_id": "db", "priority": 0, "files": ["migrations/"]},
+		{"feature_id": "auth", "priority": 1, "depends_on": ["db", "docs"], "files": ["internal/auth/", "go.mod"]},
+		{"feature_id": "docs", "priority": 2}
+	]}` + "\n```"}
+
+	result, err := NewPlanner(client).Plan(context.Background(), gaps, "test-project", runner)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(result.TaskIDs) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(result.TaskIDs))
+	}
+	dbTask, authTask, docsTask := result.TaskIDs[0], result.TaskIDs[1], result.TaskIDs[2]
+
+	// auth waits for db; its dependency on docs is dropped because docs is planned later
+	deps, err := client.GetDependencies(authTask)
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	if len(deps) != 1 || deps[0] != dbTask {
+		t.Errorf("auth deps = %v, want [%s]", deps, dbTask)
+	}
+	// docs has no dependencies and can run in parallel with db
+	if deps, _ := client.GetDependencies(docsTask); len(deps) != 0 {
+		t.Errorf("docs deps = %v, want none", deps)
+	}
+
+	item, err := client.GetItem(authTask)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if !containsString(item.Description, "**Files:** internal/auth/, go.mod") {
+		t.Errorf("auth description missing file hints:\n%s", item.Description)
+	}
+}
+
+func TestGapDependenciesByMilestone(t *testing.T) {
+	planner := &Planner{}
+	planned := []Gap{
+		{FeatureID: "M1-setup"},
+		{FeatureID: "M1-config"},
+		{FeatureID: "M2-api"},
+		{FeatureID: "readme"},
+	}
+
+	deps := planner.gapDependencies(Gap{FeatureID: "M3-ui"}, planned, nil)
+	if len(deps) != 1 || deps[0] != "M2-api" {
+		t.Errorf("M3 deps = %v, want [M2-api]", deps)
+	}
+	deps = planner.gapDependencies(Gap{FeatureID: "M2-auth"}, planned, nil)
+	if len(deps) != 2 {
+		t.Errorf("M2 deps = %v, want both M1 features", deps)
+	}
+	if deps := planner.gapDependencies(Gap{FeatureID: "M1-db"}, planned, nil); len(deps) != 0 {
+		t.Errorf("M1 deps = %v, want none", deps)
+	}
+	if deps := planner.gapDependencies(Gap{FeatureID: "extra"}, planned, nil); len(deps) != 0 {
+		t.Errorf("unnumbered deps = %v, want none", deps)
+	}
+}
+
+func TestPlanResumesInterruptedEpic(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	gaps := &GapReport{Gaps: []Gap{
+		{FeatureID: "M1-setup", Status: AuditStatusMissing, Description: "No setup"},
+		{FeatureID: "M2-api", Status: AuditStatusMissing, Description: "No API"},
+	}}
+	planner := NewPlanner(client)
+
+	// A previous run created the epic and the first task, then stopped
+	epicID, err := orchestrator.StartEpicPlan(client, planner.generateEpicTitle(gaps), &prog.EpicOptions{Project: "test-project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupID, _ := client.CreateTask("Implement M1-setup", &prog.TaskOptions{Project: "test-project", ParentID: epicID})
+
+	result, err := planner.Plan(context.Background(), gaps, "test-project", nil)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if result.EpicID != epicID {
+		t.Errorf("epic = %s, want the interrupted epic %s", result.EpicID, epicID)
+	}
+	if len(result.TaskIDs) != 2 || result.TaskIDs[0] != setupID {
+		t.Fatalf("tasks = %v, want existing %s first", result.TaskIDs, setupID)
+	}
+	deps, _ := client.GetDependencies(result.TaskIDs[1])
+	if len(deps) != 1 || deps[0] != setupID {
+		t.Errorf("M2 deps = %v, want [%s]", deps, setupID)
+	}
+}
@@ -0,0 +1,174 @@
+package architect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// Kinds of cached calls.
+const (
+	PromptKindParse = "parse"
+	PromptKindAudit = "audit"
+)
+
+// PromptCache answers the parser's and auditor's calls from earlier
+// responses when nothing they depend on changed, so an iteration over an
+// unchanged spec or codebase does not pay for the same call again. Entries
+// are addressed by the SHA256 of the prompt and, for audits, the state of
+// the repository: a changed spec or commit, or uncommitted changes, miss
+// the cache and replace the stale entry. A nil *PromptCache caches nothing.
+type PromptCache struct {
+	store state.PromptCacheStore
+
+	mu    sync.Mutex
+	stats map[string]*state.PromptCacheStats
+}
+
+// NewPromptCache creates a PromptCache backed by store.
+func NewPromptCache(store state.PromptCacheStore) *PromptCache {
+	return &PromptCache{
+		store: store,
+		stats: make(map[string]*state.PromptCacheStats),
+	}
+}
+
+// Invalidate deletes the cached responses of kind and scope; empty kind or
+// scope matches any. It returns how many were deleted.
+func (c *PromptCache) Invalidate(kind, scope string) (int64, error) {
+	if c == nil {
+		return 0, nil
+	}
+	return c.store.InvalidatePrompts(kind, scope)
+}
+
+// Report returns the hits and misses of the lookups made through this
+// cache, ordered by kind.
+func (c *PromptCache) Report() []state.PromptCacheStats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := make([]state.PromptCacheStats, 0, len(c.stats))
+	for _, s := range c.stats {
+		report = append(report, *s)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Kind < report[j].Kind })
+	return report
+}
+
+// lookup returns the cached response for a call of kind, addressed by
+// parts, and the key to store the response under on a miss. Cache errors
+// count as misses: a broken cache only costs the call.
+func (c *PromptCache) lookup(kind string, parts ...string) (key, response string, ok bool) {
+	if c == nil {
+		return "", "", false
+	}
+	key = promptCacheKey(kind, parts...)
+	entry, err := c.store.LookupPrompt(kind, key)
+	ok = err == nil && entry != nil
+
+	c.mu.Lock()
+	s, found := c.stats[kind]
+	if !found {
+		s = &state.PromptCacheStats{Kind: kind}
+		c.stats[kind] = s
+	}
+	if ok {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return key, "", false
+	}
+	return key, entry.Response, true
+}
+
+// save caches the response to a call of kind about scope under key,
+// replacing the scope's earlier response.
+func (c *PromptCache) save(kind, scope, key, response string) {
+	if c == nil || key == "" {
+		return
+	}
+	_ = c.store.StorePrompt(&state.PromptCacheEntry{Key: key, Kind: kind, Scope: scope, Response: response})
+}
+
+// promptCacheKey returns the content address of a call of kind with parts.
+func promptCacheKey(kind string, parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	for _, part := range parts {
+		// Length-prefix the parts so different splits never collide
+		fmt.Fprintf(h, "\x00%d\x00", len(part))
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// repoFingerprint returns a hash of the repository's state: its HEAD commit
+// and any uncommitted or untracked changes, ignoring alphie's own .alphie
+// directory. ok is false outside a git repository, where changes cannot be
+// detected and audits are not cached.
+func repoFingerprint(repoPath string) (fingerprint string, ok bool) {
+	runner := git.NewRunner(repoPath)
+	head, err := runner.Run("rev-parse", "HEAD")
+	if err != nil {
+		return "", false
+	}
+	pathspec := []string{"--", ".", ":(exclude).alphie"}
+	diff, err := runner.Run(append([]string{"diff", "HEAD", "--binary"}, pathspec...)...)
+	if err != nil {
+		return "", false
+	}
+	untracked, err := runner.Run(append([]string{"ls-files", "--others", "--exclude-standard"}, pathspec...)...)
+	if err != nil {
+		return "", false
+	}
+
+	h := sha256.New()
+	h.Write([]byte(head + "\n" + diff + "\n"))
+	for _, name := range strings.Split(untracked, "\n") {
+		if name == "" {
+			continue
+		}
+		h.Write([]byte(name + "\n"))
+		if data, err := os.ReadFile(filepath.Join(repoPath, name)); err == nil {
+			h.Write(data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// promptCacheScope returns the scope of calls about path: its absolute path.
+func promptCacheScope(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// promptCacheMessage summarizes report, e.g. "Prompt cache: 3/4 hits
+// (75%) - audit 1/2, parse 2/2".
+func promptCacheMessage(report []state.PromptCacheStats) string {
+	var total state.PromptCacheStats
+	kinds := make([]string, 0, len(report))
+	for _, s := range report {
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		kinds = append(kinds, fmt.Sprintf("%s %d/%d", s.Kind, s.Hits, s.Hits+s.Misses))
+	}
+	return fmt.Sprintf("Prompt cache: %d/%d hits (%.0f%%) - %s",
+		total.Hits, total.Hits+total.Misses, total.HitRate()*100, strings.Join(kinds, ", "))
+}
@@ -0,0 +1,140 @@
+package architect
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// countingRunner is a scriptedRunner that counts the calls it answers.
+type countingRunner struct {
+	scriptedRunner
+	starts *int
+}
+
+func (r *countingRunner) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	*r.starts++
+	return r.scriptedRunner.StartWithOptions(prompt, workDir, opts)
+}
+
+func newTestPromptCache(t *testing.T) *PromptCache {
+	t.Helper()
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewPromptCache(db)
+}
+
+func TestPromptCache_Parse(t *testing.T) {
+	cache := newTestPromptCache(t)
+	specPath := filepath.Join(t.TempDir(), "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## F1 Login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	starts := 0
+	parse := func() *ArchSpec {
+		t.Helper()
+		// A new parser per run, so only the persistent cache can answer
+		parser := NewParser()
+		parser.SetPromptCache(cache)
+		runner := &countingRunner{scriptedRunner{reply: `{"name": "Spec", "features": [{"id": "F1", "name": "Login"}]}`}, &starts}
+		spec, err := parser.Parse(context.Background(), specPath, runner)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return spec
+	}
+
+	parse()
+	if spec := parse(); starts != 1 || len(spec.Features) != 1 || spec.Features[0].ID != "F1" {
+		t.Errorf("second parse called Claude %d times and returned %+v, want one call", starts, spec)
+	}
+
+	// A changed spec misses and replaces the stale entry
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## F1 Login\n## F2 Logout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parse()
+	if starts != 2 {
+		t.Errorf("parse of a changed spec called Claude %d times in total, want 2", starts)
+	}
+	report := cache.Report()
+	if len(report) != 1 || report[0].Hits != 1 || report[0].Misses != 2 {
+		t.Errorf("Report() = %+v, want 1 hit and 2 misses", report)
+	}
+	if got, want := promptCacheMessage(report), "Prompt cache: 1/3 hits (33%) - parse 1/3"; got != want {
+		t.Errorf("promptCacheMessage() = %q, want %q", got, want)
+	}
+	if n, err := cache.Invalidate(PromptKindParse, ""); err != nil || n != 1 {
+		t.Errorf("Invalidate() = %d, %v, want the one current entry", n, err)
+	}
+}
+
+func TestPromptCache_Audit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitCmd("init", "-q")
+	writeFile("main.go", "package main\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "init")
+
+	cache := newTestPromptCache(t)
+	auditor := NewAuditor()
+	auditor.SetPromptCache(cache)
+	spec := &ArchSpec{Name: "Spec", Features: []Feature{{ID: "F1", Name: "Login"}}}
+
+	starts := 0
+	audit := func() {
+		t.Helper()
+		runner := &countingRunner{scriptedRunner{reply: `{"features": [{"feature_id": "F1", "status": "MISSING"}], "gaps": [], "summary": "none"}`}, &starts}
+		if _, err := auditor.Audit(context.Background(), spec, repo, runner); err != nil {
+			t.Fatalf("Audit() error = %v", err)
+		}
+	}
+
+	// The audit's own artifacts under .alphie do not change the repository state
+	audit()
+	audit()
+	if starts != 1 {
+		t.Errorf("audit of an unchanged repository called Claude %d times, want 1", starts)
+	}
+
+	// Uncommitted and committed changes both invalidate
+	writeFile("login.go", "package main\n")
+	audit()
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "login")
+	audit()
+	audit()
+	if starts != 3 {
+		t.Errorf("audits after changes called Claude %d times in total, want 3", starts)
+	}
+}
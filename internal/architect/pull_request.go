@@ -0,0 +1,251 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// Check names reported on the pull request.
+const (
+	validationCheckName        = "alphie/validation"
+	finalVerificationCheckName = "alphie/final-verification"
+)
+
+// pullRequestBranchPrefix prefixes the branch an implement run's epics
+// merge into when it opens a pull request.
+const pullRequestBranchPrefix = "alphie/implement-"
+
+// publishesPullRequest reports whether the run should open a pull request.
+// Greenfield runs merge straight into the current branch, so there is
+// nothing to review.
+func (c *Controller) publishesPullRequest() bool {
+	return c.remoteProvider != nil && !c.Greenfield && !c.PlanOnly
+}
+
+// startPullRequestBranch checks out a fresh branch from the base branch, or
+// the current one if none is set, for the run's epics to merge into, and
+// remembers that branch as the pull request base.
+func (c *Controller) startPullRequestBranch() error {
+	runner := git.NewRunner(c.RepoPath)
+	base := c.BaseBranch
+	if base == "" {
+		current, err := runner.CurrentBranch()
+		if err != nil {
+			return fmt.Errorf("get current branch: %w", err)
+		}
+		base = current
+	}
+	branch := pullRequestBranchPrefix + time.Now().Format("20060102-150405")
+	if _, err := runner.Run("checkout", "-b", branch, base); err != nil {
+		return fmt.Errorf("create branch %s from %s: %w", branch, base, err)
+	}
+	c.prBase = base
+	c.prBranch = branch
+	return nil
+}
+
+// recordReviewConcerns keeps the concerns of a second review for the pull
+// request review. The reviewed task's agent is still active while it is
+// reviewed, so its title is taken from the worker.
+func (c *Controller) recordReviewConcerns(event orchestrator.OrchestratorEvent) {
+	title := event.TaskTitle
+	if worker, ok := c.activeWorkers[event.AgentID]; ok && title == "" {
+		title = worker.TaskTitle
+	}
+	for _, text := range event.Concerns {
+		c.reviewConcerns = append(c.reviewConcerns, remote.Concern{
+			TaskID:    event.TaskID,
+			TaskTitle: title,
+			Text:      text,
+		})
+	}
+}
+
+// publishPullRequest pushes the run's branch and opens a pull request with
+// the spec summary and final audit, reporting the validation and final
+// verification results as checks. Failures are logged rather than failing
+// the run, since the work is already committed locally.
+func (c *Controller) publishPullRequest(ctx context.Context, spec *ArchSpec, report *GapReport, iteration int, reason StopReason) {
+	if c.prBranch == "" {
+		return
+	}
+	meta := ReportMeta{
+		SpecName:    spec.Name,
+		Iteration:   iteration,
+		GeneratedAt: time.Now(),
+	}
+	body, err := pullRequestBody(spec, report, meta)
+	if err != nil {
+		archLog.Warn("failed to render pull request body", logging.Err(err))
+		return
+	}
+
+	pr, err := c.remoteProvider.Publish(ctx, remote.Submission{
+		Base:     c.prBase,
+		Branch:   c.prBranch,
+		Title:    pullRequestTitle(spec),
+		Body:     body,
+		Checks:   []remote.CheckRun{validationCheck(report), finalVerificationCheck(report, reason, c.Strictness)},
+		Concerns: c.reviewConcerns,
+	})
+	if pr != nil {
+		c.pullRequest = pr
+		c.emitProgress(ProgressEvent{
+			Phase:     PhaseComplete,
+			Iteration: iteration,
+			Cost:      c.cost(),
+			Message:   fmt.Sprintf("Opened pull request #%d: %s", pr.Number, pr.URL),
+		})
+	}
+	if err != nil {
+		archLog.Warn("pull request publishing incomplete", logging.Err(err))
+		c.emitProgress(ProgressEvent{
+			Phase:     PhaseComplete,
+			Iteration: iteration,
+			Cost:      c.cost(),
+			Message:   fmt.Sprintf("Warning: pull request publishing incomplete (work is on branch %s): %v", c.prBranch, err),
+		})
+	}
+}
+
+// PullRequest returns the pull request opened by the run, or nil.
+func (c *Controller) PullRequest() *remote.PullRequest {
+	return c.pullRequest
+}
+
+// pullRequestTitle names the pull request after the spec.
+func pullRequestTitle(spec *ArchSpec) string {
+	if spec.Name == "" {
+		return "Implement architecture specification"
+	}
+	return "Implement " + spec.Name
+}
+
+// pullRequestBody summarizes the spec's features and embeds the final audit report.
+func pullRequestBody(spec *ArchSpec, report *GapReport, meta ReportMeta) (string, error) {
+	data := newReportData(report, meta)
+	var b strings.Builder
+
+	name := spec.Name
+	if name == "" {
+		name = "the architecture specification"
+	}
+	fmt.Fprintf(&b, "Alphie implemented **%s** over %d iteration(s): %d/%d features complete (%.0f%%).\n\n",
+		name, meta.Iteration, data.Complete, data.Total, data.Completion)
+
+	if len(spec.Features) > 0 {
+		b.WriteString("## Specification\n\n")
+		for _, f := range spec.Features {
+			line := "- **" + featureLabel(f) + "**"
+			if f.IsDeferred() {
+				line += " _(deferred: " + f.Deferred + ")_"
+			}
+			if desc, _, _ := strings.Cut(strings.TrimSpace(f.Description), "\n"); desc != "" {
+				line += " — " + desc
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("<details>\n<summary>Audit report</summary>\n\n")
+	if err := WriteMarkdownReport(&b, report, meta); err != nil {
+		return "", err
+	}
+	b.WriteString("\n</details>\n")
+	return b.String(), nil
+}
+
+// validationCheck reports whether the audit's validation layers agree on
+// every feature. Contested features are neutral rather than failing: they
+// need a human look, not necessarily more work.
+func validationCheck(report *GapReport) remote.CheckRun {
+	check := remote.CheckRun{Name: validationCheckName, Conclusion: remote.ConclusionSuccess}
+	switch {
+	case len(report.Assessments) == 0:
+		check.Conclusion = remote.ConclusionNeutral
+		check.Title = "No cross-layer validation ran"
+		check.Summary = "The audit did not aggregate verdicts from multiple validation layers."
+		return check
+	case len(report.Disagreements) > 0:
+		check.Conclusion = remote.ConclusionNeutral
+		check.Title = fmt.Sprintf("%d contested feature(s)", len(report.Disagreements))
+	default:
+		check.Title = fmt.Sprintf("Validation layers agree on all %d features", len(report.Assessments))
+	}
+
+	var b strings.Builder
+	b.WriteString("| Feature | Status | Confidence | Verdicts |\n|---|---|---|---|\n")
+	for _, a := range report.Assessments {
+		fmt.Fprintf(&b, "| %s | %s | %.0f%% | %s |\n", markdownCell(a.FeatureID), a.Status, a.Confidence*100, markdownCell(verdictSummary(a.Verdicts)))
+	}
+	check.Summary = b.String()
+	return check
+}
+
+// finalVerificationCheck reports the final audit. At StrictnessAll it
+// passes only if no gaps remain and the acceptance tests of every feature
+// pass; at StrictnessCritical only the critical features must be complete
+// and pass their tests. Deferred features are reported but cannot fail it.
+func finalVerificationCheck(report *GapReport, reason StopReason, strictness Strictness) remote.CheckRun {
+	data := newReportData(report, ReportMeta{})
+	score := data.Score
+	check := remote.CheckRun{
+		Name:       finalVerificationCheckName,
+		Conclusion: remote.ConclusionSuccess,
+		Title:      fmt.Sprintf("%d/%d features complete (%.0f%%)", data.Complete, data.Total, data.Completion),
+	}
+	if score.WeightedCompletion != score.Completion {
+		check.Title = fmt.Sprintf("%d/%d features complete (%.0f%%, %.0f%% weighted)", data.Complete, data.Total, data.Completion, score.WeightedCompletion)
+	}
+	critical := strictness == StrictnessCritical && score.CriticalTotal > 0
+	if critical {
+		check.Title += fmt.Sprintf(", %d/%d critical", score.CriticalComplete, score.CriticalTotal)
+	}
+	if len(report.Gaps) > 0 {
+		check.Title += fmt.Sprintf(", %d gap(s) remaining", len(report.Gaps))
+	}
+	if score.FailingTests > 0 {
+		check.Title += fmt.Sprintf(", %d feature(s) failing acceptance tests", score.FailingTests)
+	}
+	if (critical && !score.Verified(strictness)) || (!critical && (len(report.Gaps) > 0 || score.FailingTests > 0)) {
+		check.Conclusion = remote.ConclusionFailure
+	}
+
+	check.Summary = fmt.Sprintf("The implement loop stopped (%s) with %d complete, %d partial and %d missing features.",
+		reason, data.Complete, data.Partial, data.Missing)
+	if critical {
+		check.Summary += " Only the critical features were required."
+	}
+	if len(data.Deferred) > 0 {
+		check.Summary += fmt.Sprintf(" %d deferred feature(s) were not required.", len(data.Deferred))
+	}
+	if report.Summary != "" {
+		check.Summary += "\n\n" + report.Summary
+	}
+	var b strings.Builder
+	if len(report.Gaps) > 0 {
+		b.WriteString("## Remaining Gaps\n\n")
+		writeMarkdownGaps(&b, report.Gaps)
+	}
+	if len(data.AcceptanceTests) > 0 {
+		b.WriteString("## Acceptance Tests\n\n")
+		writeMarkdownAcceptance(&b, data.AcceptanceTests)
+	}
+	if len(data.Deferred) > 0 {
+		b.WriteString("## Deferred Features\n\n")
+		for _, f := range data.Deferred {
+			fmt.Fprintf(&b, "- **%s** (%s): %s, %d gap(s)\n", featureLabel(f.Feature), f.Feature.Deferred, f.Status, len(f.Gaps))
+		}
+	}
+	check.Text = b.String()
+	return check
+}
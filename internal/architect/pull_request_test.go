@@ -0,0 +1,227 @@
+package architect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// fakeProvider records the submission it is asked to publish.
+type fakeProvider struct {
+	submitted *remote.Submission
+	err       error
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Publish(ctx context.Context, sub remote.Submission) (*remote.PullRequest, error) {
+	f.submitted = &sub
+	return &remote.PullRequest{Number: 5, URL: "https://example.com/pull/5"}, f.err
+}
+
+func samplePullRequestReport() (*ArchSpec, *GapReport) {
+	spec := &ArchSpec{
+		Name: "Payments",
+		Features: []Feature{
+			{ID: "F1", Name: "Checkout", Description: "Customers pay by card.\nMore detail."},
+			{ID: "F2", Name: "Refunds"},
+		},
+	}
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: spec.Features[0], Status: AuditStatusComplete},
+			{Feature: spec.Features[1], Status: AuditStatusMissing},
+		},
+		Gaps: []Gap{
+			{FeatureID: "F2", Status: AuditStatusMissing, Description: "No refund endpoint", SuggestedAction: "Add POST /refunds"},
+		},
+	}
+	return spec, report
+}
+
+func TestPullRequestBody(t *testing.T) {
+	spec, report := samplePullRequestReport()
+
+	body, err := pullRequestBody(spec, report, ReportMeta{SpecName: spec.Name, Iteration: 2})
+	if err != nil {
+		t.Fatalf("pullRequestBody() error = %v", err)
+	}
+	for _, want := range []string{
+		"**Payments** over 2 iteration(s): 1/2 features complete (50%)",
+		"Customers pay by card.",
+		"<summary>Audit report</summary>",
+		"# Audit Report: Payments",
+		"No refund endpoint",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	summary, _, _ := strings.Cut(body, "<details>")
+	if strings.Contains(summary, "More detail.") {
+		t.Error("spec summary should keep only the first description line")
+	}
+}
+
+func TestFinalVerificationCheck(t *testing.T) {
+	_, report := samplePullRequestReport()
+
+	check := finalVerificationCheck(report, StopReasonMaxIterations, StrictnessAll)
+	if check.Name != finalVerificationCheckName || check.Conclusion != remote.ConclusionFailure {
+		t.Errorf("check = %s %s, want a failing final verification", check.Name, check.Conclusion)
+	}
+	if !strings.Contains(check.Title, "1 gap(s) remaining") || !strings.Contains(check.Summary, "max_iterations") {
+		t.Errorf("check title %q / summary %q", check.Title, check.Summary)
+	}
+	if !strings.Contains(check.Text, "Add POST /refunds") {
+		t.Errorf("check text should list the gaps: %q", check.Text)
+	}
+
+	report.Gaps = nil
+	if check := finalVerificationCheck(report, StopReasonComplete, StrictnessAll); check.Conclusion != remote.ConclusionSuccess || check.Text != "" {
+		t.Errorf("check without gaps = %s %q, want success", check.Conclusion, check.Text)
+	}
+}
+
+func TestFinalVerificationCheck_Deferred(t *testing.T) {
+	_, report := samplePullRequestReport()
+	report.Features[1].Feature.Deferred = "phase-2"
+	report.Deferred, report.Gaps = report.Gaps, nil
+
+	check := finalVerificationCheck(report, StopReasonComplete, StrictnessAll)
+	if check.Conclusion != remote.ConclusionSuccess || check.Title != "1/1 features complete (100%)" {
+		t.Errorf("check = %s %q, want deferred features ignored", check.Conclusion, check.Title)
+	}
+	if !strings.Contains(check.Summary, "1 deferred feature(s) were not required") || !strings.Contains(check.Text, "**Refunds (F2)** (phase-2): MISSING, 1 gap(s)") {
+		t.Errorf("check should report the deferred feature: summary %q, text %q", check.Summary, check.Text)
+	}
+}
+
+func TestFinalVerificationCheck_AcceptanceTests(t *testing.T) {
+	_, report := samplePullRequestReport()
+	report.Gaps = nil
+	report.AcceptanceTests = []FeatureTestResult{
+		{FeatureID: "F1", Passed: false, Results: []SelectorResult{{Command: "go test ./checkout", Reason: "exit status 1"}}},
+	}
+
+	check := finalVerificationCheck(report, StopReasonComplete, StrictnessAll)
+	if check.Conclusion != remote.ConclusionFailure || !strings.Contains(check.Title, "1 feature(s) failing acceptance tests") {
+		t.Errorf("check = %s %q, want failing acceptance tests to fail it", check.Conclusion, check.Title)
+	}
+	if !strings.Contains(check.Text, "| Checkout (F1) | FAIL | `go test ./checkout`: FAIL (exit status 1) |") {
+		t.Errorf("check text should list the acceptance tests: %q", check.Text)
+	}
+
+	report.AcceptanceTests[0].Passed = true
+	if check := finalVerificationCheck(report, StopReasonComplete, StrictnessAll); check.Conclusion != remote.ConclusionSuccess {
+		t.Errorf("check with passing tests = %s, want success", check.Conclusion)
+	}
+}
+
+func TestValidationCheck(t *testing.T) {
+	report := &GapReport{}
+	if check := validationCheck(report); check.Conclusion != remote.ConclusionNeutral {
+		t.Errorf("check without assessments = %s, want neutral", check.Conclusion)
+	}
+
+	report.Assessments = []FeatureAssessment{
+		{FeatureID: "F1", Status: AuditStatusComplete, Confidence: 1},
+		{FeatureID: "F2", Status: AuditStatusPartial, Confidence: 0.6, Contested: true},
+	}
+	if check := validationCheck(report); check.Conclusion != remote.ConclusionSuccess {
+		t.Errorf("check without disagreements = %s, want success", check.Conclusion)
+	}
+
+	report.Disagreements = report.Assessments[1:]
+	check := validationCheck(report)
+	if check.Conclusion != remote.ConclusionNeutral || check.Title != "1 contested feature(s)" {
+		t.Errorf("check = %s %q", check.Conclusion, check.Title)
+	}
+	if !strings.Contains(check.Summary, "| F2 | PARTIAL | 60% |") {
+		t.Errorf("summary should list assessments:\n%s", check.Summary)
+	}
+}
+
+func TestController_RecordReviewConcerns(t *testing.T) {
+	c := NewController(1, 0, 0)
+	c.activeWorkers["agent-1"] = WorkerInfo{AgentID: "agent-1", TaskID: "t1", TaskTitle: "Add refunds"}
+
+	c.handleOrchestratorEvent(orchestrator.OrchestratorEvent{
+		Type:     orchestrator.EventSecondReviewCompleted,
+		TaskID:   "t1",
+		AgentID:  "agent-1",
+		Concerns: []string{"refunds.go:10 skips validation", "missing audit log"},
+	})
+	c.handleOrchestratorEvent(orchestrator.OrchestratorEvent{
+		Type:    orchestrator.EventSecondReviewCompleted,
+		TaskID:  "t2",
+		AgentID: "agent-2",
+	})
+
+	if len(c.reviewConcerns) != 2 {
+		t.Fatalf("expected 2 concerns, got %+v", c.reviewConcerns)
+	}
+	if got := c.reviewConcerns[0]; got.TaskID != "t1" || got.TaskTitle != "Add refunds" || got.Text != "refunds.go:10 skips validation" {
+		t.Errorf("concern = %+v", got)
+	}
+}
+
+func TestController_PublishesPullRequest(t *testing.T) {
+	provider := &fakeProvider{}
+	tests := []struct {
+		name string
+		opts []ControllerOption
+		want bool
+	}{
+		{"disabled", nil, false},
+		{"enabled", []ControllerOption{WithRemoteProvider(provider)}, true},
+		{"greenfield", []ControllerOption{WithRemoteProvider(provider), WithGreenfield(true)}, false},
+		{"plan only", []ControllerOption{WithRemoteProvider(provider), WithPlanOnly(true)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewController(1, 0, 0, tt.opts...).publishesPullRequest(); got != tt.want {
+				t.Errorf("publishesPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_PublishPullRequest(t *testing.T) {
+	spec, report := samplePullRequestReport()
+	provider := &fakeProvider{err: errors.New("status API unavailable")}
+	var messages []string
+	c := NewController(1, 0, 0,
+		WithRemoteProvider(provider),
+		WithProgressCallback(func(e ProgressEvent) { messages = append(messages, e.Message) }),
+	)
+	c.prBase, c.prBranch = "main", "alphie/implement-1"
+	c.reviewConcerns = []remote.Concern{{TaskID: "t1", Text: "missing audit log"}}
+
+	c.publishPullRequest(context.Background(), spec, report, 2, StopReasonMaxIterations)
+
+	sub := provider.submitted
+	if sub == nil {
+		t.Fatal("expected the run to be published")
+	}
+	if sub.Base != "main" || sub.Branch != "alphie/implement-1" || sub.Title != "Implement Payments" {
+		t.Errorf("submission = %+v", sub)
+	}
+	if len(sub.Checks) != 2 || sub.Checks[0].Name != validationCheckName || sub.Checks[1].Name != finalVerificationCheckName {
+		t.Errorf("checks = %+v", sub.Checks)
+	}
+	if len(sub.Concerns) != 1 {
+		t.Errorf("concerns = %+v", sub.Concerns)
+	}
+	if c.PullRequest() == nil || c.PullRequest().Number != 5 {
+		t.Errorf("PullRequest() = %v, want pull request 5 despite the check error", c.PullRequest())
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "Opened pull request #5") || !strings.Contains(joined, "status API unavailable") {
+		t.Errorf("progress messages = %q", joined)
+	}
+}
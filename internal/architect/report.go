@@ -0,0 +1,634 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// Report file names written by WriteReports.
+const (
+	markdownReportFile = "audit-report.md"
+	htmlReportFile     = "audit-report.html"
+)
+
+// evidenceFilePattern matches file references in audit evidence, such as
+// "internal/auth/login.go" or "main.go:42". A bare name needs a line number
+// so ordinary words with dots ("e.g.") are not mistaken for files.
+var evidenceFilePattern = regexp.MustCompile(`(?:[\w.-]+/)+[\w-][\w.-]*\.[A-Za-z0-9]+(?::\d+(?:-\d+)?)?|[\w-][\w.-]*\.[A-Za-z0-9]+:\d+(?:-\d+)?`)
+
+// ReportMeta describes the audit a report was generated from.
+type ReportMeta struct {
+	// SpecName is the name of the audited architecture specification.
+	SpecName string
+	// Iteration is the implement iteration the audit ran in (1-based).
+	Iteration int
+	// GeneratedAt is when the audit finished.
+	GeneratedAt time.Time
+	// FeatureCosts is the agent spend per feature of the iterations before
+	// the audit. The report of the final audit shows what the run spent.
+	FeatureCosts []FeatureCost
+}
+
+// reportFeature is a feature's audit result with its gaps, as rendered.
+type reportFeature struct {
+	FeatureStatus
+	Files []string
+	Gaps  []Gap
+	// Static is the static cross-check of the names the spec requires.
+	Static *StaticCheck
+}
+
+// reportFeatureCost is a feature's attributed spend, as rendered.
+type reportFeatureCost struct {
+	FeatureCost
+	Label string
+}
+
+// reportAcceptance is a feature's acceptance test result, as rendered.
+type reportAcceptance struct {
+	FeatureTestResult
+	Label string
+}
+
+// reportData is the view of a gap report shared by the renderers.
+type reportData struct {
+	Meta       ReportMeta
+	Summary    string
+	Complete   int
+	Partial    int
+	Missing    int
+	Total      int
+	Completion float64
+	// Score weighs the completion by gap severity.
+	Score    AuditScore
+	Features []reportFeature
+	// Deferred are the features the spec defers, which Complete, Partial,
+	// Missing and Total do not count.
+	Deferred []reportFeature
+	// Orphans are gaps whose feature is not in the feature list.
+	Orphans []Gap
+	// Disagreements are the features the validation layers disagree on.
+	Disagreements []FeatureAssessment
+	// AcceptanceTests are the features' acceptance test results.
+	AcceptanceTests []reportAcceptance
+	// Rubric is the review's verdict on each rubric criterion.
+	Rubric []orchestrator.CriterionVerdict
+	// Costs is the spend per feature, and CostTotal its sum.
+	Costs     []reportFeatureCost
+	CostTotal FeatureCost
+}
+
+// newReportData groups the report's gaps under their features.
+func newReportData(report *GapReport, meta ReportMeta) reportData {
+	data := reportData{Meta: meta, Summary: report.Summary, Disagreements: report.Disagreements, Rubric: report.Rubric}
+
+	gapsByFeature := make(map[string][]Gap)
+	for _, gap := range report.Gaps {
+		gapsByFeature[gap.FeatureID] = append(gapsByFeature[gap.FeatureID], gap)
+	}
+	for _, gap := range report.Deferred {
+		gapsByFeature[gap.FeatureID] = append(gapsByFeature[gap.FeatureID], gap)
+	}
+	static := staticChecks(report.StaticChecks)
+
+	for _, fs := range report.Features {
+		if fs.Feature.IsDeferred() {
+			data.Deferred = append(data.Deferred, reportFeature{
+				FeatureStatus: fs,
+				Files:         evidenceFiles(fs.Evidence),
+				Gaps:          gapsByFeature[fs.Feature.ID],
+			})
+			delete(gapsByFeature, fs.Feature.ID)
+			continue
+		}
+		data.Total++
+		switch fs.Status {
+		case AuditStatusComplete:
+			data.Complete++
+		case AuditStatusPartial:
+			data.Partial++
+		case AuditStatusMissing:
+			data.Missing++
+		}
+		rf := reportFeature{
+			FeatureStatus: fs,
+			Files:         evidenceFiles(fs.Evidence),
+			Gaps:          gapsByFeature[fs.Feature.ID],
+		}
+		if check, ok := static[fs.Feature.ID]; ok {
+			rf.Static = &check
+		}
+		data.Features = append(data.Features, rf)
+		delete(gapsByFeature, fs.Feature.ID)
+	}
+	for _, gap := range report.Gaps {
+		if _, ok := gapsByFeature[gap.FeatureID]; ok {
+			data.Orphans = append(data.Orphans, gap)
+		}
+	}
+
+	if data.Total > 0 {
+		data.Completion = float64(data.Complete) / float64(data.Total) * 100.0
+	}
+	data.Score = ScoreReport(report)
+
+	features := make(map[string]Feature, len(report.Features))
+	for _, fs := range report.Features {
+		features[fs.Feature.ID] = fs.Feature
+	}
+	for _, r := range report.AcceptanceTests {
+		f, ok := features[r.FeatureID]
+		if !ok {
+			f = Feature{ID: r.FeatureID}
+		}
+		data.AcceptanceTests = append(data.AcceptanceTests, reportAcceptance{FeatureTestResult: r, Label: featureLabel(f)})
+	}
+	for _, fc := range meta.FeatureCosts {
+		label := "Unattributed"
+		if fc.FeatureID != "" {
+			f, ok := features[fc.FeatureID]
+			if !ok {
+				f = Feature{ID: fc.FeatureID}
+			}
+			label = featureLabel(f)
+		}
+		data.Costs = append(data.Costs, reportFeatureCost{FeatureCost: fc, Label: label})
+		data.CostTotal.Tasks += fc.Tasks
+		data.CostTotal.Attempts += fc.Attempts
+		data.CostTotal.TokensUsed += fc.TokensUsed
+		data.CostTotal.Cost += fc.Cost
+	}
+	return data
+}
+
+// evidenceFiles extracts the distinct file references from audit evidence.
+func evidenceFiles(evidence string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, match := range evidenceFilePattern.FindAllString(evidence, -1) {
+		match = strings.TrimRight(match, ".")
+		if seen[match] {
+			continue
+		}
+		seen[match] = true
+		files = append(files, match)
+	}
+	return files
+}
+
+// WriteMarkdownReport renders the gap report as Markdown.
+func WriteMarkdownReport(w io.Writer, report *GapReport, meta ReportMeta) error {
+	data := newReportData(report, meta)
+	var b strings.Builder
+
+	title := "Audit Report"
+	if meta.SpecName != "" {
+		title += ": " + meta.SpecName
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if meta.Iteration > 0 {
+		fmt.Fprintf(&b, "- **Iteration:** %d\n", meta.Iteration)
+	}
+	if !meta.GeneratedAt.IsZero() {
+		fmt.Fprintf(&b, "- **Generated:** %s\n", meta.GeneratedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "- **Completion:** %.0f%% (%d/%d features complete, %d partial, %d missing)\n",
+		data.Completion, data.Complete, data.Total, data.Partial, data.Missing)
+	if data.Total > 0 {
+		fmt.Fprintf(&b, "- **Weighted completion:** %.0f%%", data.Score.WeightedCompletion)
+		if data.Score.CriticalTotal > 0 {
+			fmt.Fprintf(&b, " (%d/%d critical features complete)", data.Score.CriticalComplete, data.Score.CriticalTotal)
+		}
+		b.WriteString("\n")
+	}
+	if len(data.Deferred) > 0 {
+		fmt.Fprintf(&b, "- **Deferred:** %d feature(s) out of scope\n", len(data.Deferred))
+	}
+	b.WriteString("\n")
+	if data.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", data.Summary)
+	}
+
+	if len(data.Features) > 0 {
+		b.WriteString("## Features\n\n")
+		b.WriteString("| Feature | Status | Gaps |\n|---|---|---|\n")
+		for _, f := range data.Features {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", markdownCell(featureLabel(f.Feature)), f.Status, len(f.Gaps))
+		}
+		b.WriteString("\n")
+
+		if len(data.Disagreements) > 0 {
+			b.WriteString("## Contested Features\n\n")
+			b.WriteString("| Feature | Status | Confidence | Verdicts |\n|---|---|---|---|\n")
+			for _, d := range data.Disagreements {
+				fmt.Fprintf(&b, "| %s | %s | %.0f%% | %s |\n", markdownCell(d.FeatureID), d.Status, d.Confidence*100, markdownCell(verdictSummary(d.Verdicts)))
+			}
+			b.WriteString("\n")
+		}
+
+		if len(data.AcceptanceTests) > 0 {
+			b.WriteString("## Acceptance Tests\n\n")
+			writeMarkdownAcceptance(&b, data.AcceptanceTests)
+		}
+
+		for _, f := range data.Features {
+			fmt.Fprintf(&b, "### %s — %s\n\n", featureLabel(f.Feature), f.Status)
+			if f.Feature.Description != "" {
+				fmt.Fprintf(&b, "%s\n\n", f.Feature.Description)
+			}
+			if f.Feature.Criteria != "" {
+				fmt.Fprintf(&b, "**Criteria:** %s\n\n", f.Feature.Criteria)
+			}
+			if f.Evidence != "" {
+				fmt.Fprintf(&b, "**Evidence:** %s\n\n", f.Evidence)
+			}
+			if len(f.Files) > 0 {
+				b.WriteString("**Files:**\n\n")
+				for _, file := range f.Files {
+					fmt.Fprintf(&b, "- `%s`\n", file)
+				}
+				b.WriteString("\n")
+			}
+			if len(f.Anchors) > 0 {
+				b.WriteString("**Anchors:**\n\n")
+				for _, a := range f.Anchors {
+					fmt.Fprintf(&b, "- `%s`", a.Location())
+					if a.Symbol != "" {
+						fmt.Fprintf(&b, " `%s`", a.Symbol)
+					}
+					if a.Problem != "" {
+						fmt.Fprintf(&b, " — does not check out: %s", a.Problem)
+					}
+					b.WriteString("\n")
+				}
+				b.WriteString("\n")
+			}
+			if f.Static != nil {
+				fmt.Fprintf(&b, "**Static check:** %s\n\n", staticSummary(*f.Static))
+			}
+			if f.Reasoning != "" {
+				fmt.Fprintf(&b, "**Reasoning:** %s\n\n", f.Reasoning)
+			}
+			writeMarkdownGaps(&b, f.Gaps)
+		}
+	}
+
+	if len(data.Deferred) > 0 {
+		b.WriteString("## Deferred Features\n\n")
+		b.WriteString("| Feature | Deferred | Status | Gaps |\n|---|---|---|---|\n")
+		for _, f := range data.Deferred {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d |\n", markdownCell(featureLabel(f.Feature)), markdownCell(f.Feature.Deferred), f.Status, len(f.Gaps))
+		}
+		b.WriteString("\n")
+		for _, f := range data.Deferred {
+			if len(f.Gaps) > 0 {
+				fmt.Fprintf(&b, "### %s — %s (%s)\n\n", featureLabel(f.Feature), f.Status, f.Feature.Deferred)
+				writeMarkdownGaps(&b, f.Gaps)
+			}
+		}
+	}
+
+	if len(data.Orphans) > 0 {
+		b.WriteString("## Other Gaps\n\n")
+		writeMarkdownGaps(&b, data.Orphans)
+	}
+
+	if len(data.Rubric) > 0 {
+		b.WriteString("## Review Rubric\n\n")
+		b.WriteString("| Criterion | Verdict | Reasoning |\n|---|---|---|\n")
+		for _, v := range data.Rubric {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(v.Criterion), v.Verdict, markdownCell(v.Reasoning))
+		}
+		b.WriteString("\n")
+		concerns := 0
+		for _, v := range data.Rubric {
+			for _, c := range v.Concerns {
+				fmt.Fprintf(&b, "- **%s** %s\n", v.Criterion, c)
+				concerns++
+			}
+		}
+		if concerns > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	if len(data.Costs) > 0 {
+		b.WriteString("## Cost by Feature\n\n")
+		b.WriteString("| Feature | Tasks | Attempts | Tokens | Cost |\n|---|---|---|---|---|\n")
+		for _, c := range data.Costs {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | $%.2f |\n", markdownCell(c.Label), c.Tasks, c.Attempts, c.TokensUsed, c.Cost)
+		}
+		t := data.CostTotal
+		fmt.Fprintf(&b, "| **Total** | %d | %d | %d | $%.2f |\n\n", t.Tasks, t.Attempts, t.TokensUsed, t.Cost)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownGaps writes a feature's gaps and their suggested fixes.
+func writeMarkdownGaps(b *strings.Builder, gaps []Gap) {
+	if len(gaps) == 0 {
+		return
+	}
+	b.WriteString("**Gaps:**\n\n")
+	for _, gap := range gaps {
+		if gap.Severity != "" {
+			fmt.Fprintf(b, "- **%s** (%s) %s\n", gap.Status, gap.Severity, gap.Description)
+		} else {
+			fmt.Fprintf(b, "- **%s** %s\n", gap.Status, gap.Description)
+		}
+		if gap.SuggestedAction != "" {
+			fmt.Fprintf(b, "  - Suggested fix: %s\n", gap.SuggestedAction)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeMarkdownAcceptance writes a table of the features' acceptance test
+// results, naming the failing tests.
+func writeMarkdownAcceptance(b *strings.Builder, results []reportAcceptance) {
+	b.WriteString("| Feature | Result | Tests |\n|---|---|---|\n")
+	for _, r := range results {
+		fmt.Fprintf(b, "| %s | %s | %s |\n", markdownCell(r.Label), acceptanceResult(r.Passed), markdownCell(selectorSummary(r.Results)))
+	}
+	b.WriteString("\n")
+}
+
+// acceptanceResult names an acceptance test outcome.
+func acceptanceResult(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// selectorSummary lists the commands of acceptance tests with their
+// outcome, and why failing ones failed.
+func selectorSummary(results []SelectorResult) string {
+	parts := make([]string, 0, len(results))
+	for _, sr := range results {
+		part := fmt.Sprintf("`%s`: %s", sr.Command, acceptanceResult(sr.Passed))
+		if sr.Reason != "" {
+			part += " (" + sr.Reason + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// staticSummary lists the names a static check found, with where they are
+// declared, and the names it did not find.
+func staticSummary(check StaticCheck) string {
+	var parts []string
+	for _, ref := range check.Found {
+		parts = append(parts, fmt.Sprintf("found `%s` (%s:%d)", ref.Name, ref.File, ref.Line))
+	}
+	for _, name := range check.Missing {
+		parts = append(parts, fmt.Sprintf("missing `%s`", name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// verdictSummary lists layer verdicts as "layer: STATUS" pairs.
+func verdictSummary(verdicts []LayerVerdict) string {
+	parts := make([]string, 0, len(verdicts))
+	for _, v := range verdicts {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.Layer, v.Status))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// featureLabel names a feature by its name and ID.
+func featureLabel(f Feature) string {
+	switch {
+	case f.Name == "":
+		return f.ID
+	case f.ID == "" || f.ID == f.Name:
+		return f.Name
+	default:
+		return fmt.Sprintf("%s (%s)", f.Name, f.ID)
+	}
+}
+
+// markdownCell makes text safe for a single Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// htmlReportTemplate renders a gap report as a standalone HTML page.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"label":    featureLabel,
+	"lower":    func(s AuditStatus) string { return strings.ToLower(string(s)) },
+	"rfc3339":  func(t time.Time) string { return t.Format(time.RFC3339) },
+	"percent":  func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+	"verdicts": verdictSummary,
+	"dollars":  func(f float64) string { return fmt.Sprintf("$%.2f", f) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Audit Report{{with .Meta.SpecName}}: {{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+.status { font-weight: bold; }
+.complete { color: #1a7f37; }
+.partial { color: #9a6700; }
+.missing { color: #cf222e; }
+code { background: #f4f4f4; padding: 1px 4px; }
+</style>
+</head>
+<body>
+<h1>Audit Report{{with .Meta.SpecName}}: {{.}}{{end}}</h1>
+<ul>
+{{- if .Meta.Iteration}}
+<li><strong>Iteration:</strong> {{.Meta.Iteration}}</li>
+{{- end}}
+{{- if not .Meta.GeneratedAt.IsZero}}
+<li><strong>Generated:</strong> {{rfc3339 .Meta.GeneratedAt}}</li>
+{{- end}}
+<li><strong>Completion:</strong> {{printf "%.0f" .Completion}}% ({{.Complete}}/{{.Total}} features complete, {{.Partial}} partial, {{.Missing}} missing)</li>
+{{- if .Total}}
+<li><strong>Weighted completion:</strong> {{printf "%.0f" .Score.WeightedCompletion}}%{{if .Score.CriticalTotal}} ({{.Score.CriticalComplete}}/{{.Score.CriticalTotal}} critical features complete){{end}}</li>
+{{- end}}
+{{- with .Deferred}}
+<li><strong>Deferred:</strong> {{len .}} feature(s) out of scope</li>
+{{- end}}
+</ul>
+{{- with .Summary}}
+<p>{{.}}</p>
+{{- end}}
+{{- if .Features}}
+<h2>Features</h2>
+<table>
+<tr><th>Feature</th><th>Status</th><th>Gaps</th></tr>
+{{- range .Features}}
+<tr><td>{{label .Feature}}</td><td class="status {{lower .Status}}">{{.Status}}</td><td>{{len .Gaps}}</td></tr>
+{{- end}}
+</table>
+{{- with .Disagreements}}
+<h2>Contested Features</h2>
+<table>
+<tr><th>Feature</th><th>Status</th><th>Confidence</th><th>Verdicts</th></tr>
+{{- range .}}
+<tr><td>{{.FeatureID}}</td><td class="status {{lower .Status}}">{{.Status}}</td><td>{{percent .Confidence}}</td><td>{{verdicts .Verdicts}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .AcceptanceTests}}
+<h2>Acceptance Tests</h2>
+<table>
+<tr><th>Feature</th><th>Result</th><th>Tests</th></tr>
+{{- range .}}
+<tr><td>{{.Label}}</td><td class="status {{if .Passed}}complete{{else}}missing{{end}}">{{if .Passed}}PASS{{else}}FAIL{{end}}</td><td>
+{{- range $i, $r := .Results}}{{if $i}}<br>{{end}}<code>{{$r.Command}}</code>: {{if $r.Passed}}PASS{{else}}FAIL{{with $r.Reason}} ({{.}}){{end}}{{end}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Features}}
+<h3>{{label .Feature}} — <span class="status {{lower .Status}}">{{.Status}}</span></h3>
+{{- with .Feature.Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Feature.Criteria}}
+<p><strong>Criteria:</strong> {{.}}</p>
+{{- end}}
+{{- with .Evidence}}
+<p><strong>Evidence:</strong> {{.}}</p>
+{{- end}}
+{{- with .Files}}
+<p><strong>Files:</strong></p>
+<ul>
+{{- range .}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Anchors}}
+<p><strong>Anchors:</strong></p>
+<ul>
+{{- range .}}
+<li><code>{{.Location}}</code>{{with .Symbol}} <code>{{.}}</code>{{end}}{{with .Problem}} — does not check out: {{.}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Static}}
+<p><strong>Static check:</strong>{{$found := .Found}}
+{{- range $i, $r := .Found}}{{if $i}},{{end}} found <code>{{$r.Name}}</code> ({{$r.File}}:{{$r.Line}}){{end}}
+{{- range $i, $name := .Missing}}{{if or $i $found}},{{end}} missing <code>{{$name}}</code>{{end}}</p>
+{{- end}}
+{{- with .Reasoning}}
+<p><strong>Reasoning:</strong> {{.}}</p>
+{{- end}}
+{{- template "gaps" .Gaps}}
+{{- end}}
+{{- end}}
+{{- with .Deferred}}
+<h2>Deferred Features</h2>
+<table>
+<tr><th>Feature</th><th>Deferred</th><th>Status</th><th>Gaps</th></tr>
+{{- range .}}
+<tr><td>{{label .Feature}}</td><td>{{.Feature.Deferred}}</td><td class="status {{lower .Status}}">{{.Status}}</td><td>{{len .Gaps}}</td></tr>
+{{- end}}
+</table>
+{{- range .}}
+{{- if .Gaps}}
+<h3>{{label .Feature}} — <span class="status {{lower .Status}}">{{.Status}}</span> ({{.Feature.Deferred}})</h3>
+{{- template "gaps" .Gaps}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .Orphans}}
+<h2>Other Gaps</h2>
+{{- template "gaps" .}}
+{{- end}}
+{{- with .Rubric}}
+<h2>Review Rubric</h2>
+<table>
+<tr><th>Criterion</th><th>Verdict</th><th>Reasoning</th></tr>
+{{- range .}}
+<tr><td>{{.Criterion}}</td><td class="status {{if .Failed}}missing{{else}}complete{{end}}">{{.Verdict}}</td><td>{{.Reasoning}}
+{{- range .Concerns}}<br>{{.}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Costs}}
+<h2>Cost by Feature</h2>
+<table>
+<tr><th>Feature</th><th>Tasks</th><th>Attempts</th><th>Tokens</th><th>Cost</th></tr>
+{{- range .Costs}}
+<tr><td>{{.Label}}</td><td>{{.Tasks}}</td><td>{{.Attempts}}</td><td>{{.TokensUsed}}</td><td>{{dollars .Cost}}</td></tr>
+{{- end}}
+<tr><th>Total</th><th>{{.CostTotal.Tasks}}</th><th>{{.CostTotal.Attempts}}</th><th>{{.CostTotal.TokensUsed}}</th><th>{{dollars .CostTotal.Cost}}</th></tr>
+</table>
+{{- end}}
+</body>
+</html>
+{{define "gaps"}}
+{{- if .}}
+<p><strong>Gaps:</strong></p>
+<ul>
+{{- range .}}
+<li><span class="status {{lower .Status}}">{{.Status}}</span>{{with .Severity}} ({{.}}){{end}} {{.Description}}
+{{- with .SuggestedAction}}<br>Suggested fix: {{.}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}`))
+
+// WriteHTMLReport renders the gap report as a standalone HTML page.
+func WriteHTMLReport(w io.Writer, report *GapReport, meta ReportMeta) error {
+	return htmlReportTemplate.Execute(w, newReportData(report, meta))
+}
+
+// WriteReports writes the Markdown and HTML renderings of the gap report
+// into dir, replacing the reports of any earlier audit.
+func WriteReports(dir string, report *GapReport, meta ReportMeta) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create report directory %s: %w", dir, err)
+	}
+
+	renderers := []struct {
+		file   string
+		render func(io.Writer, *GapReport, ReportMeta) error
+	}{
+		{markdownReportFile, WriteMarkdownReport},
+		{htmlReportFile, WriteHTMLReport},
+	}
+	for _, r := range renderers {
+		var buf bytes.Buffer
+		if err := r.render(&buf, report, meta); err != nil {
+			return fmt.Errorf("render %s: %w", r.file, err)
+		}
+		if err := writeFileAtomic(filepath.Join(dir, r.file), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file and renames it into place so
+// readers never see a partial report.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package architect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleGapReport() *GapReport {
+	return &GapReport{
+		Features: []FeatureStatus{
+			{
+				Feature:   Feature{ID: "F1", Name: "Login", Description: "Users can log in"},
+				Status:    AuditStatusComplete,
+				Evidence:  "Implemented in internal/auth/login.go:12-40 and tested in internal/auth/login_test.go.",
+				Reasoning: "All criteria met",
+			},
+			{
+				Feature:  Feature{ID: "F2", Name: "Audit <log>"},
+				Status:   AuditStatusPartial,
+				Evidence: "Writer exists (audit.go:7), e.g. no rotation",
+			},
+		},
+		Gaps: []Gap{
+			{FeatureID: "F2", Status: AuditStatusPartial, Description: "No log rotation", SuggestedAction: "Rotate logs daily"},
+			{FeatureID: "F9", Status: AuditStatusMissing, Description: "Unknown feature gap"},
+		},
+		Summary: "1 of 2 features complete",
+	}
+}
+
+func TestEvidenceFiles(t *testing.T) {
+	got := evidenceFiles("See internal/auth/login.go:12-40, main.go:3 and internal/auth/login.go:12-40. Also e.g. config.")
+	want := []string{"internal/auth/login.go:12-40", "main.go:3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("evidenceFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestWriteMarkdownReport(t *testing.T) {
+	meta := ReportMeta{SpecName: "Auth", Iteration: 2, GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, sampleGapReport(), meta); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Audit Report: Auth",
+		"- **Iteration:** 2",
+		"- **Completion:** 50% (1/2 features complete, 1 partial, 0 missing)",
+		"| Login (F1) | COMPLETE | 0 |",
+		"### Audit <log> (F2) — PARTIAL",
+		"- `internal/auth/login.go:12-40`",
+		"- `internal/auth/login_test.go`",
+		"- `audit.go:7`",
+		"- **PARTIAL** No log rotation\n  - Suggested fix: Rotate logs daily",
+		"## Other Gaps",
+		"Unknown feature gap",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdownReport_Deferred(t *testing.T) {
+	report := sampleGapReport()
+	report.Features = append(report.Features, FeatureStatus{
+		Feature: Feature{ID: "F3", Name: "SSO", Deferred: "phase-2"},
+		Status:  AuditStatusMissing,
+	})
+	report.Deferred = []Gap{{FeatureID: "F3", Status: AuditStatusMissing, Description: "No SAML support"}}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		// The deferred feature does not count against completion
+		"- **Completion:** 50% (1/2 features complete, 1 partial, 0 missing)",
+		"- **Deferred:** 1 feature(s) out of scope",
+		"## Deferred Features",
+		"| SSO (F3) | phase-2 | MISSING | 1 |",
+		"### SSO (F3) — MISSING (phase-2)",
+		"No SAML support",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "| SSO (F3) | MISSING |") {
+		t.Error("deferred feature should not be listed with the in-scope features")
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, sampleGapReport(), ReportMeta{SpecName: "Auth"}); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>Audit Report: Auth</title>",
+		`<td class="status complete">COMPLETE</td>`,
+		"Audit &lt;log&gt; (F2)",
+		"<li><code>internal/auth/login.go:12-40</code></li>",
+		"Suggested fix: Rotate logs daily",
+		"<h2>Other Gaps</h2>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Audit <log>") {
+		t.Error("feature name was not escaped")
+	}
+}
+
+func TestWriteReports_FeatureCosts(t *testing.T) {
+	meta := ReportMeta{FeatureCosts: []FeatureCost{
+		{FeatureID: "F2", Tasks: 2, Attempts: 3, TokensUsed: 12000, Cost: 1.5},
+		{FeatureID: "", Tasks: 1, Attempts: 1, TokensUsed: 800, Cost: 0.25},
+	}}
+
+	var md bytes.Buffer
+	if err := WriteMarkdownReport(&md, sampleGapReport(), meta); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	for _, want := range []string{
+		"## Cost by Feature",
+		"| Audit <log> (F2) | 2 | 3 | 12000 | $1.50 |",
+		"| Unattributed | 1 | 1 | 800 | $0.25 |",
+		"| **Total** | 3 | 4 | 12800 | $1.75 |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTMLReport(&html, sampleGapReport(), meta); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	if !strings.Contains(html.String(), "<h2>Cost by Feature</h2>") || !strings.Contains(html.String(), "<td>$1.50</td>") {
+		t.Errorf("html report missing feature costs:\n%s", html.String())
+	}
+
+	// Reports from before any agent ran have no cost section
+	md.Reset()
+	if err := WriteMarkdownReport(&md, sampleGapReport(), ReportMeta{}); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	if strings.Contains(md.String(), "Cost by Feature") {
+		t.Error("report without costs has a cost section")
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	if err := WriteReports(dir, sampleGapReport(), ReportMeta{}); err != nil {
+		t.Fatalf("WriteReports: %v", err)
+	}
+	for _, name := range []string{markdownReportFile, htmlReportFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if !strings.Contains(string(data), "No log rotation") {
+			t.Errorf("%s missing gap", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name+".tmp")); !os.IsNotExist(err) {
+			t.Errorf("%s temp file left behind", name)
+		}
+	}
+}
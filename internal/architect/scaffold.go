@@ -0,0 +1,209 @@
+package architect
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// scaffoldMarker opens every scaffolded spec. Specs starting with it are
+// parsed by parseScaffold instead of Claude.
+const scaffoldMarker = "<!-- alphie-spec: scaffold v1 -->"
+
+// scaffoldFeatureCount is the number of placeholder features of a scaffold
+// generated without feature names.
+const scaffoldFeatureCount = 3
+
+var (
+	// scaffoldFeaturePattern matches a feature heading: ### F001: Name.
+	scaffoldFeaturePattern = regexp.MustCompile(`^###\s+([A-Za-z][\w.-]*)\s*:\s*(.+?)\s*$`)
+	// scaffoldFieldPattern matches a feature field: - Priority: critical.
+	scaffoldFieldPattern = regexp.MustCompile(`^[-*]\s+(?i:(priority|scope))\s*:\s*(.*?)\s*$`)
+	// scaffoldCriteriaPattern matches the line introducing the acceptance
+	// criteria of a feature.
+	scaffoldCriteriaPattern = regexp.MustCompile(`^(?i:\**acceptance criteria:?\**:?)$`)
+)
+
+// ScaffoldOptions describes the spec to scaffold.
+type ScaffoldOptions struct {
+	// Name is the spec's title. Empty derives it from Description.
+	Name string
+	// Description is the short description of the project.
+	Description string
+	// Features names the features to scaffold, in order. Empty scaffolds
+	// placeholder features.
+	Features []string
+	// ProtectedAreas lists paths or globs agents must not change without
+	// review.
+	ProtectedAreas []string
+}
+
+// ScaffoldSpec renders a Markdown spec template for a project: an overview,
+// one section per feature with a priority, a scope and acceptance criteria
+// placeholders, and the protected areas. Scaffolded specs are parsed
+// without Claude as long as their features keep the template's layout.
+func ScaffoldSpec(opts ScaffoldOptions) string {
+	description := strings.TrimSpace(opts.Description)
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = scaffoldName(description)
+	}
+	features := opts.Features
+	if len(features) == 0 {
+		for i := 1; i <= scaffoldFeatureCount; i++ {
+			features = append(features, fmt.Sprintf("Feature %d", i))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(scaffoldMarker + "\n")
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	if description != "" {
+		b.WriteString(description + "\n\n")
+	}
+	b.WriteString(`<!--
+alphie reads the features of this spec from the "### ID: Name" sections
+under "## Features". Keep that layout when editing:
+- Priority is critical (gaps are fixed first) or normal.
+- Scope is "in scope", or optional, deferred or phase-2 to audit and report
+  the feature without planning it (see implement --include-deferred).
+- Each acceptance criterion is an observable, testable outcome.
+Text outside "## Features" is context for the reader only.
+-->
+
+## Overview
+
+TODO: Describe the users, the main workflow and the technology constraints.
+
+## Features
+`)
+	for i, feature := range features {
+		fmt.Fprintf(&b, "\n### F%03d: %s\n\n", i+1, strings.TrimSpace(feature))
+		b.WriteString("- Priority: normal\n")
+		b.WriteString("- Scope: in scope\n\n")
+		b.WriteString("TODO: Describe what the feature does and who uses it.\n\n")
+		b.WriteString("Acceptance criteria:\n")
+		b.WriteString("- TODO: An observable outcome, such as a command, request or test and its expected result.\n")
+		b.WriteString("- TODO: An edge case or error and how it is handled.\n")
+	}
+
+	b.WriteString("\n## Protected Areas\n\n")
+	b.WriteString("Paths agents must not change without review. These are not features; enforce\n")
+	b.WriteString("them with rules in .alphie/protected.yml.\n\n")
+	if len(opts.ProtectedAreas) == 0 {
+		b.WriteString("- TODO: A path or glob, such as `migrations/**`, and why it is protected.\n")
+	}
+	for _, area := range opts.ProtectedAreas {
+		fmt.Fprintf(&b, "- `%s`\n", strings.TrimSpace(area))
+	}
+	return b.String()
+}
+
+// scaffoldName derives a spec title from the first sentence of a project
+// description.
+func scaffoldName(description string) string {
+	name := description
+	if i := strings.IndexAny(name, ".\n"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 60 {
+		return "Project Specification"
+	}
+	return name
+}
+
+// parseScaffold extracts the features of a scaffolded spec without Claude.
+// It returns nil if content is not a scaffold or has no feature sections
+// left, so the spec is parsed by Claude instead.
+func parseScaffold(content string) (*ArchSpec, error) {
+	if !strings.HasPrefix(strings.TrimSpace(content), scaffoldMarker) {
+		return nil, nil
+	}
+
+	spec := &ArchSpec{}
+	seen := make(map[string]bool)
+	var (
+		current     *Feature
+		description []string
+		criteria    []string
+		inFeatures  bool
+		inCriteria  bool
+		inComment   bool
+	)
+	flush := func() {
+		if current != nil {
+			current.Description = strings.TrimSpace(strings.Join(description, "\n"))
+			current.Criteria = strings.TrimSpace(strings.Join(criteria, "\n"))
+			spec.Features = append(spec.Features, *current)
+		}
+		current, description, criteria, inCriteria = nil, nil, nil, false
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inComment {
+			inComment = !strings.Contains(trimmed, "-->")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "<!--") {
+			inComment = !strings.Contains(trimmed, "-->")
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "# ") && spec.Name == "":
+			spec.Name = strings.TrimSpace(trimmed[2:])
+			continue
+		case strings.HasPrefix(trimmed, "## "):
+			flush()
+			inFeatures = strings.EqualFold(strings.TrimSpace(trimmed[3:]), "Features")
+			continue
+		}
+		if !inFeatures {
+			continue
+		}
+
+		if m := scaffoldFeaturePattern.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			if seen[m[1]] {
+				return nil, fmt.Errorf("feature ID %s is used twice", m[1])
+			}
+			seen[m[1]] = true
+			current = &Feature{ID: m[1], Name: m[2]}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if m := scaffoldFieldPattern.FindStringSubmatch(trimmed); m != nil && !inCriteria {
+			value := strings.ToLower(m[2])
+			if strings.EqualFold(m[1], "priority") {
+				current.Critical = value == "critical" || value == "must-have" || value == "p0"
+			} else if value != "in scope" {
+				current.Deferred = normalizeDeferred(value)
+			}
+			continue
+		}
+		if scaffoldCriteriaPattern.MatchString(trimmed) {
+			inCriteria = true
+			continue
+		}
+		if inCriteria {
+			if trimmed != "" {
+				criteria = append(criteria, trimmed)
+			}
+		} else {
+			description = append(description, line)
+		}
+	}
+	flush()
+
+	if len(spec.Features) == 0 {
+		return nil, nil
+	}
+	if spec.Name == "" {
+		spec.Name = "Specification"
+	}
+	return spec, nil
+}
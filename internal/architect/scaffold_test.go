@@ -0,0 +1,104 @@
+package architect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffoldSpec_ParsesWithoutClaude(t *testing.T) {
+	content := ScaffoldSpec(ScaffoldOptions{
+		Description:    "A URL shortener with analytics. Runs on Postgres.",
+		Features:       []string{"Shorten URLs", "Redirects", "Click analytics"},
+		ProtectedAreas: []string{"migrations/**"},
+	})
+	if !strings.Contains(content, "`migrations/**`") {
+		t.Errorf("expected the protected area in the scaffold, got:\n%s", content)
+	}
+
+	specPath := filepath.Join(t.TempDir(), "SPEC.md")
+	if err := os.WriteFile(specPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	starts := 0
+	runner := &countingRunner{scriptedRunner{reply: `{"name": "x", "features": []}`}, &starts}
+	spec, err := NewParser().Parse(context.Background(), specPath, runner)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if starts != 0 {
+		t.Errorf("Parse() called Claude %d times, want none", starts)
+	}
+	if spec.Name != "A URL shortener with analytics" {
+		t.Errorf("Name = %q", spec.Name)
+	}
+	if len(spec.Features) != 3 {
+		t.Fatalf("got %d features, want 3: %+v", len(spec.Features), spec.Features)
+	}
+	f := spec.Features[1]
+	if f.ID != "F002" || f.Name != "Redirects" || f.Critical || f.IsDeferred() {
+		t.Errorf("feature = %+v", f)
+	}
+	if !strings.HasPrefix(f.Description, "TODO: Describe") || strings.Count(f.Criteria, "- TODO") != 2 {
+		t.Errorf("description = %q, criteria = %q", f.Description, f.Criteria)
+	}
+}
+
+func TestParseScaffold_EditedSpec(t *testing.T) {
+	content := scaffoldMarker + `
+# Shop
+
+## Overview
+
+### X1: Not a feature
+
+## Features
+
+### CART-1: Cart
+- Priority: critical
+- Scope: in scope
+
+Users keep items in a cart.
+<!-- a note
+spanning lines -->
+
+**Acceptance criteria:**
+- POST /cart returns 201
+- Scope: a criterion, not a field
+
+### CART-2: Wishlist
+- Scope: Phase 2
+
+## Protected Areas
+
+### P1: Not a feature either
+`
+	spec, err := parseScaffold(content)
+	if err != nil {
+		t.Fatalf("parseScaffold() error = %v", err)
+	}
+	if spec == nil || len(spec.Features) != 2 {
+		t.Fatalf("parseScaffold() = %+v, want 2 features", spec)
+	}
+	cart, wishlist := spec.Features[0], spec.Features[1]
+	if !cart.Critical || cart.Description != "Users keep items in a cart." {
+		t.Errorf("cart = %+v", cart)
+	}
+	if cart.Criteria != "- POST /cart returns 201\n- Scope: a criterion, not a field" {
+		t.Errorf("cart criteria = %q", cart.Criteria)
+	}
+	if wishlist.Deferred != "phase-2" || wishlist.Criteria != "" {
+		t.Errorf("wishlist = %+v", wishlist)
+	}
+
+	if _, err := parseScaffold(content + "\n## Features\n### CART-1: Again\n"); err == nil {
+		t.Error("expected an error for a duplicate feature ID")
+	}
+	for _, other := range []string{"# Spec\n\n### F1: Login\n", scaffoldMarker + "\n# Spec\n\n## Features\n"} {
+		if spec, err := parseScaffold(other); spec != nil || err != nil {
+			t.Errorf("parseScaffold(%q) = %+v, %v; want nil to fall back to Claude", other, spec, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package architect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GapSeverity is how much of a feature a gap leaves unfinished.
+type GapSeverity string
+
+const (
+	// SeverityLow is a cosmetic or edge-case gap.
+	SeverityLow GapSeverity = "low"
+	// SeverityMedium is a gap in secondary behavior.
+	SeverityMedium GapSeverity = "medium"
+	// SeverityHigh is a gap in the feature's main behavior.
+	SeverityHigh GapSeverity = "high"
+	// SeverityCritical is a gap that makes the feature unusable.
+	SeverityCritical GapSeverity = "critical"
+)
+
+// criticalFeatureWeight is how much more a critical feature counts toward
+// weighted completion than other features.
+const criticalFeatureWeight = 2.0
+
+// partialCredit is the credit of a PARTIAL feature without a gap to score.
+const partialCredit = 0.5
+
+// Weight is the share of a feature the gap leaves unfinished, from 0 to 1.
+func (s GapSeverity) Weight() float64 {
+	switch s {
+	case SeverityLow:
+		return 0.25
+	case SeverityMedium:
+		return 0.5
+	case SeverityHigh:
+		return 0.75
+	case SeverityCritical:
+		return 1.0
+	default:
+		return partialCredit
+	}
+}
+
+// parseGapSeverity returns the severity a string names, and false if it
+// names none.
+func parseGapSeverity(s string) (GapSeverity, bool) {
+	switch sev := GapSeverity(strings.ToLower(strings.TrimSpace(s))); sev {
+	case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		return sev, true
+	default:
+		return "", false
+	}
+}
+
+// gapSeverity returns the severity the auditor reported for a gap, or one
+// derived from its status: high if MISSING, medium if PARTIAL.
+func gapSeverity(reported string, status AuditStatus) GapSeverity {
+	if sev, ok := parseGapSeverity(reported); ok {
+		return sev
+	}
+	if status == AuditStatusMissing {
+		return SeverityHigh
+	}
+	return SeverityMedium
+}
+
+// ScoringMode selects how the completion percentage is computed.
+type ScoringMode string
+
+const (
+	// ScoringStrict counts the share of features that are COMPLETE.
+	ScoringStrict ScoringMode = "strict"
+	// ScoringWeighted gives PARTIAL features partial credit by the severity
+	// of their gaps, and counts critical features double.
+	ScoringWeighted ScoringMode = "weighted"
+)
+
+// ParseScoringMode validates a scoring mode name. Empty means strict.
+func ParseScoringMode(s string) (ScoringMode, error) {
+	switch mode := ScoringMode(s); mode {
+	case "":
+		return ScoringStrict, nil
+	case ScoringStrict, ScoringWeighted:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown scoring mode %q (want strict or weighted)", s)
+	}
+}
+
+// Strictness selects what final verification requires.
+type Strictness string
+
+const (
+	// StrictnessAll requires every feature to be complete.
+	StrictnessAll Strictness = "all"
+	// StrictnessCritical requires every critical feature to be complete;
+	// the gaps left in other features are reported but do not fail it. A
+	// spec without critical features requires every feature.
+	StrictnessCritical Strictness = "critical"
+)
+
+// ParseStrictness validates a verification strictness name. Empty means all.
+func ParseStrictness(s string) (Strictness, error) {
+	switch strictness := Strictness(s); strictness {
+	case "":
+		return StrictnessAll, nil
+	case StrictnessAll, StrictnessCritical:
+		return strictness, nil
+	default:
+		return "", fmt.Errorf("unknown verification strictness %q (want all or critical)", s)
+	}
+}
+
+// AuditScore summarizes the completion of an audit's in-scope features.
+type AuditScore struct {
+	// Complete and Total count the in-scope features.
+	Complete int `json:"complete"`
+	Total    int `json:"total"`
+	// Completion is the percentage of features that are complete.
+	Completion float64 `json:"completion"`
+	// WeightedCompletion is the percentage with partial credit (see
+	// ScoringWeighted).
+	WeightedCompletion float64 `json:"weighted_completion"`
+	// CriticalComplete and CriticalTotal count the critical features.
+	CriticalComplete int `json:"critical_complete"`
+	CriticalTotal    int `json:"critical_total"`
+	// FailingTests counts the in-scope features whose acceptance tests fail.
+	FailingTests int `json:"failing_tests,omitempty"`
+	// CriticalFailingTests counts the critical ones among them.
+	CriticalFailingTests int `json:"critical_failing_tests,omitempty"`
+}
+
+// ScoreReport scores the in-scope features of a gap report. A COMPLETE
+// feature earns full credit and a MISSING one none; a PARTIAL feature loses
+// the weight of its most severe gap.
+func ScoreReport(report *GapReport) AuditScore {
+	var score AuditScore
+	worst := make(map[string]float64)
+	for _, gap := range report.Gaps {
+		worst[gap.FeatureID] = max(worst[gap.FeatureID], gap.Severity.Weight())
+	}
+	failing := make(map[string]bool)
+	for _, r := range report.AcceptanceTests {
+		failing[r.FeatureID] = !r.Passed
+	}
+
+	var earned, possible float64
+	for _, fs := range report.Features {
+		f := fs.Feature
+		if f.IsDeferred() {
+			continue
+		}
+		score.Total++
+		weight := 1.0
+		if f.Critical {
+			score.CriticalTotal++
+			weight = criticalFeatureWeight
+		}
+		if failing[f.ID] {
+			score.FailingTests++
+			if f.Critical {
+				score.CriticalFailingTests++
+			}
+		}
+
+		possible += weight
+		switch fs.Status {
+		case AuditStatusComplete:
+			score.Complete++
+			if f.Critical {
+				score.CriticalComplete++
+			}
+			earned += weight
+		case AuditStatusPartial:
+			missing, ok := worst[f.ID]
+			if !ok {
+				missing = partialCredit
+			}
+			earned += weight * (1 - missing)
+		}
+	}
+
+	if score.Total > 0 {
+		score.Completion = float64(score.Complete) / float64(score.Total) * 100.0
+		score.WeightedCompletion = earned / possible * 100.0
+	}
+	return score
+}
+
+// Percent returns the completion percentage under mode.
+func (s AuditScore) Percent(mode ScoringMode) float64 {
+	if mode == ScoringWeighted {
+		return s.WeightedCompletion
+	}
+	return s.Completion
+}
+
+// Verified reports whether the audit passes final verification at the
+// given strictness: every (critical) feature complete with passing
+// acceptance tests.
+func (s AuditScore) Verified(strictness Strictness) bool {
+	if strictness == StrictnessCritical && s.CriticalTotal > 0 {
+		return s.CriticalComplete == s.CriticalTotal && s.CriticalFailingTests == 0
+	}
+	return s.Complete == s.Total && s.FailingTests == 0
+}
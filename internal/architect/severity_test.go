@@ -0,0 +1,143 @@
+package architect
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+func TestGapSeverity(t *testing.T) {
+	tests := []struct {
+		reported string
+		status   AuditStatus
+		want     GapSeverity
+	}{
+		{"low", AuditStatusPartial, SeverityLow},
+		{" Critical ", AuditStatusPartial, SeverityCritical},
+		{"", AuditStatusMissing, SeverityHigh},
+		{"", AuditStatusPartial, SeverityMedium},
+		{"blocker", AuditStatusPartial, SeverityMedium},
+	}
+	for _, tt := range tests {
+		if got := gapSeverity(tt.reported, tt.status); got != tt.want {
+			t.Errorf("gapSeverity(%q, %s) = %s, want %s", tt.reported, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestParseAuditResponse_Severity(t *testing.T) {
+	response := `{
+		"features": [{"feature_id": "F1", "status": "PARTIAL"}],
+		"gaps": [
+			{"feature_id": "F1", "status": "PARTIAL", "description": "No retries", "severity": "low"},
+			{"feature_id": "F1", "status": "MISSING", "description": "No handler"}
+		]
+	}`
+	report, err := NewAuditor().parseAuditResponse(response, []Feature{{ID: "F1", Name: "Sync"}})
+	if err != nil {
+		t.Fatalf("parseAuditResponse() error = %v", err)
+	}
+	if report.Gaps[0].Severity != SeverityLow || report.Gaps[1].Severity != SeverityHigh {
+		t.Errorf("severities = %s, %s, want the reported low and MISSING's high", report.Gaps[0].Severity, report.Gaps[1].Severity)
+	}
+}
+
+func TestScoreReport(t *testing.T) {
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1", Critical: true}, Status: AuditStatusComplete},
+			{Feature: Feature{ID: "F2", Critical: true}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "F3"}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "F4"}, Status: AuditStatusMissing},
+			{Feature: Feature{ID: "F5"}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "F6", Deferred: "phase-2"}, Status: AuditStatusMissing},
+		},
+		Gaps: []Gap{
+			{FeatureID: "F2", Severity: SeverityLow},
+			{FeatureID: "F3", Severity: SeverityLow},
+			{FeatureID: "F3", Severity: SeverityHigh},
+			{FeatureID: "F4", Severity: SeverityHigh},
+		},
+		AcceptanceTests: []FeatureTestResult{{FeatureID: "F3", Passed: false}, {FeatureID: "F6", Passed: false}},
+	}
+
+	score := ScoreReport(report)
+	if score.Complete != 1 || score.Total != 5 || score.CriticalComplete != 1 || score.CriticalTotal != 2 {
+		t.Errorf("score counts = %+v", score)
+	}
+	// The deferred feature's failing tests do not count
+	if score.FailingTests != 1 || score.CriticalFailingTests != 0 {
+		t.Errorf("failing tests = %d (%d critical), want 1 (0)", score.FailingTests, score.CriticalFailingTests)
+	}
+	if score.Completion != 20 {
+		t.Errorf("Completion = %.1f, want 20", score.Completion)
+	}
+	// F1 2 + F2 2*0.75 + F3 0.25 (worst gap high) + F4 0 + F5 0.5 (no gap) of 7
+	want := (2 + 1.5 + 0.25 + 0 + 0.5) / 7 * 100
+	if math.Abs(score.WeightedCompletion-want) > 1e-9 {
+		t.Errorf("WeightedCompletion = %.2f, want %.2f", score.WeightedCompletion, want)
+	}
+	if score.Percent(ScoringStrict) != score.Completion || score.Percent(ScoringWeighted) != score.WeightedCompletion {
+		t.Error("Percent() does not follow the scoring mode")
+	}
+}
+
+func TestAuditScore_Verified(t *testing.T) {
+	tests := []struct {
+		name       string
+		score      AuditScore
+		strictness Strictness
+		want       bool
+	}{
+		{"all complete", AuditScore{Complete: 3, Total: 3}, StrictnessAll, true},
+		{"one incomplete", AuditScore{Complete: 2, Total: 3, CriticalComplete: 1, CriticalTotal: 1}, StrictnessAll, false},
+		{"failing tests", AuditScore{Complete: 3, Total: 3, FailingTests: 1}, StrictnessAll, false},
+		{"critical complete", AuditScore{Complete: 2, Total: 3, CriticalComplete: 1, CriticalTotal: 1, FailingTests: 1}, StrictnessCritical, true},
+		{"critical failing tests", AuditScore{Complete: 3, Total: 3, CriticalComplete: 1, CriticalTotal: 1, FailingTests: 1, CriticalFailingTests: 1}, StrictnessCritical, false},
+		{"no critical features", AuditScore{Complete: 2, Total: 3}, StrictnessCritical, false},
+	}
+	for _, tt := range tests {
+		if got := tt.score.Verified(tt.strictness); got != tt.want {
+			t.Errorf("%s: Verified(%s) = %v, want %v", tt.name, tt.strictness, got, tt.want)
+		}
+	}
+}
+
+func TestParseScoringModeAndStrictness(t *testing.T) {
+	if mode, err := ParseScoringMode(""); err != nil || mode != ScoringStrict {
+		t.Errorf("ParseScoringMode(\"\") = %q, %v", mode, err)
+	}
+	if _, err := ParseScoringMode("lenient"); err == nil {
+		t.Error("ParseScoringMode() accepted an unknown mode")
+	}
+	if s, err := ParseStrictness("critical"); err != nil || s != StrictnessCritical {
+		t.Errorf("ParseStrictness(critical) = %q, %v", s, err)
+	}
+	if _, err := ParseStrictness("most"); err == nil {
+		t.Error("ParseStrictness() accepted an unknown strictness")
+	}
+}
+
+func TestFinalVerificationCheck_CriticalStrictness(t *testing.T) {
+	_, report := samplePullRequestReport()
+	report.Features[0].Feature.Critical = true
+	report.Gaps[0].Severity = SeverityHigh
+
+	check := finalVerificationCheck(report, StopReasonComplete, StrictnessCritical)
+	if check.Conclusion != remote.ConclusionSuccess {
+		t.Errorf("check = %s %q, want success with the critical feature complete", check.Conclusion, check.Title)
+	}
+	if check.Title != "1/2 features complete (50%, 67% weighted), 1/1 critical, 1 gap(s) remaining" {
+		t.Errorf("check title = %q", check.Title)
+	}
+
+	if check := finalVerificationCheck(report, StopReasonComplete, StrictnessAll); check.Conclusion != remote.ConclusionFailure {
+		t.Errorf("check at strictness all = %s, want failure", check.Conclusion)
+	}
+
+	report.Features[0].Status = AuditStatusPartial
+	if check := finalVerificationCheck(report, StopReasonComplete, StrictnessCritical); check.Conclusion != remote.ConclusionFailure {
+		t.Errorf("check with an incomplete critical feature = %s, want failure", check.Conclusion)
+	}
+}
@@ -0,0 +1,374 @@
+package architect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// staticFoundConfidence is the weight of a MISSING claim's required names
+// all being declared: they may be unused scaffolding.
+const staticFoundConfidence = 0.4
+
+// maxInventoryNames caps the exported API listed in the audit prompt.
+const maxInventoryNames = 200
+
+var (
+	// specNamePattern matches a backticked span in a spec.
+	specNamePattern = regexp.MustCompile("`([^`\n]+)`")
+	// identPattern matches an exported Go name, optionally qualified by a
+	// package or receiver, as in Server, auth.Login or Server.Shutdown().
+	identPattern = regexp.MustCompile(`^(?:[A-Za-z_]\w*\.)?[A-Z]\w*(?:\(\))?$`)
+	// routePattern matches an HTTP route, optionally with its method.
+	routePattern = regexp.MustCompile(`^(?:(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)\s+)?(/[\w\-./{}:<>*]*)$`)
+)
+
+// SymbolRef locates a declaration or route literal in the repository.
+type SymbolRef struct {
+	// Name is the declared name, Recv.Name for methods and fields, or the
+	// route as written.
+	Name string `json:"name"`
+	// Kind is func, method, type, field, const, var or route.
+	Kind string `json:"kind"`
+	// File is the path relative to the repository root, and Line 1-based.
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// SymbolIndex is an inventory of the Go declarations and HTTP route
+// literals in a repository, built without type checking.
+type SymbolIndex struct {
+	// Files counts the Go files indexed.
+	Files int
+	// Symbols are the declarations, in file order.
+	Symbols []SymbolRef
+	// byName indexes Symbols by name, Recv.Name and pkg.Name.
+	byName map[string][]int
+	// routes maps normalized route paths to their literals.
+	routes map[string][]SymbolRef
+	// exported lists the exported names per package directory.
+	exported map[string][]string
+}
+
+// BuildSymbolIndex parses the non-test Go files of a repository. Files that
+// do not parse are indexed as far as they do.
+func BuildSymbolIndex(repoPath string) (*SymbolIndex, error) {
+	idx := &SymbolIndex{
+		byName:   make(map[string][]int),
+		routes:   make(map[string][]SymbolRef),
+		exported: make(map[string][]string),
+	}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != repoPath && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+		file, _ := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if file == nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(repoPath, path)
+		idx.addFile(fset, filepath.ToSlash(rel), file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("index symbols: %w", err)
+	}
+	return idx, nil
+}
+
+// addFile indexes one parsed file.
+func (x *SymbolIndex) addFile(fset *token.FileSet, rel string, file *ast.File) {
+	x.Files++
+	pkg := file.Name.Name
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	add := func(name, recv, kind string, pos token.Pos) {
+		ref := SymbolRef{Name: name, Kind: kind, File: rel, Line: fset.Position(pos).Line}
+		keys := []string{name, pkg + "." + name}
+		if recv != "" {
+			ref.Name = recv + "." + name
+			keys = []string{ref.Name, name}
+		}
+		x.Symbols = append(x.Symbols, ref)
+		for _, key := range keys {
+			x.byName[key] = append(x.byName[key], len(x.Symbols)-1)
+		}
+		if ast.IsExported(name) && (recv == "" || ast.IsExported(recv)) {
+			x.exported[dir] = append(x.exported[dir], ref.Name)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add(d.Name.Name, receiverName(d.Recv.List[0].Type), "method", d.Name.Pos())
+			} else {
+				add(d.Name.Name, "", "func", d.Name.Pos())
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name.Name, "", "type", s.Name.Pos())
+					x.addMembers(s, add)
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, n := range s.Names {
+						add(n.Name, "", kind, n.Pos())
+					}
+				}
+			}
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if value, err := strconv.Unquote(lit.Value); err == nil {
+			if route, ok := normalizeRoute(value); ok {
+				x.routes[route] = append(x.routes[route], SymbolRef{Name: value, Kind: "route", File: rel, Line: fset.Position(lit.Pos()).Line})
+			}
+		}
+		return true
+	})
+}
+
+// addMembers indexes the fields of a struct and the methods of an interface.
+func (x *SymbolIndex) addMembers(s *ast.TypeSpec, add func(name, recv, kind string, pos token.Pos)) {
+	var fields *ast.FieldList
+	kind := "field"
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields, kind = t.Methods, "method"
+	}
+	if fields == nil {
+		return
+	}
+	for _, f := range fields.List {
+		for _, n := range f.Names {
+			add(n.Name, s.Name.Name, kind, n.Pos())
+		}
+	}
+}
+
+// receiverName returns the type name of a method receiver.
+func receiverName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// normalizeRoute reduces a route to its path with parameters written {},
+// so /users/{id}, /users/:id and GET /users/<id> compare equal. It returns
+// false if s is not a route.
+func normalizeRoute(s string) (string, bool) {
+	m := routePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || len(m[1]) < 2 {
+		return "", false
+	}
+	segments := strings.Split(strings.TrimSuffix(m[1], "/"), "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") || strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "<") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// Lookup returns the declarations a name refers to: a bare name, a
+// package-qualified name or a Recv.Name method or field.
+func (x *SymbolIndex) Lookup(name string) []SymbolRef {
+	var refs []SymbolRef
+	for _, i := range x.byName[strings.TrimSuffix(name, "()")] {
+		refs = append(refs, x.Symbols[i])
+	}
+	return refs
+}
+
+// LookupRoute returns the route literals matching a route.
+func (x *SymbolIndex) LookupRoute(route string) []SymbolRef {
+	norm, ok := normalizeRoute(route)
+	if !ok {
+		return nil
+	}
+	return x.routes[norm]
+}
+
+// StaticCheck is the static cross-check of the names a feature's spec
+// requires: the backticked exported Go names and HTTP routes in its
+// description and criteria.
+type StaticCheck struct {
+	FeatureID string `json:"feature_id"`
+	// Found locates the first declaration of each required name that
+	// exists, under the name as the spec wrote it.
+	Found []SymbolRef `json:"found,omitempty"`
+	// Missing lists the required names the code does not declare.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// requiredNames returns the exported Go names and routes a feature's spec
+// requires. Other backticked text, such as file names and commands, is
+// ignored.
+func requiredNames(f Feature) (symbols, routes []string) {
+	seen := make(map[string]bool)
+	for _, m := range specNamePattern.FindAllStringSubmatch(f.Description+"\n"+f.Criteria, -1) {
+		name := strings.TrimSpace(m[1])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, ok := normalizeRoute(name); ok {
+			routes = append(routes, name)
+		} else if identPattern.MatchString(name) {
+			symbols = append(symbols, name)
+		}
+	}
+	return symbols, routes
+}
+
+// CrossCheck looks up the names each feature requires. Features that
+// require none are skipped.
+func (x *SymbolIndex) CrossCheck(features []Feature) []StaticCheck {
+	var checks []StaticCheck
+	for _, f := range features {
+		symbols, routes := requiredNames(f)
+		if len(symbols)+len(routes) == 0 {
+			continue
+		}
+		check := StaticCheck{FeatureID: f.ID}
+		record := func(name string, refs []SymbolRef) {
+			if len(refs) == 0 {
+				check.Missing = append(check.Missing, name)
+				return
+			}
+			ref := refs[0]
+			ref.Name = name
+			check.Found = append(check.Found, ref)
+		}
+		for _, s := range symbols {
+			record(s, x.Lookup(s))
+		}
+		for _, r := range routes {
+			record(r, x.LookupRoute(r))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// staticVerdict is the static analysis layer's verdict on a feature. A
+// COMPLETE or PARTIAL claim whose required names are all missing is
+// downgraded with the audit's own confidence, and a COMPLETE claim missing
+// some of them is contested. A MISSING claim whose required names all
+// exist is contested weakly. It returns false if the check agrees with
+// the claim.
+func staticVerdict(fs FeatureStatus, check StaticCheck, auditConfidence float64) (LayerVerdict, bool) {
+	v := LayerVerdict{Layer: LayerStatic}
+	switch {
+	case fs.Status == AuditStatusMissing:
+		if len(check.Missing) > 0 {
+			return v, false
+		}
+		v.Status, v.Confidence = AuditStatusPartial, staticFoundConfidence
+		v.Reason = "every name the spec requires is declared: " + strings.Join(foundNames(check), ", ")
+	case len(check.Found) == 0:
+		v.Status, v.Confidence = downgradeStatus(fs.Status), auditConfidence
+		v.Reason = "none of the names the spec requires are declared: " + strings.Join(check.Missing, ", ")
+	case len(check.Missing) > 0 && fs.Status == AuditStatusComplete:
+		v.Status, v.Confidence = AuditStatusPartial, missingEvidenceConfidence
+		v.Reason = "names the spec requires are not declared: " + strings.Join(check.Missing, ", ")
+	default:
+		return v, false
+	}
+	return v, true
+}
+
+// foundNames returns the required names a check found.
+func foundNames(check StaticCheck) []string {
+	names := make([]string, len(check.Found))
+	for i, ref := range check.Found {
+		names[i] = ref.Name
+	}
+	return names
+}
+
+// staticChecks indexes static checks by feature ID.
+func staticChecks(checks []StaticCheck) map[string]StaticCheck {
+	byFeature := make(map[string]StaticCheck, len(checks))
+	for _, c := range checks {
+		byFeature[c.FeatureID] = c
+	}
+	return byFeature
+}
+
+// writeStaticSection tells the auditor which required names the symbol
+// index found, and lists the exported API, so claims are grounded in what
+// the code declares.
+func writeStaticSection(sb *strings.Builder, idx *SymbolIndex, checks []StaticCheck) {
+	sb.WriteString("## Static Analysis\n\n")
+	sb.WriteString(fmt.Sprintf("A symbol index of the %d Go files found the following. Names the spec requires that are not declared anywhere cannot be implemented; do not mark a feature COMPLETE on the strength of code that does not exist.\n", idx.Files))
+	for _, c := range checks {
+		sb.WriteString(fmt.Sprintf("- %s:", c.FeatureID))
+		for _, ref := range c.Found {
+			sb.WriteString(fmt.Sprintf(" found `%s` (%s:%d);", ref.Name, ref.File, ref.Line))
+		}
+		for _, name := range c.Missing {
+			sb.WriteString(fmt.Sprintf(" missing `%s`;", name))
+		}
+		sb.WriteString("\n")
+	}
+
+	dirs := make([]string, 0, len(idx.exported))
+	for dir := range idx.exported {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	sb.WriteString("\nExported API:\n")
+	listed := 0
+	for i, dir := range dirs {
+		names := idx.exported[dir]
+		if listed+len(names) > maxInventoryNames {
+			sb.WriteString(fmt.Sprintf("- ... and %d more packages\n", len(dirs)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", dir, strings.Join(names, ", ")))
+		listed += len(names)
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,205 @@
+package architect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// staticRepo creates a repository with an HTTP server package.
+func staticRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	files := map[string]string{
+		"server/server.go": `package server
+
+import "net/http"
+
+// Server serves the API.
+type Server struct {
+	Addr string
+	mux  *http.ServeMux
+}
+
+type Store interface {
+	Get(id string) (string, error)
+}
+
+const DefaultAddr = ":8080"
+
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /users/{id}", s.getUser)
+	s.mux.HandleFunc("/health", nil)
+	return s
+}
+
+func (s *Server) Shutdown() error { return nil }
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {}
+`,
+		"server/server_test.go":   "package server\n\nfunc TestOnly() {}\n",
+		"vendor/dep/dep.go":       "package dep\n\nfunc Vendored() {}\n",
+		"server/broken.go":        "package server\n\nfunc broken( {\n",
+		"web/app.js":              "fetch('/api/orders')\n",
+		"server/routes/routes.go": "package routes\n\nvar Orders = \"/orders/:orderID\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestBuildSymbolIndex(t *testing.T) {
+	idx, err := BuildSymbolIndex(staticRepo(t))
+	if err != nil {
+		t.Fatalf("BuildSymbolIndex() error = %v", err)
+	}
+	if idx.Files != 3 {
+		t.Errorf("Files = %d, want the 3 non-test, non-vendored Go files", idx.Files)
+	}
+
+	for _, name := range []string{"Server", "server.Server", "Server.Shutdown", "Shutdown()", "Server.Addr", "Store.Get", "DefaultAddr", "routes.Orders"} {
+		if len(idx.Lookup(name)) == 0 {
+			t.Errorf("Lookup(%q) found nothing", name)
+		}
+	}
+	for _, name := range []string{"TestOnly", "Vendored", "Server.Close", "routes.Server"} {
+		if refs := idx.Lookup(name); len(refs) != 0 {
+			t.Errorf("Lookup(%q) = %+v, want nothing", name, refs)
+		}
+	}
+	if refs := idx.Lookup("Server.Shutdown"); refs[0].File != "server/server.go" || refs[0].Line != 24 || refs[0].Kind != "method" {
+		t.Errorf("Lookup(Server.Shutdown) = %+v", refs[0])
+	}
+
+	for _, route := range []string{"/users/:userID", "GET /users/<id>", "/health/", "/orders/{id}"} {
+		if len(idx.LookupRoute(route)) == 0 {
+			t.Errorf("LookupRoute(%q) found nothing", route)
+		}
+	}
+	if refs := idx.LookupRoute("/api/orders"); len(refs) != 0 {
+		t.Errorf("LookupRoute(/api/orders) = %+v, want only Go literals indexed", refs)
+	}
+}
+
+func TestRequiredNames(t *testing.T) {
+	f := Feature{
+		Description: "Add `Server.Shutdown()` and `GET /users/{id}`, configured in `config.yaml` via `go run`.",
+		Criteria:    "`Server.Shutdown()` drains `/health`; `retries` is a lowercase option and `auth.Login` checks passwords",
+	}
+	symbols, routes := requiredNames(f)
+	if want := []string{"Server.Shutdown()", "auth.Login"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("symbols = %q, want %q", symbols, want)
+	}
+	if want := []string{"GET /users/{id}", "/health"}; !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes = %q, want %q", routes, want)
+	}
+}
+
+func TestAssessFeatures_StaticChecks(t *testing.T) {
+	idx, err := BuildSymbolIndex(staticRepo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	features := []Feature{
+		{ID: "F1", Name: "Users", Description: "Serve `GET /users/{id}` from `Server`"},
+		{ID: "F2", Name: "Billing", Description: "Add `Invoice` and `POST /invoices`"},
+		{ID: "F3", Name: "Shutdown", Description: "Add `Server.Shutdown` and `Server.Drain`"},
+		{ID: "F4", Name: "Health", Description: "Serve `/health`"},
+		{ID: "F5", Name: "Logging"},
+	}
+	checks := idx.CrossCheck(features)
+	if len(checks) != 4 {
+		t.Fatalf("checks = %+v, want one per feature requiring names", checks)
+	}
+	if c := checks[2]; len(c.Found) != 1 || c.Found[0].Name != "Server.Shutdown" || !reflect.DeepEqual(c.Missing, []string{"Server.Drain"}) {
+		t.Errorf("F3 check = %+v", c)
+	}
+
+	report := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: features[0], Status: AuditStatusComplete},
+			{Feature: features[1], Status: AuditStatusComplete, Confidence: 0.9},
+			{Feature: features[2], Status: AuditStatusComplete, Confidence: 0.9},
+			{Feature: features[3], Status: AuditStatusMissing},
+			{Feature: features[4], Status: AuditStatusComplete},
+		},
+		StaticChecks: checks,
+	}
+	assessFeatures(report, "")
+
+	// A claim none of whose names exist is downgraded; one missing some is
+	// contested; a MISSING claim whose names all exist is contested weakly
+	want := []AuditStatus{AuditStatusComplete, AuditStatusPartial, AuditStatusComplete, AuditStatusMissing, AuditStatusComplete}
+	for i, fs := range report.Features {
+		if fs.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", fs.Feature.ID, fs.Status, want[i])
+		}
+	}
+	if len(report.Gaps) != 1 || !strings.Contains(report.Gaps[0].Description, "static: none of the names the spec requires are declared: Invoice, POST /invoices") {
+		t.Errorf("gaps = %+v, want a gap for F2 naming the missing names", report.Gaps)
+	}
+	var contested []string
+	for _, d := range report.Disagreements {
+		contested = append(contested, d.FeatureID)
+	}
+	if want := []string{"F2", "F4", "F3"}; !reflect.DeepEqual(contested, want) {
+		t.Errorf("contested features = %v, want %v", contested, want)
+	}
+}
+
+func TestWriteStaticSection(t *testing.T) {
+	idx, err := BuildSymbolIndex(staticRepo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := idx.CrossCheck([]Feature{{ID: "F1", Description: "Add `Server.Shutdown` and `Server.Drain`"}})
+
+	var sb strings.Builder
+	writeStaticSection(&sb, idx, checks)
+	for _, want := range []string{
+		"## Static Analysis",
+		"- F1: found `Server.Shutdown` (server/server.go:24); missing `Server.Drain`;",
+		"- server: Server, Server.Addr, Store, Store.Get, DefaultAddr, New, Server.Shutdown",
+		"- server/routes: Orders",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("static section missing %q:\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestWriteMarkdownReport_StaticChecks(t *testing.T) {
+	report := sampleGapReport()
+	report.StaticChecks = []StaticCheck{{
+		FeatureID: "F1",
+		Found:     []SymbolRef{{Name: "Login", Kind: "func", File: "auth/login.go", Line: 12}},
+		Missing:   []string{"POST /logout"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteMarkdownReport: %v", err)
+	}
+	if want := "**Static check:** found `Login` (auth/login.go:12), missing `POST /logout`"; !strings.Contains(buf.String(), want) {
+		t.Errorf("markdown report missing %q:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteHTMLReport(&buf, report, ReportMeta{}); err != nil {
+		t.Fatalf("WriteHTMLReport: %v", err)
+	}
+	if want := "found <code>Login</code> (auth/login.go:12), missing <code>POST /logout</code>"; !strings.Contains(buf.String(), want) {
+		t.Errorf("html report missing %q:\n%s", want, buf.String())
+	}
+}
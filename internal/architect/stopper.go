@@ -1,41 +1,140 @@
 // Package architect provides components for the architect iteration loop.
 package architect
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
 // StopReason indicates why the architect loop should stop.
 type StopReason string
 
 const (
 	// StopReasonNone indicates no stop condition has been met.
 	StopReasonNone StopReason = ""
-	// StopReasonMaxIterations indicates the maximum iteration count was reached.
+	// StopReasonMaxIterations indicates the maximum count of implementation
+	// iterations was reached.
 	StopReasonMaxIterations StopReason = "max_iterations"
+	// StopReasonMaxVerificationRounds indicates the maximum count of
+	// verification rounds was reached without one passing.
+	StopReasonMaxVerificationRounds StopReason = "max_verification_rounds"
 	// StopReasonBudgetExceeded indicates the cost budget was exceeded.
 	StopReasonBudgetExceeded StopReason = "budget_exceeded"
 	// StopReasonConverged indicates no progress for N iterations (convergence).
 	StopReasonConverged StopReason = "converged"
 	// StopReasonComplete indicates 100% completion was achieved.
 	StopReasonComplete StopReason = "complete"
+	// StopReasonDeadline indicates the wall-clock deadline of the run passed.
+	StopReasonDeadline StopReason = "deadline"
+	// StopReasonIterationTimeout indicates an iteration ran longer than the
+	// maximum iteration duration.
+	StopReasonIterationTimeout StopReason = "iteration_timeout"
+	// StopReasonRejected indicates an operator rejected the run at an
+	// approval gate.
+	StopReasonRejected StopReason = "rejected"
+	// StopReasonInterrupted indicates the operator interrupted the run,
+	// e.g. with SIGINT.
+	StopReasonInterrupted StopReason = "interrupted"
+)
+
+// Sentinel errors for the stop reasons that have no orchestrator
+// equivalent. A StopError matches the one for its reason with errors.Is.
+var (
+	// ErrMaxIterations indicates the iteration limit was reached before
+	// the spec was complete.
+	ErrMaxIterations = errors.New("iteration limit reached")
+	// ErrNoProgress indicates the loop stopped making progress.
+	ErrNoProgress = errors.New("no progress")
+	// ErrDeadline indicates the run's wall-clock deadline passed.
+	ErrDeadline = errors.New("deadline passed")
+	// ErrIterationTimeout indicates an iteration ran past its maximum
+	// duration.
+	ErrIterationTimeout = errors.New("iteration timed out")
 )
 
+// StopError is returned by Controller.Run when the loop stops before the
+// spec is complete. It matches the sentinel for its reason with errors.Is:
+// orchestrator.ErrVerificationFailed when verification rounds ran out,
+// orchestrator.ErrApprovalRejected when an operator rejected the run,
+// orchestrator.ErrSessionInterrupted when an operator interrupted it, and
+// this package's sentinels for the other reasons.
+type StopError struct {
+	// Reason is why the loop stopped.
+	Reason StopReason
+	// Iteration is the iteration the loop stopped after.
+	Iteration int
+	// CompletionPct is the spec's completion when the loop stopped.
+	CompletionPct float64
+	// Detail explains the reason, e.g. "maximum of 5 iterations reached".
+	Detail string
+}
+
+// Error implements the error interface.
+func (e *StopError) Error() string {
+	detail := e.Detail
+	if detail == "" {
+		detail = string(e.Reason)
+	}
+	return fmt.Sprintf("stopped after iteration %d at %.0f%% complete: %s", e.Iteration, e.CompletionPct, detail)
+}
+
+// Unwrap returns the sentinel error for the stop reason.
+func (e *StopError) Unwrap() error {
+	switch e.Reason {
+	case StopReasonMaxVerificationRounds:
+		return orchestrator.ErrVerificationFailed
+	case StopReasonRejected:
+		return orchestrator.ErrApprovalRejected
+	case StopReasonInterrupted:
+		return orchestrator.ErrSessionInterrupted
+	case StopReasonBudgetExceeded:
+		return orchestrator.ErrBudgetExceeded
+	case StopReasonMaxIterations:
+		return ErrMaxIterations
+	case StopReasonConverged:
+		return ErrNoProgress
+	case StopReasonDeadline:
+		return ErrDeadline
+	case StopReasonIterationTimeout:
+		return ErrIterationTimeout
+	default:
+		return nil
+	}
+}
+
 // StopConfig holds configuration for stop condition evaluation.
 type StopConfig struct {
-	// MaxIterations is the maximum number of iterations before stopping.
-	// A value of 0 means no limit.
+	// MaxIterations is the maximum number of implementation iterations
+	// before stopping. A value of 0 means no limit.
 	MaxIterations int
+	// MaxVerificationRounds is the maximum number of verification rounds,
+	// the audits after an iteration that completed all its tasks, that may
+	// fail before stopping. A value of 0 means no limit.
+	MaxVerificationRounds int
 	// BudgetLimit is the maximum cost allowed (in dollars).
 	// A value of 0 means no limit.
 	BudgetLimit float64
 	// NoProgressLimit is the number of consecutive iterations without progress
 	// before considering the loop converged. A value of 0 means no convergence check.
 	NoProgressLimit int
+	// Deadline is the wall-clock time the loop may run for, counted from
+	// Start. A value of 0 means no limit.
+	Deadline time.Duration
+	// MaxIterationDuration is the wall-clock time a single iteration may run
+	// for, counted from StartIteration. A value of 0 means no limit.
+	MaxIterationDuration time.Duration
 }
 
 // DefaultStopConfig returns a StopConfig with sensible defaults.
 func DefaultStopConfig() StopConfig {
 	return StopConfig{
-		MaxIterations:   10,
-		BudgetLimit:     5.0,
-		NoProgressLimit: 3,
+		MaxIterations:         10,
+		MaxVerificationRounds: 3,
+		BudgetLimit:           5.0,
+		NoProgressLimit:       3,
 	}
 }
 
@@ -45,14 +144,88 @@ type StopChecker struct {
 	noProgressCount     int
 	lastCompletionPct   float64
 	iterationsCompleted int
+	startedAt           time.Time
+	iterationStartedAt  time.Time
+	now                 func() time.Time
 }
 
 // NewStopChecker creates a new StopChecker with the given configuration.
+// The deadline and iteration clocks start when it is created; call Start
+// and StartIteration to restart them.
 func NewStopChecker(config StopConfig) *StopChecker {
-	return &StopChecker{
+	s := &StopChecker{
 		config:            config,
 		noProgressCount:   0,
 		lastCompletionPct: 0,
+		now:               time.Now,
+	}
+	s.Start()
+	return s
+}
+
+// Start starts the clock of the deadline, and of the first iteration.
+func (s *StopChecker) Start() {
+	s.startedAt = s.now()
+	s.iterationStartedAt = s.startedAt
+}
+
+// StartIteration starts the clock of the maximum iteration duration.
+func (s *StopChecker) StartIteration() {
+	s.iterationStartedAt = s.now()
+}
+
+// Deadline returns when the current iteration must end: the earlier of
+// the loop's deadline and the iteration's maximum duration. ok is false
+// when neither is limited.
+func (s *StopChecker) Deadline() (deadline time.Time, ok bool) {
+	if s.config.Deadline > 0 {
+		deadline, ok = s.startedAt.Add(s.config.Deadline), true
+	}
+	if s.config.MaxIterationDuration > 0 {
+		end := s.iterationStartedAt.Add(s.config.MaxIterationDuration)
+		if !ok || end.Before(deadline) {
+			deadline, ok = end, true
+		}
+	}
+	return deadline, ok
+}
+
+// CheckTime evaluates the wall-clock limits only: the loop's deadline,
+// then the current iteration's maximum duration.
+func (s *StopChecker) CheckTime() (StopReason, bool) {
+	now := s.now()
+	if s.config.Deadline > 0 && now.Sub(s.startedAt) >= s.config.Deadline {
+		return StopReasonDeadline, true
+	}
+	if s.config.MaxIterationDuration > 0 && now.Sub(s.iterationStartedAt) >= s.config.MaxIterationDuration {
+		return StopReasonIterationTimeout, true
+	}
+	return StopReasonNone, false
+}
+
+// Describe explains reason for a person, e.g. "deadline of 4h0m0s reached".
+func (s *StopChecker) Describe(reason StopReason) string {
+	switch reason {
+	case StopReasonMaxIterations:
+		return fmt.Sprintf("maximum of %d iterations reached", s.config.MaxIterations)
+	case StopReasonMaxVerificationRounds:
+		return fmt.Sprintf("%d verification rounds failed", s.config.MaxVerificationRounds)
+	case StopReasonBudgetExceeded:
+		return fmt.Sprintf("budget of $%.2f exceeded", s.config.BudgetLimit)
+	case StopReasonConverged:
+		return fmt.Sprintf("no progress for %d iterations", s.config.NoProgressLimit)
+	case StopReasonComplete:
+		return "all features complete"
+	case StopReasonDeadline:
+		return fmt.Sprintf("deadline of %s reached", s.config.Deadline)
+	case StopReasonIterationTimeout:
+		return fmt.Sprintf("iteration ran longer than %s", s.config.MaxIterationDuration)
+	case StopReasonRejected:
+		return "rejected by the operator at an approval gate"
+	case StopReasonInterrupted:
+		return "interrupted by the operator"
+	default:
+		return string(reason)
 	}
 }
 
@@ -84,6 +257,11 @@ func (s *StopChecker) Check(iteration int, cost float64, completePct float64, pr
 		return StopReasonBudgetExceeded, true
 	}
 
+	// Check the wall-clock limits
+	if reason, stop := s.CheckTime(); stop {
+		return reason, true
+	}
+
 	// Track progress for convergence detection
 	if progressMade {
 		s.noProgressCount = 0
@@ -100,6 +278,16 @@ func (s *StopChecker) Check(iteration int, cost float64, completePct float64, pr
 	return StopReasonNone, false
 }
 
+// CheckVerification evaluates the verification budget after round, the
+// 1-based count of verification rounds, failed. Call it only for rounds
+// that did not pass.
+func (s *StopChecker) CheckVerification(round int) (StopReason, bool) {
+	if s.config.MaxVerificationRounds > 0 && round >= s.config.MaxVerificationRounds {
+		return StopReasonMaxVerificationRounds, true
+	}
+	return StopReasonNone, false
+}
+
 // NoProgressCount returns the current count of iterations without progress.
 func (s *StopChecker) NoProgressCount() int {
 	return s.noProgressCount
@@ -115,4 +303,5 @@ func (s *StopChecker) Reset() {
 	s.noProgressCount = 0
 	s.lastCompletionPct = 0
 	s.iterationsCompleted = 0
+	s.Start()
 }
@@ -1,6 +1,9 @@
 package architect
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestStopChecker_Complete(t *testing.T) {
 	checker := NewStopChecker(DefaultStopConfig())
@@ -218,6 +221,73 @@ func TestStopChecker_Reset(t *testing.T) {
 	}
 }
 
+// fakeClock returns a StopChecker clock that reads *now.
+func fakeClock(now *time.Time) func() time.Time {
+	return func() time.Time { return *now }
+}
+
+func TestStopChecker_Deadline(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	checker := NewStopChecker(StopConfig{Deadline: 4 * time.Hour})
+	checker.now = fakeClock(&now)
+	checker.Start()
+
+	if deadline, ok := checker.Deadline(); !ok || !deadline.Equal(now.Add(4*time.Hour)) {
+		t.Fatalf("Deadline() = %v, %v, want 4 hours from start", deadline, ok)
+	}
+
+	now = now.Add(3 * time.Hour)
+	if reason, stop := checker.Check(1, 0, 50.0, true); stop {
+		t.Fatalf("should not stop before the deadline, got %s", reason)
+	}
+
+	now = now.Add(time.Hour)
+	reason, stop := checker.Check(2, 0, 60.0, true)
+	if !stop || reason != StopReasonDeadline {
+		t.Fatalf("expected StopReasonDeadline, got %s (stop=%v)", reason, stop)
+	}
+	if got := checker.Describe(reason); got != "deadline of 4h0m0s reached" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestStopChecker_IterationTimeout(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	checker := NewStopChecker(StopConfig{Deadline: 2 * time.Hour, MaxIterationDuration: 45 * time.Minute})
+	checker.now = fakeClock(&now)
+	checker.Start()
+
+	// Each iteration gets its own clock
+	now = now.Add(40 * time.Minute)
+	if reason, stop := checker.CheckTime(); stop {
+		t.Fatalf("should not stop within the first iteration, got %s", reason)
+	}
+	checker.StartIteration()
+	if deadline, _ := checker.Deadline(); !deadline.Equal(now.Add(45 * time.Minute)) {
+		t.Errorf("Deadline() = %v, want the iteration's end", deadline)
+	}
+	now = now.Add(45 * time.Minute)
+	if reason, stop := checker.CheckTime(); !stop || reason != StopReasonIterationTimeout {
+		t.Fatalf("expected StopReasonIterationTimeout, got %s (stop=%v)", reason, stop)
+	}
+
+	// The run's deadline bounds an iteration that would end later
+	checker.StartIteration()
+	if deadline, _ := checker.Deadline(); !deadline.Equal(now.Add(35 * time.Minute)) {
+		t.Errorf("Deadline() = %v, want the run's deadline", deadline)
+	}
+}
+
+func TestStopChecker_NoTimeLimits(t *testing.T) {
+	checker := NewStopChecker(StopConfig{})
+	if _, ok := checker.Deadline(); ok {
+		t.Error("expected no deadline without time limits")
+	}
+	if reason, stop := checker.CheckTime(); stop {
+		t.Errorf("expected no time stop, got %s", reason)
+	}
+}
+
 func TestDefaultStopConfig(t *testing.T) {
 	config := DefaultStopConfig()
 
@@ -231,3 +301,28 @@ func TestDefaultStopConfig(t *testing.T) {
 		t.Fatal("expected positive NoProgressLimit in default config")
 	}
 }
+
+func TestStopChecker_MaxVerificationRounds(t *testing.T) {
+	checker := NewStopChecker(StopConfig{MaxIterations: 10, MaxVerificationRounds: 2})
+
+	if reason, stop := checker.CheckVerification(1); stop {
+		t.Fatalf("should not stop after the first failed round, got %s", reason)
+	}
+	reason, stop := checker.CheckVerification(2)
+	if !stop || reason != StopReasonMaxVerificationRounds {
+		t.Fatalf("expected StopReasonMaxVerificationRounds, got %s (stop=%v)", reason, stop)
+	}
+	if got := checker.Describe(reason); got != "2 verification rounds failed" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	// Verification rounds do not use up the iteration budget
+	if reason, stop := checker.Check(3, 0, 80.0, true); stop {
+		t.Fatalf("should not stop on iteration 3, got %s", reason)
+	}
+
+	unlimited := NewStopChecker(StopConfig{})
+	if reason, stop := unlimited.CheckVerification(100); stop {
+		t.Fatalf("expected no limit, got %s", reason)
+	}
+}
@@ -0,0 +1,277 @@
+// Package architect provides tools for analyzing and auditing codebases against specifications.
+package architect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+// Traceability matrix file names written by WriteTraceability.
+const (
+	traceabilityJSONFile     = "traceability.json"
+	traceabilityMarkdownFile = "traceability.md"
+)
+
+// testFilePatterns identify test files among changed and evidence files;
+// Python's "test_" prefix is checked separately.
+var testFilePatterns = []string{"_test.go", ".test.js", ".test.ts", ".spec.js", ".spec.ts", "_test.py", "Test.java", "_spec.rb", "_test.rs"}
+
+// TraceTask is a completed task in the traceability matrix.
+type TraceTask struct {
+	// TaskID is the prog task ID, or the orchestrator's task ID for tasks
+	// not tracked in prog.
+	TaskID string `json:"task_id"`
+	// Title is the task title.
+	Title string `json:"title"`
+	// FeatureID is the spec feature the task implements, or empty if the
+	// task could not be attributed to one.
+	FeatureID string `json:"feature_id,omitempty"`
+	// Iteration is the implement iteration the task completed in.
+	Iteration int `json:"iteration"`
+	// Files lists the files the task changed.
+	Files []string `json:"files,omitempty"`
+	// Verification summarizes how the task's work was verified.
+	Verification string `json:"verification,omitempty"`
+}
+
+// TraceFeature links a spec feature to the tasks that implemented it, the
+// files they changed, the tests covering it and the audit's evidence.
+type TraceFeature struct {
+	FeatureID string      `json:"feature_id"`
+	Name      string      `json:"name,omitempty"`
+	Status    AuditStatus `json:"status"`
+	Tasks     []TraceTask `json:"tasks,omitempty"`
+	// Files is every file the feature's tasks changed or the audit cited.
+	Files []string `json:"files,omitempty"`
+	// Tests is the subset of Files that are tests.
+	Tests []string `json:"tests,omitempty"`
+	// Evidence and Reasoning are the final audit's verdict on the feature.
+	Evidence  string `json:"evidence,omitempty"`
+	Reasoning string `json:"reasoning,omitempty"`
+}
+
+// TraceabilityMatrix maps each spec feature to the work implementing it.
+type TraceabilityMatrix struct {
+	SpecName    string         `json:"spec_name,omitempty"`
+	Iterations  int            `json:"iterations"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Features    []TraceFeature `json:"features"`
+	// Unattributed are completed tasks that could not be linked to a feature.
+	Unattributed []TraceTask `json:"unattributed,omitempty"`
+}
+
+// BuildTraceabilityMatrix links the final audit's features to the tasks
+// completed for them across the run.
+func BuildTraceabilityMatrix(report *GapReport, tasks []TraceTask, meta ReportMeta) *TraceabilityMatrix {
+	matrix := &TraceabilityMatrix{
+		SpecName:    meta.SpecName,
+		Iterations:  meta.Iteration,
+		GeneratedAt: meta.GeneratedAt,
+	}
+
+	byFeature := make(map[string][]TraceTask)
+	for _, task := range tasks {
+		byFeature[task.FeatureID] = append(byFeature[task.FeatureID], task)
+	}
+
+	for _, fs := range report.Features {
+		feature := TraceFeature{
+			FeatureID: fs.Feature.ID,
+			Name:      fs.Feature.Name,
+			Status:    fs.Status,
+			Tasks:     byFeature[fs.Feature.ID],
+			Evidence:  fs.Evidence,
+			Reasoning: fs.Reasoning,
+		}
+		delete(byFeature, fs.Feature.ID)
+
+		files := make(map[string]bool)
+		for _, task := range feature.Tasks {
+			for _, file := range task.Files {
+				files[file] = true
+			}
+		}
+		for _, ref := range evidenceFiles(fs.Evidence) {
+			// The evidence keeps the line references
+			files[refPath(ref)] = true
+		}
+		for file := range files {
+			feature.Files = append(feature.Files, file)
+			if isTestPath(file) {
+				feature.Tests = append(feature.Tests, file)
+			}
+		}
+		sort.Strings(feature.Files)
+		sort.Strings(feature.Tests)
+		matrix.Features = append(matrix.Features, feature)
+	}
+
+	// Tasks for features the final audit no longer lists
+	for _, task := range tasks {
+		if _, ok := byFeature[task.FeatureID]; ok {
+			matrix.Unattributed = append(matrix.Unattributed, task)
+		}
+	}
+	return matrix
+}
+
+// refPath strips the line reference from an evidence file reference.
+func refPath(ref string) string {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		return ref[:i]
+	}
+	return ref
+}
+
+// isTestPath reports whether a file is a test.
+func isTestPath(path string) bool {
+	base := filepath.Base(path)
+	if strings.HasPrefix(base, "test_") {
+		return true
+	}
+	for _, pattern := range testFilePatterns {
+		if strings.Contains(base, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTraceabilityMarkdown renders the traceability matrix as Markdown.
+func WriteTraceabilityMarkdown(w io.Writer, matrix *TraceabilityMatrix) error {
+	var b strings.Builder
+
+	title := "Traceability Matrix"
+	if matrix.SpecName != "" {
+		title += ": " + matrix.SpecName
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if matrix.Iterations > 0 {
+		fmt.Fprintf(&b, "- **Iterations:** %d\n", matrix.Iterations)
+	}
+	if !matrix.GeneratedAt.IsZero() {
+		fmt.Fprintf(&b, "- **Generated:** %s\n", matrix.GeneratedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+
+	if len(matrix.Features) > 0 {
+		b.WriteString("| Feature | Status | Tasks | Files | Tests |\n|---|---|---|---|---|\n")
+		for _, f := range matrix.Features {
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d |\n", markdownCell(featureLabel(Feature{ID: f.FeatureID, Name: f.Name})),
+				f.Status, len(f.Tasks), len(f.Files), len(f.Tests))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, f := range matrix.Features {
+		fmt.Fprintf(&b, "## %s — %s\n\n", featureLabel(Feature{ID: f.FeatureID, Name: f.Name}), f.Status)
+		writeMarkdownTraceTasks(&b, f.Tasks)
+		writeMarkdownFileList(&b, "Files", f.Files)
+		writeMarkdownFileList(&b, "Tests", f.Tests)
+		if f.Evidence != "" {
+			fmt.Fprintf(&b, "**Evidence:** %s\n\n", f.Evidence)
+		}
+		if f.Reasoning != "" {
+			fmt.Fprintf(&b, "**Reasoning:** %s\n\n", f.Reasoning)
+		}
+	}
+
+	if len(matrix.Unattributed) > 0 {
+		b.WriteString("## Unattributed Tasks\n\n")
+		writeMarkdownTraceTasks(&b, matrix.Unattributed)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownTraceTasks lists tasks with their verification evidence.
+func writeMarkdownTraceTasks(b *strings.Builder, tasks []TraceTask) {
+	if len(tasks) == 0 {
+		return
+	}
+	b.WriteString("**Tasks:**\n\n")
+	for _, task := range tasks {
+		fmt.Fprintf(b, "- `%s` %s (iteration %d)\n", task.TaskID, task.Title, task.Iteration)
+		if task.Verification != "" {
+			fmt.Fprintf(b, "  - Verification: %s\n", strings.Join(strings.Fields(task.Verification), " "))
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeMarkdownFileList writes a titled list of files.
+func writeMarkdownFileList(b *strings.Builder, title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "**%s:**\n\n", title)
+	for _, file := range files {
+		fmt.Fprintf(b, "- `%s`\n", file)
+	}
+	b.WriteString("\n")
+}
+
+// WriteTraceability writes the JSON and Markdown renderings of the
+// traceability matrix into dir.
+func WriteTraceability(dir string, matrix *TraceabilityMatrix) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create report directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(matrix, "", "  ")
+	if err != nil {
+		return fmt.Errorf("render %s: %w", traceabilityJSONFile, err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, traceabilityJSONFile), append(data, '\n')); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTraceabilityMarkdown(&buf, matrix); err != nil {
+		return fmt.Errorf("render %s: %w", traceabilityMarkdownFile, err)
+	}
+	return writeFileAtomic(filepath.Join(dir, traceabilityMarkdownFile), buf.Bytes())
+}
+
+// recordTraceTask records a completed task for the traceability matrix.
+func (c *Controller) recordTraceTask(event orchestrator.OrchestratorEvent) {
+	taskID := event.ProgTaskID
+	if taskID == "" {
+		taskID = event.TaskID
+	}
+	c.traceTasks = append(c.traceTasks, TraceTask{
+		TaskID:       taskID,
+		Title:        event.TaskTitle,
+		FeatureID:    c.taskFeatures[taskID],
+		Iteration:    c.currentIteration,
+		Files:        event.Files,
+		Verification: event.Verification,
+	})
+}
+
+// writeTraceability writes the traceability matrix once final verification
+// passes. Failures are logged rather than failing the run.
+func (c *Controller) writeTraceability(spec *ArchSpec, report *GapReport, iteration int) {
+	if c.ReportDir == "" {
+		return
+	}
+	matrix := BuildTraceabilityMatrix(report, c.traceTasks, ReportMeta{
+		SpecName:    spec.Name,
+		Iteration:   iteration,
+		GeneratedAt: time.Now(),
+	})
+	if err := WriteTraceability(c.ReportDir, matrix); err != nil {
+		archLog.Warn("failed to write traceability matrix", logging.Err(err))
+	}
+}
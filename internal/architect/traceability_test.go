@@ -0,0 +1,119 @@
+package architect
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
+)
+
+func TestBuildTraceabilityMatrix(t *testing.T) {
+	c := NewController(10, 5.0, 3)
+	c.taskFeatures = map[string]string{"ts-1": "F1", "ts-2": "F2"}
+	c.currentIteration = 1
+	for _, e := range []orchestrator.OrchestratorEvent{
+		{TaskID: "a", ProgTaskID: "ts-1", TaskTitle: "Add login", Files: []string{"internal/auth/login.go", "internal/auth/login_test.go"}, Verification: "3/3 checks passed"},
+		{TaskID: "b", ProgTaskID: "ts-2", TaskTitle: "Rotate logs", Files: []string{"audit.go", "tests/test_rotate.py"}},
+		{TaskID: "c", TaskTitle: "Fix build", Files: []string{"go.mod"}},
+	} {
+		e.Type = orchestrator.EventTaskCompleted
+		c.handleOrchestratorEvent(e)
+	}
+
+	matrix := BuildTraceabilityMatrix(sampleGapReport(), c.traceTasks, ReportMeta{SpecName: "Auth", Iteration: 2})
+	if len(matrix.Features) != 2 {
+		t.Fatalf("features = %+v, want 2", matrix.Features)
+	}
+
+	login := matrix.Features[0]
+	if len(login.Tasks) != 1 || login.Tasks[0].TaskID != "ts-1" || login.Tasks[0].Verification != "3/3 checks passed" {
+		t.Errorf("login tasks = %+v", login.Tasks)
+	}
+	// Task files and the evidence's files, without line references
+	if want := []string{"internal/auth/login.go", "internal/auth/login_test.go"}; !reflect.DeepEqual(login.Files, want) {
+		t.Errorf("login files = %v, want %v", login.Files, want)
+	}
+	if want := []string{"internal/auth/login_test.go"}; !reflect.DeepEqual(login.Tests, want) {
+		t.Errorf("login tests = %v, want %v", login.Tests, want)
+	}
+	if login.Evidence == "" || login.Status != AuditStatusComplete {
+		t.Errorf("login = %+v, want the audit's evidence", login)
+	}
+
+	if want := []string{"tests/test_rotate.py"}; !reflect.DeepEqual(matrix.Features[1].Tests, want) {
+		t.Errorf("audit log tests = %v, want %v", matrix.Features[1].Tests, want)
+	}
+	if len(matrix.Unattributed) != 1 || matrix.Unattributed[0].TaskID != "c" {
+		t.Errorf("unattributed = %+v, want the unmapped task", matrix.Unattributed)
+	}
+}
+
+func TestIsTestPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"internal/auth/login_test.go": true,
+		"web/app.spec.ts":             true,
+		"tests/test_rotate.py":        true,
+		"src/latest_news.py":          false,
+		"internal/auth/login.go":      false,
+	} {
+		if got := isTestPath(path); got != want {
+			t.Errorf("isTestPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestWriteTraceability(t *testing.T) {
+	matrix := &TraceabilityMatrix{
+		SpecName:   "Auth",
+		Iterations: 2,
+		Features: []TraceFeature{{
+			FeatureID: "F1",
+			Name:      "Login",
+			Status:    AuditStatusComplete,
+			Tasks:     []TraceTask{{TaskID: "ts-1", Title: "Add login", Iteration: 1, Verification: "3/3 checks passed"}},
+			Files:     []string{"internal/auth/login.go", "internal/auth/login_test.go"},
+			Tests:     []string{"internal/auth/login_test.go"},
+			Evidence:  "Implemented in internal/auth/login.go",
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTraceabilityMarkdown(&buf, matrix); err != nil {
+		t.Fatalf("WriteTraceabilityMarkdown: %v", err)
+	}
+	for _, want := range []string{
+		"# Traceability Matrix: Auth",
+		"| Login (F1) | COMPLETE | 1 | 2 | 1 |",
+		"- `ts-1` Add login (iteration 1)\n  - Verification: 3/3 checks passed",
+		"**Tests:**\n\n- `internal/auth/login_test.go`",
+		"**Evidence:** Implemented in internal/auth/login.go",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown matrix missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	if err := WriteTraceability(dir, matrix); err != nil {
+		t.Fatalf("WriteTraceability: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, traceabilityJSONFile))
+	if err != nil {
+		t.Fatalf("read %s: %v", traceabilityJSONFile, err)
+	}
+	var got TraceabilityMatrix
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got.Features, matrix.Features) {
+		t.Errorf("json features = %+v, want %+v", got.Features, matrix.Features)
+	}
+	if _, err := os.Stat(filepath.Join(dir, traceabilityMarkdownFile)); err != nil {
+		t.Errorf("markdown matrix not written: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package architect
+
+import "fmt"
+
+// VerificationMode selects what a verification round audits.
+type VerificationMode string
+
+const (
+	// VerificationFull re-audits every feature in each verification round.
+	VerificationFull VerificationMode = "full"
+	// VerificationIncremental re-audits only the features that had gaps in
+	// the previous audit, and carries the other features' statuses over
+	// from it. It is cheaper, but does not catch regressions in features
+	// that were already complete.
+	VerificationIncremental VerificationMode = "incremental"
+)
+
+// ParseVerificationMode validates a verification mode name. Empty means
+// full.
+func ParseVerificationMode(s string) (VerificationMode, error) {
+	switch mode := VerificationMode(s); mode {
+	case "":
+		return VerificationFull, nil
+	case VerificationFull, VerificationIncremental:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown verification mode %q (want full or incremental)", s)
+	}
+}
+
+// recheckSpec returns the part of spec an incremental verification round
+// audits: the features with gaps in prev, deferred or not, and the
+// features prev did not audit. ok is false if every feature would be
+// audited anyway.
+func recheckSpec(spec *ArchSpec, prev *GapReport) (partial *ArchSpec, ok bool) {
+	recheck := make(map[string]bool)
+	for _, gaps := range [][]Gap{prev.Gaps, prev.Deferred} {
+		for _, gap := range gaps {
+			recheck[gap.FeatureID] = true
+		}
+	}
+	audited := make(map[string]bool, len(prev.Features))
+	for _, fs := range prev.Features {
+		audited[fs.Feature.ID] = true
+	}
+
+	partial = &ArchSpec{Name: spec.Name}
+	for _, f := range spec.Features {
+		if recheck[f.ID] || !audited[f.ID] {
+			partial.Features = append(partial.Features, f)
+		}
+	}
+	if len(partial.Features) == 0 || len(partial.Features) == len(spec.Features) {
+		return nil, false
+	}
+	return partial, true
+}
+
+// mergeIncrementalAudit combines the audit of an incremental verification
+// round with the previous audit: the rechecked features take their results
+// from partial, every other feature of spec keeps its results from prev.
+// The summary and artifacts are partial's, and the score is recomputed.
+func mergeIncrementalAudit(spec *ArchSpec, prev, partial *GapReport) *GapReport {
+	rechecked := make(map[string]bool, len(partial.Features))
+	for _, fs := range partial.Features {
+		rechecked[fs.Feature.ID] = true
+	}
+	inSpec := make(map[string]bool, len(spec.Features))
+	for _, f := range spec.Features {
+		inSpec[f.ID] = true
+	}
+	// carried reports whether a result of prev for feature id is kept
+	carried := func(id string) bool {
+		return inSpec[id] && !rechecked[id]
+	}
+
+	merged := &GapReport{
+		Summary:   partial.Summary,
+		Artifacts: partial.Artifacts,
+	}
+	for _, fs := range prev.Features {
+		if carried(fs.Feature.ID) {
+			merged.Features = append(merged.Features, fs)
+		}
+	}
+	merged.Features = append(merged.Features, partial.Features...)
+	for _, gap := range prev.Gaps {
+		if carried(gap.FeatureID) {
+			merged.Gaps = append(merged.Gaps, gap)
+		}
+	}
+	merged.Gaps = append(merged.Gaps, partial.Gaps...)
+	for _, gap := range prev.Deferred {
+		if carried(gap.FeatureID) {
+			merged.Deferred = append(merged.Deferred, gap)
+		}
+	}
+	merged.Deferred = append(merged.Deferred, partial.Deferred...)
+	for _, fa := range prev.Assessments {
+		if carried(fa.FeatureID) {
+			merged.Assessments = append(merged.Assessments, fa)
+		}
+	}
+	merged.Assessments = append(merged.Assessments, partial.Assessments...)
+	for _, fa := range prev.Disagreements {
+		if carried(fa.FeatureID) {
+			merged.Disagreements = append(merged.Disagreements, fa)
+		}
+	}
+	merged.Disagreements = append(merged.Disagreements, partial.Disagreements...)
+	for _, r := range prev.AcceptanceTests {
+		if carried(r.FeatureID) {
+			merged.AcceptanceTests = append(merged.AcceptanceTests, r)
+		}
+	}
+	merged.AcceptanceTests = append(merged.AcceptanceTests, partial.AcceptanceTests...)
+	for _, check := range prev.StaticChecks {
+		if carried(check.FeatureID) {
+			merged.StaticChecks = append(merged.StaticChecks, check)
+		}
+	}
+	merged.StaticChecks = append(merged.StaticChecks, partial.StaticChecks...)
+
+	score := ScoreReport(merged)
+	merged.Score = &score
+	return merged
+}
@@ -0,0 +1,95 @@
+package architect
+
+import "testing"
+
+func TestParseVerificationMode(t *testing.T) {
+	for in, want := range map[string]VerificationMode{
+		"":            VerificationFull,
+		"full":        VerificationFull,
+		"incremental": VerificationIncremental,
+	} {
+		if got, err := ParseVerificationMode(in); err != nil || got != want {
+			t.Errorf("ParseVerificationMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseVerificationMode("partial"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestRecheckSpec(t *testing.T) {
+	spec := &ArchSpec{Name: "Shop", Features: []Feature{{ID: "F1"}, {ID: "F2"}, {ID: "F3"}, {ID: "F4"}}}
+	prev := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1"}, Status: AuditStatusComplete},
+			{Feature: Feature{ID: "F2"}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "F3"}, Status: AuditStatusComplete},
+		},
+		Gaps: []Gap{{FeatureID: "F2", Status: AuditStatusPartial}},
+	}
+
+	partial, ok := recheckSpec(spec, prev)
+	if !ok {
+		t.Fatal("recheckSpec() ok = false, want a partial spec")
+	}
+	if partial.Name != "Shop" || len(partial.Features) != 2 || partial.Features[0].ID != "F2" || partial.Features[1].ID != "F4" {
+		t.Errorf("recheckSpec() = %+v, want F2 (gap) and F4 (not audited)", partial)
+	}
+
+	prev.Gaps = nil
+	prev.Features = append(prev.Features, FeatureStatus{Feature: Feature{ID: "F4"}, Status: AuditStatusComplete})
+	if _, ok := recheckSpec(spec, prev); ok {
+		t.Error("recheckSpec() ok = true with nothing to recheck")
+	}
+	prev.Features = nil
+	if _, ok := recheckSpec(spec, prev); ok {
+		t.Error("recheckSpec() ok = true when every feature is rechecked")
+	}
+}
+
+func TestMergeIncrementalAudit(t *testing.T) {
+	spec := &ArchSpec{Features: []Feature{{ID: "F1"}, {ID: "F2"}, {ID: "F3"}}}
+	prev := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F1"}, Status: AuditStatusComplete},
+			{Feature: Feature{ID: "F2"}, Status: AuditStatusPartial},
+			{Feature: Feature{ID: "F3"}, Status: AuditStatusMissing},
+			{Feature: Feature{ID: "OLD"}, Status: AuditStatusMissing},
+		},
+		Gaps: []Gap{
+			{FeatureID: "F2", Status: AuditStatusPartial},
+			{FeatureID: "F3", Status: AuditStatusMissing},
+			{FeatureID: "OLD", Status: AuditStatusMissing},
+		},
+		Summary: "old summary",
+	}
+	partial := &GapReport{
+		Features: []FeatureStatus{
+			{Feature: Feature{ID: "F2"}, Status: AuditStatusComplete},
+			{Feature: Feature{ID: "F3"}, Status: AuditStatusPartial},
+		},
+		Gaps:    []Gap{{FeatureID: "F3", Status: AuditStatusPartial}},
+		Summary: "new summary",
+	}
+
+	merged := mergeIncrementalAudit(spec, prev, partial)
+	if len(merged.Features) != 3 {
+		t.Fatalf("got %d features, want 3: %+v", len(merged.Features), merged.Features)
+	}
+	status := make(map[string]AuditStatus)
+	for _, fs := range merged.Features {
+		status[fs.Feature.ID] = fs.Status
+	}
+	if status["F1"] != AuditStatusComplete || status["F2"] != AuditStatusComplete || status["F3"] != AuditStatusPartial {
+		t.Errorf("statuses = %v", status)
+	}
+	if len(merged.Gaps) != 1 || merged.Gaps[0].FeatureID != "F3" || merged.Gaps[0].Status != AuditStatusPartial {
+		t.Errorf("gaps = %+v, want only the rechecked gap of F3", merged.Gaps)
+	}
+	if merged.Summary != "new summary" {
+		t.Errorf("Summary = %q", merged.Summary)
+	}
+	if merged.Score == nil || merged.Score.Total != 3 || merged.Score.Complete != 2 {
+		t.Errorf("Score = %+v, want 2 of 3 complete", merged.Score)
+	}
+}
@@ -0,0 +1,312 @@
+// Package bitbucket implements remote.Provider for Bitbucket Cloud: it pushes
+// the session branch, opens a pull request, reports results as build
+// statuses and posts reviewer concerns as pull request comments. Bitbucket
+// has no standard CLI, so it calls the REST API directly, authenticating
+// with BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD or with BITBUCKET_TOKEN.
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+const (
+	// DefaultBaseURL is the Bitbucket Cloud REST API.
+	DefaultBaseURL = "https://api.bitbucket.org/2.0"
+	// maxBodyLen caps pull request descriptions, matching the GitHub provider.
+	maxBodyLen = 60000
+	// maxStatusDescriptionLen keeps build status descriptions readable.
+	maxStatusDescriptionLen = 255
+	// maxStatusKeyLen is Bitbucket's limit on build status keys.
+	maxStatusKeyLen = 40
+)
+
+// Client talks to the Bitbucket Cloud REST API and pushes with git.
+type Client struct {
+	git        *remote.Git
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	password   string
+	token      string
+}
+
+// NewClient creates a Client for the repository at repoPath with
+// credentials from the environment.
+func NewClient(repoPath string) *Client {
+	return NewClientWithExec(repoPath, iexec.NewRunner(), &http.Client{Timeout: 30 * time.Second})
+}
+
+// NewClientWithExec creates a Client with a custom command runner and HTTP
+// client (for testing). Credentials are read from the environment.
+func NewClientWithExec(repoPath string, runner iexec.CommandRunner, httpClient *http.Client) *Client {
+	return &Client{
+		git:        remote.NewGit(repoPath, runner),
+		httpClient: httpClient,
+		baseURL:    DefaultBaseURL,
+		username:   os.Getenv("BITBUCKET_USERNAME"),
+		password:   os.Getenv("BITBUCKET_APP_PASSWORD"),
+		token:      os.Getenv("BITBUCKET_TOKEN"),
+	}
+}
+
+// SetRemote sets the git remote branches are pushed to (default "origin").
+// Its URL also names the workspace and repository.
+func (c *Client) SetRemote(name string) {
+	c.git.Remote = name
+}
+
+// SetBaseURL sets the API root (default DefaultBaseURL).
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = strings.TrimSuffix(url, "/")
+}
+
+// SetCredentials sets the username and app password used instead of the
+// environment.
+func (c *Client) SetCredentials(username, appPassword string) {
+	c.username = username
+	c.password = appPassword
+}
+
+// SetToken sets an access token used instead of the environment.
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Name returns "bitbucket".
+func (c *Client) Name() string {
+	return string(remote.KindBitbucket)
+}
+
+// repository returns the "workspace/repo" slug of the remote.
+func (c *Client) repository(ctx context.Context) (string, error) {
+	url, err := c.git.URL(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, path, err := remote.ParseURL(url)
+	if err != nil {
+		return "", err
+	}
+	if strings.Count(path, "/") != 1 {
+		return "", fmt.Errorf("remote %q does not name a Bitbucket workspace and repository", url)
+	}
+	return path, nil
+}
+
+// do sends a JSON request to the repository's API and decodes the response
+// into out, if non-nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	default:
+		return errors.New("no Bitbucket credentials: set BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD, or BITBUCKET_TOKEN")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// branchRef names a branch in a pull request request.
+type branchRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+// newBranchRef returns a reference to the named branch.
+func newBranchRef(name string) branchRef {
+	var ref branchRef
+	ref.Branch.Name = name
+	return ref
+}
+
+// pullRequestRequest is the body of a "create a pull request" request.
+type pullRequestRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Source      branchRef `json:"source"`
+	Destination branchRef `json:"destination"`
+}
+
+// pullRequestResponse holds the fields read from a created pull request.
+type pullRequestResponse struct {
+	ID    int `json:"id"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// CreatePullRequest opens a pull request from head into base in the
+// repository named by repo ("workspace/repo").
+func (c *Client) CreatePullRequest(ctx context.Context, repo, base, head, title, body string) (*remote.PullRequest, error) {
+	in := pullRequestRequest{
+		Title:       title,
+		Description: remote.Truncate(body, maxBodyLen),
+		Source:      newBranchRef(head),
+		Destination: newBranchRef(base),
+	}
+	var out pullRequestResponse
+	if err := c.do(ctx, http.MethodPost, "/repositories/"+repo+"/pullrequests", in, &out); err != nil {
+		return nil, err
+	}
+	return &remote.PullRequest{Number: out.ID, URL: out.Links.HTML.Href}, nil
+}
+
+// buildStatus is the body of a "create a build status" request.
+type buildStatus struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// CreateStatus reports check as a build status linking to url. Bitbucket
+// statuses have no neutral state, so neutral checks are reported as
+// successful.
+func (c *Client) CreateStatus(ctx context.Context, repo string, check remote.CheckRun, url string) error {
+	state := "SUCCESSFUL"
+	if check.Conclusion == remote.ConclusionFailure {
+		state = "FAILED"
+	}
+	key := check.Name
+	if len(key) > maxStatusKeyLen {
+		key = key[:maxStatusKeyLen]
+	}
+	in := buildStatus{
+		Key:         key,
+		Name:        check.Name,
+		State:       state,
+		Description: remote.Truncate(check.Title, maxStatusDescriptionLen),
+		URL:         url,
+	}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repositories/%s/commit/%s/statuses/build", repo, check.HeadSHA), in, nil)
+}
+
+// comment is the body of a "create a pull request comment" request.
+type comment struct {
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline *inline `json:"inline,omitempty"`
+}
+
+// inline anchors a comment on a line of the new version of a file.
+type inline struct {
+	Path string `json:"path"`
+	To   int    `json:"to"`
+}
+
+// createComment posts a comment on the pull request.
+func (c *Client) createComment(ctx context.Context, repo string, id int, body string, at *inline) error {
+	in := comment{Inline: at}
+	in.Content.Raw = body
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/repositories/%s/pullrequests/%d/comments", repo, id), in, nil)
+}
+
+// Publish pushes the session branch, opens the pull request, reports the
+// checks on its head and posts the concerns as comments: one per concern
+// placed on a changed line, plus a summary comment.
+func (c *Client) Publish(ctx context.Context, sub remote.Submission) (*remote.PullRequest, error) {
+	repo, err := c.repository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repository: %w", err)
+	}
+	if err := c.git.Push(ctx, sub.Branch); err != nil {
+		return nil, fmt.Errorf("push session branch: %w", err)
+	}
+	pr, err := c.CreatePullRequest(ctx, repo, sub.Base, sub.Branch, sub.Title, sub.Body)
+	if err != nil {
+		return nil, fmt.Errorf("create pull request: %w", err)
+	}
+
+	var errs []error
+	head, err := c.git.RevParse(ctx, sub.Branch)
+	if err != nil {
+		return pr, fmt.Errorf("resolve head commit: %w", err)
+	}
+	for _, check := range sub.Checks {
+		check.HeadSHA = head
+		if err := c.CreateStatus(ctx, repo, check, pr.URL); err != nil {
+			errs = append(errs, fmt.Errorf("report check %s: %w", check.Name, err))
+		}
+	}
+
+	if len(sub.Concerns) > 0 {
+		if err := c.postConcerns(ctx, repo, pr.Number, sub); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return pr, errors.Join(errs...)
+}
+
+// postConcerns posts the summary comment and the inline concerns.
+func (c *Client) postConcerns(ctx context.Context, repo string, id int, sub remote.Submission) error {
+	diff, err := c.git.Diff(ctx, sub.Base, sub.Branch)
+	if err != nil {
+		return fmt.Errorf("diff session branch: %w", err)
+	}
+	placed, general := remote.PlaceConcerns(sub.Concerns, annotate.ParseDiff(diff))
+
+	var errs []error
+	if err := c.createComment(ctx, repo, id, remote.ReviewSummary(len(sub.Concerns), general), nil); err != nil {
+		errs = append(errs, fmt.Errorf("post review summary: %w", err))
+	}
+	for _, concern := range placed {
+		at := &inline{Path: concern.Path, To: concern.Line}
+		if err := c.createComment(ctx, repo, id, concern.InlineComment(), at); err != nil {
+			errs = append(errs, fmt.Errorf("post comment on %s:%d: %w", concern.Path, concern.Line, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify Client implements remote.Provider at compile time.
+var _ remote.Provider = (*Client)(nil)
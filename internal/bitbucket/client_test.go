@@ -0,0 +1,254 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// fakeRunner answers git commands by the first matching prefix.
+type fakeRunner struct {
+	calls   [][]string
+	outputs map[string]string
+	fail    map[string]bool
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{outputs: make(map[string]string), fail: make(map[string]bool)}
+}
+
+func (f *fakeRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	call := append([]string{name}, args...)
+	f.calls = append(f.calls, call)
+	line := strings.Join(call, " ")
+	for prefix := range f.fail {
+		if strings.HasPrefix(line, prefix) {
+			return []byte("boom"), errors.New("exit status 1")
+		}
+	}
+	for prefix, out := range f.outputs {
+		if strings.HasPrefix(line, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return f.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (f *fakeRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+// request is a request received by the fake API.
+type request struct {
+	method string
+	path   string
+	auth   string
+	body   map[string]any
+}
+
+// fakeAPI records requests and answers pull request creation.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []request
+	// failPath makes requests whose path contains it fail.
+	failPath string
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	var body map[string]any
+	json.Unmarshal(data, &body)
+
+	a.mu.Lock()
+	a.requests = append(a.requests, request{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), body: body})
+	a.mu.Unlock()
+
+	if a.failPath != "" && strings.Contains(r.URL.Path, a.failPath) {
+		http.Error(w, `{"error":{"message":"nope"}}`, http.StatusBadRequest)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/pullrequests") {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id": 12, "links": {"html": {"href": "https://bitbucket.org/acme/app/pull-requests/12"}}}`)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	io.WriteString(w, `{}`)
+}
+
+// matching returns the requests whose path has the given suffix.
+func (a *fakeAPI) matching(suffix string) []request {
+	var out []request
+	for _, r := range a.requests {
+		if strings.HasSuffix(r.path, suffix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+const sampleDiff = `diff --git a/internal/auth/login.go b/internal/auth/login.go
+--- a/internal/auth/login.go
++++ b/internal/auth/login.go
+@@ -10,3 +10,6 @@ func Login() {
+ 	a := 1
++	b := 2
++	c := 3
++	d := 4
+ 	return
+ }
+`
+
+func newTestClient(t *testing.T, api *fakeAPI) (*Client, *fakeRunner) {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	runner := newFakeRunner()
+	runner.outputs["git remote get-url"] = "git@bitbucket.org:acme/app.git\n"
+	runner.outputs["git rev-parse"] = "deadbeef\n"
+	runner.outputs["git diff"] = sampleDiff
+	client := NewClientWithExec("/repo", runner, server.Client())
+	client.SetBaseURL(server.URL)
+	client.SetCredentials("alice", "secret")
+	client.SetToken("")
+	return client, runner
+}
+
+func TestClient_Publish(t *testing.T) {
+	api := &fakeAPI{}
+	client, runner := newTestClient(t, api)
+
+	pr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "alphie/implement-1",
+		Title:  "Implement spec",
+		Body:   "body",
+		Checks: []remote.CheckRun{
+			{Name: "alphie/validation", Conclusion: remote.ConclusionNeutral, Title: "No assessments"},
+			{Name: "alphie/final-verification", Conclusion: remote.ConclusionFailure, Title: "2 gaps remain"},
+		},
+		Concerns: []remote.Concern{
+			{TaskID: "t1", TaskTitle: "Add login", Text: "internal/auth/login.go:12 ignores the error"},
+			{TaskID: "t2", Text: "No tests cover expiry"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if pr.Number != 12 || pr.URL != "https://bitbucket.org/acme/app/pull-requests/12" {
+		t.Errorf("Publish() = %+v", pr)
+	}
+
+	pushed := false
+	for _, call := range runner.calls {
+		if strings.Join(call, " ") == "git push --set-upstream origin alphie/implement-1" {
+			pushed = true
+		}
+	}
+	if !pushed {
+		t.Errorf("expected the branch to be pushed, calls = %v", runner.calls)
+	}
+
+	created := api.matching("/repositories/acme/app/pullrequests")
+	if len(created) != 1 {
+		t.Fatalf("expected one pull request, requests = %+v", api.requests)
+	}
+	if !strings.HasPrefix(created[0].auth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth", created[0].auth)
+	}
+	source := created[0].body["source"].(map[string]any)["branch"].(map[string]any)["name"]
+	destination := created[0].body["destination"].(map[string]any)["branch"].(map[string]any)["name"]
+	if source != "alphie/implement-1" || destination != "main" {
+		t.Errorf("source = %v, destination = %v", source, destination)
+	}
+
+	statuses := api.matching("/commit/deadbeef/statuses/build")
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 build statuses, got %d", len(statuses))
+	}
+	if statuses[0].body["state"] != "SUCCESSFUL" || statuses[1].body["state"] != "FAILED" {
+		t.Errorf("states = %v, %v", statuses[0].body["state"], statuses[1].body["state"])
+	}
+	if statuses[1].body["url"] != pr.URL {
+		t.Errorf("status url = %v, want the pull request", statuses[1].body["url"])
+	}
+
+	comments := api.matching("/pullrequests/12/comments")
+	if len(comments) != 2 {
+		t.Fatalf("expected a summary and an inline comment, got %d", len(comments))
+	}
+	if _, ok := comments[0].body["inline"]; ok {
+		t.Error("summary comment should not be inline")
+	}
+	at, ok := comments[1].body["inline"].(map[string]any)
+	if !ok || at["path"] != "internal/auth/login.go" || at["to"] != float64(12) {
+		t.Errorf("inline = %v", comments[1].body["inline"])
+	}
+}
+
+func TestClient_Publish_Token(t *testing.T) {
+	api := &fakeAPI{}
+	client, _ := newTestClient(t, api)
+	client.SetToken("tok")
+
+	if _, err := client.Publish(context.Background(), remote.Submission{Base: "main", Branch: "b"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := api.requests[0].auth; got != "Bearer tok" {
+		t.Errorf("Authorization = %q, want the token", got)
+	}
+}
+
+func TestClient_Publish_NoCredentials(t *testing.T) {
+	api := &fakeAPI{}
+	client, _ := newTestClient(t, api)
+	client.SetCredentials("", "")
+
+	pr, err := client.Publish(context.Background(), remote.Submission{Base: "main", Branch: "b"})
+	if err == nil || pr != nil || !strings.Contains(err.Error(), "BITBUCKET_TOKEN") {
+		t.Fatalf("Publish() = %v, %v; want a credentials error", pr, err)
+	}
+	if len(api.requests) != 0 {
+		t.Errorf("no request should be sent without credentials, got %d", len(api.requests))
+	}
+}
+
+func TestClient_Publish_KeepsPullRequestOnStatusFailure(t *testing.T) {
+	api := &fakeAPI{failPath: "/statuses/"}
+	client, _ := newTestClient(t, api)
+
+	pr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "b",
+		Checks: []remote.CheckRun{{Name: "alphie/validation"}},
+	})
+	if pr == nil || pr.Number != 12 {
+		t.Fatalf("Publish() pr = %v, want pull request 12", pr)
+	}
+	if err == nil || !strings.Contains(err.Error(), "report check alphie/validation") {
+		t.Errorf("Publish() error = %v, want the status failure", err)
+	}
+}
+
+func TestClient_Publish_RemoteNotBitbucket(t *testing.T) {
+	api := &fakeAPI{}
+	client, runner := newTestClient(t, api)
+	runner.outputs["git remote get-url"] = "https://bitbucket.org/acme"
+
+	if _, err := client.Publish(context.Background(), remote.Submission{Base: "main", Branch: "b"}); err == nil {
+		t.Fatal("expected an error for a remote without a repository")
+	}
+}
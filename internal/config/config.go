@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -17,12 +19,48 @@ import (
 type Config struct {
 	Anthropic    AnthropicConfig    `mapstructure:"anthropic"`
 	AWS          AWSConfig          `mapstructure:"aws"`
+	RateLimits   RateLimitsConfig   `mapstructure:"rate_limits"`
 	Defaults     DefaultsConfig     `mapstructure:"defaults"`
 	TUI          TUIConfig          `mapstructure:"tui"`
 	Timeouts     TimeoutsConfig     `mapstructure:"timeouts"`
 	QualityGates QualityGatesConfig `mapstructure:"quality_gates"`
+	Scheduling   SchedulingConfig   `mapstructure:"scheduling"`
+	Merge        MergeConfig        `mapstructure:"merge"`
+	Events       EventsConfig       `mapstructure:"events"`
+	Logging      LoggingConfig      `mapstructure:"logging"`
+	Redaction    RedactionConfig    `mapstructure:"redaction"`
+	Remote       RemoteConfig       `mapstructure:"remote"`
+	Commit       CommitConfig       `mapstructure:"commit"`
+	SecondReview SecondReviewConfig `mapstructure:"second_review"`
+	Resources    ResourcesConfig    `mapstructure:"resources"`
+	Approval     ApprovalConfig     `mapstructure:"approval"`
+	// ReviewRubric lists the criteria the semantic review and second
+	// reviewers give a structured verdict on.
+	ReviewRubric []RubricCriterionConfig `mapstructure:"review_rubric"`
+	// Budget, ProtectedAreas and Commands are usually set per project by
+	// the init wizard.
+	Budget         BudgetConfig         `mapstructure:"budget"`
+	ProtectedAreas ProtectedAreasConfig `mapstructure:"protected_areas"`
+	Commands       CommandsConfig       `mapstructure:"commands"`
+	// WarmUp lists setup commands run in task worktrees before agents
+	// start and before validation.
+	WarmUp []WarmUpConfig `mapstructure:"warm_up"`
+	// ValidationLayers declares project-specific validation layers that
+	// tiers can list in their validation pipeline by name.
+	ValidationLayers []CommandLayerConfig `mapstructure:"validation_layers"`
+	// Sandbox lists the programs this repository's commands may start when
+	// a tier runs them in a sandbox.
+	Sandbox ProjectSandboxConfig `mapstructure:"sandbox"`
+	// VCS is how agent workspaces are isolated: "git" worktrees (the
+	// default), "plain" directory copies merged back as patches, or "auto"
+	// to pick by whether the project is a git repository.
+	VCS string `mapstructure:"vcs"`
 }
 
+// ProjectConfigFile is the project config written by the init wizard,
+// relative to the repository root.
+const ProjectConfigFile = ".alphie/config.yaml"
+
 // AnthropicConfig holds Anthropic API settings.
 type AnthropicConfig struct {
 	APIKey  string `mapstructure:"api_key"`
@@ -35,6 +73,23 @@ type AWSConfig struct {
 	Profile string `mapstructure:"profile"` // optional AWS profile name
 }
 
+// RateLimitsConfig paces the Claude API requests of all agents, reviewers
+// and validators of a run. Zero limits are unlimited.
+type RateLimitsConfig struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	TokensPerMinute   int `mapstructure:"tokens_per_minute"`
+	// MaxRetries is how often a request the API rate limits is retried.
+	MaxRetries int `mapstructure:"max_retries"`
+	// Models overrides the limits per model name.
+	Models map[string]ModelRateLimitConfig `mapstructure:"models"`
+}
+
+// ModelRateLimitConfig holds the rate limits of one model.
+type ModelRateLimitConfig struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	TokensPerMinute   int `mapstructure:"tokens_per_minute"`
+}
+
 // DefaultsConfig holds default values for Alphie sessions.
 type DefaultsConfig struct {
 	Tier        string `mapstructure:"tier"`
@@ -46,11 +101,64 @@ type TUIConfig struct {
 	RefreshRate time.Duration `mapstructure:"refresh_rate"`
 }
 
+// EventsConfig controls which orchestrator events are shown in the TUI and
+// headless log. The session event log always records every event.
+type EventsConfig struct {
+	// CoalesceWindow is the minimum interval between shown events of a
+	// coalesced type for the same task.
+	CoalesceWindow time.Duration `mapstructure:"coalesce_window"`
+	// Verbosity maps event types (e.g. agent_progress) to "all", "coalesce"
+	// or "off". Listed types override the built-in levels.
+	Verbosity map[string]string `mapstructure:"verbosity"`
+}
+
+// LoggingConfig controls the structured log of the orchestrator and the
+// implementation loop, written to standard error.
+type LoggingConfig struct {
+	// Level is the minimum level logged: debug, info, warn or error.
+	Level string `mapstructure:"level"`
+	// Format is "text" (key=value lines) or "json".
+	Format string `mapstructure:"format"`
+	// Modules overrides the level per module, e.g. merge_queue: debug.
+	Modules map[string]string `mapstructure:"modules"`
+}
+
+// RedactionConfig controls the masking of secrets in log files, the state
+// and learning databases, and requests sent to Claude.
+type RedactionConfig struct {
+	// Enabled turns redaction on; the built-in detectors always apply when
+	// it is.
+	Enabled bool `mapstructure:"enabled"`
+	// Patterns are extra regular expressions matching secrets.
+	Patterns []RedactionPattern `mapstructure:"patterns"`
+	// Entropy configures the detector of random-looking tokens.
+	Entropy EntropyConfig `mapstructure:"entropy"`
+}
+
+// RedactionPattern is a named regular expression matching a secret. If it
+// has a capture group named "secret", only that group is masked.
+type RedactionPattern struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// EntropyConfig configures the entropy detector, which masks long tokens
+// mixing letters and digits whose Shannon entropy reaches Threshold.
+type EntropyConfig struct {
+	// MinLength is the shortest token checked; 0 disables the detector.
+	MinLength int `mapstructure:"min_length"`
+	// Threshold is in bits per character; 0 means 4.5.
+	Threshold float64 `mapstructure:"threshold"`
+}
+
 // TimeoutsConfig holds timeout settings per tier.
 type TimeoutsConfig struct {
 	Scout     time.Duration `mapstructure:"scout"`
 	Builder   time.Duration `mapstructure:"builder"`
 	Architect time.Duration `mapstructure:"architect"`
+	// Stall is how long an agent may show no progress before it is stopped
+	// and its task requeued. 0 disables the watchdog.
+	Stall time.Duration `mapstructure:"stall"`
 }
 
 // QualityGatesConfig holds quality gate toggles.
@@ -61,6 +169,197 @@ type QualityGatesConfig struct {
 	Typecheck bool `mapstructure:"typecheck"`
 }
 
+// SchedulingConfig holds task scheduling settings.
+type SchedulingConfig struct {
+	// ResourceLocks declares shared resources that tasks must not use concurrently.
+	ResourceLocks []ResourceLockConfig `mapstructure:"resource_locks"`
+	// WorktreePoolSize is how many idle agent worktrees are kept for reuse
+	// by later tasks. 0 removes each worktree when its task finishes.
+	WorktreePoolSize int `mapstructure:"worktree_pool_size"`
+	// Preemption lets high-priority tasks, such as critical gap fixes, stop
+	// a running low-priority agent when all agent slots are busy.
+	Preemption bool `mapstructure:"preemption"`
+	// ConflictThreshold is the predicted conflict probability (0-1) at which
+	// two ready tasks are run one after the other. 0 disables prediction.
+	ConflictThreshold float64 `mapstructure:"conflict_threshold"`
+}
+
+// ResourcesConfig holds the disk and memory guardrails of a session.
+type ResourcesConfig struct {
+	// MinFreeDiskMB is the free disk, in MB, below which the session pauses
+	// spawning agents. 0 disables the disk check.
+	MinFreeDiskMB int `mapstructure:"min_free_disk_mb"`
+	// MinFreeMemoryMB is the available memory, in MB, below which the
+	// session pauses spawning agents. 0 disables the memory check.
+	MinFreeMemoryMB int `mapstructure:"min_free_memory_mb"`
+	// CheckInterval is how often free disk and memory are measured.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// CleanupCommands are shell commands run in the repository to free disk
+	// when a threshold is crossed, e.g. clearing build caches.
+	CleanupCommands []string `mapstructure:"cleanup_commands"`
+}
+
+// ApprovalConfig holds the points where a run waits for an operator's
+// approval, given through the TUI or alphie control.
+type ApprovalConfig struct {
+	// Gates are the gates that wait: decomposition (after the request is
+	// broken into tasks), merge (before the session merges into the default
+	// branch) and iteration (before each implement iteration after the
+	// first). Empty by default.
+	Gates []string `mapstructure:"gates"`
+}
+
+// ResourceLockConfig declares a shared resource and the task keywords that claim it.
+type ResourceLockConfig struct {
+	// Resource is the lock name (e.g. "db:schema", "port:3000").
+	Resource string `mapstructure:"resource"`
+	// Patterns are case-insensitive keywords matched against task title,
+	// description, and file boundaries.
+	Patterns []string `mapstructure:"patterns"`
+}
+
+// MergeConfig holds merge settings.
+type MergeConfig struct {
+	// SemanticMaxConflictFiles skips the semantic merger above this many
+	// conflicting files (0 = no limit).
+	SemanticMaxConflictFiles int `mapstructure:"semantic_max_conflict_files"`
+	// SemanticMaxConflictLines skips the semantic merger above this many
+	// changed lines in conflicting files (0 = no limit).
+	SemanticMaxConflictLines int `mapstructure:"semantic_max_conflict_lines"`
+	// OversizeConflictAction is "human" (default) or "reexecute".
+	OversizeConflictAction string `mapstructure:"oversize_conflict_action"`
+	// DefaultBranch is the branch sessions merge into. Empty detects the
+	// repository's default branch (origin/HEAD, init.defaultBranch, then
+	// main, master, trunk or develop).
+	DefaultBranch string `mapstructure:"default_branch"`
+	// OptimizeOrder merges branches that finish close together in the order
+	// with the fewest simulated conflicts instead of completion order.
+	OptimizeOrder bool `mapstructure:"optimize_order"`
+	// PartialMerge merges the non-conflicting files and hunks of a
+	// conflicting branch right away, holding back only the conflicts.
+	PartialMerge bool `mapstructure:"partial_merge"`
+	// ReviewConfidenceThreshold quarantines semantic merges scored below it
+	// for human review (0 = never quarantine).
+	ReviewConfidenceThreshold float64 `mapstructure:"review_confidence_threshold"`
+	// SessionReview gates merging a finished session into the default
+	// branch: "none" (default), "confirm" or "auto".
+	SessionReview string `mapstructure:"session_review"`
+}
+
+// RemoteConfig selects where `alphie implement --pr` publishes sessions.
+type RemoteConfig struct {
+	// Provider is "github", "gitlab", "bitbucket", or "auto" (default) to
+	// detect it from the remote's URL.
+	Provider string `mapstructure:"provider"`
+	// Remote is the git remote session branches are pushed to (default
+	// "origin").
+	Remote string `mapstructure:"remote"`
+}
+
+// CommitConfig sets who agent, session and merge commits are made as.
+// Empty fields fall back to the repository's git config.
+type CommitConfig struct {
+	// Name and Email are the commit author and committer, e.g.
+	// "Alphie Agent" <alphie@example.com>.
+	Name  string `mapstructure:"name"`
+	Email string `mapstructure:"email"`
+	// Sign is "gpg" or "ssh" to sign commits, or empty (default) to leave
+	// signing to the git config.
+	Sign string `mapstructure:"sign"`
+	// SigningKey is the GPG key ID or SSH key file used to sign. Empty uses
+	// the git config's user.signingkey.
+	SigningKey string `mapstructure:"signing_key"`
+	// MessageTemplate formats agent commit messages; {subject}, {task_id}
+	// and {session_id} are replaced. Empty uses the subject followed by
+	// Alphie-Task and Alphie-Session trailers.
+	MessageTemplate string `mapstructure:"message_template"`
+}
+
+// BudgetConfig holds cost limits in dollars (0 = unlimited).
+type BudgetConfig struct {
+	TaskLimit    float64 `mapstructure:"task_limit"`
+	SessionLimit float64 `mapstructure:"session_limit"`
+}
+
+// SecondReviewConfig configures the panel of reviewers that vote on
+// high-risk changes.
+type SecondReviewConfig struct {
+	// Quorum is how many selected reviewers must approve (0 = majority).
+	Quorum int `mapstructure:"quorum"`
+	// Reviewers is the panel. Empty uses a single general reviewer.
+	Reviewers []ReviewerConfig `mapstructure:"reviewers"`
+}
+
+// ReviewerConfig declares one second review panel member.
+type ReviewerConfig struct {
+	// Name identifies the reviewer in votes and findings.
+	Name string `mapstructure:"name"`
+	// Focus narrows the review, such as "security" or "performance".
+	Focus string `mapstructure:"focus"`
+	// Triggers are the review triggers this reviewer is selected for
+	// (protected_area, large_diff, weak_tests, cross_cutting). Empty
+	// selects the reviewer for every trigger.
+	Triggers []string `mapstructure:"triggers"`
+}
+
+// RubricCriterionConfig declares one review rubric criterion.
+type RubricCriterionConfig struct {
+	// Name identifies the criterion. The built-in criteria (security,
+	// error_handling, test_coverage, style, i18n, accessibility) need no
+	// description.
+	Name string `mapstructure:"name"`
+	// Description tells reviewers what the criterion checks.
+	Description string `mapstructure:"description"`
+}
+
+// ProtectedAreasConfig holds project-specific protected areas, added to the
+// built-in defaults.
+type ProtectedAreasConfig struct {
+	Patterns  []string `mapstructure:"patterns"`
+	Keywords  []string `mapstructure:"keywords"`
+	FileTypes []string `mapstructure:"file_types"`
+}
+
+// WarmUpConfig declares a worktree setup command (code generation, asset
+// compilation, schema load) and the paths it applies to.
+type WarmUpConfig struct {
+	// Name labels the command in logs.
+	Name string `mapstructure:"name"`
+	// Paths are glob patterns; the command runs for tasks touching a
+	// matching path, or for every task if empty.
+	Paths []string `mapstructure:"paths"`
+	// Command is run with sh -c in the worktree root.
+	Command string `mapstructure:"command"`
+	// Timeout bounds the command (default 5m).
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// CommandLayerConfig declares a validation layer that runs a command.
+type CommandLayerConfig struct {
+	// Name is the layer's name in tier validation pipelines.
+	Name string `mapstructure:"name"`
+	// Command is run with sh -c in the worktree root; the layer passes if
+	// it exits zero.
+	Command string `mapstructure:"command"`
+	// Paths are glob patterns; the layer runs for tasks touching a
+	// matching path, or for every task if empty.
+	Paths []string `mapstructure:"paths"`
+}
+
+// ProjectSandboxConfig holds a repository's sandbox allowlist.
+type ProjectSandboxConfig struct {
+	// Allowlist names programs, such as "go" or "npm", that sandboxed
+	// commands may start in addition to the tier's allowlist.
+	Allowlist []string `mapstructure:"allowlist"`
+}
+
+// CommandsConfig holds the project's build, test and lint commands.
+type CommandsConfig struct {
+	Build string `mapstructure:"build"`
+	Test  string `mapstructure:"test"`
+	Lint  string `mapstructure:"lint"`
+}
+
 // TierConfig holds configuration for a single tier loaded from YAML.
 type TierConfig struct {
 	// Tier is the tier name (scout, builder, architect).
@@ -83,6 +382,21 @@ type TierConfig struct {
 	Models *ModelsConfig `mapstructure:"models"`
 	// Review contains review settings.
 	Review *ReviewConfig `mapstructure:"review"`
+	// Retry contains task retry settings. Nil uses the orchestrator defaults.
+	Retry *RetryConfig `mapstructure:"retry"`
+	// Validation lists the validation layers tasks run. Nil uses the
+	// tier's default pipeline.
+	Validation *ValidationConfig `mapstructure:"validation"`
+	// Sandbox runs the tier's quality gate and verification commands in a
+	// sandbox. Nil runs them on the host.
+	Sandbox *SandboxConfig `mapstructure:"sandbox"`
+	// Escalation retries tasks that keep failing validation at a higher
+	// tier. Nil never escalates.
+	Escalation *EscalationConfig `mapstructure:"escalation"`
+	// ContextBudget is the budget, in estimated tokens, of the learnings,
+	// failure context, acceptance criteria and notes injected into the
+	// tier's prompts. 0 uses the built-in budget of the tier.
+	ContextBudget int `mapstructure:"context_budget"`
 }
 
 // OverrideGatesConfig holds override gate settings for Scout tier.
@@ -111,6 +425,126 @@ type ReviewConfig struct {
 	SampleConditions []string `mapstructure:"sample_conditions"`
 }
 
+// RetryConfig holds task retry settings for a tier.
+// Zero values keep the orchestrator defaults.
+type RetryConfig struct {
+	// MaxAttempts is the total number of executions allowed, including the first.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Backoff is the delay before the first retry; it doubles on each retry.
+	Backoff time.Duration `mapstructure:"backoff"`
+	// MaxBackoff caps the retry delay.
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	// Jitter randomizes each delay by up to this fraction (0-1).
+	Jitter float64 `mapstructure:"jitter"`
+	// RetryOn lists failure classifications to retry:
+	// execution, verification, timeout, budget.
+	RetryOn []string `mapstructure:"retry_on"`
+}
+
+// EscalationConfig holds a tier's rung of the escalation ladder: tasks that
+// fail validation AfterFailures times at this tier are retried at tier To,
+// whose own escalation config may escalate them further.
+type EscalationConfig struct {
+	// AfterFailures is how many validation failures at this tier escalate
+	// a task. Zero disables escalation.
+	AfterFailures int `mapstructure:"after_failures"`
+	// To is the tier the task is retried at (builder or architect).
+	To string `mapstructure:"to"`
+	// Model is the model used at tier To (haiku, sonnet, opus or a full
+	// model ID). Empty uses tier To's default model.
+	Model string `mapstructure:"model"`
+}
+
+// ValidationConfig holds the validation pipeline of a tier: the layers run
+// after an agent finishes, in order.
+type ValidationConfig struct {
+	// Layers lists the layers to run, in order: review (self-critique),
+	// gates (quality gates) and verification (verification contract).
+	// Layers not listed are skipped.
+	Layers []ValidationLayerConfig `mapstructure:"layers"`
+	// ContinueOnFailure runs the remaining layers after one fails instead
+	// of stopping at the first failure.
+	ContinueOnFailure bool `mapstructure:"continue_on_failure"`
+	// SkipReviewConfidence skips the review layer and the second review
+	// when a task's confidence score, from 0 to 1, reaches it. Zero
+	// always reviews.
+	SkipReviewConfidence float64 `mapstructure:"skip_review_confidence"`
+	// Linters are the linters the lint layer runs on changed files. Empty
+	// runs golangci-lint, eslint and ruff, each on the files it checks.
+	Linters []LinterConfig `mapstructure:"linters"`
+	// Coverage adds a coverage gate to the gates layer, failing a task
+	// whose changes lower a package's test coverage. Nil collects no
+	// coverage.
+	Coverage *CoverageConfig `mapstructure:"coverage"`
+}
+
+// CoverageConfig configures the coverage gate.
+type CoverageConfig struct {
+	// MaxDrop is how many percentage points the coverage of a package with
+	// changed files may drop below the session baseline.
+	MaxDrop float64 `mapstructure:"max_drop"`
+}
+
+// LinterConfig configures a linter of the lint validation layer.
+type LinterConfig struct {
+	// Name is the linter's name. With no command it selects a built-in
+	// linter: golangci-lint, eslint or ruff.
+	Name string `mapstructure:"name"`
+	// Command runs the linter; the changed files it checks are appended.
+	// It must print violations as "file:line[:col]: message" lines.
+	Command string `mapstructure:"command"`
+	// Extensions selects the changed files the linter checks, e.g. ".go".
+	// Empty checks every changed file.
+	Extensions []string `mapstructure:"extensions"`
+}
+
+// SandboxConfig configures the sandbox a tier's build and test commands
+// run in.
+type SandboxConfig struct {
+	// Backend is "docker", "nsjail" or "container" (a long-lived container
+	// per task, built from the project's devcontainer or Dockerfile).
+	Backend string `mapstructure:"backend"`
+	// Image is the Docker image commands run in. For "container" it is
+	// used instead of building one.
+	Image string `mapstructure:"image"`
+	// Dockerfile is the Dockerfile or devcontainer.json "container" builds
+	// from, relative to the repository. Empty detects one.
+	Dockerfile string `mapstructure:"dockerfile"`
+	// CPUs limits the CPU cores a command may use. Zero is unlimited.
+	CPUs float64 `mapstructure:"cpus"`
+	// MemoryMB limits a command's memory in megabytes. Zero is unlimited.
+	MemoryMB int `mapstructure:"memory_mb"`
+	// Network allows network access. Commands are offline by default.
+	Network bool `mapstructure:"network"`
+	// Allowlist names the programs commands may start. Empty, with no
+	// project allowlist either, allows any program.
+	Allowlist []string `mapstructure:"allowlist"`
+}
+
+// Exec converts the config to a sandbox configuration, adding the
+// project's allowlist to the tier's.
+func (c *SandboxConfig) Exec(projectAllowlist []string) iexec.SandboxConfig {
+	allowlist := append(append([]string(nil), c.Allowlist...), projectAllowlist...)
+	return iexec.SandboxConfig{
+		Backend:    strings.ToLower(strings.TrimSpace(c.Backend)),
+		Image:      c.Image,
+		Dockerfile: c.Dockerfile,
+		CPUs:       c.CPUs,
+		MemoryMB:   c.MemoryMB,
+		Network:    c.Network,
+		Allowlist:  allowlist,
+	}
+}
+
+// ValidationLayerConfig configures one validation layer.
+type ValidationLayerConfig struct {
+	// Name is the layer: review, gates, verification, lint, security or
+	// a registered layer.
+	Name string `mapstructure:"name"`
+	// Timeout bounds the layer. Zero leaves it bounded by the task timeout only.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // GetQuestionsAllowedInt returns the questions allowed as an integer.
 // Returns -1 for "unlimited", the numeric value otherwise.
 func (tc *TierConfig) GetQuestionsAllowedInt() int {
@@ -153,7 +587,7 @@ func (tc *TierConfigs) Get(tier models.Tier) *TierConfig {
 // Load loads configuration from XDG paths, project overrides, and environment variables.
 // Precedence (highest to lowest):
 // 1. Environment variables (ANTHROPIC_API_KEY)
-// 2. Project config (.alphie.yaml in current directory or parent)
+// 2. Project config (.alphie/config.yaml or .alphie.yaml in current directory or parent)
 // 3. User config (~/.config/alphie/config.yaml)
 // 4. Built-in defaults
 func Load() (*Config, error) {
@@ -195,6 +629,8 @@ func Load() (*Config, error) {
 	v.BindEnv("anthropic.api_key", "ANTHROPIC_API_KEY")
 	v.BindEnv("aws.region", "AWS_REGION", "AWS_DEFAULT_REGION")
 	v.BindEnv("aws.profile", "AWS_PROFILE")
+	v.BindEnv("logging.level", "ALPHIE_LOG_LEVEL")
+	v.BindEnv("logging.format", "ALPHIE_LOG_FORMAT")
 
 	// Expand environment variable references in api_key
 	cfg := &Config{}
@@ -245,9 +681,14 @@ func Save(cfg *Config) error {
 	v.Set("defaults.tier", cfg.Defaults.Tier)
 	v.Set("defaults.token_budget", cfg.Defaults.TokenBudget)
 	v.Set("tui.refresh_rate", cfg.TUI.RefreshRate.String())
+	v.Set("events.coalesce_window", cfg.Events.CoalesceWindow.String())
+	if len(cfg.Events.Verbosity) > 0 {
+		v.Set("events.verbosity", cfg.Events.Verbosity)
+	}
 	v.Set("timeouts.scout", cfg.Timeouts.Scout.String())
 	v.Set("timeouts.builder", cfg.Timeouts.Builder.String())
 	v.Set("timeouts.architect", cfg.Timeouts.Architect.String())
+	v.Set("timeouts.stall", cfg.Timeouts.Stall.String())
 	v.Set("quality_gates.test", cfg.QualityGates.Test)
 	v.Set("quality_gates.build", cfg.QualityGates.Build)
 	v.Set("quality_gates.lint", cfg.QualityGates.Lint)
@@ -256,6 +697,137 @@ func Save(cfg *Config) error {
 	return v.WriteConfig()
 }
 
+// SaveProject writes cfg as a project config file at path, creating parent
+// directories as needed. Unlike Save it includes the project-level sections
+// (scheduling, merge, remote, budget, protected areas, commands, warm-up).
+func SaveProject(cfg *Config, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	v.Set("defaults.tier", cfg.Defaults.Tier)
+	v.Set("defaults.token_budget", cfg.Defaults.TokenBudget)
+	v.Set("timeouts.scout", cfg.Timeouts.Scout.String())
+	v.Set("timeouts.builder", cfg.Timeouts.Builder.String())
+	v.Set("timeouts.architect", cfg.Timeouts.Architect.String())
+	v.Set("timeouts.stall", cfg.Timeouts.Stall.String())
+	v.Set("quality_gates.test", cfg.QualityGates.Test)
+	v.Set("quality_gates.build", cfg.QualityGates.Build)
+	v.Set("quality_gates.lint", cfg.QualityGates.Lint)
+	v.Set("quality_gates.typecheck", cfg.QualityGates.Typecheck)
+	v.Set("merge.semantic_max_conflict_files", cfg.Merge.SemanticMaxConflictFiles)
+	v.Set("merge.semantic_max_conflict_lines", cfg.Merge.SemanticMaxConflictLines)
+	v.Set("merge.oversize_conflict_action", cfg.Merge.OversizeConflictAction)
+	v.Set("merge.optimize_order", cfg.Merge.OptimizeOrder)
+	v.Set("merge.partial_merge", cfg.Merge.PartialMerge)
+	v.Set("merge.review_confidence_threshold", cfg.Merge.ReviewConfidenceThreshold)
+	v.Set("merge.session_review", cfg.Merge.SessionReview)
+	if cfg.Merge.DefaultBranch != "" {
+		v.Set("merge.default_branch", cfg.Merge.DefaultBranch)
+	}
+	v.Set("remote.provider", cfg.Remote.Provider)
+	v.Set("remote.remote", cfg.Remote.Remote)
+	v.Set("budget.task_limit", cfg.Budget.TaskLimit)
+	v.Set("budget.session_limit", cfg.Budget.SessionLimit)
+	v.Set("protected_areas.patterns", cfg.ProtectedAreas.Patterns)
+	v.Set("protected_areas.keywords", cfg.ProtectedAreas.Keywords)
+	v.Set("protected_areas.file_types", cfg.ProtectedAreas.FileTypes)
+	v.Set("commands.build", cfg.Commands.Build)
+	v.Set("commands.test", cfg.Commands.Test)
+	v.Set("commands.lint", cfg.Commands.Lint)
+	v.Set("scheduling.worktree_pool_size", cfg.Scheduling.WorktreePoolSize)
+	v.Set("scheduling.preemption", cfg.Scheduling.Preemption)
+	v.Set("scheduling.conflict_threshold", cfg.Scheduling.ConflictThreshold)
+	v.Set("resources.min_free_disk_mb", cfg.Resources.MinFreeDiskMB)
+	v.Set("resources.min_free_memory_mb", cfg.Resources.MinFreeMemoryMB)
+	v.Set("resources.check_interval", cfg.Resources.CheckInterval.String())
+	if len(cfg.Resources.CleanupCommands) > 0 {
+		v.Set("resources.cleanup_commands", cfg.Resources.CleanupCommands)
+	}
+	if len(cfg.Approval.Gates) > 0 {
+		v.Set("approval.gates", cfg.Approval.Gates)
+	}
+
+	if len(cfg.Scheduling.ResourceLocks) > 0 {
+		locks := make([]map[string]interface{}, 0, len(cfg.Scheduling.ResourceLocks))
+		for _, rl := range cfg.Scheduling.ResourceLocks {
+			locks = append(locks, map[string]interface{}{
+				"resource": rl.Resource,
+				"patterns": rl.Patterns,
+			})
+		}
+		v.Set("scheduling.resource_locks", locks)
+	}
+
+	if len(cfg.SecondReview.Reviewers) > 0 {
+		v.Set("second_review.quorum", cfg.SecondReview.Quorum)
+		reviewers := make([]map[string]interface{}, 0, len(cfg.SecondReview.Reviewers))
+		for _, r := range cfg.SecondReview.Reviewers {
+			reviewer := map[string]interface{}{"name": r.Name}
+			if r.Focus != "" {
+				reviewer["focus"] = r.Focus
+			}
+			if len(r.Triggers) > 0 {
+				reviewer["triggers"] = r.Triggers
+			}
+			reviewers = append(reviewers, reviewer)
+		}
+		v.Set("second_review.reviewers", reviewers)
+	}
+
+	if len(cfg.ReviewRubric) > 0 {
+		rubric := make([]map[string]interface{}, 0, len(cfg.ReviewRubric))
+		for _, c := range cfg.ReviewRubric {
+			criterion := map[string]interface{}{"name": c.Name}
+			if c.Description != "" {
+				criterion["description"] = c.Description
+			}
+			rubric = append(rubric, criterion)
+		}
+		v.Set("review_rubric", rubric)
+	}
+
+	if len(cfg.WarmUp) > 0 {
+		hooks := make([]map[string]interface{}, 0, len(cfg.WarmUp))
+		for _, w := range cfg.WarmUp {
+			hook := map[string]interface{}{
+				"command": w.Command,
+				"paths":   w.Paths,
+			}
+			if w.Name != "" {
+				hook["name"] = w.Name
+			}
+			if w.Timeout > 0 {
+				hook["timeout"] = w.Timeout.String()
+			}
+			hooks = append(hooks, hook)
+		}
+		v.Set("warm_up", hooks)
+	}
+	if len(cfg.ValidationLayers) > 0 {
+		layers := make([]map[string]interface{}, 0, len(cfg.ValidationLayers))
+		for _, l := range cfg.ValidationLayers {
+			layer := map[string]interface{}{
+				"name":    l.Name,
+				"command": l.Command,
+			}
+			if len(l.Paths) > 0 {
+				layer["paths"] = l.Paths
+			}
+			layers = append(layers, layer)
+		}
+		v.Set("validation_layers", layers)
+	}
+	if len(cfg.Sandbox.Allowlist) > 0 {
+		v.Set("sandbox.allowlist", cfg.Sandbox.Allowlist)
+	}
+
+	return v.WriteConfig()
+}
+
 // GetUserConfigPath returns the path to the user config file.
 func GetUserConfigPath() string {
 	return filepath.Join(getUserConfigDir(), "config.yaml")
@@ -276,6 +848,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("aws.region", "")
 	v.SetDefault("aws.profile", "")
 
+	// Rate limit defaults
+	v.SetDefault("rate_limits.max_retries", 5)
+
 	// Session defaults
 	v.SetDefault("defaults.tier", "builder")
 	v.SetDefault("defaults.token_budget", 100000)
@@ -283,16 +858,48 @@ func setDefaults(v *viper.Viper) {
 	// TUI defaults
 	v.SetDefault("tui.refresh_rate", "100ms")
 
+	// Event display defaults
+	v.SetDefault("events.coalesce_window", "2s")
+
+	// Logging defaults
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "text")
+
+	// Redaction defaults
+	v.SetDefault("redaction.enabled", true)
+
 	// Timeout defaults
 	v.SetDefault("timeouts.scout", "5m")
 	v.SetDefault("timeouts.builder", "15m")
 	v.SetDefault("timeouts.architect", "30m")
+	v.SetDefault("timeouts.stall", "10m")
 
 	// Quality gate defaults
 	v.SetDefault("quality_gates.test", true)
 	v.SetDefault("quality_gates.build", true)
 	v.SetDefault("quality_gates.lint", true)
 	v.SetDefault("quality_gates.typecheck", true)
+
+	// Merge defaults
+	v.SetDefault("merge.semantic_max_conflict_files", 8)
+	v.SetDefault("merge.semantic_max_conflict_lines", 400)
+	v.SetDefault("merge.oversize_conflict_action", "human")
+	v.SetDefault("merge.review_confidence_threshold", 0.6)
+	v.SetDefault("merge.session_review", "none")
+
+	// Remote defaults
+	v.SetDefault("remote.provider", "auto")
+	v.SetDefault("remote.remote", "origin")
+
+	// Scheduling defaults
+	v.SetDefault("scheduling.worktree_pool_size", 4)
+	v.SetDefault("scheduling.preemption", false)
+	v.SetDefault("scheduling.conflict_threshold", 0.7)
+
+	// Resource guardrail defaults
+	v.SetDefault("resources.min_free_disk_mb", 2048)
+	v.SetDefault("resources.min_free_memory_mb", 512)
+	v.SetDefault("resources.check_interval", "30s")
 }
 
 // getUserConfigDir returns the XDG config directory for Alphie.
@@ -310,7 +917,9 @@ func getUserConfigDir() string {
 	return filepath.Join(home, ".config", "alphie")
 }
 
-// findProjectConfig searches for .alphie.yaml in the current directory and parents.
+// findProjectConfig searches for .alphie/config.yaml, then .alphie.yaml, in the
+// current directory and parents. The wizard-written file wins so an untouched
+// .alphie.yaml template does not shadow it.
 func findProjectConfig() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -318,9 +927,11 @@ func findProjectConfig() string {
 	}
 
 	for {
-		configPath := filepath.Join(cwd, ".alphie.yaml")
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath
+		for _, name := range []string{ProjectConfigFile, ".alphie.yaml"} {
+			configPath := filepath.Join(cwd, name)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath
+			}
 		}
 
 		parent := filepath.Dir(cwd)
@@ -349,6 +960,9 @@ func Default() *Config {
 			Region:  "",
 			Profile: "",
 		},
+		RateLimits: RateLimitsConfig{
+			MaxRetries: 5,
+		},
 		Defaults: DefaultsConfig{
 			Tier:        "builder",
 			TokenBudget: 100000,
@@ -356,10 +970,21 @@ func Default() *Config {
 		TUI: TUIConfig{
 			RefreshRate: 100 * time.Millisecond,
 		},
+		Events: EventsConfig{
+			CoalesceWindow: 2 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Redaction: RedactionConfig{
+			Enabled: true,
+		},
 		Timeouts: TimeoutsConfig{
 			Scout:     5 * time.Minute,
 			Builder:   15 * time.Minute,
 			Architect: 30 * time.Minute,
+			Stall:     10 * time.Minute,
 		},
 		QualityGates: QualityGatesConfig{
 			Test:      true,
@@ -367,6 +992,26 @@ func Default() *Config {
 			Lint:      true,
 			Typecheck: true,
 		},
+		Merge: MergeConfig{
+			SemanticMaxConflictFiles:  8,
+			SemanticMaxConflictLines:  400,
+			OversizeConflictAction:    "human",
+			ReviewConfidenceThreshold: 0.6,
+			SessionReview:             "none",
+		},
+		Remote: RemoteConfig{
+			Provider: "auto",
+			Remote:   "origin",
+		},
+		Scheduling: SchedulingConfig{
+			WorktreePoolSize:  4,
+			ConflictThreshold: 0.7,
+		},
+		Resources: ResourcesConfig{
+			MinFreeDiskMB:   2048,
+			MinFreeMemoryMB: 512,
+			CheckInterval:   30 * time.Second,
+		},
 	}
 }
 
@@ -443,6 +1088,15 @@ func loadTierConfig(path string) (*TierConfig, error) {
 	if err := v.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("unmarshaling %s: %w", path, err)
 	}
+	if e := cfg.Escalation; e != nil && e.AfterFailures > 0 && !models.Tier(e.To).Valid() {
+		return nil, fmt.Errorf("%s: escalation: invalid tier %q", path, e.To)
+	}
+	// A broken sandbox must stop the run rather than fall back to the host
+	if cfg.Sandbox != nil {
+		if err := cfg.Sandbox.Exec(nil).Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
 
 	return cfg, nil
 }
@@ -467,6 +1121,10 @@ func DefaultTierConfigs() *TierConfigs {
 				Default:  "haiku",
 				Fallback: "",
 			},
+			Escalation: &EscalationConfig{
+				AfterFailures: 2,
+				To:            "builder",
+			},
 		},
 		Builder: &TierConfig{
 			Tier:               "builder",
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +37,14 @@ func TestDefault(t *testing.T) {
 		t.Errorf("expected architect timeout 30m, got %v", cfg.Timeouts.Architect)
 	}
 
+	if cfg.Timeouts.Stall != 10*time.Minute {
+		t.Errorf("expected stall timeout 10m, got %v", cfg.Timeouts.Stall)
+	}
+
+	if cfg.Resources.MinFreeDiskMB != 2048 || cfg.Resources.MinFreeMemoryMB != 512 || cfg.Resources.CheckInterval != 30*time.Second {
+		t.Errorf("expected resource guardrails 2048MB disk, 512MB memory every 30s, got %+v", cfg.Resources)
+	}
+
 	if !cfg.QualityGates.Test {
 		t.Error("expected quality_gates.test to be true")
 	}
@@ -51,6 +60,10 @@ func TestDefault(t *testing.T) {
 	if !cfg.QualityGates.Typecheck {
 		t.Error("expected quality_gates.typecheck to be true")
 	}
+
+	if cfg.Remote.Provider != "auto" || cfg.Remote.Remote != "origin" {
+		t.Errorf("expected remote auto/origin, got %+v", cfg.Remote)
+	}
 }
 
 func TestLoadFromPath(t *testing.T) {
@@ -75,6 +88,18 @@ quality_gates:
   build: true
   lint: false
   typecheck: true
+remote:
+  provider: gitlab
+logging:
+  level: warn
+  modules:
+    merge_queue: debug
+redaction:
+  patterns:
+    - name: internal-token
+      pattern: itk_[0-9a-f]{8}
+  entropy:
+    min_length: 32
 `
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
 		t.Fatalf("failed to write config file: %v", err)
@@ -112,6 +137,18 @@ quality_gates:
 	if !cfg.QualityGates.Build {
 		t.Error("expected quality_gates.build to be true")
 	}
+
+	if cfg.Remote.Provider != "gitlab" || cfg.Remote.Remote != "origin" {
+		t.Errorf("expected remote gitlab/origin, got %+v", cfg.Remote)
+	}
+
+	if cfg.Logging.Level != "warn" || cfg.Logging.Format != "text" || cfg.Logging.Modules["merge_queue"] != "debug" {
+		t.Errorf("expected logging warn/text with merge_queue at debug, got %+v", cfg.Logging)
+	}
+
+	if !cfg.Redaction.Enabled || len(cfg.Redaction.Patterns) != 1 || cfg.Redaction.Patterns[0].Name != "internal-token" || cfg.Redaction.Entropy.MinLength != 32 {
+		t.Errorf("expected redaction enabled with one pattern and entropy min length 32, got %+v", cfg.Redaction)
+	}
 }
 
 func TestExpandEnv(t *testing.T) {
@@ -175,6 +212,7 @@ quality_threshold: 7
 max_ralph_iterations: 5
 questions_allowed: 2
 timeout: 15m
+context_budget: 3000
 models:
   default: sonnet
   fallback: haiku
@@ -183,6 +221,20 @@ review:
   sample_conditions:
     - protected_area
     - large_diff
+validation:
+  continue_on_failure: true
+  skip_review_confidence: 0.85
+  layers:
+    - name: gates
+      timeout: 5m
+    - name: verification
+sandbox:
+  backend: docker
+  image: golang:1.24
+  cpus: 2
+  memory_mb: 4096
+  allowlist:
+    - go
 `
 	if err := os.WriteFile(filepath.Join(tmpDir, "builder.yaml"), []byte(builderContent), 0644); err != nil {
 		t.Fatalf("failed to write builder.yaml: %v", err)
@@ -260,6 +312,9 @@ review:
 	if tierCfg.Builder.GetQuestionsAllowedInt() != 2 {
 		t.Errorf("expected builder questions_allowed 2, got %d", tierCfg.Builder.GetQuestionsAllowedInt())
 	}
+	if tierCfg.Builder.ContextBudget != 3000 {
+		t.Errorf("expected builder context_budget 3000, got %d", tierCfg.Builder.ContextBudget)
+	}
 	if tierCfg.Builder.Review == nil {
 		t.Error("expected builder review to be non-nil")
 	} else {
@@ -267,6 +322,29 @@ review:
 			t.Error("expected sampled_second_reviewer to be true")
 		}
 	}
+	if v := tierCfg.Builder.Validation; v == nil {
+		t.Error("expected builder validation to be non-nil")
+	} else {
+		if !v.ContinueOnFailure || v.SkipReviewConfidence != 0.85 || len(v.Layers) != 2 {
+			t.Errorf("unexpected builder validation %+v", v)
+		} else if v.Layers[0].Name != "gates" || v.Layers[0].Timeout != 5*time.Minute || v.Layers[1].Timeout != 0 {
+			t.Errorf("unexpected builder validation layers %+v", v.Layers)
+		}
+	}
+	if tierCfg.Scout.Validation != nil {
+		t.Error("expected scout validation to be nil when not configured")
+	}
+	if sb := tierCfg.Builder.Sandbox; sb == nil {
+		t.Error("expected builder sandbox to be non-nil")
+	} else {
+		ex := sb.Exec([]string{"make"})
+		if ex.Backend != "docker" || ex.Image != "golang:1.24" || ex.CPUs != 2 || ex.MemoryMB != 4096 || ex.Network {
+			t.Errorf("unexpected builder sandbox %+v", ex)
+		}
+		if len(ex.Allowlist) != 2 || ex.Allowlist[0] != "go" || ex.Allowlist[1] != "make" {
+			t.Errorf("expected the project allowlist to be added, got %v", ex.Allowlist)
+		}
+	}
 
 	// Verify architect config
 	if tierCfg.Architect == nil {
@@ -497,3 +575,52 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadTierConfigs_InvalidSandbox(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, tier := range []string{"scout", "builder", "architect"} {
+		content := "tier: " + tier + "\n"
+		if tier == "builder" {
+			content += "sandbox:\n  backend: docker\n"
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, tier+".yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s.yaml: %v", tier, err)
+		}
+	}
+
+	_, err := LoadTierConfigs(tmpDir)
+	if err == nil || !strings.Contains(err.Error(), "requires an image") {
+		t.Errorf("expected a docker sandbox without an image to be rejected, got %v", err)
+	}
+}
+
+func TestLoadTierConfigs_Escalation(t *testing.T) {
+	write := func(dir, scoutContent string) {
+		for _, tier := range []string{"scout", "builder", "architect"} {
+			content := "tier: " + tier + "\n"
+			if tier == "scout" {
+				content += scoutContent
+			}
+			if err := os.WriteFile(filepath.Join(dir, tier+".yaml"), []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s.yaml: %v", tier, err)
+			}
+		}
+	}
+
+	valid := t.TempDir()
+	write(valid, "escalation:\n  after_failures: 2\n  to: builder\n  model: sonnet\n")
+	tiers, err := LoadTierConfigs(valid)
+	if err != nil {
+		t.Fatalf("LoadTierConfigs() error = %v", err)
+	}
+	e := tiers.Scout.Escalation
+	if e == nil || e.AfterFailures != 2 || e.To != "builder" || e.Model != "sonnet" {
+		t.Errorf("unexpected escalation config: %+v", e)
+	}
+
+	invalid := t.TempDir()
+	write(invalid, "escalation:\n  after_failures: 2\n  to: wizard\n")
+	if _, err := LoadTierConfigs(invalid); err == nil || !strings.Contains(err.Error(), "invalid tier") {
+		t.Errorf("expected an unknown escalation tier to be rejected, got %v", err)
+	}
+}
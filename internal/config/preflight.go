@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// PreflightStatus is the outcome of a single preflight check.
+type PreflightStatus string
+
+const (
+	// PreflightOK means the setting is valid.
+	PreflightOK PreflightStatus = "ok"
+	// PreflightWarn means the setting is usable but likely not what was intended.
+	PreflightWarn PreflightStatus = "warn"
+	// PreflightFail means the setting is invalid and a run would misbehave.
+	PreflightFail PreflightStatus = "fail"
+)
+
+// PreflightCheck is the result of validating one configuration setting.
+type PreflightCheck struct {
+	// Name identifies the setting (e.g. "defaults.tier").
+	Name string
+	// Status is the check outcome.
+	Status PreflightStatus
+	// Message explains the outcome.
+	Message string
+}
+
+// PreflightReport collects the results of Preflight.
+type PreflightReport struct {
+	Checks []PreflightCheck
+}
+
+// OK returns true if no check failed.
+func (r *PreflightReport) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == PreflightFail {
+			return false
+		}
+	}
+	return true
+}
+
+// add records a check result.
+func (r *PreflightReport) add(name string, status PreflightStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Preflight validates cfg before a run: tier and timeouts, budgets, merge
+// policy, resource locks, protected areas, and that the configured build,
+// test and lint commands are installed.
+func Preflight(cfg *Config) *PreflightReport {
+	r := &PreflightReport{}
+
+	// Tier
+	if tier := models.Tier(cfg.Defaults.Tier); tier.Valid() {
+		r.add("defaults.tier", PreflightOK, "%s", tier)
+	} else {
+		r.add("defaults.tier", PreflightFail, "unknown tier %q (use quick, scout, builder or architect)", cfg.Defaults.Tier)
+	}
+
+	// Timeouts
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"timeouts.scout", cfg.Timeouts.Scout},
+		{"timeouts.builder", cfg.Timeouts.Builder},
+		{"timeouts.architect", cfg.Timeouts.Architect},
+	}
+	for _, t := range timeouts {
+		if t.d <= 0 {
+			r.add(t.name, PreflightFail, "must be positive, got %s", t.d)
+		}
+	}
+
+	// Budgets
+	switch {
+	case cfg.Budget.TaskLimit < 0 || cfg.Budget.SessionLimit < 0:
+		r.add("budget", PreflightFail, "limits must not be negative")
+	case cfg.Budget.SessionLimit > 0 && cfg.Budget.TaskLimit > cfg.Budget.SessionLimit:
+		r.add("budget", PreflightWarn, "task limit $%.2f exceeds session limit $%.2f", cfg.Budget.TaskLimit, cfg.Budget.SessionLimit)
+	case cfg.Budget.TaskLimit == 0 && cfg.Budget.SessionLimit == 0:
+		r.add("budget", PreflightWarn, "no cost limits set")
+	default:
+		r.add("budget", PreflightOK, "task $%.2f, session $%.2f", cfg.Budget.TaskLimit, cfg.Budget.SessionLimit)
+	}
+
+	// Merge policy
+	switch cfg.Merge.OversizeConflictAction {
+	case "", "human", "reexecute":
+		if cfg.Merge.SemanticMaxConflictFiles < 0 || cfg.Merge.SemanticMaxConflictLines < 0 {
+			r.add("merge", PreflightFail, "conflict limits must not be negative")
+		} else {
+			r.add("merge", PreflightOK, "semantic merge up to %d files / %d lines",
+				cfg.Merge.SemanticMaxConflictFiles, cfg.Merge.SemanticMaxConflictLines)
+		}
+	default:
+		r.add("merge.oversize_conflict_action", PreflightFail, "unknown action %q (use human or reexecute)", cfg.Merge.OversizeConflictAction)
+	}
+	if t := cfg.Merge.ReviewConfidenceThreshold; t < 0 || t > 1 {
+		r.add("merge.review_confidence_threshold", PreflightFail, "%.2f is outside 0..1", t)
+	}
+	if t := cfg.Scheduling.ConflictThreshold; t < 0 || t > 1 {
+		r.add("scheduling.conflict_threshold", PreflightFail, "%.2f is outside 0..1", t)
+	}
+	if cfg.Resources.MinFreeDiskMB < 0 || cfg.Resources.MinFreeMemoryMB < 0 {
+		r.add("resources", PreflightFail, "free disk and memory thresholds must not be negative")
+	} else if (cfg.Resources.MinFreeDiskMB > 0 || cfg.Resources.MinFreeMemoryMB > 0) && cfg.Resources.CheckInterval <= 0 {
+		r.add("resources.check_interval", PreflightFail, "%s must be positive to check free disk and memory", cfg.Resources.CheckInterval)
+	}
+	for _, gate := range cfg.Approval.Gates {
+		switch gate {
+		case "decomposition", "merge", "iteration":
+		default:
+			r.add("approval.gates", PreflightFail, "unknown gate %q (use decomposition, merge or iteration)", gate)
+		}
+	}
+	switch cfg.Merge.SessionReview {
+	case "", "none", "confirm", "auto":
+	default:
+		r.add("merge.session_review", PreflightFail, "unknown gate %q (use none, confirm or auto)", cfg.Merge.SessionReview)
+	}
+
+	// Rate limits
+	rateLimitsOK := cfg.RateLimits.RequestsPerMinute >= 0 && cfg.RateLimits.TokensPerMinute >= 0 && cfg.RateLimits.MaxRetries >= 0
+	for _, m := range cfg.RateLimits.Models {
+		rateLimitsOK = rateLimitsOK && m.RequestsPerMinute >= 0 && m.TokensPerMinute >= 0
+	}
+	if !rateLimitsOK {
+		r.add("rate_limits", PreflightFail, "limits and retries must not be negative")
+	}
+
+	// Commit identity
+	switch cfg.Commit.Sign {
+	case "", "gpg", "ssh":
+	default:
+		r.add("commit.sign", PreflightFail, "unknown signing format %q (use gpg or ssh)", cfg.Commit.Sign)
+	}
+	if (cfg.Commit.Name == "") != (cfg.Commit.Email == "") {
+		r.add("commit", PreflightWarn, "set both name and email; the other comes from the git config")
+	}
+
+	// Event verbosity
+	for eventType, level := range cfg.Events.Verbosity {
+		switch level {
+		case "all", "coalesce", "off":
+		default:
+			r.add("events.verbosity."+eventType, PreflightFail, "unknown level %q (use all, coalesce or off)", level)
+		}
+	}
+	if cfg.Events.CoalesceWindow < 0 {
+		r.add("events.coalesce_window", PreflightFail, "must not be negative")
+	}
+
+	// Resource locks
+	for i, rl := range cfg.Scheduling.ResourceLocks {
+		if rl.Resource == "" || len(rl.Patterns) == 0 {
+			r.add(fmt.Sprintf("scheduling.resource_locks[%d]", i), PreflightWarn, "needs a resource and at least one pattern; it will be ignored")
+		}
+	}
+
+	if cfg.Scheduling.WorktreePoolSize < 0 {
+		r.add("scheduling.worktree_pool_size", PreflightFail, "must not be negative")
+	}
+
+	// Second review panel
+	if cfg.SecondReview.Quorum < 0 || (len(cfg.SecondReview.Reviewers) > 0 && cfg.SecondReview.Quorum > len(cfg.SecondReview.Reviewers)) {
+		r.add("second_review.quorum", PreflightWarn, "should be between 0 (majority) and the number of reviewers")
+	}
+	for i, rv := range cfg.SecondReview.Reviewers {
+		if rv.Name == "" {
+			r.add(fmt.Sprintf("second_review.reviewers[%d]", i), PreflightWarn, "needs a name; it will be ignored")
+		}
+		for _, trigger := range rv.Triggers {
+			switch trigger {
+			case "protected_area", "large_diff", "weak_tests", "cross_cutting":
+			default:
+				r.add(fmt.Sprintf("second_review.reviewers[%d].triggers", i), PreflightFail,
+					"unknown trigger %q (use protected_area, large_diff, weak_tests or cross_cutting)", trigger)
+			}
+		}
+	}
+
+	// Review rubric
+	for i, c := range cfg.ReviewRubric {
+		switch {
+		case c.Name == "":
+			r.add(fmt.Sprintf("review_rubric[%d]", i), PreflightWarn, "needs a name; it will be ignored")
+		case c.Description != "":
+		case c.Name == "security", c.Name == "error_handling", c.Name == "test_coverage",
+			c.Name == "style", c.Name == "i18n", c.Name == "accessibility":
+		default:
+			r.add(fmt.Sprintf("review_rubric[%d]", i), PreflightWarn,
+				"criterion %q is not built in and needs a description; it will be ignored", c.Name)
+		}
+	}
+
+	// Protected areas
+	for _, p := range cfg.ProtectedAreas.Patterns {
+		if strings.TrimSpace(p) == "" {
+			r.add("protected_areas.patterns", PreflightWarn, "contains an empty pattern")
+			break
+		}
+	}
+
+	// Commands
+	commands := []struct{ name, cmd string }{
+		{"commands.build", cfg.Commands.Build},
+		{"commands.test", cfg.Commands.Test},
+		{"commands.lint", cfg.Commands.Lint},
+	}
+	for i, w := range cfg.WarmUp {
+		key := fmt.Sprintf("warm_up[%d]", i)
+		if strings.TrimSpace(w.Command) == "" {
+			r.add(key, PreflightWarn, "has no command; it will be ignored")
+			continue
+		}
+		if w.Timeout < 0 {
+			r.add(key+".timeout", PreflightFail, "must not be negative")
+		}
+		commands = append(commands, struct{ name, cmd string }{key + ".command", w.Command})
+	}
+	for i, l := range cfg.ValidationLayers {
+		key := fmt.Sprintf("validation_layers[%d]", i)
+		switch strings.ToLower(strings.TrimSpace(l.Name)) {
+		case "":
+			r.add(key, PreflightWarn, "needs a name; it will be ignored")
+			continue
+		case "review", "gates", "verification", "lint", "security":
+			r.add(key+".name", PreflightFail, "%q is a built-in layer", l.Name)
+			continue
+		}
+		if strings.TrimSpace(l.Command) == "" {
+			r.add(key, PreflightWarn, "has no command; it will be ignored")
+			continue
+		}
+		commands = append(commands, struct{ name, cmd string }{key + ".command", l.Command})
+	}
+	for _, c := range commands {
+		fields := strings.Fields(c.cmd)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(fields[0]); err != nil {
+			r.add(c.name, PreflightWarn, "%q not found in PATH", fields[0])
+		} else {
+			r.add(c.name, PreflightOK, "%s", c.cmd)
+		}
+	}
+
+	return r
+}
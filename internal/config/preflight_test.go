@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func findCheck(r *PreflightReport, name string) *PreflightCheck {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+func TestPreflight_Default(t *testing.T) {
+	cfg := Default()
+	cfg.Budget = BudgetConfig{TaskLimit: 2, SessionLimit: 10}
+
+	report := Preflight(cfg)
+	if !report.OK() {
+		t.Fatalf("expected default config to pass preflight, got %+v", report.Checks)
+	}
+	if c := findCheck(report, "defaults.tier"); c == nil || c.Status != PreflightOK {
+		t.Errorf("expected ok tier check, got %+v", c)
+	}
+}
+
+func TestPreflight_Failures(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.Tier = "wizard"
+	cfg.Timeouts.Builder = 0
+	cfg.Merge.OversizeConflictAction = "ignore"
+	cfg.Budget = BudgetConfig{TaskLimit: 10, SessionLimit: 5}
+	cfg.Commands.Test = "definitely-not-a-real-binary-xyz --all"
+	cfg.Events.Verbosity = map[string]string{"agent_progress": "quiet"}
+	cfg.SecondReview.Reviewers = []ReviewerConfig{{Name: "security", Triggers: []string{"auth"}}}
+	cfg.Commit.Sign = "pgp"
+
+	report := Preflight(cfg)
+	if report.OK() {
+		t.Fatal("expected preflight to fail")
+	}
+
+	expect := map[string]PreflightStatus{
+		"defaults.tier":                       PreflightFail,
+		"timeouts.builder":                    PreflightFail,
+		"merge.oversize_conflict_action":      PreflightFail,
+		"budget":                              PreflightWarn,
+		"commands.test":                       PreflightWarn,
+		"events.verbosity.agent_progress":     PreflightFail,
+		"second_review.reviewers[0].triggers": PreflightFail,
+		"commit.sign":                         PreflightFail,
+	}
+	for name, status := range expect {
+		c := findCheck(report, name)
+		if c == nil {
+			t.Errorf("missing check %s", name)
+			continue
+		}
+		if c.Status != status {
+			t.Errorf("%s: status = %s, want %s (%s)", name, c.Status, status, c.Message)
+		}
+	}
+}
+
+func TestSaveProject_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".alphie", "config.yaml")
+
+	cfg := Default()
+	cfg.Defaults.Tier = "scout"
+	cfg.Budget = BudgetConfig{TaskLimit: 1.25, SessionLimit: 5}
+	cfg.ProtectedAreas.Patterns = []string{"deploy/**"}
+	cfg.Commands = CommandsConfig{Build: "go build ./...", Test: "go test ./..."}
+	cfg.Merge.OversizeConflictAction = "reexecute"
+	cfg.Scheduling.ResourceLocks = []ResourceLockConfig{{Resource: "db:schema", Patterns: []string{"migration"}}}
+	cfg.WarmUp = []WarmUpConfig{{Name: "codegen", Paths: []string{"api/**/*.proto"}, Command: "make generate", Timeout: 2 * time.Minute}}
+	cfg.Sandbox.Allowlist = []string{"go", "make"}
+	cfg.SecondReview = SecondReviewConfig{Quorum: 2, Reviewers: []ReviewerConfig{
+		{Name: "general"},
+		{Name: "security", Focus: "security", Triggers: []string{"protected_area"}},
+	}}
+
+	if err := SaveProject(cfg, path); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath: %v", err)
+	}
+	if loaded.Defaults.Tier != "scout" {
+		t.Errorf("tier = %q, want scout", loaded.Defaults.Tier)
+	}
+	if loaded.Budget != cfg.Budget {
+		t.Errorf("budget = %+v, want %+v", loaded.Budget, cfg.Budget)
+	}
+	if len(loaded.ProtectedAreas.Patterns) != 1 || loaded.ProtectedAreas.Patterns[0] != "deploy/**" {
+		t.Errorf("protected patterns = %v", loaded.ProtectedAreas.Patterns)
+	}
+	if loaded.Commands != cfg.Commands {
+		t.Errorf("commands = %+v, want %+v", loaded.Commands, cfg.Commands)
+	}
+	if loaded.Merge.OversizeConflictAction != "reexecute" {
+		t.Errorf("oversize action = %q, want reexecute", loaded.Merge.OversizeConflictAction)
+	}
+	if len(loaded.Scheduling.ResourceLocks) != 1 || loaded.Scheduling.ResourceLocks[0].Resource != "db:schema" {
+		t.Errorf("resource locks = %+v", loaded.Scheduling.ResourceLocks)
+	}
+	if len(loaded.WarmUp) != 1 || loaded.WarmUp[0].Command != "make generate" ||
+		loaded.WarmUp[0].Timeout != 2*time.Minute || len(loaded.WarmUp[0].Paths) != 1 {
+		t.Errorf("warm up = %+v", loaded.WarmUp)
+	}
+	if len(loaded.Sandbox.Allowlist) != 2 || loaded.Sandbox.Allowlist[1] != "make" {
+		t.Errorf("sandbox allowlist = %v", loaded.Sandbox.Allowlist)
+	}
+	if loaded.SecondReview.Quorum != 2 || len(loaded.SecondReview.Reviewers) != 2 ||
+		loaded.SecondReview.Reviewers[1].Focus != "security" || len(loaded.SecondReview.Reviewers[1].Triggers) != 1 {
+		t.Errorf("second review = %+v", loaded.SecondReview)
+	}
+	if loaded.Timeouts.Builder != cfg.Timeouts.Builder {
+		t.Errorf("builder timeout = %s, want %s", loaded.Timeouts.Builder, cfg.Timeouts.Builder)
+	}
+}
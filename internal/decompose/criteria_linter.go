@@ -0,0 +1,131 @@
+package decompose
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// DefaultMaxRefinements is how many times Decompose asks Claude to refine
+// the acceptance criteria the CriteriaLinter flags.
+const DefaultMaxRefinements = 1
+
+// defaultAmbiguousTerms are words and phrases that make a criterion a
+// matter of opinion rather than a checkable outcome.
+var defaultAmbiguousTerms = []string{
+	"properly", "correctly", "appropriate", "appropriately", "as needed",
+	"as expected", "works well", "should work", "robust", "user-friendly",
+	"intuitive", "seamless", "seamlessly", "clean", "nice", "good",
+	"reasonable", "efficient", "efficiently", "fast", "quickly",
+	"if possible", "etc", "and so on", "various", "some", "tbd",
+}
+
+var (
+	// criterionBulletPattern matches a list marker starting a criterion.
+	criterionBulletPattern = regexp.MustCompile(`^(?:[-*•]|\d+[.)]|\[[ xX]\])\s+`)
+	// measurablePattern matches a signal that a criterion can be checked: a
+	// number, a code span, a quoted value, a path, an HTTP request or an
+	// observable outcome.
+	measurablePattern = regexp.MustCompile(`(?i)\d|` + "`" + `|"|'[^']+'|\w/\w|\.\w{1,4}\b|\b(?:GET|POST|PUT|PATCH|DELETE)\b|` +
+		`\b(?:returns?|responds?|pass(?:es)?|fails?|exits?|prints?|outputs?|logs?|contains?|exists?|creates?|writes?|deletes?|removes?|` +
+		`rejects?|accepts?|errors?|raises?|throws?|emits?|shows?|displays?|renders?|redirects?|stores?|saves?|persists?|applies|` +
+		`compiles?|builds?|succeeds?|matches|equals?|includes?|lists?|tests?|status|command)\b`)
+)
+
+// CriteriaIssue is a weak acceptance criterion of a decomposed task.
+type CriteriaIssue struct {
+	// Task is the title of the task.
+	Task string
+	// Criterion is the criterion as written.
+	Criterion string
+	// Problem says why the criterion cannot be checked.
+	Problem string
+}
+
+// String describes the issue for logs and refinement prompts.
+func (i CriteriaIssue) String() string {
+	return fmt.Sprintf("task %q: criterion %q %s", i.Task, i.Criterion, i.Problem)
+}
+
+// CriteriaLinter checks that the acceptance criteria of decomposed tasks
+// are testable: each criterion states a measurable outcome and avoids
+// terms that leave its meaning to the reader.
+type CriteriaLinter struct {
+	ambiguous []*regexp.Regexp
+	terms     []string
+}
+
+// NewCriteriaLinter creates a linter flagging the default ambiguous terms.
+func NewCriteriaLinter() *CriteriaLinter {
+	return NewCriteriaLinterWithTerms(defaultAmbiguousTerms)
+}
+
+// NewCriteriaLinterWithTerms creates a linter flagging terms as ambiguous.
+func NewCriteriaLinterWithTerms(terms []string) *CriteriaLinter {
+	l := &CriteriaLinter{}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		l.terms = append(l.terms, term)
+		l.ambiguous = append(l.ambiguous, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
+	}
+	return l
+}
+
+// Lint returns the weak acceptance criteria of tasks, in task order.
+func (l *CriteriaLinter) Lint(tasks []*models.Task) []CriteriaIssue {
+	var issues []CriteriaIssue
+	for _, task := range tasks {
+		issues = append(issues, l.LintCriteria(task.Title, task.AcceptanceCriteria)...)
+	}
+	return issues
+}
+
+// LintCriteria returns the weak criteria of one task's acceptance
+// criteria, read as one criterion per line (or per "; " on a single line).
+func (l *CriteriaLinter) LintCriteria(title, criteria string) []CriteriaIssue {
+	var issues []CriteriaIssue
+	for _, criterion := range splitCriteria(criteria) {
+		if term := l.ambiguousTerm(criterion); term != "" {
+			issues = append(issues, CriteriaIssue{Task: title, Criterion: criterion,
+				Problem: fmt.Sprintf("is ambiguous (%q); state the exact behavior to check", term)})
+			continue
+		}
+		if !measurablePattern.MatchString(criterion) {
+			issues = append(issues, CriteriaIssue{Task: title, Criterion: criterion,
+				Problem: "has no measurable outcome; name the command, test, output, status or file that proves it"})
+		}
+	}
+	return issues
+}
+
+// ambiguousTerm returns the first ambiguous term in criterion, if any.
+func (l *CriteriaLinter) ambiguousTerm(criterion string) string {
+	for i, pattern := range l.ambiguous {
+		if pattern.MatchString(criterion) {
+			return l.terms[i]
+		}
+	}
+	return ""
+}
+
+// splitCriteria splits acceptance criteria into single criteria, without
+// list markers.
+func splitCriteria(criteria string) []string {
+	lines := strings.Split(strings.TrimSpace(criteria), "\n")
+	if len(lines) == 1 {
+		lines = strings.Split(lines[0], "; ")
+	}
+	var out []string
+	for _, line := range lines {
+		line = strings.TrimSpace(criterionBulletPattern.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
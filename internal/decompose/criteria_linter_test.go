@@ -0,0 +1,57 @@
+package decompose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestCriteriaLinter_LintCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria string
+		want     []string
+	}{
+		{"measurable", "GET /users returns 200 with a JSON array", nil},
+		{"command", "`go test ./internal/auth/...` passes", nil},
+		{"ambiguous", "Login works properly", []string{`("properly")`}},
+		{"ambiguous word only", "The API is robust", []string{`("robust")`}},
+		{"not measurable", "Users can manage their profile", []string{"has no measurable outcome"}},
+		{"list", "- Returns 404 for unknown IDs\n- Handles errors correctly\n* Everything is nice", []string{
+			`"Handles errors correctly" is ambiguous`, `"Everything is nice" is ambiguous`,
+		}},
+		{"single line", "Exits 0 on success; performs well", []string{`"performs well" has no measurable`}},
+	}
+
+	linter := NewCriteriaLinter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := linter.LintCriteria("Task", tt.criteria)
+			if len(issues) != len(tt.want) {
+				t.Fatalf("issues = %v, want %d matching %q", issues, len(tt.want), tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(issues[i].String(), want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, issues[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCriteriaLinter_Lint(t *testing.T) {
+	tasks := []*models.Task{
+		{Title: "Schema", AcceptanceCriteria: "Migration creates the users table"},
+		{Title: "API", AcceptanceCriteria: "Endpoints behave as expected"},
+	}
+	issues := NewCriteriaLinter().Lint(tasks)
+	if len(issues) != 1 || issues[0].Task != "API" {
+		t.Errorf("Lint() = %v, want one issue for API", issues)
+	}
+
+	custom := NewCriteriaLinterWithTerms([]string{"users table"})
+	if issues := custom.Lint(tasks); len(issues) != 2 || !strings.Contains(issues[0].Problem, `"users table"`) {
+		t.Errorf("Lint() with custom terms = %v, want Schema flagged for the custom term", issues)
+	}
+}
@@ -2,9 +2,11 @@
 package decompose
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -20,35 +22,166 @@ type decomposedTask struct {
 	Description        string   `json:"description"`
 	TaskType           string   `json:"task_type"`
 	FileBoundaries     []string `json:"file_boundaries"`
+	ResourceLocks      []string `json:"resource_locks"`
 	DependsOn          []string `json:"depends_on"`
 	AcceptanceCriteria string   `json:"acceptance_criteria"`
 	VerificationIntent string   `json:"verification_intent"`
 }
 
+// DefaultMaxRepairs is how many times Decompose asks Claude to repair
+// output that failed validation before giving up.
+const DefaultMaxRepairs = 2
+
+// DecompositionError reports decomposer output that still failed
+// validation after every repair attempt.
+type DecompositionError struct {
+	// Attempts is the number of decompositions requested, including repairs.
+	Attempts int
+	// Problems lists what was wrong with the last output.
+	Problems []string
+	// RawOutput is Claude's last output, as returned.
+	RawOutput string
+}
+
+// Error implements the error interface.
+func (e *DecompositionError) Error() string {
+	return fmt.Sprintf("invalid decomposition after %d attempt(s): %s", e.Attempts, strings.Join(e.Problems, "; "))
+}
+
 // Decomposer breaks down user requests into parallelizable subtasks.
 type Decomposer struct {
-	claude agent.ClaudeRunner
+	claude         agent.ClaudeRunner
+	factory        agent.ClaudeRunnerFactory
+	maxRepairs     int
+	linter         *CriteriaLinter
+	maxRefinements int
+}
+
+// Option is a functional option for configuring a Decomposer.
+type Option func(*Decomposer)
+
+// WithRunnerFactory sets the factory repair attempts get their Claude
+// runners from. Without one, invalid output is not repaired.
+func WithRunnerFactory(factory agent.ClaudeRunnerFactory) Option {
+	return func(d *Decomposer) {
+		d.factory = factory
+	}
+}
+
+// WithMaxRepairs sets how many repair attempts are made for invalid output.
+func WithMaxRepairs(n int) Option {
+	return func(d *Decomposer) {
+		d.maxRepairs = n
+	}
+}
+
+// WithCriteriaLinter sets the linter checking the acceptance criteria of
+// decomposed tasks. Nil disables the check.
+func WithCriteriaLinter(l *CriteriaLinter) Option {
+	return func(d *Decomposer) {
+		d.linter = l
+	}
+}
+
+// WithMaxRefinements sets how many times weak acceptance criteria are sent
+// back to Claude for refinement.
+func WithMaxRefinements(n int) Option {
+	return func(d *Decomposer) {
+		d.maxRefinements = n
+	}
 }
 
 // New creates a new Decomposer with the given Claude runner.
-func New(claude agent.ClaudeRunner) *Decomposer {
-	return &Decomposer{claude: claude}
+func New(claude agent.ClaudeRunner, opts ...Option) *Decomposer {
+	d := &Decomposer{
+		claude:         claude,
+		maxRepairs:     DefaultMaxRepairs,
+		linter:         NewCriteriaLinter(),
+		maxRefinements: DefaultMaxRefinements,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // Decompose takes a user request and returns a list of tasks with dependencies.
+// Output that fails ValidateResponse is sent back to Claude with the
+// problems found until it is valid or the repair attempts run out, in which
+// case a *DecompositionError is returned. Valid output whose acceptance
+// criteria the linter flags is sent back for refinement; if the refined
+// output is invalid, the earlier decomposition is kept.
 func (d *Decomposer) Decompose(ctx context.Context, request string) ([]*models.Task, error) {
 	prompt := fmt.Sprintf(decompositionPrompt, request)
+	claude := d.claude
+	repairs, refinements := 0, 0
+	var refined []*models.Task
+
+	for attempt := 1; ; attempt++ {
+		response, err := d.run(ctx, claude, prompt)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := d.claude.Start(prompt, ""); err != nil {
-		return nil, fmt.Errorf("start claude process: %w", err)
+		tasks, problems := ValidateResponse(response)
+		if len(problems) > 0 && refined != nil {
+			log.Printf("[decompose] refined decomposition is invalid (%d problem(s)), keeping the previous one", len(problems))
+			return CoalesceSetupTasks(refined), nil
+		}
+		if len(problems) == 0 {
+			issues := d.CriteriaIssues(tasks)
+			if len(issues) == 0 || d.factory == nil || refinements >= d.maxRefinements {
+				// Coalesce SETUP tasks that share critical files to prevent merge conflicts
+				return CoalesceSetupTasks(tasks), nil
+			}
+			refinements++
+			refined = tasks
+			log.Printf("[decompose] attempt %d has %d weak acceptance criteria, requesting a refinement", attempt, len(issues))
+			claude = d.factory.NewRunner()
+			prompt = fmt.Sprintf(refinePrompt, fmt.Sprintf(decompositionPrompt, request), response, formatCriteriaIssues(issues))
+			continue
+		}
+
+		if d.factory == nil || repairs >= d.maxRepairs {
+			return nil, &DecompositionError{Attempts: attempt, Problems: problems, RawOutput: response}
+		}
+		repairs++
+		log.Printf("[decompose] attempt %d returned an invalid decomposition (%d problem(s)), requesting a repair", attempt, len(problems))
+		claude = d.factory.NewRunner()
+		prompt = fmt.Sprintf(repairPrompt, fmt.Sprintf(decompositionPrompt, request), response, "- "+strings.Join(problems, "\n- "))
+	}
+}
+
+// CriteriaIssues returns the weak acceptance criteria of tasks, or nil if
+// the decomposer does not lint criteria.
+func (d *Decomposer) CriteriaIssues(tasks []*models.Task) []CriteriaIssue {
+	if d.linter == nil {
+		return nil
+	}
+	return d.linter.Lint(tasks)
+}
+
+// formatCriteriaIssues lists issues for a refinement prompt.
+func formatCriteriaIssues(issues []CriteriaIssue) string {
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = "- " + issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// run sends a prompt to Claude and returns its response.
+func (d *Decomposer) run(ctx context.Context, claude agent.ClaudeRunner, prompt string) (string, error) {
+	if err := claude.Start(prompt, ""); err != nil {
+		return "", fmt.Errorf("start claude process: %w", err)
 	}
 
 	var response strings.Builder
-	for event := range d.claude.Output() {
+	for event := range claude.Output() {
 		select {
 		case <-ctx.Done():
-			_ = d.claude.Kill()
-			return nil, ctx.Err()
+			_ = claude.Kill()
+			return "", ctx.Err()
 		default:
 		}
 
@@ -58,27 +191,14 @@ func (d *Decomposer) Decompose(ctx context.Context, request string) ([]*models.T
 		case agent.StreamEventAssistant:
 			response.WriteString(event.Message)
 		case agent.StreamEventError:
-			return nil, fmt.Errorf("claude error: %s", event.Error)
+			return "", fmt.Errorf("claude error: %s", event.Error)
 		}
 	}
 
-	if err := d.claude.Wait(); err != nil {
-		return nil, fmt.Errorf("wait for claude: %w", err)
-	}
-
-	tasks, err := ParseResponse(response.String())
-	if err != nil {
-		return nil, fmt.Errorf("parse decomposition response: %w", err)
+	if err := claude.Wait(); err != nil {
+		return "", fmt.Errorf("wait for claude: %w", err)
 	}
-
-	if err := ValidateNoCycles(tasks); err != nil {
-		return nil, fmt.Errorf("validate dependencies: %w", err)
-	}
-
-	// Coalesce SETUP tasks that share critical files to prevent merge conflicts
-	tasks = CoalesceSetupTasks(tasks)
-
-	return tasks, nil
+	return response.String(), nil
 }
 
 // DecomposeWithReview performs decomposition with quality scoring and optional user review.
@@ -131,14 +251,22 @@ func (d *Decomposer) DecomposeWithReview(ctx context.Context, request string, re
 	return tasks, &quality, nil
 }
 
-// ParseResponse parses Claude's JSON response into Task objects.
-func ParseResponse(response string) ([]*models.Task, error) {
+// extractJSONArray returns the JSON array embedded in a response.
+func extractJSONArray(response string) (string, error) {
 	jsonStart := strings.Index(response, "[")
 	jsonEnd := strings.LastIndex(response, "]")
 	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		return nil, fmt.Errorf("no valid JSON array found in response")
+		return "", fmt.Errorf("no valid JSON array found in response")
+	}
+	return response[jsonStart : jsonEnd+1], nil
+}
+
+// ParseResponse parses Claude's JSON response into Task objects.
+func ParseResponse(response string) ([]*models.Task, error) {
+	jsonStr, err := extractJSONArray(response)
+	if err != nil {
+		return nil, err
 	}
-	jsonStr := response[jsonStart : jsonEnd+1]
 
 	var decomposed []decomposedTask
 	if err := json.Unmarshal([]byte(jsonStr), &decomposed); err != nil {
@@ -148,7 +276,92 @@ func ParseResponse(response string) ([]*models.Task, error) {
 	if len(decomposed) == 0 {
 		return nil, fmt.Errorf("empty task list returned")
 	}
+	return toTasks(decomposed)
+}
+
+// ValidateResponse strictly validates Claude's response against the
+// decomposition schema: no unknown fields, unique non-empty titles, known
+// task types, non-empty acceptance criteria and acyclic dependencies on
+// tasks in the list. Returns the tasks, or every problem found.
+func ValidateResponse(response string) ([]*models.Task, []string) {
+	jsonStr, err := extractJSONArray(response)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	var decomposed []decomposedTask
+	dec := json.NewDecoder(bytes.NewReader([]byte(jsonStr)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&decomposed); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if len(decomposed) == 0 {
+		return nil, []string{"the task list is empty"}
+	}
+
+	var problems []string
+	titles := make(map[string]bool, len(decomposed))
+	for i, dt := range decomposed {
+		title := strings.TrimSpace(dt.Title)
+		switch {
+		case title == "":
+			problems = append(problems, fmt.Sprintf("task %d: title is required", i+1))
+		case titles[title]:
+			problems = append(problems, fmt.Sprintf("task %q: title is not unique", title))
+		}
+		titles[title] = true
+	}
+	for i, dt := range decomposed {
+		name := dt.Title
+		if strings.TrimSpace(name) == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if strings.TrimSpace(dt.AcceptanceCriteria) == "" {
+			problems = append(problems, fmt.Sprintf("task %q: acceptance_criteria is required", name))
+		}
+		if dt.TaskType != "" && !knownTaskType(dt.TaskType) {
+			problems = append(problems, fmt.Sprintf("task %q: unknown task_type %q (use SETUP, FEATURE, BUGFIX or REFACTOR)", name, dt.TaskType))
+		}
+		for _, dep := range dt.DependsOn {
+			switch {
+			case dep == dt.Title:
+				problems = append(problems, fmt.Sprintf("task %q: depends on itself", name))
+			case !titles[strings.TrimSpace(dep)]:
+				problems = append(problems, fmt.Sprintf("task %q: depends_on %q is not the title of a task in the list", name, dep))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	// Check for cycles by title so the problem is readable in a repair prompt
+	byTitle := make([]*models.Task, len(decomposed))
+	for i, dt := range decomposed {
+		byTitle[i] = &models.Task{ID: dt.Title, DependsOn: dt.DependsOn}
+	}
+	if err := ValidateNoCycles(byTitle); err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	tasks, err := toTasks(decomposed)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	return tasks, nil
+}
+
+// knownTaskType reports whether a task_type is one of the schema's values.
+func knownTaskType(taskType string) bool {
+	switch strings.ToUpper(taskType) {
+	case "SETUP", "FEATURE", "BUGFIX", "REFACTOR":
+		return true
+	}
+	return false
+}
 
+// toTasks converts decomposed tasks into Tasks, resolving dependencies by title.
+func toTasks(decomposed []decomposedTask) ([]*models.Task, error) {
 	titleToID := make(map[string]string)
 	tasks := make([]*models.Task, len(decomposed))
 	now := time.Now()
@@ -182,6 +395,7 @@ func ParseResponse(response string) ([]*models.Task, error) {
 			Description:        dt.Description,
 			TaskType:           taskType,
 			FileBoundaries:     dt.FileBoundaries,
+			ResourceLocks:      dt.ResourceLocks,
 			AcceptanceCriteria: dt.AcceptanceCriteria,
 			VerificationIntent: verificationIntent,
 			Status:             models.TaskStatusPending,
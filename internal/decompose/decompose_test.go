@@ -1,9 +1,12 @@
 package decompose
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -353,3 +356,165 @@ func TestDecompositionPrompt(t *testing.T) {
 		t.Error("Prompt should mention acceptance_criteria field")
 	}
 }
+
+// scriptedRunner is an agent.ClaudeRunner that replies with a fixed message
+// and records the prompt it was given.
+type scriptedRunner struct {
+	reply    string
+	prompt   string
+	outputCh chan agent.StreamEvent
+}
+
+func (r *scriptedRunner) Start(prompt, workDir string) error {
+	return r.StartWithOptions(prompt, workDir, nil)
+}
+func (r *scriptedRunner) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	r.prompt = prompt
+	r.outputCh = make(chan agent.StreamEvent, 1)
+	r.outputCh <- agent.StreamEvent{Type: agent.StreamEventResult, Message: r.reply}
+	close(r.outputCh)
+	return nil
+}
+func (r *scriptedRunner) Output() <-chan agent.StreamEvent { return r.outputCh }
+func (r *scriptedRunner) Wait() error                      { return nil }
+func (r *scriptedRunner) Kill() error                      { return nil }
+func (r *scriptedRunner) Stderr() string                   { return "" }
+func (r *scriptedRunner) PID() int                         { return 0 }
+
+// scriptedFactory hands out runners replying with each reply in turn.
+type scriptedFactory struct {
+	replies []string
+	runners []*scriptedRunner
+}
+
+func (f *scriptedFactory) NewRunner() agent.ClaudeRunner {
+	r := &scriptedRunner{reply: f.replies[len(f.runners)]}
+	f.runners = append(f.runners, r)
+	return r
+}
+
+const validDecomposition = `[
+	{"title": "Schema", "task_type": "SETUP", "depends_on": [], "acceptance_criteria": "Migration applies"},
+	{"title": "API", "task_type": "FEATURE", "depends_on": ["Schema"], "acceptance_criteria": "GET /users returns 200"}
+]`
+
+func TestValidateResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+	}{
+		{"valid", validDecomposition, nil},
+		{"not JSON", "I could not decompose this", []string{"no valid JSON array"}},
+		{"unknown field", `[{"title": "A", "acceptance_criteria": "ok", "priority": 1}]`, []string{"unknown field"}},
+		{"empty", `[]`, []string{"the task list is empty"}},
+		{"missing fields", `[{"title": "", "acceptance_criteria": "ok"}, {"title": "B", "acceptance_criteria": " "}]`,
+			[]string{"task 1: title is required", `task "B": acceptance_criteria is required`}},
+		{"duplicate title", `[{"title": "A", "acceptance_criteria": "ok"}, {"title": "A", "acceptance_criteria": "ok"}]`,
+			[]string{`task "A": title is not unique`}},
+		{"unknown type", `[{"title": "A", "task_type": "CHORE", "acceptance_criteria": "ok"}]`,
+			[]string{`unknown task_type "CHORE"`}},
+		{"bad dependencies", `[{"title": "A", "depends_on": ["A", "Z"], "acceptance_criteria": "ok"}]`,
+			[]string{`task "A": depends on itself`, `depends_on "Z" is not the title`}},
+		{"cycle", `[{"title": "A", "depends_on": ["B"], "acceptance_criteria": "ok"}, {"title": "B", "depends_on": ["A"], "acceptance_criteria": "ok"}]`,
+			[]string{"circular dependency detected: A -> B -> A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, problems := ValidateResponse(tt.response)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems = %q, want %d matching %q", problems, len(tt.want), tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, problems[i], want)
+				}
+			}
+			if len(tt.want) == 0 && len(tasks) != 2 {
+				t.Errorf("tasks = %v, want 2", tasks)
+			}
+		})
+	}
+}
+
+func TestDecompose_RepairsInvalidOutput(t *testing.T) {
+	first := &scriptedRunner{reply: `[{"title": "Schema", "depends_on": ["Missing"], "acceptance_criteria": ""}]`}
+	factory := &scriptedFactory{replies: []string{validDecomposition}}
+
+	tasks, err := New(first, WithRunnerFactory(factory)).Decompose(context.Background(), "Add a users API")
+	if err != nil {
+		t.Fatalf("Decompose: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("tasks = %v, want the repaired decomposition", tasks)
+	}
+	if len(factory.runners) != 1 {
+		t.Fatalf("repair attempts = %d, want 1", len(factory.runners))
+	}
+	repair := factory.runners[0].prompt
+	for _, want := range []string{"Add a users API", first.reply, `acceptance_criteria is required`, `depends_on "Missing"`} {
+		if !strings.Contains(repair, want) {
+			t.Errorf("repair prompt missing %q", want)
+		}
+	}
+}
+
+func TestDecompose_GivesUpWithDecompositionError(t *testing.T) {
+	invalid := `[{"title": "A", "acceptance_criteria": ""}]`
+	factory := &scriptedFactory{replies: []string{invalid, invalid}}
+
+	_, err := New(&scriptedRunner{reply: invalid}, WithRunnerFactory(factory), WithMaxRepairs(2)).Decompose(context.Background(), "req")
+	var decompErr *DecompositionError
+	if !errors.As(err, &decompErr) {
+		t.Fatalf("err = %v, want a DecompositionError", err)
+	}
+	if decompErr.Attempts != 3 || decompErr.RawOutput != invalid || len(decompErr.Problems) != 1 {
+		t.Errorf("DecompositionError = %+v", decompErr)
+	}
+
+	// Without a runner factory there is nothing to repair with
+	_, err = New(&scriptedRunner{reply: invalid}).Decompose(context.Background(), "req")
+	if !errors.As(err, &decompErr) || decompErr.Attempts != 1 {
+		t.Errorf("err = %v, want a DecompositionError after one attempt", err)
+	}
+}
+
+func TestDecompose_RefinesWeakCriteria(t *testing.T) {
+	weak := `[{"title": "API", "task_type": "FEATURE", "depends_on": [], "acceptance_criteria": "The API works properly"}]`
+	factory := &scriptedFactory{replies: []string{validDecomposition}}
+
+	tasks, err := New(&scriptedRunner{reply: weak}, WithRunnerFactory(factory)).Decompose(context.Background(), "Add a users API")
+	if err != nil {
+		t.Fatalf("Decompose: %v", err)
+	}
+	if len(tasks) != 2 || len(factory.runners) != 1 {
+		t.Fatalf("tasks = %d after %d refinement(s), want the refined decomposition after 1", len(tasks), len(factory.runners))
+	}
+	refine := factory.runners[0].prompt
+	for _, want := range []string{"Add a users API", weak, `criterion "The API works properly" is ambiguous`} {
+		if !strings.Contains(refine, want) {
+			t.Errorf("refinement prompt missing %q", want)
+		}
+	}
+
+	// Weak criteria are kept once the refinements run out
+	factory = &scriptedFactory{replies: []string{weak}}
+	tasks, err = New(&scriptedRunner{reply: weak}, WithRunnerFactory(factory)).Decompose(context.Background(), "req")
+	if err != nil || len(tasks) != 1 || len(factory.runners) != 1 {
+		t.Errorf("Decompose() = %d tasks, %v after %d refinement(s); want the weak task after 1", len(tasks), err, len(factory.runners))
+	}
+
+	// An invalid refinement keeps the earlier decomposition
+	factory = &scriptedFactory{replies: []string{"no JSON"}}
+	tasks, err = New(&scriptedRunner{reply: weak}, WithRunnerFactory(factory)).Decompose(context.Background(), "req")
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "API" {
+		t.Errorf("Decompose() = %v, %v; want the unrefined task", tasks, err)
+	}
+
+	// Without a linter nothing is refined
+	factory = &scriptedFactory{}
+	if _, err := New(&scriptedRunner{reply: weak}, WithRunnerFactory(factory), WithCriteriaLinter(nil)).Decompose(context.Background(), "req"); err != nil || len(factory.runners) != 0 {
+		t.Errorf("Decompose() without a linter = %v after %d refinement(s), want none", err, len(factory.runners))
+	}
+}
@@ -13,6 +13,7 @@ Return ONLY a JSON array of tasks with this exact structure (no other text):
     "description": "Detailed task description",
     "task_type": "SETUP|FEATURE|BUGFIX|REFACTOR",
     "file_boundaries": ["src/auth/", "server/routes/api.ts"],
+    "resource_locks": ["db:schema"],
     "depends_on": ["title of dependency 1", "title of dependency 2"],
     "acceptance_criteria": "Criteria to verify this task is complete",
     "verification_intent": "Concrete verification: what commands/tests prove this works"
@@ -27,6 +28,13 @@ CRITICAL: File Boundary Rules
 - Include config files that will be touched: package.json, tsconfig.json, etc.
 - If a task touches a shared config file, it should likely be the ONLY task or run first
 
+Resource Lock Rules:
+- resource_locks names shared runtime resources the task needs exclusively
+- Use "db:<name>" for database schemas/migrations, "port:<number>" for fixed dev ports,
+  "config:<name>" for global config outside the repo
+- Two tasks with the same resource lock will be SERIALIZED even if their files differ
+- Omit or leave empty when the task touches no shared resource
+
 Task Type Classification:
 - SETUP: Project scaffolding, configuration, initialization (prefer FEWER tasks)
 - FEATURE: New functionality implementation (can be parallelized if boundaries don't overlap)
@@ -54,3 +62,35 @@ Guidelines:
 - Use empty array [] for depends_on if there are no dependencies
 - For SETUP work: prefer 1-2 large tasks over many small ones (reduces merge conflicts)
 - NEVER create two tasks that both modify the same config file (package.json, tsconfig.json, etc.)`
+
+// repairPrompt asks for a decomposition that failed validation to be fixed.
+// It is filled with the decomposition prompt, the invalid output and the
+// problems found.
+const repairPrompt = `%s
+
+A previous attempt returned the output below, which failed validation.
+
+Previous output:
+%s
+
+Problems:
+%s
+
+Return ONLY a corrected JSON array that fixes every problem, using exactly the fields shown above.`
+
+// refinePrompt asks for the weak acceptance criteria of a valid
+// decomposition to be made testable. It is filled with the decomposition
+// prompt, the decomposition and the weak criteria found.
+const refinePrompt = `%s
+
+A previous attempt returned the decomposition below. Some of its acceptance criteria cannot be checked.
+
+Previous output:
+%s
+
+Weak acceptance criteria:
+%s
+
+Rewrite these criteria as measurable outcomes: the command or test to run, the output, status code, return value or file expected, and how errors are handled. Avoid words such as "properly", "correctly" or "as needed". Keep every task, title, dependency and file boundary unchanged unless a criterion cannot be made testable without it.
+
+Return ONLY the complete JSON array, using exactly the fields shown above.`
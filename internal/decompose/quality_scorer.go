@@ -183,6 +183,13 @@ func scoreTask(task *models.Task, allTasks []*models.Task) TaskQualityScore {
 			Message:    "No acceptance criteria specified",
 			Suggestion: "Add acceptance criteria to validate task completion",
 		})
+	} else if weak := NewCriteriaLinter().LintCriteria(task.Title, task.AcceptanceCriteria); len(weak) > 0 {
+		score.Confidence -= 0.1
+		score.Issues = append(score.Issues, QualityIssue{
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%d acceptance criteria are not testable: %s", len(weak), weak[0].Criterion),
+			Suggestion: "State measurable outcomes: commands, outputs, status codes or files",
+		})
 	}
 
 	// Check for missing verification intent
@@ -0,0 +1,193 @@
+package exec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WorkDirReleaser is implemented by runners that hold resources for a work
+// directory between commands, such as a task's container.
+type WorkDirReleaser interface {
+	// ReleaseWorkDir frees what the runner holds for workDir and the
+	// directories under it.
+	ReleaseWorkDir(workDir string) error
+}
+
+// taskContainers tracks the long-lived containers of the container
+// backend: one per work directory, all from one image built on first use.
+type taskContainers struct {
+	mu    sync.Mutex
+	image string
+	// byDir maps a work directory to its container's name
+	byDir map[string]string
+}
+
+// containerArgs returns the docker command running name in workDir's
+// container, building the image and starting the container if needed.
+func (r *SandboxRunner) containerArgs(ctx context.Context, workDir, name string, args []string) ([]string, error) {
+	container, err := r.taskContainer(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	argv := []string{"docker", "exec", "-i", "-w", workDir, container, name}
+	return append(argv, args...), nil
+}
+
+// taskContainer returns the container for workDir, starting one the first
+// time a command runs there. The container mounts workDir at the same path
+// and keeps running, so a task's build and test commands share its caches
+// without sharing them with other tasks or the host.
+func (r *SandboxRunner) taskContainer(ctx context.Context, workDir string) (string, error) {
+	c := r.containers
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name, ok := c.byDir[workDir]; ok {
+		return name, nil
+	}
+
+	image, err := r.containerImage(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := containerName(workDir)
+	// A container left by a crashed run has the same name
+	_, _ = r.exec.Run(ctx, "", "docker", "rm", "-f", name)
+	if out, err := r.exec.Run(ctx, "", "docker", r.containerRunArgs(name, workDir, image)...); err != nil {
+		return "", fmt.Errorf("sandbox: start container: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	c.byDir[workDir] = name
+	return name, nil
+}
+
+// containerImage returns the image task containers run: the configured
+// image, or one built from the project's devcontainer or Dockerfile the
+// first time it is needed. Called with the containers lock held.
+func (r *SandboxRunner) containerImage(ctx context.Context) (string, error) {
+	c := r.containers
+	if c.image != "" {
+		return c.image, nil
+	}
+	if r.cfg.Image != "" {
+		c.image = r.cfg.Image
+		return c.image, nil
+	}
+
+	src, err := ResolveContainerSource(r.cfg.ProjectDir, r.cfg.Dockerfile)
+	if err != nil {
+		return "", err
+	}
+	if src.Image != "" {
+		c.image = src.Image
+		return c.image, nil
+	}
+	tag := "alphie-sandbox-" + shortHash(src.Dockerfile)
+	if out, err := r.exec.Run(ctx, src.Context, "docker", "build", "-q", "-t", tag, "-f", src.Dockerfile, src.Context); err != nil {
+		return "", fmt.Errorf("sandbox: build %s: %s: %w", src.Dockerfile, strings.TrimSpace(string(out)), err)
+	}
+	c.image = tag
+	return c.image, nil
+}
+
+// containerRunArgs builds the docker run arguments starting a detached
+// task container. Commands run as the host user so files they write in the
+// worktree stay removable, with HOME in the container's own filesystem.
+func (r *SandboxRunner) containerRunArgs(name, workDir, image string) []string {
+	argv := []string{"run", "-d", "--init", "--name", name, "--label", "alphie.sandbox=task"}
+	if !r.cfg.Network {
+		argv = append(argv, "--network", "none")
+	}
+	if r.cfg.CPUs > 0 {
+		argv = append(argv, "--cpus", strconv.FormatFloat(r.cfg.CPUs, 'f', -1, 64))
+	}
+	if r.cfg.MemoryMB > 0 {
+		argv = append(argv, "--memory", fmt.Sprintf("%dm", r.cfg.MemoryMB))
+	}
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 {
+		argv = append(argv, "--user", fmt.Sprintf("%d:%d", uid, gid), "-e", "HOME=/tmp")
+	}
+	return append(argv, "-v", workDir+":"+workDir, "-w", workDir,
+		"--entrypoint", "sleep", image, "infinity")
+}
+
+// ReleaseWorkDir removes the containers of workDir and the directories
+// under it. Other backends hold nothing between commands.
+func (r *SandboxRunner) ReleaseWorkDir(workDir string) error {
+	if r.containers == nil {
+		return nil
+	}
+	absDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return fmt.Errorf("sandbox: resolve %s: %w", workDir, err)
+	}
+	c := r.containers
+	c.mu.Lock()
+	var names []string
+	for dir, name := range c.byDir {
+		if dir == absDir || strings.HasPrefix(dir, absDir+string(filepath.Separator)) {
+			names = append(names, name)
+			delete(c.byDir, dir)
+		}
+	}
+	c.mu.Unlock()
+	return r.removeContainers(names)
+}
+
+// Close removes every task container the runner started.
+func (r *SandboxRunner) Close() error {
+	if r.containers == nil {
+		return nil
+	}
+	c := r.containers
+	c.mu.Lock()
+	names := make([]string, 0, len(c.byDir))
+	for dir, name := range c.byDir {
+		names = append(names, name)
+		delete(c.byDir, dir)
+	}
+	c.mu.Unlock()
+	return r.removeContainers(names)
+}
+
+// removeContainers force-removes the named containers.
+func (r *SandboxRunner) removeContainers(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	args := append([]string{"rm", "-f"}, names...)
+	if out, err := r.exec.Run(context.Background(), "", "docker", args...); err != nil {
+		return fmt.Errorf("sandbox: remove containers: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// containerName names the task container of workDir after the directory,
+// with a hash of its path so tasks' containers never collide.
+func containerName(workDir string) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, filepath.Base(workDir))
+	return "alphie-" + strings.TrimLeft(base, "-._") + "-" + shortHash(workDir)
+}
+
+// shortHash returns the first 12 hex digits of s's SHA-256.
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Verify SandboxRunner releases task containers at compile time.
+var _ WorkDirReleaser = (*SandboxRunner)(nil)
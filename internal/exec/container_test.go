@@ -0,0 +1,148 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commandLog records every command it is asked to run.
+type commandLog struct {
+	commands []string
+}
+
+func (l *commandLog) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	l.commands = append(l.commands, name+" "+strings.Join(args, " "))
+	return nil, nil
+}
+
+func (l *commandLog) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return l.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (l *commandLog) Exists(ctx context.Context, workDir string, path string) bool {
+	return true
+}
+
+// count returns how many recorded commands start with prefix.
+func (l *commandLog) count(prefix string) int {
+	n := 0
+	for _, c := range l.commands {
+		if strings.HasPrefix(c, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestResolveContainerSource(t *testing.T) {
+	project := t.TempDir()
+	if _, err := ResolveContainerSource(project, ""); err == nil || !strings.Contains(err.Error(), "no devcontainer.json or Dockerfile") {
+		t.Errorf("expected a project without a Dockerfile to fail, got %v", err)
+	}
+
+	writeTestFile(t, project, "Dockerfile", "FROM golang:1.24\n")
+	src, err := ResolveContainerSource(project, "")
+	if err != nil || src.Dockerfile != filepath.Join(project, "Dockerfile") || src.Context != project {
+		t.Errorf("ResolveContainerSource(Dockerfile) = %+v, %v", src, err)
+	}
+
+	// A devcontainer takes precedence; its paths are relative to it
+	writeTestFile(t, project, ".devcontainer/devcontainer.json", `{
+	// Go toolchain for agents
+	"name": "app",
+	"build": {
+		"dockerfile": "Dockerfile.dev", /* built from the repo */
+		"context": "..",
+	},
+}`)
+	src, err = ResolveContainerSource(project, "")
+	if err != nil {
+		t.Fatalf("ResolveContainerSource(devcontainer) error = %v", err)
+	}
+	if src.Dockerfile != filepath.Join(project, ".devcontainer", "Dockerfile.dev") || src.Context != project {
+		t.Errorf("ResolveContainerSource(devcontainer) = %+v", src)
+	}
+
+	writeTestFile(t, project, ".devcontainer.json", `{"image": "mcr.microsoft.com/devcontainers/go:1", "runArgs": ["--cap-add=SYS_PTRACE"]}`)
+	src, err = ResolveContainerSource(project, ".devcontainer.json")
+	if err != nil || src.Image != "mcr.microsoft.com/devcontainers/go:1" {
+		t.Errorf("ResolveContainerSource(image devcontainer) = %+v, %v", src, err)
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	in := `{"url": "http://x//y", /* c */ "list": [1, 2,], // trailing
+"s": "a,}"}`
+	want := `{"url": "http://x//y",  "list": [1, 2], 
+"s": "a,}"}`
+	if got := string(stripJSONC([]byte(in))); got != want {
+		t.Errorf("stripJSONC() = %q, want %q", got, want)
+	}
+}
+
+func TestSandboxRunner_ContainerPerTask(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, project, "Dockerfile", "FROM golang:1.24\n")
+
+	log := &commandLog{}
+	r, err := NewSandboxRunnerWithExec(SandboxConfig{Backend: SandboxContainer, ProjectDir: project, MemoryMB: 1024}, log)
+	if err != nil {
+		t.Fatalf("NewSandboxRunnerWithExec: %v", err)
+	}
+
+	ctx := context.Background()
+	taskA, taskB := filepath.Join(project, "wt-a"), filepath.Join(project, "wt-b")
+	for _, run := range []struct{ dir, command string }{
+		{taskA, "go build ./..."}, {taskA, "go test ./..."}, {taskB, "go test ./..."},
+	} {
+		if _, err := r.RunShell(ctx, run.dir, run.command); err != nil {
+			t.Fatalf("RunShell(%s): %v", run.dir, err)
+		}
+	}
+
+	if n := log.count("docker build"); n != 1 {
+		t.Errorf("expected the image to be built once, built %d times: %v", n, log.commands)
+	}
+	if n := log.count("docker run -d"); n != 2 {
+		t.Errorf("expected one container per task, started %d: %v", n, log.commands)
+	}
+	nameA := containerName(taskA)
+	wantExec := "docker exec -i -w " + taskA + " " + nameA + " sh -c go test ./..."
+	if log.count(wantExec) != 1 {
+		t.Errorf("expected %q in %v", wantExec, log.commands)
+	}
+	for _, c := range log.commands {
+		if strings.HasPrefix(c, "docker run -d") && strings.Contains(c, nameA) {
+			if !strings.Contains(c, "-v "+taskA+":"+taskA) || !strings.Contains(c, "--network none") || !strings.Contains(c, "--memory 1024m") {
+				t.Errorf("unexpected container start %q", c)
+			}
+		}
+	}
+
+	if err := r.ReleaseWorkDir(taskA); err != nil {
+		t.Fatalf("ReleaseWorkDir: %v", err)
+	}
+	if log.count("docker rm -f "+nameA) != 2 {
+		t.Errorf("expected the task's container to be removed, got %v", log.commands)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if last := log.commands[len(log.commands)-1]; last != "docker rm -f "+containerName(taskB) {
+		t.Errorf("expected Close to remove the remaining container, got %q", last)
+	}
+}
+
+func writeTestFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ContainerSource is where a task container's image comes from: a
+// prebuilt Image, or a Dockerfile built with Context as the build context.
+type ContainerSource struct {
+	Image      string
+	Dockerfile string
+	Context    string
+}
+
+// containerSourceFiles are checked in order when no Dockerfile is
+// configured.
+var containerSourceFiles = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+	filepath.Join(".devcontainer", "Dockerfile"),
+	"Dockerfile",
+}
+
+// ResolveContainerSource finds the image source for projectDir. file is a
+// Dockerfile or devcontainer.json relative to projectDir; empty uses the
+// first of .devcontainer/devcontainer.json, .devcontainer.json,
+// .devcontainer/Dockerfile and Dockerfile that exists.
+func ResolveContainerSource(projectDir, file string) (ContainerSource, error) {
+	if file == "" {
+		for _, candidate := range containerSourceFiles {
+			if _, err := os.Stat(filepath.Join(projectDir, candidate)); err == nil {
+				file = candidate
+				break
+			}
+		}
+		if file == "" {
+			return ContainerSource{}, fmt.Errorf("sandbox: no devcontainer.json or Dockerfile in %s; set dockerfile or image", projectDir)
+		}
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ContainerSource{}, fmt.Errorf("sandbox: %w", err)
+	}
+	if strings.HasSuffix(path, ".json") {
+		return parseDevcontainer(path)
+	}
+	// A Dockerfile under .devcontainer is built from that directory, one at
+	// the project root from the project
+	buildContext := projectDir
+	if filepath.Base(filepath.Dir(path)) == ".devcontainer" {
+		buildContext = filepath.Dir(path)
+	}
+	return ContainerSource{Dockerfile: path, Context: buildContext}, nil
+}
+
+// devcontainer holds the fields of devcontainer.json that select an image.
+type devcontainer struct {
+	Image string `json:"image"`
+	Build struct {
+		Dockerfile string `json:"dockerfile"`
+		Context    string `json:"context"`
+	} `json:"build"`
+	// DockerFile and Context are the older top-level spellings
+	DockerFile        string `json:"dockerFile"`
+	Context           string `json:"context"`
+	DockerComposeFile any    `json:"dockerComposeFile"`
+}
+
+// parseDevcontainer reads the image source from a devcontainer.json. Paths
+// in it are relative to the file's directory.
+func parseDevcontainer(path string) (ContainerSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContainerSource{}, fmt.Errorf("sandbox: %w", err)
+	}
+	var dc devcontainer
+	if err := json.Unmarshal(stripJSONC(data), &dc); err != nil {
+		return ContainerSource{}, fmt.Errorf("sandbox: parse %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	dockerfile, buildContext := dc.Build.Dockerfile, dc.Build.Context
+	if dockerfile == "" {
+		dockerfile, buildContext = dc.DockerFile, dc.Context
+	}
+	switch {
+	case dockerfile != "":
+		if buildContext == "" {
+			buildContext = "."
+		}
+		return ContainerSource{
+			Dockerfile: filepath.Join(dir, filepath.FromSlash(dockerfile)),
+			Context:    filepath.Join(dir, filepath.FromSlash(buildContext)),
+		}, nil
+	case dc.Image != "":
+		return ContainerSource{Image: dc.Image}, nil
+	case dc.DockerComposeFile != nil:
+		return ContainerSource{}, fmt.Errorf("sandbox: %s: docker compose devcontainers are not supported; set dockerfile or image", path)
+	default:
+		return ContainerSource{}, fmt.Errorf("sandbox: %s has no image or build.dockerfile", path)
+	}
+}
+
+// stripJSONC turns devcontainer.json's JSON with comments into JSON by
+// removing comments and trailing commas outside strings.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
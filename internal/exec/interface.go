@@ -12,7 +12,8 @@ type CommandRunner interface {
 	// The working directory is set to workDir if non-empty.
 	Run(ctx context.Context, workDir string, name string, args ...string) (output []byte, err error)
 
-	// RunShell executes a shell command through "sh -c".
+	// RunShell executes a shell command through "sh -c" (on Windows, see
+	// ShellCommand).
 	// This is a convenience method for running complex shell commands.
 	RunShell(ctx context.Context, workDir string, command string) (output []byte, err error)
 
@@ -0,0 +1,18 @@
+package exec
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcessAlive(t *testing.T) {
+	if ProcessAlive(0) || ProcessAlive(-1) {
+		t.Error("invalid PIDs should not be alive")
+	}
+	if !ProcessAlive(os.Getpid()) {
+		t.Error("our own PID should be alive")
+	}
+	if ProcessAlive(999999) {
+		t.Error("non-existent PID should not be alive")
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package exec
+
+import (
+	"os"
+	"syscall"
+)
+
+// ProcessAlive reports whether a process with the given PID is running.
+// Lock files, worktree records and agent records use it to tell a live
+// owner from one left by a crashed run.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for existence without affecting the process
+	return process.Signal(syscall.Signal(0)) == nil
+}
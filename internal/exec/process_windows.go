@@ -0,0 +1,36 @@
+//go:build windows
+
+package exec
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows process access and exit code constants the syscall package
+// does not define.
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// ProcessAlive reports whether a process with the given PID is running.
+// Lock files, worktree records and agent records use it to tell a live
+// owner from one left by a crashed run. Windows has no signal 0, so the
+// process is opened and its exit code checked instead.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// The process exists but belongs to someone we cannot query
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
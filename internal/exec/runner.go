@@ -2,7 +2,9 @@ package exec
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 )
 
 // ExecRunner implements CommandRunner using os/exec.
@@ -22,18 +24,20 @@ func (r *ExecRunner) Run(ctx context.Context, workDir string, name string, args
 	return cmd.CombinedOutput()
 }
 
-// RunShell executes a shell command through "sh -c".
+// RunShell executes a shell command through the platform's shell (see
+// ShellCommand).
 func (r *ExecRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
-	return r.Run(ctx, workDir, "sh", "-c", command)
+	argv := ShellCommand(command)
+	return r.Run(ctx, workDir, argv[0], argv[1:]...)
 }
 
 // Exists checks if a file exists at the given path.
 func (r *ExecRunner) Exists(ctx context.Context, workDir string, path string) bool {
-	cmd := exec.CommandContext(ctx, "test", "-e", path)
-	if workDir != "" {
-		cmd.Dir = workDir
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
 	}
-	return cmd.Run() == nil
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // Verify ExecRunner implements CommandRunner at compile time.
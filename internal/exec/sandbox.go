@@ -0,0 +1,263 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Sandbox backends.
+const (
+	// SandboxDocker runs commands in a throwaway Docker container.
+	SandboxDocker = "docker"
+	// SandboxNsjail runs commands under nsjail on the host.
+	SandboxNsjail = "nsjail"
+	// SandboxContainer runs each task's commands in its own long-lived
+	// Docker container, built from the project's devcontainer or Dockerfile.
+	SandboxContainer = "container"
+)
+
+// ErrNotAllowed is returned when a sandboxed command starts a program that
+// is not on the allowlist.
+var ErrNotAllowed = errors.New("command not allowed in sandbox")
+
+// shellBuiltins may appear in allowlisted shell commands without being
+// listed: they start no program.
+var shellBuiltins = map[string]bool{
+	"cd": true, "echo": true, "exit": true, "export": true,
+	"false": true, "test": true, "true": true, "[": true,
+}
+
+// SandboxConfig configures sandboxed command execution.
+type SandboxConfig struct {
+	// Backend is SandboxDocker, SandboxNsjail or SandboxContainer.
+	Backend string
+	// Image is the Docker image commands run in. Required for Docker; for
+	// containers it overrides building one.
+	Image string
+	// Dockerfile is the Dockerfile or devcontainer.json task containers
+	// are built from, relative to ProjectDir. Empty detects one.
+	Dockerfile string
+	// ProjectDir is the project the container image is built from.
+	ProjectDir string
+	// CPUs limits the CPU cores a command may use. Zero is unlimited.
+	CPUs float64
+	// MemoryMB limits a command's memory in megabytes. Zero is unlimited.
+	MemoryMB int
+	// Network allows network access. Commands are offline by default.
+	Network bool
+	// Allowlist names the programs commands may start, such as "go" or
+	// "npm". Empty allows any program.
+	Allowlist []string
+}
+
+// Validate checks that the backend is known and the limits are sane.
+func (c SandboxConfig) Validate() error {
+	switch c.Backend {
+	case SandboxDocker:
+		if c.Image == "" {
+			return fmt.Errorf("sandbox: docker backend requires an image")
+		}
+	case SandboxNsjail, SandboxContainer:
+	default:
+		return fmt.Errorf("sandbox: unknown backend %q (use %s, %s or %s)", c.Backend, SandboxDocker, SandboxNsjail, SandboxContainer)
+	}
+	if c.CPUs < 0 || c.MemoryMB < 0 {
+		return fmt.Errorf("sandbox: limits must not be negative")
+	}
+	return nil
+}
+
+// SandboxRunner implements CommandRunner by running commands inside a
+// sandbox with resource and network limits. Only allowlisted programs run.
+type SandboxRunner struct {
+	cfg     SandboxConfig
+	allowed map[string]bool
+	exec    CommandRunner
+	// containers is set for the container backend
+	containers *taskContainers
+}
+
+// NewSandboxRunner creates a SandboxRunner that starts the sandbox on the host.
+func NewSandboxRunner(cfg SandboxConfig) (*SandboxRunner, error) {
+	return NewSandboxRunnerWithExec(cfg, NewRunner())
+}
+
+// NewSandboxRunnerWithExec creates a SandboxRunner that starts the sandbox
+// through runner (for testing).
+func NewSandboxRunnerWithExec(cfg SandboxConfig, runner CommandRunner) (*SandboxRunner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(cfg.Allowlist))
+	for _, name := range cfg.Allowlist {
+		allowed[name] = true
+	}
+	r := &SandboxRunner{cfg: cfg, allowed: allowed, exec: runner}
+	if cfg.Backend == SandboxContainer {
+		r.containers = &taskContainers{byDir: make(map[string]string)}
+	}
+	return r, nil
+}
+
+// Run executes a command inside the sandbox and returns combined
+// stdout/stderr output.
+func (r *SandboxRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	if err := r.check(name, args); err != nil {
+		return nil, err
+	}
+	if workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("sandbox: get working directory: %w", err)
+		}
+		workDir = wd
+	}
+	absDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: resolve %s: %w", workDir, err)
+	}
+
+	var argv []string
+	switch r.cfg.Backend {
+	case SandboxDocker:
+		argv = r.dockerArgs(absDir, name, args)
+	case SandboxContainer:
+		if argv, err = r.containerArgs(ctx, absDir, name, args); err != nil {
+			return nil, err
+		}
+	default:
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+		argv = r.nsjailArgs(absDir, path, args)
+	}
+	return r.exec.Run(ctx, workDir, argv[0], argv[1:]...)
+}
+
+// RunShell executes a shell command through "sh -c" inside the sandbox,
+// which is a Linux environment whatever the host platform.
+func (r *SandboxRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return r.Run(ctx, workDir, "sh", "-c", command)
+}
+
+// Exists checks if a file exists at the given path. It runs no project
+// code, so it is not sandboxed.
+func (r *SandboxRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return r.exec.Exists(ctx, workDir, path)
+}
+
+// dockerArgs builds the docker command running name in a container with
+// the work directory mounted at the same path.
+func (r *SandboxRunner) dockerArgs(workDir, name string, args []string) []string {
+	argv := []string{"docker", "run", "--rm", "-i"}
+	if !r.cfg.Network {
+		argv = append(argv, "--network", "none")
+	}
+	if r.cfg.CPUs > 0 {
+		argv = append(argv, "--cpus", strconv.FormatFloat(r.cfg.CPUs, 'f', -1, 64))
+	}
+	if r.cfg.MemoryMB > 0 {
+		argv = append(argv, "--memory", fmt.Sprintf("%dm", r.cfg.MemoryMB))
+	}
+	argv = append(argv, "-v", workDir+":"+workDir, "-w", workDir, r.cfg.Image, name)
+	return append(argv, args...)
+}
+
+// nsjailArgs builds the nsjail command running path with the host
+// filesystem read-only and the work directory writable.
+func (r *SandboxRunner) nsjailArgs(workDir, path string, args []string) []string {
+	argv := []string{"nsjail", "--mode", "o", "--quiet", "--keep_env",
+		"--chroot", "/", "--bindmount", workDir, "--cwd", workDir, "--time_limit", "0"}
+	if r.cfg.Network {
+		argv = append(argv, "--disable_clone_newnet")
+	}
+	if r.cfg.CPUs > 0 {
+		argv = append(argv, "--cgroup_cpu_ms_per_sec", strconv.Itoa(int(r.cfg.CPUs*1000)))
+	}
+	if r.cfg.MemoryMB > 0 {
+		argv = append(argv, "--cgroup_mem_max", strconv.Itoa(r.cfg.MemoryMB*1024*1024))
+	}
+	argv = append(argv, "--", path)
+	return append(argv, args...)
+}
+
+// check verifies that the command only starts allowlisted programs. Shell
+// commands are split on their operators and each part is checked; the
+// sandbox limits still contain anything the parts start in turn.
+func (r *SandboxRunner) check(name string, args []string) error {
+	if len(r.allowed) == 0 {
+		return nil
+	}
+	base := filepath.Base(name)
+	if (base == "sh" || base == "bash") && len(args) == 2 && args[0] == "-c" {
+		for _, program := range shellPrograms(args[1]) {
+			if !r.allowed[program] && !shellBuiltins[program] {
+				return fmt.Errorf("%w: %q", ErrNotAllowed, program)
+			}
+		}
+		return nil
+	}
+	if !r.allowed[base] {
+		return fmt.Errorf("%w: %q", ErrNotAllowed, base)
+	}
+	return nil
+}
+
+// shellPrograms returns the program each part of a shell command starts,
+// skipping leading variable assignments and exec.
+func shellPrograms(command string) []string {
+	// Redirections such as 2>&1 are not command separators
+	command = strings.NewReplacer(">&", ">", "<&", "<", "&>", ">").Replace(command)
+	replacer := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n", "&", "\n", "(", "\n", ")", "\n")
+	var programs []string
+	for _, part := range strings.Split(replacer.Replace(command), "\n") {
+		fields := strings.Fields(part)
+		for len(fields) > 0 && (strings.Contains(fields[0], "=") || fields[0] == "exec") {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			programs = append(programs, filepath.Base(fields[0]))
+		}
+	}
+	return programs
+}
+
+// FailingRunner implements CommandRunner by refusing every command. It
+// stands in for a sandbox that could not be set up, so commands fail
+// instead of running unprotected on the host.
+type FailingRunner struct {
+	err error
+}
+
+// NewFailingRunner creates a FailingRunner whose commands fail with err.
+func NewFailingRunner(err error) *FailingRunner {
+	return &FailingRunner{err: err}
+}
+
+// Run returns the runner's error without running anything.
+func (r *FailingRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	return nil, r.err
+}
+
+// RunShell returns the runner's error without running anything.
+func (r *FailingRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return nil, r.err
+}
+
+// Exists reports false: nothing can be checked.
+func (r *FailingRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+// Verify the runners implement CommandRunner at compile time.
+var (
+	_ CommandRunner = (*SandboxRunner)(nil)
+	_ CommandRunner = (*FailingRunner)(nil)
+)
@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingRunner records the last command it was asked to run.
+type recordingRunner struct {
+	name string
+	args []string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	r.name = name
+	r.args = args
+	return []byte("ok"), nil
+}
+
+func (r *recordingRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return r.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (r *recordingRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return true
+}
+
+func TestSandboxConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SandboxConfig
+		wantErr string
+	}{
+		{"docker", SandboxConfig{Backend: SandboxDocker, Image: "golang:1.24"}, ""},
+		{"nsjail", SandboxConfig{Backend: SandboxNsjail, CPUs: 1, MemoryMB: 512}, ""},
+		{"container", SandboxConfig{Backend: SandboxContainer}, ""},
+		{"docker without image", SandboxConfig{Backend: SandboxDocker}, "requires an image"},
+		{"unknown backend", SandboxConfig{Backend: "chroot"}, "unknown backend"},
+		{"negative limit", SandboxConfig{Backend: SandboxNsjail, MemoryMB: -1}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSandboxRunner_Docker(t *testing.T) {
+	rec := &recordingRunner{}
+	r, err := NewSandboxRunnerWithExec(SandboxConfig{
+		Backend:  SandboxDocker,
+		Image:    "golang:1.24",
+		CPUs:     1.5,
+		MemoryMB: 2048,
+	}, rec)
+	if err != nil {
+		t.Fatalf("NewSandboxRunnerWithExec: %v", err)
+	}
+
+	if _, err := r.Run(context.Background(), "/work/tree", "go", "test", "./..."); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := rec.name + " " + strings.Join(rec.args, " ")
+	want := "docker run --rm -i --network none --cpus 1.5 --memory 2048m -v /work/tree:/work/tree -w /work/tree golang:1.24 go test ./..."
+	if got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestSandboxRunner_NsjailNetwork(t *testing.T) {
+	rec := &recordingRunner{}
+	r, err := NewSandboxRunnerWithExec(SandboxConfig{Backend: SandboxNsjail, Network: true, MemoryMB: 1}, rec)
+	if err != nil {
+		t.Fatalf("NewSandboxRunnerWithExec: %v", err)
+	}
+
+	if _, err := r.RunShell(context.Background(), "/work/tree", "echo hi"); err != nil {
+		t.Fatalf("RunShell: %v", err)
+	}
+	got := strings.Join(rec.args, " ")
+	if rec.name != "nsjail" || !strings.Contains(got, "--bindmount /work/tree") ||
+		!strings.Contains(got, "--disable_clone_newnet") || !strings.Contains(got, "--cgroup_mem_max 1048576") {
+		t.Errorf("unexpected nsjail command %s %s", rec.name, got)
+	}
+	if !strings.HasSuffix(got, "sh -c echo hi") {
+		t.Errorf("expected the resolved shell to run the command, got %q", got)
+	}
+}
+
+func TestSandboxRunner_Allowlist(t *testing.T) {
+	rec := &recordingRunner{}
+	r, err := NewSandboxRunnerWithExec(SandboxConfig{
+		Backend:   SandboxDocker,
+		Image:     "node:22",
+		Allowlist: []string{"npm", "go"},
+	}, rec)
+	if err != nil {
+		t.Fatalf("NewSandboxRunnerWithExec: %v", err)
+	}
+
+	tests := []struct {
+		command string
+		allowed bool
+	}{
+		{"npm test", true},
+		{"cd web && CI=1 npm run build 2>&1 | tee build.log", false},
+		{"cd web && CI=1 npm run build 2>&1", true},
+		{"go test ./... ; curl http://evil.example", false},
+		{"exec /usr/local/go/bin/go vet ./...", true},
+		{"echo $(rm -rf /)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			_, err := r.RunShell(context.Background(), "/w", tt.command)
+			if tt.allowed && err != nil {
+				t.Errorf("expected %q to be allowed, got %v", tt.command, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrNotAllowed) {
+				t.Errorf("expected %q to be refused, got %v", tt.command, err)
+			}
+		})
+	}
+
+	if _, err := r.Run(context.Background(), "/w", "/usr/bin/make"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("expected make to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package exec
+
+import (
+	"os/exec"
+	"regexp"
+	"runtime"
+)
+
+// powerShellSyntax matches PowerShell-only constructs: environment
+// variables, the call operator and Verb-Noun cmdlets.
+var powerShellSyntax = regexp.MustCompile(`\$env:|\$LASTEXITCODE|^\s*& |\b(Get|Set|New|Remove|Write|Copy|Move|Test|Invoke|Start|Stop|Out|Select|Where|ForEach|Import|Export|Add|Clear|Join|Split|Resolve)-[A-Z][A-Za-z]+`)
+
+// posixShellSyntax matches constructs cmd.exe cannot run: relative
+// executables, shell variables, command substitution and POSIX paths.
+var posixShellSyntax = regexp.MustCompile("^\\s*\\./|\\$\\{?[A-Za-z_]|`|\\$\\(|/dev/null|(^|[;&|]\\s*)(export|source) ")
+
+// ShellCommand returns the argv that runs command through the platform's
+// shell. On Unix it is "sh -c". On Windows it is PowerShell for commands
+// using PowerShell syntax, "sh -c" for POSIX-only syntax when an sh (such as
+// Git Bash's) is on PATH, and "cmd /C" otherwise.
+func ShellCommand(command string) []string {
+	return shellCommand(runtime.GOOS, command, exec.LookPath)
+}
+
+// shellCommand is ShellCommand for the given GOOS and PATH lookup.
+func shellCommand(goos, command string, lookPath func(string) (string, error)) []string {
+	if goos != "windows" {
+		return []string{"sh", "-c", command}
+	}
+	switch {
+	case powerShellSyntax.MatchString(command):
+		shell := "powershell"
+		if _, err := lookPath("pwsh"); err == nil {
+			shell = "pwsh"
+		}
+		return []string{shell, "-NoProfile", "-NonInteractive", "-Command", command}
+	case posixShellSyntax.MatchString(command):
+		if _, err := lookPath("sh"); err == nil {
+			return []string{"sh", "-c", command}
+		}
+	}
+	return []string{"cmd", "/C", command}
+}
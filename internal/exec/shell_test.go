@@ -0,0 +1,37 @@
+package exec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestShellCommand(t *testing.T) {
+	found := func(name string) (string, error) { return name, nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	tests := []struct {
+		name     string
+		goos     string
+		command  string
+		lookPath func(string) (string, error)
+		want     []string
+	}{
+		{"unix", "linux", "go test ./...", found, []string{"sh", "-c", "go test ./..."}},
+		{"windows plain command", "windows", "go build ./... && go vet ./...", found, []string{"cmd", "/C", "go build ./... && go vet ./..."}},
+		{"windows cmdlet", "windows", "Remove-Item -Recurse dist; npm run build", missing,
+			[]string{"powershell", "-NoProfile", "-NonInteractive", "-Command", "Remove-Item -Recurse dist; npm run build"}},
+		{"windows env var prefers pwsh", "windows", "$env:CGO_ENABLED=0; go build", found,
+			[]string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", "$env:CGO_ENABLED=0; go build"}},
+		{"windows posix syntax with sh", "windows", "./scripts/build.sh", found, []string{"sh", "-c", "./scripts/build.sh"}},
+		{"windows posix syntax without sh", "windows", "make test 2>/dev/null", missing, []string{"cmd", "/C", "make test 2>/dev/null"}},
+		{"npm script named like a cmdlet", "windows", "npm run test-unit", found, []string{"cmd", "/C", "npm run test-unit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellCommand(tt.goos, tt.command, tt.lookPath); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shellCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
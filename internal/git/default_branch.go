@@ -0,0 +1,45 @@
+// Package git provides an interface for git operations.
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultBranchCandidates are common default branch names, tried in order
+// when the repository does not record its default branch.
+var defaultBranchCandidates = []string{"main", "master", "trunk", "develop"}
+
+// DetectDefaultBranch returns the repository's default branch. It prefers
+// the remote HEAD (origin/HEAD), then init.defaultBranch, then the first
+// existing branch of main, master, trunk and develop. Only branches that
+// exist locally are returned.
+func DetectDefaultBranch(r Runner) (string, error) {
+	if ref, err := r.Run("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(strings.TrimSpace(ref), "origin/"); branch != "" {
+			if exists, err := r.BranchExists(branch); err == nil && exists {
+				return branch, nil
+			}
+		}
+	}
+
+	if name, err := r.Run("config", "--get", "init.defaultBranch"); err == nil {
+		if branch := strings.TrimSpace(name); branch != "" {
+			if exists, err := r.BranchExists(branch); err == nil && exists {
+				return branch, nil
+			}
+		}
+	}
+
+	for _, branch := range defaultBranchCandidates {
+		exists, err := r.BranchExists(branch)
+		if err != nil {
+			return "", fmt.Errorf("check branch %s: %w", branch, err)
+		}
+		if exists {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("no default branch found (tried origin/HEAD, init.defaultBranch, %s); set merge.default_branch",
+		strings.Join(defaultBranchCandidates, ", "))
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// initRepo creates a repository in a temp dir whose first commit is on branch.
+func initRepo(t *testing.T, branch string) *ExecRunner {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"checkout", "-q", "-b", branch},
+		{"-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return NewRunner(dir)
+}
+
+func TestDetectDefaultBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	// Keep the user's init.defaultBranch out of the detection
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	t.Run("trunk", func(t *testing.T) {
+		r := initRepo(t, "trunk")
+		if got, err := DetectDefaultBranch(r); err != nil || got != "trunk" {
+			t.Errorf("DetectDefaultBranch() = %q, %v; want trunk", got, err)
+		}
+	})
+
+	t.Run("main preferred over develop", func(t *testing.T) {
+		r := initRepo(t, "develop")
+		if err := r.CreateBranch("main"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DetectDefaultBranch(r); err != nil || got != "main" {
+			t.Errorf("DetectDefaultBranch() = %q, %v; want main", got, err)
+		}
+	})
+
+	t.Run("init.defaultBranch", func(t *testing.T) {
+		r := initRepo(t, "release")
+		if err := r.CreateBranch("main"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Run("config", "init.defaultBranch", "release"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DetectDefaultBranch(r); err != nil || got != "release" {
+			t.Errorf("DetectDefaultBranch() = %q, %v; want release", got, err)
+		}
+	})
+
+	t.Run("remote HEAD", func(t *testing.T) {
+		r := initRepo(t, "stable")
+		if err := r.CreateBranch("main"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Run("update-ref", "refs/remotes/origin/stable", "HEAD"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/stable"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DetectDefaultBranch(r); err != nil || got != "stable" {
+			t.Errorf("DetectDefaultBranch() = %q, %v; want stable", got, err)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		r := initRepo(t, "feature")
+		if got, err := DetectDefaultBranch(r); err == nil {
+			t.Errorf("DetectDefaultBranch() = %q, want error", got)
+		}
+	})
+}
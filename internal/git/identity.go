@@ -0,0 +1,106 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit signing formats.
+const (
+	SignNone = ""
+	SignGPG  = "gpg"
+	SignSSH  = "ssh"
+)
+
+// DefaultCommitMessageTemplate is the message of agent commits when no
+// template is configured.
+const DefaultCommitMessageTemplate = "{subject}\n\nAlphie-Task: {task_id}\nAlphie-Session: {session_id}"
+
+// CommitIdentity is who alphie's commits and merges are made as, whether
+// they are signed, and how their messages are written. The zero value, like
+// a nil *CommitIdentity, leaves all of it to the repository's git config.
+type CommitIdentity struct {
+	// Name and Email are the author and committer, e.g. "Alphie Agent".
+	Name  string
+	Email string
+	// Sign is SignGPG or SignSSH to sign commits, or SignNone.
+	Sign string
+	// SigningKey is the GPG key ID or SSH key file. Empty uses the
+	// repository's user.signingkey.
+	SigningKey string
+	// MessageTemplate formats commit messages. {subject}, {task_id} and
+	// {session_id} are replaced; lines with a placeholder that has no value
+	// are dropped. Empty uses DefaultCommitMessageTemplate.
+	MessageTemplate string
+}
+
+// Validate reports an unknown signing format.
+func (c *CommitIdentity) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Sign {
+	case SignNone, SignGPG, SignSSH:
+		return nil
+	}
+	return fmt.Errorf("unknown commit signing format %q (use gpg or ssh)", c.Sign)
+}
+
+// ConfigArgs returns the "-c key=value" options that make a git command
+// commit as this identity. They go before the git subcommand.
+func (c *CommitIdentity) ConfigArgs() []string {
+	if c == nil {
+		return nil
+	}
+	var args []string
+	if c.Name != "" {
+		args = append(args, "-c", "user.name="+c.Name)
+	}
+	if c.Email != "" {
+		args = append(args, "-c", "user.email="+c.Email)
+	}
+	if c.Sign != SignNone {
+		format := "openpgp"
+		if c.Sign == SignSSH {
+			format = "ssh"
+		}
+		args = append(args, "-c", "commit.gpgsign=true", "-c", "gpg.format="+format)
+		if c.SigningKey != "" {
+			args = append(args, "-c", "user.signingkey="+c.SigningKey)
+		}
+	}
+	return args
+}
+
+// Command returns a git command run in dir as this identity.
+func (c *CommitIdentity) Command(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append(c.ConfigArgs(), args...)...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// Message formats a commit message for subject made for a task of a
+// session. taskID and sessionID may be empty.
+func (c *CommitIdentity) Message(subject, taskID, sessionID string) string {
+	tmpl := DefaultCommitMessageTemplate
+	if c != nil && c.MessageTemplate != "" {
+		tmpl = c.MessageTemplate
+	}
+	values := map[string]string{"{subject}": subject, "{task_id}": taskID, "{session_id}": sessionID}
+
+	var lines []string
+	for _, line := range strings.Split(tmpl, "\n") {
+		keep := true
+		for placeholder, value := range values {
+			if strings.Contains(line, placeholder) {
+				keep = keep && value != ""
+				line = strings.ReplaceAll(line, placeholder, value)
+			}
+		}
+		if keep {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
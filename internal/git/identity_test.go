@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestCommitIdentity_Message(t *testing.T) {
+	var none *CommitIdentity
+	if got, want := none.Message("Agent: add login", "t-1", "s-1"), "Agent: add login\n\nAlphie-Task: t-1\nAlphie-Session: s-1"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+	if got, want := none.Message("Merge session s-1", "", "s-1"), "Merge session s-1\n\nAlphie-Session: s-1"; got != want {
+		t.Errorf("Message() without task = %q, want %q", got, want)
+	}
+	if got, want := none.Message("Quick: fix typo", "", ""), "Quick: fix typo"; got != want {
+		t.Errorf("Message() without IDs = %q, want %q", got, want)
+	}
+
+	custom := &CommitIdentity{MessageTemplate: "[{task_id}] {subject}\n\nRefs: {session_id}"}
+	if got, want := custom.Message("add login", "t-1", "s-1"), "[t-1] add login\n\nRefs: s-1"; got != want {
+		t.Errorf("Message() with template = %q, want %q", got, want)
+	}
+}
+
+func TestCommitIdentity_ConfigArgs(t *testing.T) {
+	var none *CommitIdentity
+	if args := none.ConfigArgs(); args != nil {
+		t.Errorf("nil identity ConfigArgs() = %v, want none", args)
+	}
+
+	id := &CommitIdentity{Name: "Alphie Agent", Email: "alphie@example.com", Sign: SignSSH, SigningKey: "~/.ssh/alphie.pub"}
+	want := []string{
+		"-c", "user.name=Alphie Agent",
+		"-c", "user.email=alphie@example.com",
+		"-c", "commit.gpgsign=true", "-c", "gpg.format=ssh",
+		"-c", "user.signingkey=~/.ssh/alphie.pub",
+	}
+	if got := id.ConfigArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigArgs() = %v, want %v", got, want)
+	}
+
+	if err := (&CommitIdentity{Sign: "pgp"}).Validate(); err == nil {
+		t.Error("expected an unknown signing format to be rejected")
+	}
+}
+
+func TestExecRunner_CommitIdentity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := initRepo(t, "main").WithCommitIdentity(&CommitIdentity{Name: "Alphie Agent", Email: "alphie@example.com"})
+	if _, err := r.Run("commit", "--allow-empty", "-m", "agent work"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	got, err := r.Run("log", "-1", "--format=%an <%ae> / %cn <%ce>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Alphie Agent <alphie@example.com> / Alphie Agent <alphie@example.com>"; got != want {
+		t.Errorf("commit identity = %q, want %q", got, want)
+	}
+}
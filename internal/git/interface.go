@@ -62,6 +62,9 @@ type MergeOperations interface {
 	MergeAbort() error
 	// MergeBase returns the common ancestor of two branches.
 	MergeBase(branch1, branch2 string) (string, error)
+	// MergeTree merges two commits without touching the index or working
+	// tree, returning the resulting tree and the conflicted files.
+	MergeTree(ours, theirs string) (string, []string, error)
 	// HasConflicts returns true if there are merge conflicts.
 	HasConflicts() (bool, error)
 	// Rebase rebases the current branch onto the specified base.
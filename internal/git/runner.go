@@ -10,6 +10,8 @@ import (
 // ExecRunner implements Runner using exec.Command.
 type ExecRunner struct {
 	repoPath string
+	// identity is who commits are made as; nil uses the git config.
+	identity *CommitIdentity
 }
 
 // NewRunner creates a new git runner for the repository at the given path.
@@ -17,10 +19,16 @@ func NewRunner(repoPath string) *ExecRunner {
 	return &ExecRunner{repoPath: repoPath}
 }
 
+// WithCommitIdentity makes the runner's commits and merges as identity and
+// returns the runner.
+func (r *ExecRunner) WithCommitIdentity(identity *CommitIdentity) *ExecRunner {
+	r.identity = identity
+	return r
+}
+
 // run executes a git command and returns its output.
 func (r *ExecRunner) run(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = r.repoPath
+	cmd := r.identity.Command(r.repoPath, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, string(out))
@@ -30,8 +38,7 @@ func (r *ExecRunner) run(args ...string) (string, error) {
 
 // runSilent executes a git command and ignores output.
 func (r *ExecRunner) runSilent(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = r.repoPath
+	cmd := r.identity.Command(r.repoPath, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, string(out))
@@ -41,8 +48,7 @@ func (r *ExecRunner) runSilent(args ...string) error {
 
 // runInDir executes a git command in a specific directory and returns output.
 func (r *ExecRunner) runInDir(dir string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
+	cmd := r.identity.Command(dir, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, string(out))
@@ -52,8 +58,7 @@ func (r *ExecRunner) runInDir(dir string, args ...string) (string, error) {
 
 // runSilentInDir executes a git command in a specific directory and ignores output.
 func (r *ExecRunner) runSilentInDir(dir string, args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
+	cmd := r.identity.Command(dir, args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, string(out))
@@ -311,6 +316,35 @@ func (r *ExecRunner) MergeBase(branch1, branch2 string) (string, error) {
 	return r.run("merge-base", branch1, branch2)
 }
 
+// MergeTree merges theirs into ours in memory (git merge-tree --write-tree)
+// and returns the resulting tree and the conflicted files. The tree is
+// written even when there are conflicts, with conflict markers in the files.
+func (r *ExecRunner) MergeTree(ours, theirs string) (string, []string, error) {
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs)
+	cmd.Dir = r.repoPath
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the merge has conflicts (not an error)
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return "", nil, fmt.Errorf("git merge-tree %s %s: %w: %s", ours, theirs, err, stderr.String())
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	var conflicts []string
+	seen := make(map[string]bool)
+	for _, line := range lines[1:] {
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		conflicts = append(conflicts, line)
+	}
+	return lines[0], conflicts, nil
+}
+
 // Rebase rebases the current branch onto the specified base.
 func (r *ExecRunner) Rebase(base string) error {
 	return r.runSilent("rebase", base)
@@ -0,0 +1,180 @@
+// Package github implements remote.Provider for GitHub: it pushes the
+// session branch, opens a pull request, reports results as check runs and
+// posts reviewer concerns as review comments. It drives the gh CLI, so the
+// repository's gh authentication and remote are used.
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+const (
+	// maxBodyLen stays under GitHub's 65536 character limit for PR bodies
+	// and check run output.
+	maxBodyLen = 60000
+	// maxStatusDescriptionLen is GitHub's limit for commit status descriptions.
+	maxStatusDescriptionLen = 140
+)
+
+// pullURLPattern extracts the number from a pull request URL.
+var pullURLPattern = regexp.MustCompile(`https://\S+/pull/(\d+)`)
+
+// PullRequestInput describes a pull request to open.
+type PullRequestInput struct {
+	// Base is the branch the pull request merges into.
+	Base string
+	// Head is the branch with the changes.
+	Head string
+	// Title is the pull request title.
+	Title string
+	// Body is the Markdown description.
+	Body string
+}
+
+// Client talks to GitHub through the gh CLI and pushes with git.
+type Client struct {
+	git *remote.Git
+}
+
+// NewClient creates a Client for the repository at repoPath.
+func NewClient(repoPath string) *Client {
+	return NewClientWithExec(repoPath, iexec.NewRunner())
+}
+
+// NewClientWithExec creates a Client with a custom command runner (for testing).
+func NewClientWithExec(repoPath string, runner iexec.CommandRunner) *Client {
+	return &Client{git: remote.NewGit(repoPath, runner)}
+}
+
+// SetRemote sets the git remote branches are pushed to (default "origin").
+func (c *Client) SetRemote(name string) {
+	c.git.Remote = name
+}
+
+// Name returns "github".
+func (c *Client) Name() string {
+	return string(remote.KindGitHub)
+}
+
+// CreatePullRequest opens a pull request and returns its number and URL.
+func (c *Client) CreatePullRequest(ctx context.Context, in PullRequestInput) (*remote.PullRequest, error) {
+	out, err := c.git.Run(ctx, "gh", "pr", "create",
+		"--base", in.Base,
+		"--head", in.Head,
+		"--title", in.Title,
+		"--body", remote.Truncate(in.Body, maxBodyLen))
+	if err != nil {
+		return nil, err
+	}
+	match := pullURLPattern.FindStringSubmatch(out)
+	if match == nil {
+		return nil, fmt.Errorf("gh pr create: no pull request URL in output %q", out)
+	}
+	number, _ := strconv.Atoi(match[1])
+	return &remote.PullRequest{Number: number, URL: match[0]}, nil
+}
+
+// CreateCheckRun reports check as a completed check run. Creating check runs
+// requires a GitHub App token, so with a user token the result is reported
+// as a commit status instead.
+func (c *Client) CreateCheckRun(ctx context.Context, check remote.CheckRun) error {
+	_, err := c.git.Run(ctx, "gh", "api", "repos/{owner}/{repo}/check-runs",
+		"--method", "POST",
+		"-f", "name="+check.Name,
+		"-f", "head_sha="+check.HeadSHA,
+		"-f", "status=completed",
+		"-f", "conclusion="+string(check.Conclusion),
+		"-f", "output[title]="+check.Title,
+		"-f", "output[summary]="+remote.Truncate(check.Summary, maxBodyLen),
+		"-f", "output[text]="+remote.Truncate(check.Text, maxBodyLen))
+	if err == nil {
+		return nil
+	}
+	log.Printf("[github] check run %s unavailable, reporting a commit status: %v", check.Name, err)
+	return c.createStatus(ctx, check)
+}
+
+// createStatus reports check as a commit status. Statuses have no neutral
+// state, so neutral checks are reported as successful.
+func (c *Client) createStatus(ctx context.Context, check remote.CheckRun) error {
+	state := "success"
+	if check.Conclusion == remote.ConclusionFailure {
+		state = "failure"
+	}
+	_, err := c.git.Run(ctx, "gh", "api", "repos/{owner}/{repo}/statuses/"+check.HeadSHA,
+		"--method", "POST",
+		"-f", "state="+state,
+		"-f", "context="+check.Name,
+		"-f", "description="+remote.Truncate(check.Title, maxStatusDescriptionLen))
+	return err
+}
+
+// CreateReview posts review on the pull request.
+func (c *Client) CreateReview(ctx context.Context, number int, review *annotate.Review) error {
+	path, err := remote.WriteJSONFile("alphie-review-*.json", review)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	_, err = c.git.Run(ctx, "gh", "api", fmt.Sprintf("repos/{owner}/{repo}/pulls/%d/reviews", number),
+		"--method", "POST",
+		"--input", path)
+	return err
+}
+
+// Publish pushes the session branch, opens the pull request, reports the
+// checks on its head and posts the concerns as a review.
+func (c *Client) Publish(ctx context.Context, sub remote.Submission) (*remote.PullRequest, error) {
+	if err := c.git.Push(ctx, sub.Branch); err != nil {
+		return nil, fmt.Errorf("push session branch: %w", err)
+	}
+	pr, err := c.CreatePullRequest(ctx, PullRequestInput{
+		Base:  sub.Base,
+		Head:  sub.Branch,
+		Title: sub.Title,
+		Body:  sub.Body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create pull request: %w", err)
+	}
+
+	var errs []error
+	if len(sub.Checks) > 0 {
+		sha, err := c.git.RevParse(ctx, sub.Branch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolve head commit: %w", err))
+		} else {
+			for _, check := range sub.Checks {
+				check.HeadSHA = sha
+				if err := c.CreateCheckRun(ctx, check); err != nil {
+					errs = append(errs, fmt.Errorf("report check %s: %w", check.Name, err))
+				}
+			}
+		}
+	}
+
+	if len(sub.Concerns) > 0 {
+		diff, err := c.git.Diff(ctx, sub.Base, sub.Branch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("diff session branch: %w", err))
+		} else if err := c.CreateReview(ctx, pr.Number, ReviewFromConcerns(sub.Concerns, annotate.ParseDiff(diff))); err != nil {
+			errs = append(errs, fmt.Errorf("post review: %w", err))
+		}
+	}
+
+	return pr, errors.Join(errs...)
+}
+
+// Verify Client implements remote.Provider at compile time.
+var _ remote.Provider = (*Client)(nil)
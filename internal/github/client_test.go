@@ -0,0 +1,226 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// fakeRunner records commands and answers them by the first matching prefix.
+type fakeRunner struct {
+	calls   [][]string
+	outputs map[string]string
+	fail    map[string]bool
+	// inputs holds the content of files passed with --input, read at call time.
+	inputs []string
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{outputs: make(map[string]string), fail: make(map[string]bool)}
+}
+
+func (f *fakeRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	call := append([]string{name}, args...)
+	f.calls = append(f.calls, call)
+	line := strings.Join(call, " ")
+	for i, arg := range args {
+		if arg == "--input" && i+1 < len(args) {
+			data, _ := os.ReadFile(args[i+1])
+			f.inputs = append(f.inputs, string(data))
+		}
+	}
+	for prefix := range f.fail {
+		if strings.HasPrefix(line, prefix) {
+			return []byte("boom"), errors.New("exit status 1")
+		}
+	}
+	for prefix, out := range f.outputs {
+		if strings.HasPrefix(line, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return f.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (f *fakeRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+// called returns the calls starting with prefix.
+func (f *fakeRunner) called(prefix string) [][]string {
+	var calls [][]string
+	for _, call := range f.calls {
+		if strings.HasPrefix(strings.Join(call, " "), prefix) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+const sampleDiff = `diff --git a/internal/auth/login.go b/internal/auth/login.go
+--- a/internal/auth/login.go
++++ b/internal/auth/login.go
+@@ -10,3 +10,6 @@ func Login() {
+ 	a := 1
++	b := 2
++	c := 3
++	d := 4
+ 	return
+ }
+`
+
+func TestClient_CreatePullRequest(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gh pr create"] = "Creating pull request\nhttps://github.com/acme/app/pull/42\n"
+	client := NewClientWithExec("/repo", runner)
+
+	pr, err := client.CreatePullRequest(context.Background(), PullRequestInput{
+		Base: "main", Head: "alphie/implement-1", Title: "Implement spec", Body: "body",
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if pr.Number != 42 || pr.URL != "https://github.com/acme/app/pull/42" {
+		t.Errorf("CreatePullRequest() = %+v", pr)
+	}
+	want := "gh pr create --base main --head alphie/implement-1 --title Implement spec --body body"
+	if got := strings.Join(runner.calls[0], " "); got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestClient_CreatePullRequest_NoURL(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gh pr create"] = "something unexpected"
+	client := NewClientWithExec("/repo", runner)
+
+	if _, err := client.CreatePullRequest(context.Background(), PullRequestInput{Base: "main", Head: "b"}); err == nil {
+		t.Error("expected an error without a pull request URL")
+	}
+}
+
+func TestClient_CreateCheckRun_FallsBackToStatus(t *testing.T) {
+	runner := newFakeRunner()
+	runner.fail["gh api repos/{owner}/{repo}/check-runs"] = true
+	client := NewClientWithExec("/repo", runner)
+
+	err := client.CreateCheckRun(context.Background(), remote.CheckRun{
+		Name:       "alphie/final-verification",
+		HeadSHA:    "abc123",
+		Conclusion: remote.ConclusionNeutral,
+		Title:      strings.Repeat("x", 200),
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+	statuses := runner.called("gh api repos/{owner}/{repo}/statuses/abc123")
+	if len(statuses) != 1 {
+		t.Fatalf("expected a commit status fallback, calls = %v", runner.calls)
+	}
+	args := strings.Join(statuses[0], " ")
+	if !strings.Contains(args, "state=success") || !strings.Contains(args, "context=alphie/final-verification") {
+		t.Errorf("status args = %q", args)
+	}
+	for _, arg := range statuses[0] {
+		if strings.HasPrefix(arg, "description=") && len(arg)-len("description=") > maxStatusDescriptionLen {
+			t.Errorf("description not truncated: %d bytes", len(arg))
+		}
+	}
+}
+
+func TestClient_Publish(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gh pr create"] = "https://github.com/acme/app/pull/7"
+	runner.outputs["git rev-parse"] = "deadbeef\n"
+	runner.outputs["git diff"] = sampleDiff
+	client := NewClientWithExec("/repo", runner)
+
+	pr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "alphie/implement-1",
+		Title:  "Implement spec",
+		Body:   "body",
+		Checks: []remote.CheckRun{
+			{Name: "alphie/validation", Conclusion: remote.ConclusionSuccess},
+			{Name: "alphie/final-verification", Conclusion: remote.ConclusionFailure},
+		},
+		Concerns: []remote.Concern{{TaskID: "t1", TaskTitle: "Add login", Text: "internal/auth/login.go:12 ignores the error"}},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if pr.Number != 7 {
+		t.Errorf("pr number = %d, want 7", pr.Number)
+	}
+
+	if len(runner.called("git push --set-upstream origin alphie/implement-1")) != 1 {
+		t.Errorf("expected the branch to be pushed, calls = %v", runner.calls)
+	}
+	checks := runner.called("gh api repos/{owner}/{repo}/check-runs")
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 check runs, got %d", len(checks))
+	}
+	if !strings.Contains(strings.Join(checks[1], " "), "head_sha=deadbeef") {
+		t.Errorf("check run not reported on the branch head: %v", checks[1])
+	}
+	if len(runner.called("git diff main...alphie/implement-1")) != 1 {
+		t.Errorf("expected the review to use the branch diff, calls = %v", runner.calls)
+	}
+
+	if len(runner.inputs) != 1 {
+		t.Fatalf("expected one review to be posted, got %d", len(runner.inputs))
+	}
+	var review annotate.Review
+	if err := json.Unmarshal([]byte(runner.inputs[0]), &review); err != nil {
+		t.Fatalf("review is not valid JSON: %v", err)
+	}
+	if len(review.Comments) != 1 || review.Comments[0].Path != "internal/auth/login.go" || review.Comments[0].Line != 12 {
+		t.Errorf("review comments = %+v", review.Comments)
+	}
+	if len(runner.called("gh api repos/{owner}/{repo}/pulls/7/reviews")) != 1 {
+		t.Errorf("review not posted to pull request 7, calls = %v", runner.calls)
+	}
+}
+
+func TestClient_Publish_PushFailure(t *testing.T) {
+	runner := newFakeRunner()
+	runner.fail["git push"] = true
+	client := NewClientWithExec("/repo", runner)
+
+	pr, err := client.Publish(context.Background(), remote.Submission{Base: "main", Branch: "b"})
+	if err == nil || pr != nil {
+		t.Fatalf("Publish() = %v, %v; want a push error", pr, err)
+	}
+	if len(runner.called("gh pr create")) != 0 {
+		t.Error("pull request should not be opened after a failed push")
+	}
+}
+
+func TestClient_Publish_KeepsPullRequestOnCheckFailure(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gh pr create"] = "https://github.com/acme/app/pull/3"
+	runner.fail["gh api"] = true
+	client := NewClientWithExec("/repo", runner)
+
+	pr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "b",
+		Checks: []remote.CheckRun{{Name: "alphie/validation"}},
+	})
+	if pr == nil || pr.Number != 3 {
+		t.Fatalf("Publish() pr = %v, want pull request 3", pr)
+	}
+	if err == nil || !strings.Contains(err.Error(), "report check alphie/validation") {
+		t.Errorf("Publish() error = %v, want the check failure", err)
+	}
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// ReviewFromConcerns maps reviewer concerns onto a pull request review:
+// concerns placed on a changed line become inline comments and the rest
+// are listed in the review body (see remote.PlaceConcerns).
+func ReviewFromConcerns(concerns []remote.Concern, files []annotate.FileDiff) *annotate.Review {
+	inline, general := remote.PlaceConcerns(concerns, files)
+	review := &annotate.Review{
+		Body:     remote.ReviewSummary(len(concerns), general),
+		Event:    "COMMENT",
+		Comments: []annotate.ReviewComment{},
+	}
+	for _, c := range inline {
+		review.Comments = append(review.Comments, annotate.ReviewComment{
+			Path: c.Path,
+			Line: c.Line,
+			Side: "RIGHT",
+			Body: c.InlineComment(),
+		})
+	}
+	return review
+}
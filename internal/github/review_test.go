@@ -0,0 +1,43 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+func TestReviewFromConcerns(t *testing.T) {
+	files := annotate.ParseDiff(sampleDiff)
+	concerns := []remote.Concern{
+		{TaskID: "t1", TaskTitle: "Add login", Text: "login.go:11 swallows the error from b"},
+		{TaskID: "t2", Text: "internal/auth/login.go:99 is outside the diff"},
+		{TaskID: "t3", TaskTitle: "Add sessions", Text: "No tests cover session expiry"},
+	}
+
+	review := ReviewFromConcerns(concerns, files)
+
+	if review.Event != "COMMENT" {
+		t.Errorf("Event = %q, want COMMENT", review.Event)
+	}
+	if len(review.Comments) != 1 {
+		t.Fatalf("expected 1 inline comment, got %+v", review.Comments)
+	}
+	c := review.Comments[0]
+	if c.Path != "internal/auth/login.go" || c.Line != 11 || c.Side != "RIGHT" {
+		t.Errorf("comment = %+v", c)
+	}
+	if !strings.Contains(c.Body, "Add login") {
+		t.Errorf("comment body should name the task: %q", c.Body)
+	}
+
+	if !strings.Contains(review.Body, "3 concern(s)") {
+		t.Errorf("body should count the concerns: %q", review.Body)
+	}
+	for _, want := range []string{"**t2**: internal/auth/login.go:99", "**Add sessions**: No tests cover session expiry"} {
+		if !strings.Contains(review.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, review.Body)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package gitlab implements remote.Provider for GitLab: it pushes the
+// session branch, opens a merge request, reports results as commit statuses
+// and posts reviewer concerns as merge request discussions. It drives the
+// glab CLI, so the repository's glab authentication and remote are used.
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+const (
+	// maxBodyLen caps merge request descriptions, matching the GitHub provider.
+	maxBodyLen = 60000
+	// maxStatusDescriptionLen keeps commit status descriptions readable.
+	maxStatusDescriptionLen = 255
+)
+
+// mergeRequestURLPattern extracts the IID from a merge request URL.
+var mergeRequestURLPattern = regexp.MustCompile(`https?://\S+/-/merge_requests/(\d+)`)
+
+// Client talks to GitLab through the glab CLI and pushes with git.
+type Client struct {
+	git *remote.Git
+}
+
+// NewClient creates a Client for the repository at repoPath.
+func NewClient(repoPath string) *Client {
+	return NewClientWithExec(repoPath, iexec.NewRunner())
+}
+
+// NewClientWithExec creates a Client with a custom command runner (for testing).
+func NewClientWithExec(repoPath string, runner iexec.CommandRunner) *Client {
+	return &Client{git: remote.NewGit(repoPath, runner)}
+}
+
+// SetRemote sets the git remote branches are pushed to (default "origin").
+func (c *Client) SetRemote(name string) {
+	c.git.Remote = name
+}
+
+// Name returns "gitlab".
+func (c *Client) Name() string {
+	return string(remote.KindGitLab)
+}
+
+// CreateMergeRequest opens a merge request from head into base and returns
+// its IID and URL.
+func (c *Client) CreateMergeRequest(ctx context.Context, base, head, title, body string) (*remote.PullRequest, error) {
+	out, err := c.git.Run(ctx, "glab", "mr", "create",
+		"--source-branch", head,
+		"--target-branch", base,
+		"--title", title,
+		"--description", remote.Truncate(body, maxBodyLen),
+		"--yes")
+	if err != nil {
+		return nil, err
+	}
+	match := mergeRequestURLPattern.FindStringSubmatch(out)
+	if match == nil {
+		return nil, fmt.Errorf("glab mr create: no merge request URL in output %q", out)
+	}
+	iid, _ := strconv.Atoi(match[1])
+	return &remote.PullRequest{Number: iid, URL: match[0]}, nil
+}
+
+// CreateStatus reports check as a commit status. GitLab statuses have no
+// neutral state, so neutral checks are reported as successful.
+func (c *Client) CreateStatus(ctx context.Context, check remote.CheckRun) error {
+	state := "success"
+	if check.Conclusion == remote.ConclusionFailure {
+		state = "failed"
+	}
+	_, err := c.git.Run(ctx, "glab", "api", "projects/:id/statuses/"+check.HeadSHA,
+		"--method", "POST",
+		"-f", "state="+state,
+		"-f", "name="+check.Name,
+		"-f", "description="+remote.Truncate(check.Title, maxStatusDescriptionLen))
+	return err
+}
+
+// diffRefs locates an inline comment in a merge request's diff.
+type diffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+// discussion is the body of a "create a merge request thread" request.
+type discussion struct {
+	Body     string    `json:"body"`
+	Position *position `json:"position,omitempty"`
+}
+
+// position anchors a discussion on a line of the new version of a file.
+type position struct {
+	diffRefs
+	PositionType string `json:"position_type"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// createDiscussion starts a thread on the merge request.
+func (c *Client) createDiscussion(ctx context.Context, iid int, d discussion) error {
+	path, err := remote.WriteJSONFile("alphie-discussion-*.json", d)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	_, err = c.git.Run(ctx, "glab", "api", fmt.Sprintf("projects/:id/merge_requests/%d/discussions", iid),
+		"--method", "POST",
+		"--header", "Content-Type: application/json",
+		"--input", path)
+	return err
+}
+
+// Publish pushes the session branch, opens the merge request, reports the
+// checks on its head and posts the concerns as discussions: one per concern
+// placed on a changed line, plus a summary thread.
+func (c *Client) Publish(ctx context.Context, sub remote.Submission) (*remote.PullRequest, error) {
+	if err := c.git.Push(ctx, sub.Branch); err != nil {
+		return nil, fmt.Errorf("push session branch: %w", err)
+	}
+	mr, err := c.CreateMergeRequest(ctx, sub.Base, sub.Branch, sub.Title, sub.Body)
+	if err != nil {
+		return nil, fmt.Errorf("create merge request: %w", err)
+	}
+
+	var errs []error
+	head, err := c.git.RevParse(ctx, sub.Branch)
+	if err != nil {
+		return mr, fmt.Errorf("resolve head commit: %w", err)
+	}
+	for _, check := range sub.Checks {
+		check.HeadSHA = head
+		if err := c.CreateStatus(ctx, check); err != nil {
+			errs = append(errs, fmt.Errorf("report check %s: %w", check.Name, err))
+		}
+	}
+
+	if len(sub.Concerns) > 0 {
+		if err := c.postConcerns(ctx, mr.Number, sub, head); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return mr, errors.Join(errs...)
+}
+
+// postConcerns posts the summary thread and the inline concerns.
+func (c *Client) postConcerns(ctx context.Context, iid int, sub remote.Submission, head string) error {
+	diff, err := c.git.Diff(ctx, sub.Base, sub.Branch)
+	if err != nil {
+		return fmt.Errorf("diff session branch: %w", err)
+	}
+	inline, general := remote.PlaceConcerns(sub.Concerns, annotate.ParseDiff(diff))
+
+	var errs []error
+	if err := c.createDiscussion(ctx, iid, discussion{Body: remote.ReviewSummary(len(sub.Concerns), general)}); err != nil {
+		errs = append(errs, fmt.Errorf("post review summary: %w", err))
+	}
+	if len(inline) == 0 {
+		return errors.Join(errs...)
+	}
+
+	refs := diffRefs{HeadSHA: head}
+	if refs.BaseSHA, err = c.git.MergeBase(ctx, sub.Base, sub.Branch); err != nil {
+		return errors.Join(append(errs, fmt.Errorf("resolve merge base: %w", err))...)
+	}
+	if refs.StartSHA, err = c.git.RevParse(ctx, sub.Base); err != nil {
+		return errors.Join(append(errs, fmt.Errorf("resolve target branch: %w", err))...)
+	}
+	for _, concern := range inline {
+		d := discussion{
+			Body: concern.InlineComment(),
+			Position: &position{
+				diffRefs:     refs,
+				PositionType: "text",
+				NewPath:      concern.Path,
+				NewLine:      concern.Line,
+			},
+		}
+		if err := c.createDiscussion(ctx, iid, d); err != nil {
+			errs = append(errs, fmt.Errorf("post comment on %s:%d: %w", concern.Path, concern.Line, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify Client implements remote.Provider at compile time.
+var _ remote.Provider = (*Client)(nil)
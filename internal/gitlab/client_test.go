@@ -0,0 +1,186 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/remote"
+)
+
+// fakeRunner records commands and answers them by the first matching prefix.
+type fakeRunner struct {
+	calls   [][]string
+	outputs map[string]string
+	fail    map[string]bool
+	// inputs holds the content of files passed with --input, read at call time.
+	inputs []string
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{outputs: make(map[string]string), fail: make(map[string]bool)}
+}
+
+func (f *fakeRunner) Run(ctx context.Context, workDir string, name string, args ...string) ([]byte, error) {
+	call := append([]string{name}, args...)
+	f.calls = append(f.calls, call)
+	line := strings.Join(call, " ")
+	for i, arg := range args {
+		if arg == "--input" && i+1 < len(args) {
+			data, _ := os.ReadFile(args[i+1])
+			f.inputs = append(f.inputs, string(data))
+		}
+	}
+	for prefix := range f.fail {
+		if strings.HasPrefix(line, prefix) {
+			return []byte("boom"), errors.New("exit status 1")
+		}
+	}
+	for prefix, out := range f.outputs {
+		if strings.HasPrefix(line, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRunner) RunShell(ctx context.Context, workDir string, command string) ([]byte, error) {
+	return f.Run(ctx, workDir, "sh", "-c", command)
+}
+
+func (f *fakeRunner) Exists(ctx context.Context, workDir string, path string) bool {
+	return false
+}
+
+// called returns the calls starting with prefix.
+func (f *fakeRunner) called(prefix string) [][]string {
+	var calls [][]string
+	for _, call := range f.calls {
+		if strings.HasPrefix(strings.Join(call, " "), prefix) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+const sampleDiff = `diff --git a/internal/auth/login.go b/internal/auth/login.go
+--- a/internal/auth/login.go
++++ b/internal/auth/login.go
+@@ -10,3 +10,6 @@ func Login() {
+ 	a := 1
++	b := 2
++	c := 3
++	d := 4
+ 	return
+ }
+`
+
+func TestClient_CreateMergeRequest(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["glab mr create"] = "Creating merge request for b into main\n!9 Implement spec\nhttps://gitlab.com/acme/app/-/merge_requests/9\n"
+	client := NewClientWithExec("/repo", runner)
+
+	mr, err := client.CreateMergeRequest(context.Background(), "main", "b", "Implement spec", "body")
+	if err != nil {
+		t.Fatalf("CreateMergeRequest() error = %v", err)
+	}
+	if mr.Number != 9 || mr.URL != "https://gitlab.com/acme/app/-/merge_requests/9" {
+		t.Errorf("CreateMergeRequest() = %+v", mr)
+	}
+	want := "glab mr create --source-branch b --target-branch main --title Implement spec --description body --yes"
+	if got := strings.Join(runner.calls[0], " "); got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestClient_CreateMergeRequest_NoURL(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["glab mr create"] = "something unexpected"
+	client := NewClientWithExec("/repo", runner)
+
+	if _, err := client.CreateMergeRequest(context.Background(), "main", "b", "t", "body"); err == nil {
+		t.Error("expected an error without a merge request URL")
+	}
+}
+
+func TestClient_Publish(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["glab mr create"] = "https://gitlab.com/acme/app/-/merge_requests/4"
+	runner.outputs["git rev-parse main"] = "basesha\n"
+	runner.outputs["git rev-parse alphie/implement-1"] = "deadbeef\n"
+	runner.outputs["git merge-base"] = "forksha\n"
+	runner.outputs["git diff"] = sampleDiff
+	client := NewClientWithExec("/repo", runner)
+	client.SetRemote("gitlab")
+
+	mr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "alphie/implement-1",
+		Title:  "Implement spec",
+		Body:   "body",
+		Checks: []remote.CheckRun{
+			{Name: "alphie/validation", Conclusion: remote.ConclusionNeutral},
+			{Name: "alphie/final-verification", Conclusion: remote.ConclusionFailure},
+		},
+		Concerns: []remote.Concern{
+			{TaskID: "t1", TaskTitle: "Add login", Text: "internal/auth/login.go:12 ignores the error"},
+			{TaskID: "t2", Text: "No tests cover expiry"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if mr.Number != 4 {
+		t.Errorf("merge request = %d, want 4", mr.Number)
+	}
+	if len(runner.called("git push --set-upstream gitlab alphie/implement-1")) != 1 {
+		t.Errorf("expected the branch to be pushed to the configured remote, calls = %v", runner.calls)
+	}
+
+	statuses := runner.called("glab api projects/:id/statuses/deadbeef")
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 commit statuses, got %d", len(statuses))
+	}
+	if !strings.Contains(strings.Join(statuses[0], " "), "state=success") || !strings.Contains(strings.Join(statuses[1], " "), "state=failed") {
+		t.Errorf("statuses = %v", statuses)
+	}
+
+	if len(runner.called("glab api projects/:id/merge_requests/4/discussions")) != 2 {
+		t.Fatalf("expected a summary and an inline discussion, calls = %v", runner.calls)
+	}
+	var summary, placed discussion
+	json.Unmarshal([]byte(runner.inputs[0]), &summary)
+	json.Unmarshal([]byte(runner.inputs[1]), &placed)
+	if summary.Position != nil || !strings.Contains(summary.Body, "No tests cover expiry") {
+		t.Errorf("summary = %+v", summary)
+	}
+	pos := placed.Position
+	if pos == nil || pos.NewPath != "internal/auth/login.go" || pos.NewLine != 12 {
+		t.Fatalf("inline position = %+v", pos)
+	}
+	if pos.BaseSHA != "forksha" || pos.StartSHA != "basesha" || pos.HeadSHA != "deadbeef" {
+		t.Errorf("diff refs = %+v", pos.diffRefs)
+	}
+}
+
+func TestClient_Publish_KeepsMergeRequestOnStatusFailure(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["glab mr create"] = "https://gitlab.com/acme/app/-/merge_requests/2"
+	runner.fail["glab api"] = true
+	client := NewClientWithExec("/repo", runner)
+
+	mr, err := client.Publish(context.Background(), remote.Submission{
+		Base:   "main",
+		Branch: "b",
+		Checks: []remote.CheckRun{{Name: "alphie/validation"}},
+	})
+	if mr == nil || mr.Number != 2 {
+		t.Fatalf("Publish() mr = %v, want merge request 2", mr)
+	}
+	if err == nil || !strings.Contains(err.Error(), "report check alphie/validation") {
+		t.Errorf("Publish() error = %v, want the status failure", err)
+	}
+}
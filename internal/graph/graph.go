@@ -239,6 +239,16 @@ func (g *DependencyGraph) MarkComplete(taskID string) {
 	g.debugLog("[graph.MarkComplete] completed map now: %v", g.completed)
 }
 
+// MarkIncomplete clears a task's completed mark so it becomes schedulable again.
+// Used when a finished task must be re-executed (e.g. its merge was abandoned).
+func (g *DependencyGraph) MarkIncomplete(taskID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.debugLog("[graph.MarkIncomplete] clearing completed mark for task %s", taskID)
+	delete(g.completed, taskID)
+}
+
 // GetTask returns the task for a given ID, or nil if not found.
 func (g *DependencyGraph) GetTask(taskID string) *models.Task {
 	g.mu.RLock()
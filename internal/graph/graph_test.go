@@ -276,6 +276,27 @@ func TestGraphGetReadyAfterMarkComplete(t *testing.T) {
 	}
 }
 
+func TestGraphMarkIncomplete(t *testing.T) {
+	g := New()
+	tasks := []*models.Task{
+		{ID: "A", Title: "Task A", Status: models.TaskStatusPending},
+	}
+
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g.MarkComplete("A")
+	if ready := g.GetReady(); len(ready) != 0 {
+		t.Fatalf("expected no ready tasks after A complete, got %v", ready)
+	}
+
+	g.MarkIncomplete("A")
+	if ready := g.GetReady(); len(ready) != 1 || ready[0] != "A" {
+		t.Errorf("expected A to be ready again after MarkIncomplete, got %v", ready)
+	}
+}
+
 func TestGraphGetReadyMultiple(t *testing.T) {
 	// A (no deps), B (no deps), C (depends on A and B)
 	g := New()
@@ -312,17 +312,7 @@ func (c *Capturer) ConfirmAndStore(sl *SuggestedLearning, conceptNames []string)
 		return nil, nil
 	}
 
-	// Set outcome type to failure since this came from a failure
-	learning, err := c.system.AddLearning(sl.CAO, conceptNames)
-	if err != nil {
-		return nil, err
-	}
-
-	// Update outcome type to failure
-	learning.OutcomeType = "failure"
-	if err := c.system.UpdateLearning(learning); err != nil {
-		return nil, err
-	}
-
-	return learning, nil
+	// Suggestions describe the fix for a failure, not the failure itself,
+	// so the outcome type comes from the RESULT clause like any other learning.
+	return c.system.AddLearning(sl.CAO, conceptNames)
 }
@@ -0,0 +1,488 @@
+package learning
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConceptNode is a concept in the taxonomy, with the other names it goes
+// by and the broader concept it belongs to.
+type ConceptNode struct {
+	// Name is the concept's canonical name.
+	Name string
+	// Summary describes the concept.
+	Summary string
+	// Parent is the name of the broader concept, or "" for a root concept.
+	Parent string
+	// Aliases are other terms that mean this concept.
+	Aliases []string
+	// Builtin is true for concepts Alphie defines. They can be given
+	// aliases and parents but not removed.
+	Builtin bool
+}
+
+// builtinConcepts are the concepts every taxonomy starts with.
+var builtinConcepts = []ConceptNode{
+	{Name: "testing", Aliases: []string{"test"}},
+	{Name: "debugging", Aliases: []string{"debug"}},
+	{Name: "bug-fix", Aliases: []string{"fix"}},
+	{Name: "implementation", Aliases: []string{"implement"}},
+	{Name: "refactoring", Aliases: []string{"refactor"}},
+	{Name: "configuration", Aliases: []string{"config"}},
+	{Name: "setup"},
+	{Name: "api"},
+	{Name: "database"},
+	{Name: "frontend"},
+	{Name: "backend"},
+	{Name: "security"},
+	{Name: "performance"},
+	{Name: "documentation", Aliases: []string{"doc"}},
+}
+
+// ConceptRegistry manages the concept taxonomy: user-defined concepts,
+// their aliases and their hierarchy, stored in the learning database on
+// top of the built-in concepts. It tags text with concepts and expands
+// queries to the concepts they cover.
+type ConceptRegistry struct {
+	store *LearningStore
+}
+
+// NewConceptRegistry creates a ConceptRegistry backed by the given store.
+func NewConceptRegistry(store *LearningStore) *ConceptRegistry {
+	return &ConceptRegistry{store: store}
+}
+
+// Compile-time verification that ConceptRegistry can tag and expand.
+var (
+	_ ConceptMatcher  = (*ConceptRegistry)(nil)
+	_ ConceptExpander = (*ConceptRegistry)(nil)
+)
+
+// Define adds a concept, or updates the summary of an existing one.
+func (r *ConceptRegistry) Define(name, summary string) error {
+	name = normalizeConceptName(name)
+	if name == "" {
+		return fmt.Errorf("concept name is required")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	if owner, ok := tax.terms[normalizeTerm(name)]; ok && owner != name {
+		return fmt.Errorf("%q is already an alias of concept %q", name, owner)
+	}
+	id, err := r.ensureConceptLocked(name)
+	if err != nil {
+		return err
+	}
+	if summary != "" {
+		if _, err := r.store.db.Exec("UPDATE concepts SET description = ? WHERE id = ?", summary, id); err != nil {
+			return fmt.Errorf("update concept: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetParent makes parent the broader concept of name. An empty parent
+// makes name a root concept. Either may be given by alias.
+func (r *ConceptRegistry) SetParent(name, parent string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	node, err := tax.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	var parentID sql.NullString
+	if parent != "" {
+		parentNode, err := tax.lookup(parent)
+		if err != nil {
+			return err
+		}
+		for n := parentNode; n != nil; n = tax.nodes[n.Parent] {
+			if n.Name == node.Name {
+				return fmt.Errorf("%q cannot be a parent of %q: %q is already one of its ancestors", parentNode.Name, node.Name, node.Name)
+			}
+		}
+		id, err := r.ensureConceptLocked(parentNode.Name)
+		if err != nil {
+			return err
+		}
+		parentID = sql.NullString{String: id, Valid: true}
+	}
+
+	id, err := r.ensureConceptLocked(node.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.store.db.Exec("UPDATE concepts SET parent_id = ? WHERE id = ?", parentID, id); err != nil {
+		return fmt.Errorf("set concept parent: %w", err)
+	}
+	return nil
+}
+
+// AddAlias makes alias another term for the concept name.
+func (r *ConceptRegistry) AddAlias(name, alias string) error {
+	alias = normalizeAlias(alias)
+	if alias == "" {
+		return fmt.Errorf("alias is required")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	node, err := tax.lookup(name)
+	if err != nil {
+		return err
+	}
+	if owner, ok := tax.terms[normalizeTerm(alias)]; ok {
+		if owner == node.Name {
+			return nil
+		}
+		return fmt.Errorf("%q already means concept %q", alias, owner)
+	}
+
+	id, err := r.ensureConceptLocked(node.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.store.db.Exec("INSERT INTO concept_aliases (alias, concept_id) VALUES (?, ?)", alias, id); err != nil {
+		return fmt.Errorf("add concept alias: %w", err)
+	}
+	return nil
+}
+
+// RemoveAlias removes a user-defined alias. Built-in aliases cannot be removed.
+func (r *ConceptRegistry) RemoveAlias(alias string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result, err := r.store.db.Exec("DELETE FROM concept_aliases WHERE alias = ?", normalizeAlias(alias))
+	if err != nil {
+		return fmt.Errorf("remove concept alias: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("alias not found: %s", alias)
+	}
+	return nil
+}
+
+// Remove deletes a user-defined concept with its aliases and learning
+// links. Its child concepts move up to its parent.
+func (r *ConceptRegistry) Remove(name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	node, err := tax.lookup(name)
+	if err != nil {
+		return err
+	}
+	if node.Builtin {
+		return fmt.Errorf("built-in concept %q cannot be removed", node.Name)
+	}
+
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id string
+	var parentID sql.NullString
+	if err := tx.QueryRow("SELECT id, parent_id FROM concepts WHERE name = ?", node.Name).Scan(&id, &parentID); err != nil {
+		return fmt.Errorf("query concept: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE concepts SET parent_id = ? WHERE parent_id = ?", parentID, id); err != nil {
+		return fmt.Errorf("reparent child concepts: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM concepts WHERE id = ?", id); err != nil {
+		return fmt.Errorf("delete concept: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Resolve returns the concept a term names, by name or alias, or nil if
+// the term is not in the taxonomy.
+func (r *ConceptRegistry) Resolve(term string) (*ConceptNode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return nil, err
+	}
+	name, ok := tax.terms[normalizeTerm(term)]
+	if !ok {
+		return nil, nil
+	}
+	node := *tax.nodes[name]
+	return &node, nil
+}
+
+// Taxonomy returns every concept, ordered by name.
+func (r *ConceptRegistry) Taxonomy() ([]ConceptNode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]ConceptNode, 0, len(tax.order))
+	for _, name := range tax.order {
+		nodes = append(nodes, *tax.nodes[name])
+	}
+	return nodes, nil
+}
+
+// Expand returns the terms followed by every term they cover: for a term
+// naming a concept, the concept's name and aliases and those of all its
+// descendants. A query for "security" also matches learnings about its
+// child concept "auth". Terms outside the taxonomy are kept as they are.
+func (r *ConceptRegistry) Expand(terms []string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if key := normalizeTerm(term); key != "" && !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, term)
+		}
+	}
+	for _, term := range terms {
+		add(term)
+		name, ok := tax.terms[normalizeTerm(term)]
+		if !ok {
+			continue
+		}
+		for _, n := range tax.subtree(name) {
+			add(n.Name)
+			for _, alias := range n.Aliases {
+				add(alias)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// MatchConcepts returns the names of the concepts text mentions by name or
+// alias, ordered by name. A term matches at the start of a word, so "test"
+// matches "tests" and "testing".
+func (r *ConceptRegistry) MatchConcepts(text string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tax, err := r.loadLocked()
+	if err != nil {
+		return nil, err
+	}
+	return tax.match(text), nil
+}
+
+// ensureConceptLocked returns the ID of the stored concept named name,
+// storing it first if it is only built in. The caller holds the store lock.
+func (r *ConceptRegistry) ensureConceptLocked(name string) (string, error) {
+	var id string
+	err := r.store.db.QueryRow("SELECT id FROM concepts WHERE name = ?", name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("query concept: %w", err)
+	}
+
+	id = generateConceptID()
+	if _, err := r.store.db.Exec(`
+		INSERT INTO concepts (id, name, created_at) VALUES (?, ?, ?)
+	`, id, name, formatTime(time.Now())); err != nil {
+		return "", fmt.Errorf("insert concept: %w", err)
+	}
+	return id, nil
+}
+
+// loadLocked reads the taxonomy: the built-in concepts overlaid with the
+// stored ones. The caller holds the store lock.
+func (r *ConceptRegistry) loadLocked() (*taxonomy, error) {
+	tax := newBuiltinTaxonomy()
+
+	rows, err := r.store.db.Query(`
+		SELECT c.name, c.description, p.name
+		FROM concepts c LEFT JOIN concepts p ON c.parent_id = p.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("load concepts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var summary, parent sql.NullString
+		if err := rows.Scan(&name, &summary, &parent); err != nil {
+			return nil, fmt.Errorf("scan concept: %w", err)
+		}
+		node, ok := tax.nodes[name]
+		if !ok {
+			node = &ConceptNode{Name: name}
+			tax.nodes[name] = node
+		}
+		if summary.String != "" {
+			node.Summary = summary.String
+		}
+		node.Parent = parent.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate concepts: %w", err)
+	}
+
+	aliasRows, err := r.store.db.Query(`
+		SELECT a.alias, c.name
+		FROM concept_aliases a JOIN concepts c ON a.concept_id = c.id
+		ORDER BY a.alias
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("load concept aliases: %w", err)
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var alias, name string
+		if err := aliasRows.Scan(&alias, &name); err != nil {
+			return nil, fmt.Errorf("scan concept alias: %w", err)
+		}
+		tax.nodes[name].Aliases = append(tax.nodes[name].Aliases, alias)
+	}
+	if err := aliasRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate concept aliases: %w", err)
+	}
+
+	tax.index()
+	return tax, nil
+}
+
+// MatchBuiltinConcepts returns the names of the built-in concepts text
+// mentions, for callers without a concept registry.
+func MatchBuiltinConcepts(text string) []string {
+	tax := newBuiltinTaxonomy()
+	tax.index()
+	return tax.match(text)
+}
+
+// taxonomy is a snapshot of the concept taxonomy.
+type taxonomy struct {
+	// nodes are the concepts by name.
+	nodes map[string]*ConceptNode
+	// terms maps each normalized name and alias to its concept's name.
+	terms map[string]string
+	// order is the concept names, sorted.
+	order []string
+}
+
+// newBuiltinTaxonomy returns the built-in concepts, not yet indexed.
+func newBuiltinTaxonomy() *taxonomy {
+	tax := &taxonomy{nodes: make(map[string]*ConceptNode)}
+	for _, b := range builtinConcepts {
+		node := b
+		node.Aliases = append([]string(nil), b.Aliases...)
+		node.Builtin = true
+		tax.nodes[node.Name] = &node
+	}
+	return tax
+}
+
+// index builds the term lookup and name order once the nodes are loaded.
+func (t *taxonomy) index() {
+	t.terms = make(map[string]string)
+	t.order = t.order[:0]
+	for name, node := range t.nodes {
+		t.order = append(t.order, name)
+		t.terms[normalizeTerm(name)] = name
+		for _, alias := range node.Aliases {
+			t.terms[normalizeTerm(alias)] = name
+		}
+	}
+	sort.Strings(t.order)
+}
+
+// lookup returns the concept a term names, or an error if there is none.
+func (t *taxonomy) lookup(term string) (*ConceptNode, error) {
+	name, ok := t.terms[normalizeTerm(term)]
+	if !ok {
+		return nil, fmt.Errorf("concept not found: %s", term)
+	}
+	return t.nodes[name], nil
+}
+
+// subtree returns the named concept followed by all its descendants.
+func (t *taxonomy) subtree(name string) []*ConceptNode {
+	nodes := []*ConceptNode{t.nodes[name]}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range t.order {
+			if t.nodes[child].Parent == nodes[i].Name {
+				nodes = append(nodes, t.nodes[child])
+			}
+		}
+	}
+	return nodes
+}
+
+// match returns the names of the concepts text mentions.
+func (t *taxonomy) match(text string) []string {
+	padded := " " + normalizeTerm(text)
+	var matched []string
+	for _, name := range t.order {
+		node := t.nodes[name]
+		for _, term := range append([]string{node.Name}, node.Aliases...) {
+			if key := normalizeTerm(term); key != "" && strings.Contains(padded, " "+key) {
+				matched = append(matched, name)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// normalizeConceptName lowercases a concept name and joins its words with
+// hyphens.
+func normalizeConceptName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// normalizeAlias lowercases an alias and collapses its whitespace.
+func normalizeAlias(alias string) string {
+	return strings.Join(strings.Fields(strings.ToLower(alias)), " ")
+}
+
+// normalizeTerm reduces a term to its lowercase words separated by single
+// spaces, so "Bug-Fix" and "bug fix" compare equal.
+func normalizeTerm(term string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(term), func(c rune) bool {
+		return !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'))
+	}), " ")
+}
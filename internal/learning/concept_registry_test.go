@@ -0,0 +1,155 @@
+package learning
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConceptRegistry_Builtins(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	r := NewConceptRegistry(store)
+
+	nodes, err := r.Taxonomy()
+	if err != nil {
+		t.Fatalf("Taxonomy() error = %v", err)
+	}
+	if len(nodes) != len(builtinConcepts) {
+		t.Errorf("Taxonomy() has %d concepts, want the %d built-in ones", len(nodes), len(builtinConcepts))
+	}
+
+	got, err := r.MatchConcepts("Fix the flaky tests in the API client")
+	if err != nil {
+		t.Fatalf("MatchConcepts() error = %v", err)
+	}
+	if want := []string{"api", "bug-fix", "testing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchConcepts() = %v, want %v", got, want)
+	}
+	if got := MatchBuiltinConcepts("Fix the flaky tests in the API client"); !reflect.DeepEqual(got, []string{"api", "bug-fix", "testing"}) {
+		t.Errorf("MatchBuiltinConcepts() = %v", got)
+	}
+
+	if err := r.Remove("testing"); err == nil {
+		t.Error("expected removing a built-in concept to fail")
+	}
+}
+
+func TestConceptRegistry_AliasesAndHierarchy(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	r := NewConceptRegistry(store)
+
+	if err := r.Define("Auth", "Authentication and sessions"); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	if err := r.AddAlias("auth", "Sign In"); err != nil {
+		t.Fatalf("AddAlias() error = %v", err)
+	}
+	if err := r.AddAlias("auth", "login"); err != nil {
+		t.Fatalf("AddAlias() error = %v", err)
+	}
+	if err := r.AddAlias("testing", "login"); err == nil {
+		t.Error("expected an alias already used by another concept to be rejected")
+	}
+	if err := r.SetParent("auth", "security"); err != nil {
+		t.Fatalf("SetParent() error = %v", err)
+	}
+	if err := r.SetParent("security", "auth"); err == nil {
+		t.Error("expected a cycle in the hierarchy to be rejected")
+	}
+
+	node, err := r.Resolve("sign-in")
+	if err != nil || node == nil {
+		t.Fatalf("Resolve() = %v, %v, want auth", node, err)
+	}
+	if node.Name != "auth" || node.Parent != "security" || node.Summary != "Authentication and sessions" || node.Builtin {
+		t.Errorf("Resolve() = %+v", node)
+	}
+
+	got, err := r.MatchConcepts("Users cannot sign in after a password reset")
+	if err != nil || !reflect.DeepEqual(got, []string{"auth"}) {
+		t.Errorf("MatchConcepts() = %v, %v, want [auth]", got, err)
+	}
+
+	// A query for a concept covers its aliases and sub-concepts
+	expanded, err := r.Expand([]string{"security", "cache"})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := []string{"security", "auth", "login", "sign in", "cache"}; !reflect.DeepEqual(expanded, want) {
+		t.Errorf("Expand() = %v, want %v", expanded, want)
+	}
+
+	// Removing a concept moves its children up to its parent
+	if err := r.Define("oauth", ""); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	if err := r.SetParent("oauth", "login"); err != nil {
+		t.Fatalf("SetParent() by alias error = %v", err)
+	}
+	if err := r.Remove("auth"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if node, _ := r.Resolve("oauth"); node == nil || node.Parent != "security" {
+		t.Errorf("after removing its parent, oauth = %+v, want parent security", node)
+	}
+	if node, _ := r.Resolve("login"); node != nil {
+		t.Errorf("expected the removed concept's aliases to go with it, got %+v", node)
+	}
+
+	if err := r.RemoveAlias("login"); err == nil {
+		t.Error("expected removing an unknown alias to fail")
+	}
+}
+
+func TestRetriever_ConceptExpandedQuery(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	r := NewConceptRegistry(store)
+	if err := r.Define("auth", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetParent("auth", "security"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("auth", "bug-bounty"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range []*Learning{
+		{ID: "l-auth", Condition: "auth tokens expire", Action: "refresh them", Outcome: "success", Scope: "repo", OutcomeType: "success", CreatedAt: time.Now()},
+		{ID: "l-bounty", Condition: "bug bounty report", Action: "triage it", Outcome: "success", Scope: "repo", OutcomeType: "success", CreatedAt: time.Now()},
+		{ID: "l-other", Condition: "slow queries", Action: "add an index", Outcome: "success", Scope: "repo", OutcomeType: "success", CreatedAt: time.Now()},
+	} {
+		if err := store.Create(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retriever := NewRetriever(store)
+	if results, _ := retriever.RetrieveForTask("harden security", nil); len(results) != 0 {
+		t.Errorf("without expansion, expected no learnings, got %d", len(results))
+	}
+
+	retriever.SetConceptExpander(r)
+	results, err := retriever.RetrieveForTask("harden security", nil)
+	if err != nil {
+		t.Fatalf("RetrieveForTask() error = %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, l := range results {
+		ids[l.ID] = true
+	}
+	if !ids["l-auth"] || !ids["l-bounty"] || ids["l-other"] {
+		t.Errorf("expected the auth learnings for a security task, got %v", ids)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	got := FTSQuery([]string{"auth", "sign in", "bug-fix", `say "hi"`})
+	want := `auth OR "sign in" OR "bug-fix" OR "say ""hi"""`
+	if got != want {
+		t.Errorf("FTSQuery() = %s, want %s", got, want)
+	}
+}
@@ -22,9 +22,22 @@ type LearningProvider interface {
 	// to signal the caller may want to create a new learning.
 	OnFailure(errorMessage string) ([]*Learning, error)
 
+	// RecordFailure stores an anti-pattern: an action that was tried under
+	// condition and failed. Agents are told to avoid it in similar tasks.
+	RecordFailure(condition, action, detail string) (*Learning, error)
+
 	// Close closes the learning system and releases all resources.
 	Close() error
 }
 
+// ConceptMatcher tags text with the concepts of the taxonomy it mentions.
+type ConceptMatcher interface {
+	// MatchConcepts returns the names of the concepts text is about.
+	MatchConcepts(text string) ([]string, error)
+}
+
 // Verify LearningSystem implements LearningProvider at compile time.
-var _ LearningProvider = (*LearningSystem)(nil)
+var (
+	_ LearningProvider = (*LearningSystem)(nil)
+	_ ConceptMatcher   = (*LearningSystem)(nil)
+)
@@ -0,0 +1,56 @@
+// Package learning provides learning and context management capabilities.
+package learning
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Outcome types for learnings.
+const (
+	// OutcomeSuccess marks a learning whose action led to the desired result.
+	OutcomeSuccess = "success"
+	// OutcomeFailure marks an anti-pattern: an action that was tried and failed.
+	OutcomeFailure = "failure"
+	// OutcomeNeutral marks a learning with no clear success or failure.
+	OutcomeNeutral = "neutral"
+)
+
+// failureOutcomePattern matches RESULT clauses describing a failure.
+var failureOutcomePattern = regexp.MustCompile(`(?i)^(fail|failure|failed|fails|broke|breaks|broken|errored|regress|regression|worse|no effect|did not work|didn't work|does not work|doesn't work)\b`)
+
+// successOutcomePattern matches RESULT clauses describing a success.
+var successOutcomePattern = regexp.MustCompile(`(?i)\b(pass|passes|passed|succeeds|succeeded|success|works|fixed|resolved|faster|consistent)\b`)
+
+// ClassifyOutcome infers the outcome type of a RESULT clause. Outcomes that
+// start with a failure word ("failure", "fails: ...", "broke the build") are
+// failures; outcomes mentioning success are successes; the rest are neutral.
+func ClassifyOutcome(outcome string) string {
+	outcome = strings.TrimSpace(outcome)
+	switch {
+	case failureOutcomePattern.MatchString(outcome):
+		return OutcomeFailure
+	case successOutcomePattern.MatchString(outcome):
+		return OutcomeSuccess
+	default:
+		return OutcomeNeutral
+	}
+}
+
+// IsAntiPattern returns true if the learning records an action to avoid.
+func (l *Learning) IsAntiPattern() bool {
+	return l.OutcomeType == OutcomeFailure
+}
+
+// SplitByOutcome separates learnings into guidance to follow and
+// anti-patterns to avoid, preserving order.
+func SplitByOutcome(learnings []*Learning) (follow, avoid []*Learning) {
+	for _, l := range learnings {
+		if l.IsAntiPattern() {
+			avoid = append(avoid, l)
+		} else {
+			follow = append(follow, l)
+		}
+	}
+	return follow, avoid
+}
@@ -0,0 +1,119 @@
+package learning
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	tests := []struct {
+		outcome string
+		want    string
+	}{
+		{"build succeeds", OutcomeSuccess},
+		{"tests pass", OutcomeSuccess},
+		{"failure: tests still red", OutcomeFailure},
+		{"Broke the build", OutcomeFailure},
+		{"did not work", OutcomeFailure},
+		{"errored out on startup", OutcomeFailure},
+		{"error handling is consistent", OutcomeSuccess},
+		{"reduced token usage", OutcomeNeutral},
+		{"", OutcomeNeutral},
+	}
+	for _, tt := range tests {
+		if got := ClassifyOutcome(tt.outcome); got != tt.want {
+			t.Errorf("ClassifyOutcome(%q) = %s, want %s", tt.outcome, got, tt.want)
+		}
+	}
+}
+
+func TestSplitByOutcome(t *testing.T) {
+	learnings := []*Learning{
+		{ID: "a", OutcomeType: OutcomeSuccess},
+		{ID: "b", OutcomeType: OutcomeFailure},
+		{ID: "c", OutcomeType: OutcomeNeutral},
+		{ID: "d", OutcomeType: OutcomeFailure},
+	}
+	follow, avoid := SplitByOutcome(learnings)
+	if len(follow) != 2 || follow[0].ID != "a" || follow[1].ID != "c" {
+		t.Errorf("follow = %v", follow)
+	}
+	if len(avoid) != 2 || avoid[0].ID != "b" || avoid[1].ID != "d" {
+		t.Errorf("avoid = %v", avoid)
+	}
+}
+
+func TestLearningSystem_RecordFailure(t *testing.T) {
+	ls, err := NewLearningSystem(filepath.Join(t.TempDir(), "learnings.db"))
+	if err != nil {
+		t.Fatalf("NewLearningSystem() error = %v", err)
+	}
+	defer ls.Close()
+
+	first, err := ls.RecordFailure("working on auth middleware", "mock the token store globally", "verification failure: race detected")
+	if err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	if !first.IsAntiPattern() || first.Outcome != "failure: verification failure: race detected" {
+		t.Errorf("learning = %+v", first)
+	}
+
+	again, err := ls.RecordFailure("working on auth middleware", "mock the token store globally", "execution failure")
+	if err != nil {
+		t.Fatalf("RecordFailure() repeat error = %v", err)
+	}
+	if again.ID != first.ID || again.TriggerCount != 1 {
+		t.Errorf("repeat = %+v, want same ID with trigger count 1", again)
+	}
+
+	stored, err := ls.GetStore().Get(first.ID)
+	if err != nil || stored == nil || stored.TriggerCount != 1 {
+		t.Errorf("stored = %+v, err = %v", stored, err)
+	}
+
+	if _, err := ls.RecordFailure("", "x", ""); err == nil {
+		t.Error("expected error for empty condition")
+	}
+}
+
+func TestRetriever_RetrieveForTask_AntiPatternQuota(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	for i := 0; i < 8; i++ {
+		outcomeType := OutcomeSuccess
+		if i%2 == 1 {
+			outcomeType = OutcomeFailure
+		}
+		l := &Learning{
+			ID:           "q-" + string(rune('a'+i)),
+			Condition:    "error in component",
+			Action:       "change component",
+			Outcome:      "component result",
+			Scope:        "repo",
+			OutcomeType:  outcomeType,
+			TriggerCount: i,
+			CreatedAt:    now,
+		}
+		if err := store.Create(l); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	results, err := NewRetriever(store).RetrieveForTask("error component", nil)
+	if err != nil {
+		t.Fatalf("RetrieveForTask() error = %v", err)
+	}
+	follow, avoid := SplitByOutcome(results)
+	if len(follow) != 4 || len(avoid) != maxTaskAntiPatterns {
+		t.Errorf("got %d follow and %d avoid, want 4 and %d", len(follow), len(avoid), maxTaskAntiPatterns)
+	}
+	// Anti-patterns come after the guidance learnings
+	for i, l := range results {
+		if l.IsAntiPattern() != (i >= len(follow)) {
+			t.Errorf("result %d (%s) out of order", i, l.ID)
+		}
+	}
+}
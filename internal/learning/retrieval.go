@@ -32,9 +32,25 @@ type RetrievalStore interface {
 	IncrementTriggerCount(id string) error
 }
 
+// ConceptExpander widens query terms to the concepts they cover, such as a
+// concept's aliases and sub-concepts. This is implemented by *ConceptRegistry.
+type ConceptExpander interface {
+	// Expand returns the terms followed by the terms they cover.
+	Expand(terms []string) ([]string, error)
+}
+
+const (
+	// maxTaskLearnings is the number of success and neutral learnings returned per task.
+	maxTaskLearnings = 5
+	// maxTaskAntiPatterns is the number of failure learnings returned per task.
+	maxTaskAntiPatterns = 3
+)
+
 // Retriever queries and ranks learnings for relevance to tasks and errors.
 type Retriever struct {
 	store RetrievalStore
+	// concepts, if set, expands task keywords through the concept taxonomy.
+	concepts ConceptExpander
 	// Fields used during ranking to enable BM25 scoring
 	queryTerms []string
 	avgDocLen  float64
@@ -47,10 +63,17 @@ func NewRetriever(store RetrievalStore) *Retriever {
 	return &Retriever{store: store}
 }
 
+// SetConceptExpander expands task keywords through the concept taxonomy,
+// so a task about "security" also finds learnings about "auth".
+func (r *Retriever) SetConceptExpander(concepts ConceptExpander) {
+	r.concepts = concepts
+}
+
 // RetrieveForTask retrieves learnings relevant to a task (from all scopes).
 // It extracts keywords from the task description, searches by keywords,
 // ranks results by trigger count and recency, and returns
-// the top 5 most relevant learnings.
+// the top 5 most relevant learnings followed by up to 3 anti-patterns
+// (failure learnings).
 func (r *Retriever) RetrieveForTask(taskDescription string, filePaths []string) ([]*Learning, error) {
 	return r.RetrieveForTaskWithScope(taskDescription, filePaths, nil)
 }
@@ -65,13 +88,21 @@ func (r *Retriever) RetrieveForTaskWithScope(taskDescription string, filePaths [
 
 	seen := make(map[string]*Learning)
 
-	// Step 1: Extract keywords from task description
+	// Step 1: Extract keywords from task description, expanded to the
+	// concepts they cover
 	keywords := r.extractKeywords(taskDescription)
+	queryText := taskDescription
+	if r.concepts != nil && len(keywords) > 0 {
+		if expanded, err := r.concepts.Expand(keywords); err == nil {
+			keywords = expanded
+			queryText = strings.Join(expanded, " ")
+		}
+	}
 
 	// Step 2: Search learnings by keywords (joined as query)
 	if len(keywords) > 0 {
 		// Join keywords with OR for FTS5 query
-		query := strings.Join(keywords, " OR ")
+		query := FTSQuery(keywords)
 		var results []*Learning
 		var err error
 		if len(scopes) > 0 {
@@ -114,17 +145,22 @@ func (r *Retriever) RetrieveForTaskWithScope(taskDescription string, filePaths [
 	}
 
 	// Step 4: Compute BM25 corpus stats and rank by relevance
-	r.queryTerms = tokenize(strings.ToLower(taskDescription))
+	r.queryTerms = tokenize(strings.ToLower(queryText))
 	r.avgDocLen, r.docFreqs = computeCorpusStats(learnings)
 	r.totalDocs = len(learnings)
 	r.rankLearnings(learnings)
 
-	// Step 5: Return top 5 most relevant
-	if len(learnings) > 5 {
-		learnings = learnings[:5]
+	// Step 5: Return the top guidance learnings plus the top anti-patterns.
+	// Anti-patterns get their own quota so they are not crowded out.
+	follow, avoid := SplitByOutcome(learnings)
+	if len(follow) > maxTaskLearnings {
+		follow = follow[:maxTaskLearnings]
+	}
+	if len(avoid) > maxTaskAntiPatterns {
+		avoid = avoid[:maxTaskAntiPatterns]
 	}
 
-	return learnings, nil
+	return append(follow, avoid...), nil
 }
 
 // RetrieveForError retrieves learnings relevant to an error message (from all scopes).
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
 )
 
-// Create inserts a new learning into the store.
+// Create inserts a new learning into the store. Secrets in its condition,
+// action and outcome are redacted.
 func (s *LearningStore) Create(learning *Learning) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -26,9 +29,9 @@ func (s *LearningStore) Create(learning *Learning) error {
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		learning.ID,
-		learning.Condition,
-		learning.Action,
-		learning.Outcome,
+		redact.String(learning.Condition),
+		redact.String(learning.Action),
+		redact.String(learning.Outcome),
 		nullString(learning.CommitHash),
 		nullString(learning.LogSnippetID),
 		learning.Scope,
@@ -99,7 +102,7 @@ func (s *LearningStore) Get(id string) (*Learning, error) {
 	return &learning, nil
 }
 
-// Update updates an existing learning in the store.
+// Update updates an existing learning in the store, redacting it like Create.
 func (s *LearningStore) Update(learning *Learning) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -125,9 +128,9 @@ func (s *LearningStore) Update(learning *Learning) error {
 			outcome_type = ?
 		WHERE id = ?
 	`,
-		learning.Condition,
-		learning.Action,
-		learning.Outcome,
+		redact.String(learning.Condition),
+		redact.String(learning.Action),
+		redact.String(learning.Outcome),
 		nullString(learning.CommitHash),
 		nullString(learning.LogSnippetID),
 		learning.Scope,
@@ -32,6 +32,7 @@ func (s *LearningStore) Migrate() error {
 		{1, migrationV1Learnings},
 		{2, migrationV2Concepts},
 		{3, migrationV3Effectiveness},
+		{4, migrationV4ConceptTaxonomy},
 	}
 
 	for _, m := range migrations {
@@ -153,3 +154,16 @@ CREATE INDEX IF NOT EXISTS idx_task_outcomes_session ON task_outcomes(session_id
 CREATE INDEX IF NOT EXISTS idx_task_outcomes_outcome ON task_outcomes(outcome);
 CREATE INDEX IF NOT EXISTS idx_task_outcomes_created_at ON task_outcomes(created_at);
 `
+
+const migrationV4ConceptTaxonomy = `
+-- A concept's parent is the broader concept it belongs to (auth -> security)
+ALTER TABLE concepts ADD COLUMN parent_id TEXT REFERENCES concepts(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS concept_aliases (
+	alias TEXT PRIMARY KEY,
+	concept_id TEXT NOT NULL,
+	FOREIGN KEY (concept_id) REFERENCES concepts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_concept_aliases_concept ON concept_aliases(concept_id);
+`
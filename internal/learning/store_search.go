@@ -286,3 +286,18 @@ func scanLearnings(rows *sql.Rows) ([]*Learning, error) {
 
 	return learnings, nil
 }
+
+// FTSQuery builds a full-text query matching any of terms. Terms that are
+// not a single word, such as "bug-fix", are matched as phrases.
+func FTSQuery(terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if strings.IndexFunc(term, func(c rune) bool {
+			return !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'))
+		}) >= 0 {
+			term = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		}
+		quoted = append(quoted, term)
+	}
+	return strings.Join(quoted, " OR ")
+}
@@ -0,0 +1,212 @@
+// Package learning provides learning and context management capabilities.
+package learning
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxImportLine is the longest JSONL line Import accepts.
+const maxImportLine = 1 << 20
+
+// exportRecord is the JSONL representation of a learning used by Export and Import.
+type exportRecord struct {
+	ID            string     `json:"id"`
+	Hash          string     `json:"hash"`
+	Condition     string     `json:"condition"`
+	Action        string     `json:"action"`
+	Outcome       string     `json:"outcome"`
+	CommitHash    string     `json:"commit_hash,omitempty"`
+	Scope         string     `json:"scope"`
+	TTLSeconds    int64      `json:"ttl_seconds,omitempty"`
+	LastTriggered *time.Time `json:"last_triggered,omitempty"`
+	TriggerCount  int        `json:"trigger_count"`
+	OutcomeType   string     `json:"outcome_type"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ImportResult summarizes an Import.
+type ImportResult struct {
+	// Added is the number of new learnings created.
+	Added int
+	// Merged is the number of records merged into an existing learning
+	// with the same condition and action.
+	Merged int
+	// Skipped is the number of records that were invalid or already up to date.
+	Skipped int
+}
+
+// LearningHash returns the dedupe key of a learning: a hash of its condition
+// and action, ignoring case and whitespace differences.
+func LearningHash(condition, action string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+	sum := sha256.Sum256([]byte(normalize(condition) + "\x00" + normalize(action)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Export writes every learning to w as JSON lines, oldest first.
+// Returns the number of learnings written.
+func (s *LearningStore) Export(w io.Writer) (int, error) {
+	// A negative LIMIT means no limit in SQLite
+	learnings, err := s.List(-1)
+	if err != nil {
+		return 0, fmt.Errorf("export learnings: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	for i := len(learnings) - 1; i >= 0; i-- {
+		if err := enc.Encode(toExportRecord(learnings[i])); err != nil {
+			return len(learnings) - 1 - i, fmt.Errorf("write learning %s: %w", learnings[i].ID, err)
+		}
+	}
+	return len(learnings), nil
+}
+
+// Import reads JSON lines written by Export and merges them into the store.
+// Records are deduplicated by LearningHash: a record matching an existing
+// learning is merged into it (highest trigger count, latest trigger time)
+// instead of being added, so importing the same
+// file twice is a no-op. New records keep their ID unless it is taken.
+func (s *LearningStore) Import(r io.Reader) (*ImportResult, error) {
+	existing, err := s.List(-1)
+	if err != nil {
+		return nil, fmt.Errorf("import learnings: %w", err)
+	}
+	byHash := make(map[string]*Learning, len(existing))
+	byID := make(map[string]bool, len(existing))
+	for _, l := range existing {
+		byHash[LearningHash(l.Condition, l.Action)] = l
+		byID[l.ID] = true
+	}
+
+	result := &ImportResult{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var rec exportRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Condition == "" || rec.Action == "" {
+			result.Skipped++
+			continue
+		}
+
+		incoming := fromExportRecord(&rec)
+		hash := LearningHash(incoming.Condition, incoming.Action)
+
+		if current, ok := byHash[hash]; ok {
+			if !mergeLearning(current, incoming) {
+				result.Skipped++
+				continue
+			}
+			if err := s.Update(current); err != nil {
+				return result, fmt.Errorf("line %d: %w", line, err)
+			}
+			result.Merged++
+			continue
+		}
+
+		if incoming.ID == "" || byID[incoming.ID] {
+			incoming.ID = fmt.Sprintf("lr-%s", uuid.New().String()[:8])
+		}
+		if err := s.Create(incoming); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		byHash[hash] = incoming
+		byID[incoming.ID] = true
+		result.Added++
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("read learnings: %w", err)
+	}
+
+	return result, nil
+}
+
+// mergeLearning folds incoming usage data into current.
+// Returns false if current already reflects everything in incoming.
+func mergeLearning(current, incoming *Learning) bool {
+	changed := false
+	if incoming.TriggerCount > current.TriggerCount {
+		current.TriggerCount = incoming.TriggerCount
+		changed = true
+	}
+	if incoming.LastTriggered.After(current.LastTriggered) {
+		current.LastTriggered = incoming.LastTriggered
+		changed = true
+	}
+	if current.CommitHash == "" && incoming.CommitHash != "" {
+		current.CommitHash = incoming.CommitHash
+		changed = true
+	}
+	return changed
+}
+
+// toExportRecord converts a learning to its export form.
+func toExportRecord(l *Learning) *exportRecord {
+	rec := &exportRecord{
+		ID:           l.ID,
+		Hash:         LearningHash(l.Condition, l.Action),
+		Condition:    l.Condition,
+		Action:       l.Action,
+		Outcome:      l.Outcome,
+		CommitHash:   l.CommitHash,
+		Scope:        l.Scope,
+		TTLSeconds:   int64(l.TTL.Seconds()),
+		TriggerCount: l.TriggerCount,
+		OutcomeType:  l.OutcomeType,
+		CreatedAt:    l.CreatedAt,
+	}
+	if !l.LastTriggered.IsZero() {
+		lt := l.LastTriggered
+		rec.LastTriggered = &lt
+	}
+	return rec
+}
+
+// fromExportRecord converts an export record to a learning, filling defaults.
+func fromExportRecord(rec *exportRecord) *Learning {
+	l := &Learning{
+		ID:           rec.ID,
+		Condition:    rec.Condition,
+		Action:       rec.Action,
+		Outcome:      rec.Outcome,
+		CommitHash:   rec.CommitHash,
+		Scope:        rec.Scope,
+		TTL:          time.Duration(rec.TTLSeconds) * time.Second,
+		TriggerCount: rec.TriggerCount,
+		OutcomeType:  rec.OutcomeType,
+		CreatedAt:    rec.CreatedAt,
+	}
+	if rec.LastTriggered != nil {
+		l.LastTriggered = *rec.LastTriggered
+	}
+	if l.Scope == "" {
+		l.Scope = "repo"
+	}
+	if l.OutcomeType == "" {
+		l.OutcomeType = "neutral"
+	}
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	return l
+}
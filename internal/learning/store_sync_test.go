@@ -0,0 +1,120 @@
+package learning
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLearningHash_Normalizes(t *testing.T) {
+	a := LearningHash("Tests  fail with TIMEOUT", "increase timeout")
+	b := LearningHash("tests fail with timeout", " increase   timeout ")
+	if a != b {
+		t.Errorf("expected case/whitespace-insensitive hash, got %s != %s", a, b)
+	}
+	if a == LearningHash("tests fail with timeout", "decrease timeout") {
+		t.Error("expected different actions to hash differently")
+	}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	src, cleanupSrc := newTestStore(t)
+	defer cleanupSrc()
+
+	now := time.Now().Truncate(time.Second)
+	for _, l := range []*Learning{
+		{ID: "lr-1", Condition: "build fails on cgo", Action: "set CGO_ENABLED=0", Outcome: "build passes", Scope: "repo", OutcomeType: "success", TriggerCount: 2, CreatedAt: now.Add(-time.Hour)},
+		{ID: "lr-2", Condition: "flaky e2e", Action: "retry once", Outcome: "stable CI", Scope: "global", OutcomeType: "neutral", CreatedAt: now},
+	} {
+		if err := src.Create(l); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	n, err := src.Export(&buf)
+	if err != nil || n != 2 {
+		t.Fatalf("Export() = %d, %v; want 2, nil", n, err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("expected 2 JSONL lines, got %d:\n%s", lines, buf.String())
+	}
+
+	dst, cleanupDst := newTestStore(t)
+	defer cleanupDst()
+
+	res, err := dst.Import(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Added != 2 || res.Merged != 0 {
+		t.Errorf("first import = %+v, want 2 added", res)
+	}
+
+	got, err := dst.Get("lr-1")
+	if err != nil || got == nil {
+		t.Fatalf("Get(lr-1) = %v, %v", got, err)
+	}
+	if got.Action != "set CGO_ENABLED=0" || got.TriggerCount != 2 || got.OutcomeType != "success" {
+		t.Errorf("imported learning mismatch: %+v", got)
+	}
+
+	// Importing the same export again is a no-op
+	res, err = dst.Import(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("second Import: %v", err)
+	}
+	if res.Added != 0 || res.Merged != 0 || res.Skipped != 2 {
+		t.Errorf("second import = %+v, want 2 skipped", res)
+	}
+}
+
+func TestImport_MergesDuplicates(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.Create(&Learning{
+		ID: "lr-local", Condition: "tests fail with timeout", Action: "increase timeout",
+		Outcome: "tests pass", Scope: "repo", OutcomeType: "success", TriggerCount: 1, CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	input := strings.Join([]string{
+		// Same condition+action under a different ID and spacing: merged
+		`{"id":"lr-remote","condition":"Tests fail with  timeout","action":"increase timeout","outcome":"tests pass","trigger_count":5}`,
+		// ID collision with different content: added under a new ID
+		`{"id":"lr-local","condition":"lint fails","action":"run gofmt","outcome":"lint passes"}`,
+		// Missing action: skipped
+		`{"id":"lr-bad","condition":"something"}`,
+		"",
+	}, "\n")
+
+	res, err := store.Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.Added != 1 || res.Merged != 1 || res.Skipped != 1 {
+		t.Errorf("Import() = %+v, want 1 added, 1 merged, 1 skipped", res)
+	}
+
+	local, _ := store.Get("lr-local")
+	if local == nil || local.TriggerCount != 5 || local.Condition != "tests fail with timeout" {
+		t.Errorf("expected local learning merged with trigger count 5, got %+v", local)
+	}
+
+	all, _ := store.List(-1)
+	if len(all) != 2 {
+		t.Errorf("expected 2 learnings after import, got %d", len(all))
+	}
+}
+
+func TestImport_InvalidJSON(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if _, err := store.Import(strings.NewReader("{not json}\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected line-numbered error, got %v", err)
+	}
+}
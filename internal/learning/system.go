@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,7 @@ type LearningSystem struct {
 	retriever *Retriever
 	lifecycle *LifecycleManager
 	concepts  *ConceptManager
+	registry  *ConceptRegistry
 }
 
 // NewLearningSystem creates a new LearningSystem with all components wired together.
@@ -35,7 +37,9 @@ func NewLearningSystem(dbPath string) (*LearningSystem, error) {
 		retriever: NewRetriever(store),
 		lifecycle: NewLifecycleManager(store, 0), // Use default TTL
 		concepts:  NewConceptManager(store),
+		registry:  NewConceptRegistry(store),
 	}
+	ls.retriever.SetConceptExpander(ls.registry)
 
 	// Run cleanup of stale learnings on system init
 	if _, err := ls.lifecycle.CleanupStale(); err != nil {
@@ -60,6 +64,16 @@ func (ls *LearningSystem) GetStore() *LearningStore {
 	return ls.store
 }
 
+// Concepts returns the concept taxonomy.
+func (ls *LearningSystem) Concepts() *ConceptRegistry {
+	return ls.registry
+}
+
+// MatchConcepts returns the names of the taxonomy concepts text mentions.
+func (ls *LearningSystem) MatchConcepts(text string) ([]string, error) {
+	return ls.registry.MatchConcepts(text)
+}
+
 // OnTaskStart is called at the beginning of a task to retrieve relevant learnings.
 // It retrieves learnings based on task description and file paths,
 // records triggers for matched learnings, and returns them for injection
@@ -123,6 +137,7 @@ func (ls *LearningSystem) OnFailure(errorMessage string) ([]*Learning, error) {
 
 // AddLearning creates a new learning from a CAO triple and associates it
 // with the specified concepts. It generates a unique ID and stores the learning.
+// The outcome type is inferred from the RESULT clause (see ClassifyOutcome).
 func (ls *LearningSystem) AddLearning(cao *CAOTriple, conceptNames []string) (*Learning, error) {
 	if cao == nil {
 		return nil, fmt.Errorf("cao triple is required")
@@ -141,7 +156,7 @@ func (ls *LearningSystem) AddLearning(cao *CAOTriple, conceptNames []string) (*L
 		Action:      cao.Action,
 		Outcome:     cao.Outcome,
 		Scope:       "repo",
-		OutcomeType: "neutral",
+		OutcomeType: ClassifyOutcome(cao.Outcome),
 		CreatedAt:   time.Now(),
 	}
 
@@ -152,6 +167,11 @@ func (ls *LearningSystem) AddLearning(cao *CAOTriple, conceptNames []string) (*L
 
 	// Associate with concepts
 	for _, name := range conceptNames {
+		// Aliases resolve to the concept they name
+		if node, err := ls.registry.Resolve(name); err == nil && node != nil {
+			name = node.Name
+		}
+
 		// Find or create concept
 		concept, err := ls.concepts.GetByName(name)
 		if err != nil {
@@ -179,6 +199,49 @@ func (ls *LearningSystem) AddLearning(cao *CAOTriple, conceptNames []string) (*L
 	return learning, nil
 }
 
+// RecordFailure stores an anti-pattern learning: WHEN condition DO action
+// RESULT failure. Recording the same condition and action again increments
+// the existing learning's trigger count instead of adding a duplicate, so
+// repeatedly failing approaches rank higher.
+func (ls *LearningSystem) RecordFailure(condition, action, detail string) (*Learning, error) {
+	outcome := "failure"
+	if detail = strings.TrimSpace(detail); detail != "" {
+		outcome = "failure: " + detail
+	}
+	cao := &CAOTriple{Condition: condition, Action: action, Outcome: outcome}
+	if err := cao.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid failure triple: %w", err)
+	}
+
+	// Derive the ID from the content so repeats find the existing record
+	id := "lf-" + LearningHash(condition, action)
+	existing, err := ls.store.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get learning %s: %w", id, err)
+	}
+	if existing != nil {
+		if err := ls.store.IncrementTriggerCount(id); err != nil {
+			return nil, fmt.Errorf("record repeat failure: %w", err)
+		}
+		existing.TriggerCount++
+		return existing, nil
+	}
+
+	learning := &Learning{
+		ID:          id,
+		Condition:   cao.Condition,
+		Action:      cao.Action,
+		Outcome:     cao.Outcome,
+		Scope:       "repo",
+		OutcomeType: OutcomeFailure,
+		CreatedAt:   time.Now(),
+	}
+	if err := ls.store.Create(learning); err != nil {
+		return nil, fmt.Errorf("create learning: %w", err)
+	}
+	return learning, nil
+}
+
 // GetStats returns lifecycle statistics about the learnings in the system.
 func (ls *LearningSystem) GetStats() (*LifecycleStats, error) {
 	return ls.lifecycle.GetHealthStats()
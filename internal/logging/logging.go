@@ -0,0 +1,244 @@
+// Package logging provides the structured logger shared by alphie's
+// packages. Each module logs through Logger(module); Configure sets the
+// level, format and output of every module logger at once, including
+// loggers created before it is called, and can raise or lower the level of
+// single modules so that one noisy component does not drown the warnings of
+// the rest.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
+)
+
+// Keys of the fields records carry.
+const (
+	ModuleKey  = "module"
+	SessionKey = "session"
+	TaskKey    = "task"
+	AgentKey   = "agent"
+	ErrorKey   = "err"
+)
+
+// Format is how records are written.
+type Format string
+
+const (
+	// FormatText writes key=value lines, the default.
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record.
+	FormatJSON Format = "json"
+)
+
+// Config configures the shared logger.
+type Config struct {
+	// Level is the minimum level of modules without an override.
+	Level slog.Level
+	// Format is the output format; empty means FormatText.
+	Format Format
+	// Modules overrides the level of single modules.
+	Modules map[string]slog.Level
+	// Output receives the records; nil means standard error.
+	Output io.Writer
+}
+
+// shared is the configuration every module logger consults when it logs.
+var shared = struct {
+	sync.RWMutex
+	cfg     Config
+	handler slog.Handler
+}{
+	cfg:     Config{Level: slog.LevelInfo, Format: FormatText},
+	handler: newHandler(os.Stderr, FormatText),
+}
+
+// Configure replaces the shared configuration.
+func Configure(cfg Config) {
+	if cfg.Format == "" {
+		cfg.Format = FormatText
+	}
+	out := cfg.Output
+	if out == nil {
+		out = os.Stderr
+	}
+
+	shared.Lock()
+	defer shared.Unlock()
+	shared.cfg = cfg
+	shared.handler = newHandler(out, cfg.Format)
+}
+
+// SetOutput redirects records to w, e.g. away from a terminal a TUI draws
+// on, and returns a function restoring the previous output.
+func SetOutput(w io.Writer) (restore func()) {
+	shared.RLock()
+	prev := shared.cfg
+	shared.RUnlock()
+
+	next := prev
+	next.Output = w
+	Configure(next)
+	return func() { Configure(prev) }
+}
+
+// newHandler returns the handler writing records to w in format, with
+// secrets redacted. Records of every level reach it; module handlers filter
+// them.
+func newHandler(w io.Writer, format Format) slog.Handler {
+	w = redact.Writer(w)
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// Logger returns the logger of a module. Its records carry the module's
+// name and are written if they reach the module's level.
+func Logger(module string) *slog.Logger {
+	return slog.New(&moduleHandler{module: module})
+}
+
+// Session returns the field of a session ID. Session, Task and Agent
+// identify what a record is about, as logger arguments or with Logger.With.
+func Session(id string) slog.Attr { return slog.String(SessionKey, id) }
+
+// Task returns the field of a task ID.
+func Task(id string) slog.Attr { return slog.String(TaskKey, id) }
+
+// Agent returns the field of an agent ID.
+func Agent(id string) slog.Attr { return slog.String(AgentKey, id) }
+
+// Err returns the field of an error.
+func Err(err error) slog.Attr { return slog.Any(ErrorKey, err) }
+
+// moduleHandler filters records by its module's level and passes them to
+// the shared handler. Attributes and groups added with WithAttrs and
+// WithGroup are replayed onto the shared handler on every record, so that
+// Configure takes effect on loggers derived before it was called.
+type moduleHandler struct {
+	module string
+	ops    []func(slog.Handler) slog.Handler
+}
+
+// level returns the minimum level of the module.
+func (h *moduleHandler) level() slog.Level {
+	shared.RLock()
+	defer shared.RUnlock()
+	if l, ok := shared.cfg.Modules[h.module]; ok {
+		return l
+	}
+	return shared.cfg.Level
+}
+
+func (h *moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	shared.RLock()
+	base := shared.handler
+	shared.RUnlock()
+
+	handler := base.WithAttrs([]slog.Attr{slog.String(ModuleKey, h.module)})
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+// with returns a copy of the handler with op appended.
+func (h *moduleHandler) with(op func(slog.Handler) slog.Handler) *moduleHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &moduleHandler{module: h.module, ops: append(ops, op)}
+}
+
+// ParseLevel parses a level name: debug, info, warn (or warning) or error.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q (must be debug, info, warn or error)", s)
+}
+
+// ParseFormat parses a format name: text or json.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	case "":
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("invalid log format %q (must be text or json)", s)
+}
+
+// ParseModuleLevels parses a map of module names to level names.
+func ParseModuleLevels(levels map[string]string) (map[string]slog.Level, error) {
+	if len(levels) == 0 {
+		return nil, nil
+	}
+	modules := make([]string, 0, len(levels))
+	for module := range levels {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	parsed := make(map[string]slog.Level, len(levels))
+	for _, module := range modules {
+		l, err := ParseLevel(levels[module])
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module, err)
+		}
+		parsed[module] = l
+	}
+	return parsed, nil
+}
+
+// SplitModuleLevels splits a comma-separated list of module=level pairs,
+// such as "merge_queue=debug,orchestrator=warn", into a map for
+// ParseModuleLevels.
+func SplitModuleLevels(s string) (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("invalid module level %q (want module=level)", pair)
+		}
+		levels[strings.TrimSpace(module)] = strings.TrimSpace(level)
+	}
+	return levels, nil
+}
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	defer Configure(Config{Level: slog.LevelInfo})
+
+	// Loggers created before Configure follow it
+	merges := Logger("merge_queue").With(Session("s1"))
+	orch := Logger("orchestrator")
+	Configure(Config{
+		Level:   slog.LevelWarn,
+		Modules: map[string]slog.Level{"merge_queue": slog.LevelDebug},
+		Output:  &buf,
+	})
+
+	merges.Debug("merge order", Task("t1"))
+	orch.Info("merged session branch")
+	orch.Warn("event log unavailable", Err(errors.New("disk full")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want the debug record of merge_queue and the warning:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{"level=DEBUG", `msg="merge order"`, "module=merge_queue", "session=s1", "task=t1"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("record %q missing %q", lines[0], want)
+		}
+	}
+	if want := `level=WARN msg="event log unavailable" module=orchestrator err="disk full"`; !strings.Contains(lines[1], want) {
+		t.Errorf("record %q missing %q", lines[1], want)
+	}
+}
+
+func TestLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	defer Configure(Config{Level: slog.LevelInfo})
+	Configure(Config{Format: FormatJSON, Output: &buf})
+
+	Logger("spawner").With(Session("s1")).WithGroup("usage").Info("task started", Task("t1"), Agent("a1"), "tokens", 12)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record %q is not JSON: %v", buf.String(), err)
+	}
+	if record["module"] != "spawner" || record["session"] != "s1" || record["msg"] != "task started" {
+		t.Errorf("record = %v", record)
+	}
+	usage, _ := record["usage"].(map[string]any)
+	if usage["task"] != "t1" || usage["agent"] != "a1" || usage["tokens"] != float64(12) {
+		t.Errorf("usage group = %v", record["usage"])
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	var first, second bytes.Buffer
+	defer Configure(Config{Level: slog.LevelInfo})
+	Configure(Config{Output: &first})
+
+	restore := SetOutput(&second)
+	Logger("orchestrator").Info("redirected")
+	restore()
+	Logger("orchestrator").Info("restored")
+
+	if !strings.Contains(second.String(), "redirected") || strings.Contains(second.String(), "restored") {
+		t.Errorf("redirected output = %q", second.String())
+	}
+	if !strings.Contains(first.String(), "restored") || strings.Contains(first.String(), "redirected") {
+		t.Errorf("restored output = %q", first.String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	if l, err := ParseLevel("Warning"); err != nil || l != slog.LevelWarn {
+		t.Errorf("ParseLevel(Warning) = %v, %v", l, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("ParseLevel(loud) succeeded")
+	}
+	if f, err := ParseFormat("JSON"); err != nil || f != FormatJSON {
+		t.Errorf("ParseFormat(JSON) = %v, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) succeeded")
+	}
+
+	split, err := SplitModuleLevels(" merge_queue=debug, orchestrator = warn,")
+	if err != nil {
+		t.Fatalf("SplitModuleLevels: %v", err)
+	}
+	levels, err := ParseModuleLevels(split)
+	if err != nil || len(levels) != 2 || levels["merge_queue"] != slog.LevelDebug || levels["orchestrator"] != slog.LevelWarn {
+		t.Errorf("module levels = %v, %v", levels, err)
+	}
+	if _, err := SplitModuleLevels("merge_queue"); err == nil {
+		t.Error("SplitModuleLevels without a level succeeded")
+	}
+	if _, err := ParseModuleLevels(map[string]string{"spawner": "loud"}); err == nil || !strings.Contains(err.Error(), "spawner") {
+		t.Errorf("ParseModuleLevels error = %v, want it to name the module", err)
+	}
+}
@@ -93,6 +93,9 @@ var CriticalPackageFiles = []string{
 	"Cargo.toml",
 	"pyproject.toml",
 	"tsconfig.json",
+	"pom.xml",
+	"build.gradle",
+	"build.gradle.kts",
 }
 
 // LockFiles are files that should be regenerated rather than merged.
@@ -111,11 +114,12 @@ var LockFiles = map[string]string{
 
 // IsCriticalFile checks if a file path matches any critical file pattern.
 func IsCriticalFile(path string) bool {
+	path = filepath.ToSlash(path)
 	path = strings.TrimPrefix(path, "./")
 	path = strings.TrimPrefix(path, "/")
 
 	base := filepath.Base(path)
-	dir := filepath.Dir(path)
+	dir := filepath.ToSlash(filepath.Dir(path))
 
 	isRoot := !strings.Contains(path, "/") || path == base
 
@@ -219,13 +223,30 @@ func HasCriticalFileOverlap(files1, files2 []string) bool {
 	return false
 }
 
+// IsSmartMergeable reports whether SmartMerge can merge a file with
+// format-aware logic: critical files plus any YAML file.
+func IsSmartMergeable(path string) bool {
+	return IsCriticalFile(path) || IsYAMLFile(path)
+}
+
+// GetSmartMergeableFiles filters a list of file paths to those SmartMerge can handle.
+func GetSmartMergeableFiles(files []string) []string {
+	var mergeable []string
+	for _, f := range files {
+		if IsSmartMergeable(f) {
+			mergeable = append(mergeable, f)
+		}
+	}
+	return mergeable
+}
+
 // CategorizeCriticalFiles separates files into those that can be smart-merged
 // and those that should be regenerated (lock files).
 func CategorizeCriticalFiles(files []string) (mergeable, regenerate []string) {
 	for _, f := range files {
 		if IsLockFile(f) {
 			regenerate = append(regenerate, f)
-		} else if IsCriticalFile(f) {
+		} else if IsSmartMergeable(f) {
 			mergeable = append(mergeable, f)
 		}
 	}
@@ -2,6 +2,7 @@
 package merge
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -173,6 +174,27 @@ func (m *Handler) Merge(agentBranch string) (*Result, error) {
 	}, nil
 }
 
+// RebaseOnSession rebases the agent branch onto the current head of the
+// session branch, so that it merges on top of the work that landed while
+// the agent ran. If the rebase conflicts it is aborted and the branch is
+// left as it was. The session branch is checked out afterwards either way.
+func (m *Handler) RebaseOnSession(agentBranch string) error {
+	if err := m.git.CheckoutBranch(agentBranch); err != nil {
+		return fmt.Errorf("checkout agent branch: %w", err)
+	}
+
+	var rebaseErr error
+	if err := m.git.Rebase(m.sessionBranch); err != nil {
+		_ = m.git.RebaseAbort()
+		rebaseErr = fmt.Errorf("rebase onto %s: %w", m.sessionBranch, err)
+	}
+
+	if err := m.git.CheckoutBranch(m.sessionBranch); err != nil {
+		return errors.Join(rebaseErr, fmt.Errorf("checkout session branch: %w", err))
+	}
+	return rebaseErr
+}
+
 // AbortMerge aborts an in-progress merge operation.
 func (m *Handler) AbortMerge() error {
 	return m.git.MergeAbort()
@@ -323,6 +345,9 @@ func (m *Handler) MergeWithSmartFallback(agentBranch string) (*Result, error) {
 			m.debugLog("[merger] smart merge failed: %v", err)
 		} else {
 			m.debugLog("[merger] smart merge had conflicts: %v", smartResult.Conflicts)
+			for _, lockErr := range smartResult.LockFileErrors {
+				m.debugLog("[merger] %v", lockErr)
+			}
 		}
 	}
 
@@ -331,7 +356,7 @@ func (m *Handler) MergeWithSmartFallback(agentBranch string) (*Result, error) {
 
 // SmartMergeForConflicts handles merge conflicts by using format-aware merge logic.
 func (m *Handler) SmartMergeForConflicts(agentBranch string, conflictFiles []string) (*Result, error) {
-	criticalConflicts := GetCriticalFilesFromList(conflictFiles)
+	criticalConflicts := GetSmartMergeableFiles(conflictFiles)
 	if len(criticalConflicts) == 0 {
 		return &Result{
 			Success:            false,
@@ -351,11 +376,18 @@ func (m *Handler) SmartMergeForConflicts(agentBranch string, conflictFiles []str
 	}
 
 	if !smartResult.Success {
-		return &Result{
+		result := &Result{
 			Success:            false,
 			ConflictFiles:      smartResult.Conflicts,
 			NeedsSemanticMerge: true,
-		}, nil
+		}
+		for _, lockErr := range smartResult.LockFileErrors {
+			m.debugLog("[merger] %v", lockErr)
+		}
+		if len(smartResult.LockFileErrors) > 0 {
+			result.Error = lockFileErrors(smartResult.LockFileErrors)
+		}
+		return result, nil
 	}
 
 	if err := ApplySmartMerge(m.repoPath, smartResult); err != nil {
@@ -409,3 +441,12 @@ func (m *Handler) SmartMergeForConflicts(agentBranch string, conflictFiles []str
 		ChangedFiles: changedFiles,
 	}, nil
 }
+
+// lockFileErrors joins lock file regeneration failures into one error.
+func lockFileErrors(errs []*LockFileError) error {
+	joined := make([]error, len(errs))
+	for i, err := range errs {
+		joined[i] = err
+	}
+	return errors.Join(joined...)
+}
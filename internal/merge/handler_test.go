@@ -1,7 +1,12 @@
 package merge
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
 )
 
 func TestResult_Success(t *testing.T) {
@@ -153,3 +158,61 @@ func TestResult_ZeroValue(t *testing.T) {
 		t.Error("expected zero value Diff to be empty")
 	}
 }
+
+func TestHandler_RebaseOnSession(t *testing.T) {
+	dir := t.TempDir()
+	g := git.NewRunner(dir)
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := g.Run(args...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(out)
+	}
+	commit := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", file)
+		run("commit", "-q", "-m", file)
+	}
+
+	run("init", "-q", "-b", "session")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@test.com")
+	commit("README.md", "# Test\n")
+	run("checkout", "-q", "-b", "agent-1")
+	commit("agent.txt", "agent work\n")
+	run("checkout", "-q", "session")
+	commit("other.txt", "merged while the agent ran\n")
+
+	h := NewHandler("session", dir)
+	if err := h.RebaseOnSession("agent-1"); err != nil {
+		t.Fatalf("RebaseOnSession() error = %v", err)
+	}
+
+	if base, head := run("merge-base", "session", "agent-1"), run("rev-parse", "session"); base != head {
+		t.Errorf("agent branch not rebased onto the session head: merge-base %s, head %s", base, head)
+	}
+	if branch := run("rev-parse", "--abbrev-ref", "HEAD"); branch != "session" {
+		t.Errorf("checked out %s after rebase, want session", branch)
+	}
+
+	// A conflicting rebase is aborted and leaves the branch alone
+	run("checkout", "-q", "-b", "agent-2", "session~1")
+	commit("other.txt", "conflicting change\n")
+	before := run("rev-parse", "agent-2")
+	run("checkout", "-q", "session")
+
+	if err := h.RebaseOnSession("agent-2"); err == nil {
+		t.Error("RebaseOnSession() of a conflicting branch succeeded")
+	}
+	if after := run("rev-parse", "agent-2"); after != before {
+		t.Errorf("conflicting branch moved from %s to %s", before, after)
+	}
+	if branch := run("rev-parse", "--abbrev-ref", "HEAD"); branch != "session" {
+		t.Errorf("checked out %s after failed rebase, want session", branch)
+	}
+}
@@ -0,0 +1,377 @@
+// Package merge provides smart merge logic for Gradle and Maven build files.
+package merge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// buildDependency is one entry of a build file's dependency section.
+type buildDependency struct {
+	// key identifies the dependency across branches, e.g. "implementation group:artifact".
+	// Entries that are not coordinates are keyed by their trimmed text.
+	key string
+	// version is the declared version, empty if none.
+	version string
+	// raw is the entry's text as written.
+	raw string
+}
+
+// dependencySection is the body of one dependency block within a build file.
+type dependencySection struct {
+	// start and end are the byte offsets of the body.
+	start, end int
+	deps       []buildDependency
+	// indent is the indentation of entries; closeIndent that of the closing token.
+	indent, closeIndent string
+}
+
+// buildFileFormat knows how to find and rewrite the dependency sections of a build file.
+type buildFileFormat struct {
+	name       string
+	sections   func(content string) ([]dependencySection, error)
+	setVersion func(raw, version string) string
+}
+
+var gradleFormat = buildFileFormat{
+	name:       "Gradle",
+	sections:   gradleDependencySections,
+	setVersion: setGradleVersion,
+}
+
+var mavenFormat = buildFileFormat{
+	name:       "Maven",
+	sections:   mavenDependencySections,
+	setVersion: setMavenVersion,
+}
+
+// smartMergeGradle merges build.gradle and build.gradle.kts dependency blocks.
+func smartMergeGradle(repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
+	return smartMergeBuildFile(gradleFormat, repoPath, file, sessionBranch, agentBranch)
+}
+
+// smartMergePom merges the <dependencies> sections of a Maven pom.xml.
+// Comments between <dependency> elements are not preserved.
+func smartMergePom(repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
+	return smartMergeBuildFile(mavenFormat, repoPath, file, sessionBranch, agentBranch)
+}
+
+// smartMergeBuildFile unions the dependency coordinates of both branches,
+// keeping the highest version of each. Only dependency sections are merged:
+// if the branches also differ elsewhere in the file, the merge is refused so
+// a better-informed strategy can handle it.
+func smartMergeBuildFile(format buildFileFormat, repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
+	sessionContent, err := getFileFromBranch(repoPath, file, sessionBranch)
+	if err != nil {
+		return nil, fmt.Errorf("get session content: %w", err)
+	}
+	agentContent, err := getFileFromBranch(repoPath, file, agentBranch)
+	if err != nil {
+		return nil, fmt.Errorf("get agent content: %w", err)
+	}
+	merged, err := mergeBuildFile(format, string(sessionContent), string(agentContent))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return []byte(merged), nil
+}
+
+// mergeBuildFile merges two versions of a build file's dependency sections.
+func mergeBuildFile(format buildFileFormat, session, agent string) (string, error) {
+	sessionSections, err := format.sections(session)
+	if err != nil {
+		return "", err
+	}
+	agentSections, err := format.sections(agent)
+	if err != nil {
+		return "", err
+	}
+	if len(sessionSections) != len(agentSections) {
+		return "", fmt.Errorf("%s dependency sections differ (%d vs %d)", format.name, len(sessionSections), len(agentSections))
+	}
+	if stripSections(session, sessionSections) != stripSections(agent, agentSections) {
+		return "", fmt.Errorf("%s build file changed outside dependency sections", format.name)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for i, section := range sessionSections {
+		deps := mergeBuildDependencies(format, section.deps, agentSections[i].deps)
+		sb.WriteString(session[last:section.start])
+		for _, dep := range deps {
+			sb.WriteString("\n" + section.indent + dep.raw)
+		}
+		sb.WriteString("\n" + section.closeIndent)
+		last = section.end
+	}
+	sb.WriteString(session[last:])
+	return sb.String(), nil
+}
+
+// mergeBuildDependencies keeps the session's entries in order, raising each
+// to the highest version either branch declares, then appends the agent's
+// new entries.
+func mergeBuildDependencies(format buildFileFormat, session, agent []buildDependency) []buildDependency {
+	agentByKey := make(map[string]buildDependency, len(agent))
+	for _, dep := range agent {
+		agentByKey[dep.key] = dep
+	}
+
+	seen := make(map[string]bool, len(session))
+	result := make([]buildDependency, 0, len(session)+len(agent))
+	for _, dep := range session {
+		seen[dep.key] = true
+		if other, ok := agentByKey[dep.key]; ok && other.version != dep.version && higherVersion(dep.version, other.version) == other.version {
+			dep.raw = format.setVersion(dep.raw, other.version)
+			dep.version = other.version
+		}
+		result = append(result, dep)
+	}
+	for _, dep := range agent {
+		if !seen[dep.key] {
+			seen[dep.key] = true
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
+// stripSections removes the dependency section bodies from content so the
+// rest of the file can be compared.
+func stripSections(content string, sections []dependencySection) string {
+	var sb strings.Builder
+	last := 0
+	for _, s := range sections {
+		sb.WriteString(content[last:s.start])
+		last = s.end
+	}
+	sb.WriteString(content[last:])
+	return strings.TrimSpace(sb.String())
+}
+
+var (
+	gradleBlockPattern = regexp.MustCompile(`(?m)^[ \t]*dependencies\s*\{`)
+	// Matches: implementation 'g:a:v', implementation("g:a:v"), api "g:a"
+	gradleDepPattern = regexp.MustCompile(`^(\w+)\s*\(?\s*["']([^:"'\s]+):([^:"'\s]+)(?::([^"'\s@]+))?(@\w+)?["']\s*\)?$`)
+)
+
+// gradleDependencySections finds every dependencies { } block, including
+// those nested in buildscript and subprojects blocks.
+func gradleDependencySections(content string) ([]dependencySection, error) {
+	var sections []dependencySection
+	for _, loc := range gradleBlockPattern.FindAllStringIndex(content, -1) {
+		start := loc[1]
+		end := matchingBrace(content, start)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced braces in Gradle dependencies block")
+		}
+		section := dependencySection{
+			start:       start,
+			end:         end,
+			deps:        parseGradleDependencies(content[start:end]),
+			indent:      lineIndent(content, loc[0]) + "    ",
+			closeIndent: lineIndent(content, end),
+		}
+		if first := strings.IndexFunc(content[start:end], func(r rune) bool { return r != ' ' && r != '\t' && r != '\n' && r != '\r' }); first >= 0 {
+			section.indent = lineIndent(content, start+first)
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// parseGradleDependencies splits a dependencies block body into entries. A
+// declaration with a configuration closure is kept as one entry.
+func parseGradleDependencies(body string) []buildDependency {
+	var deps []buildDependency
+	var pending []string
+	depth := 0
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" && depth == 0 {
+			continue
+		}
+		pending = append(pending, line)
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+		if depth > 0 {
+			continue
+		}
+		depth = 0
+
+		raw := strings.TrimSpace(strings.Join(pending, "\n"))
+		pending = nil
+		dep := buildDependency{key: raw, raw: raw}
+		if m := gradleDepPattern.FindStringSubmatch(raw); m != nil {
+			dep.key = m[1] + " " + m[2] + ":" + m[3] + m[5]
+			dep.version = m[4]
+		}
+		deps = append(deps, dep)
+	}
+	if len(pending) > 0 {
+		raw := strings.TrimSpace(strings.Join(pending, "\n"))
+		deps = append(deps, buildDependency{key: raw, raw: raw})
+	}
+	return deps
+}
+
+// setGradleVersion replaces the version in a Gradle coordinate.
+func setGradleVersion(raw, version string) string {
+	m := gradleDepPattern.FindStringSubmatchIndex(raw)
+	if m == nil || m[8] < 0 {
+		return raw
+	}
+	return raw[:m[8]] + version + raw[m[9]:]
+}
+
+var (
+	mavenSectionPattern    = regexp.MustCompile(`(?s)<dependencies>(.*?)</dependencies>`)
+	mavenDependencyPattern = regexp.MustCompile(`(?s)<dependency>.*?</dependency>`)
+	mavenExclusionsPattern = regexp.MustCompile(`(?s)<exclusions>.*?</exclusions>`)
+	mavenVersionPattern    = regexp.MustCompile(`<version>\s*([^<]*?)\s*</version>`)
+)
+
+// mavenDependencySections finds every <dependencies> section, including
+// those under <dependencyManagement> and profiles.
+func mavenDependencySections(content string) ([]dependencySection, error) {
+	var sections []dependencySection
+	for _, loc := range mavenSectionPattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := loc[2], loc[3]
+		body := content[start:end]
+		section := dependencySection{
+			start:       start,
+			end:         end,
+			closeIndent: lineIndent(content, end),
+			indent:      lineIndent(content, loc[0]) + "  ",
+		}
+		for _, m := range mavenDependencyPattern.FindAllStringIndex(body, -1) {
+			raw := body[m[0]:m[1]]
+			if section.deps == nil {
+				section.indent = lineIndent(content, start+m[0])
+			}
+			section.deps = append(section.deps, buildDependency{
+				key:     mavenDependencyKey(raw),
+				version: mavenTag(raw, "version"),
+				raw:     raw,
+			})
+		}
+		if len(section.deps) == 0 && strings.TrimSpace(body) != "" {
+			return nil, fmt.Errorf("unrecognized content in <dependencies>")
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// mavenDependencyKey identifies a dependency by groupId, artifactId, type and classifier.
+func mavenDependencyKey(raw string) string {
+	key := mavenTag(raw, "groupId") + ":" + mavenTag(raw, "artifactId")
+	if t := mavenTag(raw, "type"); t != "" && t != "jar" {
+		key += ":" + t
+	}
+	if c := mavenTag(raw, "classifier"); c != "" {
+		key += ":" + c
+	}
+	return key
+}
+
+// mavenTag returns the value of a direct child tag of a <dependency>,
+// ignoring anything inside <exclusions>.
+func mavenTag(raw, tag string) string {
+	raw = mavenExclusionsPattern.ReplaceAllString(raw, "")
+	m := regexp.MustCompile(`<` + tag + `>\s*([^<]*?)\s*</` + tag + `>`).FindStringSubmatch(raw)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// setMavenVersion replaces the <version> of a dependency.
+func setMavenVersion(raw, version string) string {
+	m := mavenVersionPattern.FindStringSubmatchIndex(raw)
+	if m == nil {
+		return raw
+	}
+	return raw[:m[2]] + version + raw[m[3]:]
+}
+
+// matchingBrace returns the offset of the '}' closing a block whose body
+// starts at start, or -1 if the braces are unbalanced.
+func matchingBrace(content string, start int) int {
+	depth := 1
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// lineIndent returns the leading whitespace of the line containing offset.
+func lineIndent(content string, offset int) string {
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	i := lineStart
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+		i++
+	}
+	return content[lineStart:i]
+}
+
+// higherVersion returns the higher of two dependency versions. Versions that
+// cannot be compared, such as property references, resolve to b.
+func higherVersion(a, b string) string {
+	if a == "" || !isLiteralVersion(a) || !isLiteralVersion(b) {
+		return b
+	}
+	if compareVersions(a, b) > 0 {
+		return a
+	}
+	return b
+}
+
+// isLiteralVersion reports whether v is a version number rather than a
+// property reference or range.
+func isLiteralVersion(v string) bool {
+	return v != "" && !strings.ContainsAny(v, "$[]()+,") && v[0] >= '0' && v[0] <= '9'
+}
+
+// compareVersions compares dotted versions numerically. A qualifier such as
+// -SNAPSHOT or -rc1 ranks below the plain release.
+func compareVersions(a, b string) int {
+	aNum, aQual, _ := strings.Cut(a, "-")
+	bNum, bQual, _ := strings.Cut(b, "-")
+	aParts := strings.Split(aNum, ".")
+	bParts := strings.Split(bNum, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	switch {
+	case aQual == bQual:
+		return 0
+	case aQual == "":
+		return 1
+	case bQual == "":
+		return -1
+	default:
+		return strings.Compare(aQual, bQual)
+	}
+}
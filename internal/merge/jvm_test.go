@@ -0,0 +1,143 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeBuildFile_Gradle(t *testing.T) {
+	session := `plugins {
+    id 'java'
+}
+
+dependencies {
+    implementation 'com.google.guava:guava:31.0-jre'
+    implementation("org.slf4j:slf4j-api:1.7.36")
+    testImplementation 'junit:junit:4.13.2'
+}
+`
+	agent := `plugins {
+    id 'java'
+}
+
+dependencies {
+    implementation 'com.google.guava:guava:32.1.0-jre'
+    implementation("org.slf4j:slf4j-api:1.7.30")
+    implementation('com.squareup.okhttp3:okhttp:4.12.0') {
+        exclude group: 'org.jetbrains.kotlin'
+    }
+    testImplementation 'junit:junit:4.13.2'
+}
+`
+	merged, err := mergeBuildFile(gradleFormat, session, agent)
+	if err != nil {
+		t.Fatalf("mergeBuildFile: %v", err)
+	}
+	for _, want := range []string{
+		"    implementation 'com.google.guava:guava:32.1.0-jre'\n",
+		"    implementation(\"org.slf4j:slf4j-api:1.7.36\")\n",
+		"    testImplementation 'junit:junit:4.13.2'\n",
+		"    implementation('com.squareup.okhttp3:okhttp:4.12.0') {\n        exclude group: 'org.jetbrains.kotlin'\n    }\n}\n",
+	} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("merged file missing %q:\n%s", want, merged)
+		}
+	}
+	if n := strings.Count(merged, "junit:junit"); n != 1 {
+		t.Errorf("junit declared %d times, want once", n)
+	}
+	if !strings.HasPrefix(merged, "plugins {\n    id 'java'\n}\n") {
+		t.Errorf("content outside dependencies changed:\n%s", merged)
+	}
+}
+
+func TestMergeBuildFile_GradleRefusesOtherChanges(t *testing.T) {
+	session := "plugins {\n    id 'java'\n}\ndependencies {\n    implementation 'a:b:1.0'\n}\n"
+	agent := "plugins {\n    id 'application'\n}\ndependencies {\n    implementation 'a:b:2.0'\n}\n"
+	if _, err := mergeBuildFile(gradleFormat, session, agent); err == nil {
+		t.Error("expected an error when the branches differ outside dependencies")
+	}
+}
+
+func TestMergeBuildFile_Maven(t *testing.T) {
+	session := `<project>
+  <artifactId>app</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>4.13.2</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>
+`
+	agent := `<project>
+  <artifactId>app</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.12</version>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+      <exclusions>
+        <exclusion>
+          <groupId>org.slf4j</groupId>
+          <artifactId>slf4j-api</artifactId>
+        </exclusion>
+      </exclusions>
+    </dependency>
+  </dependencies>
+</project>
+`
+	merged, err := mergeBuildFile(mavenFormat, session, agent)
+	if err != nil {
+		t.Fatalf("mergeBuildFile: %v", err)
+	}
+	for _, want := range []string{
+		"<version>2.0.12</version>",
+		"<artifactId>junit</artifactId>",
+		"<artifactId>jackson-databind</artifactId>",
+		"    </dependency>\n  </dependencies>\n</project>\n",
+	} {
+		if !strings.Contains(merged, want) {
+			t.Errorf("merged pom missing %q:\n%s", want, merged)
+		}
+	}
+	if strings.Contains(merged, "2.0.9") {
+		t.Errorf("lower slf4j version kept:\n%s", merged)
+	}
+	if n := strings.Count(merged, "<dependency>"); n != 3 {
+		t.Errorf("merged pom has %d dependencies, want 3:\n%s", n, merged)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0", "2.0.1", -1},
+		{"1.0.0-SNAPSHOT", "1.0.0", -1},
+		{"31.0-jre", "32.1.0-jre", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if got := higherVersion("${v}", "1.0"); got != "1.0" {
+		t.Errorf("higherVersion with a property = %q, want the agent version", got)
+	}
+}
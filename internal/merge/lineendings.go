@@ -0,0 +1,23 @@
+package merge
+
+import "bytes"
+
+// Smart merges work on LF content: files are read with their line endings
+// normalized, and the merged result is converted back to CRLF if the
+// agent's version of the file used it. Without this, merging a file checked
+// in with Windows line endings mixed "\r\n" and "\n" lines.
+
+// usesCRLF reports whether content has Windows line endings.
+func usesCRLF(content []byte) bool {
+	return bytes.Contains(content, []byte("\r\n"))
+}
+
+// toLF converts CRLF line endings to LF.
+func toLF(content []byte) []byte {
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// toCRLF converts LF line endings to CRLF, leaving existing CRLFs alone.
+func toCRLF(content []byte) []byte {
+	return bytes.ReplaceAll(toLF(content), []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,71 @@
+package merge
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToCRLF(t *testing.T) {
+	tests := map[string]string{
+		"a\nb\n":     "a\r\nb\r\n",
+		"a\r\nb\n":   "a\r\nb\r\n",
+		"a\r\nb\r\n": "a\r\nb\r\n",
+		"":           "",
+	}
+	for in, want := range tests {
+		if got := string(toCRLF([]byte(in))); got != want {
+			t.Errorf("toCRLF(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSmartMergeFile_KeepsCRLF(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "requirements.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "session")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	run("config", "core.autocrlf", "false")
+	write("requests==2.31.0\r\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+
+	run("checkout", "-q", "-b", "agent")
+	write("requests==2.31.0\r\nflask==3.0.0\r\n")
+	run("commit", "-q", "-am", "agent")
+
+	run("checkout", "-q", "session")
+	write("requests==2.31.0\r\nnumpy==1.26.0\r\n")
+	run("commit", "-q", "-am", "session")
+
+	merged, err := smartMergeFile(repo, "requirements.txt", "session", "agent")
+	if err != nil {
+		t.Fatalf("smartMergeFile() error = %v", err)
+	}
+	content := string(merged)
+	for _, pkg := range []string{"requests", "flask", "numpy"} {
+		if !strings.Contains(content, pkg) {
+			t.Errorf("merged file is missing %s:\n%q", pkg, content)
+		}
+	}
+	if strings.Count(content, "\n") != strings.Count(content, "\r\n") {
+		t.Errorf("expected only CRLF line endings, got %q", content)
+	}
+}
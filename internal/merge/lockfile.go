@@ -0,0 +1,335 @@
+// Package merge provides lock file regeneration for JavaScript package managers.
+package merge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// PackageManager identifies a JavaScript package manager.
+type PackageManager string
+
+const (
+	// PackageManagerNPM is npm (package-lock.json).
+	PackageManagerNPM PackageManager = "npm"
+	// PackageManagerYarn is Yarn 1.x (yarn.lock).
+	PackageManagerYarn PackageManager = "yarn"
+	// PackageManagerYarnBerry is Yarn 2+ (yarn.lock with __metadata).
+	PackageManagerYarnBerry PackageManager = "yarn-berry"
+	// PackageManagerPNPM is pnpm (pnpm-lock.yaml).
+	PackageManagerPNPM PackageManager = "pnpm"
+)
+
+// jsLockFiles maps JavaScript lock file names to the package manager that owns them.
+var jsLockFiles = map[string]PackageManager{
+	"package-lock.json": PackageManagerNPM,
+	"yarn.lock":         PackageManagerYarn,
+	"pnpm-lock.yaml":    PackageManagerPNPM,
+}
+
+// sandboxConfigFiles are package manager config files copied next to the
+// manifest in the sandbox so registries and resolution settings match the repo.
+var sandboxConfigFiles = []string{
+	".npmrc",
+	".yarnrc",
+	".yarnrc.yml",
+	"pnpm-workspace.yaml",
+	".pnpmfile.cjs",
+}
+
+// DefaultLockFileTimeout bounds a single lock file regeneration.
+const DefaultLockFileTimeout = 5 * time.Minute
+
+// IsJSLockFile reports whether path is an npm, Yarn or pnpm lock file.
+func IsJSLockFile(path string) bool {
+	_, ok := jsLockFiles[filepath.Base(path)]
+	return ok
+}
+
+// DetectPackageManager determines the package manager for a lock file. The
+// manifest's "packageManager" field takes precedence over the lock file name;
+// a yarn.lock in the Yarn 2+ format selects Yarn Berry.
+func DetectPackageManager(manifest []byte, lockFile string, lockContent []byte) PackageManager {
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if json.Unmarshal(manifest, &pkg) == nil && pkg.PackageManager != "" {
+		name, version, _ := strings.Cut(pkg.PackageManager, "@")
+		switch name {
+		case "npm":
+			return PackageManagerNPM
+		case "pnpm":
+			return PackageManagerPNPM
+		case "yarn":
+			if version != "" && !strings.HasPrefix(version, "1.") {
+				return PackageManagerYarnBerry
+			}
+			return PackageManagerYarn
+		}
+	}
+
+	manager := jsLockFiles[filepath.Base(lockFile)]
+	if manager == PackageManagerYarn && bytes.Contains(lockContent, []byte("__metadata:")) {
+		return PackageManagerYarnBerry
+	}
+	if manager == "" {
+		return PackageManagerNPM
+	}
+	return manager
+}
+
+// LockFileCommand returns the command that updates the lock file for a
+// package manager without running install scripts where the manager allows it.
+func LockFileCommand(manager PackageManager) []string {
+	switch manager {
+	case PackageManagerYarn:
+		return []string{"yarn", "install", "--ignore-scripts", "--non-interactive"}
+	case PackageManagerYarnBerry:
+		return []string{"yarn", "install", "--mode=update-lockfile"}
+	case PackageManagerPNPM:
+		return []string{"pnpm", "install", "--lockfile-only", "--ignore-scripts"}
+	default:
+		return []string{"npm", "install", "--package-lock-only", "--ignore-scripts"}
+	}
+}
+
+// DependencyConflict is a dependency both branches changed to different versions.
+type DependencyConflict struct {
+	// Name is the package name.
+	Name string
+	// Section is the manifest section, e.g. "dependencies".
+	Section string
+	// SessionVersion is the version range on the session branch.
+	SessionVersion string
+	// AgentVersion is the version range on the agent branch, which the merged manifest keeps.
+	AgentVersion string
+}
+
+// LockFileError describes a lock file that could not be regenerated, with
+// enough context to resolve it by hand.
+type LockFileError struct {
+	// LockFile is the lock file path relative to the repository.
+	LockFile string
+	// Manager is the detected package manager.
+	Manager PackageManager
+	// Command is the regeneration command that was run.
+	Command string
+	// Output is the tail of the command's output.
+	Output string
+	// Dependencies lists dependencies the two branches disagree on.
+	Dependencies []DependencyConflict
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LockFileError) Error() string {
+	var sb strings.Builder
+	if e.Command == "" {
+		fmt.Fprintf(&sb, "regenerate %s: %v", e.LockFile, e.Err)
+	} else {
+		fmt.Fprintf(&sb, "regenerate %s with %s (%s): %v", e.LockFile, e.Manager, e.Command, e.Err)
+	}
+	if len(e.Dependencies) > 0 {
+		sb.WriteString("\n  conflicting dependencies (agent version kept):")
+		for _, d := range e.Dependencies {
+			fmt.Fprintf(&sb, "\n    %s (%s): session %s, agent %s", d.Name, d.Section, d.SessionVersion, d.AgentVersion)
+		}
+	}
+	if e.Output != "" {
+		sb.WriteString("\n  output:\n")
+		for _, line := range strings.Split(e.Output, "\n") {
+			sb.WriteString("    " + line + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Unwrap returns the underlying error.
+func (e *LockFileError) Unwrap() error {
+	return e.Err
+}
+
+// maxLockFileOutputLines limits how much command output a LockFileError keeps.
+const maxLockFileOutputLines = 20
+
+// LockFileMerger regenerates JavaScript lock files from a merged manifest in
+// a sandbox directory, so a failed install never touches the repository.
+type LockFileMerger struct {
+	exec    iexec.CommandRunner
+	timeout time.Duration
+}
+
+// NewLockFileMerger creates a LockFileMerger that runs real package managers.
+func NewLockFileMerger() *LockFileMerger {
+	return NewLockFileMergerWithExec(iexec.NewRunner())
+}
+
+// NewLockFileMergerWithExec creates a LockFileMerger with a custom command runner (for testing).
+func NewLockFileMergerWithExec(runner iexec.CommandRunner) *LockFileMerger {
+	return &LockFileMerger{exec: runner, timeout: DefaultLockFileTimeout}
+}
+
+// Regenerate produces a lock file for the merged manifest. The session
+// branch's lock file seeds the sandbox so unrelated resolutions stay pinned.
+// Failures are returned as *LockFileError.
+func (m *LockFileMerger) Regenerate(repoPath, lockFile string, manifest []byte, sessionBranch, agentBranch string) ([]byte, error) {
+	dir := filepath.Dir(lockFile)
+	manifestPath := path.Join(path.Dir(filepath.ToSlash(lockFile)), "package.json")
+	sessionLock, _ := getFileFromBranch(repoPath, lockFile, sessionBranch)
+	manager := DetectPackageManager(manifest, lockFile, sessionLock)
+	command := LockFileCommand(manager)
+
+	fail := func(err error, output []byte) ([]byte, error) {
+		sessionManifest, _ := getFileFromBranch(repoPath, manifestPath, sessionBranch)
+		agentManifest, _ := getFileFromBranch(repoPath, manifestPath, agentBranch)
+		return nil, &LockFileError{
+			LockFile:     lockFile,
+			Manager:      manager,
+			Command:      strings.Join(command, " "),
+			Output:       tailLines(string(output), maxLockFileOutputLines),
+			Dependencies: conflictingDependencies(sessionManifest, agentManifest),
+			Err:          err,
+		}
+	}
+
+	sandbox, err := os.MkdirTemp("", "alphie-lockfile-*")
+	if err != nil {
+		return fail(fmt.Errorf("create sandbox: %w", err), nil)
+	}
+	defer os.RemoveAll(sandbox)
+
+	if err := m.populateSandbox(repoPath, sandbox, dir, manifest, lockFile, sessionLock); err != nil {
+		return fail(err, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	workDir := filepath.Join(sandbox, dir)
+	output, err := m.exec.Run(ctx, workDir, command[0], command[1:]...)
+	if err != nil {
+		return fail(err, output)
+	}
+
+	lock, err := os.ReadFile(filepath.Join(workDir, filepath.Base(lockFile)))
+	if err != nil {
+		return fail(fmt.Errorf("%s did not write %s: %w", manager, filepath.Base(lockFile), err), output)
+	}
+	return lock, nil
+}
+
+// populateSandbox writes the merged manifest, the seed lock file, package
+// manager config and any workspace member manifests into the sandbox.
+func (m *LockFileMerger) populateSandbox(repoPath, sandbox, dir string, manifest []byte, lockFile string, sessionLock []byte) error {
+	files := map[string][]byte{
+		filepath.Join(dir, "package.json"): manifest,
+	}
+	if len(sessionLock) > 0 {
+		files[lockFile] = sessionLock
+	}
+	for _, name := range sandboxConfigFiles {
+		if content, err := os.ReadFile(filepath.Join(repoPath, dir, name)); err == nil {
+			files[filepath.Join(dir, name)] = content
+		}
+	}
+
+	// Workspace roots resolve member packages, so their manifests are needed too
+	if out, err := m.exec.Run(context.Background(), repoPath, "git", "ls-files", "--", dir); err == nil {
+		for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if filepath.Base(path) != "package.json" || strings.Contains(path, "node_modules/") {
+				continue
+			}
+			if _, ok := files[path]; ok {
+				continue
+			}
+			if content, err := os.ReadFile(filepath.Join(repoPath, path)); err == nil && !hasConflictMarkers(content) {
+				files[path] = content
+			}
+		}
+	}
+
+	for path, content := range files {
+		full := filepath.Join(sandbox, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return fmt.Errorf("create sandbox directory: %w", err)
+		}
+		if err := os.WriteFile(full, content, 0644); err != nil {
+			return fmt.Errorf("write sandbox %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// mergedManifestFor returns the manifest a lock file should be regenerated
+// from: the smart-merged package.json if there is one, otherwise the working
+// tree copy (already merged cleanly by git), otherwise the agent's version.
+func mergedManifestFor(repoPath, lockFile string, merged map[string][]byte, agentBranch string) ([]byte, error) {
+	manifestPath := path.Join(path.Dir(filepath.ToSlash(lockFile)), "package.json")
+	if content, ok := merged[manifestPath]; ok {
+		return content, nil
+	}
+	if content, err := os.ReadFile(filepath.Join(repoPath, manifestPath)); err == nil && !hasConflictMarkers(content) {
+		return content, nil
+	}
+	content, err := getFileFromBranch(repoPath, manifestPath, agentBranch)
+	if err != nil {
+		return nil, fmt.Errorf("no package.json next to %s", lockFile)
+	}
+	return content, nil
+}
+
+// conflictingDependencies lists dependencies set to different versions in
+// the two manifests.
+func conflictingDependencies(sessionManifest, agentManifest []byte) []DependencyConflict {
+	var sessionPkg, agentPkg map[string]interface{}
+	if json.Unmarshal(sessionManifest, &sessionPkg) != nil || json.Unmarshal(agentManifest, &agentPkg) != nil {
+		return nil
+	}
+
+	var conflicts []DependencyConflict
+	for _, section := range []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"} {
+		sessionDeps := toStringMap(sessionPkg[section])
+		agentDeps := toStringMap(agentPkg[section])
+		for name, agentVersion := range agentDeps {
+			if sessionVersion, ok := sessionDeps[name]; ok && sessionVersion != agentVersion {
+				conflicts = append(conflicts, DependencyConflict{
+					Name:           name,
+					Section:        section,
+					SessionVersion: sessionVersion,
+					AgentVersion:   agentVersion,
+				})
+			}
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Section != conflicts[j].Section {
+			return conflicts[i].Section < conflicts[j].Section
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts
+}
+
+// hasConflictMarkers reports whether content still contains git conflict markers.
+func hasConflictMarkers(content []byte) bool {
+	return bytes.Contains(content, []byte("\n<<<<<<< ")) || bytes.HasPrefix(content, []byte("<<<<<<< "))
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,165 @@
+package merge
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// fakePackageManager passes git through and fakes package manager runs.
+type fakePackageManager struct {
+	iexec.ExecRunner
+	fail     bool
+	commands []string
+	manifest string
+}
+
+func (f *fakePackageManager) Run(ctx context.Context, workDir, name string, args ...string) ([]byte, error) {
+	if name == "git" {
+		return f.ExecRunner.Run(ctx, workDir, name, args...)
+	}
+	f.commands = append(f.commands, name+" "+strings.Join(args, " "))
+	manifest, _ := os.ReadFile(filepath.Join(workDir, "package.json"))
+	f.manifest = string(manifest)
+	if f.fail {
+		return []byte("error An unexpected error occurred: \"react@^19: No matching version\""), errors.New("exit status 1")
+	}
+	return nil, os.WriteFile(filepath.Join(workDir, "yarn.lock"), []byte("# regenerated\n"), 0644)
+}
+
+// setupLockFileRepo creates a repo where the session and agent branches both
+// changed package.json and yarn.lock.
+func setupLockFileRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "session")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	write("package.json", `{"name":"app","dependencies":{"react":"^17.0.0"}}`)
+	write("yarn.lock", "# base\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+
+	run("checkout", "-q", "-b", "agent")
+	write("package.json", `{"name":"app","dependencies":{"react":"^19.0.0","lodash":"^4.17.0"}}`)
+	write("yarn.lock", "# agent\n")
+	run("commit", "-q", "-am", "agent")
+
+	run("checkout", "-q", "session")
+	write("package.json", `{"name":"app","dependencies":{"react":"^18.0.0","axios":"^1.0.0"}}`)
+	write("yarn.lock", "# session\n")
+	run("commit", "-q", "-am", "session")
+	return repo
+}
+
+func TestSmartMerge_RegeneratesYarnLock(t *testing.T) {
+	repo := setupLockFileRepo(t)
+	pm := &fakePackageManager{}
+
+	result, err := SmartMergeWithLockFiles(repo, []string{"package.json", "yarn.lock"}, "session", "agent", NewLockFileMergerWithExec(pm))
+	if err != nil {
+		t.Fatalf("SmartMerge: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("conflicts = %v, errors = %v", result.Conflicts, result.LockFileErrors)
+	}
+	if got := string(result.MergedFiles["yarn.lock"]); got != "# regenerated\n" {
+		t.Errorf("yarn.lock = %q, want the regenerated lock file", got)
+	}
+	if len(result.RegenerateCommands) != 0 {
+		t.Errorf("RegenerateCommands = %v, want none for a sandboxed lock file", result.RegenerateCommands)
+	}
+	if len(pm.commands) != 1 || !strings.HasPrefix(pm.commands[0], "yarn install") {
+		t.Errorf("commands = %v, want one yarn install", pm.commands)
+	}
+	for _, dep := range []string{"axios", "lodash"} {
+		if !strings.Contains(pm.manifest, dep) {
+			t.Errorf("sandbox manifest %s is missing %s", pm.manifest, dep)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "yarn.lock")); string(content) != "# session\n" {
+		t.Errorf("repository yarn.lock changed to %q before the merge was applied", content)
+	}
+}
+
+func TestSmartMerge_LockFileFailureContext(t *testing.T) {
+	repo := setupLockFileRepo(t)
+	pm := &fakePackageManager{fail: true}
+
+	result, err := SmartMergeWithLockFiles(repo, []string{"package.json", "yarn.lock"}, "session", "agent", NewLockFileMergerWithExec(pm))
+	if err != nil {
+		t.Fatalf("SmartMerge: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected the failed regeneration to be reported as a conflict")
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != "yarn.lock" {
+		t.Errorf("Conflicts = %v, want [yarn.lock]", result.Conflicts)
+	}
+	if _, ok := result.MergedFiles["package.json"]; !ok {
+		t.Error("package.json should still be merged")
+	}
+	if len(result.LockFileErrors) != 1 {
+		t.Fatalf("LockFileErrors = %v, want one", result.LockFileErrors)
+	}
+
+	lockErr := result.LockFileErrors[0]
+	if lockErr.Manager != PackageManagerYarn {
+		t.Errorf("Manager = %s, want yarn", lockErr.Manager)
+	}
+	if len(lockErr.Dependencies) != 1 || lockErr.Dependencies[0].Name != "react" ||
+		lockErr.Dependencies[0].SessionVersion != "^18.0.0" || lockErr.Dependencies[0].AgentVersion != "^19.0.0" {
+		t.Errorf("Dependencies = %+v, want the react version conflict", lockErr.Dependencies)
+	}
+	msg := lockErr.Error()
+	for _, want := range []string{"yarn.lock", "yarn install", "react (dependencies): session ^18.0.0, agent ^19.0.0", "No matching version"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not mention %q", msg, want)
+		}
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		lockFile string
+		lock     string
+		want     PackageManager
+	}{
+		{"npm lock", `{}`, "package-lock.json", "", PackageManagerNPM},
+		{"yarn classic", `{}`, "yarn.lock", "# yarn lockfile v1\n", PackageManagerYarn},
+		{"yarn berry lock", `{}`, "yarn.lock", "__metadata:\n  version: 6\n", PackageManagerYarnBerry},
+		{"pnpm lock", `{}`, "client/pnpm-lock.yaml", "", PackageManagerPNPM},
+		{"packageManager field", `{"packageManager":"yarn@4.1.0"}`, "yarn.lock", "", PackageManagerYarnBerry},
+		{"packageManager yarn 1", `{"packageManager":"yarn@1.22.19"}`, "yarn.lock", "", PackageManagerYarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectPackageManager([]byte(tt.manifest), tt.lockFile, []byte(tt.lock)); got != tt.want {
+				t.Errorf("DetectPackageManager = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
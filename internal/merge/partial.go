@@ -0,0 +1,210 @@
+package merge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PartialResult is the outcome of merging the independent changes of an
+// agent branch.
+type PartialResult struct {
+	// Merged lists the files whose changes landed on the session branch,
+	// including conflicting files whose independent hunks landed.
+	Merged []string
+	// Held lists the files with changes held back: the conflicting files and
+	// the files the caller chose to hold.
+	Held []string
+	// HeldHunks is the number of conflicting hunks kept at the session
+	// branch's version.
+	HeldHunks int
+	// Commit is the commit holding the merged changes, empty if nothing
+	// could be merged.
+	Commit string
+}
+
+// MergeIndependent lands the parts of an agent branch that do not conflict
+// with the session branch, so that a conflict in one file does not hold up
+// the rest of the work. The branch is squash-merged: files that merge
+// cleanly are kept, conflicting hunks are reverted to the session branch's
+// version while the hunks around them are kept, and conflicting files
+// without hunks (binary files, modify/delete conflicts) are reverted
+// whole. Files for which hold returns true are reverted whole as well.
+//
+// The result is committed without recording the agent branch as merged, so
+// merging the branch later only has to resolve what was held back. If
+// nothing can be merged, the session branch is left untouched.
+func (m *Handler) MergeIndependent(agentBranch string, hold func(path string) bool) (*PartialResult, error) {
+	if err := m.git.CheckoutBranch(m.sessionBranch); err != nil {
+		return nil, fmt.Errorf("checkout session branch: %w", err)
+	}
+
+	// A squash merge stops with conflicts; those are resolved below
+	_, mergeErr := m.git.Run("merge", "--squash", agentBranch)
+	conflicted, err := m.git.ConflictedFiles()
+	if err != nil {
+		m.resetMerge()
+		return nil, fmt.Errorf("list conflicted files: %w", err)
+	}
+	if mergeErr != nil && len(conflicted) == 0 {
+		m.resetMerge()
+		return nil, fmt.Errorf("squash merge: %w", mergeErr)
+	}
+
+	result := &PartialResult{}
+	isConflicted := make(map[string]bool, len(conflicted))
+	for _, path := range conflicted {
+		isConflicted[path] = true
+		if hold != nil && hold(path) {
+			if err := m.restoreFromHead(path); err != nil {
+				m.resetMerge()
+				return nil, err
+			}
+			result.Held = append(result.Held, path)
+			continue
+		}
+		hunks, err := m.keepOursInConflicts(path)
+		if err != nil {
+			m.resetMerge()
+			return nil, err
+		}
+		result.HeldHunks += hunks
+		result.Held = append(result.Held, path)
+	}
+
+	staged, err := m.stagedFiles()
+	if err != nil {
+		m.resetMerge()
+		return nil, err
+	}
+	for _, path := range staged {
+		if isConflicted[path] || hold == nil || !hold(path) {
+			continue
+		}
+		if err := m.restoreFromHead(path); err != nil {
+			m.resetMerge()
+			return nil, err
+		}
+		result.Held = append(result.Held, path)
+	}
+
+	if result.Merged, err = m.stagedFiles(); err != nil {
+		m.resetMerge()
+		return nil, err
+	}
+	if len(result.Merged) == 0 {
+		m.resetMerge()
+		return result, nil
+	}
+
+	message := fmt.Sprintf("Partial merge of %s: %d file(s), held back: %s",
+		agentBranch, len(result.Merged), strings.Join(result.Held, ", "))
+	if err := m.git.Commit(message); err != nil {
+		m.resetMerge()
+		return nil, fmt.Errorf("commit partial merge: %w", err)
+	}
+	if commit, err := m.git.Run("rev-parse", "HEAD"); err == nil {
+		result.Commit = strings.TrimSpace(commit)
+	}
+	return result, nil
+}
+
+// keepOursInConflicts resolves the conflicting hunks of a file to the
+// session branch's version, keeping the hunks that merged, and stages it.
+// A file without conflict markers is reverted whole. It returns the number
+// of hunks resolved.
+func (m *Handler) keepOursInConflicts(path string) (int, error) {
+	fullPath := filepath.Join(m.repoPath, path)
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return 0, m.restoreFromHead(path)
+	}
+
+	resolved, hunks := KeepOurs(string(content))
+	if hunks == 0 {
+		return 0, m.restoreFromHead(path)
+	}
+	if err := os.WriteFile(fullPath, []byte(resolved), 0644); err != nil {
+		return 0, fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := m.git.Add(path); err != nil {
+		return 0, fmt.Errorf("stage %s: %w", path, err)
+	}
+	return hunks, nil
+}
+
+// restoreFromHead reverts a file, in the index and the working tree, to its
+// version on the session branch, removing it if the branch does not have it.
+func (m *Handler) restoreFromHead(path string) error {
+	if _, err := m.git.Run("restore", "--source=HEAD", "--staged", "--worktree", "--", path); err != nil {
+		return fmt.Errorf("restore %s: %w", path, err)
+	}
+	return nil
+}
+
+// stagedFiles returns the files whose staged version differs from HEAD.
+func (m *Handler) stagedFiles() ([]string, error) {
+	out, err := m.git.Run("diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, fmt.Errorf("list staged files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// resetMerge discards an uncommitted squash merge, leaving changes that
+// were in the working tree before it alone.
+func (m *Handler) resetMerge() {
+	if _, err := m.git.Run("reset", "-q", "--merge"); err != nil {
+		m.debugLog("[merger] reset after partial merge failed: %v", err)
+	}
+}
+
+// KeepOurs resolves every conflict in content, as left by git with conflict
+// markers, to "our" side, and returns the result and the number of
+// conflicts resolved. The merge base section of diff3-style conflicts is
+// dropped along with "their" side.
+func KeepOurs(content string) (string, int) {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	var b strings.Builder
+	state := outside
+	hunks := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		marker := strings.TrimRight(line, "\r\n")
+		switch {
+		case state == outside && (strings.HasPrefix(marker, "<<<<<<< ") || marker == "<<<<<<<"):
+			state = inOurs
+			continue
+		case state == inOurs && (strings.HasPrefix(marker, "||||||| ") || marker == "|||||||"):
+			state = inBase
+			continue
+		case (state == inOurs || state == inBase) && marker == "=======":
+			state = inTheirs
+			continue
+		case state == inTheirs && (strings.HasPrefix(marker, ">>>>>>> ") || marker == ">>>>>>>"):
+			state = outside
+			hunks++
+			continue
+		}
+		if state == outside || state == inOurs {
+			b.WriteString(line)
+		}
+	}
+	if state != outside {
+		// Unterminated conflict: not something git wrote
+		return content, 0
+	}
+	return b.String(), hunks
+}
@@ -0,0 +1,189 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+func TestKeepOurs(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		want      string
+		wantHunks int
+	}{
+		{
+			name:      "no conflicts",
+			in:        "a\nb\n",
+			want:      "a\nb\n",
+			wantHunks: 0,
+		},
+		{
+			name:      "merge style",
+			in:        "a\n<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> agent\nb\n<<<<<<< HEAD\n=======\nadded\n>>>>>>> agent\n",
+			want:      "a\nours\nb\n",
+			wantHunks: 2,
+		},
+		{
+			name:      "diff3 style",
+			in:        "<<<<<<< ours\nours\n||||||| base\nbase\n=======\ntheirs\n>>>>>>> theirs\nend\n",
+			want:      "ours\nend\n",
+			wantHunks: 1,
+		},
+		{
+			name:      "unterminated conflict",
+			in:        "<<<<<<< HEAD\nours\n=======\ntheirs\n",
+			want:      "<<<<<<< HEAD\nours\n=======\ntheirs\n",
+			wantHunks: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hunks := KeepOurs(tt.in)
+			if got != tt.want || hunks != tt.wantHunks {
+				t.Errorf("KeepOurs() = %q, %d, want %q, %d", got, hunks, tt.want, tt.wantHunks)
+			}
+		})
+	}
+}
+
+func TestHandler_MergeIndependent(t *testing.T) {
+	dir := t.TempDir()
+	g := git.NewRunner(dir)
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := g.Run(args...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(out)
+	}
+	write := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", file)
+	}
+	read := func(file string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	lines := func(first, last string) string {
+		return first + "\n2\n3\n4\n5\n6\n7\n8\n9\n" + last + "\n"
+	}
+
+	run("init", "-q", "-b", "session")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@test.com")
+	write("shared.txt", lines("1", "10"))
+	write("protected.txt", "v1\n")
+	run("commit", "-q", "-m", "base")
+
+	run("checkout", "-q", "-b", "agent")
+	write("shared.txt", lines("agent", "agent"))
+	write("clean.txt", "new file\n")
+	write("protected.txt", "v2\n")
+	run("commit", "-q", "-m", "agent work")
+
+	run("checkout", "-q", "session")
+	write("shared.txt", lines("session", "10"))
+	run("commit", "-q", "-m", "session work")
+
+	h := NewHandler("session", dir)
+	result, err := h.MergeIndependent("agent", func(path string) bool { return path == "protected.txt" })
+	if err != nil {
+		t.Fatalf("MergeIndependent() error = %v", err)
+	}
+
+	if want := []string{"clean.txt", "shared.txt"}; !reflect.DeepEqual(result.Merged, want) {
+		t.Errorf("Merged = %v, want %v", result.Merged, want)
+	}
+	if want := []string{"shared.txt", "protected.txt"}; !reflect.DeepEqual(result.Held, want) {
+		t.Errorf("Held = %v, want %v", result.Held, want)
+	}
+	if result.HeldHunks != 1 {
+		t.Errorf("HeldHunks = %d, want 1", result.HeldHunks)
+	}
+	if head := run("rev-parse", "HEAD"); result.Commit != head {
+		t.Errorf("Commit = %q, want the session head %q", result.Commit, head)
+	}
+
+	// The independent hunk landed, the conflicting one and the held file did not
+	if got, want := read("shared.txt"), lines("session", "agent"); got != want {
+		t.Errorf("shared.txt = %q, want %q", got, want)
+	}
+	if got := read("protected.txt"); got != "v1\n" {
+		t.Errorf("protected.txt = %q, want it held back", got)
+	}
+	if status := run("status", "--porcelain"); status != "" {
+		t.Errorf("working tree not clean after partial merge:\n%s", status)
+	}
+
+	// Merging the branch later conflicts only in what was held back
+	if _, err := g.Run("merge", "--no-ff", "--no-edit", "agent"); err == nil {
+		t.Fatal("merge of the held-back conflict succeeded")
+	}
+	conflicted, err := g.ConflictedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"shared.txt"}; !reflect.DeepEqual(conflicted, want) {
+		t.Errorf("conflicted files = %v, want %v", conflicted, want)
+	}
+}
+
+func TestHandler_MergeIndependent_NothingIndependent(t *testing.T) {
+	dir := t.TempDir()
+	g := git.NewRunner(dir)
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := g.Run(args...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(out)
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "file.txt")
+		run("commit", "-q", "-m", content)
+	}
+
+	run("init", "-q", "-b", "session")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@test.com")
+	write("base\n")
+	run("checkout", "-q", "-b", "agent")
+	write("agent\n")
+	run("checkout", "-q", "session")
+	write("session\n")
+	before := run("rev-parse", "HEAD")
+
+	result, err := NewHandler("session", dir).MergeIndependent("agent", nil)
+	if err != nil {
+		t.Fatalf("MergeIndependent() error = %v", err)
+	}
+	if result.Commit != "" || len(result.Merged) != 0 {
+		t.Errorf("result = %+v, want nothing merged", result)
+	}
+	if after := run("rev-parse", "HEAD"); after != before {
+		t.Errorf("session branch moved from %s to %s", before, after)
+	}
+	if status := run("status", "--porcelain"); status != "" {
+		t.Errorf("working tree not clean:\n%s", status)
+	}
+}
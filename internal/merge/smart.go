@@ -3,6 +3,7 @@ package merge
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -22,23 +23,28 @@ type SmartMergeResult struct {
 	Conflicts []string
 	// RegenerateCommands lists commands to run after merge (e.g., npm install).
 	RegenerateCommands []string
+	// LockFileErrors describes JavaScript lock files that could not be
+	// regenerated. Each is also listed in Conflicts.
+	LockFileErrors []*LockFileError
 }
 
 // SmartMerge attempts to merge critical files using format-aware logic.
-// It handles package.json, go.mod, and other common package manager files.
+// It handles package.json, go.mod, Gradle and Maven build files, YAML files
+// and other common package manager files.
 func SmartMerge(repoPath string, conflictFiles []string, sessionBranch, agentBranch string) (*SmartMergeResult, error) {
+	return SmartMergeWithLockFiles(repoPath, conflictFiles, sessionBranch, agentBranch, NewLockFileMerger())
+}
+
+// SmartMergeWithLockFiles is SmartMerge with a custom lock file merger.
+// npm, Yarn and pnpm lock files are regenerated from the merged package.json
+// in a sandbox; other lock files get a RegenerateCommand.
+func SmartMergeWithLockFiles(repoPath string, conflictFiles []string, sessionBranch, agentBranch string, lockFiles *LockFileMerger) (*SmartMergeResult, error) {
 	result := &SmartMergeResult{
 		MergedFiles: make(map[string][]byte),
 	}
 
 	mergeable, regenerate := CategorizeCriticalFiles(conflictFiles)
 
-	for _, lockFile := range regenerate {
-		if cmd := GetLockFileCommand(lockFile); cmd != "" {
-			result.RegenerateCommands = append(result.RegenerateCommands, cmd)
-		}
-	}
-
 	for _, file := range mergeable {
 		merged, err := smartMergeFile(repoPath, file, sessionBranch, agentBranch)
 		if err != nil {
@@ -48,11 +54,49 @@ func SmartMerge(repoPath string, conflictFiles []string, sessionBranch, agentBra
 		result.MergedFiles[file] = merged
 	}
 
+	// Lock files are regenerated after manifests so they see the merged dependencies
+	for _, lockFile := range regenerate {
+		if !IsJSLockFile(lockFile) {
+			if cmd := GetLockFileCommand(lockFile); cmd != "" {
+				result.RegenerateCommands = append(result.RegenerateCommands, cmd)
+			}
+			continue
+		}
+		manifest, err := mergedManifestFor(repoPath, lockFile, result.MergedFiles, agentBranch)
+		if err == nil {
+			var lock []byte
+			if lock, err = lockFiles.Regenerate(repoPath, lockFile, manifest, sessionBranch, agentBranch); err == nil {
+				result.MergedFiles[lockFile] = lock
+				continue
+			}
+		}
+		var lockErr *LockFileError
+		if !errors.As(err, &lockErr) {
+			lockErr = &LockFileError{LockFile: lockFile, Err: err}
+		}
+		result.LockFileErrors = append(result.LockFileErrors, lockErr)
+		result.Conflicts = append(result.Conflicts, lockFile)
+	}
+
 	result.Success = len(result.Conflicts) == 0
 	return result, nil
 }
 
+// smartMergeFile merges one file with the merger for its format, keeping the
+// agent's line endings.
 func smartMergeFile(repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
+	merged, err := mergeFileFormat(repoPath, file, sessionBranch, agentBranch)
+	if err != nil {
+		return nil, err
+	}
+	if agent, err := showFile(repoPath, file, agentBranch); err == nil && usesCRLF(agent) {
+		merged = toCRLF(merged)
+	}
+	return merged, nil
+}
+
+// mergeFileFormat dispatches a file to the smart merger for its format.
+func mergeFileFormat(repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
 	base := filepath.Base(file)
 
 	switch {
@@ -68,9 +112,16 @@ func smartMergeFile(repoPath, file, sessionBranch, agentBranch string) ([]byte,
 		return smartMergeRequirementsTxt(repoPath, file, sessionBranch, agentBranch)
 	case base == "tsconfig.json" || base == "jsconfig.json":
 		return smartMergeTSConfig(repoPath, file, sessionBranch, agentBranch)
+	case base == "build.gradle" || base == "build.gradle.kts":
+		return smartMergeGradle(repoPath, file, sessionBranch, agentBranch)
+	case base == "pom.xml":
+		return smartMergePom(repoPath, file, sessionBranch, agentBranch)
 	case base == ".gitignore":
 		return smartMergeGitignore(repoPath, file, sessionBranch, agentBranch)
 	default:
+		if IsYAMLFile(file) {
+			return smartMergeGenericYAML(repoPath, file, sessionBranch, agentBranch)
+		}
 		if strings.HasSuffix(file, ".json") {
 			return smartMergeGenericJSON(repoPath, file, sessionBranch, agentBranch)
 		}
@@ -81,8 +132,20 @@ func smartMergeFile(repoPath, file, sessionBranch, agentBranch string) ([]byte,
 	}
 }
 
+// getFileFromBranch returns a file's content on branch with LF line
+// endings.
 func getFileFromBranch(repoPath, file, branch string) ([]byte, error) {
-	cmd := exec.Command("git", "show", branch+":"+file)
+	content, err := showFile(repoPath, file, branch)
+	if err != nil {
+		return nil, err
+	}
+	return toLF(content), nil
+}
+
+// showFile returns a file's content on branch as committed. file may use
+// either path separator; git wants slashes.
+func showFile(repoPath, file, branch string) ([]byte, error) {
+	cmd := exec.Command("git", "show", branch+":"+filepath.ToSlash(file))
 	cmd.Dir = repoPath
 	return cmd.Output()
 }
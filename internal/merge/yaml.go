@@ -0,0 +1,309 @@
+// Package merge provides smart merge logic for YAML files.
+package merge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLMergeStrategy controls how a value at a YAML path is merged.
+type YAMLMergeStrategy string
+
+const (
+	// YAMLMergeDeep merges mappings key by key and unions sequences. It is
+	// the default for every path.
+	YAMLMergeDeep YAMLMergeStrategy = "deep"
+	// YAMLMergeReplace takes the agent's value as a whole.
+	YAMLMergeReplace YAMLMergeStrategy = "replace"
+	// YAMLMergeKeep keeps the session's value as a whole.
+	YAMLMergeKeep YAMLMergeStrategy = "keep"
+)
+
+// YAMLMergeStrategies overrides the merge strategy for specific paths.
+// Paths are mapping keys joined by dots; sequence items do not add a
+// segment, and "*" matches any single key. Values whose order carries
+// meaning, such as argument lists, are replaced instead of unioned.
+var YAMLMergeStrategies = map[string]YAMLMergeStrategy{
+	// docker-compose
+	"services.*.command":          YAMLMergeReplace,
+	"services.*.entrypoint":       YAMLMergeReplace,
+	"services.*.healthcheck.test": YAMLMergeReplace,
+	// Kubernetes workloads
+	"spec.template.spec.containers.command": YAMLMergeReplace,
+	"spec.template.spec.containers.args":    YAMLMergeReplace,
+	"spec.containers.command":               YAMLMergeReplace,
+	"spec.containers.args":                  YAMLMergeReplace,
+}
+
+// yamlIdentityKeys are the keys that identify an item in a sequence of
+// mappings, such as containers, workflow steps or env vars. Items with the
+// same identity are merged instead of both being kept.
+var yamlIdentityKeys = []string{"name", "id"}
+
+// IsYAMLFile reports whether path is a YAML file.
+func IsYAMLFile(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".yml") || strings.HasSuffix(lower, ".yaml")
+}
+
+// smartMergeGenericYAML deep-merges YAML files such as docker-compose.yml,
+// GitHub workflows and Kubernetes manifests.
+func smartMergeGenericYAML(repoPath, file, sessionBranch, agentBranch string) ([]byte, error) {
+	sessionContent, err := getFileFromBranch(repoPath, file, sessionBranch)
+	if err != nil {
+		sessionContent = []byte("")
+	}
+
+	agentContent, err := getFileFromBranch(repoPath, file, agentBranch)
+	if err != nil {
+		return nil, fmt.Errorf("get agent content: %w", err)
+	}
+
+	return MergeYAML(sessionContent, agentContent, YAMLMergeStrategies)
+}
+
+// MergeYAML deep-merges two YAML streams. Mappings are merged key by key
+// with the agent winning on scalar conflicts, sequences are unioned, and
+// strategies override either behavior for specific paths. Documents in a
+// multi-document stream are matched by kind and metadata.name when they have
+// them, otherwise by position. Comments on the session side are preserved.
+func MergeYAML(session, agent []byte, strategies map[string]YAMLMergeStrategy) ([]byte, error) {
+	sessionDocs, err := decodeYAMLDocuments(session)
+	if err != nil {
+		return nil, fmt.Errorf("parse session YAML: %w", err)
+	}
+	agentDocs, err := decodeYAMLDocuments(agent)
+	if err != nil {
+		return nil, fmt.Errorf("parse agent YAML: %w", err)
+	}
+
+	m := &yamlMerger{strategies: strategies}
+	merged := make([]*yaml.Node, len(sessionDocs))
+	copy(merged, sessionDocs)
+	used := make(map[int]bool)
+	for i, doc := range agentDocs {
+		j := matchYAMLDocument(sessionDocs, used, doc, i)
+		if j < 0 {
+			merged = append(merged, doc)
+			continue
+		}
+		used[j] = true
+		merged[j] = m.merge(nil, sessionDocs[j], doc)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range merged {
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("encode merged YAML: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode merged YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeYAMLDocuments parses every document in a YAML stream.
+func decodeYAMLDocuments(content []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// matchYAMLDocument finds the unused session document matching an agent
+// document, by Kubernetes identity if it has one, otherwise by position.
+func matchYAMLDocument(sessionDocs []*yaml.Node, used map[int]bool, doc *yaml.Node, index int) int {
+	if id := yamlDocumentIdentity(doc); id != "" {
+		for j, candidate := range sessionDocs {
+			if !used[j] && yamlDocumentIdentity(candidate) == id {
+				return j
+			}
+		}
+		return -1
+	}
+	if index < len(sessionDocs) && !used[index] && yamlDocumentIdentity(sessionDocs[index]) == "" {
+		return index
+	}
+	return -1
+}
+
+// yamlDocumentIdentity returns "kind/name" for a Kubernetes-style document.
+func yamlDocumentIdentity(doc *yaml.Node) string {
+	root := yamlContent(doc)
+	kind := yamlMapValue(root, "kind")
+	name := yamlMapValue(yamlMapValue(root, "metadata"), "name")
+	if kind == nil || name == nil || kind.Kind != yaml.ScalarNode || name.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return kind.Value + "/" + name.Value
+}
+
+// yamlMerger merges YAML node trees according to per-path strategies.
+type yamlMerger struct {
+	strategies map[string]YAMLMergeStrategy
+}
+
+// merge combines the session and agent values found at path.
+func (m *yamlMerger) merge(path []string, session, agent *yaml.Node) *yaml.Node {
+	switch m.strategy(path) {
+	case YAMLMergeReplace:
+		return agent
+	case YAMLMergeKeep:
+		return session
+	}
+
+	if session.Kind != agent.Kind {
+		return agent
+	}
+	switch session.Kind {
+	case yaml.DocumentNode:
+		if len(session.Content) == 1 && len(agent.Content) == 1 {
+			session.Content[0] = m.merge(path, session.Content[0], agent.Content[0])
+		}
+		return session
+	case yaml.MappingNode:
+		return m.mergeMapping(path, session, agent)
+	case yaml.SequenceNode:
+		return m.mergeSequence(path, session, agent)
+	default:
+		if session.HeadComment != "" && agent.HeadComment == "" {
+			agent.HeadComment = session.HeadComment
+		}
+		return agent
+	}
+}
+
+// mergeMapping merges the agent's keys into the session mapping, keeping the
+// session's key order and appending new keys.
+func (m *yamlMerger) mergeMapping(path []string, session, agent *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(agent.Content); i += 2 {
+		key, value := agent.Content[i], agent.Content[i+1]
+		if j := yamlMapIndex(session, key.Value); j >= 0 {
+			session.Content[j+1] = m.merge(append(path, key.Value), session.Content[j+1], value)
+		} else {
+			session.Content = append(session.Content, key, value)
+		}
+	}
+	return session
+}
+
+// mergeSequence unions two sequences. Mapping items with the same name or id
+// are merged; other items are kept once each, session items first.
+func (m *yamlMerger) mergeSequence(path []string, session, agent *yaml.Node) *yaml.Node {
+	for _, item := range agent.Content {
+		if j := yamlIdentityIndex(session, item); j >= 0 {
+			session.Content[j] = m.merge(path, session.Content[j], item)
+			continue
+		}
+		if !yamlContains(session, item) {
+			session.Content = append(session.Content, item)
+		}
+	}
+	return session
+}
+
+// strategy returns the configured strategy for path.
+func (m *yamlMerger) strategy(path []string) YAMLMergeStrategy {
+	for pattern, strategy := range m.strategies {
+		if matchYAMLPath(strings.Split(pattern, "."), path) {
+			return strategy
+		}
+	}
+	return YAMLMergeDeep
+}
+
+// matchYAMLPath reports whether path matches pattern segment by segment.
+func matchYAMLPath(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, seg := range pattern {
+		if seg != "*" && seg != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlContent unwraps a document node to its root value.
+func yamlContent(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// yamlMapIndex returns the index of key in a mapping's Content, or -1.
+func yamlMapIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlMapValue returns the value of key in a mapping, or nil.
+func yamlMapValue(mapping *yaml.Node, key string) *yaml.Node {
+	if i := yamlMapIndex(mapping, key); i >= 0 {
+		return mapping.Content[i+1]
+	}
+	return nil
+}
+
+// yamlIdentityIndex returns the index of the sequence item sharing item's
+// name or id, or -1.
+func yamlIdentityIndex(seq, item *yaml.Node) int {
+	if item.Kind != yaml.MappingNode {
+		return -1
+	}
+	for _, key := range yamlIdentityKeys {
+		id := yamlMapValue(item, key)
+		if id == nil || id.Kind != yaml.ScalarNode {
+			continue
+		}
+		for j, candidate := range seq.Content {
+			if other := yamlMapValue(candidate, key); other != nil && other.Kind == yaml.ScalarNode && other.Value == id.Value {
+				return j
+			}
+		}
+		return -1
+	}
+	return -1
+}
+
+// yamlContains reports whether seq already has an item equal to item.
+func yamlContains(seq, item *yaml.Node) bool {
+	var want interface{}
+	if item.Decode(&want) != nil {
+		return false
+	}
+	for _, candidate := range seq.Content {
+		var got interface{}
+		if candidate.Decode(&got) == nil && reflect.DeepEqual(got, want) {
+			return true
+		}
+	}
+	return false
+}
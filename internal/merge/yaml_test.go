@@ -0,0 +1,143 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeYAML_DockerCompose(t *testing.T) {
+	session := `# Local development stack
+services:
+  web:
+    image: app:latest
+    command: ["npm", "run", "dev"]
+    ports:
+      - "3000:3000"
+    environment:
+      - NODE_ENV=development
+  db:
+    image: postgres:15
+`
+	agent := `services:
+  web:
+    image: app:latest
+    command: ["npm", "start"]
+    ports:
+      - "3000:3000"
+      - "9229:9229"
+    environment:
+      - NODE_ENV=development
+  redis:
+    image: redis:7
+`
+	merged, err := MergeYAML([]byte(session), []byte(agent), YAMLMergeStrategies)
+	if err != nil {
+		t.Fatalf("MergeYAML: %v", err)
+	}
+	out := string(merged)
+	for _, want := range []string{"# Local development stack", "db:", "redis:", `"9229:9229"`, "postgres:15"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("merged YAML missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, `"3000:3000"`) != 1 || strings.Count(out, "NODE_ENV") != 1 {
+		t.Errorf("sequence union duplicated items:\n%s", out)
+	}
+	if strings.Contains(out, `"dev"`) {
+		t.Errorf("command should be replaced by the agent's, not unioned:\n%s", out)
+	}
+}
+
+func TestMergeYAML_WorkflowStepsByName(t *testing.T) {
+	session := `jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v3
+      - name: Lint
+        run: make lint
+`
+	agent := `jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v4
+      - name: Test
+        run: make test
+`
+	merged, err := MergeYAML([]byte(session), []byte(agent), nil)
+	if err != nil {
+		t.Fatalf("MergeYAML: %v", err)
+	}
+	out := string(merged)
+	if strings.Count(out, "name: Checkout") != 1 || !strings.Contains(out, "actions/checkout@v4") {
+		t.Errorf("steps with the same name should merge:\n%s", out)
+	}
+	if !strings.Contains(out, "make lint") || !strings.Contains(out, "make test") {
+		t.Errorf("steps from both branches should be kept:\n%s", out)
+	}
+	if strings.Index(out, "make lint") > strings.Index(out, "make test") {
+		t.Errorf("session steps should come first:\n%s", out)
+	}
+}
+
+func TestMergeYAML_KubernetesDocuments(t *testing.T) {
+	session := `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+`
+	agent := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  labels:
+    tier: backend
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api-config
+`
+	merged, err := MergeYAML([]byte(session), []byte(agent), map[string]YAMLMergeStrategy{"spec.replicas": YAMLMergeKeep})
+	if err != nil {
+		t.Fatalf("MergeYAML: %v", err)
+	}
+	docs := strings.Split(string(merged), "---\n")
+	if len(docs) != 3 {
+		t.Fatalf("got %d documents, want 3:\n%s", len(docs), merged)
+	}
+	if !strings.Contains(docs[0], "kind: Service") || !strings.Contains(docs[2], "kind: ConfigMap") {
+		t.Errorf("documents out of order:\n%s", merged)
+	}
+	if !strings.Contains(docs[1], "tier: backend") || !strings.Contains(docs[1], "replicas: 2") {
+		t.Errorf("Deployment not merged with the keep strategy:\n%s", docs[1])
+	}
+}
+
+func TestIsSmartMergeable(t *testing.T) {
+	for path, want := range map[string]bool{
+		".github/workflows/ci.yml": true,
+		"deploy/k8s/api.yaml":      true,
+		"docker-compose.yml":       true,
+		"src/main.go":              false,
+	} {
+		if got := IsSmartMergeable(path); got != want {
+			t.Errorf("IsSmartMergeable(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -4,13 +4,16 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/protect"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -23,6 +26,21 @@ type SpawnOptions struct {
 	WorkersRunning int
 	WorkersBlocked int
 	StructureRules interface{} // Structure guidance for agent (uses interface{} for flexibility)
+	Validation     *agent.ValidationPipeline
+	TaskHistory    agent.TaskHistory
+	ProtectedAreas *protect.Detector
+	Sandbox        iexec.CommandRunner
+	Model          string // Overrides model selection, e.g. after tier escalation
+	SessionID      string // Recorded in the agent's commit message
+	ContextBudget  int    // Prompt context budget in tokens; 0 uses the tier default
+	// Questions the task may ask (-1 for unlimited) and has asked so far;
+	// OnQuestion is called with each new one
+	QuestionsAllowed int
+	QuestionsAsked   int
+	OnQuestion       func(agentID, question string)
+	// StallTimeout stops an agent that shows no progress for this long; 0
+	// disables the watchdog
+	StallTimeout time.Duration
 }
 
 // SpawnResult contains the outcome of a spawned agent.
@@ -43,7 +61,10 @@ type DefaultAgentSpawner struct {
 	collision   *CollisionChecker
 	scheduler   *Scheduler
 	events      chan<- OrchestratorEvent
+	recorder    EventRecorder
+	filter      *EventFilter
 	repoPath    string
+	log         *slog.Logger
 }
 
 // NewAgentSpawner creates a new DefaultAgentSpawner.
@@ -60,9 +81,25 @@ func NewAgentSpawner(
 		scheduler: scheduler,
 		events:    events,
 		repoPath:  repoPath,
+		log:       logging.Logger("agent_spawner"),
 	}
 }
 
+// SetLogger sets the logger of the spawner's records.
+func (s *DefaultAgentSpawner) SetLogger(l *slog.Logger) {
+	s.log = l
+}
+
+// SetRecorder sets a recorder that persists the events the spawner emits.
+func (s *DefaultAgentSpawner) SetRecorder(r EventRecorder) {
+	s.recorder = r
+}
+
+// SetFilter sets the filter that decides which events reach subscribers.
+func (s *DefaultAgentSpawner) SetFilter(f *EventFilter) {
+	s.filter = f
+}
+
 // SetScheduler sets the task scheduler after construction.
 func (s *DefaultAgentSpawner) SetScheduler(scheduler *Scheduler) {
 	s.scheduler = scheduler
@@ -90,19 +127,21 @@ func (s *DefaultAgentSpawner) Spawn(ctx context.Context, task *models.Task, opts
 	pathPrefixes := s.collision.ExtractPathPrefixes(task)
 	s.collision.RegisterAgent(agentModel.ID, pathPrefixes, nil)
 
-	log.Printf("[agent_spawner] EMITTING EventTaskStarted for task %s (agent %s)", task.ID, agentModel.ID)
+	s.log.Debug("emitting task started", logging.Task(task.ID), logging.Agent(agentModel.ID))
 	s.emitEvent(OrchestratorEvent{
-		Type:           EventTaskStarted,
-		TaskID:         task.ID,
-		TaskTitle:      task.Title,
-		ParentID:       task.ParentID,
-		AgentID:        agentModel.ID,
-		Message:        fmt.Sprintf("Started task: %s", task.Title),
-		Timestamp:      time.Now(),
-		WorkersRunning: opts.WorkersRunning,
-		WorkersBlocked: opts.WorkersBlocked,
+		Type:             EventTaskStarted,
+		TaskID:           task.ID,
+		TaskTitle:        task.Title,
+		ParentID:         task.ParentID,
+		AgentID:          agentModel.ID,
+		Message:          fmt.Sprintf("Started task: %s", task.Title),
+		Timestamp:        time.Now(),
+		WorkersRunning:   opts.WorkersRunning,
+		WorkersBlocked:   opts.WorkersBlocked,
+		QuestionsAsked:   opts.QuestionsAsked,
+		QuestionsAllowed: opts.QuestionsAllowed,
 	})
-	log.Printf("[agent_spawner] EventTaskStarted EMITTED for task %s", task.ID)
+	s.log.Debug("emitted task started", logging.Task(task.ID), logging.Agent(agentModel.ID))
 
 	// Spawn agent goroutine
 	go func() {
@@ -115,6 +154,14 @@ func (s *DefaultAgentSpawner) Spawn(ctx context.Context, task *models.Task, opts
 			EnableQualityGates: true,
 			Baseline:           opts.Baseline,
 			StructureRules:     opts.StructureRules,
+			Validation:         opts.Validation,
+			TaskHistory:        opts.TaskHistory,
+			ProtectedAreas:     opts.ProtectedAreas,
+			Sandbox:            opts.Sandbox,
+			Model:              opts.Model,
+			SessionID:          opts.SessionID,
+			ContextBudget:      opts.ContextBudget,
+			QuestionsAllowed:   remainingQuestions(opts.QuestionsAllowed, opts.QuestionsAsked),
 			OnProgress: func(update agent.ProgressUpdate) {
 				if opts.OnProgress != nil {
 					opts.OnProgress(ProgressReport{
@@ -138,21 +185,59 @@ func (s *DefaultAgentSpawner) Spawn(ctx context.Context, task *models.Task, opts
 					Cost:           update.Cost,
 					Duration:       update.Duration,
 					CurrentAction:  update.CurrentAction,
+					LogFile:        update.LogFile,
 					WorkersRunning: opts.WorkersRunning,
 					WorkersBlocked: opts.WorkersBlocked,
 				})
 			},
 		}
+		execOpts.OnFirstCommit = func(files []string) {
+			if added := s.collision.ObservePaths(agentModel.ID, files); len(added) > 0 {
+				s.log.Info("updated collision hints from agent commit", logging.Task(task.ID), logging.Agent(agentModel.ID), "added", added)
+			}
+		}
+		if opts.OnQuestion != nil {
+			execOpts.OnQuestion = func(question string) {
+				opts.OnQuestion(agentModel.ID, question)
+			}
+		}
+		if opts.StallTimeout > 0 {
+			execOpts.StallTimeout = opts.StallTimeout
+			execOpts.OnStall = func(idle time.Duration) {
+				s.log.Warn("agent shows no progress", logging.Task(task.ID), logging.Agent(agentModel.ID), "idle", idle.Round(time.Second))
+				s.emitEvent(OrchestratorEvent{
+					Type:      EventAgentStalled,
+					TaskID:    task.ID,
+					TaskTitle: task.Title,
+					AgentID:   agentModel.ID,
+					Message:   fmt.Sprintf("No progress for %v; stopping the agent at %v", idle.Round(time.Second), opts.StallTimeout),
+					Timestamp: time.Now(),
+					Duration:  idle,
+				})
+			}
+		}
 
 		result, err := s.executor.ExecuteWithOptions(ctx, task, opts.Tier, execOpts)
 		if err != nil {
-			log.Printf("[spawner] task %s execution error: %v", task.ID, err)
+			s.log.Warn("task execution failed", logging.Task(task.ID), logging.Agent(agentModel.ID), logging.Err(err))
 			result = &agent.ExecutionResult{
 				Success: false,
 				Error:   err.Error(),
 				AgentID: agentModel.ID,
 			}
 		}
+		if result.Stalled {
+			s.log.Warn("stopped stalled agent", logging.Task(task.ID), logging.Agent(agentModel.ID), "stall_timeout", opts.StallTimeout)
+			s.emitEvent(OrchestratorEvent{
+				Type:      EventAgentStalled,
+				TaskID:    task.ID,
+				TaskTitle: task.Title,
+				AgentID:   agentModel.ID,
+				Message:   fmt.Sprintf("Stopped after %v without progress", opts.StallTimeout),
+				Timestamp: time.Now(),
+				Duration:  opts.StallTimeout,
+			})
+		}
 
 		resultCh <- SpawnResult{
 			AgentID: agentModel.ID,
@@ -167,6 +252,12 @@ func (s *DefaultAgentSpawner) Spawn(ctx context.Context, task *models.Task, opts
 
 // emitEvent sends an event to the events channel.
 func (s *DefaultAgentSpawner) emitEvent(event OrchestratorEvent) {
+	if s.recorder != nil {
+		s.recorder.Record(event)
+	}
+	if !s.filter.Allow(event) {
+		return
+	}
 	select {
 	case s.events <- event:
 	default:
@@ -0,0 +1,252 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// ApprovalRequest is a gate holding a run until an operator answers it.
+type ApprovalRequest struct {
+	// Gate is the policy.Approval* point the run is held at.
+	Gate string `json:"gate"`
+	// Summary describes what is being approved.
+	Summary     string    `json:"summary"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// ApprovalVerdict is an operator's answer to an approval request.
+type ApprovalVerdict struct {
+	Approved bool
+	// Note is the operator's optional reason.
+	Note string
+}
+
+// ApprovalGates holds a run at the gates its policy enables until an
+// operator approves or rejects it, from the TUI or the control socket. At
+// most one gate is pending at a time. The implement loop shares one
+// ApprovalGates between its own gate and the sessions it runs.
+type ApprovalGates struct {
+	policy policy.ApprovalPolicy
+
+	// turn serializes waits, so a second gate waits for the first.
+	turn sync.Mutex
+
+	mu      sync.Mutex
+	pending *ApprovalRequest
+	answer  chan ApprovalVerdict
+}
+
+// NewApprovalGates creates gates holding the run at the points p enables.
+func NewApprovalGates(p policy.ApprovalPolicy) *ApprovalGates {
+	return &ApprovalGates{policy: p}
+}
+
+// Enabled reports whether gate holds the run for approval.
+func (g *ApprovalGates) Enabled(gate string) bool {
+	return g != nil && g.policy.Has(gate)
+}
+
+// Pending returns the request the run is held at, if any.
+func (g *ApprovalGates) Pending() (ApprovalRequest, bool) {
+	if g == nil {
+		return ApprovalRequest{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending == nil {
+		return ApprovalRequest{}, false
+	}
+	return *g.pending, true
+}
+
+// Wait holds the run at gate until an operator answers it or ctx is done.
+// notify is called with the request once it can be answered. A gate that is
+// not enabled is approved at once, without calling notify.
+func (g *ApprovalGates) Wait(ctx context.Context, gate, summary string, notify func(ApprovalRequest)) (ApprovalVerdict, error) {
+	if !g.Enabled(gate) {
+		return ApprovalVerdict{Approved: true}, nil
+	}
+	g.turn.Lock()
+	defer g.turn.Unlock()
+
+	req := ApprovalRequest{Gate: gate, Summary: summary, RequestedAt: time.Now()}
+	answer := make(chan ApprovalVerdict, 1)
+	g.mu.Lock()
+	g.pending = &req
+	g.answer = answer
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.pending = nil
+		g.answer = nil
+		g.mu.Unlock()
+	}()
+
+	if notify != nil {
+		notify(req)
+	}
+	select {
+	case verdict := <-answer:
+		return verdict, nil
+	case <-ctx.Done():
+		return ApprovalVerdict{}, ctx.Err()
+	}
+}
+
+// Approve lets the run past the pending gate.
+func (g *ApprovalGates) Approve(note string) error {
+	return g.answerPending(ApprovalVerdict{Approved: true, Note: note})
+}
+
+// Reject stops the run at the pending gate.
+func (g *ApprovalGates) Reject(note string) error {
+	return g.answerPending(ApprovalVerdict{Note: note})
+}
+
+// answerPending answers the pending request with verdict.
+func (g *ApprovalGates) answerPending(verdict ApprovalVerdict) error {
+	if g == nil {
+		return fmt.Errorf("no approval is pending")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending == nil {
+		return fmt.Errorf("no approval is pending")
+	}
+	g.answer <- verdict
+	g.pending = nil
+	g.answer = nil
+	return nil
+}
+
+// ApprovalEvent returns the event reporting req, or its verdict if set, for
+// runs that report gates on their own.
+func ApprovalEvent(req ApprovalRequest, verdict *ApprovalVerdict) OrchestratorEvent {
+	if verdict == nil {
+		return OrchestratorEvent{
+			Type:      EventApprovalRequired,
+			Message:   fmt.Sprintf("Approval required at the %s gate: %s", req.Gate, req.Summary),
+			Timestamp: time.Now(),
+		}
+	}
+	message := fmt.Sprintf("Approved at the %s gate", req.Gate)
+	if !verdict.Approved {
+		message = fmt.Sprintf("Rejected at the %s gate", req.Gate)
+	}
+	if verdict.Note != "" {
+		message += ": " + verdict.Note
+	}
+	return OrchestratorEvent{Type: EventApprovalResolved, Message: message, Timestamp: time.Now()}
+}
+
+// awaitApproval holds the session at gate until an operator answers it,
+// reporting the request and its verdict as events and recording the verdict
+// in the audit trail. It returns an error wrapping ErrApprovalRejected if
+// the operator rejects the gate. Gates the policy does not enable pass.
+func (o *Orchestrator) awaitApproval(ctx context.Context, gate, summary string) error {
+	if !o.approvals.Enabled(gate) {
+		return nil
+	}
+	var req ApprovalRequest
+	verdict, err := o.approvals.Wait(ctx, gate, summary, func(r ApprovalRequest) {
+		req = r
+		o.log.Info("waiting for approval", "gate", gate)
+		o.emitEvent(ApprovalEvent(r, nil))
+	})
+	if err != nil {
+		return fmt.Errorf("wait for %s approval: %w", gate, err)
+	}
+	o.emitEvent(ApprovalEvent(req, &verdict))
+
+	decision := Decision{Kind: DecisionApproval, Actor: HumanActor(o.config.Operator), Reason: approvalReason(gate, verdict)}
+	if !verdict.Approved {
+		decision.Kind = DecisionRejection
+		o.recordDecision(decision)
+		o.log.Info("approval rejected", "gate", gate, "note", verdict.Note)
+		return fmt.Errorf("%w: %s", ErrApprovalRejected, decision.Reason)
+	}
+	o.recordDecision(decision)
+	o.log.Info("approval granted", "gate", gate)
+	return nil
+}
+
+// approvalReason describes an operator's verdict at gate for the audit
+// trail.
+func approvalReason(gate string, verdict ApprovalVerdict) string {
+	reason := fmt.Sprintf("%s gate approved by operator", gate)
+	if !verdict.Approved {
+		reason = fmt.Sprintf("%s gate rejected by operator", gate)
+	}
+	if verdict.Note != "" {
+		reason += ": " + verdict.Note
+	}
+	return reason
+}
+
+// decompositionSummary lists the tasks a request was decomposed into, for
+// approval.
+func decompositionSummary(tasks []*models.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d task(s) planned", len(tasks))
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "\n  - %s", task.Title)
+		if len(task.DependsOn) > 0 {
+			fmt.Fprintf(&b, " (after %s)", strings.Join(task.DependsOn, ", "))
+		}
+	}
+	return b.String()
+}
+
+// Approve lets the session past the gate it is held at.
+func (o *Orchestrator) Approve(note string) error {
+	return o.approvals.Approve(note)
+}
+
+// Reject stops the session at the gate it is held at.
+func (o *Orchestrator) Reject(note string) error {
+	return o.approvals.Reject(note)
+}
+
+// gateControl serves the control socket while a run waits at a gate with
+// no session running: only status, approvals and abort apply.
+type gateControl struct {
+	gates *ApprovalGates
+	abort func()
+}
+
+func (c gateControl) Pause()  {}
+func (c gateControl) Resume() {}
+
+func (c gateControl) SetMaxAgents(int) error {
+	return fmt.Errorf("no session is running; the run is waiting for approval")
+}
+
+func (c gateControl) CancelTask(taskID string) error {
+	return fmt.Errorf("task %s is not running", taskID)
+}
+
+func (c gateControl) Abort()                 { c.abort() }
+func (c gateControl) Approve(n string) error { return c.gates.Approve(n) }
+func (c gateControl) Reject(n string) error  { return c.gates.Reject(n) }
+
+func (c gateControl) ControlStatus() ControlStatus {
+	status := ControlStatus{Running: []RunningTask{}}
+	if req, ok := c.gates.Pending(); ok {
+		status.Approval = &req
+	}
+	return status
+}
+
+// ServeApprovals opens the control socket of repoPath for a run waiting at
+// one of gates between sessions, so operators can answer it with alphie
+// control. Abort requests call abort.
+func ServeApprovals(repoPath string, gates *ApprovalGates, abort func()) (*ControlServer, error) {
+	return StartControlServer(ControlSocketPath(repoPath), gateControl{gates: gates, abort: abort})
+}
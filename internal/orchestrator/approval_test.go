@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// answerWhenPending answers the pending gate of g with answer once one is
+// pending.
+func answerWhenPending(t *testing.T, g *ApprovalGates, answer func() error) {
+	t.Helper()
+	go func() {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, ok := g.Pending(); ok {
+				if err := answer(); err != nil {
+					t.Errorf("answer error = %v", err)
+				}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Error("no approval became pending")
+	}()
+}
+
+func TestApprovalGates_DisabledGatePasses(t *testing.T) {
+	g := NewApprovalGates(policy.ApprovalPolicy{Gates: []string{policy.ApprovalMerge}})
+	notified := false
+	verdict, err := g.Wait(context.Background(), policy.ApprovalDecomposition, "plan", func(ApprovalRequest) { notified = true })
+	if err != nil || !verdict.Approved {
+		t.Fatalf("Wait() = %+v, %v; want approved", verdict, err)
+	}
+	if notified {
+		t.Error("expected no notification for a disabled gate")
+	}
+
+	var nilGates *ApprovalGates
+	if nilGates.Enabled(policy.ApprovalMerge) {
+		t.Error("expected nil gates to enable nothing")
+	}
+}
+
+func TestApprovalGates_WaitForAnswer(t *testing.T) {
+	g := NewApprovalGates(policy.ApprovalPolicy{Gates: []string{policy.ApprovalMerge}})
+	answerWhenPending(t, g, func() error { return g.Reject("too risky") })
+
+	var notified ApprovalRequest
+	verdict, err := g.Wait(context.Background(), policy.ApprovalMerge, "3 files", func(r ApprovalRequest) { notified = r })
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if verdict.Approved || verdict.Note != "too risky" {
+		t.Errorf("verdict = %+v, want rejected with note", verdict)
+	}
+	if notified.Gate != policy.ApprovalMerge || notified.Summary != "3 files" {
+		t.Errorf("notified = %+v", notified)
+	}
+	if _, ok := g.Pending(); ok {
+		t.Error("expected no pending approval after the answer")
+	}
+	if err := g.Approve(""); err == nil {
+		t.Error("expected an error approving with no approval pending")
+	}
+}
+
+func TestApprovalGates_WaitCancelled(t *testing.T) {
+	g := NewApprovalGates(policy.ApprovalPolicy{Gates: []string{policy.ApprovalIteration}})
+	ctx, cancel := context.WithCancel(context.Background())
+	answerWhenPending(t, g, func() error { cancel(); return nil })
+
+	if _, err := g.Wait(ctx, policy.ApprovalIteration, "next", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want context.Canceled", err)
+	}
+	if _, ok := g.Pending(); ok {
+		t.Error("expected no pending approval after cancellation")
+	}
+}
+
+func TestOrchestrator_AwaitApproval(t *testing.T) {
+	gates := NewApprovalGates(policy.ApprovalPolicy{Gates: []string{policy.ApprovalDecomposition}})
+	o := &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "s1"},
+		emitter:   NewEventEmitter(10),
+		pauseCtrl: NewPauseController(),
+		approvals: gates,
+	}
+
+	answerWhenPending(t, gates, func() error {
+		if o.ControlStatus().Approval == nil {
+			t.Error("expected the control status to report the pending gate")
+		}
+		return o.Approve("ok")
+	})
+	tasks := []*models.Task{{ID: "t1", Title: "Add login"}, {ID: "t2", Title: "Add logout", DependsOn: []string{"t1"}}}
+	if err := o.awaitApproval(context.Background(), policy.ApprovalDecomposition, decompositionSummary(tasks)); err != nil {
+		t.Fatalf("awaitApproval() error = %v", err)
+	}
+
+	required := <-o.Events()
+	if required.Type != EventApprovalRequired || !strings.Contains(required.Message, "Add logout (after t1)") {
+		t.Errorf("first event = %+v, want approval_required with the plan", required)
+	}
+	resolved := <-o.Events()
+	if resolved.Type != EventApprovalResolved || resolved.Message != "Approved at the decomposition gate: ok" {
+		t.Errorf("second event = %+v, want approval_resolved", resolved)
+	}
+
+	answerWhenPending(t, gates, func() error { return o.Reject("") })
+	if err := o.awaitApproval(context.Background(), policy.ApprovalDecomposition, "plan"); !errors.Is(err, ErrApprovalRejected) {
+		t.Errorf("awaitApproval() error = %v, want ErrApprovalRejected", err)
+	}
+
+	if err := o.awaitApproval(context.Background(), policy.ApprovalMerge, "diff"); err != nil {
+		t.Errorf("awaitApproval() of a disabled gate error = %v", err)
+	}
+}
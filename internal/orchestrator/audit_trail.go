@@ -0,0 +1,178 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+// EventDecision is the event log record of an approval, rejection, override
+// or waiver. Decisions are recorded for the audit trail only; they are not
+// sent to event subscribers.
+const EventDecision EventType = "decision"
+
+// EventSessionMerged is the event log record written when the session
+// branch is merged into the main branch.
+const EventSessionMerged EventType = "session_merged"
+
+// DecisionKind classifies a recorded decision.
+type DecisionKind string
+
+const (
+	// DecisionApproval records that work was approved to proceed toward main.
+	DecisionApproval DecisionKind = "approval"
+	// DecisionRejection records that work was stopped from reaching main.
+	DecisionRejection DecisionKind = "rejection"
+	// DecisionOverride records that a default safeguard or limit was lifted.
+	DecisionOverride DecisionKind = "override"
+	// DecisionWaiver records that a check was skipped or did not run.
+	DecisionWaiver DecisionKind = "waiver"
+)
+
+const (
+	// ActorAlphie is the actor of decisions made automatically by policy.
+	ActorAlphie = "alphie"
+	// ActorSecondReviewer is the actor of second review verdicts.
+	ActorSecondReviewer = "second-reviewer"
+)
+
+// HumanActor returns the actor name of a decision made by a person.
+func HumanActor(identity string) string {
+	return "human:" + identity
+}
+
+// Decision is an approval, rejection, override or waiver and who made it.
+type Decision struct {
+	// Kind classifies the decision.
+	Kind DecisionKind
+	// Actor is who made the decision (ActorAlphie, ActorSecondReviewer or a HumanActor).
+	Actor string
+	// TaskID is the task the decision applies to; empty for session-wide decisions.
+	TaskID string
+	// Reason explains the decision.
+	Reason string
+}
+
+// operatorIdentity returns who is running the session, for attributing
+// human decisions: the git user email, else the OS user.
+func operatorIdentity(g git.Runner) string {
+	if g != nil {
+		if email, err := g.Run("config", "user.email"); err == nil && strings.TrimSpace(email) != "" {
+			return strings.TrimSpace(email)
+		}
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
+
+// ChainVerification is the result of checking an event log's hash chain.
+type ChainVerification struct {
+	// Verified is true if every record is chained and intact.
+	Verified bool `json:"verified"`
+	// Records is the number of records checked.
+	Records int `json:"records"`
+	// Unchained counts leading records written before hash chaining existed.
+	Unchained int `json:"unchained,omitempty"`
+	// HeadHash is the hash of the last record. Keeping it elsewhere makes
+	// truncation of the log detectable as well.
+	HeadHash string `json:"head_hash,omitempty"`
+	// BrokenAt is the 1-based position of the first record failing verification.
+	BrokenAt int `json:"broken_at,omitempty"`
+	// Problem describes why verification failed.
+	Problem string `json:"problem,omitempty"`
+}
+
+// VerifyEventLog checks the hash chain of records read from an event log.
+func VerifyEventLog(records []EventLogRecord) ChainVerification {
+	v := ChainVerification{Records: len(records)}
+	prev := ""
+	chained := false
+	for i, rec := range records {
+		fail := func(format string, args ...any) ChainVerification {
+			v.BrokenAt = i + 1
+			v.Problem = fmt.Sprintf(format, args...)
+			v.HeadHash = ""
+			return v
+		}
+		if rec.Hash == "" {
+			if chained {
+				return fail("record %d has no hash", i+1)
+			}
+			v.Unchained++
+			continue
+		}
+		chained = true
+		if rec.Seq != int64(i+1) {
+			return fail("record %d has sequence number %d", i+1, rec.Seq)
+		}
+		if rec.PrevHash != prev {
+			return fail("record %d does not follow the previous record", i+1)
+		}
+		hash, err := rec.computeHash()
+		if err != nil {
+			return fail("record %d: %v", i+1, err)
+		}
+		if hash != rec.Hash {
+			return fail("record %d was modified after it was written", i+1)
+		}
+		prev = rec.Hash
+		v.HeadHash = rec.Hash
+	}
+	switch {
+	case len(records) == 0:
+		v.Problem = "event log is empty"
+	case v.Unchained > 0:
+		v.Problem = fmt.Sprintf("%d records predate hash chaining and cannot be verified", v.Unchained)
+	default:
+		v.Verified = true
+	}
+	return v
+}
+
+// AuditTrail is an exportable record of how a session's changes reached the
+// main branch: its full event log, the decisions made along the way and
+// whether the log's hash chain is intact.
+type AuditTrail struct {
+	// SessionID identifies the session.
+	SessionID string `json:"session_id"`
+	// ExportedAt is when the trail was exported.
+	ExportedAt time.Time `json:"exported_at"`
+	// Chain is the result of verifying the event log's hash chain.
+	Chain ChainVerification `json:"chain"`
+	// Decisions lists approvals, rejections, overrides and waivers in order.
+	Decisions []EventLogRecord `json:"decisions"`
+	// Merges lists merge and session merge records in order.
+	Merges []EventLogRecord `json:"merges"`
+	// Events is the complete event log.
+	Events []EventLogRecord `json:"events"`
+}
+
+// NewAuditTrail builds the audit trail of a session from its event log records.
+func NewAuditTrail(sessionID string, records []EventLogRecord) *AuditTrail {
+	trail := &AuditTrail{
+		SessionID:  sessionID,
+		ExportedAt: time.Now().UTC(),
+		Chain:      VerifyEventLog(records),
+		Decisions:  []EventLogRecord{},
+		Merges:     []EventLogRecord{},
+		Events:     records,
+	}
+	if trail.Events == nil {
+		trail.Events = []EventLogRecord{}
+	}
+	for _, rec := range records {
+		switch rec.Type {
+		case EventDecision:
+			trail.Decisions = append(trail.Decisions, rec)
+		case EventMergeCompleted, EventSessionMerged:
+			trail.Merges = append(trail.Merges, rec)
+		}
+	}
+	return trail
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAuditLog records a short session with a decision and returns its path.
+func writeAuditLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	l, err := NewEventLog(path)
+	if err != nil {
+		t.Fatalf("NewEventLog: %v", err)
+	}
+	l.Record(OrchestratorEvent{Type: EventTaskStarted, TaskID: "t1", Timestamp: time.Now()})
+	l.RecordDecision(Decision{Kind: DecisionApproval, Actor: ActorSecondReviewer, TaskID: "t1", Reason: "looks good"})
+	l.Record(OrchestratorEvent{Type: EventMergeCompleted, TaskID: "t1", Message: "Merge completed"})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEventLogHashChain(t *testing.T) {
+	path := writeAuditLog(t)
+
+	// Reopening the log continues the chain
+	l, err := NewEventLog(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	l.RecordSessionMerge("session-s1", "main", "abc123", ActorAlphie)
+	l.Close()
+
+	records, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("ReadEventLog: %v", err)
+	}
+	if len(records) != 4 || records[3].Seq != 4 || records[3].PrevHash != records[2].Hash {
+		t.Fatalf("chain not continued: %+v", records)
+	}
+	v := VerifyEventLog(records)
+	if !v.Verified || v.HeadHash != records[3].Hash {
+		t.Errorf("VerifyEventLog() = %+v", v)
+	}
+}
+
+func TestVerifyEventLogDetectsTampering(t *testing.T) {
+	records, err := ReadEventLog(writeAuditLog(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited := append([]EventLogRecord(nil), records...)
+	edited[1].Actor = HumanActor("mallory")
+	if v := VerifyEventLog(edited); v.Verified || v.BrokenAt != 2 {
+		t.Errorf("edited record not detected: %+v", v)
+	}
+
+	removed := append([]EventLogRecord{records[0]}, records[2:]...)
+	if v := VerifyEventLog(removed); v.Verified || v.BrokenAt != 2 {
+		t.Errorf("removed record not detected: %+v", v)
+	}
+
+	legacy := []EventLogRecord{{Type: EventTaskStarted}}
+	if v := VerifyEventLog(legacy); v.Verified || v.Unchained != 1 {
+		t.Errorf("unchained log should not verify: %+v", v)
+	}
+}
+
+func TestVerifyEventLogOnDisk(t *testing.T) {
+	path := writeAuditLog(t)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), "looks good", "looks great", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := VerifyEventLog(records); v.Verified || !strings.Contains(v.Problem, "modified") {
+		t.Errorf("VerifyEventLog() = %+v", v)
+	}
+}
+
+func TestNewAuditTrail(t *testing.T) {
+	records, err := ReadEventLog(writeAuditLog(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trail := NewAuditTrail("s1", records)
+	if !trail.Chain.Verified || len(trail.Events) != 3 {
+		t.Fatalf("trail = %+v", trail)
+	}
+	if len(trail.Decisions) != 1 || trail.Decisions[0].Decision != DecisionApproval || trail.Decisions[0].Actor != ActorSecondReviewer {
+		t.Errorf("Decisions = %+v", trail.Decisions)
+	}
+	if len(trail.Merges) != 1 || trail.Merges[0].TaskID != "t1" {
+		t.Errorf("Merges = %+v", trail.Merges)
+	}
+}
@@ -0,0 +1,280 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// BudgetState describes where spending stands relative to a budget.
+type BudgetState int
+
+const (
+	// BudgetOK indicates spending is below the warning threshold.
+	BudgetOK BudgetState = iota
+	// BudgetWarn indicates spending has crossed the soft-warn threshold.
+	BudgetWarn
+	// BudgetExceeded indicates spending has reached the hard limit.
+	BudgetExceeded
+)
+
+// String returns a human-readable representation of the budget state.
+func (s BudgetState) String() string {
+	switch s {
+	case BudgetOK:
+		return "ok"
+	case BudgetWarn:
+		return "warn"
+	case BudgetExceeded:
+		return "exceeded"
+	default:
+		return "unknown"
+	}
+}
+
+// BudgetManager tracks per-task and per-session cost against configured limits.
+// The orchestrator consults it before spawning agents and on every progress update.
+type BudgetManager struct {
+	// policy holds the configured limits.
+	policy policy.BudgetPolicy
+	// taskCosts maps task IDs to the latest known cost for that task.
+	taskCosts map[string]float64
+	// warned tracks which scopes ("session" or a task ID) have already emitted a warning.
+	warned map[string]bool
+	// exceeded tracks which scopes have already been reported as exceeded.
+	exceeded map[string]bool
+	// mu protects all fields.
+	mu sync.Mutex
+}
+
+// NewBudgetManager creates a BudgetManager enforcing the given policy.
+func NewBudgetManager(p policy.BudgetPolicy) *BudgetManager {
+	return &BudgetManager{
+		policy:    p,
+		taskCosts: make(map[string]float64),
+		warned:    make(map[string]bool),
+		exceeded:  make(map[string]bool),
+	}
+}
+
+// Enabled returns true if any limit is configured.
+func (b *BudgetManager) Enabled() bool {
+	return b.policy.TaskLimit > 0 || b.policy.SessionLimit > 0
+}
+
+// RecordTaskCost records the cumulative cost for a task.
+// Costs only ever increase, so a lower value than previously recorded is ignored.
+func (b *BudgetManager) RecordTaskCost(taskID string, cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cost > b.taskCosts[taskID] {
+		b.taskCosts[taskID] = cost
+	}
+}
+
+// TaskCost returns the recorded cost for a task.
+func (b *BudgetManager) TaskCost(taskID string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.taskCosts[taskID]
+}
+
+// SessionCost returns the total recorded cost across all tasks.
+func (b *BudgetManager) SessionCost() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sessionCostLocked()
+}
+
+// sessionCostLocked sums task costs. Caller must hold b.mu.
+func (b *BudgetManager) sessionCostLocked() float64 {
+	var total float64
+	for _, c := range b.taskCosts {
+		total += c
+	}
+	return total
+}
+
+// CheckTask returns the budget state for a single task.
+func (b *BudgetManager) CheckTask(taskID string) BudgetState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(b.taskCosts[taskID], b.policy.TaskLimit)
+}
+
+// CheckSession returns the budget state for the whole session.
+func (b *BudgetManager) CheckSession() BudgetState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state(b.sessionCostLocked(), b.policy.SessionLimit)
+}
+
+// state classifies a cost against a limit. Caller must hold b.mu.
+func (b *BudgetManager) state(cost, limit float64) BudgetState {
+	if limit <= 0 {
+		return BudgetOK
+	}
+	if cost >= limit {
+		return BudgetExceeded
+	}
+	if cost >= limit*b.policy.WarnRatio {
+		return BudgetWarn
+	}
+	return BudgetOK
+}
+
+// ShouldReport returns true the first time a scope reaches the given state.
+// Subsequent calls for the same scope and state return false, so each
+// warning or exceeded notification is emitted only once.
+func (b *BudgetManager) ShouldReport(scope string, st BudgetState) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var seen map[string]bool
+	switch st {
+	case BudgetWarn:
+		seen = b.warned
+	case BudgetExceeded:
+		seen = b.exceeded
+	default:
+		return false
+	}
+	if seen[scope] {
+		return false
+	}
+	seen[scope] = true
+	return true
+}
+
+// TaskLimit returns the configured per-task limit (0 = unlimited).
+func (b *BudgetManager) TaskLimit() float64 {
+	return b.policy.TaskLimit
+}
+
+// SessionLimit returns the configured per-session limit (0 = unlimited).
+func (b *BudgetManager) SessionLimit() float64 {
+	return b.policy.SessionLimit
+}
+
+// SessionError returns a BudgetExceededError if the session budget is
+// exceeded, or nil otherwise.
+func (b *BudgetManager) SessionError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	spent := b.sessionCostLocked()
+	if b.state(spent, b.policy.SessionLimit) != BudgetExceeded {
+		return nil
+	}
+	return &BudgetExceededError{Scope: "session", Spent: spent, Limit: b.policy.SessionLimit}
+}
+
+// recordTaskSpend records a task's cumulative cost and enforces the per-task
+// and per-session budgets. A running task that exceeds its own budget is
+// stopped and failed; exceeding the session budget stops new work from
+// being spawned.
+func (o *Orchestrator) recordTaskSpend(task *models.Task, cost float64, running bool) {
+	if o.budget == nil || !o.budget.Enabled() {
+		return
+	}
+	o.budget.RecordTaskCost(task.ID, cost)
+
+	st := o.budget.CheckTask(task.ID)
+	if o.budget.ShouldReport(task.ID, st) {
+		o.emitBudgetEvent(task, st, o.budget.TaskCost(task.ID), o.budget.TaskLimit())
+		if st == BudgetExceeded && running {
+			o.stopOverBudget(task)
+		}
+	}
+
+	o.enforceSessionBudget()
+}
+
+// stopOverBudget stops the agent of a running task that exceeded its own
+// budget and fails the task with the budget error. The task leaves the
+// in-flight set here, so the run loop does not wait for its result.
+func (o *Orchestrator) stopOverBudget(task *models.Task) {
+	o.inflightMu.Lock()
+	inf, ok := o.inflightTasks[task.ID]
+	if ok {
+		delete(o.inflightTasks, task.ID)
+	}
+	o.inflightMu.Unlock()
+	if !ok {
+		return
+	}
+
+	err := &BudgetExceededError{Scope: task.ID, Spent: o.budget.TaskCost(task.ID), Limit: o.budget.TaskLimit()}
+	o.logger.Log("[budget] task %s exceeded its budget ($%.4f >= $%.2f), stopping its agent", task.ID, err.Spent, err.Limit)
+	if stopped := o.stopAgent(inf, false); stopped != nil {
+		o.failStoppedTask(stopped, inf.agentID, "Task exceeded its budget", err)
+	}
+}
+
+// emitTaskUsage reports the tokens and cost of a finished attempt so
+// callers can attribute spend to the work it was for.
+func (o *Orchestrator) emitTaskUsage(task *models.Task, result *agent.ExecutionResult) {
+	o.emitEvent(OrchestratorEvent{
+		Type:       EventTaskUsage,
+		TaskID:     task.ID,
+		TaskTitle:  task.Title,
+		ProgTaskID: o.progCoord.TaskID(task.ID),
+		ParentID:   task.ParentID,
+		AgentID:    result.AgentID,
+		TokensUsed: result.TokensUsed,
+		Cost:       result.Cost,
+		Timestamp:  time.Now(),
+	})
+}
+
+// enforceSessionBudget checks the session budget and reports it the first
+// time it is exceeded. Returns true if the session budget is exceeded, in
+// which case the run loop spawns nothing new.
+func (o *Orchestrator) enforceSessionBudget() bool {
+	if o.budget == nil || !o.budget.Enabled() {
+		return false
+	}
+	st := o.budget.CheckSession()
+	if o.budget.ShouldReport("session", st) {
+		o.emitBudgetEvent(nil, st, o.budget.SessionCost(), o.budget.SessionLimit())
+		if st == BudgetExceeded {
+			o.logger.Log("[budget] session exceeded its budget ($%.4f >= $%.2f), stopping new work", o.budget.SessionCost(), o.budget.SessionLimit())
+		}
+	}
+	return st == BudgetExceeded
+}
+
+// emitBudgetEvent emits a budget warning or exceeded event.
+// A nil task indicates the session-wide budget.
+func (o *Orchestrator) emitBudgetEvent(task *models.Task, st BudgetState, cost, limit float64) {
+	scope := "Session"
+	errScope := "session"
+	event := OrchestratorEvent{
+		Cost:      cost,
+		Timestamp: time.Now(),
+	}
+	if task != nil {
+		scope = fmt.Sprintf("Task %q", task.Title)
+		errScope = task.ID
+		event.TaskID = task.ID
+		event.TaskTitle = task.Title
+		event.ParentID = task.ParentID
+	}
+
+	switch st {
+	case BudgetWarn:
+		event.Type = EventBudgetWarning
+		event.Message = fmt.Sprintf("%s has used $%.2f of its $%.2f budget", scope, cost, limit)
+	case BudgetExceeded:
+		event.Type = EventBudgetExceeded
+		event.Message = fmt.Sprintf("%s exceeded its $%.2f budget ($%.2f spent)", scope, limit, cost)
+		event.Error = &BudgetExceededError{Scope: errScope, Spent: cost, Limit: limit}
+	default:
+		return
+	}
+	o.emitEvent(event)
+}
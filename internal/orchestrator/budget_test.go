@@ -0,0 +1,178 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestBudgetManager_Disabled(t *testing.T) {
+	b := NewBudgetManager(policy.BudgetPolicy{WarnRatio: 0.8})
+	if b.Enabled() {
+		t.Error("expected budget manager with no limits to be disabled")
+	}
+
+	b.RecordTaskCost("task-1", 100)
+	if st := b.CheckSession(); st != BudgetOK {
+		t.Errorf("expected BudgetOK with no limit, got %s", st)
+	}
+}
+
+func TestBudgetManager_TaskThresholds(t *testing.T) {
+	b := NewBudgetManager(policy.BudgetPolicy{TaskLimit: 1.0, WarnRatio: 0.8})
+
+	b.RecordTaskCost("task-1", 0.5)
+	if st := b.CheckTask("task-1"); st != BudgetOK {
+		t.Errorf("expected BudgetOK at $0.50, got %s", st)
+	}
+
+	b.RecordTaskCost("task-1", 0.85)
+	if st := b.CheckTask("task-1"); st != BudgetWarn {
+		t.Errorf("expected BudgetWarn at $0.85, got %s", st)
+	}
+
+	b.RecordTaskCost("task-1", 1.2)
+	if st := b.CheckTask("task-1"); st != BudgetExceeded {
+		t.Errorf("expected BudgetExceeded at $1.20, got %s", st)
+	}
+
+	// Costs are cumulative, lower values are ignored
+	b.RecordTaskCost("task-1", 0.1)
+	if got := b.TaskCost("task-1"); got != 1.2 {
+		t.Errorf("expected task cost to stay at 1.2, got %v", got)
+	}
+}
+
+func TestBudgetManager_SessionSumsTasks(t *testing.T) {
+	b := NewBudgetManager(policy.BudgetPolicy{SessionLimit: 2.0, WarnRatio: 0.8})
+
+	b.RecordTaskCost("task-1", 1.0)
+	b.RecordTaskCost("task-2", 0.7)
+	if got := b.SessionCost(); got != 1.7 {
+		t.Errorf("expected session cost 1.7, got %v", got)
+	}
+	if st := b.CheckSession(); st != BudgetWarn {
+		t.Errorf("expected BudgetWarn, got %s", st)
+	}
+
+	b.RecordTaskCost("task-3", 0.5)
+	if st := b.CheckSession(); st != BudgetExceeded {
+		t.Errorf("expected BudgetExceeded, got %s", st)
+	}
+}
+
+func TestBudgetManager_ShouldReportOnce(t *testing.T) {
+	b := NewBudgetManager(policy.BudgetPolicy{SessionLimit: 1.0, WarnRatio: 0.8})
+
+	if !b.ShouldReport("session", BudgetWarn) {
+		t.Error("expected first warning to be reported")
+	}
+	if b.ShouldReport("session", BudgetWarn) {
+		t.Error("expected second warning to be suppressed")
+	}
+	if !b.ShouldReport("session", BudgetExceeded) {
+		t.Error("expected first exceeded to be reported")
+	}
+	if b.ShouldReport("session", BudgetOK) {
+		t.Error("expected BudgetOK never to be reported")
+	}
+}
+
+func TestOrchestrator_SessionBudgetExceededStopsSpawning(t *testing.T) {
+	p := policy.Default()
+	p.Budget.SessionLimit = 1.0
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		RepoPath:   "/tmp/test-repo",
+		Tier:       models.TierBuilder,
+		Greenfield: true,
+		Policy:     p,
+	})
+
+	task := &models.Task{ID: "task-1", Title: "Expensive task"}
+	cancelled := false
+	orch.inflightTasks = map[string]*inflight{
+		"task-1": {taskID: "task-1", agentID: "agent-1", cancelFn: func(error) { cancelled = true }},
+	}
+	orch.recordTaskSpend(task, 1.5, true)
+
+	if !orch.enforceSessionBudget() {
+		t.Error("expected the session budget to be reported as exceeded")
+	}
+	// A pause would park the run loop with nothing to resume it
+	if orch.IsPaused() {
+		t.Error("expected the orchestrator not to pause itself when the session budget is exceeded")
+	}
+	if cancelled {
+		t.Error("expected task not to be cancelled without a task budget")
+	}
+
+	select {
+	case event := <-orch.Events():
+		if event.Type != EventBudgetExceeded {
+			t.Errorf("expected %s event, got %s", EventBudgetExceeded, event.Type)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("expected budget exceeded event")
+	}
+}
+
+func TestOrchestrator_TaskBudgetExceededStopsTask(t *testing.T) {
+	p := policy.Default()
+	p.Budget.TaskLimit = 0.5
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		RepoPath:   "/tmp/test-repo",
+		Tier:       models.TierBuilder,
+		Greenfield: true,
+		Policy:     p,
+	})
+
+	task := &models.Task{ID: "task-1", Title: "Runaway task", Status: models.TaskStatusInProgress}
+	if err := orch.graph.Build([]*models.Task{task}); err != nil {
+		t.Fatal(err)
+	}
+	orch.scheduler = NewScheduler(orch.graph, models.TierBuilder, 1)
+	orch.scheduler.OnAgentStart(&models.Agent{ID: "agent-1", TaskID: "task-1"})
+	cancelled := false
+	orch.inflightTasks = map[string]*inflight{
+		"task-1": {taskID: "task-1", agentID: "agent-1", cancelFn: func(error) { cancelled = true }},
+	}
+
+	orch.recordTaskSpend(task, 0.75, true)
+
+	if !cancelled {
+		t.Error("expected task to be cancelled when its budget is exceeded")
+	}
+	// The run loop must not wait for a result the stopped agent may never send
+	if len(orch.inflightTasks) != 0 {
+		t.Error("expected the task to leave the in-flight set")
+	}
+	if task.Status != models.TaskStatusFailed {
+		t.Errorf("task status = %s, want failed", task.Status)
+	}
+	if orch.scheduler.GetRunningCount() != 0 {
+		t.Error("expected the agent to be removed from the scheduler")
+	}
+	if orch.IsPaused() {
+		t.Error("expected orchestrator to keep running without a session budget")
+	}
+
+	for {
+		select {
+		case event := <-orch.Events():
+			if event.Type != EventTaskFailed {
+				continue
+			}
+			if !errors.Is(event.Error, ErrBudgetExceeded) {
+				t.Errorf("task failed event error = %v, want the budget error", event.Error)
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("expected a task failed event")
+		}
+	}
+}
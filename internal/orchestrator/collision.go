@@ -72,6 +72,31 @@ func (c *CollisionChecker) RegisterAgent(agentID string, pathPrefixes, hotspots
 	}
 }
 
+// ObservePaths adds the directories of files an agent actually changed to
+// its path prefixes, so that scheduling avoids where the agent works rather
+// than only where the decomposer expected it to. Declared prefixes are kept,
+// as the agent may still touch them. It returns the prefixes added, or nil if
+// the agent is not registered or every file was already covered.
+func (c *CollisionChecker) ObservePaths(agentID string, files []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hints, ok := c.hints[agentID]
+	if !ok {
+		return nil
+	}
+
+	var added []string
+	for _, prefix := range filePathPrefixes(files) {
+		if coveredByPrefix(prefix, hints.PathPrefixes) {
+			continue
+		}
+		hints.PathPrefixes = append(hints.PathPrefixes, prefix)
+		added = append(added, prefix)
+	}
+	return added
+}
+
 // UnregisterAgent removes an agent from tracking.
 func (c *CollisionChecker) UnregisterAgent(agentID string) {
 	c.mu.Lock()
@@ -323,6 +348,40 @@ func (c *CollisionChecker) hasPathPrefixCollision(taskPrefixes, agentPrefixes []
 	return false
 }
 
+// filePathPrefixes returns the directory prefix of each file, such as
+// "internal/auth/" for "internal/auth/login.go", without duplicates.
+// Root-level files are their own prefix, so that they only collide with
+// themselves.
+func filePathPrefixes(files []string) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, f := range files {
+		f = strings.TrimPrefix(f, "/")
+		if f == "" {
+			continue
+		}
+		prefix := f
+		if idx := strings.LastIndex(f, "/"); idx > 0 {
+			prefix = f[:idx+1]
+		}
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// coveredByPrefix checks if path falls within one of the prefixes.
+func coveredByPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if p != "" && strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // hasHotspotCollision checks if any task prefix might touch a hotspot file.
 func (c *CollisionChecker) hasHotspotCollision(taskPrefixes, hotspots []string) bool {
 	for _, tp := range taskPrefixes {
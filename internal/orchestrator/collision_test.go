@@ -113,6 +113,45 @@ func TestCollisionCheckerNoPathPrefixCollision(t *testing.T) {
 	}
 }
 
+func TestCollisionCheckerObservePaths(t *testing.T) {
+	cc := NewCollisionChecker()
+
+	// The decomposer expected internal/auth/, but the agent also changed the API
+	cc.RegisterAgent("agent-1", []string{"internal/auth/"}, nil)
+	added := cc.ObservePaths("agent-1", []string{
+		"internal/auth/login.go",
+		"internal/api/routes.go",
+		"internal/api/handlers.go",
+		"go.mod",
+	})
+
+	want := []string{"internal/api/", "go.mod"}
+	if len(added) != len(want) || added[0] != want[0] || added[1] != want[1] {
+		t.Errorf("ObservePaths() added %v, want %v", added, want)
+	}
+
+	runningAgents := []*models.Agent{
+		{ID: "agent-1", Status: models.AgentStatusRunning},
+	}
+	task := &models.Task{ID: "task-2", FileBoundaries: []string{"internal/api/"}}
+	if cc.CanSchedule(task, runningAgents) {
+		t.Error("expected task to be blocked by the observed path")
+	}
+
+	// Declared prefixes are kept
+	task = &models.Task{ID: "task-3", FileBoundaries: []string{"internal/auth/session/"}}
+	if cc.CanSchedule(task, runningAgents) {
+		t.Error("expected task to be blocked by the declared path")
+	}
+
+	if added := cc.ObservePaths("agent-1", []string{"internal/api/routes.go"}); added != nil {
+		t.Errorf("ObservePaths() of covered files added %v", added)
+	}
+	if added := cc.ObservePaths("unknown", []string{"cmd/main.go"}); added != nil {
+		t.Errorf("ObservePaths() for an unregistered agent added %v", added)
+	}
+}
+
 func TestCollisionCheckerPrefixContainment(t *testing.T) {
 	cc := NewCollisionChecker()
 
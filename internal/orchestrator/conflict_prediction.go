@@ -0,0 +1,318 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+const (
+	// hotCommitWindow is how many of the latest commits count as recent
+	// when measuring how hot a file is.
+	hotCommitWindow = 20
+	// hotFileMinShare is the share of a file's lines that must come from
+	// recent commits for the file to count as hot.
+	hotFileMinShare = 0.3
+	// maxHotFileScan caps the files blamed per shared directory, keeping the
+	// check cheap in large directories.
+	maxHotFileScan = 20
+	// coldSharePenalty is the probability of tasks sharing a directory
+	// without hot files, relative to sharing one whose files are all hot.
+	coldSharePenalty = 0.4
+)
+
+// ConflictPrediction estimates how likely two tasks are to produce a merge
+// conflict if they run at the same time.
+type ConflictPrediction struct {
+	// Probability is the estimated conflict probability, from 0 to 1.
+	Probability float64
+	// SharedPaths are the directories both tasks are predicted to work in.
+	SharedPaths []string
+	// HotFiles are the recently rewritten files in the shared directories,
+	// hottest first.
+	HotFiles []string
+	// Reason explains the estimate.
+	Reason string
+}
+
+// ConflictPredictor predicts merge conflicts between tasks before they are
+// scheduled together. Tasks are predicted to work in the directories of
+// their file boundaries; two tasks sharing a directory may conflict, and are
+// likely to when the directory holds hot files, files most of whose lines
+// git blame attributes to recent commits. Such files, like route tables and
+// registries, tend to be edited by every task in the area.
+type ConflictPredictor struct {
+	git       git.Runner
+	threshold float64
+	log       *slog.Logger
+
+	mu sync.Mutex
+	// head is the commit the hotness cache was computed at.
+	head    string
+	recent  map[string]bool
+	hotness map[string]float64
+	// serialized records the task pairs already logged as serialized.
+	serialized map[string]bool
+}
+
+// NewConflictPredictor creates a predictor using g for git operations.
+// Task pairs predicted to conflict with at least threshold probability are
+// serialized.
+func NewConflictPredictor(g git.Runner, threshold float64) *ConflictPredictor {
+	return &ConflictPredictor{
+		git:        g,
+		threshold:  threshold,
+		log:        pkgLog,
+		hotness:    make(map[string]float64),
+		serialized: make(map[string]bool),
+	}
+}
+
+// SetLogger sets the logger serialization decisions are logged to.
+func (p *ConflictPredictor) SetLogger(l *slog.Logger) {
+	p.log = l
+}
+
+// ShouldSerialize reports whether task must wait for other rather than run
+// alongside it, and returns the prediction. The first decision to serialize
+// a pair is logged.
+func (p *ConflictPredictor) ShouldSerialize(task, other *models.Task) (*ConflictPrediction, bool) {
+	prediction := p.Predict(task, other)
+	if prediction.Probability < p.threshold {
+		return prediction, false
+	}
+
+	key := task.ID + "|" + other.ID
+	p.mu.Lock()
+	first := !p.serialized[key]
+	p.serialized[key] = true
+	p.mu.Unlock()
+	if first {
+		p.log.Info("serializing tasks predicted to conflict",
+			logging.Task(task.ID), "with", other.ID,
+			"probability", fmt.Sprintf("%.2f", prediction.Probability),
+			"reason", prediction.Reason)
+	}
+	return prediction, true
+}
+
+// Predict estimates the conflict probability of two tasks. Tasks without
+// file boundaries are not predicted to conflict; the other scheduling layers
+// handle them.
+func (p *ConflictPredictor) Predict(a, b *models.Task) *ConflictPrediction {
+	pathsA, pathsB := LeasePaths(a), LeasePaths(b)
+	if len(pathsA) == 0 || len(pathsB) == 0 {
+		return &ConflictPrediction{Reason: "no predicted paths"}
+	}
+
+	for _, pa := range pathsA {
+		for _, pb := range pathsB {
+			if leasePathsOverlap(pa, pb) {
+				return &ConflictPrediction{
+					Probability: 1,
+					SharedPaths: []string{pa},
+					Reason:      fmt.Sprintf("both tasks touch %s", pa),
+				}
+			}
+		}
+	}
+
+	dirsA, dirsB := predictedDirs(pathsA), predictedDirs(pathsB)
+	shared := sharedDirs(dirsA, dirsB)
+	if len(shared) == 0 {
+		return &ConflictPrediction{Reason: "no shared directories"}
+	}
+
+	// The share of the narrower task's work that happens in shared directories
+	fewer := dirsA
+	if len(dirsB) < len(dirsA) {
+		fewer = dirsB
+	}
+	overlapping := 0
+	for _, dir := range fewer {
+		for _, s := range shared {
+			if leasePathsOverlap(dir, s) {
+				overlapping++
+				break
+			}
+		}
+	}
+	overlap := float64(overlapping) / float64(len(fewer))
+
+	hotFiles, maxHot := p.hotFiles(shared)
+	prediction := &ConflictPrediction{
+		Probability: overlap * (coldSharePenalty + (1-coldSharePenalty)*maxHot),
+		SharedPaths: shared,
+		HotFiles:    hotFiles,
+	}
+	prediction.Reason = fmt.Sprintf("both tasks work in %s", strings.Join(shared, ", "))
+	if len(hotFiles) > 0 {
+		prediction.Reason += fmt.Sprintf(" (hot files: %s)", strings.Join(hotFiles, ", "))
+	}
+	return prediction
+}
+
+// predictedDirs returns the directories of paths: a path with a file
+// extension stands for its directory, any other path for itself.
+func predictedDirs(paths []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, p := range paths {
+		dir := strings.TrimSuffix(p, "/")
+		if path.Ext(dir) != "" {
+			dir = path.Dir(dir)
+		}
+		if dir == "." {
+			dir = ""
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// sharedDirs returns the narrower directory of each overlapping pair of a
+// and b, sorted. The repository root is never shared: every task is under it.
+func sharedDirs(a, b []string) []string {
+	seen := make(map[string]bool)
+	var shared []string
+	for _, da := range a {
+		for _, db := range b {
+			if da == "" || db == "" || !leasePathsOverlap(da, db) {
+				continue
+			}
+			dir := da
+			if len(db) > len(da) {
+				dir = db
+			}
+			if !seen[dir] {
+				seen[dir] = true
+				shared = append(shared, dir)
+			}
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+// hotFiles returns the hot files directly in dirs, hottest first, and the
+// hotness of the hottest file scanned.
+func (p *ConflictPredictor) hotFiles(dirs []string) ([]string, float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.refreshLocked() {
+		return nil, 0
+	}
+
+	hotness := make(map[string]float64)
+	maxHot := 0.0
+	for _, dir := range dirs {
+		for _, file := range p.filesInLocked(dir) {
+			h := p.hotnessLocked(file)
+			if h > maxHot {
+				maxHot = h
+			}
+			if h >= hotFileMinShare {
+				hotness[file] = h
+			}
+		}
+	}
+
+	files := make([]string, 0, len(hotness))
+	for file := range hotness {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if hotness[files[i]] != hotness[files[j]] {
+			return hotness[files[i]] > hotness[files[j]]
+		}
+		return files[i] < files[j]
+	})
+	return files, maxHot
+}
+
+// refreshLocked drops the hotness cache when HEAD has moved and loads the
+// recent commits. It returns false if the repository has no commits.
+// Caller must hold p.mu.
+func (p *ConflictPredictor) refreshLocked() bool {
+	head, err := p.git.Run("rev-parse", "HEAD")
+	if err != nil {
+		return false
+	}
+	head = strings.TrimSpace(head)
+	if head == p.head {
+		return true
+	}
+
+	out, err := p.git.Run("rev-list", fmt.Sprintf("--max-count=%d", hotCommitWindow), "HEAD")
+	if err != nil {
+		return false
+	}
+	p.head = head
+	p.recent = make(map[string]bool)
+	for _, commit := range strings.Fields(out) {
+		p.recent[commit] = true
+	}
+	p.hotness = make(map[string]float64)
+	return true
+}
+
+// filesInLocked returns up to maxHotFileScan tracked files directly in dir.
+// Caller must hold p.mu.
+func (p *ConflictPredictor) filesInLocked(dir string) []string {
+	out, err := p.git.Run("ls-files", "--", dir+"/")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range strings.Split(strings.TrimSpace(out), "\n") {
+		if file == "" || path.Dir(file) != dir {
+			continue
+		}
+		files = append(files, file)
+		if len(files) == maxHotFileScan {
+			break
+		}
+	}
+	return files
+}
+
+// hotnessLocked returns the share of file's lines that git blame attributes
+// to recent commits.
+// Caller must hold p.mu.
+func (p *ConflictPredictor) hotnessLocked(file string) float64 {
+	if h, ok := p.hotness[file]; ok {
+		return h
+	}
+
+	h := 0.0
+	if out, err := p.git.Run("blame", "-l", "-s", "HEAD", "--", file); err == nil {
+		lines, recent := 0, 0
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			lines++
+			if p.recent[strings.TrimPrefix(fields[0], "^")] {
+				recent++
+			}
+		}
+		if lines > 0 {
+			h = float64(recent) / float64(lines)
+		}
+	}
+	p.hotness[file] = h
+	return h
+}
@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// initPredictionRepo creates a repository whose internal/api directory was
+// rewritten recently and whose internal/legacy directory was not.
+func initPredictionRepo(t *testing.T) git.Runner {
+	t.Helper()
+	dir := t.TempDir()
+	if err := initGitRepo(dir); err != nil {
+		t.Fatalf("init git repo: %v", err)
+	}
+	g := git.NewRunner(dir)
+	commit := func(file, content string) {
+		t.Helper()
+		full := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Run("add", file); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Run("commit", "-q", "-m", "edit "+file); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("internal/legacy/store.go", "package legacy\n")
+	for i := 0; i < hotCommitWindow; i++ {
+		commit("internal/api/routes.go", fmt.Sprintf("package api\n\n// %d routes\n", i))
+	}
+	return g
+}
+
+func taskIn(id string, boundaries ...string) *models.Task {
+	return &models.Task{ID: id, Title: id, Status: models.TaskStatusPending, FileBoundaries: boundaries}
+}
+
+func TestConflictPredictor_Predict(t *testing.T) {
+	p := NewConflictPredictor(initPredictionRepo(t), 0.7)
+
+	hot := p.Predict(taskIn("a", "internal/api/users.go"), taskIn("b", "internal/api/orders.go"))
+	if hot.Probability != 1 {
+		t.Errorf("hot directory: Probability = %.2f, want 1", hot.Probability)
+	}
+	if want := []string{"internal/api/routes.go"}; !reflect.DeepEqual(hot.HotFiles, want) {
+		t.Errorf("HotFiles = %v, want %v", hot.HotFiles, want)
+	}
+	if want := []string{"internal/api"}; !reflect.DeepEqual(hot.SharedPaths, want) {
+		t.Errorf("SharedPaths = %v, want %v", hot.SharedPaths, want)
+	}
+
+	cold := p.Predict(taskIn("a", "internal/legacy/a.go"), taskIn("b", "internal/legacy/b.go"))
+	if cold.Probability != coldSharePenalty || len(cold.HotFiles) != 0 {
+		t.Errorf("cold directory: %+v, want probability %.2f without hot files", cold, coldSharePenalty)
+	}
+
+	if same := p.Predict(taskIn("a", "internal/legacy/"), taskIn("b", "internal/legacy/store.go")); same.Probability != 1 {
+		t.Errorf("overlapping paths: Probability = %.2f, want 1", same.Probability)
+	}
+	if apart := p.Predict(taskIn("a", "internal/api/users.go"), taskIn("b", "internal/legacy/b.go")); apart.Probability != 0 {
+		t.Errorf("separate directories: Probability = %.2f, want 0", apart.Probability)
+	}
+	if none := p.Predict(taskIn("a"), taskIn("b", "internal/api/users.go")); none.Probability != 0 {
+		t.Errorf("no boundaries: Probability = %.2f, want 0", none.Probability)
+	}
+}
+
+func TestScheduler_SerializesPredictedConflicts(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		taskIn("users", "internal/api/users.go"),
+		taskIn("orders", "internal/api/orders.go"),
+		taskIn("legacy", "internal/legacy/b.go"),
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	if ready := scheduler.Schedule(); len(ready) != 3 {
+		t.Fatalf("without prediction scheduled %d tasks, want 3", len(ready))
+	}
+
+	scheduler.SetConflictPredictor(NewConflictPredictor(initPredictionRepo(t), 0.7))
+	var ids []string
+	for _, task := range scheduler.Schedule() {
+		ids = append(ids, task.ID)
+	}
+	sort.Strings(ids)
+	if len(ids) != 2 || ids[0] != "legacy" || (ids[1] != "users" && ids[1] != "orders") {
+		t.Fatalf("scheduled %v, want one of users and orders plus legacy", ids)
+	}
+
+	// The serialized task waits for the running one
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-1", TaskID: ids[1], Status: models.AgentStatusRunning})
+	for _, task := range scheduler.Schedule() {
+		if task.ID == "users" || task.ID == "orders" {
+			t.Errorf("scheduled %s alongside the running task it conflicts with", task.ID)
+		}
+	}
+}
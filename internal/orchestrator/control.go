@@ -0,0 +1,484 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+)
+
+// controlSocketFile is the control socket, relative to the repository root,
+// that a running session listens on. The session lock guarantees at most one
+// session per repository, so the path is fixed.
+const controlSocketFile = ".alphie/control.sock"
+
+// ControlSocketPath returns the path of the control socket for repoPath.
+func ControlSocketPath(repoPath string) string {
+	return filepath.Join(repoPath, controlSocketFile)
+}
+
+// ControlTarget is what the control socket operates on. *Orchestrator
+// implements it.
+type ControlTarget interface {
+	Pause()
+	Resume()
+	SetMaxAgents(n int) error
+	CancelTask(taskID string) error
+	Abort()
+	Approve(note string) error
+	Reject(note string) error
+	ControlStatus() ControlStatus
+}
+
+// ControlStatus is a snapshot of a running session, as reported by the
+// control socket.
+type ControlStatus struct {
+	SessionID      string        `json:"session_id"`
+	Paused         bool          `json:"paused"`
+	MaxAgents      int           `json:"max_agents"`
+	TasksTotal     int           `json:"tasks_total"`
+	TasksCompleted int           `json:"tasks_completed"`
+	Running        []RunningTask `json:"running"`
+	// Approval is the gate the run is held at, if any.
+	Approval *ApprovalRequest `json:"approval,omitempty"`
+}
+
+// RunningTask is a task an agent is currently working on.
+type RunningTask struct {
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	AgentID   string    `json:"agent_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// controlRequest is the body of control requests that take an argument.
+type controlRequest struct {
+	MaxAgents int    `json:"max_agents,omitempty"`
+	TaskID    string `json:"task_id,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// controlError is the body of a failed control request.
+type controlError struct {
+	Error string `json:"error"`
+}
+
+// ControlServer serves a session's control socket: a local HTTP API over a
+// unix socket that lets operators pause, resume and throttle a run, cancel
+// a task or answer an approval gate, without stopping it.
+//
+// Endpoints (all return the session's ControlStatus on success):
+//
+//	GET  /status
+//	POST /pause
+//	POST /resume
+//	POST /max-agents  {"max_agents": n}
+//	POST /cancel      {"task_id": "..."}
+//	POST /abort
+//	POST /approve     {"note": "..."}
+//	POST /reject      {"note": "..."}
+type ControlServer struct {
+	path     string
+	target   ControlTarget
+	listener net.Listener
+	server   *http.Server
+}
+
+// StartControlServer listens on the unix socket at path and serves control
+// requests for target until Close is called. A socket left behind by a
+// process that is gone is replaced; one that still answers is an error.
+func StartControlServer(path string, target ControlTarget) (*ControlServer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create control socket dir: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, dialErr := net.DialTimeout("unix", path, time.Second); dialErr == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket %s is in use by another session", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale control socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on control socket: %w", err)
+	}
+	// Only the user running the session may control it
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("restrict control socket: %w", err)
+	}
+
+	s := &ControlServer{path: path, target: target, listener: listener}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/pause", s.handlePause)
+	mux.HandleFunc("/resume", s.handleResume)
+	mux.HandleFunc("/max-agents", s.handleMaxAgents)
+	mux.HandleFunc("/cancel", s.handleCancel)
+	mux.HandleFunc("/abort", s.handleAbort)
+	mux.HandleFunc("/approve", s.handleApprove)
+	mux.HandleFunc("/reject", s.handleReject)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			pkgLog.Warn("control socket stopped", logging.Err(err))
+		}
+	}()
+	return s, nil
+}
+
+// Path returns the socket path the server listens on.
+func (s *ControlServer) Path() string {
+	return s.path
+}
+
+// Close stops serving and removes the socket.
+func (s *ControlServer) Close() error {
+	err := s.server.Close()
+	if rmErr := os.Remove(s.path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (s *ControlServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("use GET"))
+		return
+	}
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	s.target.Pause()
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	s.target.Resume()
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleMaxAgents(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeControlRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := s.target.SetMaxAgents(req.MaxAgents); err != nil {
+		writeControlError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeControlRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := s.target.CancelTask(req.TaskID); err != nil {
+		writeControlError(w, http.StatusNotFound, err)
+		return
+	}
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleAbort(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	s.target.Abort()
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleApprove(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeControlRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := s.target.Approve(req.Note); err != nil {
+		writeControlError(w, http.StatusConflict, err)
+		return
+	}
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+func (s *ControlServer) handleReject(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeControlRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := s.target.Reject(req.Note); err != nil {
+		writeControlError(w, http.StatusConflict, err)
+		return
+	}
+	writeControlStatus(w, s.target.ControlStatus())
+}
+
+// decodeControlRequest reads a POSTed control request, writing an error
+// response and returning false if it is not one.
+func decodeControlRequest(w http.ResponseWriter, r *http.Request) (controlRequest, bool) {
+	var req controlRequest
+	if r.Method != http.MethodPost {
+		writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return req, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeControlError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return req, false
+	}
+	return req, true
+}
+
+func writeControlStatus(w http.ResponseWriter, status ControlStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func writeControlError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(controlError{Error: err.Error()})
+}
+
+// ControlClient talks to a running session's control socket.
+type ControlClient struct {
+	path   string
+	client *http.Client
+}
+
+// NewControlClient creates a client for the control socket at path.
+func NewControlClient(path string) *ControlClient {
+	return &ControlClient{
+		path: path,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the session's status.
+func (c *ControlClient) Status(ctx context.Context) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodGet, "/status", nil)
+}
+
+// Pause stops the session from spawning new agents. Running agents finish.
+func (c *ControlClient) Pause(ctx context.Context) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/pause", nil)
+}
+
+// Resume lets a paused session spawn agents again.
+func (c *ControlClient) Resume(ctx context.Context) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/resume", nil)
+}
+
+// SetMaxAgents changes how many agents the session runs at once.
+func (c *ControlClient) SetMaxAgents(ctx context.Context, n int) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/max-agents", &controlRequest{MaxAgents: n})
+}
+
+// CancelTask stops the agent running taskID and fails the task.
+func (c *ControlClient) CancelTask(ctx context.Context, taskID string) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/cancel", &controlRequest{TaskID: taskID})
+}
+
+// Abort stops all running agents and ends the session without merging its
+// work. The session process exits shortly after the call returns.
+func (c *ControlClient) Abort(ctx context.Context) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/abort", nil)
+}
+
+// Approve lets the session past the approval gate it is held at.
+func (c *ControlClient) Approve(ctx context.Context, note string) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/approve", &controlRequest{Note: note})
+}
+
+// Reject stops the session at the approval gate it is held at.
+func (c *ControlClient) Reject(ctx context.Context, note string) (*ControlStatus, error) {
+	return c.do(ctx, http.MethodPost, "/reject", &controlRequest{Note: note})
+}
+
+// do sends a control request and decodes the status it returns. An
+// unreachable socket is reported as ErrNoRunningSession.
+func (c *ControlClient) do(ctx context.Context, method, endpoint string, body *controlRequest) (*ControlStatus, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return nil, fmt.Errorf("encode control request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "http://alphie"+endpoint, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("create control request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil, fmt.Errorf("%w (no control socket at %s)", ErrNoRunningSession, c.path)
+		}
+		return nil, fmt.Errorf("control request %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure controlError
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Error == "" {
+			return nil, fmt.Errorf("control request %s: %s", endpoint, resp.Status)
+		}
+		return nil, fmt.Errorf("control request %s: %s", endpoint, failure.Error)
+	}
+	var status ControlStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decode control status: %w", err)
+	}
+	return &status, nil
+}
+
+// startControlServer opens the session's control socket. A socket that
+// cannot be opened is logged and the session runs without it.
+func (o *Orchestrator) startControlServer() {
+	server, err := StartControlServer(ControlSocketPath(o.config.RepoPath), o)
+	if err != nil {
+		o.log.Warn("control socket unavailable", logging.Err(err))
+		return
+	}
+	o.control = server
+	o.logger.Log("[orchestrator] control socket listening on %s", server.Path())
+}
+
+// stopControlServer closes the session's control socket, if open.
+func (o *Orchestrator) stopControlServer() {
+	if o.control == nil {
+		return
+	}
+	if err := o.control.Close(); err != nil {
+		o.log.Warn("failed to close control socket", logging.Err(err))
+	}
+	o.control = nil
+}
+
+// controlScheduler returns the session's scheduler, or nil until the
+// dependency graph is built. The control socket starts before it exists.
+func (o *Orchestrator) controlScheduler() *Scheduler {
+	o.schedulerMu.RLock()
+	defer o.schedulerMu.RUnlock()
+	return o.scheduler
+}
+
+// SetMaxAgents changes how many agents the running session may run at once.
+// Lowering it lets running agents finish; no new ones start until the
+// count drops below the new limit.
+func (o *Orchestrator) SetMaxAgents(n int) error {
+	if n < 1 {
+		return fmt.Errorf("max agents must be at least 1, got %d", n)
+	}
+	scheduler := o.controlScheduler()
+	if scheduler == nil {
+		return fmt.Errorf("session has not started scheduling tasks")
+	}
+	previous := scheduler.MaxAgents()
+	scheduler.SetMaxAgents(n)
+	o.log.Info("max agents changed", "from", previous, "to", n)
+	o.recordDecision(Decision{
+		Kind:   DecisionOverride,
+		Actor:  HumanActor(o.config.Operator),
+		Reason: fmt.Sprintf("Max agents changed from %d to %d via control socket", previous, n),
+	})
+	return nil
+}
+
+// CancelTask stops the agent running taskID and fails the task without
+// retrying it; its dependents stay blocked. Only running tasks can be
+// cancelled.
+func (o *Orchestrator) CancelTask(taskID string) error {
+	o.inflightMu.Lock()
+	inf, ok := o.inflightTasks[taskID]
+	if ok {
+		delete(o.inflightTasks, taskID)
+	}
+	o.inflightMu.Unlock()
+	if !ok {
+		return fmt.Errorf("task %s is not running", taskID)
+	}
+
+	task := o.stopAgent(inf, false)
+
+	o.log.Info("cancelled task via control socket", logging.Task(taskID), logging.Agent(inf.agentID))
+	o.recordDecision(Decision{
+		Kind:   DecisionRejection,
+		Actor:  HumanActor(o.config.Operator),
+		TaskID: taskID,
+		Reason: "Task cancelled via control socket",
+	})
+
+	if task != nil {
+		o.failStoppedTask(task, inf.agentID, "Task cancelled by operator", nil)
+	}
+	return nil
+}
+
+// ControlStatus returns a snapshot of the session for the control socket.
+func (o *Orchestrator) ControlStatus() ControlStatus {
+	status := ControlStatus{
+		SessionID: o.config.SessionID,
+		Paused:    o.IsPaused(),
+		MaxAgents: o.config.MaxAgents,
+		Running:   []RunningTask{},
+	}
+	if scheduler := o.controlScheduler(); scheduler != nil {
+		status.MaxAgents = scheduler.MaxAgents()
+	}
+	if o.graph != nil {
+		status.TasksTotal = o.graph.Size()
+		status.TasksCompleted = len(o.graph.GetCompletedIDs())
+	}
+
+	o.inflightMu.Lock()
+	for _, inf := range o.inflightTasks {
+		running := RunningTask{TaskID: inf.taskID, AgentID: inf.agentID, StartedAt: inf.startTime}
+		if task := o.graph.GetTask(inf.taskID); task != nil {
+			running.Title = task.Title
+		}
+		status.Running = append(status.Running, running)
+	}
+	o.inflightMu.Unlock()
+	if req, ok := o.approvals.Pending(); ok {
+		status.Approval = &req
+	}
+	sort.Slice(status.Running, func(i, j int) bool {
+		return status.Running[i].StartedAt.Before(status.Running[j].StartedAt)
+	})
+	return status
+}
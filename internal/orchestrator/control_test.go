@@ -0,0 +1,253 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// fakeControlTarget records control requests for tests.
+type fakeControlTarget struct {
+	status    ControlStatus
+	cancelled []string
+	aborted   bool
+	approvals []string
+}
+
+func (f *fakeControlTarget) Pause()  { f.status.Paused = true }
+func (f *fakeControlTarget) Resume() { f.status.Paused = false }
+
+func (f *fakeControlTarget) SetMaxAgents(n int) error {
+	if n < 1 {
+		return fmt.Errorf("max agents must be at least 1, got %d", n)
+	}
+	f.status.MaxAgents = n
+	return nil
+}
+
+func (f *fakeControlTarget) CancelTask(taskID string) error {
+	for _, r := range f.status.Running {
+		if r.TaskID == taskID {
+			f.cancelled = append(f.cancelled, taskID)
+			return nil
+		}
+	}
+	return fmt.Errorf("task %s is not running", taskID)
+}
+
+func (f *fakeControlTarget) Abort() { f.aborted = true }
+
+func (f *fakeControlTarget) Approve(note string) error { return f.answer("approve", note) }
+func (f *fakeControlTarget) Reject(note string) error  { return f.answer("reject", note) }
+
+func (f *fakeControlTarget) answer(verdict, note string) error {
+	if f.status.Approval == nil {
+		return fmt.Errorf("no approval is pending")
+	}
+	f.approvals = append(f.approvals, verdict+":"+note)
+	f.status.Approval = nil
+	return nil
+}
+
+func (f *fakeControlTarget) ControlStatus() ControlStatus { return f.status }
+
+func startTestControlServer(t *testing.T, target ControlTarget) *ControlClient {
+	t.Helper()
+	path := ControlSocketPath(t.TempDir())
+	server, err := StartControlServer(path, target)
+	if err != nil {
+		t.Fatalf("StartControlServer() error = %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return NewControlClient(path)
+}
+
+func TestControlServer_PauseResumeAndMaxAgents(t *testing.T) {
+	target := &fakeControlTarget{status: ControlStatus{SessionID: "abc123", MaxAgents: 3}}
+	client := startTestControlServer(t, target)
+	ctx := context.Background()
+
+	status, err := client.Pause(ctx)
+	if err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if !status.Paused {
+		t.Error("expected session to be paused")
+	}
+
+	status, err = client.SetMaxAgents(ctx, 1)
+	if err != nil {
+		t.Fatalf("SetMaxAgents() error = %v", err)
+	}
+	if status.MaxAgents != 1 {
+		t.Errorf("MaxAgents = %d, want 1", status.MaxAgents)
+	}
+	if _, err := client.SetMaxAgents(ctx, 0); err == nil {
+		t.Error("expected an error for max agents 0")
+	}
+
+	status, err = client.Resume(ctx)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if status.Paused || status.SessionID != "abc123" {
+		t.Errorf("unexpected status after resume: %+v", status)
+	}
+}
+
+func TestControlServer_CancelTask(t *testing.T) {
+	target := &fakeControlTarget{status: ControlStatus{Running: []RunningTask{{TaskID: "t1", AgentID: "a1"}}}}
+	client := startTestControlServer(t, target)
+	ctx := context.Background()
+
+	if _, err := client.CancelTask(ctx, "t1"); err != nil {
+		t.Fatalf("CancelTask() error = %v", err)
+	}
+	if len(target.cancelled) != 1 || target.cancelled[0] != "t1" {
+		t.Errorf("cancelled = %v, want [t1]", target.cancelled)
+	}
+	if _, err := client.CancelTask(ctx, "missing"); err == nil {
+		t.Error("expected an error cancelling a task that is not running")
+	}
+}
+
+func TestControlServer_Abort(t *testing.T) {
+	target := &fakeControlTarget{}
+	client := startTestControlServer(t, target)
+
+	if _, err := client.Abort(context.Background()); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	if !target.aborted {
+		t.Error("expected the session to be aborted")
+	}
+}
+
+func TestControlServer_ApproveAndReject(t *testing.T) {
+	target := &fakeControlTarget{status: ControlStatus{Approval: &ApprovalRequest{Gate: "merge", Summary: "2 files"}}}
+	client := startTestControlServer(t, target)
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Approval == nil || status.Approval.Gate != "merge" {
+		t.Fatalf("Approval = %+v, want the pending merge gate", status.Approval)
+	}
+
+	status, err = client.Approve(ctx, "looks good")
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if status.Approval != nil {
+		t.Errorf("expected no pending approval, got %+v", status.Approval)
+	}
+	if len(target.approvals) != 1 || target.approvals[0] != "approve:looks good" {
+		t.Errorf("approvals = %v, want [approve:looks good]", target.approvals)
+	}
+	if _, err := client.Reject(ctx, ""); err == nil {
+		t.Error("expected an error rejecting with no approval pending")
+	}
+}
+
+func TestControlClient_NoRunningSession(t *testing.T) {
+	client := NewControlClient(ControlSocketPath(t.TempDir()))
+	_, err := client.Status(context.Background())
+	if !errors.Is(err, ErrNoRunningSession) {
+		t.Errorf("Status() error = %v, want ErrNoRunningSession", err)
+	}
+}
+
+func TestStartControlServer_ReplacesStaleSocket(t *testing.T) {
+	path := ControlSocketPath(t.TempDir())
+	first, err := StartControlServer(path, &fakeControlTarget{})
+	if err != nil {
+		t.Fatalf("first StartControlServer() error = %v", err)
+	}
+	if _, err := StartControlServer(path, &fakeControlTarget{}); err == nil {
+		t.Error("expected an error while the socket is in use")
+	}
+
+	// Simulate a crashed session: the socket file remains but nothing listens
+	first.listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	first.Close()
+	if _, err := os.Stat(path); err != nil {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := StartControlServer(path, &fakeControlTarget{})
+	if err != nil {
+		t.Fatalf("StartControlServer() over stale socket error = %v", err)
+	}
+	second.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected socket to be removed on close, stat err = %v", err)
+	}
+}
+
+func TestOrchestrator_CancelTaskAndStatus(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "t1", Title: "First", Status: models.TaskStatusInProgress},
+		{ID: "t2", Title: "Second", DependsOn: []string{"t1"}, Status: models.TaskStatusPending},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatal(err)
+	}
+	o := &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "s1", MaxAgents: 2},
+		graph:     g,
+		collision: NewCollisionChecker(),
+		progCoord: NewProgCoordinator(nil, nil, "", models.TierBuilder, ""),
+		emitter:   NewEventEmitter(10),
+		pauseCtrl: NewPauseController(),
+	}
+	o.scheduler = NewScheduler(g, models.TierBuilder, 2)
+	o.scheduler.OnAgentStart(&models.Agent{ID: "a1", TaskID: "t1"})
+
+	cancelled := false
+	o.inflightTasks = map[string]*inflight{
+		"t1": {taskID: "t1", agentID: "a1", cancelFn: func(error) { cancelled = true }},
+	}
+
+	status := o.ControlStatus()
+	if len(status.Running) != 1 || status.Running[0].Title != "First" || status.TasksTotal != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if err := o.SetMaxAgents(1); err != nil {
+		t.Fatalf("SetMaxAgents() error = %v", err)
+	}
+	if got := o.ControlStatus().MaxAgents; got != 1 {
+		t.Errorf("MaxAgents = %d, want 1", got)
+	}
+
+	if err := o.CancelTask("t1"); err != nil {
+		t.Fatalf("CancelTask() error = %v", err)
+	}
+	if !cancelled {
+		t.Error("expected the agent's context to be cancelled")
+	}
+	if tasks[0].Status != models.TaskStatusFailed {
+		t.Errorf("cancelled task status = %s, want failed", tasks[0].Status)
+	}
+	if tasks[1].Status != models.TaskStatusBlocked {
+		t.Errorf("dependent task status = %s, want blocked", tasks[1].Status)
+	}
+	if o.scheduler.GetRunningCount() != 0 {
+		t.Error("expected the agent to be removed from the scheduler")
+	}
+	if err := o.CancelTask("t1"); err == nil {
+		t.Error("expected an error cancelling a task that is no longer running")
+	}
+}
@@ -0,0 +1,158 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/prog"
+)
+
+// Epic log messages that bracket writing an epic's tasks. An epic with the
+// first but not the second was interrupted while its plan was being written.
+const (
+	epicPlanStartedLog  = "alphie: writing plan"
+	epicPlanCompleteLog = "alphie: plan complete"
+)
+
+// PlannedTask is a task to be written under an epic by WriteEpicPlan.
+type PlannedTask struct {
+	// Key identifies the task within the plan (e.g. an internal task ID).
+	Key string
+	// Title is the prog task title. Existing tasks are matched by title.
+	Title string
+	// Description is the prog task description.
+	Description string
+	// Priority is the prog priority (0 = prog default).
+	Priority int
+	// DependsOn lists the keys of planned tasks this task depends on.
+	DependsOn []string
+}
+
+// StartEpicPlan creates an epic and marks its plan as being written.
+// Call WriteEpicPlan next to add its tasks.
+func StartEpicPlan(client prog.ProgTracker, title string, opts *prog.EpicOptions) (string, error) {
+	epicID, err := client.CreateEpic(title, opts)
+	if err != nil {
+		return "", fmt.Errorf("create epic: %w", err)
+	}
+	if err := client.AddLog(epicID, epicPlanStartedLog); err != nil {
+		return "", fmt.Errorf("mark epic %s as planning: %w", epicID, err)
+	}
+	return epicID, nil
+}
+
+// FindUnfinishedEpic returns the open epic with the given title whose plan
+// was interrupted before all of its tasks were written, or nil if there is none.
+func FindUnfinishedEpic(client prog.ProgTracker, title string) (*prog.Item, error) {
+	epics, err := client.ListOpenOrInProgressEpics()
+	if err != nil {
+		return nil, fmt.Errorf("list epics: %w", err)
+	}
+	for i := range epics {
+		if epics[i].Title != title {
+			continue
+		}
+		complete, err := EpicPlanComplete(client, epics[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if !complete {
+			return &epics[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// EpicPlanComplete reports whether all of an epic's tasks were written.
+// Epics not created through StartEpicPlan are considered complete.
+func EpicPlanComplete(client prog.ProgTracker, epicID string) (bool, error) {
+	logs, err := client.GetLogs(epicID)
+	if err != nil {
+		return false, fmt.Errorf("get logs for epic %s: %w", epicID, err)
+	}
+	started := false
+	for _, l := range logs {
+		switch l.Message {
+		case epicPlanStartedLog:
+			started = true
+		case epicPlanCompleteLog:
+			return true, nil
+		}
+	}
+	return !started, nil
+}
+
+// WriteEpicPlan writes the planned tasks and their dependencies under an
+// epic and marks the plan complete. It can be re-run on an epic whose write
+// was interrupted: existing tasks are reused by title, only missing
+// tasks are created, dependencies are added idempotently, and leftover tasks
+// that are not part of the plan are canceled. Returns the prog task ID of
+// each planned task by key.
+func WriteEpicPlan(client prog.ProgTracker, epicID, project string, tasks []PlannedTask) (map[string]string, error) {
+	existing, err := client.GetChildTasks(epicID)
+	if err != nil {
+		return nil, fmt.Errorf("get tasks of epic %s: %w", epicID, err)
+	}
+	unused := make(map[string][]prog.Item) // title -> reusable tasks
+	for _, item := range existing {
+		if item.Status == prog.StatusCanceled {
+			continue
+		}
+		unused[item.Title] = append(unused[item.Title], item)
+	}
+
+	ids := make(map[string]string, len(tasks))
+	reused := 0
+	for _, task := range tasks {
+		if candidates := unused[task.Title]; len(candidates) > 0 {
+			ids[task.Key] = candidates[0].ID
+			unused[task.Title] = candidates[1:]
+			reused++
+			continue
+		}
+		id, err := client.CreateTask(task.Title, &prog.TaskOptions{
+			Project:     project,
+			Description: task.Description,
+			Priority:    task.Priority,
+			ParentID:    epicID,
+		})
+		if err != nil {
+			return ids, fmt.Errorf("create task %q: %w", task.Title, err)
+		}
+		ids[task.Key] = id
+	}
+
+	for _, task := range tasks {
+		for _, depKey := range task.DependsOn {
+			depID, ok := ids[depKey]
+			if !ok {
+				pkgLog.Warn("prog dependency not found", "epic", epicID, "dependency", depKey, logging.Task(task.Key))
+				continue
+			}
+			if err := client.AddDependency(ids[task.Key], depID); err != nil {
+				return ids, fmt.Errorf("add dependency %s -> %s: %w", ids[task.Key], depID, err)
+			}
+		}
+	}
+
+	// Open tasks left from an interrupted plan that the plan no longer contains
+	for _, leftover := range unused {
+		for _, item := range leftover {
+			if item.Status == prog.StatusDone {
+				continue
+			}
+			if err := client.UpdateStatus(item.ID, prog.StatusCanceled); err != nil {
+				return ids, fmt.Errorf("cancel leftover task %s: %w", item.ID, err)
+			}
+		}
+	}
+
+	if err := client.AddLog(epicID, epicPlanCompleteLog); err != nil {
+		return ids, fmt.Errorf("mark epic %s as planned: %w", epicID, err)
+	}
+	if reused > 0 {
+		pkgLog.Info("completed plan of epic", "epic", epicID, "reused", reused, "created", len(tasks)-reused)
+	}
+	return ids, nil
+}
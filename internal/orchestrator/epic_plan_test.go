@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// newTestProgClient opens a prog client on a fresh database.
+func newTestProgClient(t *testing.T) *prog.Client {
+	t.Helper()
+	db, err := prog.Open(filepath.Join(t.TempDir(), "prog.db"))
+	if err != nil {
+		t.Fatalf("open prog db: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("init prog db: %v", err)
+	}
+	client := prog.NewClient(db, "test-project")
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestCreateEpicAndTasksResumesInterruptedPlan(t *testing.T) {
+	client := newTestProgClient(t)
+
+	// A previous run created the epic and one task, then stopped
+	epicID, err := StartEpicPlan(client, "add login", &prog.EpicOptions{Description: "add login"})
+	if err != nil {
+		t.Fatalf("StartEpicPlan: %v", err)
+	}
+	schemaID, _ := client.CreateTask("Add users table", &prog.TaskOptions{ParentID: epicID})
+	staleID, _ := client.CreateTask("Old approach", &prog.TaskOptions{ParentID: epicID})
+
+	if complete, _ := EpicPlanComplete(client, epicID); complete {
+		t.Fatal("interrupted epic reported as planned")
+	}
+	if epic, err := FindUnfinishedEpic(client, "add login"); err != nil || epic == nil || epic.ID != epicID {
+		t.Fatalf("FindUnfinishedEpic = %v, %v", epic, err)
+	}
+
+	tasks := []*models.Task{
+		{ID: "t1", Title: "Add users table"},
+		{ID: "t2", Title: "Add login endpoint", DependsOn: []string{"t1"}},
+	}
+	coord := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, "")
+	if err := coord.CreateEpicAndTasks("add login", tasks); err != nil {
+		t.Fatalf("CreateEpicAndTasks: %v", err)
+	}
+
+	if coord.EpicID() != epicID {
+		t.Errorf("epic = %s, want the interrupted epic %s", coord.EpicID(), epicID)
+	}
+	if coord.TaskID("t1") != schemaID {
+		t.Errorf("t1 = %s, want existing task %s", coord.TaskID("t1"), schemaID)
+	}
+	deps, _ := client.GetDependencies(coord.TaskID("t2"))
+	if len(deps) != 1 || deps[0] != schemaID {
+		t.Errorf("t2 deps = %v, want [%s]", deps, schemaID)
+	}
+	if stale, _ := client.GetItem(staleID); stale.Status != prog.StatusCanceled {
+		t.Errorf("leftover task status = %s, want canceled", stale.Status)
+	}
+	children, _ := client.GetIncompleteTasks(epicID)
+	if len(children) != 2 {
+		t.Errorf("epic has %d open tasks, want 2", len(children))
+	}
+
+	if complete, _ := EpicPlanComplete(client, epicID); !complete {
+		t.Error("epic not marked planned")
+	}
+	if epic, _ := FindUnfinishedEpic(client, "add login"); epic != nil {
+		t.Errorf("planned epic still reported unfinished: %s", epic.ID)
+	}
+
+	// A new request with the same title gets a new epic
+	again := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, "")
+	if err := again.CreateEpicAndTasks("add login", []*models.Task{{ID: "x", Title: "Other"}}); err != nil {
+		t.Fatalf("CreateEpicAndTasks: %v", err)
+	}
+	if again.EpicID() == epicID {
+		t.Error("completed epic was reused")
+	}
+}
@@ -0,0 +1,151 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/verification"
+)
+
+// Sentinel errors for conditions callers commonly need to branch on.
+// They are always returned wrapped; test for them with errors.Is.
+var (
+	// ErrBudgetExceeded indicates a task or session spent past its cost limit.
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrMergeNeedsHuman indicates a merge conflict could not be resolved automatically.
+	ErrMergeNeedsHuman = errors.New("merge needs human intervention")
+	// ErrMergeQuarantined indicates a low-confidence merge is held for human review.
+	ErrMergeQuarantined = errors.New("merge quarantined for review")
+	// ErrNoRunningSession indicates no session is listening on the
+	// repository's control socket.
+	ErrNoRunningSession = errors.New("no running session")
+	// ErrSessionAborted indicates a session was stopped by an abort request
+	// without merging its work; alphie abort rolls it back.
+	ErrSessionAborted = errors.New("session aborted")
+	// ErrProtectedAreaBlocked indicates a task or merge touches a path the
+	// protected-area policy blocks.
+	ErrProtectedAreaBlocked = errors.New("protected area blocked by policy")
+	// ErrSessionMergeRejected indicates the session merge gate did not
+	// approve merging a finished session; its branch is kept for review.
+	ErrSessionMergeRejected = errors.New("session merge rejected")
+	// ErrApprovalRejected indicates an operator rejected a run at one of
+	// its approval gates.
+	ErrApprovalRejected = errors.New("approval rejected")
+	// ErrSessionInterrupted indicates a session was shut down gracefully
+	// before its tasks finished; it can be resumed from its checkpoint.
+	ErrSessionInterrupted = errors.New("session interrupted")
+	// ErrSessionLocked indicates another Alphie run holds the repository's session lock.
+	ErrSessionLocked = errors.New("session locked by another run")
+	// ErrTaskEscalated indicates a task is parked until a human resolves
+	// its escalation.
+	ErrTaskEscalated = errors.New("task escalated to a human")
+	// ErrVerificationFailed indicates a task or merged result failed verification.
+	// It is the same value as verification.ErrVerificationFailed.
+	ErrVerificationFailed = verification.ErrVerificationFailed
+)
+
+// BudgetExceededError describes which budget was exceeded and by how much.
+// It matches ErrBudgetExceeded with errors.Is.
+type BudgetExceededError struct {
+	// Scope is "session" or the ID of the task that exceeded its budget.
+	Scope string
+	// Spent is the cost recorded when the limit was hit, in dollars.
+	Spent float64
+	// Limit is the configured limit, in dollars.
+	Limit float64
+}
+
+// Error implements the error interface.
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s %v: $%.2f >= $%.2f", e.Scope, ErrBudgetExceeded, e.Spent, e.Limit)
+}
+
+// Unwrap returns ErrBudgetExceeded.
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// MergeConflictError describes a merge that needs a human to resolve.
+// It matches ErrMergeNeedsHuman with errors.Is and also unwraps to the
+// underlying cause, if any.
+type MergeConflictError struct {
+	// TaskID is the task whose branch failed to merge.
+	TaskID string
+	// Files lists the conflicting files.
+	Files []string
+	// Reason explains why automatic resolution gave up.
+	Reason string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *MergeConflictError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrMergeNeedsHuman.Error())
+	if e.TaskID != "" {
+		sb.WriteString(" for task ")
+		sb.WriteString(e.TaskID)
+	}
+	if len(e.Files) > 0 {
+		sb.WriteString(" (")
+		sb.WriteString(strings.Join(e.Files, ", "))
+		sb.WriteString(")")
+	}
+	if e.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Reason)
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns ErrMergeNeedsHuman and the underlying cause.
+func (e *MergeConflictError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrMergeNeedsHuman}
+	}
+	return []error{ErrMergeNeedsHuman, e.Err}
+}
+
+// SessionLockedError describes the run that holds the session lock.
+// It matches ErrSessionLocked with errors.Is.
+type SessionLockedError struct {
+	// Path is the lock file path.
+	Path string
+	// PID is the process holding the lock.
+	PID int
+}
+
+// Error implements the error interface.
+func (e *SessionLockedError) Error() string {
+	return fmt.Sprintf("%v (pid %d, lock %s)", ErrSessionLocked, e.PID, e.Path)
+}
+
+// Unwrap returns ErrSessionLocked.
+func (e *SessionLockedError) Unwrap() error {
+	return ErrSessionLocked
+}
+
+// SessionInterruptedError describes where a gracefully shut down session
+// stopped. It matches ErrSessionInterrupted with errors.Is.
+type SessionInterruptedError struct {
+	// Checkpoint records the session's progress and how to resume it.
+	Checkpoint *Checkpoint
+}
+
+// Error implements the error interface.
+func (e *SessionInterruptedError) Error() string {
+	c := e.Checkpoint
+	return fmt.Sprintf("%v: %d/%d tasks complete, %d interrupted", ErrSessionInterrupted, c.CompletedTasks, c.TotalTasks, len(c.InterruptedTasks))
+}
+
+// Unwrap returns ErrSessionInterrupted.
+func (e *SessionInterruptedError) Unwrap() error {
+	return ErrSessionInterrupted
+}
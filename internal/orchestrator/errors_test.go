@@ -0,0 +1,75 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/verification"
+)
+
+func TestTypedErrors_MatchSentinels(t *testing.T) {
+	cause := errors.New("semantic merge failed")
+
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"budget", &BudgetExceededError{Scope: "session", Spent: 2, Limit: 1}, ErrBudgetExceeded},
+		{"merge", &MergeConflictError{TaskID: "task-1", Files: []string{"a.go"}, Err: cause}, ErrMergeNeedsHuman},
+		{"merge cause", &MergeConflictError{TaskID: "task-1", Err: cause}, cause},
+		{"session lock", &SessionLockedError{Path: "/repo/.alphie/session.lock", PID: 42}, ErrSessionLocked},
+		{"verification alias", verification.ErrVerificationFailed, ErrVerificationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("execution loop: %w", tt.err)
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("expected %v to match %v", wrapped, tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestTypedErrors_As(t *testing.T) {
+	err := fmt.Errorf("run: %w", &BudgetExceededError{Scope: "task-1", Spent: 1.5, Limit: 1})
+
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatal("expected errors.As to find BudgetExceededError")
+	}
+	if budgetErr.Scope != "task-1" || budgetErr.Limit != 1 {
+		t.Errorf("unexpected budget error fields: %+v", budgetErr)
+	}
+}
+
+func TestTaskFailureError(t *testing.T) {
+	failed := false
+	verifyFailed := &agent.ExecutionResult{Error: "verification contract failed", VerifyPassed: &failed, VerifySummary: "2/3 checks failed"}
+	if err := taskFailureError(verifyFailed); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("expected verification failure to wrap ErrVerificationFailed, got %v", err)
+	}
+
+	crashed := &agent.ExecutionResult{Error: "process exited"}
+	if err := taskFailureError(crashed); errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("expected plain failure not to wrap ErrVerificationFailed, got %v", err)
+	}
+}
+
+func TestBudgetManager_SessionError(t *testing.T) {
+	b := NewBudgetManager(policy.BudgetPolicy{SessionLimit: 1.0, WarnRatio: 0.8})
+
+	b.RecordTaskCost("task-1", 0.5)
+	if err := b.SessionError(); err != nil {
+		t.Errorf("expected no error under budget, got %v", err)
+	}
+
+	b.RecordTaskCost("task-2", 0.6)
+	if err := b.SessionError(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+}
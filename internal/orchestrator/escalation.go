@@ -0,0 +1,334 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// Escalation resolution actions. A resolution starts with one of them,
+// optionally followed by a colon and guidance; any other resolution is
+// guidance for a retry.
+const (
+	// EscalationRetry re-runs the task, with the guidance in its prompt.
+	EscalationRetry = "retry"
+	// EscalationSkip treats the task as done, e.g. after fixing it by hand.
+	EscalationSkip = "skip"
+	// EscalationAbandon fails the task.
+	EscalationAbandon = "abandon"
+)
+
+// escalationOptions are the resolutions suggested for every escalation.
+var escalationOptions = []string{
+	EscalationRetry + ": re-run the task; add guidance after the colon",
+	EscalationSkip + ": treat the task as done, e.g. after fixing it by hand",
+	EscalationAbandon + ": give up on the task and its dependents",
+}
+
+// escalatedReasonPrefix marks a task's BlockedReason while it is parked.
+const escalatedReasonPrefix = "escalated:"
+
+// EscalationStore persists the escalation inbox. *state.DB implements it.
+type EscalationStore interface {
+	// CreateEscalation adds an escalation to the inbox.
+	CreateEscalation(e *state.Escalation) error
+	// GetEscalation retrieves an escalation by ID, or nil if it does not exist.
+	GetEscalation(id string) (*state.Escalation, error)
+	// ListEscalations lists escalations, optionally filtered by status.
+	ListEscalations(status *state.EscalationStatus) ([]state.Escalation, error)
+	// ResolveEscalation records a human resolution on an open escalation.
+	ResolveEscalation(id, resolution, resolvedBy string) error
+	// MarkEscalationApplied records that a session acted on a resolution.
+	MarkEscalationApplied(id string) error
+}
+
+// Verify state.DB implements EscalationStore at compile time.
+var _ EscalationStore = (*state.DB)(nil)
+
+// ParseResolution splits a resolution into its action and guidance.
+// Resolutions not starting with a known action are retry guidance.
+func ParseResolution(resolution string) (action, guidance string) {
+	resolution = strings.TrimSpace(resolution)
+	word, rest := resolution, ""
+	if i := strings.IndexAny(resolution, ": \t\n"); i >= 0 {
+		word, rest = resolution[:i], resolution[i+1:]
+	}
+	switch action := strings.ToLower(word); action {
+	case EscalationRetry, EscalationSkip, EscalationAbandon:
+		return action, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ":"))
+	}
+	return EscalationRetry, resolution
+}
+
+// EscalationInbox lets a human list and resolve tasks escalated by any
+// session.
+type EscalationInbox struct {
+	store EscalationStore
+	git   git.Runner
+}
+
+// NewEscalationInbox creates an EscalationInbox for the repository at repoPath.
+func NewEscalationInbox(store EscalationStore, repoPath string) *EscalationInbox {
+	return &EscalationInbox{store: store, git: git.NewRunner(repoPath)}
+}
+
+// List returns the open escalations, or all escalations if all is true.
+func (b *EscalationInbox) List(all bool) ([]state.Escalation, error) {
+	if all {
+		return b.store.ListEscalations(nil)
+	}
+	open := state.EscalationOpen
+	return b.store.ListEscalations(&open)
+}
+
+// Get returns an escalation by ID.
+func (b *EscalationInbox) Get(id string) (*state.Escalation, error) {
+	e, err := b.store.GetEscalation(id)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("escalation %s not found", id)
+	}
+	return e, nil
+}
+
+// Resolve records a resolution for an open escalation. The running session,
+// or the next one to resume the task, applies it.
+func (b *EscalationInbox) Resolve(id, resolution, operator string) (*state.Escalation, error) {
+	if strings.TrimSpace(resolution) == "" {
+		return nil, fmt.Errorf("resolution is required (%s, %s, %s or guidance for a retry)",
+			EscalationRetry, EscalationSkip, EscalationAbandon)
+	}
+	if operator == "" {
+		operator = operatorIdentity(b.git)
+	}
+	if err := b.store.ResolveEscalation(id, strings.TrimSpace(resolution), operator); err != nil {
+		return nil, err
+	}
+	return b.Get(id)
+}
+
+// escalationStore returns the state DB's escalation inbox, if it has one.
+func (o *Orchestrator) escalationStore() (EscalationStore, bool) {
+	store, ok := o.stateDB.(EscalationStore)
+	return store, ok
+}
+
+// escalateTask parks a task that needs human intervention instead of
+// failing it, recording an escalation with why and what went wrong.
+// Returns false if there is no inbox to escalate to.
+func (o *Orchestrator) escalateTask(task *models.Task, reason, context string) bool {
+	store, ok := o.escalationStore()
+	if !ok || o.scheduler == nil {
+		return false
+	}
+
+	e := &state.Escalation{
+		ID:         uuid.New().String()[:8],
+		SessionID:  o.config.SessionID,
+		TaskID:     task.ID,
+		ProgTaskID: o.progCoord.TaskID(task.ID),
+		TaskTitle:  task.Title,
+		Reason:     reason,
+		Context:    context,
+		Options:    escalationOptions,
+	}
+	if err := store.CreateEscalation(e); err != nil {
+		o.log.Warn("failed to escalate task", logging.Task(task.ID), logging.Err(err))
+		return false
+	}
+
+	o.parkTask(task, e)
+	o.progCoord.BlockTask(task.ID, fmt.Sprintf("escalated to a human (%s): %s", e.ID, reason))
+	return true
+}
+
+// parkTask holds a task back from scheduling until its escalation is resolved.
+func (o *Orchestrator) parkTask(task *models.Task, e *state.Escalation) {
+	task.Status = models.TaskStatusBlocked
+	task.BlockedReason = escalatedReasonPrefix + e.ID
+	task.AssignedTo = ""
+	o.updateTaskState(task)
+	o.scheduler.Park(task.ID)
+
+	o.log.Info("task parked awaiting escalation", logging.Task(task.ID), "escalation", e.ID, "reason", e.Reason)
+	o.emitEvent(OrchestratorEvent{
+		Type:      EventTaskBlocked,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		ParentID:  task.ParentID,
+		Message:   fmt.Sprintf("Task escalated: %s (alphie escalations resolve %s)", task.Title, e.ID),
+		Error:     fmt.Errorf("%w: %s", ErrTaskEscalated, e.Reason),
+		Timestamp: time.Now(),
+	})
+}
+
+// restoreEscalations parks tasks whose escalations from earlier sessions
+// are still open and applies those that were resolved since. Called once
+// the scheduler exists, before the first task is scheduled.
+func (o *Orchestrator) restoreEscalations() {
+	store, ok := o.escalationStore()
+	if !ok {
+		return
+	}
+	open := state.EscalationOpen
+	escalations, err := store.ListEscalations(&open)
+	if err != nil {
+		o.log.Warn("failed to load escalations", logging.Err(err))
+		return
+	}
+	for i := range escalations {
+		if task := o.escalatedTask(&escalations[i]); task != nil {
+			o.parkTask(task, &escalations[i])
+		}
+	}
+	o.applyResolvedEscalations()
+}
+
+// applyResolvedEscalations resumes tasks of this session whose escalations
+// have been resolved.
+func (o *Orchestrator) applyResolvedEscalations() {
+	store, ok := o.escalationStore()
+	if !ok {
+		return
+	}
+	resolved := state.EscalationResolved
+	escalations, err := store.ListEscalations(&resolved)
+	if err != nil {
+		o.log.Warn("failed to load resolved escalations", logging.Err(err))
+		return
+	}
+	for i := range escalations {
+		e := &escalations[i]
+		task := o.escalatedTask(e)
+		if task == nil {
+			continue
+		}
+		// Claim the resolution first so no other session applies it too
+		if err := store.MarkEscalationApplied(e.ID); err != nil {
+			o.log.Warn("could not apply escalation", "escalation", e.ID, logging.Task(e.TaskID), logging.Err(err))
+			continue
+		}
+		o.applyResolution(task, e)
+	}
+}
+
+// escalatedTask returns the unfinished task in this session's graph an
+// escalation is for, or nil. Tasks are matched by prog task ID across
+// sessions and by task ID within one.
+func (o *Orchestrator) escalatedTask(e *state.Escalation) *models.Task {
+	id := e.TaskID
+	if e.ProgTaskID != "" {
+		if internalID := o.progCoord.InternalTaskID(e.ProgTaskID); internalID != "" {
+			id = internalID
+		}
+	}
+	task := o.graph.GetTask(id)
+	if task == nil || task.Status == models.TaskStatusDone {
+		return nil
+	}
+	return task
+}
+
+// applyResolution resumes a parked task as the human resolved it.
+func (o *Orchestrator) applyResolution(task *models.Task, e *state.Escalation) {
+	action, guidance := ParseResolution(e.Resolution)
+	o.recordDecision(Decision{
+		Kind:   DecisionOverride,
+		Actor:  HumanActor(e.ResolvedBy),
+		TaskID: task.ID,
+		Reason: fmt.Sprintf("Escalation %s resolved: %s", e.ID, e.Resolution),
+	})
+	o.log.Info("applying escalation", "escalation", e.ID, logging.Task(task.ID), "action", action)
+
+	task.BlockedReason = ""
+	switch action {
+	case EscalationSkip:
+		now := time.Now()
+		task.Status = models.TaskStatusDone
+		task.CompletedAt = &now
+		o.updateTaskState(task)
+		o.graph.MarkComplete(task.ID)
+		o.scheduler.Unpark(task.ID)
+		o.progCoord.CompleteTask(task.ID)
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventTaskCompleted,
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ParentID:  task.ParentID,
+			Message:   fmt.Sprintf("Skipped task by escalation %s: %s", e.ID, task.Title),
+			Timestamp: time.Now(),
+		})
+
+	case EscalationAbandon:
+		task.Status = models.TaskStatusFailed
+		task.Error = fmt.Sprintf("Abandoned by %s (escalation %s)", e.ResolvedBy, e.ID)
+		o.updateTaskState(task)
+		o.scheduler.Unpark(task.ID)
+		o.progCoord.BlockTask(task.ID, task.Error)
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventTaskFailed,
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ParentID:  task.ParentID,
+			Message:   fmt.Sprintf("Task abandoned: %s", task.Title),
+			Error:     fmt.Errorf("%w: %s", ErrTaskEscalated, task.Error),
+			Timestamp: time.Now(),
+		})
+
+	default:
+		// A fresh set of attempts, told what the human said
+		task.Status = models.TaskStatusPending
+		task.AssignedTo = ""
+		task.Error = ""
+		task.ExecutionCount = 0
+		if guidance != "" {
+			task.LastFailure = strings.TrimSpace(fmt.Sprintf("%s\n\nA human reviewed this failure and says: %s", task.LastFailure, guidance))
+		}
+		o.updateTaskState(task)
+		o.graph.MarkIncomplete(task.ID)
+		o.scheduler.Unpark(task.ID)
+		msg := fmt.Sprintf("Resuming task after escalation %s", e.ID)
+		if guidance != "" {
+			msg += ": " + guidance
+		}
+		o.progCoord.LogTask(task.ID, msg)
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventTaskQueued,
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ParentID:  task.ParentID,
+			Message:   msg,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// escalationContext summarizes a failed execution for the human resolving
+// its escalation.
+func escalationContext(task *models.Task, result *agent.ExecutionResult) string {
+	var sb strings.Builder
+	if result.Error != "" {
+		fmt.Fprintf(&sb, "Error: %s\n", result.Error)
+	}
+	if result.VerifySummary != "" {
+		fmt.Fprintf(&sb, "Verification: %s\n", result.VerifySummary)
+	}
+	if task.LastFailure != "" {
+		fmt.Fprintf(&sb, "Last failure: %s\n", task.LastFailure)
+	}
+	if result.LogFile != "" {
+		fmt.Fprintf(&sb, "Log: %s\n", result.LogFile)
+	}
+	return strings.TrimSpace(sb.String())
+}
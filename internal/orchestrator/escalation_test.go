@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// setupEscalationOrchestrator returns an orchestrator whose graph holds
+// "first" and "second", which depends on it, backed by db.
+func setupEscalationOrchestrator(t *testing.T, db *state.DB, firstID string) *Orchestrator {
+	t.Helper()
+	g := graph.New()
+	if err := g.Build([]*models.Task{
+		{ID: firstID, Title: "first", Status: models.TaskStatusPending},
+		{ID: "second", Title: "second", Status: models.TaskStatusPending, DependsOn: []string{firstID}},
+	}); err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	emitter := NewEventEmitter(20)
+	return &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "sess"},
+		graph:     g,
+		scheduler: NewScheduler(g, models.TierBuilder, 2),
+		stateDB:   db,
+		emitter:   emitter,
+		progCoord: NewProgCoordinator(nil, emitter, "", models.TierBuilder, ""),
+	}
+}
+
+// openEscalationDB opens a migrated state database.
+func openEscalationDB(t *testing.T) *state.DB {
+	t.Helper()
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open state db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// scheduledIDs returns the IDs of the tasks the scheduler would start.
+func scheduledIDs(s *Scheduler) []string {
+	var ids []string
+	for _, task := range s.Schedule() {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestParseResolution(t *testing.T) {
+	tests := []struct {
+		resolution, action, guidance string
+	}{
+		{"retry", EscalationRetry, ""},
+		{"Retry: stub the payment client", EscalationRetry, "stub the payment client"},
+		{"skip", EscalationSkip, ""},
+		{"abandon - superseded by #12", EscalationAbandon, "- superseded by #12"},
+		{"the fixture path moved to testdata/", EscalationRetry, "the fixture path moved to testdata/"},
+	}
+	for _, tt := range tests {
+		action, guidance := ParseResolution(tt.resolution)
+		if action != tt.action || guidance != tt.guidance {
+			t.Errorf("ParseResolution(%q) = %q, %q, want %q, %q", tt.resolution, action, guidance, tt.action, tt.guidance)
+		}
+	}
+}
+
+func TestEscalation_ParkAndRetry(t *testing.T) {
+	db := openEscalationDB(t)
+	o := setupEscalationOrchestrator(t, db, "first")
+	task := o.graph.GetTask("first")
+	task.LastFailure = "TestLogin failed"
+	task.ExecutionCount = 3
+
+	if !o.escalateTask(task, "failed with test error after 3 attempt(s)", "Error: TestLogin failed") {
+		t.Fatal("expected the task to be escalated")
+	}
+	if task.Status != models.TaskStatusBlocked || !strings.HasPrefix(task.BlockedReason, escalatedReasonPrefix) {
+		t.Errorf("task = %+v, want parked", task)
+	}
+	if ids := scheduledIDs(o.scheduler); len(ids) != 0 {
+		t.Errorf("scheduled %v, want nothing while parked", ids)
+	}
+
+	inbox := NewEscalationInbox(db, t.TempDir())
+	open, err := inbox.List(false)
+	if err != nil || len(open) != 1 {
+		t.Fatalf("List = %v, %v, want one open escalation", open, err)
+	}
+	if open[0].TaskID != "first" || open[0].Context == "" || len(open[0].Options) != 3 {
+		t.Errorf("escalation = %+v", open[0])
+	}
+
+	// Nothing happens until a human resolves it
+	o.applyResolvedEscalations()
+	if task.Status != models.TaskStatusBlocked {
+		t.Fatalf("task resumed without a resolution: %+v", task)
+	}
+
+	if _, err := inbox.Resolve(open[0].ID, "retry: log in with the test account", "alice"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	o.applyResolvedEscalations()
+	if task.Status != models.TaskStatusPending || task.ExecutionCount != 0 {
+		t.Errorf("task = %+v, want pending with fresh attempts", task)
+	}
+	if !strings.Contains(task.LastFailure, "log in with the test account") {
+		t.Errorf("LastFailure = %q, want the guidance", task.LastFailure)
+	}
+	if ids := scheduledIDs(o.scheduler); len(ids) != 1 || ids[0] != "first" {
+		t.Errorf("scheduled %v, want the resumed task", ids)
+	}
+	if e, _ := inbox.Get(open[0].ID); e.Status != state.EscalationApplied {
+		t.Errorf("escalation status = %s, want applied", e.Status)
+	}
+}
+
+func TestEscalation_Skip(t *testing.T) {
+	db := openEscalationDB(t)
+	o := setupEscalationOrchestrator(t, db, "first")
+	task := o.graph.GetTask("first")
+	if !o.escalateTask(task, "failed", "") {
+		t.Fatal("expected the task to be escalated")
+	}
+
+	open, _ := db.ListEscalations(nil)
+	if _, err := NewEscalationInbox(db, t.TempDir()).Resolve(open[0].ID, "skip", "alice"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	o.applyResolvedEscalations()
+	if task.Status != models.TaskStatusDone {
+		t.Errorf("task = %+v, want done", task)
+	}
+	if ids := scheduledIDs(o.scheduler); len(ids) != 1 || ids[0] != "second" {
+		t.Errorf("scheduled %v, want the dependent task", ids)
+	}
+}
+
+func TestEscalation_RestoredAcrossSessions(t *testing.T) {
+	db := openEscalationDB(t)
+	for _, e := range []*state.Escalation{
+		{ID: "esc-open", TaskID: "old-1", ProgTaskID: "ts-1", Reason: "failed"},
+		{ID: "esc-done", TaskID: "old-2", ProgTaskID: "ts-2", Reason: "failed"},
+	} {
+		if err := db.CreateEscalation(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.ResolveEscalation("esc-done", "abandon", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resumed session gives the prog tasks new internal IDs
+	o := setupEscalationOrchestrator(t, db, "first")
+	o.progCoord.taskIDs["first"] = "ts-1"
+	o.progCoord.taskIDs["second"] = "ts-2"
+	o.restoreEscalations()
+
+	if first := o.graph.GetTask("first"); first.Status != models.TaskStatusBlocked {
+		t.Errorf("first = %+v, want parked by its open escalation", first)
+	}
+	if second := o.graph.GetTask("second"); second.Status != models.TaskStatusFailed {
+		t.Errorf("second = %+v, want abandoned by its resolution", second)
+	}
+	if ids := scheduledIDs(o.scheduler); len(ids) != 0 {
+		t.Errorf("scheduled %v, want nothing", ids)
+	}
+	if e, _ := db.GetEscalation("esc-done"); e.Status != state.EscalationApplied {
+		t.Errorf("esc-done status = %s, want applied", e.Status)
+	}
+}
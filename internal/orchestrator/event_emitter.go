@@ -2,9 +2,11 @@
 package orchestrator
 
 import (
-	"log"
+	"log/slog"
 	"sync/atomic"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
 )
 
 // EventEmitter handles event emission for the orchestrator.
@@ -12,18 +14,34 @@ import (
 type EventEmitter struct {
 	events       chan OrchestratorEvent
 	droppedCount atomic.Uint64
+	recorder     EventRecorder
+	filter       *EventFilter
+	log          *slog.Logger
 }
 
 // NewEventEmitter creates a new EventEmitter with the given buffer size.
 func NewEventEmitter(bufferSize int) *EventEmitter {
 	return &EventEmitter{
 		events: make(chan OrchestratorEvent, bufferSize),
+		log:    logging.Logger("event_emitter"),
 	}
 }
 
+// SetLogger sets the logger of the emitter's records.
+func (e *EventEmitter) SetLogger(l *slog.Logger) {
+	e.log = l
+}
+
 // Emit sends an event to the events channel.
 // If the channel is full, it tries with a timeout before dropping the event.
 func (e *EventEmitter) Emit(event OrchestratorEvent) {
+	if e.recorder != nil {
+		e.recorder.Record(event)
+	}
+	if !e.filter.Allow(event) {
+		return
+	}
+
 	// Try immediate send first
 	select {
 	case e.events <- event:
@@ -39,11 +57,25 @@ func (e *EventEmitter) Emit(event OrchestratorEvent) {
 	case <-time.After(100 * time.Millisecond):
 		// Timeout expired, drop the event
 		count := e.droppedCount.Add(1)
-		log.Printf("[EventEmitter] DROPPED EVENT #%d: type=%s, taskID=%s (channel full, timeout after 100ms)",
-			count, event.Type, event.TaskID)
+		e.log.Warn("dropped event: channel full after 100ms",
+			"dropped", count, "type", event.Type, logging.Task(event.TaskID))
 	}
 }
 
+// SetRecorder sets a recorder that persists every emitted event,
+// including events later dropped because the channel is full.
+// It must be called before events are emitted.
+func (e *EventEmitter) SetRecorder(r EventRecorder) {
+	e.recorder = r
+}
+
+// SetFilter sets the filter that decides which events reach subscribers.
+// Events are recorded before filtering. It must be called before events
+// are emitted.
+func (e *EventEmitter) SetFilter(f *EventFilter) {
+	e.filter = f
+}
+
 // DroppedCount returns the total number of events that have been dropped.
 func (e *EventEmitter) DroppedCount() uint64 {
 	return e.droppedCount.Load()
@@ -0,0 +1,118 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+// EventFilter decides which events reach subscribers (TUI, headless log).
+// It applies a per-type verbosity level and coalesces noisy event types such
+// as agent progress, which otherwise arrive several times per second with
+// near-identical messages. Filtering happens after events are recorded to
+// the session event log, so replay still sees every event.
+type EventFilter struct {
+	window    time.Duration
+	verbosity map[EventType]string
+
+	mu sync.Mutex
+	// last holds the most recent delivered event per coalescing key.
+	last map[coalesceKey]OrchestratorEvent
+
+	suppressed atomic.Uint64
+}
+
+// coalesceKey groups events that replace one another.
+type coalesceKey struct {
+	Type    EventType
+	TaskID  string
+	AgentID string
+}
+
+// NewEventFilter creates an EventFilter from the events policy.
+func NewEventFilter(p policy.EventsPolicy) *EventFilter {
+	verbosity := make(map[EventType]string, len(p.Verbosity))
+	for eventType, level := range p.Verbosity {
+		verbosity[EventType(eventType)] = level
+	}
+	return &EventFilter{
+		window:    p.CoalesceWindow,
+		verbosity: verbosity,
+		last:      make(map[coalesceKey]OrchestratorEvent),
+	}
+}
+
+// Allow reports whether the event should be delivered to subscribers.
+// A nil filter allows every event.
+func (f *EventFilter) Allow(event OrchestratorEvent) bool {
+	if f == nil {
+		return true
+	}
+
+	allow := true
+	switch f.verbosity[event.Type] {
+	case policy.VerbosityOff:
+		allow = false
+	case policy.VerbosityCoalesce:
+		allow = f.coalesce(event)
+	}
+
+	// A finished task emits no more progress; forget its coalescing state
+	switch event.Type {
+	case EventTaskCompleted, EventTaskFailed, EventTaskBlocked:
+		f.forgetTask(event.TaskID)
+	}
+
+	if !allow {
+		f.suppressed.Add(1)
+	}
+	return allow
+}
+
+// coalesce delivers an event unless it repeats the last delivered event for
+// its key or arrives within the coalesce window of it.
+func (f *EventFilter) coalesce(event OrchestratorEvent) bool {
+	key := coalesceKey{Type: event.Type, TaskID: event.TaskID, AgentID: event.AgentID}
+	at := event.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prev, ok := f.last[key]
+	if ok {
+		if prev.Message == event.Message && prev.CurrentAction == event.CurrentAction {
+			return false
+		}
+		if at.Sub(prev.Timestamp) < f.window {
+			return false
+		}
+	}
+	event.Timestamp = at
+	f.last[key] = event
+	return true
+}
+
+// forgetTask drops the coalescing state of a task's events.
+func (f *EventFilter) forgetTask(taskID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key := range f.last {
+		if key.TaskID == taskID {
+			delete(f.last, key)
+		}
+	}
+}
+
+// Suppressed returns the number of events the filter has held back.
+func (f *EventFilter) Suppressed() uint64 {
+	if f == nil {
+		return 0
+	}
+	return f.suppressed.Load()
+}
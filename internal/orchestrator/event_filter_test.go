@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+func TestEventFilter(t *testing.T) {
+	f := NewEventFilter(policy.EventsPolicy{
+		CoalesceWindow: 2 * time.Second,
+		Verbosity: map[string]string{
+			string(EventAgentProgress): policy.VerbosityCoalesce,
+			string(EventTaskQueued):    policy.VerbosityOff,
+		},
+	})
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	progress := func(task string, s int, msg string) OrchestratorEvent {
+		return OrchestratorEvent{Type: EventAgentProgress, TaskID: task, AgentID: "agent-" + task, Message: msg, Timestamp: t0.Add(time.Duration(s) * time.Second)}
+	}
+
+	steps := []struct {
+		name  string
+		event OrchestratorEvent
+		want  bool
+	}{
+		{"first progress", progress("a", 0, "100 tokens"), true},
+		{"within window", progress("a", 1, "200 tokens"), false},
+		{"other task not coalesced with a", progress("b", 1, "50 tokens"), true},
+		{"after window", progress("a", 3, "300 tokens"), true},
+		{"duplicate after window", progress("a", 10, "300 tokens"), false},
+		{"off type", OrchestratorEvent{Type: EventTaskQueued, TaskID: "a"}, false},
+		{"unlisted type", OrchestratorEvent{Type: EventTaskStarted, TaskID: "a"}, true},
+		{"repeated unlisted type", OrchestratorEvent{Type: EventTaskStarted, TaskID: "a"}, true},
+		{"task completed", OrchestratorEvent{Type: EventTaskCompleted, TaskID: "a"}, true},
+		{"progress after completion starts fresh", progress("a", 11, "300 tokens"), true},
+	}
+	for _, step := range steps {
+		if got := f.Allow(step.event); got != step.want {
+			t.Errorf("%s: Allow() = %v, want %v", step.name, got, step.want)
+		}
+	}
+	if f.Suppressed() != 3 {
+		t.Errorf("Suppressed() = %d, want 3", f.Suppressed())
+	}
+
+	var nilFilter *EventFilter
+	if !nilFilter.Allow(progress("a", 0, "x")) || nilFilter.Suppressed() != 0 {
+		t.Error("nil filter should allow everything")
+	}
+}
+
+func TestEventEmitterRecordsFilteredEvents(t *testing.T) {
+	e := NewEventEmitter(10)
+	rec := &captureRecorder{}
+	e.SetRecorder(rec)
+	e.SetFilter(NewEventFilter(policy.EventsPolicy{
+		Verbosity: map[string]string{string(EventAgentProgress): policy.VerbosityOff},
+	}))
+
+	e.Emit(OrchestratorEvent{Type: EventAgentProgress, TaskID: "a"})
+	e.Emit(OrchestratorEvent{Type: EventTaskStarted, TaskID: "a"})
+
+	if len(rec.events) != 2 {
+		t.Errorf("recorded %d events, want 2 (filtering must not affect the event log)", len(rec.events))
+	}
+	if got := len(e.Events()); got != 1 {
+		t.Errorf("delivered %d events, want 1", got)
+	}
+}
+
+// captureRecorder is an EventRecorder that keeps events in memory.
+type captureRecorder struct {
+	events []OrchestratorEvent
+}
+
+func (r *captureRecorder) Record(event OrchestratorEvent) {
+	r.events = append(r.events, event)
+}
@@ -0,0 +1,259 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// EventGraphBuilt is the event log record written when the dependency graph
+// is built. It carries the tasks and their dependencies so the graph can be
+// reconstructed later; it is not sent to event subscribers.
+const EventGraphBuilt EventType = "graph_built"
+
+// EventRecorder persists orchestrator events as they are emitted.
+type EventRecorder interface {
+	Record(event OrchestratorEvent)
+}
+
+// EventLogTask is a task as recorded in a graph_built record.
+type EventLogTask struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// EventLogRecord is one line of a session event log.
+//
+// Records are hash-chained: Hash is the SHA-256 of the record with an empty
+// Hash, and PrevHash is the Hash of the record before it. Editing, removing
+// or reordering a record breaks the chain from that point on.
+type EventLogRecord struct {
+	Seq       int64          `json:"seq,omitempty"`
+	Type      EventType      `json:"type"`
+	Timestamp time.Time      `json:"timestamp"`
+	TaskID    string         `json:"task_id,omitempty"`
+	TaskTitle string         `json:"task_title,omitempty"`
+	ParentID  string         `json:"parent_id,omitempty"`
+	AgentID   string         `json:"agent_id,omitempty"`
+	Decision  DecisionKind   `json:"decision,omitempty"`
+	Actor     string         `json:"actor,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Tasks     []EventLogTask `json:"tasks,omitempty"`
+	PrevHash  string         `json:"prev_hash,omitempty"`
+	Hash      string         `json:"hash,omitempty"`
+}
+
+// computeHash returns the chain hash of the record: the SHA-256 of its JSON
+// encoding with Hash left empty.
+func (r EventLogRecord) computeHash() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// EventLogDir returns the directory holding session event logs.
+func EventLogDir(repoPath string) string {
+	return filepath.Join(repoPath, ".alphie", "events")
+}
+
+// EventLogPath returns the path of the event log for a session.
+func EventLogPath(repoPath, sessionID string) string {
+	return filepath.Join(EventLogDir(repoPath), sessionID+".jsonl")
+}
+
+// EventLog appends orchestrator events to a JSONL file so a session can be
+// inspected after the fact. Agent progress events are not recorded; they
+// are frequent and carry no scheduling information.
+type EventLog struct {
+	mu       sync.Mutex
+	file     *os.File
+	enc      *json.Encoder
+	seq      int64
+	lastHash string
+	closed   bool
+}
+
+// NewEventLog opens (or creates) the event log at path for appending.
+// Appending to an existing log continues its hash chain.
+func NewEventLog(path string) (*EventLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create event log directory: %w", err)
+	}
+	l := &EventLog{}
+	if _, err := os.Stat(path); err == nil {
+		records, err := ReadEventLog(path)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(records); n > 0 {
+			l.seq = int64(n)
+			l.lastHash = records[n-1].Hash
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	l.file = f
+	l.enc = json.NewEncoder(f)
+	return l, nil
+}
+
+// Record appends an event to the log.
+func (l *EventLog) Record(event OrchestratorEvent) {
+	if event.Type == EventAgentProgress || event.Type == EventCostTick {
+		return
+	}
+	rec := EventLogRecord{
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		TaskID:    event.TaskID,
+		TaskTitle: event.TaskTitle,
+		ParentID:  event.ParentID,
+		AgentID:   event.AgentID,
+		Actor:     event.Actor,
+		Message:   event.Message,
+	}
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	if event.Error != nil {
+		rec.Error = event.Error.Error()
+	}
+	l.write(rec)
+}
+
+// RecordGraph appends a graph_built record describing tasks and their dependencies.
+func (l *EventLog) RecordGraph(tasks []*models.Task) {
+	rec := EventLogRecord{
+		Type:      EventGraphBuilt,
+		Timestamp: time.Now(),
+		Message:   fmt.Sprintf("Dependency graph built with %d tasks", len(tasks)),
+	}
+	for _, t := range tasks {
+		rec.Tasks = append(rec.Tasks, EventLogTask{
+			ID:        t.ID,
+			Title:     t.Title,
+			DependsOn: append([]string(nil), t.DependsOn...),
+		})
+	}
+	l.write(rec)
+}
+
+// RecordDecision appends a decision record: who approved, rejected,
+// overrode or waived what, and why.
+func (l *EventLog) RecordDecision(d Decision) {
+	l.write(EventLogRecord{
+		Type:      EventDecision,
+		Timestamp: time.Now(),
+		TaskID:    d.TaskID,
+		Decision:  d.Kind,
+		Actor:     d.Actor,
+		Message:   d.Reason,
+	})
+}
+
+// RecordSessionMerge appends a session_merged record describing the commit
+// that brought the session's work onto the target branch.
+func (l *EventLog) RecordSessionMerge(branch, target, commit, actor string) {
+	l.write(EventLogRecord{
+		Type:      EventSessionMerged,
+		Timestamp: time.Now(),
+		Actor:     actor,
+		Message:   fmt.Sprintf("Merged %s into %s at %s", branch, target, commit),
+	})
+}
+
+// write chains and encodes a record, logging rather than failing on errors
+// so that a broken event log never interrupts a session.
+func (l *EventLog) write(rec EventLogRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	// UTC timestamps re-encode identically, keeping hashes verifiable
+	rec.Timestamp = rec.Timestamp.UTC()
+	// Redact before hashing, so the chain covers what is on disk
+	rec.TaskTitle = redact.String(rec.TaskTitle)
+	rec.Message = redact.String(rec.Message)
+	rec.Error = redact.String(rec.Error)
+	rec.Seq = l.seq + 1
+	rec.PrevHash = l.lastHash
+	hash, err := rec.computeHash()
+	if err != nil {
+		debugLog("[event_log] failed to hash %s event: %v", rec.Type, err)
+		return
+	}
+	rec.Hash = hash
+	if err := l.enc.Encode(rec); err != nil {
+		debugLog("[event_log] failed to record %s event: %v", rec.Type, err)
+		return
+	}
+	l.seq = rec.Seq
+	l.lastHash = rec.Hash
+}
+
+// Close closes the log file. Later records are dropped.
+func (l *EventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.file.Close()
+}
+
+// ReadEventLog reads every record from the event log at path.
+// A truncated final record, as left by a crashed session, is skipped.
+func ReadEventLog(path string) ([]EventLogRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	defer f.Close()
+
+	var records []EventLogRecord
+	var badLine error
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		// A malformed final line is a write cut short by a crash and is
+		// ignored; a malformed line followed by more records is an error.
+		if badLine != nil {
+			return nil, badLine
+		}
+		var rec EventLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			badLine = fmt.Errorf("event log line %d: %w", line, err)
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read event log: %w", err)
+	}
+	return records, nil
+}
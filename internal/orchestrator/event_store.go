@@ -0,0 +1,199 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// Events are written to the state database in batches, at least this often
+// and at most this many at a time, so that frequent progress events do not
+// each cost a transaction.
+const (
+	eventFlushInterval = 500 * time.Millisecond
+	eventFlushBatch    = 200
+)
+
+// EventRecorders fans events out to several recorders.
+type EventRecorders []EventRecorder
+
+// Record passes the event to every recorder.
+func (rs EventRecorders) Record(event OrchestratorEvent) {
+	for _, r := range rs {
+		r.Record(event)
+	}
+}
+
+// EventStore persists every event of a session to the state database,
+// including the agent progress and cost ticks the event log skips, so the
+// session can be replayed in full with ReplaySession. Events are buffered
+// and appended in batches; Close writes the rest.
+type EventStore struct {
+	store     state.SessionEventStore
+	sessionID string
+
+	mu      sync.Mutex
+	pending []state.SessionEvent
+	closed  bool
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewEventStore starts recording the events of a session to store.
+func NewEventStore(store state.SessionEventStore, sessionID string) *EventStore {
+	s := &EventStore{
+		store:     store,
+		sessionID: sessionID,
+		flush:     make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Record queues an event to be appended. Events that cannot be encoded are
+// logged and skipped so that recording never interrupts a session.
+func (s *EventStore) Record(event OrchestratorEvent) {
+	payload, err := encodeEvent(event)
+	if err != nil {
+		debugLog("[event_store] failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	createdAt := event.Timestamp
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.pending = append(s.pending, state.SessionEvent{
+		SessionID: s.sessionID,
+		Type:      string(event.Type),
+		TaskID:    event.TaskID,
+		AgentID:   event.AgentID,
+		Payload:   payload,
+		CreatedAt: createdAt,
+	})
+	if len(s.pending) >= eventFlushBatch {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run appends the queued events on every tick or full batch until stopped.
+func (s *EventStore) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(eventFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.flush:
+		case <-s.stop:
+			s.write()
+			return
+		}
+		s.write()
+	}
+}
+
+// write appends the queued events, logging rather than failing on errors.
+func (s *EventStore) write() {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if err := s.store.AppendSessionEvents(batch); err != nil {
+		debugLog("[event_store] failed to append %d events: %v", len(batch), err)
+	}
+}
+
+// Close appends the remaining events. Later events are dropped.
+func (s *EventStore) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return nil
+}
+
+// storedEvent is the JSON form of a persisted event. Errors do not survive
+// JSON encoding, so they are kept as their messages.
+type storedEvent struct {
+	OrchestratorEvent
+	Error          string `json:",omitempty"`
+	RateLimitError string `json:",omitempty"`
+}
+
+// encodeEvent returns the JSON payload of an event.
+func encodeEvent(event OrchestratorEvent) (string, error) {
+	stored := storedEvent{OrchestratorEvent: event}
+	if event.Error != nil {
+		stored.Error = event.Error.Error()
+	}
+	if rl := event.RateLimit; rl != nil && rl.Err != nil {
+		copied := *rl
+		stored.RateLimitError = copied.Err.Error()
+		copied.Err = nil
+		stored.RateLimit = &copied
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeEvent parses the JSON payload of an event.
+func decodeEvent(payload string) (OrchestratorEvent, error) {
+	var stored storedEvent
+	if err := json.Unmarshal([]byte(payload), &stored); err != nil {
+		return OrchestratorEvent{}, err
+	}
+	event := stored.OrchestratorEvent
+	if stored.Error != "" {
+		event.Error = errors.New(stored.Error)
+	}
+	if event.RateLimit != nil && stored.RateLimitError != "" {
+		event.RateLimit.Err = errors.New(stored.RateLimitError)
+	}
+	return event, nil
+}
+
+// LoadSessionEvents reads the persisted events of a session in the order
+// they were emitted.
+func LoadSessionEvents(store state.SessionEventStore, sessionID string) ([]OrchestratorEvent, error) {
+	stored, err := store.ListSessionEvents(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]OrchestratorEvent, 0, len(stored))
+	for _, se := range stored {
+		event, err := decodeEvent(se.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode session event %d: %w", se.ID, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+func TestEncodeDecodeEvent(t *testing.T) {
+	event := OrchestratorEvent{
+		Type:          EventTaskFailed,
+		TaskID:        "a",
+		Error:         errors.New("tests failed"),
+		Timestamp:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Files:         []string{"main.go"},
+		MergeDecision: &MergeDecision{Strategy: "semantic", ConflictFiles: 2},
+		RateLimit:     &agent.RateLimitEvent{Type: agent.RateLimitThrottled, Model: "m", Err: errors.New("429")},
+	}
+	payload, err := encodeEvent(event)
+	if err != nil {
+		t.Fatalf("encodeEvent: %v", err)
+	}
+	got, err := decodeEvent(payload)
+	if err != nil {
+		t.Fatalf("decodeEvent: %v", err)
+	}
+	if got.Type != event.Type || got.TaskID != "a" || !got.Timestamp.Equal(event.Timestamp) || got.Files[0] != "main.go" {
+		t.Errorf("decoded event = %+v", got)
+	}
+	if got.Error == nil || got.Error.Error() != "tests failed" {
+		t.Errorf("Error = %v, want the message kept", got.Error)
+	}
+	if got.MergeDecision.ConflictFiles != 2 || got.RateLimit.Err == nil || got.RateLimit.Err.Error() != "429" {
+		t.Errorf("merge decision = %+v, rate limit = %+v", got.MergeDecision, got.RateLimit)
+	}
+	if event.RateLimit.Err == nil {
+		t.Error("encodeEvent modified the event's rate limit")
+	}
+}
+
+func TestEventStore(t *testing.T) {
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open state db: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	store := NewEventStore(db, "sess")
+	recorders := EventRecorders{store}
+	now := time.Now()
+	for i := 0; i < eventFlushBatch+5; i++ {
+		recorders.Record(OrchestratorEvent{Type: EventAgentProgress, TaskID: "a", AgentID: "agent-1", TokensUsed: int64(i), Timestamp: now})
+	}
+	recorders.Record(OrchestratorEvent{Type: EventSessionDone, Message: "done", Timestamp: now})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	store.Record(OrchestratorEvent{Type: EventTaskStarted, TaskID: "late"})
+
+	events, err := LoadSessionEvents(db, "sess")
+	if err != nil {
+		t.Fatalf("LoadSessionEvents: %v", err)
+	}
+	if len(events) != eventFlushBatch+6 {
+		t.Fatalf("got %d events, want every event recorded before Close, progress included", len(events))
+	}
+	if e := events[7]; e.Type != EventAgentProgress || e.TokensUsed != 7 || e.AgentID != "agent-1" {
+		t.Errorf("events[7] = %+v", e)
+	}
+	if e := events[len(events)-1]; e.Type != EventSessionDone || e.Message != "done" {
+		t.Errorf("last event = %+v", e)
+	}
+}
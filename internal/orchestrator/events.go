@@ -3,6 +3,8 @@ package orchestrator
 
 import (
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
 )
 
 // EventType represents the type of orchestrator event.
@@ -15,10 +17,18 @@ const (
 	EventTaskCompleted EventType = "task_completed"
 	// EventTaskFailed indicates a task failed.
 	EventTaskFailed EventType = "task_failed"
+	// EventMergeQueued reports a task's position in the merge queue while it
+	// waits to merge.
+	EventMergeQueued EventType = "merge_queued"
 	// EventMergeStarted indicates a merge operation has started.
 	EventMergeStarted EventType = "merge_started"
+	// EventMergePartial reports the files of a conflicting merge that landed
+	// ahead of its conflicts.
+	EventMergePartial EventType = "merge_partial"
 	// EventMergeCompleted indicates a merge operation completed.
 	EventMergeCompleted EventType = "merge_completed"
+	// EventMergeOrderChosen records the order chosen for a batch of pending merges.
+	EventMergeOrderChosen EventType = "merge_order_chosen"
 	// EventSecondReviewStarted indicates a second review has started.
 	EventSecondReviewStarted EventType = "second_review_started"
 	// EventSecondReviewCompleted indicates a second review has completed.
@@ -31,8 +41,53 @@ const (
 	EventTaskQueued EventType = "task_queued"
 	// EventAgentProgress provides periodic updates on agent execution.
 	EventAgentProgress EventType = "agent_progress"
+	// EventAgentStalled warns that an agent has produced no progress for half
+	// its stall timeout, or reports that it was stopped at the timeout.
+	EventAgentStalled EventType = "agent_stalled"
+	// EventResourceLow indicates free disk or memory dropped below its
+	// threshold and no new agents will be spawned until it recovers.
+	EventResourceLow EventType = "resource_low"
+	// EventResourceRecovered indicates free disk and memory are back above
+	// their thresholds and spawning resumed.
+	EventResourceRecovered EventType = "resource_recovered"
+	// EventApprovalRequired indicates the run is held at an approval gate
+	// until an operator approves or rejects it.
+	EventApprovalRequired EventType = "approval_required"
+	// EventApprovalResolved indicates an operator answered an approval gate.
+	EventApprovalResolved EventType = "approval_resolved"
 	// EventEpicCreated indicates a new epic has been created to track subtasks.
 	EventEpicCreated EventType = "epic_created"
+	// EventBudgetWarning indicates spending crossed the soft-warn threshold of a budget.
+	EventBudgetWarning EventType = "budget_warning"
+	// EventBudgetExceeded indicates a task or session budget was exceeded.
+	EventBudgetExceeded EventType = "budget_exceeded"
+	// EventTaskPreempted indicates a running task was stopped and requeued to
+	// free its agent slot for a higher-priority task.
+	EventTaskPreempted EventType = "task_preempted"
+	// EventCostEstimate reports the estimated cost of the session's tasks
+	// before they run.
+	EventCostEstimate EventType = "cost_estimate"
+	// EventTaskUsage reports the tokens and cost one attempt at a task used.
+	// It is emitted once per finished attempt, whatever its outcome.
+	EventTaskUsage EventType = "task_usage"
+	// EventTaskEscalated indicates a task that kept failing validation will
+	// be retried at a higher tier.
+	EventTaskEscalated EventType = "task_escalated"
+	// EventSessionReview reports the summary of what the finished session
+	// merges into the main branch, before it merges.
+	EventSessionReview EventType = "session_review"
+	// EventRateLimited reports an API request that was queued for the
+	// model's rate limits or throttled by the API.
+	EventRateLimited EventType = "rate_limited"
+	// EventCostTick reports the session's running token usage and cost,
+	// including every runner's calls, while it changes.
+	EventCostTick EventType = "cost_tick"
+	// EventQuestionAsked reports a question an agent asked, with the task's
+	// question usage.
+	EventQuestionAsked EventType = "question_asked"
+	// EventQuestionsExhausted indicates a task used up its question
+	// allowance.
+	EventQuestionsExhausted EventType = "questions_exhausted"
 )
 
 // OrchestratorEvent represents an event emitted by the orchestrator.
@@ -44,6 +99,9 @@ type OrchestratorEvent struct {
 	TaskID string
 	// TaskTitle is the title of the related task, if applicable.
 	TaskTitle string
+	// ProgTaskID is the prog task ID of the related task, if it is tracked
+	// in prog (task_usage and task_completed events only).
+	ProgTaskID string
 	// ParentID is the ID of the parent task/epic, if applicable.
 	ParentID string
 	// AgentID is the ID of the related agent, if applicable.
@@ -54,9 +112,13 @@ type OrchestratorEvent struct {
 	Error error
 	// Timestamp is when the event occurred.
 	Timestamp time.Time
-	// TokensUsed is the current total tokens used (for progress events).
+	// TokensUsed is the current total tokens used (for progress events),
+	// the attempt's tokens (for task_usage events), or the session's tokens
+	// (for cost_tick events).
 	TokensUsed int64
-	// Cost is the current total cost (for progress events).
+	// Cost is the current total cost (for progress events), the attempt's
+	// cost (for task_usage events), or the session's cost (for cost_tick
+	// events).
 	Cost float64
 	// Duration is the elapsed time (for progress events).
 	Duration time.Duration
@@ -70,4 +132,30 @@ type OrchestratorEvent struct {
 	WorkersRunning int
 	// WorkersBlocked is the number of tasks blocked by dependencies or collisions.
 	WorkersBlocked int
+	// MergeDecision records how a conflicted merge was routed and why (merge events only).
+	MergeDecision *MergeDecision
+	// MergeOrder records the order chosen for pending merges and why (merge_order_chosen events only).
+	MergeOrder *MergeOrderDecision
+	// Actor identifies who made the decision an event reports, if any
+	// (e.g. the second reviewer for second_review_completed events).
+	Actor string
+	// Concerns lists the issues the second reviewer raised (second_review_completed events only).
+	Concerns []string
+	// Estimate is the per-task and total cost estimate (cost_estimate events only).
+	Estimate *RunEstimate
+	// Files lists the files the task changed (task_completed events only).
+	Files []string
+	// Verification summarizes how the task's work was verified (task_completed events only).
+	Verification string
+	// RateLimit reports the request and the model's rate metrics (rate_limited events only).
+	RateLimit *agent.RateLimitEvent
+	// MergeQueueAhead is the number of merges ahead of the task in the merge
+	// queue, including the one in progress (merge_queued events only).
+	MergeQueueAhead int
+	// QuestionsAsked is the number of questions the task's agents asked so
+	// far (task_started, question_asked and questions_exhausted events).
+	QuestionsAsked int
+	// QuestionsAllowed is the task's question allowance, -1 for unlimited
+	// (task_started, question_asked and questions_exhausted events).
+	QuestionsAllowed int
 }
@@ -0,0 +1,279 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReplayState is the state of a task in a reconstructed dependency graph.
+type ReplayState string
+
+const (
+	// ReplayWaiting means the task has dependencies that are not done yet.
+	ReplayWaiting ReplayState = "waiting"
+	// ReplayReady means every dependency is done but the task was not scheduled yet.
+	ReplayReady ReplayState = "ready"
+	// ReplayQueued means the scheduler picked the task and it is about to start.
+	ReplayQueued ReplayState = "queued"
+	// ReplayRunning means an agent is working on the task.
+	ReplayRunning ReplayState = "running"
+	// ReplayMerging means the task's work is being merged.
+	ReplayMerging ReplayState = "merging"
+	// ReplayBlocked means the task can never run because a dependency failed,
+	// or the orchestrator reported it blocked.
+	ReplayBlocked ReplayState = "blocked"
+	// ReplayDone means the task completed.
+	ReplayDone ReplayState = "done"
+	// ReplayFailed means the task's last attempt failed.
+	ReplayFailed ReplayState = "failed"
+)
+
+// replayStateOrder is the order states are listed in when rendering.
+var replayStateOrder = []ReplayState{
+	ReplayRunning, ReplayMerging, ReplayQueued, ReplayReady,
+	ReplayWaiting, ReplayBlocked, ReplayFailed, ReplayDone,
+}
+
+// ReplayTask is a task in a reconstructed dependency graph.
+type ReplayTask struct {
+	ID        string
+	Title     string
+	State     ReplayState
+	DependsOn []string
+	// WaitingOn lists the dependencies that are not done, for waiting and
+	// blocked tasks.
+	WaitingOn []string
+	// Since is when the task entered its current state.
+	Since time.Time
+	// Note is the message of the event that set the state, if any.
+	Note string
+}
+
+// GraphSnapshot is the dependency graph as it stood at a point in time.
+type GraphSnapshot struct {
+	// Start is the time of the first event in the log.
+	Start time.Time
+	// At is the point in time the snapshot describes.
+	At time.Time
+	// Tasks are the tasks in graph order.
+	Tasks []ReplayTask
+	// LastEvent is the latest event at or before At.
+	LastEvent *EventLogRecord
+}
+
+// GraphAt reconstructs the dependency graph at time at from a session event
+// log. Events after at are ignored. Tasks the scheduler has not touched are
+// classified as ready or waiting from the state of their dependencies, which
+// is what the scheduler saw at that moment.
+func GraphAt(records []EventLogRecord, at time.Time) (*GraphSnapshot, error) {
+	snap := &GraphSnapshot{At: at}
+	byID := make(map[string]*ReplayTask)
+	var order []string
+	// touched records tasks whose state came from an event rather than the graph.
+	touched := make(map[string]bool)
+	graphSeen := false
+	if len(records) > 0 {
+		snap.Start = records[0].Timestamp
+	}
+
+	addTask := func(id, title string) *ReplayTask {
+		if t, ok := byID[id]; ok {
+			if title != "" {
+				t.Title = title
+			}
+			return t
+		}
+		t := &ReplayTask{ID: id, Title: title}
+		byID[id] = t
+		order = append(order, id)
+		return t
+	}
+
+	for i := range records {
+		rec := &records[i]
+		if rec.Timestamp.After(at) {
+			break
+		}
+		snap.LastEvent = rec
+
+		if rec.Type == EventGraphBuilt {
+			graphSeen = true
+			for _, lt := range rec.Tasks {
+				t := addTask(lt.ID, lt.Title)
+				t.DependsOn = append([]string(nil), lt.DependsOn...)
+				if !touched[t.ID] {
+					t.Since = rec.Timestamp
+				}
+			}
+			continue
+		}
+		if rec.TaskID == "" {
+			continue
+		}
+
+		var state ReplayState
+		switch rec.Type {
+		case EventTaskQueued, EventTaskPreempted:
+			state = ReplayQueued
+		case EventTaskStarted:
+			state = ReplayRunning
+		case EventMergeStarted:
+			state = ReplayMerging
+		case EventTaskCompleted:
+			state = ReplayDone
+		case EventTaskFailed:
+			state = ReplayFailed
+		case EventTaskBlocked:
+			state = ReplayBlocked
+		default:
+			continue
+		}
+		t := addTask(rec.TaskID, rec.TaskTitle)
+		t.State = state
+		t.Since = rec.Timestamp
+		t.Note = rec.Message
+		if rec.Error != "" {
+			t.Note = rec.Error
+		}
+		touched[t.ID] = true
+	}
+
+	if !graphSeen {
+		return nil, fmt.Errorf("no dependency graph recorded at or before %s", at.Format(time.RFC3339))
+	}
+
+	// Blocking propagates through chains of untouched tasks, so repeat
+	// until no state changes.
+	for changed := true; changed; {
+		changed = false
+		for _, id := range order {
+			if touched[id] {
+				continue
+			}
+			t := byID[id]
+			state := ReplayReady
+			var waitingOn []string
+			for _, dep := range t.DependsOn {
+				depTask, ok := byID[dep]
+				if ok && depTask.State == ReplayDone {
+					continue
+				}
+				waitingOn = append(waitingOn, dep)
+				if ok && (depTask.State == ReplayFailed || depTask.State == ReplayBlocked) {
+					state = ReplayBlocked
+				} else if state != ReplayBlocked {
+					state = ReplayWaiting
+				}
+			}
+			if state != t.State {
+				changed = true
+			}
+			t.State = state
+			t.WaitingOn = waitingOn
+		}
+	}
+
+	for _, id := range order {
+		snap.Tasks = append(snap.Tasks, *byID[id])
+	}
+	return snap, nil
+}
+
+// Counts returns the number of tasks in each state.
+func (s *GraphSnapshot) Counts() map[ReplayState]int {
+	counts := make(map[ReplayState]int)
+	for _, t := range s.Tasks {
+		counts[t.State]++
+	}
+	return counts
+}
+
+// Stalled reports whether no progress was possible at the snapshot time:
+// nothing was running, merging, queued or ready while unfinished tasks
+// remained. A stalled graph is a deadlock or a failure cascade.
+func (s *GraphSnapshot) Stalled() bool {
+	counts := s.Counts()
+	active := counts[ReplayRunning] + counts[ReplayMerging] + counts[ReplayQueued] + counts[ReplayReady]
+	unfinished := counts[ReplayWaiting] + counts[ReplayBlocked]
+	return active == 0 && unfinished > 0
+}
+
+// Render writes a human-readable view of the snapshot to w, grouping tasks
+// by state and showing what each waiting or blocked task is waiting on.
+func (s *GraphSnapshot) Render(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Graph at %s (+%s)\n", s.At.Format(time.RFC3339), formatOffset(s.At.Sub(s.Start))))
+	if s.LastEvent != nil {
+		sb.WriteString(fmt.Sprintf("Last event: +%s %s", formatOffset(s.LastEvent.Timestamp.Sub(s.Start)), s.LastEvent.Type))
+		if s.LastEvent.TaskID != "" {
+			sb.WriteString(" " + s.LastEvent.TaskID)
+		}
+		sb.WriteString("\n")
+	}
+
+	counts := s.Counts()
+	var summary []string
+	for _, state := range replayStateOrder {
+		if counts[state] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+	sb.WriteString(strings.Join(summary, ", "))
+	sb.WriteString("\n")
+	if s.Stalled() {
+		sb.WriteString("STALLED: no task could make progress at this point\n")
+	}
+
+	titles := make(map[string]string, len(s.Tasks))
+	for _, t := range s.Tasks {
+		titles[t.ID] = t.Title
+	}
+
+	for _, state := range replayStateOrder {
+		var tasks []ReplayTask
+		for _, t := range s.Tasks {
+			if t.State == state {
+				tasks = append(tasks, t)
+			}
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+		sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Since.Before(tasks[j].Since) })
+
+		sb.WriteString(fmt.Sprintf("\n%s (%d)\n", strings.ToUpper(string(state)), len(tasks)))
+		for _, t := range tasks {
+			sb.WriteString(fmt.Sprintf("  %-12s %s  [for %s]\n", t.ID, t.Title, formatOffset(s.At.Sub(t.Since))))
+			if len(t.WaitingOn) > 0 {
+				var deps []string
+				for _, dep := range t.WaitingOn {
+					if title := titles[dep]; title != "" {
+						deps = append(deps, fmt.Sprintf("%s (%s)", dep, title))
+					} else {
+						deps = append(deps, dep)
+					}
+				}
+				sb.WriteString(fmt.Sprintf("               waiting on: %s\n", strings.Join(deps, ", ")))
+			}
+			if t.Note != "" && (state == ReplayFailed || (state == ReplayBlocked && len(t.WaitingOn) == 0)) {
+				sb.WriteString(fmt.Sprintf("               %s\n", t.Note))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatOffset formats a duration to the second for display.
+func formatOffset(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Truncate(time.Second).String()
+}
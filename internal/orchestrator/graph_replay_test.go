@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestEventLogRoundTrip(t *testing.T) {
+	path := EventLogPath(t.TempDir(), "s1")
+	l, err := NewEventLog(path)
+	if err != nil {
+		t.Fatalf("NewEventLog: %v", err)
+	}
+
+	l.RecordGraph([]*models.Task{
+		{ID: "a", Title: "A"},
+		{ID: "b", Title: "B", DependsOn: []string{"a"}},
+	})
+	l.Record(OrchestratorEvent{Type: EventTaskStarted, TaskID: "a", Timestamp: time.Now()})
+	l.Record(OrchestratorEvent{Type: EventAgentProgress, TaskID: "a", Timestamp: time.Now()})
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	l.Record(OrchestratorEvent{Type: EventTaskCompleted, TaskID: "a", Timestamp: time.Now()})
+
+	records, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("ReadEventLog: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2 (progress and post-close events dropped)", len(records))
+	}
+	if records[0].Type != EventGraphBuilt || len(records[0].Tasks) != 2 || records[0].Tasks[1].DependsOn[0] != "a" {
+		t.Errorf("graph record = %+v", records[0])
+	}
+	if records[1].Type != EventTaskStarted || records[1].TaskID != "a" {
+		t.Errorf("event record = %+v", records[1])
+	}
+}
+
+func TestReadEventLogSkipsTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	content := `{"type":"task_started","timestamp":"2026-01-01T00:00:00Z","task_id":"a"}` + "\n" + `{"type":"task_comp`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("ReadEventLog: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("got %d records, want 1", len(records))
+	}
+
+	if err := os.WriteFile(path, []byte(`{bad`+"\n"+content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadEventLog(path); err == nil {
+		t.Error("expected error for malformed line in the middle of the log")
+	}
+}
+
+func TestGraphAt(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+
+	// a -> b -> d, a -> c; b fails, so d is blocked once b fails.
+	records := []EventLogRecord{
+		{Type: EventGraphBuilt, Timestamp: at(0), Tasks: []EventLogTask{
+			{ID: "a", Title: "A"},
+			{ID: "b", Title: "B", DependsOn: []string{"a"}},
+			{ID: "c", Title: "C", DependsOn: []string{"a"}},
+			{ID: "d", Title: "D", DependsOn: []string{"b"}},
+		}},
+		{Type: EventTaskQueued, Timestamp: at(1), TaskID: "a"},
+		{Type: EventTaskStarted, Timestamp: at(2), TaskID: "a"},
+		{Type: EventMergeStarted, Timestamp: at(10), TaskID: "a"},
+		{Type: EventTaskCompleted, Timestamp: at(12), TaskID: "a"},
+		{Type: EventTaskStarted, Timestamp: at(13), TaskID: "b"},
+		{Type: EventTaskFailed, Timestamp: at(20), TaskID: "b", Error: "tests failed"},
+	}
+
+	tests := []struct {
+		name    string
+		at      int
+		want    map[string]ReplayState
+		stalled bool
+	}{
+		{"graph built", 0, map[string]ReplayState{"a": ReplayReady, "b": ReplayWaiting, "c": ReplayWaiting, "d": ReplayWaiting}, false},
+		{"a running", 5, map[string]ReplayState{"a": ReplayRunning, "b": ReplayWaiting}, false},
+		{"a merging", 11, map[string]ReplayState{"a": ReplayMerging}, false},
+		{"a done", 12, map[string]ReplayState{"a": ReplayDone, "b": ReplayReady, "c": ReplayReady, "d": ReplayWaiting}, false},
+		{"b failed", 25, map[string]ReplayState{"b": ReplayFailed, "c": ReplayReady, "d": ReplayBlocked}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, err := GraphAt(records, at(tt.at))
+			if err != nil {
+				t.Fatalf("GraphAt: %v", err)
+			}
+			got := make(map[string]ReplayState)
+			for _, task := range snap.Tasks {
+				got[task.ID] = task.State
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("task %s = %s, want %s", id, got[id], want)
+				}
+			}
+			if snap.Stalled() != tt.stalled {
+				t.Errorf("Stalled() = %v, want %v", snap.Stalled(), tt.stalled)
+			}
+		})
+	}
+
+	if _, err := GraphAt(records, t0.Add(-time.Second)); err == nil {
+		t.Error("expected error before the graph was built")
+	}
+}
+
+func TestGraphAtStalledAndRender(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []EventLogRecord{
+		{Type: EventGraphBuilt, Timestamp: t0, Tasks: []EventLogTask{
+			{ID: "a", Title: "A"},
+			{ID: "b", Title: "B", DependsOn: []string{"a"}},
+			{ID: "c", Title: "C", DependsOn: []string{"b"}},
+		}},
+		{Type: EventTaskStarted, Timestamp: t0.Add(time.Second), TaskID: "a"},
+		{Type: EventTaskFailed, Timestamp: t0.Add(time.Minute), TaskID: "a", Error: "build broken"},
+	}
+
+	snap, err := GraphAt(records, t0.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("GraphAt: %v", err)
+	}
+	// Blocking propagates through b to c.
+	for _, task := range snap.Tasks {
+		if task.ID == "c" && task.State != ReplayBlocked {
+			t.Errorf("c = %s, want blocked", task.State)
+		}
+	}
+	if !snap.Stalled() {
+		t.Error("expected stalled graph")
+	}
+
+	var sb strings.Builder
+	if err := snap.Render(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{"STALLED", "BLOCKED (2)", "FAILED (1)", "build broken", "waiting on: a (A)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("render missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -3,10 +3,13 @@ package orchestrator
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/prog"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
@@ -19,16 +22,28 @@ type LearningCoordinator struct {
 	progCoord *ProgCoordinator
 	// tier is the agent tier used for concept derivation.
 	tier models.Tier
+	// concepts tags learnings with the user's concept taxonomy.
+	// If nil, only the built-in concepts are used.
+	concepts learning.ConceptMatcher
+	log      *slog.Logger
 }
 
-// NewLearningCoordinator creates a new LearningCoordinator.
-func NewLearningCoordinator(progCoord *ProgCoordinator, tier models.Tier) *LearningCoordinator {
+// NewLearningCoordinator creates a new LearningCoordinator. concepts may be
+// nil, in which case learnings are tagged with the built-in concepts.
+func NewLearningCoordinator(progCoord *ProgCoordinator, tier models.Tier, concepts learning.ConceptMatcher) *LearningCoordinator {
 	return &LearningCoordinator{
 		progCoord: progCoord,
 		tier:      tier,
+		concepts:  concepts,
+		log:       logging.Logger("learning"),
 	}
 }
 
+// SetLogger sets the logger of the coordinator's records.
+func (l *LearningCoordinator) SetLogger(log *slog.Logger) {
+	l.log = log
+}
+
 // CaptureOnCompletion extracts learnings from successful task completion
 // and stores them via prog for cross-session knowledge retention.
 func (l *LearningCoordinator) CaptureOnCompletion(task *models.Task, result *agent.ExecutionResult) {
@@ -55,11 +70,11 @@ func (l *LearningCoordinator) CaptureOnCompletion(task *models.Task, result *age
 		Concepts: concepts,
 	})
 	if err != nil {
-		log.Printf("[orchestrator] warning: failed to capture learning for task %s: %v", task.ID, err)
+		l.log.Warn("failed to capture learning", logging.Task(task.ID), logging.Err(err))
 		return
 	}
 
-	log.Printf("[orchestrator] captured learning %s for task %s: %s", learningID, task.ID, learningCandidate.Summary)
+	l.log.Info("captured learning", "learning", learningID, logging.Task(task.ID), "summary", learningCandidate.Summary)
 
 	// Also log to task for traceability
 	l.progCoord.LogTask(task.ID, fmt.Sprintf("Captured learning: %s", learningCandidate.Summary))
@@ -147,34 +162,17 @@ func (l *LearningCoordinator) deriveLearningConcepts(task *models.Task) []string
 		concepts = append(concepts, string(l.tier))
 	}
 
-	// Extract keywords from task title for concepts
-	title := strings.ToLower(task.Title)
-	description := strings.ToLower(task.Description)
-	combined := title + " " + description
-
-	// Common concept patterns
-	conceptKeywords := map[string]string{
-		"test":        "testing",
-		"debug":       "debugging",
-		"fix":         "bug-fix",
-		"implement":   "implementation",
-		"refactor":    "refactoring",
-		"config":      "configuration",
-		"setup":       "setup",
-		"api":         "api",
-		"database":    "database",
-		"frontend":    "frontend",
-		"backend":     "backend",
-		"security":    "security",
-		"performance": "performance",
-		"doc":         "documentation",
-	}
-
-	for keyword, concept := range conceptKeywords {
-		if strings.Contains(combined, keyword) {
-			concepts = append(concepts, concept)
+	// Match the task title and description against the concept taxonomy
+	combined := task.Title + " " + task.Description
+	matched := learning.MatchBuiltinConcepts(combined)
+	if l.concepts != nil {
+		if fromRegistry, err := l.concepts.MatchConcepts(combined); err == nil {
+			matched = fromRegistry
+		} else {
+			l.log.Warn("failed to match concepts, using built-in ones", logging.Task(task.ID), logging.Err(err))
 		}
 	}
+	concepts = append(concepts, matched...)
 
 	// Limit concepts to avoid over-categorization
 	if len(concepts) > 5 {
@@ -183,3 +181,58 @@ func (l *LearningCoordinator) deriveLearningConcepts(task *models.Task) []string
 
 	return concepts
 }
+
+// maxFailedApproach caps the attempted-approach summary stored in a failure learning.
+const maxFailedApproach = 300
+
+// recordFailureLearning stores the approach a failed attempt took as an
+// anti-pattern, so agents on similar tasks are told to avoid it. Only
+// execution and verification failures are recorded: timeouts and budget
+// exhaustion say nothing about whether the approach itself was wrong.
+func (o *Orchestrator) recordFailureLearning(task *models.Task, result *agent.ExecutionResult, class string) {
+	if o.learnings == nil || (class != policy.FailureExecution && class != policy.FailureVerification) {
+		return
+	}
+	condition, action, detail := failureLearning(task, result, class)
+	if action == "" {
+		return
+	}
+	l, err := o.learnings.RecordFailure(condition, action, detail)
+	if err != nil {
+		o.log.Warn("failed to record failure learning", logging.Task(task.ID), logging.Err(err))
+		return
+	}
+	o.logger.Log("[task_completion] recorded failure learning %s for task %s (seen %d times)", l.ID, task.ID, l.TriggerCount+1)
+}
+
+// failureLearning builds the WHEN/DO/RESULT parts of a failure learning.
+// The action is the last paragraph of the agent's output, which is where
+// agents summarize what they did; it is empty if there was no output.
+func failureLearning(task *models.Task, result *agent.ExecutionResult, class string) (condition, action, detail string) {
+	condition = "working on " + task.Title
+
+	paragraphs := strings.Split(strings.TrimSpace(result.Output), "\n\n")
+	for i := len(paragraphs) - 1; i >= 0; i-- {
+		if p := strings.Join(strings.Fields(paragraphs[i]), " "); p != "" {
+			action = p
+			break
+		}
+	}
+	if len(action) > maxFailedApproach {
+		action = action[:maxFailedApproach] + "..."
+	}
+
+	reason := result.Error
+	if class == policy.FailureVerification && result.VerifySummary != "" {
+		reason = result.VerifySummary
+	}
+	reason = strings.TrimSpace(reason)
+	if i := strings.IndexByte(reason, '\n'); i >= 0 {
+		reason = reason[:i]
+	}
+	detail = class + " failure"
+	if reason != "" {
+		detail += ": " + reason
+	}
+	return condition, action, detail
+}
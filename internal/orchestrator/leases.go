@@ -0,0 +1,158 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// FileLease grants a task exclusive ownership of a file or directory while
+// its agent runs. Leases are derived from the task's file boundaries.
+type FileLease struct {
+	// Path is a file path, or a directory path ending in "/". An empty path
+	// covers the whole repository.
+	Path string
+	// TaskID is the task holding the lease.
+	TaskID string
+	// AgentID is the agent working on the task.
+	AgentID string
+	// GrantedAt is when the lease was granted.
+	GrantedAt time.Time
+}
+
+// LeaseManager grants and tracks exclusive file ownership leases. Two
+// leases conflict when their paths are equal or one directory contains the
+// other. It is safe for concurrent use.
+type LeaseManager struct {
+	// leases maps task IDs to the leases they hold.
+	leases map[string][]FileLease
+	// mu protects leases.
+	mu sync.RWMutex
+}
+
+// NewLeaseManager creates an empty LeaseManager.
+func NewLeaseManager() *LeaseManager {
+	return &LeaseManager{leases: make(map[string][]FileLease)}
+}
+
+// LeasePaths returns the paths a task needs leases on, normalized from its
+// file boundaries. Glob boundaries lease the directory before the first
+// wildcard. Tasks without file boundaries need no leases.
+func LeasePaths(task *models.Task) []string {
+	if task == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var paths []string
+	for _, boundary := range task.FileBoundaries {
+		if strings.TrimSpace(boundary) == "" {
+			continue
+		}
+		path := normalizeLeasePath(boundary)
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Acquire grants taskID leases on paths for agentID. Leases the task
+// already holds are replaced. If any path conflicts with another task's
+// lease, nothing is granted and the conflicting lease is returned.
+func (m *LeaseManager) Acquire(taskID, agentID string, paths []string) (*FileLease, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if conflict := m.conflictLocked(taskID, paths); conflict != nil {
+		return conflict, false
+	}
+	now := time.Now()
+	leases := make([]FileLease, 0, len(paths))
+	for _, path := range paths {
+		leases = append(leases, FileLease{Path: path, TaskID: taskID, AgentID: agentID, GrantedAt: now})
+	}
+	if len(leases) == 0 {
+		delete(m.leases, taskID)
+	} else {
+		m.leases[taskID] = leases
+	}
+	return nil, true
+}
+
+// Release drops every lease held by taskID.
+func (m *LeaseManager) Release(taskID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.leases, taskID)
+}
+
+// Conflict returns a lease held by a task other than taskID that overlaps
+// any of paths, or nil if paths are free.
+func (m *LeaseManager) Conflict(taskID string, paths []string) *FileLease {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conflictLocked(taskID, paths)
+}
+
+// GetLeases returns all current leases ordered by path.
+func (m *LeaseManager) GetLeases() []FileLease {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var all []FileLease
+	for _, leases := range m.leases {
+		all = append(all, leases...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Path != all[j].Path {
+			return all[i].Path < all[j].Path
+		}
+		return all[i].TaskID < all[j].TaskID
+	})
+	return all
+}
+
+// conflictLocked is Conflict without locking. Caller must hold m.mu.
+func (m *LeaseManager) conflictLocked(taskID string, paths []string) *FileLease {
+	for holder, leases := range m.leases {
+		if holder == taskID {
+			continue
+		}
+		for i := range leases {
+			for _, path := range paths {
+				if leasePathsOverlap(leases[i].Path, path) {
+					lease := leases[i]
+					return &lease
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeLeasePath cleans a file boundary into a lease path.
+func normalizeLeasePath(boundary string) string {
+	path := filepath.ToSlash(strings.TrimSpace(boundary))
+	path = strings.TrimPrefix(path, "./")
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexAny(path, "*?["); i >= 0 {
+		path = path[:strings.LastIndex(path[:i], "/")+1]
+	}
+	return path
+}
+
+// leasePathsOverlap reports whether two lease paths cover a common file.
+func leasePathsOverlap(a, b string) bool {
+	a = strings.TrimSuffix(a, "/")
+	b = strings.TrimSuffix(b, "/")
+	if a == "" || b == "" || a == b {
+		return true
+	}
+	return strings.HasPrefix(b, a+"/") || strings.HasPrefix(a, b+"/")
+}
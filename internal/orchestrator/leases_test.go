@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestLeasePaths(t *testing.T) {
+	task := &models.Task{FileBoundaries: []string{"./internal/auth/", "internal/api/**/*.go", "", "go.mod", "internal/auth/"}}
+	got := LeasePaths(task)
+	want := []string{"go.mod", "internal/api/", "internal/auth/"}
+	if len(got) != len(want) {
+		t.Fatalf("LeasePaths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("LeasePaths[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLeasePathsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"internal/auth/", "internal/auth/login.go", true},
+		{"internal/auth", "internal/auth/login.go", true},
+		{"internal/auth/", "internal/authz/", false},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "go.sum", false},
+		{"", "anything.go", true},
+	}
+	for _, tt := range tests {
+		if got := leasePathsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("leasePathsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLeaseManager_AcquireRelease(t *testing.T) {
+	lm := NewLeaseManager()
+	if _, ok := lm.Acquire("task-1", "agent-1", []string{"internal/auth/"}); !ok {
+		t.Fatal("first lease should be granted")
+	}
+	lease, ok := lm.Acquire("task-2", "agent-2", []string{"cmd/", "internal/auth/token.go"})
+	if ok || lease == nil || lease.TaskID != "task-1" {
+		t.Fatalf("Acquire = %+v, %v; want conflict with task-1", lease, ok)
+	}
+	if leases := lm.GetLeases(); len(leases) != 1 || leases[0].AgentID != "agent-1" {
+		t.Errorf("GetLeases = %+v, want only task-1's lease", leases)
+	}
+
+	lm.Release("task-1")
+	if _, ok := lm.Acquire("task-2", "agent-2", []string{"cmd/", "internal/auth/token.go"}); !ok {
+		t.Fatal("lease should be granted after release")
+	}
+	if leases := lm.GetLeases(); len(leases) != 2 || leases[0].Path != "cmd/" {
+		t.Errorf("GetLeases = %+v, want task-2's two leases ordered by path", leases)
+	}
+}
+
+func TestSchedulerLeases(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "task-1", Title: "Auth", Status: models.TaskStatusPending, FileBoundaries: []string{"internal/auth/"}},
+		{ID: "task-2", Title: "Token", Status: models.TaskStatusPending, FileBoundaries: []string{"internal/auth/token.go"}},
+		{ID: "task-3", Title: "CLI", Status: models.TaskStatusPending, FileBoundaries: []string{"cmd/"}},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	scheduler.SetLeaseManager(NewLeaseManager())
+
+	// task-1 and task-2 overlap, so only one of them fits in a batch
+	ready := scheduler.Schedule()
+	var auth *models.Task
+	for _, task := range ready {
+		if task.ID == "task-1" || task.ID == "task-2" {
+			if auth != nil {
+				t.Fatalf("Schedule = %v, want only one of the overlapping tasks", taskIDs(ready))
+			}
+			auth = task
+		}
+	}
+	if len(ready) != 2 || auth == nil {
+		t.Fatalf("Schedule = %v, want one auth task and task-3", taskIDs(ready))
+	}
+	other := "task-2"
+	if auth.ID == "task-2" {
+		other = "task-1"
+	}
+
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-1", TaskID: auth.ID, Status: models.AgentStatusRunning})
+	if leases := scheduler.GetLeases(); len(leases) != 1 || leases[0].TaskID != auth.ID {
+		t.Fatalf("GetLeases = %+v, want %s's lease", leases, auth.ID)
+	}
+	for _, task := range scheduler.Schedule() {
+		if task.ID == other {
+			t.Fatalf("%s scheduled while %s holds an overlapping lease", other, auth.ID)
+		}
+	}
+
+	scheduler.OnAgentComplete("agent-1", true)
+	if leases := scheduler.GetLeases(); len(leases) != 0 {
+		t.Errorf("GetLeases = %+v, want leases released on completion", leases)
+	}
+	found := false
+	for _, task := range scheduler.Schedule() {
+		found = found || task.ID == other
+	}
+	if !found {
+		t.Errorf("%s should be schedulable once the lease is released", other)
+	}
+}
+
+// taskIDs returns the IDs of tasks, for test messages.
+func taskIDs(tasks []*models.Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
@@ -3,12 +3,26 @@ package orchestrator
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/redact"
 )
 
+// pkgLog is the structured logger of code that runs outside a session, such
+// as the control server and session lock helpers. Components of a session
+// log through sessionLogger so their records carry the session ID.
+var pkgLog = logging.Logger("orchestrator")
+
+// sessionLogger returns the logger of a module of a session.
+func sessionLogger(module, sessionID string) *slog.Logger {
+	return logging.Logger(module).With(logging.Session(sessionID))
+}
+
 // pkgLogger is the package-level debug logger used by orchestrator components.
 var pkgLogger *DebugLogger
 var pkgLoggerMu sync.RWMutex
@@ -94,7 +108,7 @@ func (l *DebugLogger) Log(format string, args ...interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	msg := fmt.Sprintf(format, args...)
+	msg := redact.String(fmt.Sprintf(format, args...))
 	timestamp := time.Now().Format("15:04:05.000")
 	fmt.Fprintf(l.file, "[%s] %s\n", timestamp, msg)
 	l.file.Sync()
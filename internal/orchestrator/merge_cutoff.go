@@ -0,0 +1,117 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+// Merge routing strategies recorded in MergeDecision.
+const (
+	// MergeStrategySemantic means the conflict was small enough for the semantic merger.
+	MergeStrategySemantic = "semantic"
+	// MergeStrategyHuman means the semantic merger was skipped in favor of the human/resolver queue.
+	MergeStrategyHuman = "human"
+	// MergeStrategyReexecute means the semantic merger was skipped and the task re-runs on the current session branch.
+	MergeStrategyReexecute = "reexecute"
+)
+
+// MergeDecision records how a conflicted merge was routed and the thresholds
+// that drove the decision.
+type MergeDecision struct {
+	// Strategy is one of the MergeStrategy* constants.
+	Strategy string
+	// ConflictFiles is the number of conflicting files.
+	ConflictFiles int
+	// ConflictLines is the number of lines changed in conflicting files on both sides.
+	ConflictLines int
+	// MaxFiles is the configured file cutoff (0 = no limit).
+	MaxFiles int
+	// MaxLines is the configured line cutoff (0 = no limit).
+	MaxLines int
+	// Reason explains the decision.
+	Reason string
+}
+
+// Oversized returns true if the semantic merger was skipped.
+func (d *MergeDecision) Oversized() bool {
+	return d != nil && d.Strategy != MergeStrategySemantic
+}
+
+// decideMergeStrategy compares a conflict's size against the merge policy
+// cutoffs and returns the routing decision.
+func decideMergeStrategy(files, lines int, p policy.MergePolicy) *MergeDecision {
+	d := &MergeDecision{
+		Strategy:      MergeStrategySemantic,
+		ConflictFiles: files,
+		ConflictLines: lines,
+		MaxFiles:      p.SemanticMaxConflictFiles,
+		MaxLines:      p.SemanticMaxConflictLines,
+	}
+
+	var over []string
+	if p.SemanticMaxConflictFiles > 0 && files > p.SemanticMaxConflictFiles {
+		over = append(over, fmt.Sprintf("%d files > %d", files, p.SemanticMaxConflictFiles))
+	}
+	if p.SemanticMaxConflictLines > 0 && lines > p.SemanticMaxConflictLines {
+		over = append(over, fmt.Sprintf("%d lines > %d", lines, p.SemanticMaxConflictLines))
+	}
+	if len(over) == 0 {
+		d.Reason = fmt.Sprintf("conflict within semantic merge limits (%d files, %d lines)", files, lines)
+		return d
+	}
+
+	d.Strategy = MergeStrategyHuman
+	if p.OversizeConflictAction == policy.OversizeActionReexecute {
+		d.Strategy = MergeStrategyReexecute
+	}
+	d.Reason = fmt.Sprintf("conflict too large for semantic merge (%s), routing to %s", strings.Join(over, ", "), d.Strategy)
+	return d
+}
+
+// measureConflictLines sums the lines changed in the conflicting files on
+// both sides of the merge, relative to their merge base. Errors count as 0
+// so an unmeasurable conflict never blocks the semantic merger.
+func measureConflictLines(g git.Runner, targetBranch, agentBranch string, files []string) int {
+	if g == nil || len(files) == 0 {
+		return 0
+	}
+	base, err := g.MergeBase(targetBranch, agentBranch)
+	if err != nil {
+		return 0
+	}
+	base = strings.TrimSpace(base)
+
+	total := 0
+	for _, branch := range []string{targetBranch, agentBranch} {
+		args := append([]string{"diff", "--numstat", base, branch, "--"}, files...)
+		out, err := g.Run(args...)
+		if err != nil {
+			continue
+		}
+		total += sumNumstat(out)
+	}
+	return total
+}
+
+// sumNumstat totals added and deleted lines from `git diff --numstat` output.
+// Binary files ("-" counts) are ignored.
+func sumNumstat(out string) int {
+	total := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		for _, f := range fields[:2] {
+			if n, err := strconv.Atoi(f); err == nil {
+				total += n
+			}
+		}
+	}
+	return total
+}
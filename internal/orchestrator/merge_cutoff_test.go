@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+func TestDecideMergeStrategy(t *testing.T) {
+	limits := policy.MergePolicy{
+		SemanticMaxConflictFiles: 3,
+		SemanticMaxConflictLines: 100,
+		OversizeConflictAction:   policy.OversizeActionHuman,
+	}
+
+	tests := []struct {
+		name   string
+		files  int
+		lines  int
+		policy policy.MergePolicy
+		want   string
+	}{
+		{name: "within limits", files: 2, lines: 50, policy: limits, want: MergeStrategySemantic},
+		{name: "at limits", files: 3, lines: 100, policy: limits, want: MergeStrategySemantic},
+		{name: "too many files", files: 4, lines: 10, policy: limits, want: MergeStrategyHuman},
+		{name: "too many lines", files: 1, lines: 101, policy: limits, want: MergeStrategyHuman},
+		{
+			name:  "reexecute action",
+			files: 10,
+			lines: 1000,
+			policy: policy.MergePolicy{
+				SemanticMaxConflictFiles: 3,
+				SemanticMaxConflictLines: 100,
+				OversizeConflictAction:   policy.OversizeActionReexecute,
+			},
+			want: MergeStrategyReexecute,
+		},
+		{name: "zero means no limit", files: 50, lines: 5000, policy: policy.MergePolicy{}, want: MergeStrategySemantic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decideMergeStrategy(tt.files, tt.lines, tt.policy)
+			if d.Strategy != tt.want {
+				t.Errorf("Strategy = %q, want %q (reason: %s)", d.Strategy, tt.want, d.Reason)
+			}
+			if d.Oversized() != (tt.want != MergeStrategySemantic) {
+				t.Errorf("Oversized() = %v for strategy %q", d.Oversized(), d.Strategy)
+			}
+			if d.ConflictFiles != tt.files || d.ConflictLines != tt.lines {
+				t.Errorf("decision recorded %d files/%d lines, want %d/%d", d.ConflictFiles, d.ConflictLines, tt.files, tt.lines)
+			}
+		})
+	}
+}
+
+func TestSumNumstat(t *testing.T) {
+	out := "10\t2\tsrc/a.go\n-\t-\tassets/logo.png\n3\t0\tsrc/b.go\n"
+	if got := sumNumstat(out); got != 15 {
+		t.Errorf("sumNumstat() = %d, want 15", got)
+	}
+	if got := sumNumstat(""); got != 0 {
+		t.Errorf("sumNumstat(\"\") = %d, want 0", got)
+	}
+}
@@ -3,14 +3,19 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/merge"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/protect"
 )
 
 // MergeProcessorConfig contains configuration for the merge executor.
@@ -21,6 +26,9 @@ type MergeProcessorConfig struct {
 	RetryBaseDelay time.Duration
 	// SemanticMergeTimeout is the maximum time to wait for semantic merge.
 	SemanticMergeTimeout time.Duration
+	// ConflictCutoff holds the conflict-size limits above which the semantic
+	// merger is skipped, and what to do instead.
+	ConflictCutoff policy.MergePolicy
 }
 
 // DefaultMergeProcessorConfig returns sensible defaults.
@@ -29,6 +37,7 @@ func DefaultMergeProcessorConfig() MergeProcessorConfig {
 		MaxRetries:           3,
 		RetryBaseDelay:       2 * time.Second,
 		SemanticMergeTimeout: 5 * time.Minute,
+		ConflictCutoff:       policy.Default().Merge,
 	}
 }
 
@@ -40,11 +49,22 @@ type MergeProcessor struct {
 	factory        func() *SemanticMerger
 	config         MergeProcessorConfig
 	sessionBranch  string
+	mainBranch     string // Merge target in greenfield mode
 	greenfield     bool
 	humanResolver  merge.HumanMergeResolver // For interactive conflict resolution
 	repoPath       string
-	orchestrator   *Orchestrator // For merge conflict blocking
-	git            git.Runner    // For git operations in resolver
+	orchestrator   *Orchestrator    // For merge conflict blocking
+	git            git.Runner       // For git operations in resolver
+	reviews        MergeReviewStore // Review queue for low-confidence merges
+	sessionID      string
+	// protectedPolicy is enforced on every merge; secondReviewer reviews
+	// merges it requires a second review for
+	protectedPolicy *protect.Policy
+	secondReviewer  *SecondReviewer
+	// onPartialMerge is called when the independent part of a conflicting
+	// branch lands ahead of its conflicts
+	onPartialMerge func(req *MergeRequest, partial *merge.PartialResult)
+	log            *slog.Logger
 }
 
 // NewMergeProcessor creates a new MergeProcessor.
@@ -67,6 +87,7 @@ func NewMergeProcessor(
 		greenfield:     greenfield,
 		humanResolver:  humanResolver,
 		repoPath:       repoPath,
+		log:            logging.Logger("merge_executor"),
 	}
 }
 
@@ -75,11 +96,42 @@ func (e *MergeProcessor) SetOrchestrator(o *Orchestrator) {
 	e.orchestrator = o
 }
 
+// SetMainBranch sets the branch merged into in greenfield mode.
+func (e *MergeProcessor) SetMainBranch(branch string) {
+	e.mainBranch = branch
+}
+
+// targetBranch returns the branch agent work is merged into.
+func (e *MergeProcessor) targetBranch() string {
+	if !e.greenfield {
+		return e.sessionBranch
+	}
+	if e.mainBranch != "" {
+		return e.mainBranch
+	}
+	return "main"
+}
+
 // SetGitRunner sets the git runner for merge resolver operations.
 func (e *MergeProcessor) SetGitRunner(g git.Runner) {
 	e.git = g
 }
 
+// SetReviewQueue enables quarantining semantic merges whose confidence is
+// below the policy's ReviewConfidenceThreshold, recording them in store.
+func (e *MergeProcessor) SetReviewQueue(store MergeReviewStore, sessionID string) {
+	e.reviews = store
+	e.sessionID = sessionID
+}
+
+// gitRunner returns the git runner for operations on the target branch.
+func (e *MergeProcessor) gitRunner() git.Runner {
+	if e.git == nil && e.merger != nil {
+		return e.merger.GitRunner()
+	}
+	return e.git
+}
+
 // Execute performs the merge operation for a request.
 // It first tries a git merge, then falls back to semantic merge if needed.
 // Returns the merge outcome indicating success or failure with details.
@@ -96,6 +148,10 @@ func (e *MergeProcessor) Execute(ctx context.Context, req *MergeRequest) MergeOu
 
 	// Step 2: If git merge succeeded, we're done
 	if mergeResult.Success {
+		// A clean git merge needs no confidence discount
+		if outcome, held := e.enforceProtectedPolicy(ctx, req, 1); held {
+			return outcome
+		}
 		_ = e.merger.DeleteBranch(req.AgentBranch)
 		return MergeOutcome{
 			Success: true,
@@ -113,16 +169,82 @@ func (e *MergeProcessor) Execute(ctx context.Context, req *MergeRequest) MergeOu
 		}
 	}
 
-	// Step 4: Try semantic merge with retries
+	// Step 4: Land what does not conflict, so the conflicts hold up only themselves
+	partial := e.mergeIndependent(req)
+
+	// Step 5: Skip the semantic merger for conflicts too large to resolve reliably
+	decision := e.decideConflictRoute(req, mergeResult.ConflictFiles)
+	if decision.Oversized() {
+		debugLog("[merge-executor] task %s: %s", req.TaskID, decision.Reason)
+		return MergeOutcome{
+			Success:       false,
+			Error:         &MergeConflictError{TaskID: req.TaskID, Files: mergeResult.ConflictFiles, Reason: decision.Reason},
+			Reason:        decision.Reason,
+			ConflictFiles: mergeResult.ConflictFiles,
+			Decision:      decision,
+			Partial:       partial,
+		}
+	}
+
+	// Step 6: Try semantic merge with retries
 	outcome := e.trySemanticMergeWithRetry(ctx, req, mergeResult.ConflictFiles)
-	if !outcome.Success {
+	// Merges refused by the protected-area policy must not reach the fallback
+	if !outcome.Success && outcome.Review == nil && !errors.Is(outcome.Error, ErrProtectedAreaBlocked) {
 		outcome.ConflictFiles = mergeResult.ConflictFiles
 	}
+	outcome.Decision = decision
+	outcome.Partial = partial
 	return outcome
 }
 
+// mergeIndependent lands the changes of a conflicting branch that merge
+// cleanly, if the merge policy allows partial merges. Files in protected
+// areas are held back whole so that they still go through the policy when
+// the rest of the branch merges. It returns nil if nothing landed.
+func (e *MergeProcessor) mergeIndependent(req *MergeRequest) *merge.PartialResult {
+	// Greenfield merges target the main branch, which the handler does not
+	// commit to directly
+	if !e.config.ConflictCutoff.PartialMerge || e.greenfield {
+		return nil
+	}
+
+	hold := func(path string) bool {
+		return e.protectedPolicy != nil && len(e.protectedPolicy.Evaluate([]string{path})) > 0
+	}
+	partial, err := e.merger.MergeIndependent(req.AgentBranch, hold)
+	if err != nil {
+		e.log.Warn("partial merge failed", logging.Task(req.TaskID), logging.Err(err))
+		return nil
+	}
+	if partial.Commit == "" {
+		debugLog("[merge-executor] task %s: no independent changes to merge", req.TaskID)
+		return nil
+	}
+	if e.onPartialMerge != nil {
+		e.onPartialMerge(req, partial)
+	}
+	return partial
+}
+
+// decideConflictRoute measures a conflict and decides whether the semantic
+// merger should attempt it.
+func (e *MergeProcessor) decideConflictRoute(req *MergeRequest, conflictFiles []string) *MergeDecision {
+	targetBranch := e.targetBranch()
+
+	lines := 0
+	if e.config.ConflictCutoff.SemanticMaxConflictLines > 0 {
+		lines = measureConflictLines(e.gitRunner(), targetBranch, req.AgentBranch, conflictFiles)
+	}
+	return decideMergeStrategy(len(conflictFiles), lines, e.config.ConflictCutoff)
+}
+
 // tryGitMerge attempts a git merge, with retry logic for greenfield mode.
+// The agent branch is first rebased onto the current head of the target
+// branch; if that conflicts, the merge reports the conflict as usual.
 func (e *MergeProcessor) tryGitMerge(req *MergeRequest) (*merge.Result, error) {
+	if err := e.merger.RebaseOnSession(req.AgentBranch); err != nil {
+		e.log.Info("rebase before merge failed, merging without it", logging.Task(req.TaskID), logging.Err(err))
+	}
 	if e.greenfield {
 		return e.merger.MergeWithRetry(req.AgentBranch, 3)
 	}
@@ -139,10 +261,7 @@ func (e *MergeProcessor) trySemanticMergeWithRetry(ctx context.Context, req *Mer
 		}
 	}
 
-	targetBranch := e.sessionBranch
-	if e.greenfield {
-		targetBranch = "main"
-	}
+	targetBranch := e.targetBranch()
 
 	var lastErr error
 	for attempt := 0; attempt <= e.config.MaxRetries; attempt++ {
@@ -199,6 +318,14 @@ func (e *MergeProcessor) trySemanticMergeWithRetry(ctx context.Context, req *Mer
 		}
 
 		if result.Success {
+			if outcome, held := e.enforceProtectedPolicy(ctx, req, result.Confidence); held {
+				return outcome
+			}
+			if e.needsReview(result) {
+				if outcome, ok := e.quarantine(req, result, targetBranch); ok {
+					return outcome
+				}
+			}
 			_ = e.merger.DeleteBranch(req.AgentBranch)
 			return MergeOutcome{
 				Success: true,
@@ -210,7 +337,7 @@ func (e *MergeProcessor) trySemanticMergeWithRetry(ctx context.Context, req *Mer
 			// Don't retry if human intervention is explicitly needed
 			return MergeOutcome{
 				Success: false,
-				Error:   fmt.Errorf("human intervention required"),
+				Error:   &MergeConflictError{TaskID: req.TaskID, Files: conflictFiles, Reason: result.Reason},
 				Reason:  result.Reason,
 			}
 		}
@@ -235,14 +362,43 @@ func (e *MergeProcessor) trySemanticMergeWithRetry(ctx context.Context, req *Mer
 		go e.spawnMergeResolverAgent(ctx, req, conflictFiles)
 	}
 
+	reason := fmt.Sprintf("semantic merge failed after %d attempts, spawning dedicated resolver", e.config.MaxRetries+1)
 	return MergeOutcome{
 		Success:       false,
-		Error:         lastErr,
-		Reason:        fmt.Sprintf("semantic merge failed after %d attempts, spawning dedicated resolver", e.config.MaxRetries+1),
+		Error:         &MergeConflictError{TaskID: req.TaskID, Files: conflictFiles, Reason: reason, Err: lastErr},
+		Reason:        reason,
 		ConflictFiles: conflictFiles,
 	}
 }
 
+// needsReview returns true if a successful semantic merge scored below the
+// review threshold and a review queue is configured.
+func (e *MergeProcessor) needsReview(result *SemanticMergeResult) bool {
+	threshold := e.config.ConflictCutoff.ReviewConfidenceThreshold
+	return e.reviews != nil && threshold > 0 && result.Confidence < threshold
+}
+
+// quarantine moves a low-confidence semantic merge off the target branch
+// and queues it for human review. The agent branch is kept until the review
+// is decided. Returns false if the merge could not be quarantined, in which
+// case it stays on the target branch.
+func (e *MergeProcessor) quarantine(req *MergeRequest, result *SemanticMergeResult, targetBranch string) (MergeOutcome, bool) {
+	review, err := quarantineMerge(e.gitRunner(), e.reviews, e.sessionID, targetBranch, req, result)
+	if err != nil {
+		e.log.Warn("could not quarantine merge, keeping it", logging.Task(req.TaskID), logging.Err(err))
+		return MergeOutcome{}, false
+	}
+
+	reason := fmt.Sprintf("semantic merge confidence %.2f below %.2f, held on %s for review (alphie merges approve %s)",
+		result.Confidence, e.config.ConflictCutoff.ReviewConfidenceThreshold, review.QuarantineBranch, review.ID)
+	return MergeOutcome{
+		Success: false,
+		Error:   fmt.Errorf("%w: %s", ErrMergeQuarantined, reason),
+		Reason:  reason,
+		Review:  review,
+	}, true
+}
+
 // spawnMergeResolverAgent creates a dedicated agent to resolve merge conflicts.
 func (e *MergeProcessor) spawnMergeResolverAgent(ctx context.Context, req *MergeRequest, conflictFiles []string) {
 	if e.factory == nil {
@@ -286,10 +442,7 @@ func (e *MergeProcessor) escalateToHuman(ctx context.Context, req *MergeRequest,
 	debugLog("[merge-executor] presenting %d conflicts to human for task %s", len(conflictFiles), req.TaskID)
 
 	// Get target branch
-	targetBranch := e.sessionBranch
-	if e.greenfield {
-		targetBranch = "main"
-	}
+	targetBranch := e.targetBranch()
 
 	// Create conflict presenter
 	presenter := merge.NewConflictPresenter(e.repoPath, git.NewRunner(e.repoPath))
@@ -317,13 +470,15 @@ func (e *MergeProcessor) escalateToHuman(ctx context.Context, req *MergeRequest,
 	if err != nil {
 		return MergeOutcome{
 			Success: false,
-			Error:   fmt.Errorf("human resolution failed: %w", err),
+			Error:   &MergeConflictError{TaskID: req.TaskID, Files: conflictFiles, Reason: "human resolution failed", Err: err},
 			Reason:  "user declined to resolve conflicts",
 		}
 	}
 
 	// Apply the resolution
-	return e.applyHumanResolution(req, resolution, conflictFiles)
+	outcome := e.applyHumanResolution(req, resolution, conflictFiles)
+	outcome.HumanResolution = &resolution
+	return outcome
 }
 
 // applyHumanResolution applies the user's resolution choice.
@@ -35,7 +35,7 @@ func (f *FallbackStrategy) SetVerifier(verifier *MergeVerifier) {
 // Returns a merge outcome indicating success or failure.
 //
 // Strategy:
-// 1. Smart merge critical files (package.json, go.mod, etc.) - these can be structurally merged
+// 1. Smart merge critical and YAML files (package.json, go.mod, workflows, etc.) - these can be structurally merged
 // 2. Handle remaining non-critical conflicts: accept ours for non-code, fail for code
 func (f *FallbackStrategy) Attempt(req *MergeRequest, conflicts []string) MergeOutcome {
 	debugLog("[fallback] attempting fallback merge for task %s with %d conflicts", req.TaskID, len(conflicts))
@@ -43,7 +43,7 @@ func (f *FallbackStrategy) Attempt(req *MergeRequest, conflicts []string) MergeO
 	// Step 1: Separate critical from non-critical conflicts
 	var critical, remaining []string
 	for _, file := range conflicts {
-		if merge.IsCriticalFile(file) {
+		if merge.IsSmartMergeable(file) {
 			critical = append(critical, file)
 		} else {
 			remaining = append(remaining, file)
@@ -63,6 +63,9 @@ func (f *FallbackStrategy) Attempt(req *MergeRequest, conflicts []string) MergeO
 			remaining = append(remaining, critical...)
 		} else if !smartResult.Success {
 			debugLog("[fallback] smart merge had conflicts: %v", smartResult.Conflicts)
+			for _, lockErr := range smartResult.LockFileErrors {
+				debugLog("[fallback] %v", lockErr)
+			}
 			// Some critical files couldn't be merged, add them to remaining
 			remaining = append(remaining, smartResult.Conflicts...)
 			// But apply the ones that succeeded
@@ -0,0 +1,241 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+// maxExhaustiveMergeOrder is the largest batch whose every merge order is
+// simulated. Larger batches are ordered greedily.
+const maxExhaustiveMergeOrder = 4
+
+// MergeOrderDecision records the merge order chosen for a batch of pending
+// merges and why.
+type MergeOrderDecision struct {
+	// Original is the order the merges were queued in.
+	Original []string
+	// Order is the chosen order of agent branches.
+	Order []string
+	// Conflicts is the number of conflicting files the chosen order is
+	// expected to produce.
+	Conflicts int
+	// OriginalConflicts is the expected number of conflicting files when
+	// merging in queue order.
+	OriginalConflicts int
+	// ConflictFiles lists the expected conflicting files per branch of Order.
+	ConflictFiles map[string][]string
+	// Evaluated is the number of candidate orders simulated.
+	Evaluated int
+	// Reason explains the decision.
+	Reason string
+}
+
+// Reordered returns true if the chosen order differs from the queue order.
+func (d *MergeOrderDecision) Reordered() bool {
+	if d == nil {
+		return false
+	}
+	for i := range d.Order {
+		if d.Order[i] != d.Original[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeOrderOptimizer picks the order in which to merge a batch of agent
+// branches so that the fewest conflicts arise. It simulates merges with
+// git merge-tree, which touches neither the index nor the working tree.
+type MergeOrderOptimizer struct {
+	git git.Runner
+}
+
+// NewMergeOrderOptimizer creates a MergeOrderOptimizer using g for git operations.
+func NewMergeOrderOptimizer(g git.Runner) *MergeOrderOptimizer {
+	return &MergeOrderOptimizer{git: g}
+}
+
+// mergeStep is the simulated result of merging one branch on top of a state.
+type mergeStep struct {
+	commit    string
+	conflicts []string
+}
+
+// mergeSimulation memoizes simulated merges by the sequence of branches merged.
+type mergeSimulation struct {
+	opt    *MergeOrderOptimizer
+	target string
+	steps  map[string]mergeStep
+}
+
+// Optimize simulates merging the branches into target and returns the order
+// with the fewest expected conflicting files. Ties keep the queue order.
+func (m *MergeOrderOptimizer) Optimize(target string, branches []string) (*MergeOrderDecision, error) {
+	head, err := m.git.Run("rev-parse", target)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", target, err)
+	}
+	sim := &mergeSimulation{opt: m, target: strings.TrimSpace(head), steps: make(map[string]mergeStep)}
+
+	decision := &MergeOrderDecision{Original: append([]string(nil), branches...)}
+	decision.OriginalConflicts, err = sim.conflicts(branches)
+	if err != nil {
+		return nil, err
+	}
+	decision.Order, decision.Conflicts = decision.Original, decision.OriginalConflicts
+	decision.Evaluated = 1
+
+	if len(branches) <= maxExhaustiveMergeOrder {
+		err = permuteOrders(branches, func(order []string) error {
+			decision.Evaluated++
+			n, err := sim.conflicts(order)
+			if err != nil {
+				return err
+			}
+			if n < decision.Conflicts {
+				decision.Order, decision.Conflicts = append([]string(nil), order...), n
+			}
+			return nil
+		})
+		decision.Evaluated-- // the queue order is visited again
+	} else {
+		var order []string
+		var n int
+		order, n, err = sim.greedy(branches)
+		decision.Evaluated++
+		if err == nil && n < decision.Conflicts {
+			decision.Order, decision.Conflicts = order, n
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decision.ConflictFiles = make(map[string][]string)
+	for i, branch := range decision.Order {
+		step, err := sim.merge(decision.Order[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		if len(step.conflicts) > 0 {
+			decision.ConflictFiles[branch] = step.conflicts
+		}
+	}
+	decision.Reason = mergeOrderReason(decision)
+	return decision, nil
+}
+
+// conflicts returns the total conflicting files of merging branches in order.
+func (s *mergeSimulation) conflicts(order []string) (int, error) {
+	total := 0
+	for i := range order {
+		step, err := s.merge(order[:i+1])
+		if err != nil {
+			return 0, err
+		}
+		total += len(step.conflicts)
+	}
+	return total, nil
+}
+
+// greedy builds an order by repeatedly merging the branch that conflicts least
+// with the branches merged so far.
+func (s *mergeSimulation) greedy(branches []string) ([]string, int, error) {
+	remaining := append([]string(nil), branches...)
+	var order []string
+	total := 0
+	for len(remaining) > 0 {
+		best, bestConflicts := -1, 0
+		for i, branch := range remaining {
+			step, err := s.merge(append(append([]string(nil), order...), branch))
+			if err != nil {
+				return nil, 0, err
+			}
+			if best < 0 || len(step.conflicts) < bestConflicts {
+				best, bestConflicts = i, len(step.conflicts)
+			}
+		}
+		order = append(order, remaining[best])
+		total += bestConflicts
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return order, total, nil
+}
+
+// merge simulates merging the branches of seq in order on top of the target
+// and returns the last step. A conflicted step continues from its tree with
+// conflict markers, approximating a resolution that keeps both sides.
+func (s *mergeSimulation) merge(seq []string) (mergeStep, error) {
+	key := strings.Join(seq, "\x00")
+	if step, ok := s.steps[key]; ok {
+		return step, nil
+	}
+
+	base := s.target
+	if len(seq) > 1 {
+		prev, err := s.merge(seq[:len(seq)-1])
+		if err != nil {
+			return mergeStep{}, err
+		}
+		base = prev.commit
+	}
+	branch := seq[len(seq)-1]
+
+	tree, conflicts, err := s.opt.git.MergeTree(base, branch)
+	if err != nil {
+		return mergeStep{}, fmt.Errorf("simulate merge of %s: %w", branch, err)
+	}
+	// Record the merge as an unreferenced commit so the next step can build on it
+	commit, err := s.opt.git.Run("-c", "user.name=alphie", "-c", "user.email=alphie@localhost",
+		"commit-tree", tree, "-p", base, "-p", branch, "-m", "alphie merge order simulation")
+	if err != nil {
+		return mergeStep{}, fmt.Errorf("record simulated merge of %s: %w", branch, err)
+	}
+
+	step := mergeStep{commit: strings.TrimSpace(commit), conflicts: conflicts}
+	s.steps[key] = step
+	return step, nil
+}
+
+// permuteOrders calls fn with every order of items, starting with the
+// original order and proceeding lexicographically by original position.
+func permuteOrders(items []string, fn func([]string) error) error {
+	order := make([]string, 0, len(items))
+	used := make([]bool, len(items))
+	var visit func() error
+	visit = func() error {
+		if len(order) == len(items) {
+			return fn(order)
+		}
+		for i, item := range items {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			order = append(order, item)
+			if err := visit(); err != nil {
+				return err
+			}
+			order = order[:len(order)-1]
+			used[i] = false
+		}
+		return nil
+	}
+	return visit()
+}
+
+// mergeOrderReason explains a merge order decision.
+func mergeOrderReason(d *MergeOrderDecision) string {
+	if !d.Reordered() {
+		if d.Conflicts == 0 {
+			return fmt.Sprintf("queue order merges cleanly (%d orders simulated)", d.Evaluated)
+		}
+		return fmt.Sprintf("queue order already has the fewest expected conflicts (%d files, %d orders simulated)",
+			d.Conflicts, d.Evaluated)
+	}
+	return fmt.Sprintf("reordered to %s: %d expected conflicting files instead of %d (%d orders simulated)",
+		strings.Join(d.Order, " -> "), d.Conflicts, d.OriginalConflicts, d.Evaluated)
+}
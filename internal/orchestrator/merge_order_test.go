@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+)
+
+// setupMergeOrderRepo creates a repo whose branches a and c make the same
+// change to config.txt, b makes a conflicting change, and d touches another file.
+func setupMergeOrderRepo(t *testing.T) (*git.ExecRunner, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := initGitRepo(dir); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	g := git.NewRunner(dir)
+	base, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commitOn := func(branch, file, content string) {
+		t.Helper()
+		if _, err := g.Run("checkout", "-q", "-b", branch, base); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Run("add", file); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Run("commit", "-q", "-m", branch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commitOn("a", "config.txt", "port=8080\n")
+	commitOn("b", "config.txt", "port=9090\n")
+	commitOn("c", "config.txt", "port=8080\n")
+	commitOn("d", "other.txt", "hello\n")
+	if _, err := g.Run("checkout", "-q", base); err != nil {
+		t.Fatal(err)
+	}
+	return g, base
+}
+
+func TestMergeOrderOptimizer(t *testing.T) {
+	g, base := setupMergeOrderRepo(t)
+	opt := NewMergeOrderOptimizer(g)
+
+	// a, b, c conflicts twice (b with a, then c with the conflicted result);
+	// merging c before b leaves only b's conflict
+	decision, err := opt.Optimize(base, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if want := []string{"a", "c", "b"}; !reflect.DeepEqual(decision.Order, want) {
+		t.Errorf("Order = %v, want %v", decision.Order, want)
+	}
+	if decision.OriginalConflicts != 2 || decision.Conflicts != 1 || decision.Evaluated != 6 {
+		t.Errorf("decision = %+v", decision)
+	}
+	if !decision.Reordered() || decision.Reason == "" {
+		t.Errorf("expected a reordering with a reason: %+v", decision)
+	}
+	if files := decision.ConflictFiles["b"]; len(files) != 1 || files[0] != "config.txt" {
+		t.Errorf("ConflictFiles = %v", decision.ConflictFiles)
+	}
+
+	// The simulation leaves the working tree alone
+	if status, _ := g.Status(); status != "" {
+		t.Errorf("working tree changed: %q", status)
+	}
+}
+
+func TestMergeOrderOptimizerKeepsCleanQueueOrder(t *testing.T) {
+	g, base := setupMergeOrderRepo(t)
+	decision, err := NewMergeOrderOptimizer(g).Optimize(base, []string{"d", "a"})
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if decision.Reordered() || decision.Conflicts != 0 {
+		t.Errorf("clean queue order should be kept: %+v", decision)
+	}
+}
+
+func TestMergeOrderOptimizerGreedy(t *testing.T) {
+	g, base := setupMergeOrderRepo(t)
+	head, err := g.Run("rev-parse", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := &mergeSimulation{opt: NewMergeOrderOptimizer(g), target: head, steps: make(map[string]mergeStep)}
+	order, conflicts, err := sim.greedy([]string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatalf("greedy: %v", err)
+	}
+	if want := []string{"a", "c", "d", "b"}; !reflect.DeepEqual(order, want) || conflicts != 1 {
+		t.Errorf("greedy = %v (%d conflicts), want %v (1)", order, conflicts, want)
+	}
+}
+
+func TestMergeQueueNextBatchReorders(t *testing.T) {
+	g, base := setupMergeOrderRepo(t)
+	mq := &MergeQueue{
+		queue:          make(chan *MergeRequest, 4),
+		processor:      &MergeProcessor{sessionBranch: base, log: pkgLog},
+		orderOptimizer: NewMergeOrderOptimizer(g),
+		log:            pkgLog,
+	}
+	events := make(chan OrchestratorEvent, 1)
+	mq.eventCh = events
+
+	first := &MergeRequest{TaskID: "t1", AgentBranch: "a"}
+	mq.queue <- &MergeRequest{TaskID: "t2", AgentBranch: "b"}
+	mq.queue <- &MergeRequest{TaskID: "t3", AgentBranch: "c"}
+
+	batch := mq.nextBatch(first)
+	var got []string
+	for _, r := range batch {
+		got = append(got, r.TaskID)
+	}
+	if want := []string{"t1", "t3", "t2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("batch order = %v, want %v", got, want)
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != EventMergeOrderChosen || ev.MergeOrder == nil {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	default:
+		t.Error("expected a merge_order_chosen event")
+	}
+}
@@ -4,13 +4,15 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/merge"
 	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/state"
 )
 
 // MergeRequest represents a pending merge operation.
@@ -41,6 +43,16 @@ type MergeOutcome struct {
 	Reason string
 	// ConflictFiles lists files that had conflicts (for fallback use).
 	ConflictFiles []string
+	// Decision records the conflict-size routing decision, if one was made.
+	Decision *MergeDecision
+	// HumanResolution is the operator's conflict resolution, if one was made.
+	HumanResolution *merge.Resolution
+	// Review is set if the merge was quarantined for human review instead of
+	// landing on the target branch.
+	Review *state.MergeReview
+	// Partial is set if the non-conflicting part of the branch was merged
+	// before its conflicts were resolved.
+	Partial *merge.PartialResult
 }
 
 // MergeQueueConfig contains configuration for the merge queue.
@@ -80,7 +92,11 @@ type MergeQueue struct {
 	rollback *merge.RollbackManager
 	// stats tracks merge statistics.
 	stats MergeQueueStats
-	// mu protects stats.
+	// pending holds the requests waiting to merge, in merge order.
+	pending []*MergeRequest
+	// merging is the request being merged, if any.
+	merging *MergeRequest
+	// mu protects stats, pending and merging.
 	mu sync.RWMutex
 	// wg tracks the worker goroutine.
 	wg sync.WaitGroup
@@ -90,6 +106,13 @@ type MergeQueue struct {
 	cancel context.CancelFunc
 	// eventCh receives merge events for logging.
 	eventCh chan<- OrchestratorEvent
+	// recorder persists merge events, if set.
+	recorder EventRecorder
+	// filter decides which merge events reach subscribers, if set.
+	filter *EventFilter
+	// orderOptimizer reorders merges that are pending together, if set.
+	orderOptimizer *MergeOrderOptimizer
+	log            *slog.Logger
 }
 
 // MergeQueueStats tracks merge queue statistics.
@@ -148,6 +171,10 @@ func NewMergeQueueWithPolicy(
 		MaxRetries:           config.MaxRetries,
 		RetryBaseDelay:       config.RetryBaseDelay,
 		SemanticMergeTimeout: config.SemanticMergeTimeout,
+		ConflictCutoff:       policy.Default().Merge,
+	}
+	if policyConfig != nil {
+		processorConfig.ConflictCutoff = policyConfig.Merge
 	}
 
 	// Use NoOp resolver by default (will be replaced by orchestrator if interactive mode enabled)
@@ -164,6 +191,7 @@ func NewMergeQueueWithPolicy(
 		humanResolver,
 		merger.RepoPath(),
 	)
+	processor.log = sessionLogger("merge_executor", sessionID)
 
 	// Create the fallback strategy
 	fallback := NewFallbackStrategy(merger, merger.RepoPath(), sessionBranch)
@@ -188,7 +216,13 @@ func NewMergeQueueWithPolicy(
 		eventCh:     eventCh,
 		ctx:         ctx,
 		cancel:      cancel,
+		log:         sessionLogger("merge_queue", sessionID),
+	}
+
+	if policyConfig != nil && policyConfig.Merge.OptimizeOrder {
+		mq.orderOptimizer = NewMergeOrderOptimizer(gitRepo)
 	}
+	processor.onPartialMerge = mq.emitPartialMerge
 
 	// Start the worker goroutine
 	mq.wg.Add(1)
@@ -211,16 +245,22 @@ func (mq *MergeQueue) Enqueue(ctx context.Context, taskID, agentID, agentBranch
 		Ctx:         ctx,
 	}
 
+	// Track the request before the worker can see it
+	ahead := mq.addPending(req)
+
 	select {
 	case mq.queue <- req:
 		debugLog("[merge-queue] enqueued merge for task %s (queue size: %d)", taskID, len(mq.queue))
+		mq.emitQueued(req, ahead)
 	case <-ctx.Done():
+		mq.removePending(req)
 		resultCh <- MergeOutcome{
 			Success: false,
 			Error:   ctx.Err(),
 			Reason:  "context cancelled before enqueue",
 		}
 	case <-mq.ctx.Done():
+		mq.removePending(req)
 		resultCh <- MergeOutcome{
 			Success: false,
 			Error:   mq.ctx.Err(),
@@ -231,6 +271,130 @@ func (mq *MergeQueue) Enqueue(ctx context.Context, taskID, agentID, agentBranch
 	return resultCh
 }
 
+// addPending appends a request to the pending merges and returns how many
+// merges are ahead of it, including the one in progress.
+func (mq *MergeQueue) addPending(req *MergeRequest) int {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.pending = append(mq.pending, req)
+	return mq.aheadLocked(len(mq.pending) - 1)
+}
+
+// removePending drops a request that was never queued.
+func (mq *MergeQueue) removePending(req *MergeRequest) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	for i, r := range mq.pending {
+		if r == req {
+			mq.pending = append(mq.pending[:i], mq.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// aheadLocked returns how many merges are ahead of the pending request at
+// index i. mu must be held.
+func (mq *MergeQueue) aheadLocked(i int) int {
+	if mq.merging != nil {
+		return i + 1
+	}
+	return i
+}
+
+// reorderPending moves the requests of a batch to the front of the pending
+// merges, in the order the batch will be merged.
+func (mq *MergeQueue) reorderPending(batch []*MergeRequest) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	inBatch := make(map[*MergeRequest]bool, len(batch))
+	for _, r := range batch {
+		inBatch[r] = true
+	}
+	pending := append([]*MergeRequest(nil), batch...)
+	for _, r := range mq.pending {
+		if !inBatch[r] {
+			pending = append(pending, r)
+		}
+	}
+	mq.pending = pending
+}
+
+// startMerge marks a request as being merged and reports the new position
+// of every request still waiting.
+func (mq *MergeQueue) startMerge(req *MergeRequest) {
+	mq.mu.Lock()
+	for i, r := range mq.pending {
+		if r == req {
+			mq.pending = append(mq.pending[:i], mq.pending[i+1:]...)
+			break
+		}
+	}
+	mq.merging = req
+	waiting := append([]*MergeRequest(nil), mq.pending...)
+	mq.mu.Unlock()
+
+	for i, r := range waiting {
+		mq.emitQueued(r, i+1)
+	}
+}
+
+// finishMerge clears the request being merged.
+func (mq *MergeQueue) finishMerge() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.merging = nil
+}
+
+// Position returns how many merges are ahead of a task's pending merge,
+// including the one in progress, or -1 if the task is not waiting to merge.
+func (mq *MergeQueue) Position(taskID string) int {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+	for i, r := range mq.pending {
+		if r.TaskID == taskID {
+			return mq.aheadLocked(i)
+		}
+	}
+	return -1
+}
+
+// emitQueued reports a request's position in the queue.
+func (mq *MergeQueue) emitQueued(req *MergeRequest, ahead int) {
+	mq.emitEvent(OrchestratorEvent{
+		Type:            EventMergeQueued,
+		TaskID:          req.TaskID,
+		AgentID:         req.AgentID,
+		Message:         MergeWaitMessage(ahead),
+		Timestamp:       time.Now(),
+		MergeQueueAhead: ahead,
+	})
+}
+
+// emitPartialMerge reports the part of a conflicting branch that landed
+// ahead of its conflicts.
+func (mq *MergeQueue) emitPartialMerge(req *MergeRequest, partial *merge.PartialResult) {
+	msg := fmt.Sprintf("Partially merged %d file(s); holding %d conflicting file(s) (%d hunk(s)) for resolution",
+		len(partial.Merged), len(partial.Held), partial.HeldHunks)
+	mq.log.Info(msg, logging.Task(req.TaskID), "merged", partial.Merged, "held", partial.Held)
+	mq.emitEvent(OrchestratorEvent{
+		Type:      EventMergePartial,
+		TaskID:    req.TaskID,
+		AgentID:   req.AgentID,
+		Message:   msg,
+		Files:     partial.Merged,
+		Timestamp: time.Now(),
+	})
+}
+
+// MergeWaitMessage describes a merge waiting behind ahead others, e.g.
+// "Waiting to merge (2 ahead)".
+func MergeWaitMessage(ahead int) string {
+	if ahead == 0 {
+		return "Waiting to merge (next)"
+	}
+	return fmt.Sprintf("Waiting to merge (%d ahead)", ahead)
+}
+
 // Stop gracefully shuts down the merge queue.
 func (mq *MergeQueue) Stop() {
 	mq.cancel()
@@ -282,37 +446,108 @@ func (mq *MergeQueue) worker() {
 	defer mq.wg.Done()
 
 	for req := range mq.queue {
-		// Check if we should stop
+		for _, r := range mq.nextBatch(req) {
+			mq.handleRequest(r)
+		}
+	}
+}
+
+// nextBatch returns req together with the requests already waiting behind
+// it, in the order they should be merged. Without an order optimizer the
+// batch is just req.
+func (mq *MergeQueue) nextBatch(req *MergeRequest) []*MergeRequest {
+	batch := []*MergeRequest{req}
+	if mq.orderOptimizer == nil {
+		return batch
+	}
+drain:
+	for {
 		select {
-		case <-mq.ctx.Done():
-			req.ResultCh <- MergeOutcome{
-				Success: false,
-				Error:   mq.ctx.Err(),
-				Reason:  "merge queue shutting down",
+		case next, ok := <-mq.queue:
+			if !ok {
+				break drain
 			}
-			continue
+			batch = append(batch, next)
 		default:
+			break drain
 		}
+	}
+	if len(batch) < 2 {
+		return batch
+	}
+	batch = mq.orderBatch(batch)
+	mq.reorderPending(batch)
+	return batch
+}
 
-		// Process the merge
-		outcome := mq.processMerge(req)
-
-		// Update stats
-		mq.mu.Lock()
-		mq.stats.TotalMerges++
-		if outcome.Success {
-			mq.stats.SuccessfulMerges++
-		} else {
-			mq.stats.FailedMerges++
+// orderBatch reorders a batch of merges to minimize simulated conflicts.
+// If the simulation fails the batch is merged in queue order.
+func (mq *MergeQueue) orderBatch(batch []*MergeRequest) []*MergeRequest {
+	byBranch := make(map[string]*MergeRequest, len(batch))
+	branches := make([]string, 0, len(batch))
+	for _, r := range batch {
+		if _, dup := byBranch[r.AgentBranch]; dup {
+			return batch
 		}
-		if outcome.FallbackUsed {
-			mq.stats.FallbackMerges++
+		byBranch[r.AgentBranch] = r
+		branches = append(branches, r.AgentBranch)
+	}
+
+	decision, err := mq.orderOptimizer.Optimize(mq.processor.targetBranch(), branches)
+	if err != nil {
+		mq.log.Warn("merge order simulation failed, merging in queue order", logging.Err(err))
+		return batch
+	}
+	mq.log.Info("ordered pending merges", "pending", len(batch), "reason", decision.Reason)
+	mq.emitEvent(OrchestratorEvent{
+		Type:       EventMergeOrderChosen,
+		Message:    decision.Reason,
+		Timestamp:  time.Now(),
+		MergeOrder: decision,
+	})
+
+	ordered := make([]*MergeRequest, 0, len(batch))
+	for _, branch := range decision.Order {
+		ordered = append(ordered, byBranch[branch])
+	}
+	return ordered
+}
+
+// handleRequest merges a single request and sends its outcome.
+func (mq *MergeQueue) handleRequest(req *MergeRequest) {
+	mq.startMerge(req)
+	defer mq.finishMerge()
+
+	// Check if we should stop
+	select {
+	case <-mq.ctx.Done():
+		req.ResultCh <- MergeOutcome{
+			Success: false,
+			Error:   mq.ctx.Err(),
+			Reason:  "merge queue shutting down",
 		}
-		mq.mu.Unlock()
+		return
+	default:
+	}
+
+	// Process the merge
+	outcome := mq.processMerge(req)
 
-		// Send result
-		req.ResultCh <- outcome
+	// Update stats
+	mq.mu.Lock()
+	mq.stats.TotalMerges++
+	if outcome.Success {
+		mq.stats.SuccessfulMerges++
+	} else {
+		mq.stats.FailedMerges++
+	}
+	if outcome.FallbackUsed {
+		mq.stats.FallbackMerges++
 	}
+	mq.mu.Unlock()
+
+	// Send result
+	req.ResultCh <- outcome
 }
 
 // processMerge handles a single merge request by delegating to processor and fallback.
@@ -320,7 +555,7 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 	// Create checkpoint before merge attempt
 	if mq.checkpoints != nil {
 		if err := mq.checkpoints.CreateCheckpoint(req.AgentID, req.TaskID); err != nil {
-			log.Printf("[merge_queue] warning: failed to create checkpoint for agent %s: %v", req.AgentID, err)
+			mq.log.Warn("failed to create checkpoint", logging.Task(req.TaskID), logging.Agent(req.AgentID), logging.Err(err))
 		}
 	}
 
@@ -335,11 +570,24 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 	// Delegate to processor for git + semantic merge
 	outcome := mq.processor.Execute(req.Ctx, req)
 
+	if outcome.Review != nil {
+		mq.log.Info("merge not attempted", logging.Task(req.TaskID), "reason", outcome.Reason)
+		mq.emitEvent(OrchestratorEvent{
+			Type:      EventMergeCompleted,
+			TaskID:    req.TaskID,
+			AgentID:   req.AgentID,
+			Message:   fmt.Sprintf("Merge quarantined: %s", outcome.Reason),
+			Error:     outcome.Error,
+			Timestamp: time.Now(),
+		})
+		return outcome
+	}
+
 	if outcome.Success {
 		// Mark checkpoint as good
 		if mq.checkpoints != nil {
 			if err := mq.checkpoints.MarkGood(req.AgentID); err != nil {
-				log.Printf("[merge_queue] warning: failed to mark checkpoint as good for agent %s: %v", req.AgentID, err)
+				mq.log.Warn("failed to mark checkpoint as good", logging.Task(req.TaskID), logging.Agent(req.AgentID), logging.Err(err))
 			}
 		}
 
@@ -363,14 +611,19 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 	// Processor failed, try fallback strategy
 	if len(outcome.ConflictFiles) > 0 {
 		conflictSummary := fmt.Sprintf("Attempting fallback merge strategy for %d conflict file(s)", len(outcome.ConflictFiles))
-		log.Printf("[merge_queue] %s for task %s: %v", conflictSummary, req.TaskID, outcome.ConflictFiles)
+		mq.log.Info(conflictSummary, logging.Task(req.TaskID), "files", outcome.ConflictFiles)
+
+		if outcome.Decision.Oversized() {
+			conflictSummary = fmt.Sprintf("%s (%s)", conflictSummary, outcome.Decision.Reason)
+		}
 
 		mq.emitEvent(OrchestratorEvent{
-			Type:      EventMergeStarted,
-			TaskID:    req.TaskID,
-			AgentID:   req.AgentID,
-			Message:   conflictSummary,
-			Timestamp: time.Now(),
+			Type:          EventMergeStarted,
+			TaskID:        req.TaskID,
+			AgentID:       req.AgentID,
+			Message:       conflictSummary,
+			Timestamp:     time.Now(),
+			MergeDecision: outcome.Decision,
 		})
 
 		fallbackOutcome := mq.fallback.Attempt(req, outcome.ConflictFiles)
@@ -378,48 +631,61 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 			// Fallback succeeded - mark checkpoint as good
 			if mq.checkpoints != nil {
 				if err := mq.checkpoints.MarkGood(req.AgentID); err != nil {
-					log.Printf("[merge_queue] warning: failed to mark checkpoint as good for agent %s: %v", req.AgentID, err)
+					mq.log.Warn("failed to mark checkpoint as good", logging.Task(req.TaskID), logging.Agent(req.AgentID), logging.Err(err))
 				}
 			}
 
 			_ = mq.merger.DeleteBranch(req.AgentBranch)
 			successMsg := fmt.Sprintf("Fallback merge completed: %s", fallbackOutcome.Reason)
-			log.Printf("[merge_queue] %s for task %s", successMsg, req.TaskID)
+			mq.log.Info(successMsg, logging.Task(req.TaskID))
 
+			fallbackOutcome.Decision = outcome.Decision
 			mq.emitEvent(OrchestratorEvent{
-				Type:      EventMergeCompleted,
-				TaskID:    req.TaskID,
-				AgentID:   req.AgentID,
-				Message:   successMsg,
-				Timestamp: time.Now(),
+				Type:          EventMergeCompleted,
+				TaskID:        req.TaskID,
+				AgentID:       req.AgentID,
+				Message:       successMsg,
+				Timestamp:     time.Now(),
+				MergeDecision: outcome.Decision,
 			})
 		} else {
 			// Fallback failed - mark checkpoint as bad
 			if mq.checkpoints != nil {
 				if err := mq.checkpoints.MarkBad(req.AgentID); err != nil {
-					log.Printf("[merge_queue] warning: failed to mark checkpoint as bad for agent %s: %v", req.AgentID, err)
+					mq.log.Warn("failed to mark checkpoint as bad", logging.Task(req.TaskID), logging.Agent(req.AgentID), logging.Err(err))
 				}
 			}
 
 			// Build detailed error message with conflict files
 			errorMsg := fmt.Sprintf("Merge failed after fallback attempt: %s. Conflict files: %v",
 				fallbackOutcome.Reason, outcome.ConflictFiles)
-			log.Printf("[merge_queue] ERROR: %s for task %s (error: %v)", errorMsg, req.TaskID, fallbackOutcome.Error)
-
-			// If fallback failed due to code conflicts, escalate to resolver
-			// (processor has access to orchestrator/factory for spawning)
-			if len(fallbackOutcome.ConflictFiles) > 0 {
-				log.Printf("[merge_queue] Escalating code conflicts to processor for resolver spawn")
-				return mq.processor.HandleFallbackFailure(req.Ctx, req, fallbackOutcome.ConflictFiles)
+			mq.log.Error(errorMsg, logging.Task(req.TaskID), logging.Err(fallbackOutcome.Error))
+
+			fallbackOutcome.Decision = outcome.Decision
+
+			// Oversized conflicts routed to re-execution skip the resolver entirely:
+			// the task re-runs on top of the current session branch instead
+			if len(fallbackOutcome.ConflictFiles) > 0 && outcome.Decision != nil && outcome.Decision.Strategy == MergeStrategyReexecute {
+				mq.log.Info(outcome.Decision.Reason, logging.Task(req.TaskID))
+				fallbackOutcome.Error = &MergeConflictError{TaskID: req.TaskID, Files: fallbackOutcome.ConflictFiles, Reason: outcome.Decision.Reason}
+				fallbackOutcome.Reason = outcome.Decision.Reason
+			} else if len(fallbackOutcome.ConflictFiles) > 0 {
+				// If fallback failed due to code conflicts, escalate to resolver
+				// (processor has access to orchestrator/factory for spawning)
+				mq.log.Info("escalating code conflicts to a resolver", logging.Task(req.TaskID))
+				escalated := mq.processor.HandleFallbackFailure(req.Ctx, req, fallbackOutcome.ConflictFiles)
+				escalated.Decision = outcome.Decision
+				return escalated
 			}
 
 			mq.emitEvent(OrchestratorEvent{
-				Type:      EventMergeCompleted,
-				TaskID:    req.TaskID,
-				AgentID:   req.AgentID,
-				Message:   errorMsg,
-				Error:     fallbackOutcome.Error,
-				Timestamp: time.Now(),
+				Type:          EventMergeCompleted,
+				TaskID:        req.TaskID,
+				AgentID:       req.AgentID,
+				Message:       errorMsg,
+				Error:         fallbackOutcome.Error,
+				Timestamp:     time.Now(),
+				MergeDecision: outcome.Decision,
 			})
 		}
 		return fallbackOutcome
@@ -428,7 +694,7 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 	// No conflict files to attempt fallback - mark checkpoint as bad
 	if mq.checkpoints != nil {
 		if err := mq.checkpoints.MarkBad(req.AgentID); err != nil {
-			log.Printf("[merge_queue] warning: failed to mark checkpoint as bad for agent %s: %v", req.AgentID, err)
+			mq.log.Warn("failed to mark checkpoint as bad", logging.Task(req.TaskID), logging.Agent(req.AgentID), logging.Err(err))
 		}
 	}
 
@@ -437,7 +703,7 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 	if outcome.Error != nil {
 		errorMsg = fmt.Sprintf("%s (error: %v)", errorMsg, outcome.Error)
 	}
-	log.Printf("[merge_queue] ERROR: %s for task %s", errorMsg, req.TaskID)
+	mq.log.Error(errorMsg, logging.Task(req.TaskID))
 
 	mq.emitEvent(OrchestratorEvent{
 		Type:      EventMergeCompleted,
@@ -452,7 +718,10 @@ func (mq *MergeQueue) processMerge(req *MergeRequest) MergeOutcome {
 
 // emitEvent sends an event if the event channel is configured.
 func (mq *MergeQueue) emitEvent(event OrchestratorEvent) {
-	if mq.eventCh == nil {
+	if mq.recorder != nil {
+		mq.recorder.Record(event)
+	}
+	if mq.eventCh == nil || !mq.filter.Allow(event) {
 		return
 	}
 	select {
@@ -462,6 +731,18 @@ func (mq *MergeQueue) emitEvent(event OrchestratorEvent) {
 	}
 }
 
+// SetRecorder sets a recorder that persists merge events.
+// It must be called before merges are enqueued.
+func (mq *MergeQueue) SetRecorder(r EventRecorder) {
+	mq.recorder = r
+}
+
+// SetFilter sets the filter that decides which merge events reach subscribers.
+// It must be called before merges are enqueued.
+func (mq *MergeQueue) SetFilter(f *EventFilter) {
+	mq.filter = f
+}
+
 // GetProcessor returns the merge processor for configuration.
 func (mq *MergeQueue) GetProcessor() *MergeProcessor {
 	return mq.processor
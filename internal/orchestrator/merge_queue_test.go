@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"testing"
+)
+
+// drainQueued returns the merge_queued events emitted so far, by task ID.
+func drainQueued(ch chan OrchestratorEvent) map[string]OrchestratorEvent {
+	queued := make(map[string]OrchestratorEvent)
+	for {
+		select {
+		case e := <-ch:
+			if e.Type == EventMergeQueued {
+				queued[e.TaskID] = e
+			}
+		default:
+			return queued
+		}
+	}
+}
+
+func TestMergeQueue_Positions(t *testing.T) {
+	events := make(chan OrchestratorEvent, 20)
+	mq := &MergeQueue{eventCh: events, log: pkgLog}
+
+	a := &MergeRequest{TaskID: "a", AgentID: "agent-a"}
+	b := &MergeRequest{TaskID: "b", AgentID: "agent-b"}
+	c := &MergeRequest{TaskID: "c", AgentID: "agent-c"}
+	for i, r := range []*MergeRequest{a, b, c} {
+		if ahead := mq.addPending(r); ahead != i {
+			t.Errorf("addPending(%s) = %d, want %d", r.TaskID, ahead, i)
+		}
+	}
+
+	// a starts merging: b and c move up, counting a as ahead
+	mq.startMerge(a)
+	queued := drainQueued(events)
+	if len(queued) != 2 || queued["b"].MergeQueueAhead != 1 || queued["c"].MergeQueueAhead != 2 {
+		t.Errorf("queued events = %+v, want b 1 ahead and c 2 ahead", queued)
+	}
+	if msg := queued["c"].Message; msg != "Waiting to merge (2 ahead)" {
+		t.Errorf("Message = %q", msg)
+	}
+	if got := mq.Position("a"); got != -1 {
+		t.Errorf("Position(a) while merging = %d, want -1", got)
+	}
+
+	// A new arrival waits behind the merge in progress and both pending
+	d := &MergeRequest{TaskID: "d", AgentID: "agent-d"}
+	if ahead := mq.addPending(d); ahead != 3 {
+		t.Errorf("addPending(d) = %d, want 3", ahead)
+	}
+
+	// Reordering a batch moves it to the front
+	mq.finishMerge()
+	mq.reorderPending([]*MergeRequest{c, b})
+	if got := mq.Position("c"); got != 0 {
+		t.Errorf("Position(c) after reordering = %d, want 0", got)
+	}
+	if got := mq.Position("d"); got != 2 {
+		t.Errorf("Position(d) after reordering = %d, want 2", got)
+	}
+
+	mq.removePending(d)
+	if got := mq.Position("d"); got != -1 {
+		t.Errorf("Position(d) after removal = %d, want -1", got)
+	}
+}
+
+func TestMergeWaitMessage(t *testing.T) {
+	if got := MergeWaitMessage(0); got != "Waiting to merge (next)" {
+		t.Errorf("MergeWaitMessage(0) = %q", got)
+	}
+	if got := MergeWaitMessage(2); got != "Waiting to merge (2 ahead)" {
+		t.Errorf("MergeWaitMessage(2) = %q", got)
+	}
+}
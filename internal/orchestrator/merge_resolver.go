@@ -124,6 +124,9 @@ func (mr *MergeResolverAgent) validateResolution(conflictFiles []string) error {
 func (mr *MergeResolverAgent) getTargetBranch() string {
 	// Check orchestrator's greenfield flag
 	if mr.orchestrator != nil && mr.orchestrator.config.Greenfield {
+		if mr.orchestrator.config.MainBranch != "" {
+			return mr.orchestrator.config.MainBranch
+		}
 		return "main"
 	}
 	if mr.orchestrator != nil && mr.orchestrator.sessionMgr != nil {
@@ -0,0 +1,250 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// quarantineBranchPrefix names the branches low-confidence merges are held on.
+const quarantineBranchPrefix = "alphie/quarantine/"
+
+// QuarantineBranchName returns the branch a task's low-confidence merge is
+// held on until it is reviewed.
+func QuarantineBranchName(taskID string) string {
+	return quarantineBranchPrefix + taskID
+}
+
+// MergeReviewStore persists the merge review queue. *state.DB implements it.
+type MergeReviewStore interface {
+	// CreateMergeReview adds a merge to the review queue.
+	CreateMergeReview(r *state.MergeReview) error
+	// GetMergeReview retrieves a merge review by ID, or nil if it does not exist.
+	GetMergeReview(id string) (*state.MergeReview, error)
+	// ListMergeReviews lists merge reviews, optionally filtered by status.
+	ListMergeReviews(status *state.MergeReviewStatus) ([]state.MergeReview, error)
+	// DecideMergeReview records a human decision on a pending merge review.
+	DecideMergeReview(id string, status state.MergeReviewStatus, decidedBy string) error
+}
+
+// Verify state.DB implements MergeReviewStore at compile time.
+var _ MergeReviewStore = (*state.DB)(nil)
+
+// quarantineMerge moves a semantic merge commit off the target branch onto a
+// quarantine branch and queues it for human review. It expects the merge
+// commit to be HEAD of the checked-out target branch.
+func quarantineMerge(g git.Runner, store MergeReviewStore, sessionID, targetBranch string, req *MergeRequest, result *SemanticMergeResult) (*state.MergeReview, error) {
+	review := &state.MergeReview{
+		ID:               uuid.New().String()[:8],
+		SessionID:        sessionID,
+		TaskID:           req.TaskID,
+		AgentBranch:      req.AgentBranch,
+		TargetBranch:     targetBranch,
+		QuarantineBranch: QuarantineBranchName(req.TaskID),
+		Confidence:       result.Confidence,
+		Reason:           result.Reason,
+		Files:            result.ChangedFiles,
+	}
+	if len(review.Files) == 0 {
+		review.Files = result.MergedFiles
+	}
+	return holdMerge(g, store, review)
+}
+
+// holdMerge moves the merge commit at HEAD of the checked-out target branch
+// onto review's quarantine branch and adds review to the queue.
+func holdMerge(g git.Runner, store MergeReviewStore, review *state.MergeReview) (*state.MergeReview, error) {
+	if _, err := g.Run("branch", "-f", review.QuarantineBranch, "HEAD"); err != nil {
+		return nil, fmt.Errorf("create quarantine branch: %w", err)
+	}
+	if _, err := g.Run("reset", "--hard", "HEAD^"); err != nil {
+		return nil, fmt.Errorf("remove merge from %s: %w", review.TargetBranch, err)
+	}
+	if err := store.CreateMergeReview(review); err != nil {
+		// Put the merge back rather than lose track of it
+		_, _ = g.Run("reset", "--hard", review.QuarantineBranch)
+		_ = g.DeleteBranch(review.QuarantineBranch)
+		return nil, err
+	}
+	return review, nil
+}
+
+// MergeReviewQueue lets a human approve, reject or edit low-confidence
+// semantic merges held on quarantine branches.
+type MergeReviewQueue struct {
+	store    MergeReviewStore
+	git      git.Runner
+	repoPath string
+}
+
+// NewMergeReviewQueue creates a MergeReviewQueue for the repository at repoPath.
+func NewMergeReviewQueue(store MergeReviewStore, repoPath string) *MergeReviewQueue {
+	return NewMergeReviewQueueWithRunner(store, repoPath, git.NewRunner(repoPath))
+}
+
+// NewMergeReviewQueueWithRunner creates a MergeReviewQueue with a custom git runner (for testing).
+func NewMergeReviewQueueWithRunner(store MergeReviewStore, repoPath string, runner git.Runner) *MergeReviewQueue {
+	return &MergeReviewQueue{store: store, git: runner, repoPath: repoPath}
+}
+
+// List returns the pending reviews, or all reviews if all is true.
+func (q *MergeReviewQueue) List(all bool) ([]state.MergeReview, error) {
+	if all {
+		return q.store.ListMergeReviews(nil)
+	}
+	pending := state.MergeReviewPending
+	return q.store.ListMergeReviews(&pending)
+}
+
+// Get returns a review by ID.
+func (q *MergeReviewQueue) Get(id string) (*state.MergeReview, error) {
+	review, err := q.store.GetMergeReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, fmt.Errorf("merge review %s not found", id)
+	}
+	return review, nil
+}
+
+// Diff returns the changes the quarantined merge would bring onto its
+// target branch.
+func (q *MergeReviewQueue) Diff(id string) (string, error) {
+	review, err := q.pending(id)
+	if err != nil {
+		return "", err
+	}
+	return q.git.Run("diff", review.TargetBranch+"..."+review.QuarantineBranch)
+}
+
+// EditPath returns the worktree a review is checked out in for editing.
+func (q *MergeReviewQueue) EditPath(id string) string {
+	return filepath.Join(q.repoPath, ".alphie", "reviews", id)
+}
+
+// Edit checks the quarantine branch out in a worktree at EditPath, where
+// the merge can be amended with new commits before it is approved.
+func (q *MergeReviewQueue) Edit(id string) (string, error) {
+	review, err := q.pending(id)
+	if err != nil {
+		return "", err
+	}
+	path := q.EditPath(id)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := q.git.WorktreeAdd(path, review.QuarantineBranch); err != nil {
+		return "", fmt.Errorf("check out %s: %w", review.QuarantineBranch, err)
+	}
+	return path, nil
+}
+
+// Approve merges the quarantine branch, including any edits, into its
+// target branch and deletes the quarantine and agent branches. Edits must be
+// committed first.
+func (q *MergeReviewQueue) Approve(id, operator string) (*state.MergeReview, error) {
+	review, err := q.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.closeEditWorktree(id, false); err != nil {
+		return nil, fmt.Errorf("edits in %s are not committed: %w", q.EditPath(id), err)
+	}
+
+	current, err := q.git.CurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("get current branch: %w", err)
+	}
+	if current != review.TargetBranch {
+		if err := q.git.CheckoutBranch(review.TargetBranch); err != nil {
+			return nil, fmt.Errorf("check out %s: %w", review.TargetBranch, err)
+		}
+		defer func() { _ = q.git.CheckoutBranch(current) }()
+	}
+
+	msg := fmt.Sprintf("Merge reviewed %s into %s", review.QuarantineBranch, review.TargetBranch)
+	if _, err := q.git.Run("merge", "--no-ff", "-m", msg, review.QuarantineBranch); err != nil {
+		_ = q.git.MergeAbort()
+		return nil, fmt.Errorf("merge %s: %w", review.QuarantineBranch, err)
+	}
+	_ = q.git.DeleteBranch(review.QuarantineBranch)
+	if review.AgentBranch != "" {
+		_ = q.git.DeleteBranch(review.AgentBranch)
+	}
+	return q.decide(review, state.MergeReviewApproved, operator)
+}
+
+// Reject discards the quarantined merge. The agent branch is kept so the
+// task can be merged by hand or re-run.
+func (q *MergeReviewQueue) Reject(id, operator string) (*state.MergeReview, error) {
+	review, err := q.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.closeEditWorktree(id, true); err != nil {
+		return nil, err
+	}
+	if err := q.git.DeleteBranch(review.QuarantineBranch); err != nil {
+		return nil, fmt.Errorf("delete %s: %w", review.QuarantineBranch, err)
+	}
+	return q.decide(review, state.MergeReviewRejected, operator)
+}
+
+// pending returns a review that still awaits a decision.
+func (q *MergeReviewQueue) pending(id string) (*state.MergeReview, error) {
+	review, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != state.MergeReviewPending {
+		return nil, fmt.Errorf("merge review %s was already %s", id, review.Status)
+	}
+	return review, nil
+}
+
+// closeEditWorktree removes the review's edit worktree, if there is one.
+// Without force, removal fails if the worktree has uncommitted changes.
+func (q *MergeReviewQueue) closeEditWorktree(id string, force bool) error {
+	path := q.EditPath(id)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return q.git.WorktreeRemoveOptionalForce(path, force)
+}
+
+// decide records the decision in the review queue and in the session's
+// audit trail.
+func (q *MergeReviewQueue) decide(review *state.MergeReview, status state.MergeReviewStatus, operator string) (*state.MergeReview, error) {
+	if operator == "" {
+		operator = operatorIdentity(q.git)
+	}
+	if err := q.store.DecideMergeReview(review.ID, status, operator); err != nil {
+		return nil, err
+	}
+
+	if review.SessionID != "" {
+		if eventLog, err := NewEventLog(EventLogPath(q.repoPath, review.SessionID)); err == nil {
+			kind := DecisionApproval
+			if status == state.MergeReviewRejected {
+				kind = DecisionRejection
+			}
+			eventLog.RecordDecision(Decision{
+				Kind:   kind,
+				Actor:  HumanActor(operator),
+				TaskID: review.TaskID,
+				Reason: fmt.Sprintf("Quarantined merge %s (confidence %.2f) %s: %s",
+					review.ID, review.Confidence, status, strings.TrimSpace(review.Reason)),
+			})
+			_ = eventLog.Close()
+		}
+	}
+	return q.store.GetMergeReview(review.ID)
+}
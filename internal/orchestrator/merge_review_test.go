@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// setupQuarantinedMerge creates a repository whose target branch has a
+// semantic merge commit adding merged.txt, quarantines that commit and
+// returns the review queue and the review.
+func setupQuarantinedMerge(t *testing.T) (*MergeReviewQueue, *state.MergeReview, string) {
+	t.Helper()
+	repo := t.TempDir()
+	if err := initGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	g := git.NewRunner(repo)
+	target, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := gitOutput(t, repo, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(repo, "merged.txt"), []byte("merged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, repo, "add", ".")
+	gitOutput(t, repo, "commit", "-q", "-m", "Semantic merge")
+
+	req := &MergeRequest{TaskID: "task-1", AgentBranch: "agent-task-1"}
+	result := &SemanticMergeResult{Success: true, Confidence: 0.3, Reason: "guessed", ChangedFiles: []string{"merged.txt"}}
+	review, err := quarantineMerge(g, db, "sess1", target, req, result)
+	if err != nil {
+		t.Fatalf("quarantineMerge: %v", err)
+	}
+
+	if head := gitOutput(t, repo, "rev-parse", "HEAD"); head != base {
+		t.Errorf("target HEAD = %s, want merge removed (%s)", head, base)
+	}
+	if exists, _ := g.BranchExists(review.QuarantineBranch); !exists {
+		t.Fatalf("quarantine branch %s missing", review.QuarantineBranch)
+	}
+	return NewMergeReviewQueue(db, repo), review, repo
+}
+
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestMergeReviewQueue_ApproveWithEdits(t *testing.T) {
+	queue, review, repo := setupQuarantinedMerge(t)
+
+	pending, err := queue.List(false)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != review.ID || pending[0].Confidence != 0.3 {
+		t.Fatalf("pending = %+v, want the quarantined merge", pending)
+	}
+	if diff, err := queue.Diff(review.ID); err != nil || !strings.Contains(diff, "merged.txt") {
+		t.Errorf("Diff = %q, %v; want merged.txt", diff, err)
+	}
+
+	path, err := queue.Edit(review.ID)
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "merged.txt"), []byte("fixed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queue.Approve(review.ID, "alice"); err == nil {
+		t.Fatal("Approve succeeded with uncommitted edits")
+	}
+	gitOutput(t, path, "commit", "-q", "-am", "Fix merge")
+
+	decided, err := queue.Approve(review.ID, "alice")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if decided.Status != state.MergeReviewApproved || decided.DecidedBy != "alice" {
+		t.Errorf("review = %+v, want approved by alice", decided)
+	}
+	content, err := os.ReadFile(filepath.Join(repo, "merged.txt"))
+	if err != nil || string(content) != "fixed" {
+		t.Errorf("merged.txt = %q, %v; want the edited merge on the target branch", content, err)
+	}
+	if _, err := queue.Approve(review.ID, "alice"); err == nil {
+		t.Error("approving a decided review should fail")
+	}
+
+	records, err := ReadEventLog(EventLogPath(repo, "sess1"))
+	if err != nil || len(records) != 1 || records[0].Decision != DecisionApproval || records[0].Actor != HumanActor("alice") {
+		t.Errorf("event log = %+v, %v; want one approval by alice", records, err)
+	}
+}
+
+func TestMergeReviewQueue_Reject(t *testing.T) {
+	queue, review, repo := setupQuarantinedMerge(t)
+
+	decided, err := queue.Reject(review.ID, "bob")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if decided.Status != state.MergeReviewRejected {
+		t.Errorf("status = %s, want rejected", decided.Status)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "merged.txt")); !os.IsNotExist(err) {
+		t.Error("rejected merge reached the target branch")
+	}
+	if exists, _ := git.NewRunner(repo).BranchExists(review.QuarantineBranch); exists {
+		t.Error("quarantine branch kept after reject")
+	}
+	if pending, _ := queue.List(false); len(pending) != 0 {
+		t.Errorf("pending = %+v, want none", pending)
+	}
+}
+
+func TestMergeProcessor_NeedsReview(t *testing.T) {
+	config := DefaultMergeProcessorConfig()
+	e := NewMergeProcessor(nil, nil, nil, config, "session", false, nil, "")
+	low := &SemanticMergeResult{Success: true, Confidence: 0.2}
+
+	if e.needsReview(low) {
+		t.Error("needsReview without a review queue")
+	}
+	e.SetReviewQueue(&state.DB{}, "sess")
+	if !e.needsReview(low) {
+		t.Error("low-confidence merge not sent to review")
+	}
+	if e.needsReview(&SemanticMergeResult{Success: true, Confidence: 0.9}) {
+		t.Error("confident merge sent to review")
+	}
+	e.config.ConflictCutoff.ReviewConfidenceThreshold = 0
+	if e.needsReview(low) {
+		t.Error("threshold 0 should disable review")
+	}
+}
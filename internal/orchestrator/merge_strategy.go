@@ -5,6 +5,7 @@ import (
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/git"
 	"github.com/ShayCichocki/alphie/internal/merge"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/protect"
 )
 
@@ -14,12 +15,21 @@ type MergeStrategyConfig struct {
 	RepoPath string
 	// SessionBranch is the session branch name (for normal mode).
 	SessionBranch string
+	// MainBranch is the repository's main branch, merged into directly in
+	// greenfield mode. Defaults to "main".
+	MainBranch string
 	// GitRunner provides git operations.
 	GitRunner git.Runner
 	// MergerClaude is the Claude runner for semantic merges.
 	MergerClaude agent.ClaudeRunner
 	// SecondReviewerClaude is the Claude runner for second reviews.
 	SecondReviewerClaude agent.ClaudeRunner
+	// RunnerFactory gives each second reviewer on a panel its own runner.
+	// If nil, only SecondReviewerClaude is available.
+	RunnerFactory agent.ClaudeRunnerFactory
+	// ReviewPolicy holds the second review thresholds and reviewer panel.
+	// If nil, policy.Default().Review is used.
+	ReviewPolicy *policy.ReviewPolicy
 	// Protected is the protected area checker for second review triggers.
 	Protected *protect.Detector
 	// Greenfield indicates if this is a new project (merge directly to main).
@@ -39,6 +49,9 @@ func NewMergeStrategy(cfg MergeStrategyConfig) *MergeStrategy {
 // TargetBranch returns the branch that agents merge into.
 func (s *MergeStrategy) TargetBranch() string {
 	if s.cfg.Greenfield {
+		if s.cfg.MainBranch != "" {
+			return s.cfg.MainBranch
+		}
 		return "main"
 	}
 	return s.cfg.SessionBranch
@@ -51,7 +64,7 @@ func (s *MergeStrategy) CreateMerger() *merge.Handler {
 
 // CreateSemanticMerger creates a SemanticMerger for AI-assisted conflict resolution.
 func (s *MergeStrategy) CreateSemanticMerger() *SemanticMerger {
-	return NewSemanticMerger(s.cfg.MergerClaude, s.cfg.RepoPath)
+	return NewSemanticMergerWithRunner(s.cfg.MergerClaude, s.cfg.RepoPath, s.cfg.GitRunner)
 }
 
 // CreateSecondReviewer creates a SecondReviewer if configured, nil otherwise.
@@ -60,7 +73,9 @@ func (s *MergeStrategy) CreateSecondReviewer() *SecondReviewer {
 	if s.cfg.Greenfield || s.cfg.SecondReviewerClaude == nil {
 		return nil
 	}
-	return NewSecondReviewer(s.cfg.Protected, s.cfg.SecondReviewerClaude)
+	r := NewSecondReviewerWithPolicy(s.cfg.Protected, s.cfg.SecondReviewerClaude, s.cfg.ReviewPolicy)
+	r.SetRunnerFactory(s.cfg.RunnerFactory)
+	return r
 }
 
 // IsGreenfield returns true if this is a greenfield (new project) strategy.
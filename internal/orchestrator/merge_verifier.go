@@ -4,9 +4,10 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 // VerificationResult contains the result of a post-merge verification.
@@ -26,14 +27,21 @@ type MergeVerifier struct {
 	repoPath    string
 	projectInfo *ProjectTypeInfo
 	timeout     time.Duration
+	// runner runs the build, in the session's sandbox if it has one
+	runner iexec.CommandRunner
 }
 
 // NewMergeVerifier creates a new MergeVerifier for the given repository.
-func NewMergeVerifier(repoPath string, projectInfo *ProjectTypeInfo, timeout time.Duration) *MergeVerifier {
+// The build runs through runner; nil runs it on the host.
+func NewMergeVerifier(repoPath string, projectInfo *ProjectTypeInfo, timeout time.Duration, runner iexec.CommandRunner) *MergeVerifier {
+	if runner == nil {
+		runner = iexec.NewRunner()
+	}
 	return &MergeVerifier{
 		repoPath:    repoPath,
 		projectInfo: projectInfo,
 		timeout:     timeout,
+		runner:      runner,
 	}
 }
 
@@ -64,11 +72,8 @@ func (v *MergeVerifier) VerifyMerge(ctx context.Context, branchName string) (*Ve
 
 	debugLog("[verifier] running build verification: %s %v", cmdName, cmdArgs)
 
-	cmd := exec.CommandContext(verifyCtx, cmdName, cmdArgs...)
-	cmd.Dir = v.repoPath
-
 	// Capture both stdout and stderr
-	output, err := cmd.CombinedOutput()
+	output, err := v.runner.Run(verifyCtx, v.repoPath, cmdName, cmdArgs...)
 	outputStr := string(output)
 
 	duration := time.Since(startTime)
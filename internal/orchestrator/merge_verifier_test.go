@@ -0,0 +1,24 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+func TestMergeVerifier_RunsBuildThroughRunner(t *testing.T) {
+	// A build that passes on the host must still go through the sandbox
+	info := &ProjectTypeInfo{Type: ProjectTypeGo, BuildCommand: []string{"true"}}
+	verifier := NewMergeVerifier(t.TempDir(), info, time.Minute, iexec.NewFailingRunner(errors.New("sandbox unavailable")))
+
+	result, err := verifier.VerifyMerge(context.Background(), "agent/task-1")
+	if err != nil {
+		t.Fatalf("VerifyMerge() error = %v", err)
+	}
+	if result.Passed {
+		t.Error("expected verification to fail when the sandbox refuses the build")
+	}
+}
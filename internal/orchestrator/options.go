@@ -13,6 +13,7 @@ import (
 	"github.com/ShayCichocki/alphie/internal/prog"
 	"github.com/ShayCichocki/alphie/internal/protect"
 	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -37,6 +38,12 @@ type orchestratorOptions struct {
 	tierConfigs          *config.TierConfigs
 	policyConfig         *policy.Config
 	greenfield           bool
+	mainBranch           string
+	vcs                  vcs.Kind
+	commitIdentity       *git.CommitIdentity
+	operator             string
+	sandboxAllowlist     []string
+	protectedPolicy      *protect.Policy
 	decomposerClaude     agent.ClaudeRunner
 	mergerClaude         agent.ClaudeRunner
 	secondReviewerClaude agent.ClaudeRunner
@@ -49,6 +56,13 @@ type orchestratorOptions struct {
 	execRunner           iexec.CommandRunner
 	resumeEpicID         string
 	originalTaskID       string
+	tasks                []*models.Task
+	keepSessionBranch    bool
+	sessionMergeGate     SessionMergeGate
+	approvalGates        *ApprovalGates
+	rateLimiter          *agent.RateLimiter
+	usageMeter           *agent.UsageMeter
+	baseline             *agent.Baseline
 
 	// Injectable dependencies for testing
 	decomposer           *decompose.Decomposer
@@ -57,6 +71,7 @@ type orchestratorOptions struct {
 	protectedAreaChecker *protect.Detector
 	overrideGate         *ScoutOverrideGate
 	mergeStrategy        *MergeStrategy
+	budgetManager        *BudgetManager
 }
 
 // WithMaxAgents sets the maximum number of concurrent agents.
@@ -79,6 +94,41 @@ func WithGreenfield(b bool) Option {
 	return func(o *orchestratorOptions) { o.greenfield = b }
 }
 
+// WithMainBranch overrides the main branch sessions merge into.
+// If empty, the repository's default branch is detected.
+func WithMainBranch(branch string) Option {
+	return func(o *orchestratorOptions) { o.mainBranch = branch }
+}
+
+// WithVCS sets the workspace backend the executor was created with.
+func WithVCS(kind vcs.Kind) Option {
+	return func(o *orchestratorOptions) { o.vcs = kind }
+}
+
+// WithCommitIdentity sets who the session's commits and merges are made
+// as, whether they are signed, and their message template.
+func WithCommitIdentity(identity *git.CommitIdentity) Option {
+	return func(o *orchestratorOptions) { o.commitIdentity = identity }
+}
+
+// WithOperator sets who the audit trail attributes human decisions to.
+// If empty, the git user email is used.
+func WithOperator(identity string) Option {
+	return func(o *orchestratorOptions) { o.operator = identity }
+}
+
+// WithSandboxAllowlist adds the repository's allowlist to the programs
+// sandboxed commands may start, when the tier runs commands in a sandbox.
+func WithSandboxAllowlist(programs []string) Option {
+	return func(o *orchestratorOptions) { o.sandboxAllowlist = programs }
+}
+
+// WithProtectedPolicy sets the repository's protected-area policy,
+// enforced when scheduling tasks and when merging their work.
+func WithProtectedPolicy(p *protect.Policy) Option {
+	return func(o *orchestratorOptions) { o.protectedPolicy = p }
+}
+
 // WithDecomposerClaude sets the Claude runner for task decomposition.
 func WithDecomposerClaude(r agent.ClaudeRunner) Option {
 	return func(o *orchestratorOptions) { o.decomposerClaude = r }
@@ -139,6 +189,49 @@ func WithOriginalTaskID(id string) Option {
 	return func(o *orchestratorOptions) { o.originalTaskID = id }
 }
 
+// WithTasks runs the given tasks instead of decomposing the request.
+// Use it to run hand-written tasks through the pipeline in isolation.
+func WithTasks(tasks ...*models.Task) Option {
+	return func(o *orchestratorOptions) { o.tasks = tasks }
+}
+
+// WithKeepSessionBranch leaves the session branch in place for inspection
+// instead of merging it into the main branch when the session ends.
+func WithKeepSessionBranch(b bool) Option {
+	return func(o *orchestratorOptions) { o.keepSessionBranch = b }
+}
+
+// WithSessionMergeGate sets the gate a finished session must pass before
+// it merges into the main branch, overriding the policy's SessionReview.
+func WithSessionMergeGate(g SessionMergeGate) Option {
+	return func(o *orchestratorOptions) { o.sessionMergeGate = g }
+}
+
+// WithApprovalGates holds the session at g's gates for an operator's
+// approval, overriding the policy's Approval. Runs of several sessions
+// share one ApprovalGates.
+func WithApprovalGates(g *ApprovalGates) Option {
+	return func(o *orchestratorOptions) { o.approvalGates = g }
+}
+
+// WithRateLimiter reports the queueing and throttling of the API rate
+// limiter the session's runners share as rate_limited events.
+func WithRateLimiter(l *agent.RateLimiter) Option {
+	return func(o *orchestratorOptions) { o.rateLimiter = l }
+}
+
+// WithUsageMeter reports the running usage and cost of the session's
+// runners, as recorded by m, as cost_tick events.
+func WithUsageMeter(m *agent.UsageMeter) Option {
+	return func(o *orchestratorOptions) { o.usageMeter = m }
+}
+
+// WithBaseline uses a baseline captured earlier, such as before the first
+// of several sessions, instead of capturing one when the session starts.
+func WithBaseline(b *agent.Baseline) Option {
+	return func(o *orchestratorOptions) { o.baseline = b }
+}
+
 // WithDecomposer sets a custom task decomposer (mainly for testing).
 func WithDecomposer(d *decompose.Decomposer) Option {
 	return func(o *orchestratorOptions) { o.decomposer = d }
@@ -169,6 +262,12 @@ func WithMergeStrategy(s *MergeStrategy) Option {
 	return func(o *orchestratorOptions) { o.mergeStrategy = s }
 }
 
+// WithBudgetManager sets a custom budget manager.
+// If not set, one is created from the policy's Budget settings.
+func WithBudgetManager(b *BudgetManager) Option {
+	return func(o *orchestratorOptions) { o.budgetManager = b }
+}
+
 // toOrchestratorConfig converts RequiredConfig + Options to the internal OrchestratorConfig.
 // This bridges the new API to the existing implementation.
 func toOrchestratorConfig(req RequiredConfig, opts *orchestratorOptions) OrchestratorConfig {
@@ -180,6 +279,12 @@ func toOrchestratorConfig(req RequiredConfig, opts *orchestratorOptions) Orchest
 		TierConfigs:          opts.tierConfigs,
 		Policy:               opts.policyConfig,
 		Greenfield:           opts.greenfield,
+		MainBranch:           opts.mainBranch,
+		VCS:                  opts.vcs,
+		CommitIdentity:       opts.commitIdentity,
+		Operator:             opts.operator,
+		SandboxAllowlist:     opts.sandboxAllowlist,
+		ProtectedPolicy:      opts.protectedPolicy,
 		DecomposerClaude:     opts.decomposerClaude,
 		MergerClaude:         opts.mergerClaude,
 		SecondReviewerClaude: opts.secondReviewerClaude,
@@ -192,11 +297,19 @@ func toOrchestratorConfig(req RequiredConfig, opts *orchestratorOptions) Orchest
 		ExecRunner:           opts.execRunner,
 		ResumeEpicID:         opts.resumeEpicID,
 		OriginalTaskID:       opts.originalTaskID,
+		Tasks:                opts.tasks,
+		KeepSessionBranch:    opts.keepSessionBranch,
+		SessionMergeGate:     opts.sessionMergeGate,
+		ApprovalGates:        opts.approvalGates,
+		RateLimiter:          opts.rateLimiter,
+		UsageMeter:           opts.usageMeter,
+		Baseline:             opts.baseline,
 		Decomposer:           opts.decomposer,
 		Graph:                opts.graph,
 		CollisionChecker:     opts.collisionChecker,
 		ProtectedAreaChecker: opts.protectedAreaChecker,
 		OverrideGate:         opts.overrideGate,
 		MergeStrategy:        opts.mergeStrategy,
+		BudgetManager:        opts.budgetManager,
 	}
 }
@@ -2,6 +2,7 @@
 package orchestrator
 
 import (
+	"log/slog"
 	"sync"
 	"time"
 
@@ -14,12 +15,14 @@ import (
 	"github.com/ShayCichocki/alphie/internal/git"
 	"github.com/ShayCichocki/alphie/internal/graph"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/merge"
 	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/prog"
 	"github.com/ShayCichocki/alphie/internal/protect"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/internal/structure"
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -40,6 +43,27 @@ type OrchestratorConfig struct {
 	Policy *policy.Config
 	// Greenfield indicates if this is a new project (no session branch needed).
 	Greenfield bool
+	// MainBranch overrides the branch sessions merge into (and greenfield
+	// work targets). If empty, the repository's default branch is detected.
+	MainBranch string
+	// VCS is the workspace backend the Executor was created with. With
+	// vcs.KindPlain there is no session branch: each task's patch is
+	// applied to RepoPath directly. Empty means git.
+	VCS vcs.Kind
+	// CommitIdentity sets the author, signing and message template of the
+	// session's commits and merges. It applies to the default git runner,
+	// not an injected GitRunner. If nil, the git config is used.
+	CommitIdentity *git.CommitIdentity
+	// Operator identifies the person running the session in the audit trail.
+	// If empty, the git user email is used.
+	Operator string
+	// SandboxAllowlist is the repository's sandbox allowlist, added to the
+	// tier's when the tier config enables a sandbox.
+	SandboxAllowlist []string
+	// ProtectedPolicy is the repository's protected-area policy. Tasks whose
+	// file boundaries it blocks are not run, and merges touching its paths
+	// need the action it requires. If nil, no policy is enforced.
+	ProtectedPolicy *protect.Policy
 	// DecomposerClaude is the Claude runner for task decomposition.
 	DecomposerClaude agent.ClaudeRunner
 	// MergerClaude is the Claude runner for semantic merge operations.
@@ -76,6 +100,26 @@ type OrchestratorConfig struct {
 	// OriginalTaskID is the task ID from the TUI's task_entered event.
 	// Used to link epic_created events back to the original task for deduplication.
 	OriginalTaskID string
+	// Tasks are predefined tasks to run instead of decomposing the request.
+	Tasks []*models.Task
+	// KeepSessionBranch leaves the session branch for inspection instead of
+	// merging it into the main branch (or deleting it on failure).
+	KeepSessionBranch bool
+	// SessionMergeGate must approve a finished session before it merges into
+	// the main branch. If nil, the gate is chosen by Policy.Merge.SessionReview.
+	SessionMergeGate SessionMergeGate
+	// ApprovalGates hold the session for an operator's approval. If nil,
+	// they are created from Policy.Approval.
+	ApprovalGates *ApprovalGates
+	// Baseline is the build, test and lint state validation compares
+	// against. If nil, it is captured from RepoPath when the session starts.
+	Baseline *agent.Baseline
+	// RateLimiter is the API rate limiter the session's runners share. Its
+	// queueing and throttling are reported as rate_limited events.
+	RateLimiter *agent.RateLimiter
+	// UsageMeter records the usage of every runner of the session. Its
+	// running totals are reported as cost_tick events.
+	UsageMeter *agent.UsageMeter
 
 	// Verification options
 	// EnablePostMergeVerification enables build verification after merge.
@@ -107,6 +151,8 @@ type OrchestratorConfig struct {
 	// MergeStrategy defines how merge operations are configured.
 	// If nil, automatically selected based on Greenfield flag.
 	MergeStrategy *MergeStrategy
+	// BudgetManager enforces cost budgets. If nil, one is created from Policy.Budget.
+	BudgetManager *BudgetManager
 }
 
 // Orchestrator coordinates the entire workflow from request to completion.
@@ -124,30 +170,68 @@ type Orchestrator struct {
 	semanticMerger *SemanticMerger
 	secondReviewer *SecondReviewer
 	sessionMgr     *SessionBranchManager
+	sessionGate    SessionMergeGate   // approves merging the finished session; nil merges it
+	approvals      *ApprovalGates     // holds the session for operator approval
+	rateLimiter    *agent.RateLimiter // reported as rate_limited events; may be nil
+	usageMeter     *agent.UsageMeter  // reported as cost_tick events; may be nil
 	mergeQueue     *MergeQueue
 	mergeVerifier  *MergeVerifier
+	// patches applies task patches in plain mode; nil for git
+	patches agent.PatchApplier
 
 	// Support components
 	collision          *CollisionChecker
+	leases             *LeaseManager
 	protected          *protect.Detector
+	protectedPolicy    *protect.Policy
 	overrideGate       *ScoutOverrideGate
 	learnings          learning.LearningProvider
 	progCoord          *ProgCoordinator
 	learningCoord      *LearningCoordinator
 	effectivenessTracker *learning.EffectivenessTracker
 	structureAnalyzer  *structure.StructureAnalyzer
+	budget             *BudgetManager
 
 	// External dependencies
 	stateDB       state.StateStore
+	presetTasks   []*models.Task // Run instead of decomposing, if set
 	runnerFactory agent.ClaudeRunnerFactory
 	logger        *DebugLogger
+	// log writes the session's structured records.
+	log *slog.Logger
 
 	// Runtime state
-	emitter   *EventEmitter
+	emitter  *EventEmitter
+	eventLog *EventLog
+	// eventStore persists every event to the state DB for replay, if the
+	// state DB keeps session events.
+	eventStore *EventStore
+	// recorder records events to the event log and event store.
+	recorder EventRecorder
+	// eventFilter holds back noisy events from subscribers
+	eventFilter *EventFilter
+	// estimate is the pre-run cost estimate, set once tasks are resolved
+	estimate *RunEstimate
 	stopCh    chan struct{}
 	wg        sync.WaitGroup
 	registry  *AgentRegistry
 	pauseCtrl *PauseController
+	// inflightTasks are the tasks agents are running, keyed by task ID.
+	// Owned by the run loop; the control socket cancels tasks through it.
+	inflightTasks map[string]*inflight
+	inflightMu    sync.Mutex
+	// control serves the session's control socket while it runs
+	control *ControlServer
+	// schedulerMu guards publishing the scheduler, which the control socket
+	// reads from its own goroutine once the graph is built
+	schedulerMu sync.RWMutex
+	// drain tracks a graceful shutdown requested with Drain
+	drain drainState
+	// escalator retries tasks that keep failing validation at a higher
+	// tier. Nil if no tier config escalates.
+	escalator *tierEscalator
+	// questions counts the questions agents ask per task
+	questions *QuestionBudget
 
 	// Merge conflict blocking state
 	mergeConflictMu      sync.RWMutex
@@ -188,18 +272,27 @@ func New(req RequiredConfig, opts ...Option) *Orchestrator {
 // Prefer using New() with functional options for cleaner API.
 func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	sessionID := uuid.New().String()[:8]
+	olog := sessionLogger("orchestrator", sessionID)
 
 	// Use provided policy or default
 	policyConfig := cfg.Policy
 	if policyConfig == nil {
 		policyConfig = policy.Default()
 	}
+	// Tier-specific retry settings override the policy (copied so the caller's policy is untouched)
+	if cfg.TierConfigs != nil {
+		if tc := cfg.TierConfigs.Get(cfg.Tier); tc != nil && tc.Retry != nil {
+			tiered := *policyConfig
+			tiered.Retry = retryPolicyFromTier(tiered.Retry, tc.Retry)
+			policyConfig = &tiered
+		}
+	}
 	_ = policyConfig.Validate() // Normalize values
 
 	// Use injected dependencies or create defaults
 	decomposer := cfg.Decomposer
 	if decomposer == nil {
-		decomposer = decompose.New(cfg.DecomposerClaude)
+		decomposer = decompose.New(cfg.DecomposerClaude, decompose.WithRunnerFactory(cfg.ClaudeRunnerFactory))
 	}
 
 	g := cfg.Graph
@@ -216,6 +309,9 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	if protected == nil {
 		protected = protect.New()
 	}
+	if cfg.ProtectedPolicy != nil {
+		protected.SetPolicy(cfg.ProtectedPolicy)
+	}
 
 	// Create scout override gate - use injected or create from protected area checker
 	overrideGate := cfg.OverrideGate
@@ -231,10 +327,15 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 		overrideGate = NewScoutOverrideGateWithPolicy(protected, overridePolicy, cfg.TierConfigs)
 	}
 
+	budget := cfg.BudgetManager
+	if budget == nil {
+		budget = NewBudgetManager(policyConfig.Budget)
+	}
+
 	// Create git runner - use provided or create default
 	gitRunner := cfg.GitRunner
 	if gitRunner == nil {
-		gitRunner = git.NewRunner(cfg.RepoPath)
+		gitRunner = git.NewRunner(cfg.RepoPath).WithCommitIdentity(cfg.CommitIdentity)
 	}
 
 	// Create exec runner - use provided or create default
@@ -243,8 +344,26 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 		execRunner = iexec.NewRunner()
 	}
 
+	// Resolve the main branch once so every component agrees on it
+	mainBranch := cfg.MainBranch
+	if mainBranch == "" {
+		detected, err := git.DetectDefaultBranch(gitRunner)
+		if err != nil {
+			olog.Warn("could not detect the default branch, assuming main", logging.Err(err))
+			detected = "main"
+		}
+		mainBranch = detected
+	}
+
+	operator := cfg.Operator
+	if operator == "" {
+		operator = operatorIdentity(gitRunner)
+	}
+
 	// Session branch manager
 	sessionMgr := NewSessionBranchManagerWithRunner(sessionID, cfg.RepoPath, cfg.Greenfield, gitRunner)
+	sessionMgr.SetMainBranch(mainBranch)
+	sessionMgr.SetCommitIdentity(cfg.CommitIdentity)
 
 	// Create or use injected merge strategy
 	mergeStrategy := cfg.MergeStrategy
@@ -252,14 +371,56 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 		mergeStrategy = NewMergeStrategy(MergeStrategyConfig{
 			RepoPath:             cfg.RepoPath,
 			SessionBranch:        sessionMgr.GetBranchName(),
+			MainBranch:           mainBranch,
 			GitRunner:            gitRunner,
 			MergerClaude:         cfg.MergerClaude,
 			SecondReviewerClaude: cfg.SecondReviewerClaude,
+			RunnerFactory:        cfg.ClaudeRunnerFactory,
+			ReviewPolicy:         &policyConfig.Review,
 			Protected:            protected,
 			Greenfield:           cfg.Greenfield,
 		})
 	}
 
+	// Tier-specific validation pipeline; an invalid one falls back to the default
+	var validation *agent.ValidationPipeline
+	if cfg.TierConfigs != nil {
+		if tc := cfg.TierConfigs.Get(cfg.Tier); tc != nil {
+			var err error
+			if validation, err = agent.ValidationPipelineFromConfig(tc.Validation); err != nil {
+				olog.Warn("invalid validation config, using default", "tier", cfg.Tier, logging.Err(err))
+			}
+		}
+	}
+
+	// Tier-specific command sandbox; a broken one fails every command rather
+	// than running it on the host
+	var sandbox iexec.CommandRunner
+	if cfg.TierConfigs != nil {
+		if tc := cfg.TierConfigs.Get(cfg.Tier); tc != nil && tc.Sandbox != nil {
+			sbCfg := tc.Sandbox.Exec(cfg.SandboxAllowlist)
+			sbCfg.ProjectDir = cfg.RepoPath
+			sb, err := iexec.NewSandboxRunner(sbCfg)
+			if err != nil {
+				olog.Warn("invalid sandbox config, gate and verification commands will fail", "tier", cfg.Tier, logging.Err(err))
+				sandbox = iexec.NewFailingRunner(err)
+			} else {
+				sandbox = sb
+			}
+		}
+	}
+
+	// Per-tier prompt context budgets; escalated tasks use their new tier's
+	var contextBudgets map[models.Tier]int
+	if cfg.TierConfigs != nil {
+		contextBudgets = make(map[models.Tier]int)
+		for _, tier := range []models.Tier{models.TierScout, models.TierBuilder, models.TierArchitect} {
+			if tc := cfg.TierConfigs.Get(tier); tc != nil && tc.ContextBudget > 0 {
+				contextBudgets[tier] = tc.ContextBudget
+			}
+		}
+	}
+
 	// Determine maxAgents from config or TierConfigs
 	maxAgents := cfg.MaxAgents
 	if maxAgents <= 0 && cfg.TierConfigs != nil {
@@ -283,20 +444,33 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	// Create event emitter with large buffer to prevent event loss
 	// Buffer size of 1000 supports ~10 concurrent tasks with ~100 events each
 	emitter := NewEventEmitter(1000)
+	// Filter noisy events (agent progress) before they reach subscribers
+	eventFilter := NewEventFilter(policyConfig.Events)
+	emitter.SetFilter(eventFilter)
 
 	// Create prog coordinator for cross-session task tracking
 	progCoord := NewProgCoordinator(cfg.ProgClient, emitter, cfg.OriginalTaskID, cfg.Tier, cfg.ResumeEpicID)
+	if links, ok := cfg.StateDB.(ProgLinkStore); ok {
+		progCoord.SetLinkStore(links)
+	}
 
 	// Create learning coordinator for learning capture on task completion
-	learningCoord := NewLearningCoordinator(progCoord, cfg.Tier)
+	concepts, _ := cfg.LearningSystem.(learning.ConceptMatcher)
+	learningCoord := NewLearningCoordinator(progCoord, cfg.Tier, concepts)
 
 	// Create agent spawner (scheduler will be set later in Run)
 	spawner := NewAgentSpawner(cfg.Executor, collision, nil, emitter.Channel(), cfg.RepoPath)
+	spawner.SetFilter(eventFilter)
 
 	// Create merge components from strategy
 	merger := mergeStrategy.CreateMerger()
 	semanticMerger := mergeStrategy.CreateSemanticMerger()
 	secondReviewer := mergeStrategy.CreateSecondReviewer()
+	sessionGate := newSessionMergeGate(cfg.SessionMergeGate, policyConfig.Merge.SessionReview, secondReviewer)
+	approvals := cfg.ApprovalGates
+	if approvals == nil {
+		approvals = NewApprovalGates(policyConfig.Approval)
+	}
 
 	// Apply configuration defaults
 	// Verification defaults to enabled unless explicitly disabled
@@ -318,9 +492,9 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 
 	// Create merge verifier for post-merge build verification (if enabled)
 	var mergeVerifier *MergeVerifier
-	if enableVerification {
+	if enableVerification && cfg.VCS != vcs.KindPlain {
 		projectInfo := GetProjectTypeInfo(cfg.RepoPath)
-		mergeVerifier = NewMergeVerifier(cfg.RepoPath, projectInfo, verificationTimeout)
+		mergeVerifier = NewMergeVerifier(cfg.RepoPath, projectInfo, verificationTimeout, sandbox)
 		logger.Log("[orchestrator] post-merge verification enabled (timeout: %v, project type: %s)", verificationTimeout, projectInfo.Type)
 	} else {
 		logger.Log("[orchestrator] post-merge verification disabled")
@@ -350,9 +524,17 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 		Tier:           cfg.Tier,
 		MaxAgents:      maxAgents,
 		Greenfield:     cfg.Greenfield,
+		MainBranch:     mainBranch,
+		Operator:       operator,
 		OriginalTaskID: cfg.OriginalTaskID,
 		Policy:         policyConfig,
-		// Baseline is set later in Run() after capture
+		Validation:     validation,
+		Sandbox:        sandbox,
+		ContextBudgets: contextBudgets,
+		KeepSession:    cfg.KeepSessionBranch,
+		VCS:            cfg.VCS,
+		// Baseline is captured in Run() unless one was given
+		Baseline: cfg.Baseline,
 	}
 
 	o := &Orchestrator{
@@ -365,24 +547,47 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 		semanticMerger:    semanticMerger,
 		secondReviewer:    secondReviewer,
 		sessionMgr:        sessionMgr,
+		sessionGate:       sessionGate,
+		approvals:         approvals,
+		rateLimiter:       cfg.RateLimiter,
+		usageMeter:        cfg.UsageMeter,
 		mergeQueue:        nil, // Created in Run
 		mergeVerifier:     mergeVerifier,
 		collision:         collision,
+		leases:            NewLeaseManager(),
 		protected:         protected,
+		protectedPolicy:   cfg.ProtectedPolicy,
 		overrideGate:      overrideGate,
+		escalator:         newTierEscalator(cfg.TierConfigs, cfg.Tier),
+		questions:         NewQuestionBudget(),
 		learnings:         cfg.LearningSystem,
 		progCoord:         progCoord,
 		learningCoord:     learningCoord,
 		structureAnalyzer: structureAnalyzer,
+		budget:            budget,
 		stateDB:           cfg.StateDB,
+		presetTasks:       cfg.Tasks,
 		runnerFactory:     cfg.ClaudeRunnerFactory,
 		logger:            logger,
+		log:               olog,
 		emitter:           emitter,
+		eventFilter:       eventFilter,
 		stopCh:            make(chan struct{}),
 		registry:          NewAgentRegistry(),
 		pauseCtrl:         NewPauseController(),
 	}
 
+	// Components of the session log with its ID
+	emitter.SetLogger(sessionLogger("event_emitter", sessionID))
+	spawner.SetLogger(sessionLogger("agent_spawner", sessionID))
+	progCoord.SetLogger(sessionLogger("prog", sessionID))
+	learningCoord.SetLogger(sessionLogger("learning", sessionID))
+	o.pauseCtrl.SetLogger(olog)
+
+	if cfg.VCS == vcs.KindPlain {
+		o.patches, _ = cfg.Executor.(agent.PatchApplier)
+	}
+
 	// Initialize effectiveness tracker if learning system is available
 	if ls, ok := cfg.LearningSystem.(*learning.LearningSystem); ok {
 		o.effectivenessTracker = learning.NewEffectivenessTracker(ls.GetStore())
@@ -407,6 +612,17 @@ func (o *Orchestrator) emitEvent(event OrchestratorEvent) {
 	o.emitter.Emit(event)
 }
 
+// SessionID returns the ID of the orchestration session.
+func (o *Orchestrator) SessionID() string {
+	return o.config.SessionID
+}
+
+// GetLeases returns the file ownership leases held by running tasks,
+// ordered by path. It is safe to call while the orchestrator is running.
+func (o *Orchestrator) GetLeases() []FileLease {
+	return o.leases.GetLeases()
+}
+
 // GetSessionBranch returns the session branch name.
 func (o *Orchestrator) GetSessionBranch() string {
 	if o.sessionMgr != nil {
@@ -427,6 +643,12 @@ func (o *Orchestrator) QuestionsAllowedForTask(taskID string) int {
 	return QuestionsAllowed(o.config.Tier, o.overrideGate, taskID)
 }
 
+// QuestionsAskedForTask returns the number of questions the task's agents
+// asked so far.
+func (o *Orchestrator) QuestionsAskedForTask(taskID string) int {
+	return o.questions.Asked(taskID)
+}
+
 // GetProgEpicID returns the prog epic ID for cross-session tracking.
 // Returns empty string if no epic is associated with this session.
 func (o *Orchestrator) GetProgEpicID() string {
@@ -443,7 +665,7 @@ func (o *Orchestrator) SetMergeConflict(taskID string, files []string) {
 	o.mergeConflictTask = taskID
 	o.mergeConflictFiles = files
 
-	o.logger.Log("MERGE_CONFLICT", "Blocking all scheduling - conflict in task %s (%d files)", taskID, len(files))
+	o.logger.Log("[MERGE_CONFLICT] Blocking all scheduling - conflict in task %s (%d files)", taskID, len(files))
 }
 
 // HasMergeConflict returns true if there is an active merge conflict blocking scheduling.
@@ -462,7 +684,7 @@ func (o *Orchestrator) ClearMergeConflict() {
 		return
 	}
 
-	o.logger.Log("MERGE_RESOLVED", "Clearing merge conflict flag - resuming scheduling")
+	o.logger.Log("[MERGE_RESOLVED] Clearing merge conflict flag - resuming scheduling")
 	o.hasMergeConflict = false
 	o.mergeConflictTask = ""
 	o.mergeConflictFiles = nil
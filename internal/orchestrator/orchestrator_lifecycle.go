@@ -3,13 +3,17 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os/exec"
+	"io"
 	"path/filepath"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/decompose"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
@@ -27,6 +31,17 @@ func (o *Orchestrator) Run(ctx context.Context, request string) error {
 		return fmt.Errorf("orchestrator has been stopped")
 	}
 
+	// Refuse to run alongside another Alphie process on the same repository
+	releaseLock, err := acquireSessionLock(o.config.RepoPath)
+	if err != nil {
+		if errors.Is(err, ErrSessionLocked) {
+			return err
+		}
+		o.log.Warn("session lock unavailable", logging.Err(err))
+		releaseLock = func() {}
+	}
+	defer releaseLock()
+
 	// Create a derived context that we can cancel
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -40,6 +55,30 @@ func (o *Orchestrator) Run(ctx context.Context, request string) error {
 		}
 	}()
 
+	// Persist events so the session can be replayed later
+	o.openEventLog()
+	if o.eventLog != nil {
+		defer o.eventLog.Close()
+	}
+	if o.eventStore != nil {
+		defer o.eventStore.Close()
+	}
+	defer o.reportRateLimits()()
+	defer o.reportUsage()()
+	defer func() {
+		if n := o.eventFilter.Suppressed(); n > 0 {
+			o.logger.Log("[orchestrator] event filter held back %d noisy events", n)
+		}
+	}()
+
+	if o.config.Greenfield {
+		o.recordDecision(Decision{
+			Kind:   DecisionWaiver,
+			Actor:  HumanActor(o.config.Operator),
+			Reason: fmt.Sprintf("Greenfield mode: agent work merges directly into %s without a session branch", o.config.MainBranch),
+		})
+	}
+
 	// Create session in state DB
 	if err := o.createSessionState(request); err != nil {
 		return fmt.Errorf("create session state: %w", err)
@@ -47,12 +86,21 @@ func (o *Orchestrator) Run(ctx context.Context, request string) error {
 
 	// Capture baseline at session start for regression detection
 	if err := o.captureBaseline(); err != nil {
-		log.Printf("[orchestrator] warning: failed to capture baseline: %v", err)
+		o.log.Warn("failed to capture baseline", logging.Err(err))
 	}
 
+	// Let operators pause, throttle and cancel work and answer approval
+	// gates while the session runs
+	o.startControlServer()
+	defer o.stopControlServer()
+
 	// Get or decompose tasks
 	tasks, err := o.resolveTasks(ctx, request)
 	if err != nil {
+		if errors.Is(err, ErrApprovalRejected) {
+			o.updateSessionStatus(state.SessionCanceled)
+			return err
+		}
 		o.updateSessionStatus(state.SessionFailed)
 		return err
 	}
@@ -62,45 +110,91 @@ func (o *Orchestrator) Run(ctx context.Context, request string) error {
 		o.updateSessionStatus(state.SessionFailed)
 		return fmt.Errorf("persist tasks: %w", err)
 	}
+	o.recordSessionOrigin(tasks)
+	o.estimateTasks(tasks)
 
 	// Build dependency graph
 	if err := o.graph.Build(tasks); err != nil {
 		o.updateSessionStatus(state.SessionFailed)
 		return fmt.Errorf("build dependency graph: %w", err)
 	}
+	if o.eventLog != nil {
+		o.eventLog.RecordGraph(tasks)
+	}
 
 	// Create scheduler now that graph is built
-	o.scheduler = NewScheduler(o.graph, o.config.Tier, o.config.MaxAgents)
-	o.scheduler.SetCollisionChecker(o.collision)
-	o.scheduler.SetGreenfield(o.config.Greenfield)
-	o.scheduler.SetOrchestrator(o) // For merge conflict checking
+	scheduler := NewScheduler(o.graph, o.config.Tier, o.config.MaxAgents)
+	scheduler.SetCollisionChecker(o.collision)
+	scheduler.SetGreenfield(o.config.Greenfield)
+	scheduler.SetResourceRules(o.config.Policy.Scheduling.ResourceRules)
+	scheduler.SetLeaseManager(o.leases)
+	if o.config.Policy.Scheduling.Preemption {
+		scheduler.SetPreemption(o.config.Policy.Scheduling.PreemptPriority)
+	}
+	if threshold := o.config.Policy.Scheduling.ConflictThreshold; threshold > 0 {
+		predictor := NewConflictPredictor(git.NewRunner(o.config.RepoPath), threshold)
+		predictor.SetLogger(sessionLogger("scheduler", o.config.SessionID))
+		scheduler.SetConflictPredictor(predictor)
+	}
+	scheduler.SetOrchestrator(o) // For merge conflict checking
+	o.schedulerMu.Lock()
+	o.scheduler = scheduler
+	o.schedulerMu.Unlock()
+
+	// Keep tasks escalated by earlier sessions parked until resolved
+	o.restoreEscalations()
 
 	// Wire scheduler into spawner (scheduler wasn't available at construction)
 	o.spawner.SetScheduler(o.scheduler)
 
+	// Pause spawning while the machine is low on disk or memory
+	defer o.startResourceGuard(ctx)()
+
 	// Create merge queue for serialized, reliable merging
 	o.mergeQueue = o.createMergeQueue()
+	if o.recorder != nil {
+		o.mergeQueue.SetRecorder(o.recorder)
+	}
+	o.mergeQueue.SetFilter(o.eventFilter)
 	defer o.mergeQueue.Stop()
 
-	// Create session branch
-	if err := o.sessionMgr.CreateBranch(); err != nil {
-		o.updateSessionStatus(state.SessionFailed)
-		return fmt.Errorf("create session branch: %w", err)
+	// Create session branch; plain mode applies patches to the directory
+	if !o.config.PlainMode() {
+		if err := o.sessionMgr.CreateBranch(); err != nil {
+			o.updateSessionStatus(state.SessionFailed)
+			return fmt.Errorf("create session branch: %w", err)
+		}
 	}
 
 	// Main execution loop
 	if err := o.runLoop(ctx); err != nil {
+		if errors.Is(err, errSessionDrained) {
+			if o.isAborted() {
+				o.updateSessionStatus(state.SessionCanceled)
+				return fmt.Errorf("execution loop: %w", ErrSessionAborted)
+			}
+			return o.checkpointSession(ctx)
+		}
 		o.handleRunError()
 		o.updateSessionStatus(state.SessionFailed)
 		return fmt.Errorf("execution loop: %w", err)
 	}
 
 	// Merge session branch to main
-	o.finalizeSession()
+	mergeErr := o.finalizeSession(ctx)
 
 	// Mark session completed and emit done event
 	o.updateSessionStatus(state.SessionCompleted)
 	o.updateProgEpicStatus()
+	if mergeErr != nil {
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventSessionDone,
+			Message:   "All tasks completed; session branch kept for review",
+			Error:     mergeErr,
+			Timestamp: time.Now(),
+		})
+		return mergeErr
+	}
 	o.emitEvent(OrchestratorEvent{
 		Type:      EventSessionDone,
 		Message:   "All tasks completed successfully",
@@ -110,46 +204,102 @@ func (o *Orchestrator) Run(ctx context.Context, request string) error {
 	return nil
 }
 
-// captureBaseline captures the baseline at session start for regression detection.
-func (o *Orchestrator) captureBaseline() error {
-	baseline, err := agent.CaptureBaseline(o.config.RepoPath)
+// openEventLog starts recording events to the session's event log and,
+// if the state DB keeps session events, to the state DB for replay.
+// Failure to open the log is logged and the session runs without it.
+func (o *Orchestrator) openEventLog() {
+	var recorders EventRecorders
+	eventLog, err := NewEventLog(EventLogPath(o.config.RepoPath, o.config.SessionID))
 	if err != nil {
-		return err
+		o.log.Warn("event log unavailable", logging.Err(err))
+	} else {
+		o.eventLog = eventLog
+		recorders = append(recorders, eventLog)
+	}
+	if store, ok := o.stateDB.(state.SessionEventStore); ok {
+		o.eventStore = NewEventStore(store, o.config.SessionID)
+		recorders = append(recorders, o.eventStore)
 	}
-	o.config.Baseline = baseline
+	if len(recorders) == 0 {
+		return
+	}
+	o.recorder = recorders
+	o.emitter.SetRecorder(recorders)
+	o.spawner.SetRecorder(recorders)
+}
+
+// recordDecision adds a decision to the session's audit trail.
+func (o *Orchestrator) recordDecision(d Decision) {
+	if o.eventLog != nil {
+		o.eventLog.RecordDecision(d)
+	}
+}
+
+// captureBaseline captures the baseline at session start for regression
+// detection, unless one was given, and saves it with the session.
+func (o *Orchestrator) captureBaseline() error {
+	baseline := o.config.Baseline
+	if baseline == nil {
+		var err error
+		if baseline, err = agent.CaptureBaseline(o.config.RepoPath); err != nil {
+			return err
+		}
+		o.config.Baseline = baseline
+	}
+	o.persistBaseline(baseline)
 	baselinePath := filepath.Join(o.config.RepoPath, ".alphie", "baselines", fmt.Sprintf("%s.json", o.config.SessionID))
 	if saveErr := baseline.Save(baselinePath); saveErr != nil {
-		log.Printf("[orchestrator] warning: failed to save baseline: %v", saveErr)
+		o.log.Warn("failed to save baseline", logging.Err(saveErr))
 	} else {
-		o.logger.Log("Baseline captured: %d failing tests, %d lint errors, %d type errors",
+		o.logger.Log("Baseline captured at commit %q: build passing=%t, %d failing tests, %d lint errors, %d type errors",
+			baseline.Commit, baseline.BuildPassed,
 			len(baseline.FailingTests), len(baseline.LintErrors), len(baseline.TypeErrors))
 	}
 	return nil
 }
 
-// resolveTasks either loads tasks from existing epic or decomposes the request.
+// resolveTasks returns the predefined tasks, loads tasks from an existing
+// epic, or decomposes the request. Decomposed tasks wait at the
+// decomposition approval gate, if enabled.
 func (o *Orchestrator) resolveTasks(ctx context.Context, request string) ([]*models.Task, error) {
+	if len(o.presetTasks) > 0 {
+		o.log.Info("running predefined tasks without decomposition", "tasks", len(o.presetTasks))
+		return o.presetTasks, nil
+	}
+
 	if o.progCoord.HasResumeEpic() {
 		tasks, err := o.progCoord.LoadTasksFromEpic(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("load tasks from prog epic %s: %w", o.progCoord.EpicID(), err)
 		}
-		log.Printf("[orchestrator] resuming epic %s with %d tasks", o.progCoord.EpicID(), len(tasks))
+		o.log.Info("resuming epic", "epic", o.progCoord.EpicID(), "tasks", len(tasks))
 		return tasks, nil
 	}
 
 	// Decompose request into tasks
 	tasks, err := o.decomposer.Decompose(ctx, request)
 	if err != nil {
+		var invalid *decompose.DecompositionError
+		if errors.As(err, &invalid) {
+			o.logger.Log("[orchestrator] invalid decomposition output:\n%s", invalid.RawOutput)
+		}
 		return nil, fmt.Errorf("decompose request: %w", err)
 	}
 	if len(tasks) == 0 {
 		return nil, fmt.Errorf("no tasks generated from request")
 	}
+	for _, issue := range o.decomposer.CriteriaIssues(tasks) {
+		o.log.Warn("weak acceptance criterion", "task", issue.Task, "criterion", issue.Criterion, "problem", issue.Problem)
+	}
+
+	// Hold the plan for approval before any agent runs
+	if err := o.awaitApproval(ctx, policy.ApprovalDecomposition, decompositionSummary(tasks)); err != nil {
+		return nil, err
+	}
 
 	// Create prog epic and tasks for cross-session tracking
 	if err := o.progCoord.CreateEpicAndTasks(request, tasks); err != nil {
-		log.Printf("[orchestrator] warning: failed to create prog epic/tasks: %v", err)
+		o.log.Warn("failed to create prog epic and tasks", logging.Err(err))
 	}
 	return tasks, nil
 }
@@ -161,7 +311,7 @@ func (o *Orchestrator) createMergeQueue() *MergeQueue {
 			return o.semanticMerger
 		}
 		freshClaude := o.runnerFactory.NewRunner()
-		return NewSemanticMerger(freshClaude, o.config.RepoPath)
+		return NewSemanticMergerWithRunner(freshClaude, o.config.RepoPath, o.merger.GitRunner())
 	}
 
 	mq := NewMergeQueueWithPolicy(
@@ -181,9 +331,18 @@ func (o *Orchestrator) createMergeQueue() *MergeQueue {
 	processor := mq.GetProcessor()
 	if processor != nil {
 		processor.SetOrchestrator(o)
+		processor.SetMainBranch(o.config.MainBranch)
 		if o.merger != nil {
 			processor.SetGitRunner(o.merger.GitRunner())
 		}
+		// Low-confidence semantic merges are quarantined for review when the
+		// state database can hold the review queue
+		if reviews, ok := o.stateDB.(MergeReviewStore); ok {
+			processor.SetReviewQueue(reviews, o.config.SessionID)
+		}
+		if o.protectedPolicy != nil {
+			processor.SetProtectedPolicy(o.protectedPolicy, o.secondReviewer)
+		}
 	}
 
 	return mq
@@ -191,6 +350,13 @@ func (o *Orchestrator) createMergeQueue() *MergeQueue {
 
 // handleRunError cleans up after a run error.
 func (o *Orchestrator) handleRunError() {
+	if o.config.PlainMode() {
+		return
+	}
+	if o.config.KeepSession {
+		_ = o.checkoutMain()
+		return
+	}
 	if !o.config.Greenfield {
 		_ = o.sessionMgr.Cleanup()
 	} else {
@@ -198,19 +364,39 @@ func (o *Orchestrator) handleRunError() {
 	}
 }
 
-// finalizeSession merges session branch to main and cleans up.
-func (o *Orchestrator) finalizeSession() {
-	if o.config.Greenfield || o.sessionMgr == nil {
-		return
+// finalizeSession merges session branch to main and cleans up. If the
+// session merge gate rejects the merge, the session branch is kept and the
+// returned error wraps ErrSessionMergeRejected.
+func (o *Orchestrator) finalizeSession(ctx context.Context) error {
+	if o.config.Greenfield || o.config.PlainMode() || o.sessionMgr == nil {
+		return nil
+	}
+	if o.config.KeepSession {
+		o.log.Info("keeping session branch for inspection", "branch", o.sessionMgr.GetBranchName())
+		_ = o.checkoutMain()
+		return nil
+	}
+	if err := o.reviewSessionMerge(ctx); err != nil {
+		o.log.Info("not merging session", "branch", o.config.MainBranch, "reason", err)
+		_ = o.checkoutMain()
+		return err
 	}
 	if err := o.sessionMgr.MergeToMain(); err != nil {
-		log.Printf("[orchestrator] warning: failed to merge session to main: %v", err)
-		return
+		o.log.Warn("failed to merge session", "branch", o.config.MainBranch, logging.Err(err))
+		return nil
+	}
+	o.log.Info("merged session branch", "branch", o.config.MainBranch)
+	if o.eventLog != nil {
+		commit, err := o.sessionMgr.HeadCommit()
+		if err != nil {
+			commit = "unknown commit"
+		}
+		o.eventLog.RecordSessionMerge(o.sessionMgr.GetBranchName(), o.config.MainBranch, commit, ActorAlphie)
 	}
-	log.Printf("[orchestrator] merged session branch to main")
 	if err := o.sessionMgr.Cleanup(); err != nil {
-		log.Printf("[orchestrator] warning: failed to cleanup session branch: %v", err)
+		o.log.Warn("failed to clean up session branch", logging.Err(err))
 	}
+	return nil
 }
 
 // updateProgEpicStatus updates the prog epic status if all tasks are complete.
@@ -220,9 +406,9 @@ func (o *Orchestrator) updateProgEpicStatus() {
 	}
 	epicID := o.progCoord.EpicID()
 	if done, err := o.progCoord.Client().UpdateEpicStatusIfComplete(epicID); err != nil {
-		log.Printf("[orchestrator] warning: failed to update epic status: %v", err)
+		o.log.Warn("failed to update epic status", "epic", epicID, logging.Err(err))
 	} else if done {
-		log.Printf("[orchestrator] epic %s marked as done", epicID)
+		o.log.Info("epic marked as done", "epic", epicID)
 	}
 }
 
@@ -242,8 +428,18 @@ func (o *Orchestrator) Stop() error {
 	// Close events channel
 	o.emitter.Close()
 
+	// Remove task containers left by tasks that did not finish
+	if closer, ok := o.config.Sandbox.(io.Closer); ok {
+		_ = closer.Close()
+	}
+
 	// Cleanup session branch if not greenfield
-	if !o.config.Greenfield && o.sessionMgr != nil {
+	if o.config.PlainMode() {
+		return nil
+	}
+	if o.config.KeepSession {
+		_ = o.checkoutMain()
+	} else if !o.config.Greenfield && o.sessionMgr != nil {
 		if err := o.sessionMgr.Cleanup(); err != nil {
 			return fmt.Errorf("cleanup session: %w", err)
 		}
@@ -256,12 +452,8 @@ func (o *Orchestrator) Stop() error {
 
 // checkoutMain ensures the repository is on the main branch.
 func (o *Orchestrator) checkoutMain() error {
-	cmd := exec.Command("git", "checkout", "main")
-	cmd.Dir = o.config.RepoPath
-	if err := cmd.Run(); err != nil {
-		cmd = exec.Command("git", "checkout", "master")
-		cmd.Dir = o.config.RepoPath
-		return cmd.Run()
+	if err := git.NewRunner(o.config.RepoPath).CheckoutBranch(o.config.MainBranch); err != nil {
+		return fmt.Errorf("checkout %s: %w", o.config.MainBranch, err)
 	}
 	return nil
 }
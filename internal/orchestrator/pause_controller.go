@@ -4,7 +4,7 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 )
 
@@ -15,26 +15,35 @@ type PauseController struct {
 	paused bool
 	// stopped indicates whether the orchestrator has been stopped.
 	stopped bool
+	// draining indicates the orchestrator is shutting down and will not
+	// spawn agents again, so waiting for a resume is pointless.
+	draining bool
 	// mu protects all fields.
 	mu sync.RWMutex
 	// cond is used to signal when the orchestrator is unpaused or stopped.
 	cond *sync.Cond
+	log  *slog.Logger
 }
 
 // NewPauseController creates a new PauseController.
 func NewPauseController() *PauseController {
-	p := &PauseController{}
+	p := &PauseController{log: pkgLog}
 	p.cond = sync.NewCond(&p.mu)
 	return p
 }
 
+// SetLogger sets the logger of the controller's records.
+func (p *PauseController) SetLogger(l *slog.Logger) {
+	p.log = l
+}
+
 // Pause pauses execution. New agents will not be spawned.
 func (p *PauseController) Pause() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if !p.paused {
 		p.paused = true
-		log.Printf("[orchestrator] paused - no new agents will be spawned")
+		p.log.Info("paused: no new agents will be spawned")
 	}
 }
 
@@ -44,7 +53,7 @@ func (p *PauseController) Resume() {
 	defer p.mu.Unlock()
 	if p.paused {
 		p.paused = false
-		log.Printf("[orchestrator] resumed - agent spawning enabled")
+		p.log.Info("resumed: agent spawning enabled")
 		p.cond.Broadcast()
 	}
 }
@@ -59,6 +68,17 @@ func (p *PauseController) Stop() {
 	}
 }
 
+// Drain releases WaitIfPaused callers without resuming, for a session that
+// is shutting down gracefully and will not spawn agents again.
+func (p *PauseController) Drain() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.draining {
+		p.draining = true
+		p.cond.Broadcast()
+	}
+}
+
 // IsPaused returns whether execution is currently paused.
 func (p *PauseController) IsPaused() bool {
 	p.mu.RLock()
@@ -73,11 +93,11 @@ func (p *PauseController) IsStopped() bool {
 	return p.stopped
 }
 
-// WaitIfPaused blocks until the orchestrator is unpaused or stopped.
+// WaitIfPaused blocks until the orchestrator is unpaused, draining or stopped.
 // Returns an error if the context is cancelled or the controller is stopped.
 func (p *PauseController) WaitIfPaused(ctx context.Context) error {
 	p.mu.Lock()
-	if p.paused && !p.stopped {
+	if p.paused && !p.stopped && !p.draining {
 		// Spawn ONE goroutine to signal condition if context is cancelled
 		done := make(chan struct{})
 		go func() {
@@ -91,7 +111,7 @@ func (p *PauseController) WaitIfPaused(ctx context.Context) error {
 		}()
 
 		// Wait loop - no new goroutines spawned on spurious wakeups
-		for p.paused && !p.stopped {
+		for p.paused && !p.stopped && !p.draining {
 			p.cond.Wait()
 			if ctx.Err() != nil {
 				close(done)
@@ -0,0 +1,45 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/vcs"
+)
+
+// applyPatch lands the work of a task run in a plain workspace by applying
+// its patch to the project directory. Patches are applied whole or not at
+// all: one that conflicts with a patch applied since the task started
+// fails the merge with the conflicting files.
+func (o *Orchestrator) applyPatch(taskID string, result *agent.ExecutionResult) (*MergeOutcome, error) {
+	if result.Patch.Empty() {
+		o.progCoord.LogTask(taskID, "No changes to apply")
+		return &MergeOutcome{Success: true, Reason: "no changes"}, nil
+	}
+
+	apply := func(p *vcs.Patch) error { return vcs.Apply(o.config.RepoPath, p) }
+	if o.patches != nil {
+		apply = o.patches.ApplyPatch
+	}
+
+	err := apply(result.Patch)
+	var conflict *vcs.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		outcome := &MergeOutcome{
+			Error:         err,
+			Reason:        "patch conflicts with changes applied since the task started",
+			ConflictFiles: conflict.Files,
+		}
+		o.progCoord.LogTask(taskID, fmt.Sprintf("Patch conflicts in: %s", strings.Join(conflict.Files, ", ")))
+		return outcome, fmt.Errorf("merge failed: %s: %w", outcome.Reason, err)
+	case err != nil:
+		o.progCoord.LogTask(taskID, fmt.Sprintf("Applying patch failed: %v", err))
+		return &MergeOutcome{Error: err, Reason: "apply patch"}, fmt.Errorf("merge failed: apply patch: %w", err)
+	}
+
+	o.progCoord.LogTask(taskID, fmt.Sprintf("Applied patch to %d file(s)", len(result.Patch.Changes)))
+	return &MergeOutcome{Success: true, Reason: "patch applied"}, nil
+}
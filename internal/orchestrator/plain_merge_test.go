@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/vcs"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestPerformMerge_PlainModeAppliesPatch(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := vcs.TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "s1", RepoPath: root, VCS: vcs.KindPlain},
+		progCoord: NewProgCoordinator(nil, nil, "", models.TierBuilder, ""),
+	}
+
+	// Two tasks started from the same base both change a.txt
+	first := editedCopy(t, "a\nfirst\n")
+	firstPatch, _ := vcs.Diff(first, base)
+	outcome, err := o.performMerge(context.Background(), "t1", &agent.ExecutionResult{Patch: firstPatch})
+	if err != nil || !outcome.Success {
+		t.Fatalf("performMerge(first) = %+v, %v", outcome, err)
+	}
+
+	second := editedCopy(t, "a\nsecond\n")
+	secondPatch, _ := vcs.Diff(second, base)
+	outcome, err = o.performMerge(context.Background(), "t2", &agent.ExecutionResult{Patch: secondPatch})
+	if err == nil || outcome.Success {
+		t.Fatalf("expected the second patch to conflict, got %+v", outcome)
+	}
+	if !reflect.DeepEqual(outcome.ConflictFiles, []string{"a.txt"}) {
+		t.Errorf("conflict files = %v", outcome.ConflictFiles)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(data) != "a\nfirst\n" {
+		t.Errorf("a.txt = %q, want the first task's change", data)
+	}
+
+	outcome, err = o.performMerge(context.Background(), "t3", &agent.ExecutionResult{})
+	if err != nil || !outcome.Success {
+		t.Errorf("expected a task without changes to merge, got %+v, %v", outcome, err)
+	}
+}
+
+// editedCopy writes content to a.txt in a fresh directory and returns it.
+func editedCopy(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
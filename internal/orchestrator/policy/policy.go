@@ -25,6 +25,24 @@ type Config struct {
 
 	// Merge policies
 	Merge MergePolicy
+
+	// Budget policies
+	Budget BudgetPolicy
+
+	// Retry policies
+	Retry RetryPolicy
+
+	// Hung-agent watchdog policies
+	Heartbeat HeartbeatPolicy
+
+	// Resource guardrail policies
+	Resources ResourcePolicy
+
+	// Human approval gate policies
+	Approval ApprovalPolicy
+
+	// Event display policies
+	Events EventsPolicy
 }
 
 // SchedulingPolicy controls task scheduling behavior.
@@ -32,6 +50,34 @@ type SchedulingPolicy struct {
 	// RootTouchingPatterns are keywords indicating a task might modify root-level files.
 	// Used in greenfield mode to serialize tasks that might conflict on package.json, etc.
 	RootTouchingPatterns []string
+
+	// ResourceRules assign resource locks to tasks based on keywords, in addition
+	// to any locks the decomposer annotated. Tasks sharing a lock are serialized.
+	ResourceRules []ResourceRule
+
+	// Preemption lets a ready task at or above PreemptPriority stop a running
+	// lower-priority agent when every agent slot is taken. The stopped task is
+	// requeued and starts over once a slot frees up.
+	Preemption bool
+
+	// PreemptPriority is the least urgent priority (1=high, 3=low) that may
+	// preempt running agents.
+	PreemptPriority int
+
+	// ConflictThreshold is the predicted conflict probability (0-1) at
+	// which two ready tasks are serialized instead of run concurrently.
+	// 0 disables conflict prediction.
+	ConflictThreshold float64
+}
+
+// ResourceRule maps task keywords to a named shared resource.
+type ResourceRule struct {
+	// Resource is the lock name (e.g. "db:schema", "port:3000").
+	Resource string
+
+	// Patterns are case-insensitive substrings matched against a task's
+	// title, description, and file boundaries.
+	Patterns []string
 }
 
 // CollisionPolicy controls file collision detection.
@@ -51,6 +97,96 @@ type ReviewPolicy struct {
 
 	// CrossCuttingThreshold is the package count at which changes are cross-cutting.
 	CrossCuttingThreshold int
+
+	// Reviewers is the panel of independent reviewers. Each review is voted
+	// on by the reviewers whose triggers match it. Empty uses one general
+	// reviewer for every review.
+	Reviewers []ReviewerSpec
+
+	// Quorum is how many of a review's selected reviewers must approve the
+	// change. Zero requires a majority; it is capped at the number selected.
+	Quorum int
+
+	// Rubric lists the criteria reviewers give a verdict on. Reviewers
+	// with a rubric return one structured verdict per criterion instead of
+	// free-text concerns, and approve only if no criterion fails. Empty
+	// keeps the free-text review.
+	Rubric []RubricCriterion
+}
+
+// Built-in review rubric criteria. Configuring one by name alone uses its
+// built-in description.
+const (
+	RubricSecurity      = "security"
+	RubricErrorHandling = "error_handling"
+	RubricTestCoverage  = "test_coverage"
+	RubricStyle         = "style"
+	RubricI18n          = "i18n"
+	RubricAccessibility = "accessibility"
+)
+
+// rubricDescriptions describes the built-in rubric criteria.
+var rubricDescriptions = map[string]string{
+	RubricSecurity:      "No injection, unsafe input handling, leaked secrets, or missing authentication and authorization checks",
+	RubricErrorHandling: "Errors are checked, wrapped with context and surfaced; failures do not leave state inconsistent",
+	RubricTestCoverage:  "New and changed behavior is covered by tests, including failure paths and edge cases",
+	RubricStyle:         "The change follows the conventions, naming and structure of the surrounding code",
+	RubricI18n:          "User-facing text is translatable and dates, numbers and text direction are locale-aware",
+	RubricAccessibility: "User interfaces are usable with a keyboard and screen reader, with labels, roles and sufficient contrast",
+}
+
+// RubricCriterion is one criterion of the review rubric.
+type RubricCriterion struct {
+	// Name identifies the criterion in verdicts, e.g. "security".
+	Name string
+
+	// Description tells the reviewer what the criterion checks. Empty uses
+	// the built-in description of a built-in criterion.
+	Description string
+}
+
+// IsBuiltinRubricCriterion reports whether name is a built-in criterion.
+func IsBuiltinRubricCriterion(name string) bool {
+	_, ok := rubricDescriptions[name]
+	return ok
+}
+
+// Second review trigger types, used to select reviewers.
+const (
+	TriggerProtectedArea = "protected_area"
+	TriggerLargeDiff     = "large_diff"
+	TriggerWeakTests     = "weak_tests"
+	TriggerCrossCutting  = "cross_cutting"
+)
+
+// ReviewerSpec describes one reviewer on the second review panel.
+type ReviewerSpec struct {
+	// Name identifies the reviewer in findings and the audit trail.
+	Name string
+
+	// Focus tells the reviewer what to concentrate on, e.g. "security".
+	// Empty reviews for general correctness.
+	Focus string
+
+	// Triggers limits the reviewer to reviews raised by these trigger types.
+	// Empty reviews every trigger.
+	Triggers []string
+}
+
+// Handles reports whether the reviewer votes on a review raised by any of
+// the given trigger types.
+func (s ReviewerSpec) Handles(triggers []string) bool {
+	if len(s.Triggers) == 0 {
+		return true
+	}
+	for _, want := range s.Triggers {
+		for _, got := range triggers {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // OverridePolicy controls Scout question override behavior.
@@ -71,10 +207,185 @@ type LoopPolicy struct {
 	SpawnStagger time.Duration
 }
 
+// Actions for conflicts too large for the semantic merger.
+const (
+	// OversizeActionHuman sends oversized conflicts to the human/resolver queue.
+	OversizeActionHuman = "human"
+	// OversizeActionReexecute re-runs the task on the current session branch.
+	OversizeActionReexecute = "reexecute"
+)
+
+// Gates for merging a finished session into the main branch.
+const (
+	// SessionReviewNone merges sessions without a gate.
+	SessionReviewNone = "none"
+	// SessionReviewConfirm asks the operator to confirm the merge.
+	SessionReviewConfirm = "confirm"
+	// SessionReviewAuto has the reviewer panel approve the session diff.
+	SessionReviewAuto = "auto"
+)
+
 // MergePolicy controls merge queue behavior.
 type MergePolicy struct {
 	// QueueBufferSize is the buffer size for the merge queue channel.
 	QueueBufferSize int
+
+	// SemanticMaxConflictFiles is the number of conflicting files above which
+	// the semantic merger is skipped (0 = no limit).
+	SemanticMaxConflictFiles int
+
+	// SemanticMaxConflictLines is the number of lines changed in conflicting
+	// files, summed over both sides, above which the semantic merger is skipped
+	// (0 = no limit).
+	SemanticMaxConflictLines int
+
+	// OversizeConflictAction decides what happens to conflicts over the cutoff:
+	// OversizeActionHuman or OversizeActionReexecute.
+	OversizeConflictAction string
+
+	// OptimizeOrder simulates the possible orders of merges that are pending
+	// at the same time and merges them in the order with the fewest conflicts.
+	OptimizeOrder bool
+
+	// PartialMerge lands the parts of a conflicting merge that do not
+	// conflict right away, leaving only the conflicting hunks and files for
+	// the semantic merger or human review.
+	PartialMerge bool
+
+	// ReviewConfidenceThreshold is the semantic merge confidence below which
+	// the merge is held on a quarantine branch for human review instead of
+	// landing on the target branch (0 = never quarantine).
+	ReviewConfidenceThreshold float64
+
+	// SessionReview gates merging the finished session into the main branch:
+	// SessionReviewNone, SessionReviewConfirm or SessionReviewAuto. A session
+	// that is not approved keeps its branch for the operator.
+	SessionReview string
+}
+
+// BudgetPolicy controls cost budget enforcement.
+// A limit of 0 means no limit.
+type BudgetPolicy struct {
+	// TaskLimit is the maximum cost in dollars a single task may consume.
+	TaskLimit float64
+
+	// SessionLimit is the maximum cost in dollars for the whole session.
+	SessionLimit float64
+
+	// WarnRatio is the fraction of a limit at which a soft warning is emitted.
+	WarnRatio float64
+}
+
+// Failure classifications used by RetryPolicy.RetryOn.
+const (
+	// FailureExecution is an agent error or crash.
+	FailureExecution = "execution"
+	// FailureVerification is a failed quality gate or verification check.
+	FailureVerification = "verification"
+	// FailureTimeout is a task that ran past its timeout.
+	FailureTimeout = "timeout"
+	// FailureBudget is a task cancelled for exceeding its cost budget.
+	FailureBudget = "budget"
+)
+
+// RetryPolicy controls re-execution of failed tasks.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of executions allowed, including the first.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. It doubles on each
+	// subsequent retry, up to MaxBackoff.
+	Backoff time.Duration
+
+	// MaxBackoff caps the retry delay.
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each delay by up to this fraction (0-1) in either direction.
+	Jitter float64
+
+	// RetryOn lists the failure classifications that are retried.
+	// Other failures mark the task failed immediately.
+	RetryOn []string
+}
+
+// HeartbeatPolicy controls the watchdog that stops agents whose Claude call
+// stalls, so that they do not hold their slot indefinitely.
+type HeartbeatPolicy struct {
+	// StallTimeout is how long an agent may produce no progress events or
+	// tokens before it is stopped and its task requeued. A warning is
+	// emitted after half of it. 0 disables the watchdog.
+	StallTimeout time.Duration
+}
+
+// ResourcePolicy controls the guard that pauses the session when the
+// machine runs low on disk or memory.
+type ResourcePolicy struct {
+	// MinFreeDiskMB is the free disk, in MB, below which no new agents are
+	// spawned. It is checked on the filesystems of the repository and the
+	// worktrees. 0 disables the disk check.
+	MinFreeDiskMB int
+	// MinFreeMemoryMB is the available memory, in MB, below which no new
+	// agents are spawned. 0 disables the memory check.
+	MinFreeMemoryMB int
+	// CheckInterval is how often free disk and memory are measured.
+	CheckInterval time.Duration
+	// CleanupCommands are shell commands run in the repository to free disk
+	// (e.g. clearing build caches) when a threshold is crossed, after idle
+	// and stale worktrees are pruned.
+	CleanupCommands []string
+}
+
+// Approval gates used by ApprovalPolicy.Gates.
+const (
+	// ApprovalDecomposition holds a session after its request is decomposed,
+	// before any agent runs.
+	ApprovalDecomposition = "decomposition"
+	// ApprovalMerge holds a finished session before it merges into the main
+	// branch.
+	ApprovalMerge = "merge"
+	// ApprovalIteration holds the implement loop before each iteration after
+	// the first.
+	ApprovalIteration = "iteration"
+)
+
+// ApprovalPolicy controls the points where a run stops until an operator
+// approves it, through the TUI or the control socket.
+type ApprovalPolicy struct {
+	// Gates are the Approval* points that wait for approval. None by default.
+	Gates []string
+}
+
+// Has reports whether gate waits for approval.
+func (p ApprovalPolicy) Has(gate string) bool {
+	for _, g := range p.Gates {
+		if g == gate {
+			return true
+		}
+	}
+	return false
+}
+
+// Event verbosity levels used by EventsPolicy.Verbosity.
+const (
+	// VerbosityAll delivers every event of the type.
+	VerbosityAll = "all"
+	// VerbosityCoalesce drops repeats of the last delivered event and
+	// delivers at most one event per task within the coalesce window.
+	VerbosityCoalesce = "coalesce"
+	// VerbosityOff delivers no events of the type.
+	VerbosityOff = "off"
+)
+
+// EventsPolicy controls which orchestrator events reach subscribers such as
+// the TUI and headless log. The session event log always receives every event.
+type EventsPolicy struct {
+	// CoalesceWindow is the minimum interval between delivered events of a
+	// coalesced type for the same task.
+	CoalesceWindow time.Duration
+
+	// Verbosity maps event types (e.g. "agent_progress") to a Verbosity*
+	// level. Types not listed use VerbosityAll.
+	Verbosity map[string]string
 }
 
 // Default returns the default policy configuration.
@@ -88,6 +399,8 @@ func Default() *Config {
 				"root", "project structure", "initialize", "setup",
 				"monorepo", "workspaces",
 			},
+			PreemptPriority:   1,
+			ConflictThreshold: 0.7,
 		},
 		Collision: CollisionPolicy{
 			HotspotThreshold:     3,
@@ -106,7 +419,37 @@ func Default() *Config {
 			SpawnStagger: 2 * time.Second,
 		},
 		Merge: MergePolicy{
-			QueueBufferSize: 100,
+			QueueBufferSize:           100,
+			SemanticMaxConflictFiles:  8,
+			SemanticMaxConflictLines:  400,
+			OversizeConflictAction:    OversizeActionHuman,
+			ReviewConfidenceThreshold: 0.6,
+			SessionReview:             SessionReviewNone,
+		},
+		Budget: BudgetPolicy{
+			WarnRatio: 0.8,
+		},
+		Retry: RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     5 * time.Second,
+			MaxBackoff:  2 * time.Minute,
+			Jitter:      0.2,
+			RetryOn:     []string{FailureExecution, FailureVerification, FailureTimeout},
+		},
+		Heartbeat: HeartbeatPolicy{
+			StallTimeout: 10 * time.Minute,
+		},
+		Resources: ResourcePolicy{
+			MinFreeDiskMB:   2048,
+			MinFreeMemoryMB: 512,
+			CheckInterval:   30 * time.Second,
+		},
+		Events: EventsPolicy{
+			CoalesceWindow: 2 * time.Second,
+			Verbosity: map[string]string{
+				"agent_progress": VerbosityCoalesce,
+				"rate_limited":   VerbosityCoalesce,
+			},
 		},
 	}
 }
@@ -125,6 +468,26 @@ func (c *Config) Validate() error {
 	if c.Review.CrossCuttingThreshold < 1 {
 		c.Review.CrossCuttingThreshold = 3
 	}
+	reviewers := c.Review.Reviewers[:0]
+	for _, r := range c.Review.Reviewers {
+		if r.Name != "" {
+			reviewers = append(reviewers, r)
+		}
+	}
+	c.Review.Reviewers = reviewers
+	if c.Review.Quorum < 0 {
+		c.Review.Quorum = 0
+	}
+	rubric := c.Review.Rubric[:0]
+	for _, r := range c.Review.Rubric {
+		if r.Description == "" {
+			r.Description = rubricDescriptions[r.Name]
+		}
+		if r.Name != "" && r.Description != "" {
+			rubric = append(rubric, r)
+		}
+	}
+	c.Review.Rubric = rubric
 	if c.Override.BlockedAfterNAttempts < 1 {
 		c.Override.BlockedAfterNAttempts = 5
 	}
@@ -137,5 +500,62 @@ func (c *Config) Validate() error {
 	if c.Merge.QueueBufferSize < 1 {
 		c.Merge.QueueBufferSize = 100
 	}
+	if c.Merge.SemanticMaxConflictFiles < 0 {
+		c.Merge.SemanticMaxConflictFiles = 0
+	}
+	if c.Merge.SemanticMaxConflictLines < 0 {
+		c.Merge.SemanticMaxConflictLines = 0
+	}
+	if c.Merge.OversizeConflictAction != OversizeActionReexecute {
+		c.Merge.OversizeConflictAction = OversizeActionHuman
+	}
+	if c.Merge.ReviewConfidenceThreshold < 0 || c.Merge.ReviewConfidenceThreshold > 1 {
+		c.Merge.ReviewConfidenceThreshold = 0.6
+	}
+	if c.Merge.SessionReview != SessionReviewConfirm && c.Merge.SessionReview != SessionReviewAuto {
+		c.Merge.SessionReview = SessionReviewNone
+	}
+	if c.Budget.TaskLimit < 0 {
+		c.Budget.TaskLimit = 0
+	}
+	if c.Budget.SessionLimit < 0 {
+		c.Budget.SessionLimit = 0
+	}
+	if c.Budget.WarnRatio <= 0 || c.Budget.WarnRatio > 1 {
+		c.Budget.WarnRatio = 0.8
+	}
+	if c.Retry.MaxAttempts < 1 {
+		c.Retry.MaxAttempts = 3
+	}
+	if c.Retry.Backoff < 0 {
+		c.Retry.Backoff = 0
+	}
+	if c.Retry.MaxBackoff < c.Retry.Backoff {
+		c.Retry.MaxBackoff = c.Retry.Backoff
+	}
+	if c.Retry.Jitter < 0 {
+		c.Retry.Jitter = 0
+	}
+	if c.Retry.Jitter > 1 {
+		c.Retry.Jitter = 1
+	}
+	if c.Retry.RetryOn == nil {
+		c.Retry.RetryOn = []string{FailureExecution, FailureVerification, FailureTimeout}
+	}
+	gates := c.Approval.Gates[:0]
+	for _, g := range c.Approval.Gates {
+		if g == ApprovalDecomposition || g == ApprovalMerge || g == ApprovalIteration {
+			gates = append(gates, g)
+		}
+	}
+	c.Approval.Gates = gates
+	if c.Events.CoalesceWindow < 0 {
+		c.Events.CoalesceWindow = 0
+	}
+	for eventType, level := range c.Events.Verbosity {
+		if level != VerbosityCoalesce && level != VerbosityOff {
+			c.Events.Verbosity[eventType] = VerbosityAll
+		}
+	}
 	return nil
 }
@@ -3,14 +3,15 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/google/uuid"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/git"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/prog"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
@@ -21,6 +22,8 @@ type PoolConfig struct {
 	RepoPath       string
 	TierConfigs    *config.TierConfigs
 	Greenfield     bool
+	MainBranch     string // empty detects the repository's default branch
+	CommitIdentity *git.CommitIdentity
 	Executor       *agent.Executor
 	StateDB        state.StateStore
 	LearningSystem learning.LearningProvider
@@ -49,6 +52,9 @@ type OrchestratorPool struct {
 	wg sync.WaitGroup
 }
 
+// poolLog is the logger of the pool's records.
+var poolLog = logging.Logger("pool")
+
 // NewOrchestratorPool creates a new OrchestratorPool.
 func NewOrchestratorPool(cfg PoolConfig) *OrchestratorPool {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -92,9 +98,13 @@ func (p *OrchestratorPool) SubmitWithID(task string, tier models.Tier, originalT
 		},
 		WithTierConfigs(p.cfg.TierConfigs),
 		WithGreenfield(p.cfg.Greenfield),
+		WithMainBranch(p.cfg.MainBranch),
+		WithCommitIdentity(p.cfg.CommitIdentity),
 		WithDecomposerClaude(decomposerClaude),
 		WithMergerClaude(mergerClaude),
 		WithRunnerFactory(p.cfg.RunnerFactory),
+		WithRateLimiter(agent.RateLimiterOf(p.cfg.RunnerFactory)),
+		WithUsageMeter(agent.UsageMeterOf(p.cfg.RunnerFactory)),
 		WithStateDB(p.cfg.StateDB),
 		WithLearningSystem(p.cfg.LearningSystem),
 		WithProgClient(p.cfg.ProgClient),
@@ -114,7 +124,7 @@ func (p *OrchestratorPool) SubmitWithID(task string, tier models.Tier, originalT
 		defer p.wg.Done()
 
 		if err := orch.Run(p.ctx, task); err != nil {
-			log.Printf("[pool] orchestrator %s failed: %v", orchID, err)
+			poolLog.Warn("orchestrator failed", "orchestrator", orchID, logging.Err(err))
 		}
 
 		p.mu.Lock()
@@ -0,0 +1,131 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// suspendTimeout bounds how long stopAgent waits for a suspended agent to
+// checkpoint its work before requeueing the task.
+const suspendTimeout = 30 * time.Second
+
+// preemptForUrgentTasks stops running agents whose slots are needed by more
+// urgent ready tasks, as chosen by Scheduler.Preemptions. Each preempted task
+// is requeued without counting as a failed attempt and resumes from the work
+// checkpointed on its branch when it is scheduled again. Returns the number
+// of agents preempted.
+func (o *Orchestrator) preemptForUrgentTasks(inflightTasks map[string]*inflight, inflightMu *sync.Mutex) int {
+	preempted := 0
+	for _, p := range o.scheduler.Preemptions() {
+		inflightMu.Lock()
+		inf, ok := inflightTasks[p.TaskID]
+		if ok && inf.agentID == p.AgentID {
+			delete(inflightTasks, p.TaskID)
+		} else {
+			ok = false
+		}
+		inflightMu.Unlock()
+		if !ok {
+			continue
+		}
+
+		task := o.stopAgent(inf, true)
+		if task == nil {
+			continue
+		}
+
+		urgentTitle := p.ForTaskID
+		if urgent := o.graph.GetTask(p.ForTaskID); urgent != nil {
+			urgentTitle = urgent.Title
+		}
+		o.log.Info("preempted agent", logging.Agent(p.AgentID), logging.Task(p.TaskID), "priority", task.EffectivePriority(), "for_task", p.ForTaskID)
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventTaskPreempted,
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ParentID:  task.ParentID,
+			AgentID:   p.AgentID,
+			Message:   fmt.Sprintf("Task preempted for higher-priority task: %s", urgentTitle),
+			Timestamp: time.Now(),
+		})
+		preempted++
+	}
+	return preempted
+}
+
+// stopAgent cancels the agent of an in-flight task the caller has already
+// removed from the in-flight set, and returns the task (nil if it is no
+// longer in the graph). A requeued task goes back to pending and its agent
+// frees its slot without counting as a failure, keeping the work it has done
+// so far on the task branch; otherwise the agent is
+// recorded as failed and the caller settles the task.
+func (o *Orchestrator) stopAgent(inf *inflight, requeue bool) *models.Task {
+	// Its result, if it still arrives, no longer matches an in-flight
+	// task and is ignored by the run loop. A suspended agent's work is
+	// committed to the task branch, where the next attempt picks it up.
+	var cause error
+	if requeue {
+		cause = agent.ErrSuspended
+	}
+	inf.cancelFn(cause)
+	if requeue {
+		o.awaitSuspended(inf)
+	}
+	o.collision.UnregisterAgent(inf.agentID)
+	if requeue {
+		o.scheduler.OnAgentPreempted(inf.agentID)
+		o.updateAgentState(inf.agentID, string(models.AgentStatusPaused))
+	} else {
+		o.scheduler.OnAgentComplete(inf.agentID, false)
+		o.updateAgentState(inf.agentID, string(models.AgentStatusFailed))
+	}
+
+	task := o.graph.GetTask(inf.taskID)
+	if task != nil && requeue {
+		task.Status = models.TaskStatusPending
+		task.AssignedTo = ""
+		o.updateTaskState(task)
+	}
+	return task
+}
+
+// awaitSuspended waits up to suspendTimeout for a suspended agent to exit,
+// so its checkpoint is on the task branch before the task can be scheduled
+// again.
+func (o *Orchestrator) awaitSuspended(inf *inflight) {
+	if inf.doneCh == nil {
+		return
+	}
+	select {
+	case <-inf.doneCh:
+	case <-time.After(suspendTimeout):
+		o.log.Warn("suspended agent did not exit in time; its task may restart without its checkpoint", logging.Agent(inf.agentID), logging.Task(inf.taskID), "timeout", suspendTimeout)
+	}
+}
+
+// failStoppedTask settles a task whose agent stopAgent stopped without
+// requeueing as failed, with message as the reason. err, if not nil, is
+// reported with the failure event.
+func (o *Orchestrator) failStoppedTask(task *models.Task, agentID, message string, err error) {
+	task.Status = models.TaskStatusFailed
+	task.AssignedTo = ""
+	task.Error = message
+	o.updateTaskState(task)
+	o.progCoord.BlockTask(task.ID, message)
+	o.emitEvent(OrchestratorEvent{
+		Type:      EventTaskFailed,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		ParentID:  task.ParentID,
+		AgentID:   agentID,
+		Message:   fmt.Sprintf("%s: %s", message, task.Title),
+		Error:     err,
+		Timestamp: time.Now(),
+	})
+}
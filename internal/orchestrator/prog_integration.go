@@ -4,12 +4,16 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -28,7 +32,7 @@ var progOperationConfig = struct {
 
 // retryProgOperation executes a prog operation with exponential backoff retry.
 // Operations run asynchronously to avoid blocking on database locks.
-func retryProgOperation(opName string, op func() error) {
+func retryProgOperation(log *slog.Logger, opName string, op func() error) {
 	go func() {
 		delay := progOperationConfig.InitialDelay
 		var lastErr error
@@ -44,21 +48,21 @@ func retryProgOperation(opName string, op func() error) {
 
 			if err := op(); err != nil {
 				lastErr = err
-				log.Printf("[orchestrator] %s attempt %d/%d failed: %v",
-					opName, attempt+1, progOperationConfig.MaxRetries+1, err)
+				log.Debug("prog operation failed",
+					"op", opName, "attempt", attempt+1, "attempts", progOperationConfig.MaxRetries+1, logging.Err(err))
 				continue
 			}
 
 			// Success
 			if attempt > 0 {
-				log.Printf("[orchestrator] %s succeeded after %d retries", opName, attempt)
+				log.Debug("prog operation succeeded after retries", "op", opName, "retries", attempt)
 			}
 			return
 		}
 
 		// All retries exhausted
-		log.Printf("[orchestrator] ERROR: %s failed after %d attempts, last error: %v",
-			opName, progOperationConfig.MaxRetries+1, lastErr)
+		log.Error("prog operation failed",
+			"op", opName, "attempts", progOperationConfig.MaxRetries+1, logging.Err(lastErr))
 	}()
 }
 
@@ -77,8 +81,22 @@ type ProgCoordinator struct {
 	originalTaskID string
 	// tier is the agent tier for task creation.
 	tier models.Tier
+	// links persists taskIDs across sessions, if configured.
+	links ProgLinkStore
+	log   *slog.Logger
 }
 
+// ProgLinkStore is the state DB side of an epic: the prog task behind each
+// internal task ID, and the internal tasks with their dependencies.
+// *state.DB implements it.
+type ProgLinkStore interface {
+	state.ProgLinkStore
+	GetTask(id string) (*state.Task, error)
+}
+
+// Verify state.DB implements ProgLinkStore at compile time.
+var _ ProgLinkStore = (*state.DB)(nil)
+
 // NewProgCoordinator creates a new ProgCoordinator.
 // If client is nil, returns a no-op coordinator that skips all operations.
 func NewProgCoordinator(client prog.ProgTracker, emitter *EventEmitter, originalTaskID string, tier models.Tier, resumeEpicID string) *ProgCoordinator {
@@ -89,6 +107,29 @@ func NewProgCoordinator(client prog.ProgTracker, emitter *EventEmitter, original
 		emitter:        emitter,
 		originalTaskID: originalTaskID,
 		tier:           tier,
+		log:            logging.Logger("prog"),
+	}
+}
+
+// SetLogger sets the logger of the coordinator's records.
+func (p *ProgCoordinator) SetLogger(l *slog.Logger) {
+	p.log = l
+}
+
+// SetLinkStore persists the mapping of internal to prog task IDs in store,
+// so resuming the epic in a later session restores the same task IDs and
+// the dependencies recorded for them.
+func (p *ProgCoordinator) SetLinkStore(store ProgLinkStore) {
+	p.links = store
+}
+
+// saveLinks persists the mapping of internal to prog task IDs.
+func (p *ProgCoordinator) saveLinks() {
+	if p.links == nil || p.epicID == "" || len(p.taskIDs) == 0 {
+		return
+	}
+	if err := p.links.SaveProgLinks(p.epicID, p.taskIDs); err != nil {
+		p.log.Warn("failed to save prog task links", "epic", p.epicID, logging.Err(err))
 	}
 }
 
@@ -126,15 +167,24 @@ func (p *ProgCoordinator) CreateEpicAndTasks(request string, tasks []*models.Tas
 		epicTitle = epicTitle[:97] + "..."
 	}
 
-	// Create the epic
-	epicID, err := p.client.CreateEpic(epicTitle, &prog.EpicOptions{
-		Description: request,
-	})
+	// Finish an epic whose plan was interrupted instead of creating a duplicate
+	var epicID string
+	unfinished, err := FindUnfinishedEpic(p.client, epicTitle)
 	if err != nil {
-		return fmt.Errorf("create epic: %w", err)
+		p.log.Warn("failed to check for unfinished epics", logging.Err(err))
+	}
+	if unfinished != nil {
+		epicID = unfinished.ID
+		p.log.Info("resuming partially written prog epic", "epic", epicID)
+	} else {
+		epicID, err = StartEpicPlan(p.client, epicTitle, &prog.EpicOptions{
+			Description: request,
+		})
+		if err != nil {
+			return err
+		}
+		p.log.Info("created prog epic", "epic", epicID)
 	}
-
-	log.Printf("[orchestrator] created prog epic %s for request", epicID)
 
 	// Store epic ID for later reference
 	p.epicID = epicID
@@ -149,47 +199,29 @@ func (p *ProgCoordinator) CreateEpicAndTasks(request string, tasks []*models.Tas
 		OriginalTaskID: p.originalTaskID,
 	})
 
-	// Map internal task IDs to prog task IDs for dependency resolution
-	internalToProgID := make(map[string]string)
-
-	// First pass: create all tasks (without dependencies)
+	planned := make([]PlannedTask, 0, len(tasks))
 	for _, task := range tasks {
 		// Set ParentID on the internal task for event propagation
 		task.ParentID = epicID
 
-		progTaskID, err := p.client.CreateTask(task.Title, &prog.TaskOptions{
+		planned = append(planned, PlannedTask{
+			Key:         task.ID,
+			Title:       task.Title,
 			Description: task.Description,
-			ParentID:    epicID,
+			DependsOn:   task.DependsOn,
 		})
-		if err != nil {
-			return fmt.Errorf("create task %q: %w", task.Title, err)
-		}
-		internalToProgID[task.ID] = progTaskID
 	}
 
-	// Second pass: add dependencies
-	for _, task := range tasks {
-		if len(task.DependsOn) == 0 {
-			continue
-		}
-
-		progTaskID := internalToProgID[task.ID]
-		for _, depID := range task.DependsOn {
-			progDepID, ok := internalToProgID[depID]
-			if !ok {
-				log.Printf("[orchestrator] warning: prog dependency %s not found for task %s", depID, task.ID)
-				continue
-			}
-			if err := p.client.AddDependency(progTaskID, progDepID); err != nil {
-				return fmt.Errorf("add dependency %s -> %s: %w", progTaskID, progDepID, err)
-			}
-		}
-	}
-
-	// Store the mapping for later status updates
+	// Map internal task IDs to prog task IDs; keep the mapping for later
+	// status updates even if writing the plan stopped part way
+	internalToProgID, err := WriteEpicPlan(p.client, epicID, "", planned)
 	p.taskIDs = internalToProgID
+	p.saveLinks()
+	if err != nil {
+		return err
+	}
 
-	log.Printf("[orchestrator] created %d prog tasks under epic %s", len(tasks), epicID)
+	p.log.Info("created prog tasks", "epic", epicID, "tasks", len(tasks))
 	return nil
 }
 
@@ -202,6 +234,17 @@ func (p *ProgCoordinator) TaskID(internalID string) string {
 	return p.taskIDs[internalID]
 }
 
+// InternalTaskID returns the internal task ID mapped to a prog task ID.
+// Returns empty string if no mapping exists or prog is not configured.
+func (p *ProgCoordinator) InternalTaskID(progID string) string {
+	for internalID, id := range p.taskIDs {
+		if id == progID {
+			return internalID
+		}
+	}
+	return ""
+}
+
 // StartTask marks a prog task as in_progress and logs the start event.
 func (p *ProgCoordinator) StartTask(internalID string) {
 	if p.client == nil {
@@ -213,7 +256,7 @@ func (p *ProgCoordinator) StartTask(internalID string) {
 	}
 
 	// Start task with retry logic
-	retryProgOperation(fmt.Sprintf("start task %s", progID), func() error {
+	retryProgOperation(p.log, fmt.Sprintf("start task %s", progID), func() error {
 		if err := p.client.Start(progID); err != nil {
 			return err
 		}
@@ -232,7 +275,7 @@ func (p *ProgCoordinator) LogTask(internalID, message string) {
 	}
 
 	// Log with retry logic
-	retryProgOperation(fmt.Sprintf("log task %s", progID), func() error {
+	retryProgOperation(p.log, fmt.Sprintf("log task %s", progID), func() error {
 		return p.client.AddLog(progID, message)
 	})
 }
@@ -248,7 +291,7 @@ func (p *ProgCoordinator) CompleteTask(internalID string) {
 	}
 
 	// Complete with retry logic
-	retryProgOperation(fmt.Sprintf("complete task %s", progID), func() error {
+	retryProgOperation(p.log, fmt.Sprintf("complete task %s", progID), func() error {
 		if err := p.client.AddLog(progID, "Task completed successfully"); err != nil {
 			return err
 		}
@@ -267,7 +310,7 @@ func (p *ProgCoordinator) BlockTask(internalID, reason string) {
 	}
 
 	// Block with retry logic
-	retryProgOperation(fmt.Sprintf("block task %s", progID), func() error {
+	retryProgOperation(p.log, fmt.Sprintf("block task %s", progID), func() error {
 		if err := p.client.AddLog(progID, fmt.Sprintf("Task failed: %s", reason)); err != nil {
 			return err
 		}
@@ -277,7 +320,9 @@ func (p *ProgCoordinator) BlockTask(internalID, reason string) {
 
 // LoadTasksFromEpic loads tasks from an existing prog epic for resumption.
 // Completed tasks are loaded with status Done so they will be skipped.
-// In-progress tasks are reset to Pending for re-execution.
+// In-progress tasks are reset to Pending for re-execution. Tasks keep the
+// internal IDs earlier sessions gave them when a link store is set, and
+// their dependencies are rebuilt from both prog and the state DB.
 func (p *ProgCoordinator) LoadTasksFromEpic(ctx context.Context) ([]*models.Task, error) {
 	if p.client == nil {
 		return nil, fmt.Errorf("prog client not configured")
@@ -292,7 +337,7 @@ func (p *ProgCoordinator) LoadTasksFromEpic(ctx context.Context) ([]*models.Task
 	// Mark epic as in-progress if it's open
 	if epic.Status == prog.StatusOpen {
 		if err := p.client.Start(p.epicID); err != nil {
-			log.Printf("[orchestrator] warning: failed to mark epic as in-progress: %v", err)
+			p.log.Warn("failed to mark epic as in progress", "epic", p.epicID, logging.Err(err))
 		}
 	}
 
@@ -306,11 +351,27 @@ func (p *ProgCoordinator) LoadTasksFromEpic(ctx context.Context) ([]*models.Task
 		return nil, fmt.Errorf("epic %s has no tasks", p.epicID)
 	}
 
+	// Internal task IDs given to the epic's tasks by earlier sessions
+	linked := make(map[string]string)
+	if p.links != nil {
+		saved, err := p.links.GetProgLinks(p.epicID)
+		if err != nil {
+			p.log.Warn("failed to load prog task links", "epic", p.epicID, logging.Err(err))
+		}
+		for internalID, progID := range saved {
+			linked[progID] = internalID
+		}
+	}
+
 	// Convert prog tasks to internal tasks
 	tasks := make([]*models.Task, 0, len(progTasks))
+	progToInternalID := make(map[string]string, len(progTasks))
 	for _, pt := range progTasks {
 		// Map prog task ID to internal task ID for status sync
-		internalID := uuid.New().String()[:8]
+		internalID := linked[pt.ID]
+		if internalID == "" {
+			internalID = uuid.New().String()[:8]
+		}
 		p.taskIDs[internalID] = pt.ID
 
 		// Convert status
@@ -327,33 +388,78 @@ func (p *ProgCoordinator) LoadTasksFromEpic(ctx context.Context) ([]*models.Task
 		}
 
 		task := &models.Task{
-			ID:          internalID,
-			Title:       pt.Title,
-			Description: pt.Description,
-			Status:      status,
-			Tier:        p.tier,
-			CreatedAt:   pt.CreatedAt,
+			ID:             internalID,
+			Title:          pt.Title,
+			Description:    pt.Description,
+			Status:         status,
+			Tier:           p.tier,
+			FileBoundaries: ParseFileBoundaries(pt.Description),
+			Priority:       pt.Priority,
+			CreatedAt:      pt.CreatedAt,
 		}
 		// Set ParentID if the prog task has one
 		if pt.ParentID != nil {
 			task.ParentID = *pt.ParentID
 		}
 
+		progToInternalID[pt.ID] = internalID
 		tasks = append(tasks, task)
 	}
+	p.saveLinks()
 
-	// Note: Dependencies from prog are not loaded here.
-	// When resuming, tasks that were in-progress may have had their
-	// dependencies already completed, so we execute them independently.
-	// For more sophisticated dependency handling, we would need to map
-	// prog dep IDs to internal task IDs.
+	loaded := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		loaded[task.ID] = true
+	}
 
-	log.Printf("[orchestrator] loaded %d tasks from epic (skipped canceled, %d already done)",
-		len(tasks), countDoneTasks(tasks))
+	// Map prog dependencies to internal task IDs, and add the ones the state
+	// DB recorded for the task in an earlier session but prog lacks (e.g. the
+	// plan was interrupted before they were written). Dependencies on tasks
+	// outside the epic or canceled tasks are dropped; dependencies on done
+	// tasks are kept and already satisfied.
+	restored := 0
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusDone {
+			continue
+		}
+		depIDs, err := p.client.GetDependencies(p.taskIDs[task.ID])
+		if err != nil {
+			p.log.Warn("failed to load task dependencies", logging.Task(task.ID), logging.Err(err))
+		}
+		for _, depID := range depIDs {
+			if internalDep, ok := progToInternalID[depID]; ok {
+				task.DependsOn = appendUnique(task.DependsOn, internalDep)
+			}
+		}
+		if p.links == nil {
+			continue
+		}
+		saved, err := p.links.GetTask(task.ID)
+		if err != nil || saved == nil {
+			continue
+		}
+		for _, dep := range saved.DependsOn {
+			if loaded[dep] && dep != task.ID && !slices.Contains(task.DependsOn, dep) {
+				task.DependsOn = append(task.DependsOn, dep)
+				restored++
+			}
+		}
+	}
+
+	p.log.Info("loaded tasks from epic, skipping canceled ones",
+		"epic", p.epicID, "tasks", len(tasks), "done", countDoneTasks(tasks), "dependencies_restored", restored)
 
 	return tasks, nil
 }
 
+// appendUnique appends id to ids unless it is already there.
+func appendUnique(ids []string, id string) []string {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
 // countDoneTasks counts tasks with Done status.
 func countDoneTasks(tasks []*models.Task) int {
 	count := 0
@@ -364,3 +470,36 @@ func countDoneTasks(tasks []*models.Task) int {
 	}
 	return count
 }
+
+// fileBoundariesPrefix starts the line of a prog task description that
+// lists the task's file boundaries, so they survive the round trip through prog.
+const fileBoundariesPrefix = "**Files:** "
+
+// FormatFileBoundaries returns the description line recording a task's file
+// boundaries, or "" if there are none. Tasks loaded from an epic get their
+// FileBoundaries back from this line.
+func FormatFileBoundaries(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	return fileBoundariesPrefix + strings.Join(files, ", ") + "\n"
+}
+
+// ParseFileBoundaries extracts the file boundaries written by
+// FormatFileBoundaries from a task description.
+func ParseFileBoundaries(description string) []string {
+	for _, line := range strings.Split(description, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), strings.TrimSpace(fileBoundariesPrefix))
+		if !ok {
+			continue
+		}
+		var files []string
+		for _, f := range strings.Split(rest, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				files = append(files, f)
+			}
+		}
+		return files
+	}
+	return nil
+}
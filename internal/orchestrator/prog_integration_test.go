@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestLoadTasksFromEpicRestoresDependencies(t *testing.T) {
+	client := newTestProgClient(t)
+
+	epicID, err := client.CreateEpic("Gaps", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, _ := client.CreateTask("Implement db", &prog.TaskOptions{ParentID: epicID})
+	api, _ := client.CreateTask("Implement api", &prog.TaskOptions{
+		ParentID:    epicID,
+		DependsOn:   []string{schema},
+		Description: "**Status:** MISSING\n\n" + FormatFileBoundaries([]string{"internal/api/", "go.mod"}),
+	})
+	outside, _ := client.CreateTask("Unrelated", nil)
+	if err := client.AddDependency(api, outside); err != nil {
+		t.Fatal(err)
+	}
+
+	coord := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, epicID)
+	tasks, err := coord.LoadTasksFromEpic(context.Background())
+	if err != nil {
+		t.Fatalf("LoadTasksFromEpic: %v", err)
+	}
+	byTitle := make(map[string]*models.Task)
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+
+	apiTask, schemaTask := byTitle["Implement api"], byTitle["Implement db"]
+	if apiTask == nil || schemaTask == nil {
+		t.Fatalf("tasks = %v", tasks)
+	}
+	if len(apiTask.DependsOn) != 1 || apiTask.DependsOn[0] != schemaTask.ID {
+		t.Errorf("api depends on %v, want [%s] (dependency outside the epic dropped)", apiTask.DependsOn, schemaTask.ID)
+	}
+	if len(apiTask.FileBoundaries) != 2 || apiTask.FileBoundaries[0] != "internal/api/" || apiTask.FileBoundaries[1] != "go.mod" {
+		t.Errorf("api file boundaries = %v", apiTask.FileBoundaries)
+	}
+	if len(schemaTask.DependsOn) != 0 || len(schemaTask.FileBoundaries) != 0 {
+		t.Errorf("db task = %+v", schemaTask)
+	}
+}
+
+func TestLoadTasksFromEpicReusesLinkedTaskIDs(t *testing.T) {
+	client := newTestProgClient(t)
+	db := openEscalationDB(t)
+
+	// First session: plan two tasks where "api" depends on "db"
+	first := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, "")
+	first.SetLinkStore(db)
+	planned := []*models.Task{
+		{ID: "task-db", Title: "Implement db"},
+		{ID: "task-api", Title: "Implement api", DependsOn: []string{"task-db"}},
+		{ID: "task-docs", Title: "Write docs"},
+	}
+	if err := first.CreateEpicAndTasks("Build the service", planned); err != nil {
+		t.Fatalf("CreateEpicAndTasks: %v", err)
+	}
+	for _, task := range planned {
+		if err := db.CreateTask(&state.Task{ID: task.ID, Title: task.Title, DependsOn: task.DependsOn, Status: state.TaskPending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The state DB recorded a dependency that never made it into prog
+	docs, _ := db.GetTask("task-docs")
+	docs.DependsOn = []string{"task-api"}
+	if err := db.UpdateTask(docs); err != nil {
+		t.Fatal(err)
+	}
+	epicID := first.EpicID()
+
+	// Second session resumes the epic
+	second := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, epicID)
+	second.SetLinkStore(db)
+	tasks, err := second.LoadTasksFromEpic(context.Background())
+	if err != nil {
+		t.Fatalf("LoadTasksFromEpic: %v", err)
+	}
+
+	deps := make(map[string][]string)
+	for _, task := range tasks {
+		deps[task.ID] = task.DependsOn
+	}
+	want := map[string][]string{
+		"task-db":   nil,
+		"task-api":  {"task-db"},
+		"task-docs": {"task-api"},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("resumed dependencies = %v, want %v", deps, want)
+	}
+	if second.TaskID("task-api") != first.TaskID("task-api") {
+		t.Errorf("task-api maps to %s, want %s", second.TaskID("task-api"), first.TaskID("task-api"))
+	}
+}
@@ -0,0 +1,209 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// checkSchedulingPolicy applies the protected-area policy to a task's file
+// boundaries before it is scheduled. Tasks that would touch a blocked path
+// are failed without running. Returns false if the task must not run.
+func (o *Orchestrator) checkSchedulingPolicy(task *models.Task) bool {
+	matches := o.protectedPolicy.Evaluate(task.FileBoundaries)
+	switch action := protect.Strictest(matches); action {
+	case "":
+		return true
+	case protect.ActionBlock:
+		reason := "Protected-area policy blocks " + protect.Describe(matches, action)
+		o.log.Info("not running task", logging.Task(task.ID), "reason", reason)
+
+		task.Status = models.TaskStatusFailed
+		task.Error = reason
+		o.updateTaskState(task)
+		o.progCoord.BlockTask(task.ID, reason)
+		o.recordDecision(Decision{
+			Kind:   DecisionRejection,
+			Actor:  ActorAlphie,
+			TaskID: task.ID,
+			Reason: reason,
+		})
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventTaskFailed,
+			TaskID:    task.ID,
+			TaskTitle: task.Title,
+			ParentID:  task.ParentID,
+			Message:   fmt.Sprintf("Task blocked: %s", task.Title),
+			Error:     fmt.Errorf("%w: %s", ErrProtectedAreaBlocked, reason),
+			Timestamp: time.Now(),
+		})
+		return false
+	default:
+		// The merge path enforces the action once the changed files are known
+		o.log.Info("task touches protected areas",
+			logging.Task(task.ID), "merge_requires", action, "areas", protect.Describe(matches, action))
+		return true
+	}
+}
+
+// SetProtectedPolicy enforces the protected-area policy on merges. Merges
+// touching a path whose rule requires a second review are reviewed by
+// reviewer; without one they are held for human approval instead.
+func (e *MergeProcessor) SetProtectedPolicy(p *protect.Policy, reviewer *SecondReviewer) {
+	e.protectedPolicy = p
+	e.secondReviewer = reviewer
+}
+
+// enforceProtectedPolicy applies the protected-area policy to the merge
+// commit at HEAD of the target branch. Returns false if the merge may stay.
+// Otherwise the merge has been removed from the target branch and the
+// outcome says why; the agent branch is kept either way. Confidence is
+// recorded on the review if the merge is held for human approval.
+func (e *MergeProcessor) enforceProtectedPolicy(ctx context.Context, req *MergeRequest, confidence float64) (MergeOutcome, bool) {
+	if e.protectedPolicy == nil {
+		return MergeOutcome{}, false
+	}
+
+	g := e.gitRunner()
+	files, err := g.ChangedFilesBetween("HEAD^", "HEAD")
+	if err != nil {
+		// Fail closed: a merge that cannot be checked does not land
+		return e.rejectProtectedMerge(req, ActorAlphie, fmt.Sprintf("could not list merged files for the protected-area policy: %v", err)), true
+	}
+
+	matches := e.protectedPolicy.Evaluate(files)
+	switch action := protect.Strictest(matches); action {
+	case protect.ActionBlock:
+		return e.rejectProtectedMerge(req, ActorAlphie, "protected-area policy blocks "+protect.Describe(matches, action)), true
+	case protect.ActionHumanApproval:
+		return e.holdForApproval(req, matches, confidence, "protected-area policy requires human approval for "+protect.Describe(matches, action))
+	case protect.ActionSecondReview:
+		return e.reviewProtectedMerge(ctx, req, matches, confidence)
+	}
+	return MergeOutcome{}, false
+}
+
+// reviewProtectedMerge has the second reviewer review a merge the policy
+// requires one for. A merge that cannot be reviewed is held for human
+// approval instead.
+func (e *MergeProcessor) reviewProtectedMerge(ctx context.Context, req *MergeRequest, matches []protect.Match, confidence float64) (MergeOutcome, bool) {
+	described := protect.Describe(matches, protect.ActionSecondReview)
+	if e.secondReviewer == nil {
+		return e.holdForApproval(req, matches, confidence,
+			"protected-area policy requires a second review, but no reviewer is configured: "+described)
+	}
+
+	diff, err := e.gitRunner().DiffBetween("HEAD^", "HEAD")
+	if err != nil {
+		return e.holdForApproval(req, matches, confidence, fmt.Sprintf("could not diff merge for second review (%v): %s", err, described))
+	}
+	description := req.TaskID
+	var task *models.Task
+	if e.orchestrator != nil && e.orchestrator.graph != nil {
+		if task = e.orchestrator.graph.GetTask(req.TaskID); task != nil {
+			description = task.Description
+		}
+	}
+
+	// Reviewers for protected areas vote, plus any the diff triggers itself
+	triggers := []string{policy.TriggerProtectedArea}
+	files := make([]string, 0, len(matches))
+	for _, m := range matches {
+		files = append(files, m.Path)
+	}
+	if task != nil {
+		triggers = append(triggers, e.secondReviewer.ShouldSecondReview(diff, files, task).Types...)
+	}
+
+	result, err := e.secondReviewer.ReviewWithPanel(ctx, diff, description, triggers)
+	if err != nil {
+		return e.holdForApproval(req, matches, confidence, fmt.Sprintf("second review failed (%v): %s", err, described))
+	}
+	if e.orchestrator != nil {
+		e.orchestrator.persistReviewFindings(req.TaskID, result.Findings())
+	}
+	if !result.Approved {
+		return e.rejectProtectedMerge(req, ActorSecondReviewer, fmt.Sprintf("second review rejected protected-area change (%s; %d/%d approvals, quorum %d): %s",
+			described, result.Approvals, len(result.Votes), result.Quorum, strings.Join(result.Concerns(), "; "))), true
+	}
+
+	e.recordDecision(Decision{
+		Kind:   DecisionApproval,
+		Actor:  ActorSecondReviewer,
+		TaskID: req.TaskID,
+		Reason: fmt.Sprintf("Protected-area change approved by %d/%d reviewers: %s", result.Approvals, len(result.Votes), described),
+	})
+	return MergeOutcome{}, false
+}
+
+// holdForApproval quarantines a merge until a human approves it. Without a
+// review queue the merge cannot be held, so it is rejected.
+func (e *MergeProcessor) holdForApproval(req *MergeRequest, matches []protect.Match, confidence float64, reason string) (MergeOutcome, bool) {
+	if e.reviews == nil {
+		return e.rejectProtectedMerge(req, ActorAlphie, reason+" (no review queue to hold it in)"), true
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, m := range matches {
+		files = append(files, m.Path)
+	}
+	review, err := holdMerge(e.gitRunner(), e.reviews, &state.MergeReview{
+		ID:               uuid.New().String()[:8],
+		SessionID:        e.sessionID,
+		TaskID:           req.TaskID,
+		AgentBranch:      req.AgentBranch,
+		TargetBranch:     e.targetBranch(),
+		QuarantineBranch: QuarantineBranchName(req.TaskID),
+		Confidence:       confidence,
+		Reason:           reason,
+		Files:            files,
+	})
+	if err != nil {
+		return e.rejectProtectedMerge(req, ActorAlphie, fmt.Sprintf("%s (could not quarantine: %v)", reason, err)), true
+	}
+
+	reason = fmt.Sprintf("%s, held on %s for review (alphie merges approve %s)", reason, review.QuarantineBranch, review.ID)
+	return MergeOutcome{
+		Success: false,
+		Error:   fmt.Errorf("%w: %s", ErrMergeQuarantined, reason),
+		Reason:  reason,
+		Review:  review,
+	}, true
+}
+
+// rejectProtectedMerge removes the merge commit from the target branch and
+// records actor's rejection.
+func (e *MergeProcessor) rejectProtectedMerge(req *MergeRequest, actor, reason string) MergeOutcome {
+	if _, err := e.gitRunner().Run("reset", "--hard", "HEAD^"); err != nil {
+		e.log.Warn("could not remove blocked merge", logging.Task(req.TaskID), logging.Err(err))
+	}
+	e.log.Info("rejected protected merge", logging.Task(req.TaskID), "reason", reason)
+	e.recordDecision(Decision{
+		Kind:   DecisionRejection,
+		Actor:  actor,
+		TaskID: req.TaskID,
+		Reason: "Merge " + reason,
+	})
+	return MergeOutcome{
+		Success: false,
+		Error:   fmt.Errorf("%w: %s", ErrProtectedAreaBlocked, reason),
+		Reason:  reason,
+	}
+}
+
+// recordDecision records a merge decision in the session's audit trail.
+func (e *MergeProcessor) recordDecision(d Decision) {
+	if e.orchestrator != nil {
+		e.orchestrator.recordDecision(d)
+	}
+}
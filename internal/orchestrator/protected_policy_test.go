@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/merge"
+	"github.com/ShayCichocki/alphie/internal/protect"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// setupProtectedMerge creates a repository with an agent branch that adds
+// path, and a merge processor targeting the current branch.
+func setupProtectedMerge(t *testing.T, path string) (*MergeProcessor, *MergeRequest, string) {
+	t.Helper()
+	repo := t.TempDir()
+	if err := initGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	target, err := git.NewRunner(repo).CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitOutput(t, repo, "checkout", "-q", "-b", "agent-task-1")
+	if err := os.MkdirAll(filepath.Join(repo, filepath.Dir(path)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, path), []byte("change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, repo, "add", ".")
+	gitOutput(t, repo, "commit", "-q", "-m", "Agent work")
+	gitOutput(t, repo, "checkout", "-q", target)
+
+	processor := NewMergeProcessor(merge.NewHandler(target, repo), nil, nil, DefaultMergeProcessorConfig(), target, false, nil, repo)
+	return processor, &MergeRequest{TaskID: "task-1", AgentBranch: "agent-task-1"}, repo
+}
+
+// protectedPolicy returns a policy with one rule applying action to pattern.
+func protectedPolicy(pattern string, action protect.Action) *protect.Policy {
+	return &protect.Policy{Rules: []protect.Rule{{Pattern: pattern, Severity: protect.SeverityHigh, Action: action}}}
+}
+
+func TestMergeProcessor_ProtectedPolicyBlocks(t *testing.T) {
+	processor, req, repo := setupProtectedMerge(t, "migrations/001.sql")
+	base := gitOutput(t, repo, "rev-parse", "HEAD")
+	processor.SetProtectedPolicy(protectedPolicy("migrations/**", protect.ActionBlock), nil)
+
+	outcome := processor.Execute(t.Context(), req)
+	if outcome.Success || !errors.Is(outcome.Error, ErrProtectedAreaBlocked) {
+		t.Fatalf("outcome = %+v, want blocked", outcome)
+	}
+	if len(outcome.ConflictFiles) != 0 {
+		t.Errorf("blocked merge must not be handed to the fallback, got conflict files %v", outcome.ConflictFiles)
+	}
+	if head := gitOutput(t, repo, "rev-parse", "HEAD"); head != base {
+		t.Errorf("target HEAD = %s, want blocked merge removed (%s)", head, base)
+	}
+	if exists, _ := git.NewRunner(repo).BranchExists(req.AgentBranch); !exists {
+		t.Error("expected the agent branch to be kept")
+	}
+}
+
+func TestMergeProcessor_ProtectedPolicyHoldsForApproval(t *testing.T) {
+	processor, req, repo := setupProtectedMerge(t, "internal/billing/invoice.go")
+	base := gitOutput(t, repo, "rev-parse", "HEAD")
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	processor.SetReviewQueue(db, "sess1")
+	processor.SetProtectedPolicy(protectedPolicy("internal/billing/**", protect.ActionHumanApproval), nil)
+
+	outcome := processor.Execute(t.Context(), req)
+	if outcome.Review == nil || !errors.Is(outcome.Error, ErrMergeQuarantined) {
+		t.Fatalf("outcome = %+v, want quarantined", outcome)
+	}
+	if outcome.Review.Confidence != 1 || len(outcome.Review.Files) != 1 || outcome.Review.Files[0] != "internal/billing/invoice.go" {
+		t.Errorf("review = %+v", outcome.Review)
+	}
+	if head := gitOutput(t, repo, "rev-parse", "HEAD"); head != base {
+		t.Errorf("target HEAD = %s, want merge held off the branch (%s)", head, base)
+	}
+	if exists, _ := git.NewRunner(repo).BranchExists(outcome.Review.QuarantineBranch); !exists {
+		t.Errorf("quarantine branch %s missing", outcome.Review.QuarantineBranch)
+	}
+}
+
+func TestMergeProcessor_ProtectedPolicyReviewUnavailable(t *testing.T) {
+	// Without a reviewer or a review queue, a merge needing review cannot land
+	processor, req, repo := setupProtectedMerge(t, "auth/login.go")
+	base := gitOutput(t, repo, "rev-parse", "HEAD")
+	processor.SetProtectedPolicy(protectedPolicy("auth/**", protect.ActionSecondReview), nil)
+
+	outcome := processor.Execute(t.Context(), req)
+	if !errors.Is(outcome.Error, ErrProtectedAreaBlocked) {
+		t.Fatalf("outcome = %+v, want blocked", outcome)
+	}
+	if head := gitOutput(t, repo, "rev-parse", "HEAD"); head != base {
+		t.Errorf("target HEAD = %s, want merge removed (%s)", head, base)
+	}
+}
+
+func TestMergeProcessor_ProtectedPolicyUnmatched(t *testing.T) {
+	processor, req, repo := setupProtectedMerge(t, "docs/guide.md")
+	processor.SetProtectedPolicy(protectedPolicy("migrations/**", protect.ActionBlock), nil)
+
+	outcome := processor.Execute(t.Context(), req)
+	if !outcome.Success {
+		t.Fatalf("outcome = %+v, want success", outcome)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "docs", "guide.md")); err != nil {
+		t.Errorf("expected the merge to land: %v", err)
+	}
+}
+
+func TestCheckSchedulingPolicy(t *testing.T) {
+	emitter := NewEventEmitter(10)
+	o := &Orchestrator{
+		log:             pkgLog,
+		protectedPolicy: protectedPolicy("migrations/**", protect.ActionBlock),
+		emitter:         emitter,
+		progCoord:       NewProgCoordinator(nil, emitter, "", models.TierBuilder, ""),
+	}
+
+	allowed := &models.Task{ID: "task-1", FileBoundaries: []string{"internal/api/handler.go"}}
+	if !o.checkSchedulingPolicy(allowed) {
+		t.Error("expected a task outside protected areas to run")
+	}
+
+	blocked := &models.Task{ID: "task-2", FileBoundaries: []string{"migrations/002.sql"}}
+	if o.checkSchedulingPolicy(blocked) {
+		t.Fatal("expected a task touching a blocked path not to run")
+	}
+	if blocked.Status != models.TaskStatusFailed || blocked.Error == "" {
+		t.Errorf("task = %+v, want failed with a reason", blocked)
+	}
+	select {
+	case event := <-emitter.Events():
+		if event.Type != EventTaskFailed || !errors.Is(event.Error, ErrProtectedAreaBlocked) {
+			t.Errorf("event = %+v, want task failed by policy", event)
+		}
+	default:
+		t.Error("expected a task failed event")
+	}
+
+	o.protectedPolicy = nil
+	if !o.checkSchedulingPolicy(blocked) {
+		t.Error("expected every task to run without a policy")
+	}
+}
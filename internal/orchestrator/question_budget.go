@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// QuestionBudget counts the questions agents ask per task, across the
+// task's attempts, against the allowance of QuestionsAllowedWithConfig.
+// A nil QuestionBudget counts nothing.
+type QuestionBudget struct {
+	mu        sync.Mutex
+	asked     map[string]int
+	exhausted map[string]bool
+}
+
+// NewQuestionBudget creates an empty QuestionBudget.
+func NewQuestionBudget() *QuestionBudget {
+	return &QuestionBudget{
+		asked:     make(map[string]int),
+		exhausted: make(map[string]bool),
+	}
+}
+
+// Asked returns the number of questions asked for a task.
+func (b *QuestionBudget) Asked(taskID string) int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.asked[taskID]
+}
+
+// Record counts a question asked for a task with the given allowance
+// (negative for unlimited). It returns the questions asked so far and
+// whether this question used up the allowance, which is reported once per
+// task.
+func (b *QuestionBudget) Record(taskID string, allowed int) (asked int, exhausted bool) {
+	if b == nil {
+		return 0, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.asked[taskID]++
+	asked = b.asked[taskID]
+	if allowed >= 0 && asked >= allowed && !b.exhausted[taskID] {
+		b.exhausted[taskID] = true
+		return asked, true
+	}
+	return asked, false
+}
+
+// remainingQuestions returns how many more questions may be asked, or -1
+// if the allowance is unlimited.
+func remainingQuestions(allowed, asked int) int {
+	if allowed < 0 {
+		return -1
+	}
+	return max(allowed-asked, 0)
+}
+
+// FormatQuestionUsage describes question usage for display, e.g.
+// "questions 1/2 used" or "questions 3 used" for an unlimited allowance.
+func FormatQuestionUsage(asked, allowed int) string {
+	if allowed < 0 {
+		return fmt.Sprintf("questions %d used", asked)
+	}
+	return fmt.Sprintf("questions %d/%d used", asked, allowed)
+}
+
+// recordQuestion counts a question an agent asked for a task and reports
+// it, and the task running out of questions, as events.
+func (o *Orchestrator) recordQuestion(task *models.Task, agentID string, allowed int, question string) {
+	asked, exhausted := o.questions.Record(task.ID, allowed)
+	o.log.Info("agent asked a question", logging.Task(task.ID), logging.Agent(agentID), "asked", asked, "allowed", allowed)
+
+	o.emitEvent(OrchestratorEvent{
+		Type:             EventQuestionAsked,
+		TaskID:           task.ID,
+		TaskTitle:        task.Title,
+		ParentID:         task.ParentID,
+		AgentID:          agentID,
+		Message:          question,
+		QuestionsAsked:   asked,
+		QuestionsAllowed: allowed,
+		Timestamp:        time.Now(),
+	})
+	if !exhausted {
+		return
+	}
+
+	msg := fmt.Sprintf("Task %s used its question allowance (%s)", task.Title, FormatQuestionUsage(asked, allowed))
+	o.log.Warn("task exhausted its question allowance", logging.Task(task.ID), "asked", asked, "allowed", allowed)
+	o.progCoord.LogTask(task.ID, msg)
+	o.emitEvent(OrchestratorEvent{
+		Type:             EventQuestionsExhausted,
+		TaskID:           task.ID,
+		TaskTitle:        task.Title,
+		ParentID:         task.ParentID,
+		AgentID:          agentID,
+		Message:          msg,
+		QuestionsAsked:   asked,
+		QuestionsAllowed: allowed,
+		Timestamp:        time.Now(),
+	})
+}
@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestQuestionBudget_Record(t *testing.T) {
+	b := NewQuestionBudget()
+
+	if asked, exhausted := b.Record("task-1", 2); asked != 1 || exhausted {
+		t.Errorf("first question: asked = %d, exhausted = %v, want 1, false", asked, exhausted)
+	}
+	if asked, exhausted := b.Record("task-1", 2); asked != 2 || !exhausted {
+		t.Errorf("second question: asked = %d, exhausted = %v, want 2, true", asked, exhausted)
+	}
+	// Exhaustion is reported once
+	if asked, exhausted := b.Record("task-1", 2); asked != 3 || exhausted {
+		t.Errorf("third question: asked = %d, exhausted = %v, want 3, false", asked, exhausted)
+	}
+	if got := b.Asked("task-1"); got != 3 {
+		t.Errorf("Asked(task-1) = %d, want 3", got)
+	}
+	if got := b.Asked("task-2"); got != 0 {
+		t.Errorf("Asked(task-2) = %d, want 0", got)
+	}
+}
+
+func TestQuestionBudget_Unlimited(t *testing.T) {
+	b := NewQuestionBudget()
+	for i := 0; i < 5; i++ {
+		if _, exhausted := b.Record("task-1", -1); exhausted {
+			t.Fatal("unlimited allowance reported exhausted")
+		}
+	}
+}
+
+func TestQuestionBudget_Nil(t *testing.T) {
+	var b *QuestionBudget
+	if asked, exhausted := b.Record("task-1", 1); asked != 0 || exhausted {
+		t.Errorf("nil Record() = %d, %v", asked, exhausted)
+	}
+	if got := b.Asked("task-1"); got != 0 {
+		t.Errorf("nil Asked() = %d", got)
+	}
+}
+
+func TestRemainingQuestions(t *testing.T) {
+	tests := []struct {
+		allowed, asked, want int
+	}{
+		{2, 0, 2},
+		{2, 1, 1},
+		{2, 3, 0},
+		{0, 0, 0},
+		{-1, 4, -1},
+	}
+	for _, tt := range tests {
+		if got := remainingQuestions(tt.allowed, tt.asked); got != tt.want {
+			t.Errorf("remainingQuestions(%d, %d) = %d, want %d", tt.allowed, tt.asked, got, tt.want)
+		}
+	}
+}
+
+func TestFormatQuestionUsage(t *testing.T) {
+	if got := FormatQuestionUsage(1, 2); got != "questions 1/2 used" {
+		t.Errorf("FormatQuestionUsage(1, 2) = %q", got)
+	}
+	if got := FormatQuestionUsage(3, -1); got != "questions 3 used" {
+		t.Errorf("FormatQuestionUsage(3, -1) = %q", got)
+	}
+}
+
+func TestOrchestrator_RecordQuestion_EmitsExhausted(t *testing.T) {
+	emitter := NewEventEmitter(10)
+	o := &Orchestrator{
+		log:       pkgLog,
+		emitter:   emitter,
+		progCoord: NewProgCoordinator(nil, emitter, "", models.TierBuilder, ""),
+		questions: NewQuestionBudget(),
+	}
+	task := &models.Task{ID: "task-1", Title: "Add login"}
+
+	o.recordQuestion(task, "agent-1", 1, "Which OAuth provider?")
+
+	var got []OrchestratorEvent
+	timeout := time.After(time.Second)
+	for len(got) < 2 {
+		select {
+		case e := <-emitter.Events():
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("got %d events, want 2", len(got))
+		}
+	}
+	if got[0].Type != EventQuestionAsked || got[0].Message != "Which OAuth provider?" || got[0].QuestionsAsked != 1 {
+		t.Errorf("first event = %+v, want the question asked", got[0])
+	}
+	if got[1].Type != EventQuestionsExhausted || got[1].QuestionsAllowed != 1 || got[1].AgentID != "agent-1" {
+		t.Errorf("second event = %+v, want questions exhausted", got[1])
+	}
+}
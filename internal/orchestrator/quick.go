@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/redact"
 )
 
 // QuickResult contains the outcome of a quick task execution.
@@ -28,6 +30,8 @@ type QuickExecutor struct {
 	repoPath string
 	// runnerFactory creates ClaudeRunner instances via the Anthropic API.
 	runnerFactory agent.ClaudeRunnerFactory
+	// commitIdentity is who commits are made as; nil uses the git config.
+	commitIdentity *git.CommitIdentity
 }
 
 // NewQuickExecutor creates a new QuickExecutor.
@@ -39,6 +43,12 @@ func NewQuickExecutor(repoPath string, factory agent.ClaudeRunnerFactory) *Quick
 	}
 }
 
+// SetCommitIdentity sets who the executor's commits are made as, whether
+// they are signed, and their message template.
+func (q *QuickExecutor) SetCommitIdentity(identity *git.CommitIdentity) {
+	q.commitIdentity = identity
+}
+
 // Execute runs a task directly on the current branch without decomposition.
 func (q *QuickExecutor) Execute(ctx context.Context, task string) (*QuickResult, error) {
 	startTime := time.Now()
@@ -67,7 +77,7 @@ func (q *QuickExecutor) Execute(ctx context.Context, task string) (*QuickResult,
 	// Start the process in the repo directory (no worktree)
 	if err := claude.Start(task, q.repoPath); err != nil {
 		errMsg := fmt.Sprintf("failed to start claude: %v", err)
-		_ = os.WriteFile(logFile, []byte(fmt.Sprintf("Task: %s\nError: %s\n", task, errMsg)), 0644)
+		writeQuickLog(logFile, fmt.Sprintf("Task: %s\nError: %s\n", task, errMsg))
 		return &QuickResult{
 			Success:  false,
 			Error:    errMsg,
@@ -89,7 +99,7 @@ func (q *QuickExecutor) Execute(ctx context.Context, task string) (*QuickResult,
 		case agent.StreamEventError:
 			output := outputBuilder.String()
 			logContent := fmt.Sprintf("Task: %s\nError: %s\n\n--- Output ---\n%s\n", task, event.Error, output)
-			_ = os.WriteFile(logFile, []byte(logContent), 0644)
+			writeQuickLog(logFile, logContent)
 			return &QuickResult{
 				Success:  false,
 				Output:   output,
@@ -105,7 +115,7 @@ func (q *QuickExecutor) Execute(ctx context.Context, task string) (*QuickResult,
 		output := outputBuilder.String()
 		errMsg := fmt.Sprintf("process failed: %v", err)
 		logContent := fmt.Sprintf("Task: %s\nError: %s\n\n--- Output ---\n%s\n", task, errMsg, output)
-		_ = os.WriteFile(logFile, []byte(logContent), 0644)
+		writeQuickLog(logFile, logContent)
 		return &QuickResult{
 			Success:  false,
 			Output:   output,
@@ -139,7 +149,7 @@ func (q *QuickExecutor) Execute(ctx context.Context, task string) (*QuickResult,
 	} else {
 		logContent += "\n--- Auto-commit ---\nSuccess\n"
 	}
-	_ = os.WriteFile(logFile, []byte(logContent), 0644)
+	writeQuickLog(logFile, logContent)
 
 	return &QuickResult{
 		Success:    true,
@@ -173,13 +183,16 @@ func (q *QuickExecutor) autoCommitChanges(taskTitle string) error {
 		return fmt.Errorf("git add: %s: %w", string(output), err)
 	}
 
-	// Commit with task title as message
-	commitMsg := fmt.Sprintf("Quick: %s", taskTitle)
-	commitCmd := exec.Command("git", "commit", "-m", commitMsg)
-	commitCmd.Dir = q.repoPath
+	commitMsg := q.commitIdentity.Message("Quick: "+taskTitle, "", "")
+	commitCmd := q.commitIdentity.Command(q.repoPath, "commit", "-m", commitMsg)
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git commit: %s: %w", string(output), err)
 	}
 
 	return nil
 }
+
+// writeQuickLog writes a quick task's log file with secrets redacted.
+func writeQuickLog(logFile, content string) {
+	_ = os.WriteFile(logFile, []byte(redact.String(content)), 0644)
+}
@@ -0,0 +1,39 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+)
+
+// reportRateLimits emits a rate_limited event for each request the rate
+// limiter queues or the API throttles, until the returned function is
+// called. It does nothing without a rate limiter.
+func (o *Orchestrator) reportRateLimits() (stop func()) {
+	if o.rateLimiter == nil {
+		return func() {}
+	}
+	return o.rateLimiter.Subscribe(func(e agent.RateLimitEvent) {
+		o.emitEvent(OrchestratorEvent{
+			Type:      EventRateLimited,
+			Message:   rateLimitMessage(e),
+			Error:     e.Err,
+			Duration:  e.Wait,
+			RateLimit: &e,
+			Timestamp: time.Now(),
+		})
+	})
+}
+
+// rateLimitMessage describes a rate limit event.
+func rateLimitMessage(e agent.RateLimitEvent) string {
+	switch e.Type {
+	case agent.RateLimitQueued:
+		return fmt.Sprintf("API request to %s queued for %s (%d waiting)", e.Model, e.Wait.Round(time.Millisecond), e.Stats.Waiting)
+	case agent.RateLimitThrottled:
+		return fmt.Sprintf("API rate limited %s; retry %d in %s (%d throttled so far)", e.Model, e.Attempt, e.Wait.Round(time.Millisecond), e.Stats.Throttled)
+	default:
+		return fmt.Sprintf("API request to %s still rate limited after %d retries", e.Model, e.Attempt)
+	}
+}
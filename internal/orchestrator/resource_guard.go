@@ -0,0 +1,353 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/logging"
+)
+
+// cleanupCommandTimeout bounds each configured cleanup command.
+const cleanupCommandTimeout = 5 * time.Minute
+
+// ResourceUsage is a measurement of the free resources agents depend on.
+type ResourceUsage struct {
+	// FreeDisk is the free disk in bytes on the fullest of the measured
+	// filesystems.
+	FreeDisk uint64
+	// FreeMemory is the memory in bytes available to new processes.
+	FreeMemory uint64
+	// DiskMeasured and MemoryMeasured report whether the resource could be
+	// measured on this platform; unmeasured resources never cross a
+	// threshold.
+	DiskMeasured   bool
+	MemoryMeasured bool
+}
+
+// ResourceThresholds are the free resources below which no new agents are
+// spawned. A zero threshold disables its check.
+type ResourceThresholds struct {
+	MinFreeDisk   uint64
+	MinFreeMemory uint64
+}
+
+// shortfalls describes each resource of u below its threshold.
+func (t ResourceThresholds) shortfalls(u ResourceUsage) []string {
+	var low []string
+	if t.MinFreeDisk > 0 && u.DiskMeasured && u.FreeDisk < t.MinFreeDisk {
+		low = append(low, fmt.Sprintf("free disk %s below %s", formatBytes(u.FreeDisk), formatBytes(t.MinFreeDisk)))
+	}
+	if t.MinFreeMemory > 0 && u.MemoryMeasured && u.FreeMemory < t.MinFreeMemory {
+		low = append(low, fmt.Sprintf("available memory %s below %s", formatBytes(u.FreeMemory), formatBytes(t.MinFreeMemory)))
+	}
+	return low
+}
+
+// CleanupHook frees resources when a threshold is crossed. Run returns a
+// short summary of what it freed.
+type CleanupHook struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// ResourceGuard keeps a long session from exhausting the machine: worktrees,
+// dependency directories and build artifacts pile up over a run. When free
+// disk or memory drops below its threshold, the guard pauses the session so
+// no new agents are spawned (running agents finish), emits resource_low and
+// runs its cleanup hooks. It resumes the session and emits
+// resource_recovered once every resource is back above its threshold. A
+// session paused by something else stays paused.
+type ResourceGuard struct {
+	probe      func() ResourceUsage
+	thresholds ResourceThresholds
+	hooks      []CleanupHook
+	log        *slog.Logger
+
+	// pause, resume and isPaused control the session.
+	pause    func()
+	resume   func()
+	isPaused func() bool
+	// emit reports resource events.
+	emit func(OrchestratorEvent)
+
+	mu sync.Mutex
+	// low is set while a threshold is crossed.
+	low bool
+	// paused is set if the guard paused the session and must resume it.
+	paused bool
+}
+
+// NewResourceGuard creates a guard measuring resources with probe.
+func NewResourceGuard(probe func() ResourceUsage, thresholds ResourceThresholds) *ResourceGuard {
+	return &ResourceGuard{
+		probe:      probe,
+		thresholds: thresholds,
+		log:        pkgLog,
+		pause:      func() {},
+		resume:     func() {},
+		isPaused:   func() bool { return false },
+		emit:       func(OrchestratorEvent) {},
+	}
+}
+
+// SetLogger sets the logger of the guard's records.
+func (g *ResourceGuard) SetLogger(l *slog.Logger) {
+	g.log = l
+}
+
+// AddCleanupHook adds a hook run, in the order added, when a threshold is
+// crossed.
+func (g *ResourceGuard) AddCleanupHook(hook CleanupHook) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.hooks = append(g.hooks, hook)
+}
+
+// Low reports whether a threshold is currently crossed.
+func (g *ResourceGuard) Low() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.low
+}
+
+// Check measures resources once, pausing the session, running the cleanup
+// hooks and resuming it as thresholds are crossed and recovered.
+func (g *ResourceGuard) Check(ctx context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	usage := g.probe()
+	low := g.thresholds.shortfalls(usage)
+
+	if !g.low {
+		if len(low) == 0 {
+			return
+		}
+		g.low = true
+		reason := strings.Join(low, ", ")
+		if !g.isPaused() {
+			g.pause()
+			g.paused = true
+		}
+		g.log.Warn("resources low, pausing new agents", "reason", reason)
+		g.emit(OrchestratorEvent{
+			Type:      EventResourceLow,
+			Message:   fmt.Sprintf("Resources low (%s); no new agents until they recover", reason),
+			Timestamp: time.Now(),
+		})
+
+		g.runHooksLocked(ctx)
+		usage = g.probe()
+		low = g.thresholds.shortfalls(usage)
+	}
+
+	if len(low) > 0 {
+		return
+	}
+	g.low = false
+	if g.paused {
+		g.paused = false
+		g.resume()
+	}
+	g.log.Info("resources recovered, resuming", "free_disk", formatBytes(usage.FreeDisk), "free_memory", formatBytes(usage.FreeMemory))
+	g.emit(OrchestratorEvent{
+		Type:      EventResourceRecovered,
+		Message:   fmt.Sprintf("Resources recovered (%s)", describeUsage(usage)),
+		Timestamp: time.Now(),
+	})
+}
+
+// runHooksLocked runs the cleanup hooks, logging what each freed. A failing
+// hook does not stop the others.
+// Caller must hold g.mu.
+func (g *ResourceGuard) runHooksLocked(ctx context.Context) {
+	for _, hook := range g.hooks {
+		summary, err := hook.Run(ctx)
+		if err != nil {
+			g.log.Warn("resource cleanup failed", "hook", hook.Name, logging.Err(err))
+			continue
+		}
+		if summary != "" {
+			g.log.Info("resource cleanup", "hook", hook.Name, "freed", summary)
+		}
+	}
+}
+
+// Watch checks resources every interval until the returned function is
+// called or ctx is done.
+func (g *ResourceGuard) Watch(ctx context.Context, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		g.Check(ctx)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.Check(ctx)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// startResourceGuard starts guarding free disk and memory as configured by
+// the resource policy, until the returned function is called. It does
+// nothing if every threshold is disabled.
+func (o *Orchestrator) startResourceGuard(ctx context.Context) (stop func()) {
+	p := o.config.Policy.Resources
+	if (p.MinFreeDiskMB <= 0 && p.MinFreeMemoryMB <= 0) || p.CheckInterval <= 0 {
+		return func() {}
+	}
+
+	paths := []string{o.config.RepoPath}
+	pruner, _ := o.spawner.executor.(worktreePruner)
+	if pruner != nil {
+		paths = append(paths, pruner.WorktreeBaseDir())
+	}
+	guard := NewResourceGuard(func() ResourceUsage { return measureResources(paths) }, ResourceThresholds{
+		MinFreeDisk:   uint64(p.MinFreeDiskMB) << 20,
+		MinFreeMemory: uint64(p.MinFreeMemoryMB) << 20,
+	})
+	guard.SetLogger(sessionLogger("resources", o.config.SessionID))
+	guard.pause = o.Pause
+	guard.resume = o.Resume
+	guard.isPaused = o.IsPaused
+	guard.emit = o.emitEvent
+	if pruner != nil {
+		guard.AddCleanupHook(pruneWorktreesHook(pruner))
+	}
+	for _, command := range p.CleanupCommands {
+		guard.AddCleanupHook(cleanupCommandHook(o.config.RepoPath, command))
+	}
+	return guard.Watch(ctx, p.CheckInterval)
+}
+
+// worktreePruner is implemented by executors that can free the disk their
+// worktrees use.
+type worktreePruner interface {
+	WorktreeBaseDir() string
+	PruneWorktrees() (*agent.StaleCleanupReport, error)
+}
+
+// pruneWorktreesHook removes idle pooled and stale worktrees.
+func pruneWorktreesHook(p worktreePruner) CleanupHook {
+	return CleanupHook{
+		Name: "prune worktrees",
+		Run: func(context.Context) (string, error) {
+			report, err := p.PruneWorktrees()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d worktree(s), %d branch(es)", len(report.Worktrees), len(report.Branches)), nil
+		},
+	}
+}
+
+// cleanupCommandHook runs a shell command in dir.
+func cleanupCommandHook(dir, command string) CleanupHook {
+	return CleanupHook{
+		Name: command,
+		Run: func(ctx context.Context) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, cleanupCommandTimeout)
+			defer cancel()
+			argv := iexec.ShellCommand(command)
+			cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+			}
+			return "ok", nil
+		},
+	}
+}
+
+// measureResources measures the free disk of the fullest filesystem of
+// paths and the available memory.
+func measureResources(paths []string) ResourceUsage {
+	var usage ResourceUsage
+	for _, path := range paths {
+		free, err := diskFree(path)
+		if err != nil {
+			continue
+		}
+		if !usage.DiskMeasured || free < usage.FreeDisk {
+			usage.FreeDisk = free
+		}
+		usage.DiskMeasured = true
+	}
+	if free, err := memoryAvailable(); err == nil {
+		usage.FreeMemory = free
+		usage.MemoryMeasured = true
+	}
+	return usage
+}
+
+// memoryAvailable returns the memory available to new processes, as
+// reported by MemAvailable in /proc/meminfo. Platforms without
+// /proc/meminfo return an error.
+func memoryAvailable() (uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return parseMemAvailable(f)
+}
+
+// parseMemAvailable returns the MemAvailable entry of a /proc/meminfo
+// listing, in bytes.
+func parseMemAvailable(r io.Reader) (uint64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse MemAvailable: %w", err)
+		}
+		return kb << 10, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("MemAvailable not reported")
+}
+
+// describeUsage describes the measured resources of u.
+func describeUsage(u ResourceUsage) string {
+	var parts []string
+	if u.DiskMeasured {
+		parts = append(parts, "free disk "+formatBytes(u.FreeDisk))
+	}
+	if u.MemoryMeasured {
+		parts = append(parts, "available memory "+formatBytes(u.FreeMemory))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatBytes formats n bytes in MB, or GB from 1 GB.
+func formatBytes(n uint64) string {
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", n>>20)
+}
@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSession records what a resource guard does to the session.
+type fakeSession struct {
+	paused bool
+	events []EventType
+}
+
+func (s *fakeSession) attach(g *ResourceGuard) {
+	g.pause = func() { s.paused = true }
+	g.resume = func() { s.paused = false }
+	g.isPaused = func() bool { return s.paused }
+	g.emit = func(e OrchestratorEvent) { s.events = append(s.events, e.Type) }
+}
+
+func TestResourceGuard_PausesUntilRecovered(t *testing.T) {
+	usage := ResourceUsage{FreeDisk: 10 << 30, DiskMeasured: true}
+	g := NewResourceGuard(func() ResourceUsage { return usage }, ResourceThresholds{MinFreeDisk: 1 << 30})
+	session := &fakeSession{}
+	session.attach(g)
+	cleanups := 0
+	g.AddCleanupHook(CleanupHook{Name: "count", Run: func(context.Context) (string, error) {
+		cleanups++
+		return "", nil
+	}})
+
+	g.Check(context.Background())
+	if session.paused || len(session.events) != 0 || cleanups != 0 {
+		t.Fatalf("with enough disk: paused=%v events=%v cleanups=%d", session.paused, session.events, cleanups)
+	}
+
+	usage.FreeDisk = 512 << 20
+	g.Check(context.Background())
+	g.Check(context.Background())
+	if !session.paused || !g.Low() {
+		t.Fatal("session not paused while disk is low")
+	}
+	if want := []EventType{EventResourceLow}; !reflect.DeepEqual(session.events, want) {
+		t.Errorf("events = %v, want %v", session.events, want)
+	}
+	if cleanups != 1 {
+		t.Errorf("cleanup hooks ran %d times, want once per low period", cleanups)
+	}
+
+	usage.FreeDisk = 2 << 30
+	g.Check(context.Background())
+	if session.paused || g.Low() {
+		t.Fatal("session still paused after disk recovered")
+	}
+	if want := []EventType{EventResourceLow, EventResourceRecovered}; !reflect.DeepEqual(session.events, want) {
+		t.Errorf("events = %v, want %v", session.events, want)
+	}
+}
+
+func TestResourceGuard_CleanupFreesEnough(t *testing.T) {
+	usage := ResourceUsage{FreeMemory: 100 << 20, MemoryMeasured: true}
+	g := NewResourceGuard(func() ResourceUsage { return usage }, ResourceThresholds{MinFreeMemory: 256 << 20})
+	session := &fakeSession{}
+	session.attach(g)
+	g.AddCleanupHook(CleanupHook{Name: "free", Run: func(context.Context) (string, error) {
+		usage.FreeMemory = 1 << 30
+		return "freed", nil
+	}})
+
+	g.Check(context.Background())
+	if session.paused || g.Low() {
+		t.Error("session still paused after cleanup freed enough memory")
+	}
+	if want := []EventType{EventResourceLow, EventResourceRecovered}; !reflect.DeepEqual(session.events, want) {
+		t.Errorf("events = %v, want %v", session.events, want)
+	}
+}
+
+func TestResourceGuard_KeepsOtherPauses(t *testing.T) {
+	usage := ResourceUsage{FreeDisk: 0, DiskMeasured: true}
+	g := NewResourceGuard(func() ResourceUsage { return usage }, ResourceThresholds{MinFreeDisk: 1 << 30})
+	session := &fakeSession{paused: true}
+	session.attach(g)
+
+	g.Check(context.Background())
+	usage.FreeDisk = 2 << 30
+	g.Check(context.Background())
+	if !session.paused {
+		t.Error("guard resumed a session paused by the operator")
+	}
+}
+
+func TestResourceThresholds_Unmeasured(t *testing.T) {
+	thresholds := ResourceThresholds{MinFreeDisk: 1 << 30, MinFreeMemory: 1 << 30}
+	if low := thresholds.shortfalls(ResourceUsage{}); len(low) != 0 {
+		t.Errorf("unmeasured resources reported low: %v", low)
+	}
+	if low := (ResourceThresholds{}).shortfalls(ResourceUsage{DiskMeasured: true, MemoryMeasured: true}); len(low) != 0 {
+		t.Errorf("disabled thresholds reported low: %v", low)
+	}
+}
+
+func TestParseMemAvailable(t *testing.T) {
+	meminfo := "MemTotal:       16318480 kB\nMemFree:          935560 kB\nMemAvailable:    8388608 kB\n"
+	got, err := parseMemAvailable(strings.NewReader(meminfo))
+	if err != nil {
+		t.Fatalf("parseMemAvailable() error = %v", err)
+	}
+	if want := uint64(8 << 30); got != want {
+		t.Errorf("parseMemAvailable() = %d, want %d", got, want)
+	}
+	if _, err := parseMemAvailable(strings.NewReader("MemTotal: 1 kB\n")); err == nil {
+		t.Error("parseMemAvailable() without MemAvailable succeeded")
+	}
+}
+
+func TestMeasureResources(t *testing.T) {
+	usage := measureResources([]string{t.TempDir()})
+	if !usage.DiskMeasured || usage.FreeDisk == 0 {
+		t.Errorf("measureResources() = %+v, want free disk measured", usage)
+	}
+}
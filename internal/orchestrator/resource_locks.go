@@ -0,0 +1,65 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// ResolveResourceLocks returns the normalized set of resource locks a task holds.
+// It combines the task's own ResourceLocks annotation with any configured rules
+// whose patterns match the task's title, description, or file boundaries.
+func ResolveResourceLocks(task *models.Task, rules []policy.ResourceRule) []string {
+	if task == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var locks []string
+	add := func(name string) {
+		name = normalizeResourceName(name)
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		locks = append(locks, name)
+	}
+
+	for _, lock := range task.ResourceLocks {
+		add(lock)
+	}
+
+	if len(rules) > 0 {
+		haystack := strings.ToLower(task.Title + "\n" + task.Description + "\n" + strings.Join(task.FileBoundaries, "\n"))
+		for _, rule := range rules {
+			for _, pattern := range rule.Patterns {
+				pattern = strings.ToLower(strings.TrimSpace(pattern))
+				if pattern != "" && strings.Contains(haystack, pattern) {
+					add(rule.Resource)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Strings(locks)
+	return locks
+}
+
+// normalizeResourceName lowercases and trims a resource lock name.
+func normalizeResourceName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// resourceLockConflict returns the first lock in locks that is already held, or "".
+func resourceLockConflict(locks []string, held map[string]bool) string {
+	for _, lock := range locks {
+		if held[lock] {
+			return lock
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestResolveResourceLocks(t *testing.T) {
+	rules := []policy.ResourceRule{
+		{Resource: "db:schema", Patterns: []string{"migration", "schema"}},
+		{Resource: "port:3000", Patterns: []string{"dev server"}},
+	}
+
+	tests := []struct {
+		name string
+		task *models.Task
+		want []string
+	}{
+		{
+			name: "nil task",
+			task: nil,
+			want: nil,
+		},
+		{
+			name: "annotated locks are normalized",
+			task: &models.Task{ResourceLocks: []string{" DB:Schema ", "db:schema"}},
+			want: []string{"db:schema"},
+		},
+		{
+			name: "rule matches title",
+			task: &models.Task{Title: "Add users migration"},
+			want: []string{"db:schema"},
+		},
+		{
+			name: "rule matches file boundary",
+			task: &models.Task{Title: "Add users table", FileBoundaries: []string{"db/schema.sql"}},
+			want: []string{"db:schema"},
+		},
+		{
+			name: "annotation and rules combined",
+			task: &models.Task{Title: "Start dev server", ResourceLocks: []string{"config:global"}},
+			want: []string{"config:global", "port:3000"},
+		},
+		{
+			name: "no match",
+			task: &models.Task{Title: "Refactor parser"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveResourceLocks(tt.task, rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveResourceLocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
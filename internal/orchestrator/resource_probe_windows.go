@@ -0,0 +1,10 @@
+//go:build windows
+
+package orchestrator
+
+import "errors"
+
+// diskFree is not measured on Windows; the disk guardrail is disabled there.
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("free disk is not measured on windows")
+}
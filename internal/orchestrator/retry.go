@@ -0,0 +1,125 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// maxFailureContext caps the failure detail injected into retry prompts.
+const maxFailureContext = 2000
+
+// retryPolicyFromTier overlays a tier's retry settings on the policy.
+// Zero values in the tier config keep the policy values.
+func retryPolicyFromTier(p policy.RetryPolicy, tc *config.RetryConfig) policy.RetryPolicy {
+	if tc == nil {
+		return p
+	}
+	if tc.MaxAttempts > 0 {
+		p.MaxAttempts = tc.MaxAttempts
+	}
+	if tc.Backoff > 0 {
+		p.Backoff = tc.Backoff
+	}
+	if tc.MaxBackoff > 0 {
+		p.MaxBackoff = tc.MaxBackoff
+	}
+	if tc.Jitter > 0 {
+		p.Jitter = tc.Jitter
+	}
+	if tc.RetryOn != nil {
+		p.RetryOn = tc.RetryOn
+	}
+	return p
+}
+
+// classifyFailure returns the policy.Failure* classification of a failed result.
+func (o *Orchestrator) classifyFailure(task *models.Task, result *agent.ExecutionResult) string {
+	if o.budget != nil && o.budget.Enabled() && o.budget.CheckTask(task.ID) == BudgetExceeded {
+		return policy.FailureBudget
+	}
+	if result.Stalled {
+		return policy.FailureTimeout
+	}
+	lower := strings.ToLower(result.Error)
+	if strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out") || strings.Contains(lower, "deadline exceeded") {
+		return policy.FailureTimeout
+	}
+	if !result.IsVerified() || !result.AreGatesPassed() || len(result.LintViolations) > 0 || len(result.BlockingSecurityFindings()) > 0 {
+		return policy.FailureVerification
+	}
+	return policy.FailureExecution
+}
+
+// retryable returns true if the policy retries the given failure classification.
+func retryable(p policy.RetryPolicy, class string) bool {
+	for _, c := range p.RetryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay returns the backoff before retry number attempt (1-based):
+// Backoff doubled per previous retry, capped at MaxBackoff, then jittered.
+func retryDelay(p policy.RetryPolicy, attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		// Scale by a random factor in [1-Jitter, 1+Jitter)
+		factor := 1 + p.Jitter*(2*rand.Float64()-1)
+		delay = time.Duration(float64(delay) * factor)
+	}
+	return delay
+}
+
+// failureContext summarizes a failed attempt for the next attempt's prompt.
+func failureContext(class string, result *agent.ExecutionResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Failure type: %s\n", class))
+	if result.Error != "" {
+		sb.WriteString(fmt.Sprintf("Error: %s\n", result.Error))
+	}
+	if result.VerifySummary != "" {
+		sb.WriteString("Verification:\n")
+		sb.WriteString(result.VerifySummary)
+		sb.WriteString("\n")
+	}
+	if len(result.LintViolations) > 0 {
+		sb.WriteString("New lint violations:\n")
+		for _, v := range result.LintViolations {
+			sb.WriteString("- " + v + "\n")
+		}
+	}
+	if blocking := result.BlockingSecurityFindings(); len(blocking) > 0 {
+		sb.WriteString("Security findings to fix:\n")
+		for _, f := range blocking {
+			sb.WriteString("- " + f.String() + "\n")
+		}
+	}
+	if result.LoopExitReason != "" {
+		sb.WriteString(fmt.Sprintf("Self-review loop exited: %s\n", result.LoopExitReason))
+	}
+
+	ctx := strings.TrimSpace(sb.String())
+	if len(ctx) > maxFailureContext {
+		ctx = ctx[:maxFailureContext] + "\n... (truncated)"
+	}
+	return ctx
+}
@@ -0,0 +1,150 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestRetryDelay(t *testing.T) {
+	p := policy.RetryPolicy{Backoff: 5 * time.Second, MaxBackoff: 15 * time.Second}
+
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second, 15 * time.Second}
+	for i, w := range want {
+		if got := retryDelay(p, i+1); got != w {
+			t.Errorf("retryDelay(attempt %d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		got := retryDelay(p, 1)
+		if got < 2500*time.Millisecond || got > 7500*time.Millisecond {
+			t.Fatalf("jittered delay %v outside [2.5s, 7.5s]", got)
+		}
+	}
+
+	if got := retryDelay(policy.RetryPolicy{}, 3); got != 0 {
+		t.Errorf("expected no delay without backoff, got %v", got)
+	}
+}
+
+func TestRetryPolicyFromTier(t *testing.T) {
+	base := policy.Default().Retry
+
+	if got := retryPolicyFromTier(base, nil); got.MaxAttempts != base.MaxAttempts {
+		t.Errorf("nil tier config should keep policy, got %+v", got)
+	}
+
+	got := retryPolicyFromTier(base, &config.RetryConfig{
+		MaxAttempts: 5,
+		Backoff:     time.Minute,
+		RetryOn:     []string{policy.FailureTimeout},
+	})
+	if got.MaxAttempts != 5 || got.Backoff != time.Minute {
+		t.Errorf("tier values not applied: %+v", got)
+	}
+	if got.MaxBackoff != base.MaxBackoff || got.Jitter != base.Jitter {
+		t.Errorf("zero tier values should keep policy values: %+v", got)
+	}
+	if retryable(got, policy.FailureExecution) || !retryable(got, policy.FailureTimeout) {
+		t.Errorf("RetryOn not applied: %v", got.RetryOn)
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	o := &Orchestrator{log: pkgLog}
+	task := &models.Task{ID: "task-1"}
+	failed := false
+
+	tests := []struct {
+		name   string
+		result *agent.ExecutionResult
+		want   string
+	}{
+		{"execution", &agent.ExecutionResult{Error: "claude exited with status 1"}, policy.FailureExecution},
+		{"timeout", &agent.ExecutionResult{Error: "context deadline exceeded"}, policy.FailureTimeout},
+		{"stalled", &agent.ExecutionResult{Error: "agent stalled: no progress for 10m0s", Stalled: true}, policy.FailureTimeout},
+		{"verification", &agent.ExecutionResult{Error: "checks failed", VerifyPassed: &failed}, policy.FailureVerification},
+		{"gates", &agent.ExecutionResult{Error: "build failed", GatesPassed: &failed}, policy.FailureVerification},
+		{"lint", &agent.ExecutionResult{Error: "lint validation failed: 1 new violation(s)", LintViolations: []string{"a.go:errcheck: unchecked"}}, policy.FailureVerification},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.classifyFailure(task, tt.result); got != tt.want {
+				t.Errorf("classifyFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	o.budget = NewBudgetManager(policy.BudgetPolicy{TaskLimit: 1, WarnRatio: 0.8})
+	o.budget.RecordTaskCost(task.ID, 2)
+	if got := o.classifyFailure(task, &agent.ExecutionResult{Error: "context canceled"}); got != policy.FailureBudget {
+		t.Errorf("classifyFailure() = %q, want budget", got)
+	}
+}
+
+func TestFailureContext(t *testing.T) {
+	ctx := failureContext(policy.FailureVerification, &agent.ExecutionResult{
+		Error:         "tests failed",
+		VerifySummary: "FAIL TestLogin",
+	})
+	if !strings.Contains(ctx, "verification") || !strings.Contains(ctx, "FAIL TestLogin") {
+		t.Errorf("unexpected failure context:\n%s", ctx)
+	}
+
+	lint := failureContext(policy.FailureVerification, &agent.ExecutionResult{
+		Error:          "lint validation failed: 1 new violation(s)",
+		LintViolations: []string{"api/handler.go:errcheck: Error return value is not checked"},
+	})
+	if !strings.Contains(lint, "- api/handler.go:errcheck: Error return value is not checked") {
+		t.Errorf("failure context missing lint violation:\n%s", lint)
+	}
+
+	secret := failureContext(policy.FailureVerification, &agent.ExecutionResult{
+		SecurityFindings: []agent.SecurityFinding{
+			{Severity: "critical", File: "deploy.env", Line: 2, Message: "possible secret added (env-secret)", Blocking: true},
+			{Severity: "moderate", File: "package.json", Message: "express has a moderate vulnerability"},
+		},
+	})
+	if !strings.Contains(secret, "deploy.env:2: possible secret added") || strings.Contains(secret, "express") {
+		t.Errorf("failure context should list only blocking security findings:\n%s", secret)
+	}
+
+	long := failureContext(policy.FailureExecution, &agent.ExecutionResult{Error: strings.Repeat("x", 5000)})
+	if len(long) > maxFailureContext+50 {
+		t.Errorf("failure context not truncated: %d bytes", len(long))
+	}
+}
+
+func TestFailureLearning(t *testing.T) {
+	task := &models.Task{ID: "t1", Title: "Add login endpoint"}
+	result := &agent.ExecutionResult{
+		Output:        "Looking at the handlers.\n\nI stubbed the session store\nwith a global map.\n\n",
+		Error:         "tests failed",
+		VerifySummary: "FAIL TestLogin\nFAIL TestLogout",
+	}
+	condition, action, detail := failureLearning(task, result, policy.FailureVerification)
+	if condition != "working on Add login endpoint" {
+		t.Errorf("condition = %q", condition)
+	}
+	if action != "I stubbed the session store with a global map." {
+		t.Errorf("action = %q", action)
+	}
+	if detail != "verification failure: FAIL TestLogin" {
+		t.Errorf("detail = %q", detail)
+	}
+
+	_, action, _ = failureLearning(task, &agent.ExecutionResult{Output: strings.Repeat("y", 1000)}, policy.FailureExecution)
+	if len(action) > maxFailedApproach+3 {
+		t.Errorf("action not truncated: %d bytes", len(action))
+	}
+	if _, action, _ = failureLearning(task, &agent.ExecutionResult{}, policy.FailureExecution); action != "" {
+		t.Errorf("action = %q, want empty without output", action)
+	}
+}
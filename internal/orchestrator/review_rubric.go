@@ -0,0 +1,145 @@
+// Package orchestrator provides task decomposition and coordination.
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+// Rubric verdicts a reviewer gives a criterion.
+const (
+	// VerdictPass means the change meets the criterion.
+	VerdictPass = "pass"
+	// VerdictFail means the change does not meet the criterion.
+	VerdictFail = "fail"
+	// VerdictNotApplicable means the criterion does not apply to the change,
+	// such as accessibility for a change without a user interface.
+	VerdictNotApplicable = "n/a"
+)
+
+// CriterionVerdict is a reviewer's verdict on one review rubric criterion.
+type CriterionVerdict struct {
+	// Criterion names the rubric criterion.
+	Criterion string `json:"criterion"`
+	// Verdict is VerdictPass, VerdictFail or VerdictNotApplicable.
+	Verdict string `json:"verdict"`
+	// Reasoning explains the verdict.
+	Reasoning string `json:"reasoning,omitempty"`
+	// Concerns are the issues behind a failing verdict, in the same form
+	// as free-text review concerns.
+	Concerns []string `json:"concerns,omitempty"`
+}
+
+// Failed reports whether the change does not meet the criterion.
+func (v CriterionVerdict) Failed() bool {
+	return v.Verdict == VerdictFail
+}
+
+// WriteRubricSection enumerates the rubric criteria for a review prompt.
+func WriteRubricSection(sb *strings.Builder, rubric []policy.RubricCriterion) {
+	sb.WriteString("## Review Rubric\n\n")
+	sb.WriteString("Give a verdict on each of these criteria: pass, fail, or n/a if the criterion does not apply to the change.\n\n")
+	for _, c := range rubric {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", c.Name, c.Description))
+	}
+	sb.WriteString("\n")
+}
+
+// rubricVerdictSchema is the JSON each criterion's verdict is returned as.
+const rubricVerdictSchema = `{"criterion": "string", "verdict": "pass|fail|n/a", "reasoning": "string", "concerns": ["string"]}`
+
+// NormalizeVerdicts matches a reviewer's verdicts to the rubric, in rubric
+// order. Verdicts on criteria outside the rubric are dropped, and a
+// criterion the reviewer gave no valid verdict on fails, so a review cannot
+// pass by leaving criteria out.
+func NormalizeVerdicts(raw []CriterionVerdict, rubric []policy.RubricCriterion) []CriterionVerdict {
+	byName := make(map[string]CriterionVerdict, len(raw))
+	for _, v := range raw {
+		name := strings.ToLower(strings.TrimSpace(v.Criterion))
+		v.Verdict = strings.ToLower(strings.TrimSpace(v.Verdict))
+		if v.Verdict == "na" || v.Verdict == "not_applicable" {
+			v.Verdict = VerdictNotApplicable
+		}
+		byName[name] = v
+	}
+
+	verdicts := make([]CriterionVerdict, 0, len(rubric))
+	for _, c := range rubric {
+		v, ok := byName[strings.ToLower(c.Name)]
+		switch {
+		case !ok:
+			v = CriterionVerdict{Verdict: VerdictFail, Reasoning: "no verdict given"}
+		case v.Verdict != VerdictPass && v.Verdict != VerdictFail && v.Verdict != VerdictNotApplicable:
+			v = CriterionVerdict{Verdict: VerdictFail, Reasoning: fmt.Sprintf("unrecognized verdict %q", v.Verdict)}
+		}
+		v.Criterion = c.Name
+		verdicts = append(verdicts, v)
+	}
+	return verdicts
+}
+
+// buildRubricReviewPrompt constructs the prompt for a reviewer that gives a
+// verdict on each rubric criterion instead of free-text concerns.
+func buildRubricReviewPrompt(diff, taskDescription, focus string, rubric []policy.RubricCriterion) string {
+	var sb strings.Builder
+	if focus != "" {
+		sb.WriteString(fmt.Sprintf("You are a %s reviewer performing a second review of high-risk changes. Weigh %s issues most heavily.\n\n", focus, focus))
+	} else {
+		sb.WriteString("You are a code reviewer performing a second review of high-risk changes.\n\n")
+	}
+	sb.WriteString("TASK DESCRIPTION:\n")
+	sb.WriteString(taskDescription)
+	sb.WriteString("\n\nDIFF TO REVIEW:\n")
+	sb.WriteString(diff)
+	sb.WriteString("\n\n")
+	WriteRubricSection(&sb, rubric)
+	sb.WriteString("For each failing criterion, list its concerns. Start each concern with its severity in brackets as [low], [medium], [high] or [critical], and cite the file and line it applies to as path/to/file.go:42 where possible.\n\n")
+	sb.WriteString("Respond with ONLY a JSON object with this exact structure (no other text):\n")
+	sb.WriteString(fmt.Sprintf("{\n  \"verdicts\": [\n    %s\n  ]\n}\n", rubricVerdictSchema))
+	return sb.String()
+}
+
+// parseRubricReviewResponse extracts the per-criterion verdicts from a
+// rubric reviewer's output. The change is approved only if no criterion
+// fails; each failing criterion's concerns become the review's concerns.
+func parseRubricReviewResponse(output string, rubric []policy.RubricCriterion) *SecondReviewResult {
+	var response struct {
+		Verdicts []CriterionVerdict `json:"verdicts"`
+	}
+	if start, end := strings.Index(output, "{"), strings.LastIndex(output, "}"); start != -1 && end > start {
+		_ = json.Unmarshal([]byte(output[start:end+1]), &response)
+	}
+
+	result := &SecondReviewResult{
+		Approved:       true,
+		Concerns:       []string{},
+		ReviewerOutput: output,
+		Verdicts:       NormalizeVerdicts(response.Verdicts, rubric),
+	}
+	for _, v := range result.Verdicts {
+		if !v.Failed() {
+			continue
+		}
+		result.Approved = false
+		concerns := v.Concerns
+		if len(concerns) == 0 && v.Reasoning != "" {
+			concerns = []string{v.Reasoning}
+		} else if len(concerns) == 0 {
+			concerns = []string{"does not meet the criterion"}
+		}
+		for _, concern := range concerns {
+			concern = strings.TrimSpace(concern)
+			if concern == "" {
+				continue
+			}
+			result.Concerns = append(result.Concerns, v.Criterion+": "+concern)
+			finding := parseFinding(concern)
+			finding.Criterion = v.Criterion
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+	return result
+}
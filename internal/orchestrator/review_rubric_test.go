@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+)
+
+func testRubric() []policy.RubricCriterion {
+	p := policy.Default()
+	p.Review.Rubric = []policy.RubricCriterion{
+		{Name: policy.RubricSecurity},
+		{Name: policy.RubricErrorHandling},
+		{Name: "logging", Description: "Changes log at the right level"},
+	}
+	_ = p.Validate()
+	return p.Review.Rubric
+}
+
+func TestPolicyValidate_RubricDescriptions(t *testing.T) {
+	p := policy.Default()
+	p.Review.Rubric = []policy.RubricCriterion{
+		{Name: policy.RubricSecurity},
+		{Name: "perf"},
+		{Name: "", Description: "no name"},
+	}
+	_ = p.Validate()
+
+	if len(p.Review.Rubric) != 1 {
+		t.Fatalf("rubric = %+v, want only the built-in criterion", p.Review.Rubric)
+	}
+	if p.Review.Rubric[0].Description == "" {
+		t.Error("built-in criterion should get its built-in description")
+	}
+}
+
+func TestBuildRubricReviewPrompt_EnumeratesCriteria(t *testing.T) {
+	prompt := buildRubricReviewPrompt("diff --git a/x.go b/x.go", "Add login", "", testRubric())
+
+	for _, want := range []string{"- security:", "- error_handling:", "- logging: Changes log at the right level", `"verdicts"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestParseRubricReviewResponse(t *testing.T) {
+	output := "Here is my review:\n" + `{
+  "verdicts": [
+    {"criterion": "security", "verdict": "PASS", "reasoning": "No user input"},
+    {"criterion": "error_handling", "verdict": "fail", "reasoning": "Ignored error",
+     "concerns": ["[high] auth/login.go:42 error from Save is dropped"]},
+    {"criterion": "style", "verdict": "pass"}
+  ]
+}`
+
+	result := parseRubricReviewResponse(output, testRubric())
+
+	if result.Approved {
+		t.Error("a failing criterion should block approval")
+	}
+	if len(result.Verdicts) != 3 {
+		t.Fatalf("verdicts = %+v, want one per rubric criterion", result.Verdicts)
+	}
+	if result.Verdicts[0].Verdict != VerdictPass {
+		t.Errorf("security verdict = %q, want pass", result.Verdicts[0].Verdict)
+	}
+	if v := result.Verdicts[2]; v.Criterion != "logging" || !v.Failed() || v.Reasoning != "no verdict given" {
+		t.Errorf("missing criterion = %+v, want a failed verdict", v)
+	}
+
+	if len(result.Findings) != 2 {
+		t.Fatalf("findings = %+v, want 2", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.Criterion != "error_handling" || f.Severity != "high" || f.File != "auth/login.go" || f.Line != 42 {
+		t.Errorf("finding = %+v", f)
+	}
+	if result.Concerns[0] != "error_handling: [high] auth/login.go:42 error from Save is dropped" {
+		t.Errorf("concern = %q", result.Concerns[0])
+	}
+}
+
+func TestParseRubricReviewResponse_AllPass(t *testing.T) {
+	output := `{"verdicts": [
+    {"criterion": "security", "verdict": "pass"},
+    {"criterion": "error_handling", "verdict": "n/a"},
+    {"criterion": "logging", "verdict": "pass"}
+  ]}`
+
+	result := parseRubricReviewResponse(output, testRubric())
+
+	if !result.Approved {
+		t.Errorf("expected approval, concerns = %v", result.Concerns)
+	}
+	if len(result.Concerns) != 0 {
+		t.Errorf("concerns = %v, want none", result.Concerns)
+	}
+}
+
+func TestParseRubricReviewResponse_FreeText(t *testing.T) {
+	result := parseRubricReviewResponse("APPROVED\nLooks good to me.", testRubric())
+
+	if result.Approved {
+		t.Error("a review without verdicts should not approve")
+	}
+	for _, v := range result.Verdicts {
+		if !v.Failed() {
+			t.Errorf("verdict %+v should fail without a response", v)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+// RollbackStore is the state a rollback reads and updates. *state.DB
+// implements it.
+type RollbackStore interface {
+	state.SessionOriginStore
+	state.ProgLinkStore
+	GetSession(id string) (*state.Session, error)
+	UpdateSession(s *state.Session) error
+}
+
+// Verify state.DB implements RollbackStore at compile time.
+var _ RollbackStore = (*state.DB)(nil)
+
+// RollbackPlan is what rolling back a session, or every session of an epic,
+// undoes. Build it with Rollback.Plan and carry it out with Rollback.Apply.
+type RollbackPlan struct {
+	// SessionIDs are the sessions rolled back, oldest first.
+	SessionIDs []string
+	// EpicID is the prog epic the sessions executed, if any.
+	EpicID string
+	// WholeEpic is set when the epic itself is aborted, not one session.
+	WholeEpic bool
+	// BaseBranch and BaseCommit are what was checked out before the first
+	// session started; the repository is left there.
+	BaseBranch string
+	BaseCommit string
+	// DiscardedCommits are the commits BaseBranch gained since BaseCommit,
+	// newest first: session work merged into it (or committed directly in
+	// greenfield mode) and anything else committed since. They are only
+	// dropped when Apply is asked to reset the base branch.
+	DiscardedCommits []string
+	// SessionBranches and AgentBranches are the branches to delete.
+	SessionBranches []string
+	AgentBranches   []string
+	// Worktrees are the paths of the agent worktrees to remove.
+	Worktrees []string
+	// ProgTasks are the unfinished prog tasks to cancel.
+	ProgTasks []string
+}
+
+// Empty reports whether there is nothing left to undo apart from marking
+// the sessions canceled.
+func (p *RollbackPlan) Empty() bool {
+	return len(p.DiscardedCommits) == 0 && len(p.SessionBranches) == 0 && len(p.AgentBranches) == 0 &&
+		len(p.Worktrees) == 0 && len(p.ProgTasks) == 0
+}
+
+// Rollback undoes what sessions did to a repository: it removes their agent
+// worktrees and branches, deletes their session branches, cancels their
+// unfinished prog tasks and returns the repository to the commit checked
+// out before they started.
+type Rollback struct {
+	store     RollbackStore
+	git       git.Runner
+	worktrees agent.WorktreeProvider
+	// prog is optional; without it prog tasks are left alone.
+	prog prog.ProgTracker
+}
+
+// NewRollback creates a Rollback for the repository git operates on.
+// progTracker may be nil.
+func NewRollback(store RollbackStore, runner git.Runner, worktrees agent.WorktreeProvider, progTracker prog.ProgTracker) *Rollback {
+	return &Rollback{store: store, git: runner, worktrees: worktrees, prog: progTracker}
+}
+
+// Plan works out what rolling back id undoes. id is a session ID, or an
+// epic ID to roll back every session that executed the epic. Only sessions
+// that recorded their origin can be rolled back.
+func (r *Rollback) Plan(id string) (*RollbackPlan, error) {
+	plan := &RollbackPlan{}
+	var origins []state.SessionOrigin
+	origin, err := r.store.GetSessionOrigin(id)
+	if err != nil {
+		return nil, err
+	}
+	if origin != nil {
+		origins = []state.SessionOrigin{*origin}
+		plan.EpicID = origin.EpicID
+	} else {
+		if origins, err = r.store.ListSessionOriginsByEpic(id); err != nil {
+			return nil, err
+		}
+		plan.EpicID = id
+		plan.WholeEpic = true
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("no session or epic %s with a recorded origin; it may predate abort support", id)
+	}
+
+	first := origins[0]
+	plan.BaseBranch = first.BaseBranch
+	plan.BaseCommit = first.BaseCommit
+	if r.hasBaseBranch(plan) {
+		out, err := r.git.Run("rev-list", plan.BaseCommit+".."+plan.BaseBranch)
+		if err != nil {
+			return nil, fmt.Errorf("list commits on %s since %s: %w", plan.BaseBranch, shortCommit(plan.BaseCommit), err)
+		}
+		plan.DiscardedCommits = strings.Fields(out)
+	}
+
+	var taskIDs []string
+	for _, o := range origins {
+		plan.SessionIDs = append(plan.SessionIDs, o.SessionID)
+		taskIDs = append(taskIDs, o.TaskIDs...)
+		if o.SessionBranch != "" && !slices.Contains(plan.SessionBranches, o.SessionBranch) {
+			if exists, _ := r.git.BranchExists(o.SessionBranch); exists {
+				plan.SessionBranches = append(plan.SessionBranches, o.SessionBranch)
+			}
+		}
+	}
+
+	// Tasks of the epic, including ones added after its sessions started
+	links := map[string]string{}
+	if plan.EpicID != "" {
+		if links, err = r.store.GetProgLinks(plan.EpicID); err != nil {
+			return nil, err
+		}
+		if plan.WholeEpic {
+			for taskID := range links {
+				taskIDs = append(taskIDs, taskID)
+			}
+		}
+	}
+	slices.Sort(taskIDs)
+	taskIDs = slices.Compact(taskIDs)
+	for _, taskID := range taskIDs {
+		branch := "agent-" + taskID
+		if exists, _ := r.git.BranchExists(branch); exists {
+			plan.AgentBranches = append(plan.AgentBranches, branch)
+		}
+	}
+
+	worktrees, err := r.worktrees.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range worktrees {
+		if slices.Contains(plan.AgentBranches, wt.BranchName) || slices.Contains(plan.SessionBranches, wt.BranchName) {
+			plan.Worktrees = append(plan.Worktrees, wt.Path)
+		}
+	}
+
+	if r.prog != nil && plan.EpicID != "" {
+		incomplete, err := r.prog.GetIncompleteTasks(plan.EpicID)
+		if err != nil {
+			return nil, fmt.Errorf("get unfinished tasks of epic %s: %w", plan.EpicID, err)
+		}
+		for _, item := range incomplete {
+			if plan.WholeEpic || slices.ContainsFunc(taskIDs, func(taskID string) bool { return links[taskID] == item.ID }) {
+				plan.ProgTasks = append(plan.ProgTasks, item.ID)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// Apply carries out plan. Tracked files must have no uncommitted changes.
+// Commits the base branch gained since the sessions started are only
+// dropped when resetBase is set; otherwise the base branch is checked out
+// as it is. Apply carries on past individual failures and returns them
+// joined.
+func (r *Rollback) Apply(plan *RollbackPlan, resetBase bool) error {
+	// Untracked files survive the checkout and reset; changes to tracked
+	// files would be lost
+	status, err := r.git.Run("status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return fmt.Errorf("check working tree: %w", err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them before rolling back")
+	}
+
+	var errs []error
+	for _, path := range plan.Worktrees {
+		if err := r.worktrees.Remove(path, true); err != nil {
+			errs = append(errs, fmt.Errorf("remove worktree %s: %w", path, err))
+		}
+	}
+
+	if err := r.restoreBase(plan, resetBase); err != nil {
+		// Deleting the branches could delete the one checked out
+		return errors.Join(append(errs, err)...)
+	}
+
+	for _, branch := range append(slices.Clone(plan.AgentBranches), plan.SessionBranches...) {
+		if err := r.git.DeleteBranch(branch); err != nil {
+			errs = append(errs, fmt.Errorf("delete branch %s: %w", branch, err))
+		}
+	}
+	if err := r.worktrees.Prune(); err != nil {
+		pkgLog.Warn("failed to prune worktrees", logging.Err(err))
+	}
+
+	if r.prog != nil {
+		for _, id := range plan.ProgTasks {
+			if err := r.prog.AddLog(id, "Canceled: session aborted"); err != nil {
+				errs = append(errs, err)
+			}
+			if err := r.prog.UpdateStatus(id, prog.StatusCanceled); err != nil {
+				errs = append(errs, fmt.Errorf("cancel prog task %s: %w", id, err))
+			}
+		}
+		if plan.WholeEpic {
+			if err := r.prog.UpdateStatus(plan.EpicID, prog.StatusCanceled); err != nil {
+				errs = append(errs, fmt.Errorf("cancel epic %s: %w", plan.EpicID, err))
+			}
+		}
+	}
+
+	for _, id := range plan.SessionIDs {
+		session, err := r.store.GetSession(id)
+		if err != nil || session == nil {
+			continue
+		}
+		session.Status = state.SessionCanceled
+		if err := r.store.UpdateSession(session); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreBase checks out the branch the sessions started from, resetting it
+// to the commit they started from if resetBase is set.
+func (r *Rollback) restoreBase(plan *RollbackPlan, resetBase bool) error {
+	if !r.hasBaseBranch(plan) {
+		// Started on a detached HEAD
+		if _, err := r.git.Run("checkout", "--detach", plan.BaseCommit); err != nil {
+			return fmt.Errorf("check out %s: %w", shortCommit(plan.BaseCommit), err)
+		}
+		return nil
+	}
+	if err := r.git.CheckoutBranch(plan.BaseBranch); err != nil {
+		return fmt.Errorf("check out %s: %w", plan.BaseBranch, err)
+	}
+	if resetBase && len(plan.DiscardedCommits) > 0 {
+		if _, err := r.git.Run("reset", "--hard", plan.BaseCommit); err != nil {
+			return fmt.Errorf("reset %s to %s: %w", plan.BaseBranch, shortCommit(plan.BaseCommit), err)
+		}
+	}
+	return nil
+}
+
+// hasBaseBranch reports whether the sessions started on a branch that still
+// exists, rather than on a detached HEAD.
+func (r *Rollback) hasBaseBranch(plan *RollbackPlan) bool {
+	if plan.BaseBranch == "" || plan.BaseBranch == "HEAD" {
+		return false
+	}
+	exists, _ := r.git.BranchExists(plan.BaseBranch)
+	return exists
+}
+
+// shortCommit abbreviates a commit hash for messages.
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/git"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/internal/state"
+)
+
+func TestRollback_SessionAndEpic(t *testing.T) {
+	repo := t.TempDir()
+	if err := initGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	g := git.NewRunner(repo)
+	mustGit := func(args ...string) string {
+		t.Helper()
+		out, err := g.Run(args...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(out)
+	}
+	base := mustGit("rev-parse", "HEAD")
+	mainBranch := mustGit("rev-parse", "--abbrev-ref", "HEAD")
+
+	db := openEscalationDB(t)
+	client := newTestProgClient(t)
+	epicID, _ := client.CreateEpic("Build the service", nil)
+	doneTask, _ := client.CreateTask("Done", &prog.TaskOptions{ParentID: epicID})
+	openTask, _ := client.CreateTask("Open", &prog.TaskOptions{ParentID: epicID})
+	_ = client.Done(doneTask)
+	if err := db.SaveProgLinks(epicID, map[string]string{"t1": doneTask, "t2": openTask}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSession(&state.Session{ID: "s1", Status: state.SessionActive}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveSessionOrigin(&state.SessionOrigin{
+		SessionID: "s1", EpicID: epicID, SessionBranch: "session-s1",
+		BaseBranch: mainBranch, BaseCommit: base,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, taskID := range []string{"t1", "t2"} {
+		if err := db.AddSessionTask("s1", taskID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// What the session left behind: its branch, an agent worktree and
+	// work merged into the base branch
+	mustGit("branch", "session-s1")
+	worktrees, err := agent.NewWorktreeManager(filepath.Join(t.TempDir(), "worktrees"), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := worktrees.Create("t2")
+	if err != nil {
+		t.Fatalf("create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "merged.txt"), []byte("session work"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit("add", "merged.txt")
+	mustGit("commit", "-m", "Merge session s1")
+
+	rollback := NewRollback(db, g, worktrees, client)
+	if _, err := rollback.Plan("missing"); err == nil {
+		t.Error("expected an error for an unknown session")
+	}
+
+	plan, err := rollback.Plan("s1")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.WholeEpic || plan.BaseCommit != base || len(plan.DiscardedCommits) != 1 {
+		t.Errorf("plan = %+v", plan)
+	}
+	if !reflect.DeepEqual(plan.SessionBranches, []string{"session-s1"}) || !reflect.DeepEqual(plan.AgentBranches, []string{"agent-t2"}) {
+		t.Errorf("branches = %v %v", plan.SessionBranches, plan.AgentBranches)
+	}
+	if len(plan.Worktrees) != 1 || !reflect.DeepEqual(plan.ProgTasks, []string{openTask}) {
+		t.Errorf("worktrees = %v, prog tasks = %v", plan.Worktrees, plan.ProgTasks)
+	}
+
+	// Aborting the whole epic also cancels the epic
+	epicPlan, err := rollback.Plan(epicID)
+	if err != nil {
+		t.Fatalf("Plan(epic) error = %v", err)
+	}
+	if !epicPlan.WholeEpic || !reflect.DeepEqual(epicPlan.SessionIDs, []string{"s1"}) {
+		t.Errorf("epic plan = %+v", epicPlan)
+	}
+
+	if err := rollback.Apply(epicPlan, true); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if head := mustGit("rev-parse", mainBranch); head != base {
+		t.Errorf("%s at %s, want the pre-session commit %s", mainBranch, head, base)
+	}
+	for _, branch := range []string{"session-s1", "agent-t2"} {
+		if exists, _ := g.BranchExists(branch); exists {
+			t.Errorf("branch %s still exists", branch)
+		}
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Errorf("worktree %s still exists", wt.Path)
+	}
+	if item, _ := client.GetItem(openTask); item.Status != prog.StatusCanceled {
+		t.Errorf("open task status = %s, want canceled", item.Status)
+	}
+	if item, _ := client.GetItem(doneTask); item.Status != prog.StatusDone {
+		t.Errorf("done task status = %s, want done", item.Status)
+	}
+	if epic, _ := client.GetItem(epicID); epic.Status != prog.StatusCanceled {
+		t.Errorf("epic status = %s, want canceled", epic.Status)
+	}
+	if session, _ := db.GetSession("s1"); session.Status != state.SessionCanceled {
+		t.Errorf("session status = %s, want canceled", session.Status)
+	}
+}
@@ -3,7 +3,9 @@ package orchestrator
 
 import (
 	"github.com/ShayCichocki/alphie/internal/agent"
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/vcs"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -25,6 +27,14 @@ type OrchestratorRunConfig struct {
 	// Greenfield indicates if this is a new project (changes branch handling).
 	Greenfield bool
 
+	// MainBranch is the repository's main branch: the configured override
+	// or the detected default branch.
+	MainBranch string
+
+	// Operator identifies the person running the session; human decisions
+	// in the audit trail are attributed to them.
+	Operator string
+
 	// OriginalTaskID is the task ID from the TUI's task_entered event.
 	// Used to link epic_created events back to the original task for deduplication.
 	OriginalTaskID string
@@ -35,6 +45,32 @@ type OrchestratorRunConfig struct {
 
 	// Policy contains configurable policy parameters.
 	Policy *policy.Config
+
+	// Validation lists the validation layers agents run after finishing,
+	// from the tier config. Nil uses the tier's default pipeline.
+	Validation *agent.ValidationPipeline
+
+	// Sandbox runs agents' quality gate and verification commands, from
+	// the tier config. Nil runs them on the host.
+	Sandbox iexec.CommandRunner
+
+	// ContextBudgets is the prompt context budget of each tier, from the
+	// tier configs. Tiers without one use agent.DefaultContextBudgets.
+	ContextBudgets map[models.Tier]int
+
+	// KeepSession leaves the session branch in place when the session ends
+	// instead of merging or deleting it.
+	KeepSession bool
+
+	// VCS is the workspace backend. vcs.KindPlain applies task patches to
+	// RepoPath instead of merging branches; empty means git.
+	VCS vcs.Kind
+}
+
+// PlainMode reports whether the session runs in plain-directory mode, with
+// no git branches.
+func (c *OrchestratorRunConfig) PlainMode() bool {
+	return c.VCS == vcs.KindPlain
 }
 
 // NewRunConfig creates a new OrchestratorRunConfig with the given values.
@@ -4,19 +4,23 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
 // runLoop is the main execution loop that schedules, spawns, and merges work.
 func (o *Orchestrator) runLoop(ctx context.Context) error {
-	inflightTasks := make(map[string]*inflight)
-	var inflightMu sync.Mutex
+	// The in-flight tasks are shared with the control socket, which can cancel them
+	inflightMu := &o.inflightMu
+	inflightMu.Lock()
+	o.inflightTasks = make(map[string]*inflight)
+	inflightTasks := o.inflightTasks
+	inflightMu.Unlock()
 
 	// Aggregate channel for completion notifications
 	completionCh := make(chan string, o.config.MaxAgents)
@@ -31,7 +35,7 @@ func (o *Orchestrator) runLoop(ctx context.Context) error {
 			// Cancel all in-flight tasks
 			inflightMu.Lock()
 			for _, inf := range inflightTasks {
-				inf.cancelFn()
+				inf.cancelFn(nil)
 			}
 			inflightMu.Unlock()
 			return ctx.Err()
@@ -57,6 +61,8 @@ func (o *Orchestrator) runLoop(ctx context.Context) error {
 
 				if result != nil {
 					outcome := o.handleTaskCompletion(ctx, completedTask.taskID, result, completedTask.startTime)
+					o.persistTaskAttempt(outcome)
+					o.persistSecurityFindings(completedTask.taskID, result)
 					// Log outcome for debugging
 					o.logger.Log("[runLoop] task %s completed with outcome: %s", completedTask.taskID, outcome.Status.String())
 					// Note: Merge failures are logged and tracked but don't stop the session.
@@ -68,6 +74,9 @@ func (o *Orchestrator) runLoop(ctx context.Context) error {
 		case <-ticker.C:
 			// Check if we're done
 			o.logger.Log("[runLoop] checking for ready tasks...")
+			if o.scheduler.ParkedCount() > 0 {
+				o.applyResolvedEscalations()
+			}
 			ready := o.scheduler.Schedule()
 			inflightMu.Lock()
 			inflightCount := len(inflightTasks)
@@ -75,12 +84,30 @@ func (o *Orchestrator) runLoop(ctx context.Context) error {
 
 			o.logger.Log("[runLoop] Schedule() returned %d ready tasks, %d inflight", len(ready), inflightCount)
 
-			if len(ready) == 0 && inflightCount == 0 {
-				// No more tasks to schedule and none in flight - we're done
+			if len(ready) == 0 && inflightCount == 0 && !o.scheduler.HasDeferredTasks() {
+				// No more tasks to schedule and none in flight - we're done.
+				// Parked tasks resume in the session that sees their resolution.
+				if parked := o.scheduler.ParkedCount(); parked > 0 {
+					o.log.Info("tasks parked awaiting escalation (alphie escalations)", "parked", parked)
+				}
 				o.logger.Log("[runLoop] EXITING: no ready tasks and no inflight tasks")
 				return nil
 			}
 
+			// Shutting down: start nothing new, let running agents finish
+			if o.IsDraining() {
+				if o.drainStep(inflightTasks, inflightMu) {
+					o.logger.Log("[runLoop] EXITING: session drained for shutdown")
+					return errSessionDrained
+				}
+				continue
+			}
+
+			// All slots taken: make room for urgent tasks if preemption is enabled
+			if len(ready) == 0 && inflightCount > 0 && o.preemptForUrgentTasks(inflightTasks, inflightMu) > 0 {
+				continue
+			}
+
 			if len(ready) == 0 {
 				// Nothing to schedule, wait for completions
 				select {
@@ -95,13 +122,27 @@ func (o *Orchestrator) runLoop(ctx context.Context) error {
 				continue
 			}
 
+			// Consult the budget before spawning - once it is exceeded nothing new
+			// starts, running agents finish and their completions are still
+			// handled, then the run ends.
+			if o.enforceSessionBudget() {
+				if inflightCount == 0 {
+					o.logger.Log("[runLoop] EXITING: session budget exceeded")
+					return o.budget.SessionError()
+				}
+				continue
+			}
+
 			// Check if paused - wait until resumed before spawning new agents
 			if err := o.pauseCtrl.WaitIfPaused(ctx); err != nil {
 				return err
 			}
+			if o.IsDraining() {
+				continue
+			}
 
 			// Spawn agents for ready tasks
-			if err := o.spawnAgents(ctx, ready, inflightTasks, &inflightMu, completionCh); err != nil {
+			if err := o.spawnAgents(ctx, ready, inflightTasks, inflightMu, completionCh); err != nil {
 				return err
 			}
 		}
@@ -114,7 +155,7 @@ type inflight struct {
 	agentID   string
 	startTime time.Time
 	doneCh    chan *agent.ExecutionResult
-	cancelFn  context.CancelFunc
+	cancelFn  context.CancelCauseFunc
 }
 
 // spawnAgents spawns agents for the given ready tasks using the AgentSpawner.
@@ -145,6 +186,11 @@ func (o *Orchestrator) spawnAgents(ctx context.Context, ready []*models.Task, in
 			Timestamp: time.Now(),
 		})
 
+		// Enforce the protected-area policy on the task's declared files
+		if !o.checkSchedulingPolicy(task) {
+			continue
+		}
+
 		// Check for protected areas
 		isProtected := o.protected.IsProtected(task.Description) || o.protected.IsProtected(task.Title)
 		if isProtected {
@@ -152,7 +198,13 @@ func (o *Orchestrator) spawnAgents(ctx context.Context, ready []*models.Task, in
 			// For other tiers, they can already ask questions, so proceed
 			if o.config.Tier == models.TierScout {
 				o.overrideGate.SetProtectedArea(task.ID, true)
-				log.Printf("[orchestrator] task %s touches protected area, Scout can now ask questions", task.ID)
+				o.log.Info("task touches protected area, Scout can now ask questions", logging.Task(task.ID))
+				o.recordDecision(Decision{
+					Kind:   DecisionOverride,
+					Actor:  ActorAlphie,
+					TaskID: task.ID,
+					Reason: "Scout question limit lifted: task touches a protected area",
+				})
 			}
 		}
 
@@ -161,15 +213,15 @@ func (o *Orchestrator) spawnAgents(ctx context.Context, ready []*models.Task, in
 		if o.learnings != nil {
 			learnings, err := o.learnings.OnTaskStart(task.Description, nil)
 			if err != nil {
-				log.Printf("[orchestrator] warning: failed to retrieve learnings for task %s: %v", task.ID, err)
+				o.log.Warn("failed to retrieve learnings", logging.Task(task.ID), logging.Err(err))
 			} else if len(learnings) > 0 {
 				taskLearnings = learnings
-				log.Printf("[orchestrator] retrieved %d learnings for task %s", len(learnings), task.ID)
+				o.log.Debug("retrieved learnings", logging.Task(task.ID), "learnings", len(learnings))
 			}
 		}
 
 		// Create agent context
-		taskCtx, taskCancel := context.WithCancel(ctx)
+		taskCtx, taskCancel := context.WithCancelCause(ctx)
 
 		// Get structure rules for this task
 		var structureRules interface{}
@@ -177,13 +229,31 @@ func (o *Orchestrator) spawnAgents(ctx context.Context, ready []*models.Task, in
 			structureRules = o.structureAnalyzer.GetRules()
 		}
 
+		tier, model := o.taskTier(task)
+		questionsAllowed := QuestionsAllowed(tier, o.overrideGate, task.ID)
 		agentID, resultCh := o.spawner.Spawn(taskCtx, task, SpawnOptions{
-			Tier:           o.config.Tier,
-			Learnings:      taskLearnings,
-			Baseline:       o.config.Baseline,
-			WorkersRunning: workersRunning + i + 1,
-			WorkersBlocked: 0,
-			StructureRules: structureRules,
+			Tier:             tier,
+			Model:            model,
+			Learnings:        taskLearnings,
+			Baseline:         o.config.Baseline,
+			WorkersRunning:   workersRunning + i + 1,
+			WorkersBlocked:   0,
+			StructureRules:   structureRules,
+			Validation:       o.config.Validation,
+			TaskHistory:      o.taskHistory(task),
+			ProtectedAreas:   o.protected,
+			Sandbox:          o.config.Sandbox,
+			SessionID:        o.config.SessionID,
+			ContextBudget:    o.config.ContextBudgets[tier],
+			StallTimeout:     o.config.Policy.Heartbeat.StallTimeout,
+			QuestionsAllowed: questionsAllowed,
+			QuestionsAsked:   o.questions.Asked(task.ID),
+			OnQuestion: func(agentID, question string) {
+				o.recordQuestion(task, agentID, questionsAllowed, question)
+			},
+			OnProgress: func(report ProgressReport) {
+				o.recordTaskSpend(task, report.Cost, true)
+			},
 		})
 
 		// Create agent model for state persistence
@@ -225,6 +295,7 @@ func (o *Orchestrator) spawnAgents(ctx context.Context, ready []*models.Task, in
 
 			// Wait for spawner result
 			result := <-resultCh
+			inf.doneCh <- result.Result
 
 			// Store result in registry
 			o.registry.StoreResult(aID, result.Result)
@@ -7,8 +7,10 @@ import (
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -26,9 +28,22 @@ type Scheduler struct {
 	maxAgents int
 	// collision is the collision checker for avoiding file conflicts.
 	collision *CollisionChecker
+	// resourceRules assign resource locks to tasks from configured keywords.
+	resourceRules []policy.ResourceRule
+	// leases grants running tasks exclusive ownership of their file boundaries.
+	leases *LeaseManager
+	// predictor serializes tasks predicted to conflict.
+	predictor *ConflictPredictor
+	// preemptPriority is the least urgent priority allowed to preempt running
+	// agents when all slots are taken. 0 disables preemption.
+	preemptPriority int
 	// greenfield indicates whether this is a greenfield project.
 	// In greenfield mode, tasks that might touch root files are serialized.
 	greenfield bool
+	// retryAfter maps task IDs to the earliest time they may be retried.
+	retryAfter map[string]time.Time
+	// parked holds escalated tasks that wait for a human resolution.
+	parked map[string]bool
 	// orchestrator is a reference to the parent orchestrator for conflict checking.
 	orchestrator *Orchestrator
 	// trigger is a channel to signal the scheduler to check for work.
@@ -40,11 +55,13 @@ type Scheduler struct {
 // NewScheduler creates a new Scheduler with the given dependency graph, tier, and max agents limit.
 func NewScheduler(graph *graph.DependencyGraph, tier models.Tier, maxAgents int) *Scheduler {
 	return &Scheduler{
-		graph:     graph,
-		tier:      tier,
-		running:   make(map[string]*models.Agent),
-		maxAgents: maxAgents,
-		trigger:   make(chan struct{}, 1),
+		graph:      graph,
+		tier:       tier,
+		running:    make(map[string]*models.Agent),
+		retryAfter: make(map[string]time.Time),
+		parked:     make(map[string]bool),
+		maxAgents:  maxAgents,
+		trigger:    make(chan struct{}, 1),
 	}
 }
 
@@ -63,6 +80,22 @@ func (s *Scheduler) SetCollisionChecker(cc *CollisionChecker) {
 	s.collision = cc
 }
 
+// SetLeaseManager sets the lease manager used to grant tasks exclusive
+// ownership of their file boundaries. If not set, leasing is disabled.
+func (s *Scheduler) SetLeaseManager(lm *LeaseManager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leases = lm
+}
+
+// SetConflictPredictor sets the predictor used to serialize tasks likely to
+// conflict. If not set, conflict prediction is disabled.
+func (s *Scheduler) SetConflictPredictor(p *ConflictPredictor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.predictor = p
+}
+
 // SetGreenfield enables greenfield mode, which serializes tasks that might touch root files.
 func (s *Scheduler) SetGreenfield(greenfield bool) {
 	s.mu.Lock()
@@ -70,11 +103,94 @@ func (s *Scheduler) SetGreenfield(greenfield bool) {
 	s.greenfield = greenfield
 }
 
-// Schedule returns a slice of tasks that are ready to be scheduled.
-// It considers:
+// SetResourceRules sets the configured rules that assign resource locks to tasks.
+// Locks annotated directly on tasks are honored regardless of rules.
+func (s *Scheduler) SetResourceRules(rules []policy.ResourceRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resourceRules = rules
+}
+
+// SetPreemption lets ready tasks at or above priority (1=high, 3=low)
+// preempt running agents working on less urgent tasks when all agent slots
+// are taken. A priority of 0 disables preemption.
+func (s *Scheduler) SetPreemption(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preemptPriority = priority
+}
+
+// SetMaxAgents changes how many agents may run at once. Lowering it stops
+// new tasks from being scheduled until running agents finish; agents already
+// running are not stopped.
+func (s *Scheduler) SetMaxAgents(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxAgents = n
+}
+
+// MaxAgents returns how many agents may run at once.
+func (s *Scheduler) MaxAgents() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxAgents
+}
+
+// DeferTask holds a task back from scheduling until the given time.
+// Used to back off before retrying a failed task.
+func (s *Scheduler) DeferTask(taskID string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retryAfter[taskID] = until
+}
+
+// HasDeferredTasks returns true if any task is waiting out a retry backoff.
+// The run loop must not exit while retries are pending.
+func (s *Scheduler) HasDeferredTasks() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	for _, until := range s.retryAfter {
+		if now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// Park holds a task back from scheduling until Unpark is called, for tasks
+// escalated to a human.
+func (s *Scheduler) Park(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.parked[taskID] = true
+}
+
+// Unpark makes a parked task schedulable again.
+func (s *Scheduler) Unpark(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.parked, taskID)
+}
+
+// ParkedCount returns how many tasks are parked. Parked tasks do not keep
+// the run loop alive: a session ends with them still waiting.
+func (s *Scheduler) ParkedCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.parked)
+}
+
+// Schedule returns a slice of tasks that are ready to be scheduled, most
+// urgent priority first. It considers:
 // - Tasks with no unmet dependencies (from the graph)
 // - Available agent slots (maxAgents - running count)
 // - Collision avoidance rules (if a collision checker is set)
+// - Resource locks held by running tasks or claimed earlier in the batch
+// - File leases held by running tasks or claimed earlier in the batch
+// - Predicted conflicts with running tasks or tasks earlier in the batch
+// - Retry backoff for tasks whose previous attempt failed
+// - Tasks parked awaiting a human resolution
 // - Merge conflict blocking (if orchestrator has active conflict)
 func (s *Scheduler) Schedule() []*models.Task {
 	s.mu.RLock()
@@ -102,31 +218,15 @@ func (s *Scheduler) Schedule() []*models.Task {
 		return nil
 	}
 
-	// Filter out tasks that are already being worked on.
-	var candidates []*models.Task
-	for _, id := range readyIDs {
-		// Check if this task is already assigned to a running agent.
-		alreadyRunning := false
-		for _, agent := range s.running {
-			if agent.TaskID == id {
-				alreadyRunning = true
-				break
-			}
-		}
-		if alreadyRunning {
-			continue
-		}
-
-		task := s.graph.GetTask(id)
-		if task != nil {
-			candidates = append(candidates, task)
-		}
-	}
-
+	candidates := s.readyCandidatesLocked(readyIDs)
 	if len(candidates) == 0 {
 		return nil
 	}
 
+	// Consider urgent tasks first, so they claim critical files, locks and
+	// leases in this batch before less urgent ones.
+	sortByPriority(candidates)
+
 	// Get running agents for collision and SETUP checks.
 	runningAgents := s.getRunningAgentsLocked()
 
@@ -151,6 +251,15 @@ func (s *Scheduler) Schedule() []*models.Task {
 	schedulingCriticalFiles := make(map[string]bool)
 	// Track skip reasons for logging
 	skipReasons := make(map[string]string)
+	// Track resource locks held by running agents and claimed in this batch
+	heldLocks := make(map[string]bool)
+	for _, agent := range runningAgents {
+		for _, lock := range ResolveResourceLocks(s.graph.GetTask(agent.TaskID), s.resourceRules) {
+			heldLocks[lock] = true
+		}
+	}
+	// Track file leases claimed by tasks earlier in this batch
+	batchLeases := NewLeaseManager()
 
 	for _, task := range candidates {
 		// Layer 1: Skip SETUP tasks if one is already running.
@@ -227,6 +336,44 @@ func (s *Scheduler) Schedule() []*models.Task {
 			}
 		}
 
+		// Layer 5: Resource locks - tasks sharing a named resource are serialized.
+		taskLocks := ResolveResourceLocks(task, s.resourceRules)
+		if lock := resourceLockConflict(taskLocks, heldLocks); lock != "" {
+			debugLog("[scheduler] Layer 5: Skipping task %s (%s) - resource lock %q is held", task.ID, task.Title, lock)
+			skipReasons[task.ID] = fmt.Sprintf("Layer 5: Resource lock %s held", lock)
+			continue
+		}
+
+		// Layer 6: File leases - tasks whose file boundaries overlap are serialized.
+		var leasePaths []string
+		if s.leases != nil {
+			leasePaths = LeasePaths(task)
+			lease := s.leases.Conflict(task.ID, leasePaths)
+			if lease == nil {
+				lease = batchLeases.Conflict(task.ID, leasePaths)
+			}
+			if lease != nil {
+				debugLog("[scheduler] Layer 6: Skipping task %s (%s) - %q is leased to task %s", task.ID, task.Title, lease.Path, lease.TaskID)
+				skipReasons[task.ID] = fmt.Sprintf("Layer 6: %s leased to task %s", lease.Path, lease.TaskID)
+				continue
+			}
+		}
+
+		// Layer 7: Predicted conflicts - tasks likely to conflict are serialized.
+		if other, prediction := s.predictedConflictLocked(task, runningAgents, schedulable); other != nil {
+			debugLog("[scheduler] Layer 7: Skipping task %s (%s) - predicted to conflict with task %s (p=%.2f): %s",
+				task.ID, task.Title, other.ID, prediction.Probability, prediction.Reason)
+			skipReasons[task.ID] = fmt.Sprintf("Layer 7: Predicted conflict with task %s (p=%.2f)", other.ID, prediction.Probability)
+			continue
+		}
+
+		for _, lock := range taskLocks {
+			heldLocks[lock] = true
+		}
+		if len(leasePaths) > 0 {
+			batchLeases.Acquire(task.ID, "", leasePaths)
+		}
+
 		schedulable = append(schedulable, task)
 	}
 
@@ -239,18 +386,14 @@ func (s *Scheduler) Schedule() []*models.Task {
 	}
 	debugLog("[scheduler] Scheduled %d tasks for execution (max parallelism: %d)", len(schedulable), availableSlots)
 
-	sort.SliceStable(schedulable, func(i, j int) bool {
-		return extractMilestoneNumber(schedulable[i]) < extractMilestoneNumber(schedulable[j])
-	})
-
 	if len(schedulable) > 0 {
-		debugLog("[scheduler] Sorted %d tasks by milestone:", len(schedulable))
+		debugLog("[scheduler] Sorted %d tasks by priority and milestone:", len(schedulable))
 		for _, task := range schedulable {
 			milestone := extractMilestoneNumber(task)
 			if milestone == math.MaxInt {
-				debugLog("[scheduler]   - %s (%s) [no milestone]", task.ID, task.Title)
+				debugLog("[scheduler]   - %s (%s) [P%d, no milestone]", task.ID, task.Title, task.EffectivePriority())
 			} else {
-				debugLog("[scheduler]   - %s (%s) [M%d]", task.ID, task.Title, milestone)
+				debugLog("[scheduler]   - %s (%s) [P%d, M%d]", task.ID, task.Title, task.EffectivePriority(), milestone)
 			}
 		}
 	}
@@ -262,6 +405,213 @@ func (s *Scheduler) Schedule() []*models.Task {
 	return schedulable
 }
 
+// predictedConflictLocked returns the first running or batched task that
+// task is predicted to conflict with, and the prediction, or nil if task may
+// run alongside all of them.
+// Caller must hold s.mu.
+func (s *Scheduler) predictedConflictLocked(task *models.Task, runningAgents []*models.Agent, batch []*models.Task) (*models.Task, *ConflictPrediction) {
+	if s.predictor == nil {
+		return nil, nil
+	}
+	others := make([]*models.Task, 0, len(runningAgents)+len(batch))
+	for _, agent := range runningAgents {
+		if other := s.graph.GetTask(agent.TaskID); other != nil {
+			others = append(others, other)
+		}
+	}
+	others = append(others, batch...)
+
+	for _, other := range others {
+		if prediction, serialize := s.predictor.ShouldSerialize(task, other); serialize {
+			return other, prediction
+		}
+	}
+	return nil, nil
+}
+
+// readyCandidatesLocked returns the tasks among readyIDs that are not
+// already running or backing off after a failed attempt.
+// Caller must hold s.mu.
+func (s *Scheduler) readyCandidatesLocked(readyIDs []string) []*models.Task {
+	now := time.Now()
+	var candidates []*models.Task
+	for _, id := range readyIDs {
+		// Check if this task is already assigned to a running agent.
+		alreadyRunning := false
+		for _, agent := range s.running {
+			if agent.TaskID == id {
+				alreadyRunning = true
+				break
+			}
+		}
+		if alreadyRunning {
+			continue
+		}
+
+		// Skip tasks escalated to a human.
+		if s.parked[id] {
+			debugLog("[scheduler] Skipping task %s - parked awaiting escalation resolution", id)
+			continue
+		}
+
+		// Skip tasks still backing off after a failed attempt.
+		if until, ok := s.retryAfter[id]; ok && now.Before(until) {
+			debugLog("[scheduler] Skipping task %s - retry backoff until %s", id, until.Format(time.RFC3339))
+			continue
+		}
+
+		task := s.graph.GetTask(id)
+		if task != nil {
+			candidates = append(candidates, task)
+		}
+	}
+	return candidates
+}
+
+// sortByPriority orders tasks by priority, most urgent first, then by
+// milestone number.
+func sortByPriority(tasks []*models.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		pi, pj := tasks[i].EffectivePriority(), tasks[j].EffectivePriority()
+		if pi != pj {
+			return pi < pj
+		}
+		return extractMilestoneNumber(tasks[i]) < extractMilestoneNumber(tasks[j])
+	})
+}
+
+// Preemption pairs a running agent with the more urgent task that should
+// take its slot.
+type Preemption struct {
+	// AgentID is the running agent to stop.
+	AgentID string
+	// TaskID is the task the agent is working on; it is requeued.
+	TaskID string
+	// ForTaskID is the urgent task waiting for a slot.
+	ForTaskID string
+}
+
+// Preemptions returns the running agents to stop so that ready tasks at or
+// above the preemption priority can run. It returns nil unless preemption is
+// enabled and every agent slot is taken. Each urgent task claims at most one
+// agent working on a less urgent task, preferring the least urgent and then
+// the most recently started, so the least work is thrown away. Agents whose
+// slot would not let the urgent task run, because it also conflicts with
+// another running task, are left alone.
+func (s *Scheduler) Preemptions() []Preemption {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.preemptPriority <= 0 || len(s.running) < s.maxAgents {
+		return nil
+	}
+	if s.orchestrator != nil && s.orchestrator.HasMergeConflict() {
+		return nil
+	}
+
+	var urgent []*models.Task
+	for _, task := range s.readyCandidatesLocked(s.graph.GetReady()) {
+		if task.EffectivePriority() <= s.preemptPriority {
+			urgent = append(urgent, task)
+		}
+	}
+	if len(urgent) == 0 {
+		return nil
+	}
+	sortByPriority(urgent)
+
+	victims := s.getRunningAgentsLocked()
+	sort.SliceStable(victims, func(i, j int) bool {
+		pi, pj := s.agentPriorityLocked(victims[i]), s.agentPriorityLocked(victims[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return victims[i].StartedAt.After(victims[j].StartedAt)
+	})
+
+	var preemptions []Preemption
+	taken := make(map[string]bool)
+	for _, task := range urgent {
+		for _, victim := range victims {
+			if taken[victim.ID] || s.agentPriorityLocked(victim) <= task.EffectivePriority() {
+				continue
+			}
+			if s.blockedWithoutLocked(task, victim) {
+				continue
+			}
+			taken[victim.ID] = true
+			preemptions = append(preemptions, Preemption{AgentID: victim.ID, TaskID: victim.TaskID, ForTaskID: task.ID})
+			debugLog("[scheduler] preempting agent %s (task %s, P%d) for task %s (P%d)",
+				victim.ID, victim.TaskID, s.agentPriorityLocked(victim), task.ID, task.EffectivePriority())
+			break
+		}
+	}
+	return preemptions
+}
+
+// agentPriorityLocked returns the priority of the task an agent is working on.
+// Caller must hold s.mu.
+func (s *Scheduler) agentPriorityLocked(agent *models.Agent) int {
+	if task := s.graph.GetTask(agent.TaskID); task != nil {
+		return task.EffectivePriority()
+	}
+	return models.PriorityLow
+}
+
+// blockedWithoutLocked reports whether task would still be held back by the
+// other running agents if victim stopped.
+// Caller must hold s.mu.
+func (s *Scheduler) blockedWithoutLocked(task *models.Task, victim *models.Agent) bool {
+	var others []*models.Agent
+	for _, agent := range s.running {
+		if agent.ID != victim.ID {
+			others = append(others, agent)
+		}
+	}
+
+	heldLocks := make(map[string]bool)
+	for _, agent := range others {
+		other := s.graph.GetTask(agent.TaskID)
+		if task.TaskType == models.TaskTypeSetup && other != nil && other.TaskType == models.TaskTypeSetup {
+			return true
+		}
+		for _, lock := range ResolveResourceLocks(other, s.resourceRules) {
+			heldLocks[lock] = true
+		}
+	}
+	if resourceLockConflict(ResolveResourceLocks(task, s.resourceRules), heldLocks) != "" {
+		return true
+	}
+
+	if s.collision != nil {
+		if s.collision.HasCriticalFileConflict(task, others, s.graph) || !s.collision.CanSchedule(task, others) {
+			return true
+		}
+		if s.greenfield && s.collision.HasRootTouchingConflict(task, others, s.graph) {
+			return true
+		}
+	}
+
+	if other, _ := s.predictedConflictLocked(task, others, nil); other != nil {
+		return true
+	}
+
+	if s.leases != nil {
+		paths := LeasePaths(task)
+		for _, lease := range s.leases.GetLeases() {
+			if lease.TaskID == task.ID || lease.TaskID == victim.TaskID {
+				continue
+			}
+			for _, path := range paths {
+				if leasePathsOverlap(lease.Path, path) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // OnAgentStart records that an agent has started working on a task.
 func (s *Scheduler) OnAgentStart(agent *models.Agent) {
 	s.mu.Lock()
@@ -269,6 +619,13 @@ func (s *Scheduler) OnAgentStart(agent *models.Agent) {
 	debugLog("[scheduler.OnAgentStart] registering agent %s for task %s", agent.ID, agent.TaskID)
 	s.running[agent.ID] = agent
 	debugLog("[scheduler.OnAgentStart] running map now has %d agents", len(s.running))
+
+	if s.leases != nil {
+		if lease, ok := s.leases.Acquire(agent.TaskID, agent.ID, LeasePaths(s.graph.GetTask(agent.TaskID))); !ok {
+			// Schedule checks leases first, so this only happens for tasks started outside it
+			debugLog("[scheduler.OnAgentStart] task %s started while %q is leased to task %s", agent.TaskID, lease.Path, lease.TaskID)
+		}
+	}
 }
 
 // OnAgentComplete handles the completion of an agent.
@@ -297,11 +654,33 @@ func (s *Scheduler) OnAgentComplete(agentID string, success bool) {
 		s.markDependentsBlocked(agent.TaskID)
 	}
 
-	// Remove from running agents.
+	// Remove from running agents and release the task's file leases.
 	delete(s.running, agentID)
+	if s.leases != nil {
+		s.leases.Release(agent.TaskID)
+	}
 	debugLog("[scheduler.OnAgentComplete] removed agent %s from running map, now have %d agents", agentID, len(s.running))
 }
 
+// OnAgentPreempted removes a preempted agent from the running map and
+// releases its task's file leases. The task is neither completed nor failed:
+// its dependents are left alone and it becomes ready to schedule again.
+func (s *Scheduler) OnAgentPreempted(agentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.running[agentID]
+	if !ok {
+		debugLog("[scheduler.OnAgentPreempted] agent %s NOT FOUND in running map", agentID)
+		return
+	}
+	delete(s.running, agentID)
+	if s.leases != nil {
+		s.leases.Release(agent.TaskID)
+	}
+	debugLog("[scheduler.OnAgentPreempted] requeued task %s from agent %s, now have %d agents", agent.TaskID, agentID, len(s.running))
+}
+
 // GetRunningAgents returns a slice of all currently running agents.
 func (s *Scheduler) GetRunningAgents() []*models.Agent {
 	s.mu.RLock()
@@ -319,6 +698,16 @@ func (s *Scheduler) getRunningAgentsLocked() []*models.Agent {
 	return agents
 }
 
+// GetLeases returns the file leases currently held by running tasks.
+func (s *Scheduler) GetLeases() []FileLease {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.leases == nil {
+		return nil
+	}
+	return s.leases.GetLeases()
+}
+
 // GetRunningCount returns the number of currently running agents.
 func (s *Scheduler) GetRunningCount() int {
 	s.mu.RLock()
@@ -2,8 +2,10 @@ package orchestrator
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -377,3 +379,174 @@ func TestSchedulerWithNoCollisionChecker(t *testing.T) {
 		t.Errorf("expected 2 ready tasks (no collision checker), got %d", len(ready))
 	}
 }
+
+func TestSchedulerResourceLocksSerializeBatch(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "task-1", Title: "Add users table", Status: models.TaskStatusPending, ResourceLocks: []string{"db:schema"}},
+		{ID: "task-2", Title: "Add orders table", Status: models.TaskStatusPending, ResourceLocks: []string{"db:schema"}},
+		{ID: "task-3", Title: "Update README", Status: models.TaskStatusPending},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	ready := scheduler.Schedule()
+	if len(ready) != 2 {
+		t.Fatalf("expected 2 ready tasks (one db:schema holder + README), got %d", len(ready))
+	}
+	// Either migration may win the lock, but only one may run
+	holders := 0
+	for _, task := range ready {
+		if task.ID == "task-1" || task.ID == "task-2" {
+			holders++
+		}
+	}
+	if holders != 1 {
+		t.Errorf("expected exactly one db:schema holder to be scheduled, got %d", holders)
+	}
+}
+
+func TestSchedulerResourceLocksHeldByRunningAgent(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "task-1", Title: "Run migration for users", Status: models.TaskStatusPending},
+		{ID: "task-2", Title: "Run migration for orders", Status: models.TaskStatusPending},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	scheduler.SetResourceRules([]policy.ResourceRule{
+		{Resource: "db:schema", Patterns: []string{"migration"}},
+	})
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-1", TaskID: "task-1", Status: models.AgentStatusRunning})
+
+	ready := scheduler.Schedule()
+	if len(ready) != 0 {
+		t.Errorf("expected no tasks while db:schema is held, got %d", len(ready))
+	}
+
+	scheduler.OnAgentComplete("agent-1", true)
+	ready = scheduler.Schedule()
+	if len(ready) != 1 || ready[0].ID != "task-2" {
+		t.Errorf("expected task-2 once the lock is released, got %v", ready)
+	}
+}
+
+func TestSchedulerDeferTask(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "task-1", Title: "Flaky task", Status: models.TaskStatusPending},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	scheduler.DeferTask("task-1", time.Now().Add(time.Hour))
+
+	if ready := scheduler.Schedule(); len(ready) != 0 {
+		t.Errorf("expected deferred task to be held back, got %d ready", len(ready))
+	}
+	if !scheduler.HasDeferredTasks() {
+		t.Error("expected HasDeferredTasks to report the pending retry")
+	}
+
+	scheduler.DeferTask("task-1", time.Now().Add(-time.Second))
+	if ready := scheduler.Schedule(); len(ready) != 1 {
+		t.Errorf("expected task once backoff elapsed, got %d ready", len(ready))
+	}
+	if scheduler.HasDeferredTasks() {
+		t.Error("expected no pending retries once backoff elapsed")
+	}
+}
+
+func TestSchedulerPriorityOrder(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "low", Title: "M1: Polish", Status: models.TaskStatusPending, Priority: models.PriorityLow},
+		{ID: "default", Title: "M1: Build", Status: models.TaskStatusPending},
+		{ID: "high", Title: "M2: Fix missing feature", Status: models.TaskStatusPending, Priority: models.PriorityHigh},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 4)
+	ready := scheduler.Schedule()
+	if got := taskIDs(ready); len(got) != 3 || got[0] != "high" || got[1] != "default" || got[2] != "low" {
+		t.Errorf("expected tasks in priority order [high default low], got %v", got)
+	}
+
+	scheduler = NewScheduler(g, models.TierBuilder, 1)
+	if got := taskIDs(scheduler.Schedule()); len(got) != 1 || got[0] != "high" {
+		t.Errorf("expected the single slot to go to the high priority task, got %v", got)
+	}
+}
+
+func TestSchedulerPreemptions(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "low", Title: "Low", Status: models.TaskStatusPending, Priority: models.PriorityLow},
+		{ID: "medium", Title: "Medium", Status: models.TaskStatusPending},
+		{ID: "critical", Title: "Critical gap fix", Status: models.TaskStatusPending, Priority: models.PriorityHigh},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 2)
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-low", TaskID: "low", StartedAt: time.Now()})
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-medium", TaskID: "medium", StartedAt: time.Now()})
+
+	if p := scheduler.Preemptions(); len(p) != 0 {
+		t.Fatalf("expected no preemptions while disabled, got %v", p)
+	}
+
+	scheduler.SetPreemption(models.PriorityHigh)
+	p := scheduler.Preemptions()
+	if len(p) != 1 {
+		t.Fatalf("expected 1 preemption, got %v", p)
+	}
+	if p[0].AgentID != "agent-low" || p[0].TaskID != "low" || p[0].ForTaskID != "critical" {
+		t.Errorf("expected the low priority agent to be preempted for the critical task, got %+v", p[0])
+	}
+
+	scheduler.OnAgentPreempted("agent-low")
+	if scheduler.GetRunningCount() != 1 {
+		t.Errorf("expected 1 running agent after preemption, got %d", scheduler.GetRunningCount())
+	}
+	if task := g.GetTask("low"); task.Status == models.TaskStatusBlocked {
+		t.Error("preempted task should not be blocked")
+	}
+	if got := taskIDs(scheduler.Schedule()); len(got) != 1 || got[0] != "critical" {
+		t.Errorf("expected the freed slot to go to the critical task, got %v", got)
+	}
+}
+
+func TestSchedulerPreemptionsSkipsBlockedTask(t *testing.T) {
+	g := graph.New()
+	tasks := []*models.Task{
+		{ID: "low", Title: "Low", Status: models.TaskStatusPending, Priority: models.PriorityLow, FileBoundaries: []string{"docs/"}},
+		{ID: "medium", Title: "Medium", Status: models.TaskStatusPending, FileBoundaries: []string{"internal/api/"}},
+		{ID: "critical", Title: "Critical", Status: models.TaskStatusPending, Priority: models.PriorityHigh, FileBoundaries: []string{"internal/api/handler.go"}},
+	}
+	if err := g.Build(tasks); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	scheduler := NewScheduler(g, models.TierBuilder, 2)
+	scheduler.SetLeaseManager(NewLeaseManager())
+	scheduler.SetPreemption(models.PriorityHigh)
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-low", TaskID: "low", StartedAt: time.Now()})
+	scheduler.OnAgentStart(&models.Agent{ID: "agent-medium", TaskID: "medium", StartedAt: time.Now()})
+
+	// Stopping the low priority agent would not help: the medium task leases internal/api/
+	p := scheduler.Preemptions()
+	if len(p) != 1 || p[0].AgentID != "agent-medium" {
+		t.Errorf("expected only the agent holding the conflicting lease to be preempted, got %v", p)
+	}
+}
@@ -4,7 +4,10 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
@@ -18,6 +21,9 @@ type ReviewTrigger struct {
 	Triggered bool
 	// Reasons lists why the second review was triggered.
 	Reasons []string
+	// Types lists the trigger types (policy.Trigger*) that fired, used to
+	// select reviewers.
+	Types []string
 }
 
 // SecondReviewResult contains the outcome of a second review.
@@ -28,6 +34,76 @@ type SecondReviewResult struct {
 	Concerns []string
 	// ReviewerOutput contains the raw output from the reviewer.
 	ReviewerOutput string
+	// Findings are the concerns in structured form.
+	Findings []ReviewFinding
+	// Verdicts are the reviewer's verdicts on each rubric criterion, in
+	// rubric order. Empty unless the policy configures a rubric.
+	Verdicts []CriterionVerdict
+}
+
+// ReviewFinding is a concern raised by a reviewer, with the location and
+// severity it cites when it cites them.
+type ReviewFinding struct {
+	// Reviewer names the reviewer that raised the concern.
+	Reviewer string
+	// Criterion is the rubric criterion the concern fails, if the review
+	// used a rubric.
+	Criterion string
+	// Severity is the bracketed severity the concern starts with, if any.
+	Severity string
+	// File and Line locate the concern, if it cites a location.
+	File string
+	Line int
+	// Message is the concern without its severity.
+	Message string
+}
+
+// ReviewVote is one panel reviewer's verdict.
+type ReviewVote struct {
+	// Reviewer names the reviewer.
+	Reviewer string
+	// Result is the reviewer's review, or nil if it could not run.
+	Result *SecondReviewResult
+	// Err is why the review could not run. A failed review does not approve.
+	Err error
+}
+
+// PanelReviewResult is the outcome of a review by the reviewer panel.
+type PanelReviewResult struct {
+	// Approved indicates whether at least Quorum reviewers approved.
+	Approved bool
+	// Approvals is how many reviewers approved.
+	Approvals int
+	// Quorum is how many approvals were needed.
+	Quorum int
+	// Votes holds each selected reviewer's verdict, in panel order.
+	Votes []ReviewVote
+}
+
+// Concerns returns every reviewer's concerns, prefixed with the reviewer.
+func (p *PanelReviewResult) Concerns() []string {
+	var concerns []string
+	for _, v := range p.Votes {
+		if v.Err != nil {
+			concerns = append(concerns, fmt.Sprintf("%s: review failed: %v", v.Reviewer, v.Err))
+			continue
+		}
+		for _, c := range v.Result.Concerns {
+			concerns = append(concerns, v.Reviewer+": "+c)
+		}
+	}
+	return concerns
+}
+
+// Findings returns every reviewer's findings.
+func (p *PanelReviewResult) Findings() []ReviewFinding {
+	var findings []ReviewFinding
+	for _, v := range p.Votes {
+		if v.Result != nil {
+			findings = append(findings, v.Result.Findings...)
+		}
+	}
+	return findings
 }
 
 // SecondReviewer triggers and coordinates second agent reviews for high-risk diffs.
@@ -36,8 +112,11 @@ type SecondReviewer struct {
 	// claude is the Claude runner for reviews.
 	// Can be either subprocess (ClaudeProcess) or direct API (ClaudeAPIAdapter).
 	claude agent.ClaudeRunner
-	// policy contains configurable review thresholds.
+	// policy contains configurable review thresholds and the reviewer panel.
 	policy *policy.ReviewPolicy
+	// factory gives each panel reviewer its own runner. Without it only
+	// claude is available, so only one reviewer can vote.
+	factory agent.ClaudeRunnerFactory
 }
 
 // NewSecondReviewer creates a new SecondReviewer with the given dependencies.
@@ -57,6 +136,11 @@ func NewSecondReviewerWithPolicy(protected *protect.Detector, claude agent.Claud
 	}
 }
 
+// SetRunnerFactory lets each panel reviewer run on its own runner.
+func (r *SecondReviewer) SetRunnerFactory(f agent.ClaudeRunnerFactory) {
+	r.factory = f
+}
+
 // ShouldSecondReview determines whether a diff requires a second review.
 // It checks multiple conditions and returns a trigger with reasons if ANY condition is met.
 //
@@ -74,24 +158,28 @@ func (r *SecondReviewer) ShouldSecondReview(diff string, changedFiles []string,
 	// Check for protected areas
 	if r.touchesProtectedAreas(changedFiles) {
 		trigger.Triggered = true
+		trigger.Types = append(trigger.Types, policy.TriggerProtectedArea)
 		trigger.Reasons = append(trigger.Reasons, "touches protected areas (auth, migrations, infra, security)")
 	}
 
 	// Check for large diff
 	if r.isLargeDiff(diff) {
 		trigger.Triggered = true
+		trigger.Types = append(trigger.Types, policy.TriggerLargeDiff)
 		trigger.Reasons = append(trigger.Reasons, fmt.Sprintf("large diff exceeds %d lines", r.policy.LargeDiffThreshold))
 	}
 
 	// Check for weak/absent tests
 	if r.hasWeakTests(changedFiles) {
 		trigger.Triggered = true
+		trigger.Types = append(trigger.Types, policy.TriggerWeakTests)
 		trigger.Reasons = append(trigger.Reasons, "weak or absent tests for touched code")
 	}
 
 	// Check for cross-cutting changes
 	if r.isCrossCutting(changedFiles) {
 		trigger.Triggered = true
+		trigger.Types = append(trigger.Types, policy.TriggerCrossCutting)
 		trigger.Reasons = append(trigger.Reasons, fmt.Sprintf("cross-cutting changes affect >%d packages", r.policy.CrossCuttingThreshold))
 	}
 
@@ -167,20 +255,102 @@ func (r *SecondReviewer) isCrossCutting(changedFiles []string) bool {
 
 // RequestReview spawns a second Claude agent to review the diff.
 func (r *SecondReviewer) RequestReview(ctx context.Context, diff string, taskDescription string) (*SecondReviewResult, error) {
-	if r.claude == nil {
-		return nil, fmt.Errorf("claude process not configured")
+	claude := r.claude
+	if r.factory != nil {
+		claude = r.factory.NewRunner()
 	}
+	return r.review(ctx, claude, "", r.buildPrompt(diff, taskDescription, ""))
+}
 
-	prompt := buildReviewPrompt(diff, taskDescription)
+// ReviewWithPanel has the panel reviewers selected by the trigger types
+// review the diff independently and in parallel, and approves the diff if
+// a quorum of them approves. Without a configured panel one general
+// reviewer votes. Returns an error only if no reviewer could run.
+func (r *SecondReviewer) ReviewWithPanel(ctx context.Context, diff, taskDescription string, triggers []string) (*PanelReviewResult, error) {
+	panel := r.selectReviewers(triggers)
+
+	votes := make([]ReviewVote, len(panel))
+	var wg sync.WaitGroup
+	for i, spec := range panel {
+		votes[i].Reviewer = spec.Name
+		claude := r.claude
+		if r.factory != nil {
+			claude = r.factory.NewRunner()
+		} else if i > 0 {
+			// claude runs once; the rest of the panel needs a factory
+			votes[i].Err = fmt.Errorf("no runner available for reviewer %s", spec.Name)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, spec policy.ReviewerSpec, claude agent.ClaudeRunner) {
+			defer wg.Done()
+			prompt := r.buildPrompt(diff, taskDescription, spec.Focus)
+			votes[i].Result, votes[i].Err = r.review(ctx, claude, spec.Name, prompt)
+		}(i, spec, claude)
+	}
+	wg.Wait()
+
+	result := &PanelReviewResult{Votes: votes, Quorum: r.quorum(len(panel))}
+	failed := 0
+	for _, v := range votes {
+		switch {
+		case v.Err != nil:
+			failed++
+		case v.Result.Approved:
+			result.Approvals++
+		}
+	}
+	if failed == len(votes) {
+		return nil, fmt.Errorf("no reviewer could run: %w", votes[0].Err)
+	}
+	result.Approved = result.Approvals >= result.Quorum
+	return result, nil
+}
+
+// selectReviewers returns the panel reviewers that handle any of the
+// trigger types. If none do, the whole panel reviews.
+func (r *SecondReviewer) selectReviewers(triggers []string) []policy.ReviewerSpec {
+	if len(r.policy.Reviewers) == 0 {
+		return []policy.ReviewerSpec{{Name: "general"}}
+	}
+	var selected []policy.ReviewerSpec
+	for _, spec := range r.policy.Reviewers {
+		if spec.Handles(triggers) {
+			selected = append(selected, spec)
+		}
+	}
+	if len(selected) == 0 {
+		return r.policy.Reviewers
+	}
+	return selected
+}
+
+// quorum returns how many of n reviewers must approve.
+func (r *SecondReviewer) quorum(n int) int {
+	q := r.policy.Quorum
+	if q <= 0 {
+		q = n/2 + 1
+	}
+	if q > n {
+		q = n
+	}
+	return q
+}
+
+// review runs one reviewer on claude and parses its verdict.
+func (r *SecondReviewer) review(ctx context.Context, claude agent.ClaudeRunner, reviewer, prompt string) (*SecondReviewResult, error) {
+	if claude == nil {
+		return nil, fmt.Errorf("claude process not configured")
+	}
 
 	// Start the Claude process with the review prompt
-	if err := r.claude.Start(prompt, ""); err != nil {
+	if err := claude.Start(prompt, ""); err != nil {
 		return nil, fmt.Errorf("start claude process: %w", err)
 	}
 
 	// Collect the output
 	var output strings.Builder
-	for event := range r.claude.Output() {
+	for event := range claude.Output() {
 		switch event.Type {
 		case agent.StreamEventAssistant, agent.StreamEventResult:
 			output.WriteString(event.Message)
@@ -190,17 +360,47 @@ func (r *SecondReviewer) RequestReview(ctx context.Context, diff string, taskDes
 	}
 
 	// Wait for process to complete
-	if err := r.claude.Wait(); err != nil {
+	if err := claude.Wait(); err != nil {
 		return nil, fmt.Errorf("wait for claude: %w", err)
 	}
 
 	// Parse the response
-	return parseReviewResponse(output.String()), nil
+	var result *SecondReviewResult
+	if len(r.policy.Rubric) > 0 {
+		result = parseRubricReviewResponse(output.String(), r.policy.Rubric)
+	} else {
+		result = parseReviewResponse(output.String())
+	}
+	for i := range result.Findings {
+		result.Findings[i].Reviewer = reviewer
+	}
+	return result, nil
+}
+
+// buildPrompt constructs a reviewer's prompt: one asking for a verdict on
+// each rubric criterion if the policy configures a rubric, and a free-text
+// review otherwise.
+func (r *SecondReviewer) buildPrompt(diff, taskDescription, focus string) string {
+	if len(r.policy.Rubric) > 0 {
+		return buildRubricReviewPrompt(diff, taskDescription, focus, r.policy.Rubric)
+	}
+	return buildFocusedReviewPrompt(diff, taskDescription, focus)
 }
 
 // buildReviewPrompt constructs the prompt for the second review agent.
 func buildReviewPrompt(diff, taskDescription string) string {
-	return fmt.Sprintf(`You are a code reviewer performing a second review of high-risk changes.
+	return buildFocusedReviewPrompt(diff, taskDescription, "")
+}
+
+// buildFocusedReviewPrompt constructs the prompt for a panel reviewer that
+// concentrates on focus, such as "security". Empty focus reviews for
+// general correctness.
+func buildFocusedReviewPrompt(diff, taskDescription, focus string) string {
+	role := "You are a code reviewer performing a second review of high-risk changes."
+	if focus != "" {
+		role = fmt.Sprintf("You are a %s reviewer performing a second review of high-risk changes. Concentrate on %s issues.", focus, focus)
+	}
+	return fmt.Sprintf(`%s
 
 TASK DESCRIPTION:
 %s
@@ -212,7 +412,9 @@ Please review this diff carefully and provide your assessment.
 
 Your response MUST include:
 1. A clear APPROVED or NOT APPROVED verdict on the first line
-2. A list of concerns, if any (prefix each with "CONCERN:")
+2. A list of concerns, if any (prefix each with "CONCERN:", then its
+   severity in brackets as [low], [medium], [high] or [critical], and cite
+   the file and line it applies to as path/to/file.go:42 where possible)
 3. Any recommendations for improvement
 
 Focus on:
@@ -223,7 +425,7 @@ Focus on:
 - Potential performance problems
 
 If you approve, state "APPROVED" on the first line.
-If you have concerns that block approval, state "NOT APPROVED" on the first line.`, taskDescription, diff)
+If you have concerns that block approval, state "NOT APPROVED" on the first line.`, role, taskDescription, diff)
 }
 
 // parseReviewResponse extracts approval status and concerns from the reviewer output.
@@ -258,6 +460,7 @@ func parseReviewResponse(output string) *SecondReviewResult {
 			concern = strings.TrimSpace(concern)
 			if concern != "" {
 				result.Concerns = append(result.Concerns, concern)
+				result.Findings = append(result.Findings, parseFinding(concern))
 			}
 		}
 	}
@@ -265,6 +468,28 @@ func parseReviewResponse(output string) *SecondReviewResult {
 	return result
 }
 
+// findingSeverity matches a concern's leading bracketed severity.
+var findingSeverity = regexp.MustCompile(`^\[(\w+)\]\s*`)
+
+// findingLocation matches a path:line citation in a concern.
+var findingLocation = regexp.MustCompile(`([\w./-]+\.\w+):(\d+)`)
+
+// parseFinding structures a concern, extracting its severity and the first
+// location it cites.
+func parseFinding(concern string) ReviewFinding {
+	var f ReviewFinding
+	if m := findingSeverity.FindStringSubmatch(concern); m != nil {
+		f.Severity = strings.ToLower(m[1])
+		concern = concern[len(m[0]):]
+	}
+	if m := findingLocation.FindStringSubmatch(concern); m != nil {
+		f.File = m[1]
+		f.Line, _ = strconv.Atoi(m[2])
+	}
+	f.Message = concern
+	return f
+}
+
 // isSourceFile checks if a file is a source code file.
 func isSourceFile(path string) bool {
 	extensions := []string{".go", ".js", ".ts", ".py", ".java", ".rb", ".rs", ".c", ".cpp", ".h"}
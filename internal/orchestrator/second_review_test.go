@@ -2,8 +2,11 @@ package orchestrator
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/protect"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
@@ -477,3 +480,142 @@ func TestReviewTrigger_MultipleTriggers(t *testing.T) {
 		t.Errorf("expected at least 3 reasons, got %d: %v", len(trigger.Reasons), trigger.Reasons)
 	}
 }
+
+// reviewRunner answers a review prompt with the response of the first
+// reviewer focus the prompt mentions.
+type reviewRunner struct {
+	responses map[string]string
+	outputCh  chan agent.StreamEvent
+}
+
+func (m *reviewRunner) Start(prompt, workDir string) error {
+	response := m.responses[""]
+	for focus, r := range m.responses {
+		if focus != "" && strings.Contains(prompt, "You are a "+focus+" reviewer") {
+			response = r
+		}
+	}
+	go func() {
+		m.outputCh <- agent.StreamEvent{Type: agent.StreamEventResult, Message: response}
+		close(m.outputCh)
+	}()
+	return nil
+}
+func (m *reviewRunner) StartWithOptions(prompt, workDir string, opts *agent.StartOptions) error {
+	return m.Start(prompt, workDir)
+}
+func (m *reviewRunner) Output() <-chan agent.StreamEvent { return m.outputCh }
+func (m *reviewRunner) Wait() error                      { return nil }
+func (m *reviewRunner) Kill() error                      { return nil }
+func (m *reviewRunner) Stderr() string                   { return "" }
+func (m *reviewRunner) PID() int                         { return 0 }
+
+// reviewRunnerFactory creates reviewRunners, counting how many ran.
+type reviewRunnerFactory struct {
+	mu        sync.Mutex
+	runs      int
+	responses map[string]string
+}
+
+func (f *reviewRunnerFactory) NewRunner() agent.ClaudeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs++
+	return &reviewRunner{responses: f.responses, outputCh: make(chan agent.StreamEvent, 1)}
+}
+
+func TestReviewWithPanel_Quorum(t *testing.T) {
+	panel := &policy.ReviewPolicy{
+		Reviewers: []policy.ReviewerSpec{
+			{Name: "general"},
+			{Name: "security", Focus: "security"},
+			{Name: "performance", Focus: "performance"},
+		},
+	}
+	factory := &reviewRunnerFactory{responses: map[string]string{
+		"":         "APPROVED",
+		"security": "NOT APPROVED\nCONCERN: [high] auth/login.go:12 token compared with ==",
+	}}
+	reviewer := NewSecondReviewerWithPolicy(protect.New(), nil, panel)
+	reviewer.SetRunnerFactory(factory)
+
+	result, err := reviewer.ReviewWithPanel(t.Context(), "diff", "task", nil)
+	if err != nil {
+		t.Fatalf("ReviewWithPanel: %v", err)
+	}
+	if factory.runs != 3 || len(result.Votes) != 3 {
+		t.Fatalf("expected all 3 reviewers to vote, got %d runs and %d votes", factory.runs, len(result.Votes))
+	}
+	if !result.Approved || result.Approvals != 2 || result.Quorum != 2 {
+		t.Errorf("result = %+v, want approved 2/3 with quorum 2", result)
+	}
+	if concerns := result.Concerns(); len(concerns) != 1 || !strings.HasPrefix(concerns[0], "security: ") {
+		t.Errorf("Concerns = %v, want one from security", concerns)
+	}
+	findings := result.Findings()
+	if len(findings) != 1 || findings[0].Reviewer != "security" || findings[0].File != "auth/login.go" {
+		t.Errorf("Findings = %+v", findings)
+	}
+
+	// Requiring every reviewer turns the lone rejection into a veto
+	panel.Quorum = 3
+	result, err = reviewer.ReviewWithPanel(t.Context(), "diff", "task", nil)
+	if err != nil {
+		t.Fatalf("ReviewWithPanel: %v", err)
+	}
+	if result.Approved {
+		t.Errorf("result = %+v, want rejected under a unanimous quorum", result)
+	}
+}
+
+func TestReviewWithPanel_SelectsByTrigger(t *testing.T) {
+	panel := &policy.ReviewPolicy{
+		Reviewers: []policy.ReviewerSpec{
+			{Name: "security", Focus: "security", Triggers: []string{policy.TriggerProtectedArea}},
+			{Name: "architecture", Focus: "architecture", Triggers: []string{policy.TriggerCrossCutting}},
+		},
+	}
+	factory := &reviewRunnerFactory{responses: map[string]string{"": "APPROVED"}}
+	reviewer := NewSecondReviewerWithPolicy(protect.New(), nil, panel)
+	reviewer.SetRunnerFactory(factory)
+
+	result, err := reviewer.ReviewWithPanel(t.Context(), "diff", "task", []string{policy.TriggerProtectedArea})
+	if err != nil {
+		t.Fatalf("ReviewWithPanel: %v", err)
+	}
+	if len(result.Votes) != 1 || result.Votes[0].Reviewer != "security" {
+		t.Errorf("votes = %+v, want only the security reviewer", result.Votes)
+	}
+
+	// A trigger no reviewer handles falls back to the whole panel
+	result, err = reviewer.ReviewWithPanel(t.Context(), "diff", "task", []string{policy.TriggerLargeDiff})
+	if err != nil {
+		t.Fatalf("ReviewWithPanel: %v", err)
+	}
+	if len(result.Votes) != 2 {
+		t.Errorf("votes = %+v, want the whole panel", result.Votes)
+	}
+}
+
+func TestReviewWithPanel_NoRunner(t *testing.T) {
+	reviewer := NewSecondReviewer(protect.New(), nil)
+	if _, err := reviewer.ReviewWithPanel(t.Context(), "diff", "task", nil); err == nil {
+		t.Error("expected an error when no reviewer can run")
+	}
+}
+
+func TestParseFinding(t *testing.T) {
+	tests := []struct {
+		concern string
+		want    ReviewFinding
+	}{
+		{"[HIGH] auth/login.go:12 token compared with ==", ReviewFinding{Severity: "high", File: "auth/login.go", Line: 12, Message: "auth/login.go:12 token compared with =="}},
+		{"missing error handling in internal/api/handler.go:7", ReviewFinding{File: "internal/api/handler.go", Line: 7, Message: "missing error handling in internal/api/handler.go:7"}},
+		{"[low] naming is inconsistent", ReviewFinding{Severity: "low", Message: "naming is inconsistent"}},
+	}
+	for _, tt := range tests {
+		if got := parseFinding(tt.concern); got != tt.want {
+			t.Errorf("parseFinding(%q) = %+v, want %+v", tt.concern, got, tt.want)
+		}
+	}
+}
@@ -42,8 +42,12 @@ Return ONLY a JSON object with this exact structure (no other text):
     "path/to/file1.go": "full merged file content...",
     "path/to/file2.go": "full merged file content..."
   },
-  "reasoning": "Brief explanation of how conflicts were resolved"
-}`
+  "reasoning": "Brief explanation of how conflicts were resolved",
+  "confidence": 0.8
+}
+
+Set "confidence" between 0 and 1 to how sure you are that the merged code
+preserves the intent of both branches. Use a low value if you had to guess.`
 
 // SemanticMergeResult contains the outcome of a semantic merge operation.
 type SemanticMergeResult struct {
@@ -61,12 +65,16 @@ type SemanticMergeResult struct {
 	// ChangedFiles lists all files that were changed in the merge.
 	// Populated only on successful merge.
 	ChangedFiles []string `json:"changed_files,omitempty"`
+	// Confidence estimates how likely the merge is correct, from 0 to 1.
+	// Populated only on successful merge.
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
 // mergeResponse is the JSON structure returned by Claude for merge resolution.
 type mergeResponse struct {
 	MergedFiles map[string]string `json:"merged_files"`
 	Reasoning   string            `json:"reasoning"`
+	Confidence  float64           `json:"confidence"`
 }
 
 // SemanticMerger uses a Claude agent to resolve merge conflicts semantically.
@@ -157,6 +165,7 @@ func (m *SemanticMerger) Merge(ctx context.Context, branch1, branch2 string, con
 			MergedFiles: append(files1, files2...),
 			NeedsHuman:  false,
 			Reason:      "Changes affect disjoint file paths - trivial merge",
+			Confidence:  1,
 		}, nil
 	}
 
@@ -281,9 +290,42 @@ func (m *SemanticMerger) Merge(ctx context.Context, branch1, branch2 string, con
 		Reason:       mergeResp.Reasoning,
 		FinalDiff:    finalDiff,
 		ChangedFiles: changedFiles,
+		Confidence:   m.mergeConfidence(mergeResp.Confidence, m.CanAutoMerge(diff1, diff2)),
 	}, nil
 }
 
+// Confidence adjustments applied by mergeConfidence.
+const (
+	// defaultModelConfidence is used when Claude does not report a confidence.
+	defaultModelConfidence = 0.5
+	// unvalidatedPenalty scales confidence down for each validation step
+	// (build, tests) the project has no command for.
+	unvalidatedPenalty = 0.8
+)
+
+// mergeConfidence scores a validated semantic merge. It starts from Claude's
+// own confidence, raises it halfway to certain if the branches touched
+// different functions, and lowers it for each validation step that could
+// not run because the project has no build or test command.
+func (m *SemanticMerger) mergeConfidence(modelConfidence float64, autoMergeable bool) float64 {
+	confidence := modelConfidence
+	if confidence <= 0 || confidence > 1 {
+		confidence = defaultModelConfidence
+	}
+	if autoMergeable {
+		confidence += (1 - confidence) / 2
+	}
+
+	info := GetProjectTypeInfo(m.repoPath)
+	if len(info.BuildCommand) == 0 {
+		confidence *= unvalidatedPenalty
+	}
+	if len(info.TestCommand) == 0 {
+		confidence *= unvalidatedPenalty
+	}
+	return confidence
+}
+
 // CanAutoMerge determines if two diffs can be safely auto-merged based on strict conditions.
 // Returns true if:
 // - Changes affect disjoint file paths, OR
@@ -1,6 +1,9 @@
 package orchestrator
 
 import (
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -507,3 +510,35 @@ index 0000000..abc1234
 		})
 	}
 }
+
+func TestSemanticMergerConfidence(t *testing.T) {
+	goRepo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(goRepo, "go.mod"), []byte("module example.com/m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	validated := NewSemanticMerger(nil, goRepo)
+	unvalidated := NewSemanticMerger(nil, t.TempDir())
+
+	tests := []struct {
+		name          string
+		merger        *SemanticMerger
+		model         float64
+		autoMergeable bool
+		want          float64
+	}{
+		{"model confidence is kept when build and tests run", validated, 0.7, false, 0.7},
+		{"missing model confidence defaults", validated, 0, false, defaultModelConfidence},
+		{"out of range model confidence defaults", validated, 3, false, defaultModelConfidence},
+		{"different functions raise confidence", validated, 0.6, true, 0.8},
+		{"no build or test command lowers confidence", unvalidated, 0.5, false, 0.5 * unvalidatedPenalty * unvalidatedPenalty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.merger.mergeConfidence(tt.model, tt.autoMergeable)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("mergeConfidence(%v, %v) = %v, want %v", tt.model, tt.autoMergeable, got, tt.want)
+			}
+		})
+	}
+}
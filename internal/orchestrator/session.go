@@ -18,6 +18,12 @@ type SessionBranchManager struct {
 	greenfield bool
 	repoPath   string
 	git        git.Runner
+	// mainBranch is the branch sessions merge into. Empty means it is
+	// detected from the repository when needed.
+	mainBranch string
+	// commitIdentity formats the session's commit messages; nil uses the
+	// default message template.
+	commitIdentity *git.CommitIdentity
 }
 
 // NewSessionBranchManager creates a new SessionBranchManager.
@@ -53,8 +59,10 @@ func NewSessionBranchManagerWithRunner(sessionID, repoPath string, greenfield bo
 	}
 }
 
-// CreateBranch creates the session branch if not in greenfield mode.
-// Returns nil if greenfield mode is enabled (no branch creation needed).
+// CreateBranch creates the session branch from the main branch, or checks
+// it out if it already exists, so the session starts from what it merges
+// into whatever branch is checked out. Returns nil if greenfield mode is
+// enabled (no branch creation needed).
 func (m *SessionBranchManager) CreateBranch() error {
 	if m.greenfield {
 		return nil
@@ -74,14 +82,39 @@ func (m *SessionBranchManager) CreateBranch() error {
 		return nil
 	}
 
+	mainBranch, err := m.MainBranch()
+	if err != nil {
+		return fmt.Errorf("failed to determine main branch: %w", err)
+	}
+
 	// Create and checkout new branch
-	if err := m.git.CreateAndCheckoutBranch(m.branchName); err != nil {
-		return fmt.Errorf("failed to create branch %s: %w", m.branchName, err)
+	if _, err := m.git.Run("checkout", "-b", m.branchName, mainBranch); err != nil {
+		return fmt.Errorf("failed to create branch %s from %s: %w", m.branchName, mainBranch, err)
 	}
 
 	return nil
 }
 
+// SetMainBranch sets the branch the session merges into, overriding detection.
+func (m *SessionBranchManager) SetMainBranch(branch string) {
+	m.mainBranch = branch
+}
+
+// SetCommitIdentity sets the message template of the session's commits.
+// Who they are made as is up to the git runner.
+func (m *SessionBranchManager) SetCommitIdentity(identity *git.CommitIdentity) {
+	m.commitIdentity = identity
+}
+
+// MainBranch returns the branch the session merges into: the configured
+// main branch, or the repository's detected default branch.
+func (m *SessionBranchManager) MainBranch() (string, error) {
+	if m.mainBranch != "" {
+		return m.mainBranch, nil
+	}
+	return git.DetectDefaultBranch(m.git)
+}
+
 // GetBranchName returns the session branch name.
 // Returns empty string if in greenfield mode.
 func (m *SessionBranchManager) GetBranchName() string {
@@ -91,6 +124,9 @@ func (m *SessionBranchManager) GetBranchName() string {
 // IsProtected checks if the given branch name is a protected branch.
 func (m *SessionBranchManager) IsProtected(branch string) bool {
 	normalized := strings.TrimSpace(strings.ToLower(branch))
+	if m.mainBranch != "" && normalized == strings.ToLower(m.mainBranch) {
+		return true
+	}
 	for _, protected := range protectedBranches {
 		if normalized == protected {
 			return true
@@ -99,7 +135,7 @@ func (m *SessionBranchManager) IsProtected(branch string) bool {
 	return false
 }
 
-// MergeToMain merges the session branch into main (or master).
+// MergeToMain merges the session branch into the main branch.
 // This should be called after all tasks complete successfully.
 // Returns nil if greenfield mode is enabled (no branch to merge).
 func (m *SessionBranchManager) MergeToMain() error {
@@ -111,34 +147,13 @@ func (m *SessionBranchManager) MergeToMain() error {
 		return nil
 	}
 
-	// Determine the main branch name
-	mainBranch := "main"
-	exists, err := m.git.BranchExists("main")
+	mainBranch, err := m.MainBranch()
 	if err != nil {
-		return fmt.Errorf("failed to check if main branch exists: %w", err)
-	}
-	if !exists {
-		// Try master if main doesn't exist
-		exists, err = m.git.BranchExists("master")
-		if err != nil {
-			return fmt.Errorf("failed to check if master branch exists: %w", err)
-		}
-		if !exists {
-			return fmt.Errorf("failed to find main or master branch")
-		}
-		mainBranch = "master"
+		return fmt.Errorf("failed to determine main branch: %w", err)
 	}
 
 	// Commit any pending changes on session branch before merging to main
-	// This catches any uncommitted changes from agent merges
-	if _, err := m.git.Run("add", "."); err != nil {
-		// If add fails, it might be because there are no changes - that's OK
-		// But we should still try to continue
-	}
-	if _, err := m.git.Run("commit", "-m", fmt.Sprintf("Auto-commit pending changes before merging session %s", m.sessionID)); err != nil {
-		// If commit fails, it might be because there are no changes - that's OK
-		// Continue with the merge
-	}
+	m.CommitPending()
 
 	// Checkout the main branch
 	if err := m.git.CheckoutBranch(mainBranch); err != nil {
@@ -146,13 +161,52 @@ func (m *SessionBranchManager) MergeToMain() error {
 	}
 
 	// Merge the session branch into main with a custom message
-	if err := m.git.MergeNoFFMessage(m.branchName, fmt.Sprintf("Merge session %s", m.sessionID)); err != nil {
+	if err := m.git.MergeNoFFMessage(m.branchName, m.commitIdentity.Message("Merge session "+m.sessionID, "", m.sessionID)); err != nil {
 		return fmt.Errorf("failed to merge session branch %s into %s: %w", m.branchName, mainBranch, err)
 	}
 
 	return nil
 }
 
+// CommitPending commits any uncommitted changes on the session branch, such
+// as ones left behind by agent merges, so merging the branch carries them.
+func (m *SessionBranchManager) CommitPending() {
+	if m.greenfield || m.branchName == "" {
+		return
+	}
+	if _, err := m.git.Run("add", "."); err != nil {
+		// If add fails, it might be because there are no changes - that's OK
+		// But we should still try to continue
+	}
+	if _, err := m.git.Run("commit", "-m", m.commitIdentity.Message("Auto-commit pending changes before merging session "+m.sessionID, "", m.sessionID)); err != nil {
+		// If commit fails, it might be because there are no changes - that's OK
+		// Continue with the merge
+	}
+}
+
+// HeadCommit returns the commit currently checked out, such as the session
+// merge commit right after MergeToMain.
+func (m *SessionBranchManager) HeadCommit() (string, error) {
+	out, err := m.git.Run("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// MainCommit returns the commit the main branch is at.
+func (m *SessionBranchManager) MainCommit() (string, error) {
+	mainBranch, err := m.MainBranch()
+	if err != nil {
+		return "", err
+	}
+	out, err := m.git.Run("rev-parse", mainBranch)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
 // Cleanup deletes the session branch.
 // This is typically called when a session is cancelled or after successful merge.
 // Returns nil if greenfield mode is enabled (no branch to clean up).
@@ -165,13 +219,9 @@ func (m *SessionBranchManager) Cleanup() error {
 		return nil
 	}
 
-	// Determine the main branch name
-	mainBranch := "main"
-	exists, err := m.git.BranchExists("main")
+	mainBranch, err := m.MainBranch()
 	if err != nil {
-		mainBranch = "master"
-	} else if !exists {
-		mainBranch = "master"
+		return fmt.Errorf("failed to determine main branch before cleanup: %w", err)
 	}
 
 	// First, checkout main to avoid deleting the current branch
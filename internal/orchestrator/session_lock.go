@@ -0,0 +1,111 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+	"github.com/ShayCichocki/alphie/internal/logging"
+)
+
+// sessionLockFile is the lock file, relative to the repository root, that
+// prevents two Alphie processes from orchestrating the same repository.
+const sessionLockFile = ".alphie/session.lock"
+
+// sessionLocks counts holders of each lock file within this process.
+// Orchestrators in the same process (e.g. the interactive pool) share the lock.
+var sessionLocks = struct {
+	held map[string]int
+	mu   sync.Mutex
+}{held: make(map[string]int)}
+
+// acquireSessionLock takes the repository's session lock and returns a
+// function that releases it. If another live process holds the lock, the
+// returned error wraps ErrSessionLocked. Locks left behind by dead
+// processes are reclaimed.
+func acquireSessionLock(repoPath string) (func(), error) {
+	path := filepath.Join(repoPath, sessionLockFile)
+
+	sessionLocks.mu.Lock()
+	defer sessionLocks.mu.Unlock()
+
+	if sessionLocks.held[path] == 0 {
+		if err := writeSessionLock(path); err != nil {
+			return nil, err
+		}
+	}
+	sessionLocks.held[path]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { releaseSessionLock(path) })
+	}, nil
+}
+
+// writeSessionLock creates the lock file containing this process's PID.
+func writeSessionLock(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create session lock dir: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, writeErr := f.WriteString(strconv.Itoa(os.Getpid()))
+			closeErr := f.Close()
+			if writeErr != nil {
+				return fmt.Errorf("write session lock: %w", writeErr)
+			}
+			return closeErr
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("create session lock: %w", err)
+		}
+
+		pid := readSessionLockPID(path)
+		if pid != os.Getpid() && iexec.ProcessAlive(pid) {
+			return &SessionLockedError{Path: path, PID: pid}
+		}
+
+		// Stale lock from a dead process (or an unreleased lock of our own)
+		pkgLog.Info("reclaiming stale session lock", "path", path, "pid", pid)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove stale session lock: %w", err)
+		}
+	}
+	return &SessionLockedError{Path: path, PID: readSessionLockPID(path)}
+}
+
+// releaseSessionLock drops one holder and removes the file when none remain.
+func releaseSessionLock(path string) {
+	sessionLocks.mu.Lock()
+	defer sessionLocks.mu.Unlock()
+
+	sessionLocks.held[path]--
+	if sessionLocks.held[path] > 0 {
+		return
+	}
+	delete(sessionLocks.held, path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		pkgLog.Warn("failed to remove session lock", "path", path, logging.Err(err))
+	}
+}
+
+// readSessionLockPID returns the PID recorded in a lock file, or 0.
+func readSessionLockPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestSessionLock_AcquireRelease(t *testing.T) {
+	repo := t.TempDir()
+	lockPath := filepath.Join(repo, sessionLockFile)
+
+	release, err := acquireSessionLock(repo)
+	if err != nil {
+		t.Fatalf("acquireSessionLock() error = %v", err)
+	}
+	if pid := readSessionLockPID(lockPath); pid != os.Getpid() {
+		t.Errorf("expected lock to record pid %d, got %d", os.Getpid(), pid)
+	}
+
+	release()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected lock file to be removed, stat err = %v", err)
+	}
+}
+
+func TestSessionLock_SharedWithinProcess(t *testing.T) {
+	repo := t.TempDir()
+	lockPath := filepath.Join(repo, sessionLockFile)
+
+	release1, err := acquireSessionLock(repo)
+	if err != nil {
+		t.Fatalf("first acquire error = %v", err)
+	}
+	release2, err := acquireSessionLock(repo)
+	if err != nil {
+		t.Fatalf("second acquire in same process should succeed, got %v", err)
+	}
+
+	release1()
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("expected lock to remain while another holder exists: %v", err)
+	}
+	release2()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected lock file to be removed after last release, stat err = %v", err)
+	}
+}
+
+func TestSessionLock_HeldByOtherProcess(t *testing.T) {
+	repo := t.TempDir()
+	lockPath := filepath.Join(repo, sessionLockFile)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The parent process is alive and is not us
+	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(os.Getppid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := acquireSessionLock(repo)
+	if !errors.Is(err, ErrSessionLocked) {
+		t.Fatalf("expected ErrSessionLocked, got %v", err)
+	}
+	var lockErr *SessionLockedError
+	if !errors.As(err, &lockErr) || lockErr.PID != os.Getppid() {
+		t.Errorf("expected SessionLockedError with pid %d, got %v", os.Getppid(), err)
+	}
+}
+
+func TestSessionLock_ReclaimsStaleLock(t *testing.T) {
+	repo := t.TempDir()
+	lockPath := filepath.Join(repo, sessionLockFile)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// PID 0 is never a live holder
+	if err := os.WriteFile(lockPath, []byte("0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := acquireSessionLock(repo)
+	if err != nil {
+		t.Fatalf("expected stale lock to be reclaimed, got %v", err)
+	}
+	defer release()
+	if pid := readSessionLockPID(lockPath); pid != os.Getpid() {
+		t.Errorf("expected lock to be rewritten with pid %d, got %d", os.Getpid(), pid)
+	}
+}
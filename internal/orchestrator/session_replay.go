@@ -0,0 +1,255 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// replayLogEvents is how many of the latest events a session replay keeps,
+// as the TUI's log panel shows them.
+const replayLogEvents = 10
+
+// SessionReplay is the state of a session reconstructed from its persisted
+// events: the tasks, agents and usage the TUI showed at a point in time.
+type SessionReplay struct {
+	// Start is the time of the first event.
+	Start time.Time
+	// At is the point in time replayed.
+	At time.Time
+	// Events is the number of events replayed.
+	Events int
+	// Counts is the number of replayed events of each type.
+	Counts map[EventType]int
+	// Tasks are the tasks in the order their first event was emitted.
+	Tasks []SessionReplayTask
+	// TokensUsed and Cost are the session's usage at At.
+	TokensUsed int64
+	Cost       float64
+	// Done reports whether the session had finished by At; Success and
+	// Message describe how it finished.
+	Done    bool
+	Success bool
+	Message string
+	// Log holds the latest events other than agent progress and cost ticks.
+	Log []OrchestratorEvent
+}
+
+// SessionReplayTask is a task of a replayed session.
+type SessionReplayTask struct {
+	ID       string
+	Title    string
+	ParentID string
+	State    ReplayState
+	// Since is when the task entered its current state.
+	Since time.Time
+	// AgentID is the agent of the task's latest attempt.
+	AgentID string
+	// Attempts counts the times the task was started.
+	Attempts int
+	// TokensUsed and Cost are the task's usage over every attempt,
+	// including the progress of the running one.
+	TokensUsed int64
+	Cost       float64
+	// CurrentAction is what the agent was doing, while the task runs.
+	CurrentAction string
+	// Files lists the files the task changed, once done.
+	Files []string
+	// Error is why the task's latest attempt failed or why it is blocked.
+	Error string
+
+	// Usage of finished attempts and the progress of the running one.
+	doneTokens, liveTokens int64
+	doneCost, liveCost     float64
+}
+
+// ReplaySession reconstructs a session from its events, in the order they
+// were emitted, as it stood at time at. Events after at are ignored; a zero
+// at replays every event.
+func ReplaySession(events []OrchestratorEvent, at time.Time) *SessionReplay {
+	r := &SessionReplay{At: at, Counts: make(map[EventType]int)}
+	byID := make(map[string]*SessionReplayTask)
+	var order []string
+	costTicked := false
+
+	task := func(e OrchestratorEvent) *SessionReplayTask {
+		t, ok := byID[e.TaskID]
+		if !ok {
+			t = &SessionReplayTask{ID: e.TaskID, Since: e.Timestamp}
+			byID[e.TaskID] = t
+			order = append(order, e.TaskID)
+		}
+		if e.TaskTitle != "" {
+			t.Title = e.TaskTitle
+		}
+		if e.ParentID != "" {
+			t.ParentID = e.ParentID
+		}
+		return t
+	}
+	setState := func(t *SessionReplayTask, state ReplayState, e OrchestratorEvent) {
+		if t.State != state {
+			t.State = state
+			t.Since = e.Timestamp
+		}
+	}
+
+	for _, e := range events {
+		if !at.IsZero() && e.Timestamp.After(at) {
+			break
+		}
+		if r.Events == 0 {
+			r.Start = e.Timestamp
+		}
+		r.Events++
+		r.Counts[e.Type]++
+		if e.Type != EventAgentProgress && e.Type != EventCostTick {
+			r.Log = append(r.Log, e)
+			if len(r.Log) > replayLogEvents {
+				r.Log = r.Log[1:]
+			}
+		}
+
+		switch e.Type {
+		case EventCostTick:
+			costTicked = true
+			r.TokensUsed = e.TokensUsed
+			r.Cost = e.Cost
+			continue
+		case EventSessionDone:
+			r.Done = true
+			r.Success = e.Error == nil
+			r.Message = e.Message
+			if e.Error != nil {
+				r.Message = e.Error.Error()
+			}
+			continue
+		}
+		if e.TaskID == "" {
+			continue
+		}
+
+		t := task(e)
+		switch e.Type {
+		case EventTaskQueued, EventTaskPreempted, EventTaskEscalated:
+			setState(t, ReplayQueued, e)
+			t.CurrentAction = ""
+		case EventTaskStarted:
+			setState(t, ReplayRunning, e)
+			t.Attempts++
+			t.AgentID = e.AgentID
+			t.Error = ""
+			t.CurrentAction = ""
+		case EventAgentProgress:
+			t.liveTokens = e.TokensUsed
+			t.liveCost = e.Cost
+			if e.CurrentAction != "" {
+				t.CurrentAction = e.CurrentAction
+			}
+		case EventTaskUsage:
+			t.doneTokens += e.TokensUsed
+			t.doneCost += e.Cost
+			t.liveTokens, t.liveCost = 0, 0
+		case EventMergeStarted:
+			setState(t, ReplayMerging, e)
+		case EventTaskCompleted:
+			setState(t, ReplayDone, e)
+			t.Files = e.Files
+			t.CurrentAction = ""
+		case EventTaskFailed:
+			setState(t, ReplayFailed, e)
+			t.Error = e.Message
+			if e.Error != nil {
+				t.Error = e.Error.Error()
+			}
+			t.CurrentAction = ""
+		case EventTaskBlocked:
+			setState(t, ReplayBlocked, e)
+			t.Error = e.Message
+		}
+	}
+
+	for _, id := range order {
+		t := byID[id]
+		if t.State == "" {
+			// Only usage or progress was seen, e.g. for an epic's own ID
+			t.State = ReplayWaiting
+		}
+		t.TokensUsed = t.doneTokens + t.liveTokens
+		t.Cost = t.doneCost + t.liveCost
+		if !costTicked {
+			r.TokensUsed += t.TokensUsed
+			r.Cost += t.Cost
+		}
+		r.Tasks = append(r.Tasks, *t)
+	}
+	if r.At.IsZero() && len(events) > 0 {
+		r.At = events[len(events)-1].Timestamp
+	}
+	return r
+}
+
+// Render writes a human-readable view of the replay to w: the session's
+// outcome and usage, its tasks grouped by state, and its latest events.
+func (r *SessionReplay) Render(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Replay at %s (+%s), %d events\n", r.At.Format(time.RFC3339), formatOffset(r.At.Sub(r.Start)), r.Events))
+	switch {
+	case !r.Done:
+		sb.WriteString("Status: running\n")
+	case r.Success:
+		sb.WriteString(fmt.Sprintf("Status: succeeded  %s\n", r.Message))
+	default:
+		sb.WriteString(fmt.Sprintf("Status: failed  %s\n", r.Message))
+	}
+	sb.WriteString(fmt.Sprintf("Usage: %d tokens, $%.2f\n", r.TokensUsed, r.Cost))
+
+	for _, state := range replayStateOrder {
+		var tasks []SessionReplayTask
+		for _, t := range r.Tasks {
+			if t.State == state {
+				tasks = append(tasks, t)
+			}
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d)\n", strings.ToUpper(string(state)), len(tasks)))
+		for _, t := range tasks {
+			details := []string{fmt.Sprintf("for %s", formatOffset(r.At.Sub(t.Since)))}
+			if t.AgentID != "" {
+				details = append(details, t.AgentID)
+			}
+			if t.Attempts > 1 {
+				details = append(details, fmt.Sprintf("%d attempts", t.Attempts))
+			}
+			if t.TokensUsed > 0 || t.Cost > 0 {
+				details = append(details, fmt.Sprintf("%d tokens, $%.2f", t.TokensUsed, t.Cost))
+			}
+			sb.WriteString(fmt.Sprintf("  %-12s %s  [%s]\n", t.ID, t.Title, strings.Join(details, ", ")))
+			switch {
+			case t.CurrentAction != "":
+				sb.WriteString(fmt.Sprintf("               %s\n", t.CurrentAction))
+			case t.Error != "" && (state == ReplayFailed || state == ReplayBlocked):
+				sb.WriteString(fmt.Sprintf("               %s\n", t.Error))
+			}
+		}
+	}
+
+	if len(r.Log) > 0 {
+		sb.WriteString("\nLatest events\n")
+		for _, e := range r.Log {
+			line := fmt.Sprintf("  +%-8s %-24s %s", formatOffset(e.Timestamp.Sub(r.Start)), e.Type, e.TaskID)
+			if e.Message != "" {
+				line += "  " + e.Message
+			}
+			sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
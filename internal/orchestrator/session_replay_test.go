@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replayEvents is a session in which task a succeeds on its second
+// attempt, task b fails and task c is still running.
+func replayEvents(start time.Time) []OrchestratorEvent {
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	return []OrchestratorEvent{
+		{Type: EventTaskQueued, TaskID: "a", TaskTitle: "Add login", Timestamp: at(0)},
+		{Type: EventTaskStarted, TaskID: "a", AgentID: "agent-1", Timestamp: at(1)},
+		{Type: EventAgentProgress, TaskID: "a", TokensUsed: 500, Cost: 0.05, CurrentAction: "Reading auth.go", Timestamp: at(2)},
+		{Type: EventTaskUsage, TaskID: "a", TokensUsed: 800, Cost: 0.08, Timestamp: at(3)},
+		{Type: EventTaskStarted, TaskID: "a", AgentID: "agent-2", Timestamp: at(4)},
+		{Type: EventTaskStarted, TaskID: "b", TaskTitle: "Add logout", AgentID: "agent-3", Timestamp: at(5)},
+		{Type: EventTaskUsage, TaskID: "a", TokensUsed: 1000, Cost: 0.10, Timestamp: at(6)},
+		{Type: EventMergeStarted, TaskID: "a", Timestamp: at(7)},
+		{Type: EventTaskCompleted, TaskID: "a", Files: []string{"auth.go"}, Message: "merged", Timestamp: at(8)},
+		{Type: EventTaskFailed, TaskID: "b", Error: errors.New("tests failed"), Timestamp: at(9)},
+		{Type: EventTaskStarted, TaskID: "c", TaskTitle: "Add signup", AgentID: "agent-4", Timestamp: at(10)},
+		{Type: EventAgentProgress, TaskID: "c", TokensUsed: 200, Cost: 0.02, CurrentAction: "Writing signup.go", Timestamp: at(11)},
+		{Type: EventSessionDone, Error: errors.New("1 task failed"), Timestamp: at(20)},
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := replayEvents(start)
+
+	r := ReplaySession(events, time.Time{})
+	if r.Events != len(events) || !r.At.Equal(start.Add(20*time.Second)) {
+		t.Errorf("events = %d, at = %s", r.Events, r.At)
+	}
+	if !r.Done || r.Success || r.Message != "1 task failed" {
+		t.Errorf("done = %v, success = %v, message = %q", r.Done, r.Success, r.Message)
+	}
+	if len(r.Tasks) != 3 {
+		t.Fatalf("tasks = %+v", r.Tasks)
+	}
+	if a := r.Tasks[0]; a.State != ReplayDone || a.Attempts != 2 || a.AgentID != "agent-2" || a.TokensUsed != 1800 || a.Files[0] != "auth.go" || a.CurrentAction != "" {
+		t.Errorf("task a = %+v", a)
+	}
+	if b := r.Tasks[1]; b.State != ReplayFailed || b.Error != "tests failed" || b.Title != "Add logout" {
+		t.Errorf("task b = %+v", b)
+	}
+	if c := r.Tasks[2]; c.State != ReplayRunning || c.TokensUsed != 200 || c.CurrentAction != "Writing signup.go" {
+		t.Errorf("task c = %+v", c)
+	}
+	// Without cost ticks the session's usage is the sum of its tasks'
+	if r.TokensUsed != 2000 {
+		t.Errorf("session tokens = %d, want 2000", r.TokensUsed)
+	}
+	if r.Counts[EventTaskStarted] != 4 || len(r.Log) != replayLogEvents {
+		t.Errorf("counts = %v, log = %d events", r.Counts, len(r.Log))
+	}
+}
+
+func TestReplaySession_At(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := append(replayEvents(start), OrchestratorEvent{Type: EventCostTick, TokensUsed: 9999, Cost: 1.5, Timestamp: start.Add(30 * time.Second)})
+
+	r := ReplaySession(events, start.Add(2*time.Second))
+	if r.Done || r.Events != 3 || len(r.Tasks) != 1 {
+		t.Fatalf("replay at +2s = %+v", r)
+	}
+	if a := r.Tasks[0]; a.State != ReplayRunning || a.TokensUsed != 500 || a.CurrentAction != "Reading auth.go" {
+		t.Errorf("task a at +2s = %+v", a)
+	}
+
+	if r := ReplaySession(events, time.Time{}); r.TokensUsed != 9999 || r.Cost != 1.5 {
+		t.Errorf("session usage = %d, $%.2f, want the last cost tick", r.TokensUsed, r.Cost)
+	}
+}
+
+func TestSessionReplayRender(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sb strings.Builder
+	if err := ReplaySession(replayEvents(start), time.Time{}).Render(&sb); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{
+		"Replay at 2026-01-01T00:00:20Z (+20s), 13 events",
+		"Status: failed  1 task failed",
+		"Usage: 2000 tokens, $0.20",
+		"RUNNING (1)\n  c            Add signup  [for 10s, agent-4, 200 tokens, $0.02]\n               Writing signup.go",
+		"FAILED (1)\n  b            Add logout  [for 11s, agent-3]\n               tests failed",
+		"DONE (1)\n  a            Add login  [for 12s, agent-2, 2 attempts, 1800 tokens, $0.18]",
+		"  +20s      session_done",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("render missing %q:\n%s", want, sb.String())
+		}
+	}
+}
@@ -3,11 +3,12 @@ package orchestrator
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"strings"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
 	"github.com/ShayCichocki/alphie/internal/prog"
 )
 
@@ -38,6 +39,7 @@ type SessionResumeChecker struct {
 	progClient      *prog.Client
 	worktreeManager *agent.WorktreeManager
 	repoPath        string
+	log             *slog.Logger
 }
 
 // NewSessionResumeChecker creates a new SessionResumeChecker.
@@ -48,6 +50,7 @@ func NewSessionResumeChecker(progClient *prog.Client, worktreeManager *agent.Wor
 		progClient:      progClient,
 		worktreeManager: worktreeManager,
 		repoPath:        repoPath,
+		log:             logging.Logger("session_resume"),
 	}
 }
 
@@ -61,21 +64,21 @@ func (src *SessionResumeChecker) CheckForResumableSessions() (*SessionRecoveryRe
 	if src.progClient != nil {
 		epics, err := src.progClient.ListOpenOrInProgressEpics()
 		if err != nil {
-			log.Printf("[session-resume] warning: failed to list epics: %v", err)
+			src.log.Warn("failed to list epics", logging.Err(err))
 		} else if len(epics) > 0 {
 			result.HasResumableSessions = true
 
 			for _, epic := range epics {
 				completed, total, err := src.progClient.ComputeEpicProgress(epic.ID)
 				if err != nil {
-					log.Printf("[session-resume] warning: failed to compute progress for epic %s: %v", epic.ID, err)
+					src.log.Warn("failed to compute epic progress", "epic", epic.ID, logging.Err(err))
 					continue
 				}
 
 				// Get incomplete task IDs
 				incompleteTasks, err := src.progClient.GetIncompleteTasks(epic.ID)
 				if err != nil {
-					log.Printf("[session-resume] warning: failed to get incomplete tasks for epic %s: %v", epic.ID, err)
+					src.log.Warn("failed to get incomplete tasks", "epic", epic.ID, logging.Err(err))
 					continue
 				}
 
@@ -110,7 +113,7 @@ func (src *SessionResumeChecker) CheckForResumableSessions() (*SessionRecoveryRe
 	if src.worktreeManager != nil {
 		orphans, err := src.findOrphanedWorktrees()
 		if err != nil {
-			log.Printf("[session-resume] warning: failed to list orphaned worktrees: %v", err)
+			src.log.Warn("failed to list orphaned worktrees", logging.Err(err))
 		} else {
 			result.OrphanedWorktrees = orphans
 		}
@@ -208,7 +211,7 @@ func (src *SessionResumeChecker) CleanupOrphanedWorktrees(verbose func(string))
 	cleaned := 0
 	for _, path := range orphans {
 		if err := src.worktreeManager.Remove(path, true); err != nil {
-			log.Printf("[session-resume] warning: failed to remove worktree %s: %v", path, err)
+			src.log.Warn("failed to remove worktree", "path", path, logging.Err(err))
 			continue
 		}
 		if verbose != nil {
@@ -219,7 +222,7 @@ func (src *SessionResumeChecker) CleanupOrphanedWorktrees(verbose func(string))
 
 	// Prune any dangling worktree references
 	if err := src.worktreeManager.Prune(); err != nil {
-		log.Printf("[session-resume] warning: failed to prune worktrees: %v", err)
+		src.log.Warn("failed to prune worktrees", logging.Err(err))
 	}
 
 	return cleaned, nil
@@ -258,12 +261,12 @@ func (src *SessionResumeChecker) ReconcileProgStateWithWorktrees(epicID string)
 			expectedWorktreeName := fmt.Sprintf("agent-%s", task.ID)
 			if _, exists := worktreeMap[expectedWorktreeName]; !exists {
 				// Worktree doesn't exist - task was interrupted
-				log.Printf("[session-resume] task %s marked in-progress but worktree missing, resetting to open", task.ID)
+				src.log.Info("task marked in progress but worktree missing, resetting to open", logging.Task(task.ID))
 				if err := src.progClient.Reopen(task.ID); err != nil {
-					log.Printf("[session-resume] warning: failed to reset task %s: %v", task.ID, err)
+					src.log.Warn("failed to reset task", logging.Task(task.ID), logging.Err(err))
 				} else {
 					if err := src.progClient.AddLog(task.ID, "Task reset to open: worktree not found during session resume"); err != nil {
-						log.Printf("[session-resume] warning: failed to log task reset: %v", err)
+						src.log.Warn("failed to log task reset", logging.Task(task.ID), logging.Err(err))
 					}
 				}
 			}
@@ -0,0 +1,289 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/internal/protect"
+)
+
+// SessionFileChange is one file a session changed.
+type SessionFileChange struct {
+	Path    string
+	Added   int
+	Deleted int
+	// Binary is set for binary files, which have no line counts.
+	Binary bool
+}
+
+// SessionRiskFlag is a changed file that touches a protected area.
+type SessionRiskFlag struct {
+	Path   string
+	Reason string
+}
+
+// SessionDiffSummary is what merging a session branch changes on the main
+// branch.
+type SessionDiffSummary struct {
+	SessionID  string
+	Branch     string
+	MainBranch string
+	// Commits is how many commits the session branch adds.
+	Commits int
+	Files   []SessionFileChange
+	// Added and Deleted are the line totals over all files.
+	Added   int
+	Deleted int
+	// RiskFlags are the changed files in protected areas.
+	RiskFlags []SessionRiskFlag
+}
+
+// Empty reports whether merging the session changes nothing.
+func (s *SessionDiffSummary) Empty() bool {
+	return len(s.Files) == 0
+}
+
+// ChangedFiles returns the paths of the changed files.
+func (s *SessionDiffSummary) ChangedFiles() []string {
+	paths := make([]string, len(s.Files))
+	for i, f := range s.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// String formats the summary for the terminal and logs.
+func (s *SessionDiffSummary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Session %s: %s -> %s\n", s.SessionID, s.Branch, s.MainBranch)
+	fmt.Fprintf(&sb, "  %d commit(s), %d file(s) changed, +%d -%d\n", s.Commits, len(s.Files), s.Added, s.Deleted)
+	for _, f := range s.Files {
+		if f.Binary {
+			fmt.Fprintf(&sb, "    %-50s  binary\n", f.Path)
+			continue
+		}
+		fmt.Fprintf(&sb, "    %-50s  +%d -%d\n", f.Path, f.Added, f.Deleted)
+	}
+	if len(s.RiskFlags) > 0 {
+		sb.WriteString("  Risk flags:\n")
+		for _, r := range s.RiskFlags {
+			fmt.Fprintf(&sb, "    %s: %s\n", r.Path, r.Reason)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// SessionMergeVerdict is a gate's decision on merging a session.
+type SessionMergeVerdict struct {
+	Approved bool
+	// Actor is who decided (ActorAlphie, ActorSecondReviewer or a HumanActor).
+	// Empty means the operator running the session.
+	Actor string
+	// Reason explains the decision.
+	Reason string
+}
+
+// SessionMergeGate decides whether a finished session may merge into the
+// main branch. A session that is not approved keeps its branch for the
+// operator to review and merge by hand.
+type SessionMergeGate interface {
+	ReviewSessionMerge(ctx context.Context, summary *SessionDiffSummary, diff string) (*SessionMergeVerdict, error)
+}
+
+// SessionMergeGateFunc adapts a function to a SessionMergeGate.
+type SessionMergeGateFunc func(ctx context.Context, summary *SessionDiffSummary, diff string) (*SessionMergeVerdict, error)
+
+// ReviewSessionMerge calls f.
+func (f SessionMergeGateFunc) ReviewSessionMerge(ctx context.Context, summary *SessionDiffSummary, diff string) (*SessionMergeVerdict, error) {
+	return f(ctx, summary, diff)
+}
+
+// holdSessionGate holds every session merge for the operator. It stands in
+// for a confirmation prompt when there is no terminal to prompt on.
+type holdSessionGate struct{}
+
+// ReviewSessionMerge rejects the merge.
+func (holdSessionGate) ReviewSessionMerge(_ context.Context, summary *SessionDiffSummary, _ string) (*SessionMergeVerdict, error) {
+	return &SessionMergeVerdict{
+		Actor:  ActorAlphie,
+		Reason: fmt.Sprintf("session merges need confirmation; review %s and merge it into %s by hand", summary.Branch, summary.MainBranch),
+	}, nil
+}
+
+// reviewSessionGate has the second reviewer panel review the whole session
+// diff before it lands.
+type reviewSessionGate struct {
+	reviewer *SecondReviewer
+}
+
+// NewReviewSessionGate returns a gate that approves a session merge when a
+// quorum of the reviewer panel approves the session diff.
+func NewReviewSessionGate(reviewer *SecondReviewer) SessionMergeGate {
+	return &reviewSessionGate{reviewer: reviewer}
+}
+
+// ReviewSessionMerge has the panel review the session diff.
+func (g *reviewSessionGate) ReviewSessionMerge(ctx context.Context, summary *SessionDiffSummary, diff string) (*SessionMergeVerdict, error) {
+	trigger := g.reviewer.ShouldSecondReview(diff, summary.ChangedFiles(), nil)
+	result, err := g.reviewer.ReviewWithPanel(ctx, diff, "Everything session "+summary.SessionID+" merges into "+summary.MainBranch, trigger.Types)
+	if err != nil {
+		return nil, err
+	}
+	verdict := &SessionMergeVerdict{Approved: result.Approved, Actor: ActorSecondReviewer}
+	if result.Approved {
+		verdict.Reason = fmt.Sprintf("Session diff approved by %d/%d reviewers, quorum %d", result.Approvals, len(result.Votes), result.Quorum)
+	} else {
+		concerns := strings.Join(result.Concerns(), "; ")
+		if concerns == "" {
+			concerns = "no specific concerns provided"
+		}
+		verdict.Reason = fmt.Sprintf("Session diff rejected by reviewers (%d/%d approved, quorum %d): %s",
+			result.Approvals, len(result.Votes), result.Quorum, concerns)
+	}
+	return verdict, nil
+}
+
+// newSessionMergeGate returns gate if set, else the gate the policy's
+// session review mode selects. It returns nil when sessions merge ungated.
+func newSessionMergeGate(gate SessionMergeGate, mode string, reviewer *SecondReviewer) SessionMergeGate {
+	if gate != nil {
+		return gate
+	}
+	switch mode {
+	case policy.SessionReviewConfirm:
+		return holdSessionGate{}
+	case policy.SessionReviewAuto:
+		if reviewer == nil {
+			pkgLog.Warn("session review is auto but no reviewer is configured; session merges are held")
+			return holdSessionGate{}
+		}
+		return NewReviewSessionGate(reviewer)
+	}
+	return nil
+}
+
+// reviewSessionMerge commits the session's pending changes, reports what
+// merging it changes and has the session gate, then the merge approval
+// gate if enabled, approve the merge. The returned error wraps
+// ErrSessionMergeRejected if the session must not merge.
+func (o *Orchestrator) reviewSessionMerge(ctx context.Context) error {
+	o.sessionMgr.CommitPending()
+	branch := o.sessionMgr.GetBranchName()
+	gated := o.sessionGate != nil || o.approvals.Enabled(policy.ApprovalMerge)
+	summary, err := o.sessionMgr.DiffSummary(o.protected)
+	if err != nil {
+		if !gated {
+			o.log.Warn("failed to summarize session diff", logging.Err(err))
+			return nil
+		}
+		return fmt.Errorf("%w: summarize session diff: %v (branch %s kept)", ErrSessionMergeRejected, err, branch)
+	}
+	o.logger.Log("[orchestrator] session merge summary:\n%s", summary)
+	o.emitEvent(OrchestratorEvent{
+		Type:      EventSessionReview,
+		Message:   summary.String(),
+		Timestamp: time.Now(),
+	})
+	if !gated || summary.Empty() {
+		return nil
+	}
+	if o.sessionGate == nil {
+		return o.approveSessionMerge(ctx, summary)
+	}
+
+	diff, err := o.sessionMgr.Diff()
+	if err != nil {
+		return fmt.Errorf("%w: diff session branch: %v (branch %s kept)", ErrSessionMergeRejected, err, branch)
+	}
+	verdict, err := o.sessionGate.ReviewSessionMerge(ctx, summary, diff)
+	if err != nil {
+		verdict = &SessionMergeVerdict{Actor: ActorAlphie, Reason: fmt.Sprintf("Session review could not run: %v", err)}
+	}
+	if verdict.Actor == "" {
+		verdict.Actor = HumanActor(o.config.Operator)
+	}
+	if !verdict.Approved {
+		o.recordDecision(Decision{Kind: DecisionRejection, Actor: verdict.Actor, Reason: verdict.Reason})
+		return fmt.Errorf("%w: %s (branch %s kept)", ErrSessionMergeRejected, verdict.Reason, branch)
+	}
+	o.recordDecision(Decision{Kind: DecisionApproval, Actor: verdict.Actor, Reason: verdict.Reason})
+	return o.approveSessionMerge(ctx, summary)
+}
+
+// approveSessionMerge holds the session at the merge approval gate, if
+// enabled. The returned error wraps ErrSessionMergeRejected if the operator
+// does not approve the merge.
+func (o *Orchestrator) approveSessionMerge(ctx context.Context, summary *SessionDiffSummary) error {
+	if err := o.awaitApproval(ctx, policy.ApprovalMerge, summary.String()); err != nil {
+		return fmt.Errorf("%w: %v (branch %s kept)", ErrSessionMergeRejected, err, summary.Branch)
+	}
+	return nil
+}
+
+// DiffSummary summarizes what merging the session branch changes on the
+// main branch. Changed files the detector considers protected are flagged;
+// detector may be nil. Uncommitted changes are not included; commit them
+// with CommitPending first.
+func (m *SessionBranchManager) DiffSummary(detector *protect.Detector) (*SessionDiffSummary, error) {
+	mainBranch, err := m.MainBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine main branch: %w", err)
+	}
+	summary := &SessionDiffSummary{SessionID: m.sessionID, Branch: m.branchName, MainBranch: mainBranch}
+	if m.branchName == "" {
+		return summary, nil
+	}
+
+	count, err := m.git.Run("rev-list", "--count", mainBranch+".."+m.branchName)
+	if err != nil {
+		return nil, fmt.Errorf("count session commits: %w", err)
+	}
+	summary.Commits, _ = strconv.Atoi(strings.TrimSpace(count))
+
+	numstat, err := m.git.Run("diff", "--numstat", mainBranch+"..."+m.branchName)
+	if err != nil {
+		return nil, fmt.Errorf("diff session branch: %w", err)
+	}
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		f := SessionFileChange{Path: fields[2]}
+		added, addErr := strconv.Atoi(fields[0])
+		deleted, delErr := strconv.Atoi(fields[1])
+		if addErr != nil || delErr != nil {
+			f.Binary = true
+		} else {
+			f.Added, f.Deleted = added, deleted
+		}
+		summary.Files = append(summary.Files, f)
+		summary.Added += f.Added
+		summary.Deleted += f.Deleted
+
+		if detector != nil {
+			if protected, reason := detector.IsProtectedWithReason(f.Path); protected {
+				summary.RiskFlags = append(summary.RiskFlags, SessionRiskFlag{Path: f.Path, Reason: reason})
+			}
+		}
+	}
+	return summary, nil
+}
+
+// Diff returns the diff merging the session branch applies to the main branch.
+func (m *SessionBranchManager) Diff() (string, error) {
+	if m.branchName == "" {
+		return "", nil
+	}
+	mainBranch, err := m.MainBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine main branch: %w", err)
+	}
+	return m.git.Run("diff", mainBranch+"..."+m.branchName)
+}
@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/protect"
+)
+
+// setupSessionBranch creates a repository whose session branch changes a
+// protected file and a README, and returns its session manager.
+func setupSessionBranch(t *testing.T) (*SessionBranchManager, string) {
+	t.Helper()
+	repo := t.TempDir()
+	if err := initGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	mainBranch := gitOutput(t, repo, "rev-parse", "--abbrev-ref", "HEAD")
+
+	mgr := NewSessionBranchManager("s1", repo, false)
+	mgr.SetMainBranch(mainBranch)
+	if err := mgr.CreateBranch(); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "auth", "login.go"), []byte("package auth\n\nfunc Login() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, repo, "add", ".")
+	gitOutput(t, repo, "commit", "-m", "Add login")
+	// Left uncommitted, as agent merges can leave changes
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Test\nUpdated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return mgr, repo
+}
+
+func TestSessionBranchManager_DiffSummary(t *testing.T) {
+	mgr, _ := setupSessionBranch(t)
+	mgr.CommitPending()
+
+	summary, err := mgr.DiffSummary(protect.New())
+	if err != nil {
+		t.Fatalf("DiffSummary() error = %v", err)
+	}
+	if summary.Branch != "session-s1" || summary.Commits != 2 || len(summary.Files) != 2 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if summary.Added != 5 || summary.Deleted != 1 {
+		t.Errorf("lines = +%d -%d, want +5 -1", summary.Added, summary.Deleted)
+	}
+	if len(summary.RiskFlags) != 1 || summary.RiskFlags[0].Path != "auth/login.go" {
+		t.Errorf("risk flags = %+v, want auth/login.go", summary.RiskFlags)
+	}
+
+	diff, err := mgr.Diff()
+	if err != nil || diff == "" {
+		t.Errorf("Diff() = %q, %v", diff, err)
+	}
+}
+
+func TestReviewSessionMerge_Gate(t *testing.T) {
+	var reviewed *SessionDiffSummary
+	approve := true
+	gate := SessionMergeGateFunc(func(_ context.Context, summary *SessionDiffSummary, diff string) (*SessionMergeVerdict, error) {
+		reviewed = summary
+		return &SessionMergeVerdict{Approved: approve, Reason: "checked"}, nil
+	})
+
+	mgr, repo := setupSessionBranch(t)
+	o := &Orchestrator{
+		log:         pkgLog,
+		config:      &OrchestratorRunConfig{SessionID: "s1", Operator: "dev@example.com", MainBranch: mgr.mainBranch, RepoPath: repo},
+		sessionMgr:  mgr,
+		sessionGate: gate,
+		protected:   protect.New(),
+		emitter:     NewEventEmitter(10),
+		logger:      NopLogger(),
+	}
+	if err := o.reviewSessionMerge(context.Background()); err != nil {
+		t.Fatalf("reviewSessionMerge() error = %v", err)
+	}
+	if reviewed == nil || len(reviewed.Files) != 2 {
+		t.Fatalf("gate reviewed %+v, want both files including the uncommitted one", reviewed)
+	}
+
+	// A rejected session keeps its branch
+	approve = false
+	err := o.finalizeSession(context.Background())
+	if !errors.Is(err, ErrSessionMergeRejected) {
+		t.Fatalf("finalizeSession() error = %v, want ErrSessionMergeRejected", err)
+	}
+	if exists, _ := mgr.git.BranchExists("session-s1"); !exists {
+		t.Error("expected the session branch to be kept")
+	}
+	if n := gitOutput(t, repo, "rev-list", "--count", mgr.mainBranch); n != "1" {
+		t.Errorf("%s has %s commits, want it untouched", mgr.mainBranch, n)
+	}
+
+	// Without a gate the session merges as before
+	o.sessionGate = newSessionMergeGate(nil, "none", nil)
+	if err := o.finalizeSession(context.Background()); err != nil {
+		t.Fatalf("finalizeSession() error = %v", err)
+	}
+	if gitOutput(t, repo, "rev-list", "--count", mgr.mainBranch) == "1" {
+		t.Error("expected the session to merge")
+	}
+}
+
+func TestNewSessionMergeGate(t *testing.T) {
+	summary := &SessionDiffSummary{Branch: "session-s1", MainBranch: "main", Files: []SessionFileChange{{Path: "a.go"}}}
+	gate := newSessionMergeGate(nil, "confirm", nil)
+	verdict, err := gate.ReviewSessionMerge(context.Background(), summary, "")
+	if err != nil || verdict.Approved {
+		t.Errorf("confirm without a prompt = %+v, %v, want the merge held", verdict, err)
+	}
+	if gate := newSessionMergeGate(nil, "auto", nil); gate == nil {
+		t.Error("expected auto without a reviewer to hold merges")
+	}
+	if gate := newSessionMergeGate(nil, "none", nil); gate != nil {
+		t.Errorf("expected no gate, got %T", gate)
+	}
+}
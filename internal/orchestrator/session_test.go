@@ -1,6 +1,8 @@
 package orchestrator
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -206,3 +208,48 @@ func TestSessionBranchManager_GreenfieldMode(t *testing.T) {
 		t.Errorf("expected empty branch name in greenfield mode, got %q", manager.GetBranchName())
 	}
 }
+
+func TestSessionBranchManager_MainBranchOverride(t *testing.T) {
+	manager := NewSessionBranchManager("test", "/tmp/fake-repo", false)
+	manager.SetMainBranch("trunk")
+
+	branch, err := manager.MainBranch()
+	if err != nil || branch != "trunk" {
+		t.Errorf("MainBranch() = %q, %v; want trunk", branch, err)
+	}
+	if !manager.IsProtected("trunk") {
+		t.Error("configured main branch should be protected")
+	}
+}
+
+func TestSessionBranchManager_CreateBranchFromMainBranch(t *testing.T) {
+	repo := t.TempDir()
+	if err := initGitRepo(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	gitOutput(t, repo, "branch", "-M", "trunk")
+	base := gitOutput(t, repo, "rev-parse", "HEAD")
+
+	// Work checked out on another branch stays out of the session
+	gitOutput(t, repo, "checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, repo, "add", ".")
+	gitOutput(t, repo, "commit", "-m", "Feature work")
+
+	manager := NewSessionBranchManager("s1", repo, false)
+	manager.SetMainBranch("trunk")
+	if err := manager.CreateBranch(); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if branch := gitOutput(t, repo, "rev-parse", "--abbrev-ref", "HEAD"); branch != "session-s1" {
+		t.Errorf("checked out %s, want session-s1", branch)
+	}
+	if head := gitOutput(t, repo, "rev-parse", "HEAD"); head != base {
+		t.Errorf("session branch starts at %s, want trunk at %s", head, base)
+	}
+	if commit, err := manager.MainCommit(); err != nil || commit != base {
+		t.Errorf("MainCommit() = %s, %v, want %s", commit, err, base)
+	}
+}
@@ -0,0 +1,186 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// errSessionDrained ends the run loop once a graceful shutdown has drained
+// the running agents.
+var errSessionDrained = errors.New("session drained")
+
+// Checkpoint records where a gracefully shut down session stopped.
+type Checkpoint struct {
+	// SessionID is the interrupted session.
+	SessionID string
+	// EpicID is the prog epic holding the session's tasks, if any.
+	EpicID string
+	// CompletedTasks is how many tasks finished and were merged.
+	CompletedTasks int
+	// TotalTasks is how many tasks the session had.
+	TotalTasks int
+	// InterruptedTasks are the titles of tasks whose agents were still
+	// running when the grace window ran out. They re-run on resume.
+	InterruptedTasks []string
+}
+
+// ResumeCommand returns the command that resumes the session, or "" if
+// its tasks are not tracked in prog and cannot be resumed.
+func (c *Checkpoint) ResumeCommand(request string) string {
+	if c.EpicID == "" {
+		return ""
+	}
+	return fmt.Sprintf("alphie run --epic %s %q", c.EpicID, request)
+}
+
+// drainState tracks a graceful shutdown in progress.
+type drainState struct {
+	mu sync.Mutex
+	// requested is set once Drain is called.
+	requested bool
+	// deadline is when agents still running are interrupted.
+	deadline time.Time
+	// interrupted are the tasks whose agents were stopped at the deadline.
+	interrupted []*models.Task
+	// aborted is set when the session is drained by Abort.
+	aborted bool
+}
+
+// Drain starts a graceful shutdown: no new tasks are scheduled, and running
+// agents get up to grace to finish and merge their work. Agents still
+// running after that are stopped and their tasks reset to pending. Run then
+// merges the completed work, checkpoints the session in the state database
+// and returns a *SessionInterruptedError describing how to resume.
+// Drain returns immediately; calling it again has no effect.
+func (o *Orchestrator) Drain(grace time.Duration) {
+	o.drain.mu.Lock()
+	if o.drain.requested {
+		o.drain.mu.Unlock()
+		return
+	}
+	o.drain.requested = true
+	o.drain.deadline = time.Now().Add(grace)
+	o.drain.mu.Unlock()
+
+	o.log.Info("draining: no new tasks will start, waiting for running agents", "grace", grace)
+	// A paused run loop must wake up to notice the drain
+	o.pauseCtrl.Drain()
+}
+
+// Abort stops the session without keeping its work: running agents are
+// stopped at once and Run returns ErrSessionAborted without merging the
+// session branch. Rolling back what the session already did is left to
+// RollbackSession.
+func (o *Orchestrator) Abort() {
+	o.drain.mu.Lock()
+	o.drain.aborted = true
+	o.drain.mu.Unlock()
+
+	o.log.Info("aborting: stopping running agents")
+	o.recordDecision(Decision{
+		Kind:   DecisionRejection,
+		Actor:  HumanActor(o.config.Operator),
+		Reason: "Session aborted via control socket",
+	})
+	o.Drain(0)
+}
+
+// isAborted returns whether the session is being stopped by Abort.
+func (o *Orchestrator) isAborted() bool {
+	o.drain.mu.Lock()
+	defer o.drain.mu.Unlock()
+	return o.drain.aborted
+}
+
+// IsDraining returns whether a graceful shutdown is in progress.
+func (o *Orchestrator) IsDraining() bool {
+	o.drain.mu.Lock()
+	defer o.drain.mu.Unlock()
+	return o.drain.requested
+}
+
+// drainStep checks a draining session's running agents. It reports true once
+// none are left, interrupting those still running past the grace window.
+func (o *Orchestrator) drainStep(inflightTasks map[string]*inflight, inflightMu *sync.Mutex) bool {
+	o.drain.mu.Lock()
+	deadline := o.drain.deadline
+	o.drain.mu.Unlock()
+
+	inflightMu.Lock()
+	if len(inflightTasks) == 0 {
+		inflightMu.Unlock()
+		return true
+	}
+	if time.Now().Before(deadline) {
+		o.logger.Log("[runLoop] draining: waiting for %d running agent(s)", len(inflightTasks))
+		inflightMu.Unlock()
+		return false
+	}
+	stopped := make([]*inflight, 0, len(inflightTasks))
+	for taskID, inf := range inflightTasks {
+		stopped = append(stopped, inf)
+		delete(inflightTasks, taskID)
+	}
+	inflightMu.Unlock()
+
+	for _, inf := range stopped {
+		o.interruptTask(inf)
+	}
+	return true
+}
+
+// interruptTask stops an agent that outlived the grace window and resets
+// its task to pending, so it re-runs when the session is resumed.
+func (o *Orchestrator) interruptTask(inf *inflight) {
+	task := o.stopAgent(inf, true)
+	if task == nil {
+		return
+	}
+	o.progCoord.LogTask(task.ID, "Interrupted by shutdown before finishing; re-runs when the epic is resumed")
+	o.log.Info("interrupted task at end of shutdown grace window", logging.Task(task.ID), logging.Agent(inf.agentID))
+
+	o.drain.mu.Lock()
+	o.drain.interrupted = append(o.drain.interrupted, task)
+	o.drain.mu.Unlock()
+}
+
+// checkpointSession finishes a drained session: the work merged so far is
+// kept, the session is marked interrupted in the state database and the
+// returned error carries the checkpoint.
+func (o *Orchestrator) checkpointSession(ctx context.Context) error {
+	if err := o.finalizeSession(ctx); err != nil {
+		o.log.Warn("interrupted session not merged", logging.Err(err))
+	}
+	o.updateSessionStatus(state.SessionInterrupted)
+
+	checkpoint := &Checkpoint{
+		SessionID:      o.config.SessionID,
+		EpicID:         o.progCoord.EpicID(),
+		CompletedTasks: len(o.graph.GetCompletedIDs()),
+		TotalTasks:     o.graph.Size(),
+	}
+	o.drain.mu.Lock()
+	for _, task := range o.drain.interrupted {
+		checkpoint.InterruptedTasks = append(checkpoint.InterruptedTasks, task.Title)
+	}
+	o.drain.mu.Unlock()
+	sort.Strings(checkpoint.InterruptedTasks)
+
+	o.recordDecision(Decision{
+		Kind:   DecisionWaiver,
+		Actor:  HumanActor(o.config.Operator),
+		Reason: fmt.Sprintf("Session shut down gracefully with %d/%d tasks complete", checkpoint.CompletedTasks, checkpoint.TotalTasks),
+	})
+	o.log.Info("session checkpointed",
+		"completed", checkpoint.CompletedTasks, "total", checkpoint.TotalTasks, "interrupted", len(checkpoint.InterruptedTasks))
+	return &SessionInterruptedError{Checkpoint: checkpoint}
+}
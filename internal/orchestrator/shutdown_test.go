@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// newDrainTestOrchestrator builds an orchestrator with one task in flight.
+func newDrainTestOrchestrator(t *testing.T) (*Orchestrator, *models.Task, *bool) {
+	t.Helper()
+	g := graph.New()
+	task := &models.Task{ID: "t1", Title: "Long task", Status: models.TaskStatusInProgress}
+	if err := g.Build([]*models.Task{task}); err != nil {
+		t.Fatal(err)
+	}
+	o := &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "s1"},
+		graph:     g,
+		collision: NewCollisionChecker(),
+		progCoord: NewProgCoordinator(nil, nil, "", models.TierBuilder, ""),
+		pauseCtrl: NewPauseController(),
+		logger:    NopLogger(),
+	}
+	o.scheduler = NewScheduler(g, models.TierBuilder, 2)
+	o.scheduler.OnAgentStart(&models.Agent{ID: "a1", TaskID: "t1"})
+
+	cancelled := false
+	o.inflightTasks = map[string]*inflight{
+		"t1": {taskID: "t1", agentID: "a1", cancelFn: func(error) { cancelled = true }},
+	}
+	return o, task, &cancelled
+}
+
+func TestDrainStep_WaitsThenInterrupts(t *testing.T) {
+	o, task, cancelled := newDrainTestOrchestrator(t)
+	var mu sync.Mutex
+
+	o.Drain(time.Hour)
+	if !o.IsDraining() {
+		t.Fatal("expected orchestrator to be draining")
+	}
+	if o.drainStep(o.inflightTasks, &mu) {
+		t.Fatal("drainStep() should wait for running agents within the grace window")
+	}
+	if *cancelled {
+		t.Fatal("agent should not be cancelled within the grace window")
+	}
+
+	// Grace window over: the running agent is interrupted
+	o.drain.deadline = time.Now().Add(-time.Second)
+	if !o.drainStep(o.inflightTasks, &mu) {
+		t.Fatal("drainStep() should finish once the grace window has passed")
+	}
+	if !*cancelled {
+		t.Error("expected the agent to be cancelled")
+	}
+	if task.Status != models.TaskStatusPending {
+		t.Errorf("interrupted task status = %s, want pending", task.Status)
+	}
+	if o.scheduler.GetRunningCount() != 0 {
+		t.Error("expected the agent to be removed from the scheduler")
+	}
+
+	err := o.checkpointSession(context.Background())
+	var interrupted *SessionInterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, ErrSessionInterrupted) {
+		t.Fatalf("checkpointSession() error = %v, want *SessionInterruptedError", err)
+	}
+	c := interrupted.Checkpoint
+	if c.SessionID != "s1" || c.TotalTasks != 1 || len(c.InterruptedTasks) != 1 || c.InterruptedTasks[0] != "Long task" {
+		t.Errorf("unexpected checkpoint: %+v", c)
+	}
+}
+
+func TestInterruptTask_WaitsForCheckpoint(t *testing.T) {
+	o, task, _ := newDrainTestOrchestrator(t)
+
+	// The agent exits shortly after being suspended, once it has committed
+	var cause error
+	var exited bool
+	inf := &inflight{taskID: "t1", agentID: "a1", doneCh: make(chan *agent.ExecutionResult, 1)}
+	inf.cancelFn = func(err error) {
+		cause = err
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			exited = true
+			inf.doneCh <- &agent.ExecutionResult{}
+		}()
+	}
+
+	o.interruptTask(inf)
+	if !errors.Is(cause, agent.ErrSuspended) {
+		t.Errorf("cancel cause = %v, want agent.ErrSuspended", cause)
+	}
+	if !exited {
+		t.Error("task was requeued before its agent exited")
+	}
+	if task.Status != models.TaskStatusPending {
+		t.Errorf("task status = %s, want pending", task.Status)
+	}
+}
+
+func TestDrainStep_FinishesWhenAgentsDone(t *testing.T) {
+	o, _, cancelled := newDrainTestOrchestrator(t)
+	var mu sync.Mutex
+	o.Drain(time.Hour)
+	delete(o.inflightTasks, "t1")
+
+	if !o.drainStep(o.inflightTasks, &mu) {
+		t.Fatal("drainStep() should finish when no agents are running")
+	}
+	if *cancelled {
+		t.Error("a finished agent should not be cancelled")
+	}
+}
+
+func TestDrain_ReleasesPausedWaiters(t *testing.T) {
+	o, _, _ := newDrainTestOrchestrator(t)
+	o.Pause()
+
+	done := make(chan error, 1)
+	go func() { done <- o.pauseCtrl.WaitIfPaused(context.Background()) }()
+
+	o.Drain(time.Minute)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WaitIfPaused() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitIfPaused() still blocked after Drain")
+	}
+}
+
+func TestCheckpoint_ResumeCommand(t *testing.T) {
+	c := &Checkpoint{EpicID: "ep-123"}
+	if got, want := c.ResumeCommand("Add auth"), `alphie run --epic ep-123 "Add auth"`; got != want {
+		t.Errorf("ResumeCommand() = %q, want %q", got, want)
+	}
+	if got := (&Checkpoint{}).ResumeCommand("Add auth"); got != "" {
+		t.Errorf("ResumeCommand() without epic = %q, want empty", got)
+	}
+}
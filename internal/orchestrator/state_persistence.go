@@ -4,6 +4,9 @@ package orchestrator
 import (
 	"time"
 
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
 	"github.com/ShayCichocki/alphie/internal/state"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
@@ -40,14 +43,17 @@ func (o *Orchestrator) updateSessionStatus(status state.SessionStatus) {
 	o.stateDB.UpdateSession(session)
 }
 
-// persistTasks creates task records in the state database.
+// persistTasks creates task records in the state database. Tasks of a
+// resumed epic that an earlier session already recorded are updated instead.
+// Stores that save tasks in batches record them in one transaction.
 func (o *Orchestrator) persistTasks(tasks []*models.Task) error {
 	if o.stateDB == nil {
 		return nil // No-op if state DB not configured
 	}
 
+	stateTasks := make([]state.Task, 0, len(tasks))
 	for _, t := range tasks {
-		stateTask := &state.Task{
+		stateTasks = append(stateTasks, state.Task{
 			ID:          t.ID,
 			ParentID:    t.ParentID,
 			Title:       t.Title,
@@ -56,6 +62,25 @@ func (o *Orchestrator) persistTasks(tasks []*models.Task) error {
 			DependsOn:   t.DependsOn,
 			Tier:        string(t.Tier),
 			CreatedAt:   t.CreatedAt,
+		})
+	}
+	if batch, ok := o.stateDB.(state.TaskBatchStore); ok {
+		return batch.SaveTasks(stateTasks)
+	}
+
+	for i := range stateTasks {
+		stateTask := &stateTasks[i]
+		existing, err := o.stateDB.GetTask(stateTask.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			stateTask.CreatedAt = existing.CreatedAt
+			stateTask.CompletedAt = existing.CompletedAt
+			if err := o.stateDB.UpdateTask(stateTask); err != nil {
+				return err
+			}
+			continue
 		}
 		if err := o.stateDB.CreateTask(stateTask); err != nil {
 			return err
@@ -65,6 +90,43 @@ func (o *Orchestrator) persistTasks(tasks []*models.Task) error {
 	return nil
 }
 
+// recordSessionOrigin saves the commit and branch the session starts from,
+// its session branch, epic and tasks, so alphie abort can roll the session
+// back. It must run before the session branch is checked out.
+func (o *Orchestrator) recordSessionOrigin(tasks []*models.Task) {
+	store, ok := o.stateDB.(state.SessionOriginStore)
+	if !ok || o.config.PlainMode() {
+		return
+	}
+	branch, err := o.sessionMgr.MainBranch()
+	if err != nil {
+		o.log.Warn("failed to record session origin", logging.Err(err))
+		return
+	}
+	commit, err := o.sessionMgr.MainCommit()
+	if err != nil {
+		o.log.Warn("failed to record session origin", logging.Err(err))
+		return
+	}
+
+	origin := &state.SessionOrigin{
+		SessionID:     o.config.SessionID,
+		EpicID:        o.progCoord.EpicID(),
+		SessionBranch: o.sessionMgr.GetBranchName(),
+		BaseBranch:    branch,
+		BaseCommit:    commit,
+	}
+	if err := store.SaveSessionOrigin(origin); err != nil {
+		o.log.Warn("failed to record session origin", logging.Err(err))
+		return
+	}
+	for _, task := range tasks {
+		if err := store.AddSessionTask(o.config.SessionID, task.ID); err != nil {
+			o.log.Warn("failed to record session task", logging.Task(task.ID), logging.Err(err))
+		}
+	}
+}
+
 // updateTaskState updates a task's status in the state database.
 func (o *Orchestrator) updateTaskState(task *models.Task) {
 	if o.stateDB == nil {
@@ -117,3 +179,158 @@ func (o *Orchestrator) updateAgentState(agentID string, status string) {
 	agent.Status = state.AgentStatus(status)
 	o.stateDB.UpdateAgent(agent)
 }
+
+// finishAgentState records an agent's final status and usage in the state
+// database, so completed tasks can calibrate later cost estimates.
+func (o *Orchestrator) finishAgentState(result *agent.ExecutionResult, status string) {
+	if o.stateDB == nil {
+		return // No-op if state DB not configured
+	}
+
+	a, err := o.stateDB.GetAgent(result.AgentID)
+	if err != nil || a == nil {
+		return
+	}
+
+	a.Status = state.AgentStatus(status)
+	a.TokensUsed = int(result.TokensUsed)
+	a.Cost = result.Cost
+	o.stateDB.UpdateAgent(a)
+}
+
+// persistTaskAttempt records a finished task attempt for historical
+// analytics. Cancelled attempts are not recorded.
+func (o *Orchestrator) persistTaskAttempt(outcome *TaskOutcome) {
+	recorder, ok := o.stateDB.(state.AttemptRecorder)
+	if !ok || outcome == nil || outcome.Status == OutcomeCancelled {
+		return
+	}
+
+	attempt := &state.TaskAttempt{
+		SessionID: o.config.SessionID,
+		TaskID:    outcome.TaskID,
+		Outcome:   state.AttemptOutcome(outcome.Status.String()),
+		Duration:  outcome.Duration,
+	}
+	task := o.graph.GetTask(outcome.TaskID)
+	if task != nil {
+		attempt.Tier = string(task.Tier)
+		attempt.TaskType = string(task.TaskType)
+	}
+	if outcome.Result != nil {
+		attempt.TokensUsed = int(outcome.Result.TokensUsed)
+		attempt.Cost = outcome.Result.Cost
+	}
+	if m := outcome.MergeResult; m != nil {
+		attempt.MergeAttempted = true
+		attempt.MergeConflict = len(m.ConflictFiles) > 0 || m.Decision != nil || m.Review != nil
+	}
+
+	switch outcome.Status {
+	case OutcomeMergeFailed:
+		attempt.MergeAttempted = true
+		attempt.FailureCategory = "merge"
+	case OutcomeAborted:
+		attempt.FailureCategory = policy.FailureVerification
+	case OutcomeFailed:
+		if task != nil && outcome.Result != nil {
+			attempt.FailureCategory = o.classifyFailure(task, outcome.Result)
+		} else {
+			attempt.FailureCategory = policy.FailureExecution
+		}
+	}
+
+	if err := recorder.RecordTaskAttempt(attempt); err != nil {
+		o.log.Warn("failed to record task attempt", logging.Task(outcome.TaskID), logging.Err(err))
+	}
+}
+
+// persistReviewFindings records a task's second review findings.
+func (o *Orchestrator) persistReviewFindings(taskID string, findings []ReviewFinding) {
+	store, ok := o.stateDB.(state.ReviewFindingStore)
+	if !ok || len(findings) == 0 {
+		return
+	}
+
+	records := make([]state.ReviewFinding, 0, len(findings))
+	for _, f := range findings {
+		records = append(records, state.ReviewFinding{
+			SessionID: o.config.SessionID,
+			TaskID:    taskID,
+			Reviewer:  f.Reviewer,
+			Severity:  f.Severity,
+			File:      f.File,
+			Line:      f.Line,
+			Message:   f.Message,
+		})
+	}
+	if err := store.RecordReviewFindings(records); err != nil {
+		o.log.Warn("failed to record review findings", logging.Task(taskID), logging.Err(err))
+	}
+}
+
+// persistSecurityFindings records what a task's security validation layer
+// found.
+func (o *Orchestrator) persistSecurityFindings(taskID string, result *agent.ExecutionResult) {
+	store, ok := o.stateDB.(state.SecurityFindingStore)
+	if !ok || result == nil || len(result.SecurityFindings) == 0 {
+		return
+	}
+
+	records := make([]state.SecurityFinding, 0, len(result.SecurityFindings))
+	for _, f := range result.SecurityFindings {
+		records = append(records, state.SecurityFinding{
+			SessionID: o.config.SessionID,
+			TaskID:    taskID,
+			Kind:      f.Kind,
+			Rule:      f.Rule,
+			Severity:  f.Severity,
+			File:      f.File,
+			Line:      f.Line,
+			Message:   f.Message,
+			Blocking:  f.Blocking,
+		})
+	}
+	if err := store.RecordSecurityFindings(records); err != nil {
+		o.log.Warn("failed to record security findings", logging.Task(taskID), logging.Err(err))
+	}
+}
+
+// persistBaseline saves the session's baseline to the state DB.
+func (o *Orchestrator) persistBaseline(baseline *agent.Baseline) {
+	store, ok := o.stateDB.(state.BaselineStore)
+	if !ok {
+		return
+	}
+	err := store.SaveBaseline(&state.Baseline{
+		SessionID:    o.config.SessionID,
+		Commit:       baseline.Commit,
+		BuildPassed:  baseline.BuildPassed,
+		FailingTests: baseline.FailingTests,
+		LintErrors:   baseline.LintErrors,
+		TypeErrors:   baseline.TypeErrors,
+		CapturedAt:   baseline.CapturedAt,
+	})
+	if err != nil {
+		o.log.Warn("failed to save baseline", logging.Err(err))
+	}
+}
+
+// taskHistoryWindow is how many recent attempts of a task type are
+// considered when judging how reliably that type succeeds.
+const taskHistoryWindow = 20
+
+// taskHistory returns the success record of recent tasks of the same type
+// as task. It is empty if the task is untyped or history is unavailable.
+func (o *Orchestrator) taskHistory(task *models.Task) agent.TaskHistory {
+	history, ok := o.stateDB.(state.TaskTypeHistory)
+	if !ok || task.TaskType == "" {
+		return agent.TaskHistory{}
+	}
+	succeeded, attempts, err := history.TaskTypeSuccess(string(task.TaskType), taskHistoryWindow)
+	if err != nil {
+		o.log.Warn("failed to load task history", logging.Task(task.ID), "task_type", task.TaskType, logging.Err(err))
+		return agent.TaskHistory{}
+	}
+	return agent.TaskHistory{Succeeded: succeeded, Attempts: attempts}
+}
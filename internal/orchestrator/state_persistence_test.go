@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestPersistTaskAttempt(t *testing.T) {
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open state db: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	g := graph.New()
+	if err := g.Build([]*models.Task{
+		{ID: "ok", Title: "ok", Tier: models.TierBuilder, TaskType: models.TaskTypeFeature},
+		{ID: "conflict", Title: "conflict", Tier: models.TierQuick, TaskType: models.TaskTypeFeature},
+		{ID: "broken", Title: "broken", Tier: models.TierQuick, TaskType: models.TaskTypeBugfix},
+	}); err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	o := &Orchestrator{
+		log:     pkgLog,
+		config:  &OrchestratorRunConfig{SessionID: "sess"},
+		graph:   g,
+		stateDB: db,
+	}
+
+	o.persistTaskAttempt(&TaskOutcome{
+		Status:      OutcomeSuccess,
+		TaskID:      "ok",
+		Result:      &agent.ExecutionResult{Success: true, TokensUsed: 1000, Cost: 0.30},
+		Duration:    time.Minute,
+		MergeResult: &MergeOutcome{Success: true},
+	})
+	o.persistTaskAttempt(&TaskOutcome{
+		Status:      OutcomeMergeFailed,
+		TaskID:      "conflict",
+		Result:      &agent.ExecutionResult{Success: true, Cost: 0.10},
+		MergeResult: &MergeOutcome{ConflictFiles: []string{"main.go"}},
+	})
+	o.persistTaskAttempt(&TaskOutcome{
+		Status: OutcomeFailed,
+		TaskID: "broken",
+		Result: &agent.ExecutionResult{Error: "context deadline exceeded", Cost: 0.05},
+	})
+	o.persistTaskAttempt(&TaskOutcome{Status: OutcomeCancelled, TaskID: "ok"})
+
+	report, err := db.Report(time.Time{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.Tasks.Attempts != 3 || report.Tasks.Succeeded != 1 {
+		t.Errorf("expected cancelled attempts to be skipped, got %+v", report.Tasks)
+	}
+	if report.Merges.Attempted != 2 || report.Merges.Conflicts != 1 {
+		t.Errorf("merges = %+v", report.Merges)
+	}
+	want := map[string]int{"merge": 1, "timeout": 1}
+	if len(report.Failures) != len(want) {
+		t.Fatalf("failures = %+v", report.Failures)
+	}
+	for _, f := range report.Failures {
+		if want[f.Category] != f.Count {
+			t.Errorf("failure %s = %d, want %d", f.Category, f.Count, want[f.Category])
+		}
+	}
+	if len(report.Tiers) != 2 || report.Tiers[0].Tier != "builder" || report.Tiers[0].Succeeded != 1 {
+		t.Errorf("tiers = %+v", report.Tiers)
+	}
+
+	if h := o.taskHistory(g.GetTask("conflict")); h.Succeeded != 1 || h.Attempts != 2 {
+		t.Errorf("feature history = %+v, want 1/2", h)
+	}
+	if h := o.taskHistory(&models.Task{ID: "untyped"}); h.Attempts != 0 {
+		t.Errorf("expected no history for an untyped task, got %+v", h)
+	}
+}
+
+func TestCaptureBaseline_UsesGivenBaseline(t *testing.T) {
+	db, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open state db: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	given := &agent.Baseline{
+		Commit:       "abc123",
+		BuildPassed:  true,
+		FailingTests: []string{"example.com/app/TestLegacy"},
+		CapturedAt:   time.Now(),
+	}
+	o := &Orchestrator{
+		log:     pkgLog,
+		config:  &OrchestratorRunConfig{SessionID: "sess", RepoPath: t.TempDir(), Baseline: given},
+		stateDB: db,
+		logger:  NopLogger(),
+	}
+
+	if err := o.captureBaseline(); err != nil {
+		t.Fatalf("captureBaseline failed: %v", err)
+	}
+	if o.config.Baseline != given {
+		t.Error("expected the given baseline to be used instead of capturing one")
+	}
+
+	saved, err := db.GetBaseline("sess")
+	if err != nil || saved == nil {
+		t.Fatalf("expected the baseline to be saved with the session, got %v, %v", saved, err)
+	}
+	if saved.Commit != "abc123" || !saved.BuildPassed || len(saved.FailingTests) != 1 {
+		t.Errorf("saved baseline = %+v", saved)
+	}
+}
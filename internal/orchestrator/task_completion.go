@@ -4,12 +4,13 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/ShayCichocki/alphie/internal/agent"
 	"github.com/ShayCichocki/alphie/internal/learning"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/merge"
 	"github.com/ShayCichocki/alphie/pkg/models"
 )
 
@@ -33,6 +34,13 @@ func (o *Orchestrator) handleTaskCompletion(ctx context.Context, taskID string,
 	// Unregister from collision checker
 	o.collision.UnregisterAgent(result.AgentID)
 
+	// Keep a readable record of the attempt, before the retry feedback is replaced
+	o.exportTranscript(task, result)
+
+	// Record final task cost against the budget
+	o.recordTaskSpend(task, result.Cost, false)
+	o.emitTaskUsage(task, result)
+
 	// Update scheduler - pass success so failed tasks don't unblock dependents
 	o.logger.Log("[handleTaskCompletion] calling scheduler.OnAgentComplete(%s, success=%v)", result.AgentID, result.Success)
 	o.scheduler.OnAgentComplete(result.AgentID, result.Success)
@@ -51,7 +59,7 @@ func (o *Orchestrator) handleTaskCompletion(ctx context.Context, taskID string,
 			TaskID:   taskID,
 			AgentID:  result.AgentID,
 			Result:   result,
-			Error:    fmt.Errorf("aborted after %d iterations without passing verification", result.LoopIterations),
+			Error:    fmt.Errorf("%w: aborted after %d iterations", ErrVerificationFailed, result.LoopIterations),
 			Duration: time.Since(startTime),
 		}
 	}
@@ -85,7 +93,7 @@ func (o *Orchestrator) handleTaskCompletion(ctx context.Context, taskID string,
 		TaskID:   taskID,
 		AgentID:  result.AgentID,
 		Result:   result,
-		Error:    fmt.Errorf("%s", result.Error),
+		Error:    taskFailureError(result),
 		Duration: time.Since(startTime),
 	}
 }
@@ -97,12 +105,18 @@ func (o *Orchestrator) handleAbortedTask(task *models.Task, result *agent.Execut
 	// Build detailed failure message
 	failureMsg := fmt.Sprintf("Task aborted: max iterations reached (%d) without passing verification. %s",
 		result.LoopIterations, result.VerifySummary)
+	o.finishAgentState(result, "failed")
+
+	// A human may know why verification keeps failing
+	reason := fmt.Sprintf("did not pass verification in %d iterations", result.LoopIterations)
+	if o.escalateTask(task, reason, escalationContext(task, result)) {
+		return
+	}
 
 	// Mark task as failed
 	task.Status = models.TaskStatusFailed
 	task.Error = failureMsg
 	o.updateTaskState(task)
-	o.updateAgentState(result.AgentID, "failed")
 
 	// Update prog task status
 	o.progCoord.BlockTask(task.ID, failureMsg)
@@ -115,7 +129,7 @@ func (o *Orchestrator) handleAbortedTask(task *models.Task, result *agent.Execut
 		ParentID:  task.ParentID,
 		AgentID:   result.AgentID,
 		Message:   failureMsg,
-		Error:     fmt.Errorf("verification failed after %d iterations", result.LoopIterations),
+		Error:     fmt.Errorf("%w after %d iterations", ErrVerificationFailed, result.LoopIterations),
 		Timestamp: time.Now(),
 		LogFile:   result.LogFile,
 	})
@@ -135,6 +149,11 @@ func (o *Orchestrator) handleSuccessfulTask(ctx context.Context, task *models.Ta
 		outcome, err := o.performMerge(ctx, task.ID, result)
 		mergeOutcome = outcome
 		if err != nil {
+			// Oversized conflicts may be routed back to the queue instead of failing
+			if o.requeueForReexecution(task, mergeOutcome) {
+				return mergeOutcome, fmt.Errorf("merge deferred for re-execution: %w", err)
+			}
+
 			// Merge failed - emit failure event and return error
 			// Task should NOT be marked as complete
 			o.emitEvent(OrchestratorEvent{
@@ -169,7 +188,7 @@ func (o *Orchestrator) handleSuccessfulTask(ctx context.Context, task *models.Ta
 				if rollbackErr := o.merger.GitRunner().Reset("HEAD~1"); rollbackErr != nil {
 					// Rollback also failed - this is serious
 					o.logger.Log("[task_completion] CRITICAL: verification failed AND rollback failed for task %s", task.ID)
-					return mergeOutcome, fmt.Errorf("verification failed (%v) and rollback failed (%v)", errorMsg, rollbackErr)
+					return mergeOutcome, fmt.Errorf("%w (%v) and rollback failed (%v)", ErrVerificationFailed, errorMsg, rollbackErr)
 				}
 
 				o.progCoord.LogTask(task.ID, "Merge rolled back due to build failure")
@@ -183,11 +202,11 @@ func (o *Orchestrator) handleSuccessfulTask(ctx context.Context, task *models.Ta
 					ParentID:  task.ParentID,
 					AgentID:   result.AgentID,
 					Message:   fmt.Sprintf("Post-merge verification failed: %s", errorMsg),
-					Error:     fmt.Errorf("build verification failed: %w", verifyResult.Error),
+					Error:     fmt.Errorf("build %w: %w", ErrVerificationFailed, verifyResult.Error),
 					Timestamp: time.Now(),
 				})
 
-				return mergeOutcome, fmt.Errorf("post-merge verification failed: %w", verifyResult.Error)
+				return mergeOutcome, fmt.Errorf("post-merge %w: %w", ErrVerificationFailed, verifyResult.Error)
 			}
 
 			// Verification passed
@@ -203,7 +222,7 @@ func (o *Orchestrator) handleSuccessfulTask(ctx context.Context, task *models.Ta
 
 	// Update state persistence
 	o.updateTaskState(task)
-	o.updateAgentState(result.AgentID, "done")
+	o.finishAgentState(result, "done")
 
 	// Reset override gate state for this task
 	if o.overrideGate != nil {
@@ -219,14 +238,17 @@ func (o *Orchestrator) handleSuccessfulTask(ctx context.Context, task *models.Ta
 	// Emit completion event
 	o.logger.Log("[task_completion] EMITTING EventTaskCompleted for task %s (agent %s)", task.ID, result.AgentID)
 	o.emitEvent(OrchestratorEvent{
-		Type:      EventTaskCompleted,
-		TaskID:    task.ID,
-		TaskTitle: task.Title,
-		ParentID:  task.ParentID,
-		AgentID:   result.AgentID,
-		Message:   fmt.Sprintf("Completed task: %s", task.Title),
-		Timestamp: time.Now(),
-		LogFile:   result.LogFile,
+		Type:         EventTaskCompleted,
+		TaskID:       task.ID,
+		TaskTitle:    task.Title,
+		ProgTaskID:   o.progCoord.TaskID(task.ID),
+		ParentID:     task.ParentID,
+		AgentID:      result.AgentID,
+		Message:      fmt.Sprintf("Completed task: %s", task.Title),
+		Timestamp:    time.Now(),
+		LogFile:      result.LogFile,
+		Files:        result.ChangedFiles,
+		Verification: result.VerifySummary,
 	})
 	o.logger.Log("[task_completion] EventTaskCompleted EMITTED for task %s", task.ID)
 
@@ -239,41 +261,76 @@ func (o *Orchestrator) handleFailedTask(task *models.Task, result *agent.Executi
 
 	if o.overrideGate != nil && o.config.Tier == models.TierScout {
 		if o.overrideGate.CanAskQuestionWithCount(task.ExecutionCount) {
-			log.Printf("[orchestrator] task %s has %d failed attempts, Scout can now ask questions", task.ID, task.ExecutionCount)
+			o.log.Info("task keeps failing, Scout can now ask questions", logging.Task(task.ID), "attempts", task.ExecutionCount)
+			if task.ExecutionCount == o.overrideGate.GetBlockedAfterN() {
+				o.recordDecision(Decision{
+					Kind:   DecisionOverride,
+					Actor:  ActorAlphie,
+					TaskID: task.ID,
+					Reason: fmt.Sprintf("Scout question limit lifted after %d failed attempts", task.ExecutionCount),
+				})
+			}
 		}
 	}
 
-	const maxRetries = 3
-	shouldRetry := task.ExecutionCount < maxRetries
+	retry := o.config.Policy.Retry
+	maxRetries := retry.MaxAttempts
+	class := o.classifyFailure(task, result)
+	attempt := o.taskAttempts(task)
+	// A task escalated to a higher tier is retried there with fresh attempts
+	escalated := o.escalateTier(task, class)
+	shouldRetry := escalated || (retryable(retry, class) && attempt < maxRetries)
 
+	var delay time.Duration
 	if shouldRetry {
+		delay = retryDelay(retry, attempt)
 		task.Status = models.TaskStatusPending
 		task.AssignedTo = ""
-		log.Printf("[orchestrator] task %s failed (attempt %d/%d), will retry", task.ID, task.ExecutionCount, maxRetries)
+		o.scheduler.DeferTask(task.ID, time.Now().Add(delay))
+		o.log.Info("task failed, will retry", logging.Task(task.ID), "class", class, "attempt", attempt, "max_retries", maxRetries, "delay", delay)
 	} else {
 		task.Status = models.TaskStatusFailed
-		log.Printf("[orchestrator] task %s failed after %d attempts, no more retries", task.ID, task.ExecutionCount)
+		if !retryable(retry, class) {
+			o.log.Warn("task failed with an error that is not retryable", logging.Task(task.ID), "class", class)
+		} else {
+			o.log.Warn("task failed, no more retries", logging.Task(task.ID), "attempts", attempt)
+		}
 	}
 
-	o.updateTaskState(task)
-	o.updateAgentState(result.AgentID, "failed")
+	o.finishAgentState(result, "failed")
 
 	if o.learnings != nil && result.Error != "" {
 		learnings, err := o.learnings.OnFailure(result.Error)
 		if err != nil {
-			log.Printf("[orchestrator] warning: failed to check learnings for error: %v", err)
+			o.log.Warn("failed to check learnings for error", logging.Task(task.ID), logging.Err(err))
 		} else if len(learnings) > 0 {
-			log.Printf("[orchestrator] found %d learnings for error in task %s", len(learnings), task.ID)
+			o.log.Debug("found learnings for error", logging.Task(task.ID), "learnings", len(learnings))
 			var suggestions []string
 			for _, l := range learnings {
+				// Anti-patterns describe what not to do; they are not fixes
+				if l.IsAntiPattern() {
+					continue
+				}
 				suggestions = append(suggestions, l.Action)
 			}
-			result.Error = fmt.Sprintf("%s (suggestions from learnings: %v)", result.Error, suggestions)
+			if len(suggestions) > 0 {
+				result.Error = fmt.Sprintf("%s (suggestions from learnings: %v)", result.Error, suggestions)
+			}
 		}
 	}
+	o.recordFailureLearning(task, result, class)
+
+	// Record what went wrong so the retry prompt can address it
+	task.LastFailure = failureContext(class, result)
+
+	// Out of attempts: park the task for a human instead of failing it
+	if !shouldRetry && o.escalateTask(task, fmt.Sprintf("failed with %s error after %d attempt(s)", class, attempt), escalationContext(task, result)) {
+		return
+	}
+	o.updateTaskState(task)
 
 	if shouldRetry {
-		o.progCoord.LogTask(task.ID, fmt.Sprintf("Attempt %d failed: %s. Retrying...", task.ExecutionCount, result.Error))
+		o.progCoord.LogTask(task.ID, fmt.Sprintf("Attempt %d failed (%s): %s. Retrying in %v...", attempt, class, result.Error, delay.Round(time.Second)))
 	} else {
 		o.progCoord.BlockTask(task.ID, result.Error)
 	}
@@ -285,18 +342,76 @@ func (o *Orchestrator) handleFailedTask(task *models.Task, result *agent.Executi
 		TaskTitle: task.Title,
 		ParentID:  task.ParentID,
 		AgentID:   result.AgentID,
-		Message:   fmt.Sprintf("Task failed: %s (attempt %d/%d)", task.Title, task.ExecutionCount, maxRetries),
-		Error:     fmt.Errorf("%s", result.Error),
+		Message:   fmt.Sprintf("Task failed: %s (%s, attempt %d/%d)", task.Title, class, attempt, maxRetries),
+		Error:     taskFailureError(result),
 		Timestamp: time.Now(),
 		LogFile:   result.LogFile,
 	})
 	o.logger.Log("[task_completion] EventTaskFailed EMITTED for task %s", task.ID)
 }
 
+// requeueForReexecution puts a task back in the queue when its merge conflict
+// was too large for the semantic merger and policy routes such conflicts to
+// re-execution. The task re-runs from the current session branch, so its new
+// work is based on everything merged since. Returns false if the outcome was
+// not routed to re-execution or the task has no attempts left.
+func (o *Orchestrator) requeueForReexecution(task *models.Task, outcome *MergeOutcome) bool {
+	if outcome == nil || outcome.Decision == nil || outcome.Decision.Strategy != MergeStrategyReexecute {
+		return false
+	}
+	maxAttempts := o.config.Policy.Retry.MaxAttempts
+	if task.ExecutionCount+1 >= maxAttempts {
+		o.logger.Log("[task_completion] task %s has no attempts left for re-execution", task.ID)
+		return false
+	}
+	task.ExecutionCount++
+
+	// Drop the stale agent branch so the next attempt starts from the session branch
+	if o.merger != nil {
+		_ = o.merger.DeleteBranch(fmt.Sprintf("agent-%s", task.ID))
+	}
+
+	task.Status = models.TaskStatusPending
+	task.AssignedTo = ""
+	task.LastFailure = fmt.Sprintf("Your previous work could not be merged: %s. The session branch has changed since; start from its current state.", outcome.Decision.Reason)
+	o.graph.MarkIncomplete(task.ID)
+	o.updateTaskState(task)
+
+	msg := fmt.Sprintf("Re-executing on current session branch (attempt %d/%d): %s", task.ExecutionCount+1, maxAttempts, outcome.Decision.Reason)
+	o.progCoord.LogTask(task.ID, msg)
+	o.emitEvent(OrchestratorEvent{
+		Type:          EventTaskQueued,
+		TaskID:        task.ID,
+		TaskTitle:     task.Title,
+		ParentID:      task.ParentID,
+		Message:       msg,
+		Timestamp:     time.Now(),
+		MergeDecision: outcome.Decision,
+	})
+	return true
+}
+
+// taskFailureError converts a failed execution result into an error.
+// Failures caused by verification wrap ErrVerificationFailed.
+func taskFailureError(result *agent.ExecutionResult) error {
+	if !result.IsVerified() {
+		detail := result.VerifySummary
+		if detail == "" {
+			detail = result.Error
+		}
+		return fmt.Errorf("%w: %s", ErrVerificationFailed, detail)
+	}
+	return fmt.Errorf("%s", result.Error)
+}
+
 // performMerge attempts to merge the agent's work into the session branch.
 // Uses the merge queue for serialized, reliable merging with retry and fallback.
 // Returns the merge outcome and any error.
 func (o *Orchestrator) performMerge(ctx context.Context, taskID string, result *agent.ExecutionResult) (*MergeOutcome, error) {
+	if o.config.PlainMode() {
+		return o.applyPatch(taskID, result)
+	}
+
 	// Log merge start to prog
 	o.progCoord.LogTask(taskID, "Starting merge operation (queued)")
 
@@ -310,6 +425,7 @@ func (o *Orchestrator) performMerge(ctx context.Context, taskID string, result *
 	// Wait for the merge to complete
 	select {
 	case outcome := <-resultCh:
+		o.recordHumanResolution(taskID, outcome)
 		if outcome.Success {
 			// Log merge success to prog
 			msg := "Merge completed successfully"
@@ -322,6 +438,11 @@ func (o *Orchestrator) performMerge(ctx context.Context, taskID string, result *
 			return &outcome, nil
 		}
 
+		if outcome.Review != nil {
+			o.progCoord.LogTask(taskID, fmt.Sprintf("Merge held for review: %s", outcome.Reason))
+			return &outcome, fmt.Errorf("merge held for review: %w", outcome.Error)
+		}
+
 		// Merge failed
 		o.progCoord.LogTask(taskID, fmt.Sprintf("Merge failed: %s", outcome.Reason))
 		return &outcome, fmt.Errorf("merge failed: %s: %w", outcome.Reason, outcome.Error)
@@ -331,6 +452,25 @@ func (o *Orchestrator) performMerge(ctx context.Context, taskID string, result *
 	}
 }
 
+// recordHumanResolution adds the operator's merge conflict resolution, if
+// one was made, to the audit trail.
+func (o *Orchestrator) recordHumanResolution(taskID string, outcome MergeOutcome) {
+	if outcome.HumanResolution == nil {
+		return
+	}
+	kind := DecisionRejection
+	switch outcome.HumanResolution.Strategy {
+	case merge.AcceptAgent, merge.ManualMerge:
+		kind = DecisionApproval
+	}
+	o.recordDecision(Decision{
+		Kind:   kind,
+		Actor:  HumanActor(o.config.Operator),
+		TaskID: taskID,
+		Reason: fmt.Sprintf("Merge conflict resolved by %s: %s", outcome.HumanResolution.Strategy, outcome.Reason),
+	})
+}
+
 // performSecondReview checks if a second review is needed and performs it.
 // Returns nil if no review is needed or the review approves the changes.
 // Returns an error if the review rejects the changes.
@@ -352,6 +492,20 @@ func (o *Orchestrator) performSecondReview(ctx context.Context, taskID string, r
 		return nil
 	}
 
+	// Skip the review when the task's confidence score clears the threshold
+	if v := o.config.Validation; v != nil && v.SkipReviewConfidence > 0 && result.Confidence != nil &&
+		*result.Confidence >= v.SkipReviewConfidence {
+		o.log.Info("skipping second review: confidence above threshold",
+			logging.Task(taskID), "confidence", *result.Confidence, "threshold", v.SkipReviewConfidence, "triggers", trigger.Reasons)
+		o.recordDecision(Decision{
+			Kind:   DecisionApproval,
+			Actor:  ActorAlphie,
+			TaskID: taskID,
+			Reason: fmt.Sprintf("Second review skipped: confidence %.2f >= %.2f", *result.Confidence, v.SkipReviewConfidence),
+		})
+		return nil
+	}
+
 	// Emit second review started event
 	o.emitEvent(OrchestratorEvent{
 		Type:      EventSecondReviewStarted,
@@ -361,13 +515,13 @@ func (o *Orchestrator) performSecondReview(ctx context.Context, taskID string, r
 		Timestamp: time.Now(),
 	})
 
-	log.Printf("[orchestrator] second review triggered for task %s: %v", taskID, trigger.Reasons)
+	o.log.Info("second review triggered", logging.Task(taskID), "triggers", trigger.Reasons)
 
-	// Request the review
-	reviewResult, err := o.secondReviewer.RequestReview(ctx, diff, task.Description)
+	// Request the review from the reviewers the triggers select
+	reviewResult, err := o.secondReviewer.ReviewWithPanel(ctx, diff, task.Description, trigger.Types)
 	if err != nil {
 		// Log the error but don't block the merge on review failure
-		log.Printf("[orchestrator] warning: second review failed for task %s: %v", taskID, err)
+		o.log.Warn("second review failed", logging.Task(taskID), logging.Err(err))
 		o.emitEvent(OrchestratorEvent{
 			Type:      EventSecondReviewCompleted,
 			TaskID:    taskID,
@@ -376,23 +530,39 @@ func (o *Orchestrator) performSecondReview(ctx context.Context, taskID string, r
 			Error:     err,
 			Timestamp: time.Now(),
 		})
+		o.recordDecision(Decision{
+			Kind:   DecisionWaiver,
+			Actor:  ActorAlphie,
+			TaskID: taskID,
+			Reason: fmt.Sprintf("Second review could not run (%v); merge proceeded without it", err),
+		})
 		return nil // Don't block merge on review errors
 	}
 
 	// Process the review result
+	o.persistReviewFindings(taskID, reviewResult.Findings())
 	if reviewResult.Approved {
 		o.emitEvent(OrchestratorEvent{
 			Type:      EventSecondReviewCompleted,
 			TaskID:    taskID,
 			AgentID:   result.AgentID,
 			Message:   "Second review approved",
+			Actor:     ActorSecondReviewer,
+			Concerns:  reviewResult.Concerns(),
 			Timestamp: time.Now(),
 		})
+		o.recordDecision(Decision{
+			Kind:   DecisionApproval,
+			Actor:  ActorSecondReviewer,
+			TaskID: taskID,
+			Reason: fmt.Sprintf("Second review approved by %d/%d reviewers, quorum %d (triggered by: %s)",
+				reviewResult.Approvals, len(reviewResult.Votes), reviewResult.Quorum, strings.Join(trigger.Reasons, "; ")),
+		})
 		return nil
 	}
 
 	// Review rejected - block the merge
-	concerns := strings.Join(reviewResult.Concerns, "; ")
+	concerns := strings.Join(reviewResult.Concerns(), "; ")
 	if concerns == "" {
 		concerns = "no specific concerns provided"
 	}
@@ -403,8 +573,17 @@ func (o *Orchestrator) performSecondReview(ctx context.Context, taskID string, r
 		AgentID:   result.AgentID,
 		Message:   fmt.Sprintf("Second review rejected: %s", concerns),
 		Error:     fmt.Errorf("second review rejected"),
+		Actor:     ActorSecondReviewer,
+		Concerns:  reviewResult.Concerns(),
 		Timestamp: time.Now(),
 	})
+	o.recordDecision(Decision{
+		Kind:   DecisionRejection,
+		Actor:  ActorSecondReviewer,
+		TaskID: taskID,
+		Reason: fmt.Sprintf("Second review rejected by panel (%d/%d approvals, quorum %d): %s",
+			reviewResult.Approvals, len(reviewResult.Votes), reviewResult.Quorum, concerns),
+	})
 
 	return fmt.Errorf("second review rejected: %s", concerns)
 }
@@ -444,6 +623,6 @@ func (o *Orchestrator) recordTaskOutcome(taskID string, result *agent.ExecutionR
 	// Record the outcome
 	if err := o.effectivenessTracker.RecordOutcome(outcome); err != nil {
 		// Log but don't fail - effectiveness tracking is not critical
-		log.Printf("[orchestrator] warning: failed to record task outcome for effectiveness tracking: %v", err)
+		o.log.Warn("failed to record task outcome for effectiveness tracking", logging.Task(taskID), logging.Err(err))
 	}
 }
@@ -0,0 +1,122 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// TaskDefinition is a hand-written task, loaded from YAML, that runs
+// through the executor, validation and merge pipeline without being
+// decomposed from a request. It is used to debug prompts, validation and
+// merge behavior one task at a time.
+type TaskDefinition struct {
+	// ID identifies the task; a random ID is used if empty.
+	ID string `yaml:"id"`
+	// Title is the short description of the task.
+	Title string `yaml:"title"`
+	// Description is the full task prompt.
+	Description string `yaml:"description"`
+	// AcceptanceCriteria defines when the task is complete.
+	AcceptanceCriteria string `yaml:"acceptance_criteria"`
+	// VerificationIntent describes what verification should check.
+	VerificationIntent string `yaml:"verification_intent"`
+	// TaskType is SETUP, FEATURE, BUGFIX or REFACTOR.
+	TaskType models.TaskType `yaml:"task_type"`
+	// Tier overrides the tier the task runs at.
+	Tier models.Tier `yaml:"tier"`
+	// FileBoundaries are the files and directories the task should modify.
+	FileBoundaries []string `yaml:"file_boundaries"`
+	// ResourceLocks names shared resources the task needs exclusively.
+	ResourceLocks []string `yaml:"resource_locks"`
+	// Priority is 1 (high), 2 (medium) or 3 (low); empty means medium.
+	Priority int `yaml:"priority"`
+}
+
+// LoadTaskDefinition reads a TaskDefinition from a YAML file.
+func LoadTaskDefinition(path string) (*TaskDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read task definition: %w", err)
+	}
+	var def TaskDefinition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("parse task definition %s: %w", path, err)
+	}
+	if def.Title == "" && def.Description == "" {
+		return nil, fmt.Errorf("task definition %s has neither title nor description", path)
+	}
+	if def.Tier != "" && !def.Tier.Valid() {
+		return nil, fmt.Errorf("task definition %s: invalid tier %q", path, def.Tier)
+	}
+	return &def, nil
+}
+
+// Task converts the definition to a pending task at the given default tier.
+func (d *TaskDefinition) Task(tier models.Tier) *models.Task {
+	task := &models.Task{
+		ID:                 d.ID,
+		Title:              d.Title,
+		Description:        d.Description,
+		AcceptanceCriteria: d.AcceptanceCriteria,
+		VerificationIntent: d.VerificationIntent,
+		TaskType:           d.TaskType,
+		Tier:               d.Tier,
+		FileBoundaries:     d.FileBoundaries,
+		ResourceLocks:      d.ResourceLocks,
+		Priority:           d.Priority,
+	}
+	if task.Title == "" {
+		task.Title = firstLine(task.Description)
+	}
+	return prepareTask(task, tier)
+}
+
+// TaskFromProgItem converts a prog task to a pending task at the given tier.
+// File boundaries recorded in the description are restored.
+func TaskFromProgItem(item *prog.Item, tier models.Tier) *models.Task {
+	task := &models.Task{
+		Title:          item.Title,
+		Description:    item.Description,
+		Tier:           tier,
+		FileBoundaries: ParseFileBoundaries(item.Description),
+		Priority:       item.Priority,
+		CreatedAt:      item.CreatedAt,
+	}
+	if item.ParentID != nil {
+		task.ParentID = *item.ParentID
+	}
+	return prepareTask(task, tier)
+}
+
+// prepareTask fills in the fields a predefined task needs to be scheduled.
+func prepareTask(task *models.Task, tier models.Tier) *models.Task {
+	if task.ID == "" {
+		task.ID = uuid.New().String()[:8]
+	}
+	if task.Tier == "" {
+		task.Tier = tier
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	task.Status = models.TaskStatusPending
+	return task
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestLoadTaskDefinition(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "task.yaml")
+	content := `description: |
+  Add a --json flag to the status command.
+  Print the status as JSON.
+acceptance_criteria: status --json prints valid JSON
+task_type: FEATURE
+file_boundaries: [cmd/alphie/status.go]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := LoadTaskDefinition(path)
+	if err != nil {
+		t.Fatalf("LoadTaskDefinition: %v", err)
+	}
+	task := def.Task(models.TierScout)
+	if task.ID == "" {
+		t.Error("task has no ID")
+	}
+	if task.Title != "Add a --json flag to the status command." {
+		t.Errorf("Title = %q, want the first line of the description", task.Title)
+	}
+	if task.Tier != models.TierScout || task.Status != models.TaskStatusPending || task.TaskType != models.TaskTypeFeature {
+		t.Errorf("task = %+v, want a pending scout FEATURE task", task)
+	}
+	if len(task.FileBoundaries) != 1 || task.FileBoundaries[0] != "cmd/alphie/status.go" {
+		t.Errorf("FileBoundaries = %v", task.FileBoundaries)
+	}
+
+	for name, bad := range map[string]string{
+		"empty":        "task_type: FEATURE\n",
+		"invalid tier": "title: x\ntier: wizard\n",
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTaskDefinition(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestTaskFromProgItem(t *testing.T) {
+	parent := "ep-123456"
+	item := &prog.Item{
+		ID:          "ts-abcdef",
+		Title:       "Fix the parser",
+		Description: "Handle empty input.\n" + FormatFileBoundaries([]string{"parser.go", "parser_test.go"}),
+		ParentID:    &parent,
+	}
+
+	task := TaskFromProgItem(item, models.TierBuilder)
+	if task.Title != item.Title || task.ParentID != parent || task.Tier != models.TierBuilder {
+		t.Errorf("task = %+v", task)
+	}
+	if len(task.FileBoundaries) != 2 {
+		t.Errorf("FileBoundaries = %v, want the boundaries from the description", task.FileBoundaries)
+	}
+}
@@ -0,0 +1,232 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// Baseline token usage of a task per tier, from typical agent sessions.
+// Higher tiers run more iterations and read more of the codebase.
+var tierBaseTokens = map[models.Tier]struct{ input, output int64 }{
+	models.TierQuick:     {input: 10_000, output: 1_500},
+	models.TierScout:     {input: 20_000, output: 2_500},
+	models.TierBuilder:   {input: 50_000, output: 6_000},
+	models.TierArchitect: {input: 90_000, output: 12_000},
+}
+
+const (
+	// estimatePerFileInputTokens is added for each file a task is expected to touch.
+	estimatePerFileInputTokens = 5_000
+	// estimateHistoryLimit is how many completed tasks calibrate estimates.
+	estimateHistoryLimit = 200
+	// minCalibrationSamples is the fewest same-tier tasks used on their own;
+	// with fewer, tasks of every tier are used.
+	minCalibrationSamples = 3
+	// Without history, the band spans half to twice the heuristic estimate.
+	uncalibratedLowFactor  = 0.5
+	uncalibratedHighFactor = 2.0
+)
+
+// TaskEstimate is the predicted usage of one task with a confidence band.
+type TaskEstimate struct {
+	// TaskID is the estimated task.
+	TaskID string `json:"task_id"`
+	// TaskTitle is the estimated task's title.
+	TaskTitle string `json:"task_title"`
+	// Tier is the tier the task runs at.
+	Tier models.Tier `json:"tier"`
+	// Model is the model the task is expected to run on.
+	Model string `json:"model"`
+	// InputTokens is the estimated number of input tokens.
+	InputTokens int64 `json:"input_tokens"`
+	// OutputTokens is the estimated number of output tokens.
+	OutputTokens int64 `json:"output_tokens"`
+	// Cost is the estimated cost in dollars.
+	Cost float64 `json:"cost"`
+	// CostLow and CostHigh bound the likely cost (10th to 90th percentile
+	// of past tasks when calibrated).
+	CostLow  float64 `json:"cost_low"`
+	CostHigh float64 `json:"cost_high"`
+	// Samples is the number of completed tasks the estimate was calibrated
+	// with (0 = heuristic only).
+	Samples int `json:"samples"`
+}
+
+// RunEstimate is the predicted cost of a session's tasks before they run.
+type RunEstimate struct {
+	// Tasks holds the estimate of each task.
+	Tasks []TaskEstimate `json:"tasks"`
+	// Cost is the total estimated cost in dollars.
+	Cost float64 `json:"cost"`
+	// CostLow and CostHigh bound the total cost.
+	CostLow  float64 `json:"cost_low"`
+	CostHigh float64 `json:"cost_high"`
+}
+
+// Summary describes the estimate in one line.
+func (r *RunEstimate) Summary() string {
+	return fmt.Sprintf("Estimated cost for %d task(s): $%.2f (range $%.2f-$%.2f)",
+		len(r.Tasks), r.Cost, r.CostLow, r.CostHigh)
+}
+
+// TaskEstimator predicts the tokens and cost of decomposed tasks before they
+// run. A heuristic based on the tier, the model the task would be routed to
+// and the description length is calibrated against the actual cost of
+// completed tasks: the point estimate is scaled by the median ratio of
+// actual to heuristic cost, and the confidence band by its spread.
+type TaskEstimator struct {
+	history []state.TaskCost
+}
+
+// NewTaskEstimator creates a TaskEstimator calibrated with history, the
+// usage of completed tasks. With no history estimates are heuristic only.
+func NewTaskEstimator(history []state.TaskCost) *TaskEstimator {
+	return &TaskEstimator{history: history}
+}
+
+// Estimate predicts the usage of a single task.
+func (e *TaskEstimator) Estimate(task *models.Task) TaskEstimate {
+	tier := task.Tier
+	if tier == "" {
+		tier = models.TierBuilder
+	}
+	model := agent.SelectModel(task, tier)
+	input, output := heuristicTokens(tier, len(task.Description), len(ParseFileBoundaries(task.Description)))
+	cost := tokenCost(model, input, output)
+
+	est := TaskEstimate{
+		TaskID:       task.ID,
+		TaskTitle:    task.Title,
+		Tier:         tier,
+		Model:        model,
+		InputTokens:  input,
+		OutputTokens: output,
+		Cost:         cost,
+		CostLow:      cost * uncalibratedLowFactor,
+		CostHigh:     cost * uncalibratedHighFactor,
+	}
+
+	ratios := e.calibration(tier)
+	if len(ratios) == 0 {
+		return est
+	}
+	median := percentile(ratios, 0.5)
+	est.InputTokens = int64(float64(input) * median)
+	est.OutputTokens = int64(float64(output) * median)
+	est.Cost = cost * median
+	est.CostLow = cost * percentile(ratios, 0.1)
+	est.CostHigh = cost * percentile(ratios, 0.9)
+	est.Samples = len(ratios)
+	return est
+}
+
+// EstimateAll predicts the usage of every task and the session total.
+func (e *TaskEstimator) EstimateAll(tasks []*models.Task) *RunEstimate {
+	run := &RunEstimate{Tasks: make([]TaskEstimate, 0, len(tasks))}
+	for _, task := range tasks {
+		est := e.Estimate(task)
+		run.Tasks = append(run.Tasks, est)
+		run.Cost += est.Cost
+		run.CostLow += est.CostLow
+		run.CostHigh += est.CostHigh
+	}
+	return run
+}
+
+// calibration returns the sorted ratios of actual to heuristic cost of past
+// tasks at tier, or of all past tasks if too few ran at tier.
+func (e *TaskEstimator) calibration(tier models.Tier) []float64 {
+	var sameTier, all []float64
+	for _, h := range e.history {
+		htier := models.Tier(h.Tier)
+		if htier == "" {
+			htier = models.TierBuilder
+		}
+		input, output := heuristicTokens(htier, h.DescriptionLength, 0)
+		expected := tokenCost(agent.SelectModel(nil, htier), input, output)
+		if expected <= 0 || h.Cost <= 0 {
+			continue
+		}
+		ratio := h.Cost / expected
+		all = append(all, ratio)
+		if htier == tier {
+			sameTier = append(sameTier, ratio)
+		}
+	}
+	ratios := sameTier
+	if len(ratios) < minCalibrationSamples {
+		ratios = all
+	}
+	sort.Float64s(ratios)
+	return ratios
+}
+
+// estimateTasks estimates the session's tasks before they run, calibrated
+// with the state DB's history, and emits the estimate.
+func (o *Orchestrator) estimateTasks(tasks []*models.Task) {
+	var history []state.TaskCost
+	if store, ok := o.stateDB.(state.CostHistory); ok {
+		var err error
+		if history, err = store.ListTaskCosts(estimateHistoryLimit); err != nil {
+			o.log.Warn("failed to load task cost history", logging.Err(err))
+		}
+	}
+
+	estimate := NewTaskEstimator(history).EstimateAll(tasks)
+	o.estimate = estimate
+	o.logger.Log("%s", estimate.Summary())
+	o.emitEvent(OrchestratorEvent{
+		Type:      EventCostEstimate,
+		Message:   estimate.Summary(),
+		Estimate:  estimate,
+		Timestamp: time.Now(),
+	})
+}
+
+// Estimate returns the pre-run cost estimate of the session's tasks, or nil
+// before tasks are resolved.
+func (o *Orchestrator) Estimate() *RunEstimate {
+	return o.estimate
+}
+
+// heuristicTokens estimates a task's tokens from its tier, description
+// length and number of files it is expected to touch.
+func heuristicTokens(tier models.Tier, descriptionLength, files int) (input, output int64) {
+	base, ok := tierBaseTokens[tier]
+	if !ok {
+		base = tierBaseTokens[models.TierBuilder]
+	}
+	// The description is sent with every turn (rough: ~4 chars per token)
+	input = base.input + int64(descriptionLength/4) + int64(files)*estimatePerFileInputTokens
+	return input, base.output
+}
+
+// tokenCost prices tokens at the rates of model's family.
+func tokenCost(model string, input, output int64) float64 {
+	pricing, ok := agent.DefaultModelPricing[model]
+	if !ok {
+		family := "sonnet"
+		for _, f := range []string{"haiku", "opus"} {
+			if strings.Contains(model, f) {
+				family = f
+			}
+		}
+		pricing = agent.DefaultModelPricing[family]
+	}
+	return float64(input)/1_000_000*pricing.InputPerMillion +
+		float64(output)/1_000_000*pricing.OutputPerMillion
+}
+
+// percentile returns the p-th percentile of sorted values by nearest rank.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(p*float64(len(sorted)-1) + 0.5)
+	return sorted[i]
+}
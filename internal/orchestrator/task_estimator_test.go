@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/state"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestTaskEstimator_Heuristic(t *testing.T) {
+	e := NewTaskEstimator(nil)
+
+	scout := e.Estimate(&models.Task{ID: "s", Title: "Find usages", Tier: models.TierScout})
+	builder := e.Estimate(&models.Task{ID: "b", Title: "Add endpoint", Tier: models.TierBuilder})
+	architect := e.Estimate(&models.Task{ID: "a", Title: "Add endpoint", Tier: models.TierArchitect})
+
+	if !(scout.Cost < builder.Cost && builder.Cost < architect.Cost) {
+		t.Errorf("expected cost to grow with tier: scout=%f builder=%f architect=%f", scout.Cost, builder.Cost, architect.Cost)
+	}
+	if builder.Samples != 0 || builder.CostLow != builder.Cost*uncalibratedLowFactor || builder.CostHigh != builder.Cost*uncalibratedHighFactor {
+		t.Errorf("uncalibrated band = %+v", builder)
+	}
+	if !strings.Contains(builder.Model, "sonnet") || !strings.Contains(architect.Model, "opus") {
+		t.Errorf("models = %s, %s", builder.Model, architect.Model)
+	}
+
+	long := e.Estimate(&models.Task{
+		ID:          "l",
+		Tier:        models.TierBuilder,
+		Description: strings.Repeat("x", 4000) + "\n" + fileBoundariesPrefix + "a.go, b.go",
+	})
+	if long.InputTokens <= builder.InputTokens+2*estimatePerFileInputTokens {
+		t.Errorf("expected description and files to add input tokens: %d vs %d", long.InputTokens, builder.InputTokens)
+	}
+}
+
+func TestTaskEstimator_CalibratesWithHistory(t *testing.T) {
+	task := &models.Task{ID: "t", Title: "Add endpoint", Tier: models.TierBuilder}
+	base := NewTaskEstimator(nil).Estimate(task)
+
+	// Past builder tasks cost two to four times the heuristic.
+	var history []state.TaskCost
+	for _, factor := range []float64{2, 3, 3, 3, 4} {
+		history = append(history, state.TaskCost{TaskID: "h", Tier: "builder", Cost: base.Cost * factor})
+	}
+	est := NewTaskEstimator(history).Estimate(task)
+
+	if est.Samples != 5 {
+		t.Errorf("samples = %d, want 5", est.Samples)
+	}
+	if !approxEqual(est.Cost, base.Cost*3) {
+		t.Errorf("cost = %f, want the median ratio applied (%f)", est.Cost, base.Cost*3)
+	}
+	if !approxEqual(est.CostLow, base.Cost*2) || !approxEqual(est.CostHigh, base.Cost*4) {
+		t.Errorf("band = %f-%f, want %f-%f", est.CostLow, est.CostHigh, base.Cost*2, base.Cost*4)
+	}
+	if est.InputTokens <= base.InputTokens {
+		t.Errorf("expected tokens to be scaled: %d vs %d", est.InputTokens, base.InputTokens)
+	}
+}
+
+func TestTaskEstimator_FallsBackToAllTiers(t *testing.T) {
+	task := &models.Task{ID: "t", Title: "Find usages", Tier: models.TierScout}
+	history := []state.TaskCost{
+		{Tier: "scout", Cost: 1},
+		{Tier: "builder", Cost: 1},
+		{Tier: "builder", Cost: 1},
+		{Tier: "builder", Cost: 0},
+	}
+
+	est := NewTaskEstimator(history).Estimate(task)
+	if est.Samples != 3 {
+		t.Errorf("samples = %d, want all 3 tasks with a cost", est.Samples)
+	}
+}
+
+func TestTaskEstimator_EstimateAll(t *testing.T) {
+	run := NewTaskEstimator(nil).EstimateAll([]*models.Task{
+		{ID: "a", Tier: models.TierBuilder},
+		{ID: "b", Tier: models.TierScout},
+	})
+
+	if len(run.Tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2", len(run.Tasks))
+	}
+	if !approxEqual(run.Cost, run.Tasks[0].Cost+run.Tasks[1].Cost) || !approxEqual(run.CostHigh, run.Tasks[0].CostHigh+run.Tasks[1].CostHigh) {
+		t.Errorf("totals = %+v", run)
+	}
+	if !strings.Contains(run.Summary(), "2 task(s)") {
+		t.Errorf("summary = %q", run.Summary())
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
@@ -0,0 +1,173 @@
+// Package orchestrator manages the coordination of agents and workflows.
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// tierRank orders tiers by capability. Escalation only moves up.
+var tierRank = map[models.Tier]int{
+	models.TierQuick:     0,
+	models.TierScout:     1,
+	models.TierBuilder:   2,
+	models.TierArchitect: 3,
+}
+
+// tierRung is one step of the escalation ladder.
+type tierRung struct {
+	afterFailures int
+	to            models.Tier
+	model         string
+}
+
+// taskTierState is a task's position on the escalation ladder.
+type taskTierState struct {
+	tier  models.Tier
+	model string
+	// failures counts validation failures at tier.
+	failures int
+	// attemptsBefore is how many times the task ran before reaching tier.
+	attemptsBefore int
+}
+
+// tierEscalator retries tasks that keep failing validation at a higher tier,
+// following the escalation ladder of the tier configs.
+type tierEscalator struct {
+	mu    sync.Mutex
+	base  models.Tier
+	rungs map[models.Tier]tierRung
+	tasks map[string]*taskTierState
+}
+
+// newTierEscalator builds the escalation ladder from the tier configs.
+// Returns nil if no tier escalates.
+func newTierEscalator(tiers *config.TierConfigs, base models.Tier) *tierEscalator {
+	if tiers == nil {
+		return nil
+	}
+	rungs := make(map[models.Tier]tierRung)
+	for _, from := range []models.Tier{models.TierScout, models.TierBuilder, models.TierArchitect} {
+		tc := tiers.Get(from)
+		if tc == nil || tc.Escalation == nil || tc.Escalation.AfterFailures <= 0 {
+			continue
+		}
+		to := models.Tier(tc.Escalation.To)
+		if tierRank[to] <= tierRank[from] {
+			pkgLog.Warn("ignoring tier escalation to a tier that is not higher", "from", from, "to", to)
+			continue
+		}
+		model := agent.TierDefaultModels[to]
+		if tc.Escalation.Model != "" {
+			model = agent.ResolveModel(tc.Escalation.Model)
+		}
+		rungs[from] = tierRung{afterFailures: tc.Escalation.AfterFailures, to: to, model: model}
+	}
+	if len(rungs) == 0 {
+		return nil
+	}
+	return &tierEscalator{base: base, rungs: rungs, tasks: make(map[string]*taskTierState)}
+}
+
+// state returns a task's position on the ladder. Callers hold e.mu.
+func (e *tierEscalator) state(taskID string) *taskTierState {
+	st, ok := e.tasks[taskID]
+	if !ok {
+		st = &taskTierState{tier: e.base}
+		e.tasks[taskID] = st
+	}
+	return st
+}
+
+// current returns the tier a task runs at and the model it is pinned to,
+// empty if it has not been escalated.
+func (e *tierEscalator) current(taskID string) (models.Tier, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := e.state(taskID)
+	return st.tier, st.model
+}
+
+// attempts returns how many of a task's executions ran at its current tier.
+func (e *tierEscalator) attempts(taskID string, executions int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return executions - e.state(taskID).attemptsBefore
+}
+
+// onFailure records a failed attempt of a task that has run executions
+// times. When the task has failed validation often enough at its tier, it
+// moves up the ladder and the tier it left and the rung taken are returned.
+func (e *tierEscalator) onFailure(taskID, class string, executions int) (models.Tier, *tierRung) {
+	if class != policy.FailureVerification {
+		return "", nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := e.state(taskID)
+	st.failures++
+	rung, ok := e.rungs[st.tier]
+	if !ok || st.failures < rung.afterFailures {
+		return "", nil
+	}
+	from := st.tier
+	*st = taskTierState{tier: rung.to, model: rung.model, attemptsBefore: executions}
+	return from, &rung
+}
+
+// taskTier returns the tier and model override a task's next agent runs with.
+func (o *Orchestrator) taskTier(task *models.Task) (models.Tier, string) {
+	if o.escalator == nil {
+		return o.config.Tier, ""
+	}
+	return o.escalator.current(task.ID)
+}
+
+// taskAttempts returns how many times a task ran at its current tier; retry
+// limits apply per tier, so an escalated task gets a fresh set of attempts.
+func (o *Orchestrator) taskAttempts(task *models.Task) int {
+	if o.escalator == nil {
+		return task.ExecutionCount
+	}
+	return o.escalator.attempts(task.ID, task.ExecutionCount)
+}
+
+// escalateTier moves a task that keeps failing validation up the escalation
+// ladder, recording why. Returns true if the task was escalated and should
+// be retried at its new tier.
+func (o *Orchestrator) escalateTier(task *models.Task, class string) bool {
+	if o.escalator == nil {
+		return false
+	}
+	from, rung := o.escalator.onFailure(task.ID, class, task.ExecutionCount)
+	if rung == nil {
+		return false
+	}
+	task.Tier = rung.to
+
+	msg := fmt.Sprintf("Escalated from %s to %s tier (%s) after %d failed validations", from, rung.to, rung.model, rung.afterFailures)
+	o.log.Info(msg, logging.Task(task.ID))
+	o.progCoord.LogTask(task.ID, msg)
+	o.recordDecision(Decision{
+		Kind:   DecisionOverride,
+		Actor:  ActorAlphie,
+		TaskID: task.ID,
+		Reason: msg,
+	})
+	o.emitEvent(OrchestratorEvent{
+		Type:      EventTaskEscalated,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		ParentID:  task.ParentID,
+		Message:   fmt.Sprintf("Task %s: %s", task.Title, msg),
+		Timestamp: time.Now(),
+	})
+	return true
+}
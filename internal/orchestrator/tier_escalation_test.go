@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/config"
+	"github.com/ShayCichocki/alphie/internal/graph"
+	"github.com/ShayCichocki/alphie/internal/orchestrator/policy"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func escalationTierConfigs() *config.TierConfigs {
+	tiers := config.DefaultTierConfigs()
+	tiers.Scout.Escalation = &config.EscalationConfig{AfterFailures: 2, To: "builder"}
+	tiers.Builder.Escalation = &config.EscalationConfig{AfterFailures: 1, To: "architect", Model: "sonnet"}
+	return tiers
+}
+
+func TestNewTierEscalator(t *testing.T) {
+	if e := newTierEscalator(nil, models.TierScout); e != nil {
+		t.Error("expected no escalator without tier configs")
+	}
+	tiers := config.DefaultTierConfigs()
+	tiers.Scout.Escalation = nil
+	if e := newTierEscalator(tiers, models.TierScout); e != nil {
+		t.Error("expected no escalator when no tier escalates")
+	}
+
+	// Escalating down the ladder is ignored
+	tiers.Builder.Escalation = &config.EscalationConfig{AfterFailures: 1, To: "scout"}
+	if e := newTierEscalator(tiers, models.TierBuilder); e != nil {
+		t.Error("expected a downward escalation to be ignored")
+	}
+}
+
+func TestTierEscalator_ClimbsLadder(t *testing.T) {
+	e := newTierEscalator(escalationTierConfigs(), models.TierScout)
+
+	if tier, model := e.current("t1"); tier != models.TierScout || model != "" {
+		t.Fatalf("current() = %s, %q, want scout with no model override", tier, model)
+	}
+
+	// Only validation failures count towards escalation
+	if _, rung := e.onFailure("t1", policy.FailureTimeout, 1); rung != nil {
+		t.Fatal("a timeout should not escalate")
+	}
+	if _, rung := e.onFailure("t1", policy.FailureVerification, 2); rung != nil {
+		t.Fatal("one validation failure should not escalate a scout task")
+	}
+	from, rung := e.onFailure("t1", policy.FailureVerification, 3)
+	if rung == nil || from != models.TierScout || rung.to != models.TierBuilder {
+		t.Fatalf("onFailure() = %s, %+v, want escalation from scout to builder", from, rung)
+	}
+	if tier, model := e.current("t1"); tier != models.TierBuilder || model != agent.ModelSonnet {
+		t.Errorf("current() = %s, %q, want builder with %s", tier, model, agent.ModelSonnet)
+	}
+	if got := e.attempts("t1", 3); got != 0 {
+		t.Errorf("attempts() after escalation = %d, want 0", got)
+	}
+
+	// The builder rung continues the ladder, pinned to its configured model
+	from, rung = e.onFailure("t1", policy.FailureVerification, 4)
+	if rung == nil || from != models.TierBuilder || rung.to != models.TierArchitect || rung.model != agent.ModelSonnet {
+		t.Fatalf("onFailure() = %s, %+v, want escalation from builder to architect on sonnet", from, rung)
+	}
+	if _, rung := e.onFailure("t1", policy.FailureVerification, 5); rung != nil {
+		t.Error("the top of the ladder should not escalate")
+	}
+
+	// Other tasks are unaffected
+	if tier, _ := e.current("t2"); tier != models.TierScout {
+		t.Errorf("current() for another task = %s, want scout", tier)
+	}
+}
+
+func TestHandleFailedTask_EscalatesInsteadOfFailing(t *testing.T) {
+	g := graph.New()
+	task := &models.Task{ID: "t1", Title: "Fix parser", Status: models.TaskStatusInProgress}
+	if err := g.Build([]*models.Task{task}); err != nil {
+		t.Fatal(err)
+	}
+	retryPolicy := policy.Default()
+	retryPolicy.Retry.MaxAttempts = 2
+	retryPolicy.Retry.Backoff = 0
+	emitter := NewEventEmitter(100)
+	o := &Orchestrator{
+		log:       pkgLog,
+		config:    &OrchestratorRunConfig{SessionID: "s1", Tier: models.TierScout, Policy: retryPolicy},
+		graph:     g,
+		collision: NewCollisionChecker(),
+		progCoord: NewProgCoordinator(nil, nil, "", models.TierScout, ""),
+		logger:    NopLogger(),
+		emitter:   emitter,
+		escalator: newTierEscalator(escalationTierConfigs(), models.TierScout),
+	}
+	o.scheduler = NewScheduler(g, models.TierScout, 2)
+
+	failed := &agent.ExecutionResult{AgentID: "a1", Error: "tests failed"}
+	verified := false
+	failed.VerifyPassed = &verified
+
+	// Two failed validations use up the scout tier's attempts; the task
+	// is escalated instead of failed.
+	for i := 0; i < 2; i++ {
+		o.handleFailedTask(task, failed)
+		if task.Status != models.TaskStatusPending {
+			t.Fatalf("after failure %d: status = %s, want pending", i+1, task.Status)
+		}
+	}
+	if tier, model := o.taskTier(task); tier != models.TierBuilder || model != agent.ModelSonnet {
+		t.Errorf("taskTier() = %s, %q, want builder on %s", tier, model, agent.ModelSonnet)
+	}
+	if task.Tier != models.TierBuilder {
+		t.Errorf("task.Tier = %s, want builder", task.Tier)
+	}
+
+	var escalations int
+	for len(emitter.Events()) > 0 {
+		if ev := <-emitter.Events(); ev.Type == EventTaskEscalated {
+			escalations++
+		}
+	}
+	if escalations != 1 {
+		t.Errorf("got %d task_escalated events, want 1", escalations)
+	}
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/logging"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+// transcriptAttemptHeading starts the section of each attempt in a
+// transcript.
+const transcriptAttemptHeading = "\n## Attempt "
+
+// TranscriptDir returns the directory of a repository's task transcripts.
+func TranscriptDir(repoPath string) string {
+	return filepath.Join(repoPath, ".alphie", "transcripts")
+}
+
+// TranscriptPath returns the path of a task's Markdown transcript.
+func TranscriptPath(repoPath, taskID string) string {
+	return filepath.Join(TranscriptDir(repoPath), taskID+".md")
+}
+
+// exportTranscript appends a finished attempt at task to the task's
+// Markdown transcript. The first attempt creates the transcript and links
+// it from the prog task's log; retries, including those of later sessions,
+// are numbered after the attempts already in it. Failures are logged and
+// do not affect the task.
+func (o *Orchestrator) exportTranscript(task *models.Task, result *agent.ExecutionResult) {
+	path := TranscriptPath(o.config.RepoPath, task.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		o.log.Warn("failed to create transcript directory", logging.Task(task.ID), logging.Err(err))
+		return
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		o.log.Warn("failed to read transcript", logging.Task(task.ID), logging.Err(err))
+		return
+	}
+	created := len(existing) == 0
+	attempt := strings.Count(string(existing), transcriptAttemptHeading) + 1
+
+	var content strings.Builder
+	if created {
+		content.WriteString(agent.RenderTranscriptHeader(task))
+	}
+	content.WriteString(agent.RenderTranscriptAttempt(attempt, result, task.LastFailure))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		o.log.Warn("failed to open transcript", logging.Task(task.ID), logging.Err(err))
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content.String()); err != nil {
+		o.log.Warn("failed to write transcript", logging.Task(task.ID), logging.Err(err))
+		return
+	}
+
+	if created {
+		rel, err := filepath.Rel(o.config.RepoPath, path)
+		if err != nil {
+			rel = path
+		}
+		o.progCoord.LogTask(task.ID, fmt.Sprintf("Transcript: %s", rel))
+	}
+}
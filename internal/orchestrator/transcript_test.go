@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+	"github.com/ShayCichocki/alphie/internal/prog"
+	"github.com/ShayCichocki/alphie/pkg/models"
+)
+
+func TestExportTranscript(t *testing.T) {
+	dir := t.TempDir()
+	client := newTestProgClient(t)
+	progID, err := client.CreateTask("Add login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coord := NewProgCoordinator(client, NewEventEmitter(10), "", models.TierBuilder, "")
+	coord.taskIDs["t1"] = progID
+	o := &Orchestrator{
+		config:    &OrchestratorRunConfig{RepoPath: dir},
+		log:       pkgLog,
+		progCoord: coord,
+	}
+	task := &models.Task{ID: "t1", Title: "Add login", Description: "Add a login endpoint"}
+
+	o.exportTranscript(task, &agent.ExecutionResult{AgentID: "agent-1", Error: "tests failed"})
+	task.LastFailure = "Your previous attempt failed: tests failed"
+	o.exportTranscript(task, &agent.ExecutionResult{AgentID: "agent-2", Success: true})
+
+	content, err := os.ReadFile(TranscriptPath(dir, "t1"))
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	md := string(content)
+	if strings.Count(md, "# Add login\n") != 1 {
+		t.Errorf("transcript header written %d times, want once", strings.Count(md, "# Add login\n"))
+	}
+	first := strings.Index(md, "## Attempt 1 (failed)")
+	second := strings.Index(md, "## Attempt 2 (succeeded)")
+	if first < 0 || second < first {
+		t.Errorf("attempts missing or out of order:\n%s", md)
+	}
+	if !strings.Contains(md[second:], "> Your previous attempt failed: tests failed") {
+		t.Errorf("second attempt missing its retry feedback:\n%s", md[second:])
+	}
+
+	// Prog logs are written in the background
+	var links []prog.Log
+	for deadline := time.Now().Add(2 * time.Second); len(links) == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		logs, err := client.GetLogs(progID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range logs {
+			if strings.HasPrefix(l.Message, "Transcript: ") {
+				links = append(links, l)
+			}
+		}
+	}
+	if len(links) != 1 || links[0].Message != "Transcript: .alphie/transcripts/t1.md" {
+		t.Errorf("transcript links in prog log = %+v, want one link", links)
+	}
+}
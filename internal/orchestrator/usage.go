@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/agent"
+)
+
+// costTickInterval is how often cost_tick events report changed usage.
+const costTickInterval = time.Second
+
+// reportUsage emits a cost_tick event with the session's running usage and
+// cost whenever it changed in the last costTickInterval, until the returned
+// function is called. It does nothing without a usage meter.
+func (o *Orchestrator) reportUsage() (stop func()) {
+	return o.usageMeter.Watch(costTickInterval, func(s agent.UsageSnapshot) {
+		o.emitEvent(OrchestratorEvent{
+			Type:       EventCostTick,
+			Message:    fmt.Sprintf("%d tokens, $%.4f", s.Usage.TotalTokens, s.Cost),
+			TokensUsed: s.Usage.TotalTokens,
+			Cost:       s.Cost,
+			Timestamp:  time.Now(),
+		})
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
 )
 
 const (
@@ -28,6 +30,10 @@ func BackupPath() (string, error) {
 // Backup creates a backup of the database.
 // Returns the path to the backup file.
 func (db *DB) Backup() (string, error) {
+	if db.Dialect == sqldb.Postgres {
+		return "", fmt.Errorf("backups of a Postgres database are not supported: use pg_dump")
+	}
+
 	backupDir, err := BackupPath()
 	if err != nil {
 		return "", err
@@ -462,3 +462,77 @@ func (c *Client) GetIncompleteTasks(epicID string) ([]Item, error) {
 	}
 	return incomplete, nil
 }
+
+// ListEpics returns the epics of the client's project. Done and canceled
+// epics are only included when includeClosed is true.
+func (c *Client) ListEpics(includeClosed bool) ([]Item, error) {
+	epics, err := c.db.ListItemsFiltered(ListFilter{
+		Project: c.project,
+		Type:    string(ItemTypeEpic),
+	})
+	if err != nil || includeClosed {
+		return epics, err
+	}
+	open := epics[:0]
+	for _, epic := range epics {
+		if epic.Status != StatusDone && epic.Status != StatusCanceled {
+			open = append(open, epic)
+		}
+	}
+	return open, nil
+}
+
+// AddTaskToEpic creates a task under an existing epic, in the epic's project,
+// and returns its ID. Sessions executing the epic pick the task up when they
+// resume it.
+func (c *Client) AddTaskToEpic(epicID, title string, opts *TaskOptions) (string, error) {
+	epic, err := c.GetEpic(epicID)
+	if err != nil {
+		return "", err
+	}
+	if epic.Status == StatusDone || epic.Status == StatusCanceled {
+		return "", fmt.Errorf("epic %s is %s", epicID, epic.Status)
+	}
+
+	taskOpts := TaskOptions{}
+	if opts != nil {
+		taskOpts = *opts
+	}
+	taskOpts.Project = epic.Project
+	taskOpts.ParentID = epicID
+	return c.CreateTask(title, &taskOpts)
+}
+
+// CancelTask cancels a task that is not done yet and logs why. Canceled
+// tasks are skipped when their epic is resumed.
+func (c *Client) CancelTask(id, reason string) error {
+	item, err := c.db.GetItem(id)
+	if err != nil {
+		return err
+	}
+	if item.Type != ItemTypeTask {
+		return fmt.Errorf("item %s is not a task (type: %s)", id, item.Type)
+	}
+	if item.Status == StatusDone || item.Status == StatusCanceled {
+		return fmt.Errorf("task %s is already %s", id, item.Status)
+	}
+	if reason == "" {
+		reason = "no reason given"
+	}
+	if err := c.db.AddLog(id, fmt.Sprintf("Canceled: %s", reason)); err != nil {
+		return err
+	}
+	return c.Cancel(id)
+}
+
+// AddNote attaches a note to an item. The note is logged and appended to the
+// description, so agents that later work on the task see it.
+func (c *Client) AddNote(id, note string) error {
+	if note == "" {
+		return fmt.Errorf("note cannot be empty")
+	}
+	if err := c.db.AppendDescription(id, "Note: "+note); err != nil {
+		return err
+	}
+	return c.db.AddLog(id, "Note: "+note)
+}
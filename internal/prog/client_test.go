@@ -3,7 +3,10 @@ package prog
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb/sqldbtest"
 )
 
 func TestClient_CreateEpic(t *testing.T) {
@@ -786,6 +789,93 @@ func TestClient_GetIncompleteTasks(t *testing.T) {
 	}
 }
 
+func TestClient_ListEpics(t *testing.T) {
+	client := setupTestClient(t)
+	defer client.Close()
+
+	open, _ := client.CreateEpic("Open Epic", nil)
+	done, _ := client.CreateEpic("Done Epic", nil)
+	_ = client.Done(done)
+
+	epics, err := client.ListEpics(false)
+	if err != nil {
+		t.Fatalf("ListEpics failed: %v", err)
+	}
+	if len(epics) != 1 || epics[0].ID != open {
+		t.Errorf("Expected only the open epic, got %v", epics)
+	}
+
+	epics, err = client.ListEpics(true)
+	if err != nil {
+		t.Fatalf("ListEpics failed: %v", err)
+	}
+	if len(epics) != 2 {
+		t.Errorf("Expected 2 epics with closed ones included, got %d", len(epics))
+	}
+}
+
+func TestClient_AddTaskToEpic(t *testing.T) {
+	client := setupTestClient(t)
+	defer client.Close()
+
+	epicID, _ := client.CreateEpic("Epic", &EpicOptions{Project: "other"})
+	taskID, err := client.AddTaskToEpic(epicID, "Manual task", &TaskOptions{Priority: 1})
+	if err != nil {
+		t.Fatalf("AddTaskToEpic failed: %v", err)
+	}
+
+	item, _ := client.GetItem(taskID)
+	if item.ParentID == nil || *item.ParentID != epicID {
+		t.Errorf("Expected task under epic %s, got %v", epicID, item.ParentID)
+	}
+	if item.Project != "other" || item.Priority != 1 {
+		t.Errorf("Expected the epic's project and priority 1, got %q and %d", item.Project, item.Priority)
+	}
+
+	if _, err := client.AddTaskToEpic(taskID, "Nested", nil); err == nil {
+		t.Error("Expected adding a task under a task to fail")
+	}
+	_ = client.Done(epicID)
+	if _, err := client.AddTaskToEpic(epicID, "Late", nil); err == nil {
+		t.Error("Expected adding a task to a done epic to fail")
+	}
+}
+
+func TestClient_CancelTaskAndAddNote(t *testing.T) {
+	client := setupTestClient(t)
+	defer client.Close()
+
+	epicID, _ := client.CreateEpic("Epic", nil)
+	taskID, _ := client.CreateTask("Task", &TaskOptions{ParentID: epicID, Description: "Do it"})
+
+	if err := client.AddNote(taskID, "use the v2 API"); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	item, _ := client.GetItem(taskID)
+	if !strings.Contains(item.Description, "Note: use the v2 API") {
+		t.Errorf("Expected the note in the description, got %q", item.Description)
+	}
+
+	if err := client.CancelTask(taskID, "done by hand"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	item, _ = client.GetItem(taskID)
+	if item.Status != StatusCanceled {
+		t.Errorf("Expected canceled, got %s", item.Status)
+	}
+	logs, _ := client.GetLogs(taskID)
+	if len(logs) != 2 || logs[1].Message != "Canceled: done by hand" {
+		t.Errorf("Expected note and cancel logs, got %v", logs)
+	}
+
+	if err := client.CancelTask(taskID, ""); err == nil {
+		t.Error("Expected canceling a canceled task to fail")
+	}
+	if err := client.CancelTask(epicID, ""); err == nil {
+		t.Error("Expected canceling an epic as a task to fail")
+	}
+}
+
 // setupTestClient creates a test client with an in-memory database.
 func setupTestClient(t *testing.T) *Client {
 	t.Helper()
@@ -797,7 +887,10 @@ func setupTestClient(t *testing.T) *Client {
 	}
 	t.Cleanup(func() { os.RemoveAll(tmpDir) })
 
-	dbPath := filepath.Join(tmpDir, "test.db")
+	dbPath := sqldbtest.PostgresDSN(t)
+	if dbPath == "" {
+		dbPath = filepath.Join(tmpDir, "test.db")
+	}
 	db, err := Open(dbPath)
 	if err != nil {
 		t.Fatalf("Failed to open database: %v", err)
@@ -1,17 +1,17 @@
 // Package prog provides SQLite database operations for the prog task system.
 // Vendored from github.com/baiirun/prog/internal/db.
 //
-// The database is stored at ~/.prog/prog.db by default.
+// The database is stored at ~/.prog/prog.db by default, or in the Postgres
+// database of a postgres:// URL in PROG_DB.
 // Use Open() to connect and Init() to create the schema.
 package prog
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
 
-	_ "modernc.org/sqlite"
+	"github.com/ShayCichocki/alphie/internal/sqldb"
 )
 
 // SchemaVersion is the current schema version.
@@ -81,6 +81,19 @@ CREATE TABLE IF NOT EXISTS learning_concepts (
 	PRIMARY KEY (learning_id, concept_id)
 );
 
+CREATE INDEX IF NOT EXISTS idx_items_project ON items(project);
+CREATE INDEX IF NOT EXISTS idx_items_status ON items(status);
+CREATE INDEX IF NOT EXISTS idx_items_parent ON items(parent_id);
+CREATE INDEX IF NOT EXISTS idx_logs_item ON logs(item_id);
+CREATE INDEX IF NOT EXISTS idx_learnings_project ON learnings(project);
+CREATE INDEX IF NOT EXISTS idx_learnings_task ON learnings(task_id);
+CREATE INDEX IF NOT EXISTS idx_learnings_status ON learnings(status);
+CREATE INDEX IF NOT EXISTS idx_learning_concepts_concept ON learning_concepts(concept_id);
+`
+
+// ftsSchema is the full-text index of learnings, part of the original
+// schema on SQLite. Postgres searches learnings with to_tsvector instead.
+const ftsSchema = `
 CREATE VIRTUAL TABLE IF NOT EXISTS learnings_fts USING fts5(
 	summary,
 	detail,
@@ -104,15 +117,6 @@ CREATE TRIGGER IF NOT EXISTS learnings_au AFTER UPDATE ON learnings BEGIN
 	INSERT INTO learnings_fts(rowid, summary, detail)
 	VALUES (NEW.rowid, NEW.summary, NEW.detail);
 END;
-
-CREATE INDEX IF NOT EXISTS idx_items_project ON items(project);
-CREATE INDEX IF NOT EXISTS idx_items_status ON items(status);
-CREATE INDEX IF NOT EXISTS idx_items_parent ON items(parent_id);
-CREATE INDEX IF NOT EXISTS idx_logs_item ON logs(item_id);
-CREATE INDEX IF NOT EXISTS idx_learnings_project ON learnings(project);
-CREATE INDEX IF NOT EXISTS idx_learnings_task ON learnings(task_id);
-CREATE INDEX IF NOT EXISTS idx_learnings_status ON learnings(status);
-CREATE INDEX IF NOT EXISTS idx_learning_concepts_concept ON learning_concepts(concept_id);
 `
 
 // migrations defines incremental schema changes.
@@ -145,11 +149,12 @@ CREATE INDEX IF NOT EXISTS idx_item_labels_label ON item_labels(label_id);
 
 // DB wraps a SQL database connection with task-specific operations.
 type DB struct {
-	*sql.DB
+	*sqldb.DB
 }
 
 // DefaultPath returns the default database path (~/.prog/prog.db)
-// Can be overridden with PROG_DB environment variable.
+// Can be overridden with PROG_DB environment variable, which may also be a
+// postgres:// URL.
 func DefaultPath() (string, error) {
 	if envPath := os.Getenv("PROG_DB"); envPath != "" {
 		return envPath, nil
@@ -161,15 +166,24 @@ func DefaultPath() (string, error) {
 	return filepath.Join(home, ".prog", "prog.db"), nil
 }
 
-// Open opens or creates the database at the given path
+// Open opens or creates the database at the given path, or connects to the
+// Postgres database of a postgres:// URL.
 func Open(path string) (*DB, error) {
+	if sqldb.DialectOf(path) == sqldb.Postgres {
+		db, err := sqldb.Open(sqldb.Postgres, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		return &DB{db}, nil
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", path)
+	db, err := sqldb.Open(sqldb.SQLite, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -213,10 +227,15 @@ func Open(path string) (*DB, error) {
 // Init creates the schema for a fresh database.
 // For existing databases, use Migrate() instead.
 func (db *DB) Init() error {
-	_, err := db.Exec(baseSchema)
+	_, err := db.Exec(db.Dialect.Schema(baseSchema))
 	if err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
+	if db.Dialect == sqldb.SQLite {
+		if _, err := db.Exec(ftsSchema); err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
 
 	// Run all migrations to bring to current version
 	if err := db.Migrate(); err != nil {
@@ -260,7 +279,7 @@ func (db *DB) Migrate() error {
 			continue
 		}
 
-		if _, err := db.Exec(migration); err != nil {
+		if _, err := db.Exec(db.Dialect.Schema(migration)); err != nil {
 			return fmt.Errorf("migration to v%d failed: %w", targetVersion, err)
 		}
 
@@ -273,36 +292,61 @@ func (db *DB) Migrate() error {
 	return nil
 }
 
-// getSchemaVersion returns the current schema version using PRAGMA user_version.
+// getSchemaVersion returns the current schema version using PRAGMA user_version,
+// or the prog_schema_version table on Postgres.
 func (db *DB) getSchemaVersion() (int, error) {
 	var version int
+	if db.Dialect == sqldb.Postgres {
+		if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS prog_schema_version (version INTEGER NOT NULL)`); err != nil {
+			return 0, err
+		}
+		err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM prog_schema_version").Scan(&version)
+		return version, err
+	}
 	err := db.QueryRow("PRAGMA user_version").Scan(&version)
 	return version, err
 }
 
-// setSchemaVersion sets the schema version using PRAGMA user_version.
+// setSchemaVersion sets the schema version using PRAGMA user_version,
+// or the prog_schema_version table on Postgres.
 func (db *DB) setSchemaVersion(version int) error {
+	if db.Dialect == sqldb.Postgres {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+		if _, err := tx.Exec("DELETE FROM prog_schema_version"); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO prog_schema_version (version) VALUES (?)", version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
 	_, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", version))
 	return err
 }
 
 // tableExists checks if a table exists in the database.
 func (db *DB) tableExists(name string) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?"
+	if db.Dialect == sqldb.Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
 	var count int
-	err := db.QueryRow(
-		"SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?",
-		name,
-	).Scan(&count)
+	err := db.QueryRow(query, name).Scan(&count)
 	return count > 0, err
 }
 
 // migrateProjects populates the projects table from existing items.
 func (db *DB) migrateProjects() error {
 	_, err := db.Exec(`
-		INSERT OR IGNORE INTO projects (name, created_at, updated_at)
+		INSERT INTO projects (name, created_at, updated_at)
 		SELECT DISTINCT project, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
 		FROM items
 		WHERE project != ''
+		ON CONFLICT DO NOTHING
 	`)
 	return err
 }
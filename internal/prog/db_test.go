@@ -6,13 +6,17 @@ import (
 	"testing"
 	"time"
 
-	
+	"github.com/ShayCichocki/alphie/internal/sqldb/sqldbtest"
 )
 
+// setupTestDB creates an initialized database: an SQLite file, or a
+// Postgres schema if ALPHIE_TEST_POSTGRES_URL is set.
 func setupTestDB(t *testing.T) *DB {
 	t.Helper()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "test.db")
+	path := sqldbtest.PostgresDSN(t)
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "test.db")
+	}
 
 	db, err := Open(path)
 	if err != nil {
@@ -17,7 +17,7 @@ func (db *DB) AddDep(itemID, dependsOnID string) error {
 	}
 
 	_, err = db.Exec(`
-		INSERT OR IGNORE INTO deps (item_id, depends_on) VALUES (?, ?)`,
+		INSERT INTO deps (item_id, depends_on) VALUES (?, ?) ON CONFLICT DO NOTHING`,
 		itemID, dependsOnID)
 	if err != nil {
 		return fmt.Errorf("failed to add dependency: %w", err)
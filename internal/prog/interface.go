@@ -14,6 +14,9 @@ type EpicManager interface {
 	// FindInProgressEpic returns an in-progress epic for the client's project, if any.
 	FindInProgressEpic() (*Item, error)
 
+	// ListOpenOrInProgressEpics returns the open and in-progress epics for the client's project.
+	ListOpenOrInProgressEpics() ([]Item, error)
+
 	// ComputeEpicProgress returns the number of completed and total tasks for an epic.
 	ComputeEpicProgress(epicID string) (completed int, total int, err error)
 
@@ -34,6 +37,9 @@ type TaskManager interface {
 
 	// GetIncompleteTasks returns tasks under an epic that are not yet done.
 	GetIncompleteTasks(epicID string) ([]Item, error)
+
+	// GetDependencies returns the IDs of items that the given item depends on.
+	GetDependencies(itemID string) ([]string, error)
 }
 
 // StatusUpdater handles status mutations.
@@ -56,6 +62,9 @@ type MetadataRecorder interface {
 	// AddLog adds a timestamped log entry to an item.
 	AddLog(itemID, message string) error
 
+	// GetLogs retrieves all log entries for an item.
+	GetLogs(itemID string) ([]Log, error)
+
 	// AddDependency adds a dependency between items.
 	AddDependency(itemID, dependsOnID string) error
 
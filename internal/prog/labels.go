@@ -166,8 +166,9 @@ func (db *DB) AddLabelToItem(itemID, project, labelName string) error {
 
 	// Add association (ignore if already exists)
 	_, err = db.Exec(`
-		INSERT OR IGNORE INTO item_labels (item_id, label_id)
+		INSERT INTO item_labels (item_id, label_id)
 		VALUES (?, ?)
+		ON CONFLICT DO NOTHING
 	`, itemID, label.ID)
 	if err != nil {
 		return fmt.Errorf("failed to add label to item: %w", err)
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
 )
 
 // GetLearningsByConcepts returns learnings that have any of the specified concepts.
@@ -64,6 +66,18 @@ func (db *DB) SearchLearnings(project string, query string, includeStale bool) (
 		` + statusFilter + `
 		ORDER BY rank
 	`
+	if db.Dialect == sqldb.Postgres {
+		// Postgres has no FTS5 index; match the same columns with its
+		// own text search
+		sqlQuery = `
+			SELECT l.id, l.project, l.created_at, l.updated_at, l.task_id,
+				l.summary, l.detail, l.files, l.status
+			FROM learnings l, websearch_to_tsquery('english', ?) q
+			WHERE to_tsvector('english', l.summary || ' ' || COALESCE(l.detail, '')) @@ q AND l.project = ?
+			` + statusFilter + `
+			ORDER BY ts_rank(to_tsvector('english', l.summary || ' ' || COALESCE(l.detail, '')), q) DESC
+		`
+	}
 
 	rows, err := db.Query(sqlQuery, query, project)
 	if err != nil {
@@ -21,6 +21,7 @@ type Detector struct {
 	keywords       []string
 	fileTypes      []string
 	importDetector *ImportDetector
+	policy         *Policy
 	mu             sync.RWMutex
 }
 
@@ -59,6 +60,11 @@ func (d *Detector) IsProtectedWithReason(path string) (bool, string) {
 	normalizedPath := filepath.ToSlash(path)
 	lowerPath := strings.ToLower(normalizedPath)
 
+	// Paths covered by the protected-area policy are always protected
+	if matches := d.policy.Evaluate([]string{normalizedPath}); len(matches) > 0 {
+		return true, "Path matches protected policy rule: " + matches[0].Rule.Pattern
+	}
+
 	// Strategy 1: Glob patterns
 	for _, pattern := range d.patterns {
 		if matchGlobPattern(normalizedPath, pattern) {
@@ -113,6 +119,13 @@ func (d *Detector) AddFileType(ext string) {
 	d.fileTypes = append(d.fileTypes, ext)
 }
 
+// SetPolicy makes every path covered by the protected-area policy protected.
+func (d *Detector) SetPolicy(p *Policy) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.policy = p
+}
+
 // LoadConfig loads protected area configuration from an .alphie.yaml file.
 func (d *Detector) LoadConfig(configPath string) error {
 	data, err := os.ReadFile(configPath)
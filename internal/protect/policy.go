@@ -0,0 +1,182 @@
+package protect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// PolicyFile is the protected-area policy file, relative to the repository root.
+const PolicyFile = ".alphie/protected.yml"
+
+// Severity ranks how sensitive a protected area is.
+type Severity string
+
+// Policy severities.
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Action is what a policy rule requires before a change to a matching
+// path may land.
+type Action string
+
+// Policy actions, from least to most strict.
+const (
+	// ActionSecondReview requires an independent reviewer to approve the merge.
+	ActionSecondReview Action = "second_review"
+	// ActionHumanApproval quarantines the merge until a human approves it.
+	ActionHumanApproval Action = "human_approval"
+	// ActionBlock refuses the change outright.
+	ActionBlock Action = "block"
+)
+
+// strictness orders the actions so the strictest matching rule wins.
+func (a Action) strictness() int {
+	switch a {
+	case ActionSecondReview:
+		return 1
+	case ActionHumanApproval:
+		return 2
+	case ActionBlock:
+		return 3
+	}
+	return 0
+}
+
+// Rule is one entry of a protected-area policy.
+type Rule struct {
+	// Pattern is a glob matched against repository-relative paths; ** matches
+	// any number of directories.
+	Pattern string `yaml:"pattern"`
+	// Severity ranks the area. Defaults to medium.
+	Severity Severity `yaml:"severity"`
+	// Action is required before a change to the area may land.
+	Action Action `yaml:"action"`
+	// Reason explains the rule to reviewers.
+	Reason string `yaml:"reason"`
+}
+
+// Policy lists the protected-area rules of a repository.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Match is a path matched by a policy rule.
+type Match struct {
+	Path string
+	Rule Rule
+}
+
+// LoadPolicy reads the policy file of the repository at repoPath. It
+// returns nil without an error when the repository has no policy.
+func LoadPolicy(repoPath string) (*Policy, error) {
+	path := filepath.Join(repoPath, PolicyFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", PolicyFile, err)
+	}
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", PolicyFile, err)
+	}
+	return p, nil
+}
+
+// ParsePolicy parses and validates a policy document.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	for i := range p.Rules {
+		if p.Rules[i].Severity == "" {
+			p.Rules[i].Severity = SeverityMedium
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that every rule has a pattern, a known severity and a
+// known action.
+func (p *Policy) Validate() error {
+	for i, r := range p.Rules {
+		if strings.TrimSpace(r.Pattern) == "" {
+			return fmt.Errorf("rule %d: pattern is required", i+1)
+		}
+		switch r.Severity {
+		case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		default:
+			return fmt.Errorf("rule %d (%s): unknown severity %q", i+1, r.Pattern, r.Severity)
+		}
+		if r.Action.strictness() == 0 {
+			return fmt.Errorf("rule %d (%s): unknown action %q (use %s, %s or %s)",
+				i+1, r.Pattern, r.Action, ActionBlock, ActionSecondReview, ActionHumanApproval)
+		}
+	}
+	return nil
+}
+
+// Evaluate matches paths against the policy. Each matched path is reported
+// once, with the strictest rule that matches it. A nil policy matches nothing.
+func (p *Policy) Evaluate(paths []string) []Match {
+	if p == nil {
+		return nil
+	}
+	var matches []Match
+	for _, path := range paths {
+		normalized := filepath.ToSlash(path)
+		var best *Rule
+		for i := range p.Rules {
+			r := &p.Rules[i]
+			if matchGlobPattern(normalized, r.Pattern) && (best == nil || r.Action.strictness() > best.Action.strictness()) {
+				best = r
+			}
+		}
+		if best != nil {
+			matches = append(matches, Match{Path: path, Rule: *best})
+		}
+	}
+	return matches
+}
+
+// Strictest returns the strictest action among matches, or "" when there
+// are none.
+func Strictest(matches []Match) Action {
+	var action Action
+	for _, m := range matches {
+		if m.Rule.Action.strictness() > action.strictness() {
+			action = m.Rule.Action
+		}
+	}
+	return action
+}
+
+// Describe summarizes the matches requiring action, for logs and reviews.
+func Describe(matches []Match, action Action) string {
+	var parts []string
+	for _, m := range matches {
+		if m.Rule.Action != action {
+			continue
+		}
+		part := fmt.Sprintf("%s (%s, %s)", m.Path, m.Rule.Pattern, m.Rule.Severity)
+		if m.Rule.Reason != "" {
+			part += ": " + m.Rule.Reason
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,129 @@
+package protect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+  - pattern: "migrations/**"
+    severity: critical
+    action: block
+    reason: schema changes ship separately
+  - pattern: "**/*.go"
+    action: second_review
+  - pattern: "internal/billing/**"
+    severity: high
+    action: human_approval
+`
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	if len(p.Rules) != 3 {
+		t.Fatalf("expected 3 rules, got %d", len(p.Rules))
+	}
+	if p.Rules[1].Severity != SeverityMedium {
+		t.Errorf("expected default severity medium, got %q", p.Rules[1].Severity)
+	}
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"missing pattern", "rules:\n  - action: block\n", "pattern is required"},
+		{"unknown action", "rules:\n  - pattern: a/**\n    action: warn\n", "unknown action"},
+		{"unknown severity", "rules:\n  - pattern: a/**\n    action: block\n    severity: extreme\n", "unknown severity"},
+		{"bad yaml", "rules: [", "parse policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePolicy error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	matches := p.Evaluate([]string{"README.md", "cmd/main.go", "internal/billing/invoice.go", "migrations/001.sql"})
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", matches)
+	}
+	want := map[string]Action{
+		"cmd/main.go":                 ActionSecondReview,
+		"internal/billing/invoice.go": ActionHumanApproval,
+		"migrations/001.sql":          ActionBlock,
+	}
+	for _, m := range matches {
+		if m.Rule.Action != want[m.Path] {
+			t.Errorf("%s: action = %q, want %q", m.Path, m.Rule.Action, want[m.Path])
+		}
+	}
+	if got := Strictest(matches); got != ActionBlock {
+		t.Errorf("Strictest = %q, want block", got)
+	}
+	if got := Describe(matches, ActionBlock); got != "migrations/001.sql (migrations/**, critical): schema changes ship separately" {
+		t.Errorf("Describe = %q", got)
+	}
+
+	if got := Strictest(p.Evaluate([]string{"docs/guide.md"})); got != "" {
+		t.Errorf("expected no action for an unprotected path, got %q", got)
+	}
+	var none *Policy
+	if matches := none.Evaluate([]string{"migrations/001.sql"}); matches != nil {
+		t.Errorf("expected a nil policy to match nothing, got %+v", matches)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	p, err := LoadPolicy(dir)
+	if err != nil || p != nil {
+		t.Fatalf("expected no policy without a file, got %v, %v", p, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".alphie"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PolicyFile), []byte(testPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadPolicy(dir)
+	if err != nil || p == nil || len(p.Rules) != 3 {
+		t.Fatalf("LoadPolicy = %v, %v", p, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, PolicyFile), []byte("rules:\n  - pattern: x\n    action: nope\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicy(dir); err == nil || !strings.Contains(err.Error(), PolicyFile) {
+		t.Errorf("expected an invalid policy to fail with the file name, got %v", err)
+	}
+}
+
+func TestDetector_SetPolicy(t *testing.T) {
+	d := New()
+	if d.IsProtected("docs/guide.md") {
+		t.Fatal("expected docs to be unprotected by default")
+	}
+	d.SetPolicy(&Policy{Rules: []Rule{{Pattern: "docs/**", Severity: SeverityLow, Action: ActionSecondReview}}})
+	protected, reason := d.IsProtectedWithReason("docs/guide.md")
+	if !protected || !strings.Contains(reason, "docs/**") {
+		t.Errorf("IsProtectedWithReason = %v, %q", protected, reason)
+	}
+}
@@ -0,0 +1,320 @@
+// Package redact masks secrets in text before alphie writes it anywhere it
+// outlives the process or leaves the machine: log files, the state and
+// learning databases, and requests to Claude. Agents read .env files and
+// error messages quote credentials, so any text alphie did not write itself
+// may carry a token.
+//
+// Secrets are found by regular expressions, built in and configured, and
+// optionally by an entropy detector that flags long random-looking tokens.
+// Each is replaced by a placeholder naming the rule that found it, such as
+// [REDACTED:aws-access-key].
+package redact
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Rule is a named pattern matching a secret. If the pattern has a capture
+// group named "secret", only that group is replaced, so that the context
+// around a secret (a header name, a variable name) stays readable.
+type Rule struct {
+	Name    string
+	Pattern string
+}
+
+// secretGroup is the capture group holding the secret in a rule that keeps
+// its surroundings.
+const secretGroup = "secret"
+
+// BuiltinRules are the patterns every redactor applies.
+var BuiltinRules = []Rule{
+	{Name: "private-key", Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{Name: "aws-access-key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "anthropic-key", Pattern: `\bsk-ant-[A-Za-z0-9_\-]{20,}`},
+	{Name: "openai-key", Pattern: `\bsk-(?:proj-)?[A-Za-z0-9_\-]{32,}`},
+	{Name: "github-token", Pattern: `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})`},
+	{Name: "gitlab-token", Pattern: `\bglpat-[A-Za-z0-9_\-]{20,}`},
+	{Name: "slack-token", Pattern: `\bxox[abposr]-[A-Za-z0-9\-]{10,}`},
+	{Name: "stripe-key", Pattern: `\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}`},
+	{Name: "google-api-key", Pattern: `\bAIza[0-9A-Za-z_\-]{35}`},
+	{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,}`},
+	{Name: "bearer-token", Pattern: `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9_\-\.=+/]{16,})`},
+	{Name: "url-password", Pattern: `[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s:/@]+:(?P<secret>[^\s/@]+)@`},
+	// Upper-case assignments such as DB_PASSWORD=... in .env files, shell
+	// output and error messages. Only unquoted-safe values followed by the
+	// end of the value are matched, so that code referring to a variable
+	// (PASSWORD=$PASSWORD, os.Getenv("API_KEY")) is left alone.
+	{Name: "env-secret", Pattern: `\b[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIAL)[A-Z0-9_]*\s*[=:]\s*["']?(?P<secret>[^\s"'$(){}\[\]<>` + "`" + `]{8,})(?:["'\s]|$)`},
+}
+
+// Config configures a Redactor.
+type Config struct {
+	// Rules are applied in addition to BuiltinRules.
+	Rules []Rule
+	// EntropyMinLength is the length from which a token is checked by the
+	// entropy detector; 0 disables the detector.
+	EntropyMinLength int
+	// EntropyThreshold is the Shannon entropy, in bits per character, from
+	// which a token is considered a secret.
+	EntropyThreshold float64
+}
+
+// DefaultEntropyThreshold is the threshold used when the entropy detector is
+// enabled without one. Hex digests stay below it; random base64 keys of 32
+// characters or more are above.
+const DefaultEntropyThreshold = 4.5
+
+// compiledRule is a Rule ready to apply.
+type compiledRule struct {
+	name  string
+	re    *regexp.Regexp
+	group int // index of the secret group, or 0 for the whole match
+}
+
+// Redactor masks secrets in text. It is safe for concurrent use.
+type Redactor struct {
+	rules            []compiledRule
+	entropyMinLength int
+	entropyThreshold float64
+}
+
+// New builds a redactor from the built-in rules and cfg.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{entropyMinLength: cfg.EntropyMinLength, entropyThreshold: cfg.EntropyThreshold}
+	if r.entropyMinLength > 0 && r.entropyThreshold <= 0 {
+		r.entropyThreshold = DefaultEntropyThreshold
+	}
+
+	for _, rule := range append(append([]Rule(nil), BuiltinRules...), cfg.Rules...) {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("redaction rule %q: missing name", rule.Pattern)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %s: %w", rule.Name, err)
+		}
+		r.rules = append(r.rules, compiledRule{
+			name:  rule.Name,
+			re:    re,
+			group: max(re.SubexpIndex(secretGroup), 0),
+		})
+	}
+	return r, nil
+}
+
+// Placeholder returns the text that replaces a secret found by rule.
+func Placeholder(rule string) string {
+	return "[REDACTED:" + rule + "]"
+}
+
+// Redact returns s with every secret replaced by its placeholder.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.apply(s)
+	}
+	if r.entropyMinLength > 0 {
+		s = r.redactEntropy(s)
+	}
+	return s
+}
+
+// Find returns the names of the rules that find a secret in s, in rule
+// order, for scanning text without changing it.
+func (r *Redactor) Find(s string) []string {
+	if r == nil || s == "" {
+		return nil
+	}
+	var found []string
+	for _, rule := range r.rules {
+		for _, m := range rule.re.FindAllStringSubmatchIndex(s, -1) {
+			if m[2*rule.group] >= 0 {
+				found = append(found, rule.name)
+				break
+			}
+		}
+	}
+	if r.entropyMinLength > 0 && r.redactEntropy(s) != s {
+		found = append(found, entropyRule)
+	}
+	return found
+}
+
+// apply replaces the matches of the rule in s.
+func (c compiledRule) apply(s string) string {
+	matches := c.re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	placeholder := Placeholder(c.name)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*c.group], m[2*c.group+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(placeholder)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// entropyToken matches the candidates of the entropy detector: runs of
+// characters that appear in keys and tokens.
+var entropyToken = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// entropyRule is the rule name of secrets found by the entropy detector.
+const entropyRule = "high-entropy"
+
+// redactEntropy replaces long tokens whose entropy reaches the threshold.
+// Tokens must mix letters and digits, which leaves identifiers and words
+// alone however long they are.
+func (r *Redactor) redactEntropy(s string) string {
+	return entropyToken.ReplaceAllStringFunc(s, func(tok string) string {
+		if len(tok) < r.entropyMinLength || strings.Contains(tok, "REDACTED") {
+			return tok
+		}
+		if !strings.ContainsAny(tok, "0123456789") || !containsLetter(tok) {
+			return tok
+		}
+		if shannonEntropy(tok) < r.entropyThreshold {
+			return tok
+		}
+		return Placeholder(entropyRule)
+	})
+}
+
+func containsLetter(s string) bool {
+	for _, c := range s {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	var h float64
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Writer returns a writer redacting what it passes to w. Each Write is
+// redacted on its own, so callers should write whole lines or records.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{r: r, w: w}
+}
+
+type writer struct {
+	r *Redactor
+	w io.Writer
+}
+
+// Write redacts p and writes it. It reports len(p) on success, as callers
+// count what they handed in, not what was written after redaction.
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.r.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// shared is the process-wide redactor used by String and friends. It starts
+// with the built-in rules so that text is redacted even before Configure is
+// called; a nil redactor disables redaction.
+var shared = struct {
+	sync.RWMutex
+	r *Redactor
+}{r: mustNew(Config{})}
+
+func mustNew(cfg Config) *Redactor {
+	r, err := New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Configure replaces the process-wide redactor. A nil redactor turns
+// redaction off.
+func Configure(r *Redactor) {
+	shared.Lock()
+	defer shared.Unlock()
+	shared.r = r
+}
+
+// Default returns the process-wide redactor, nil if redaction is off.
+func Default() *Redactor {
+	shared.RLock()
+	defer shared.RUnlock()
+	return shared.r
+}
+
+// String redacts s with the process-wide redactor.
+func String(s string) string {
+	return Default().Redact(s)
+}
+
+// Args redacts the string arguments in args with the process-wide redactor,
+// for SQL statement arguments. args is copied if any of them change.
+func Args(args []any) []any {
+	r := Default()
+	if r == nil {
+		return args
+	}
+	out := args
+	copied := false
+	for i, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			continue
+		}
+		if red := r.Redact(s); red != s {
+			if !copied {
+				out = append([]any(nil), args...)
+				copied = true
+			}
+			out[i] = red
+		}
+	}
+	return out
+}
+
+// Writer returns a writer redacting what it passes to w with the
+// process-wide redactor current at each write.
+func Writer(w io.Writer) io.Writer {
+	return defaultWriter{w: w}
+}
+
+type defaultWriter struct {
+	w io.Writer
+}
+
+func (w defaultWriter) Write(p []byte) (int, error) {
+	r := Default()
+	if r == nil {
+		return w.w.Write(p)
+	}
+	return (&writer{r: r, w: w.w}).Write(p)
+}
@@ -0,0 +1,69 @@
+package remote
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseKind validates a configured provider name. An empty name or "auto"
+// returns "", meaning the provider is detected from the remote URL.
+func ParseKind(name string) (Kind, error) {
+	switch kind := Kind(strings.ToLower(strings.TrimSpace(name))); kind {
+	case "", "auto":
+		return "", nil
+	case KindGitHub, KindGitLab, KindBitbucket:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown remote provider %q (want github, gitlab or bitbucket)", name)
+	}
+}
+
+// DetectKind guesses the provider from a remote URL's host. Self-hosted
+// instances are recognized when their host names the product (e.g.
+// gitlab.example.com); otherwise it returns "" and the provider must be
+// configured.
+func DetectKind(remoteURL string) Kind {
+	host, _, err := ParseURL(remoteURL)
+	if err != nil {
+		return ""
+	}
+	host = strings.ToLower(host)
+	for _, kind := range []Kind{KindGitHub, KindGitLab, KindBitbucket} {
+		if strings.Contains(host, string(kind)) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// ParseURL splits a git remote URL into its host and repository path,
+// without a leading slash or trailing ".git". It accepts HTTPS and ssh://
+// URLs as well as scp-style addresses (git@host:owner/repo.git).
+func ParseURL(remoteURL string) (host, path string, err error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if !strings.Contains(remoteURL, "://") {
+		// scp-style: [user@]host:path
+		at := strings.LastIndex(remoteURL, "@")
+		rest := remoteURL[at+1:]
+		h, p, ok := strings.Cut(rest, ":")
+		if !ok || h == "" || p == "" {
+			return "", "", fmt.Errorf("unrecognized remote URL %q", remoteURL)
+		}
+		return h, trimRepoPath(p), nil
+	}
+
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", "", fmt.Errorf("parse remote URL: %w", err)
+	}
+	if u.Hostname() == "" || trimRepoPath(u.Path) == "" {
+		return "", "", fmt.Errorf("unrecognized remote URL %q", remoteURL)
+	}
+	return u.Hostname(), trimRepoPath(u.Path), nil
+}
+
+// trimRepoPath strips the slashes and ".git" suffix around a repository path.
+func trimRepoPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
@@ -0,0 +1,56 @@
+package remote
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url, host, path string
+	}{
+		{"https://github.com/acme/app.git", "github.com", "acme/app"},
+		{"git@github.com:acme/app.git", "github.com", "acme/app"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/app.git", "gitlab.example.com", "group/sub/app"},
+		{"https://jane@bitbucket.org/team/app", "bitbucket.org", "team/app"},
+	}
+	for _, tt := range tests {
+		host, path, err := ParseURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseURL(%q) error = %v", tt.url, err)
+			continue
+		}
+		if host != tt.host || path != tt.path {
+			t.Errorf("ParseURL(%q) = %q, %q; want %q, %q", tt.url, host, path, tt.host, tt.path)
+		}
+	}
+
+	for _, bad := range []string{"", "not a url", "https://github.com/"} {
+		if _, _, err := ParseURL(bad); err == nil {
+			t.Errorf("ParseURL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tests := map[string]Kind{
+		"git@github.com:acme/app.git":              KindGitHub,
+		"https://gitlab.example.com/group/app.git": KindGitLab,
+		"git@bitbucket.org:team/app.git":           KindBitbucket,
+		"https://git.example.com/app.git":          "",
+	}
+	for url, want := range tests {
+		if got := DetectKind(url); got != want {
+			t.Errorf("DetectKind(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for name, want := range map[string]Kind{"": "", "auto": "", "GitLab": KindGitLab, "bitbucket": KindBitbucket} {
+		got, err := ParseKind(name)
+		if err != nil || got != want {
+			t.Errorf("ParseKind(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseKind("gitea"); err == nil {
+		t.Error("ParseKind(gitea) should fail")
+	}
+}
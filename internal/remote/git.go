@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
+)
+
+// DefaultRemote is the git remote session branches are pushed to.
+const DefaultRemote = "origin"
+
+// Git runs the commands providers share: pushing the session branch and
+// inspecting it. Its Run method also runs the providers' host CLIs so their
+// failures are reported the same way.
+type Git struct {
+	// RepoPath is the repository commands run in.
+	RepoPath string
+	// Remote is the git remote branches are pushed to.
+	Remote string
+	// Runner executes the commands.
+	Runner iexec.CommandRunner
+}
+
+// NewGit creates a Git for the repository at repoPath pushing to DefaultRemote.
+func NewGit(repoPath string, runner iexec.CommandRunner) *Git {
+	return &Git{RepoPath: repoPath, Remote: DefaultRemote, Runner: runner}
+}
+
+// Run executes a command in the repository and returns its trimmed output,
+// wrapping failures with the output.
+func (g *Git) Run(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := g.Runner.Run(ctx, g.RepoPath, name, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Push pushes branch to the remote and sets it as the upstream.
+func (g *Git) Push(ctx context.Context, branch string) error {
+	_, err := g.Run(ctx, "git", "push", "--set-upstream", g.Remote, branch)
+	return err
+}
+
+// RevParse returns the commit ref points to.
+func (g *Git) RevParse(ctx context.Context, ref string) (string, error) {
+	return g.Run(ctx, "git", "rev-parse", ref)
+}
+
+// MergeBase returns the commit where head diverged from base.
+func (g *Git) MergeBase(ctx context.Context, base, head string) (string, error) {
+	return g.Run(ctx, "git", "merge-base", base, head)
+}
+
+// Diff returns the changes on head since it diverged from base.
+func (g *Git) Diff(ctx context.Context, base, head string) (string, error) {
+	return g.Run(ctx, "git", "diff", base+"..."+head)
+}
+
+// URL returns the remote's fetch URL.
+func (g *Git) URL(ctx context.Context) (string, error) {
+	return g.Run(ctx, "git", "remote", "get-url", g.Remote)
+}
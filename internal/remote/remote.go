@@ -0,0 +1,217 @@
+// Package remote defines the interface to the service hosting a repository
+// (GitHub, GitLab, Bitbucket) and the types shared by its implementations:
+// a finished session is submitted as a pull or merge request with status
+// checks and reviewer comments.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+)
+
+// Provider publishes sessions to a repository hosting service.
+type Provider interface {
+	// Name identifies the provider (e.g. "github").
+	Name() string
+	// Publish pushes the session branch, opens a pull request, reports the
+	// checks on its head and posts the concerns as review comments. The
+	// pull request is returned even if reporting checks or comments fails;
+	// those failures are returned as the error.
+	Publish(ctx context.Context, sub Submission) (*PullRequest, error)
+}
+
+// Kind names a supported provider.
+type Kind string
+
+const (
+	// KindGitHub publishes pull requests to GitHub.
+	KindGitHub Kind = "github"
+	// KindGitLab publishes merge requests to GitLab.
+	KindGitLab Kind = "gitlab"
+	// KindBitbucket publishes pull requests to Bitbucket Cloud.
+	KindBitbucket Kind = "bitbucket"
+)
+
+// CheckConclusion is the outcome reported by a check.
+type CheckConclusion string
+
+const (
+	// ConclusionSuccess marks a passing check.
+	ConclusionSuccess CheckConclusion = "success"
+	// ConclusionFailure marks a failing check.
+	ConclusionFailure CheckConclusion = "failure"
+	// ConclusionNeutral marks a check that neither passes nor fails.
+	// Providers without a neutral state report it as successful.
+	ConclusionNeutral CheckConclusion = "neutral"
+)
+
+// PullRequest is an opened pull or merge request.
+type PullRequest struct {
+	// Number is the pull request number (the merge request IID on GitLab).
+	Number int
+	// URL is the pull request's web URL.
+	URL string
+}
+
+// CheckRun is a completed check reported on a commit.
+type CheckRun struct {
+	// Name identifies the check (e.g. "alphie/final-verification").
+	Name string
+	// HeadSHA is the commit the check reports on.
+	HeadSHA string
+	// Conclusion is the check outcome.
+	Conclusion CheckConclusion
+	// Title is the one-line result shown next to the check.
+	Title string
+	// Summary is the Markdown summary of the result.
+	Summary string
+	// Text is optional Markdown detail shown below the summary.
+	Text string
+}
+
+// Submission is a finished session to publish as a pull request.
+type Submission struct {
+	// Base is the branch the pull request merges into.
+	Base string
+	// Branch is the session branch holding the work.
+	Branch string
+	// Title is the pull request title.
+	Title string
+	// Body is the pull request description.
+	Body string
+	// Checks are reported on the head of Branch.
+	Checks []CheckRun
+	// Concerns are posted as review comments.
+	Concerns []Concern
+}
+
+// Concern is an issue a second reviewer raised about a task's changes.
+type Concern struct {
+	// TaskID is the reviewed task.
+	TaskID string
+	// TaskTitle is the reviewed task's title.
+	TaskTitle string
+	// Text is the concern as written by the reviewer.
+	Text string
+}
+
+// Label names the concern's task for review text.
+func (c Concern) Label() string {
+	if c.TaskTitle != "" {
+		return c.TaskTitle
+	}
+	return c.TaskID
+}
+
+// InlineComment is the Markdown body of a concern placed on a line.
+func (c Concern) InlineComment() string {
+	return fmt.Sprintf("**Second review — %s**\n\n%s", c.Label(), c.Text)
+}
+
+// InlineConcern is a concern placed on a changed line of the diff.
+type InlineConcern struct {
+	Concern
+	// Path is the changed file.
+	Path string
+	// Line is the line in the new version of the file.
+	Line int
+}
+
+// concernLocationPattern matches a file reference with a line number in a
+// reviewer concern, such as "internal/auth/login.go:42".
+var concernLocationPattern = regexp.MustCompile(`((?:[\w.-]+/)*[\w-][\w.-]*\.[A-Za-z0-9]+):(\d+)`)
+
+// PlaceConcerns splits concerns into those naming a file and line inside
+// one of the diff's hunks, which can be posted inline, and the rest. Hosts
+// reject inline comments outside the diff, so those go in a summary.
+func PlaceConcerns(concerns []Concern, files []annotate.FileDiff) ([]InlineConcern, []Concern) {
+	var inline []InlineConcern
+	var general []Concern
+	for _, c := range concerns {
+		path, line, ok := concernLocation(c.Text, files)
+		if !ok {
+			general = append(general, c)
+			continue
+		}
+		inline = append(inline, InlineConcern{Concern: c, Path: path, Line: line})
+	}
+	return inline, general
+}
+
+// ReviewSummary is the Markdown summary posted with the concerns, listing
+// those that could not be placed inline.
+func ReviewSummary(total int, general []Concern) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The second reviewer raised %d concern(s) during this session.\n", total)
+	if len(general) > 0 {
+		b.WriteString("\n")
+		for _, c := range general {
+			fmt.Fprintf(&b, "- **%s**: %s\n", c.Label(), c.Text)
+		}
+	}
+	return b.String()
+}
+
+// concernLocation returns the first file and line referenced by text that
+// falls inside an added or kept region of the diff.
+func concernLocation(text string, files []annotate.FileDiff) (string, int, bool) {
+	for _, match := range concernLocationPattern.FindAllStringSubmatch(text, -1) {
+		line, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if f.Path != match[1] && !strings.HasSuffix(f.Path, "/"+match[1]) {
+				continue
+			}
+			for _, h := range f.Hunks {
+				if h.NewLines > 0 && line >= h.NewStart && line < h.NewStart+h.NewLines {
+					return f.Path, line, true
+				}
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// Truncate shortens s to at most n bytes, marking the cut.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	const marker = "\n\n_(truncated)_"
+	if n <= len(marker) {
+		return s[:n]
+	}
+	return s[:n-len(marker)] + marker
+}
+
+// WriteJSONFile writes v as JSON to a new temporary file, for host CLIs
+// that take a request body from a file. The caller removes the file.
+func WriteJSONFile(pattern string, v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("create request file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write request file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write request file: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShayCichocki/alphie/internal/annotate"
+)
+
+const sampleDiff = `diff --git a/internal/auth/login.go b/internal/auth/login.go
+--- a/internal/auth/login.go
++++ b/internal/auth/login.go
+@@ -10,3 +10,6 @@ func Login() {
+ 	a := 1
++	b := 2
++	c := 3
++	d := 4
+ 	return
+ }
+`
+
+func TestPlaceConcerns(t *testing.T) {
+	files := annotate.ParseDiff(sampleDiff)
+	concerns := []Concern{
+		{TaskID: "t1", TaskTitle: "Add login", Text: "login.go:11 swallows the error from b"},
+		{TaskID: "t2", Text: "internal/auth/login.go:99 is outside the diff"},
+		{TaskID: "t3", TaskTitle: "Add sessions", Text: "No tests cover session expiry"},
+	}
+
+	inline, general := PlaceConcerns(concerns, files)
+	if len(inline) != 1 {
+		t.Fatalf("expected 1 inline concern, got %+v", inline)
+	}
+	if c := inline[0]; c.Path != "internal/auth/login.go" || c.Line != 11 || c.TaskID != "t1" {
+		t.Errorf("inline concern = %+v", c)
+	}
+	if len(general) != 2 || general[0].TaskID != "t2" || general[1].TaskID != "t3" {
+		t.Errorf("general concerns = %+v", general)
+	}
+
+	summary := ReviewSummary(len(concerns), general)
+	for _, want := range []string{"3 concern(s)", "**t2**: internal/auth/login.go:99", "**Add sessions**: No tests cover session expiry"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if body := inline[0].InlineComment(); !strings.Contains(body, "Add login") {
+		t.Errorf("inline comment should name the task: %q", body)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("short", 10); got != "short" {
+		t.Errorf("Truncate() = %q", got)
+	}
+	got := Truncate(strings.Repeat("a", 100), 50)
+	if len(got) != 50 || !strings.HasSuffix(got, "_(truncated)_") {
+		t.Errorf("Truncate() = %q (%d bytes)", got, len(got))
+	}
+}
@@ -0,0 +1,210 @@
+// Package sqldb lets the state and prog stores run on SQLite or Postgres.
+// Queries are written once with ? placeholders and SQL both databases
+// accept; DB and Tx rewrite them for the dialect they run on.
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ShayCichocki/alphie/internal/redact"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Dialect is a database the stores can run on.
+type Dialect string
+
+const (
+	// SQLite is a local database file, the default.
+	SQLite Dialect = "sqlite"
+	// Postgres is a Postgres server, for state shared between machines
+	// such as CI runners.
+	Postgres Dialect = "postgres"
+)
+
+// DialectOf returns the dialect of a data source: Postgres for a
+// postgres:// or postgresql:// URL, SQLite for anything else (a file path).
+func DialectOf(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return Postgres
+	}
+	return SQLite
+}
+
+// driverName returns the database/sql driver of the dialect.
+func (d Dialect) driverName() string {
+	if d == Postgres {
+		return "pgx"
+	}
+	return "sqlite"
+}
+
+// Schema rewrites SQLite DDL for the dialect. On Postgres, integer primary
+// keys become BIGSERIAL, DATETIME becomes TIMESTAMPTZ and REAL becomes
+// DOUBLE PRECISION.
+func (d Dialect) Schema(ddl string) string {
+	if d != Postgres {
+		return ddl
+	}
+	for _, r := range postgresTypes {
+		ddl = r.pattern.ReplaceAllString(ddl, r.replacement)
+	}
+	return ddl
+}
+
+// postgresTypes maps SQLite column types to Postgres, in order.
+var postgresTypes = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\bINTEGER\s+PRIMARY\s+KEY(\s+AUTOINCREMENT)?`), "BIGSERIAL PRIMARY KEY"},
+	{regexp.MustCompile(`(?i)\bDATETIME\b`), "TIMESTAMPTZ"},
+	{regexp.MustCompile(`(?i)\bREAL\b`), "DOUBLE PRECISION"},
+}
+
+// Rebind rewrites the ? placeholders of a query for the dialect: $1, $2 and
+// so on for Postgres. Question marks in string literals are kept.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// args converts query arguments for the dialect. Postgres does not take
+// booleans for the INTEGER columns SQLite stores them in.
+func (d Dialect) args(args []any) []any {
+	if d != Postgres {
+		return args
+	}
+	var converted []any
+	for i, a := range args {
+		v, ok := a.(bool)
+		if !ok {
+			continue
+		}
+		if converted == nil {
+			converted = append([]any(nil), args...)
+		}
+		converted[i] = boolInt(v)
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
+
+// boolInt returns 1 for true and 0 for false.
+func boolInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// DB is a database connection pool that rewrites queries for its dialect.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// Open connects to a data source of the given dialect. The connection is
+// checked before Open returns.
+func Open(dialect Dialect, dsn string) (*DB, error) {
+	conn, err := sql.Open(dialect.driverName(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open %s database: %w", dialect, err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open %s database: %w", dialect, err)
+	}
+	return &DB{DB: conn, Dialect: dialect}, nil
+}
+
+// Exec executes a query that doesn't return rows. Secrets in its string
+// arguments are redacted, so they never reach the database.
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.Dialect.Rebind(query), db.Dialect.args(redact.Args(args))...)
+}
+
+// Query executes a query that returns rows.
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.Dialect.Rebind(query), db.Dialect.args(args)...)
+}
+
+// QueryRow executes a query that returns at most one row.
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.Dialect.Rebind(query), db.Dialect.args(args)...)
+}
+
+// Begin starts a transaction.
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, Dialect: db.Dialect}, nil
+}
+
+// Tx is a transaction that rewrites queries for its dialect.
+type Tx struct {
+	*sql.Tx
+	Dialect Dialect
+}
+
+// Exec executes a query that doesn't return rows, redacting its arguments
+// like DB.Exec.
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.Dialect.Rebind(query), tx.Dialect.args(redact.Args(args))...)
+}
+
+// Query executes a query that returns rows.
+func (tx *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.Dialect.Rebind(query), tx.Dialect.args(args)...)
+}
+
+// QueryRow executes a query that returns at most one row.
+func (tx *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.Dialect.Rebind(query), tx.Dialect.args(args)...)
+}
+
+// Prepare creates a prepared statement for use within the transaction.
+// Its arguments are converted for the dialect when it is executed.
+func (tx *Tx) Prepare(query string) (*Stmt, error) {
+	stmt, err := tx.Tx.Prepare(tx.Dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{Stmt: stmt, Dialect: tx.Dialect}, nil
+}
+
+// Stmt is a prepared statement that converts its arguments for its dialect.
+type Stmt struct {
+	*sql.Stmt
+	Dialect Dialect
+}
+
+// Exec executes the statement, redacting its arguments like DB.Exec.
+func (s *Stmt) Exec(args ...any) (sql.Result, error) {
+	return s.Stmt.Exec(s.Dialect.args(redact.Args(args))...)
+}
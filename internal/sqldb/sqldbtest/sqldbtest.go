@@ -0,0 +1,56 @@
+// Package sqldbtest runs store tests against Postgres as well as SQLite.
+package sqldbtest
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresEnv names the environment variable with the URL of a Postgres
+// database to run the store tests against.
+const PostgresEnv = "ALPHIE_TEST_POSTGRES_URL"
+
+// PostgresDSN returns the URL of an empty schema of the Postgres database in
+// ALPHIE_TEST_POSTGRES_URL, dropped when the test ends, or "" if the
+// variable is not set and the test should use SQLite.
+func PostgresDSN(t testing.TB) string {
+	t.Helper()
+	base := os.Getenv(PostgresEnv)
+	if base == "" {
+		return ""
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("parse %s: %v", PostgresEnv, err)
+	}
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "alphie_test_" + hex.EncodeToString(suffix)
+
+	admin, err := sql.Open("pgx", base)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatalf("create schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		defer admin.Close()
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,317 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// AttemptOutcome is how a task attempt ended.
+type AttemptOutcome string
+
+const (
+	// AttemptSucceeded means the task's work was merged.
+	AttemptSucceeded AttemptOutcome = "success"
+	// AttemptFailed means the agent failed; the task may be retried.
+	AttemptFailed AttemptOutcome = "failed"
+	// AttemptAborted means the agent ran out of iterations without passing verification.
+	AttemptAborted AttemptOutcome = "aborted"
+	// AttemptMergeFailed means the agent succeeded but its work could not be merged.
+	AttemptMergeFailed AttemptOutcome = "merge_failed"
+)
+
+// TaskAttempt records one execution of a task for historical analytics.
+// A task retried after a failure has one attempt per execution.
+type TaskAttempt struct {
+	ID        int64  `json:"id"`
+	SessionID string `json:"session_id"`
+	TaskID    string `json:"task_id"`
+	Tier      string `json:"tier"`
+	// TaskType is the task's classification (SETUP, FEATURE, BUGFIX, REFACTOR).
+	TaskType string         `json:"task_type,omitempty"`
+	Outcome  AttemptOutcome `json:"outcome"`
+	// FailureCategory classifies unsuccessful attempts (e.g. "verification",
+	// "timeout", "merge"); empty on success.
+	FailureCategory string `json:"failure_category,omitempty"`
+	// MergeAttempted is true if the attempt's work reached the merge queue.
+	MergeAttempted bool `json:"merge_attempted"`
+	// MergeConflict is true if merging the attempt's work hit conflicts.
+	MergeConflict bool          `json:"merge_conflict"`
+	TokensUsed    int           `json:"tokens_used"`
+	Cost          float64       `json:"cost"`
+	Duration      time.Duration `json:"duration"`
+	FinishedAt    time.Time     `json:"finished_at"`
+}
+
+// AttemptRecorder stores finished task attempts.
+type AttemptRecorder interface {
+	RecordTaskAttempt(a *TaskAttempt) error
+}
+
+// Compile-time verification that DB implements AttemptRecorder.
+var _ AttemptRecorder = (*DB)(nil)
+
+// RecordTaskAttempt stores a finished task attempt.
+func (db *DB) RecordTaskAttempt(a *TaskAttempt) error {
+	if a.FinishedAt.IsZero() {
+		a.FinishedAt = time.Now()
+	}
+	err := db.Transaction(func(tx *sqldb.Tx) error {
+		return tx.QueryRow(`
+			INSERT INTO task_attempts (session_id, task_id, tier, task_type, outcome, failure_category,
+				merge_attempted, merge_conflict, tokens_used, cost, duration_ms, finished_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, a.SessionID, a.TaskID, a.Tier, a.TaskType, string(a.Outcome), a.FailureCategory,
+			a.MergeAttempted, a.MergeConflict, a.TokensUsed, a.Cost, a.Duration.Milliseconds(), formatTime(a.FinishedAt)).Scan(&a.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("record task attempt: %w", err)
+	}
+	return nil
+}
+
+// TaskTypeHistory provides the track record of past tasks by type.
+type TaskTypeHistory interface {
+	TaskTypeSuccess(taskType string, limit int) (succeeded, attempts int, err error)
+}
+
+// Compile-time verification that DB implements TaskTypeHistory.
+var _ TaskTypeHistory = (*DB)(nil)
+
+// TaskTypeSuccess counts how many of the last limit attempts of tasks of
+// taskType succeeded.
+func (db *DB) TaskTypeSuccess(taskType string, limit int) (succeeded, attempts int, err error) {
+	err = db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END), 0)
+		FROM (
+			SELECT outcome FROM task_attempts
+			WHERE task_type = ?
+			ORDER BY finished_at DESC
+			LIMIT ?
+		)
+	`, string(AttemptSucceeded), taskType, limit).Scan(&attempts, &succeeded)
+	if err != nil {
+		return 0, 0, fmt.Errorf("task type success: %w", err)
+	}
+	return succeeded, attempts, nil
+}
+
+// SessionMetrics summarizes session outcomes.
+type SessionMetrics struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	// Active counts sessions still running or interrupted.
+	Active int `json:"active"`
+	// SuccessRate is Completed over finished (completed or failed) sessions.
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// TaskMetrics summarizes task attempts.
+type TaskMetrics struct {
+	Attempts int `json:"attempts"`
+	// Succeeded counts merged attempts, i.e. delivered features.
+	Succeeded int     `json:"succeeded"`
+	Failed    int     `json:"failed"`
+	TotalCost float64 `json:"total_cost"`
+	// SuccessRate is Succeeded over Attempts.
+	SuccessRate float64 `json:"success_rate"`
+	// AvgCostPerFeature is the cost of all attempts, failed ones included,
+	// divided by the features delivered.
+	AvgCostPerFeature float64 `json:"avg_cost_per_feature"`
+	// AvgDuration is the mean duration of an attempt.
+	AvgDuration time.Duration `json:"avg_duration"`
+}
+
+// FailureCount is the number of failed attempts in a category.
+type FailureCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// MergeMetrics summarizes merges of task work.
+type MergeMetrics struct {
+	Attempted int `json:"attempted"`
+	Conflicts int `json:"conflicts"`
+	// ConflictRate is Conflicts over Attempted.
+	ConflictRate float64 `json:"conflict_rate"`
+	// Quarantined counts low-confidence merges sent to human review.
+	Quarantined int `json:"quarantined"`
+}
+
+// TierMetrics summarizes task attempts at one tier.
+type TierMetrics struct {
+	Tier              string  `json:"tier"`
+	Attempts          int     `json:"attempts"`
+	Succeeded         int     `json:"succeeded"`
+	AvgCostPerFeature float64 `json:"avg_cost_per_feature"`
+}
+
+// Report aggregates past sessions into historical metrics.
+type Report struct {
+	// Since is the start of the reporting window (zero = all history).
+	Since    time.Time      `json:"since"`
+	Sessions SessionMetrics `json:"sessions"`
+	Tasks    TaskMetrics    `json:"tasks"`
+	// Failures lists failure categories, most common first.
+	Failures []FailureCount `json:"failures"`
+	Merges   MergeMetrics   `json:"merges"`
+	Tiers    []TierMetrics  `json:"tiers"`
+}
+
+// SessionMetrics summarizes sessions started at or after since.
+func (db *DB) SessionMetrics(since time.Time) (*SessionMetrics, error) {
+	var m SessionMetrics
+	err := db.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
+		FROM sessions WHERE started_at >= ?
+	`, string(SessionCompleted), string(SessionFailed), formatTime(since)).Scan(&m.Total, &m.Completed, &m.Failed)
+	if err != nil {
+		return nil, fmt.Errorf("session metrics: %w", err)
+	}
+	m.Active = m.Total - m.Completed - m.Failed
+	m.SuccessRate = ratio(m.Completed, m.Completed+m.Failed)
+	return &m, nil
+}
+
+// TaskMetrics summarizes task attempts finished at or after since.
+func (db *DB) TaskMetrics(since time.Time) (*TaskMetrics, error) {
+	var m TaskMetrics
+	var avgMillis float64
+	err := db.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(cost), 0),
+			COALESCE(AVG(duration_ms), 0)
+		FROM task_attempts WHERE finished_at >= ?
+	`, string(AttemptSucceeded), formatTime(since)).Scan(&m.Attempts, &m.Succeeded, &m.TotalCost, &avgMillis)
+	if err != nil {
+		return nil, fmt.Errorf("task metrics: %w", err)
+	}
+	m.Failed = m.Attempts - m.Succeeded
+	m.SuccessRate = ratio(m.Succeeded, m.Attempts)
+	if m.Succeeded > 0 {
+		m.AvgCostPerFeature = m.TotalCost / float64(m.Succeeded)
+	}
+	m.AvgDuration = time.Duration(avgMillis) * time.Millisecond
+	return &m, nil
+}
+
+// FailureCategories counts failed attempts finished at or after since by
+// category, most common first.
+func (db *DB) FailureCategories(since time.Time) ([]FailureCount, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(NULLIF(failure_category, ''), 'unknown') AS category, COUNT(*) AS n
+		FROM task_attempts
+		WHERE outcome != ? AND finished_at >= ?
+		GROUP BY category
+		ORDER BY n DESC, category
+	`, string(AttemptSucceeded), formatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failure categories: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []FailureCount
+	for rows.Next() {
+		var c FailureCount
+		if err := rows.Scan(&c.Category, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan failure category: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// MergeMetrics summarizes merges of attempts finished at or after since.
+func (db *DB) MergeMetrics(since time.Time) (*MergeMetrics, error) {
+	var m MergeMetrics
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(merge_attempted), 0), COALESCE(SUM(merge_conflict), 0)
+		FROM task_attempts WHERE finished_at >= ?
+	`, formatTime(since)).Scan(&m.Attempted, &m.Conflicts)
+	if err != nil {
+		return nil, fmt.Errorf("merge metrics: %w", err)
+	}
+	err = db.QueryRow(`SELECT COUNT(*) FROM merge_reviews WHERE created_at >= ?`, formatTime(since)).Scan(&m.Quarantined)
+	if err != nil {
+		return nil, fmt.Errorf("merge metrics: %w", err)
+	}
+	m.ConflictRate = ratio(m.Conflicts, m.Attempted)
+	return &m, nil
+}
+
+// TierMetrics summarizes attempts finished at or after since per tier.
+func (db *DB) TierMetrics(since time.Time) ([]TierMetrics, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(NULLIF(tier, ''), 'unknown') AS t, COUNT(*),
+			COALESCE(SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(cost), 0)
+		FROM task_attempts WHERE finished_at >= ?
+		GROUP BY t
+		ORDER BY t
+	`, string(AttemptSucceeded), formatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("tier metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var tiers []TierMetrics
+	for rows.Next() {
+		var m TierMetrics
+		var cost float64
+		if err := rows.Scan(&m.Tier, &m.Attempts, &m.Succeeded, &cost); err != nil {
+			return nil, fmt.Errorf("scan tier metrics: %w", err)
+		}
+		if m.Succeeded > 0 {
+			m.AvgCostPerFeature = cost / float64(m.Succeeded)
+		}
+		tiers = append(tiers, m)
+	}
+	return tiers, rows.Err()
+}
+
+// Report aggregates sessions and task attempts since the given time (zero
+// for all history) into a single report.
+func (db *DB) Report(since time.Time) (*Report, error) {
+	sessions, err := db.SessionMetrics(since)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := db.TaskMetrics(since)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := db.FailureCategories(since)
+	if err != nil {
+		return nil, err
+	}
+	merges, err := db.MergeMetrics(since)
+	if err != nil {
+		return nil, err
+	}
+	tiers, err := db.TierMetrics(since)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{
+		Since:    since,
+		Sessions: *sessions,
+		Tasks:    *tasks,
+		Failures: failures,
+		Merges:   *merges,
+		Tiers:    tiers,
+	}, nil
+}
+
+// ratio returns n/d, or 0 if d is 0.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
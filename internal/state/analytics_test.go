@@ -0,0 +1,163 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+
+	sessions := []struct {
+		id      string
+		status  SessionStatus
+		started time.Time
+	}{
+		{"s1", SessionCompleted, now.Add(-time.Hour)},
+		{"s2", SessionCompleted, now.Add(-2 * time.Hour)},
+		{"s3", SessionFailed, now.Add(-3 * time.Hour)},
+		{"s4", SessionActive, now.Add(-time.Minute)},
+		{"ancient", SessionFailed, old},
+	}
+	for _, s := range sessions {
+		if err := db.CreateSession(&Session{ID: s.id, RootTask: "task", Tier: "builder", StartedAt: s.started, Status: s.status}); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+	}
+
+	attempts := []TaskAttempt{
+		{SessionID: "s1", TaskID: "a", Tier: "builder", Outcome: AttemptSucceeded, MergeAttempted: true, Cost: 0.40, Duration: 2 * time.Minute},
+		{SessionID: "s1", TaskID: "b", Tier: "builder", Outcome: AttemptFailed, FailureCategory: "verification", Cost: 0.20, Duration: 4 * time.Minute},
+		{SessionID: "s2", TaskID: "b", Tier: "builder", Outcome: AttemptSucceeded, MergeAttempted: true, MergeConflict: true, Cost: 0.30},
+		{SessionID: "s2", TaskID: "c", Tier: "quick", Outcome: AttemptMergeFailed, FailureCategory: "merge", MergeAttempted: true, MergeConflict: true, Cost: 0.10},
+		{SessionID: "s3", TaskID: "d", Tier: "quick", Outcome: AttemptAborted, FailureCategory: "verification", Cost: 0.05},
+		{SessionID: "ancient", TaskID: "e", Tier: "quick", Outcome: AttemptFailed, FailureCategory: "timeout", Cost: 9, FinishedAt: old},
+	}
+	for i := range attempts {
+		if err := db.RecordTaskAttempt(&attempts[i]); err != nil {
+			t.Fatalf("RecordTaskAttempt failed: %v", err)
+		}
+		if attempts[i].ID == 0 {
+			t.Errorf("expected attempt %d to get an ID", i)
+		}
+	}
+	if err := db.CreateMergeReview(&MergeReview{ID: "r1", SessionID: "s2", TaskID: "c", Files: []string{"a.go"}}); err != nil {
+		t.Fatalf("CreateMergeReview failed: %v", err)
+	}
+
+	report, err := db.Report(now.Add(-30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	s := report.Sessions
+	if s.Total != 4 || s.Completed != 2 || s.Failed != 1 || s.Active != 1 {
+		t.Errorf("sessions = %+v", s)
+	}
+	if !closeTo(s.SuccessRate, 2.0/3.0) {
+		t.Errorf("session success rate = %v, want 2/3", s.SuccessRate)
+	}
+
+	tm := report.Tasks
+	if tm.Attempts != 5 || tm.Succeeded != 2 || tm.Failed != 3 {
+		t.Errorf("tasks = %+v", tm)
+	}
+	if !closeTo(tm.TotalCost, 1.05) || !closeTo(tm.AvgCostPerFeature, 0.525) {
+		t.Errorf("expected failed attempts to count toward feature cost, got %+v", tm)
+	}
+	if tm.AvgDuration != 72*time.Second {
+		t.Errorf("avg duration = %v, want 1m12s", tm.AvgDuration)
+	}
+
+	if len(report.Failures) != 2 || report.Failures[0] != (FailureCount{"verification", 2}) || report.Failures[1] != (FailureCount{"merge", 1}) {
+		t.Errorf("failures = %+v", report.Failures)
+	}
+
+	m := report.Merges
+	if m.Attempted != 3 || m.Conflicts != 2 || m.Quarantined != 1 || !closeTo(m.ConflictRate, 2.0/3.0) {
+		t.Errorf("merges = %+v", m)
+	}
+
+	if len(report.Tiers) != 2 || report.Tiers[0].Tier != "builder" || report.Tiers[1].Tier != "quick" {
+		t.Fatalf("tiers = %+v", report.Tiers)
+	}
+	if b := report.Tiers[0]; b.Attempts != 3 || b.Succeeded != 2 || !closeTo(b.AvgCostPerFeature, 0.45) {
+		t.Errorf("builder tier = %+v", b)
+	}
+	if q := report.Tiers[1]; q.Succeeded != 0 || q.AvgCostPerFeature != 0 {
+		t.Errorf("quick tier = %+v", q)
+	}
+
+	all, err := db.Report(time.Time{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if all.Sessions.Total != 5 || all.Tasks.Attempts != 6 {
+		t.Errorf("expected zero since to include all history, got %+v / %+v", all.Sessions, all.Tasks)
+	}
+}
+
+func TestReport_Empty(t *testing.T) {
+	db := setupTestDB(t)
+
+	report, err := db.Report(time.Time{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.Sessions.SuccessRate != 0 || report.Tasks.AvgCostPerFeature != 0 || report.Merges.ConflictRate != 0 {
+		t.Errorf("expected zero rates without history, got %+v", report)
+	}
+	if len(report.Failures) != 0 || len(report.Tiers) != 0 {
+		t.Errorf("expected no breakdowns, got %+v / %+v", report.Failures, report.Tiers)
+	}
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestTaskTypeSuccess(t *testing.T) {
+	db := setupTestDB(t)
+	base := time.Now().Add(-time.Hour)
+
+	outcomes := []AttemptOutcome{AttemptFailed, AttemptSucceeded, AttemptSucceeded, AttemptAborted, AttemptSucceeded}
+	for i, outcome := range outcomes {
+		a := &TaskAttempt{
+			SessionID:  "s1",
+			TaskID:     "t",
+			TaskType:   "BUGFIX",
+			Outcome:    outcome,
+			FinishedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := db.RecordTaskAttempt(a); err != nil {
+			t.Fatalf("RecordTaskAttempt failed: %v", err)
+		}
+	}
+	if err := db.RecordTaskAttempt(&TaskAttempt{SessionID: "s1", TaskID: "f", TaskType: "FEATURE", Outcome: AttemptFailed}); err != nil {
+		t.Fatalf("RecordTaskAttempt failed: %v", err)
+	}
+
+	succeeded, attempts, err := db.TaskTypeSuccess("BUGFIX", 10)
+	if err != nil {
+		t.Fatalf("TaskTypeSuccess failed: %v", err)
+	}
+	if succeeded != 3 || attempts != 5 {
+		t.Errorf("got %d/%d, want 3/5", succeeded, attempts)
+	}
+
+	// The limit keeps the most recent attempts
+	succeeded, attempts, err = db.TaskTypeSuccess("BUGFIX", 2)
+	if err != nil {
+		t.Fatalf("TaskTypeSuccess failed: %v", err)
+	}
+	if succeeded != 1 || attempts != 2 {
+		t.Errorf("got %d/%d, want 1/2", succeeded, attempts)
+	}
+
+	if _, attempts, _ := db.TaskTypeSuccess("REFACTOR", 10); attempts != 0 {
+		t.Errorf("expected no history for an unseen type, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Baseline is the build, test and lint state of the repository before a
+// session's agents changed anything. Validation compares against it so
+// failures that already existed do not block the session.
+type Baseline struct {
+	SessionID string `json:"session_id"`
+	// Commit is the commit the baseline was captured on, if known.
+	Commit       string    `json:"commit,omitempty"`
+	BuildPassed  bool      `json:"build_passed"`
+	FailingTests []string  `json:"failing_tests,omitempty"`
+	LintErrors   []string  `json:"lint_errors,omitempty"`
+	TypeErrors   []string  `json:"type_errors,omitempty"`
+	CapturedAt   time.Time `json:"captured_at"`
+}
+
+// BaselineStore persists session baselines.
+type BaselineStore interface {
+	// SaveBaseline saves a session's baseline, replacing any earlier one.
+	SaveBaseline(b *Baseline) error
+	// GetBaseline retrieves a session's baseline, or nil if none was saved.
+	GetBaseline(sessionID string) (*Baseline, error)
+}
+
+// Compile-time verification that DB implements BaselineStore.
+var _ BaselineStore = (*DB)(nil)
+
+// SaveBaseline saves a session's baseline, replacing any earlier one.
+func (db *DB) SaveBaseline(b *Baseline) error {
+	if b.CapturedAt.IsZero() {
+		b.CapturedAt = time.Now()
+	}
+	failingTests, _ := json.Marshal(b.FailingTests)
+	lintErrors, _ := json.Marshal(b.LintErrors)
+	typeErrors, _ := json.Marshal(b.TypeErrors)
+
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO baselines (session_id, commit_sha, build_passed, failing_tests,
+			lint_errors, type_errors, captured_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, b.SessionID, b.Commit, b.BuildPassed, string(failingTests), string(lintErrors),
+		string(typeErrors), formatTime(b.CapturedAt))
+	if err != nil {
+		return fmt.Errorf("save baseline: %w", err)
+	}
+	return nil
+}
+
+// GetBaseline retrieves a session's baseline.
+// Returns nil if the session has no baseline.
+func (db *DB) GetBaseline(sessionID string) (*Baseline, error) {
+	var b Baseline
+	var commit, failingTests, lintErrors, typeErrors sql.NullString
+	var capturedAt string
+	err := db.QueryRow(`
+		SELECT session_id, commit_sha, build_passed, failing_tests, lint_errors, type_errors, captured_at
+		FROM baselines WHERE session_id = ?
+	`, sessionID).Scan(&b.SessionID, &commit, &b.BuildPassed, &failingTests, &lintErrors, &typeErrors, &capturedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get baseline: %w", err)
+	}
+
+	b.Commit = commit.String
+	for _, field := range []struct {
+		raw sql.NullString
+		dst *[]string
+	}{{failingTests, &b.FailingTests}, {lintErrors, &b.LintErrors}, {typeErrors, &b.TypeErrors}} {
+		if field.raw.Valid {
+			json.Unmarshal([]byte(field.raw.String), field.dst)
+		}
+	}
+	if b.CapturedAt, err = parseTime(capturedAt); err != nil {
+		return nil, fmt.Errorf("parse baseline time: %w", err)
+	}
+	return &b, nil
+}
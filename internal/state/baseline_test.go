@@ -0,0 +1,46 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBaseline_SaveGet(t *testing.T) {
+	db := setupTestDB(t)
+
+	if b, err := db.GetBaseline("sess"); err != nil || b != nil {
+		t.Fatalf("expected no baseline before one is saved, got %+v, %v", b, err)
+	}
+
+	saved := &Baseline{
+		SessionID:    "sess",
+		Commit:       "abc123",
+		BuildPassed:  true,
+		FailingTests: []string{"example.com/app/TestLegacy"},
+		LintErrors:   []string{"main.go:vet: unreachable code"},
+	}
+	if err := db.SaveBaseline(saved); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+
+	got, err := db.GetBaseline("sess")
+	if err != nil {
+		t.Fatalf("GetBaseline failed: %v", err)
+	}
+	if got.Commit != "abc123" || !got.BuildPassed || got.CapturedAt.IsZero() {
+		t.Errorf("baseline = %+v", got)
+	}
+	if !reflect.DeepEqual(got.FailingTests, saved.FailingTests) || !reflect.DeepEqual(got.LintErrors, saved.LintErrors) || len(got.TypeErrors) != 0 {
+		t.Errorf("baseline failures = %+v", got)
+	}
+
+	// Saving again replaces the session's baseline
+	saved.BuildPassed = false
+	saved.TypeErrors = []string{"main.go:3: undefined: x"}
+	if err := db.SaveBaseline(saved); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+	if got, _ := db.GetBaseline("sess"); got.BuildPassed || len(got.TypeErrors) != 1 {
+		t.Errorf("replaced baseline = %+v", got)
+	}
+}
@@ -1,28 +1,58 @@
 // Package state provides SQLite-based state management for Alphie.
 // It handles both global state (~/.local/share/alphie/alphie.db) and
-// project-local state (.alphie/state.db).
+// project-local state (.alphie/state.db). Setting ALPHIE_STATE_DB to a
+// postgres:// URL keeps both in a Postgres database instead.
 package state
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
-// DB wraps an SQLite database connection with Alphie-specific operations.
+// DBEnv names the environment variable overriding the database path with
+// another data source, e.g. a postgres:// URL shared between CI runners.
+const DBEnv = "ALPHIE_STATE_DB"
+
+// busyTimeout is how long a connection waits for another connection's
+// lock before SQLite reports the database as busy.
+const busyTimeout = 5 * time.Second
+
+// Retries of writes that still find the database busy after busyTimeout,
+// e.g. while another process holds a long transaction.
+const (
+	maxBusyRetries = 5
+	busyBackoff    = 50 * time.Millisecond
+)
+
+// writeLocks holds one mutex per database file, shared by every DB of the
+// process that opened it, so their writes never contend for SQLite's lock.
+var writeLocks sync.Map
+
+// DB wraps an SQLite or Postgres database connection with Alphie-specific
+// operations.
 type DB struct {
-	conn *sql.DB
+	conn *sqldb.DB
 	path string
 	mu   sync.RWMutex
+	// writeMu serializes the writes of every DB on the same file.
+	writeMu *sync.Mutex
 }
 
-// GlobalDBPath returns the path to the global Alphie database.
+// GlobalDBPath returns the path to the global Alphie database, or the data
+// source in ALPHIE_STATE_DB if it is set.
 func GlobalDBPath() string {
+	if dsn := os.Getenv(DBEnv); dsn != "" {
+		return dsn
+	}
 	dataDir := os.Getenv("XDG_DATA_HOME")
 	if dataDir == "" {
 		home, _ := os.UserHomeDir()
@@ -31,46 +61,86 @@ func GlobalDBPath() string {
 	return filepath.Join(dataDir, "alphie", "alphie.db")
 }
 
-// ProjectDBPath returns the path to the project-local database.
+// ProjectDBPath returns the path to the project-local database, or the
+// data source in ALPHIE_STATE_DB if it is set.
 func ProjectDBPath(projectRoot string) string {
+	if dsn := os.Getenv(DBEnv); dsn != "" {
+		return dsn
+	}
 	return filepath.Join(projectRoot, ".alphie", "state.db")
 }
 
-// Open opens an SQLite database at the given path.
-// It creates the parent directories if they don't exist.
-// WAL mode is enabled for concurrent reads.
+// Exists reports whether the database at path exists. A Postgres database
+// always does; its server creates the tables on Migrate.
+func Exists(path string) bool {
+	if sqldb.DialectOf(path) == sqldb.Postgres {
+		return true
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open opens an SQLite database at the given path, or the Postgres
+// database of a postgres:// URL.
+// For SQLite, it creates the parent directories if they don't exist.
+// WAL mode is enabled for concurrent reads, and every connection waits
+// for locks held by other connections instead of failing with "database is
+// locked". Transactions take the write lock when they begin, so two
+// transactions never deadlock upgrading their read locks.
 func Open(path string) (*DB, error) {
-	// Ensure parent directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("create db directory: %w", err)
+	dialect := sqldb.DialectOf(path)
+	dsn := path
+	if dialect == sqldb.SQLite {
+		// Ensure parent directory exists
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("create db directory: %w", err)
+		}
+		dsn = dataSourceName(path)
 	}
 
-	conn, err := sql.Open("sqlite", path)
+	// The pragmas run on each new connection; the ping of the first one
+	// surfaces their errors
+	conn, err := sqldb.Open(dialect, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// Enable WAL mode for concurrent reads
-	if _, err := conn.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("enable WAL mode: %w", err)
-	}
-
-	// Enable foreign keys
-	if _, err := conn.Exec("PRAGMA foreign_keys=ON"); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
+	key := path
+	if dialect == sqldb.SQLite {
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
 	}
+	lock, _ := writeLocks.LoadOrStore(key, &sync.Mutex{})
 
 	db := &DB{
-		conn: conn,
-		path: path,
+		conn:    conn,
+		path:    path,
+		writeMu: lock.(*sync.Mutex),
 	}
 
 	return db, nil
 }
 
+// dataSourceName returns the DSN opening path with the pragmas every
+// connection needs: the busy timeout first, so the others wait for locks
+// too.
+func dataSourceName(path string) string {
+	return fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(1)&_txlock=immediate",
+		path, busyTimeout.Milliseconds())
+}
+
+// JournalMode returns the database's journal mode, "wal" once Open has
+// enabled it. It is only available on SQLite.
+func (db *DB) JournalMode() (string, error) {
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		return "", fmt.Errorf("get journal mode: %w", err)
+	}
+	return mode, nil
+}
+
 // OpenGlobal opens the global Alphie database.
 func OpenGlobal() (*DB, error) {
 	return Open(GlobalDBPath())
@@ -88,23 +158,31 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// Path returns the path to the database file.
+// Path returns the path to the database file, or its URL for Postgres.
 func (db *DB) Path() string {
 	return db.path
 }
 
-// Migrate applies all pending schema migrations.
+// Dialect returns the kind of database the DB is connected to.
+func (db *DB) Dialect() sqldb.Dialect {
+	return db.conn.Dialect
+}
+
+// Migrate applies all pending schema migrations. They are written for
+// SQLite and rewritten for the database's dialect.
 func (db *DB) Migrate() error {
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	// Create schema version table
-	_, err := db.conn.Exec(`
+	_, err := db.conn.Exec(db.conn.Dialect.Schema(`
 		CREATE TABLE IF NOT EXISTS schema_version (
 			version INTEGER PRIMARY KEY,
 			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
-	`)
+	`))
 	if err != nil {
 		return fmt.Errorf("create schema_version table: %w", err)
 	}
@@ -124,6 +202,19 @@ func (db *DB) Migrate() error {
 		{1, migrationV1Sessions},
 		{2, migrationV2Agents},
 		{3, migrationV3Tasks},
+		{4, migrationV4Worktrees},
+		{5, migrationV5MergeReviews},
+		{6, migrationV6TaskAttempts},
+		{7, migrationV7AttemptTaskType},
+		{8, migrationV8ReviewFindings},
+		{9, migrationV9Escalations},
+		{10, migrationV10Baselines},
+		{11, migrationV11ProgLinks},
+		{12, migrationV12SessionOrigins},
+		{13, migrationV13PromptCache},
+		{14, migrationV14SessionEvents},
+		{15, migrationV15SecurityFindings},
+		{16, migrationV16SessionTaskOrder(db.conn.Dialect)},
 	}
 
 	for _, m := range migrations {
@@ -136,7 +227,7 @@ func (db *DB) Migrate() error {
 			return fmt.Errorf("begin transaction: %w", err)
 		}
 
-		if _, err := tx.Exec(m.sql); err != nil {
+		if _, err := tx.Exec(db.conn.Dialect.Schema(m.sql)); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("apply migration v%d: %w", m.version, err)
 		}
@@ -206,11 +297,234 @@ CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
 CREATE INDEX IF NOT EXISTS idx_tasks_assigned_to ON tasks(assigned_to);
 `
 
-// Exec executes a query that doesn't return rows.
+const migrationV4Worktrees = `
+CREATE TABLE IF NOT EXISTS worktrees (
+	path TEXT PRIMARY KEY,
+	repo_path TEXT NOT NULL,
+	branch TEXT,
+	task_id TEXT,
+	status TEXT NOT NULL DEFAULT 'in_use',
+	pid INTEGER,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_worktrees_repo_path ON worktrees(repo_path);
+CREATE INDEX IF NOT EXISTS idx_worktrees_status ON worktrees(status);
+`
+
+const migrationV5MergeReviews = `
+CREATE TABLE IF NOT EXISTS merge_reviews (
+	id TEXT PRIMARY KEY,
+	session_id TEXT,
+	task_id TEXT NOT NULL,
+	agent_branch TEXT,
+	target_branch TEXT NOT NULL,
+	quarantine_branch TEXT NOT NULL,
+	confidence REAL NOT NULL DEFAULT 0,
+	reason TEXT,
+	files TEXT,
+	status TEXT NOT NULL DEFAULT 'pending',
+	created_at DATETIME NOT NULL,
+	decided_at DATETIME,
+	decided_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_status ON merge_reviews(status);
+CREATE INDEX IF NOT EXISTS idx_merge_reviews_task_id ON merge_reviews(task_id);
+`
+
+const migrationV6TaskAttempts = `
+CREATE TABLE IF NOT EXISTS task_attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	tier TEXT,
+	outcome TEXT NOT NULL,
+	failure_category TEXT,
+	merge_attempted INTEGER NOT NULL DEFAULT 0,
+	merge_conflict INTEGER NOT NULL DEFAULT 0,
+	tokens_used INTEGER NOT NULL DEFAULT 0,
+	cost REAL NOT NULL DEFAULT 0.0,
+	duration_ms INTEGER NOT NULL DEFAULT 0,
+	finished_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_attempts_session_id ON task_attempts(session_id);
+CREATE INDEX IF NOT EXISTS idx_task_attempts_finished_at ON task_attempts(finished_at);
+`
+
+const migrationV7AttemptTaskType = `
+ALTER TABLE task_attempts ADD COLUMN task_type TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_task_attempts_task_type ON task_attempts(task_type);
+`
+
+const migrationV8ReviewFindings = `
+CREATE TABLE IF NOT EXISTS review_findings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	task_id TEXT NOT NULL,
+	reviewer TEXT NOT NULL,
+	severity TEXT,
+	file TEXT,
+	line INTEGER NOT NULL DEFAULT 0,
+	message TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_findings_task_id ON review_findings(task_id);
+`
+
+const migrationV9Escalations = `
+CREATE TABLE IF NOT EXISTS escalations (
+	id TEXT PRIMARY KEY,
+	session_id TEXT,
+	task_id TEXT NOT NULL,
+	prog_task_id TEXT,
+	task_title TEXT,
+	reason TEXT NOT NULL,
+	context TEXT,
+	options TEXT,
+	status TEXT NOT NULL DEFAULT 'open',
+	resolution TEXT,
+	resolved_by TEXT,
+	created_at DATETIME NOT NULL,
+	resolved_at DATETIME,
+	applied_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_escalations_status ON escalations(status);
+CREATE INDEX IF NOT EXISTS idx_escalations_task_id ON escalations(task_id);
+`
+
+const migrationV10Baselines = `
+CREATE TABLE IF NOT EXISTS baselines (
+	session_id TEXT PRIMARY KEY,
+	commit_sha TEXT,
+	build_passed INTEGER NOT NULL DEFAULT 0,
+	failing_tests TEXT,
+	lint_errors TEXT,
+	type_errors TEXT,
+	captured_at DATETIME NOT NULL
+);
+`
+
+const migrationV11ProgLinks = `
+CREATE TABLE IF NOT EXISTS prog_links (
+	task_id TEXT PRIMARY KEY,
+	prog_id TEXT NOT NULL UNIQUE,
+	epic_id TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_prog_links_epic ON prog_links(epic_id);
+`
+
+const migrationV12SessionOrigins = `
+CREATE TABLE IF NOT EXISTS session_origins (
+	session_id TEXT PRIMARY KEY,
+	epic_id TEXT,
+	session_branch TEXT,
+	base_branch TEXT NOT NULL,
+	base_commit TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_origins_epic ON session_origins(epic_id);
+
+CREATE TABLE IF NOT EXISTS session_tasks (
+	session_id TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	PRIMARY KEY (session_id, task_id)
+);
+`
+
+const migrationV13PromptCache = `
+CREATE TABLE IF NOT EXISTS prompt_cache (
+	key TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,
+	scope TEXT NOT NULL,
+	response TEXT NOT NULL,
+	hits INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	last_hit_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_prompt_cache_scope ON prompt_cache(kind, scope);
+
+CREATE TABLE IF NOT EXISTS prompt_cache_stats (
+	kind TEXT PRIMARY KEY,
+	hits INTEGER NOT NULL DEFAULT 0,
+	misses INTEGER NOT NULL DEFAULT 0
+);
+`
+
+const migrationV14SessionEvents = `
+CREATE TABLE IF NOT EXISTS session_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	task_id TEXT,
+	agent_id TEXT,
+	payload TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_events_session ON session_events(session_id, id);
+`
+
+const migrationV15SecurityFindings = `
+CREATE TABLE IF NOT EXISTS security_findings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT,
+	task_id TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	rule TEXT,
+	severity TEXT,
+	file TEXT,
+	line INTEGER NOT NULL DEFAULT 0,
+	message TEXT NOT NULL,
+	blocking INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_findings_task_id ON security_findings(task_id);
+`
+
+// migrationV16SessionTaskOrder gives session_tasks a sequence column to
+// order a session's tasks by, since Postgres has no stable insertion order.
+// Existing rows are copied in SQLite's rowid order; on Postgres they keep
+// the order of the table scan, the best left to recover.
+func migrationV16SessionTaskOrder(dialect sqldb.Dialect) string {
+	order := ""
+	if dialect == sqldb.SQLite {
+		order = " ORDER BY rowid"
+	}
+	return `
+CREATE TABLE session_tasks_ordered (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	UNIQUE (session_id, task_id)
+);
+INSERT INTO session_tasks_ordered (session_id, task_id)
+	SELECT session_id, task_id FROM session_tasks` + order + `;
+DROP TABLE session_tasks;
+ALTER TABLE session_tasks_ordered RENAME TO session_tasks;
+`
+}
+
+// Exec executes a query that doesn't return rows. Writes are serialized
+// and retried while the database is busy.
 func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-	return db.conn.Exec(query, args...)
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result sql.Result
+	err := retryBusy(func() error {
+		var err error
+		result, err = db.conn.Exec(query, args...)
+		return err
+	})
+	return result, err
 }
 
 // Query executes a query that returns rows.
@@ -227,30 +541,61 @@ func (db *DB) QueryRow(query string, args ...any) *sql.Row {
 	return db.conn.QueryRow(query, args...)
 }
 
-// Transaction runs the given function within a transaction.
-func (db *DB) Transaction(fn func(tx *sql.Tx) error) error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
+// Transaction runs the given function within a transaction. Transactions
+// are serialized with the other writes, and a transaction that finds the
+// database busy is rolled back and run again, so fn must not have effects
+// outside tx.
+func (db *DB) Transaction(fn func(tx *sqldb.Tx) error) error {
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
+	return retryBusy(func() error {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
 
-	if err := fn(tx); err != nil {
-		tx.Rollback()
-		return err
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		return tx.Commit()
+	})
+}
+
+// retryBusy runs fn until it does not fail with a busy database, backing
+// off between attempts.
+func retryBusy(fn func() error) error {
+	err := fn()
+	for attempt := 0; attempt < maxBusyRetries && isBusy(err); attempt++ {
+		time.Sleep(busyBackoff << attempt)
+		err = fn()
 	}
+	return err
+}
 
-	return tx.Commit()
+// isBusy reports whether err is SQLite finding the database locked.
+// Postgres waits for row locks itself.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
 }
 
-// formatTime formats a time.Time for SQLite storage.
+// formatTime formats a time.Time for storage. Postgres parses the string
+// into its TIMESTAMPTZ columns.
 func formatTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
 
-// parseTime parses a time string from SQLite.
+// parseTime parses a time string from the database. Postgres timestamps
+// are read back with fractional seconds, which time.Parse accepts.
 func parseTime(s string) (time.Time, error) {
 	return time.Parse(time.RFC3339, s)
 }
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+	"github.com/ShayCichocki/alphie/internal/sqldb/sqldbtest"
 )
 
 // tempDBPath returns a path to a temp database file.
@@ -16,10 +20,29 @@ func tempDBPath(t *testing.T) string {
 	return filepath.Join(dir, "test.db")
 }
 
-// setupTestDB creates a new temporary database for testing.
+// setupTestDB creates a new temporary database for testing: an SQLite
+// file, or a Postgres schema if ALPHIE_TEST_POSTGRES_URL is set.
 func setupTestDB(t *testing.T) *DB {
 	t.Helper()
-	db, err := Open(tempDBPath(t))
+	path := sqldbtest.PostgresDSN(t)
+	if path == "" {
+		path = tempDBPath(t)
+	}
+	return openTestDB(t, path)
+}
+
+// setupSQLiteDB creates a new temporary SQLite database for tests of
+// SQLite's locking and pragmas.
+func setupSQLiteDB(t *testing.T) *DB {
+	t.Helper()
+	return openTestDB(t, tempDBPath(t))
+}
+
+// openTestDB opens and migrates the database at path, closing it when the
+// test ends.
+func openTestDB(t *testing.T, path string) *DB {
+	t.Helper()
+	db, err := Open(path)
 	if err != nil {
 		t.Fatalf("failed to open test db: %v", err)
 	}
@@ -106,7 +129,7 @@ func TestMigrate(t *testing.T) {
 	}
 
 	// Check tables exist
-	tables := []string{"schema_version", "sessions", "agents", "tasks"}
+	tables := []string{"schema_version", "sessions", "agents", "tasks", "worktrees", "merge_reviews", "task_attempts", "review_findings", "escalations", "baselines"}
 	for _, table := range tables {
 		var count int
 		row := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table)
@@ -119,6 +142,34 @@ func TestMigrate(t *testing.T) {
 	}
 }
 
+func TestExists(t *testing.T) {
+	path := tempDBPath(t)
+	if Exists(path) {
+		t.Errorf("Exists(%q) = true before the database is opened", path)
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.Close()
+	if !Exists(path) {
+		t.Errorf("Exists(%q) = false after the database is opened", path)
+	}
+	if !Exists("postgres://localhost/alphie") {
+		t.Error("Exists() = false for a Postgres URL")
+	}
+}
+
+func TestProjectDBPath_Env(t *testing.T) {
+	t.Setenv(DBEnv, "postgres://ci@db/alphie")
+	if got := ProjectDBPath("/my/project"); got != "postgres://ci@db/alphie" {
+		t.Errorf("ProjectDBPath() = %q, want the %s data source", got, DBEnv)
+	}
+	if got := GlobalDBPath(); got != "postgres://ci@db/alphie" {
+		t.Errorf("GlobalDBPath() = %q, want the %s data source", got, DBEnv)
+	}
+}
+
 func TestMigrate_Idempotent(t *testing.T) {
 	db, err := Open(tempDBPath(t))
 	if err != nil {
@@ -139,8 +190,8 @@ func TestMigrate_Idempotent(t *testing.T) {
 	if err := row.Scan(&version); err != nil {
 		t.Fatalf("failed to get schema version: %v", err)
 	}
-	if version != 3 {
-		t.Errorf("schema version = %d, want 3", version)
+	if version != 16 {
+		t.Errorf("schema version = %d, want 16", version)
 	}
 }
 
@@ -171,7 +222,7 @@ func TestMigrate_SchemaVersionTracking(t *testing.T) {
 		versions = append(versions, v)
 	}
 
-	expected := []int{1, 2, 3}
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
 	if len(versions) != len(expected) {
 		t.Errorf("versions = %v, want %v", versions, expected)
 	}
@@ -182,6 +233,102 @@ func TestMigrate_SchemaVersionTracking(t *testing.T) {
 	}
 }
 
+func TestOpen_ConnectionPragmas(t *testing.T) {
+	db := setupSQLiteDB(t)
+
+	if mode, err := db.JournalMode(); err != nil || mode != "wal" {
+		t.Errorf("JournalMode() = %q, %v, want wal", mode, err)
+	}
+	// Every pooled connection gets the pragmas, not just the first
+	db.conn.SetMaxIdleConns(0)
+	for i := 0; i < 3; i++ {
+		var timeout, foreignKeys int
+		if err := db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+			t.Fatal(err)
+		}
+		if timeout != int(busyTimeout.Milliseconds()) || foreignKeys != 1 {
+			t.Errorf("busy_timeout = %d, foreign_keys = %d, want %d and 1", timeout, foreignKeys, busyTimeout.Milliseconds())
+		}
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	path := tempDBPath(t)
+	dbs := make([]*DB, 2)
+	for i := range dbs {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		defer db.Close()
+		if err := db.Migrate(); err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		dbs[i] = db
+	}
+
+	// Goroutines of two DBs on the same file write at once
+	var wg sync.WaitGroup
+	errs := make(chan error, 80)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			db := dbs[g%2]
+			for i := 0; i < 10; i++ {
+				s := &Session{ID: fmt.Sprintf("s-%d-%d", g, i), RootTask: "task", Tier: "builder", StartedAt: time.Now(), Status: SessionActive}
+				if err := db.CreateSession(s); err != nil {
+					errs <- err
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent write failed: %v", err)
+	}
+
+	var count int
+	if err := dbs[0].QueryRow("SELECT COUNT(*) FROM sessions").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 80 {
+		t.Errorf("sessions = %d, want 80", count)
+	}
+}
+
+func TestExec_WaitsForOtherProcessLock(t *testing.T) {
+	db := setupSQLiteDB(t)
+
+	// Another process holding the write lock is simulated by a connection
+	// outside the DB
+	other, err := sql.Open("sqlite", db.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	tx, err := other.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO sessions (id, root_task, tier, started_at) VALUES ('other', 'x', 'quick', '2024-01-01T00:00:00Z')"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		tx.Commit()
+	}()
+
+	s := &Session{ID: "mine", RootTask: "task", Tier: "builder", StartedAt: time.Now(), Status: SessionActive}
+	if err := db.CreateSession(s); err != nil {
+		t.Fatalf("CreateSession while another connection held the lock: %v", err)
+	}
+}
+
 func TestExec(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -251,7 +398,7 @@ func TestQueryRow(t *testing.T) {
 func TestTransaction_Success(t *testing.T) {
 	db := setupTestDB(t)
 
-	err := db.Transaction(func(tx *sql.Tx) error {
+	err := db.Transaction(func(tx *sqldb.Tx) error {
 		_, err := tx.Exec("INSERT INTO sessions (id, root_task, tier, token_budget, tokens_used, started_at, status) VALUES (?, ?, ?, ?, ?, ?, ?)",
 			"tx-1", "task-1", "premium", 1000, 0, "2024-01-01T00:00:00Z", "active")
 		return err
@@ -274,7 +421,7 @@ func TestTransaction_Success(t *testing.T) {
 func TestTransaction_Rollback(t *testing.T) {
 	db := setupTestDB(t)
 
-	err := db.Transaction(func(tx *sql.Tx) error {
+	err := db.Transaction(func(tx *sqldb.Tx) error {
 		_, err := tx.Exec("INSERT INTO sessions (id, root_task, tier, token_budget, tokens_used, started_at, status) VALUES (?, ?, ?, ?, ?, ?, ?)",
 			"tx-fail", "task-1", "premium", 1000, 0, "2024-01-01T00:00:00Z", "active")
 		if err != nil {
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EscalationStatus represents the state of an escalated task.
+type EscalationStatus string
+
+const (
+	// EscalationOpen means the task is parked awaiting a human resolution.
+	EscalationOpen EscalationStatus = "open"
+	// EscalationResolved means a resolution was recorded but no session
+	// has acted on it yet.
+	EscalationResolved EscalationStatus = "resolved"
+	// EscalationApplied means a session acted on the resolution.
+	EscalationApplied EscalationStatus = "applied"
+)
+
+// Escalation is a task that needs human intervention. The task is parked
+// until a human records a resolution, which the next session to see it
+// applies.
+type Escalation struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	TaskID    string `json:"task_id"`
+	// ProgTaskID identifies the task across sessions, since resumed
+	// sessions give tasks new IDs.
+	ProgTaskID string           `json:"prog_task_id,omitempty"`
+	TaskTitle  string           `json:"task_title"`
+	Reason     string           `json:"reason"`
+	Context    string           `json:"context,omitempty"`
+	Options    []string         `json:"options,omitempty"`
+	Status     EscalationStatus `json:"status"`
+	Resolution string           `json:"resolution,omitempty"`
+	ResolvedBy string           `json:"resolved_by,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ResolvedAt *time.Time       `json:"resolved_at,omitempty"`
+	AppliedAt  *time.Time       `json:"applied_at,omitempty"`
+}
+
+// Escalation operations
+
+// CreateEscalation adds an escalation to the inbox.
+func (db *DB) CreateEscalation(e *Escalation) error {
+	if e.Status == "" {
+		e.Status = EscalationOpen
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	options, _ := json.Marshal(e.Options)
+
+	_, err := db.Exec(`
+		INSERT INTO escalations (id, session_id, task_id, prog_task_id, task_title, reason, context,
+			options, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.ID, e.SessionID, e.TaskID, e.ProgTaskID, e.TaskTitle, e.Reason, e.Context,
+		string(options), string(e.Status), formatTime(e.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("create escalation: %w", err)
+	}
+	return nil
+}
+
+// GetEscalation retrieves an escalation by ID.
+// Returns nil if the escalation does not exist.
+func (db *DB) GetEscalation(id string) (*Escalation, error) {
+	row := db.QueryRow(`
+		SELECT id, session_id, task_id, prog_task_id, task_title, reason, context, options,
+			status, resolution, resolved_by, created_at, resolved_at, applied_at
+		FROM escalations WHERE id = ?
+	`, id)
+
+	e, err := scanEscalation(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get escalation: %w", err)
+	}
+	return e, nil
+}
+
+// ListEscalations lists escalations, oldest first, optionally filtered by status.
+func (db *DB) ListEscalations(status *EscalationStatus) ([]Escalation, error) {
+	var rows *sql.Rows
+	var err error
+
+	if status != nil {
+		rows, err = db.Query(`
+			SELECT id, session_id, task_id, prog_task_id, task_title, reason, context, options,
+				status, resolution, resolved_by, created_at, resolved_at, applied_at
+			FROM escalations WHERE status = ? ORDER BY created_at
+		`, string(*status))
+	} else {
+		rows, err = db.Query(`
+			SELECT id, session_id, task_id, prog_task_id, task_title, reason, context, options,
+				status, resolution, resolved_by, created_at, resolved_at, applied_at
+			FROM escalations ORDER BY created_at
+		`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list escalations: %w", err)
+	}
+	defer rows.Close()
+
+	var escalations []Escalation
+	for rows.Next() {
+		e, err := scanEscalation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan escalation: %w", err)
+		}
+		escalations = append(escalations, *e)
+	}
+	return escalations, rows.Err()
+}
+
+// ResolveEscalation records a human resolution on an open escalation.
+// Returns an error if the escalation does not exist or is not open.
+func (db *DB) ResolveEscalation(id, resolution, resolvedBy string) error {
+	result, err := db.Exec(`
+		UPDATE escalations SET status = ?, resolution = ?, resolved_by = ?, resolved_at = ?
+		WHERE id = ? AND status = ?
+	`, string(EscalationResolved), resolution, resolvedBy, formatTime(time.Now()), id, string(EscalationOpen))
+	if err != nil {
+		return fmt.Errorf("resolve escalation: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("resolve escalation: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("escalation %s not found or not open", id)
+	}
+	return nil
+}
+
+// MarkEscalationApplied records that a session acted on a resolved
+// escalation. Returns an error if the escalation is not resolved, so two
+// sessions cannot both apply it.
+func (db *DB) MarkEscalationApplied(id string) error {
+	result, err := db.Exec(`
+		UPDATE escalations SET status = ?, applied_at = ?
+		WHERE id = ? AND status = ?
+	`, string(EscalationApplied), formatTime(time.Now()), id, string(EscalationResolved))
+	if err != nil {
+		return fmt.Errorf("mark escalation applied: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("mark escalation applied: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("escalation %s not found or not resolved", id)
+	}
+	return nil
+}
+
+// scanEscalation scans an escalation row from a *sql.Row or *sql.Rows.
+func scanEscalation(s interface{ Scan(...any) error }) (*Escalation, error) {
+	var e Escalation
+	var sessionID, progTaskID, taskTitle, context, options, resolution, resolvedBy sql.NullString
+	var createdAt string
+	var resolvedAt, appliedAt sql.NullString
+	if err := s.Scan(&e.ID, &sessionID, &e.TaskID, &progTaskID, &taskTitle, &e.Reason, &context, &options,
+		&e.Status, &resolution, &resolvedBy, &createdAt, &resolvedAt, &appliedAt); err != nil {
+		return nil, err
+	}
+	e.SessionID = sessionID.String
+	e.ProgTaskID = progTaskID.String
+	e.TaskTitle = taskTitle.String
+	e.Context = context.String
+	e.Resolution = resolution.String
+	e.ResolvedBy = resolvedBy.String
+	if options.Valid {
+		json.Unmarshal([]byte(options.String), &e.Options)
+	}
+	if t, err := parseTime(createdAt); err == nil {
+		e.CreatedAt = t
+	}
+	e.ResolvedAt = parseNullableTime(resolvedAt)
+	e.AppliedAt = parseNullableTime(appliedAt)
+	return &e, nil
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalation_CreateResolveApply(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"esc-1", "esc-2"} {
+		e := &Escalation{
+			ID:         id,
+			SessionID:  "sess",
+			TaskID:     "task-" + id,
+			ProgTaskID: "ts-" + id,
+			TaskTitle:  "Add login",
+			Reason:     "failed with test error after 3 attempt(s)",
+			Context:    "TestLogin: expected 200, got 500",
+			Options:    []string{"retry", "skip", "abandon"},
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := db.CreateEscalation(e); err != nil {
+			t.Fatalf("CreateEscalation failed: %v", err)
+		}
+	}
+
+	got, err := db.GetEscalation("esc-1")
+	if err != nil {
+		t.Fatalf("GetEscalation failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("GetEscalation returned nil")
+	}
+	if got.Status != EscalationOpen || got.ProgTaskID != "ts-esc-1" || len(got.Options) != 3 || got.ResolvedAt != nil {
+		t.Errorf("escalation = %+v", got)
+	}
+	if missing, err := db.GetEscalation("nope"); err != nil || missing != nil {
+		t.Errorf("GetEscalation(nope) = %v, %v, want nil", missing, err)
+	}
+
+	if err := db.MarkEscalationApplied("esc-1"); err == nil {
+		t.Error("applying an unresolved escalation should fail")
+	}
+	if err := db.ResolveEscalation("esc-1", "retry: mock the clock", "alice"); err != nil {
+		t.Fatalf("ResolveEscalation failed: %v", err)
+	}
+	if err := db.ResolveEscalation("esc-1", "skip", "bob"); err == nil {
+		t.Error("resolving an already resolved escalation should fail")
+	}
+
+	open := EscalationOpen
+	pending, err := db.ListEscalations(&open)
+	if err != nil {
+		t.Fatalf("ListEscalations failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "esc-2" {
+		t.Errorf("open escalations = %+v, want esc-2", pending)
+	}
+
+	got, _ = db.GetEscalation("esc-1")
+	if got.Status != EscalationResolved || got.Resolution != "retry: mock the clock" || got.ResolvedBy != "alice" || got.ResolvedAt == nil {
+		t.Errorf("resolved escalation = %+v", got)
+	}
+
+	if err := db.MarkEscalationApplied("esc-1"); err != nil {
+		t.Fatalf("MarkEscalationApplied failed: %v", err)
+	}
+	if err := db.MarkEscalationApplied("esc-1"); err == nil {
+		t.Error("applying an escalation twice should fail")
+	}
+
+	all, err := db.ListEscalations(nil)
+	if err != nil {
+		t.Fatalf("ListEscalations failed: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "esc-1" || all[0].Status != EscalationApplied || all[0].AppliedAt == nil {
+		t.Errorf("all escalations = %+v", all)
+	}
+}
@@ -27,6 +27,13 @@ type TaskStore interface {
 	ListTasksByParent(parentID string) ([]Task, error)
 }
 
+// TaskBatchStore saves many tasks at once.
+type TaskBatchStore interface {
+	// SaveTasks creates the tasks, or updates those that exist, in one
+	// transaction.
+	SaveTasks(tasks []Task) error
+}
+
 // Migrator handles database schema migrations.
 // Separating this allows clients to depend only on migration functionality.
 type Migrator interface {
@@ -48,9 +55,10 @@ type StateStore interface {
 
 // Compile-time verification that DB implements all interfaces.
 var (
-	_ StateStore   = (*DB)(nil)
-	_ Migrator     = (*DB)(nil)
-	_ SessionStore = (*DB)(nil)
-	_ AgentStore   = (*DB)(nil)
-	_ TaskStore    = (*DB)(nil)
+	_ StateStore     = (*DB)(nil)
+	_ Migrator       = (*DB)(nil)
+	_ SessionStore   = (*DB)(nil)
+	_ AgentStore     = (*DB)(nil)
+	_ TaskStore      = (*DB)(nil)
+	_ TaskBatchStore = (*DB)(nil)
 )
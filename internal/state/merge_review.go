@@ -0,0 +1,159 @@
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MergeReviewStatus represents the state of a quarantined merge.
+type MergeReviewStatus string
+
+const (
+	// MergeReviewPending means the merge awaits a human decision.
+	MergeReviewPending MergeReviewStatus = "pending"
+	// MergeReviewApproved means the merge was promoted to its target branch.
+	MergeReviewApproved MergeReviewStatus = "approved"
+	// MergeReviewRejected means the merge was discarded.
+	MergeReviewRejected MergeReviewStatus = "rejected"
+)
+
+// MergeReview is a low-confidence merge held on a quarantine branch until a
+// human approves or rejects it.
+type MergeReview struct {
+	ID               string            `json:"id"`
+	SessionID        string            `json:"session_id"`
+	TaskID           string            `json:"task_id"`
+	AgentBranch      string            `json:"agent_branch"`
+	TargetBranch     string            `json:"target_branch"`
+	QuarantineBranch string            `json:"quarantine_branch"`
+	Confidence       float64           `json:"confidence"`
+	Reason           string            `json:"reason"`
+	Files            []string          `json:"files"`
+	Status           MergeReviewStatus `json:"status"`
+	CreatedAt        time.Time         `json:"created_at"`
+	DecidedAt        *time.Time        `json:"decided_at,omitempty"`
+	DecidedBy        string            `json:"decided_by,omitempty"`
+}
+
+// Merge review operations
+
+// CreateMergeReview adds a merge to the review queue.
+func (db *DB) CreateMergeReview(r *MergeReview) error {
+	if r.Status == "" {
+		r.Status = MergeReviewPending
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	files, _ := json.Marshal(r.Files)
+
+	_, err := db.Exec(`
+		INSERT INTO merge_reviews (id, session_id, task_id, agent_branch, target_branch, quarantine_branch,
+			confidence, reason, files, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.ID, r.SessionID, r.TaskID, r.AgentBranch, r.TargetBranch, r.QuarantineBranch,
+		r.Confidence, r.Reason, string(files), string(r.Status), formatTime(r.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("create merge review: %w", err)
+	}
+	return nil
+}
+
+// GetMergeReview retrieves a merge review by ID.
+// Returns nil if the review does not exist.
+func (db *DB) GetMergeReview(id string) (*MergeReview, error) {
+	row := db.QueryRow(`
+		SELECT id, session_id, task_id, agent_branch, target_branch, quarantine_branch,
+			confidence, reason, files, status, created_at, decided_at, decided_by
+		FROM merge_reviews WHERE id = ?
+	`, id)
+
+	r, err := scanMergeReview(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get merge review: %w", err)
+	}
+	return r, nil
+}
+
+// ListMergeReviews lists merge reviews, oldest first, optionally filtered by status.
+func (db *DB) ListMergeReviews(status *MergeReviewStatus) ([]MergeReview, error) {
+	var rows *sql.Rows
+	var err error
+
+	if status != nil {
+		rows, err = db.Query(`
+			SELECT id, session_id, task_id, agent_branch, target_branch, quarantine_branch,
+				confidence, reason, files, status, created_at, decided_at, decided_by
+			FROM merge_reviews WHERE status = ? ORDER BY created_at
+		`, string(*status))
+	} else {
+		rows, err = db.Query(`
+			SELECT id, session_id, task_id, agent_branch, target_branch, quarantine_branch,
+				confidence, reason, files, status, created_at, decided_at, decided_by
+			FROM merge_reviews ORDER BY created_at
+		`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list merge reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []MergeReview
+	for rows.Next() {
+		r, err := scanMergeReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan merge review: %w", err)
+		}
+		reviews = append(reviews, *r)
+	}
+	return reviews, rows.Err()
+}
+
+// DecideMergeReview records a human decision on a pending merge review.
+// Returns an error if the review does not exist or was already decided.
+func (db *DB) DecideMergeReview(id string, status MergeReviewStatus, decidedBy string) error {
+	result, err := db.Exec(`
+		UPDATE merge_reviews SET status = ?, decided_at = ?, decided_by = ?
+		WHERE id = ? AND status = ?
+	`, string(status), formatTime(time.Now()), decidedBy, id, string(MergeReviewPending))
+	if err != nil {
+		return fmt.Errorf("decide merge review: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("decide merge review: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("merge review %s not found or already decided", id)
+	}
+	return nil
+}
+
+// scanMergeReview scans a merge review row from a *sql.Row or *sql.Rows.
+func scanMergeReview(s interface{ Scan(...any) error }) (*MergeReview, error) {
+	var r MergeReview
+	var sessionID, agentBranch, reason, files, decidedBy sql.NullString
+	var createdAt string
+	var decidedAt sql.NullString
+	if err := s.Scan(&r.ID, &sessionID, &r.TaskID, &agentBranch, &r.TargetBranch, &r.QuarantineBranch,
+		&r.Confidence, &reason, &files, &r.Status, &createdAt, &decidedAt, &decidedBy); err != nil {
+		return nil, err
+	}
+	r.SessionID = sessionID.String
+	r.AgentBranch = agentBranch.String
+	r.Reason = reason.String
+	r.DecidedBy = decidedBy.String
+	if files.Valid {
+		json.Unmarshal([]byte(files.String), &r.Files)
+	}
+	if t, err := parseTime(createdAt); err == nil {
+		r.CreatedAt = t
+	}
+	r.DecidedAt = parseNullableTime(decidedAt)
+	return &r, nil
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeReview_CreateListDecide(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"mr-1", "mr-2"} {
+		r := &MergeReview{
+			ID:               id,
+			SessionID:        "sess",
+			TaskID:           "task-" + id,
+			AgentBranch:      "agent-task-" + id,
+			TargetBranch:     "alphie-sess",
+			QuarantineBranch: "alphie/quarantine/task-" + id,
+			Confidence:       0.4,
+			Reason:           "overlapping edits",
+			Files:            []string{"a.go", "b.go"},
+			CreatedAt:        base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := db.CreateMergeReview(r); err != nil {
+			t.Fatalf("CreateMergeReview failed: %v", err)
+		}
+	}
+
+	got, err := db.GetMergeReview("mr-1")
+	if err != nil {
+		t.Fatalf("GetMergeReview failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("GetMergeReview returned nil")
+	}
+	if got.Status != MergeReviewPending || len(got.Files) != 2 || got.Confidence != 0.4 || got.DecidedAt != nil {
+		t.Errorf("review = %+v", got)
+	}
+
+	if err := db.DecideMergeReview("mr-1", MergeReviewApproved, "alice"); err != nil {
+		t.Fatalf("DecideMergeReview failed: %v", err)
+	}
+	if err := db.DecideMergeReview("mr-1", MergeReviewRejected, "bob"); err == nil {
+		t.Error("deciding an already decided review should fail")
+	}
+
+	pending := MergeReviewPending
+	open, err := db.ListMergeReviews(&pending)
+	if err != nil {
+		t.Fatalf("ListMergeReviews failed: %v", err)
+	}
+	if len(open) != 1 || open[0].ID != "mr-2" {
+		t.Errorf("pending reviews = %+v, want mr-2", open)
+	}
+
+	all, err := db.ListMergeReviews(nil)
+	if err != nil {
+		t.Fatalf("ListMergeReviews failed: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "mr-1" {
+		t.Fatalf("all reviews = %+v, want mr-1 first", all)
+	}
+	if all[0].Status != MergeReviewApproved || all[0].DecidedBy != "alice" || all[0].DecidedAt == nil {
+		t.Errorf("decided review = %+v", all[0])
+	}
+
+	if got, _ := db.GetMergeReview("missing"); got != nil {
+		t.Errorf("GetMergeReview(missing) = %+v, want nil", got)
+	}
+}
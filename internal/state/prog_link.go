@@ -0,0 +1,64 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// ProgLinkStore persists which prog task each internal task stands for, so
+// a session resuming an epic reuses the task IDs, and with them the history
+// and dependencies, recorded by earlier sessions.
+type ProgLinkStore interface {
+	// SaveProgLinks records the prog task of each internal task ID of an epic.
+	SaveProgLinks(epicID string, taskIDs map[string]string) error
+	// GetProgLinks returns the prog task of each internal task ID of an epic.
+	GetProgLinks(epicID string) (map[string]string, error)
+}
+
+// Compile-time verification that DB implements ProgLinkStore.
+var _ ProgLinkStore = (*DB)(nil)
+
+// SaveProgLinks records the prog task of each internal task ID of an epic.
+// A prog task linked to another internal task before is relinked.
+func (db *DB) SaveProgLinks(epicID string, taskIDs map[string]string) error {
+	now := formatTime(time.Now())
+	return db.Transaction(func(tx *sqldb.Tx) error {
+		for taskID, progID := range taskIDs {
+			if taskID == "" || progID == "" {
+				continue
+			}
+			if _, err := tx.Exec(`DELETE FROM prog_links WHERE task_id = ? OR prog_id = ?`, taskID, progID); err != nil {
+				return fmt.Errorf("save prog link %s: %w", taskID, err)
+			}
+			_, err := tx.Exec(`
+				INSERT INTO prog_links (task_id, prog_id, epic_id, created_at)
+				VALUES (?, ?, ?, ?)
+			`, taskID, progID, epicID, now)
+			if err != nil {
+				return fmt.Errorf("save prog link %s: %w", taskID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetProgLinks returns the prog task of each internal task ID of an epic.
+func (db *DB) GetProgLinks(epicID string) (map[string]string, error) {
+	rows, err := db.Query(`SELECT task_id, prog_id FROM prog_links WHERE epic_id = ?`, epicID)
+	if err != nil {
+		return nil, fmt.Errorf("get prog links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make(map[string]string)
+	for rows.Next() {
+		var taskID, progID string
+		if err := rows.Scan(&taskID, &progID); err != nil {
+			return nil, fmt.Errorf("scan prog link: %w", err)
+		}
+		links[taskID] = progID
+	}
+	return links, rows.Err()
+}
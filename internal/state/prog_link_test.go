@@ -0,0 +1,38 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProgLinks_SaveGet(t *testing.T) {
+	db := setupTestDB(t)
+
+	if links, err := db.GetProgLinks("ep-1"); err != nil || len(links) != 0 {
+		t.Fatalf("expected no links before any are saved, got %v, %v", links, err)
+	}
+
+	if err := db.SaveProgLinks("ep-1", map[string]string{"a1": "ts-1", "b2": "ts-2"}); err != nil {
+		t.Fatalf("SaveProgLinks failed: %v", err)
+	}
+	if err := db.SaveProgLinks("ep-2", map[string]string{"c3": "ts-3"}); err != nil {
+		t.Fatalf("SaveProgLinks failed: %v", err)
+	}
+
+	links, err := db.GetProgLinks("ep-1")
+	if err != nil {
+		t.Fatalf("GetProgLinks failed: %v", err)
+	}
+	if want := map[string]string{"a1": "ts-1", "b2": "ts-2"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("links = %v, want %v", links, want)
+	}
+
+	// Linking a prog task to a new internal task replaces the old link
+	if err := db.SaveProgLinks("ep-1", map[string]string{"d4": "ts-2"}); err != nil {
+		t.Fatalf("SaveProgLinks failed: %v", err)
+	}
+	links, _ = db.GetProgLinks("ep-1")
+	if want := map[string]string{"a1": "ts-1", "d4": "ts-2"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("relinked = %v, want %v", links, want)
+	}
+}
@@ -0,0 +1,165 @@
+package state
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// PromptCacheEntry is a cached model response to a deterministic prompt.
+type PromptCacheEntry struct {
+	// Key is the content address of the prompt and everything else the
+	// response depends on.
+	Key string `json:"key"`
+	// Kind is the call the response answers, e.g. "parse" or "audit".
+	Kind string `json:"kind"`
+	// Scope is what the prompt was about, e.g. the spec path or repository.
+	// A new entry replaces the older entries of its kind and scope, whose
+	// inputs have since changed.
+	Scope    string `json:"scope"`
+	Response string `json:"response"`
+	// Hits counts the lookups the entry answered.
+	Hits      int        `json:"hits"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastHitAt *time.Time `json:"last_hit_at,omitempty"`
+}
+
+// PromptCacheStats counts the lookups of one kind of call.
+type PromptCacheStats struct {
+	Kind   string `json:"kind"`
+	Hits   int64  `json:"hits"`
+	Misses int64  `json:"misses"`
+	// Entries is how many responses of the kind are cached.
+	Entries int64 `json:"entries"`
+}
+
+// HitRate returns the share of lookups answered from the cache, from 0 to 1.
+func (s PromptCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// PromptCacheStore persists cached prompt responses.
+type PromptCacheStore interface {
+	// LookupPrompt returns the cached response for key, or nil, and counts
+	// the lookup as a hit or miss of kind.
+	LookupPrompt(kind, key string) (*PromptCacheEntry, error)
+	// StorePrompt caches a response, replacing the other entries of its
+	// kind and scope.
+	StorePrompt(e *PromptCacheEntry) error
+	// InvalidatePrompts deletes the entries of kind and scope and returns
+	// how many were deleted. Empty kind or scope matches any.
+	InvalidatePrompts(kind, scope string) (int64, error)
+	// PromptCacheStats returns the lookup counts and entries of each kind.
+	PromptCacheStats() ([]PromptCacheStats, error)
+}
+
+// Compile-time verification that DB implements PromptCacheStore.
+var _ PromptCacheStore = (*DB)(nil)
+
+// LookupPrompt returns the cached response for key, or nil, and counts the
+// lookup as a hit or miss of kind.
+func (db *DB) LookupPrompt(kind, key string) (*PromptCacheEntry, error) {
+	var entry *PromptCacheEntry
+	err := db.Transaction(func(tx *sqldb.Tx) error {
+		e := PromptCacheEntry{Key: key}
+		var createdAt string
+		var lastHitAt sql.NullString
+		err := tx.QueryRow(`
+			SELECT kind, scope, response, hits, created_at, last_hit_at
+			FROM prompt_cache WHERE key = ? AND kind = ?
+		`, key, kind).Scan(&e.Kind, &e.Scope, &e.Response, &e.Hits, &createdAt, &lastHitAt)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		hit := err == nil
+		hits, misses := 0, 1
+		if hit {
+			now := time.Now()
+			e.Hits++
+			e.LastHitAt = &now
+			e.CreatedAt, _ = parseTime(createdAt)
+			entry = &e
+			hits, misses = 1, 0
+			if _, err := tx.Exec(`UPDATE prompt_cache SET hits = hits + 1, last_hit_at = ? WHERE key = ?`, formatTime(now), key); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`
+			INSERT INTO prompt_cache_stats (kind, hits, misses) VALUES (?, ?, ?)
+			ON CONFLICT(kind) DO UPDATE SET hits = prompt_cache_stats.hits + excluded.hits,
+				misses = prompt_cache_stats.misses + excluded.misses
+		`, kind, hits, misses)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lookup prompt: %w", err)
+	}
+	return entry, nil
+}
+
+// StorePrompt caches a response, replacing the other entries of its kind and
+// scope.
+func (db *DB) StorePrompt(e *PromptCacheEntry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	err := db.Transaction(func(tx *sqldb.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM prompt_cache WHERE (kind = ? AND scope = ?) OR key = ?`, e.Kind, e.Scope, e.Key); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			INSERT INTO prompt_cache (key, kind, scope, response, hits, created_at)
+			VALUES (?, ?, ?, ?, 0, ?)
+		`, e.Key, e.Kind, e.Scope, e.Response, formatTime(e.CreatedAt))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("store prompt: %w", err)
+	}
+	return nil
+}
+
+// InvalidatePrompts deletes the entries of kind and scope and returns how
+// many were deleted. Empty kind or scope matches any.
+func (db *DB) InvalidatePrompts(kind, scope string) (int64, error) {
+	result, err := db.Exec(`
+		DELETE FROM prompt_cache WHERE (? = '' OR kind = ?) AND (? = '' OR scope = ?)
+	`, kind, kind, scope, scope)
+	if err != nil {
+		return 0, fmt.Errorf("invalidate prompts: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PromptCacheStats returns the lookup counts and entries of each kind,
+// ordered by kind.
+func (db *DB) PromptCacheStats() ([]PromptCacheStats, error) {
+	rows, err := db.Query(`
+		SELECT kind, SUM(hits), SUM(misses), SUM(entries) FROM (
+			SELECT kind, hits, misses, 0 AS entries FROM prompt_cache_stats
+			UNION ALL
+			SELECT kind, 0, 0, COUNT(*) FROM prompt_cache GROUP BY kind
+		) AS counts GROUP BY kind ORDER BY kind
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("get prompt cache stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []PromptCacheStats
+	for rows.Next() {
+		var s PromptCacheStats
+		if err := rows.Scan(&s.Kind, &s.Hits, &s.Misses, &s.Entries); err != nil {
+			return nil, fmt.Errorf("scan prompt cache stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,81 @@
+package state
+
+import "testing"
+
+func TestPromptCache_LookupStore(t *testing.T) {
+	db := setupTestDB(t)
+
+	if e, err := db.LookupPrompt("parse", "k1"); err != nil || e != nil {
+		t.Fatalf("expected a miss before anything is stored, got %+v, %v", e, err)
+	}
+	if err := db.StorePrompt(&PromptCacheEntry{Key: "k1", Kind: "parse", Scope: "spec.md", Response: `{"name":"v1"}`}); err != nil {
+		t.Fatalf("StorePrompt failed: %v", err)
+	}
+	e, err := db.LookupPrompt("parse", "k1")
+	if err != nil || e == nil {
+		t.Fatalf("expected a hit, got %+v, %v", e, err)
+	}
+	if e.Response != `{"name":"v1"}` || e.Hits != 1 || e.LastHitAt == nil {
+		t.Errorf("entry = %+v", e)
+	}
+
+	// The same key of another kind is a different call
+	if e, err := db.LookupPrompt("audit", "k1"); err != nil || e != nil {
+		t.Errorf("expected an audit miss, got %+v, %v", e, err)
+	}
+
+	// A changed spec replaces the entry of its scope
+	if err := db.StorePrompt(&PromptCacheEntry{Key: "k2", Kind: "parse", Scope: "spec.md", Response: `{"name":"v2"}`}); err != nil {
+		t.Fatalf("StorePrompt failed: %v", err)
+	}
+	if err := db.StorePrompt(&PromptCacheEntry{Key: "k3", Kind: "parse", Scope: "other.md", Response: "{}"}); err != nil {
+		t.Fatalf("StorePrompt failed: %v", err)
+	}
+	if e, _ := db.LookupPrompt("parse", "k1"); e != nil {
+		t.Errorf("expected the superseded entry to be gone, got %+v", e)
+	}
+
+	stats, err := db.PromptCacheStats()
+	if err != nil {
+		t.Fatalf("PromptCacheStats failed: %v", err)
+	}
+	want := []PromptCacheStats{
+		{Kind: "audit", Misses: 1},
+		{Kind: "parse", Hits: 1, Misses: 2, Entries: 2},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+	if rate := stats[1].HitRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("parse hit rate = %f, want 1/3", rate)
+	}
+}
+
+func TestPromptCache_Invalidate(t *testing.T) {
+	db := setupTestDB(t)
+
+	for _, e := range []PromptCacheEntry{
+		{Key: "p1", Kind: "parse", Scope: "a.md"},
+		{Key: "p2", Kind: "parse", Scope: "b.md"},
+		{Key: "a1", Kind: "audit", Scope: "/repo"},
+	} {
+		if err := db.StorePrompt(&e); err != nil {
+			t.Fatalf("StorePrompt failed: %v", err)
+		}
+	}
+
+	if n, err := db.InvalidatePrompts("parse", "a.md"); err != nil || n != 1 {
+		t.Errorf("InvalidatePrompts(parse, a.md) = %d, %v, want 1", n, err)
+	}
+	if n, err := db.InvalidatePrompts("audit", ""); err != nil || n != 1 {
+		t.Errorf("InvalidatePrompts(audit) = %d, %v, want 1", n, err)
+	}
+	if n, err := db.InvalidatePrompts("", ""); err != nil || n != 1 {
+		t.Errorf("InvalidatePrompts() = %d, %v, want the last entry", n, err)
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
+
+	iexec "github.com/ShayCichocki/alphie/internal/exec"
 )
 
 // InterruptedSession contains information about an interrupted session detected on startup.
@@ -57,7 +58,7 @@ func (rm *RecoveryManager) CheckForInterrupted() (*InterruptedSession, error) {
 		for _, a := range agents {
 			if a.Status == AgentRunning {
 				// Check if process is still alive
-				if a.PID > 0 && !isProcessAlive(a.PID) {
+				if a.PID > 0 && !iexec.ProcessAlive(a.PID) {
 					// Process is dead but marked running - this is orphaned
 					runningAgents++
 				} else if a.PID > 0 {
@@ -105,7 +106,7 @@ func (rm *RecoveryManager) Resume(sessionID string) error {
 	for _, a := range agents {
 		if a.Status == AgentRunning {
 			// Check if process is still alive
-			if a.PID > 0 && !isProcessAlive(a.PID) {
+			if a.PID > 0 && !iexec.ProcessAlive(a.PID) {
 				// Process is dead - reset agent to pending for re-run
 				a.Status = AgentPending
 				a.PID = 0
@@ -150,7 +151,7 @@ func (rm *RecoveryManager) Clean(sessionID string) error {
 	for _, a := range agents {
 		if a.Status == AgentRunning {
 			// Try to kill the process if it's still alive
-			if a.PID > 0 && isProcessAlive(a.PID) {
+			if a.PID > 0 && iexec.ProcessAlive(a.PID) {
 				process, err := os.FindProcess(a.PID)
 				if err == nil {
 					if err := process.Kill(); err != nil {
@@ -226,7 +227,7 @@ func (db *DB) CheckRecovery() (*RecoveryInfo, error) {
 
 	for _, a := range agents {
 		// Check if process is still alive
-		if a.PID > 0 && !isProcessAlive(a.PID) {
+		if a.PID > 0 && !iexec.ProcessAlive(a.PID) {
 			info.OrphanedAgents = append(info.OrphanedAgents, a)
 			info.StaleProcesses = append(info.StaleProcesses, a.PID)
 		}
@@ -321,20 +322,6 @@ func (db *DB) CleanupOrphanedResources() error {
 	return db.RecoverSession(false)
 }
 
-// isProcessAlive checks if a process with the given PID is still running.
-func isProcessAlive(pid int) bool {
-	if pid <= 0 {
-		return false
-	}
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-	// Send signal 0 to check if process exists
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
-}
-
 // listAlphieWorktrees lists all git worktrees that belong to Alphie.
 func listAlphieWorktrees() ([]string, error) {
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
@@ -372,26 +372,6 @@ func TestCheckRecovery_WithOrphanedAgents(t *testing.T) {
 	}
 }
 
-func TestIsProcessAlive(t *testing.T) {
-	// Test with invalid PID
-	if isProcessAlive(0) {
-		t.Error("PID 0 should not be alive")
-	}
-	if isProcessAlive(-1) {
-		t.Error("PID -1 should not be alive")
-	}
-
-	// Test with our own PID (should be alive)
-	if !isProcessAlive(os.Getpid()) {
-		t.Error("our own PID should be alive")
-	}
-
-	// Test with non-existent PID
-	if isProcessAlive(999999) {
-		t.Error("non-existent PID should not be alive")
-	}
-}
-
 func TestWorktreeBasePath(t *testing.T) {
 	// Save and restore env
 	original := os.Getenv("XDG_CACHE_HOME")
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// ReviewFinding is one concern a second reviewer raised about a task's diff.
+type ReviewFinding struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	TaskID    string    `json:"task_id"`
+	Reviewer  string    `json:"reviewer"`
+	Severity  string    `json:"severity,omitempty"`
+	File      string    `json:"file,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewFindingStore persists second review findings.
+type ReviewFindingStore interface {
+	// RecordReviewFindings saves findings, setting their IDs.
+	RecordReviewFindings(findings []ReviewFinding) error
+	// ListReviewFindings lists a task's findings, oldest first.
+	ListReviewFindings(taskID string) ([]ReviewFinding, error)
+}
+
+// Compile-time verification that DB implements ReviewFindingStore.
+var _ ReviewFindingStore = (*DB)(nil)
+
+// RecordReviewFindings saves findings in one transaction, setting their IDs.
+func (db *DB) RecordReviewFindings(findings []ReviewFinding) error {
+	return db.Transaction(func(tx *sqldb.Tx) error {
+		for i := range findings {
+			f := &findings[i]
+			if f.CreatedAt.IsZero() {
+				f.CreatedAt = time.Now()
+			}
+			err := tx.QueryRow(`
+				INSERT INTO review_findings (session_id, task_id, reviewer, severity, file, line, message, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				RETURNING id
+			`, f.SessionID, f.TaskID, f.Reviewer, f.Severity, f.File, f.Line, f.Message, formatTime(f.CreatedAt)).Scan(&f.ID)
+			if err != nil {
+				return fmt.Errorf("record review finding: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListReviewFindings lists a task's review findings, oldest first.
+func (db *DB) ListReviewFindings(taskID string) ([]ReviewFinding, error) {
+	rows, err := db.Query(`
+		SELECT id, session_id, task_id, reviewer, severity, file, line, message, created_at
+		FROM review_findings WHERE task_id = ? ORDER BY created_at, id
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list review findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []ReviewFinding
+	for rows.Next() {
+		var f ReviewFinding
+		var sessionID, severity, file sql.NullString
+		var createdAt string
+		if err := rows.Scan(&f.ID, &sessionID, &f.TaskID, &f.Reviewer, &severity, &file, &f.Line, &f.Message, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan review finding: %w", err)
+		}
+		f.SessionID = sessionID.String
+		f.Severity = severity.String
+		f.File = file.String
+		if f.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, fmt.Errorf("parse review finding time: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
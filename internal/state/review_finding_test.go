@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewFindings_RecordList(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	findings := []ReviewFinding{
+		{SessionID: "sess", TaskID: "task-1", Reviewer: "security", Severity: "high", File: "auth/login.go", Line: 42, Message: "token compared with ==", CreatedAt: base},
+		{SessionID: "sess", TaskID: "task-1", Reviewer: "general", Message: "missing error handling", CreatedAt: base.Add(time.Minute)},
+		{SessionID: "sess", TaskID: "task-2", Reviewer: "general", Message: "unrelated"},
+	}
+	if err := db.RecordReviewFindings(findings); err != nil {
+		t.Fatalf("RecordReviewFindings failed: %v", err)
+	}
+	for i, f := range findings {
+		if f.ID == 0 {
+			t.Errorf("expected finding %d to get an ID", i)
+		}
+	}
+
+	got, err := db.ListReviewFindings("task-1")
+	if err != nil {
+		t.Fatalf("ListReviewFindings failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(got))
+	}
+	if f := got[0]; f.Reviewer != "security" || f.Severity != "high" || f.File != "auth/login.go" || f.Line != 42 {
+		t.Errorf("first finding = %+v", f)
+	}
+	if f := got[1]; f.Reviewer != "general" || f.File != "" || f.Line != 0 {
+		t.Errorf("second finding = %+v", f)
+	}
+
+	if none, err := db.ListReviewFindings("task-3"); err != nil || len(none) != 0 {
+		t.Errorf("expected no findings for an unreviewed task, got %v, %v", none, err)
+	}
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// SecurityFinding is a secret or dependency vulnerability the security
+// validation layer found in a task's changes.
+type SecurityFinding struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	TaskID    string    `json:"task_id"`
+	Kind      string    `json:"kind"`
+	Rule      string    `json:"rule,omitempty"`
+	Severity  string    `json:"severity,omitempty"`
+	File      string    `json:"file,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Message   string    `json:"message"`
+	Blocking  bool      `json:"blocking"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SecurityFindingStore persists security layer findings.
+type SecurityFindingStore interface {
+	// RecordSecurityFindings saves findings, setting their IDs.
+	RecordSecurityFindings(findings []SecurityFinding) error
+	// ListSecurityFindings lists a task's findings, oldest first.
+	ListSecurityFindings(taskID string) ([]SecurityFinding, error)
+}
+
+// Compile-time verification that DB implements SecurityFindingStore.
+var _ SecurityFindingStore = (*DB)(nil)
+
+// RecordSecurityFindings saves findings in one transaction, setting their IDs.
+func (db *DB) RecordSecurityFindings(findings []SecurityFinding) error {
+	return db.Transaction(func(tx *sqldb.Tx) error {
+		for i := range findings {
+			f := &findings[i]
+			if f.CreatedAt.IsZero() {
+				f.CreatedAt = time.Now()
+			}
+			err := tx.QueryRow(`
+				INSERT INTO security_findings (session_id, task_id, kind, rule, severity, file, line, message, blocking, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				RETURNING id
+			`, f.SessionID, f.TaskID, f.Kind, f.Rule, f.Severity, f.File, f.Line, f.Message, f.Blocking, formatTime(f.CreatedAt)).Scan(&f.ID)
+			if err != nil {
+				return fmt.Errorf("record security finding: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListSecurityFindings lists a task's security findings, oldest first.
+func (db *DB) ListSecurityFindings(taskID string) ([]SecurityFinding, error) {
+	rows, err := db.Query(`
+		SELECT id, session_id, task_id, kind, rule, severity, file, line, message, blocking, created_at
+		FROM security_findings WHERE task_id = ? ORDER BY created_at, id
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list security findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []SecurityFinding
+	for rows.Next() {
+		var f SecurityFinding
+		var sessionID, rule, severity, file sql.NullString
+		var createdAt string
+		if err := rows.Scan(&f.ID, &sessionID, &f.TaskID, &f.Kind, &rule, &severity, &file, &f.Line, &f.Message, &f.Blocking, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan security finding: %w", err)
+		}
+		f.SessionID = sessionID.String
+		f.Rule = rule.String
+		f.Severity = severity.String
+		f.File = file.String
+		if f.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, fmt.Errorf("parse security finding time: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecurityFindings_RecordList(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	findings := []SecurityFinding{
+		{SessionID: "sess", TaskID: "task-1", Kind: "secret", Rule: "aws-access-key", Severity: "critical", File: "config/dev.env", Line: 3, Message: "possible secret added (aws-access-key)", Blocking: true, CreatedAt: base},
+		{SessionID: "sess", TaskID: "task-1", Kind: "vulnerability", Rule: "GO-2024-0001", Severity: "critical", File: "go.mod", Message: "golang.org/x/net v0.1.0 is affected", CreatedAt: base.Add(time.Minute)},
+		{SessionID: "sess", TaskID: "task-2", Kind: "secret", Message: "unrelated"},
+	}
+	if err := db.RecordSecurityFindings(findings); err != nil {
+		t.Fatalf("RecordSecurityFindings failed: %v", err)
+	}
+	for i, f := range findings {
+		if f.ID == 0 {
+			t.Errorf("expected finding %d to get an ID", i)
+		}
+	}
+
+	got, err := db.ListSecurityFindings("task-1")
+	if err != nil {
+		t.Fatalf("ListSecurityFindings failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(got))
+	}
+	if f := got[0]; f.Kind != "secret" || f.Rule != "aws-access-key" || f.File != "config/dev.env" || f.Line != 3 || !f.Blocking {
+		t.Errorf("first finding = %+v", f)
+	}
+	if f := got[1]; f.Kind != "vulnerability" || f.Line != 0 || f.Blocking {
+		t.Errorf("second finding = %+v", f)
+	}
+
+	if none, err := db.ListSecurityFindings("task-3"); err != nil || len(none) != 0 {
+		t.Errorf("expected no findings for an unscanned task, got %v, %v", none, err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
 )
 
 // SessionStatus represents the status of a session.
@@ -15,6 +17,9 @@ const (
 	SessionCompleted SessionStatus = "completed"
 	SessionFailed    SessionStatus = "failed"
 	SessionCanceled  SessionStatus = "canceled"
+	// SessionInterrupted marks a session checkpointed by a graceful
+	// shutdown; its unfinished tasks re-run when it is resumed.
+	SessionInterrupted SessionStatus = "interrupted"
 )
 
 // AgentStatus represents the status of an agent.
@@ -408,6 +413,32 @@ func (db *DB) UpdateTask(t *Task) error {
 	return nil
 }
 
+// SaveTasks creates the tasks in one transaction. Tasks that already exist
+// are updated instead, keeping their created and completed times.
+func (db *DB) SaveTasks(tasks []Task) error {
+	return db.Transaction(func(tx *sqldb.Tx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO tasks (id, parent_id, title, description, status, depends_on, assigned_to, tier, created_at, completed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)
+			ON CONFLICT(id) DO UPDATE SET parent_id = excluded.parent_id, title = excluded.title,
+				description = excluded.description, status = excluded.status, depends_on = excluded.depends_on,
+				assigned_to = excluded.assigned_to, tier = excluded.tier
+		`)
+		if err != nil {
+			return fmt.Errorf("save tasks: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, t := range tasks {
+			dependsOn, _ := json.Marshal(t.DependsOn)
+			if _, err := stmt.Exec(t.ID, t.ParentID, t.Title, t.Description, string(t.Status), string(dependsOn), t.AssignedTo, t.Tier, formatTime(t.CreatedAt)); err != nil {
+				return fmt.Errorf("save task %s: %w", t.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
 // DeleteTask deletes a task by ID.
 func (db *DB) DeleteTask(id string) error {
 	_, err := db.Exec("DELETE FROM tasks WHERE id = ?", id)
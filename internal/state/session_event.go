@@ -0,0 +1,93 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ShayCichocki/alphie/internal/sqldb"
+)
+
+// SessionEvent is one orchestrator event of a session, kept so the session
+// can be replayed after its event channel is gone.
+type SessionEvent struct {
+	ID        int64  `json:"id"`
+	SessionID string `json:"session_id"`
+	Type      string `json:"type"`
+	TaskID    string `json:"task_id,omitempty"`
+	AgentID   string `json:"agent_id,omitempty"`
+	// Payload is the JSON-encoded event.
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionEventStore persists the events of sessions. Events are only ever
+// appended.
+type SessionEventStore interface {
+	// AppendSessionEvents appends events in one transaction.
+	AppendSessionEvents(events []SessionEvent) error
+	// ListSessionEvents lists a session's events in the order they were
+	// appended.
+	ListSessionEvents(sessionID string) ([]SessionEvent, error)
+}
+
+// Compile-time verification that DB implements SessionEventStore.
+var _ SessionEventStore = (*DB)(nil)
+
+// AppendSessionEvents appends events in one transaction.
+func (db *DB) AppendSessionEvents(events []SessionEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *sqldb.Tx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO session_events (session_id, type, task_id, agent_id, payload, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("append session events: %w", err)
+		}
+		defer stmt.Close()
+
+		for i := range events {
+			e := &events[i]
+			if e.CreatedAt.IsZero() {
+				e.CreatedAt = time.Now()
+			}
+			if _, err := stmt.Exec(e.SessionID, e.Type, e.TaskID, e.AgentID, e.Payload, formatTime(e.CreatedAt)); err != nil {
+				return fmt.Errorf("append session event %s: %w", e.Type, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListSessionEvents lists a session's events in the order they were
+// appended.
+func (db *DB) ListSessionEvents(sessionID string) ([]SessionEvent, error) {
+	rows, err := db.Query(`
+		SELECT id, session_id, type, task_id, agent_id, payload, created_at
+		FROM session_events WHERE session_id = ? ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list session events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []SessionEvent
+	for rows.Next() {
+		var e SessionEvent
+		var taskID, agentID sql.NullString
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Type, &taskID, &agentID, &e.Payload, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan session event: %w", err)
+		}
+		e.TaskID = taskID.String
+		e.AgentID = agentID.String
+		if e.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, fmt.Errorf("parse session event time: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
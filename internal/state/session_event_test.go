@@ -0,0 +1,45 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionEvents_AppendList(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	if err := db.AppendSessionEvents([]SessionEvent{
+		{SessionID: "sess-1", Type: "task_started", TaskID: "task-1", AgentID: "agent-1", Payload: `{"Type":"task_started"}`, CreatedAt: base},
+		{SessionID: "sess-2", Type: "task_started", Payload: `{}`},
+	}); err != nil {
+		t.Fatalf("AppendSessionEvents failed: %v", err)
+	}
+	// Later batches come after earlier ones, whatever their timestamps
+	if err := db.AppendSessionEvents([]SessionEvent{
+		{SessionID: "sess-1", Type: "session_done", Payload: `{"Type":"session_done"}`, CreatedAt: base.Add(-time.Minute)},
+	}); err != nil {
+		t.Fatalf("AppendSessionEvents failed: %v", err)
+	}
+	if err := db.AppendSessionEvents(nil); err != nil {
+		t.Errorf("AppendSessionEvents(nil) = %v", err)
+	}
+
+	events, err := db.ListSessionEvents("sess-1")
+	if err != nil {
+		t.Fatalf("ListSessionEvents failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if e := events[0]; e.Type != "task_started" || e.TaskID != "task-1" || e.AgentID != "agent-1" || e.Payload != `{"Type":"task_started"}` || e.CreatedAt.Unix() != base.Unix() {
+		t.Errorf("first event = %+v", e)
+	}
+	if e := events[1]; e.Type != "session_done" || e.TaskID != "" || e.ID <= events[0].ID {
+		t.Errorf("second event = %+v", e)
+	}
+
+	if none, err := db.ListSessionEvents("sess-3"); err != nil || len(none) != 0 {
+		t.Errorf("expected no events for an unknown session, got %v, %v", none, err)
+	}
+}
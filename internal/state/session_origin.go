@@ -0,0 +1,148 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SessionOrigin records where a session started and what it created, so the
+// session can be rolled back: the branch and commit checked out before it
+// ran, its session branch and epic, and the tasks it ran.
+type SessionOrigin struct {
+	SessionID string `json:"session_id"`
+	// EpicID is the prog epic the session executed, if any.
+	EpicID string `json:"epic_id,omitempty"`
+	// SessionBranch is the branch agent work merged into; empty in
+	// greenfield mode, where it merged into BaseBranch directly.
+	SessionBranch string `json:"session_branch,omitempty"`
+	// BaseBranch is the branch the session started from and merges into;
+	// BaseCommit is where it was when the session started.
+	BaseBranch string `json:"base_branch"`
+	BaseCommit string `json:"base_commit"`
+	// TaskIDs are the tasks the session ran; their agents work on
+	// agent-<task> branches.
+	TaskIDs   []string  `json:"task_ids,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionOriginStore persists session origins.
+type SessionOriginStore interface {
+	// SaveSessionOrigin saves where a session started, replacing any earlier
+	// record but keeping the tasks added for it.
+	SaveSessionOrigin(o *SessionOrigin) error
+	// AddSessionTask records a task run by a session.
+	AddSessionTask(sessionID, taskID string) error
+	// GetSessionOrigin retrieves a session's origin, or nil if none was saved.
+	GetSessionOrigin(sessionID string) (*SessionOrigin, error)
+	// ListSessionOriginsByEpic returns the origins of the sessions that
+	// executed an epic, oldest first.
+	ListSessionOriginsByEpic(epicID string) ([]SessionOrigin, error)
+}
+
+// Compile-time verification that DB implements SessionOriginStore.
+var _ SessionOriginStore = (*DB)(nil)
+
+// SaveSessionOrigin saves where a session started, replacing any earlier
+// record but keeping the tasks added for it.
+func (db *DB) SaveSessionOrigin(o *SessionOrigin) error {
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO session_origins (session_id, epic_id, session_branch, base_branch, base_commit, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET epic_id = excluded.epic_id,
+			session_branch = excluded.session_branch, base_branch = excluded.base_branch,
+			base_commit = excluded.base_commit
+	`, o.SessionID, o.EpicID, o.SessionBranch, o.BaseBranch, o.BaseCommit, formatTime(o.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("save session origin: %w", err)
+	}
+	return nil
+}
+
+// AddSessionTask records a task run by a session.
+func (db *DB) AddSessionTask(sessionID, taskID string) error {
+	_, err := db.Exec(`
+		INSERT INTO session_tasks (session_id, task_id) VALUES (?, ?)
+		ON CONFLICT DO NOTHING
+	`, sessionID, taskID)
+	if err != nil {
+		return fmt.Errorf("add session task: %w", err)
+	}
+	return nil
+}
+
+// GetSessionOrigin retrieves a session's origin.
+// Returns nil if the session has no origin recorded.
+func (db *DB) GetSessionOrigin(sessionID string) (*SessionOrigin, error) {
+	origins, err := db.querySessionOrigins(`WHERE session_id = ?`, sessionID)
+	if err != nil || len(origins) == 0 {
+		return nil, err
+	}
+	return &origins[0], nil
+}
+
+// ListSessionOriginsByEpic returns the origins of the sessions that executed
+// an epic, oldest first.
+func (db *DB) ListSessionOriginsByEpic(epicID string) ([]SessionOrigin, error) {
+	return db.querySessionOrigins(`WHERE epic_id = ? ORDER BY created_at ASC, session_id ASC`, epicID)
+}
+
+// querySessionOrigins returns the session origins matching clause, with
+// their tasks.
+func (db *DB) querySessionOrigins(clause string, args ...any) ([]SessionOrigin, error) {
+	rows, err := db.Query(`
+		SELECT session_id, epic_id, session_branch, base_branch, base_commit, created_at
+		FROM session_origins `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get session origins: %w", err)
+	}
+	var origins []SessionOrigin
+	for rows.Next() {
+		var o SessionOrigin
+		var epicID, sessionBranch sql.NullString
+		var createdAt string
+		if err := rows.Scan(&o.SessionID, &epicID, &sessionBranch, &o.BaseBranch, &o.BaseCommit, &createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan session origin: %w", err)
+		}
+		o.EpicID = epicID.String
+		o.SessionBranch = sessionBranch.String
+		o.CreatedAt, _ = parseTime(createdAt)
+		origins = append(origins, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range origins {
+		taskIDs, err := db.sessionTasks(origins[i].SessionID)
+		if err != nil {
+			return nil, err
+		}
+		origins[i].TaskIDs = taskIDs
+	}
+	return origins, nil
+}
+
+// sessionTasks returns the IDs of the tasks a session ran.
+func (db *DB) sessionTasks(sessionID string) ([]string, error) {
+	rows, err := db.Query(`SELECT task_id FROM session_tasks WHERE session_id = ? ORDER BY seq`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get session tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var taskIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan session task: %w", err)
+		}
+		taskIDs = append(taskIDs, id)
+	}
+	return taskIDs, rows.Err()
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSessionOrigin_SaveGet(t *testing.T) {
+	db := setupTestDB(t)
+
+	if o, err := db.GetSessionOrigin("sess-1"); err != nil || o != nil {
+		t.Fatalf("expected no origin before one is saved, got %+v, %v", o, err)
+	}
+
+	origin := &SessionOrigin{
+		SessionID:     "sess-1",
+		SessionBranch: "session-sess-1",
+		BaseBranch:    "main",
+		BaseCommit:    "abc123",
+		CreatedAt:     time.Now().Add(-time.Hour),
+	}
+	if err := db.SaveSessionOrigin(origin); err != nil {
+		t.Fatalf("SaveSessionOrigin failed: %v", err)
+	}
+	for _, taskID := range []string{"task-b", "task-a", "task-b"} {
+		if err := db.AddSessionTask("sess-1", taskID); err != nil {
+			t.Fatalf("AddSessionTask failed: %v", err)
+		}
+	}
+
+	// The epic is only known once tasks are resolved
+	origin.EpicID = "ep-1"
+	if err := db.SaveSessionOrigin(origin); err != nil {
+		t.Fatalf("SaveSessionOrigin failed: %v", err)
+	}
+
+	got, err := db.GetSessionOrigin("sess-1")
+	if err != nil {
+		t.Fatalf("GetSessionOrigin failed: %v", err)
+	}
+	if got.EpicID != "ep-1" || got.SessionBranch != "session-sess-1" || got.BaseBranch != "main" || got.BaseCommit != "abc123" {
+		t.Errorf("origin = %+v", got)
+	}
+	if want := []string{"task-b", "task-a"}; !reflect.DeepEqual(got.TaskIDs, want) {
+		t.Errorf("tasks = %v, want %v", got.TaskIDs, want)
+	}
+
+	// A resumed session of the same epic
+	if err := db.SaveSessionOrigin(&SessionOrigin{SessionID: "sess-2", EpicID: "ep-1", BaseBranch: "main", BaseCommit: "def456"}); err != nil {
+		t.Fatalf("SaveSessionOrigin failed: %v", err)
+	}
+	origins, err := db.ListSessionOriginsByEpic("ep-1")
+	if err != nil {
+		t.Fatalf("ListSessionOriginsByEpic failed: %v", err)
+	}
+	if len(origins) != 2 || origins[0].SessionID != "sess-1" || origins[1].SessionID != "sess-2" {
+		t.Errorf("origins of epic = %+v, want sess-1 then sess-2", origins)
+	}
+}
+
+func TestMigrate_SessionTaskOrder(t *testing.T) {
+	db := setupTestDB(t)
+
+	// Recreate the table as it was before the sequence column
+	for _, stmt := range []string{
+		`DROP TABLE session_tasks`,
+		`CREATE TABLE session_tasks (session_id TEXT NOT NULL, task_id TEXT NOT NULL, PRIMARY KEY (session_id, task_id))`,
+		`INSERT INTO session_tasks (session_id, task_id) VALUES ('sess-1', 'task-c'), ('sess-1', 'task-a'), ('sess-1', 'task-b')`,
+		`DELETE FROM schema_version WHERE version = 16`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	if err := db.AddSessionTask("sess-1", "task-0"); err != nil {
+		t.Fatalf("AddSessionTask failed: %v", err)
+	}
+	got, err := db.sessionTasks("sess-1")
+	if err != nil {
+		t.Fatalf("sessionTasks failed: %v", err)
+	}
+	if want := []string{"task-c", "task-a", "task-b", "task-0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tasks = %v, want %v", got, want)
+	}
+}
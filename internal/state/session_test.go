@@ -666,6 +666,44 @@ func TestUpdateTask(t *testing.T) {
 	}
 }
 
+func TestSaveTasks(t *testing.T) {
+	db := setupTestDB(t)
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	completed := created.Add(time.Minute)
+	existing := &Task{ID: "task-1", Title: "Old title", Status: TaskDone, CreatedAt: created}
+	if err := db.CreateTask(existing); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	existing.CompletedAt = &completed
+	if err := db.UpdateTask(existing); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	tasks := []Task{
+		{ID: "task-1", Title: "New title", Status: TaskPending, CreatedAt: time.Now()},
+		{ID: "task-2", Title: "Second", Status: TaskPending, DependsOn: []string{"task-1"}, CreatedAt: time.Now()},
+	}
+	if err := db.SaveTasks(tasks); err != nil {
+		t.Fatalf("SaveTasks failed: %v", err)
+	}
+
+	got, err := db.GetTask("task-1")
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if got.Title != "New title" || got.Status != TaskPending {
+		t.Errorf("updated task = %+v, want the new title and status", got)
+	}
+	if !got.CreatedAt.Equal(created) || got.CompletedAt == nil || !got.CompletedAt.Equal(completed) {
+		t.Errorf("updated task times = %v, %v, want the existing ones kept", got.CreatedAt, got.CompletedAt)
+	}
+	second, err := db.GetTask("task-2")
+	if err != nil || second == nil || len(second.DependsOn) != 1 {
+		t.Errorf("created task = %+v, %v", second, err)
+	}
+}
+
 func TestDeleteTask(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -0,0 +1,52 @@
+package state
+
+import "fmt"
+
+// TaskCost is the recorded usage of a completed task, summed over the agents
+// that worked on it. It calibrates cost estimates for new tasks.
+type TaskCost struct {
+	TaskID string `json:"task_id"`
+	Tier   string `json:"tier"`
+	// DescriptionLength is the length of the task description in bytes.
+	DescriptionLength int     `json:"description_length"`
+	TokensUsed        int     `json:"tokens_used"`
+	Cost              float64 `json:"cost"`
+}
+
+// CostHistory provides the usage of completed tasks.
+type CostHistory interface {
+	ListTaskCosts(limit int) ([]TaskCost, error)
+}
+
+// Compile-time verification that DB implements CostHistory.
+var _ CostHistory = (*DB)(nil)
+
+// ListTaskCosts returns the usage of up to limit completed tasks that
+// recorded a cost, most recently completed first.
+func (db *DB) ListTaskCosts(limit int) ([]TaskCost, error) {
+	rows, err := db.Query(`
+		SELECT t.id, COALESCE(t.tier, ''), LENGTH(COALESCE(t.description, '')),
+			SUM(a.tokens_used), SUM(a.cost)
+		FROM tasks t
+		JOIN agents a ON a.task_id = t.id
+		WHERE t.status = ?
+		GROUP BY t.id
+		HAVING SUM(a.cost) > 0
+		ORDER BY t.completed_at DESC
+		LIMIT ?
+	`, string(TaskDone), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list task costs: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []TaskCost
+	for rows.Next() {
+		var c TaskCost
+		if err := rows.Scan(&c.TaskID, &c.Tier, &c.DescriptionLength, &c.TokensUsed, &c.Cost); err != nil {
+			return nil, fmt.Errorf("scan task cost: %w", err)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
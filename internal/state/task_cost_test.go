@@ -0,0 +1,78 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListTaskCosts(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	tasks := []struct {
+		id     string
+		status TaskStatus
+		costs  []float64
+	}{
+		{"old", TaskDone, []float64{0.10, 0.20}},
+		{"new", TaskDone, []float64{0.50}},
+		{"running", TaskInProgress, []float64{0.40}},
+		{"free", TaskDone, []float64{0}},
+	}
+	for i, tc := range tasks {
+		completed := base.Add(time.Duration(i) * time.Minute)
+		task := &Task{
+			ID:          tc.id,
+			Title:       tc.id,
+			Description: "add the thing",
+			Status:      tc.status,
+			Tier:        "builder",
+			CreatedAt:   base,
+			CompletedAt: &completed,
+		}
+		if err := db.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		if err := db.UpdateTask(task); err != nil {
+			t.Fatalf("UpdateTask failed: %v", err)
+		}
+		for j, cost := range tc.costs {
+			agent := &Agent{
+				ID:         tc.id + "-agent-" + string(rune('a'+j)),
+				TaskID:     tc.id,
+				Status:     AgentDone,
+				TokensUsed: 1000,
+				Cost:       cost,
+			}
+			if err := db.CreateAgent(agent); err != nil {
+				t.Fatalf("CreateAgent failed: %v", err)
+			}
+		}
+	}
+
+	costs, err := db.ListTaskCosts(10)
+	if err != nil {
+		t.Fatalf("ListTaskCosts failed: %v", err)
+	}
+	if len(costs) != 2 {
+		t.Fatalf("expected 2 completed tasks with a cost, got %+v", costs)
+	}
+	if costs[0].TaskID != "new" || costs[1].TaskID != "old" {
+		t.Errorf("expected newest first, got %s, %s", costs[0].TaskID, costs[1].TaskID)
+	}
+	old := costs[1]
+	if old.TokensUsed != 2000 || old.Cost < 0.299 || old.Cost > 0.301 {
+		t.Errorf("expected usage summed over agents, got %+v", old)
+	}
+	if old.Tier != "builder" || old.DescriptionLength != len("add the thing") {
+		t.Errorf("task fields = %+v", old)
+	}
+
+	limited, err := db.ListTaskCosts(1)
+	if err != nil {
+		t.Fatalf("ListTaskCosts failed: %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d", len(limited))
+	}
+}
@@ -0,0 +1,124 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WorktreeStatus represents the ownership state of an agent worktree.
+type WorktreeStatus string
+
+const (
+	// WorktreeInUse means a process has checked the worktree out for a task.
+	WorktreeInUse WorktreeStatus = "in_use"
+	// WorktreePooled means the worktree is idle and can be reused.
+	WorktreePooled WorktreeStatus = "pooled"
+)
+
+// WorktreeRecord records which process owns an agent worktree.
+type WorktreeRecord struct {
+	Path       string         `json:"path"`
+	RepoPath   string         `json:"repo_path"`
+	BranchName string         `json:"branch"`
+	TaskID     string         `json:"task_id"`
+	Status     WorktreeStatus `json:"status"`
+	PID        int            `json:"pid"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// Worktree ownership operations
+
+// SaveWorktree creates or replaces the ownership record of a worktree.
+func (db *DB) SaveWorktree(w *WorktreeRecord) error {
+	if w.UpdatedAt.IsZero() {
+		w.UpdatedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO worktrees (path, repo_path, branch, task_id, status, pid, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET repo_path = excluded.repo_path, branch = excluded.branch,
+			task_id = excluded.task_id, status = excluded.status, pid = excluded.pid, updated_at = excluded.updated_at
+	`, w.Path, w.RepoPath, w.BranchName, w.TaskID, string(w.Status), w.PID, formatTime(w.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("save worktree: %w", err)
+	}
+	return nil
+}
+
+// GetWorktree retrieves the ownership record of the worktree at path.
+// Returns nil if the worktree is not tracked.
+func (db *DB) GetWorktree(path string) (*WorktreeRecord, error) {
+	row := db.QueryRow(`
+		SELECT path, repo_path, branch, task_id, status, pid, updated_at
+		FROM worktrees WHERE path = ?
+	`, path)
+
+	w, err := scanWorktree(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+	return w, nil
+}
+
+// ListWorktrees lists the worktrees of a repository, optionally filtered by status.
+func (db *DB) ListWorktrees(repoPath string, status *WorktreeStatus) ([]WorktreeRecord, error) {
+	var rows *sql.Rows
+	var err error
+
+	if status != nil {
+		rows, err = db.Query(`
+			SELECT path, repo_path, branch, task_id, status, pid, updated_at
+			FROM worktrees WHERE repo_path = ? AND status = ? ORDER BY updated_at
+		`, repoPath, string(*status))
+	} else {
+		rows, err = db.Query(`
+			SELECT path, repo_path, branch, task_id, status, pid, updated_at
+			FROM worktrees WHERE repo_path = ? ORDER BY updated_at
+		`, repoPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list worktrees: %w", err)
+	}
+	defer rows.Close()
+
+	var worktrees []WorktreeRecord
+	for rows.Next() {
+		w, err := scanWorktree(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan worktree: %w", err)
+		}
+		worktrees = append(worktrees, *w)
+	}
+	return worktrees, rows.Err()
+}
+
+// DeleteWorktree deletes the ownership record of the worktree at path.
+func (db *DB) DeleteWorktree(path string) error {
+	_, err := db.Exec("DELETE FROM worktrees WHERE path = ?", path)
+	if err != nil {
+		return fmt.Errorf("delete worktree: %w", err)
+	}
+	return nil
+}
+
+// scanWorktree scans a worktree row from a *sql.Row or *sql.Rows.
+func scanWorktree(s interface{ Scan(...any) error }) (*WorktreeRecord, error) {
+	var w WorktreeRecord
+	var branch, taskID sql.NullString
+	var pid sql.NullInt64
+	var updatedAt string
+	if err := s.Scan(&w.Path, &w.RepoPath, &branch, &taskID, &w.Status, &pid, &updatedAt); err != nil {
+		return nil, err
+	}
+	w.BranchName = branch.String
+	w.TaskID = taskID.String
+	w.PID = int(pid.Int64)
+	if t, err := parseTime(updatedAt); err == nil {
+		w.UpdatedAt = t
+	}
+	return &w, nil
+}
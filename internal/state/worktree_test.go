@@ -0,0 +1,77 @@
+package state
+
+import (
+	"testing"
+)
+
+func TestSaveWorktree_Upsert(t *testing.T) {
+	db := setupTestDB(t)
+
+	rec := &WorktreeRecord{
+		Path:       "/tmp/wt/pool-1",
+		RepoPath:   "/repo",
+		BranchName: "agent-task-1",
+		TaskID:     "task-1",
+		Status:     WorktreeInUse,
+		PID:        1234,
+	}
+	if err := db.SaveWorktree(rec); err != nil {
+		t.Fatalf("SaveWorktree failed: %v", err)
+	}
+
+	rec.Status = WorktreePooled
+	rec.BranchName = ""
+	rec.TaskID = ""
+	if err := db.SaveWorktree(rec); err != nil {
+		t.Fatalf("SaveWorktree (update) failed: %v", err)
+	}
+
+	got, err := db.GetWorktree("/tmp/wt/pool-1")
+	if err != nil {
+		t.Fatalf("GetWorktree failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("GetWorktree returned nil")
+	}
+	if got.Status != WorktreePooled || got.BranchName != "" || got.PID != 1234 || got.RepoPath != "/repo" {
+		t.Errorf("worktree = %+v", got)
+	}
+}
+
+func TestListWorktrees_FiltersByRepoAndStatus(t *testing.T) {
+	db := setupTestDB(t)
+
+	for _, rec := range []*WorktreeRecord{
+		{Path: "/wt/a", RepoPath: "/repo", Status: WorktreeInUse},
+		{Path: "/wt/b", RepoPath: "/repo", Status: WorktreePooled},
+		{Path: "/wt/c", RepoPath: "/other", Status: WorktreePooled},
+	} {
+		if err := db.SaveWorktree(rec); err != nil {
+			t.Fatalf("SaveWorktree failed: %v", err)
+		}
+	}
+
+	all, err := db.ListWorktrees("/repo", nil)
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("got %d worktrees for /repo, want 2", len(all))
+	}
+
+	pooled := WorktreePooled
+	idle, err := db.ListWorktrees("/repo", &pooled)
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	if len(idle) != 1 || idle[0].Path != "/wt/b" {
+		t.Errorf("pooled worktrees = %+v, want /wt/b", idle)
+	}
+
+	if err := db.DeleteWorktree("/wt/b"); err != nil {
+		t.Fatalf("DeleteWorktree failed: %v", err)
+	}
+	if got, _ := db.GetWorktree("/wt/b"); got != nil {
+		t.Errorf("worktree still tracked after delete: %+v", got)
+	}
+}
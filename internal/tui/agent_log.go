@@ -0,0 +1,301 @@
+// Package tui provides the terminal user interface for Alphie.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// agentLogRefreshInterval is how often an open agent log is re-read.
+const agentLogRefreshInterval = 500 * time.Millisecond
+
+// agentLogMaxLines caps the lines kept in memory for one agent log.
+const agentLogMaxLines = 5000
+
+// AgentLogTickMsg triggers a re-read of the open agent log.
+type AgentLogTickMsg struct{}
+
+// agentLogTick schedules the next AgentLogTickMsg.
+func agentLogTick() tea.Cmd {
+	return tea.Tick(agentLogRefreshInterval, func(time.Time) tea.Msg {
+		return AgentLogTickMsg{}
+	})
+}
+
+// AgentLogPane is a scrollable live tail of one agent's execution log.
+// In follow mode it stays at the end of the log as new output arrives.
+type AgentLogPane struct {
+	agentID   string
+	taskTitle string
+	path      string
+
+	// lines holds the complete lines read so far; partial holds a trailing
+	// line that has not been terminated yet.
+	lines   []string
+	partial string
+	// readOffset is how many bytes of the file have been read.
+	readOffset int64
+	err        error
+
+	scrollOffset int
+	follow       bool
+	width        int
+	height       int
+
+	titleStyle lipgloss.Style
+	hintStyle  lipgloss.Style
+	lineStyle  lipgloss.Style
+	errorStyle lipgloss.Style
+}
+
+// NewAgentLogPane creates a pane following the log of agentID. path may be
+// empty until the agent reports where its log is.
+func NewAgentLogPane(agentID, taskTitle, path string) *AgentLogPane {
+	return &AgentLogPane{
+		agentID:   agentID,
+		taskTitle: taskTitle,
+		path:      path,
+		follow:    true,
+
+		titleStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("205")),
+
+		hintStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")),
+
+		lineStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")),
+
+		errorStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")),
+	}
+}
+
+// AgentID returns the agent whose log is shown.
+func (p *AgentLogPane) AgentID() string {
+	return p.agentID
+}
+
+// SetPath sets the log file once it is known. Changing the path starts
+// reading the new file from the beginning.
+func (p *AgentLogPane) SetPath(path string) {
+	if path == "" || path == p.path {
+		return
+	}
+	p.path = path
+	p.reset()
+}
+
+// SetSize sets the pane dimensions.
+func (p *AgentLogPane) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	if p.follow {
+		p.scrollToBottom()
+	}
+}
+
+// Refresh reads any output appended to the log since the last refresh. The
+// executor rewrites the log when the agent finishes, so a file that shrank
+// is read again from the start.
+func (p *AgentLogPane) Refresh() {
+	if p.path == "" {
+		return
+	}
+	f, err := os.Open(p.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			p.err = err
+		}
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		p.err = err
+		return
+	}
+	if info.Size() < p.readOffset {
+		p.reset()
+	}
+	if info.Size() == p.readOffset {
+		return
+	}
+	if _, err := f.Seek(p.readOffset, io.SeekStart); err != nil {
+		p.err = err
+		return
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.err = nil
+	p.readOffset += int64(len(data))
+	p.appendText(string(data))
+}
+
+// appendText splits text into lines, completing the pending partial line.
+func (p *AgentLogPane) appendText(text string) {
+	parts := strings.Split(p.partial+text, "\n")
+	p.partial = parts[len(parts)-1]
+	p.lines = append(p.lines, parts[:len(parts)-1]...)
+	if over := len(p.lines) - agentLogMaxLines; over > 0 {
+		p.lines = p.lines[over:]
+		p.scrollOffset -= over
+		if p.scrollOffset < 0 {
+			p.scrollOffset = 0
+		}
+	}
+	if p.follow {
+		p.scrollToBottom()
+	}
+}
+
+// reset forgets everything read so far.
+func (p *AgentLogPane) reset() {
+	p.lines = nil
+	p.partial = ""
+	p.readOffset = 0
+	p.scrollOffset = 0
+	p.err = nil
+}
+
+// Update handles scrolling keys.
+func (p *AgentLogPane) Update(msg tea.Msg) (*AgentLogPane, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+	switch keyMsg.String() {
+	case "up", "k":
+		p.scrollBy(-1)
+	case "down", "j":
+		p.scrollBy(1)
+	case "pgup", "b":
+		p.scrollBy(-p.visibleLines())
+	case "pgdown", " ":
+		p.scrollBy(p.visibleLines())
+	case "g":
+		// Go to top
+		p.scrollOffset = 0
+		p.follow = false
+	case "G":
+		// Go to bottom and follow
+		p.follow = true
+		p.scrollToBottom()
+	case "f":
+		// Toggle follow mode
+		p.follow = !p.follow
+		if p.follow {
+			p.scrollToBottom()
+		}
+	}
+	return p, nil
+}
+
+// scrollBy moves the view by delta lines. Scrolling up leaves follow mode;
+// reaching the bottom does not re-enter it.
+func (p *AgentLogPane) scrollBy(delta int) {
+	if delta < 0 {
+		p.follow = false
+	}
+	p.scrollOffset += delta
+	if limit := p.maxScrollOffset(); p.scrollOffset > limit {
+		p.scrollOffset = limit
+	}
+	if p.scrollOffset < 0 {
+		p.scrollOffset = 0
+	}
+}
+
+// allLines returns the complete lines followed by the pending partial line.
+func (p *AgentLogPane) allLines() []string {
+	if p.partial == "" {
+		return p.lines
+	}
+	return append(p.lines[:len(p.lines):len(p.lines)], p.partial)
+}
+
+// visibleLines returns the number of log lines that fit in the pane.
+func (p *AgentLogPane) visibleLines() int {
+	lines := p.height - 3 // Account for title, status line and key hints
+	if lines < 1 {
+		lines = 1
+	}
+	return lines
+}
+
+// maxScrollOffset returns the offset that shows the last page of the log.
+func (p *AgentLogPane) maxScrollOffset() int {
+	offset := len(p.allLines()) - p.visibleLines()
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// scrollToBottom scrolls to the end of the log.
+func (p *AgentLogPane) scrollToBottom() {
+	p.scrollOffset = p.maxScrollOffset()
+}
+
+// View renders the pane.
+func (p *AgentLogPane) View() string {
+	var b strings.Builder
+
+	agentShort := p.agentID
+	if len(agentShort) > 12 {
+		agentShort = agentShort[:12]
+	}
+	b.WriteString(p.titleStyle.Render(fmt.Sprintf("Agent %s: %s", agentShort, p.taskTitle)))
+	b.WriteString("\n")
+
+	lines := p.allLines()
+	status := p.path
+	if status == "" {
+		status = "waiting for the agent to report its log file"
+	}
+	mode := "follow"
+	if !p.follow {
+		mode = fmt.Sprintf("line %d/%d", p.scrollOffset+1, len(lines))
+	}
+	b.WriteString(p.hintStyle.Render(fmt.Sprintf("%s [%s]", status, mode)))
+	b.WriteString("\n")
+
+	visible := p.visibleLines()
+	switch {
+	case p.err != nil:
+		b.WriteString(p.errorStyle.Render(fmt.Sprintf("  Error reading log: %v", p.err)))
+		b.WriteString("\n")
+		visible--
+	case len(lines) == 0:
+		b.WriteString(p.hintStyle.Italic(true).Render("  No output yet"))
+		b.WriteString("\n")
+		visible--
+	}
+
+	end := p.scrollOffset + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for _, line := range lines[min(p.scrollOffset, end):end] {
+		if p.width > 0 && len(line) > p.width {
+			line = line[:p.width]
+		}
+		b.WriteString(p.lineStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(p.hintStyle.Render("esc: close  1-9: switch agent  j/k: scroll  g/G: top/bottom  f: follow"))
+	b.WriteString("\n")
+	return b.String()
+}
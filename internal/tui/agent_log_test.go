@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAgentLogPane_TailsAppendedOutput(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "task.log")
+	if err := os.WriteFile(logFile, []byte("line 1\nline 2\npart"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pane := NewAgentLogPane("agent-1", "Add login", logFile)
+	pane.SetSize(80, 20)
+	pane.Refresh()
+	if got := pane.allLines(); len(got) != 3 || got[2] != "part" {
+		t.Fatalf("expected two lines and a partial line, got %q", got)
+	}
+
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(f, "ial line\nline 4\n")
+	f.Close()
+
+	pane.Refresh()
+	want := []string{"line 1", "line 2", "partial line", "line 4"}
+	if got := pane.allLines(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAgentLogPane_RereadsRewrittenLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "task.log")
+	if err := os.WriteFile(logFile, []byte("streaming output that is fairly long\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pane := NewAgentLogPane("agent-1", "Add login", logFile)
+	pane.Refresh()
+
+	// The executor replaces the streamed log with the final one
+	if err := os.WriteFile(logFile, []byte("final\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pane.Refresh()
+	if got := pane.allLines(); len(got) != 1 || got[0] != "final" {
+		t.Errorf("expected the rewritten log, got %q", got)
+	}
+}
+
+func TestAgentLogPane_FollowMode(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	logFile := filepath.Join(t.TempDir(), "task.log")
+	if err := os.WriteFile(logFile, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pane := NewAgentLogPane("agent-1", "Add login", logFile)
+	pane.SetSize(80, 13) // 10 visible lines
+	pane.Refresh()
+
+	if !pane.follow || pane.scrollOffset != 40 {
+		t.Fatalf("expected to follow the end of the log, got follow=%v offset=%d", pane.follow, pane.scrollOffset)
+	}
+	if view := pane.View(); !strings.Contains(view, "line 50") || strings.Contains(view, "line 40\n") {
+		t.Errorf("expected the last page of the log, got:\n%s", view)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if pane.follow || pane.scrollOffset != 39 {
+		t.Errorf("expected scrolling up to stop following, got follow=%v offset=%d", pane.follow, pane.scrollOffset)
+	}
+
+	// New output does not move the view while not following
+	f, _ := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	fmt.Fprint(f, "line 51\n")
+	f.Close()
+	pane.Refresh()
+	if pane.scrollOffset != 39 {
+		t.Errorf("expected the view to stay put, got offset %d", pane.scrollOffset)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if !pane.follow || pane.scrollOffset != 41 {
+		t.Errorf("expected G to resume following, got follow=%v offset=%d", pane.follow, pane.scrollOffset)
+	}
+}
+
+func TestAgentLogPane_WaitsForLogFile(t *testing.T) {
+	pane := NewAgentLogPane("agent-1", "Add login", "")
+	pane.Refresh()
+	if view := pane.View(); !strings.Contains(view, "waiting for the agent") {
+		t.Errorf("expected a waiting message, got:\n%s", view)
+	}
+
+	logFile := filepath.Join(t.TempDir(), "task.log")
+	if err := os.WriteFile(logFile, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pane.SetPath(logFile)
+	pane.Refresh()
+	if view := pane.View(); !strings.Contains(view, "hello") {
+		t.Errorf("expected log content once the path is known, got:\n%s", view)
+	}
+}
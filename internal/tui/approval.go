@@ -0,0 +1,60 @@
+package tui
+
+import "fmt"
+
+// ApprovalHandler answers the approval gate a run is held at: true
+// approves it, false rejects it.
+type ApprovalHandler func(approved bool) error
+
+// ImplementApprovalMsg reports that the implement loop is held at an
+// approval gate, or that the gate was answered.
+type ImplementApprovalMsg struct {
+	Pending bool
+	Summary string
+}
+
+// approvalPrompt tracks the approval gate a run is held at and answers it
+// with y or n.
+type approvalPrompt struct {
+	summary string
+	pending bool
+	handler ApprovalHandler
+}
+
+// set records that the run is held at a gate described by summary.
+func (p *approvalPrompt) set(summary string) {
+	p.summary = summary
+	p.pending = true
+}
+
+// clear records that the gate was answered.
+func (p *approvalPrompt) clear() {
+	p.summary = ""
+	p.pending = false
+}
+
+// active reports whether a gate is pending and can be answered.
+func (p *approvalPrompt) active() bool {
+	return p.pending && p.handler != nil
+}
+
+// handleKey answers the pending gate on y or n. It reports whether the key
+// was used, and the error answering the gate, if any.
+func (p *approvalPrompt) handleKey(key string) (bool, error) {
+	if !p.active() {
+		return false, nil
+	}
+	var approved bool
+	switch key {
+	case "y", "Y":
+		approved = true
+	case "n", "N":
+	default:
+		return false, nil
+	}
+	if err := p.handler(approved); err != nil {
+		return true, fmt.Errorf("answer approval: %w", err)
+	}
+	p.clear()
+	return true, nil
+}
@@ -22,6 +22,10 @@ type Footer struct {
 	activeTab    int
 	width        int
 	taskCounts   TaskCounts
+	cost         float64
+	tokens       int64
+	// awaitingApproval is set while the session is held at an approval gate.
+	awaitingApproval bool
 
 	// Styles
 	successStyle   lipgloss.Style
@@ -79,6 +83,18 @@ func (f *Footer) SetTaskCounts(counts TaskCounts) {
 	f.taskCounts = counts
 }
 
+// SetCost updates the session's running cost and token usage for display.
+func (f *Footer) SetCost(cost float64, tokens int64) {
+	f.cost = cost
+	f.tokens = tokens
+}
+
+// SetAwaitingApproval sets whether the session is held at an approval gate
+// the operator can answer.
+func (f *Footer) SetAwaitingApproval(awaiting bool) {
+	f.awaitingApproval = awaiting
+}
+
 // SetActiveTab sets which tab is currently active.
 func (f *Footer) SetActiveTab(tab int) {
 	f.activeTab = tab
@@ -89,7 +105,7 @@ func (f *Footer) View() string {
 	var left string
 	var right string
 
-	// Left side: task counts and status message
+	// Left side: task counts, cost and status message
 	total := f.taskCounts.Done + f.taskCounts.Failed + f.taskCounts.Running
 	if total > 0 {
 		counts := fmt.Sprintf("✓%d", f.taskCounts.Done)
@@ -101,6 +117,14 @@ func (f *Footer) View() string {
 		}
 		left = counts
 	}
+	if f.tokens > 0 {
+		cost := fmt.Sprintf("$%.2f (%s tokens)", f.cost, formatTokensCompact(f.tokens))
+		if left != "" {
+			left += " " + cost
+		} else {
+			left = cost
+		}
+	}
 
 	if f.sessionDone {
 		if f.success {
@@ -134,6 +158,9 @@ func (f *Footer) keyboardHints() string {
 
 	// Base hints - always show tab switching
 	hints := "1:Main 2:Logs"
+	if f.awaitingApproval {
+		hints = "y approve │ n reject │ " + hints
+	}
 
 	if f.activeTab == 1 { // TabLogs
 		// Logs tab hints
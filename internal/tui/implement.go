@@ -3,9 +3,11 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/ShayCichocki/alphie/internal/orchestrator"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -33,6 +35,10 @@ type WorkerInfo struct {
 	TaskID    string
 	TaskTitle string
 	Status    string // "running", "blocked", etc.
+	LogFile   string // Execution log, streamed while the agent runs
+	// Questions the task's agents asked and may ask, -1 for unlimited
+	QuestionsAsked   int
+	QuestionsAllowed int
 }
 
 // ImplementUpdateMsg is sent when implementation state changes.
@@ -40,12 +46,22 @@ type ImplementUpdateMsg struct {
 	State ImplementState
 }
 
+// ImplementCostMsg is sent as the run's cost grows between state changes.
+// It updates the displayed cost only.
+type ImplementCostMsg struct {
+	Cost float64
+}
+
 // ImplementView displays the implementation progress tab.
 type ImplementView struct {
 	state  ImplementState
 	width  int
 	height int
 
+	// workerSlots lists agent IDs by the number key (index+1) that opens
+	// their log. A finished worker's slot is reused by the next new one.
+	workerSlots []string
+
 	// Styles
 	headerStyle   lipgloss.Style
 	labelStyle    lipgloss.Style
@@ -110,7 +126,9 @@ func (v *ImplementView) Update(msg tea.Msg) (*ImplementView, tea.Cmd) {
 		v.width = msg.Width
 		v.height = msg.Height
 	case ImplementUpdateMsg:
-		v.state = msg.State
+		v.SetState(msg.State)
+	case ImplementCostMsg:
+		v.SetCost(msg.Cost)
 	}
 	return v, nil
 }
@@ -174,7 +192,11 @@ func (v *ImplementView) View() string {
 		b.WriteString("\n")
 		b.WriteString(v.labelStyle.Render("Active Workers:"))
 		b.WriteString("\n")
-		for _, worker := range v.state.ActiveWorkers {
+		for i, agentID := range v.workerSlots {
+			worker, ok := v.state.ActiveWorkers[agentID]
+			if !ok {
+				continue
+			}
 			agentShort := worker.AgentID
 			if len(agentShort) > 12 {
 				agentShort = agentShort[:12]
@@ -193,11 +215,19 @@ func (v *ImplementView) View() string {
 				statusStyle = v.blockedStyle
 			}
 
-			workerLine := fmt.Sprintf("  %s  A:%s T:%s  %s",
+			workerLine := fmt.Sprintf("  [%d] %s  A:%s T:%s  %s",
+				i+1,
 				statusStyle.Render(worker.Status),
 				agentShort,
 				taskShort,
 				title)
+			if worker.QuestionsAllowed != 0 || worker.QuestionsAsked > 0 {
+				usageStyle := v.valueStyle
+				if worker.QuestionsAllowed >= 0 && worker.QuestionsAsked >= worker.QuestionsAllowed {
+					usageStyle = v.warningStyle
+				}
+				workerLine += "  " + usageStyle.Render(orchestrator.FormatQuestionUsage(worker.QuestionsAsked, worker.QuestionsAllowed))
+			}
 			b.WriteString(workerLine)
 			b.WriteString("\n")
 		}
@@ -252,6 +282,60 @@ func (v *ImplementView) renderProgressBar(pct float64, width int) string {
 // SetState updates the implementation state.
 func (v *ImplementView) SetState(state ImplementState) {
 	v.state = state
+	v.assignWorkerSlots()
+}
+
+// SetCost updates the displayed cost.
+func (v *ImplementView) SetCost(cost float64) {
+	v.state.Cost = cost
+}
+
+// assignWorkerSlots keeps each active worker on the same number key for as
+// long as it runs, giving new workers the lowest free slot.
+func (v *ImplementView) assignWorkerSlots() {
+	assigned := make(map[string]bool, len(v.workerSlots))
+	for i, agentID := range v.workerSlots {
+		if _, ok := v.state.ActiveWorkers[agentID]; ok {
+			assigned[agentID] = true
+		} else {
+			v.workerSlots[i] = ""
+		}
+	}
+
+	var added []string
+	for agentID := range v.state.ActiveWorkers {
+		if !assigned[agentID] {
+			added = append(added, agentID)
+		}
+	}
+	sort.Strings(added)
+	for _, agentID := range added {
+		slot := -1
+		for i, id := range v.workerSlots {
+			if id == "" {
+				slot = i
+				break
+			}
+		}
+		if slot < 0 {
+			v.workerSlots = append(v.workerSlots, agentID)
+		} else {
+			v.workerSlots[slot] = agentID
+		}
+	}
+
+	for len(v.workerSlots) > 0 && v.workerSlots[len(v.workerSlots)-1] == "" {
+		v.workerSlots = v.workerSlots[:len(v.workerSlots)-1]
+	}
+}
+
+// WorkerInSlot returns the active worker shown with number key n (1-based).
+func (v *ImplementView) WorkerInSlot(n int) (WorkerInfo, bool) {
+	if n < 1 || n > len(v.workerSlots) || v.workerSlots[n-1] == "" {
+		return WorkerInfo{}, false
+	}
+	worker, ok := v.state.ActiveWorkers[v.workerSlots[n-1]]
+	return worker, ok
 }
 
 // SetSize sets the view dimensions.
@@ -282,6 +366,13 @@ type ImplementApp struct {
 	done     bool
 	err      error
 
+	// logPane is the open agent log, or nil.
+	logPane *AgentLogPane
+	// logTicking is true while an AgentLogTickMsg is scheduled.
+	logTicking bool
+	// approval is the approval gate the loop is held at.
+	approval approvalPrompt
+
 	// Styles
 	logStyle     lipgloss.Style
 	logTimeStyle lipgloss.Style
@@ -311,6 +402,11 @@ func NewImplementApp() *ImplementApp {
 	}
 }
 
+// SetApprovalHandler lets the operator answer approval gates with y or n.
+func (a *ImplementApp) SetApprovalHandler(h ApprovalHandler) {
+	a.approval.handler = h
+}
+
 // Init implements tea.Model.
 func (a *ImplementApp) Init() tea.Cmd {
 	return nil
@@ -320,19 +416,59 @@ func (a *ImplementApp) Init() tea.Cmd {
 func (a *ImplementApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		if handled, err := a.approval.handleKey(msg.String()); handled {
+			if err != nil {
+				a.logs = append(a.logs, ImplementLogEntry{Timestamp: time.Now(), Phase: "approval", Message: err.Error()})
+			}
+			return a, nil
+		}
+		switch key := msg.String(); key {
 		case "q", "ctrl+c":
 			a.quitting = true
 			return a, tea.Quit
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			return a, a.openAgentLog(int(key[0] - '0'))
+		case "esc":
+			a.logPane = nil
+		default:
+			if a.logPane != nil {
+				a.logPane.Update(msg)
+			}
 		}
 
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
 		a.view.SetSize(msg.Width, msg.Height)
+		if a.logPane != nil {
+			a.logPane.SetSize(a.width, a.logPaneHeight())
+		}
 
 	case ImplementUpdateMsg:
 		a.view.SetState(msg.State)
+		if a.logPane != nil {
+			if worker, ok := msg.State.ActiveWorkers[a.logPane.AgentID()]; ok {
+				a.logPane.SetPath(worker.LogFile)
+			}
+		}
+
+	case ImplementCostMsg:
+		a.view.SetCost(msg.Cost)
+
+	case ImplementApprovalMsg:
+		if msg.Pending {
+			a.approval.set(msg.Summary)
+		} else {
+			a.approval.clear()
+		}
+
+	case AgentLogTickMsg:
+		if a.logPane == nil {
+			a.logTicking = false
+			return a, nil
+		}
+		a.logPane.Refresh()
+		return a, agentLogTick()
 
 	case ImplementLogMsg:
 		a.logs = append(a.logs, ImplementLogEntry{
@@ -352,6 +488,30 @@ func (a *ImplementApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// openAgentLog opens the log of the worker shown with number key n. The log
+// stays open after the worker finishes.
+func (a *ImplementApp) openAgentLog(n int) tea.Cmd {
+	worker, ok := a.view.WorkerInSlot(n)
+	if !ok {
+		return nil
+	}
+	if a.logPane == nil || a.logPane.AgentID() != worker.AgentID {
+		a.logPane = NewAgentLogPane(worker.AgentID, worker.TaskTitle, worker.LogFile)
+		a.logPane.SetSize(a.width, a.logPaneHeight())
+	}
+	a.logPane.Refresh()
+	if a.logTicking {
+		return nil
+	}
+	a.logTicking = true
+	return agentLogTick()
+}
+
+// logPaneHeight returns the height available to the agent log below the header.
+func (a *ImplementApp) logPaneHeight() int {
+	return a.height - 2
+}
+
 // View implements tea.Model.
 func (a *ImplementApp) View() string {
 	if a.quitting {
@@ -368,6 +528,12 @@ func (a *ImplementApp) View() string {
 	b.WriteString(header)
 	b.WriteString("\n\n")
 
+	// An open agent log replaces the progress view
+	if a.logPane != nil {
+		b.WriteString(a.logPane.View())
+		return b.String()
+	}
+
 	// Progress view
 	b.WriteString(a.view.View())
 	b.WriteString("\n")
@@ -383,10 +549,15 @@ func (a *ImplementApp) View() string {
 		} else {
 			b.WriteString(a.doneStyle.Render("Implementation complete! Press q to exit."))
 		}
+	} else if a.approval.active() {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true).
+			Render("Approval required: press y to approve, n to reject"))
 	} else {
 		b.WriteString(lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")).
-			Render("Press q to cancel"))
+			Render("Press q to cancel, 1-9 to follow an agent's log"))
 	}
 	b.WriteString("\n")
 
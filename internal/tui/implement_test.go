@@ -2,6 +2,8 @@ package tui
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -904,3 +906,120 @@ func TestImplementView_PercentageMatchesProgressBar(t *testing.T) {
 		})
 	}
 }
+
+func TestImplementView_WorkerSlotsAreStable(t *testing.T) {
+	view := NewImplementView()
+	view.SetState(ImplementState{ActiveWorkers: map[string]WorkerInfo{
+		"agent-a": {AgentID: "agent-a", TaskTitle: "A"},
+		"agent-b": {AgentID: "agent-b", TaskTitle: "B"},
+	}})
+
+	if w, ok := view.WorkerInSlot(2); !ok || w.AgentID != "agent-b" {
+		t.Fatalf("expected agent-b in slot 2, got %+v (ok=%v)", w, ok)
+	}
+
+	// agent-a finishes and agent-c starts: agent-b keeps its key, agent-c takes the free one
+	view.SetState(ImplementState{ActiveWorkers: map[string]WorkerInfo{
+		"agent-b": {AgentID: "agent-b", TaskTitle: "B"},
+		"agent-c": {AgentID: "agent-c", TaskTitle: "C"},
+	}})
+	if w, ok := view.WorkerInSlot(1); !ok || w.AgentID != "agent-c" {
+		t.Errorf("expected agent-c in freed slot 1, got %+v (ok=%v)", w, ok)
+	}
+	if w, ok := view.WorkerInSlot(2); !ok || w.AgentID != "agent-b" {
+		t.Errorf("expected agent-b to keep slot 2, got %+v (ok=%v)", w, ok)
+	}
+	if _, ok := view.WorkerInSlot(3); ok {
+		t.Error("expected slot 3 to be empty")
+	}
+	if output := view.View(); !strings.Contains(output, "[1]") || !strings.Contains(output, "[2]") {
+		t.Errorf("expected worker lines to show their number keys, got:\n%s", output)
+	}
+}
+
+func TestImplementApp_NumberKeyOpensAgentLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "task.log")
+	if err := os.WriteFile(logFile, []byte("Task: Add login\n--- Output ---\nReading auth.go\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewImplementApp()
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	app.Update(ImplementUpdateMsg{State: ImplementState{ActiveWorkers: map[string]WorkerInfo{
+		"agent-1": {AgentID: "agent-1", TaskTitle: "Add login", Status: "running", LogFile: logFile},
+	}}})
+
+	if _, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}}); cmd != nil || app.logPane != nil {
+		t.Fatal("expected a key without a worker to do nothing")
+	}
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	if cmd == nil {
+		t.Error("expected opening a log to schedule a refresh")
+	}
+	if app.logPane == nil || app.logPane.AgentID() != "agent-1" {
+		t.Fatal("expected the log of agent-1 to be open")
+	}
+	if output := app.View(); !strings.Contains(output, "Reading auth.go") {
+		t.Errorf("expected the log content in the view, got:\n%s", output)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.logPane != nil {
+		t.Error("expected esc to close the log")
+	}
+}
+
+func TestImplementView_View_QuestionUsage(t *testing.T) {
+	view := NewImplementView()
+	view.SetState(ImplementState{ActiveWorkers: map[string]WorkerInfo{
+		"agent-a": {AgentID: "agent-a", TaskTitle: "A", Status: "running", QuestionsAsked: 1, QuestionsAllowed: 2},
+		"agent-b": {AgentID: "agent-b", TaskTitle: "B", Status: "running", QuestionsAsked: 3, QuestionsAllowed: -1},
+		"agent-c": {AgentID: "agent-c", TaskTitle: "C", Status: "running"},
+	}})
+
+	output := view.View()
+	if !strings.Contains(output, "questions 1/2 used") {
+		t.Error("expected limited question usage in view")
+	}
+	if !strings.Contains(output, "questions 3 used") {
+		t.Error("expected unlimited question usage in view")
+	}
+	if strings.Count(output, "questions") != 2 {
+		t.Error("expected no question usage for a worker that may not ask")
+	}
+}
+
+func TestImplementApp_ApprovalKeys(t *testing.T) {
+	app := NewImplementApp()
+	var answers []bool
+	app.SetApprovalHandler(func(approved bool) error {
+		answers = append(answers, approved)
+		return nil
+	})
+
+	// Keys do nothing until a gate is pending
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if len(answers) != 0 {
+		t.Fatalf("expected no answer without a pending gate, got %v", answers)
+	}
+
+	app.Update(ImplementApprovalMsg{Pending: true, Summary: "Iteration 1 complete"})
+	if output := app.View(); !strings.Contains(output, "Approval required") {
+		t.Errorf("expected the approval prompt in the view, got:\n%s", output)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if len(answers) != 1 || answers[0] {
+		t.Fatalf("answers = %v, want [false]", answers)
+	}
+	if app.approval.active() {
+		t.Error("expected the prompt to clear after answering")
+	}
+
+	app.Update(ImplementApprovalMsg{Pending: true})
+	app.Update(ImplementApprovalMsg{Pending: false})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if len(answers) != 1 {
+		t.Errorf("expected no answer after the gate resolved, got %v", answers)
+	}
+}
@@ -52,6 +52,9 @@ type PanelApp struct {
 
 	// showHeader controls whether the header is displayed.
 	showHeader bool
+
+	// approval is the approval gate the session is held at.
+	approval approvalPrompt
 }
 
 // NewPanelApp creates a new PanelApp instance.
@@ -80,6 +83,11 @@ func (a *PanelApp) SetShowHeader(show bool) {
 	}
 }
 
+// SetApprovalHandler lets the operator answer approval gates with y or n.
+func (a *PanelApp) SetApprovalHandler(h ApprovalHandler) {
+	a.approval.handler = h
+}
+
 // Init implements tea.Model.
 func (a *PanelApp) Init() tea.Cmd {
 	return nil
@@ -91,6 +99,14 @@ func (a *PanelApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if handled, err := a.approval.handleKey(msg.String()); handled {
+			if err != nil {
+				a.logsPanel.AddLog(PanelLogEntry{Timestamp: time.Now(), Level: LogLevelError, Message: err.Error()})
+			} else {
+				a.footer.SetAwaitingApproval(false)
+			}
+			return a, nil
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			a.quitting = true
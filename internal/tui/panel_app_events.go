@@ -10,16 +10,24 @@ import (
 
 // handleOrchestratorEvent processes orchestrator events.
 func (a *PanelApp) handleOrchestratorEvent(msg OrchestratorEventMsg) {
+	// Cost ticks only update the running cost, without logging
+	if msg.Type == "cost_tick" {
+		a.footer.SetCost(msg.Cost, msg.TokensUsed)
+		return
+	}
+
 	log.Printf("[TUI] Received event: type=%s, taskID=%s, agentID=%s", msg.Type, msg.TaskID, msg.AgentID)
 
 	// Determine log level based on event type
 	level := LogLevelInfo
 	if msg.Error != "" {
 		level = LogLevelError
+	} else if msg.Type == "agent_stalled" || msg.Type == "resource_low" || msg.Type == "approval_required" {
+		level = LogLevelWarn
 	}
 
 	// Handle progress events differently - aggregate instead of spam
-	if msg.Type == "agent_progress" {
+	if msg.Type == "agent_progress" || msg.Type == "merge_queued" {
 		a.logsPanel.UpdateProgress(msg.AgentID, PanelLogEntry{
 			Timestamp: msg.Timestamp,
 			Level:     level,
@@ -53,10 +61,20 @@ func (a *PanelApp) handleOrchestratorEvent(msg OrchestratorEventMsg) {
 		a.handleTaskCompleted(msg)
 	case "task_failed":
 		a.handleTaskFailed(msg)
+	case "task_preempted":
+		a.handleTaskPreempted(msg)
 	case "agent_progress":
 		a.handleAgentProgress(msg)
-	case "merge_started", "merge_completed":
+	case "merge_queued":
+		a.handleMergeQueued(msg)
+	case "merge_started", "merge_partial", "merge_completed":
 		// Log only, no state changes needed
+	case "approval_required":
+		a.approval.set(msg.Message)
+		a.footer.SetAwaitingApproval(a.approval.active())
+	case "approval_resolved":
+		a.approval.clear()
+		a.footer.SetAwaitingApproval(false)
 	case "session_done":
 		a.handleSessionDone(msg)
 	}
@@ -168,6 +186,24 @@ func (a *PanelApp) handleTaskCompleted(msg OrchestratorEventMsg) {
 	a.updateFooterCounts()
 }
 
+func (a *PanelApp) handleTaskPreempted(msg OrchestratorEventMsg) {
+	// The agent was stopped to free its slot; the task waits to run again
+	if msg.AgentID != "" {
+		agent := a.findOrCreateAgent(msg.AgentID)
+		agent.Status = models.AgentStatusPaused
+		agent.CurrentAction = ""
+		a.agentsPanel.SetAgents(a.agents)
+		a.logsPanel.ClearProgress(msg.AgentID)
+	}
+	if msg.TaskID != "" {
+		task := a.findOrCreateTask(msg.TaskID)
+		task.Status = models.TaskStatusPending
+		task.AssignedTo = ""
+		a.tasksPanel.SetTasks(a.tasks)
+		a.updateFooterCounts()
+	}
+}
+
 func (a *PanelApp) handleTaskFailed(msg OrchestratorEventMsg) {
 	log.Printf("[TUI] handleTaskFailed: taskID=%s, agentID=%s, error=%s", msg.TaskID, msg.AgentID, msg.Error)
 
@@ -215,6 +251,15 @@ func (a *PanelApp) handleAgentProgress(msg OrchestratorEventMsg) {
 	}
 }
 
+func (a *PanelApp) handleMergeQueued(msg OrchestratorEventMsg) {
+	// Show the agent's place in the merge queue, e.g. "Waiting to merge (2 ahead)"
+	if msg.AgentID != "" {
+		agent := a.findOrCreateAgent(msg.AgentID)
+		agent.CurrentAction = msg.Message
+		a.agentsPanel.SetAgents(a.agents)
+	}
+}
+
 func (a *PanelApp) handleSessionDone(msg OrchestratorEventMsg) {
 	a.sessionDone = true
 	a.sessionSuccess = msg.Error == ""
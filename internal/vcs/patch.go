@@ -0,0 +1,235 @@
+package vcs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// skipDirs are directories never copied into a workspace or included in
+// its patch: version control metadata and Alphie's own state.
+var skipDirs = map[string]bool{
+	".git":    true,
+	".jj":     true,
+	".alphie": true,
+}
+
+// Snapshot maps each file of a directory, by slash-separated relative
+// path, to the SHA-256 of its content.
+type Snapshot map[string]string
+
+// TakeSnapshot hashes every regular file under root.
+func TakeSnapshot(root string) (Snapshot, error) {
+	snap := Snapshot{}
+	err := walkFiles(root, func(rel, path string) error {
+		sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		snap[rel] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", root, err)
+	}
+	return snap, nil
+}
+
+// Op is how a patch changes a file.
+type Op string
+
+const (
+	OpAdd    Op = "add"
+	OpModify Op = "modify"
+	OpDelete Op = "delete"
+)
+
+// FileChange is one file's change in a patch.
+type FileChange struct {
+	// Path is the slash-separated path relative to the project root.
+	Path string
+	Op   Op
+	// BaseHash is the hash of the file the change was made against; empty
+	// for an added file.
+	BaseHash string
+	// Content is the file's new content; nil for a deleted file.
+	Content []byte
+	// Mode is the new file's permission bits.
+	Mode fs.FileMode
+}
+
+// Patch is the set of file changes a workspace made to its base.
+type Patch struct {
+	Changes []FileChange
+}
+
+// Empty reports whether the patch changes nothing.
+func (p *Patch) Empty() bool {
+	return p == nil || len(p.Changes) == 0
+}
+
+// Files returns the paths the patch changes.
+func (p *Patch) Files() []string {
+	if p == nil {
+		return nil
+	}
+	files := make([]string, len(p.Changes))
+	for i, c := range p.Changes {
+		files[i] = c.Path
+	}
+	return files
+}
+
+// Diff returns the changes made under root since base was taken.
+func Diff(root string, base Snapshot) (*Patch, error) {
+	patch := &Patch{}
+	seen := map[string]bool{}
+	err := walkFiles(root, func(rel, path string) error {
+		seen[rel] = true
+		sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		baseHash, existed := base[rel]
+		if existed && baseHash == sum {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		change := FileChange{Path: rel, Op: OpAdd, Content: content, Mode: info.Mode().Perm()}
+		if existed {
+			change.Op = OpModify
+			change.BaseHash = baseHash
+		}
+		patch.Changes = append(patch.Changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("diff %s: %w", root, err)
+	}
+	for rel, baseHash := range base {
+		if !seen[rel] {
+			patch.Changes = append(patch.Changes, FileChange{Path: rel, Op: OpDelete, BaseHash: baseHash})
+		}
+	}
+	sort.Slice(patch.Changes, func(i, j int) bool { return patch.Changes[i].Path < patch.Changes[j].Path })
+	return patch, nil
+}
+
+// ConflictError is returned by Apply when files the patch changes were
+// changed in the target since the patch's base.
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("patch conflicts in %d file(s): %s", len(e.Files), strings.Join(e.Files, ", "))
+}
+
+// Apply writes the patch into root. Every change is checked against root
+// first: a modified or deleted file must still have its base content and
+// an added file must not exist (or already have the added content). If any
+// change conflicts, nothing is written and a *ConflictError lists them.
+func Apply(root string, p *Patch) error {
+	if p.Empty() {
+		return nil
+	}
+	var conflicts []string
+	for _, c := range p.Changes {
+		current, err := hashFile(filepath.Join(root, filepath.FromSlash(c.Path)))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("check %s: %w", c.Path, err)
+		}
+		if current != c.BaseHash && current != contentHash(c) {
+			conflicts = append(conflicts, c.Path)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Files: conflicts}
+	}
+
+	for _, c := range p.Changes {
+		path := filepath.Join(root, filepath.FromSlash(c.Path))
+		if c.Op == OpDelete {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("delete %s: %w", c.Path, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("write %s: %w", c.Path, err)
+		}
+		mode := c.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := os.WriteFile(path, c.Content, mode); err != nil {
+			return fmt.Errorf("write %s: %w", c.Path, err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("write %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// contentHash returns the hash the file has once the change is applied:
+// "" for a deletion, since a missing file hashes to "".
+func contentHash(c FileChange) string {
+	if c.Op == OpDelete {
+		return ""
+	}
+	sum := sha256.Sum256(c.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+// walkFiles calls fn with the relative and absolute path of every regular
+// file under root, skipping skipDirs. Symlinks are not followed.
+func walkFiles(root string, fn func(rel, path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), path)
+	})
+}
+
+// hashFile returns the SHA-256 of the file at path, or "" and an error
+// satisfying os.IsNotExist if it does not exist.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,87 @@
+// Package vcs abstracts how agent workspaces are created and how their
+// changes land, so sessions can run outside a git repository.
+package vcs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Kind is a workspace backend.
+type Kind string
+
+const (
+	// KindGit isolates agents in git worktrees and merges their branches.
+	// It is the default.
+	KindGit Kind = "git"
+	// KindPlain copies the project directory for each agent and applies
+	// each agent's changes back as a patch.
+	KindPlain Kind = "plain"
+	// KindJJ is reserved for jujutsu workspaces; it is detected but not
+	// supported yet.
+	KindJJ Kind = "jj"
+	// KindAuto picks the backend from the project directory.
+	KindAuto Kind = "auto"
+)
+
+// ErrUnsupported is returned for a backend Alphie detects but cannot run.
+var ErrUnsupported = errors.New("vcs backend not supported")
+
+// ParseKind parses a configured backend name. An empty name is KindGit.
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(s); k {
+	case "":
+		return KindGit, nil
+	case KindGit, KindPlain, KindJJ, KindAuto:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown vcs %q: must be auto, git, plain or jj", s)
+	}
+}
+
+// Detect returns the backend for dir: KindJJ if it is a jujutsu repository,
+// KindGit if it is a git repository, and KindPlain otherwise. A colocated
+// jujutsu repository, which also has a .git, is reported as KindJJ.
+func Detect(dir string) Kind {
+	for d := dir; ; {
+		if exists(filepath.Join(d, ".jj")) {
+			return KindJJ
+		}
+		if exists(filepath.Join(d, ".git")) {
+			return KindGit
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return KindPlain
+		}
+		d = parent
+	}
+}
+
+// Resolve returns the backend a session in dir runs with: kind itself, or
+// the detected backend for KindAuto. It fails for backends that cannot run
+// yet, so a session does not start half-configured.
+func Resolve(kind Kind, dir string) (Kind, error) {
+	if kind == "" {
+		kind = KindGit
+	}
+	if kind == KindAuto {
+		kind = Detect(dir)
+		// A colocated jujutsu repository is still a git repository
+		if kind == KindJJ && exists(filepath.Join(dir, ".git")) {
+			kind = KindGit
+		}
+	}
+	if kind == KindJJ {
+		return "", fmt.Errorf("%w: jujutsu workspaces are not supported yet; use git or plain", ErrUnsupported)
+	}
+	return kind, nil
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
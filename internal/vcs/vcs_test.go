@@ -0,0 +1,180 @@
+package vcs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseKind(t *testing.T) {
+	for in, want := range map[string]Kind{"": KindGit, "git": KindGit, "plain": KindPlain, "jj": KindJJ, "auto": KindAuto} {
+		if got, err := ParseKind(in); err != nil || got != want {
+			t.Errorf("ParseKind(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseKind("svn"); err == nil {
+		t.Error("expected an unknown backend to be rejected")
+	}
+}
+
+func TestDetectAndResolve(t *testing.T) {
+	plain := t.TempDir()
+	if got := Detect(plain); got != KindPlain {
+		t.Errorf("Detect(plain) = %q", got)
+	}
+
+	repo := t.TempDir()
+	mustMkdir(t, filepath.Join(repo, ".git"))
+	mustMkdir(t, filepath.Join(repo, "sub"))
+	if got := Detect(filepath.Join(repo, "sub")); got != KindGit {
+		t.Errorf("Detect(git subdirectory) = %q", got)
+	}
+
+	jj := t.TempDir()
+	mustMkdir(t, filepath.Join(jj, ".jj"))
+	if got := Detect(jj); got != KindJJ {
+		t.Errorf("Detect(jj) = %q", got)
+	}
+	if _, err := Resolve(KindAuto, jj); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Resolve(auto, jj) error = %v, want ErrUnsupported", err)
+	}
+	// A colocated jujutsu repository runs as git
+	mustMkdir(t, filepath.Join(jj, ".git"))
+	if got, err := Resolve(KindAuto, jj); err != nil || got != KindGit {
+		t.Errorf("Resolve(auto, colocated jj) = %q, %v", got, err)
+	}
+
+	if got, err := Resolve(KindAuto, plain); err != nil || got != KindPlain {
+		t.Errorf("Resolve(auto, plain) = %q, %v", got, err)
+	}
+	if got, err := Resolve("", plain); err != nil || got != KindGit {
+		t.Errorf("Resolve(\"\", plain) = %q, %v, want git as the default", got, err)
+	}
+}
+
+func TestPlainWorkspaces_DiffAndApply(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n")
+	writeFile(t, root, "README.md", "# app\n")
+	writeFile(t, root, "old.txt", "remove me\n")
+	writeFile(t, root, ".alphie/state.db", "state")
+
+	ws, err := NewPlainWorkspaces(root, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := ws.Create("agent-a")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(a.Path, ".alphie")); !os.IsNotExist(err) {
+		t.Error("expected Alphie's state not to be copied into the workspace")
+	}
+
+	writeFile(t, a.Path, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, a.Path, "pkg/util.go", "package pkg\n")
+	if err := os.Remove(filepath.Join(a.Path, "old.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := ws.Diff(a.Path)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if got, want := patch.Files(), []string{"main.go", "old.txt", "pkg/util.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("patch files = %v, want %v", got, want)
+	}
+	ops := map[string]Op{}
+	for _, c := range patch.Changes {
+		ops[c.Path] = c.Op
+	}
+	if want := map[string]Op{"main.go": OpModify, "old.txt": OpDelete, "pkg/util.go": OpAdd}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %v, want %v", ops, want)
+	}
+
+	if err := ws.Apply(patch); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := readFile(t, root, "main.go"); got != "package main\n\nfunc main() {}\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readFile(t, root, "pkg/util.go"); got != "package pkg\n" {
+		t.Errorf("pkg/util.go = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old.txt")); !os.IsNotExist(err) {
+		t.Error("expected old.txt to be deleted")
+	}
+	// Applying the same patch again is a no-op, not a conflict
+	if err := ws.Apply(patch); err != nil {
+		t.Errorf("re-applying the patch: %v", err)
+	}
+
+	if err := ws.Remove(a.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a.Path); !os.IsNotExist(err) {
+		t.Error("expected the workspace to be removed")
+	}
+}
+
+func TestPlainWorkspaces_ConflictAppliesNothing(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.txt", "a\n")
+	writeFile(t, root, "b.txt", "b\n")
+
+	ws, err := NewPlainWorkspaces(root, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := ws.Create("first")
+	second, _ := ws.Create("second")
+
+	writeFile(t, first.Path, "a.txt", "a from first\n")
+	writeFile(t, second.Path, "a.txt", "a from second\n")
+	writeFile(t, second.Path, "b.txt", "b from second\n")
+
+	firstPatch, _ := ws.Diff(first.Path)
+	if err := ws.Apply(firstPatch); err != nil {
+		t.Fatalf("Apply(first) error = %v", err)
+	}
+
+	secondPatch, _ := ws.Diff(second.Path)
+	err = ws.Apply(secondPatch)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Apply(second) error = %v, want a ConflictError", err)
+	}
+	if !reflect.DeepEqual(conflict.Files, []string{"a.txt"}) {
+		t.Errorf("conflicts = %v", conflict.Files)
+	}
+	if got := readFile(t, root, "b.txt"); got != "b\n" {
+		t.Errorf("expected a conflicting patch to change nothing, b.txt = %q", got)
+	}
+}
+
+func mustMkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	mustMkdir(t, filepath.Dir(path))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, rel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,239 @@
+package vcs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Workspace is a copy of the project an agent works in.
+type Workspace struct {
+	// Name identifies the workspace; it is the last element of Path.
+	Name string
+	// Path is the workspace directory.
+	Path string
+	// Base is the snapshot of the workspace when it was created, which its
+	// patch is computed against.
+	Base Snapshot
+}
+
+// PlainWorkspaces creates copy-on-write copies of a plain project
+// directory for agents and applies their changes back as patches.
+type PlainWorkspaces struct {
+	root    string
+	baseDir string
+
+	mu sync.Mutex
+	// applyMu serializes patches applied to root
+	applyMu    sync.Mutex
+	workspaces map[string]*Workspace
+}
+
+// NewPlainWorkspaces creates workspaces of root under baseDir.
+func NewPlainWorkspaces(root, baseDir string) (*PlainWorkspaces, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("create workspace base directory: %w", err)
+	}
+	return &PlainWorkspaces{
+		root:       root,
+		baseDir:    baseDir,
+		workspaces: make(map[string]*Workspace),
+	}, nil
+}
+
+// Root returns the project directory.
+func (w *PlainWorkspaces) Root() string {
+	return w.root
+}
+
+// BaseDir returns the directory workspaces are created in.
+func (w *PlainWorkspaces) BaseDir() string {
+	return w.baseDir
+}
+
+// Create copies the project into a new workspace called name. The copy
+// is taken while no patch is being applied, so its base is consistent.
+func (w *PlainWorkspaces) Create(name string) (*Workspace, error) {
+	path := filepath.Join(w.baseDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("workspace %s already exists", path)
+	}
+
+	w.applyMu.Lock()
+	err := CopyTree(w.root, path)
+	w.applyMu.Unlock()
+	if err != nil {
+		_ = os.RemoveAll(path)
+		return nil, fmt.Errorf("copy project into workspace: %w", err)
+	}
+	base, err := TakeSnapshot(path)
+	if err != nil {
+		_ = os.RemoveAll(path)
+		return nil, err
+	}
+
+	ws := &Workspace{Name: name, Path: path, Base: base}
+	w.mu.Lock()
+	w.workspaces[path] = ws
+	w.mu.Unlock()
+	return ws, nil
+}
+
+// Get returns the workspace at path, or nil if this process did not
+// create it.
+func (w *PlainWorkspaces) Get(path string) *Workspace {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.workspaces[path]
+}
+
+// Diff returns the changes made in the workspace at path.
+func (w *PlainWorkspaces) Diff(path string) (*Patch, error) {
+	ws := w.Get(path)
+	if ws == nil {
+		return nil, fmt.Errorf("unknown workspace %s", path)
+	}
+	return Diff(ws.Path, ws.Base)
+}
+
+// Apply applies a workspace's patch to the project. Patches are applied
+// one at a time; a conflicting patch returns a *ConflictError and changes
+// nothing.
+func (w *PlainWorkspaces) Apply(p *Patch) error {
+	w.applyMu.Lock()
+	defer w.applyMu.Unlock()
+	return Apply(w.root, p)
+}
+
+// Remove deletes the workspace at path.
+func (w *PlainWorkspaces) Remove(path string) error {
+	w.mu.Lock()
+	delete(w.workspaces, path)
+	w.mu.Unlock()
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("remove workspace %s: %w", path, err)
+	}
+	return nil
+}
+
+// List returns the paths of the workspace directories under the base
+// directory, including those left by earlier runs.
+func (w *PlainWorkspaces) List() ([]string, error) {
+	entries, err := os.ReadDir(w.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list workspaces: %w", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			paths = append(paths, filepath.Join(w.baseDir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// CopyTree copies src into the new directory dst, skipping version control
+// metadata and Alphie's state. Files are cloned copy-on-write where the
+// platform's cp supports it (reflinks on Linux, clonefile on macOS) and
+// copied otherwise.
+func CopyTree(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if skipDirs[e.Name()] {
+			continue
+		}
+		from := filepath.Join(src, e.Name())
+		to := filepath.Join(dst, e.Name())
+		if err := cloneEntry(from, to); err != nil {
+			_ = os.RemoveAll(to)
+			if err := copyEntry(from, to); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cloneEntry copies one top-level entry with the platform's cp, asking it
+// to share blocks with the original where the filesystem can.
+func cloneEntry(from, to string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("cp", "-a", "--reflink=auto", from, to)
+	case "darwin":
+		cmd = exec.Command("cp", "-c", "-pR", from, to)
+	default:
+		return fmt.Errorf("no copy-on-write cp on %s", runtime.GOOS)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cp: %s: %w", output, err)
+	}
+	return nil
+}
+
+// copyEntry copies a file or directory tree byte by byte.
+func copyEntry(from, to string) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// copyFile copies the regular file from to the path to.
+func copyFile(from, to string, mode fs.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
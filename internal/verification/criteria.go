@@ -0,0 +1,310 @@
+// Package verification provides verification contract generation and execution.
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// acceptanceContractPrompt is the prompt template for turning a task's
+// acceptance criteria into executable checks before implementation.
+const acceptanceContractPrompt = `Turn the acceptance criteria of a task into executable checks BEFORE implementation.
+
+## Acceptance Criteria
+%s
+
+## File Boundaries
+%s
+
+## Project Context
+%s
+
+Write one or more checks that fail now and pass once the criteria are met.
+Each check is either a POSIX shell script or a Go test file.
+
+Return ONLY a JSON object with this exact structure (no other text):
+{
+  "checks": [
+    {
+      "kind": "script",
+      "name": "health_endpoint.sh",
+      "criterion": "The criterion this check proves",
+      "content": "#!/bin/sh\nset -e\n...",
+      "required": true
+    },
+    {
+      "kind": "go_test",
+      "name": "contract_login_test.go",
+      "package": "internal/auth",
+      "criterion": "The criterion this check proves",
+      "content": "package auth\n\nimport \"testing\"\n\nfunc TestContractLogin(t *testing.T) { ... }",
+      "required": true
+    }
+  ]
+}
+
+Guidelines:
+- Scripts run from the repository root and pass when they exit 0
+- Go tests are copied into "package" (a directory relative to the repository root) and run there;
+  use the package clause of that directory, name the file *_test.go and every test function TestContract*
+- Test behavior described by the criteria, not implementation details that do not exist yet
+- Mark checks for criteria that must hold as required=true
+- Prefer a few strong checks over many weak ones
+`
+
+// CheckKind identifies how an acceptance check is executed.
+type CheckKind string
+
+const (
+	// CheckScript is a shell script run from the repository root.
+	CheckScript CheckKind = "script"
+	// CheckGoTest is a Go test file run inside a package of the repository.
+	CheckGoTest CheckKind = "go_test"
+)
+
+// acceptanceTestPattern selects the test functions of a go_test check.
+const acceptanceTestPattern = "^TestContract"
+
+// goTestTimeout bounds a go_test check, which may have to build its package.
+const goTestTimeout = 5 * time.Minute
+
+var (
+	// checkNamePattern restricts check names to safe base names.
+	checkNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// testFuncPattern finds the test functions declared in a go_test check.
+	testFuncPattern = regexp.MustCompile(`(?m)^func\s+(Test\w*)\s*\(`)
+)
+
+// AcceptanceCheck is one executable check generated from an acceptance criterion.
+type AcceptanceCheck struct {
+	// Kind is how the check is executed.
+	Kind CheckKind `json:"kind"`
+	// Name is the file name of the check.
+	Name string `json:"name"`
+	// Package is the directory a go_test check runs in, relative to the repository root.
+	Package string `json:"package,omitempty"`
+	// Criterion is the acceptance criterion the check proves.
+	Criterion string `json:"criterion"`
+	// Required indicates whether a failing check fails verification.
+	Required bool `json:"required"`
+	// Content is the script or test source. It is persisted as its own file.
+	Content string `json:"-"`
+}
+
+// AcceptanceContract holds the checks generated from a task's acceptance criteria.
+type AcceptanceContract struct {
+	// TaskID is the ID of the task the checks belong to.
+	TaskID string `json:"task_id"`
+	// Criteria are the acceptance criteria the checks were generated from.
+	Criteria string `json:"criteria"`
+	// Checks are the generated checks.
+	Checks []AcceptanceCheck `json:"checks"`
+	// CreatedAt is when the checks were generated.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContractGenerator turns acceptance criteria into executable contract
+// scripts and Go tests and persists them with the task's contracts.
+type ContractGenerator struct {
+	storage      *ContractStorage
+	promptRunner PromptRunner
+}
+
+// NewContractGenerator creates a ContractGenerator that stores checks in storage.
+func NewContractGenerator(storage *ContractStorage, runner PromptRunner) *ContractGenerator {
+	return &ContractGenerator{
+		storage:      storage,
+		promptRunner: runner,
+	}
+}
+
+// Generate returns the acceptance checks for a task. Checks already persisted
+// for the same criteria are reused so a retried task keeps its contract;
+// otherwise new checks are generated and persisted. Returns nil if the
+// criteria are empty or no usable check was generated.
+func (g *ContractGenerator) Generate(
+	ctx context.Context,
+	taskID string,
+	criteria string,
+	fileBoundaries []string,
+	projectContext string,
+	workDir string,
+) (*AcceptanceContract, error) {
+	criteria = strings.TrimSpace(criteria)
+	if criteria == "" {
+		return nil, nil
+	}
+
+	if existing, err := g.storage.LoadAcceptance(taskID); err == nil && existing.Criteria == criteria {
+		return existing, nil
+	}
+
+	if g.promptRunner == nil {
+		return nil, fmt.Errorf("no prompt runner to generate acceptance checks")
+	}
+
+	boundaries := strings.Join(fileBoundaries, "\n")
+	if boundaries == "" {
+		boundaries = "(no file boundaries)"
+	}
+	if projectContext == "" {
+		projectContext = GetProjectContext(workDir)
+	}
+
+	prompt := fmt.Sprintf(acceptanceContractPrompt, criteria, boundaries, projectContext)
+	response, err := g.promptRunner.RunPrompt(ctx, prompt, workDir)
+	if err != nil {
+		return nil, fmt.Errorf("run acceptance prompt: %w", err)
+	}
+
+	checks, err := parseAcceptanceChecks(response)
+	if err != nil {
+		return nil, fmt.Errorf("parse acceptance response: %w", err)
+	}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	contract := &AcceptanceContract{
+		TaskID:    taskID,
+		Criteria:  criteria,
+		Checks:    checks,
+		CreatedAt: time.Now(),
+	}
+	if err := g.storage.SaveAcceptance(contract); err != nil {
+		return nil, fmt.Errorf("save acceptance checks: %w", err)
+	}
+	return contract, nil
+}
+
+// acceptanceResponse is the expected JSON structure of the acceptance prompt.
+type acceptanceResponse struct {
+	Checks []struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Package   string `json:"package"`
+		Criterion string `json:"criterion"`
+		Content   string `json:"content"`
+		Required  bool   `json:"required"`
+	} `json:"checks"`
+}
+
+// parseAcceptanceChecks parses Claude's response into validated checks.
+// Invalid checks are dropped rather than failing the whole contract.
+func parseAcceptanceChecks(response string) ([]AcceptanceCheck, error) {
+	jsonStart := strings.Index(response, "{")
+	jsonEnd := strings.LastIndex(response, "}")
+	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+
+	var ar acceptanceResponse
+	if err := json.Unmarshal([]byte(response[jsonStart:jsonEnd+1]), &ar); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var checks []AcceptanceCheck
+	for _, c := range ar.Checks {
+		check := AcceptanceCheck{
+			Kind:      CheckKind(c.Kind),
+			Name:      c.Name,
+			Package:   c.Package,
+			Criterion: strings.TrimSpace(c.Criterion),
+			Content:   c.Content,
+			Required:  c.Required,
+		}
+		if err := check.validate(); err != nil || seen[check.Name] {
+			continue
+		}
+		if check.Kind == CheckGoTest {
+			check.Package = cleanPackageDir(check.Package)
+		}
+		seen[check.Name] = true
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// validate reports whether the check can be persisted and run safely.
+func (c AcceptanceCheck) validate() error {
+	if !checkNamePattern.MatchString(c.Name) || c.Name == acceptanceFile {
+		return fmt.Errorf("invalid check name %q", c.Name)
+	}
+	if strings.TrimSpace(c.Content) == "" {
+		return fmt.Errorf("check %s has no content", c.Name)
+	}
+	switch c.Kind {
+	case CheckScript:
+		return nil
+	case CheckGoTest:
+		if !strings.HasSuffix(c.Name, "_test.go") {
+			return fmt.Errorf("go test %s must end in _test.go", c.Name)
+		}
+		pkg := cleanPackageDir(c.Package)
+		if path.IsAbs(pkg) || pkg == ".." || strings.HasPrefix(pkg, "../") {
+			return fmt.Errorf("go test %s has package outside the repository: %q", c.Name, c.Package)
+		}
+		funcs := testFuncPattern.FindAllStringSubmatch(c.Content, -1)
+		if len(funcs) == 0 {
+			return fmt.Errorf("go test %s declares no tests", c.Name)
+		}
+		for _, f := range funcs {
+			if !strings.HasPrefix(f[1], "TestContract") {
+				return fmt.Errorf("go test %s declares %s, want TestContract*", c.Name, f[1])
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown check kind %q", c.Kind)
+	}
+}
+
+// Commands returns the verification commands that run the checks. dir is
+// the directory the check files are stored in (see ContractStorage.AcceptanceDir).
+// A go_test check is copied into its package for the run and removed afterwards.
+func (c *AcceptanceContract) Commands(dir string) []VerificationCommand {
+	cmds := make([]VerificationCommand, 0, len(c.Checks))
+	for _, check := range c.Checks {
+		src := shellQuote(filepath.Join(dir, check.Name))
+		vc := VerificationCommand{
+			Expect:      "exit 0",
+			Description: "Acceptance: " + check.Criterion,
+			Required:    check.Required,
+		}
+		switch check.Kind {
+		case CheckScript:
+			vc.Command = "sh " + src
+		case CheckGoTest:
+			dst := shellQuote(path.Join(check.Package, check.Name))
+			vc.Command = fmt.Sprintf("cp %s %s && go test ./%s -run '%s' -count=1; status=$?; rm -f %s; exit $status",
+				src, dst, check.Package, acceptanceTestPattern, dst)
+			vc.Timeout = goTestTimeout
+		default:
+			continue
+		}
+		if check.Criterion == "" {
+			vc.Description = "Acceptance: " + check.Name
+		}
+		cmds = append(cmds, vc)
+	}
+	return cmds
+}
+
+// cleanPackageDir normalizes a go_test package to a slash-separated
+// directory relative to the repository root.
+func cleanPackageDir(pkg string) string {
+	pkg = strings.TrimSuffix(strings.TrimSpace(pkg), "/...")
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(pkg), "./"))
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
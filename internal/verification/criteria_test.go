@@ -0,0 +1,177 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePromptRunner returns a canned response and counts calls.
+type fakePromptRunner struct {
+	response string
+	calls    int
+}
+
+func (f *fakePromptRunner) RunPrompt(ctx context.Context, prompt, workDir string) (string, error) {
+	f.calls++
+	return f.response, nil
+}
+
+func checksResponse(t *testing.T, checks ...map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"checks": checks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Here are the checks:\n" + string(data)
+}
+
+func TestParseAcceptanceChecks_DropsUnsafeChecks(t *testing.T) {
+	response := checksResponse(t,
+		map[string]interface{}{"kind": "script", "name": "ok.sh", "criterion": "works", "content": "exit 0", "required": true},
+		map[string]interface{}{"kind": "script", "name": "../escape.sh", "content": "exit 0"},
+		map[string]interface{}{"kind": "script", "name": "contract.json", "content": "exit 0"},
+		map[string]interface{}{"kind": "script", "name": "ok.sh", "content": "exit 1"},
+		map[string]interface{}{"kind": "go_test", "name": "a_test.go", "package": "../other", "content": "func TestContractA(t *testing.T) {}"},
+		map[string]interface{}{"kind": "go_test", "name": "b_test.go", "package": "pkg", "content": "func TestB(t *testing.T) {}"},
+		map[string]interface{}{"kind": "go_test", "name": "c.go", "package": "pkg", "content": "func TestContractC(t *testing.T) {}"},
+		map[string]interface{}{"kind": "go_test", "name": "d_test.go", "package": "./pkg/...", "content": "func TestContractD(t *testing.T) {}"},
+		map[string]interface{}{"kind": "binary", "name": "e", "content": "x"},
+	)
+
+	checks, err := parseAcceptanceChecks(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "ok.sh,d_test.go" {
+		t.Fatalf("expected ok.sh and d_test.go, got %v", names)
+	}
+	if checks[1].Package != "pkg" {
+		t.Errorf("expected package to be normalized to pkg, got %q", checks[1].Package)
+	}
+}
+
+func TestContractGenerator_PersistsAndReusesChecks(t *testing.T) {
+	repo := t.TempDir()
+	storage := NewContractStorage(repo)
+	runner := &fakePromptRunner{response: checksResponse(t,
+		map[string]interface{}{"kind": "script", "name": "greeting.sh", "criterion": "greeting file exists", "content": "test -f greeting.txt", "required": true},
+	)}
+	gen := NewContractGenerator(storage, runner)
+
+	contract, err := gen.Generate(context.Background(), "task-1", "A greeting file exists", nil, "go", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contract == nil || len(contract.Checks) != 1 {
+		t.Fatalf("expected one check, got %+v", contract)
+	}
+	script := filepath.Join(storage.AcceptanceDir("task-1"), "greeting.sh")
+	if data, err := os.ReadFile(script); err != nil || string(data) != "test -f greeting.txt" {
+		t.Fatalf("expected the script to be persisted, got %q (%v)", data, err)
+	}
+
+	// Same criteria reuse the stored checks
+	again, err := gen.Generate(context.Background(), "task-1", "A greeting file exists", nil, "go", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runner.calls != 1 {
+		t.Errorf("expected stored checks to be reused, prompt ran %d times", runner.calls)
+	}
+	if again.Checks[0].Content != "test -f greeting.txt" {
+		t.Errorf("expected loaded check content, got %q", again.Checks[0].Content)
+	}
+
+	// Changed criteria regenerate them
+	if _, err := gen.Generate(context.Background(), "task-1", "A farewell file exists", nil, "go", repo); err != nil {
+		t.Fatal(err)
+	}
+	if runner.calls != 2 {
+		t.Errorf("expected changed criteria to regenerate checks, prompt ran %d times", runner.calls)
+	}
+}
+
+func TestContractGenerator_EmptyCriteria(t *testing.T) {
+	runner := &fakePromptRunner{}
+	gen := NewContractGenerator(NewContractStorage(t.TempDir()), runner)
+
+	contract, err := gen.Generate(context.Background(), "task-1", "  ", nil, "", t.TempDir())
+	if err != nil || contract != nil {
+		t.Errorf("expected no contract for empty criteria, got %+v (%v)", contract, err)
+	}
+	if runner.calls != 0 {
+		t.Errorf("expected no prompt for empty criteria")
+	}
+}
+
+func TestAcceptanceContract_CommandsRunScripts(t *testing.T) {
+	repo := t.TempDir()
+	storage := NewContractStorage(repo)
+	contract := &AcceptanceContract{
+		TaskID: "task-1",
+		Checks: []AcceptanceCheck{
+			{Kind: CheckScript, Name: "greeting.sh", Criterion: "greeting file exists", Content: "test -f greeting.txt", Required: true},
+		},
+	}
+	if err := storage.SaveAcceptance(contract); err != nil {
+		t.Fatal(err)
+	}
+
+	verify := &VerificationContract{Commands: contract.Commands(storage.AcceptanceDir("task-1"))}
+	if verify.Commands[0].Description != "Acceptance: greeting file exists" {
+		t.Errorf("unexpected description %q", verify.Commands[0].Description)
+	}
+
+	runner := NewContractRunner(repo)
+	result, err := runner.Run(context.Background(), verify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AllPassed {
+		t.Fatal("expected the check to fail before the criterion is met")
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "greeting.txt"), []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = runner.Run(context.Background(), verify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.AllPassed {
+		t.Errorf("expected the check to pass, got %s", result.Summary)
+	}
+}
+
+func TestAcceptanceContract_GoTestCommand(t *testing.T) {
+	contract := &AcceptanceContract{
+		Checks: []AcceptanceCheck{
+			{Kind: CheckGoTest, Name: "contract_login_test.go", Package: "internal/auth", Criterion: "login works", Required: true},
+		},
+	}
+	cmds := contract.Commands("/repo/.alphie/contracts/task-1")
+	if len(cmds) != 1 {
+		t.Fatalf("expected one command, got %d", len(cmds))
+	}
+	cmd := cmds[0].Command
+	for _, want := range []string{
+		"cp '/repo/.alphie/contracts/task-1/contract_login_test.go' 'internal/auth/contract_login_test.go'",
+		"go test ./internal/auth -run '^TestContract' -count=1",
+		"rm -f 'internal/auth/contract_login_test.go'",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("expected command to contain %q, got %q", want, cmd)
+		}
+	}
+	if cmds[0].Timeout != goTestTimeout {
+		t.Errorf("expected go test timeout, got %v", cmds[0].Timeout)
+	}
+}
@@ -0,0 +1,22 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVerificationFailed indicates required verification checks did not pass.
+// It is returned wrapped; test for it with errors.Is.
+var ErrVerificationFailed = errors.New("verification failed")
+
+// Err returns nil if all required checks passed, otherwise an error wrapping
+// ErrVerificationFailed with the result summary.
+func (r *VerificationResult) Err() error {
+	if r == nil || r.AllPassed {
+		return nil
+	}
+	if r.Summary == "" {
+		return ErrVerificationFailed
+	}
+	return fmt.Errorf("%w: %s", ErrVerificationFailed, r.Summary)
+}
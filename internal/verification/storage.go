@@ -9,6 +9,9 @@ import (
 	"time"
 )
 
+// acceptanceFile is the name of the file describing a task's acceptance checks.
+const acceptanceFile = "contract.json"
+
 // ContractStorage handles persistence of verification contracts.
 type ContractStorage struct {
 	baseDir string
@@ -149,6 +152,61 @@ func (s *ContractStorage) FinalExists(taskID string) bool {
 	return err == nil
 }
 
+// AcceptanceDir returns the directory holding a task's acceptance checks.
+func (s *ContractStorage) AcceptanceDir(taskID string) string {
+	return filepath.Join(s.baseDir, taskID)
+}
+
+// SaveAcceptance saves acceptance checks to the task's acceptance directory:
+// contract.json describes the checks and each check's content is written to
+// a file of its own name. Files of earlier checks are removed.
+func (s *ContractStorage) SaveAcceptance(contract *AcceptanceContract) error {
+	dir := s.AcceptanceDir(contract.TaskID)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("clear acceptance dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create acceptance dir: %w", err)
+	}
+
+	for _, check := range contract.Checks {
+		if err := os.WriteFile(filepath.Join(dir, check.Name), []byte(check.Content), 0644); err != nil {
+			return fmt.Errorf("write check %s: %w", check.Name, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(contract, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal acceptance contract: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, acceptanceFile), data, 0644); err != nil {
+		return fmt.Errorf("write acceptance contract: %w", err)
+	}
+	return nil
+}
+
+// LoadAcceptance loads a task's acceptance checks, including their content.
+func (s *ContractStorage) LoadAcceptance(taskID string) (*AcceptanceContract, error) {
+	dir := s.AcceptanceDir(taskID)
+	data, err := os.ReadFile(filepath.Join(dir, acceptanceFile))
+	if err != nil {
+		return nil, fmt.Errorf("read acceptance contract: %w", err)
+	}
+
+	var contract AcceptanceContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("unmarshal acceptance contract: %w", err)
+	}
+	for i := range contract.Checks {
+		content, err := os.ReadFile(filepath.Join(dir, contract.Checks[i].Name))
+		if err != nil {
+			return nil, fmt.Errorf("read check %s: %w", contract.Checks[i].Name, err)
+		}
+		contract.Checks[i].Content = string(content)
+	}
+	return &contract, nil
+}
+
 // ValidateRefinement checks that the refined contract only strengthens (never weakens) the draft.
 // Returns nil if valid, error describing the violation otherwise.
 func (s *ContractStorage) ValidateRefinement(draft, refined *VerificationContract) error {
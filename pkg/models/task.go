@@ -42,6 +42,16 @@ const (
 	TaskTypeRefactor TaskType = "REFACTOR"
 )
 
+// Task priorities, on the same scale as prog: lower values are more urgent.
+const (
+	// PriorityHigh is for critical work, such as fixing a missing feature.
+	PriorityHigh = 1
+	// PriorityMedium is the default priority.
+	PriorityMedium = 2
+	// PriorityLow is for work that can wait.
+	PriorityLow = 3
+)
+
 // Task represents a unit of work in the system.
 type Task struct {
 	// ID is the unique identifier for this task.
@@ -70,6 +80,13 @@ type Task struct {
 	// FileBoundaries are the files/directories this task is expected to modify.
 	// Used for conflict detection and scheduling.
 	FileBoundaries []string `json:"file_boundaries,omitempty"`
+	// ResourceLocks names shared resources this task needs exclusive access to
+	// while it runs (e.g. "db:schema", "port:3000"). Tasks holding the same
+	// lock are never scheduled concurrently, even if their files differ.
+	ResourceLocks []string `json:"resource_locks,omitempty"`
+	// Priority orders ready tasks for scheduling: PriorityHigh, PriorityMedium
+	// or PriorityLow. Zero means PriorityMedium.
+	Priority int `json:"priority,omitempty"`
 	// CreatedAt is when the task was created.
 	CreatedAt time.Time `json:"created_at"`
 	// CompletedAt is when the task was completed, if applicable.
@@ -86,6 +103,17 @@ type Task struct {
 	// - Tracking task difficulty
 	// - Debugging stuck tasks
 	ExecutionCount int `json:"execution_count,omitempty"`
+	// LastFailure describes why the previous attempt failed. It is injected
+	// into the agent prompt when the task is retried.
+	LastFailure string `json:"last_failure,omitempty"`
+}
+
+// EffectivePriority returns the task's priority, treating unset as PriorityMedium.
+func (t *Task) EffectivePriority() int {
+	if t.Priority <= 0 {
+		return PriorityMedium
+	}
+	return t.Priority
 }
 
 // RubricScore holds quality scores for completed work.